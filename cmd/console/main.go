@@ -12,10 +12,14 @@ import (
 
 	"github.com/joho/godotenv"
 
-	_ "github.com/kubestellar/console/pkg/agent" // Initialize AI providers
+	"github.com/kubestellar/console/pkg/agent" // Initialize AI providers
 	"github.com/kubestellar/console/pkg/ai"
 	"github.com/kubestellar/console/pkg/api"
+	"github.com/kubestellar/console/pkg/doctor"
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/sanitize"
+	"github.com/kubestellar/console/pkg/settings"
 )
 
 func main() {
@@ -29,13 +33,22 @@ func main() {
 	} else {
 		logHandler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
 	}
-	slog.SetDefault(slog.New(logHandler))
+	// Scrub credentials from every log line; REDACT_PATTERNS adds
+	// semicolon-separated regular expressions to the built-in patterns.
+	slog.SetDefault(slog.New(sanitize.NewRedactingHandler(logHandler)))
+	if err := sanitize.AddSecretPatterns(sanitize.SplitSecretPatterns(os.Getenv("REDACT_PATTERNS"))...); err != nil {
+		slog.Warn("ignoring REDACT_PATTERNS", "error", err)
+	}
 
 	// Parse flags
 	devMode := flag.Bool("dev", false, "Run in development mode")
 	port := flag.Int("port", 0, "Server port (default: 8080)")
 	dbPath := flag.String("db", "", "Database path (default: ./data/console.db)")
 	version := flag.Bool("version", false, "Print version and exit")
+	fakeMode := flag.Bool("fake-mode", false, "Serve deterministic in-memory clusters, benchmarks and AI replies instead of real backends")
+	airGapped := flag.Bool("air-gapped", false, "Disable every outbound integration and allow outbound HTTP only to AIR_GAPPED_ALLOWED_HOSTS")
+	check := flag.Bool("check", false, "Check the configuration, print a JSON report to stdout and exit non-zero if a check fails")
+	settings.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
 	if *version {
@@ -45,6 +58,14 @@ func main() {
 
 	slog.Info("console starting", "version", api.Version)
 
+	// Air-gapped mode must be in force before anything reaches out, AI
+	// provider setup included.
+	egressErr := egress.Setup(*airGapped)
+	if egressErr != nil && !*check {
+		slog.Error("invalid air-gapped configuration", "error", egressErr)
+		os.Exit(1)
+	}
+
 	// Load config from environment
 	cfg := api.LoadConfigFromEnv()
 
@@ -58,12 +79,20 @@ func main() {
 	if *dbPath != "" {
 		cfg.DatabasePath = *dbPath
 	}
+	if *fakeMode {
+		cfg.FakeMode = true
+	}
 
 	// Ensure data directory exists
 	if cfg.DatabasePath != "" {
 		ensureDir(cfg.DatabasePath)
 	}
 
+	if *check {
+		checks := append(api.ConfigChecks(cfg), agent.ProviderKeyChecks()...)
+		os.Exit(doctor.Main(os.Stdout, "console", api.Version, egressErr, checks...))
+	}
+
 	// Initialize AI providers early so they're available when the server starts
 	if err := ai.InitializeProviders(); err != nil {
 		slog.Warn("AI features disabled — add API keys in Settings to enable", "error", err)
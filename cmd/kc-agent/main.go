@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -10,7 +11,11 @@ import (
 	"syscall"
 
 	"github.com/kubestellar/console/pkg/agent"
+	"github.com/kubestellar/console/pkg/agent/relay"
+	"github.com/kubestellar/console/pkg/doctor"
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/sanitize"
 
 	// Blank-import federation providers so their init() funcs register them.
 	_ "github.com/kubestellar/console/pkg/agent/federation/providers"
@@ -24,12 +29,21 @@ func main() {
 	} else {
 		logHandler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
 	}
-	slog.SetDefault(slog.New(logHandler))
+	// Redact credentials from logs, honouring REDACT_PATTERNS like the console.
+	slog.SetDefault(slog.New(sanitize.NewRedactingHandler(logHandler)))
+	if err := sanitize.AddSecretPatterns(sanitize.SplitSecretPatterns(os.Getenv("REDACT_PATTERNS"))...); err != nil {
+		slog.Warn("ignoring REDACT_PATTERNS", "error", err)
+	}
 
 	port := flag.Int("port", 8585, "Port to listen on")
 	kubeconfig := flag.String("kubeconfig", "", "Path to kubeconfig file")
 	allowedOrigins := flag.String("allowed-origins", "", "Comma-separated list of additional allowed WebSocket origins")
 	version := flag.Bool("version", false, "Print version and exit")
+	airGapped := flag.Bool("air-gapped", false, "Disable every outbound integration and allow outbound HTTP only to AIR_GAPPED_ALLOWED_HOSTS")
+	check := flag.Bool("check", false, "Check the configuration, print a JSON report to stdout and exit non-zero if a check fails")
+	relayURL := flag.String("relay-url", os.Getenv("KC_RELAY_URL"), "mTLS relay of a hosted console to connect to, e.g. https://console.example.com:8443")
+	consoleURL := flag.String("console-url", os.Getenv("KC_CONSOLE_URL"), "Hosted console to pair with when --relay-url is set and the agent is not paired yet")
+	agentID := flag.String("agent-id", os.Getenv("KC_AGENT_ID"), "Agent ID the relay pairing code was issued for")
 	flag.Parse()
 
 	if *version {
@@ -39,6 +53,15 @@ func main() {
 
 	slog.Info("KubeStellar Console - Local Agent starting", "version", agent.Version, "commit", agent.CommitSHA, "built", agent.BuildTime)
 
+	egressErr := egress.Setup(*airGapped)
+	if egressErr != nil && !*check {
+		slog.Error("invalid air-gapped configuration", "error", egressErr)
+		os.Exit(1)
+	}
+	if *check {
+		os.Exit(doctor.Main(os.Stdout, "kc-agent", agent.Version, egressErr, agent.ConfigChecks(agent.Config{Kubeconfig: *kubeconfig})...))
+	}
+
 	// Parse comma-separated allowed origins from flag
 	var origins []string
 	if *allowedOrigins != "" {
@@ -49,10 +72,30 @@ func main() {
 		}
 	}
 
+	// The pairing code is read from the environment only, so it does not
+	// show up in the process list.
+	var relayClient *relay.Client
+	if *relayURL != "" {
+		var err error
+		relayClient, err = relay.Open(context.Background(), relay.Config{
+			URL:         *relayURL,
+			ConsoleURL:  *consoleURL,
+			AgentID:     *agentID,
+			PairingCode: os.Getenv("KC_PAIRING_CODE"),
+			Dir:         relay.DefaultDir(),
+		})
+		if err != nil {
+			slog.Error("failed to connect to the console relay", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("console relay enabled", "url", *relayURL, "agentId", relayClient.AgentID())
+	}
+
 	server, err := agent.NewServer(agent.Config{
 		Port:           *port,
 		Kubeconfig:     *kubeconfig,
 		AllowedOrigins: origins,
+		Relay:          relayClient,
 	})
 	if err != nil {
 		slog.Error("failed to create server", "error", err)
@@ -0,0 +1,12 @@
+// Package crds embeds the console.kubestellar.io CustomResourceDefinition
+// manifests in this directory, so the backend can install them on a cluster
+// that lacks them. The YAML files stay the single source, shared with
+// kubectl apply -f deploy/crds and the envtest harness.
+package crds
+
+import "embed"
+
+// FS holds every *.yaml manifest in this directory.
+//
+//go:embed *.yaml
+var FS embed.FS
@@ -0,0 +1,154 @@
+package envtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	crdKind = "CustomResourceDefinition"
+	// yamlReadBuffer is the decoder's lookahead for YAML-vs-JSON sniffing.
+	yamlReadBuffer = 4096
+)
+
+// CRDDir returns the repository's deploy/crds directory, resolved from this
+// source file so tests work from any package directory.
+func CRDDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return filepath.Join("deploy", "crds")
+	}
+	return filepath.Join(filepath.Dir(file), "..", "..", "deploy", "crds")
+}
+
+// LoadCRDs reads every CustomResourceDefinition from the given files and
+// directories. Directories are read non-recursively and only *.yaml, *.yml
+// and *.json files are considered; other documents in a file are ignored.
+func LoadCRDs(paths ...string) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading CRD path: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading CRD dir: %w", err)
+		}
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(p, entry.Name()))
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	for _, file := range files {
+		found, err := readCRDFile(file)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, found...)
+	}
+	if len(crds) == 0 {
+		return nil, fmt.Errorf("no CustomResourceDefinitions found in %s", strings.Join(paths, ", "))
+	}
+	return crds, nil
+}
+
+func readCRDFile(path string) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	f, err := os.Open(path) // #nosec G304 -- test-only path supplied by the caller
+	if err != nil {
+		return nil, fmt.Errorf("opening CRD file: %w", err)
+	}
+	defer f.Close()
+
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, yamlReadBuffer)
+	for {
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := decoder.Decode(&crd); err != nil {
+			if errors.Is(err, io.EOF) {
+				return crds, nil
+			}
+			return nil, fmt.Errorf("decoding %s: %w", path, err)
+		}
+		if crd.Kind != crdKind || crd.Name == "" {
+			continue
+		}
+		crds = append(crds, &crd)
+	}
+}
+
+// InstallCRDs creates or updates each CRD and waits until all of them are
+// established, so callers can use the new resources immediately.
+func InstallCRDs(ctx context.Context, cfg *rest.Config, crds []*apiextensionsv1.CustomResourceDefinition) error {
+	client, err := apiextensionsclient.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("building apiextensions client: %w", err)
+	}
+	api := client.ApiextensionsV1().CustomResourceDefinitions()
+
+	for _, crd := range crds {
+		_, err := api.Create(ctx, crd.DeepCopy(), metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			existing, getErr := api.Get(ctx, crd.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return fmt.Errorf("getting CRD %s: %w", crd.Name, getErr)
+			}
+			updated := crd.DeepCopy()
+			updated.ResourceVersion = existing.ResourceVersion
+			_, err = api.Update(ctx, updated, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("installing CRD %s: %w", crd.Name, err)
+		}
+	}
+
+	for _, crd := range crds {
+		for {
+			got, err := api.Get(ctx, crd.Name, metav1.GetOptions{})
+			if err == nil && crdEstablished(got) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("CRD %s not established: %w", crd.Name, ctx.Err())
+			case <-time.After(readyPollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established {
+			return cond.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+func TestLoadCRDs_ConsoleCRDs(t *testing.T) {
+	crds, err := LoadCRDs(CRDDir())
+	require.NoError(t, err)
+
+	plurals := map[string]bool{}
+	for _, crd := range crds {
+		assert.Equal(t, v1alpha1.GroupVersion.Group, crd.Spec.Group)
+		plurals[crd.Spec.Names.Plural] = true
+	}
+	for _, gvr := range []string{
+		v1alpha1.ManagedWorkloadGVR.Resource,
+		v1alpha1.ClusterGroupGVR.Resource,
+		v1alpha1.WorkloadDeploymentGVR.Resource,
+	} {
+		assert.True(t, plurals[gvr], "deploy/crds defines %s", gvr)
+	}
+}
+
+func TestLoadCRDs_MultiDocumentAndFiltering(t *testing.T) {
+	dir := t.TempDir()
+	multi := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-crd
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names: {kind: Widget, plural: widgets}
+  scope: Namespaced
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "multi.yaml"), []byte(multi), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not yaml: ["), 0o600))
+
+	crds, err := LoadCRDs(dir)
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	assert.Equal(t, "widgets.example.com", crds[0].Name)
+
+	_, err = LoadCRDs(t.TempDir())
+	assert.ErrorContains(t, err, "no CustomResourceDefinitions")
+
+	_, err = LoadCRDs(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestCRDEstablished(t *testing.T) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	assert.False(t, crdEstablished(crd))
+
+	crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+		{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+		{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionFalse},
+	}
+	assert.False(t, crdEstablished(crd))
+
+	crd.Status.Conditions[1].Status = apiextensionsv1.ConditionTrue
+	assert.True(t, crdEstablished(crd))
+}
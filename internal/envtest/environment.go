@@ -0,0 +1,419 @@
+// Package envtest runs a real kube-apiserver and etcd for integration tests
+// of the console CRD stack: ConsolePersistence, ConsoleWatcher and the
+// WorkloadDeployment reconciler.
+//
+// The binaries are located through KUBEBUILDER_ASSETS, the same variable
+// controller-runtime's envtest uses, so `setup-envtest use -p path` output
+// can be exported as-is. When the variable is unset the tests that need an
+// API server are skipped, which keeps `go test ./...` green on machines
+// without the assets.
+package envtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// AssetsEnvVar names the directory holding the etcd and kube-apiserver
+// binaries.
+const AssetsEnvVar = "KUBEBUILDER_ASSETS"
+
+const (
+	etcdBinary      = "etcd"
+	apiServerBinary = "kube-apiserver"
+
+	defaultStartTimeout = 60 * time.Second
+	stopTimeout         = 10 * time.Second
+	readyPollInterval   = 100 * time.Millisecond
+	// outputTailBytes is how much process output is kept for error messages.
+	outputTailBytes = 4096
+
+	adminUser  = "envtest-admin"
+	saKeyBits  = 2048
+	serviceIPs = "10.0.0.0/24"
+)
+
+// Options configures Start. The zero value starts both processes from
+// KUBEBUILDER_ASSETS and installs the console CRDs from deploy/crds.
+type Options struct {
+	// AssetsDir overrides KUBEBUILDER_ASSETS.
+	AssetsDir string
+	// CRDPaths are files or directories of CRD manifests to install.
+	// Defaults to CRDDir().
+	CRDPaths []string
+	// SkipCRDs starts a bare API server without installing any CRDs.
+	SkipCRDs bool
+	// StartTimeout bounds how long Start waits for /readyz and for the CRDs
+	// to become established. Defaults to 60s.
+	StartTimeout time.Duration
+}
+
+// Environment is a running kube-apiserver backed by its own etcd.
+type Environment struct {
+	// Config talks to the API server as a system:masters user.
+	Config *rest.Config
+	// Kubernetes and Dynamic are built from Config.
+	Kubernetes kubernetes.Interface
+	Dynamic    dynamic.Interface
+
+	dir       string
+	etcd      *process
+	apiServer *process
+	stopOnce  sync.Once
+	stopErr   error
+}
+
+// AssetsDir returns the directory named by KUBEBUILDER_ASSETS, or "" when
+// it is unset.
+func AssetsDir() string {
+	return os.Getenv(AssetsEnvVar)
+}
+
+// findBinaries returns the etcd and kube-apiserver paths inside dir.
+func findBinaries(dir string) (etcd, apiServer string, err error) {
+	if dir == "" {
+		return "", "", fmt.Errorf("%s is not set", AssetsEnvVar)
+	}
+	paths := make([]string, 0, 2)
+	for _, name := range []string{etcdBinary, apiServerBinary} {
+		p := filepath.Join(dir, name)
+		info, statErr := os.Stat(p)
+		if statErr != nil {
+			return "", "", fmt.Errorf("envtest asset %s: %w", name, statErr)
+		}
+		if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+			return "", "", fmt.Errorf("envtest asset %s is not an executable file", p)
+		}
+		paths = append(paths, p)
+	}
+	return paths[0], paths[1], nil
+}
+
+// Start launches etcd and kube-apiserver, waits for the API server to report
+// ready and installs the CRDs. The caller must call Stop.
+func Start(ctx context.Context, opts Options) (env *Environment, err error) {
+	assets := opts.AssetsDir
+	if assets == "" {
+		assets = AssetsDir()
+	}
+	etcdPath, apiServerPath, err := findBinaries(assets)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.StartTimeout
+	if timeout <= 0 {
+		timeout = defaultStartTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "console-envtest-")
+	if err != nil {
+		return nil, fmt.Errorf("creating envtest work dir: %w", err)
+	}
+	env = &Environment{dir: dir}
+	defer func() {
+		if err != nil {
+			env.Stop() //nolint:errcheck // the start error is the one worth reporting
+			env = nil
+		}
+	}()
+
+	etcdURL, err := env.startEtcd(etcdPath)
+	if err != nil {
+		return env, err
+	}
+	if err := env.startAPIServer(apiServerPath, etcdURL); err != nil {
+		return env, err
+	}
+	if err := env.waitReady(ctx); err != nil {
+		return env, err
+	}
+
+	if !opts.SkipCRDs {
+		paths := opts.CRDPaths
+		if len(paths) == 0 {
+			paths = []string{CRDDir()}
+		}
+		crds, err := LoadCRDs(paths...)
+		if err != nil {
+			return env, err
+		}
+		if err := InstallCRDs(ctx, env.Config, crds); err != nil {
+			return env, err
+		}
+	}
+	return env, nil
+}
+
+// Stop terminates the API server and etcd and removes their data. It is
+// safe to call more than once.
+func (e *Environment) Stop() error {
+	e.stopOnce.Do(func() {
+		// Stop the API server first so it does not log a storm of etcd
+		// connection errors on the way down.
+		e.stopErr = errors.Join(e.apiServer.stop(), e.etcd.stop())
+		if e.dir != "" {
+			e.stopErr = errors.Join(e.stopErr, os.RemoveAll(e.dir))
+		}
+	})
+	return e.stopErr
+}
+
+func (e *Environment) startEtcd(binary string) (string, error) {
+	clientPort, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	peerPort, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	clientURL := "http://127.0.0.1:" + strconv.Itoa(clientPort)
+	peerURL := "http://127.0.0.1:" + strconv.Itoa(peerPort)
+	e.etcd, err = startProcess(binary,
+		"--data-dir="+filepath.Join(e.dir, "etcd"),
+		"--listen-client-urls="+clientURL,
+		"--advertise-client-urls="+clientURL,
+		"--listen-peer-urls="+peerURL,
+		"--initial-advertise-peer-urls="+peerURL,
+		"--initial-cluster=default="+peerURL,
+		"--unsafe-no-fsync=true",
+	)
+	return clientURL, err
+}
+
+func (e *Environment) startAPIServer(binary, etcdURL string) error {
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	tokenFile := filepath.Join(e.dir, "tokens.csv")
+	line := fmt.Sprintf("%s,%s,%s,\"system:masters\"\n", token, adminUser, adminUser)
+	if err := os.WriteFile(tokenFile, []byte(line), 0o600); err != nil {
+		return fmt.Errorf("writing token file: %w", err)
+	}
+	saKeyFile := filepath.Join(e.dir, "sa.key")
+	if err := writeServiceAccountKey(saKeyFile); err != nil {
+		return err
+	}
+
+	e.apiServer, err = startProcess(binary,
+		"--etcd-servers="+etcdURL,
+		"--bind-address=127.0.0.1",
+		"--secure-port="+strconv.Itoa(port),
+		// With no --tls-cert-file the API server writes a self-signed
+		// serving certificate into --cert-dir.
+		"--cert-dir="+filepath.Join(e.dir, "certs"),
+		"--token-auth-file="+tokenFile,
+		"--authorization-mode=RBAC",
+		"--service-account-issuer=https://envtest.kubestellar.invalid",
+		"--service-account-key-file="+saKeyFile,
+		"--service-account-signing-key-file="+saKeyFile,
+		"--service-cluster-ip-range="+serviceIPs,
+		"--disable-admission-plugins=ServiceAccount",
+		"--allow-privileged=true",
+	)
+	if err != nil {
+		return err
+	}
+
+	e.Config = &rest.Config{
+		Host:        "https://127.0.0.1:" + strconv.Itoa(port),
+		BearerToken: token,
+		// The serving certificate is self-signed and only lives as long as
+		// the process, so there is nothing to pin.
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+		QPS:             -1,
+	}
+	if e.Kubernetes, err = kubernetes.NewForConfig(e.Config); err != nil {
+		return fmt.Errorf("building kubernetes client: %w", err)
+	}
+	if e.Dynamic, err = dynamic.NewForConfig(e.Config); err != nil {
+		return fmt.Errorf("building dynamic client: %w", err)
+	}
+	return nil
+}
+
+// waitReady polls /readyz until the API server answers ok.
+func (e *Environment) waitReady(ctx context.Context) error {
+	var lastErr error
+	for {
+		if exited := e.firstExited(); exited != nil {
+			return exited
+		}
+		body, err := e.Kubernetes.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		if err == nil && string(body) == "ok" {
+			return nil
+		}
+		lastErr = err
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("kube-apiserver not ready: %w (last error: %v)\n%s", ctx.Err(), lastErr, e.apiServer.tail())
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// firstExited reports a process that exited while Start was waiting.
+func (e *Environment) firstExited() error {
+	for _, p := range []*process{e.etcd, e.apiServer} {
+		if err := p.exited(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// process is a child process whose output is kept for error reports.
+type process struct {
+	name string
+	cmd  *exec.Cmd
+	out  *tailBuffer
+	done chan struct{}
+	err  error
+}
+
+func startProcess(binary string, args ...string) (*process, error) {
+	p := &process{
+		name: filepath.Base(binary),
+		cmd:  exec.Command(binary, args...), // #nosec G204 -- binary comes from the test's own KUBEBUILDER_ASSETS
+		out:  &tailBuffer{limit: outputTailBytes},
+		done: make(chan struct{}),
+	}
+	p.cmd.Stdout = p.out
+	p.cmd.Stderr = p.out
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting %s: %w", p.name, err)
+	}
+	go func() {
+		p.err = p.cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// exited returns an error if the process has already terminated.
+func (p *process) exited() error {
+	if p == nil {
+		return nil
+	}
+	select {
+	case <-p.done:
+		return fmt.Errorf("%s exited early: %v\n%s", p.name, p.err, p.tail())
+	default:
+		return nil
+	}
+}
+
+// stop sends SIGTERM and falls back to SIGKILL after stopTimeout.
+func (p *process) stop() error {
+	if p == nil {
+		return nil
+	}
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("stopping %s: %w", p.name, err)
+	}
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(stopTimeout):
+		if err := p.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("killing %s: %w", p.name, err)
+		}
+		<-p.done
+		return nil
+	}
+}
+
+func (p *process) tail() string {
+	if p == nil {
+		return ""
+	}
+	return p.out.String()
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Write(p)
+	if extra := b.buf.Len() - b.limit; extra > 0 {
+		b.buf.Next(extra)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// freePort asks the kernel for an unused loopback port. The port is released
+// before the child binds it, which is racy in theory but good enough for
+// tests and is what controller-runtime does too.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("finding a free port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func randomToken() (string, error) {
+	const tokenBytes = 24
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+	return fmt.Sprintf("%x", b), nil
+}
+
+// writeServiceAccountKey writes a fresh RSA key. kube-apiserver accepts the
+// private key for both signing and verification.
+func writeServiceAccountKey(path string) error {
+	key, err := rsa.GenerateKey(rand.Reader, saKeyBits)
+	if err != nil {
+		return fmt.Errorf("generating service account key: %w", err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return fmt.Errorf("writing service account key: %w", err)
+	}
+	return nil
+}
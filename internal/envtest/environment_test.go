@@ -0,0 +1,73 @@
+package envtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBinaries(t *testing.T) {
+	_, _, err := findBinaries("")
+	require.ErrorContains(t, err, AssetsEnvVar)
+
+	dir := t.TempDir()
+	_, _, err = findBinaries(dir)
+	require.ErrorContains(t, err, etcdBinary)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, etcdBinary), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, apiServerBinary), []byte("#!/bin/sh\n"), 0o644))
+	_, _, err = findBinaries(dir)
+	require.ErrorContains(t, err, "not an executable", "a non-executable kube-apiserver is rejected")
+
+	require.NoError(t, os.Chmod(filepath.Join(dir, apiServerBinary), 0o755))
+	etcd, apiServer, err := findBinaries(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, etcdBinary), etcd)
+	assert.Equal(t, filepath.Join(dir, apiServerBinary), apiServer)
+}
+
+func TestStart_MissingAssets(t *testing.T) {
+	_, err := Start(context.Background(), Options{AssetsDir: t.TempDir()})
+	require.Error(t, err)
+}
+
+func TestStart_ProcessExitsEarly(t *testing.T) {
+	dir := t.TempDir()
+	failing := []byte("#!/bin/sh\necho boom >&2\nexit 3\n")
+	for _, name := range []string{etcdBinary, apiServerBinary} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), failing, 0o755))
+	}
+	_, err := Start(context.Background(), Options{AssetsDir: dir, SkipCRDs: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exited early")
+	assert.Contains(t, err.Error(), "boom", "process output is included in the error")
+}
+
+func TestTailBuffer_KeepsLastBytes(t *testing.T) {
+	b := &tailBuffer{limit: 8}
+	_, _ = b.Write([]byte("0123456789"))
+	_, _ = b.Write([]byte("ab"))
+	assert.Equal(t, "456789ab", b.String())
+}
+
+func TestStop_Idempotent(t *testing.T) {
+	env := &Environment{dir: t.TempDir()}
+	require.NoError(t, env.Stop())
+	require.NoError(t, env.Stop())
+	_, err := os.Stat(env.dir)
+	assert.True(t, os.IsNotExist(err), "the work dir is removed")
+}
+
+func TestRandomToken(t *testing.T) {
+	a, err := randomToken()
+	require.NoError(t, err)
+	b, err := randomToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, a, b)
+	assert.False(t, strings.ContainsAny(a, ",\""), "tokens are safe to write into the CSV token file")
+}
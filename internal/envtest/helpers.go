@@ -0,0 +1,184 @@
+package envtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// DefaultWaitTimeout bounds the Wait* helpers.
+const DefaultWaitTimeout = 30 * time.Second
+
+// New starts an Environment for a single test and stops it in t.Cleanup.
+// The test is skipped when KUBEBUILDER_ASSETS is unset or when -short is
+// passed; any other start failure is fatal.
+func New(t testing.TB, opts Options) *Environment {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("envtest: skipped in -short mode")
+	}
+	if opts.AssetsDir == "" && AssetsDir() == "" {
+		t.Skipf("envtest: %s not set; run `setup-envtest use -p path` to install kube-apiserver and etcd", AssetsEnvVar)
+	}
+	env, err := Start(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("envtest: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Logf("envtest: stop: %v", err)
+		}
+	})
+	return env
+}
+
+// CreateNamespace creates a namespace with a generated name and deletes it
+// when the test ends. Without a namespace controller the namespace stays
+// Terminating, which is harmless because every Environment is throwaway.
+func (e *Environment) CreateNamespace(t testing.TB, prefix string) string {
+	t.Helper()
+	ns, err := e.Kubernetes.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: prefix + "-"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("envtest: creating namespace: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = e.Kubernetes.CoreV1().Namespaces().Delete(context.Background(), ns.Name, metav1.DeleteOptions{})
+	})
+	return ns.Name
+}
+
+// Persistence returns a ConsolePersistence backed by the API server.
+func (e *Environment) Persistence() k8s.ConsolePersistence {
+	return k8s.NewConsolePersistence(e.Dynamic)
+}
+
+// MultiClusterClient returns a client whose contexts all point at this API
+// server, so multi-cluster code paths such as DeployWorkload can run
+// against it. At least one context name is required.
+func (e *Environment) MultiClusterClient(contexts ...string) (*k8s.MultiClusterClient, error) {
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("at least one context name is required")
+	}
+	raw := api.NewConfig()
+	clients := make(map[string]kubernetes.Interface, len(contexts))
+	dynamicClients := make(map[string]dynamic.Interface, len(contexts))
+	for _, name := range contexts {
+		raw.Clusters[name] = &api.Cluster{Server: e.Config.Host, InsecureSkipTLSVerify: true}
+		raw.AuthInfos[name] = &api.AuthInfo{Token: e.Config.BearerToken}
+		raw.Contexts[name] = &api.Context{Cluster: name, AuthInfo: name}
+		clients[name] = e.Kubernetes
+		dynamicClients[name] = e.Dynamic
+	}
+	raw.CurrentContext = contexts[0]
+
+	client := k8s.NewInMemoryMultiClusterClient(raw, clients, dynamicClients)
+	for _, name := range contexts {
+		client.InjectRestConfig(name, e.Config)
+	}
+	return client, nil
+}
+
+// EventRecorder collects ConsoleWatcher events for assertions.
+type EventRecorder struct {
+	mu      sync.Mutex
+	events  []k8s.ConsoleResourceEvent
+	changed chan struct{}
+}
+
+// NewEventRecorder returns an empty recorder. Pass its Handle method to
+// k8s.NewConsoleWatcher.
+func NewEventRecorder() *EventRecorder {
+	return &EventRecorder{changed: make(chan struct{})}
+}
+
+// Handle records an event. It is a k8s.ConsoleResourceEventHandler.
+func (r *EventRecorder) Handle(event k8s.ConsoleResourceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Events returns a copy of everything recorded so far.
+func (r *EventRecorder) Events() []k8s.ConsoleResourceEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]k8s.ConsoleResourceEvent(nil), r.events...)
+}
+
+// WaitFor blocks until an event with the given type ("ADDED", "MODIFIED",
+// "DELETED"), resource type and name has been recorded, failing the test
+// after DefaultWaitTimeout.
+func (r *EventRecorder) WaitFor(t testing.TB, eventType, resourceType, name string) k8s.ConsoleResourceEvent {
+	t.Helper()
+	deadline := time.After(DefaultWaitTimeout)
+	for {
+		r.mu.Lock()
+		for _, ev := range r.events {
+			if ev.Type == eventType && ev.ResourceType == resourceType && ev.Name == name {
+				r.mu.Unlock()
+				return ev
+			}
+		}
+		changed := r.changed
+		r.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			t.Fatalf("envtest: no %s %s %q event after %s; got %d events", eventType, resourceType, name, DefaultWaitTimeout, len(r.Events()))
+			return k8s.ConsoleResourceEvent{}
+		}
+	}
+}
+
+// StartWatcher runs a ConsoleWatcher on namespace until the test ends and
+// returns the recorder receiving its events.
+func (e *Environment) StartWatcher(t testing.TB, namespace string) *EventRecorder {
+	t.Helper()
+	recorder := NewEventRecorder()
+	watcher := k8s.NewConsoleWatcher(e.Dynamic, namespace, recorder.Handle)
+	if err := watcher.Start(context.Background()); err != nil {
+		t.Fatalf("envtest: starting console watcher: %v", err)
+	}
+	t.Cleanup(watcher.Stop)
+	return recorder
+}
+
+// WaitForDeploymentPhase polls a WorkloadDeployment until its status phase
+// is one of phases and returns it, failing the test after
+// DefaultWaitTimeout.
+func (e *Environment) WaitForDeploymentPhase(t testing.TB, namespace, name string, phases ...string) *v1alpha1.WorkloadDeployment {
+	t.Helper()
+	persistence := e.Persistence()
+	deadline := time.Now().Add(DefaultWaitTimeout)
+	var last string
+	for time.Now().Before(deadline) {
+		wd, err := persistence.GetWorkloadDeployment(context.Background(), namespace, name)
+		if err == nil && wd != nil {
+			last = wd.Status.Phase
+			for _, p := range phases {
+				if last == p {
+					return wd
+				}
+			}
+		}
+		time.Sleep(readyPollInterval)
+	}
+	t.Fatalf("envtest: WorkloadDeployment %s/%s phase %q, want one of %v", namespace, name, last, phases)
+	return nil
+}
@@ -0,0 +1,101 @@
+package envtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// TestConsoleCRDs_Envtest exercises ConsolePersistence and
+// ConsoleWatcher against a real API server. One Environment is shared by the
+// subtests; each uses its own namespace.
+func TestConsoleCRDs_Envtest(t *testing.T) {
+	env := New(t, Options{})
+	ctx := context.Background()
+
+	t.Run("persistence CRUD round-trips through the API server", func(t *testing.T) {
+		ns := env.CreateNamespace(t, "persistence")
+		p := env.Persistence()
+
+		replicas := int32(2)
+		created, err := p.CreateManagedWorkload(ctx, &v1alpha1.ManagedWorkload{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+			Spec: v1alpha1.ManagedWorkloadSpec{
+				SourceCluster:   "hub",
+				SourceNamespace: "default",
+				WorkloadRef:     v1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+				Replicas:        &replicas,
+			},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, created.ResourceVersion)
+
+		got, err := p.GetManagedWorkload(ctx, ns, "web")
+		require.NoError(t, err)
+		require.NotNil(t, got.Spec.Replicas)
+		assert.Equal(t, replicas, *got.Spec.Replicas)
+
+		_, err = p.CreateClusterGroup(ctx, &v1alpha1.ClusterGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: ns},
+			Spec:       v1alpha1.ClusterGroupSpec{StaticMembers: []string{"a", "b"}},
+		})
+		require.NoError(t, err)
+		groups, err := p.ListClusterGroups(ctx, ns)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, []string{"a", "b"}, groups[0].Spec.StaticMembers)
+
+		wd, err := p.CreateWorkloadDeployment(ctx, &v1alpha1.WorkloadDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-rollout", Namespace: ns},
+			Spec: v1alpha1.WorkloadDeploymentSpec{
+				WorkloadRef:    v1alpha1.ResourceReference{Name: "web"},
+				TargetClusters: []string{"a"},
+			},
+		})
+		require.NoError(t, err)
+
+		wd.Status.Phase = "InProgress"
+		updated, err := p.UpdateWorkloadDeploymentStatus(ctx, wd)
+		require.NoError(t, err)
+		assert.NotEqual(t, wd.ResourceVersion, updated.ResourceVersion)
+
+		wd.Status.Phase = "Complete"
+		_, err = p.UpdateWorkloadDeploymentStatus(ctx, wd)
+		assert.Error(t, err, "a stale resourceVersion is rejected with a conflict")
+
+		require.NoError(t, p.DeleteManagedWorkload(ctx, ns, "web"))
+		_, err = p.GetManagedWorkload(ctx, ns, "web")
+		assert.Error(t, err)
+	})
+
+	t.Run("watcher reports add, modify and delete", func(t *testing.T) {
+		ns := env.CreateNamespace(t, "watcher")
+		events := env.StartWatcher(t, ns)
+		p := env.Persistence()
+
+		cg, err := p.CreateClusterGroup(ctx, &v1alpha1.ClusterGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: ns},
+			Spec:       v1alpha1.ClusterGroupSpec{StaticMembers: []string{"a"}},
+		})
+		require.NoError(t, err)
+		added := events.WaitFor(t, "ADDED", "ClusterGroup", "gpu")
+		assert.Equal(t, ns, added.Namespace)
+
+		cg.Spec.StaticMembers = append(cg.Spec.StaticMembers, "b")
+		_, err = p.UpdateClusterGroup(ctx, cg)
+		require.NoError(t, err)
+		events.WaitFor(t, "MODIFIED", "ClusterGroup", "gpu")
+
+		require.NoError(t, p.DeleteClusterGroup(ctx, ns, "gpu"))
+		events.WaitFor(t, "DELETED", "ClusterGroup", "gpu")
+
+		for _, ev := range events.Events() {
+			assert.Equal(t, ns, ev.Namespace, "the watcher only reports its own namespace")
+		}
+	})
+}
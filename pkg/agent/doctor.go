@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubestellar/console/pkg/agent/kube"
+	"github.com/kubestellar/console/pkg/doctor"
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// keyValidatedProviders are the providers whose API keys validateAPIKey
+// checks against the provider's API; other keys are accepted as set.
+var keyValidatedProviders = []string{"claude", "openai", "gemini", "openrouter", "groq"}
+
+// ConfigChecks returns the checks kc-agent --check runs against cfg.
+func ConfigChecks(cfg Config) []doctor.Check {
+	checks := []doctor.Check{
+		{Name: "kubectl", Run: func(context.Context) (doctor.Status, string) {
+			if _, err := kube.NewKubectlProxy(cfg.Kubeconfig); err != nil {
+				return doctor.StatusFail, err.Error()
+			}
+			return doctor.StatusOK, ""
+		}},
+		{Name: "kubeconfig", Run: func(context.Context) (doctor.Status, string) {
+			k8sClient, err := k8s.NewMultiClusterClient(cfg.Kubeconfig)
+			if err != nil {
+				return doctor.StatusFail, err.Error()
+			}
+			if !k8sClient.HasClusterConfig() {
+				return doctor.StatusWarn, "no kubeconfig at " + k8sClient.KubeconfigPath() + "; cluster data is unavailable"
+			}
+			if err := k8sClient.LoadConfig(); err != nil {
+				return doctor.StatusFail, err.Error()
+			}
+			contexts := 0
+			if raw := k8sClient.GetRawConfig(); raw != nil {
+				contexts = len(raw.Contexts)
+			}
+			return doctor.StatusOK, fmt.Sprintf("%s: %d contexts", k8sClient.KubeconfigPath(), contexts)
+		}},
+	}
+	return append(checks, ProviderKeyChecks()...)
+}
+
+// ProviderKeyChecks returns a check per configured AI provider key that
+// tries the key against the provider's API. A key the provider rejects
+// fails; one that could not be tried, e.g. offline, only warns.
+func ProviderKeyChecks() []doctor.Check {
+	if egress.AirGapped() {
+		return []doctor.Check{{Name: "provider-keys", Run: func(context.Context) (doctor.Status, string) {
+			return doctor.StatusSkipped, "cloud AI providers are off in air-gapped mode"
+		}}}
+	}
+	cm := GetConfigManager()
+	var checks []doctor.Check
+	for _, provider := range keyValidatedProviders {
+		if !cm.HasAPIKey(provider) {
+			continue
+		}
+		checks = append(checks, doctor.Check{Name: "provider-key/" + provider, Run: func(context.Context) (doctor.Status, string) {
+			valid, err := (&Server{}).validateAPIKey(provider)
+			switch {
+			case err != nil:
+				return doctor.StatusWarn, "could not validate: " + err.Error()
+			case !valid:
+				return doctor.StatusFail, "the provider rejected the key"
+			}
+			return doctor.StatusOK, ""
+		}})
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctor.Check{Name: "provider-keys", Run: func(context.Context) (doctor.Status, string) {
+			return doctor.StatusSkipped, "no provider API keys configured"
+		}})
+	}
+	return checks
+}
@@ -30,6 +30,11 @@ var (
 		Version:  "v1beta1",
 		Resource: "machinedeployments",
 	}
+	capiMachineGVR = schema.GroupVersionResource{
+		Group:    "cluster.x-k8s.io",
+		Version:  "v1beta1",
+		Resource: "machines",
+	}
 	capiKubeadmControlPlaneGVR = schema.GroupVersionResource{
 		Group:    "controlplane.cluster.x-k8s.io",
 		Version:  "v1beta1",
@@ -54,6 +59,16 @@ const (
 	// KubeadmControlPlanes to identify the owning CAPI Cluster.
 	capiClusterNameLabel = "cluster.x-k8s.io/cluster-name"
 
+	// capiControlPlaneLabel marks Machines that belong to the control plane.
+	capiControlPlaneLabel = "cluster.x-k8s.io/control-plane"
+
+	// capiMachinePhaseRunning is the Machine phase once its node has joined.
+	capiMachinePhaseRunning = "Running"
+	// capiMachinePhaseFailed is the Machine phase after a terminal error.
+	capiMachinePhaseFailed = "Failed"
+	// capiMachinePhaseDeleting is the Machine phase during teardown.
+	capiMachinePhaseDeleting = "Deleting"
+
 	// capiInfraGroupPrefix is prepended to the infrastructure reference
 	// kind to form the federation group name (e.g. "capi:aws").
 	capiInfraGroupPrefix = "capi:"
@@ -100,9 +115,13 @@ func (p *capiProvider) ReadClusters(ctx context.Context, cfg *rest.Config) ([]fe
 	// Batch-fetch KubeadmControlPlanes for control-plane readiness.
 	kcpByCluster := capiIndexKubeadmControlPlanes(ctx, dc)
 
+	// Batch-fetch Machines for per-machine health.
+	machinesByCluster := capiIndexMachines(ctx, dc)
+
 	out := make([]federation.FederatedCluster, 0, len(clusterList.Items))
 	for i := range clusterList.Items {
 		fc := parseCAPICluster(&clusterList.Items[i], mdByCluster, kcpByCluster)
+		capiApplyMachines(&fc, machinesByCluster[fc.Name])
 		out = append(out, fc)
 	}
 	return out, nil
@@ -147,10 +166,38 @@ func (p *capiProvider) ReadGroups(ctx context.Context, cfg *rest.Config) ([]fede
 	return out, nil
 }
 
-func (p *capiProvider) ReadPendingJoins(_ context.Context, _ *rest.Config) ([]federation.PendingJoin, error) {
-	// CAPI clusters in provisioning state are already surfaced as clusters
-	// with ClusterStateProvisioning — no separate pending-join concept.
-	return nil, nil
+// ReadPendingJoins reports Machines whose node has not joined yet. The
+// clusters themselves are surfaced by ReadClusters with
+// ClusterStateProvisioning even while their API server is unreachable.
+func (p *capiProvider) ReadPendingJoins(ctx context.Context, cfg *rest.Config) ([]federation.PendingJoin, error) {
+	dc, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := dc.Resource(capiMachineGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if isNotFoundOrGroupNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	out := make([]federation.PendingJoin, 0)
+	for i := range list.Items {
+		obj := &list.Items[i]
+		m := parseCAPIMachine(obj)
+		if !capiMachineBootstrapping(m) {
+			continue
+		}
+		out = append(out, federation.PendingJoin{
+			Provider:    federation.ProviderCAPI,
+			ClusterName: capiMachineClusterName(obj),
+			RequestedAt: obj.GetCreationTimestamp().Time,
+			Detail:      fmt.Sprintf("Machine %s is %s", m.Name, strings.ToLower(capiMachinePhaseOrPending(m.Phase))),
+		})
+	}
+	return out, nil
 }
 
 // ---------------------------------------------------------------------------
@@ -307,6 +354,115 @@ func safeInt64ToInt32(v int64) int32 {
 	return int32(v)
 }
 
+// ---------------------------------------------------------------------------
+// Machine index
+// ---------------------------------------------------------------------------
+
+// capiIndexMachines lists all Machines and groups them by owning cluster.
+// If the CRD is absent or the list fails, returns an empty map.
+func capiIndexMachines(ctx context.Context, dc dynamic.Interface) map[string][]federation.Machine {
+	out := map[string][]federation.Machine{}
+	list, err := dc.Resource(capiMachineGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return out
+	}
+	for i := range list.Items {
+		obj := &list.Items[i]
+		clusterName := capiMachineClusterName(obj)
+		if clusterName == "" {
+			continue
+		}
+		out[clusterName] = append(out[clusterName], parseCAPIMachine(obj))
+	}
+	return out
+}
+
+// capiMachineClusterName returns the owning cluster from spec.clusterName,
+// falling back to the cluster-name label.
+func capiMachineClusterName(obj *unstructured.Unstructured) string {
+	if name, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterName"); name != "" {
+		return name
+	}
+	return obj.GetLabels()[capiClusterNameLabel]
+}
+
+func parseCAPIMachine(obj *unstructured.Unstructured) federation.Machine {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	nodeName, _, _ := unstructured.NestedString(obj.Object, "status", "nodeRef", "name")
+	_, controlPlane := obj.GetLabels()[capiControlPlaneLabel]
+
+	m := federation.Machine{
+		Name:         obj.GetName(),
+		Phase:        phase,
+		NodeName:     nodeName,
+		ControlPlane: controlPlane,
+		Healthy:      true,
+	}
+
+	reason, _, _ := unstructured.NestedString(obj.Object, "status", "failureReason")
+	message, _, _ := unstructured.NestedString(obj.Object, "status", "failureMessage")
+	if reason != "" || message != "" || phase == capiMachinePhaseFailed {
+		m.Healthy, m.Reason, m.Message = false, reason, message
+		return m
+	}
+
+	// NodeHealthy goes False when the node is gone or NotReady;
+	// HealthCheckSucceeded is set by a MachineHealthCheck.
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		condStatus, _ := cond["status"].(string)
+		if condStatus != "False" || (condType != "NodeHealthy" && condType != "HealthCheckSucceeded") {
+			continue
+		}
+		m.Healthy = false
+		m.Reason, _ = cond["reason"].(string)
+		m.Message, _ = cond["message"].(string)
+		if m.Reason == "" {
+			m.Reason = condType + "False"
+		}
+		break
+	}
+	return m
+}
+
+// capiMachineBootstrapping reports whether a Machine is still on its way to
+// becoming a node.
+func capiMachineBootstrapping(m federation.Machine) bool {
+	if !m.Healthy || m.NodeName != "" {
+		return false
+	}
+	switch m.Phase {
+	case capiMachinePhaseRunning, capiMachinePhaseDeleting:
+		return false
+	}
+	return true
+}
+
+func capiMachinePhaseOrPending(phase string) string {
+	if phase == "" {
+		return "Pending"
+	}
+	return phase
+}
+
+// capiApplyMachines attaches a cluster's Machines to its lifecycle block.
+func capiApplyMachines(fc *federation.FederatedCluster, machines []federation.Machine) {
+	if fc.Lifecycle == nil || len(machines) == 0 {
+		return
+	}
+	fc.Lifecycle.Machines = machines
+	for _, m := range machines {
+		if !m.Healthy {
+			fc.Lifecycle.UnhealthyMachines++
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // KubeadmControlPlane index
 // ---------------------------------------------------------------------------
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -247,3 +248,135 @@ func TestCAPIAPIServerURL(t *testing.T) {
 		}
 	}
 }
+
+func capiTestMachine(name, cluster, phase string, status map[string]interface{}) map[string]interface{} {
+	if status == nil {
+		status = map[string]interface{}{}
+	}
+	status["phase"] = phase
+	return map[string]interface{}{
+		"apiVersion": "cluster.x-k8s.io/v1beta1",
+		"kind":       "Machine",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"creationTimestamp": "2026-01-02T03:04:05Z",
+		},
+		"spec":   map[string]interface{}{"clusterName": cluster},
+		"status": status,
+	}
+}
+
+func TestParseCAPIMachine_Health(t *testing.T) {
+	cases := []struct {
+		name        string
+		obj         map[string]interface{}
+		wantHealthy bool
+		wantReason  string
+	}{
+		{
+			name: "running",
+			obj: capiTestMachine("m1", "c1", "Running", map[string]interface{}{
+				"nodeRef": map[string]interface{}{"name": "node-1"},
+			}),
+			wantHealthy: true,
+		},
+		{
+			name: "failed",
+			obj: capiTestMachine("m2", "c1", "Failed", map[string]interface{}{
+				"failureReason":  "CreateError",
+				"failureMessage": "quota exceeded",
+			}),
+			wantReason: "CreateError",
+		},
+		{
+			name: "node gone",
+			obj: capiTestMachine("m3", "c1", "Running", map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+					map[string]interface{}{"type": "NodeHealthy", "status": "False", "reason": "NodeNotFound"},
+				},
+			}),
+			wantReason: "NodeNotFound",
+		},
+		{
+			name: "health check failed",
+			obj: capiTestMachine("m4", "c1", "Running", map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "HealthCheckSucceeded", "status": "False"},
+				},
+			}),
+			wantReason: "HealthCheckSucceededFalse",
+		},
+	}
+	for _, tc := range cases {
+		m := parseCAPIMachine(&unstructured.Unstructured{Object: tc.obj})
+		if m.Healthy != tc.wantHealthy {
+			t.Errorf("%s: healthy = %v, want %v", tc.name, m.Healthy, tc.wantHealthy)
+		}
+		if m.Reason != tc.wantReason {
+			t.Errorf("%s: reason = %q, want %q", tc.name, m.Reason, tc.wantReason)
+		}
+	}
+}
+
+func TestCAPIReadClusters_Machines(t *testing.T) {
+	ts, cfg := fakeAPIServer(t, map[string]interface{}{
+		"/apis/cluster.x-k8s.io/v1beta1/clusters": map[string]interface{}{
+			"kind":       "ClusterList",
+			"apiVersion": "cluster.x-k8s.io/v1beta1",
+			"items": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "cluster.x-k8s.io/v1beta1",
+					"kind":       "Cluster",
+					"metadata":   map[string]interface{}{"name": "c1"},
+					"status":     map[string]interface{}{"phase": "Provisioning"},
+				},
+			},
+		},
+		"/apis/cluster.x-k8s.io/v1beta1/machines": map[string]interface{}{
+			"kind":       "MachineList",
+			"apiVersion": "cluster.x-k8s.io/v1beta1",
+			"items": []interface{}{
+				capiTestMachine("c1-cp-0", "c1", "Running", map[string]interface{}{
+					"nodeRef": map[string]interface{}{"name": "c1-cp-0"},
+				}),
+				capiTestMachine("c1-md-0", "c1", "Provisioning", nil),
+				capiTestMachine("c1-md-1", "c1", "Failed", map[string]interface{}{"failureReason": "CreateError"}),
+			},
+		},
+	})
+	defer ts.Close()
+
+	p := &capiProvider{}
+	clusters, err := p.ReadClusters(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(clusters) != 1 {
+		t.Fatalf("expected 1 cluster, got %d", len(clusters))
+	}
+	lc := clusters[0].Lifecycle
+	if clusters[0].State != federation.ClusterStateProvisioning {
+		t.Errorf("expected provisioning cluster, got %s", clusters[0].State)
+	}
+	if len(lc.Machines) != 3 {
+		t.Fatalf("expected 3 machines, got %d", len(lc.Machines))
+	}
+	if lc.UnhealthyMachines != 1 {
+		t.Errorf("expected 1 unhealthy machine, got %d", lc.UnhealthyMachines)
+	}
+
+	joins, err := p.ReadPendingJoins(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(joins) != 1 {
+		t.Fatalf("expected only the provisioning machine to be pending, got %+v", joins)
+	}
+	if joins[0].ClusterName != "c1" || joins[0].Detail != "Machine c1-md-0 is provisioning" {
+		t.Errorf("unexpected pending join: %+v", joins[0])
+	}
+	if joins[0].RequestedAt.IsZero() {
+		t.Error("expected RequestedAt from the Machine creation time")
+	}
+}
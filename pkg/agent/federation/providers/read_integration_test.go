@@ -461,8 +461,8 @@ func TestCAPIReadPendingJoins_NoPending(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if pending != nil {
-		t.Errorf("CAPI ReadPendingJoins should always return nil, got %v", pending)
+	if len(pending) != 0 {
+		t.Errorf("expected no pending joins without Machines, got %v", pending)
 	}
 }
 
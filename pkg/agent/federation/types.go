@@ -109,6 +109,30 @@ type Lifecycle struct {
 	DesiredMachines int32 `json:"desiredMachines"`
 	// ReadyMachines is the sum of ready Machine counts for this cluster.
 	ReadyMachines int32 `json:"readyMachines"`
+	// UnhealthyMachines counts Machines that failed, lost their node, or
+	// failed a MachineHealthCheck.
+	UnhealthyMachines int32 `json:"unhealthyMachines"`
+	// Machines lists the cluster's Machines, control plane and workers alike.
+	Machines []Machine `json:"machines,omitempty"`
+}
+
+// Machine is one CAPI Machine of a lifecycle-managed cluster.
+type Machine struct {
+	Name string `json:"name"`
+	// Phase is Machine.status.phase ("Pending"/"Provisioning"/"Provisioned"/
+	// "Running"/"Deleting"/"Failed"/"Unknown"), kept raw like Lifecycle.Phase.
+	Phase string `json:"phase"`
+	// NodeName is the workload-cluster Node backing the Machine, once it
+	// has joined.
+	NodeName     string `json:"nodeName,omitempty"`
+	ControlPlane bool   `json:"controlPlane"`
+	// Healthy is false when the Machine failed or a NodeHealthy /
+	// HealthCheckSucceeded condition is False.
+	Healthy bool `json:"healthy"`
+	// Reason explains an unhealthy Machine (failureReason or the failing
+	// condition's reason).
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 // FederatedCluster is one row in the "My Clusters" federation overlay. The
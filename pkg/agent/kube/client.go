@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -49,6 +50,7 @@ type KubectlProxy struct {
 	kubeconfig string
 	config     *api.Config
 	lastReload time.Time // wall time of last successful Reload, for ReloadIfStale (#8075)
+	native     nativeClientCache
 }
 
 func NewKubectlProxy(kubeconfig string) (*KubectlProxy, error) {
@@ -104,33 +106,41 @@ func (k *KubectlProxy) Execute(ctxName, namespace string, args []string) protoco
 // ExecuteWithContext runs a kubectl command, deriving the execution deadline
 // from the supplied parent context. When the parent is cancelled (e.g. the
 // WebSocket connection closes), the kubectl process is killed immediately
-// instead of running until its own timeout expires (#9997).
+// instead of running until its own timeout expires (#9997). Commands the
+// client-go path handles never start a kubectl process.
 func (k *KubectlProxy) ExecuteWithContext(parent context.Context, ctxName, namespace string, args []string) protocol.KubectlResponse {
-	cmdArgs := []string{}
-	if k.kubeconfig != "" {
-		cmdArgs = append(cmdArgs, "--kubeconfig", k.kubeconfig)
-	}
-	if ctxName != "" {
-		cmdArgs = append(cmdArgs, "--context", ctxName)
-	}
-	if namespace != "" {
-		cmdArgs = append(cmdArgs, "-n", namespace)
-	}
-	cmdArgs = append(cmdArgs, args...)
+	return k.execute(parent, ctxName, namespace, args, "")
+}
+
+// execute runs a kubectl command with input, if any, on its stdin.
+func (k *KubectlProxy) execute(parent context.Context, ctxName, namespace string, args []string, input string) protocol.KubectlResponse {
+	cmdArgs := k.commandArgs(ctxName, namespace, args)
 
 	if !k.validateArgs(args) {
 		return protocol.KubectlResponse{ExitCode: 1, Error: "Disallowed kubectl command"}
 	}
+	if !AllowsNamespace(namespace) {
+		return protocol.KubectlResponse{ExitCode: 1, Error: "Disallowed kubectl namespace"}
+	}
 
 	// Bound kubectl execution with a context timeout to prevent goroutine/FD leaks (#7258).
 	// Derive from the parent context so client disconnect also cancels the command (#9997).
 	ctx, cancel := context.WithTimeout(parent, kubectlExecTimeout)
 	defer cancel()
 
+	// The common commands run with client-go; the kubectl binary is the
+	// fallback for everything else (see client_native.go).
+	if resp, ok := k.executeNative(ctx, ctxName, namespace, args); ok {
+		return resp
+	}
+
 	cmd := execCommandContext(ctx, "kubectl", cmdArgs...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
 
 	err := cmd.Run()
 	exitCode := 0
@@ -145,11 +155,23 @@ func (k *KubectlProxy) ExecuteWithContext(parent context.Context, ctxName, names
 		}
 	}
 
-	output := stdout.String()
-	if stderr.String() != "" && output == "" {
-		output = stderr.String()
+	return kubectlResponse(stdout.String(), stderr.String(), exitCode)
+}
+
+// commandArgs prefixes args with the kubeconfig, context and namespace
+// flags.
+func (k *KubectlProxy) commandArgs(ctxName, namespace string, args []string) []string {
+	cmdArgs := []string{}
+	if k.kubeconfig != "" {
+		cmdArgs = append(cmdArgs, "--kubeconfig", k.kubeconfig)
+	}
+	if ctxName != "" {
+		cmdArgs = append(cmdArgs, "--context", ctxName)
+	}
+	if namespace != "" {
+		cmdArgs = append(cmdArgs, "-n", namespace)
 	}
-	return protocol.KubectlResponse{Output: output, ExitCode: exitCode, Error: stderr.String()}
+	return append(cmdArgs, args...)
 }
 
 // AllowedKubectlCommands is a whitelist of safe kubectl commands
@@ -249,10 +271,12 @@ func ValidateKubectlArgs(args []string) bool {
 	}
 
 	command := strings.ToLower(args[0])
+	policy := currentKubectlPolicy()
 
-	// Check if command is in allowlist
-	allowed, exists := AllowedKubectlCommands[command]
-	if !exists || !allowed {
+	// Check if command is in allowlist, as extended or restricted by the
+	// policy file (see policy.go). A server-side dry-run apply is allowed
+	// unless the policy denies apply (see client_dryrun.go)
+	if !policy.allowsVerb(command) && (!IsServerDryRunApply(args) || policy.deniesVerb(command)) {
 		return false
 	}
 
@@ -300,7 +324,7 @@ func ValidateKubectlArgs(args []string) bool {
 			return false // Need at least "delete <resource>"
 		}
 		resourceType := strings.ToLower(args[1])
-		if !allowedDeleteResources[resourceType] {
+		if !allowedDeleteResources[resourceType] && !policy.allowsResource(command, resourceType) {
 			return false
 		}
 	}
@@ -324,17 +348,31 @@ func ValidateKubectlArgs(args []string) bool {
 		// Handle "scale deployment/myapp" format
 		if strings.Contains(firstPositional, "/") {
 			parts := strings.SplitN(firstPositional, "/", 2)
-			if !allowedScaleResources[parts[0]] {
+			if !allowedScaleResources[parts[0]] && !policy.allowsResource(command, parts[0]) {
 				return false
 			}
 		} else {
 			// Handle "scale deployment myapp" format
-			if !allowedScaleResources[firstPositional] {
+			if !allowedScaleResources[firstPositional] && !policy.allowsResource(command, firstPositional) {
 				return false
 			}
 		}
 	}
 
+	// Verbs added by the policy file are limited to the resource kinds it
+	// lists for them, if any
+	if policy.addsVerb(command) && len(policy.AllowResources[command]) > 0 {
+		kinds, _, _ := strings.Cut(firstResourceArg(args[1:]), "/")
+		for _, kind := range strings.Split(kinds, ",") {
+			if !policy.allowsResource(command, kind) {
+				return false
+			}
+		}
+	}
+	if policy.deniesResource(firstResourceArg(args[1:])) || !policy.allowsFlags(args[1:]) {
+		return false
+	}
+
 	// Block any args that might execute arbitrary commands
 	for _, arg := range args {
 		argLower := strings.ToLower(arg)
@@ -450,6 +488,55 @@ func (k *KubectlProxy) RenameContext(oldName, newName string) error {
 	return nil
 }
 
+// RemoveContext deletes a kubeconfig context, along with its cluster and
+// user entries when no other context uses them. The current context cannot
+// be removed. The file is backed up before it is rewritten.
+func (k *KubectlProxy) RemoveContext(name string) (protocol.RemoveContextResponse, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.config == nil {
+		return protocol.RemoveContextResponse{}, fmt.Errorf("no kubeconfig loaded")
+	}
+	ctx, ok := k.config.Contexts[name]
+	if !ok {
+		return protocol.RemoveContextResponse{}, fmt.Errorf("context %q not found", name)
+	}
+	if k.config.CurrentContext == name {
+		return protocol.RemoveContextResponse{}, fmt.Errorf("cannot remove the current context %q", name)
+	}
+
+	clusterUsed, userUsed := false, false
+	for other, c := range k.config.Contexts {
+		if other == name || c == nil {
+			continue
+		}
+		clusterUsed = clusterUsed || c.Cluster == ctx.Cluster
+		userUsed = userUsed || c.AuthInfo == ctx.AuthInfo
+	}
+
+	if err := k.backupKubeconfigLocked(); err != nil {
+		return protocol.RemoveContextResponse{}, err
+	}
+	// Edit a copy so a failed write leaves the loaded config as the file is.
+	updated := k.config.DeepCopy()
+	result := protocol.RemoveContextResponse{Success: true, Context: name}
+	delete(updated.Contexts, name)
+	if _, ok := updated.Clusters[ctx.Cluster]; ok && !clusterUsed {
+		delete(updated.Clusters, ctx.Cluster)
+		result.Cluster = ctx.Cluster
+	}
+	if _, ok := updated.AuthInfos[ctx.AuthInfo]; ok && !userUsed {
+		delete(updated.AuthInfos, ctx.AuthInfo)
+		result.User = ctx.AuthInfo
+	}
+	if err := clientcmd.WriteToFile(*updated, k.kubeconfig); err != nil {
+		return protocol.RemoveContextResponse{}, fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	k.reloadLocked()
+	return result, nil
+}
+
 // KubeconfigPreviewEntry describes a context found in an imported kubeconfig.
 type KubeconfigPreviewEntry struct {
 	ContextName string `json:"contextName"`
@@ -507,28 +594,15 @@ func (k *KubectlProxy) ImportKubeconfig(yamlContent string) (added []string, ski
 		return nil, nil, fmt.Errorf("kubeconfig contains no contexts")
 	}
 
-	// SECURITY: Reject any AuthInfo with an exec plugin — uploading a
-	// kubeconfig with exec.command = "/bin/sh" achieves RCE (#7260).
-	for name, ai := range incoming.AuthInfos {
-		if ai != nil && ai.Exec != nil {
-			return nil, nil, fmt.Errorf("SECURITY: kubeconfig user %q uses exec-based auth (command: %s) — exec plugins are not allowed for imported configs", name, ai.Exec.Command)
-		}
+	if err := rejectExecAuthInfos(incoming); err != nil {
+		return nil, nil, err
 	}
 
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
-	// Backup existing kubeconfig if the file exists.
-	// Uses UnixNano to avoid collisions from concurrent imports (#7276).
-	if _, statErr := os.Stat(k.kubeconfig); statErr == nil {
-		backupPath := fmt.Sprintf("%s.bak-%d", k.kubeconfig, time.Now().UnixNano())
-		data, readErr := os.ReadFile(k.kubeconfig)
-		if readErr != nil {
-			return nil, nil, fmt.Errorf("failed to read kubeconfig for backup: %w", readErr)
-		}
-		if writeErr := os.WriteFile(backupPath, data, 0600); writeErr != nil {
-			return nil, nil, fmt.Errorf("failed to write backup: %w", writeErr)
-		}
+	if err := k.backupKubeconfigLocked(); err != nil {
+		return nil, nil, err
 	}
 
 	// Initialise maps if they are nil (empty starting config)
@@ -603,6 +677,104 @@ func (k *KubectlProxy) ImportKubeconfig(yamlContent string) (added []string, ski
 	return added, skipped, nil
 }
 
+// RefreshImportedKubeconfig re-applies a kubeconfig that was imported
+// earlier, e.g. after the Secret it came from rotated its credentials. For
+// every incoming context already in the local kubeconfig, the cluster and
+// user entries that context points at are replaced when they differ.
+// Contexts the user has since removed are not re-added. The file is only
+// rewritten (after a backup) when something changed. Returns the contexts
+// whose entries were updated.
+//
+// SECURITY: AuthInfo entries with Exec plugins are rejected, as on import.
+func (k *KubectlProxy) RefreshImportedKubeconfig(yamlContent string) ([]string, error) {
+	incoming, err := clientcmd.Load([]byte(yamlContent))
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig YAML: %w", err)
+	}
+	if err := rejectExecAuthInfos(incoming); err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	clusters := make(map[string]*api.Cluster)
+	users := make(map[string]*api.AuthInfo)
+	var updated []string
+	for name, ctx := range incoming.Contexts {
+		existing, ok := k.config.Contexts[name]
+		if !ok {
+			continue
+		}
+		changed := false
+		if cluster, ok := incoming.Clusters[ctx.Cluster]; ok && !clustersEquivalent(k.config.Clusters[existing.Cluster], cluster) {
+			clusters[existing.Cluster] = cluster
+			changed = true
+		}
+		if user, ok := incoming.AuthInfos[ctx.AuthInfo]; ok && !authInfosEquivalent(k.config.AuthInfos[existing.AuthInfo], user) {
+			users[existing.AuthInfo] = user
+			changed = true
+		}
+		if changed {
+			updated = append(updated, name)
+		}
+	}
+	if len(updated) == 0 {
+		return nil, nil
+	}
+
+	if err := k.backupKubeconfigLocked(); err != nil {
+		return nil, err
+	}
+	if k.config.Clusters == nil {
+		k.config.Clusters = make(map[string]*api.Cluster)
+	}
+	if k.config.AuthInfos == nil {
+		k.config.AuthInfos = make(map[string]*api.AuthInfo)
+	}
+	for name, cluster := range clusters {
+		k.config.Clusters[name] = cluster
+	}
+	for name, user := range users {
+		k.config.AuthInfos[name] = user
+	}
+	if err := clientcmd.WriteToFile(*k.config, k.kubeconfig); err != nil {
+		return nil, fmt.Errorf("failed to write refreshed kubeconfig: %w", err)
+	}
+	k.reloadLocked()
+	sort.Strings(updated)
+	return updated, nil
+}
+
+// rejectExecAuthInfos refuses kubeconfigs whose users run exec plugins:
+// uploading a kubeconfig with exec.command = "/bin/sh" achieves RCE (#7260).
+func rejectExecAuthInfos(config *api.Config) error {
+	for name, ai := range config.AuthInfos {
+		if ai != nil && ai.Exec != nil {
+			return fmt.Errorf("SECURITY: kubeconfig user %q uses exec-based auth (command: %s) — exec plugins are not allowed for imported configs", name, ai.Exec.Command)
+		}
+	}
+	return nil
+}
+
+// backupKubeconfigLocked copies the kubeconfig file, if it exists, next to
+// itself before a merge rewrites it. Uses UnixNano to avoid collisions from
+// concurrent imports (#7276). k.mu must be held.
+func (k *KubectlProxy) backupKubeconfigLocked() error {
+	if _, statErr := os.Stat(k.kubeconfig); statErr != nil {
+		return nil
+	}
+	backupPath := fmt.Sprintf("%s.bak-%d", k.kubeconfig, time.Now().UnixNano())
+	data, readErr := os.ReadFile(k.kubeconfig)
+	if readErr != nil {
+		return fmt.Errorf("failed to read kubeconfig for backup: %w", readErr)
+	}
+	if writeErr := os.WriteFile(backupPath, data, 0600); writeErr != nil {
+		return fmt.Errorf("failed to write backup: %w", writeErr)
+	}
+	return nil
+}
+
 // clustersEquivalent returns true if two Cluster structs carry the same
 // semantic configuration.  The LocationOfOrigin field is ignored because it
 // reflects which file a value was loaded from, not the cluster definition.
@@ -0,0 +1,146 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+)
+
+// dryRunManifestReadBuffer is the decoder's lookahead for YAML-vs-JSON
+// sniffing.
+const dryRunManifestReadBuffer = 4096
+
+// dryRunApplyFlags are the flags a server-side dry-run apply may pass besides
+// --dry-run=server and -f -, with whether each needs a value. Flags that
+// read local files, such as --template, are left out so a manifest is the
+// only input.
+var dryRunApplyFlags = map[string]bool{
+	"-n":                    true,
+	"--namespace":           true,
+	"-o":                    true,
+	"--output":              true,
+	"--field-manager":       true,
+	"--validate":            false,
+	"--server-side":         false,
+	"--force-conflicts":     false,
+	"--show-managed-fields": false,
+}
+
+// dryRunApplyOutputs are the output formats a server-side dry-run apply may
+// use.
+var dryRunApplyOutputs = map[string]bool{
+	"json": true,
+	"yaml": true,
+	"name": true,
+}
+
+// IsServerDryRunApply reports whether args are `apply --dry-run=server -f -`,
+// optionally with the flags in dryRunApplyFlags. The API server validates
+// such an apply, runs admission and returns the result without persisting
+// anything, so it is allowed even though apply is not.
+func IsServerDryRunApply(args []string) bool {
+	if len(args) == 0 || strings.ToLower(args[0]) != "apply" {
+		return false
+	}
+	dryRun, stdin := false, false
+	for i := 1; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--dry-run":
+			if value != "server" {
+				return false
+			}
+			dryRun = true
+			continue
+		case "-f", "--filename":
+			if !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			if value != "-" {
+				return false
+			}
+			stdin = true
+			continue
+		}
+		needsValue, ok := dryRunApplyFlags[name]
+		if !ok {
+			return false
+		}
+		if needsValue && !hasValue {
+			if i+1 >= len(args) {
+				return false
+			}
+			i++
+			value = args[i]
+		}
+		if (name == "-o" || name == "--output") && !dryRunApplyOutputs[value] {
+			return false
+		}
+	}
+	return dryRun && stdin
+}
+
+// ExecuteWithInput runs a server-side dry-run apply of manifest, passed to
+// kubectl on stdin, and returns the server's validation errors or the
+// objects as they would be applied. It refuses any other command, and
+// manifests with an object of a kind or namespace the policy denies.
+func (k *KubectlProxy) ExecuteWithInput(parent context.Context, ctxName, namespace string, args []string, manifest string) protocol.KubectlResponse {
+	if !IsServerDryRunApply(args) {
+		return protocol.KubectlResponse{ExitCode: 1, Error: "A manifest can only be passed to apply --dry-run=server -f -"}
+	}
+	if strings.TrimSpace(manifest) == "" {
+		return protocol.KubectlResponse{ExitCode: 1, Error: "Empty manifest"}
+	}
+	if err := checkManifestPolicy(manifest); err != nil {
+		return protocol.KubectlResponse{ExitCode: 1, Error: "Disallowed manifest: " + err.Error()}
+	}
+	return k.execute(parent, ctxName, namespace, args, manifest)
+}
+
+// checkManifestPolicy reports the first object in manifest whose kind or
+// namespace the policy in use denies, or why manifest cannot be read. A List
+// is checked item by item.
+func checkManifestPolicy(manifest string) error {
+	p := currentKubectlPolicy()
+	if p == nil {
+		return nil
+	}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), dryRunManifestReadBuffer)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("cannot read manifest: %w", err)
+		}
+		if doc == nil {
+			continue
+		}
+		obj := unstructured.Unstructured{Object: doc}
+		objects := []unstructured.Unstructured{obj}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return fmt.Errorf("cannot read manifest: %w", err)
+			}
+			objects = list.Items
+		}
+		for _, o := range objects {
+			if kind := strings.ToLower(o.GetKind()); p.deniesResource(kind) {
+				return fmt.Errorf("kind %s is not allowed", o.GetKind())
+			}
+			if !p.allowsNamespace(o.GetNamespace()) {
+				return fmt.Errorf("namespace %s is not allowed", o.GetNamespace())
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package kube
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const testDryRunManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  replicas: 2
+`
+
+func TestIsServerDryRunApply(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"apply", "--dry-run=server", "-f", "-"}, true},
+		{[]string{"apply", "-f", "-", "--dry-run=server", "-o", "yaml"}, true},
+		{[]string{"apply", "--filename=-", "--dry-run=server", "-n", "team-a", "--server-side", "--field-manager=console"}, true},
+		{[]string{"apply", "-f", "-"}, false},
+		{[]string{"apply", "--dry-run=client", "-f", "-"}, false},
+		{[]string{"apply", "--dry-run", "-f", "-"}, false},
+		{[]string{"apply", "--dry-run=server"}, false},
+		{[]string{"apply", "--dry-run=server", "-f", "web.yaml"}, false},
+		{[]string{"apply", "--dry-run=server", "-f", "-", "-f", "/etc/passwd"}, false},
+		{[]string{"apply", "--dry-run=server", "-f", "-", "-o", "go-template-file=/tmp/t"}, false},
+		{[]string{"apply", "--dry-run=server", "-f", "-", "--prune"}, false},
+		{[]string{"apply", "--dry-run=server", "-f", "-", "web"}, false},
+		{[]string{"apply", "--dry-run=server", "-f", "-", "-n"}, false},
+		{[]string{"create", "--dry-run=server", "-f", "-"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsServerDryRunApply(tt.args), "%v", tt.args)
+	}
+}
+
+func TestValidateKubectlArgs_ServerDryRunApply(t *testing.T) {
+	assert.True(t, ValidateKubectlArgs([]string{"apply", "--dry-run=server", "-f", "-"}))
+	assert.False(t, ValidateKubectlArgs([]string{"apply", "-f", "-"}))
+
+	useKubectlPolicy(t, testKubectlPolicy)
+	assert.True(t, ValidateKubectlArgs([]string{"apply", "--dry-run=server", "-f", "-", "-n", "team-a"}))
+	assert.False(t, ValidateKubectlArgs([]string{"apply", "--dry-run=server", "-f", "-", "-n", "default"}))
+
+	useKubectlPolicy(t, "deny_verbs: [apply]\n")
+	assert.False(t, ValidateKubectlArgs([]string{"apply", "--dry-run=server", "-f", "-"}), "apply is denied outright")
+}
+
+func TestCheckManifestPolicy(t *testing.T) {
+	assert.NoError(t, checkManifestPolicy("kind: Secret\n"), "no policy")
+
+	useKubectlPolicy(t, testKubectlPolicy)
+	assert.NoError(t, checkManifestPolicy(testDryRunManifest))
+	assert.NoError(t, checkManifestPolicy(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cfg"}}`))
+	assert.ErrorContains(t, checkManifestPolicy(testDryRunManifest+"---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: token\n"), "kind Secret")
+	assert.ErrorContains(t, checkManifestPolicy("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n  namespace: default\n"), "namespace default")
+	assert.ErrorContains(t, checkManifestPolicy(`{"apiVersion":"v1","kind":"List","items":[{"apiVersion":"v1","kind":"Secret","metadata":{"name":"token"}}]}`), "kind Secret")
+	assert.ErrorContains(t, checkManifestPolicy("kind: [\n"), "cannot read manifest")
+}
+
+func TestKubectlProxy_ExecuteWithInput(t *testing.T) {
+	defer func() { execCommandContext = exec.CommandContext; mockEchoStdin = false }()
+	execCommandContext = fakeExecCommandContext
+	mockStdout, mockStderr, mockExitCode, mockEchoStdin = "", "", 0, true
+
+	proxy := &KubectlProxy{config: &api.Config{}}
+	args := []string{"apply", "--dry-run=server", "-f", "-", "-o", "yaml"}
+
+	resp := proxy.ExecuteWithInput(context.Background(), "default", "team-a", args, testDryRunManifest)
+	assert.Equal(t, 0, resp.ExitCode, resp.Error)
+	assert.Equal(t, testDryRunManifest, resp.Output, "the manifest reaches kubectl on stdin")
+
+	resp = proxy.ExecuteWithInput(context.Background(), "default", "team-a", []string{"apply", "-f", "-"}, testDryRunManifest)
+	assert.Equal(t, 1, resp.ExitCode)
+	resp = proxy.ExecuteWithInput(context.Background(), "default", "team-a", args, " \n")
+	assert.Equal(t, "Empty manifest", resp.Error)
+
+	useKubectlPolicy(t, testKubectlPolicy)
+	resp = proxy.ExecuteWithInput(context.Background(), "default", "team-a", args, "apiVersion: v1\nkind: Secret\nmetadata:\n  name: token\n")
+	assert.Equal(t, 1, resp.ExitCode)
+	assert.Contains(t, resp.Error, "Disallowed manifest")
+}
@@ -0,0 +1,428 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// NativeKubectlEnvVar turns off the client-go path when set to false, so
+// every command runs the kubectl binary.
+const NativeKubectlEnvVar = "KC_KUBECTL_NATIVE"
+
+// errNotNative is returned by the native verbs for commands they do not
+// handle, such as an unknown flag or output format. Those run the kubectl
+// binary instead.
+var errNotNative = errors.New("not handled natively")
+
+// nativeVerbs run the common kubectl commands with client-go, so kc-agent
+// works without a kubectl binary, or with one of another version.
+var nativeVerbs = map[string]func(context.Context, *nativeClients, *nativeCommand) (string, string, error){
+	"get":      nativeGet,
+	"describe": nativeDescribe,
+	"logs":     nativeLogs,
+	"scale":    nativeScale,
+	"delete":   nativeDelete,
+	"rollout":  nativeRolloutStatus,
+}
+
+// nativeFlagNames maps the flag spellings the native verbs accept to their
+// long names.
+var nativeFlagNames = map[string]string{
+	"-n":               "namespace",
+	"--namespace":      "namespace",
+	"-o":               "output",
+	"--output":         "output",
+	"-l":               "selector",
+	"--selector":       "selector",
+	"--field-selector": "field-selector",
+	"-A":               "all-namespaces",
+	"--all-namespaces": "all-namespaces",
+	"--chunk-size":     "chunk-size",
+	"-c":               "container",
+	"--container":      "container",
+	"-p":               "previous",
+	"--previous":       "previous",
+	"--tail":           "tail",
+	"--since":          "since",
+	"--timestamps":     "timestamps",
+	"--replicas":       "replicas",
+	"--grace-period":   "grace-period",
+	"--wait":           "wait",
+	"-w":               "watch",
+	"--watch":          "watch",
+	"--timeout":        "timeout",
+}
+
+// nativeBoolFlags are the flags that take no separate value.
+var nativeBoolFlags = map[string]bool{
+	"all-namespaces": true,
+	"previous":       true,
+	"timestamps":     true,
+	"wait":           true,
+	"watch":          true,
+}
+
+// nativeVerbFlags are the flags each native verb understands. Any other
+// flag runs the kubectl binary.
+var nativeVerbFlags = map[string][]string{
+	"get":      {"namespace", "output", "selector", "field-selector", "all-namespaces", "chunk-size"},
+	"describe": {"namespace"},
+	"logs":     {"namespace", "container", "previous", "tail", "since", "timestamps"},
+	"scale":    {"namespace", "replicas"},
+	"delete":   {"namespace", "grace-period", "wait"},
+	"rollout":  {"namespace", "watch", "timeout"},
+}
+
+// nativeCommand is a parsed kubectl command line.
+type nativeCommand struct {
+	verb  string
+	args  []string
+	flags map[string]string
+	// namespace is the one the command acts in: -n, else the request's,
+	// else the context's.
+	namespace string
+}
+
+// flag returns the value of a flag and whether it was passed.
+func (c *nativeCommand) flag(name string) (string, bool) {
+	v, ok := c.flags[name]
+	return v, ok
+}
+
+// boolFlag reports whether a boolean flag is on, def when it was not
+// passed.
+func (c *nativeCommand) boolFlag(name string, def bool) (bool, error) {
+	v, ok := c.flags[name]
+	if !ok {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for --%s", v, name)
+	}
+	return b, nil
+}
+
+// parseNativeCommand splits args into positionals and the flags the verb
+// understands. It returns errNotNative for anything else.
+func parseNativeCommand(args []string) (*nativeCommand, error) {
+	if len(args) == 0 {
+		return nil, errNotNative
+	}
+	cmd := &nativeCommand{verb: strings.ToLower(args[0]), flags: make(map[string]string)}
+	allowed := make(map[string]bool)
+	for _, f := range nativeVerbFlags[cmd.verb] {
+		allowed[f] = true
+	}
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		if arg == "--" {
+			return nil, errNotNative
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			cmd.args = append(cmd.args, arg)
+			continue
+		}
+		spelling, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue && !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			// Short flags with the value attached, such as -ojson.
+			spelling, value, hasValue = arg[:2], arg[2:], true
+		}
+		name, ok := nativeFlagNames[spelling]
+		if !ok || !allowed[name] {
+			return nil, errNotNative
+		}
+		switch {
+		case hasValue:
+		case nativeBoolFlags[name]:
+			value = "true"
+		case i+1 < len(rest):
+			i++
+			value = rest[i]
+		default:
+			return nil, errNotNative
+		}
+		cmd.flags[name] = value
+	}
+	return cmd, nil
+}
+
+// nativeClients are the clients the native verbs use for one context.
+type nativeClients struct {
+	clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	mapper    meta.ResettableRESTMapper
+	// namespace is the context's namespace, used when a command names none.
+	namespace string
+	// tables reads resources as server-side tables, for get's default and
+	// wide output.
+	tables tableReader
+}
+
+// nativeClientCache keeps each context's clients for as long as the
+// kubeconfig they were built from is current.
+type nativeClientCache struct {
+	mu      sync.Mutex
+	entries map[string]nativeClientEntry
+}
+
+type nativeClientEntry struct {
+	config  *api.Config
+	clients *nativeClients
+}
+
+// nativeClientsFor returns the clients of kubeconfig context ctxName, the
+// current context when empty. Clients are rebuilt after a reload replaces
+// the kubeconfig.
+func (k *KubectlProxy) nativeClientsFor(ctxName string) (*nativeClients, error) {
+	k.native.mu.Lock()
+	defer k.native.mu.Unlock()
+
+	k.mu.RLock()
+	config := k.config
+	if config == nil {
+		k.mu.RUnlock()
+		return nil, errors.New("no kubeconfig loaded")
+	}
+	if ctxName == "" {
+		ctxName = config.CurrentContext
+	}
+	if e, ok := k.native.entries[ctxName]; ok && e.config == config {
+		k.mu.RUnlock()
+		return e.clients, nil
+	}
+	if _, ok := config.Contexts[ctxName]; !ok {
+		k.mu.RUnlock()
+		return nil, fmt.Errorf("context %q not found in kubeconfig", ctxName)
+	}
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(*config, ctxName, &clientcmd.ConfigOverrides{}, nil)
+	restConfig, err := clientConfig.ClientConfig()
+	namespace := ""
+	if err == nil {
+		namespace, _, err = clientConfig.Namespace()
+	}
+	k.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	cached := memory.NewMemCacheClient(clientset.Discovery())
+	deferred := restmapper.NewDeferredDiscoveryRESTMapper(cached)
+	clients := &nativeClients{
+		clientset: clientset,
+		dynamic:   dyn,
+		mapper:    resettableMapper{RESTMapper: restmapper.NewShortcutExpander(deferred, cached, nil), reset: deferred.Reset},
+		namespace: namespace,
+		tables:    restTableReader{client: clientset.Discovery().RESTClient()},
+	}
+	if k.native.entries == nil {
+		k.native.entries = make(map[string]nativeClientEntry)
+	}
+	k.native.entries[ctxName] = nativeClientEntry{config: config, clients: clients}
+	return clients, nil
+}
+
+// resettableMapper is a RESTMapper whose discovery cache can be dropped,
+// to find resource types added since it was filled.
+type resettableMapper struct {
+	meta.RESTMapper
+	reset func()
+}
+
+func (m resettableMapper) Reset() { m.reset() }
+
+// nativeKubectlEnabled reports whether NativeKubectlEnvVar leaves the
+// client-go path on.
+func nativeKubectlEnabled() bool {
+	v := os.Getenv(NativeKubectlEnvVar)
+	if v == "" {
+		return true
+	}
+	on, err := strconv.ParseBool(v)
+	return err != nil || on
+}
+
+// executeNative runs an already validated command with client-go. ok is
+// false when the command is not one it handles, or the context's clients
+// cannot be built, and the kubectl binary should run it instead.
+func (k *KubectlProxy) executeNative(ctx context.Context, ctxName, namespace string, args []string) (protocol.KubectlResponse, bool) {
+	if !nativeKubectlEnabled() {
+		return protocol.KubectlResponse{}, false
+	}
+	cmd, err := parseNativeCommand(args)
+	if err != nil {
+		return protocol.KubectlResponse{}, false
+	}
+	run, ok := nativeVerbs[cmd.verb]
+	if !ok {
+		return protocol.KubectlResponse{}, false
+	}
+	clients, err := k.nativeClientsFor(ctxName)
+	if err != nil {
+		slog.Debug("[kubectl] running kubectl binary, no client-go clients", "context", ctxName, "error", err)
+		return protocol.KubectlResponse{}, false
+	}
+	cmd.namespace = namespace
+	if ns, ok := cmd.flag("namespace"); ok {
+		cmd.namespace = ns
+	}
+	if cmd.namespace == "" {
+		cmd.namespace = clients.namespace
+	}
+
+	stdout, stderr, err := run(ctx, clients, cmd)
+	if errors.Is(err, errNotNative) {
+		return protocol.KubectlResponse{}, false
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return protocol.KubectlResponse{ExitCode: 1, Error: fmt.Sprintf("kubectl timed out after %s", kubectlExecTimeout)}, true
+		}
+		return kubectlResponse(stdout, stderr+nativeErrorText(err), 1), true
+	}
+	return kubectlResponse(stdout, stderr, 0), true
+}
+
+// kubectlResponse builds the response of a command with the given output
+// and exit code. When it wrote nothing to stdout, its stderr is the output.
+func kubectlResponse(stdout, stderr string, exitCode int) protocol.KubectlResponse {
+	output := stdout
+	if stderr != "" && output == "" {
+		output = stderr
+	}
+	return protocol.KubectlResponse{Output: output, ExitCode: exitCode, Error: stderr}
+}
+
+// nativeErrorText formats err the way kubectl prints errors.
+func nativeErrorText(err error) string {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		s := status.Status()
+		if s.Reason == "" || s.Reason == metav1.StatusReasonUnknown {
+			return fmt.Sprintf("Error from server: %s\n", s.Message)
+		}
+		return fmt.Sprintf("Error from server (%s): %s\n", s.Reason, s.Message)
+	}
+	return fmt.Sprintf("error: %s\n", err)
+}
+
+// resolveResource maps a kubectl resource argument, such as po, pods,
+// deployments.apps or deployments.v1.apps, to its REST mapping. It drops
+// the mapper's discovery cache and retries once, for types added since it
+// was filled.
+func resolveResource(clients *nativeClients, arg string) (*meta.RESTMapping, error) {
+	mapping, err := lookupResource(clients.mapper, arg)
+	if meta.IsNoMatchError(err) {
+		clients.mapper.Reset()
+		mapping, err = lookupResource(clients.mapper, arg)
+	}
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("the server doesn't have a resource type %q", arg)
+	}
+	return mapping, err
+}
+
+func lookupResource(mapper meta.RESTMapper, arg string) (*meta.RESTMapping, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(strings.ToLower(arg))
+	var gvk schema.GroupVersionKind
+	var err error
+	if fullySpecified != nil {
+		gvk, err = mapper.KindFor(*fullySpecified)
+	}
+	if fullySpecified == nil || err != nil {
+		gvk, err = mapper.KindFor(groupResource.WithVersion(""))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// resourceTarget is a resource type and the names a command acts on.
+type resourceTarget struct {
+	mapping *meta.RESTMapping
+	names   []string
+}
+
+// parseTargets reads the "kind name..." or "kind/name..." arguments of a
+// command. Only one resource type is handled natively.
+func parseTargets(clients *nativeClients, args []string) (*resourceTarget, error) {
+	if len(args) == 0 {
+		return nil, errNotNative
+	}
+	var kind string
+	var names []string
+	if strings.Contains(args[0], "/") {
+		for _, a := range args {
+			k, name, ok := strings.Cut(a, "/")
+			if !ok || name == "" || (kind != "" && k != kind) {
+				return nil, errNotNative
+			}
+			kind = k
+			names = append(names, name)
+		}
+	} else {
+		kind, names = args[0], args[1:]
+	}
+	if kind == "" || strings.Contains(kind, ",") {
+		return nil, errNotNative
+	}
+	for _, name := range names {
+		if strings.Contains(name, "/") {
+			return nil, errNotNative
+		}
+	}
+	mapping, err := resolveResource(clients, kind)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceTarget{mapping: mapping, names: names}, nil
+}
+
+// resourceClient returns the dynamic client of mapping's resource in namespace,
+// or across all namespaces when namespace is empty. Cluster-scoped
+// resources ignore namespace.
+func (c *nativeClients) resourceClient(mapping *meta.RESTMapping, namespace string) dynamic.ResourceInterface {
+	r := c.dynamic.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace != "" {
+		return r.Namespace(namespace)
+	}
+	return r
+}
+
+// qualifiedKind is how kubectl names a resource type in messages, such as
+// pod or deployment.apps.
+func qualifiedKind(mapping *meta.RESTMapping) string {
+	kind := strings.ToLower(mapping.GroupVersionKind.Kind)
+	if g := mapping.GroupVersionKind.Group; g != "" {
+		return kind + "." + g
+	}
+	return kind
+}
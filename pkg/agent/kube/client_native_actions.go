@@ -0,0 +1,388 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// defaultContainerAnnotation names the container kubectl logs reads
+	// from a pod with several when none is given.
+	defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+	// rolloutStatusPollInterval is how often rollout status re-reads a
+	// workload while waiting for its rollout to finish.
+	rolloutStatusPollInterval = time.Second
+)
+
+var (
+	deploymentsResource  = schema.GroupResource{Group: "apps", Resource: "deployments"}
+	daemonSetsResource   = schema.GroupResource{Group: "apps", Resource: "daemonsets"}
+	statefulSetsResource = schema.GroupResource{Group: "apps", Resource: "statefulsets"}
+)
+
+// nativeLogs runs kubectl logs for one pod. Logs of other kinds of
+// workload, by selector or followed run the kubectl binary.
+func nativeLogs(ctx context.Context, c *nativeClients, cmd *nativeCommand) (string, string, error) {
+	if len(cmd.args) != 1 {
+		return "", "", errNotNative
+	}
+	name := cmd.args[0]
+	if kind, pod, ok := strings.Cut(name, "/"); ok {
+		if kind != "pod" && kind != "pods" && kind != "po" {
+			return "", "", errNotNative
+		}
+		name = pod
+	}
+
+	opts := &corev1.PodLogOptions{}
+	opts.Container, _ = cmd.flag("container")
+	var err error
+	if opts.Previous, err = cmd.boolFlag("previous", false); err != nil {
+		return "", "", err
+	}
+	if opts.Timestamps, err = cmd.boolFlag("timestamps", false); err != nil {
+		return "", "", err
+	}
+	if v, ok := cmd.flag("tail"); ok {
+		tail, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid value %q for --tail", v)
+		}
+		if tail >= 0 {
+			opts.TailLines = &tail
+		}
+	}
+	if v, ok := cmd.flag("since"); ok {
+		since, err := time.ParseDuration(v)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid value %q for --since", v)
+		}
+		seconds := int64(since.Round(time.Second).Seconds())
+		opts.SinceSeconds = &seconds
+	}
+
+	pods := c.clientset.CoreV1().Pods(cmd.namespace)
+	if opts.Container == "" {
+		pod, err := pods.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", err
+		}
+		opts.Container = defaultContainer(pod)
+	}
+	stream, err := pods.GetLogs(name, opts).Stream(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer stream.Close()
+	var out bytes.Buffer
+	if _, err := io.Copy(&out, stream); err != nil {
+		return out.String(), "", err
+	}
+	return out.String(), "", nil
+}
+
+// defaultContainer is the container kubectl logs reads from pod: the one
+// its default-container annotation names, else its first.
+func defaultContainer(pod *corev1.Pod) string {
+	if name := pod.Annotations[defaultContainerAnnotation]; name != "" {
+		for _, c := range pod.Spec.Containers {
+			if c.Name == name {
+				return name
+			}
+		}
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// nativeScale runs kubectl scale --replicas through the scale subresource
+// of the named objects.
+func nativeScale(ctx context.Context, c *nativeClients, cmd *nativeCommand) (string, string, error) {
+	target, err := parseTargets(c, cmd.args)
+	if err != nil {
+		return "", "", err
+	}
+	if len(target.names) == 0 {
+		return "", "", errors.New("resource(s) were provided, but no name was specified")
+	}
+	v, ok := cmd.flag("replicas")
+	if !ok {
+		return "", "", errors.New("required flag(s) \"replicas\" not set")
+	}
+	replicas, err := strconv.Atoi(v)
+	if err != nil || replicas < 0 {
+		return "", "", errors.New("the --replicas=COUNT flag is required, and COUNT must be greater than or equal to 0")
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	rc := c.resourceClient(target.mapping, cmd.namespace)
+	var out strings.Builder
+	for _, name := range target.names {
+		if _, err := rc.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "scale"); err != nil {
+			return out.String(), "", err
+		}
+		fmt.Fprintf(&out, "%s/%s scaled\n", qualifiedKind(target.mapping), name)
+	}
+	return out.String(), "", nil
+}
+
+// nativeDelete runs kubectl delete for named objects. It returns once the
+// deletions are accepted, without waiting for the objects to go.
+func nativeDelete(ctx context.Context, c *nativeClients, cmd *nativeCommand) (string, string, error) {
+	target, err := parseTargets(c, cmd.args)
+	if err != nil {
+		return "", "", err
+	}
+	if len(target.names) == 0 {
+		return "", "", errNotNative
+	}
+	opts := metav1.DeleteOptions{}
+	if v, ok := cmd.flag("grace-period"); ok {
+		grace, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid value %q for --grace-period", v)
+		}
+		// kubectl treats 0 without --force, which is not handled here, as 1.
+		if grace == 0 {
+			grace = 1
+		}
+		if grace > 0 {
+			opts.GracePeriodSeconds = &grace
+		}
+	}
+
+	rc := c.resourceClient(target.mapping, cmd.namespace)
+	var out strings.Builder
+	for _, name := range target.names {
+		if err := rc.Delete(ctx, name, opts); err != nil {
+			return out.String(), "", err
+		}
+		fmt.Fprintf(&out, "%s %q deleted\n", qualifiedKind(target.mapping), name)
+	}
+	return out.String(), "", nil
+}
+
+// nativeRolloutStatus runs kubectl rollout status for a deployment, daemon
+// set or stateful set. It waits for the rollout to finish unless
+// --watch=false, printing each new status line, until --timeout or the
+// command's own deadline.
+func nativeRolloutStatus(ctx context.Context, c *nativeClients, cmd *nativeCommand) (string, string, error) {
+	if len(cmd.args) < 2 || strings.ToLower(cmd.args[0]) != "status" {
+		return "", "", errNotNative
+	}
+	target, err := parseTargets(c, cmd.args[1:])
+	if err != nil {
+		return "", "", err
+	}
+	if len(target.names) != 1 {
+		return "", "", errNotNative
+	}
+	watch, err := cmd.boolFlag("watch", true)
+	if err != nil {
+		return "", "", err
+	}
+	if v, ok := cmd.flag("timeout"); ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid value %q for --timeout", v)
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	status := rolloutStatusFunc(c, target.mapping, cmd.namespace, target.names[0])
+	if status == nil {
+		return "", "", errNotNative
+	}
+	var out strings.Builder
+	last := ""
+	for {
+		msg, done, err := status(ctx)
+		if err != nil {
+			return out.String(), "", err
+		}
+		if msg != last {
+			out.WriteString(msg)
+			last = msg
+		}
+		if done || !watch {
+			return out.String(), "", nil
+		}
+		select {
+		case <-ctx.Done():
+			return out.String(), "", errors.New("timed out waiting for the condition")
+		case <-time.After(rolloutStatusPollInterval):
+		}
+	}
+}
+
+// rolloutStatusFunc returns the function reading the rollout status of the
+// named workload, or nil for kinds rollout status does not support.
+func rolloutStatusFunc(c *nativeClients, mapping *meta.RESTMapping, namespace, name string) func(context.Context) (string, bool, error) {
+	apps := c.clientset.AppsV1()
+	switch mapping.Resource.GroupResource() {
+	case deploymentsResource:
+		return func(ctx context.Context) (string, bool, error) {
+			d, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", false, err
+			}
+			return deploymentRolloutStatus(d)
+		}
+	case daemonSetsResource:
+		return func(ctx context.Context) (string, bool, error) {
+			ds, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", false, err
+			}
+			return daemonSetRolloutStatus(ds)
+		}
+	case statefulSetsResource:
+		return func(ctx context.Context) (string, bool, error) {
+			sts, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return "", false, err
+			}
+			return statefulSetRolloutStatus(sts)
+		}
+	}
+	return nil
+}
+
+// deploymentRolloutStatus reports a deployment's rollout the way kubectl
+// rollout status does.
+func deploymentRolloutStatus(d *appsv1.Deployment) (string, bool, error) {
+	if d.Generation > d.Status.ObservedGeneration {
+		return "Waiting for deployment spec update to be observed...\n", false, nil
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			return "", false, fmt.Errorf("deployment %q exceeded its progress deadline", d.Name)
+		}
+	}
+	s := d.Status
+	switch {
+	case d.Spec.Replicas != nil && s.UpdatedReplicas < *d.Spec.Replicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...\n",
+			d.Name, s.UpdatedReplicas, *d.Spec.Replicas), false, nil
+	case s.Replicas > s.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...\n",
+			d.Name, s.Replicas-s.UpdatedReplicas), false, nil
+	case s.AvailableReplicas < s.UpdatedReplicas:
+		return fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...\n",
+			d.Name, s.AvailableReplicas, s.UpdatedReplicas), false, nil
+	}
+	return fmt.Sprintf("deployment %q successfully rolled out\n", d.Name), true, nil
+}
+
+// daemonSetRolloutStatus reports a daemon set's rollout the way kubectl
+// rollout status does.
+func daemonSetRolloutStatus(ds *appsv1.DaemonSet) (string, bool, error) {
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return "", true, errors.New("rollout status is only available for RollingUpdate strategy type")
+	}
+	if ds.Generation > ds.Status.ObservedGeneration {
+		return "Waiting for daemon set spec update to be observed...\n", false, nil
+	}
+	s := ds.Status
+	switch {
+	case s.UpdatedNumberScheduled < s.DesiredNumberScheduled:
+		return fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...\n",
+			ds.Name, s.UpdatedNumberScheduled, s.DesiredNumberScheduled), false, nil
+	case s.NumberAvailable < s.DesiredNumberScheduled:
+		return fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...\n",
+			ds.Name, s.NumberAvailable, s.DesiredNumberScheduled), false, nil
+	}
+	return fmt.Sprintf("daemon set %q successfully rolled out\n", ds.Name), true, nil
+}
+
+// statefulSetRolloutStatus reports a stateful set's rollout the way kubectl
+// rollout status does.
+func statefulSetRolloutStatus(sts *appsv1.StatefulSet) (string, bool, error) {
+	if sts.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return "", true, errors.New("rollout status is only available for RollingUpdate strategy type")
+	}
+	s := sts.Status
+	if s.ObservedGeneration == 0 || sts.Generation > s.ObservedGeneration {
+		return "Waiting for statefulset spec update to be observed...\n", false, nil
+	}
+	if sts.Spec.Replicas != nil && s.ReadyReplicas < *sts.Spec.Replicas {
+		return fmt.Sprintf("Waiting for %d pods to be ready...\n", *sts.Spec.Replicas-s.ReadyReplicas), false, nil
+	}
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		if sts.Spec.Replicas != nil && s.UpdatedReplicas < *sts.Spec.Replicas-*ru.Partition {
+			return fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...\n",
+				s.UpdatedReplicas, *sts.Spec.Replicas-*ru.Partition), false, nil
+		}
+		return fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...\n", s.UpdatedReplicas), true, nil
+	}
+	if s.UpdateRevision != s.CurrentRevision {
+		return fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...\n",
+			s.UpdatedReplicas, s.UpdateRevision), false, nil
+	}
+	return fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...\n", s.CurrentReplicas, s.CurrentRevision), true, nil
+}
+
+// describeEvents writes the Events section of describe output, oldest
+// first.
+func describeEvents(out *strings.Builder, events *corev1.EventList) {
+	if events == nil || len(events.Items) == 0 {
+		out.WriteString("Events:       <none>\n")
+		return
+	}
+	items := append([]corev1.Event(nil), events.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return eventTime(items[i]).Before(eventTime(items[j])) })
+
+	out.WriteString("Events:\n")
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 6, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  Type\tReason\tAge\tFrom\tMessage")
+	fmt.Fprintln(w, "  ----\t------\t----\t----\t-------")
+	now := time.Now()
+	for _, e := range items {
+		age := duration.ShortHumanDuration(now.Sub(eventTime(e)))
+		if e.Count > 1 && !e.FirstTimestamp.IsZero() {
+			age = fmt.Sprintf("%s (x%d over %s)", age, e.Count, duration.ShortHumanDuration(now.Sub(e.FirstTimestamp.Time)))
+		}
+		from := e.Source.Component
+		if from == "" {
+			from = e.ReportingController
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", e.Type, e.Reason, age, from, strings.TrimSpace(e.Message))
+	}
+	_ = w.Flush()
+	out.Write(buf.Bytes())
+}
+
+// eventTime is when an event last happened.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
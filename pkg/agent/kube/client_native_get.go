@@ -0,0 +1,406 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// tableAcceptHeader asks the API server for a resource as a table, in the
+// columns kubectl get prints.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// lastAppliedAnnotation is left out of describe output, as kubectl does.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// tableReader reads resources as server-side tables.
+type tableReader interface {
+	Table(ctx context.Context, mapping *meta.RESTMapping, namespace, name string, opts metav1.ListOptions) (*metav1.Table, error)
+}
+
+// restTableReader reads tables with a REST client of the API server.
+type restTableReader struct {
+	client rest.Interface
+}
+
+func (r restTableReader) Table(ctx context.Context, mapping *meta.RESTMapping, namespace, name string, opts metav1.ListOptions) (*metav1.Table, error) {
+	gv := mapping.Resource.GroupVersion()
+	segments := []string{"/apis", gv.Group, gv.Version}
+	if gv.Group == "" {
+		segments = []string{"/api", gv.Version}
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	segments = append(segments, mapping.Resource.Resource)
+	if name != "" {
+		segments = append(segments, name)
+	}
+	req := r.client.Get().AbsPath(path.Join(segments...)).
+		SetHeader("Accept", tableAcceptHeader).
+		Param("includeObject", string(metav1.IncludeMetadata))
+	if opts.LabelSelector != "" {
+		req = req.Param("labelSelector", opts.LabelSelector)
+	}
+	if opts.FieldSelector != "" {
+		req = req.Param("fieldSelector", opts.FieldSelector)
+	}
+	raw, err := req.Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var table metav1.Table
+	if err := json.Unmarshal(raw, &table); err != nil {
+		return nil, err
+	}
+	if table.Kind != "Table" {
+		// The server cannot print this resource; kubectl can.
+		return nil, errNotNative
+	}
+	return &table, nil
+}
+
+// nativeGet runs kubectl get for one resource type, printed as a table,
+// json, yaml, names or a jsonpath template.
+func nativeGet(ctx context.Context, c *nativeClients, cmd *nativeCommand) (string, string, error) {
+	target, err := parseTargets(c, cmd.args)
+	if err != nil {
+		return "", "", err
+	}
+	allNamespaces, err := cmd.boolFlag("all-namespaces", false)
+	if err != nil {
+		return "", "", err
+	}
+	namespace := cmd.namespace
+	if allNamespaces {
+		if len(target.names) > 0 {
+			return "", "", errors.New("a resource cannot be retrieved by name across all namespaces")
+		}
+		namespace = ""
+	}
+	selector, _ := cmd.flag("selector")
+	fieldSelector, _ := cmd.flag("field-selector")
+	if len(target.names) > 0 && (selector != "" || fieldSelector != "") {
+		return "", "", errNotNative
+	}
+	opts := metav1.ListOptions{LabelSelector: selector, FieldSelector: fieldSelector}
+
+	output, _ := cmd.flag("output")
+	format, template, _ := strings.Cut(output, "=")
+	switch format {
+	case "", "wide":
+		return getTable(ctx, c, target, namespace, opts, format == "wide", allNamespaces)
+	case "json", "yaml", "name", "jsonpath":
+	default:
+		return "", "", errNotNative
+	}
+
+	objects, err := getObjects(ctx, c, target, namespace, opts)
+	if err != nil {
+		return "", "", err
+	}
+	if format == "name" {
+		var out strings.Builder
+		for _, obj := range objects {
+			fmt.Fprintf(&out, "%s/%s\n", qualifiedKind(target.mapping), obj.GetName())
+		}
+		return out.String(), "", nil
+	}
+
+	var data interface{}
+	if len(target.names) == 1 {
+		data = objects[0].Object
+	} else {
+		items := make([]interface{}, 0, len(objects))
+		for _, obj := range objects {
+			items = append(items, obj.Object)
+		}
+		data = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+			"metadata":   map[string]interface{}{"resourceVersion": ""},
+		}
+	}
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(data, "", "    ")
+		if err != nil {
+			return "", "", err
+		}
+		return string(out) + "\n", "", nil
+	case "yaml":
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return "", "", err
+		}
+		return string(out), "", nil
+	}
+	jp := jsonpath.New("output").AllowMissingKeys(true)
+	if err := jp.Parse(template); err != nil {
+		return "", "", fmt.Errorf("error parsing jsonpath %s, %v", template, err)
+	}
+	var out bytes.Buffer
+	if err := jp.Execute(&out, data); err != nil {
+		return "", "", err
+	}
+	return out.String(), "", nil
+}
+
+// getObjects reads the named objects of target, or lists them all.
+// Managed fields are left out, as kubectl does by default.
+func getObjects(ctx context.Context, c *nativeClients, target *resourceTarget, namespace string, opts metav1.ListOptions) ([]unstructured.Unstructured, error) {
+	rc := c.resourceClient(target.mapping, namespace)
+	var objects []unstructured.Unstructured
+	if len(target.names) > 0 {
+		for _, name := range target.names {
+			obj, err := rc.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, *obj)
+		}
+	} else {
+		list, err := rc.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		objects = list.Items
+	}
+	for i := range objects {
+		objects[i].SetManagedFields(nil)
+		if objects[i].GetKind() == "" {
+			objects[i].SetGroupVersionKind(target.mapping.GroupVersionKind)
+		}
+	}
+	return objects, nil
+}
+
+// getTable prints target as kubectl get's table, with the priority
+// columns too when wide.
+func getTable(ctx context.Context, c *nativeClients, target *resourceTarget, namespace string, opts metav1.ListOptions, wide, allNamespaces bool) (string, string, error) {
+	names := target.names
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	var tables []*metav1.Table
+	for _, name := range names {
+		t, err := c.tables.Table(ctx, target.mapping, namespace, name, opts)
+		if err != nil {
+			return "", "", err
+		}
+		tables = append(tables, t)
+	}
+	rows := 0
+	for _, t := range tables {
+		rows += len(t.Rows)
+	}
+	if rows == 0 {
+		if allNamespaces || target.mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return "", "No resources found\n", nil
+		}
+		return "", fmt.Sprintf("No resources found in %s namespace.\n", namespace), nil
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 6, 4, 3, ' ', 0)
+	var columns []int
+	var headers []string
+	if allNamespaces {
+		headers = append(headers, "NAMESPACE")
+	}
+	for i, col := range tables[0].ColumnDefinitions {
+		if wide || col.Priority == 0 {
+			columns = append(columns, i)
+			headers = append(headers, strings.ToUpper(col.Name))
+		}
+	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, t := range tables {
+		for _, row := range t.Rows {
+			var cells []string
+			if allNamespaces {
+				cells = append(cells, rowNamespace(row))
+			}
+			for _, i := range columns {
+				var cell interface{}
+				if i < len(row.Cells) {
+					cell = row.Cells[i]
+				}
+				cells = append(cells, tableCell(t.ColumnDefinitions[i], cell))
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), "", nil
+}
+
+// rowNamespace returns the namespace of a table row's object metadata.
+func rowNamespace(row metav1.TableRow) string {
+	var obj metav1.PartialObjectMetadata
+	if len(row.Object.Raw) > 0 && json.Unmarshal(row.Object.Raw, &obj) == nil {
+		return obj.Namespace
+	}
+	return ""
+}
+
+// tableCell formats one table cell the way kubectl does. Timestamps in
+// date columns are shown as ages.
+func tableCell(col metav1.TableColumnDefinition, cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		if col.Type == "date" {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				return duration.HumanDuration(time.Since(t))
+			}
+		}
+		return v
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+	}
+	return fmt.Sprint(cell)
+}
+
+// nativeDescribe runs kubectl describe for named objects of one resource
+// type. It prints the object's metadata, its other fields as YAML and its
+// events; kubectl's per-kind layouts are not reproduced.
+func nativeDescribe(ctx context.Context, c *nativeClients, cmd *nativeCommand) (string, string, error) {
+	target, err := parseTargets(c, cmd.args)
+	if err != nil {
+		return "", "", err
+	}
+	if len(target.names) == 0 {
+		return "", "", errNotNative
+	}
+	var out strings.Builder
+	for i, name := range target.names {
+		obj, err := c.resourceClient(target.mapping, cmd.namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return out.String(), "", err
+		}
+		if i > 0 {
+			out.WriteString("\n\n")
+		}
+		if err := describeObject(&out, obj, target.mapping); err != nil {
+			return out.String(), "", err
+		}
+		events, err := c.clientset.CoreV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.Set{
+				"involvedObject.name":      obj.GetName(),
+				"involvedObject.namespace": obj.GetNamespace(),
+				"involvedObject.uid":       string(obj.GetUID()),
+			}.String(),
+		})
+		if err != nil {
+			// Describing still works for users who may not list events.
+			events = nil
+		}
+		describeEvents(&out, events)
+	}
+	return out.String(), "", nil
+}
+
+// describeObject writes obj's name, labels and annotations, then each of
+// its other top-level fields as YAML. Secret values are replaced by their
+// sizes.
+func describeObject(out *strings.Builder, obj *unstructured.Unstructured, mapping *meta.RESTMapping) error {
+	fmt.Fprintf(out, "Name:         %s\n", obj.GetName())
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		fmt.Fprintf(out, "Namespace:    %s\n", obj.GetNamespace())
+	}
+	describeMap(out, "Labels:", obj.GetLabels())
+	annotations := obj.GetAnnotations()
+	delete(annotations, lastAppliedAnnotation)
+	describeMap(out, "Annotations:", annotations)
+	fmt.Fprintf(out, "API Version:  %s\n", obj.GetAPIVersion())
+	fmt.Fprintf(out, "Kind:         %s\n", obj.GetKind())
+	fmt.Fprintf(out, "Created:      %s\n", obj.GetCreationTimestamp().UTC().Format(time.RFC1123Z))
+
+	secret := mapping.GroupVersionKind.Group == "" && mapping.GroupVersionKind.Kind == "Secret"
+	var keys []string
+	for key := range obj.Object {
+		if key != "apiVersion" && key != "kind" && key != "metadata" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := obj.Object[key]
+		if secret && key == "data" {
+			value = secretSizes(value)
+		}
+		body, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s:\n", strings.ToUpper(key[:1])+key[1:])
+		for _, line := range strings.Split(strings.TrimRight(string(body), "\n"), "\n") {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+	return nil
+}
+
+// describeMap writes a labels or annotations heading and its entries, one
+// per line, or <none>.
+func describeMap(out *strings.Builder, heading string, m map[string]string) {
+	if len(m) == 0 {
+		fmt.Fprintf(out, "%-14s<none>\n", heading)
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		if i == 0 {
+			fmt.Fprintf(out, "%-14s%s=%s\n", heading, k, m[k])
+		} else {
+			fmt.Fprintf(out, "%-14s%s=%s\n", "", k, m[k])
+		}
+	}
+}
+
+// secretSizes replaces a Secret's base64 values with their decoded sizes,
+// as kubectl describe shows them.
+func secretSizes(value interface{}) interface{} {
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	sizes := make(map[string]string, len(data))
+	for k, v := range data {
+		s, _ := v.(string)
+		size := len(s)
+		if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+			size = len(decoded)
+		}
+		sizes[k] = fmt.Sprintf("%d bytes", size)
+	}
+	return sizes
+}
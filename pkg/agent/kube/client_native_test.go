@@ -0,0 +1,329 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	podsGVR        = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	secretsGVR     = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+)
+
+// fakeTables serves the table kubectl get prints for pods.
+type fakeTables struct{}
+
+func (fakeTables) Table(_ context.Context, _ *meta.RESTMapping, namespace, name string, _ metav1.ListOptions) (*metav1.Table, error) {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{Kind: "Table", APIVersion: "meta.k8s.io/v1"},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "IP", Type: "string", Priority: 1},
+		},
+	}
+	if namespace == "empty" {
+		return table, nil
+	}
+	for _, pod := range []string{"p1", "p2"} {
+		if name != "" && name != pod {
+			continue
+		}
+		meta, _ := json.Marshal(metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: pod, Namespace: "default"}})
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  []interface{}{pod, "Running", nil},
+			Object: runtime.RawExtension{Raw: meta},
+		})
+	}
+	return table, nil
+}
+
+func testUnstructured(gvr schema.GroupVersionResource, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for k, v := range fields {
+		obj.Object[k] = v
+	}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "test"}})
+	return obj
+}
+
+// newNativeTestProxy returns a proxy whose "test" context uses fake
+// clients, and fails the test if it runs the kubectl binary.
+func newNativeTestProxy(t *testing.T, typed ...runtime.Object) *KubectlProxy {
+	t.Helper()
+	execCommandContext = func(context.Context, string, ...string) *exec.Cmd {
+		t.Fatal("the kubectl binary ran for a command the client-go path handles")
+		return nil
+	}
+	t.Cleanup(func() { execCommandContext = exec.CommandContext })
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			podsGVR:        "PodList",
+			secretsGVR:     "SecretList",
+			deploymentsGVR: "DeploymentList",
+		},
+		testUnstructured(podsGVR, "Pod", "default", "p1", map[string]interface{}{"spec": map[string]interface{}{"nodeName": "node-a"}}),
+		testUnstructured(podsGVR, "Pod", "default", "p2", nil),
+		testUnstructured(secretsGVR, "Secret", "default", "creds", map[string]interface{}{"data": map[string]interface{}{"password": "aHVudGVyMg=="}}),
+		testUnstructured(deploymentsGVR, "Deployment", "default", "web", map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}}),
+	)
+
+	config := &api.Config{
+		CurrentContext: "test",
+		Contexts:       map[string]*api.Context{"test": {Cluster: "test"}},
+		Clusters:       map[string]*api.Cluster{"test": {Server: "https://test.invalid"}},
+	}
+	k := &KubectlProxy{config: config}
+	k.native.entries = map[string]nativeClientEntry{"test": {config: config, clients: &nativeClients{
+		clientset: kubefake.NewSimpleClientset(typed...),
+		dynamic:   dyn,
+		mapper:    resettableMapper{RESTMapper: mapper, reset: func() {}},
+		namespace: "default",
+		tables:    fakeTables{},
+	}}}
+	return k
+}
+
+func TestKubectlProxy_ExecuteNative_Get(t *testing.T) {
+	k := newNativeTestProxy(t)
+
+	resp := k.Execute("test", "default", []string{"get", "pods", "-o", "json"})
+	if resp.ExitCode != 0 {
+		t.Fatalf("get -o json failed: %+v", resp)
+	}
+	var list struct {
+		Kind  string                   `json:"kind"`
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(resp.Output), &list); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, resp.Output)
+	}
+	if list.Kind != "List" || len(list.Items) != 2 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if strings.Contains(resp.Output, "managedFields") {
+		t.Error("managed fields should be left out")
+	}
+
+	resp = k.Execute("", "", []string{"get", "pod", "p1", "-oyaml"})
+	if resp.ExitCode != 0 || !strings.Contains(resp.Output, "name: p1") || !strings.Contains(resp.Output, "nodeName: node-a") {
+		t.Errorf("get -o yaml: %+v", resp)
+	}
+
+	resp = k.Execute("test", "default", []string{"get", "pods", "-o", "jsonpath={.items[*].metadata.name}"})
+	if resp.Output != "p1 p2" {
+		t.Errorf("jsonpath output = %q, want %q", resp.Output, "p1 p2")
+	}
+
+	resp = k.Execute("test", "default", []string{"get", "deployments", "-o", "name"})
+	if resp.Output != "deployment.apps/web\n" {
+		t.Errorf("name output = %q", resp.Output)
+	}
+
+	resp = k.Execute("test", "default", []string{"get", "pod", "missing", "-o", "json"})
+	if resp.ExitCode != 1 || resp.Error != "Error from server (NotFound): pods \"missing\" not found\n" {
+		t.Errorf("missing pod: %+v", resp)
+	}
+
+	resp = k.Execute("test", "default", []string{"get", "widgets"})
+	if resp.ExitCode != 1 || !strings.Contains(resp.Error, `the server doesn't have a resource type "widgets"`) {
+		t.Errorf("unknown resource type: %+v", resp)
+	}
+}
+
+func TestKubectlProxy_ExecuteNative_GetTable(t *testing.T) {
+	k := newNativeTestProxy(t)
+
+	resp := k.Execute("test", "default", []string{"get", "pods"})
+	want := "NAME   STATUS\np1     Running\np2     Running\n"
+	if resp.ExitCode != 0 || resp.Output != want {
+		t.Errorf("table output = %q, want %q", resp.Output, want)
+	}
+
+	resp = k.Execute("test", "", []string{"get", "pods", "-A", "-o", "wide"})
+	want = "NAMESPACE   NAME   STATUS    IP\ndefault     p1     Running   <none>\ndefault     p2     Running   <none>\n"
+	if resp.Output != want {
+		t.Errorf("wide output = %q, want %q", resp.Output, want)
+	}
+
+	resp = k.Execute("test", "empty", []string{"get", "pods"})
+	if resp.ExitCode != 0 || resp.Output != "No resources found in empty namespace.\n" {
+		t.Errorf("empty namespace: %+v", resp)
+	}
+}
+
+func TestKubectlProxy_ExecuteNative_Describe(t *testing.T) {
+	k := newNativeTestProxy(t)
+
+	resp := k.Execute("test", "default", []string{"describe", "pod", "p1"})
+	for _, want := range []string{"Name:         p1\n", "Namespace:    default\n", "Labels:       <none>\n", "Spec:\n  nodeName: node-a\n", "Events:       <none>\n"} {
+		if !strings.Contains(resp.Output, want) {
+			t.Errorf("describe output lacks %q:\n%s", want, resp.Output)
+		}
+	}
+
+	resp = k.Execute("test", "default", []string{"describe", "secret/creds"})
+	if !strings.Contains(resp.Output, "password: 7 bytes") || strings.Contains(resp.Output, "aHVudGVyMg==") {
+		t.Errorf("secret values should be shown as sizes:\n%s", resp.Output)
+	}
+}
+
+func TestKubectlProxy_ExecuteNative_Actions(t *testing.T) {
+	replicas := int32(2)
+	k := newNativeTestProxy(t,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+		},
+	)
+
+	resp := k.Execute("test", "default", []string{"logs", "p1", "--tail=10"})
+	if resp.ExitCode != 0 || resp.Output != "fake logs" {
+		t.Errorf("logs: %+v", resp)
+	}
+
+	resp = k.Execute("test", "default", []string{"scale", "deployment/web", "--replicas=3"})
+	if resp.ExitCode != 0 || resp.Output != "deployment.apps/web scaled\n" {
+		t.Errorf("scale: %+v", resp)
+	}
+
+	resp = k.Execute("test", "default", []string{"rollout", "status", "deployment", "web", "--watch=false"})
+	want := "Waiting for deployment \"web\" rollout to finish: 1 of 2 updated replicas are available...\n"
+	if resp.ExitCode != 0 || resp.Output != want {
+		t.Errorf("rollout status = %+v, want output %q", resp, want)
+	}
+
+	resp = k.Execute("test", "default", []string{"delete", "pod", "p2"})
+	if resp.ExitCode != 0 || resp.Output != "pod \"p2\" deleted\n" {
+		t.Errorf("delete: %+v", resp)
+	}
+	resp = k.Execute("test", "default", []string{"get", "pod", "p2", "-o", "name"})
+	if resp.ExitCode != 1 {
+		t.Errorf("deleted pod still readable: %+v", resp)
+	}
+}
+
+func TestKubectlProxy_ExecuteNative_Fallback(t *testing.T) {
+	defer func() { execCommand = exec.Command; execCommandContext = exec.CommandContext }()
+	mockStdout, mockStderr, mockExitCode = "from kubectl", "", 0
+
+	tests := []struct {
+		name    string
+		context string
+		args    []string
+		env     string
+	}{
+		{"unhandled flag", "test", []string{"get", "pods", "--sort-by=.metadata.name"}, ""},
+		{"unhandled output", "test", []string{"get", "pods", "-o", "custom-columns=NAME:.metadata.name"}, ""},
+		{"several resource types", "test", []string{"get", "pods,deployments"}, ""},
+		{"unhandled verb", "test", []string{"top", "pods"}, ""},
+		{"unknown context", "other", []string{"get", "pods"}, ""},
+		{"turned off", "test", []string{"get", "pods"}, "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(NativeKubectlEnvVar, tt.env)
+			k := newNativeTestProxy(t)
+			execCommandContext = fakeExecCommandContext
+			resp := k.Execute(tt.context, "default", tt.args)
+			if resp.Output != "from kubectl" {
+				t.Errorf("expected the kubectl binary to run, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestParseNativeCommand(t *testing.T) {
+	cmd, err := parseNativeCommand([]string{"logs", "p1", "-n=ns", "-c", "app", "--tail", "5", "-p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmd.args) != 1 || cmd.args[0] != "p1" {
+		t.Errorf("args = %v", cmd.args)
+	}
+	want := map[string]string{"namespace": "ns", "container": "app", "tail": "5", "previous": "true"}
+	for k, v := range want {
+		if cmd.flags[k] != v {
+			t.Errorf("flag %s = %q, want %q", k, cmd.flags[k], v)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"logs", "p1", "-f"},
+		{"get", "pods", "--replicas=2"},
+		{"get", "pods", "-o"},
+		{"get", "pods", "--", "x"},
+	} {
+		if _, err := parseNativeCommand(args); err == nil {
+			t.Errorf("%v should not be handled natively", args)
+		}
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	replicas := int32(3)
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 1}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+	if msg, done, _ := deploymentRolloutStatus(d); done || msg != "Waiting for deployment spec update to be observed...\n" {
+		t.Errorf("unobserved: %q %v", msg, done)
+	}
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3}
+	if msg, done, _ := deploymentRolloutStatus(d); done || !strings.Contains(msg, "1 old replicas are pending termination") {
+		t.Errorf("old replicas: %q %v", msg, done)
+	}
+	d.Status.Replicas = 3
+	if msg, done, _ := deploymentRolloutStatus(d); !done || msg != "deployment \"web\" successfully rolled out\n" {
+		t.Errorf("complete: %q %v", msg, done)
+	}
+	d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"}}
+	if _, _, err := deploymentRolloutStatus(d); err == nil {
+		t.Error("a deployment past its progress deadline should fail")
+	}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent"},
+		Spec:       appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 1},
+	}
+	if msg, done, _ := daemonSetRolloutStatus(ds); done || !strings.Contains(msg, "1 out of 3 new pods have been updated") {
+		t.Errorf("daemon set: %q %v", msg, done)
+	}
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas, UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, CurrentReplicas: 3, CurrentRevision: "r1", UpdateRevision: "r1"},
+	}
+	if msg, done, _ := statefulSetRolloutStatus(sts); !done || msg != "statefulset rolling update complete 3 pods at revision r1...\n" {
+		t.Errorf("stateful set: %q %v", msg, done)
+	}
+}
@@ -0,0 +1,60 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+)
+
+// kubectlStreamMaxDuration bounds how long a streamed kubectl command may
+// run, so a followed log nobody cancels does not hold a process forever.
+const kubectlStreamMaxDuration = time.Hour
+
+// streamableKubectlCommands are the commands Stream runs. They are the ones
+// whose output is worth reading before they exit.
+var streamableKubectlCommands = map[string]bool{
+	"logs": true,
+}
+
+// Stream runs a kubectl command, writing its output to stdout and stderr as
+// it arrives instead of buffering it the way Execute does. Only the
+// commands in streamableKubectlCommands are allowed, and the usual
+// allowlist applies to their arguments. The command runs until it exits,
+// ctx is cancelled or kubectlStreamMaxDuration passes. Cancellation is not
+// an error, so a cancelled stream returns a zero result.
+func (k *KubectlProxy) Stream(ctx context.Context, ctxName, namespace string, args []string, stdout, stderr io.Writer) protocol.KubectlStreamResult {
+	if len(args) == 0 || !streamableKubectlCommands[strings.ToLower(args[0])] || !k.validateArgs(args) {
+		return protocol.KubectlStreamResult{ExitCode: 1, Error: "Disallowed kubectl command"}
+	}
+	if !AllowsNamespace(namespace) {
+		return protocol.KubectlStreamResult{ExitCode: 1, Error: "Disallowed kubectl namespace"}
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, kubectlStreamMaxDuration)
+	defer cancel()
+
+	cmd := execCommandContext(runCtx, "kubectl", k.commandArgs(ctxName, namespace, args)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Run()
+	if err == nil {
+		return protocol.KubectlStreamResult{}
+	}
+	if ctx.Err() != nil {
+		return protocol.KubectlStreamResult{}
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return protocol.KubectlStreamResult{ExitCode: 1, Error: fmt.Sprintf("kubectl stream stopped after %s", kubectlStreamMaxDuration)}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return protocol.KubectlStreamResult{ExitCode: exitErr.ExitCode()}
+	}
+	return protocol.KubectlStreamResult{ExitCode: 1, Error: err.Error()}
+}
@@ -0,0 +1,66 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestKubectlProxy_Stream(t *testing.T) {
+	defer func() { execCommandContext = exec.CommandContext }()
+	execCommandContext = fakeExecCommandContext
+	proxy := &KubectlProxy{config: &api.Config{}}
+
+	mockStdout, mockStderr, mockExitCode = "line 1\nline 2\n", "warning\n", 0
+	defer func() { mockStdout, mockStderr, mockExitCode = "", "", 0 }()
+	var stdout, stderr bytes.Buffer
+	res := proxy.Stream(context.Background(), "default", "default", []string{"logs", "web-0", "--follow"}, &stdout, &stderr)
+	if res.ExitCode != 0 || res.Error != "" {
+		t.Fatalf("result = %+v; want success", res)
+	}
+	if stdout.String() != "line 1\nline 2\n" || stderr.String() != "warning\n" {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+
+	mockExitCode = 3
+	if res = proxy.Stream(context.Background(), "", "", []string{"logs", "web-0"}, &stdout, &stderr); res.ExitCode != 3 {
+		t.Errorf("ExitCode = %d; want 3", res.ExitCode)
+	}
+
+	for _, args := range [][]string{nil, {"get", "pods"}, {"delete", "pod", "web-0"}} {
+		res = proxy.Stream(context.Background(), "", "", args, &stdout, &stderr)
+		if res.Error != "Disallowed kubectl command" {
+			t.Errorf("Stream(%v) = %+v; want it refused", args, res)
+		}
+	}
+}
+
+// TestKubectlProxy_Stream_Cancel verifies that cancelling ctx stops the
+// command and is not reported as an error.
+func TestKubectlProxy_Stream_Cancel(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not available")
+	}
+	defer func() { execCommandContext = exec.CommandContext }()
+	execCommandContext = func(ctx context.Context, _ string, _ ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, sleep, "30")
+	}
+	proxy := &KubectlProxy{config: &api.Config{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	var out bytes.Buffer
+	res := proxy.Stream(ctx, "", "", []string{"logs", "web-0", "--follow"}, &out, &out)
+	if time.Since(start) > 10*time.Second {
+		t.Fatal("Stream did not return after cancel")
+	}
+	if res.ExitCode != 0 || res.Error != "" {
+		t.Errorf("result = %+v; want a zero result", res)
+	}
+}
@@ -3,6 +3,7 @@ package kube
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
@@ -19,6 +21,8 @@ var (
 	mockStdout   string
 	mockStderr   string
 	mockExitCode int
+	// mockEchoStdin makes the helper copy its stdin to stdout.
+	mockEchoStdin bool
 )
 
 // fakeExecCommand mimics exec.Command but calls a helper test function
@@ -31,6 +35,7 @@ func fakeExecCommand(command string, args ...string) *exec.Cmd {
 		"MOCK_STDOUT=" + mockStdout,
 		"MOCK_STDERR=" + mockStderr,
 		"MOCK_EXIT_CODE=" + strconv.Itoa(mockExitCode),
+		"MOCK_ECHO_STDIN=" + strconv.FormatBool(mockEchoStdin),
 		// Prevent coverage warning from polluting stderr
 		"GOCOVERDIR=" + os.TempDir(),
 	}
@@ -50,6 +55,9 @@ func TestHelperProcess(t *testing.T) {
 
 	// Write mock stdout
 	fmt.Fprint(os.Stdout, os.Getenv("MOCK_STDOUT"))
+	if os.Getenv("MOCK_ECHO_STDIN") == "true" {
+		_, _ = io.Copy(os.Stdout, os.Stdin)
+	}
 
 	// Write mock stderr
 	fmt.Fprint(os.Stderr, os.Getenv("MOCK_STDERR"))
@@ -270,6 +278,99 @@ func TestKubectlProxy_RenameContext(t *testing.T) {
 	}
 }
 
+func TestKubectlProxy_RemoveContext(t *testing.T) {
+	kubeconfigPath := filepath.Join(t.TempDir(), "config")
+	initial := &api.Config{
+		CurrentContext: "prod",
+		Contexts: map[string]*api.Context{
+			"prod":    {Cluster: "shared", AuthInfo: "admin"},
+			"staging": {Cluster: "shared", AuthInfo: "staging-user"},
+			"dev":     {Cluster: "dev-cluster", AuthInfo: "admin"},
+		},
+		Clusters: map[string]*api.Cluster{
+			"shared":      {Server: "https://shared.example.com"},
+			"dev-cluster": {Server: "https://dev.example.com"},
+		},
+		AuthInfos: map[string]*api.AuthInfo{
+			"admin":        {Token: "admin-token"},
+			"staging-user": {Token: "staging-token"},
+		},
+	}
+	if err := clientcmd.WriteToFile(*initial, kubeconfigPath); err != nil {
+		t.Fatalf("Failed to write initial kubeconfig: %v", err)
+	}
+	proxy, err := NewKubectlProxy(kubeconfigPath)
+	if err != nil {
+		t.Fatalf("NewKubectlProxy failed: %v", err)
+	}
+
+	// staging's cluster is shared with prod; only its user goes.
+	result, err := proxy.RemoveContext("staging")
+	if err != nil {
+		t.Fatalf("RemoveContext(staging) failed: %v", err)
+	}
+	if result.Context != "staging" || result.Cluster != "" || result.User != "staging-user" {
+		t.Errorf("RemoveContext(staging) = %+v, want only staging-user removed", result)
+	}
+
+	// dev's user is shared with prod; only its cluster goes.
+	result, err = proxy.RemoveContext("dev")
+	if err != nil {
+		t.Fatalf("RemoveContext(dev) failed: %v", err)
+	}
+	if result.Cluster != "dev-cluster" || result.User != "" {
+		t.Errorf("RemoveContext(dev) = %+v, want only dev-cluster removed", result)
+	}
+
+	onDisk, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		t.Fatalf("Failed to reload kubeconfig: %v", err)
+	}
+	if len(onDisk.Contexts) != 1 || onDisk.Contexts["prod"] == nil {
+		t.Errorf("contexts on disk = %v, want only prod", onDisk.Contexts)
+	}
+	if len(onDisk.Clusters) != 1 || onDisk.Clusters["shared"] == nil {
+		t.Errorf("clusters on disk = %v, want only shared", onDisk.Clusters)
+	}
+	if len(onDisk.AuthInfos) != 1 || onDisk.AuthInfos["admin"] == nil {
+		t.Errorf("users on disk = %v, want only admin", onDisk.AuthInfos)
+	}
+	if _, ok := proxy.config.Contexts["dev"]; ok {
+		t.Error("dev still loaded after RemoveContext")
+	}
+
+	backups, _ := filepath.Glob(kubeconfigPath + ".bak-*")
+	if len(backups) != 2 {
+		t.Errorf("expected a backup per removal, got %d", len(backups))
+	}
+
+	if _, err := proxy.RemoveContext("prod"); err == nil {
+		t.Error("RemoveContext should refuse the current context")
+	}
+	if _, err := proxy.RemoveContext("missing"); err == nil {
+		t.Error("RemoveContext should fail for an unknown context")
+	}
+}
+
+func TestKubectlProxy_RemoveContext_WriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	proxy := &KubectlProxy{
+		// A directory can be neither backed up nor written as a file.
+		kubeconfig: dir,
+		config: &api.Config{
+			Contexts:  map[string]*api.Context{"a": {Cluster: "a", AuthInfo: "a"}},
+			Clusters:  map[string]*api.Cluster{"a": {Server: "https://a.example.com"}},
+			AuthInfos: map[string]*api.AuthInfo{"a": {}},
+		},
+	}
+	if _, err := proxy.RemoveContext("a"); err == nil {
+		t.Fatal("RemoveContext should fail when the kubeconfig cannot be written")
+	}
+	if _, ok := proxy.config.Contexts["a"]; !ok {
+		t.Error("a failed write must leave the loaded config unchanged")
+	}
+}
+
 func TestKubectlProxy_Execute_Flags(t *testing.T) {
 	// Restore original execCommand after tests
 	defer func() { execCommand = exec.Command; execCommandContext = exec.CommandContext }()
@@ -513,6 +614,58 @@ func TestKubectlProxy_ImportKubeconfig_SkipExisting(t *testing.T) {
 	}
 }
 
+func TestKubectlProxy_RefreshImportedKubeconfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	kubeconfigPath := filepath.Join(tmpDir, "config")
+
+	initial := sampleKubeconfig("workload-ctx", "workload-cluster", "workload-admin", "https://workload.example.com")
+	if err := os.WriteFile(kubeconfigPath, []byte(initial), 0600); err != nil {
+		t.Fatalf("Failed to write initial kubeconfig: %v", err)
+	}
+	proxy, err := NewKubectlProxy(kubeconfigPath)
+	if err != nil {
+		t.Fatalf("NewKubectlProxy failed: %v", err)
+	}
+
+	// Unchanged content does not rewrite the file.
+	updated, err := proxy.RefreshImportedKubeconfig(initial)
+	if err != nil {
+		t.Fatalf("RefreshImportedKubeconfig failed: %v", err)
+	}
+	if len(updated) != 0 {
+		t.Errorf("Expected no updates, got %v", updated)
+	}
+	if backups, _ := filepath.Glob(kubeconfigPath + ".bak-*"); len(backups) != 0 {
+		t.Errorf("Expected no backup for an unchanged refresh, got %v", backups)
+	}
+
+	// Rotated credentials replace the existing entries; contexts that are not
+	// in the local kubeconfig are not added.
+	rotated := strings.Replace(initial, "fake-token", "rotated-token", 1)
+	other := sampleKubeconfig("other-ctx", "other-cluster", "other-user", "https://other.example.com")
+	updated, err = proxy.RefreshImportedKubeconfig(rotated)
+	if err != nil {
+		t.Fatalf("RefreshImportedKubeconfig failed: %v", err)
+	}
+	if len(updated) != 1 || updated[0] != "workload-ctx" {
+		t.Errorf("Expected updated=[workload-ctx], got %v", updated)
+	}
+	if _, err := proxy.RefreshImportedKubeconfig(other); err != nil {
+		t.Fatalf("RefreshImportedKubeconfig failed: %v", err)
+	}
+
+	written, err := clientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		t.Fatalf("Failed to load refreshed kubeconfig: %v", err)
+	}
+	if got := written.AuthInfos["workload-admin"].Token; got != "rotated-token" {
+		t.Errorf("Expected rotated token, got %q", got)
+	}
+	if _, ok := written.Contexts["other-ctx"]; ok {
+		t.Error("Refresh must not add contexts that were not imported")
+	}
+}
+
 func TestKubectlProxy_ImportKubeconfig_InvalidYAML(t *testing.T) {
 	tmpDir := t.TempDir()
 	kubeconfigPath := filepath.Join(tmpDir, "config")
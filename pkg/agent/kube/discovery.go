@@ -71,6 +71,10 @@ type VClusterInstance struct {
 	Status    string `json:"status"`    // "Running", "Paused", etc.
 	Connected bool   `json:"connected"` // whether kubeconfig context exists
 	Context   string `json:"context"`   // kubeconfig context name if connected
+	// HostContext is the kubeconfig context of the cluster the vCluster runs
+	// in. Set by CheckVClusterOnCluster; `vcluster list` only sees the
+	// current context and leaves it empty.
+	HostContext string `json:"hostContext,omitempty"`
 }
 
 // vclusterListEntry mirrors the JSON output from `vcluster list --output json`
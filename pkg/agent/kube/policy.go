@@ -0,0 +1,288 @@
+package kube
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KubectlPolicyEnvVar names the YAML policy file that extends or restricts
+// the built-in kubectl allowlist. Unset uses the built-in allowlist only.
+const KubectlPolicyEnvVar = "KC_KUBECTL_POLICY"
+
+// KubectlPolicy adjusts which kubectl commands the agent runs. Deny lists
+// win over allow lists and over the built-in allowlist. The built-in checks
+// for shell metacharacters and --exec cannot be lifted.
+type KubectlPolicy struct {
+	// AllowVerbs are verbs added to AllowedKubectlCommands.
+	AllowVerbs []string `yaml:"allow_verbs"`
+	// DenyVerbs are verbs refused even when built in.
+	DenyVerbs []string `yaml:"deny_verbs"`
+	// AllowResources maps a verb to the resource kinds it may act on. For
+	// delete and scale it extends the built-in kinds; for a verb from
+	// AllowVerbs it is the only kinds allowed, and without an entry any
+	// kind is.
+	AllowResources map[string][]string `yaml:"allow_resources"`
+	// DenyResources are resource kinds no command may name.
+	DenyResources []string `yaml:"deny_resources"`
+	// AllowNamespaces, when set, are the only namespaces a command may
+	// name; all-namespaces commands are then refused.
+	AllowNamespaces []string `yaml:"allow_namespaces"`
+	// DenyNamespaces are namespaces no command may name.
+	DenyNamespaces []string `yaml:"deny_namespaces"`
+	// DenyFlags are flags no command may pass, such as --all-namespaces.
+	DenyFlags []string `yaml:"deny_flags"`
+}
+
+// ParseKubectlPolicy parses a policy file, lower-casing verbs, kinds and
+// flags so they compare like kubectl arguments do.
+func ParseKubectlPolicy(data []byte) (*KubectlPolicy, error) {
+	var p KubectlPolicy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl policy: %w", err)
+	}
+	lower := func(values []string) []string {
+		out := make([]string, 0, len(values))
+		for _, v := range values {
+			if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	p.AllowVerbs = lower(p.AllowVerbs)
+	p.DenyVerbs = lower(p.DenyVerbs)
+	p.DenyResources = lower(p.DenyResources)
+	p.DenyFlags = lower(p.DenyFlags)
+	resources := make(map[string][]string, len(p.AllowResources))
+	for verb, kinds := range p.AllowResources {
+		resources[strings.ToLower(strings.TrimSpace(verb))] = lower(kinds)
+	}
+	p.AllowResources = resources
+	for _, ns := range append(slices.Clone(p.AllowNamespaces), p.DenyNamespaces...) {
+		if err := ValidateDNS1123Label("namespace", ns); err != nil {
+			return nil, fmt.Errorf("invalid kubectl policy: %w", err)
+		}
+	}
+	for _, f := range p.DenyFlags {
+		if !strings.HasPrefix(f, "-") {
+			return nil, fmt.Errorf("invalid kubectl policy: deny_flags entry %q is not a flag", f)
+		}
+	}
+	return &p, nil
+}
+
+// kubectlPolicyFile is the policy file in use, re-read when it changes.
+type kubectlPolicyFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	policy  *KubectlPolicy
+}
+
+var activeKubectlPolicy atomic.Pointer[kubectlPolicyFile]
+
+// SetKubectlPolicyFile makes the kubectl allowlist follow the policy file at
+// path, or only the built-in allowlist when path is empty. The file must
+// parse now. It is checked for changes before each command; a change that
+// does not parse is logged and the previous policy is kept.
+func SetKubectlPolicyFile(path string) error {
+	if path == "" {
+		activeKubectlPolicy.Store(nil)
+		return nil
+	}
+	f := &kubectlPolicyFile{path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read kubectl policy: %w", err)
+	}
+	if err := f.load(info); err != nil {
+		return err
+	}
+	activeKubectlPolicy.Store(f)
+	slog.Info("kubectl policy loaded", "path", path)
+	return nil
+}
+
+// currentKubectlPolicy returns the policy in use, or nil for none.
+func currentKubectlPolicy() *KubectlPolicy {
+	f := activeKubectlPolicy.Load()
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(f.path)
+	if err == nil && (!info.ModTime().Equal(f.modTime) || info.Size() != f.size) {
+		if err := f.load(info); err != nil {
+			slog.Error("kubectl policy not reloaded, keeping the previous one", "path", f.path, "error", err)
+		} else {
+			slog.Info("kubectl policy reloaded", "path", f.path)
+		}
+	}
+	return f.policy
+}
+
+// load reads the file as of info. The size and time are recorded even when
+// it does not parse, so a broken file is reported once rather than on every
+// command.
+func (f *kubectlPolicyFile) load(info os.FileInfo) error {
+	f.modTime, f.size = info.ModTime(), info.Size()
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read kubectl policy: %w", err)
+	}
+	p, err := ParseKubectlPolicy(data)
+	if err != nil {
+		return err
+	}
+	f.policy = p
+	return nil
+}
+
+// allowsVerb reports whether verb may run: built in or added by p, and not
+// denied by p.
+func (p *KubectlPolicy) allowsVerb(verb string) bool {
+	if p == nil {
+		return AllowedKubectlCommands[verb]
+	}
+	if slices.Contains(p.DenyVerbs, verb) {
+		return false
+	}
+	return AllowedKubectlCommands[verb] || slices.Contains(p.AllowVerbs, verb)
+}
+
+// deniesVerb reports whether p's DenyVerbs lists verb.
+func (p *KubectlPolicy) deniesVerb(verb string) bool {
+	return p != nil && slices.Contains(p.DenyVerbs, verb)
+}
+
+// addsVerb reports whether verb is allowed only because p adds it.
+func (p *KubectlPolicy) addsVerb(verb string) bool {
+	return p != nil && !AllowedKubectlCommands[verb] && slices.Contains(p.AllowVerbs, verb)
+}
+
+// allowsResource reports whether p lists kind for verb in AllowResources.
+func (p *KubectlPolicy) allowsResource(verb, kind string) bool {
+	if p == nil {
+		return false
+	}
+	return slices.ContainsFunc(p.AllowResources[verb], func(k string) bool { return sameResourceKind(k, kind) })
+}
+
+// deniesResource reports whether any kind in arg, such as "pods,secrets" or
+// "secret/name", is in p's DenyResources.
+func (p *KubectlPolicy) deniesResource(arg string) bool {
+	if p == nil || len(p.DenyResources) == 0 {
+		return false
+	}
+	kinds, _, _ := strings.Cut(strings.ToLower(arg), "/")
+	for _, kind := range strings.Split(kinds, ",") {
+		if slices.ContainsFunc(p.DenyResources, func(k string) bool { return sameResourceKind(k, kind) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyDenies reports whether the policy in use refuses args through its
+// deny lists or namespace restrictions, as opposed to args simply not
+// being allowlisted. Callers that let users approve commands outside the
+// allowlist use it to keep such commands refused.
+func PolicyDenies(args []string) bool {
+	p := currentKubectlPolicy()
+	if p == nil || len(args) == 0 {
+		return false
+	}
+	return slices.Contains(p.DenyVerbs, strings.ToLower(args[0])) ||
+		p.deniesResource(firstResourceArg(args[1:])) || !p.allowsFlags(args[1:])
+}
+
+// AllowsNamespace reports whether the policy in use lets a command run in
+// namespace. The empty namespace, the context's default, is always allowed.
+func AllowsNamespace(namespace string) bool {
+	return currentKubectlPolicy().allowsNamespace(namespace)
+}
+
+func (p *KubectlPolicy) allowsNamespace(namespace string) bool {
+	if p == nil || namespace == "" {
+		return true
+	}
+	if slices.Contains(p.DenyNamespaces, namespace) {
+		return false
+	}
+	return len(p.AllowNamespaces) == 0 || slices.Contains(p.AllowNamespaces, namespace)
+}
+
+// allowsFlags checks the flags of args against DenyFlags and the
+// namespaces they name against the namespace lists.
+func (p *KubectlPolicy) allowsFlags(args []string) bool {
+	if p == nil {
+		return true
+	}
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.ToLower(arg), "=")
+		if !strings.HasPrefix(name, "-") {
+			continue
+		}
+		if slices.Contains(p.DenyFlags, name) {
+			return false
+		}
+		switch name {
+		case "-a", "--all-namespaces":
+			if len(p.AllowNamespaces) > 0 {
+				return false
+			}
+		case "-n", "--namespace":
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+			}
+			if !p.allowsNamespace(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// kubectlValueFlags are flags whose value is the next argument, so it is
+// not mistaken for a resource kind.
+var kubectlValueFlags = map[string]bool{
+	"-n": true, "--namespace": true,
+	"-o": true, "--output": true,
+	"-l": true, "--selector": true,
+	"-c": true, "--container": true,
+	"--field-selector": true,
+}
+
+// firstResourceArg returns the first positional argument of args, the
+// resource kind for most verbs, or "" when there is none.
+func firstResourceArg(args []string) string {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if strings.HasPrefix(a, "-") {
+			if kubectlValueFlags[strings.ToLower(a)] {
+				i++
+			}
+			continue
+		}
+		return strings.ToLower(a)
+	}
+	return ""
+}
+
+// sameResourceKind reports whether two resource kinds are the same up to a
+// plural "s" or "es", so "secret" matches "secrets". Short names such as
+// "po" must be listed themselves.
+func sameResourceKind(a, b string) bool {
+	return a == b || a+"s" == b || b+"s" == a || a+"es" == b || b+"es" == a
+}
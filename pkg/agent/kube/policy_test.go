@@ -0,0 +1,105 @@
+package kube
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubectlPolicy = `
+allow_verbs: [label, annotate]
+deny_verbs: [scale]
+allow_resources:
+  delete: [deployments, Jobs]
+  label: [pods]
+deny_resources: [secrets]
+allow_namespaces: [team-a, team-b]
+deny_flags: [--raw]
+`
+
+func useKubectlPolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, SetKubectlPolicyFile(path))
+	t.Cleanup(func() { _ = SetKubectlPolicyFile("") })
+	return path
+}
+
+func TestKubectlPolicy_Validate(t *testing.T) {
+	useKubectlPolicy(t, testKubectlPolicy)
+
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"get", "pods"}, true},
+		{[]string{"label", "pods", "web", "tier=frontend"}, true},
+		{[]string{"label", "nodes", "worker-1", "gpu=true"}, false},
+		{[]string{"annotate", "deploy/web", "note=x"}, true},
+		{[]string{"scale", "deployment", "web", "--replicas=2"}, false},
+		{[]string{"delete", "deployments", "web"}, true},
+		{[]string{"delete", "job", "migrate"}, true},
+		{[]string{"delete", "pods", "web-0"}, true},
+		{[]string{"delete", "services", "web"}, false},
+		{[]string{"get", "secret", "token"}, false},
+		{[]string{"get", "pods,secrets"}, false},
+		{[]string{"get", "-o", "yaml", "secrets/token"}, false},
+		{[]string{"config", "view", "--raw"}, false},
+		{[]string{"get", "pods", "-n", "team-a"}, true},
+		{[]string{"get", "pods", "--namespace=kube-system"}, false},
+		{[]string{"get", "pods", "-A"}, false},
+		{[]string{"exec", "web", "--", "sh"}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ValidateKubectlArgs(tt.args), "%v", tt.args)
+	}
+	assert.True(t, PolicyDenies([]string{"scale", "deploy/web", "--replicas=0"}))
+	assert.True(t, PolicyDenies([]string{"get", "pods", "-n", "default"}))
+	assert.False(t, PolicyDenies([]string{"apply", "-f", "web.yaml"}), "not allowlisted, but not denied")
+
+	assert.True(t, AllowsNamespace(""))
+	assert.True(t, AllowsNamespace("team-b"))
+	assert.False(t, AllowsNamespace("default"))
+}
+
+func TestKubectlPolicy_HotReload(t *testing.T) {
+	path := useKubectlPolicy(t, "deny_verbs: [logs]\n")
+	assert.False(t, ValidateKubectlArgs([]string{"logs", "web"}))
+
+	// Push the time forward so the change is seen on file systems with
+	// coarse timestamps.
+	reload := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(path, later, later))
+	}
+	reload("allow_verbs: [cordon]\n")
+	assert.True(t, ValidateKubectlArgs([]string{"logs", "web"}))
+	assert.True(t, ValidateKubectlArgs([]string{"cordon", "worker-1"}))
+
+	reload("allow_verbs: [cordon\n")
+	assert.True(t, ValidateKubectlArgs([]string{"cordon", "worker-1"}), "a broken file keeps the previous policy")
+
+	require.NoError(t, SetKubectlPolicyFile(""))
+	assert.False(t, ValidateKubectlArgs([]string{"cordon", "worker-1"}))
+}
+
+func TestSetKubectlPolicyFile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	assert.Error(t, SetKubectlPolicyFile(filepath.Join(dir, "missing.yaml")))
+	for _, content := range []string{
+		"allow_verbs: [get\n",
+		"allow_namespaces: [Team_A]\n",
+		"deny_flags: [raw]\n",
+	} {
+		path := filepath.Join(dir, "policy.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		assert.Error(t, SetKubectlPolicyFile(path), content)
+	}
+	assert.Nil(t, currentKubectlPolicy(), "a failed load leaves no policy")
+}
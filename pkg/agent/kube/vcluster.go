@@ -99,12 +99,25 @@ func (m *LocalClusterManager) CreateVCluster(name, namespace string) error {
 	return nil
 }
 
-// ConnectVCluster connects to an existing vCluster by updating kubeconfig.
+// ConnectVCluster connects to an existing vCluster on the current context by
+// updating kubeconfig.
 func (m *LocalClusterManager) ConnectVCluster(name, namespace string) error {
+	return m.ConnectVClusterOnHost(name, namespace, "")
+}
+
+// ConnectVClusterOnHost connects to an existing vCluster running on the
+// hostContext cluster, adding a kubeconfig context named by
+// VClusterContextName so it shows up as a managed cluster. An empty
+// hostContext uses the current context.
+func (m *LocalClusterManager) ConnectVClusterOnHost(name, namespace, hostContext string) error {
 	m.broadcastProgress("vcluster", name, "connecting",
 		fmt.Sprintf("Connecting to vCluster '%s' in namespace '%s'...", name, namespace), progressConnecting)
 
-	cmd := execCommand("vcluster", "connect", name, "-n", namespace, "--update-current=false")
+	args := []string{"connect", name, "-n", namespace, "--update-current=false"}
+	if hostContext != "" {
+		args = append(args, "--context", hostContext)
+	}
+	cmd := execCommand("vcluster", args...)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
@@ -220,6 +233,45 @@ func runWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
 	}
 }
 
+// VClusterContextName is the kubeconfig context `vcluster connect` creates
+// for a vCluster on hostContext.
+func VClusterContextName(name, namespace, hostContext string) string {
+	return "vcluster_" + name + "_" + namespace + "_" + hostContext
+}
+
+// listKubeconfigContexts returns the context names in the user's kubeconfig.
+func listKubeconfigContexts() ([]string, error) {
+	cmd := execCommand("kubectl", "config", "get-contexts", "-o", "name")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	var contexts []string
+	for _, ctx := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		if ctx != "" {
+			contexts = append(contexts, ctx)
+		}
+	}
+	return contexts, nil
+}
+
+// markConnectedVClusters fills in Context/Connected for instances whose
+// `vcluster connect` context already exists.
+func markConnectedVClusters(instances []VClusterInstance, contexts []string) {
+	known := make(map[string]bool, len(contexts))
+	for _, ctx := range contexts {
+		known[ctx] = true
+	}
+	for i := range instances {
+		ctx := VClusterContextName(instances[i].Name, instances[i].Namespace, instances[i].HostContext)
+		if known[ctx] {
+			instances[i].Connected = true
+			instances[i].Context = ctx
+		}
+	}
+}
+
 // VClusterClusterStatus represents vCluster status on a specific host cluster.
 type VClusterClusterStatus struct {
 	Context   string             `json:"context"`
@@ -236,6 +288,12 @@ const vclusterCRDCheckTimeout = 10 * time.Second
 // CheckVClusterOnCluster checks if vCluster CRDs are installed on a specific cluster
 // and lists any existing vCluster instances.
 func (m *LocalClusterManager) CheckVClusterOnCluster(context string) (*VClusterClusterStatus, error) {
+	return m.checkVClusterOnCluster(context, nil)
+}
+
+// checkVClusterOnCluster is CheckVClusterOnCluster with the kubeconfig
+// contexts already listed; nil lists them when instances are found.
+func (m *LocalClusterManager) checkVClusterOnCluster(context string, contexts []string) (*VClusterClusterStatus, error) {
 	status := &VClusterClusterStatus{
 		Context: context,
 		Name:    context,
@@ -285,9 +343,10 @@ func (m *LocalClusterManager) CheckVClusterOnCluster(context string) (*VClusterC
 				parts := strings.SplitN(line, ",", 3)
 				if len(parts) >= 2 && parts[0] != "" {
 					inst := VClusterInstance{
-						Name:      parts[0],
-						Namespace: parts[1],
-						Status:    "Running",
+						Name:        parts[0],
+						Namespace:   parts[1],
+						Status:      "Running",
+						HostContext: context,
 					}
 					if len(parts) >= 3 && parts[2] != "" {
 						inst.Status = parts[2]
@@ -297,6 +356,10 @@ func (m *LocalClusterManager) CheckVClusterOnCluster(context string) (*VClusterC
 			}
 			status.Instances = len(status.VClusters)
 		}
+		if len(status.VClusters) > 0 && contexts == nil {
+			contexts, _ = listKubeconfigContexts()
+		}
+		markConnectedVClusters(status.VClusters, contexts)
 	}
 
 	return status, nil
@@ -304,21 +367,15 @@ func (m *LocalClusterManager) CheckVClusterOnCluster(context string) (*VClusterC
 
 // CheckVClusterOnAllClusters checks vCluster status across all kubeconfig contexts.
 func (m *LocalClusterManager) CheckVClusterOnAllClusters() ([]VClusterClusterStatus, error) {
-	cmd := execCommand("kubectl", "config", "get-contexts", "-o", "name")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	if err := cmd.Run(); err != nil {
+	contexts, err := listKubeconfigContexts()
+	if err != nil {
 		return nil, fmt.Errorf("failed to list contexts: %w", err)
 	}
 
-	contexts := strings.Split(strings.TrimSpace(stdout.String()), "\n")
 	results := make([]VClusterClusterStatus, 0)
 
 	for _, ctx := range contexts {
-		if ctx == "" {
-			continue
-		}
-		status, err := m.CheckVClusterOnCluster(ctx)
+		status, err := m.checkVClusterOnCluster(ctx, contexts)
 		if err != nil {
 			continue
 		}
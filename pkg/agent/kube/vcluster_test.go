@@ -270,6 +270,28 @@ func TestConnectVCluster_Failure(t *testing.T) {
 	}
 }
 
+func TestConnectVClusterOnHost_PassesHostContext(t *testing.T) {
+	oldExecCommand := execCommand
+	defer func() { execCommand = oldExecCommand }()
+
+	var gotArgs []string
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		if name == "vcluster" {
+			gotArgs = arg
+		}
+		return exec.Command("echo", "ok")
+	}
+
+	m := NewLocalClusterManager(nil)
+	if err := m.ConnectVClusterOnHost("dev", "team-a", "prod-east"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "connect dev -n team-a --update-current=false --context prod-east"
+	if got := strings.Join(gotArgs, " "); got != want {
+		t.Fatalf("expected args %q, got %q", want, got)
+	}
+}
+
 // --- DisconnectVCluster ---
 
 func TestDisconnectVCluster_ListFails(t *testing.T) {
@@ -467,6 +489,44 @@ func TestCheckVClusterOnCluster_WithStatefulsets(t *testing.T) {
 	}
 }
 
+func TestCheckVClusterOnCluster_MapsHostAndContext(t *testing.T) {
+	oldExecCommand := execCommand
+	defer func() { execCommand = oldExecCommand }()
+
+	execCommand = func(name string, arg ...string) *exec.Cmd {
+		joined := strings.Join(arg, " ")
+		if strings.Contains(joined, "get statefulset -n vcluster") {
+			return exec.Command("echo", "dev")
+		}
+		if strings.Contains(joined, "get statefulset -A") {
+			return exec.Command("printf", "dev,team-a,1/1\nqa,team-b,0/1\n")
+		}
+		if strings.Contains(joined, "config get-contexts") {
+			return exec.Command("printf", "kind-kind\nvcluster_dev_team-a_kind-kind\n")
+		}
+		return exec.Command("echo", "")
+	}
+
+	m := NewLocalClusterManager(nil)
+	status, err := m.CheckVClusterOnCluster("kind-kind")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(status.VClusters) != 2 {
+		t.Fatalf("expected 2 instances, got %d", len(status.VClusters))
+	}
+	dev, qa := status.VClusters[0], status.VClusters[1]
+	if dev.HostContext != "kind-kind" || qa.HostContext != "kind-kind" {
+		t.Fatalf("expected host context kind-kind, got %q and %q", dev.HostContext, qa.HostContext)
+	}
+	if !dev.Connected || dev.Context != "vcluster_dev_team-a_kind-kind" {
+		t.Fatalf("expected dev to map to its connected context, got %+v", dev)
+	}
+	if qa.Connected || qa.Context != "" {
+		t.Fatalf("expected qa to be unconnected, got %+v", qa)
+	}
+}
+
 // --- CheckVClusterOnAllClusters ---
 
 func TestCheckVClusterOnAllClusters_NoContexts(t *testing.T) {
@@ -0,0 +1,104 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Contract fixtures live in testdata/contract/v<N>/ and are wire messages
+// exactly as a peer speaking version N sends them. They are frozen: a
+// fixture that stops decoding means a released console or agent would
+// break. "<type>.json" carries the message's own payload, ".result.json"
+// the TypeResult reply to a "<type>" request.
+const contractDir = "testdata/contract"
+
+const resultFixtureSuffix = ".result.json"
+
+type wireMessage struct {
+	ID      string          `json:"id"`
+	Type    MessageType     `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func TestContractFixtures(t *testing.T) {
+	versions, err := os.ReadDir(contractDir)
+	require.NoError(t, err)
+
+	covered := map[string]bool{}
+	for _, dir := range versions {
+		version, err := strconv.Atoi(strings.TrimPrefix(dir.Name(), "v"))
+		require.NoError(t, err, "contract dirs are named v<N>: %s", dir.Name())
+		if version < MinSupportedVersion {
+			continue
+		}
+		require.LessOrEqual(t, version, CurrentVersion, "fixtures for an unreleased version")
+
+		files, err := filepath.Glob(filepath.Join(contractDir, dir.Name(), "*.json"))
+		require.NoError(t, err)
+		for _, file := range files {
+			name := dir.Name() + "/" + filepath.Base(file)
+			t.Run(name, func(t *testing.T) {
+				raw, err := os.ReadFile(file)
+				require.NoError(t, err)
+				var msg wireMessage
+				require.NoError(t, json.Unmarshal(raw, &msg))
+
+				base := filepath.Base(file)
+				requestType, isResult := strings.CutSuffix(base, resultFixtureSuffix)
+				if !isResult {
+					requestType = strings.TrimSuffix(base, ".json")
+				}
+				spec, ok := Lookup(MessageType(requestType))
+				require.True(t, ok, "unknown message type %q", requestType)
+				assert.LessOrEqual(t, spec.Since, version, "%s predates its message type", name)
+
+				target := spec.Payload
+				if isResult {
+					assert.Equal(t, TypeResult, msg.Type)
+					target = spec.Result
+				} else {
+					assert.Equal(t, spec.Type, msg.Type)
+				}
+				if target == nil {
+					assert.Empty(t, msg.Payload, "%s has no typed payload", spec.Type)
+					return
+				}
+				covered[reflect.TypeOf(target).Name()] = true
+				assertStrictRoundTrip(t, msg.Payload, reflect.TypeOf(target))
+			})
+		}
+	}
+
+	// Every typed payload must have at least one fixture.
+	for _, m := range Messages {
+		for _, v := range []any{m.Payload, m.Result} {
+			if v != nil {
+				assert.True(t, covered[reflect.TypeOf(v).Name()], "no contract fixture for %s", reflect.TypeOf(v).Name())
+			}
+		}
+	}
+}
+
+// assertStrictRoundTrip decodes raw into a fresh value of typ, rejecting
+// unknown fields, and checks that encoding it again reproduces raw, so no
+// field a peer sends is silently dropped or renamed.
+func assertStrictRoundTrip(t *testing.T, raw json.RawMessage, typ reflect.Type) {
+	t.Helper()
+	value := reflect.New(typ)
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	require.NoError(t, decoder.Decode(value.Interface()), "payload no longer decodes into %s", typ.Name())
+
+	encoded, err := json.Marshal(value.Elem().Interface())
+	require.NoError(t, err)
+	assert.JSONEq(t, string(raw), string(encoded), "%s does not round-trip", typ.Name())
+}
@@ -54,6 +54,11 @@ type HealthPayload struct {
 	Claude             *ClaudeInfo       `json:"claude,omitempty"`
 	InstallMethod      string            `json:"install_method,omitempty"`
 	AvailableProviders []ProviderSummary `json:"availableProviders,omitempty"`
+	// ProtocolVersion and MinProtocolVersion advertise the WebSocket
+	// protocol range so the console can check compatibility before it
+	// connects. Absent on agents that predate the handshake.
+	ProtocolVersion    int `json:"protocolVersion,omitempty"`
+	MinProtocolVersion int `json:"minProtocolVersion,omitempty"`
 }
 
 // ProviderSummary is a lightweight view of a detected AI provider for telemetry
@@ -111,6 +116,9 @@ type KubectlRequest struct {
 	// can enforce dry-run mode: if the session was started with dryRun=true,
 	// mutating commands are rejected at the server level. (#6442)
 	SessionID string `json:"sessionId,omitempty"`
+	// Manifest is passed to kubectl on stdin. Only a server-side dry-run
+	// apply, `apply --dry-run=server -f -`, accepts one.
+	Manifest string `json:"manifest,omitempty"`
 }
 
 // KubectlResponse is the response from kubectl commands
@@ -122,6 +130,105 @@ type KubectlResponse struct {
 	Command              string `json:"command,omitempty"`              // the command requiring confirmation
 }
 
+// KubectlStreamRequest is the payload for kubectl_stream: a kubectl logs
+// command whose output is sent in kubectl_output messages as it arrives,
+// ended by a KubectlStreamResult.
+type KubectlStreamRequest struct {
+	Context   string   `json:"context,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Args      []string `json:"args"` // e.g. ["logs", "web-0", "-c", "web"]
+	// Follow keeps streaming new output until the stream is cancelled or
+	// the container exits, like kubectl logs -f.
+	Follow bool `json:"follow,omitempty"`
+	// Tail is the number of recent lines to start with, like --tail; -1
+	// means all of them.
+	Tail *int64 `json:"tail,omitempty"`
+	// Since only shows output newer than a Go duration such as "10m", like
+	// --since.
+	Since string `json:"since,omitempty"`
+}
+
+// KubectlOutputPayload is one chunk of a kubectl stream's output. Chunks are
+// sent as kubectl writes them and need not end at a line break.
+type KubectlOutputPayload struct {
+	Stream string `json:"stream"` // "stdout" or "stderr"
+	Data   string `json:"data"`
+}
+
+// KubectlStreamResult ends a kubectl stream.
+type KubectlStreamResult struct {
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+	// Cancelled is set when the stream was stopped by cancel_kubectl_stream
+	// rather than ending on its own.
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// CancelKubectlStreamRequest is the payload for stopping a kubectl stream.
+// StreamID is the ID of the kubectl_stream message that started it.
+type CancelKubectlStreamRequest struct {
+	StreamID string `json:"streamId"`
+}
+
+// CancelKubectlStreamResponse is the result sent after a
+// cancel_kubectl_stream request.
+type CancelKubectlStreamResponse struct {
+	Cancelled bool   `json:"cancelled"`
+	StreamID  string `json:"streamId"`
+}
+
+// PortForwardRequest is the payload for port_forward. Exactly one of Pod
+// and Service names the target; a service forwards to one of its running
+// pods, like kubectl port-forward svc/NAME.
+type PortForwardRequest struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod,omitempty"`
+	Service   string `json:"service,omitempty"`
+	// Port is the pod's port, or for a service one of the service's ports.
+	Port int `json:"port"`
+}
+
+// PortForwardResponse is the result of port_forward once the tunnel to the
+// pod is open.
+type PortForwardResponse struct {
+	SessionID string `json:"sessionId"`
+	Pod       string `json:"pod"`
+	Port      int    `json:"port"` // Pod port the session forwards to
+}
+
+// PortForwardDataPayload carries bytes of one tunnelled TCP connection, in
+// port_forward_send to the pod and port_forward_data back from it.
+type PortForwardDataPayload struct {
+	SessionID    string `json:"sessionId"`
+	ConnectionID string `json:"connectionId"`
+	Data         string `json:"data,omitempty"` // Base64-encoded bytes
+	// Close ends the connection after Data. From the console it closes the
+	// pod's side; from the agent it means the pod's side is gone.
+	Close bool `json:"close,omitempty"`
+	// Error says why the agent closed the connection, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// PortForwardClosedPayload reports that a port-forward session ended and
+// all of its connections with it.
+type PortForwardClosedPayload struct {
+	SessionID string `json:"sessionId"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// StopPortForwardRequest is the payload for stopping a port-forward session.
+type StopPortForwardRequest struct {
+	SessionID string `json:"sessionId"`
+}
+
+// StopPortForwardResponse is the result sent after a stop_port_forward
+// request.
+type StopPortForwardResponse struct {
+	Stopped   bool   `json:"stopped"`
+	SessionID string `json:"sessionId"`
+}
+
 // ClaudeRequest is the payload for Claude Code requests
 type ClaudeRequest struct {
 	Prompt    string `json:"prompt"`
@@ -154,6 +261,21 @@ type RenameContextResponse struct {
 	NewName string `json:"newName"`
 }
 
+// RemoveContextRequest is the payload for removing a kubeconfig context
+type RemoveContextRequest struct {
+	Context string `json:"context"`
+}
+
+// RemoveContextResponse is the response from removing a context. Cluster
+// and User name the entries removed with it because no other context used
+// them; they are empty when the entries were kept.
+type RemoveContextResponse struct {
+	Success bool   `json:"success"`
+	Context string `json:"context"`
+	Cluster string `json:"cluster,omitempty"`
+	User    string `json:"user,omitempty"`
+}
+
 // AgentInfo contains information about an AI agent
 type AgentInfo struct {
 	Name         string `json:"name"`
@@ -0,0 +1,310 @@
+package protocol
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Direction says which side sends a message type.
+type Direction string
+
+const (
+	DirectionToAgent   Direction = "console_to_agent"
+	DirectionToConsole Direction = "agent_to_console"
+)
+
+// MessageSpec describes one message type on the wire.
+type MessageSpec struct {
+	Type      MessageType
+	Direction Direction
+	// Since is the protocol version that introduced the type.
+	Since int
+	// Payload is a zero value of the message's payload type, or nil when the
+	// payload is absent or free-form.
+	Payload any
+	// Result is the payload of the TypeResult reply for requests answered
+	// that way, or nil.
+	Result any
+}
+
+// CancelChatRequest is the payload for cancelling an in-progress chat
+type CancelChatRequest struct {
+	SessionID string `json:"sessionId"`
+}
+
+// CancelChatResponse is the result sent after a cancel_chat request
+type CancelChatResponse struct {
+	Cancelled bool   `json:"cancelled"`
+	SessionID string `json:"sessionId"`
+}
+
+// Messages is every message type of the agent WebSocket protocol. Adding a
+// type or changing a payload changes Schema, which the golden contract
+// tests compare against testdata/.
+var Messages = []MessageSpec{
+	// Requests
+	{Type: TypeHealth, Direction: DirectionToAgent, Since: LegacyVersion, Result: HealthPayload{}},
+	{Type: TypeClusters, Direction: DirectionToAgent, Since: LegacyVersion, Result: ClustersPayload{}},
+	{Type: TypeKubectl, Direction: DirectionToAgent, Since: LegacyVersion, Payload: KubectlRequest{}, Result: KubectlResponse{}},
+	{Type: TypeClaude, Direction: DirectionToAgent, Since: LegacyVersion, Payload: ChatRequest{}},
+	{Type: TypeChat, Direction: DirectionToAgent, Since: LegacyVersion, Payload: ChatRequest{}},
+	{Type: TypeListAgents, Direction: DirectionToAgent, Since: LegacyVersion},
+	{Type: TypeSelectAgent, Direction: DirectionToAgent, Since: LegacyVersion, Payload: SelectAgentRequest{}},
+	{Type: TypeCancelChat, Direction: DirectionToAgent, Since: LegacyVersion, Payload: CancelChatRequest{}, Result: CancelChatResponse{}},
+	{Type: TypeRenameContext, Direction: DirectionToAgent, Since: LegacyVersion, Payload: RenameContextRequest{}, Result: RenameContextResponse{}},
+	{Type: TypeHello, Direction: DirectionToAgent, Since: HandshakeVersion, Payload: HelloPayload{}},
+	{Type: TypeKubectlStream, Direction: DirectionToAgent, Since: KubectlStreamVersion, Payload: KubectlStreamRequest{}, Result: KubectlStreamResult{}},
+	{Type: TypeCancelKubectlStream, Direction: DirectionToAgent, Since: KubectlStreamVersion, Payload: CancelKubectlStreamRequest{}, Result: CancelKubectlStreamResponse{}},
+	{Type: TypePortForward, Direction: DirectionToAgent, Since: PortForwardVersion, Payload: PortForwardRequest{}, Result: PortForwardResponse{}},
+	{Type: TypePortForwardSend, Direction: DirectionToAgent, Since: PortForwardVersion, Payload: PortForwardDataPayload{}},
+	{Type: TypeStopPortForward, Direction: DirectionToAgent, Since: PortForwardVersion, Payload: StopPortForwardRequest{}, Result: StopPortForwardResponse{}},
+	{Type: TypeRemoveContext, Direction: DirectionToAgent, Since: RemoveContextVersion, Payload: RemoveContextRequest{}, Result: RemoveContextResponse{}},
+
+	// Responses and events
+	{Type: TypeResult, Direction: DirectionToConsole, Since: LegacyVersion},
+	{Type: TypeError, Direction: DirectionToConsole, Since: LegacyVersion, Payload: ErrorPayload{}},
+	{Type: TypeStream, Direction: DirectionToConsole, Since: LegacyVersion, Payload: ChatStreamPayload{}},
+	{Type: TypeStreamChunk, Direction: DirectionToConsole, Since: LegacyVersion},
+	{Type: TypeStreamEnd, Direction: DirectionToConsole, Since: LegacyVersion},
+	{Type: TypeProgress, Direction: DirectionToConsole, Since: LegacyVersion, Payload: ProgressPayload{}},
+	{Type: TypeAgentSelected, Direction: DirectionToConsole, Since: LegacyVersion, Payload: AgentSelectedPayload{}},
+	{Type: TypeAgentsList, Direction: DirectionToConsole, Since: LegacyVersion, Payload: AgentsListPayload{}},
+	{Type: TypeMixedModeThinking, Direction: DirectionToConsole, Since: LegacyVersion},
+	{Type: TypeMixedModeExecuting, Direction: DirectionToConsole, Since: LegacyVersion},
+	{Type: TypeStateDigest, Direction: DirectionToConsole, Since: LegacyVersion, Payload: StateDigestPayload{}},
+	{Type: TypeHelloAck, Direction: DirectionToConsole, Since: HandshakeVersion, Payload: HelloAckPayload{}},
+	{Type: TypeKubectlOutput, Direction: DirectionToConsole, Since: KubectlStreamVersion, Payload: KubectlOutputPayload{}},
+	{Type: TypePortForwardData, Direction: DirectionToConsole, Since: PortForwardVersion, Payload: PortForwardDataPayload{}},
+	{Type: TypePortForwardClosed, Direction: DirectionToConsole, Since: PortForwardVersion, Payload: PortForwardClosedPayload{}},
+}
+
+// ProtocolSchema is the serialized form of the protocol: every message type
+// and the shape of every payload, as JSON sees it.
+type ProtocolSchema struct {
+	Version    int                    `json:"version"`
+	MinVersion int                    `json:"minVersion"`
+	Messages   []MessageSchema        `json:"messages"`
+	Types      map[string][]FieldSpec `json:"types"`
+}
+
+// MessageSchema is one message type in a ProtocolSchema. Payload and Result
+// name entries in ProtocolSchema.Types.
+type MessageSchema struct {
+	Type      MessageType `json:"type"`
+	Direction Direction   `json:"direction"`
+	Since     int         `json:"since"`
+	Payload   string      `json:"payload,omitempty"`
+	Result    string      `json:"result,omitempty"`
+}
+
+// FieldSpec is one JSON field of a payload type. Type is "string",
+// "integer", "number", "boolean", "any", "[]T", "map[string]T" or the name
+// of another entry in ProtocolSchema.Types.
+type FieldSpec struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Schema builds the ProtocolSchema for Messages.
+func Schema() ProtocolSchema {
+	s := ProtocolSchema{
+		Version:    CurrentVersion,
+		MinVersion: MinSupportedVersion,
+		Types:      map[string][]FieldSpec{},
+	}
+	for _, m := range Messages {
+		s.Messages = append(s.Messages, MessageSchema{
+			Type:      m.Type,
+			Direction: m.Direction,
+			Since:     m.Since,
+			Payload:   s.typeName(m.Payload),
+			Result:    s.typeName(m.Result),
+		})
+	}
+	sort.Slice(s.Messages, func(i, j int) bool { return s.Messages[i].Type < s.Messages[j].Type })
+	return s
+}
+
+// typeName registers v's struct type (and any it references) and returns
+// its name, or "" for nil.
+func (s *ProtocolSchema) typeName(v any) string {
+	if v == nil {
+		return ""
+	}
+	return s.describe(reflect.TypeOf(v))
+}
+
+func (s *ProtocolSchema) describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.describe(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "[]" + s.describe(t.Elem())
+	case reflect.Map:
+		return "map[" + s.describe(t.Key()) + "]" + s.describe(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if _, seen := s.Types[name]; seen {
+			return name
+		}
+		// Reserve the name before recursing so self-references terminate.
+		s.Types[name] = nil
+		s.Types[name] = s.fields(t)
+		return name
+	default:
+		return "any"
+	}
+}
+
+func (s *ProtocolSchema) fields(t reflect.Type) []FieldSpec {
+	fields := []FieldSpec{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, FieldSpec{
+			Name:     name,
+			Type:     s.describe(f.Type),
+			Optional: strings.Contains(","+opts+",", ",omitempty,") || f.Type.Kind() == reflect.Pointer,
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// BreakingChanges lists every change from old to current that a peer built
+// against old would notice: removed message types, changed directions or
+// payload types, and removed, retyped or newly required fields. Additions
+// of optional fields and new message types are compatible.
+func BreakingChanges(old, current ProtocolSchema) []string {
+	var problems []string
+	currentMessages := make(map[MessageType]MessageSchema, len(current.Messages))
+	for _, m := range current.Messages {
+		currentMessages[m.Type] = m
+	}
+	for _, was := range old.Messages {
+		now, ok := currentMessages[was.Type]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("message %q was removed", was.Type))
+			continue
+		}
+		if now.Direction != was.Direction {
+			problems = append(problems, fmt.Sprintf("message %q changed direction from %s to %s", was.Type, was.Direction, now.Direction))
+		}
+		problems = append(problems, compareRef(old, current, fmt.Sprintf("message %q payload", was.Type), was.Payload, now.Payload)...)
+		problems = append(problems, compareRef(old, current, fmt.Sprintf("message %q result", was.Type), was.Result, now.Result)...)
+	}
+	if current.MinVersion > old.Version {
+		problems = append(problems, fmt.Sprintf("minimum version %d drops support for version %d", current.MinVersion, old.Version))
+	}
+	return problems
+}
+
+// compareRef compares two type references. Named types are compared field
+// by field, so renaming a Go type without changing its JSON is compatible.
+func compareRef(old, current ProtocolSchema, where, was, now string) []string {
+	return compareTypes(old, current, where, was, now, map[string]bool{})
+}
+
+func compareTypes(old, current ProtocolSchema, where, was, now string, visited map[string]bool) []string {
+	// A payload that used to be free-form or absent can gain a type.
+	if was == "" || was == "any" {
+		return nil
+	}
+	if now == "" {
+		return []string{fmt.Sprintf("%s was removed", where)}
+	}
+	if wasElem, ok := strings.CutPrefix(was, "[]"); ok {
+		nowElem, ok := strings.CutPrefix(now, "[]")
+		if !ok {
+			return []string{fmt.Sprintf("%s changed type from %s to %s", where, was, now)}
+		}
+		return compareTypes(old, current, where+"[]", wasElem, nowElem, visited)
+	}
+	if strings.HasPrefix(was, "map[") {
+		wasKey, wasElem, _ := strings.Cut(strings.TrimPrefix(was, "map["), "]")
+		if !strings.HasPrefix(now, "map[") {
+			return []string{fmt.Sprintf("%s changed type from %s to %s", where, was, now)}
+		}
+		nowKey, nowElem, _ := strings.Cut(strings.TrimPrefix(now, "map["), "]")
+		if wasKey != nowKey {
+			return []string{fmt.Sprintf("%s changed key type from %s to %s", where, wasKey, nowKey)}
+		}
+		return compareTypes(old, current, where+"{}", wasElem, nowElem, visited)
+	}
+	if !isNamed(old, was) {
+		if was != now {
+			return []string{fmt.Sprintf("%s changed type from %s to %s", where, was, now)}
+		}
+		return nil
+	}
+	if !isNamed(current, now) {
+		return []string{fmt.Sprintf("%s changed type from %s to %s", where, was, now)}
+	}
+	key := was + "->" + now
+	if visited[key] {
+		return nil
+	}
+	visited[key] = true
+
+	var problems []string
+	nowFields := make(map[string]FieldSpec, len(current.Types[now]))
+	for _, f := range current.Types[now] {
+		nowFields[f.Name] = f
+	}
+	wasFields := make(map[string]bool, len(old.Types[was]))
+	for _, f := range old.Types[was] {
+		wasFields[f.Name] = true
+		field := where + "." + f.Name
+		nf, ok := nowFields[f.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s was removed", field))
+			continue
+		}
+		if f.Optional && !nf.Optional {
+			problems = append(problems, fmt.Sprintf("%s became required", field))
+		}
+		problems = append(problems, compareTypes(old, current, field, f.Type, nf.Type, visited)...)
+	}
+	for _, nf := range current.Types[now] {
+		if !wasFields[nf.Name] && !nf.Optional {
+			problems = append(problems, fmt.Sprintf("%s.%s was added as a required field", where, nf.Name))
+		}
+	}
+	return problems
+}
+
+func isNamed(s ProtocolSchema, ref string) bool {
+	_, ok := s.Types[ref]
+	return ok
+}
+
+// Lookup returns the spec for a message type.
+func Lookup(t MessageType) (MessageSpec, bool) {
+	for _, m := range Messages {
+		if m.Type == t {
+			return m, true
+		}
+	}
+	return MessageSpec{}, false
+}
@@ -0,0 +1,196 @@
+package protocol
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites testdata/schema.json from the current types:
+//
+//	go test ./pkg/agent/protocol -run TestSchemaGolden -update
+//
+// Frozen snapshots (schema_v<N>.json) are never rewritten.
+var update = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+const (
+	currentSchemaGolden = "schema.json"
+	frozenSchemaPattern = "schema_v*.json"
+)
+
+func marshalSchema(t *testing.T, s ProtocolSchema) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(s, "", "  ")
+	require.NoError(t, err)
+	return append(data, '\n')
+}
+
+func loadSchema(t *testing.T, path string) ProtocolSchema {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var s ProtocolSchema
+	require.NoError(t, json.Unmarshal(data, &s))
+	return s
+}
+
+// TestSchemaGolden fails on any wire change so it shows up in review. If the
+// change is intended, rerun with -update; TestSchemaBackwardCompatible then
+// decides whether it also needs a version bump.
+func TestSchemaGolden(t *testing.T) {
+	path := filepath.Join("testdata", currentSchemaGolden)
+	got := marshalSchema(t, Schema())
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got),
+		"protocol schema changed; rerun with -update if the change is intended")
+}
+
+// TestSchemaBackwardCompatible checks the current schema against every
+// frozen snapshot of a still-supported version. A failure here means an
+// older console or agent would break: restore the field or type, or raise
+// MinSupportedVersion deliberately and delete the snapshot.
+func TestSchemaBackwardCompatible(t *testing.T) {
+	snapshots, err := filepath.Glob(filepath.Join("testdata", frozenSchemaPattern))
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshots, "at least the legacy protocol snapshot must exist")
+
+	current := Schema()
+	for _, path := range snapshots {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			old := loadSchema(t, path)
+			if old.Version < MinSupportedVersion {
+				t.Skipf("version %d is no longer supported", old.Version)
+			}
+			assert.Empty(t, BreakingChanges(old, current))
+			assert.LessOrEqual(t, old.Version, current.Version)
+		})
+	}
+}
+
+func TestSchemaSnapshotForCurrentVersion(t *testing.T) {
+	// The newest version needs its own frozen snapshot once it ships (copy
+	// schema.json to schema_v<CurrentVersion>.json) so the next bump is
+	// checked against it.
+	path := filepath.Join("testdata", "schema_v"+strconv.Itoa(CurrentVersion)+".json")
+	_, err := os.Stat(path)
+	assert.NoError(t, err, "missing frozen snapshot %s", path)
+}
+
+func TestMessages_CoverEveryType(t *testing.T) {
+	all := []MessageType{
+		TypeHealth, TypeClusters, TypeKubectl, TypeClaude, TypeChat, TypeListAgents,
+		TypeSelectAgent, TypeCancelChat, TypeRenameContext, TypeHello,
+		TypeResult, TypeError, TypeStream, TypeStreamChunk, TypeStreamEnd, TypeProgress,
+		TypeAgentSelected, TypeAgentsList, TypeMixedModeThinking, TypeMixedModeExecuting,
+		TypeStateDigest, TypeHelloAck, TypeKubectlStream, TypeCancelKubectlStream, TypeKubectlOutput,
+		TypePortForward, TypePortForwardSend, TypeStopPortForward, TypePortForwardData, TypePortForwardClosed,
+		TypeRemoveContext,
+	}
+	seen := map[MessageType]bool{}
+	for _, m := range Messages {
+		assert.False(t, seen[m.Type], "duplicate spec for %q", m.Type)
+		seen[m.Type] = true
+		assert.GreaterOrEqual(t, m.Since, LegacyVersion, m.Type)
+		assert.LessOrEqual(t, m.Since, CurrentVersion, m.Type)
+	}
+	for _, typ := range all {
+		_, ok := Lookup(typ)
+		assert.True(t, ok, "message type %q has no MessageSpec", typ)
+	}
+	assert.Len(t, Messages, len(all))
+}
+
+func TestSchema_DescribesPayloads(t *testing.T) {
+	s := Schema()
+	fields := map[string]FieldSpec{}
+	for _, f := range s.Types["ChatRequest"] {
+		fields[f.Name] = f
+	}
+	assert.Equal(t, FieldSpec{Name: "prompt", Type: "string"}, fields["prompt"])
+	assert.Equal(t, FieldSpec{Name: "history", Type: "[]ChatMessage", Optional: true}, fields["history"])
+	assert.Contains(t, s.Types, "ChatMessage", "referenced types are included")
+
+	for _, f := range s.Types["ChatStreamPayload"] {
+		if f.Name == "usage" {
+			assert.Equal(t, "ChatTokenUsage", f.Type)
+			assert.True(t, f.Optional)
+		}
+	}
+	for _, f := range s.Types["StateDigestPayload"] {
+		if f.Name == "versions" {
+			assert.Equal(t, "map[string]string", f.Type)
+		}
+	}
+	assert.True(t, sort.SliceIsSorted(s.Messages, func(i, j int) bool { return s.Messages[i].Type < s.Messages[j].Type }))
+}
+
+func TestBreakingChanges(t *testing.T) {
+	base := func() ProtocolSchema {
+		return ProtocolSchema{
+			Version: 1, MinVersion: 1,
+			Messages: []MessageSchema{
+				{Type: "ping", Direction: DirectionToAgent, Since: 1, Payload: "Ping", Result: "Pong"},
+			},
+			Types: map[string][]FieldSpec{
+				"Ping": {{Name: "id", Type: "string"}, {Name: "tags", Type: "[]Tag", Optional: true}},
+				"Tag":  {{Name: "key", Type: "string"}},
+				"Pong": {{Name: "ok", Type: "boolean"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *ProtocolSchema)
+		want   string
+	}{
+		{name: "no change", mutate: func(*ProtocolSchema) {}},
+		{name: "optional field added", mutate: func(s *ProtocolSchema) {
+			s.Types["Ping"] = append(s.Types["Ping"], FieldSpec{Name: "extra", Type: "integer", Optional: true})
+		}},
+		{name: "new message type", mutate: func(s *ProtocolSchema) {
+			s.Messages = append(s.Messages, MessageSchema{Type: "pong2", Direction: DirectionToConsole, Since: 2})
+		}},
+		{name: "go type renamed with same JSON", mutate: func(s *ProtocolSchema) {
+			s.Types["PingV2"] = s.Types["Ping"]
+			delete(s.Types, "Ping")
+			s.Messages[0].Payload = "PingV2"
+		}},
+		{name: "message removed", mutate: func(s *ProtocolSchema) { s.Messages = nil }, want: `message "ping" was removed`},
+		{name: "direction changed", mutate: func(s *ProtocolSchema) { s.Messages[0].Direction = DirectionToConsole }, want: "changed direction"},
+		{name: "field removed", mutate: func(s *ProtocolSchema) { s.Types["Pong"] = nil }, want: `message "ping" result.ok was removed`},
+		{name: "field retyped", mutate: func(s *ProtocolSchema) { s.Types["Ping"][0].Type = "integer" }, want: "payload.id changed type from string to integer"},
+		{name: "nested field removed", mutate: func(s *ProtocolSchema) { s.Types["Tag"] = nil }, want: "payload.tags[].key was removed"},
+		{name: "optional became required", mutate: func(s *ProtocolSchema) { s.Types["Ping"][1].Optional = false }, want: "payload.tags became required"},
+		{name: "required field added", mutate: func(s *ProtocolSchema) {
+			s.Types["Pong"] = append(s.Types["Pong"], FieldSpec{Name: "code", Type: "integer"})
+		}, want: "result.code was added as a required field"},
+		{name: "payload removed", mutate: func(s *ProtocolSchema) { s.Messages[0].Payload = "" }, want: "payload was removed"},
+		{name: "min version raised", mutate: func(s *ProtocolSchema) { s.MinVersion = 2 }, want: "drops support for version 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := base()
+			tt.mutate(&current)
+			problems := BreakingChanges(base(), current)
+			if tt.want == "" {
+				assert.Empty(t, problems)
+				return
+			}
+			require.NotEmpty(t, problems)
+			assert.Contains(t, strings.Join(problems, "\n"), tt.want)
+		})
+	}
+}
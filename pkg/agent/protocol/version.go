@@ -0,0 +1,123 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Protocol versions. Bump CurrentVersion when a message type or payload
+// change needs negotiation, keep the older version in the supported range
+// for as long as released consoles still speak it, and record the new
+// schema with `go test ./pkg/agent/protocol -update`.
+const (
+	// LegacyVersion is what a peer that never sends hello speaks: the
+	// message set that shipped before the handshake existed.
+	LegacyVersion = 1
+	// HandshakeVersion added the hello/hello_ack handshake and the protocol
+	// range in HealthPayload.
+	HandshakeVersion = 2
+	// KubectlStreamVersion added kubectl_stream, which streams kubectl logs
+	// output as it arrives, and cancel_kubectl_stream.
+	KubectlStreamVersion = 3
+	// PortForwardVersion added port_forward, which tunnels TCP connections
+	// to a pod port over the WebSocket, and the messages carrying them.
+	PortForwardVersion = 4
+	// RemoveContextVersion added remove_context, which deletes a kubeconfig
+	// context and the cluster and user entries only it used.
+	RemoveContextVersion = 5
+	// CurrentVersion is the newest version this build speaks.
+	CurrentVersion = RemoveContextVersion
+	// MinSupportedVersion is the oldest version this build still accepts.
+	MinSupportedVersion = LegacyVersion
+)
+
+// Handshake message types. The console sends hello as its first message and
+// the agent replies with hello_ack. A console that gets an unknown_type error
+// back is talking to a pre-handshake agent and must stay on LegacyVersion.
+const (
+	TypeHello    MessageType = "hello"
+	TypeHelloAck MessageType = "hello_ack"
+)
+
+// Kubectl stream message types. The console sends kubectl_stream; the agent
+// answers with kubectl_output messages carrying the same ID, then a result
+// with a KubectlStreamResult. cancel_kubectl_stream stops a stream early.
+const (
+	TypeKubectlStream       MessageType = "kubectl_stream"
+	TypeCancelKubectlStream MessageType = "cancel_kubectl_stream"
+	TypeKubectlOutput       MessageType = "kubectl_output"
+)
+
+// Port-forward message types. The console sends port_forward to open a
+// session to a pod or service port; its result carries the session ID, the
+// message's own ID. Every TCP connection the console tunnels gets a
+// connection ID of the console's choosing: port_forward_send carries its
+// bytes to the pod, the first one opening it, and port_forward_data the
+// pod's bytes back. Either side ends a connection with close set.
+// port_forward_closed reports that a whole session ended, and
+// stop_port_forward ends one early.
+const (
+	TypePortForward       MessageType = "port_forward"
+	TypePortForwardSend   MessageType = "port_forward_send"
+	TypeStopPortForward   MessageType = "stop_port_forward"
+	TypePortForwardData   MessageType = "port_forward_data"
+	TypePortForwardClosed MessageType = "port_forward_closed"
+)
+
+// TypeRemoveContext removes a kubeconfig context. Its result is a
+// RemoveContextResponse.
+const TypeRemoveContext MessageType = "remove_context"
+
+// ErrorCodeIncompatibleProtocol is the ErrorPayload code sent when the two
+// version ranges do not overlap.
+const ErrorCodeIncompatibleProtocol = "incompatible_protocol"
+
+// ErrIncompatibleVersion is returned by Negotiate when no common version exists.
+var ErrIncompatibleVersion = errors.New("no common protocol version")
+
+// HelloPayload opens the handshake and advertises the client's version range.
+type HelloPayload struct {
+	ProtocolVersion    int    `json:"protocolVersion"`              // Newest version the client speaks
+	MinProtocolVersion int    `json:"minProtocolVersion,omitempty"` // Oldest version the client accepts; defaults to ProtocolVersion
+	Client             string `json:"client,omitempty"`             // e.g. "console-web"
+	ClientVersion      string `json:"clientVersion,omitempty"`
+}
+
+// HelloAckPayload is the agent's answer with the negotiated version.
+type HelloAckPayload struct {
+	ProtocolVersion    int    `json:"protocolVersion"`    // Negotiated version both sides use from now on
+	MinProtocolVersion int    `json:"minProtocolVersion"` // Oldest version the agent accepts
+	MaxProtocolVersion int    `json:"maxProtocolVersion"` // Newest version the agent speaks
+	AgentVersion       string `json:"agentVersion"`
+}
+
+// Negotiate picks the highest version inside both the client's and the
+// server's ranges. A zero or negative clientMin means "only clientMax".
+func Negotiate(clientMin, clientMax, serverMin, serverMax int) (int, error) {
+	if clientMax <= 0 {
+		return 0, fmt.Errorf("%w: client sent protocol version %d", ErrIncompatibleVersion, clientMax)
+	}
+	if clientMin <= 0 || clientMin > clientMax {
+		clientMin = clientMax
+	}
+	version := min(clientMax, serverMax)
+	if version < max(clientMin, serverMin) {
+		return 0, fmt.Errorf("%w: client supports %d-%d, server supports %d-%d",
+			ErrIncompatibleVersion, clientMin, clientMax, serverMin, serverMax)
+	}
+	return version, nil
+}
+
+// NegotiateHello answers a hello with this build's supported range.
+func NegotiateHello(hello HelloPayload, agentVersion string) (HelloAckPayload, error) {
+	version, err := Negotiate(hello.MinProtocolVersion, hello.ProtocolVersion, MinSupportedVersion, CurrentVersion)
+	if err != nil {
+		return HelloAckPayload{}, err
+	}
+	return HelloAckPayload{
+		ProtocolVersion:    version,
+		MinProtocolVersion: MinSupportedVersion,
+		MaxProtocolVersion: CurrentVersion,
+		AgentVersion:       agentVersion,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name                 string
+		clientMin, clientMax int
+		serverMin, serverMax int
+		want                 int
+		wantErr              bool
+	}{
+		{name: "same range", clientMin: 1, clientMax: 2, serverMin: 1, serverMax: 2, want: 2},
+		{name: "newer client falls back to server max", clientMin: 1, clientMax: 5, serverMin: 1, serverMax: 2, want: 2},
+		{name: "newer server falls back to client max", clientMin: 1, clientMax: 2, serverMin: 1, serverMax: 4, want: 2},
+		{name: "unset client min means exactly client max", clientMax: 2, serverMin: 1, serverMax: 3, want: 2},
+		{name: "client min above server max", clientMin: 3, clientMax: 4, serverMin: 1, serverMax: 2, wantErr: true},
+		{name: "client max below server min", clientMin: 1, clientMax: 1, serverMin: 2, serverMax: 3, wantErr: true},
+		{name: "non-positive client version", clientMax: 0, serverMin: 1, serverMax: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Negotiate(tt.clientMin, tt.clientMax, tt.serverMin, tt.serverMax)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrIncompatibleVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNegotiateHello(t *testing.T) {
+	ack, err := NegotiateHello(HelloPayload{ProtocolVersion: CurrentVersion + 1, MinProtocolVersion: LegacyVersion}, "1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, HelloAckPayload{
+		ProtocolVersion:    CurrentVersion,
+		MinProtocolVersion: MinSupportedVersion,
+		MaxProtocolVersion: CurrentVersion,
+		AgentVersion:       "1.2.3",
+	}, ack)
+
+	ack, err = NegotiateHello(HelloPayload{ProtocolVersion: LegacyVersion}, "1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, LegacyVersion, ack.ProtocolVersion, "a legacy-only client is still served")
+
+	_, err = NegotiateHello(HelloPayload{ProtocolVersion: CurrentVersion + 2, MinProtocolVersion: CurrentVersion + 1}, "1.2.3")
+	assert.ErrorIs(t, err, ErrIncompatibleVersion)
+}
@@ -3,6 +3,7 @@ package providers
 import (
 	"github.com/kubestellar/console/pkg/agent/prompts"
 	"github.com/kubestellar/console/pkg/ai"
+	"github.com/kubestellar/console/pkg/egress"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -124,12 +125,12 @@ func newRestrictedAIProviderHTTPTransport() *http.Transport {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	dialer := &net.Dialer{Timeout: aiProviderDialTimeout}
 
-	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+	transport.DialContext = egress.Dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
 		return dialAIProviderContext(ctx, dialer, network, addr)
-	}
-	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+	})
+	transport.DialTLSContext = egress.Dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
 		return dialAIProviderTLSContext(ctx, dialer, network, addr, transport.TLSClientConfig)
-	}
+	})
 
 	return transport
 }
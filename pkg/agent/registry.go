@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/kubestellar/console/pkg/ai"
+	"github.com/kubestellar/console/pkg/egress"
 )
 
 // Registry manages available AI providers
@@ -315,11 +316,23 @@ func (r *Registry) HasAvailableProviders() bool {
 func InitializeProviders() error {
 	registry := GetRegistry()
 
+	// registerCloud registers a provider whose model runs at a vendor's
+	// hosted service. Air-gapped mode (see pkg/egress) leaves those out; the
+	// in-cluster and local runners stay, reachable at in-network hosts only.
+	var skipped []string
+	registerCloud := func(p ai.Provider) {
+		if egress.AirGapped() {
+			skipped = append(skipped, p.Name())
+			return
+		}
+		registry.Register(p)
+	}
+
 	// Register tool-capable agents FIRST so they become the default.
 	// Tool-capable agents can execute kubectl, helm, and other commands.
 	// Order matters: the first available agent becomes the default.
-	registry.Register(providers.NewClaudeCodeProvider())
-	registry.Register(providers.NewBobProvider())
+	registerCloud(providers.NewClaudeCodeProvider())
+	registerCloud(providers.NewBobProvider())
 
 	// Register in-cluster Kagenti agent (preferred when in-cluster)
 	if p := providers.NewKagentiProvider(); p != nil {
@@ -327,15 +340,15 @@ func InitializeProviders() error {
 	}
 
 	// Register CLI-based tool-capable agents
-	registry.Register(providers.NewCodexProvider())
-	registry.Register(providers.NewGeminiCLIProvider())
-	registry.Register(providers.NewAntigravityProvider())
-	registry.Register(providers.NewGooseProvider())
+	registerCloud(providers.NewCodexProvider())
+	registerCloud(providers.NewGeminiCLIProvider())
+	registerCloud(providers.NewAntigravityProvider())
+	registerCloud(providers.NewGooseProvider())
 
 	// Register copilot-cli LAST among tool-capable agents.
 	// copilot-cli suggests commands as text rather than executing them,
 	// so it should only be the default when no other agent is available (#3609).
-	registry.Register(providers.NewCopilotCLIProvider())
+	registerCloud(providers.NewCopilotCLIProvider())
 
 	// Register chat-only local LLM providers AFTER the tool-capable CLI agents.
 	// Rationale: missions need to execute cluster commands, so they must route
@@ -364,8 +377,8 @@ func InitializeProviders() error {
 	// registering them is safe and lets operators pick a remote
 	// OpenAI-compatible endpoint from the dropdown (Groq LPU, OpenRouter
 	// gateway, or a self-hosted Open WebUI behind their own model).
-	registry.Register(providers.NewGroqProvider())
-	registry.Register(providers.NewOpenRouterProvider())
+	registerCloud(providers.NewGroqProvider())
+	registerCloud(providers.NewOpenRouterProvider())
 	registry.Register(providers.NewOpenWebUIProvider())
 
 	// NOTE: API-only vendor agents (Claude API, OpenAI direct, Gemini API) and
@@ -376,6 +389,10 @@ func InitializeProviders() error {
 	// providers above offer. Only CLI-based tool-capable agents and
 	// operator-controlled OpenAI-compatible HTTP endpoints are registered.
 
+	if len(skipped) > 0 {
+		slog.Info("[Registry] air-gapped: cloud AI providers not registered", "providers", skipped)
+	}
+
 	// Set default agent based on environment or availability
 	if defaultAgent := os.Getenv("DEFAULT_AGENT"); defaultAgent != "" {
 		if err := registry.SetDefault(defaultAgent); err != nil {
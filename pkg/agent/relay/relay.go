@@ -0,0 +1,292 @@
+// Package relay connects kc-agent to a hosted console over the mutual-TLS
+// relay channel.
+//
+// On first start the agent pairs with an admin-issued code: it generates its
+// own key, trades the code and a CSR for a client certificate, and pins the
+// console's agent CA. The credentials are kept on disk, presented on every
+// relay request and rotated over the relay before the certificate expires.
+package relay
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agentpki"
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/fileutil"
+)
+
+const (
+	// credentialsFile holds the key, certificate and pinned CA in one file so
+	// a rotation replaces them together.
+	credentialsFile = "credentials.json"
+	// rotationCheckInterval is how often Run checks whether the certificate
+	// is due for rotation.
+	rotationCheckInterval = time.Hour
+	// requestTimeout bounds pairing, rotation and relayed requests.
+	requestTimeout = 30 * time.Second
+	// maxErrorBody bounds how much of an error response is read.
+	maxErrorBody = 4096
+)
+
+// ErrNotPaired is returned by Open when no credentials are stored and no
+// pairing code was given.
+var ErrNotPaired = errors.New("relay: agent is not paired; set KC_PAIRING_CODE")
+
+// Config configures the relay client.
+type Config struct {
+	// URL is the relay listener, e.g. https://console.example.com:8443.
+	URL string
+	// ConsoleURL is the console the agent pairs through, e.g.
+	// https://console.example.com.
+	ConsoleURL string
+	// AgentID is the ID the pairing code was issued for.
+	AgentID string
+	// PairingCode is only needed until the agent holds a certificate.
+	PairingCode string
+	// Dir holds the agent's relay credentials.
+	Dir string
+}
+
+// credentials is the on-disk form of the agent's relay identity.
+type credentials struct {
+	Certificate string `json:"certificate"`
+	Key         string `json:"key"`
+	CA          string `json:"ca"`
+}
+
+// certificateResponse is what the console returns from pairing and rotation.
+type certificateResponse struct {
+	AgentID     string `json:"agentId"`
+	Certificate string `json:"certificate"`
+	CA          string `json:"ca"`
+}
+
+// Client sends requests over the relay with the agent's client certificate.
+type Client struct {
+	cfg        Config
+	serverName string
+	now        func() time.Time
+
+	mu   sync.RWMutex
+	cert *x509.Certificate
+	http *http.Client
+}
+
+// DefaultDir is where kc-agent keeps its relay credentials.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, ".kc", "relay")
+}
+
+// Open loads the stored credentials from cfg.Dir, pairing first when there
+// are none.
+func Open(ctx context.Context, cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return nil, fmt.Errorf("relay: URL must be an https URL, got %q", cfg.URL)
+	}
+	c := &Client{cfg: cfg, serverName: u.Hostname(), now: time.Now}
+
+	creds, err := c.load()
+	if errors.Is(err, os.ErrNotExist) {
+		if cfg.PairingCode == "" {
+			return nil, ErrNotPaired
+		}
+		creds, err = c.pair(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.use(creds); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AgentID is the ID in the agent's current certificate.
+func (c *Client) AgentID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert.Subject.CommonName
+}
+
+// URL returns the relay URL of path, e.g. "/relay/events".
+func (c *Client) URL(path string) string {
+	return strings.TrimSuffix(c.cfg.URL, "/") + path
+}
+
+// Do sends req over the relay, presenting the current certificate.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	c.mu.RLock()
+	client := c.http
+	c.mu.RUnlock()
+	return client.Do(req)
+}
+
+// Run rotates the certificate when it is due until stop is closed.
+func (c *Client) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(rotationCheckInterval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		if err := c.RotateIfDue(ctx); err != nil {
+			slog.Warn("[Relay] certificate rotation failed", "error", err)
+		}
+		cancel()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RotateIfDue replaces the certificate and key once agentpki.NeedsRotation
+// reports the certificate due. The relay identifies the agent by the
+// certificate it presents, so no pairing code is needed.
+func (c *Client) RotateIfDue(ctx context.Context) error {
+	c.mu.RLock()
+	due := agentpki.NeedsRotation(c.cert, c.now())
+	c.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	csrPEM, keyPEM, err := agentpki.NewCSR(c.AgentID())
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, c.URL("/relay/rotate"), map[string]string{"csr": string(csrPEM)}, c.Do)
+	if err != nil {
+		return fmt.Errorf("relay: rotate: %w", err)
+	}
+	creds := credentials{Certificate: resp.Certificate, Key: string(keyPEM), CA: resp.CA}
+	if err := c.use(creds); err != nil {
+		return err
+	}
+	if err := c.save(creds); err != nil {
+		return err
+	}
+	slog.Info("[Relay] rotated agent certificate", "agentId", resp.AgentID)
+	return nil
+}
+
+// pair trades the pairing code and a fresh CSR for a certificate and stores
+// the result. The private key never leaves the agent.
+func (c *Client) pair(ctx context.Context) (credentials, error) {
+	if c.cfg.ConsoleURL == "" {
+		return credentials{}, errors.New("relay: a console URL is required for pairing")
+	}
+	csrPEM, keyPEM, err := agentpki.NewCSR(c.cfg.AgentID)
+	if err != nil {
+		return credentials{}, err
+	}
+	client := &http.Client{
+		Timeout:   requestTimeout,
+		Transport: &http.Transport{DialContext: egress.Dial(nil)},
+	}
+	resp, err := c.post(ctx, strings.TrimSuffix(c.cfg.ConsoleURL, "/")+"/api/agents/pair", map[string]string{
+		"agentId": c.cfg.AgentID,
+		"code":    c.cfg.PairingCode,
+		"csr":     string(csrPEM),
+	}, client.Do)
+	if err != nil {
+		return credentials{}, fmt.Errorf("relay: pair: %w", err)
+	}
+	creds := credentials{Certificate: resp.Certificate, Key: string(keyPEM), CA: resp.CA}
+	if err := c.save(creds); err != nil {
+		return credentials{}, err
+	}
+	slog.Info("[Relay] paired with console", "agentId", resp.AgentID)
+	return creds, nil
+}
+
+// post sends body as JSON with do and decodes the certificate response.
+func (c *Client) post(ctx context.Context, target string, body interface{}, do func(*http.Request) (*http.Response, error)) (certificateResponse, error) {
+	var out certificateResponse
+	data, err := json.Marshal(body)
+	if err != nil {
+		return out, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return out, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := do(req)
+	if err != nil {
+		return out, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return out, fmt.Errorf("console returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return out, fmt.Errorf("decode response: %w", err)
+	}
+	return out, nil
+}
+
+// use switches the client to creds.
+func (c *Client) use(creds credentials) error {
+	cert, err := agentpki.ParseCertPEM([]byte(creds.Certificate))
+	if err != nil {
+		return fmt.Errorf("relay: parse agent certificate: %w", err)
+	}
+	tlsConfig, err := agentpki.ClientTLSConfig([]byte(creds.Certificate), []byte(creds.Key), []byte(creds.CA), c.serverName)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			DialContext:     egress.Dial(nil),
+		},
+	}
+	c.mu.Lock()
+	c.cert, c.http = cert, client
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Client) load() (credentials, error) {
+	var creds credentials
+	data, err := os.ReadFile(filepath.Join(c.cfg.Dir, credentialsFile))
+	if err != nil {
+		return creds, err
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return creds, fmt.Errorf("relay: parse credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func (c *Client) save(creds credentials) error {
+	if err := os.MkdirAll(c.cfg.Dir, 0700); err != nil {
+		return fmt.Errorf("relay: create credentials dir: %w", err)
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return fileutil.AtomicWriteFile(filepath.Join(c.cfg.Dir, credentialsFile), data, 0600)
+}
@@ -0,0 +1,174 @@
+package relay
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agentpki"
+)
+
+const testPairingCode = "pairing-code"
+
+// startConsole serves the pairing endpoint over plain HTTP and the relay over
+// mutual TLS, both backed by authority. Relay event requests record the
+// agent ID of the presented certificate in seen.
+func startConsole(t *testing.T, authority *agentpki.Authority, seen chan<- string) (consoleURL, relayURL string) {
+	t.Helper()
+	console := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ AgentID, Code, CSR string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code != testPairingCode {
+			http.Error(w, "invalid or expired pairing code", http.StatusUnauthorized)
+			return
+		}
+		certPEM, err := authority.Issue(req.AgentID, []byte(req.CSR))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeCertificate(w, req.AgentID, certPEM, authority)
+	}))
+	t.Cleanup(console.Close)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/relay/events", func(w http.ResponseWriter, r *http.Request) {
+		seen <- r.TLS.PeerCertificates[0].Subject.CommonName
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/relay/rotate", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ CSR string }
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		current := r.TLS.PeerCertificates[0]
+		certPEM, err := authority.Rotate(current, []byte(req.CSR))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		writeCertificate(w, current.Subject.CommonName, certPEM, authority)
+	})
+	serverTLS, err := authority.RelayTLSConfig("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return console.URL, "https://" + ln.Addr().String()
+}
+
+func writeCertificate(w http.ResponseWriter, agentID string, certPEM []byte, authority *agentpki.Authority) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(certificateResponse{AgentID: agentID, Certificate: string(certPEM), CA: string(authority.CAPEM())})
+}
+
+func sendEvent(t *testing.T, c *Client) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, c.URL("/relay/events"), strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("relay request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("relay status %d", resp.StatusCode)
+	}
+}
+
+func TestOpen_PairsAndPresentsCertificate(t *testing.T) {
+	authority, err := agentpki.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(chan string, 1)
+	consoleURL, relayURL := startConsole(t, authority, seen)
+	cfg := Config{URL: relayURL, ConsoleURL: consoleURL, AgentID: "agent-1", Dir: t.TempDir()}
+
+	if _, err := Open(context.Background(), cfg); !errors.Is(err, ErrNotPaired) {
+		t.Fatalf("Open without credentials or code: %v, want ErrNotPaired", err)
+	}
+
+	cfg.PairingCode = "wrong"
+	if _, err := Open(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Open with a wrong code: %v, want a 401", err)
+	}
+
+	cfg.PairingCode = testPairingCode
+	c, err := Open(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	sendEvent(t, c)
+	if got := <-seen; got != "agent-1" {
+		t.Errorf("relay saw agent %q, want agent-1", got)
+	}
+
+	// A restart reuses the stored credentials without a pairing code.
+	cfg.PairingCode = ""
+	c, err = Open(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Open with stored credentials: %v", err)
+	}
+	if c.AgentID() != "agent-1" {
+		t.Errorf("AgentID = %q, want agent-1", c.AgentID())
+	}
+	sendEvent(t, c)
+	<-seen
+}
+
+func TestRotateIfDue(t *testing.T) {
+	authority, err := agentpki.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(chan string, 1)
+	consoleURL, relayURL := startConsole(t, authority, seen)
+	cfg := Config{URL: relayURL, ConsoleURL: consoleURL, AgentID: "agent-1", PairingCode: testPairingCode, Dir: t.TempDir()}
+	c, err := Open(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	first := c.cert.SerialNumber
+
+	if err := c.RotateIfDue(context.Background()); err != nil {
+		t.Fatalf("RotateIfDue: %v", err)
+	}
+	if c.cert.SerialNumber.Cmp(first) != 0 {
+		t.Fatal("rotated a certificate that was not due")
+	}
+
+	c.now = func() time.Time { return c.cert.NotAfter.Add(-time.Hour) }
+	if err := c.RotateIfDue(context.Background()); err != nil {
+		t.Fatalf("RotateIfDue: %v", err)
+	}
+	if c.cert.SerialNumber.Cmp(first) == 0 {
+		t.Fatal("certificate was not rotated")
+	}
+	sendEvent(t, c)
+	<-seen
+
+	// The rotated credentials are the ones stored.
+	cfg.PairingCode = ""
+	reopened, err := Open(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if reopened.cert.SerialNumber.Cmp(c.cert.SerialNumber) != 0 {
+		t.Error("rotated certificate was not persisted")
+	}
+}
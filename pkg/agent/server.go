@@ -15,8 +15,12 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/kubestellar/console/pkg/agent/relay"
 	"github.com/kubestellar/console/pkg/agent/tokentracker"
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/idempotency"
+	"github.com/kubestellar/console/pkg/limits"
 	"github.com/kubestellar/console/pkg/safego"
 	"github.com/kubestellar/console/pkg/settings"
 	"github.com/kubestellar/console/pkg/agent/kube"
@@ -128,6 +132,9 @@ type Config struct {
 	Port           int
 	Kubeconfig     string
 	AllowedOrigins []string // Additional allowed origins (from --allowed-origins flag)
+	// Relay is the mTLS relay to a hosted console; nil when the agent only
+	// serves a local console.
+	Relay *relay.Client
 }
 
 // AllowedOrigins for WebSocket connections (can be extended via env var)
@@ -205,6 +212,19 @@ type Server struct {
 	resourceRetryMu    sync.Mutex
 	resourceRetryState map[string]clusterResourceRetryState
 
+	// Kubeconfig Secrets re-read for credential rotation; nil uses the
+	// settings manager.
+	kubeconfigSecretsMu  sync.Mutex
+	kubeconfigSecretRefs kubeconfigSecretStore
+
+	// Resource limits checked before clusters or managed workloads are
+	// added; nil enforces none.
+	limits *limits.Enforcer
+
+	// Responses of creates retried with an Idempotency-Key; nil disables
+	// replay.
+	idempotency *idempotency.Cache
+
 	// digestSequence tracks state integrity packets (#12000)
 	digestSequence atomic.Int64
 	stopCh         chan struct{}
@@ -221,6 +241,9 @@ type Server struct {
 	// Semaphore for bounding concurrent event forwards to prevent goroutine exhaustion (#13991)
 	stellarForwardSem       chan struct{}
 	missionExecutionTimeout time.Duration
+
+	// Namespaces, audit log and timeout of /ws/exec sessions.
+	execSessions execPolicy
 }
 
 // NewServer creates a new agent server
@@ -229,6 +252,13 @@ func NewServer(cfg Config) (*Server, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize kubectl proxy: %w", err)
 	}
+	if err := kube.SetKubectlPolicyFile(os.Getenv(kube.KubectlPolicyEnvVar)); err != nil {
+		return nil, err
+	}
+	execSessions, err := execPolicyFromEnv()
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize k8s client for rich cluster data queries
 	k8sClient, err := k8s.NewMultiClusterClient(cfg.Kubeconfig)
@@ -338,6 +368,9 @@ func NewServer(cfg Config) (*Server, error) {
 		dryRunSessions:          make(map[string]bool),
 		resourceRetryState:      make(map[string]clusterResourceRetryState),
 		missionExecutionTimeout: missionExecutionTimeout,
+		execSessions:            execSessions,
+		limits:                  limits.FromEnv(settings.GetSettingsManager()),
+		idempotency:             idempotency.NewCache(idempotency.DefaultWindow),
 		stellarClient: &http.Client{
 			Timeout: 5 * time.Second,
 			Transport: &http.Transport{
@@ -386,6 +419,10 @@ func NewServer(cfg Config) (*Server, error) {
 
 	server.stopCh = make(chan struct{})
 
+	if cfg.Relay != nil {
+		safego.GoWith("server/relay-rotation", func() { cfg.Relay.Run(server.stopCh) })
+	}
+
 	// Start state integrity worker (#12000)
 	safego.GoWith("server/state-digest-worker", server.startStateDigestWorker)
 
@@ -439,6 +476,8 @@ func (s *Server) Start() error {
 		if err := s.k8sClient.StartWatching(); err != nil {
 			slog.Error("failed to start kubeconfig watcher", "error", err)
 		}
+		// Re-read kubeconfig Secrets registered for rotation.
+		safego.GoWith("server/kubeconfig-secret-refresh", s.startKubeconfigSecretRefresh)
 	}
 
 	// Start prediction system
@@ -457,8 +496,12 @@ func (s *Server) Start() error {
 		slog.Info("Device tracker started")
 	}
 
+	egress.LogReport("Agent", []egress.Feature{
+		{Name: "auto-update", Disabled: true, Detail: "updates are neither checked for nor installed"},
+	})
+
 	// Load auto-update config from settings and start if enabled
-	if s.updateChecker != nil {
+	if s.updateChecker != nil && !egress.AirGapped() {
 		mgr := settings.GetSettingsManager()
 		if all, err := mgr.GetAll(); err == nil && all.AutoUpdateEnabled {
 			channel := all.AutoUpdateChannel
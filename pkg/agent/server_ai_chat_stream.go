@@ -516,9 +516,7 @@ func (s *Server) handleCancelChat(conn *websocket.Conn, msg protocol.Message, wr
 		slog.Error("[Chat] failed to marshal cancel chat payload", "error", err)
 		return
 	}
-	var req struct {
-		SessionID string `json:"sessionId"`
-	}
+	var req protocol.CancelChatRequest
 	if err := json.Unmarshal(payloadBytes, &req); err != nil {
 		slog.Error("[Chat] failed to unmarshal cancel chat request", "error", err)
 		return
@@ -584,9 +582,9 @@ func (s *Server) handleCancelChat(conn *websocket.Conn, msg protocol.Message, wr
 		if err := conn.WriteJSON(protocol.Message{
 			ID:   msg.ID,
 			Type: protocol.TypeResult,
-			Payload: map[string]interface{}{
-				"cancelled": ok,
-				"sessionId": req.SessionID,
+			Payload: protocol.CancelChatResponse{
+				Cancelled: ok,
+				SessionID: req.SessionID,
 			},
 		}); err != nil {
 			slog.Error("[Chat] failed to write cancel ack to WebSocket",
@@ -195,6 +195,9 @@ func validateMixedModeKubectlCommand(args []string) (bool, string) {
 	}
 
 	verb := strings.ToLower(args[0])
+	if kube.PolicyDenies(args) {
+		return false, fmt.Sprintf("kubectl %s is refused by the kubectl policy", verb)
+	}
 	if !kube.ValidateKubectlArgs(args) {
 		if verb == "config" {
 			return false, "kubectl config mutations are blocked in mixed mode"
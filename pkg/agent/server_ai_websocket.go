@@ -116,6 +116,11 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Semaphore to limit concurrent work goroutines per connection (#7277)
 	sem := make(chan struct{}, maxWSGoroutines)
 
+	// streams are this connection's running kubectl_stream requests.
+	streams := newKubectlStreams()
+	// forwards are this connection's port_forward sessions.
+	forwards := newPortForwards()
+
 	// wg tracks all spawned goroutines (pinger, chat, kubectl, misc) so
 	// the handler can wait for them before closing the connection (#11878).
 	var wg sync.WaitGroup
@@ -228,6 +233,45 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		} else if msg.Type == protocol.TypeCancelChat {
 			// Cancel an in-progress chat by session ID
 			s.handleCancelChat(conn, msg, writeMu)
+		} else if msg.Type == protocol.TypeCancelKubectlStream {
+			s.handleCancelKubectlStream(conn, msg, streams, writeMu)
+		} else if msg.Type == protocol.TypeKubectlStream {
+			// Streams run until kubectl exits or they are cancelled, so
+			// they need their own goroutine like chats. Bounded by the
+			// semaphore (#7277) and maxKubectlStreamsPerConn.
+			select {
+			case sem <- struct{}{}:
+			case <-connCtx.Done():
+				continue
+			}
+			wg.Add(1)
+			m := msg
+			safego.GoWith("ai-ws-kubectl-stream", func() {
+				defer wg.Done()
+				defer func() { <-sem }() // release slot
+				s.handleKubectlStream(connCtx, conn, m, streams, writeMu, &closed)
+			})
+		} else if msg.Type == protocol.TypePortForwardSend {
+			// Bytes of a tunnelled connection are queued in order here,
+			// never blocking the read loop.
+			s.handlePortForwardSend(conn, msg, forwards, writeMu, &closed)
+		} else if msg.Type == protocol.TypeStopPortForward {
+			s.handleStopPortForward(conn, msg, forwards, writeMu, &closed)
+		} else if msg.Type == protocol.TypePortForward {
+			// Sessions stay open until stopped, like kubectl streams.
+			// Bounded by the semaphore (#7277) and maxPortForwardsPerConn.
+			select {
+			case sem <- struct{}{}:
+			case <-connCtx.Done():
+				continue
+			}
+			wg.Add(1)
+			m := msg
+			safego.GoWith("ai-ws-port-forward", func() {
+				defer wg.Done()
+				defer func() { <-sem }() // release slot
+				s.handlePortForward(connCtx, conn, m, forwards, writeMu, &closed)
+			})
 		} else if msg.Type == protocol.TypeKubectl {
 			// Handle kubectl messages concurrently so one slow cluster
 			// doesn't block the entire WebSocket message loop.
@@ -368,6 +412,8 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// cancel function. Explicitly cancelling and removing them here ensures
 	// prompt cleanup on disconnect.
 	s.cancelAllChatsForConn(conn)
+	streams.cancelAll()
+	forwards.stopAll()
 
 	// Wait for spawned goroutines to finish (with timeout to avoid hangs).
 	drainDone := make(chan struct{})
@@ -402,6 +448,10 @@ func (s *Server) handleMessage(ctx context.Context, msg protocol.Message) protoc
 		return s.handleListAgentsMessage(msg)
 	case protocol.TypeSelectAgent:
 		return s.handleSelectAgentMessage(msg)
+	case protocol.TypeHello:
+		return s.handleHelloMessage(msg)
+	case protocol.TypeRemoveContext:
+		return s.handleRemoveContextMessage(msg)
 	default:
 		return protocol.Message{
 			ID:   msg.ID,
@@ -429,6 +479,29 @@ func (s *Server) handleHealthMessage(msg protocol.Message) protocol.Message {
 	}
 }
 
+// handleHelloMessage answers the protocol handshake with the negotiated
+// version. Consoles that never send hello are served LegacyVersion.
+func (s *Server) handleHelloMessage(msg protocol.Message) protocol.Message {
+	payloadBytes, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return s.errorResponse(msg.ID, "invalid_payload", "Failed to parse hello request")
+	}
+
+	var hello protocol.HelloPayload
+	if err := json.Unmarshal(payloadBytes, &hello); err != nil {
+		return s.errorResponse(msg.ID, "invalid_payload", "Invalid hello request format")
+	}
+
+	ack, err := protocol.NegotiateHello(hello, Version)
+	if err != nil {
+		slog.Warn("[WS] protocol handshake failed", "client", hello.Client,
+			"clientVersion", hello.ClientVersion, "error", err)
+		return s.errorResponse(msg.ID, protocol.ErrorCodeIncompatibleProtocol, err.Error())
+	}
+	slog.Debug("[WS] protocol handshake", "client", hello.Client, "version", ack.ProtocolVersion)
+	return protocol.Message{ID: msg.ID, Type: protocol.TypeHelloAck, Payload: ack}
+}
+
 func (s *Server) handleClustersMessage(msg protocol.Message) protocol.Message {
 	clusters, current := s.kubectl.ListContexts()
 	return protocol.Message{
@@ -441,6 +514,27 @@ func (s *Server) handleClustersMessage(msg protocol.Message) protocol.Message {
 	}
 }
 
+// handleRemoveContextMessage removes a kubeconfig context, like POST
+// /kubeconfig/remove.
+func (s *Server) handleRemoveContextMessage(msg protocol.Message) protocol.Message {
+	payloadBytes, err := json.Marshal(msg.Payload)
+	if err != nil {
+		return s.errorResponse(msg.ID, "invalid_payload", "Failed to parse remove_context request")
+	}
+
+	var req protocol.RemoveContextRequest
+	if err := json.Unmarshal(payloadBytes, &req); err != nil || req.Context == "" {
+		return s.errorResponse(msg.ID, "invalid_payload", "Missing 'context' field")
+	}
+
+	result, err := s.kubectl.RemoveContext(req.Context)
+	if err != nil {
+		slog.Error("[kubeconfig] failed to remove context", "context", req.Context, "error", err)
+		return s.errorResponse(msg.ID, "remove_context_failed", sanitizeAgentError("remove cluster context", err))
+	}
+	return protocol.Message{ID: msg.ID, Type: protocol.TypeResult, Payload: result}
+}
+
 // readOnlyKubectlVerbs are kubectl subcommands that do not modify cluster state.
 // Used by the dry-run gate (#6442) to allow observation while blocking mutations.
 var readOnlyKubectlVerbs = map[string]bool{
@@ -467,6 +561,10 @@ func isReadOnlyKubectlCommand(args []string) bool {
 	if verb == "cluster-info" {
 		return firstMixedModePositionalArg(args[1:]) == ""
 	}
+	// A server-side dry run persists nothing.
+	if verb == "apply" {
+		return kube.IsServerDryRunApply(args)
+	}
 	return readOnlyKubectlVerbs[verb]
 }
 
@@ -574,7 +672,12 @@ func (s *Server) handleKubectlMessage(ctx context.Context, msg protocol.Message)
 
 	// Execute kubectl — propagate the connection context so client disconnect
 	// kills the kubectl process immediately (#9997).
-	result := s.kubectl.ExecuteWithContext(ctx, req.Context, req.Namespace, req.Args)
+	var result protocol.KubectlResponse
+	if req.Manifest != "" {
+		result = s.kubectl.ExecuteWithInput(ctx, req.Context, req.Namespace, req.Args, req.Manifest)
+	} else {
+		result = s.kubectl.ExecuteWithContext(ctx, req.Context, req.Namespace, req.Args)
+	}
 	return protocol.Message{
 		ID:      msg.ID,
 		Type:    protocol.TypeResult,
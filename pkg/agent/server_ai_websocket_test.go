@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -69,6 +70,8 @@ func TestIsReadOnlyKubectlCommand(t *testing.T) {
 		{name: "bare cluster info is read only", args: []string{"cluster-info"}, want: true},
 		{name: "cluster info dump is not read only", args: []string{"cluster-info", "dump"}, want: false},
 		{name: "cluster info skips valued flags", args: []string{"cluster-info", "-n", "default"}, want: true},
+		{name: "server dry run apply is read only", args: []string{"apply", "--dry-run=server", "-f", "-"}, want: true},
+		{name: "apply is not read only", args: []string{"apply", "-f", "-"}, want: false},
 	}
 
 	for _, tt := range tests {
@@ -182,3 +185,83 @@ func TestServer_HandleWebSocket_MessageRouting(t *testing.T) {
 		t.Errorf("Unexpected response: %+v", resp)
 	}
 }
+
+func TestServer_HandleWebSocket_ProtocolHandshake(t *testing.T) {
+	s := &Server{
+		allowedOrigins: []string{"*"},
+		upgrader:       websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		clients:        make(map[*websocket.Conn]*wsClient),
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handleWebSocket))
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// A newer console negotiates down to the agent's current version.
+	require.NoError(t, conn.WriteJSON(protocol.Message{
+		ID:   "hello-1",
+		Type: protocol.TypeHello,
+		Payload: protocol.HelloPayload{
+			ProtocolVersion:    protocol.CurrentVersion + 1,
+			MinProtocolVersion: protocol.LegacyVersion,
+			Client:             "console-web",
+		},
+	}))
+	var resp struct {
+		ID      string                   `json:"id"`
+		Type    protocol.MessageType     `json:"type"`
+		Payload protocol.HelloAckPayload `json:"payload"`
+	}
+	require.NoError(t, conn.ReadJSON(&resp))
+	require.Equal(t, "hello-1", resp.ID)
+	require.Equal(t, protocol.TypeHelloAck, resp.Type)
+	require.Equal(t, protocol.CurrentVersion, resp.Payload.ProtocolVersion)
+	require.Equal(t, protocol.MinSupportedVersion, resp.Payload.MinProtocolVersion)
+
+	// A console that only speaks future versions is refused, not disconnected.
+	require.NoError(t, conn.WriteJSON(protocol.Message{
+		ID:      "hello-2",
+		Type:    protocol.TypeHello,
+		Payload: protocol.HelloPayload{ProtocolVersion: protocol.CurrentVersion + 2, MinProtocolVersion: protocol.CurrentVersion + 1},
+	}))
+	var errResp struct {
+		ID      string                `json:"id"`
+		Type    protocol.MessageType  `json:"type"`
+		Payload protocol.ErrorPayload `json:"payload"`
+	}
+	require.NoError(t, conn.ReadJSON(&errResp))
+	require.Equal(t, protocol.TypeError, errResp.Type)
+	require.Equal(t, protocol.ErrorCodeIncompatibleProtocol, errResp.Payload.Code)
+
+	// Malformed hello payloads are rejected.
+	require.NoError(t, conn.WriteJSON(protocol.Message{ID: "hello-3", Type: protocol.TypeHello, Payload: "v2"}))
+	require.NoError(t, conn.ReadJSON(&errResp))
+	require.Equal(t, "invalid_payload", errResp.Payload.Code)
+}
+
+func TestHandleMessage_RemoveContext(t *testing.T) {
+	s := newTestServer(t, withContexts("keep", "remove-me"))
+
+	resp := s.handleMessage(context.Background(), protocol.Message{
+		ID:      "rm-1",
+		Type:    protocol.TypeRemoveContext,
+		Payload: protocol.RemoveContextRequest{Context: "remove-me"},
+	})
+	require.Equal(t, protocol.TypeResult, resp.Type)
+	require.Equal(t, protocol.RemoveContextResponse{Success: true, Context: "remove-me", Cluster: "remove-me"}, resp.Payload)
+
+	resp = s.handleMessage(context.Background(), protocol.Message{
+		ID:      "rm-2",
+		Type:    protocol.TypeRemoveContext,
+		Payload: protocol.RemoveContextRequest{Context: "keep"},
+	})
+	require.Equal(t, protocol.TypeError, resp.Type)
+	require.Equal(t, "remove_context_failed", resp.Payload.(protocol.ErrorPayload).Code)
+
+	resp = s.handleMessage(context.Background(), protocol.Message{ID: "rm-3", Type: protocol.TypeRemoveContext})
+	require.Equal(t, "invalid_payload", resp.Payload.(protocol.ErrorPayload).Code)
+}
@@ -20,14 +20,20 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/clusterexpr"
+	"github.com/kubestellar/console/pkg/clustergroup"
+	"github.com/kubestellar/console/pkg/deploywindow"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
 )
 
 // consoleCRRequestTimeout is the per-request deadline applied to CR writes.
@@ -106,6 +112,19 @@ func (s *Server) handleConsoleCRManagedWorkloads(w http.ResponseWriter, r *http.
 		if mw.CreationTimestamp.IsZero() {
 			mw.CreationTimestamp = metav1.Now()
 		}
+		if errs := mw.Validate(); len(errs) > 0 {
+			writeValidationError(w, mw.Kind, mw.Name, errs)
+			return
+		}
+		if err := s.limits.CheckManagedWorkload(namespace, k8s.ManagedWorkloadCounter(ctx, persistence)); err != nil {
+			if limits.Code(err) == "" {
+				slog.Error("failed to count managed workloads", "namespace", namespace, "error", err)
+				writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("count managed workloads", err))
+				return
+			}
+			writeLimitError(w, err)
+			return
+		}
 		created, err := persistence.CreateManagedWorkload(ctx, &mw)
 		if err != nil {
 			slog.Error("failed to create managed workload", "namespace", namespace, "name", mw.Name, "error", err)
@@ -192,6 +211,14 @@ func (s *Server) handleConsoleCRClusterGroups(w http.ResponseWriter, r *http.Req
 		if cg.CreationTimestamp.IsZero() {
 			cg.CreationTimestamp = metav1.Now()
 		}
+		if err := validateClusterGroupCriteria(cg.Spec); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if status, msg := validateClusterGroupHierarchy(ctx, persistence, cg); status != 0 {
+			writeJSONError(w, status, msg)
+			return
+		}
 		created, err := persistence.CreateClusterGroup(ctx, &cg)
 		if err != nil {
 			slog.Error("failed to create cluster group", "namespace", namespace, "name", cg.Name, "error", err)
@@ -214,6 +241,14 @@ func (s *Server) handleConsoleCRClusterGroups(w http.ResponseWriter, r *http.Req
 		}
 		cg.Name = name
 		cg.Namespace = namespace
+		if err := validateClusterGroupCriteria(cg.Spec); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if status, msg := validateClusterGroupHierarchy(ctx, persistence, cg); status != 0 {
+			writeJSONError(w, status, msg)
+			return
+		}
 		updated, err := persistence.UpdateClusterGroup(ctx, &cg)
 		if err != nil {
 			slog.Error("failed to update cluster group", "namespace", namespace, "name", name, "error", err)
@@ -240,6 +275,74 @@ func (s *Server) handleConsoleCRClusterGroups(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// validateClusterGroupCriteria compiles the group's CEL expression and checks
+// its dynamic filters so a typo is rejected on write rather than silently
+// matching no clusters.
+func validateClusterGroupCriteria(spec v1alpha1.ClusterGroupSpec) error {
+	if err := clustergroup.ValidateFilters(spec.DynamicFilters); err != nil {
+		return err
+	}
+	if spec.Expression == "" {
+		return nil
+	}
+	_, err := clusterexpr.Compile(spec.Expression)
+	return err
+}
+
+// validateClusterGroupHierarchy rejects invalid settings and memberGroups
+// that would make the group a member of itself. It returns the HTTP status
+// and message to fail the request with, or 0 when the group is valid.
+func validateClusterGroupHierarchy(ctx context.Context, persistence k8s.ConsolePersistence, cg v1alpha1.ClusterGroup) (int, string) {
+	if err := clustergroup.ValidateSettings(cg.Spec.Settings); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	if len(cg.Spec.MemberGroups) == 0 {
+		return 0, ""
+	}
+	groups, err := persistence.ListClusterGroups(ctx, cg.Namespace)
+	if err != nil {
+		slog.Error("failed to list cluster groups", "namespace", cg.Namespace, "error", err)
+		return http.StatusInternalServerError, sanitizeAgentError("list cluster groups", err)
+	}
+	if err := clustergroup.CheckCycle(groups, cg); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	return 0, ""
+}
+
+// validationViolation is one entry of a 422 response's violations list.
+type validationViolation struct {
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// writeValidationError fails a create with 422, listing every problem errs
+// found so the caller can fix them all in one go. Reason is the field error
+// type, such as FieldValueRequired, for clients that match on it.
+func writeValidationError(w http.ResponseWriter, kind, name string, errs field.ErrorList) {
+	violations := make([]validationViolation, 0, len(errs))
+	for _, e := range errs {
+		violations = append(violations, validationViolation{Field: e.Field, Reason: string(e.Type), Message: e.ErrorBody()})
+	}
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	writeJSON(w, map[string]any{
+		"error":      fmt.Sprintf("%s %q is invalid", kind, name),
+		"violations": violations,
+	})
+}
+
+// validateDeploymentWindows parses each deployment window so a malformed
+// time or timezone is rejected on write rather than failing the rollout.
+func validateDeploymentWindows(windows []v1alpha1.DeploymentWindow) error {
+	for i, dw := range windows {
+		if _, err := deploywindow.Parse(dw.Start, dw.End, dw.Days, dw.Timezone); err != nil {
+			return fmt.Errorf("deploymentWindows[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
 // handleConsoleCRWorkloadDeployments serves POST/DELETE for WorkloadDeployment
 // CRs. The general PUT path is intentionally absent — the backend only ever
 // exposed status updates (see handleConsoleCRWorkloadDeploymentStatus), and
@@ -281,6 +384,43 @@ func (s *Server) handleConsoleCRWorkloadDeployments(w http.ResponseWriter, r *ht
 		if wd.CreationTimestamp.IsZero() {
 			wd.CreationTimestamp = metav1.Now()
 		}
+		if errs := wd.Validate(); len(errs) > 0 {
+			writeValidationError(w, wd.Kind, wd.Name, errs)
+			return
+		}
+		if err := validateDeploymentWindows(wd.Spec.DeploymentWindows); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if cc := wd.Spec.CanaryConfig; cc != nil {
+			if err := cc.Validate(); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if b := wd.Spec.PreDeployBackup; b != nil {
+			if err := b.Validate(); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		// Whether change metadata is required is project policy, enforced by
+		// the reconciler; malformed values are rejected here.
+		if change := wd.ChangeMetadata(); change != nil {
+			if err := change.Validate(); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if err := s.checkWorkloadDeploymentQuota(ctx, persistence, &wd); err != nil {
+			if limits.Code(err) == "" {
+				slog.Error("failed to check workload deployment quota", "namespace", namespace, "name", wd.Name, "error", err)
+				writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("check workload deployment quota", err))
+				return
+			}
+			writeLimitError(w, err)
+			return
+		}
 		created, err := persistence.CreateWorkloadDeployment(ctx, &wd)
 		if err != nil {
 			slog.Error("failed to create workload deployment", "namespace", namespace, "name", wd.Name, "error", err)
@@ -3,6 +3,7 @@ package agent
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/kubestellar/console/pkg/k8s"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
 )
 
@@ -107,4 +109,269 @@ func TestServer_HandleConsoleCRClusterGroups(t *testing.T) {
 	if w.Code != http.StatusCreated {
 		t.Errorf("Expected status 201, got %d", w.Code)
 	}
+
+	// An expression that does not type-check is rejected before it is stored.
+	cg.Name = "bad-expr"
+	cg.Spec.Expression = `cluster.gpuCnt >= 8`
+	body, _ = json.Marshal(cg)
+	req = httptest.NewRequest("POST", "/console-cr/clustergroups?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRClusterGroups(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid expression, got %d", w.Code)
+	}
+
+	// So is a filter on a field that does not exist.
+	cg.Name = "bad-filter"
+	cg.Spec.Expression = ""
+	cg.Spec.DynamicFilters = []v1alpha1.ClusterFilter{{Field: "gpuCnt", Operator: "gte", Value: "8"}}
+	body, _ = json.Marshal(cg)
+	req = httptest.NewRequest("POST", "/console-cr/clustergroups?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRClusterGroups(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid filter, got %d", w.Code)
+	}
+}
+
+func TestServer_HandleConsoleCRManagedWorkloads_ListsViolations(t *testing.T) {
+	fakeDyn := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fakeDyn)
+
+	s := &Server{
+		k8sClient:      k8sClient,
+		allowedOrigins: []string{"*"},
+	}
+
+	mw := v1alpha1.ManagedWorkload{
+		ObjectMeta: metav1.ObjectMeta{Name: "untargeted"},
+		Spec: v1alpha1.ManagedWorkloadSpec{
+			TargetClusters: []string{"c1"},
+			TargetGroups:   []string{"prod"},
+		},
+	}
+	body, _ := json.Marshal(mw)
+	req := httptest.NewRequest("POST", "/console-cr/managedworkloads?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	s.handleConsoleCRManagedWorkloads(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Error      string `json:"error"`
+		Violations []struct {
+			Field  string `json:"field"`
+			Reason string `json:"reason"`
+		} `json:"violations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	var fields []string
+	for _, v := range resp.Violations {
+		fields = append(fields, v.Field+" "+v.Reason)
+	}
+	want := []string{
+		"spec.workloadRef.kind FieldValueRequired",
+		"spec.workloadRef.name FieldValueRequired",
+		"spec.targetGroups FieldValueForbidden",
+	}
+	if fmt.Sprint(fields) != fmt.Sprint(want) {
+		t.Errorf("Expected violations %v, got %v", want, fields)
+	}
+	if resp.Error != `ManagedWorkload "untargeted" is invalid` {
+		t.Errorf("Unexpected error message %q", resp.Error)
+	}
+}
+
+func TestServer_HandleConsoleCRWorkloadDeployments_RejectsBadWindow(t *testing.T) {
+	fakeDyn := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fakeDyn)
+
+	s := &Server{
+		k8sClient:      k8sClient,
+		allowedOrigins: []string{"*"},
+	}
+
+	wd := v1alpha1.WorkloadDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "apac-rollout"},
+		Spec: v1alpha1.WorkloadDeploymentSpec{
+			WorkloadRef: v1alpha1.ResourceReference{Name: "my-app"},
+			DeploymentWindows: []v1alpha1.DeploymentWindow{
+				{ClusterGroup: "apac", Start: "02:00", End: "05:00", Timezone: "Mars/Olympus"},
+			},
+		},
+	}
+	body, _ := json.Marshal(wd)
+	req := httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid deployment window, got %d", w.Code)
+	}
+
+	wd.Spec.DeploymentWindows[0].Timezone = "Asia/Tokyo"
+	body, _ = json.Marshal(wd)
+	req = httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleConsoleCRWorkloadDeployments_RejectsBadChangeTicket(t *testing.T) {
+	fakeDyn := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fakeDyn)
+
+	s := &Server{
+		k8sClient:      k8sClient,
+		allowedOrigins: []string{"*"},
+	}
+
+	wd := v1alpha1.WorkloadDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ticketed-rollout",
+			Annotations: map[string]string{v1alpha1.AnnotationChangeTicket: "OPS-12"},
+		},
+		Spec: v1alpha1.WorkloadDeploymentSpec{WorkloadRef: v1alpha1.ResourceReference{Name: "my-app"}},
+	}
+	body, _ := json.Marshal(wd)
+	req := httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a relative ticket URL, got %d", w.Code)
+	}
+
+	wd.Annotations[v1alpha1.AnnotationChangeTicket] = "https://jira.example.com/browse/OPS-12"
+	body, _ = json.Marshal(wd)
+	req = httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleConsoleCRWorkloadDeployments_RejectsBadRolloutConfig(t *testing.T) {
+	fakeDyn := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fakeDyn)
+
+	s := &Server{
+		k8sClient:      k8sClient,
+		allowedOrigins: []string{"*"},
+	}
+
+	wd := v1alpha1.WorkloadDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "rolling"},
+		Spec: v1alpha1.WorkloadDeploymentSpec{
+			WorkloadRef:   v1alpha1.ResourceReference{Name: "my-app"},
+			Strategy:      v1alpha1.StrategyRollingUpdate,
+			RolloutConfig: &v1alpha1.RolloutConfig{PauseBetweenClusters: "a minute"},
+		},
+	}
+	body, _ := json.Marshal(wd)
+	req := httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for an invalid pause, got %d", w.Code)
+	}
+
+	wd.Spec.RolloutConfig.PauseBetweenClusters = "1m"
+	wd.Spec.PreDeployBackup = &v1alpha1.PreDeployBackup{TTL: "a week"}
+	body, _ = json.Marshal(wd)
+	req = httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid backup TTL, got %d", w.Code)
+	}
+
+	wd.Spec.PreDeployBackup.TTL = "168h"
+	wd.Spec.CanaryConfig = &v1alpha1.CanaryConfig{InitialWeight: 80, MaxWeight: 50}
+	body, _ = json.Marshal(wd)
+	req = httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid canary config, got %d", w.Code)
+	}
+
+	wd.Spec.CanaryConfig = nil
+	body, _ = json.Marshal(wd)
+	req = httptest.NewRequest("POST", "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+
+	s.handleConsoleCRWorkloadDeployments(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleConsoleCRClusterGroups_RejectsCycle(t *testing.T) {
+	fakeDyn := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		v1alpha1.ClusterGroupGVR: "ClusterGroupList",
+	})
+
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fakeDyn)
+
+	s := &Server{
+		k8sClient:      k8sClient,
+		allowedOrigins: []string{"*"},
+	}
+
+	post := func(name string, spec v1alpha1.ClusterGroupSpec) int {
+		body, _ := json.Marshal(v1alpha1.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec})
+		req := httptest.NewRequest("POST", "/console-cr/clustergroups?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleConsoleCRClusterGroups(w, req)
+		return w.Code
+	}
+
+	if code := post("region", v1alpha1.ClusterGroupSpec{MemberGroups: []string{"env"}}); code != http.StatusCreated {
+		t.Fatalf("Expected status 201 for region, got %d", code)
+	}
+	if code := post("env", v1alpha1.ClusterGroupSpec{MemberGroups: []string{"region"}}); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a memberGroups cycle, got %d", code)
+	}
+	if code := post("env", v1alpha1.ClusterGroupSpec{Settings: &v1alpha1.ClusterGroupSettings{
+		NotificationRoutes: []v1alpha1.NotificationRoute{{Type: "carrier-pigeon", Target: "roof"}},
+	}}); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown notification route type, got %d", code)
+	}
+	if code := post("env", v1alpha1.ClusterGroupSpec{StaticMembers: []string{"prod-1"}}); code != http.StatusCreated {
+		t.Errorf("Expected status 201 for env, got %d", code)
+	}
 }
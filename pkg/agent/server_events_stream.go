@@ -251,14 +251,20 @@ func (s *Server) forwardEventToStellar(event sseEventSummary) {
 	reqCtx, reqCancel := context.WithTimeout(context.Background(), forwardTimeout)
 	defer reqCancel()
 
-	req, err := http.NewRequestWithContext(reqCtx, "POST", backendURL+"/api/stellar/events/ingest", bytes.NewReader(body))
+	target, do := backendURL+"/api/stellar/events/ingest", s.stellarClient.Do
+	if s.config.Relay != nil {
+		// Paired with a hosted console: send over the mTLS relay, which
+		// identifies the agent by its client certificate.
+		target, do = s.config.Relay.URL("/relay/events"), s.config.Relay.Do
+	}
+	req, err := http.NewRequestWithContext(reqCtx, "POST", target, bytes.NewReader(body))
 	if err != nil {
 		slog.Warn("stellar: failed to create request", "error", err)
 		return
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.stellarClient.Do(req)
+	resp, err := do(req)
 	if err != nil {
 		slog.Debug("stellar: failed to forward event to backend", "error", err)
 		return
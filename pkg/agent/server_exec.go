@@ -48,10 +48,17 @@ package agent
 //   - The #6891 pattern: a write-side ping ticker + a read-side pong handler
 //     that resets the read deadline.
 //   - The terminal size queue for TTY resize events.
+//
+// On top of that, operators can restrict sessions to some namespaces, record
+// every keystroke and all output to an audit log, and bound how long a
+// session stays open; see execPolicy in server_exec_audit.go. An
+// "attach_init" frame in place of "exec_init" attaches to the container's
+// running process instead of starting a command.
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
@@ -133,9 +140,17 @@ func GetAgentExecStdinDropCount() uint64 {
 	return agentExecStdinDropCount.Load()
 }
 
+// Init frame types: exec starts Command in the container, attach connects
+// to the process it already runs.
+const (
+	agentExecInitType   = "exec_init"
+	agentAttachInitType = "attach_init"
+)
+
 // agentExecInitMessage is the first JSON frame the client must send after
 // the WebSocket upgrade. It names the target cluster, namespace, pod,
-// container, and command, plus the initial terminal dimensions.
+// container, and command, plus the initial terminal dimensions. Command is
+// ignored when attaching.
 type agentExecInitMessage struct {
 	Type      string   `json:"type"`
 	Cluster   string   `json:"cluster"`
@@ -168,6 +183,8 @@ type agentWSWriter struct {
 	conn    *websocket.Conn
 	msgType string // "stdout" or "stderr"
 	mu      *sync.Mutex
+	// audit records what was sent; nil records nothing.
+	audit *execAuditor
 }
 
 func (w *agentWSWriter) Write(p []byte) (int, error) {
@@ -184,6 +201,7 @@ func (w *agentWSWriter) Write(p []byte) (int, error) {
 	if err := w.conn.WriteMessage(websocket.TextMessage, data); err != nil {
 		return 0, err
 	}
+	w.audit.record(execAuditRecord{Event: execAuditOutput, Stream: w.msgType, Data: string(p)})
 	return len(p), nil
 }
 
@@ -280,12 +298,6 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		return conn.SetReadDeadline(time.Now().Add(agentExecPongTimeout))
 	})
 
-	// Single cancellable context for the whole session. Cancelling it
-	// unblocks executor.StreamWithContext and any goroutine selecting on
-	// execCtx.Done().
-	execCtx, execCancel := context.WithCancel(r.Context())
-	defer execCancel()
-
 	// Read the init message. The backend handler reads this AFTER the JWT
 	// handshake; kc-agent reads it right after the upgrade since auth is
 	// already verified above.
@@ -299,10 +311,15 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		agentExecWriteError(conn, "Invalid init message")
 		return
 	}
-	if init.Type != "exec_init" {
-		agentExecWriteError(conn, "Expected exec_init message")
+	if init.Type != agentExecInitType && init.Type != agentAttachInitType {
+		agentExecWriteError(conn, "Expected exec_init or attach_init message")
 		return
 	}
+	mode := "exec"
+	if init.Type == agentAttachInitType {
+		mode = "attach"
+		init.Command = nil
+	}
 	if init.Cluster == "" || init.Namespace == "" || init.Pod == "" {
 		agentExecWriteError(conn, "Missing cluster, namespace, or pod")
 		return
@@ -311,7 +328,13 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		agentExecWriteError(conn, "Invalid cluster context")
 		return
 	}
-	if len(init.Command) == 0 {
+	if !s.execSessions.allowsNamespace(init.Namespace) {
+		slog.Warn("[AgentExec] SECURITY: rejected session in a namespace exec is not allowed in",
+			"cluster", init.Cluster, "namespace", init.Namespace, "pod", init.Pod)
+		agentExecWriteError(conn, "Exec sessions are not allowed in namespace "+init.Namespace)
+		return
+	}
+	if mode == "exec" && len(init.Command) == 0 {
 		init.Command = []string{"/bin/sh"}
 	}
 	if init.Cols == 0 {
@@ -327,7 +350,10 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	if len(init.Command) > 0 {
 		cmdBinary = init.Command[0]
 	}
+	sessionID := newExecSessionID()
 	slog.Info("[AgentExec] exec session",
+		"session", sessionID,
+		"mode", mode,
 		"cluster", init.Cluster,
 		"namespace", init.Namespace,
 		"pod", init.Pod,
@@ -338,6 +364,25 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	)
 	slog.Debug("[AgentExec] full exec command", "command", init.Command)
 
+	// The audit log is the one place the full command and every keystroke
+	// are kept, so a session that cannot be recorded is refused.
+	audit, err := openExecAudit(s.execSessions.auditLog, sessionID)
+	if err != nil {
+		slog.Error("[AgentExec] failed to open audit log", "path", s.execSessions.auditLog, "error", err)
+		agentExecWriteError(conn, "Exec audit log unavailable")
+		return
+	}
+	defer audit.close()
+
+	// Single cancellable context for the whole session, bounded by the
+	// session timeout. Cancelling it unblocks executor.StreamWithContext
+	// and any goroutine selecting on execCtx.Done().
+	execCtx, execCancel := context.WithCancel(r.Context())
+	if s.execSessions.sessionTimeout > 0 {
+		execCtx, execCancel = context.WithTimeout(r.Context(), s.execSessions.sessionTimeout)
+	}
+	defer execCancel()
+
 	// Resolve clientset + REST config for the target cluster. These come
 	// from the user's kubeconfig via the shared *k8s.MultiClusterClient; the
 	// apiserver will enforce RBAC against whatever identity that kubeconfig
@@ -356,14 +401,24 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build the pods/exec request. Stderr is merged into stdout when TTY is
-	// on (same as the backend handler, matches kubectl behaviour).
+	// Build the pods/exec or pods/attach request. Stderr is merged into
+	// stdout when TTY is on (same as the backend handler, matches kubectl
+	// behaviour).
 	execReq := clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(init.Pod).
 		Namespace(init.Namespace).
-		SubResource("exec").
-		VersionedParams(&corev1.PodExecOptions{
+		SubResource(mode)
+	if mode == "attach" {
+		execReq = execReq.VersionedParams(&corev1.PodAttachOptions{
+			Container: init.Container,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    !init.TTY,
+			TTY:       init.TTY,
+		}, scheme.ParameterCodec)
+	} else {
+		execReq = execReq.VersionedParams(&corev1.PodExecOptions{
 			Container: init.Container,
 			Command:   init.Command,
 			Stdin:     true,
@@ -371,6 +426,7 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 			Stderr:    !init.TTY,
 			TTY:       init.TTY,
 		}, scheme.ParameterCodec)
+	}
 
 	executor, err := remotecommand.NewSPDYExecutor(restConfig, "POST", execReq.URL())
 	if err != nil {
@@ -404,13 +460,24 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	writeMu.Unlock()
+	audit.record(execAuditRecord{
+		Event:     execAuditStart,
+		Mode:      mode,
+		Cluster:   init.Cluster,
+		Namespace: init.Namespace,
+		Pod:       init.Pod,
+		Container: init.Container,
+		Command:   init.Command,
+		Cols:      init.Cols,
+		Rows:      init.Rows,
+	})
 
 	// Channels bridging the WebSocket to the SPDY executor.
 	stdinCh := make(chan []byte, agentExecStdinBufferSize)
 	stdinReader := &agentWSReader{ch: stdinCh}
 
-	stdoutWriter := &agentWSWriter{conn: conn, msgType: "stdout", mu: writeMu}
-	stderrWriter := &agentWSWriter{conn: conn, msgType: "stderr", mu: writeMu}
+	stdoutWriter := &agentWSWriter{conn: conn, msgType: "stdout", mu: writeMu, audit: audit}
+	stderrWriter := &agentWSWriter{conn: conn, msgType: "stderr", mu: writeMu, audit: audit}
 
 	sizeQueue := &agentTerminalSizeQueue{
 		ch: make(chan remotecommand.TerminalSize, agentExecResizeBufferSize),
@@ -477,6 +544,7 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 				}
 				select {
 				case stdinCh <- []byte(m.Data):
+					audit.record(execAuditRecord{Event: execAuditStdin, Data: m.Data})
 				default:
 					totalDrops := agentExecStdinDropCount.Add(1)
 					sessionStdinDrops++
@@ -491,6 +559,7 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 				}
 			case "resize":
 				if m.Cols > 0 && m.Rows > 0 {
+					audit.record(execAuditRecord{Event: execAuditResize, Cols: m.Cols, Rows: m.Rows})
 					select {
 					case sizeQueue.ch <- remotecommand.TerminalSize{Width: m.Cols, Height: m.Rows}:
 					default:
@@ -513,6 +582,11 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		streamOpts.TerminalSizeQueue = sizeQueue
 	}
 	execErr := executor.StreamWithContext(execCtx, streamOpts)
+	timedOut := errors.Is(execCtx.Err(), context.DeadlineExceeded)
+
+	// The session is over; expire the read deadline so a read loop still
+	// waiting for client input returns now rather than at the next frame.
+	_ = conn.SetReadDeadline(time.Now())
 
 	// #7048 — Wait for the reader goroutine to finish before closing the
 	// size queue channel. The reader may still be pushing resize events;
@@ -521,10 +595,20 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	close(sizeQueue.ch)
 
 	exitCode := 0
+	reason := ""
 	if execErr != nil {
 		exitCode = 1
-		slog.Error("[AgentExec] stream ended with error", "error", execErr)
+		reason = execErr.Error()
+		slog.Error("[AgentExec] stream ended with error", "session", sessionID, "error", execErr)
+	}
+	if timedOut {
+		reason = "session timeout"
+		slog.Info("[AgentExec] session timed out", "session", sessionID, "timeout", s.execSessions.sessionTimeout)
+		writeMu.Lock()
+		agentExecWriteError(conn, "Exec session timed out after "+s.execSessions.sessionTimeout.String())
+		writeMu.Unlock()
 	}
+	audit.record(execAuditRecord{Event: execAuditEnd, ExitCode: &exitCode, Reason: reason})
 	exitMsg, mErr := json.Marshal(agentExecMessage{Type: "exit", ExitCode: exitCode})
 	if mErr != nil {
 		slog.Error("[AgentExec] failed to marshal exit message", "error", mErr, "exit_code", exitCode)
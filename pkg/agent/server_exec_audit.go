@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/kube"
+)
+
+// Environment variables that restrict and audit /ws/exec sessions. All are
+// optional; see docs/pod-exec.md.
+const (
+	// execNamespacesEnvVar is a comma-separated list of the only namespaces
+	// exec and attach sessions may open in.
+	execNamespacesEnvVar = "KC_EXEC_NAMESPACES"
+	// execAuditLogEnvVar names a file every session is recorded to as JSON
+	// lines: its start and end, every stdin frame and all output.
+	execAuditLogEnvVar = "KC_EXEC_AUDIT_LOG"
+	// execSessionTimeoutEnvVar overrides defaultExecSessionTimeout with a Go
+	// duration; "0" lets sessions run until either end closes them.
+	execSessionTimeoutEnvVar = "KC_EXEC_SESSION_TIMEOUT"
+)
+
+// defaultExecSessionTimeout bounds how long one exec or attach session
+// stays open, so a forgotten browser tab does not hold a shell forever.
+const defaultExecSessionTimeout = time.Hour
+
+// execSessionIDBytes is the number of random bytes in an exec session ID,
+// which ties the audit records of one session together.
+const execSessionIDBytes = 8
+
+// Audit record events.
+const (
+	execAuditStart  = "start"
+	execAuditStdin  = "stdin"
+	execAuditOutput = "output"
+	execAuditResize = "resize"
+	execAuditEnd    = "end"
+)
+
+// execPolicy restricts the exec and attach sessions of /ws/exec. The zero
+// value allows every namespace, records nothing and sets no timeout.
+type execPolicy struct {
+	// namespaces are the only namespaces sessions may open in; empty
+	// allows any the kubectl policy allows.
+	namespaces []string
+	// auditLog is the file sessions are recorded to, or empty.
+	auditLog string
+	// sessionTimeout closes sessions that run longer; 0 never does.
+	sessionTimeout time.Duration
+}
+
+// execPolicyFromEnv reads the KC_EXEC_* variables. An invalid namespace is
+// an error, so a typo cannot silently lift the restriction; an invalid
+// timeout falls back to the default.
+func execPolicyFromEnv() (execPolicy, error) {
+	p := execPolicy{
+		auditLog:       strings.TrimSpace(os.Getenv(execAuditLogEnvVar)),
+		sessionTimeout: defaultExecSessionTimeout,
+	}
+	for _, ns := range strings.Split(os.Getenv(execNamespacesEnvVar), ",") {
+		if ns = strings.TrimSpace(ns); ns == "" {
+			continue
+		}
+		if err := kube.ValidateDNS1123Label("namespace", ns); err != nil {
+			return p, fmt.Errorf("invalid %s: %w", execNamespacesEnvVar, err)
+		}
+		p.namespaces = append(p.namespaces, ns)
+	}
+	if raw := os.Getenv(execSessionTimeoutEnvVar); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed >= 0 {
+			p.sessionTimeout = parsed
+		} else {
+			slog.Warn("invalid KC_EXEC_SESSION_TIMEOUT value, using default",
+				"value", raw, "default", defaultExecSessionTimeout)
+		}
+	}
+	if len(p.namespaces) > 0 || p.auditLog != "" {
+		slog.Info("exec sessions restricted",
+			"namespaces", p.namespaces, "audit_log", p.auditLog, "timeout", p.sessionTimeout)
+	}
+	return p, nil
+}
+
+// allowsNamespace reports whether a session may open in namespace: it must
+// be listed, when namespaces are configured, and allowed by the kubectl
+// policy.
+func (p execPolicy) allowsNamespace(namespace string) bool {
+	if len(p.namespaces) > 0 && !slices.Contains(p.namespaces, namespace) {
+		return false
+	}
+	return kube.AllowsNamespace(namespace)
+}
+
+// execAuditRecord is one line of the exec audit log.
+type execAuditRecord struct {
+	Time      time.Time `json:"time"`
+	Session   string    `json:"session"`
+	Event     string    `json:"event"`
+	Mode      string    `json:"mode,omitempty"`
+	Cluster   string    `json:"cluster,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Pod       string    `json:"pod,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Command   []string  `json:"command,omitempty"`
+	// Stream is "stdout" or "stderr" for output records.
+	Stream   string `json:"stream,omitempty"`
+	Data     string `json:"data,omitempty"`
+	Cols     uint16 `json:"cols,omitempty"`
+	Rows     uint16 `json:"rows,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// execAuditor appends the records of one session to the audit log. A nil
+// auditor records nothing, so sessions without an audit log need no checks.
+type execAuditor struct {
+	session string
+
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// openExecAudit opens the audit log for a new session, or returns nil when
+// path is empty. The file is opened per session so it can be rotated
+// without restarting kc-agent.
+func openExecAudit(path, session string) (*execAuditor, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, agentFileMode) // #nosec G304 -- operator-configured path
+	if err != nil {
+		return nil, err
+	}
+	return &execAuditor{session: session, file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// record appends rec, stamped with the session and time. A failed write is
+// logged; the session goes on, as its start was already recorded.
+func (a *execAuditor) record(rec execAuditRecord) {
+	if a == nil {
+		return
+	}
+	rec.Time = time.Now().UTC()
+	rec.Session = a.session
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.enc == nil {
+		return
+	}
+	if err := a.enc.Encode(rec); err != nil {
+		slog.Error("[AgentExec] audit write failed", "session", a.session, "error", err)
+	}
+}
+
+func (a *execAuditor) close() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file != nil {
+		_ = a.file.Close()
+		a.file, a.enc = nil, nil
+	}
+}
+
+// newExecSessionID returns a random hex session ID.
+func newExecSessionID() string {
+	b := make([]byte, execSessionIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestExecPolicyFromEnv verifies the KC_EXEC_* variables and their
+// defaults.
+func TestExecPolicyFromEnv(t *testing.T) {
+	t.Setenv(execNamespacesEnvVar, " team-a, ,team-b")
+	t.Setenv(execAuditLogEnvVar, "/var/log/kc-agent/exec.log")
+	t.Setenv(execSessionTimeoutEnvVar, "15m")
+
+	p, err := execPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(p.namespaces) != 2 || p.namespaces[0] != "team-a" || p.namespaces[1] != "team-b" {
+		t.Errorf("namespaces = %v; want [team-a team-b]", p.namespaces)
+	}
+	if p.auditLog != "/var/log/kc-agent/exec.log" {
+		t.Errorf("auditLog = %q", p.auditLog)
+	}
+	if p.sessionTimeout != 15*time.Minute {
+		t.Errorf("sessionTimeout = %v; want 15m", p.sessionTimeout)
+	}
+
+	t.Setenv(execSessionTimeoutEnvVar, "soon")
+	if p, _ = execPolicyFromEnv(); p.sessionTimeout != defaultExecSessionTimeout {
+		t.Errorf("invalid timeout: sessionTimeout = %v; want the default", p.sessionTimeout)
+	}
+
+	t.Setenv(execNamespacesEnvVar, "Team_A")
+	if _, err := execPolicyFromEnv(); err == nil {
+		t.Error("expected an invalid namespace to be an error")
+	}
+}
+
+// TestExecPolicy_AllowsNamespace verifies that only the listed namespaces
+// are allowed, and any when none are listed.
+func TestExecPolicy_AllowsNamespace(t *testing.T) {
+	var open execPolicy
+	if !open.allowsNamespace("default") {
+		t.Error("the zero policy should allow every namespace")
+	}
+	restricted := execPolicy{namespaces: []string{"team-a"}}
+	if !restricted.allowsNamespace("team-a") {
+		t.Error("team-a should be allowed")
+	}
+	if restricted.allowsNamespace("kube-system") {
+		t.Error("kube-system should not be allowed")
+	}
+}
+
+// TestExecAuditor_Records verifies that records are appended as JSON lines
+// stamped with the session, and that a nil auditor is a no-op.
+func TestExecAuditor_Records(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exec.log")
+	a, err := openExecAudit(path, "abc123")
+	if err != nil {
+		t.Fatalf("openExecAudit: %v", err)
+	}
+	exitCode := 0
+	a.record(execAuditRecord{Event: execAuditStart, Mode: "exec", Pod: "web-0", Command: []string{"/bin/sh"}})
+	a.record(execAuditRecord{Event: execAuditStdin, Data: "ls\r"})
+	a.record(execAuditRecord{Event: execAuditOutput, Stream: "stdout", Data: "README.md\r\n"})
+	a.record(execAuditRecord{Event: execAuditEnd, ExitCode: &exitCode})
+	a.close()
+	a.record(execAuditRecord{Event: execAuditStdin, Data: "after close"})
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+	var events []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec execAuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		if rec.Session != "abc123" || rec.Time.IsZero() {
+			t.Errorf("record %+v lacks its session or time", rec)
+		}
+		events = append(events, rec.Event)
+	}
+	if got := strings.Join(events, ","); got != "start,stdin,output,end" {
+		t.Errorf("events = %s; want start,stdin,output,end", got)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != agentFileMode {
+		t.Errorf("audit log mode = %v; want %v", info.Mode().Perm(), os.FileMode(agentFileMode))
+	}
+
+	var none *execAuditor
+	none.record(execAuditRecord{Event: execAuditStdin})
+	none.close()
+	if a, err := openExecAudit("", "abc123"); a != nil || err != nil {
+		t.Errorf("openExecAudit(\"\") = %v, %v; want nil, nil", a, err)
+	}
+	if _, err := openExecAudit(filepath.Join(t.TempDir(), "missing", "exec.log"), "abc123"); err == nil {
+		t.Error("expected an error for a log in a missing directory")
+	}
+}
+
+// TestAgentWSWriter_Audit verifies that output frames are recorded once
+// they are sent.
+func TestAgentWSWriter_Audit(t *testing.T) {
+	serverConn, clientConn, cleanup := newTestWSPair(t)
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "exec.log")
+	a, err := openExecAudit(path, "abc123")
+	if err != nil {
+		t.Fatalf("openExecAudit: %v", err)
+	}
+	w := &agentWSWriter{conn: serverConn, msgType: "stderr", mu: &sync.Mutex{}, audit: a}
+	if _, err := w.Write([]byte("denied\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, _, err := clientConn.ReadMessage(); err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	a.close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	var rec execAuditRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("invalid audit log %q: %v", data, err)
+	}
+	if rec.Event != execAuditOutput || rec.Stream != "stderr" || rec.Data != "denied\n" {
+		t.Errorf("record = %+v; want stderr output %q", rec, "denied\n")
+	}
+}
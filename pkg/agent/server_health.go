@@ -64,6 +64,8 @@ func (s *Server) buildStatusPayload() protocol.HealthPayload {
 		Claude:             s.getClaudeInfo(),
 		InstallMethod:      updater.DetectAgentInstallMethod(),
 		AvailableProviders: providerSummaries,
+		ProtocolVersion:    protocol.CurrentVersion,
+		MinProtocolVersion: protocol.MinSupportedVersion,
 	}
 }
 
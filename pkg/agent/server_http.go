@@ -17,6 +17,7 @@ import (
 
 	"github.com/kubestellar/console/pkg/agent/httputil"
 	"github.com/kubestellar/console/pkg/agent/updater"
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/safego"
 	"github.com/kubestellar/console/pkg/settings"
 )
@@ -81,6 +82,10 @@ func writeJSONError(w http.ResponseWriter, statusCode int, msg string) {
 // back-compat for every GET-only handler that still passes no methods.
 const defaultCORSAllowedMethods = "GET, OPTIONS"
 
+// corsAllowedHeaders are the request headers the frontend may send.
+// Idempotency-Key makes retried creates safe (see server_idempotency.go).
+const corsAllowedHeaders = "Authorization, Content-Type, X-Requested-With, Idempotency-Key"
+
 // catchallCORSAllowedMethods is the Access-Control-Allow-Methods value used
 // by the mux fallback ("/") preflight handler. It is intentionally the
 // superset of HTTP verbs supported by any registered handler so that a
@@ -121,7 +126,7 @@ func (s *Server) setCORSHeaders(w http.ResponseWriter, r *http.Request, methods
 		allowed = strings.Join(methods, ", ")
 	}
 	w.Header().Set("Access-Control-Allow-Methods", allowed)
-	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With")
+	w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
 	// #10461: Credentialed requests (Authorization header) require this header
 	// or browsers block the response even when the origin is allowed.
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
@@ -153,7 +158,7 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 		}
 		w.Header().Set("Access-Control-Allow-Private-Network", "true")
 		w.Header().Set("Access-Control-Allow-Methods", catchallCORSAllowedMethods)
-		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", corsPreflightMaxAge)
 
@@ -506,6 +511,12 @@ func (s *Server) handleAutoUpdateConfig(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if req.Enabled && egress.AirGapped() {
+			w.WriteHeader(http.StatusForbidden)
+			writeJSON(w, map[string]string{"error": "auto-update is disabled in air-gapped mode"})
+			return
+		}
+
 		// Validate channel
 		switch req.Channel {
 		case "stable", "unstable", "developer":
@@ -596,6 +607,11 @@ func (s *Server) handleAutoUpdateTrigger(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, map[string]string{"error": "update checker not initialized"})
 		return
 	}
+	if egress.AirGapped() {
+		w.WriteHeader(http.StatusForbidden)
+		writeJSON(w, map[string]interface{}{"success": false, "error": "auto-update is disabled in air-gapped mode"})
+		return
+	}
 
 	// Accept optional channel override from frontend.
 	// SECURITY: reject malformed JSON instead of silently using zero-value (#4156).
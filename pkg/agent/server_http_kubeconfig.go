@@ -164,6 +164,12 @@ func (s *Server) handleKubeconfigImportHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if err := s.checkKubeconfigLimit(req.Kubeconfig); err != nil {
+		slog.Warn("kubeconfig import refused", "error", err)
+		writeLimitError(w, err)
+		return
+	}
+
 	added, skipped, err := s.kubectl.ImportKubeconfig(req.Kubeconfig)
 	if err != nil {
 		slog.Error("kubeconfig import error", "error", err)
@@ -182,7 +188,8 @@ type kubeconfigAddResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
-// handleKubeconfigRemoveHTTP removes a cluster context from the kubeconfig (#5658).
+// handleKubeconfigRemoveHTTP removes a cluster context from the kubeconfig (#5658),
+// with the cluster and user entries no other context uses.
 func (s *Server) handleKubeconfigRemoveHTTP(w http.ResponseWriter, r *http.Request) {
 	// POST-only kubeconfig removal — preflight must advertise POST (#8201).
 	s.setCORSHeaders(w, r, http.MethodPost, http.MethodOptions)
@@ -214,20 +221,27 @@ func (s *Server) handleKubeconfigRemoveHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if s.k8sClient == nil {
+	if s.kubectl == nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
-		writeJSON(w, map[string]string{"error": "k8s client not initialized"})
+		writeJSON(w, map[string]string{"error": "kubectl proxy not initialized"})
 		return
 	}
 
-	if err := s.k8sClient.RemoveContext(req.Context); err != nil {
+	// The kubeconfig watcher reloads k8sClient once the file is rewritten.
+	result, err := s.kubectl.RemoveContext(req.Context)
+	if err != nil {
 		slog.Error("[kubeconfig] failed to remove context", "context", req.Context, "error", err)
 		w.WriteHeader(http.StatusBadRequest)
 		writeJSON(w, map[string]string{"error": sanitizeAgentError("remove cluster context", err)})
 		return
 	}
 
-	writeJSON(w, map[string]interface{}{"ok": true, "removed": req.Context})
+	writeJSON(w, map[string]interface{}{
+		"ok":             true,
+		"removed":        req.Context,
+		"removedCluster": result.Cluster,
+		"removedUser":    result.User,
+	})
 }
 
 // handleKubeconfigAddHTTP adds a cluster from structured form fields
@@ -258,6 +272,12 @@ func (s *Server) handleKubeconfigAddHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := s.checkClusterLimit(1); err != nil {
+		slog.Warn("add cluster refused", "context", req.ContextName, "error", err)
+		writeLimitError(w, err)
+		return
+	}
+
 	if err := s.kubectl.AddCluster(req); err != nil {
 		slog.Error("add cluster error", "error", err)
 		w.WriteHeader(http.StatusBadRequest)
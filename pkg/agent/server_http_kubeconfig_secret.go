@@ -0,0 +1,333 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kubestellar/console/pkg/agent/kube"
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+const (
+	// kubeconfigSecretRefreshInterval is how often registered kubeconfig
+	// Secrets are re-read to pick up rotated credentials.
+	kubeconfigSecretRefreshInterval = 5 * time.Minute
+	// kubeconfigSecretReadTimeout bounds a single Secret read.
+	kubeconfigSecretReadTimeout = 15 * time.Second
+	// maxKubeconfigSecrets bounds the Secrets registered for refresh.
+	maxKubeconfigSecrets = 100
+)
+
+// kubeconfigSecretDefaultKeys are tried in order when the request names no
+// data key. Cluster API writes "value"; other provisioners use the others.
+var kubeconfigSecretDefaultKeys = []string{"value", "kubeconfig", "config"}
+
+// kubeconfigSecretStore persists the kubeconfig Secrets registered for
+// refresh. The settings manager implements it.
+type kubeconfigSecretStore interface {
+	GetKubeconfigSecrets() []settings.KubeconfigSecretRef
+	SaveKubeconfigSecrets([]settings.KubeconfigSecretRef) error
+}
+
+// kubeconfigSecretImportRequest is the JSON body for importing a kubeconfig
+// from a Secret on an already-connected cluster.
+type kubeconfigSecretImportRequest struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Key       string `json:"key,omitempty"`
+	// Refresh keeps re-reading the Secret so rotated credentials replace
+	// the imported ones.
+	Refresh bool `json:"refresh"`
+}
+
+// kubeconfigSecretImportResponse is the response from a Secret import.
+type kubeconfigSecretImportResponse struct {
+	kubeconfigImportResponse
+	Key     string `json:"key,omitempty"`
+	Refresh bool   `json:"refresh"`
+}
+
+// kubeconfigSecrets returns the store for registered kubeconfig Secrets.
+func (s *Server) kubeconfigSecrets() kubeconfigSecretStore {
+	if s.kubeconfigSecretRefs != nil {
+		return s.kubeconfigSecretRefs
+	}
+	return settings.GetSettingsManager()
+}
+
+// validateKubeconfigSecretRef checks the Secret coordinates of a request.
+func validateKubeconfigSecretRef(ref settings.KubeconfigSecretRef) error {
+	if ref.Context == "" || ref.Namespace == "" || ref.Name == "" {
+		return errors.New("context, namespace and name are required")
+	}
+	if err := kube.ValidateKubeContext(ref.Context); err != nil {
+		return errors.New("invalid context name")
+	}
+	if len(validation.IsDNS1123Label(ref.Namespace)) > 0 {
+		return errors.New("invalid namespace")
+	}
+	if len(validation.IsDNS1123Subdomain(ref.Name)) > 0 {
+		return errors.New("invalid secret name")
+	}
+	if ref.Key != "" && len(validation.IsConfigMapKey(ref.Key)) > 0 {
+		return errors.New("invalid secret key")
+	}
+	return nil
+}
+
+// readKubeconfigSecret returns the kubeconfig held in ref and the data key
+// it was read from. When ref.Key is empty the default keys are tried.
+func (s *Server) readKubeconfigSecret(ctx context.Context, ref settings.KubeconfigSecretRef) (string, string, error) {
+	client, err := s.k8sClient.GetClient(ref.Context)
+	if err != nil {
+		return "", "", fmt.Errorf("connect to context %q: %w", ref.Context, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, kubeconfigSecretReadTimeout)
+	defer cancel()
+	secret, err := client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+
+	keys := kubeconfigSecretDefaultKeys
+	if ref.Key != "" {
+		keys = []string{ref.Key}
+	}
+	for _, key := range keys {
+		if data, ok := secret.Data[key]; ok && len(data) > 0 {
+			return string(data), key, nil
+		}
+	}
+	return "", "", fmt.Errorf("secret %s/%s has no kubeconfig under %v", ref.Namespace, ref.Name, keys)
+}
+
+// handleKubeconfigImportSecretHTTP imports the kubeconfig stored in a Secret
+// on an already-connected cluster, e.g. a Cluster API <cluster>-kubeconfig
+// Secret. With refresh=true the Secret is re-read periodically and rotated
+// credentials replace the imported ones.
+func (s *Server) handleKubeconfigImportSecretHTTP(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r, http.MethodPost, http.MethodOptions)
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !s.validateToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeJSON(w, protocol.ErrorPayload{Code: "method_not_allowed", Message: "POST required"})
+		return
+	}
+
+	var req kubeconfigSecretImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, protocol.ErrorPayload{Code: "invalid_request", Message: "Invalid JSON"})
+		return
+	}
+	ref := settings.KubeconfigSecretRef{Context: req.Context, Namespace: req.Namespace, Name: req.Name, Key: req.Key}
+	if err := validateKubeconfigSecretRef(ref); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, protocol.ErrorPayload{Code: "invalid_request", Message: err.Error()})
+		return
+	}
+
+	if s.k8sClient == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		writeJSON(w, protocol.ErrorPayload{Code: "no_k8s_client", Message: "k8s client not initialized"})
+		return
+	}
+
+	kubeconfig, key, err := s.readKubeconfigSecret(r.Context(), ref)
+	if err != nil {
+		slog.Error("[kubeconfig] failed to read kubeconfig secret", "context", ref.Context, "namespace", ref.Namespace, "name", ref.Name, "error", err)
+		status := http.StatusBadRequest
+		if apierrors.IsForbidden(err) {
+			status = http.StatusForbidden
+		} else if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		writeJSON(w, protocol.ErrorPayload{Code: "secret_read_failed", Message: sanitizeAgentError("read kubeconfig secret", err)})
+		return
+	}
+
+	if err := s.checkKubeconfigLimit(kubeconfig); err != nil {
+		slog.Warn("kubeconfig secret import refused", "context", ref.Context, "namespace", ref.Namespace, "name", ref.Name, "error", err)
+		writeLimitError(w, err)
+		return
+	}
+
+	added, skipped, err := s.kubectl.ImportKubeconfig(kubeconfig)
+	if err != nil {
+		slog.Error("kubeconfig secret import error", "error", err)
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, kubeconfigImportResponse{Success: false, Error: sanitizeAgentError("import kubeconfig", err)})
+		return
+	}
+
+	if req.Refresh {
+		// Pin the key that worked so a later default-key change in the
+		// Secret cannot switch which kubeconfig is followed.
+		ref.Key = key
+		if err := s.registerKubeconfigSecret(ref); err != nil {
+			slog.Error("[kubeconfig] failed to register kubeconfig secret for refresh", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			writeJSON(w, kubeconfigImportResponse{Success: false, Added: added, Skipped: skipped, Error: "imported, but failed to save refresh registration"})
+			return
+		}
+	}
+
+	slog.Info("kubeconfig secret import complete", "context", ref.Context, "namespace", ref.Namespace, "name", ref.Name,
+		"added", len(added), "skipped", len(skipped), "refresh", req.Refresh)
+	writeJSON(w, kubeconfigSecretImportResponse{
+		kubeconfigImportResponse: kubeconfigImportResponse{Success: true, Added: added, Skipped: skipped},
+		Key:                      key,
+		Refresh:                  req.Refresh,
+	})
+}
+
+// handleKubeconfigSecretsHTTP lists the kubeconfig Secrets registered for
+// refresh (GET) or stops refreshing one (DELETE with context, namespace and
+// name as query parameters). Stopping leaves the imported contexts in place.
+func (s *Server) handleKubeconfigSecretsHTTP(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r, http.MethodGet, http.MethodDelete, http.MethodOptions)
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !s.validateToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		refs := s.kubeconfigSecrets().GetKubeconfigSecrets()
+		if refs == nil {
+			refs = []settings.KubeconfigSecretRef{}
+		}
+		writeJSON(w, map[string]interface{}{"secrets": refs})
+	case http.MethodDelete:
+		q := r.URL.Query()
+		ref := settings.KubeconfigSecretRef{Context: q.Get("context"), Namespace: q.Get("namespace"), Name: q.Get("name")}
+		if err := validateKubeconfigSecretRef(ref); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, protocol.ErrorPayload{Code: "invalid_request", Message: err.Error()})
+			return
+		}
+		removed, err := s.unregisterKubeconfigSecret(ref)
+		if err != nil {
+			slog.Error("[kubeconfig] failed to unregister kubeconfig secret", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			writeJSON(w, protocol.ErrorPayload{Code: "save_failed", Message: "failed to save refresh registrations"})
+			return
+		}
+		if !removed {
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(w, protocol.ErrorPayload{Code: "not_found", Message: "secret is not registered for refresh"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeJSON(w, protocol.ErrorPayload{Code: "method_not_allowed", Message: "GET or DELETE required"})
+	}
+}
+
+func sameKubeconfigSecret(a, b settings.KubeconfigSecretRef) bool {
+	return a.Context == b.Context && a.Namespace == b.Namespace && a.Name == b.Name
+}
+
+// registerKubeconfigSecret adds or updates ref in the refresh registrations.
+func (s *Server) registerKubeconfigSecret(ref settings.KubeconfigSecretRef) error {
+	s.kubeconfigSecretsMu.Lock()
+	defer s.kubeconfigSecretsMu.Unlock()
+
+	store := s.kubeconfigSecrets()
+	refs := store.GetKubeconfigSecrets()
+	for i := range refs {
+		if sameKubeconfigSecret(refs[i], ref) {
+			refs[i] = ref
+			return store.SaveKubeconfigSecrets(refs)
+		}
+	}
+	if len(refs) >= maxKubeconfigSecrets {
+		return fmt.Errorf("at most %d kubeconfig secrets can be refreshed", maxKubeconfigSecrets)
+	}
+	return store.SaveKubeconfigSecrets(append(refs, ref))
+}
+
+// unregisterKubeconfigSecret removes ref from the refresh registrations and
+// reports whether it was registered.
+func (s *Server) unregisterKubeconfigSecret(ref settings.KubeconfigSecretRef) (bool, error) {
+	s.kubeconfigSecretsMu.Lock()
+	defer s.kubeconfigSecretsMu.Unlock()
+
+	store := s.kubeconfigSecrets()
+	refs := store.GetKubeconfigSecrets()
+	for i := range refs {
+		if sameKubeconfigSecret(refs[i], ref) {
+			return true, store.SaveKubeconfigSecrets(append(refs[:i], refs[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// startKubeconfigSecretRefresh re-reads the registered kubeconfig Secrets
+// until the server stops.
+func (s *Server) startKubeconfigSecretRefresh() {
+	ticker := time.NewTicker(kubeconfigSecretRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.refreshKubeconfigSecrets(context.Background())
+		}
+	}
+}
+
+// refreshKubeconfigSecrets re-reads every registered Secret once and applies
+// changed credentials to the contexts imported from it. A Secret that cannot
+// be read is skipped until the next round; its contexts keep working with the
+// credentials they already have.
+func (s *Server) refreshKubeconfigSecrets(ctx context.Context) {
+	for _, ref := range s.kubeconfigSecrets().GetKubeconfigSecrets() {
+		kubeconfig, _, err := s.readKubeconfigSecret(ctx, ref)
+		if err != nil {
+			slog.Warn("[kubeconfig] failed to re-read kubeconfig secret", "context", ref.Context, "namespace", ref.Namespace, "name", ref.Name, "error", err)
+			continue
+		}
+		updated, err := s.kubectl.RefreshImportedKubeconfig(kubeconfig)
+		if err != nil {
+			slog.Warn("[kubeconfig] failed to apply refreshed kubeconfig", "context", ref.Context, "namespace", ref.Namespace, "name", ref.Name, "error", err)
+			continue
+		}
+		if len(updated) > 0 {
+			slog.Info("[kubeconfig] applied rotated credentials from secret", "namespace", ref.Namespace, "name", ref.Name, "contexts", updated)
+		}
+	}
+}
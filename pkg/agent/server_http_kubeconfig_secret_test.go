@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+// memoryKubeconfigSecretStore keeps refresh registrations in memory so tests
+// never touch the real settings file.
+type memoryKubeconfigSecretStore struct {
+	refs []settings.KubeconfigSecretRef
+}
+
+func (m *memoryKubeconfigSecretStore) GetKubeconfigSecrets() []settings.KubeconfigSecretRef {
+	return append([]settings.KubeconfigSecretRef(nil), m.refs...)
+}
+
+func (m *memoryKubeconfigSecretStore) SaveKubeconfigSecrets(refs []settings.KubeconfigSecretRef) error {
+	m.refs = refs
+	return nil
+}
+
+const capiKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://workload.example.com
+  name: workload
+contexts:
+- context:
+    cluster: workload
+    user: workload-admin
+  name: workload-admin@workload
+users:
+- name: workload-admin
+  user:
+    token: %s
+current-context: workload-admin@workload
+`
+
+// newSecretImportServer returns a server connected to a "mgmt" context whose
+// cluster holds a Cluster API style kubeconfig Secret.
+func newSecretImportServer(t *testing.T, token string) (*Server, *fake.Clientset, *memoryKubeconfigSecretStore) {
+	t.Helper()
+	s := newTestServer(t, withContexts("mgmt"))
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "workload-kubeconfig", Namespace: "default"},
+		Data:       map[string][]byte{"value": []byte(strings.Replace(capiKubeconfig, "%s", token, 1))},
+	})
+	s.k8sClient.InjectClient("mgmt", client)
+	store := &memoryKubeconfigSecretStore{}
+	s.kubeconfigSecretRefs = store
+	return s, client, store
+}
+
+func TestHandleKubeconfigImportSecretHTTP_OPTIONSPreflight(t *testing.T) {
+	s := newTestServer(t)
+	req := httptest.NewRequest(http.MethodOptions, "/kubeconfig/import-secret", nil)
+	rec := serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("OPTIONS: got %d, want 204", rec.Code)
+	}
+}
+
+func TestHandleKubeconfigImportSecretHTTP_Unauthorized(t *testing.T) {
+	s := newTestServer(t, withToken("secret"))
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import-secret", strings.NewReader(`{}`))
+	rec := serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got %d, want 401", rec.Code)
+	}
+}
+
+func TestHandleKubeconfigImportSecretHTTP_InvalidRequest(t *testing.T) {
+	s := newTestServer(t)
+	for name, body := range map[string]string{
+		"malformed":      `not json`,
+		"missing name":   `{"context":"mgmt","namespace":"default"}`,
+		"bad namespace":  `{"context":"mgmt","namespace":"Not_Valid","name":"x"}`,
+		"bad context":    `{"context":"../mgmt","namespace":"default","name":"x"}`,
+		"bad secret key": `{"context":"mgmt","namespace":"default","name":"x","key":"a/b"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import-secret", strings.NewReader(body))
+		rec := serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", name, rec.Code)
+		}
+	}
+}
+
+func TestHandleKubeconfigImportSecretHTTP_NoClient(t *testing.T) {
+	s := newTestServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import-secret",
+		strings.NewReader(`{"context":"mgmt","namespace":"default","name":"workload-kubeconfig"}`))
+	rec := serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d, want 503", rec.Code)
+	}
+}
+
+func TestHandleKubeconfigImportSecretHTTP_MissingSecret(t *testing.T) {
+	s, _, _ := newSecretImportServer(t, "t1")
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import-secret",
+		strings.NewReader(`{"context":"mgmt","namespace":"default","name":"other-kubeconfig"}`))
+	rec := serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("got %d, want 404", rec.Code)
+	}
+	var resp protocol.ErrorPayload
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Code != "secret_read_failed" {
+		t.Fatalf("expected code=secret_read_failed, got %s", resp.Code)
+	}
+}
+
+func TestHandleKubeconfigImportSecretHTTP_ImportAndRefresh(t *testing.T) {
+	s, client, store := newSecretImportServer(t, "t1")
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import-secret",
+		strings.NewReader(`{"context":"mgmt","namespace":"default","name":"workload-kubeconfig","refresh":true}`))
+	rec := serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var resp kubeconfigSecretImportResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if !resp.Success || len(resp.Added) != 1 || resp.Added[0] != "workload-admin@workload" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.Key != "value" {
+		t.Errorf("expected the Cluster API key to be found, got %q", resp.Key)
+	}
+	want := settings.KubeconfigSecretRef{Context: "mgmt", Namespace: "default", Name: "workload-kubeconfig", Key: "value"}
+	if len(store.refs) != 1 || store.refs[0] != want {
+		t.Fatalf("expected the secret to be registered for refresh, got %+v", store.refs)
+	}
+
+	// Rotate the credentials in the Secret and refresh.
+	secret, _ := client.CoreV1().Secrets("default").Get(context.Background(), "workload-kubeconfig", metav1.GetOptions{})
+	secret.Data["value"] = []byte(strings.Replace(capiKubeconfig, "%s", "t2", 1))
+	if _, err := client.CoreV1().Secrets("default").Update(context.Background(), secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update secret: %v", err)
+	}
+	s.refreshKubeconfigSecrets(context.Background())
+	written, err := clientcmd.LoadFromFile(s.kubectl.GetKubeconfigPath())
+	if err != nil {
+		t.Fatalf("load kubeconfig: %v", err)
+	}
+	if got := written.AuthInfos["workload-admin"].Token; got != "t2" {
+		t.Errorf("expected rotated token t2, got %q", got)
+	}
+
+	// Registering again does not duplicate the entry.
+	req = httptest.NewRequest(http.MethodPost, "/kubeconfig/import-secret",
+		strings.NewReader(`{"context":"mgmt","namespace":"default","name":"workload-kubeconfig","refresh":true}`))
+	serveAndRecord(s.handleKubeconfigImportSecretHTTP, req)
+	if len(store.refs) != 1 {
+		t.Fatalf("expected one registration, got %+v", store.refs)
+	}
+}
+
+func TestHandleKubeconfigSecretsHTTP_ListAndDelete(t *testing.T) {
+	s, _, store := newSecretImportServer(t, "t1")
+	store.refs = []settings.KubeconfigSecretRef{{Context: "mgmt", Namespace: "default", Name: "workload-kubeconfig", Key: "value"}}
+
+	rec := serveAndRecord(s.handleKubeconfigSecretsHTTP, httptest.NewRequest(http.MethodGet, "/kubeconfig/secrets", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "workload-kubeconfig") {
+		t.Fatalf("list: got %d %s", rec.Code, rec.Body.String())
+	}
+
+	target := "/kubeconfig/secrets?context=mgmt&namespace=default&name=workload-kubeconfig"
+	rec = serveAndRecord(s.handleKubeconfigSecretsHTTP, httptest.NewRequest(http.MethodDelete, target, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d, want 204", rec.Code)
+	}
+	if len(store.refs) != 0 {
+		t.Fatalf("expected registration removed, got %+v", store.refs)
+	}
+	rec = serveAndRecord(s.handleKubeconfigSecretsHTTP, httptest.NewRequest(http.MethodDelete, target, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete: got %d, want 404", rec.Code)
+	}
+}
@@ -250,9 +250,9 @@ func TestHandleKubeconfigRemoveHTTP_MissingContext(t *testing.T) {
 	}
 }
 
-func TestHandleKubeconfigRemoveHTTP_NilK8sClient(t *testing.T) {
+func TestHandleKubeconfigRemoveHTTP_NilKubectl(t *testing.T) {
 	s := newTestServer(t)
-	s.k8sClient = nil
+	s.kubectl = nil
 	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/remove", strings.NewReader(`{"context":"ctx1"}`))
 	rec := serveAndRecord(s.handleKubeconfigRemoveHTTP, req)
 	if rec.Code != http.StatusServiceUnavailable {
@@ -274,6 +274,33 @@ func TestHandleKubeconfigRemoveHTTP_Success(t *testing.T) {
 	}
 }
 
+func TestHandleKubeconfigRemoveHTTP_RemovesOrphanedCluster(t *testing.T) {
+	// "keep" is the current context; both contexts share test-user.
+	s := newTestServer(t, withContexts("keep", "remove-me"))
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/remove", strings.NewReader(`{"context":"remove-me"}`))
+	rec := serveAndRecord(s.handleKubeconfigRemoveHTTP, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["removed"] != "remove-me" || body["removedCluster"] != "remove-me" || body["removedUser"] != "" {
+		t.Fatalf("unexpected body: %v", body)
+	}
+	clusters, _ := s.kubectl.ListContexts()
+	if len(clusters) != 1 || clusters[0].Name != "keep" {
+		t.Fatalf("contexts after removal = %v, want only keep", clusters)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/kubeconfig/remove", strings.NewReader(`{"context":"keep"}`))
+	rec = serveAndRecord(s.handleKubeconfigRemoveHTTP, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("removing the current context: got %d, want 400", rec.Code)
+	}
+}
+
 // --- handleKubeconfigAddHTTP ---
 
 func TestHandleKubeconfigAddHTTP_OPTIONSPreflight(t *testing.T) {
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/kubestellar/console/pkg/idempotency"
+)
+
+// idempotent wraps a handler whose POST creates something, so a retried
+// request carrying the same Idempotency-Key header gets the first response
+// back instead of creating it twice. It mirrors the backend's
+// middleware.Idempotency: responses of 500 and above are not kept, a key
+// reused with another body gets 422 and a retry during the first request
+// gets 409. Unauthenticated requests and requests without the header go
+// straight to next, which rejects or serves them as before.
+func (s *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotency.Header)
+		if r.Method != http.MethodPost || key == "" || s.idempotency == nil || !s.validateToken(r) {
+			next(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !idempotency.ValidKey(key) {
+			writeJSONError(w, http.StatusBadRequest, "invalid Idempotency-Key header")
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The query names the target cluster and namespace, so it is part
+		// of what the key identifies.
+		scoped := r.Method + " " + r.URL.RequestURI() + " " + key
+		stored, err := s.idempotency.Begin(scoped, idempotency.Fingerprint(body))
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		case stored != nil:
+			w.Header().Set(idempotency.ReplayedHeader, "true")
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			if stored.Location != "" {
+				w.Header().Set("Location", stored.Location)
+			}
+			w.WriteHeader(stored.Status)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			if !completed {
+				s.idempotency.Release(scoped)
+			}
+		}()
+		next(rec, r)
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		s.idempotency.Complete(scoped, idempotency.Response{
+			Status:      rec.status,
+			ContentType: w.Header().Get("Content-Type"),
+			Location:    w.Header().Get("Location"),
+			Body:        rec.body.Bytes(),
+		})
+		completed = true
+	}
+}
+
+// idempotencyRecorder passes a response through while keeping its status
+// and body for the idempotency cache.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/idempotency"
+)
+
+func TestIdempotent_ReplaysCreate(t *testing.T) {
+	s := &Server{agentToken: "secret", idempotency: idempotency.NewCache(0)}
+	created := 0
+	status := http.StatusServiceUnavailable
+	handler := s.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		if !s.validateToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		created++
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"id":%d}`, created)
+	})
+	post := func(token, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/console-cr/groups?cluster=c&namespace=ns", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(idempotency.Header, key)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	// A server error is not kept, so the retry creates.
+	post("secret", "k1", `{"name":"g"}`)
+	status = http.StatusCreated
+	first := post("secret", "k1", `{"name":"g"}`)
+	retry := post("secret", "k1", `{"name":"g"}`)
+	if created != 2 {
+		t.Fatalf("handler ran %d times, want 2", created)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry got %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Error("retry not marked replayed")
+	}
+
+	if w := post("secret", "k1", `{"name":"other"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key got %d, want 422", w.Code)
+	}
+	if w := post("wrong", "k1", `{"name":"g"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token got %d, want 401 from the handler", w.Code)
+	}
+	if created != 2 {
+		t.Errorf("handler created %d times, want 2", created)
+	}
+}
@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kubestellar/console/pkg/agent/kube"
+	"github.com/kubestellar/console/pkg/agent/protocol"
+)
+
+// maxKubectlStreamsPerConn caps the kubectl streams one connection runs at
+// once. Followed logs hold a goroutine slot until cancelled, so the cap
+// keeps slots free for other requests.
+const maxKubectlStreamsPerConn = 5
+
+var (
+	errTooManyKubectlStreams  = fmt.Errorf("at most %d kubectl streams may run per connection", maxKubectlStreamsPerConn)
+	errDuplicateKubectlStream = errors.New("a kubectl stream with this ID is already running")
+)
+
+// kubectlStreams tracks the kubectl streams running on one connection by the
+// ID of the message that started them. Being per connection, a client can
+// only cancel its own streams.
+type kubectlStreams struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newKubectlStreams() *kubectlStreams {
+	return &kubectlStreams{cancels: make(map[string]context.CancelFunc)}
+}
+
+// start registers a stream and returns its context, derived from parent,
+// and the function that unregisters it.
+func (ks *kubectlStreams) start(parent context.Context, id string) (context.Context, func(), error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if _, dup := ks.cancels[id]; dup {
+		return nil, nil, errDuplicateKubectlStream
+	}
+	if len(ks.cancels) >= maxKubectlStreamsPerConn {
+		return nil, nil, errTooManyKubectlStreams
+	}
+	ctx, cancel := context.WithCancel(parent)
+	ks.cancels[id] = cancel
+	return ctx, func() {
+		ks.mu.Lock()
+		delete(ks.cancels, id)
+		ks.mu.Unlock()
+		cancel()
+	}, nil
+}
+
+// cancel stops the stream started by id and reports whether it was running.
+func (ks *kubectlStreams) cancel(id string) bool {
+	ks.mu.Lock()
+	cancel, ok := ks.cancels[id]
+	ks.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// cancelAll stops every stream, when the connection closes.
+func (ks *kubectlStreams) cancelAll() {
+	ks.mu.Lock()
+	cancels := make([]context.CancelFunc, 0, len(ks.cancels))
+	for _, cancel := range ks.cancels {
+		cancels = append(cancels, cancel)
+	}
+	ks.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+// kubectlStreamArgs returns the kubectl arguments for req, with its follow,
+// tail and since options as flags.
+func kubectlStreamArgs(req protocol.KubectlStreamRequest) ([]string, error) {
+	if len(req.Args) == 0 {
+		return nil, errors.New("args is required")
+	}
+	args := append([]string{}, req.Args...)
+	if req.Follow {
+		args = append(args, "--follow")
+	}
+	if req.Tail != nil {
+		if *req.Tail < -1 {
+			return nil, errors.New("tail must be -1 or more")
+		}
+		args = append(args, "--tail="+strconv.FormatInt(*req.Tail, 10))
+	}
+	if req.Since != "" {
+		since, err := time.ParseDuration(req.Since)
+		if err != nil || since <= 0 {
+			return nil, fmt.Errorf("invalid since %q: want a positive duration such as 10m", req.Since)
+		}
+		args = append(args, "--since="+since.String())
+	}
+	return args, nil
+}
+
+// kubectlOutputWriter sends what kubectl writes to one of its streams as
+// kubectl_output messages.
+type kubectlOutputWriter struct {
+	id     string
+	stream string
+	send   func(protocol.Message) error
+}
+
+func (w *kubectlOutputWriter) Write(p []byte) (int, error) {
+	err := w.send(protocol.Message{
+		ID:      w.id,
+		Type:    protocol.TypeKubectlOutput,
+		Payload: protocol.KubectlOutputPayload{Stream: w.stream, Data: string(p)},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// handleKubectlStream runs a kubectl_stream request, sending kubectl's output
+// as it arrives and a KubectlStreamResult when the command ends. Runs in a
+// goroutine so the read loop stays free to receive cancel_kubectl_stream.
+func (s *Server) handleKubectlStream(connCtx context.Context, conn *websocket.Conn, msg protocol.Message, streams *kubectlStreams, writeMu *sync.Mutex, closed *atomic.Bool) {
+	send := newWSMessageSender(conn, writeMu, closed, "[KubectlStream]")
+	fail := func(code, message string) {
+		_ = send(s.errorResponse(msg.ID, code, message))
+	}
+
+	payloadBytes, err := json.Marshal(msg.Payload)
+	if err != nil {
+		fail("invalid_payload", "Failed to parse kubectl stream request")
+		return
+	}
+	var req protocol.KubectlStreamRequest
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		fail("invalid_payload", "Invalid kubectl stream request format")
+		return
+	}
+	if req.Context != "" {
+		if err := kube.ValidateKubeContext(req.Context); err != nil {
+			fail("invalid_context", err.Error())
+			return
+		}
+	}
+	if req.Namespace != "" {
+		if err := kube.ValidateDNS1123Label("namespace", req.Namespace); err != nil {
+			fail("invalid_namespace", err.Error())
+			return
+		}
+	}
+	args, err := kubectlStreamArgs(req)
+	if err != nil {
+		fail("invalid_payload", err.Error())
+		return
+	}
+	if s.kubectl == nil {
+		fail("kubectl_unavailable", "kubectl proxy not initialized")
+		return
+	}
+
+	ctx, done, err := streams.start(connCtx, msg.ID)
+	if err != nil {
+		fail("stream_rejected", err.Error())
+		return
+	}
+	defer done()
+
+	result := s.kubectl.Stream(ctx, req.Context, req.Namespace, args,
+		&kubectlOutputWriter{id: msg.ID, stream: "stdout", send: send},
+		&kubectlOutputWriter{id: msg.ID, stream: "stderr", send: send})
+	result.Cancelled = ctx.Err() != nil
+	_ = send(protocol.Message{ID: msg.ID, Type: protocol.TypeResult, Payload: result})
+}
+
+// handleCancelKubectlStream stops one of this connection's kubectl streams.
+// The stream itself still ends with its result, marked cancelled.
+func (s *Server) handleCancelKubectlStream(conn *websocket.Conn, msg protocol.Message, streams *kubectlStreams, writeMu *sync.Mutex) {
+	var req protocol.CancelKubectlStreamRequest
+	if payloadBytes, err := json.Marshal(msg.Payload); err == nil {
+		_ = json.Unmarshal(payloadBytes, &req)
+	}
+	cancelled := streams.cancel(req.StreamID)
+	slog.Info("[KubectlStream] cancel requested", "streamID", req.StreamID, "cancelled", cancelled)
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if err := setWSWriteDeadline(conn, "[KubectlStream] failed to set WebSocket write deadline",
+		"msgID", msg.ID, "type", protocol.TypeResult); err != nil {
+		return
+	}
+	if err := conn.WriteJSON(protocol.Message{
+		ID:      msg.ID,
+		Type:    protocol.TypeResult,
+		Payload: protocol.CancelKubectlStreamResponse{Cancelled: cancelled, StreamID: req.StreamID},
+	}); err != nil {
+		slog.Error("[KubectlStream] failed to write cancel ack to WebSocket", "streamID", req.StreamID, "error", err)
+	}
+	_ = clearWSWriteDeadline(conn, "[KubectlStream] failed to clear WebSocket write deadline",
+		"msgID", msg.ID, "type", protocol.TypeResult)
+}
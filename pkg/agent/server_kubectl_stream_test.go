@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+)
+
+func TestKubectlStreamArgs(t *testing.T) {
+	tail := int64(100)
+	badTail := int64(-2)
+	tests := []struct {
+		name    string
+		req     protocol.KubectlStreamRequest
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", req: protocol.KubectlStreamRequest{Args: []string{"logs", "web-0"}}, want: "logs web-0"},
+		{
+			name: "all options",
+			req:  protocol.KubectlStreamRequest{Args: []string{"logs", "web-0"}, Follow: true, Tail: &tail, Since: "90s"},
+			want: "logs web-0 --follow --tail=100 --since=1m30s",
+		},
+		{name: "no args", req: protocol.KubectlStreamRequest{}, wantErr: true},
+		{name: "bad tail", req: protocol.KubectlStreamRequest{Args: []string{"logs"}, Tail: &badTail}, wantErr: true},
+		{name: "bad since", req: protocol.KubectlStreamRequest{Args: []string{"logs"}, Since: "yesterday"}, wantErr: true},
+		{name: "negative since", req: protocol.KubectlStreamRequest{Args: []string{"logs"}, Since: "-5m"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := kubectlStreamArgs(tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("args = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKubectlStreams verifies stream registration, cancellation and the
+// per-connection limit.
+func TestKubectlStreams(t *testing.T) {
+	streams := newKubectlStreams()
+	ctx, done, err := streams.start(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, _, err := streams.start(context.Background(), "s1"); !errors.Is(err, errDuplicateKubectlStream) {
+		t.Errorf("duplicate start: err = %v", err)
+	}
+	if !streams.cancel("s1") || ctx.Err() == nil {
+		t.Error("cancel should stop a running stream")
+	}
+	done()
+	if streams.cancel("s1") {
+		t.Error("cancel should report a finished stream as not running")
+	}
+
+	var ctxs []context.Context
+	for i := range maxKubectlStreamsPerConn {
+		ctx, _, err := streams.start(context.Background(), "s"+strconv.Itoa(i))
+		if err != nil {
+			t.Fatalf("start %d: %v", i, err)
+		}
+		ctxs = append(ctxs, ctx)
+	}
+	if _, _, err := streams.start(context.Background(), "one-more"); !errors.Is(err, errTooManyKubectlStreams) {
+		t.Errorf("start past the limit: err = %v", err)
+	}
+	streams.cancelAll()
+	for i, ctx := range ctxs {
+		if ctx.Err() == nil {
+			t.Errorf("stream %d still running after cancelAll", i)
+		}
+	}
+}
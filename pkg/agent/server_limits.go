@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// checkKubeconfigLimit reports whether importing kubeconfig keeps the
+// contexts within the cluster limit. Contexts that already exist are
+// skipped by the import and not counted. A kubeconfig that cannot be parsed
+// passes; the import reports it.
+func (s *Server) checkKubeconfigLimit(kubeconfig string) error {
+	if s.limits == nil {
+		return nil
+	}
+	entries, err := s.kubectl.PreviewKubeconfig(kubeconfig)
+	if err != nil {
+		return nil
+	}
+	adding := 0
+	for _, e := range entries {
+		if e.IsNew {
+			adding++
+		}
+	}
+	return s.checkClusterLimit(adding)
+}
+
+// checkClusterLimit reports whether adding more kubeconfig contexts stays
+// within the cluster limit.
+func (s *Server) checkClusterLimit(adding int) error {
+	if s.limits == nil {
+		return nil
+	}
+	contexts, _ := s.kubectl.ListContexts()
+	return s.limits.Check(limits.Clusters, len(contexts), adding)
+}
+
+// checkWorkloadDeploymentQuota reports whether wd stays within the quota of
+// the project owning its namespace: the clusters it targets, then how many
+// deployments the project created in the last day.
+func (s *Server) checkWorkloadDeploymentQuota(ctx context.Context, persistence k8s.ConsolePersistence, wd *v1alpha1.WorkloadDeployment) error {
+	if s.limits == nil {
+		return nil
+	}
+	project, quota, ok := s.limits.Quotas().ForNamespace(wd.Namespace)
+	if !ok {
+		return nil
+	}
+	if quota.MaxTargetClusters > 0 {
+		clusters, err := deploymentTargetClusterCount(ctx, persistence, wd)
+		if err != nil {
+			return err
+		}
+		if err := quota.CheckTargetClusters(project, clusters); err != nil {
+			return err
+		}
+	}
+	if quota.MaxDeploymentsPerDay > 0 {
+		var created []time.Time
+		for _, ns := range quota.Namespaces {
+			deployments, err := persistence.ListWorkloadDeployments(ctx, ns)
+			if err != nil {
+				return err
+			}
+			for _, d := range deployments {
+				created = append(created, d.CreationTimestamp.Time)
+			}
+		}
+		return quota.CheckDeploymentsPerDay(project, created, time.Now())
+	}
+	return nil
+}
+
+// deploymentTargetClusterCount returns how many distinct clusters wd
+// targets: its explicit clusters plus the current matches of its target
+// ClusterGroup. A group that does not exist adds none; the reconciler
+// reports it.
+func deploymentTargetClusterCount(ctx context.Context, persistence k8s.ConsolePersistence, wd *v1alpha1.WorkloadDeployment) (int, error) {
+	clusters := make(map[string]bool)
+	for _, c := range wd.Spec.TargetClusters {
+		clusters[c] = true
+	}
+	unnamed := 0
+	if ref := wd.Spec.TargetGroupRef; ref != nil && ref.Name != "" {
+		ns := ref.Namespace
+		if ns == "" {
+			ns = wd.Namespace
+		}
+		group, err := persistence.GetClusterGroup(ctx, ns, ref.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return 0, err
+		case len(group.Status.MatchedClusters) > 0:
+			for _, c := range group.Status.MatchedClusters {
+				clusters[c] = true
+			}
+		default:
+			// Only the count is recorded; it may overlap the explicit ones.
+			unnamed = group.Status.MatchedClusterCount
+		}
+	}
+	return len(clusters) + unnamed, nil
+}
+
+// writeLimitError writes the response of a creation refused by the resource
+// limits or a project quota: 429 with Retry-After when the project created
+// too many recently, 403 otherwise.
+func writeLimitError(w http.ResponseWriter, err error) {
+	var rate *limits.RateExceededError
+	if errors.As(err, &rate) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rate.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		writeJSON(w, limits.Payload(err))
+		return
+	}
+	w.WriteHeader(http.StatusForbidden)
+	writeJSON(w, limits.Payload(err))
+}
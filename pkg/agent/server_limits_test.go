@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
+	"github.com/kubestellar/console/pkg/settings"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+type agentTestLimits settings.LimitsSettings
+
+func (l agentTestLimits) GetLimits() settings.LimitsSettings { return settings.LimitsSettings(l) }
+
+func withLimits(l settings.LimitsSettings) serverTestOption {
+	return func(s *Server) {
+		s.limits = limits.NewEnforcer(limits.NewSettingsSource(agentTestLimits(l)))
+	}
+}
+
+const limitsTestKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: new-cluster
+  cluster:
+    server: https://new.example.com
+contexts:
+- name: new-ctx
+  context:
+    cluster: new-cluster
+    user: new-user
+- name: existing
+  context:
+    cluster: new-cluster
+    user: new-user
+users:
+- name: new-user
+  user:
+    token: fake-token
+`
+
+func kubeconfigImportBody(kc string) string {
+	body, _ := json.Marshal(kubeconfigImportRequest{Kubeconfig: kc})
+	return string(body)
+}
+
+func TestHandleKubeconfigImportHTTP_ClusterLimit(t *testing.T) {
+	s := newTestServer(t, withContexts("existing"), withLimits(settings.LimitsSettings{MaxClusters: 1}))
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import", strings.NewReader(kubeconfigImportBody(limitsTestKubeconfig)))
+	rec := serveAndRecord(s.handleKubeconfigImportHTTP, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want 403; body: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp["code"] != limits.CodeLimitExceeded || resp["resource"] != string(limits.Clusters) {
+		t.Fatalf("unexpected payload: %v", resp)
+	}
+	// Only new-ctx is new; the existing context does not count twice.
+	if resp["current"] != float64(1) || resp["adding"] != float64(1) {
+		t.Fatalf("unexpected counts: %v", resp)
+	}
+	if contexts, _ := s.kubectl.ListContexts(); len(contexts) != 1 {
+		t.Fatalf("refused import changed the kubeconfig: %d contexts", len(contexts))
+	}
+}
+
+func TestHandleKubeconfigImportHTTP_WithinClusterLimit(t *testing.T) {
+	s := newTestServer(t, withContexts("existing"), withLimits(settings.LimitsSettings{MaxClusters: 2}))
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/import", strings.NewReader(kubeconfigImportBody(limitsTestKubeconfig)))
+	rec := serveAndRecord(s.handleKubeconfigImportHTTP, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleKubeconfigAddHTTP_ClusterLimit(t *testing.T) {
+	s := newTestServer(t, withContexts("existing"), withLimits(settings.LimitsSettings{MaxClusters: 1}))
+	req := httptest.NewRequest(http.MethodPost, "/kubeconfig/add", strings.NewReader(
+		`{"contextName":"new-ctx","clusterName":"new-cluster","serverUrl":"https://new.example.com","authType":"token","token":"t"}`))
+	rec := serveAndRecord(s.handleKubeconfigAddHTTP, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want 403; body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServer_HandleConsoleCRManagedWorkloads_Limit(t *testing.T) {
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		v1alpha1.ManagedWorkloadGVR: "ManagedWorkloadList",
+	}))
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+	withLimits(settings.LimitsSettings{MaxManagedWorkloads: 1})(s)
+
+	create := func(name string) *httptest.ResponseRecorder {
+		mw := v1alpha1.ManagedWorkload{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.ManagedWorkloadSpec{
+				SourceCluster: "c1",
+				WorkloadRef:   v1alpha1.WorkloadReference{Name: name, Kind: "Deployment"},
+			},
+		}
+		body, _ := json.Marshal(mw)
+		req := httptest.NewRequest(http.MethodPost, "/console-cr/managedworkloads?cluster=persistence-cluster&namespace=test-ns", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleConsoleCRManagedWorkloads(w, req)
+		return w
+	}
+
+	if w := create("first"); w.Code != http.StatusCreated {
+		t.Fatalf("first: got %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	w := create("second")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("second: got %d, want 403; body: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["code"] != limits.CodeLimitExceeded || resp["resource"] != string(limits.ManagedWorkloads) {
+		t.Fatalf("unexpected payload: %v", resp)
+	}
+}
+
+func TestServer_HandleConsoleCRManagedWorkloads_ProjectQuota(t *testing.T) {
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		v1alpha1.ManagedWorkloadGVR: "ManagedWorkloadList",
+	}))
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+	withLimits(settings.LimitsSettings{Projects: map[string]settings.ProjectQuotaSettings{
+		"payments": {Namespaces: []string{"payments-a", "payments-b"}, MaxManagedWorkloads: 1},
+	}})(s)
+
+	create := func(name, namespace string) *httptest.ResponseRecorder {
+		mw := v1alpha1.ManagedWorkload{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.ManagedWorkloadSpec{
+				SourceCluster: "c1",
+				WorkloadRef:   v1alpha1.WorkloadReference{Name: name, Kind: "Deployment"},
+			},
+		}
+		body, _ := json.Marshal(mw)
+		req := httptest.NewRequest(http.MethodPost, "/console-cr/managedworkloads?cluster=persistence-cluster&namespace="+namespace, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleConsoleCRManagedWorkloads(w, req)
+		return w
+	}
+
+	if w := create("first", "payments-a"); w.Code != http.StatusCreated {
+		t.Fatalf("first: got %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	w := create("second", "payments-b")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("second: got %d, want 403; body: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["resource"] != string(limits.ProjectManagedWorkloads) || resp["project"] != "payments" {
+		t.Fatalf("unexpected payload: %v", resp)
+	}
+	if w := create("other", "platform-console"); w.Code != http.StatusCreated {
+		t.Fatalf("namespace without a quota: got %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_HandleConsoleCRWorkloadDeployments_ProjectQuota(t *testing.T) {
+	group := &v1alpha1.ClusterGroup{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"},
+		ObjectMeta: metav1.ObjectMeta{Name: "fleet", Namespace: "payments-console"},
+		Status:     v1alpha1.ClusterGroupStatus{MatchedClusters: []string{"c1", "c2", "c3"}, MatchedClusterCount: 3},
+	}
+	u, err := group.ToUnstructured()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		v1alpha1.WorkloadDeploymentGVR: "WorkloadDeploymentList",
+		v1alpha1.ClusterGroupGVR:       "ClusterGroupList",
+	}, u))
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+	withLimits(settings.LimitsSettings{Projects: map[string]settings.ProjectQuotaSettings{
+		"payments": {Namespaces: []string{"payments-console"}, MaxDeploymentsPerDay: 1, MaxTargetClusters: 3},
+	}})(s)
+
+	create := func(name string, spec v1alpha1.WorkloadDeploymentSpec) *httptest.ResponseRecorder {
+		spec.WorkloadRef = v1alpha1.ResourceReference{Name: "app"}
+		body, _ := json.Marshal(v1alpha1.WorkloadDeployment{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec})
+		req := httptest.NewRequest(http.MethodPost, "/console-cr/workloaddeployments?cluster=persistence-cluster&namespace=payments-console", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleConsoleCRWorkloadDeployments(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		var resp map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	// The group's three clusters plus a fourth explicit one are too many.
+	w := create("wide", v1alpha1.WorkloadDeploymentSpec{
+		TargetGroupRef: &v1alpha1.ResourceReference{Name: "fleet"},
+		TargetClusters: []string{"c1", "c4"},
+	})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("wide: got %d, want 403; body: %s", w.Code, w.Body.String())
+	}
+	if resp := decode(w); resp["resource"] != string(limits.TargetClusters) || resp["adding"] != float64(4) {
+		t.Fatalf("unexpected payload: %v", resp)
+	}
+
+	if w := create("first", v1alpha1.WorkloadDeploymentSpec{TargetGroupRef: &v1alpha1.ResourceReference{Name: "fleet"}, TargetClusters: []string{"c1"}}); w.Code != http.StatusCreated {
+		t.Fatalf("first: got %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	w = create("second", v1alpha1.WorkloadDeploymentSpec{TargetClusters: []string{"c1"}})
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second: got %d, want 429; body: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatal("429 without Retry-After")
+	}
+	if resp := decode(w); resp["code"] != limits.CodeRateLimited || resp["project"] != "payments" {
+		t.Fatalf("unexpected payload: %v", resp)
+	}
+}
@@ -147,6 +147,13 @@ func (s *Server) handleLocalClusters(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := s.checkClusterLimit(1); err != nil {
+			slog.Warn("[LocalClusters] cluster creation refused", "cluster", req.Name, "error", err)
+			w.Header().Set("Content-Type", "application/json")
+			writeLimitError(w, err)
+			return
+		}
+
 		// Create cluster in background and return immediately
 		s.clusterOpsWG.Add(1)
 		safego.GoWith("local-cluster-create", func() {
@@ -480,6 +487,9 @@ func (s *Server) handleVClusterConnect(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name      string `json:"name"`
 		Namespace string `json:"namespace"`
+		// HostContext is the context of the cluster the vCluster runs in,
+		// as reported by /vcluster/check. Empty uses the current context.
+		HostContext string `json:"hostContext,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -500,8 +510,15 @@ func (s *Server) handleVClusterConnect(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid namespace", http.StatusBadRequest)
 		return
 	}
+	if req.HostContext != "" {
+		if err := kube.ValidateKubeContext(req.HostContext); err != nil {
+			slog.Warn("[vCluster] invalid host context", "context", req.HostContext, "error", err)
+			http.Error(w, "invalid host context", http.StatusBadRequest)
+			return
+		}
+	}
 
-	if err := s.localClusters.ConnectVCluster(req.Name, req.Namespace); err != nil {
+	if err := s.localClusters.ConnectVClusterOnHost(req.Name, req.Namespace, req.HostContext); err != nil {
 		slog.Error("[vCluster] failed to connect to vcluster", "name", req.Name, "error", err)
 		s.BroadcastToClients("local_cluster_progress", map[string]interface{}{
 			"tool":     "vcluster",
@@ -514,7 +531,7 @@ func (s *Server) handleVClusterConnect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	slog.Info("[vCluster] connected to vcluster", "name", req.Name, "namespace", req.Namespace)
+	slog.Info("[vCluster] connected to vcluster", "name", req.Name, "namespace", req.Namespace, "hostContext", req.HostContext)
 	s.BroadcastToClients("local_cluster_progress", map[string]interface{}{
 		"tool":     "vcluster",
 		"name":     req.Name,
@@ -522,12 +539,17 @@ func (s *Server) handleVClusterConnect(w http.ResponseWriter, r *http.Request) {
 		"message":  fmt.Sprintf("Connected to vCluster '%s'", req.Name),
 		"progress": progressDone,
 	})
-	writeJSON(w, map[string]interface{}{
+	resp := map[string]interface{}{
 		"status":    "connected",
 		"name":      req.Name,
 		"namespace": req.Namespace,
 		"message":   fmt.Sprintf("Connected to vCluster '%s'", req.Name),
-	})
+	}
+	if req.HostContext != "" {
+		resp["hostContext"] = req.HostContext
+		resp["context"] = kube.VClusterContextName(req.Name, req.Namespace, req.HostContext)
+	}
+	writeJSON(w, resp)
 }
 
 // handleVClusterDisconnect disconnects from a vCluster
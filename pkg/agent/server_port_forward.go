@@ -0,0 +1,678 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kubestellar/console/pkg/agent/kube"
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/kubestellar/console/pkg/safego"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// maxPortForwardsPerConn caps the port-forward sessions one connection
+	// holds open. Each keeps a goroutine slot until it ends, like a kubectl
+	// stream.
+	maxPortForwardsPerConn = 5
+	// maxPortForwardConnections caps the TCP connections one session
+	// tunnels at once.
+	maxPortForwardConnections = 16
+	// portForwardIdleTimeout ends a session that has had no open connection
+	// for this long, so a forgotten forward does not hold a tunnel to the pod.
+	portForwardIdleTimeout = 10 * time.Minute
+	// portForwardOpenTimeout bounds resolving the target pod.
+	portForwardOpenTimeout = 30 * time.Second
+	// portForwardReadBufferBytes is the most pod bytes one port_forward_data
+	// message carries.
+	portForwardReadBufferBytes = 32 * 1024
+	// portForwardSendQueueSize is how many port_forward_send messages one
+	// connection buffers while they are written to the pod. Dropping bytes
+	// would corrupt the stream, so a client that outruns the pod loses the
+	// connection instead.
+	portForwardSendQueueSize = 64
+)
+
+var (
+	errTooManyPortForwards      = fmt.Errorf("at most %d port-forward sessions may run per connection", maxPortForwardsPerConn)
+	errDuplicatePortForward     = errors.New("a port-forward session with this ID is already running")
+	errTooManyPortForwardConns  = fmt.Errorf("at most %d connections may be open per port-forward session", maxPortForwardConnections)
+	errPortForwardConnReused    = errors.New("connection IDs cannot be reused within a session")
+	errPortForwardSendQueueFull = errors.New("send buffer full: the client sent faster than the pod reads")
+	errPortForwardStopped       = errors.New("stopped")
+	errPortForwardIdle          = errors.New("idle timeout")
+	errPortForwardLostPod       = errors.New("lost connection to pod")
+	errPortForwardClientGone    = errors.New("WebSocket closed")
+)
+
+// portForwards tracks the port-forward sessions running on one connection
+// by the ID of the message that started them. Being per connection, a
+// client can only use and stop its own sessions.
+type portForwards struct {
+	mu       sync.Mutex
+	sessions map[string]*portForwardEntry
+}
+
+type portForwardEntry struct {
+	cancel  context.CancelCauseFunc
+	session *portForwardSession // nil until the tunnel is open
+}
+
+func newPortForwards() *portForwards {
+	return &portForwards{sessions: make(map[string]*portForwardEntry)}
+}
+
+// start reserves a session and returns its context, derived from parent,
+// and the function that unregisters it.
+func (pf *portForwards) start(parent context.Context, id string) (context.Context, func(), error) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if _, dup := pf.sessions[id]; dup {
+		return nil, nil, errDuplicatePortForward
+	}
+	if len(pf.sessions) >= maxPortForwardsPerConn {
+		return nil, nil, errTooManyPortForwards
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	pf.sessions[id] = &portForwardEntry{cancel: cancel}
+	return ctx, func() {
+		pf.mu.Lock()
+		delete(pf.sessions, id)
+		pf.mu.Unlock()
+		cancel(nil)
+	}, nil
+}
+
+// attach makes a started session's connections reachable by send.
+func (pf *portForwards) attach(session *portForwardSession) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if entry, ok := pf.sessions[session.id]; ok {
+		entry.session = session
+	}
+}
+
+// get returns the open session id, or nil.
+func (pf *portForwards) get(id string) *portForwardSession {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if entry, ok := pf.sessions[id]; ok {
+		return entry.session
+	}
+	return nil
+}
+
+// stop ends session id and reports whether it was running.
+func (pf *portForwards) stop(id string) bool {
+	pf.mu.Lock()
+	entry, ok := pf.sessions[id]
+	pf.mu.Unlock()
+	if ok {
+		entry.cancel(errPortForwardStopped)
+	}
+	return ok
+}
+
+// stopAll ends every session, when the connection closes.
+func (pf *portForwards) stopAll() {
+	pf.mu.Lock()
+	cancels := make([]context.CancelCauseFunc, 0, len(pf.sessions))
+	for _, entry := range pf.sessions {
+		cancels = append(cancels, entry.cancel)
+	}
+	pf.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel(errPortForwardClientGone)
+	}
+}
+
+// portForwardSession tunnels the client's TCP connections to one pod port
+// over a single port-forward connection to the apiserver.
+type portForwardSession struct {
+	id          string
+	port        int
+	tunnel      httpstream.Connection
+	send        func(protocol.Message) error
+	idleTimeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu            sync.Mutex
+	conns         map[string]*portForwardConn
+	ended         map[string]bool
+	idleSince     time.Time
+	nextRequestID int
+}
+
+// portForwardConn is one tunnelled TCP connection. queue holds the client's
+// bytes until they are written to the pod, and is closed once the client
+// closes its side.
+type portForwardConn struct {
+	id     string
+	queue  chan []byte
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	closed bool
+}
+
+func newPortForwardSession(parent context.Context, id string, port int, tunnel httpstream.Connection, send func(protocol.Message) error) *portForwardSession {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &portForwardSession{
+		id:          id,
+		port:        port,
+		tunnel:      tunnel,
+		send:        send,
+		idleTimeout: portForwardIdleTimeout,
+		ctx:         ctx,
+		cancel:      cancel,
+		conns:       make(map[string]*portForwardConn),
+		ended:       make(map[string]bool),
+		idleSince:   time.Now(),
+	}
+}
+
+// write queues data for connection connID, opening the connection on its
+// first data and closing the client's side of it when closeConn is set. It
+// never blocks, so it can run on the read loop.
+func (s *portForwardSession) write(connID string, data []byte, closeConn bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return nil
+	}
+	c, ok := s.conns[connID]
+	if !ok {
+		if s.ended[connID] {
+			if closeConn {
+				return nil
+			}
+			return errPortForwardConnReused
+		}
+		if len(data) == 0 && closeConn {
+			return nil
+		}
+		if len(s.conns) >= maxPortForwardConnections {
+			return errTooManyPortForwardConns
+		}
+		c = s.open(connID)
+	}
+	if c.closed {
+		return nil
+	}
+	if len(data) > 0 {
+		select {
+		case c.queue <- data:
+		default:
+			c.cancel(errPortForwardSendQueueFull)
+			return nil
+		}
+	}
+	if closeConn {
+		c.closed = true
+		close(c.queue)
+	}
+	return nil
+}
+
+// open starts forwarding a new connection. s.mu must be held.
+func (s *portForwardSession) open(connID string) *portForwardConn {
+	ctx, cancel := context.WithCancelCause(s.ctx)
+	c := &portForwardConn{id: connID, queue: make(chan []byte, portForwardSendQueueSize), ctx: ctx, cancel: cancel}
+	s.conns[connID] = c
+	s.nextRequestID++
+	requestID := s.nextRequestID
+	s.wg.Add(1)
+	safego.GoWith("port-forward-conn", func() { s.serve(c, requestID) })
+	return c
+}
+
+// serve forwards c until either side closes it, then tells the client the
+// connection is gone, unless the whole session ended.
+func (s *portForwardSession) serve(c *portForwardConn, requestID int) {
+	defer s.wg.Done()
+	err := s.forward(c, requestID)
+	c.cancel(nil)
+
+	s.mu.Lock()
+	delete(s.conns, c.id)
+	s.ended[c.id] = true
+	if len(s.conns) == 0 {
+		s.idleSince = time.Now()
+	}
+	s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+	payload := protocol.PortForwardDataPayload{SessionID: s.id, ConnectionID: c.id, Close: true}
+	if err != nil {
+		payload.Error = err.Error()
+	}
+	_ = s.send(protocol.Message{ID: s.id, Type: protocol.TypePortForwardData, Payload: payload})
+}
+
+// forward opens the error and data streams of one connection, the way
+// kubectl port-forward does, and copies bytes both ways until the pod
+// closes its side, a write to the pod fails or c is cancelled.
+func (s *portForwardSession) forward(c *portForwardConn, requestID int) error {
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(s.port))
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.Itoa(requestID))
+	errorStream, err := s.tunnel.CreateStream(headers)
+	if err != nil {
+		return fmt.Errorf("failed to open connection to pod: %w", err)
+	}
+	// Nothing is written to the error stream.
+	errorStream.Close()
+	defer s.tunnel.RemoveStreams(errorStream)
+
+	remoteErr := make(chan error, 1)
+	safego.GoWith("port-forward-error-stream", func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			remoteErr <- fmt.Errorf("failed to read error stream: %w", err)
+		case len(message) > 0:
+			remoteErr <- errors.New(string(message))
+		default:
+			remoteErr <- nil
+		}
+	})
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := s.tunnel.CreateStream(headers)
+	if err != nil {
+		return fmt.Errorf("failed to open connection to pod: %w", err)
+	}
+	defer s.tunnel.RemoveStreams(dataStream)
+
+	remoteDone := make(chan struct{})
+	safego.GoWith("port-forward-from-pod", func() {
+		defer close(remoteDone)
+		s.copyFromPod(c, dataStream)
+	})
+	localErr := make(chan error, 1)
+	safego.GoWith("port-forward-to-pod", func() {
+		if err := copyToPod(c, dataStream); err != nil {
+			localErr <- err
+		}
+	})
+
+	select {
+	case <-remoteDone:
+	case err = <-localErr:
+	case <-c.ctx.Done():
+		err = context.Cause(c.ctx)
+	}
+	// Discard unsent data so the pod's error stream is not held up behind it.
+	_ = dataStream.Reset()
+	<-remoteDone
+	if rerr := <-remoteErr; rerr != nil && err == nil {
+		err = rerr
+	}
+	if s.ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// copyFromPod sends what the pod writes to the client as port_forward_data
+// messages until the pod closes its side or the stream is reset.
+func (s *portForwardSession) copyFromPod(c *portForwardConn, r io.Reader) {
+	buf := make([]byte, portForwardReadBufferBytes)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if sendErr := s.send(protocol.Message{
+				ID:   s.id,
+				Type: protocol.TypePortForwardData,
+				Payload: protocol.PortForwardDataPayload{
+					SessionID:    s.id,
+					ConnectionID: c.id,
+					Data:         base64.StdEncoding.EncodeToString(buf[:n]),
+				},
+			}); sendErr != nil {
+				c.cancel(errPortForwardClientGone)
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// copyToPod writes the client's queued bytes to the pod. Once the client
+// closes its side it closes the pod's write side, leaving the pod's answer
+// to arrive.
+func copyToPod(c *portForwardConn, w io.WriteCloser) error {
+	for {
+		select {
+		case data, ok := <-c.queue:
+			if !ok {
+				return w.Close()
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		case <-c.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// run keeps the session open until it is cancelled, the tunnel to the pod
+// drops or it has been idle for idleTimeout, then closes every connection
+// and returns why it ended.
+func (s *portForwardSession) run() error {
+	checkEvery := min(time.Minute, s.idleTimeout)
+	ticker := time.NewTicker(checkEvery)
+	defer ticker.Stop()
+
+	var reason error
+	for reason == nil {
+		select {
+		case <-s.ctx.Done():
+			reason = context.Cause(s.ctx)
+			if errors.Is(reason, context.Canceled) {
+				reason = errPortForwardClientGone
+			}
+		case <-s.tunnel.CloseChan():
+			reason = errPortForwardLostPod
+		case <-ticker.C:
+			s.mu.Lock()
+			idle := len(s.conns) == 0 && time.Since(s.idleSince) >= s.idleTimeout
+			s.mu.Unlock()
+			if idle {
+				reason = errPortForwardIdle
+			}
+		}
+	}
+	// Under s.mu, so write cannot open a connection once wg.Wait starts.
+	s.mu.Lock()
+	s.cancel(reason)
+	s.mu.Unlock()
+	_ = s.tunnel.Close()
+	s.wg.Wait()
+	return reason
+}
+
+// resolvePortForwardTarget returns the pod and pod port req forwards to. A
+// service resolves, like kubectl port-forward svc/NAME, to the first of its
+// running pods by name and to the target port of the requested service port.
+func resolvePortForwardTarget(ctx context.Context, client kubernetes.Interface, req protocol.PortForwardRequest) (string, int, error) {
+	if req.Pod != "" {
+		pod, err := client.CoreV1().Pods(req.Namespace).Get(ctx, req.Pod, metav1.GetOptions{})
+		if err != nil {
+			return "", 0, err
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			return "", 0, fmt.Errorf("pod %s is not running (phase %s)", pod.Name, pod.Status.Phase)
+		}
+		return pod.Name, req.Port, nil
+	}
+
+	svc, err := client.CoreV1().Services(req.Namespace).Get(ctx, req.Service, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s has no selector to find its pods by", svc.Name)
+	}
+	var svcPort *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if int(svc.Spec.Ports[i].Port) == req.Port {
+			svcPort = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if svcPort == nil {
+		return "", 0, fmt.Errorf("service %s has no port %d", svc.Name, req.Port)
+	}
+	pods, err := client.CoreV1().Pods(req.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		port, err := podTargetPort(pod, *svcPort)
+		if err != nil {
+			return "", 0, err
+		}
+		return pod.Name, port, nil
+	}
+	return "", 0, fmt.Errorf("service %s has no running pods", svc.Name)
+}
+
+// podTargetPort returns the port of pod that svcPort's target port names.
+func podTargetPort(pod *corev1.Pod, svcPort corev1.ServicePort) (int, error) {
+	target := svcPort.TargetPort
+	if target.StrVal == "" {
+		if target.IntVal == 0 {
+			return int(svcPort.Port), nil
+		}
+		return int(target.IntVal), nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == target.StrVal && port.Protocol != corev1.ProtocolUDP && port.Protocol != corev1.ProtocolSCTP {
+				return int(port.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %s has no port named %s", pod.Name, target.StrVal)
+}
+
+// validatePortForwardRequest checks req's names and port before anything
+// is sent to a cluster.
+func validatePortForwardRequest(req protocol.PortForwardRequest) (code string, err error) {
+	if err := kube.ValidateKubeContext(req.Context); err != nil {
+		return "invalid_context", err
+	}
+	if err := kube.ValidateDNS1123Label("namespace", req.Namespace); err != nil {
+		return "invalid_namespace", err
+	}
+	if !kube.AllowsNamespace(req.Namespace) {
+		return "invalid_namespace", fmt.Errorf("namespace %s is not allowed by the kubectl policy", req.Namespace)
+	}
+	switch {
+	case (req.Pod == "") == (req.Service == ""):
+		return "invalid_payload", errors.New("exactly one of pod and service is required")
+	case req.Pod != "":
+		if errs := validation.IsDNS1123Subdomain(req.Pod); len(errs) > 0 {
+			return "invalid_payload", fmt.Errorf("invalid pod %q: %s", req.Pod, errs[0])
+		}
+	default:
+		if err := kube.ValidateDNS1123Label("service", req.Service); err != nil {
+			return "invalid_payload", err
+		}
+	}
+	if errs := validation.IsValidPortNum(req.Port); len(errs) > 0 {
+		return "invalid_payload", fmt.Errorf("invalid port %d: %s", req.Port, errs[0])
+	}
+	return "", nil
+}
+
+// dialPortForward opens the port-forward connection to pod with the user's
+// kubeconfig, so the apiserver checks pods/portforward against the user.
+func (s *Server) dialPortForward(kubeContext, namespace, pod string) (httpstream.Connection, error) {
+	clientset, err := s.k8sClient.GetClient(kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	restConfig, err := s.k8sClient.GetRestConfig(kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	url := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+	tunnel, protocolName, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, err
+	}
+	if protocolName != portforward.PortForwardProtocolV1Name {
+		_ = tunnel.Close()
+		return nil, fmt.Errorf("apiserver does not support %s", portforward.PortForwardProtocolV1Name)
+	}
+	return tunnel, nil
+}
+
+// handlePortForward opens a port_forward session and keeps it open until it
+// is stopped, goes idle, loses its pod or the WebSocket closes, then sends
+// port_forward_closed. Runs in a goroutine, like handleKubectlStream.
+func (s *Server) handlePortForward(connCtx context.Context, conn *websocket.Conn, msg protocol.Message, forwards *portForwards, writeMu *sync.Mutex, closed *atomic.Bool) {
+	send := newWSMessageSender(conn, writeMu, closed, "[PortForward]")
+	fail := func(code, message string) {
+		_ = send(s.errorResponse(msg.ID, code, message))
+	}
+
+	payloadBytes, err := json.Marshal(msg.Payload)
+	if err != nil {
+		fail("invalid_payload", "Failed to parse port-forward request")
+		return
+	}
+	var req protocol.PortForwardRequest
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		fail("invalid_payload", "Invalid port-forward request format")
+		return
+	}
+	if code, err := validatePortForwardRequest(req); err != nil {
+		fail(code, err.Error())
+		return
+	}
+	if s.k8sClient == nil {
+		fail("k8s_unavailable", "k8s client not initialized")
+		return
+	}
+
+	ctx, done, err := forwards.start(connCtx, msg.ID)
+	if err != nil {
+		fail("port_forward_rejected", err.Error())
+		return
+	}
+	defer done()
+
+	clientset, err := s.k8sClient.GetClient(req.Context)
+	if err != nil {
+		slog.Error("[PortForward] failed to get client", "cluster", req.Context, "error", err)
+		fail("port_forward_failed", "Failed to get cluster client")
+		return
+	}
+	resolveCtx, cancel := context.WithTimeout(ctx, portForwardOpenTimeout)
+	pod, port, err := resolvePortForwardTarget(resolveCtx, clientset, req)
+	cancel()
+	if err != nil {
+		fail("port_forward_failed", err.Error())
+		return
+	}
+	tunnel, err := s.dialPortForward(req.Context, req.Namespace, pod)
+	if err != nil {
+		slog.Error("[PortForward] failed to open tunnel", "cluster", req.Context,
+			"namespace", req.Namespace, "pod", pod, "error", err)
+		fail("port_forward_failed", "Failed to open port-forward to pod "+pod+": "+err.Error())
+		return
+	}
+
+	session := newPortForwardSession(ctx, msg.ID, port, tunnel, send)
+	forwards.attach(session)
+	slog.Info("[PortForward] session opened", "session", msg.ID, "cluster", req.Context,
+		"namespace", req.Namespace, "pod", pod, "port", port)
+	_ = send(protocol.Message{
+		ID:      msg.ID,
+		Type:    protocol.TypeResult,
+		Payload: protocol.PortForwardResponse{SessionID: msg.ID, Pod: pod, Port: port},
+	})
+
+	reason := session.run()
+	slog.Info("[PortForward] session ended", "session", msg.ID, "reason", reason)
+	_ = send(protocol.Message{
+		ID:      msg.ID,
+		Type:    protocol.TypePortForwardClosed,
+		Payload: protocol.PortForwardClosedPayload{SessionID: msg.ID, Reason: reason.Error()},
+	})
+}
+
+// handlePortForwardSend passes a port_forward_send message to its session.
+// It runs on the read loop and never blocks; a message it cannot deliver
+// closes its connection with an error.
+func (s *Server) handlePortForwardSend(conn *websocket.Conn, msg protocol.Message, forwards *portForwards, writeMu *sync.Mutex, closed *atomic.Bool) {
+	var p protocol.PortForwardDataPayload
+	if payloadBytes, err := json.Marshal(msg.Payload); err == nil {
+		_ = json.Unmarshal(payloadBytes, &p)
+	}
+	session := forwards.get(p.SessionID)
+	if session == nil || p.ConnectionID == "" {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(p.Data)
+	if err == nil {
+		err = session.write(p.ConnectionID, data, p.Close)
+	}
+	if err != nil {
+		send := newWSMessageSender(conn, writeMu, closed, "[PortForward]")
+		_ = send(protocol.Message{
+			ID:   p.SessionID,
+			Type: protocol.TypePortForwardData,
+			Payload: protocol.PortForwardDataPayload{
+				SessionID:    p.SessionID,
+				ConnectionID: p.ConnectionID,
+				Close:        true,
+				Error:        err.Error(),
+			},
+		})
+	}
+}
+
+// handleStopPortForward ends one of this connection's port-forward
+// sessions. The session still ends with port_forward_closed.
+func (s *Server) handleStopPortForward(conn *websocket.Conn, msg protocol.Message, forwards *portForwards, writeMu *sync.Mutex, closed *atomic.Bool) {
+	var req protocol.StopPortForwardRequest
+	if payloadBytes, err := json.Marshal(msg.Payload); err == nil {
+		_ = json.Unmarshal(payloadBytes, &req)
+	}
+	stopped := forwards.stop(req.SessionID)
+	slog.Info("[PortForward] stop requested", "session", req.SessionID, "stopped", stopped)
+	send := newWSMessageSender(conn, writeMu, closed, "[PortForward]")
+	_ = send(protocol.Message{
+		ID:      msg.ID,
+		Type:    protocol.TypeResult,
+		Payload: protocol.StopPortForwardResponse{Stopped: stopped, SessionID: req.SessionID},
+	})
+}
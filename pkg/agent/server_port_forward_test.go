@@ -0,0 +1,304 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/agent/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+// refusedPort is a port the fake pod refuses connections on.
+const refusedPort = 9999
+
+// fakeStream is one side of a stream of fakeTunnel: the agent reads what
+// the pod writes to r's writer and writes to w.
+type fakeStream struct {
+	headers http.Header
+	r       *io.PipeReader
+	w       *io.PipeWriter
+}
+
+func (s *fakeStream) Read(p []byte) (int, error)  { return s.r.Read(p) }
+func (s *fakeStream) Write(p []byte) (int, error) { return s.w.Write(p) }
+func (s *fakeStream) Close() error                { return s.w.Close() }
+func (s *fakeStream) Headers() http.Header        { return s.headers }
+func (s *fakeStream) Identifier() uint32          { return 0 }
+func (s *fakeStream) Reset() error {
+	s.r.CloseWithError(io.ErrClosedPipe)
+	return s.w.CloseWithError(io.ErrClosedPipe)
+}
+
+// fakeTunnel is a port-forward connection to a pod that echoes every
+// connection's bytes back until the agent closes its side, and refuses
+// connections to refusedPort.
+type fakeTunnel struct {
+	mu        sync.Mutex
+	errWriter map[string]*io.PipeWriter
+	closeCh   chan bool
+	closeOnce sync.Once
+}
+
+func newFakeTunnel() *fakeTunnel {
+	return &fakeTunnel{errWriter: map[string]*io.PipeWriter{}, closeCh: make(chan bool)}
+}
+
+func (t *fakeTunnel) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	requestID := headers.Get(corev1.PortForwardRequestIDHeader)
+	if headers.Get(corev1.StreamType) == corev1.StreamTypeError {
+		errR, errW := io.Pipe()
+		_, discard := io.Pipe()
+		t.errWriter[requestID] = errW
+		return &fakeStream{headers: headers.Clone(), r: errR, w: discard}, nil
+	}
+	errW := t.errWriter[requestID]
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go func() {
+		defer errW.Close()
+		defer outW.Close()
+		if headers.Get(corev1.PortHeader) == strconv.Itoa(refusedPort) {
+			_, _ = errW.Write([]byte("connection refused"))
+			return
+		}
+		_, _ = io.Copy(outW, inR)
+	}()
+	return &fakeStream{headers: headers.Clone(), r: outR, w: inW}, nil
+}
+
+func (t *fakeTunnel) Close() error {
+	t.closeOnce.Do(func() { close(t.closeCh) })
+	return nil
+}
+
+func (t *fakeTunnel) CloseChan() <-chan bool             { return t.closeCh }
+func (t *fakeTunnel) SetIdleTimeout(time.Duration)       {}
+func (t *fakeTunnel) RemoveStreams(...httpstream.Stream) {}
+
+// recordedMessages collects what a session sends to the client.
+type recordedMessages chan protocol.Message
+
+func (r recordedMessages) send(msg protocol.Message) error {
+	r <- msg
+	return nil
+}
+
+func (r recordedMessages) next(t *testing.T) protocol.PortForwardDataPayload {
+	t.Helper()
+	select {
+	case msg := <-r:
+		require.Equal(t, protocol.TypePortForwardData, msg.Type)
+		return msg.Payload.(protocol.PortForwardDataPayload)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no port_forward_data message")
+		return protocol.PortForwardDataPayload{}
+	}
+}
+
+func TestPortForwardSession_ForwardsConnections(t *testing.T) {
+	msgs := make(recordedMessages, 16)
+	s := newPortForwardSession(context.Background(), "pf-1", 8080, newFakeTunnel(), msgs.send)
+	defer s.cancel(nil)
+
+	require.NoError(t, s.write("c1", []byte("hello"), false))
+	got := msgs.next(t)
+	data, err := base64.StdEncoding.DecodeString(got.Data)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "c1", got.ConnectionID)
+
+	require.NoError(t, s.write("c1", nil, true))
+	got = msgs.next(t)
+	assert.True(t, got.Close, "the pod closes its side after the client")
+	assert.Empty(t, got.Error)
+
+	assert.ErrorIs(t, s.write("c1", []byte("again"), false), errPortForwardConnReused)
+	assert.NoError(t, s.write("c1", nil, true), "a late close of an ended connection is ignored")
+}
+
+func TestPortForwardSession_PodError(t *testing.T) {
+	msgs := make(recordedMessages, 16)
+	s := newPortForwardSession(context.Background(), "pf-1", refusedPort, newFakeTunnel(), msgs.send)
+	defer s.cancel(nil)
+
+	require.NoError(t, s.write("c1", []byte("hello"), false))
+	got := msgs.next(t)
+	assert.True(t, got.Close)
+	assert.Equal(t, "connection refused", got.Error)
+}
+
+func TestPortForwardSession_ConnectionLimit(t *testing.T) {
+	msgs := make(recordedMessages, maxPortForwardConnections)
+	s := newPortForwardSession(context.Background(), "pf-1", 8080, newFakeTunnel(), msgs.send)
+	defer s.cancel(nil)
+
+	for i := range maxPortForwardConnections {
+		require.NoError(t, s.write("c"+strconv.Itoa(i), []byte("x"), false))
+	}
+	assert.ErrorIs(t, s.write("one-more", []byte("x"), false), errTooManyPortForwardConns)
+}
+
+func TestPortForwardSession_Run(t *testing.T) {
+	t.Run("idle", func(t *testing.T) {
+		s := newPortForwardSession(context.Background(), "pf-1", 8080, newFakeTunnel(), make(recordedMessages, 1).send)
+		s.idleTimeout = 10 * time.Millisecond
+		assert.ErrorIs(t, s.run(), errPortForwardIdle)
+	})
+	t.Run("lost pod", func(t *testing.T) {
+		tunnel := newFakeTunnel()
+		s := newPortForwardSession(context.Background(), "pf-1", 8080, tunnel, make(recordedMessages, 1).send)
+		_ = tunnel.Close()
+		assert.ErrorIs(t, s.run(), errPortForwardLostPod)
+	})
+	t.Run("stopped with open connections", func(t *testing.T) {
+		forwards := newPortForwards()
+		ctx, done, err := forwards.start(context.Background(), "pf-1")
+		require.NoError(t, err)
+		defer done()
+		msgs := make(recordedMessages, 16)
+		s := newPortForwardSession(ctx, "pf-1", 8080, newFakeTunnel(), msgs.send)
+		forwards.attach(s)
+		require.NoError(t, s.write("c1", []byte("x"), false))
+		msgs.next(t)
+
+		require.True(t, forwards.stop("pf-1"))
+		assert.ErrorIs(t, s.run(), errPortForwardStopped)
+		assert.Empty(t, s.conns, "run waits for every connection to end")
+		assert.NoError(t, s.write("c2", []byte("x"), false))
+		assert.Empty(t, s.conns, "an ended session opens no connections")
+	})
+}
+
+func TestPortForwards(t *testing.T) {
+	forwards := newPortForwards()
+	ctx, done, err := forwards.start(context.Background(), "pf-1")
+	require.NoError(t, err)
+	_, _, err = forwards.start(context.Background(), "pf-1")
+	assert.ErrorIs(t, err, errDuplicatePortForward)
+	assert.Nil(t, forwards.get("pf-1"), "no session until the tunnel is open")
+
+	s := newPortForwardSession(ctx, "pf-1", 8080, newFakeTunnel(), make(recordedMessages, 1).send)
+	forwards.attach(s)
+	assert.Same(t, s, forwards.get("pf-1"))
+	done()
+	assert.Nil(t, forwards.get("pf-1"))
+	assert.False(t, forwards.stop("pf-1"))
+
+	var ctxs []context.Context
+	for i := range maxPortForwardsPerConn {
+		ctx, _, err := forwards.start(context.Background(), "s"+strconv.Itoa(i))
+		require.NoError(t, err)
+		ctxs = append(ctxs, ctx)
+	}
+	_, _, err = forwards.start(context.Background(), "one-more")
+	assert.ErrorIs(t, err, errTooManyPortForwards)
+	forwards.stopAll()
+	for _, ctx := range ctxs {
+		assert.True(t, errors.Is(context.Cause(ctx), errPortForwardClientGone))
+	}
+}
+
+func TestResolvePortForwardTarget(t *testing.T) {
+	running := corev1.PodStatus{Phase: corev1.PodRunning}
+	labels := map[string]string{"app": "web"}
+	client := fakek8s.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-b", Namespace: "shop", Labels: labels},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "web",
+				Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			}}},
+			Status: running,
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-a", Namespace: "shop", Labels: labels},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: corev1.ServiceSpec{Selector: labels, Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("http")},
+				{Port: 81, TargetPort: intstr.FromInt32(9000)},
+				{Port: 82},
+			}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "shop"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 80}}},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		req      protocol.PortForwardRequest
+		wantPod  string
+		wantPort int
+		wantErr  string
+	}{
+		{name: "pod", req: protocol.PortForwardRequest{Pod: "web-b", Port: 5432}, wantPod: "web-b", wantPort: 5432},
+		{name: "pod not running", req: protocol.PortForwardRequest{Pod: "web-a", Port: 80}, wantErr: "not running"},
+		{name: "missing pod", req: protocol.PortForwardRequest{Pod: "nope", Port: 80}, wantErr: "not found"},
+		{name: "named target port", req: protocol.PortForwardRequest{Service: "web", Port: 80}, wantPod: "web-b", wantPort: 8080},
+		{name: "numeric target port", req: protocol.PortForwardRequest{Service: "web", Port: 81}, wantPod: "web-b", wantPort: 9000},
+		{name: "default target port", req: protocol.PortForwardRequest{Service: "web", Port: 82}, wantPod: "web-b", wantPort: 82},
+		{name: "unknown service port", req: protocol.PortForwardRequest{Service: "web", Port: 443}, wantErr: "has no port 443"},
+		{name: "service without selector", req: protocol.PortForwardRequest{Service: "external", Port: 80}, wantErr: "no selector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Namespace = "shop"
+			pod, port, err := resolvePortForwardTarget(context.Background(), client, tt.req)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPod, pod)
+			assert.Equal(t, tt.wantPort, port)
+		})
+	}
+}
+
+func TestValidatePortForwardRequest(t *testing.T) {
+	valid := protocol.PortForwardRequest{Context: "prod", Namespace: "shop", Pod: "web-0", Port: 8080}
+	tests := []struct {
+		name     string
+		mutate   func(r *protocol.PortForwardRequest)
+		wantCode string
+	}{
+		{name: "valid pod", mutate: func(*protocol.PortForwardRequest) {}},
+		{name: "valid service", mutate: func(r *protocol.PortForwardRequest) { r.Pod, r.Service = "", "web" }},
+		{name: "bad context", mutate: func(r *protocol.PortForwardRequest) { r.Context = "--kubeconfig=x" }, wantCode: "invalid_context"},
+		{name: "no namespace", mutate: func(r *protocol.PortForwardRequest) { r.Namespace = "" }, wantCode: "invalid_namespace"},
+		{name: "no target", mutate: func(r *protocol.PortForwardRequest) { r.Pod = "" }, wantCode: "invalid_payload"},
+		{name: "both targets", mutate: func(r *protocol.PortForwardRequest) { r.Service = "web" }, wantCode: "invalid_payload"},
+		{name: "bad pod", mutate: func(r *protocol.PortForwardRequest) { r.Pod = "Web_0" }, wantCode: "invalid_payload"},
+		{name: "port zero", mutate: func(r *protocol.PortForwardRequest) { r.Port = 0 }, wantCode: "invalid_payload"},
+		{name: "port too high", mutate: func(r *protocol.PortForwardRequest) { r.Port = 70000 }, wantCode: "invalid_payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.mutate(&req)
+			code, err := validatePortForwardRequest(req)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.wantCode != "", err != nil, "err = %v", err)
+		})
+	}
+}
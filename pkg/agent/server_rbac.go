@@ -22,10 +22,13 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/validation"
+
 	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/kubestellar/console/pkg/models"
 )
@@ -183,3 +186,65 @@ func (s *Server) handlePermissionsSummaryHTTP(w http.ResponseWriter, r *http.Req
 	}
 	writeJSON(w, response)
 }
+
+// handlePermissionMatrixHTTP returns what the caller may do in a namespace of
+// one cluster (`?cluster=`) or of every cluster in the user's kubeconfig,
+// from a SelfSubjectRulesReview per cluster. The frontend uses the matrix to
+// disable actions before they fail; the cluster still enforces RBAC when they
+// run.
+func (s *Server) handlePermissionMatrixHTTP(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !s.validateToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.k8sClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "k8s client not initialized")
+		return
+	}
+
+	namespace := r.URL.Query().Get("namespace")
+	if namespace != "" && len(validation.IsDNS1123Label(namespace)) > 0 {
+		writeJSONError(w, http.StatusBadRequest, "invalid namespace")
+		return
+	}
+
+	cluster := r.URL.Query().Get("cluster")
+	if cluster != "" {
+		ctx, cancel := context.WithTimeout(r.Context(), k8s.RBACDefaultTimeout)
+		defer cancel()
+		matrix, err := s.k8sClient.GetPermissionMatrix(ctx, cluster, namespace)
+		if err != nil {
+			slog.Error("failed to review permissions", "cluster", cluster, "namespace", namespace, "error", err)
+			writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("review permissions", err))
+			return
+		}
+		writeJSON(w, models.PermissionMatrixResponse{Clusters: []models.ClusterPermissionMatrix{*matrix}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), rbacAnalysisTimeout)
+	defer cancel()
+	matrices, err := s.k8sClient.GetAllPermissionMatrices(ctx, namespace)
+	if err != nil {
+		slog.Error("failed to list clusters for permission review", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("review permissions", err))
+		return
+	}
+	for i := range matrices {
+		if matrices[i].Error != "" {
+			slog.Warn("failed to review permissions", "cluster", matrices[i].Cluster, "error", matrices[i].Error)
+			matrices[i].Error = sanitizeAgentError("review permissions", errors.New(matrices[i].Error))
+		}
+	}
+	writeJSON(w, models.PermissionMatrixResponse{Clusters: matrices})
+}
@@ -1,9 +1,19 @@
 package agent
 
 import (
+	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
 )
 
 // TestHandleCanIHTTP_CORSMethodsHeader verifies the POST-specific
@@ -87,3 +97,60 @@ func TestHandleServiceAccounts_CORSMethodsHeader(t *testing.T) {
 		}
 	}
 }
+
+// TestHandlePermissionMatrixHTTP checks that a single-cluster request reviews
+// the requested namespace and reports the operations its rules allow.
+func TestHandlePermissionMatrixHTTP(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var reviewed string
+	clientset.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectRulesReview).DeepCopy()
+		reviewed = review.Spec.Namespace
+		review.Status.ResourceRules = []authv1.ResourceRule{
+			{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+		}
+		return true, review, nil
+	})
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.InjectClient("prod", clientset)
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/rbac/matrix?cluster=prod&namespace=shop", nil)
+	w := httptest.NewRecorder()
+	s.handlePermissionMatrixHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if reviewed != "shop" {
+		t.Errorf("expected the rules review for namespace shop, got %q", reviewed)
+	}
+
+	var resp models.PermissionMatrixResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(resp.Clusters) != 1 || resp.Clusters[0].Cluster != "prod" {
+		t.Fatalf("expected one entry for prod, got %+v", resp.Clusters)
+	}
+	allowed := map[string]bool{}
+	for _, op := range resp.Clusters[0].Operations {
+		allowed[op.Verb+" "+op.Resource+"/"+op.Subresource] = op.Allowed
+	}
+	for op, want := range map[string]bool{"list pods/": true, "get pods/log": true, "delete pods/": false, "create pods/exec": false} {
+		if allowed[op] != want {
+			t.Errorf("%s: expected allowed=%v", op, want)
+		}
+	}
+}
+
+func TestHandlePermissionMatrixHTTP_InvalidNamespace(t *testing.T) {
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/rbac/matrix?namespace=Not_Valid", nil)
+	w := httptest.NewRecorder()
+	s.handlePermissionMatrixHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,139 @@
+package agent
+
+// Resource explorer handlers for kc-agent.
+//
+// The backend serves the read side of the generic resource explorer
+// (pkg/api/handlers/resource_explorer.go). The two operations whose answer
+// depends on *who* is asking — patching an object and the RBAC capability
+// hints shown next to each kind — live here so they run under the user's
+// kubeconfig, following the #7993 identity rule.
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resourcePatchRequest is the body accepted by POST /resources/patch.
+type resourcePatchRequest struct {
+	Cluster   string          `json:"cluster"`
+	Group     string          `json:"group"`
+	Version   string          `json:"version"`
+	Resource  string          `json:"resource"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name"`
+	PatchType string          `json:"patchType,omitempty"`
+	Patch     json.RawMessage `json:"patch"`
+}
+
+// explorerPatchTypes maps the short patch type names used by the frontend to
+// the Kubernetes content types. Empty defaults to a JSON merge patch.
+var explorerPatchTypes = map[string]types.PatchType{
+	"":          types.MergePatchType,
+	"merge":     types.MergePatchType,
+	"json":      types.JSONPatchType,
+	"strategic": types.StrategicMergePatchType,
+}
+
+// handleResourcePatch applies a patch to a single object of any kind under
+// the user's kubeconfig.
+func (s *Server) handleResourcePatch(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r, http.MethodPost, http.MethodOptions)
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !s.validateToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.k8sClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "k8s client not initialized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	var req resourcePatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Cluster == "" || req.Version == "" || req.Resource == "" || req.Name == "" || len(req.Patch) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "cluster, version, resource, name, and patch are required")
+		return
+	}
+	patchType, ok := explorerPatchTypes[req.PatchType]
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "patchType must be one of merge, json, strategic")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), agentDefaultTimeout)
+	defer cancel()
+
+	gvr := schema.GroupVersionResource{Group: req.Group, Version: req.Version, Resource: req.Resource}
+	obj, err := s.k8sClient.PatchResource(ctx, req.Cluster, gvr, req.Namespace, req.Name, patchType, req.Patch)
+	if err != nil {
+		slog.Error("failed to patch resource", "cluster", req.Cluster, "resource", gvr.String(), "namespace", req.Namespace, "name", req.Name, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("patch resource", err))
+		return
+	}
+	writeJSON(w, obj.Object)
+}
+
+// handleResourceCapabilities reports which verbs the caller may use on a
+// resource kind, so the explorer can hide edit/delete actions the user's
+// RBAC would reject.
+func (s *Server) handleResourceCapabilities(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !s.validateToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.k8sClient == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "k8s client not initialized")
+		return
+	}
+
+	q := r.URL.Query()
+	cluster := q.Get("cluster")
+	gvr := schema.GroupVersionResource{Group: q.Get("group"), Version: q.Get("version"), Resource: q.Get("resource")}
+	if cluster == "" || gvr.Version == "" || gvr.Resource == "" {
+		writeJSONError(w, http.StatusBadRequest, "cluster, version, and resource are required")
+		return
+	}
+	namespace := q.Get("namespace")
+
+	caps, err := s.k8sClient.GetResourceCapabilities(r.Context(), cluster, gvr, namespace)
+	if err != nil {
+		slog.Error("failed to check resource capabilities", "cluster", cluster, "resource", gvr.String(), "error", err)
+		writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("check resource capabilities", err))
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"cluster":      cluster,
+		"group":        gvr.Group,
+		"version":      gvr.Version,
+		"resource":     gvr.Resource,
+		"namespace":    namespace,
+		"capabilities": caps,
+	})
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestHandleResourcePatch(t *testing.T) {
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	fakeDyn := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		widgets: "WidgetList",
+	})
+	_, err := fakeDyn.Resource(widgets).Namespace("default").Create(context.Background(), &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w1", "namespace": "default"},
+	}}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("seed widget: %v", err)
+	}
+
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("c1", fakeDyn)
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+
+	body, _ := json.Marshal(resourcePatchRequest{
+		Cluster:   "c1",
+		Group:     "example.com",
+		Version:   "v1",
+		Resource:  "widgets",
+		Namespace: "default",
+		Name:      "w1",
+		Patch:     json.RawMessage(`{"metadata":{"labels":{"tier":"gold"}}}`),
+	})
+	req := httptest.NewRequest(http.MethodPost, "/resources/patch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	s.handleResourcePatch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"tier":"gold"`) {
+		t.Errorf("expected patched label in response, got %s", w.Body.String())
+	}
+}
+
+func TestHandleResourcePatch_Validation(t *testing.T) {
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+
+	cases := map[string]string{
+		"missing name":   `{"cluster":"c1","version":"v1","resource":"widgets","patch":{}}`,
+		"bad patch type": `{"cluster":"c1","version":"v1","resource":"widgets","name":"w1","patchType":"apply","patch":{}}`,
+		"bad json":       `{`,
+	}
+	for name, body := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/resources/patch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleResourcePatch(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/resources/patch", nil)
+	w := httptest.NewRecorder()
+	s.handleResourcePatch(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", w.Code)
+	}
+}
+
+func TestHandleResourceCapabilities_Validation(t *testing.T) {
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/resources/capabilities?cluster=c1&version=v1", nil)
+	w := httptest.NewRecorder()
+	s.handleResourceCapabilities(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when resource is missing, got %d", w.Code)
+	}
+}
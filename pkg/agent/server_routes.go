@@ -49,7 +49,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/scale", s.handleScaleHTTP)
 	// Workload deploy and delete routes moved to kc-agent (#7993 Phase 1 PR B).
 	// These run under the user's kubeconfig instead of the backend pod SA.
-	mux.HandleFunc("/workloads/deploy", s.handleDeployWorkloadHTTP)
+	mux.HandleFunc("/workloads/deploy", s.idempotent(s.handleDeployWorkloadHTTP))
 	mux.HandleFunc("/workloads/delete", s.handleDeleteWorkloadHTTP)
 
 	// MCS ServiceExport create/delete moved to kc-agent (#7993 Phase 1.5 PR B).
@@ -79,9 +79,9 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// reconcileDeployment) because that's system-internal — it reacts to
 	// CR state changes without a human in the loop and legitimately runs
 	// as the pod SA.
-	mux.HandleFunc("/console-cr/workloads", s.handleConsoleCRManagedWorkloads)
-	mux.HandleFunc("/console-cr/groups", s.handleConsoleCRClusterGroups)
-	mux.HandleFunc("/console-cr/deployments", s.handleConsoleCRWorkloadDeployments)
+	mux.HandleFunc("/console-cr/workloads", s.idempotent(s.handleConsoleCRManagedWorkloads))
+	mux.HandleFunc("/console-cr/groups", s.idempotent(s.handleConsoleCRClusterGroups))
+	mux.HandleFunc("/console-cr/deployments", s.idempotent(s.handleConsoleCRWorkloadDeployments))
 	mux.HandleFunc("/console-cr/deployments/status", s.handleConsoleCRWorkloadDeploymentStatus)
 
 	// Federation / multi-cluster-management awareness (Issue 9368, PR A).
@@ -137,6 +137,14 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/rbac/can-i", s.handleCanIHTTP)
 	mux.HandleFunc("/rbac/permissions", s.handleClusterPermissionsHTTP)
 	mux.HandleFunc("/permissions/summary", s.handlePermissionsSummaryHTTP)
+	mux.HandleFunc("/rbac/matrix", s.handlePermissionMatrixHTTP)
+
+	// Resource explorer operations that depend on the caller's identity.
+	// The read side (discovery, lists, schemas) is served by the backend;
+	// patches and RBAC capability hints run under the user's kubeconfig.
+	// Routes in pkg/agent/server_resources_explorer.go.
+	mux.HandleFunc("/resources/patch", s.handleResourcePatch)
+	mux.HandleFunc("/resources/capabilities", s.handleResourceCapabilities)
 
 	// Rename context endpoint
 	mux.HandleFunc("/rename-context", s.handleRenameContextHTTP)
@@ -147,6 +155,8 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/kubeconfig/add", s.handleKubeconfigAddHTTP)
 	mux.HandleFunc("/kubeconfig/test", s.handleKubeconfigTestHTTP)
 	mux.HandleFunc("/kubeconfig/remove", s.handleKubeconfigRemoveHTTP)
+	mux.HandleFunc("/kubeconfig/import-secret", s.handleKubeconfigImportSecretHTTP)
+	mux.HandleFunc("/kubeconfig/secrets", s.handleKubeconfigSecretsHTTP)
 
 	// Settings endpoints for API key management
 	mux.HandleFunc("/settings/keys", s.handleSettingsKeys)
@@ -178,6 +188,7 @@ func (s *Server) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/devices/alerts/clear", s.handleDeviceAlertsClear)
 	mux.HandleFunc("/devices/inventory", s.handleDeviceInventory)
 	mux.HandleFunc("/metrics/history", s.handleMetricsHistory)
+	mux.HandleFunc("/reports/usage", s.handleUsageReport)
 
 	// Kagenti AI agent platform endpoints
 	mux.HandleFunc("/kagenti/agents", s.handleKagentiAgents)
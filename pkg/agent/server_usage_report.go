@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultUsageReportWindow is the window reported when from is omitted. It
+// matches the metrics history retention; older usage is not kept.
+const defaultUsageReportWindow = 7 * 24 * time.Hour
+
+// handleUsageReport serves GET /reports/usage: the requests, GPU-hours and
+// rollouts of each ClusterGroup over a window, as JSON or, with format=csv,
+// as a CSV download for chargeback. Like the console-cr endpoints it takes
+// the persistence cluster and namespace as query parameters.
+func (s *Server) handleUsageReport(w http.ResponseWriter, r *http.Request) {
+	s.setCORSHeaders(w, r, http.MethodGet, http.MethodOptions)
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.validateToken(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	from, to, err := parseUsageWindow(r.URL.Query(), time.Now())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeJSONError(w, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	persistence, namespace, ok := s.resolveConsoleCRTarget(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), consoleCRRequestTimeout)
+	defer cancel()
+
+	groups, err := persistence.ListClusterGroups(ctx, namespace)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("list cluster groups", err))
+		return
+	}
+	deployments, err := persistence.ListWorkloadDeployments(ctx, namespace)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, sanitizeAgentError("list workload deployments", err))
+		return
+	}
+	var snapshots []MetricsSnapshot
+	if s.metricsHistory != nil {
+		snapshots = s.metricsHistory.GetSnapshots().Snapshots
+	}
+	report := buildUsageReport(snapshots, groups, deployments, from, to)
+
+	if format != "csv" {
+		writeJSON(w, report)
+		return
+	}
+	filename := fmt.Sprintf("usage-%s-%s.csv", from.UTC().Format("20060102"), to.UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := writeUsageReportCSV(w, report); err != nil {
+		slog.Error("failed to write usage report", "error", err)
+	}
+}
+
+// parseUsageWindow reads the RFC 3339 from and to query parameters. to
+// defaults to now and from to defaultUsageReportWindow before to.
+func parseUsageWindow(q url.Values, now time.Time) (time.Time, time.Time, error) {
+	to := now
+	if raw := q.Get("to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be an RFC 3339 time")
+		}
+		to = t
+	}
+	from := to.Add(-defaultUsageReportWindow)
+	if raw := q.Get("from"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be an RFC 3339 time")
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must be before to")
+	}
+	return from, to, nil
+}
@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/safego"
 )
 
@@ -79,7 +80,11 @@ func (uc *UpdateChecker) Stop() {
 }
 
 // Configure updates the channel and enabled state. Restarts the loop if needed.
+// Air-gapped mode keeps auto-update off.
 func (uc *UpdateChecker) Configure(enabled bool, channel string) {
+	if egress.AirGapped() {
+		enabled = false
+	}
 	uc.mu.Lock()
 	changed := uc.enabled != enabled || uc.channel != channel
 	uc.enabled = enabled
@@ -106,6 +111,7 @@ func (uc *UpdateChecker) Status() AutoUpdateStatusResponse {
 		AutoUpdateEnabled: uc.enabled,
 		Channel:           uc.channel,
 		UpdateInProgress:  uc.IsUpdating(),
+		AirGapped:         egress.AirGapped(),
 	}
 
 	if !uc.lastUpdateTime.IsZero() {
@@ -130,8 +136,9 @@ func (uc *UpdateChecker) Status() AutoUpdateStatusResponse {
 		}
 	}
 
-	// Fetch latest SHA from origin/main (uses git fetch, no rate limits)
-	if repoPath != "" {
+	// Fetch latest SHA from origin/main (uses git fetch, no rate limits).
+	// Air-gapped mode has no origin to reach.
+	if repoPath != "" && !resp.AirGapped {
 		if sha, err := fetchLatestMainSHAWithRepo(repoPath); err == nil {
 			resp.LatestSHA = sha
 			resp.HasUpdate = sha != resp.CurrentSHA && resp.CurrentSHA != ""
@@ -145,8 +152,11 @@ func (uc *UpdateChecker) Status() AutoUpdateStatusResponse {
 
 // TriggerNow runs an immediate update check (non-blocking).
 // If channelOverride is non-empty, it temporarily uses that channel for this check.
-// Returns false if an update is already in progress.
+// Returns false if an update is already in progress, or in air-gapped mode.
 func (uc *UpdateChecker) TriggerNow(channelOverride string) bool {
+	if egress.AirGapped() {
+		return false
+	}
 	// Reset the cancellation flag *before* the CAS so that a concurrent
 	// CancelUpdate() call between the CAS and context creation cannot have
 	// its intent silently dropped (#7439).
@@ -129,6 +129,8 @@ type AutoUpdateStatusResponse struct {
 	LastUpdateTime        string `json:"lastUpdateTime,omitempty"`
 	LastUpdateResult      string `json:"lastUpdateResult,omitempty"`
 	UpdateInProgress      bool   `json:"updateInProgress"`
+	// AirGapped is true when air-gapped mode keeps auto-update off.
+	AirGapped bool `json:"airGapped,omitempty"`
 }
 
 // AutoUpdateConfigRequest is the body for POST /auto-update/config.
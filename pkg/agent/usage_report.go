@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/clustergroup"
+)
+
+// maxUsageSampleSpan caps how long one metrics snapshot is taken to stand
+// for, so a gap in the history (kc-agent was down) is not billed as if the
+// last snapshot's usage continued throughout it. The last snapshot stands
+// for one collection interval.
+const maxUsageSampleSpan = 2 * metricsHistoryTick
+
+// UsageReport is the usage of each ClusterGroup over a time window.
+type UsageReport struct {
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Groups []GroupUsage `json:"groups"`
+}
+
+// GroupUsage is the usage of one ClusterGroup's clusters. A cluster that
+// belongs to several groups counts toward each of them.
+type GroupUsage struct {
+	Group                string   `json:"group"`
+	Clusters             []string `json:"clusters"`
+	CPURequestCoreHours  float64  `json:"cpuRequestCoreHours"`
+	MemoryRequestGBHours float64  `json:"memoryRequestGBHours"`
+	GPUHours             float64  `json:"gpuHours"`
+	// Deployments counts the rollouts started in the window that targeted
+	// the group or a group it includes.
+	Deployments int `json:"deployments"`
+}
+
+// clusterUsage is the usage of one cluster in the window.
+type clusterUsage struct {
+	cpuCoreHours  float64
+	memoryGBHours float64
+	gpuHours      float64
+}
+
+// buildUsageReport integrates the metrics history over [from, to) for each
+// group's current members. Each snapshot stands for the time until the next
+// one, capped at maxUsageSampleSpan.
+func buildUsageReport(snapshots []MetricsSnapshot, groups []v1alpha1.ClusterGroup, deployments []v1alpha1.WorkloadDeployment, from, to time.Time) UsageReport {
+	perCluster := integrateClusterUsage(snapshots, from, to)
+
+	hierarchy := clustergroup.New(groups)
+	deploymentsByGroup := make(map[string]int)
+	for _, wd := range deployments {
+		if wd.Spec.TargetGroupRef == nil || wd.Spec.TargetGroupRef.Name == "" {
+			continue
+		}
+		target := wd.Spec.TargetGroupRef.Name
+		for _, entry := range wd.Status.History {
+			if entry.StartedAt == nil || entry.StartedAt.Time.Before(from) || !entry.StartedAt.Time.Before(to) {
+				continue
+			}
+			deploymentsByGroup[target]++
+			for _, ancestor := range hierarchy.Ancestors(target) {
+				deploymentsByGroup[ancestor]++
+			}
+		}
+	}
+
+	report := UsageReport{From: from, To: to, Groups: make([]GroupUsage, 0, len(groups))}
+	for _, name := range hierarchy.Names() {
+		usage := GroupUsage{
+			Group:       name,
+			Clusters:    usageGroupMembers(hierarchy, name),
+			Deployments: deploymentsByGroup[name],
+		}
+		for _, cluster := range usage.Clusters {
+			c := perCluster[cluster]
+			usage.CPURequestCoreHours += c.cpuCoreHours
+			usage.MemoryRequestGBHours += c.memoryGBHours
+			usage.GPUHours += c.gpuHours
+		}
+		report.Groups = append(report.Groups, usage)
+	}
+	return report
+}
+
+// usageGroupMembers returns the clusters of the named group as last
+// evaluated by the console, or, for a group not evaluated yet, the static
+// members of the group and the groups it includes.
+func usageGroupMembers(hierarchy *clustergroup.Hierarchy, name string) []string {
+	group := hierarchy.Group(name)
+	if group.Status.LastEvaluated != nil {
+		members := append([]string{}, group.Status.MatchedClusters...)
+		sort.Strings(members)
+		return members
+	}
+	set := make(map[string]bool)
+	for _, g := range hierarchy.Expand([]string{name}) {
+		for _, c := range hierarchy.Group(g).Spec.StaticMembers {
+			set[c] = true
+		}
+	}
+	members := make([]string, 0, len(set))
+	for c := range set {
+		members = append(members, c)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// integrateClusterUsage sums each cluster's requests and allocated GPUs over
+// the part of each snapshot's span that falls in [from, to).
+func integrateClusterUsage(snapshots []MetricsSnapshot, from, to time.Time) map[string]clusterUsage {
+	type sample struct {
+		at   time.Time
+		snap *MetricsSnapshot
+	}
+	samples := make([]sample, 0, len(snapshots))
+	for i := range snapshots {
+		at, err := time.Parse(time.RFC3339, snapshots[i].Timestamp)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, sample{at: at, snap: &snapshots[i]})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].at.Before(samples[j].at) })
+
+	out := make(map[string]clusterUsage)
+	for i, s := range samples {
+		end := s.at.Add(metricsHistoryTick)
+		if i+1 < len(samples) {
+			end = samples[i+1].at
+		}
+		if limit := s.at.Add(maxUsageSampleSpan); end.After(limit) {
+			end = limit
+		}
+		start := s.at
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		hours := end.Sub(start).Hours()
+
+		for _, c := range s.snap.Clusters {
+			u := out[c.Name]
+			u.cpuCoreHours += c.CPURequestsCores * hours
+			u.memoryGBHours += c.MemoryRequestsGB * hours
+			out[c.Name] = u
+		}
+		for _, g := range s.snap.GPUNodes {
+			u := out[g.Cluster]
+			u.gpuHours += float64(g.GPUAllocated) * hours
+			out[g.Cluster] = u
+		}
+	}
+	return out
+}
+
+// usageReportCSVHeader is the header row of the CSV export.
+var usageReportCSVHeader = []string{
+	"group", "from", "to", "clusters", "cpu_request_core_hours",
+	"memory_request_gb_hours", "gpu_hours", "deployments",
+}
+
+// writeUsageReportCSV writes one row per group. The clusters column holds
+// the member count; the JSON report lists them.
+func writeUsageReportCSV(w io.Writer, report UsageReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(usageReportCSVHeader); err != nil {
+		return err
+	}
+	from := report.From.UTC().Format(time.RFC3339)
+	to := report.To.UTC().Format(time.RFC3339)
+	for _, g := range report.Groups {
+		if err := cw.Write([]string{
+			g.Group,
+			from,
+			to,
+			strconv.Itoa(len(g.Clusters)),
+			formatUsageHours(g.CPURequestCoreHours),
+			formatUsageHours(g.MemoryRequestGBHours),
+			formatUsageHours(g.GPUHours),
+			strconv.Itoa(g.Deployments),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatUsageHours(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,217 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+var usageTestStart = time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+// usageTestSnapshot is a snapshot at offset after usageTestStart in which
+// gpu-prod requests 4 cores and 8 GB and allocates 2 GPUs, and cpu-dev
+// requests 1 core.
+func usageTestSnapshot(offset time.Duration) MetricsSnapshot {
+	return MetricsSnapshot{
+		Timestamp: usageTestStart.Add(offset).Format(time.RFC3339),
+		Clusters: []ClusterMetricSnapshot{
+			{Name: "gpu-prod", CPURequestsCores: 4, MemoryRequestsGB: 8},
+			{Name: "cpu-dev", CPURequestsCores: 1},
+		},
+		GPUNodes: []GPUNodeMetricSnapshot{{Name: "g1", Cluster: "gpu-prod", GPUAllocated: 2, GPUTotal: 8}},
+	}
+}
+
+func usageTestGroups() []v1alpha1.ClusterGroup {
+	evaluated := metav1.NewTime(usageTestStart)
+	return []v1alpha1.ClusterGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+			Spec:       v1alpha1.ClusterGroupSpec{Expression: "cluster.gpuCount > 0"},
+			Status:     v1alpha1.ClusterGroupStatus{MatchedClusters: []string{"gpu-prod"}, LastEvaluated: &evaluated},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+			Spec:       v1alpha1.ClusterGroupSpec{StaticMembers: []string{"cpu-dev"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+			Spec:       v1alpha1.ClusterGroupSpec{MemberGroups: []string{"dev"}},
+		},
+	}
+}
+
+func usageTestDeployment(name, group string, started ...time.Duration) v1alpha1.WorkloadDeployment {
+	wd := v1alpha1.WorkloadDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.WorkloadDeploymentSpec{TargetGroupRef: &v1alpha1.ResourceReference{Name: group}},
+	}
+	for _, offset := range started {
+		at := metav1.NewTime(usageTestStart.Add(offset))
+		wd.Status.History = append(wd.Status.History, v1alpha1.DeploymentHistoryEntry{StartedAt: &at})
+	}
+	return wd
+}
+
+func usageByGroup(report UsageReport) map[string]GroupUsage {
+	out := make(map[string]GroupUsage, len(report.Groups))
+	for _, g := range report.Groups {
+		out[g.Group] = g
+	}
+	return out
+}
+
+func assertHours(t *testing.T, field string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func TestBuildUsageReport(t *testing.T) {
+	// Two snapshots an hour apart, then a six hour gap. The first two stand
+	// for the capped span each and the last for one collection interval.
+	snapshots := []MetricsSnapshot{
+		usageTestSnapshot(0),
+		usageTestSnapshot(time.Hour),
+		usageTestSnapshot(7 * time.Hour),
+	}
+	deployments := []v1alpha1.WorkloadDeployment{
+		usageTestDeployment("a", "dev", time.Hour, 48*time.Hour),
+		usageTestDeployment("b", "gpu", 2*time.Hour),
+		{ObjectMeta: metav1.ObjectMeta{Name: "explicit"}, Spec: v1alpha1.WorkloadDeploymentSpec{TargetClusters: []string{"cpu-dev"}}},
+	}
+	report := buildUsageReport(snapshots, usageTestGroups(), deployments, usageTestStart, usageTestStart.Add(24*time.Hour))
+	groups := usageByGroup(report)
+	if len(groups) != 3 {
+		t.Fatalf("got groups %v, want 3", report.Groups)
+	}
+
+	sampled := (2*maxUsageSampleSpan + metricsHistoryTick).Hours()
+	gpu := groups["gpu"]
+	assertHours(t, "gpu cpu", gpu.CPURequestCoreHours, 4*sampled)
+	assertHours(t, "gpu memory", gpu.MemoryRequestGBHours, 8*sampled)
+	assertHours(t, "gpu gpus", gpu.GPUHours, 2*sampled)
+	if gpu.Deployments != 1 {
+		t.Errorf("gpu deployments = %d, want 1", gpu.Deployments)
+	}
+
+	// team-a has no evaluated status and rolls up dev's static member and
+	// its rollouts; the rollout outside the window is not counted.
+	for _, name := range []string{"dev", "team-a"} {
+		g := groups[name]
+		if len(g.Clusters) != 1 || g.Clusters[0] != "cpu-dev" {
+			t.Errorf("%s clusters = %v, want [cpu-dev]", name, g.Clusters)
+		}
+		assertHours(t, name+" cpu", g.CPURequestCoreHours, sampled)
+		assertHours(t, name+" gpus", g.GPUHours, 0)
+		if g.Deployments != 1 {
+			t.Errorf("%s deployments = %d, want 1", name, g.Deployments)
+		}
+	}
+}
+
+func TestBuildUsageReport_ClipsToWindow(t *testing.T) {
+	snapshots := []MetricsSnapshot{usageTestSnapshot(0), usageTestSnapshot(10 * time.Minute)}
+	from := usageTestStart.Add(5 * time.Minute)
+	to := usageTestStart.Add(15 * time.Minute)
+	report := buildUsageReport(snapshots, usageTestGroups(), nil, from, to)
+	assertHours(t, "gpu gpus", usageByGroup(report)["gpu"].GPUHours, 2*(10*time.Minute).Hours())
+}
+
+func TestWriteUsageReportCSV(t *testing.T) {
+	report := UsageReport{
+		From:   usageTestStart,
+		To:     usageTestStart.Add(24 * time.Hour),
+		Groups: []GroupUsage{{Group: "gpu", Clusters: []string{"gpu-prod"}, GPUHours: 1.5, Deployments: 2}},
+	}
+	var buf bytes.Buffer
+	if err := writeUsageReportCSV(&buf, report); err != nil {
+		t.Fatalf("writeUsageReportCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want header and one group", len(rows))
+	}
+	want := []string{"gpu", "2026-10-14T00:00:00Z", "2026-10-15T00:00:00Z", "1", "0.00", "0.00", "1.50", "2"}
+	for i := range want {
+		if rows[1][i] != want[i] {
+			t.Errorf("column %s = %q, want %q", rows[0][i], rows[1][i], want[i])
+		}
+	}
+}
+
+func TestParseUsageWindow(t *testing.T) {
+	now := usageTestStart
+	from, to, err := parseUsageWindow(map[string][]string{}, now)
+	if err != nil || !to.Equal(now) || !from.Equal(now.Add(-defaultUsageReportWindow)) {
+		t.Fatalf("defaults: got %v..%v, %v", from, to, err)
+	}
+	if _, _, err := parseUsageWindow(map[string][]string{"from": {"yesterday"}}, now); err == nil {
+		t.Error("expected an error for an invalid from")
+	}
+	if _, _, err := parseUsageWindow(map[string][]string{"from": {"2026-10-15T00:00:00Z"}}, now); err == nil {
+		t.Error("expected an error for from after to")
+	}
+}
+
+func TestServer_HandleUsageReport(t *testing.T) {
+	scheme := runtime.NewScheme()
+	var objects []runtime.Object
+	for _, g := range usageTestGroups() {
+		g.TypeMeta = metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"}
+		g.Namespace = "test-ns"
+		u, err := g.ToUnstructured()
+		if err != nil {
+			t.Fatalf("ToUnstructured: %v", err)
+		}
+		objects = append(objects, u)
+	}
+	k8sClient, _ := k8s.NewMultiClusterClient("")
+	k8sClient.SetDynamicClient("persistence-cluster", fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		v1alpha1.ClusterGroupGVR:       "ClusterGroupList",
+		v1alpha1.WorkloadDeploymentGVR: "WorkloadDeploymentList",
+	}, objects...))
+	s := &Server{k8sClient: k8sClient, allowedOrigins: []string{"*"}}
+
+	const target = "/reports/usage?cluster=persistence-cluster&namespace=test-ns"
+	w := httptest.NewRecorder()
+	s.handleUsageReport(w, httptest.NewRequest(http.MethodGet, target, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200; body: %s", w.Code, w.Body.String())
+	}
+	var report UsageReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(report.Groups) != 3 {
+		t.Fatalf("got groups %v, want 3", report.Groups)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleUsageReport(w, httptest.NewRequest(http.MethodGet, target+"&format=csv", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("csv: got %d %q; body: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.handleUsageReport(w, httptest.NewRequest(http.MethodGet, target+"&format=xml", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bad format: got %d, want 400", w.Code)
+	}
+}
@@ -2,9 +2,12 @@ package agent
 
 import (
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/kubestellar/console/pkg/agent/protocol"
 )
 
 func setWSWriteDeadline(conn *websocket.Conn, logMsg string, logArgs ...any) error {
@@ -26,3 +29,33 @@ func clearWSWriteDeadline(conn *websocket.Conn, logMsg string, logArgs ...any) e
 	}
 	return nil
 }
+
+// newWSMessageSender returns a function that writes messages to conn from a
+// goroutine other than the read loop, under writeMu and a write deadline.
+// A failed write marks the connection closed, and later calls return
+// websocket.ErrCloseSent without writing.
+func newWSMessageSender(conn *websocket.Conn, writeMu *sync.Mutex, closed *atomic.Bool, logPrefix string) func(protocol.Message) error {
+	return func(out protocol.Message) error {
+		if closed.Load() {
+			return websocket.ErrCloseSent
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := setWSWriteDeadline(conn, logPrefix+" failed to set WebSocket write deadline",
+			"msgID", out.ID, "type", out.Type); err != nil {
+			closed.Store(true)
+			return err
+		}
+		err := conn.WriteJSON(out)
+		if clearErr := clearWSWriteDeadline(conn, logPrefix+" failed to clear WebSocket write deadline",
+			"msgID", out.ID, "type", out.Type); clearErr != nil {
+			closed.Store(true)
+		}
+		if err != nil {
+			slog.Error(logPrefix+" WebSocket write failed; marking connection closed",
+				"msgID", out.ID, "type", out.Type, "error", err)
+			closed.Store(true)
+		}
+		return err
+	}
+}
@@ -39,6 +39,10 @@ type ClusterMetricSnapshot struct {
 	MemoryPercent float64 `json:"memoryPercent"`
 	NodeCount     int     `json:"nodeCount"`
 	HealthyNodes  int     `json:"healthyNodes"`
+	// CPURequestsCores and MemoryRequestsGB are the summed pod requests;
+	// zero in snapshots recorded before they were captured.
+	CPURequestsCores float64 `json:"cpuRequestsCores,omitempty"`
+	MemoryRequestsGB float64 `json:"memoryRequestsGB,omitempty"`
 }
 
 // PodIssueSnapshot holds pod issue data at a point in time
@@ -207,11 +211,13 @@ func (mh *MetricsHistory) captureSnapshot() error {
 				memPercent = (h.MemoryRequestsGB / h.MemoryGB) * 100
 			}
 			snapshot.Clusters = append(snapshot.Clusters, ClusterMetricSnapshot{
-				Name:          h.Cluster,
-				CPUPercent:    cpuPercent,
-				MemoryPercent: memPercent,
-				NodeCount:     h.NodeCount,
-				HealthyNodes:  h.ReadyNodes,
+				Name:             h.Cluster,
+				CPUPercent:       cpuPercent,
+				MemoryPercent:    memPercent,
+				NodeCount:        h.NodeCount,
+				HealthyNodes:     h.ReadyNodes,
+				CPURequestsCores: h.CpuRequestsCores,
+				MemoryRequestsGB: h.MemoryRequestsGB,
 			})
 		}
 	}
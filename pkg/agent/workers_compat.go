@@ -34,6 +34,8 @@ type DeviceInventoryResponse = workers.DeviceInventoryResponse
 
 type MetricsHistory = workers.MetricsHistory
 type MetricsSnapshot = workers.MetricsSnapshot
+type ClusterMetricSnapshot = workers.ClusterMetricSnapshot
+type GPUNodeMetricSnapshot = workers.GPUNodeMetricSnapshot
 type MetricsHistoryResponse = workers.MetricsHistoryResponse
 
 // --- Constants ---
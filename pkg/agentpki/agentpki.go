@@ -0,0 +1,429 @@
+// Package agentpki issues and verifies the client certificates kc-agents use
+// for mutual TLS on the relay channel to a hosted console backend.
+//
+// The backend runs a private certificate authority. During pairing an agent
+// generates its own key and sends a certificate signing request, so the
+// private key never leaves the agent; the authority signs it with the agent
+// ID as CommonName. Agents renew before expiry (NeedsRotation) by presenting
+// their current certificate, and the backend can revoke an agent, which
+// rejects every certificate issued to it so far.
+package agentpki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/fileutil"
+)
+
+const (
+	// CALifetime is the validity of the generated authority certificate.
+	CALifetime = 5 * 365 * 24 * time.Hour
+	// CertLifetime is the validity of an issued agent certificate.
+	CertLifetime = 30 * 24 * time.Hour
+	// RotationFraction is the share of a certificate's lifetime that may be
+	// left before it is due for rotation.
+	RotationFraction = 3
+	// clockSkew backdates NotBefore so agents with a slightly slow clock
+	// accept a freshly issued certificate.
+	clockSkew = 5 * time.Minute
+	// maxAgentIDLength bounds the agent ID used as CommonName.
+	maxAgentIDLength = 64
+
+	caCertFile  = "ca.crt"
+	caKeyFile   = "ca.key"
+	revokedFile = "revoked.json"
+
+	pemTypeCertificate = "CERTIFICATE"
+	pemTypeECKey       = "EC PRIVATE KEY"
+	pemTypeCSR         = "CERTIFICATE REQUEST"
+)
+
+var (
+	// ErrInvalidAgentID is returned for an empty or malformed agent ID.
+	ErrInvalidAgentID = errors.New("invalid agent ID")
+	// ErrInvalidCSR is returned when a signing request cannot be parsed or
+	// its signature does not verify.
+	ErrInvalidCSR = errors.New("invalid certificate signing request")
+	// ErrRevoked is returned for a certificate of a revoked agent.
+	ErrRevoked = errors.New("agent certificate revoked")
+	// ErrUntrusted is returned for a certificate not issued by this authority.
+	ErrUntrusted = errors.New("agent certificate not issued by this authority")
+)
+
+// Authority is the backend's agent certificate authority. Its key pair and
+// the revocation list are kept in a directory so they survive restarts.
+type Authority struct {
+	dir    string
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	caPEM  []byte
+	now    func() time.Time
+	mu     sync.RWMutex
+	revoke map[string]time.Time // agent ID -> revoked at
+}
+
+// Open loads the authority from dir, generating a new one on first use.
+func Open(dir string) (*Authority, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("agentpki: create dir: %w", err)
+	}
+	a := &Authority{dir: dir, now: time.Now, revoke: map[string]time.Time{}}
+
+	certPEM, certErr := os.ReadFile(filepath.Join(dir, caCertFile))
+	keyPEM, keyErr := os.ReadFile(filepath.Join(dir, caKeyFile))
+	switch {
+	case certErr == nil && keyErr == nil:
+		if err := a.load(certPEM, keyPEM); err != nil {
+			return nil, err
+		}
+	case errors.Is(certErr, os.ErrNotExist) && errors.Is(keyErr, os.ErrNotExist):
+		if err := a.generate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("agentpki: incomplete authority in %s", dir)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, revokedFile))
+	if err == nil {
+		if err := json.Unmarshal(data, &a.revoke); err != nil {
+			return nil, fmt.Errorf("agentpki: parse revocation list: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("agentpki: read revocation list: %w", err)
+	}
+	return a, nil
+}
+
+func (a *Authority) load(certPEM, keyPEM []byte) error {
+	cert, err := ParseCertPEM(certPEM)
+	if err != nil {
+		return fmt.Errorf("agentpki: parse CA certificate: %w", err)
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != pemTypeECKey {
+		return errors.New("agentpki: parse CA key: no EC private key block")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("agentpki: parse CA key: %w", err)
+	}
+	a.cert, a.key, a.caPEM = cert, key, certPEM
+	return nil
+}
+
+func (a *Authority) generate() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("agentpki: generate CA key: %w", err)
+	}
+	now := a.now()
+	template := &x509.Certificate{
+		SerialNumber:          newSerial(),
+		Subject:               pkix.Name{Organization: []string{"KubeStellar Console"}, CommonName: "kc-agent relay CA"},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(CALifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("agentpki: create CA certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("agentpki: marshal CA key: %w", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: pemTypeECKey, Bytes: keyDER})
+	if err := fileutil.AtomicWriteFile(filepath.Join(a.dir, caKeyFile), keyPEM, 0600); err != nil {
+		return err
+	}
+	if err := fileutil.AtomicWriteFile(filepath.Join(a.dir, caCertFile), certPEM, 0644); err != nil {
+		return err
+	}
+	return a.load(certPEM, keyPEM)
+}
+
+// CAPEM returns the authority certificate agents pin for the relay channel.
+func (a *Authority) CAPEM() []byte { return a.caPEM }
+
+// Issue signs csrPEM for agentID. It is called during pairing, after the
+// pairing code has been checked; pairing again after a revocation yields a
+// certificate that is accepted while the older ones stay revoked.
+func (a *Authority) Issue(agentID string, csrPEM []byte) ([]byte, error) {
+	if !ValidAgentID(agentID) {
+		return nil, ErrInvalidAgentID
+	}
+	return a.sign(agentID, csrPEM)
+}
+
+// Rotate issues a fresh certificate for the agent that owns current, which
+// must still verify. The new certificate may use a new key.
+func (a *Authority) Rotate(current *x509.Certificate, csrPEM []byte) ([]byte, error) {
+	if err := a.Verify(current); err != nil {
+		return nil, err
+	}
+	return a.sign(current.Subject.CommonName, csrPEM)
+}
+
+func (a *Authority) sign(agentID string, csrPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != pemTypeCSR {
+		return nil, ErrInvalidCSR
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil || csr.CheckSignature() != nil {
+		return nil, ErrInvalidCSR
+	}
+	now := a.now()
+	template := &x509.Certificate{
+		SerialNumber: newSerial(),
+		Subject:      pkix.Name{Organization: []string{"KubeStellar Console"}, CommonName: agentID},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(CertLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, csr.PublicKey, a.key)
+	if err != nil {
+		return nil, fmt.Errorf("agentpki: sign certificate: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: der}), nil
+}
+
+// Revoke rejects every certificate issued to agentID up to now.
+func (a *Authority) Revoke(agentID string) error {
+	if !ValidAgentID(agentID) {
+		return ErrInvalidAgentID
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.revoke[agentID] = a.now().UTC()
+	return a.saveRevokedLocked()
+}
+
+// revoked reports whether cert was issued before its agent was revoked.
+// Issue time is NotBefore without the clock-skew backdating.
+func (a *Authority) revoked(cert *x509.Certificate) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	at, ok := a.revoke[cert.Subject.CommonName]
+	return ok && !cert.NotBefore.Add(clockSkew).After(at)
+}
+
+func (a *Authority) saveRevokedLocked() error {
+	data, err := json.Marshal(a.revoke)
+	if err != nil {
+		return fmt.Errorf("agentpki: marshal revocation list: %w", err)
+	}
+	return fileutil.AtomicWriteFile(filepath.Join(a.dir, revokedFile), data, 0600)
+}
+
+// Verify checks that cert was issued by this authority for client auth, is
+// within its validity period and belongs to an agent that is not revoked.
+func (a *Authority) Verify(cert *x509.Certificate) error {
+	if cert == nil {
+		return ErrUntrusted
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(a.cert)
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: a.now(),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return fmt.Errorf("%w: %v", ErrUntrusted, err)
+	}
+	if a.revoked(cert) {
+		return ErrRevoked
+	}
+	return nil
+}
+
+// ServerTLSConfig returns the relay listener configuration: serverCert is the
+// backend's own certificate and every client must present a valid agent
+// certificate. The agent ID is the peer certificate's CommonName.
+func (a *Authority) ServerTLSConfig(serverCert tls.Certificate) *tls.Config {
+	pool := x509.NewCertPool()
+	pool.AddCert(a.cert)
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		// The chain was verified against ClientCAs already; this adds the
+		// revocation check, which crypto/tls has no hook for otherwise.
+		VerifyPeerCertificate: func(_ [][]byte, chains [][]*x509.Certificate) error {
+			if len(chains) == 0 || len(chains[0]) == 0 {
+				return ErrUntrusted
+			}
+			if a.revoked(chains[0][0]) {
+				return ErrRevoked
+			}
+			return nil
+		},
+	}
+}
+
+// RelayTLSConfig is ServerTLSConfig with a server certificate the authority
+// issues for hosts, so agents can reach the relay trusting only the CA they
+// pinned at pairing. The server certificate is reissued when it is due for
+// rotation.
+func (a *Authority) RelayTLSConfig(hosts ...string) (*tls.Config, error) {
+	cert, err := a.issueServer(hosts)
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.ServerTLSConfig(cert)
+	cfg.Certificates = nil
+	var mu sync.Mutex
+	cfg.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if NeedsRotation(cert.Leaf, a.now()) {
+			next, err := a.issueServer(hosts)
+			if err != nil {
+				return nil, err
+			}
+			cert = next
+		}
+		return &cert, nil
+	}
+	return cfg, nil
+}
+
+// issueServer signs a fresh server-auth certificate for hosts, which may be
+// DNS names or IP addresses.
+func (a *Authority) issueServer(hosts []string) (tls.Certificate, error) {
+	if len(hosts) == 0 {
+		return tls.Certificate{}, errors.New("agentpki: relay certificate needs at least one host")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("agentpki: generate relay key: %w", err)
+	}
+	now := a.now()
+	template := &x509.Certificate{
+		SerialNumber: newSerial(),
+		Subject:      pkix.Name{Organization: []string{"KubeStellar Console"}, CommonName: hosts[0]},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(CertLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("agentpki: sign relay certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("agentpki: parse relay certificate: %w", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// NewCSR generates the agent key pair and a signing request for agentID.
+// The agent keeps keyPEM and sends csrPEM to the backend.
+func NewCSR(agentID string) (csrPEM, keyPEM []byte, err error) {
+	if !ValidAgentID(agentID) {
+		return nil, nil, ErrInvalidAgentID
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("agentpki: generate key: %w", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: agentID},
+	}, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("agentpki: create CSR: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("agentpki: marshal key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCSR, Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: pemTypeECKey, Bytes: keyDER}), nil
+}
+
+// ClientTLSConfig returns the agent side of the relay connection: it
+// presents the agent certificate and trusts only the pinned authority.
+func ClientTLSConfig(certPEM, keyPEM, caPEM []byte, serverName string) (*tls.Config, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("agentpki: load agent certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("agentpki: no CA certificate in bundle")
+	}
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{pair},
+		RootCAs:      pool,
+		ServerName:   serverName,
+	}, nil
+}
+
+// NeedsRotation reports whether cert has less than 1/RotationFraction of its
+// lifetime left at now, so agents renew well before it expires.
+func NeedsRotation(cert *x509.Certificate, now time.Time) bool {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotAfter.Sub(now) < lifetime/RotationFraction
+}
+
+// ParseCertPEM parses the first certificate in a PEM bundle.
+func ParseCertPEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypeCertificate {
+		return nil, errors.New("no certificate block")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// ValidAgentID accepts the characters used by generated agent IDs
+// (letters, digits, '-', '_', '.').
+func ValidAgentID(id string) bool {
+	if id == "" || len(id) > maxAgentIDLength || strings.Trim(id, ".") == "" {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return serial
+}
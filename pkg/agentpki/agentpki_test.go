@@ -0,0 +1,282 @@
+package agentpki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// pair issues a certificate for agentID and returns it with the agent key.
+func pair(t *testing.T, a *Authority, agentID string) (certPEM, keyPEM []byte, cert *x509.Certificate) {
+	t.Helper()
+	csrPEM, keyPEM, err := NewCSR(agentID)
+	if err != nil {
+		t.Fatalf("NewCSR: %v", err)
+	}
+	certPEM, err = a.Issue(agentID, csrPEM)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	cert, err = ParseCertPEM(certPEM)
+	if err != nil {
+		t.Fatalf("ParseCertPEM: %v", err)
+	}
+	return certPEM, keyPEM, cert
+}
+
+func TestOpen_PersistsAuthority(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_, _, cert := pair(t, a, "agent-1")
+	if err := a.Revoke("agent-2"); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if string(reopened.CAPEM()) != string(a.CAPEM()) {
+		t.Fatal("reopening generated a new CA")
+	}
+	if err := reopened.Verify(cert); err != nil {
+		t.Fatalf("certificate from before the restart should verify: %v", err)
+	}
+	if _, ok := reopened.revoke["agent-2"]; !ok {
+		t.Fatal("revocation list was not persisted")
+	}
+}
+
+func TestIssue_RejectsBadInput(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	csrPEM, _, err := NewCSR("agent-1")
+	if err != nil {
+		t.Fatalf("NewCSR: %v", err)
+	}
+	for _, id := range []string{"", "..", "agent/1", "CN=x,O=y"} {
+		if _, err := a.Issue(id, csrPEM); !errors.Is(err, ErrInvalidAgentID) {
+			t.Errorf("Issue(%q) = %v, want ErrInvalidAgentID", id, err)
+		}
+	}
+	if _, err := a.Issue("agent-1", []byte("not a csr")); !errors.Is(err, ErrInvalidCSR) {
+		t.Errorf("Issue with junk CSR = %v, want ErrInvalidCSR", err)
+	}
+}
+
+func TestVerify_RejectsForeignAndExpired(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	other, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_, _, foreign := pair(t, other, "agent-1")
+	if err := a.Verify(foreign); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("foreign certificate: got %v, want ErrUntrusted", err)
+	}
+
+	_, _, cert := pair(t, a, "agent-1")
+	a.now = func() time.Time { return time.Now().Add(CertLifetime + time.Hour) }
+	if err := a.Verify(cert); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("expired certificate: got %v, want ErrUntrusted", err)
+	}
+}
+
+func TestRevokeAndRotate(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	_, _, cert := pair(t, a, "agent-1")
+
+	csrPEM, _, err := NewCSR("ignored")
+	if err != nil {
+		t.Fatalf("NewCSR: %v", err)
+	}
+	rotatedPEM, err := a.Rotate(cert, csrPEM)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	rotated, err := ParseCertPEM(rotatedPEM)
+	if err != nil {
+		t.Fatalf("ParseCertPEM: %v", err)
+	}
+	if rotated.Subject.CommonName != "agent-1" {
+		t.Errorf("rotated certificate is for %q, want the current agent ID", rotated.Subject.CommonName)
+	}
+
+	revokedAt := time.Now().Add(time.Hour)
+	a.now = func() time.Time { return revokedAt }
+	if err := a.Revoke("agent-1"); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	for _, c := range []*x509.Certificate{cert, rotated} {
+		if err := a.Verify(c); !errors.Is(err, ErrRevoked) {
+			t.Errorf("Verify after revoke = %v, want ErrRevoked", err)
+		}
+	}
+	if _, err := a.Rotate(cert, csrPEM); !errors.Is(err, ErrRevoked) {
+		t.Errorf("Rotate of a revoked certificate = %v, want ErrRevoked", err)
+	}
+
+	a.now = func() time.Time { return revokedAt.Add(time.Hour) }
+	_, _, repaired := pair(t, a, "agent-1")
+	if err := a.Verify(repaired); err != nil {
+		t.Errorf("certificate from pairing again should verify: %v", err)
+	}
+	if err := a.Verify(cert); !errors.Is(err, ErrRevoked) {
+		t.Errorf("pairing again must not revive old certificates, got %v", err)
+	}
+}
+
+func TestNeedsRotation(t *testing.T) {
+	now := time.Now()
+	cert := &x509.Certificate{NotBefore: now, NotAfter: now.Add(30 * 24 * time.Hour)}
+	if NeedsRotation(cert, now.Add(10*24*time.Hour)) {
+		t.Error("a third of the way in should not need rotation")
+	}
+	if !NeedsRotation(cert, now.Add(21*24*time.Hour)) {
+		t.Error("less than a third left should need rotation")
+	}
+}
+
+// serverCertificate returns a throwaway server certificate for 127.0.0.1.
+func serverCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "relay"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// handshake dials a relay listener with the agent's credentials and returns
+// the server-side handshake error.
+func handshake(t *testing.T, a *Authority, certPEM, keyPEM []byte) error {
+	t.Helper()
+	serverCert, serverPool := serverCertificate(t)
+	cfg, err := ClientTLSConfig(certPEM, keyPEM, a.CAPEM(), "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ClientTLSConfig: %v", err)
+	}
+	cfg.RootCAs = serverPool // the test server certificate is self-signed
+	return dial(t, a.ServerTLSConfig(serverCert), cfg)
+}
+
+// dial connects client to a listener using server and returns the
+// server-side handshake error.
+func dial(t *testing.T, server, client *tls.Config) error {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	result := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			result <- err
+			return
+		}
+		defer conn.Close()
+		result <- conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), client)
+	if err == nil {
+		// TLS 1.3 reports client-certificate rejection on the first read.
+		_, _ = conn.Read(make([]byte, 1))
+		conn.Close()
+	} else if !errors.Is(err, io.EOF) {
+		t.Logf("client handshake: %v", err)
+	}
+	return <-result
+}
+
+func TestServerTLSConfig_MutualAuth(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	certPEM, keyPEM, _ := pair(t, a, "agent-1")
+	if err := handshake(t, a, certPEM, keyPEM); err != nil {
+		t.Fatalf("paired agent should connect: %v", err)
+	}
+
+	other, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	foreignPEM, foreignKey, _ := pair(t, other, "agent-1")
+	if err := handshake(t, a, foreignPEM, foreignKey); err == nil {
+		t.Error("certificate from another authority should be rejected")
+	}
+
+	a.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if err := a.Revoke("agent-1"); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := handshake(t, a, certPEM, keyPEM); !errors.Is(err, ErrRevoked) {
+		t.Errorf("revoked agent handshake = %v, want ErrRevoked", err)
+	}
+}
+
+func TestRelayTLSConfig_AgentTrustsPinnedCA(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	server, err := a.RelayTLSConfig("127.0.0.1")
+	if err != nil {
+		t.Fatalf("RelayTLSConfig: %v", err)
+	}
+	certPEM, keyPEM, _ := pair(t, a, "agent-1")
+	client, err := ClientTLSConfig(certPEM, keyPEM, a.CAPEM(), "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ClientTLSConfig: %v", err)
+	}
+	if err := dial(t, server, client); err != nil {
+		t.Fatalf("agent pinning the CA should reach the relay: %v", err)
+	}
+
+	if _, err := a.RelayTLSConfig(); err == nil {
+		t.Error("RelayTLSConfig without hosts should fail")
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/url"
+
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/telemetry"
+)
+
+// applyAirGap turns off the console's outbound integrations when air-gapped
+// mode is on and returns the startup report. Each entry is checked against
+// the configuration as it will run, not assumed from the mode, so the report
+// shows what would still leave the network if a switch were missed.
+func applyAirGap(cfg *Config) []egress.Feature {
+	if !egress.AirGapped() {
+		return nil
+	}
+	cfg.BenchmarkGoogleDriveAPIKey = ""
+	cfg.BenchmarkGoogleCredentials = ""
+
+	features := []egress.Feature{
+		{Name: "telemetry", Disabled: telemetry.DisabledByEnv(), Detail: "usage reports are neither collected nor sent"},
+		{Name: "google-drive-benchmarks", Disabled: cfg.BenchmarkGoogleDriveAPIKey == "" && cfg.BenchmarkGoogleCredentials == "", Detail: "benchmark reports are not fetched from Google Drive"},
+	}
+	if cfg.BenchmarkSource != "" {
+		features = append(features, egress.Feature{Name: "benchmark-source", Detail: "benchmark reports are read from " + cfg.BenchmarkSource})
+	}
+	github := githubAPIHost(cfg.GitHubURL)
+	if egress.Allowed(github) {
+		features = append(features, egress.Feature{Name: "github", Detail: "login, feedback and rewards use the in-network GitHub at " + github})
+	} else {
+		features = append(features, egress.Feature{Name: "github", Disabled: true, Detail: "GitHub login, feedback and rewards cannot reach " + github})
+	}
+	return features
+}
+
+// githubAPIHost is the host the console calls for GitHub's API: api.github.com
+// for github.com, else the GitHub Enterprise host itself.
+func githubAPIHost(githubURL string) string {
+	u, err := url.Parse(githubURL)
+	if err != nil || u.Hostname() == "" {
+		return "api.github.com"
+	}
+	if u.Hostname() == "github.com" {
+		return "api.github.com"
+	}
+	return u.Hostname()
+}
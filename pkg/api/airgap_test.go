@@ -0,0 +1,61 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/egress"
+)
+
+func TestApplyAirGap_OffLeavesConfigAlone(t *testing.T) {
+	require.NoError(t, egress.Configure(egress.Policy{}))
+	cfg := Config{IntegrationsConfig: IntegrationsConfig{BenchmarkGoogleDriveAPIKey: "key"}}
+
+	assert.Nil(t, applyAirGap(&cfg))
+	assert.Equal(t, "key", cfg.BenchmarkGoogleDriveAPIKey)
+}
+
+func TestApplyAirGap_DisablesIntegrations(t *testing.T) {
+	require.NoError(t, egress.Configure(egress.Policy{AirGapped: true, AllowedHosts: []string{"github.corp.example"}}))
+	t.Cleanup(func() { _ = egress.Configure(egress.Policy{}) })
+
+	report := func(githubURL string) map[string]bool {
+		cfg := Config{
+			AuthConfig:         AuthConfig{GitHubURL: githubURL},
+			IntegrationsConfig: IntegrationsConfig{BenchmarkGoogleDriveAPIKey: "key", BenchmarkGoogleCredentials: "/creds.json"},
+		}
+		features := map[string]bool{}
+		for _, f := range applyAirGap(&cfg) {
+			features[f.Name] = f.Disabled
+		}
+		assert.Empty(t, cfg.BenchmarkGoogleDriveAPIKey)
+		assert.Empty(t, cfg.BenchmarkGoogleCredentials)
+		return features
+	}
+
+	public := report("https://github.com")
+	assert.True(t, public["telemetry"])
+	assert.True(t, public["google-drive-benchmarks"])
+	assert.True(t, public["github"], "public GitHub is outside the network")
+
+	enterprise := report("https://github.corp.example")
+	assert.False(t, enterprise["github"], "an allowlisted GitHub Enterprise stays usable")
+}
+
+func TestApplyAirGap_KeepsBenchmarkSource(t *testing.T) {
+	require.NoError(t, egress.Configure(egress.Policy{AirGapped: true}))
+	t.Cleanup(func() { _ = egress.Configure(egress.Policy{}) })
+	cfg := Config{IntegrationsConfig: IntegrationsConfig{BenchmarkSource: "file:///var/lib/benchmarks"}}
+
+	var found bool
+	for _, f := range applyAirGap(&cfg) {
+		if f.Name == "benchmark-source" {
+			found = true
+			assert.False(t, f.Disabled)
+		}
+	}
+	assert.True(t, found)
+	assert.Equal(t, "file:///var/lib/benchmarks", cfg.BenchmarkSource)
+}
@@ -7,6 +7,7 @@
 package audit
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	"github.com/kubestellar/console/pkg/sanitize"
 	"github.com/kubestellar/console/pkg/store"
 )
 
@@ -37,9 +39,10 @@ const (
 	ActionDeleteResourceQuota    = "delete_resource_quota"
 
 	// Authentication events
-	ActionUserLogin  = "user_login"
-	ActionUserLogout = "user_logout"
-	ActionAuthFailed = "auth_failed"
+	ActionUserLogin     = "user_login"
+	ActionUserLogout    = "user_logout"
+	ActionAuthFailed    = "auth_failed"
+	ActionRevokeSession = "revoke_session"
 
 	// Phase 3 (#9890): GPU reservation and mission mutations.
 	ActionCreateGPUReservation = "create_gpu_reservation"
@@ -53,6 +56,69 @@ const (
 	ActionDeleteTeam       = "delete_team"
 	ActionAddTeamMember    = "add_team_member"
 	ActionRemoveTeamMember = "remove_team_member"
+
+	// Manifest apply from the YAML editor.
+	ActionApplyManifest = "apply_manifest"
+
+	// Feature flag administration.
+	ActionUpdateFeatureFlag = "update_feature_flag"
+
+	// Usage telemetry opt-in.
+	ActionUpdateTelemetry = "update_telemetry"
+
+	// White-label branding.
+	ActionUpdateBranding = "update_branding"
+
+	// Resource limits.
+	ActionUpdateLimits = "update_limits"
+
+	// Benchmark report cache purge.
+	ActionPurgeBenchmarks = "purge_benchmarks"
+
+	// Controlled disruption experiments.
+	ActionChaosEvictPod         = "chaos_evict_pod"
+	ActionChaosCordonNode       = "chaos_cordon_node"
+	ActionChaosExperimentResult = "chaos_experiment_result"
+
+	// Cluster timezones used by deployment windows.
+	ActionSetClusterTimezone    = "set_cluster_timezone"
+	ActionDeleteClusterTimezone = "delete_cluster_timezone"
+
+	// Change metadata policy for WorkloadDeployments.
+	ActionUpdateChangePolicy = "update_change_policy"
+
+	// Workload snapshots and restores.
+	ActionSnapshotWorkload        = "snapshot_workload"
+	ActionRestoreWorkloadSnapshot = "restore_workload_snapshot"
+	ActionDeleteWorkloadSnapshot  = "delete_workload_snapshot"
+
+	// Temporary debug pods and jobs.
+	ActionCreateDebugResource = "create_debug_resource"
+	ActionDeleteDebugResource = "delete_debug_resource"
+
+	// In-cluster UI proxy sessions.
+	ActionOpenServiceProxy = "open_service_proxy"
+
+	// Read-only API server proxy.
+	ActionAPIProxyRequest = "api_proxy_request"
+	ActionAPIProxyDenied  = "api_proxy_denied"
+
+	// Cluster diagnostics runs.
+	ActionRunClusterDiagnostics = "run_cluster_diagnostics"
+
+	// Ad-hoc Velero backups.
+	ActionTriggerVeleroBackup = "trigger_velero_backup"
+
+	// Canary WorkloadDeployment promotion and abort.
+	ActionPromoteCanary = "promote_canary"
+	ActionAbortCanary   = "abort_canary"
+
+	// ManagedWorkloads imported from live cluster workloads.
+	ActionImportManagedWorkload = "import_managed_workload"
+
+	// kc-agent pairing and relay certificates.
+	ActionCreateAgentPairingCode = "create_agent_pairing_code"
+	ActionRevokeAgentCertificate = "revoke_agent_certificate"
 )
 
 // storeMu guards the package-level store reference.
@@ -99,7 +165,7 @@ func Log(c *fiber.Ctx, action, targetType, targetID string, details ...string) {
 
 	detailText := ""
 	if len(details) > 0 {
-		detailText = strings.Join(details, " ")
+		detailText = sanitize.Secrets(strings.Join(details, " "))
 		attrs = append(attrs, "details", detailText)
 	}
 
@@ -120,3 +186,28 @@ func Log(c *fiber.Ctx, action, targetType, targetID string, details ...string) {
 		}
 	}
 }
+
+// LogAsync records an audit entry for work that finishes after its request
+// returned, such as a background experiment reporting its outcome. The actor
+// is passed explicitly and the entry carries no ip, path or method.
+func LogAsync(ctx context.Context, actorID uuid.UUID, action, targetType, targetID string, details ...string) {
+	detailText := sanitize.Secrets(strings.Join(details, " "))
+	slog.Info("audit",
+		"action", action,
+		"actor_id", actorID,
+		"target_type", targetType,
+		"target_id", targetID,
+		"details", detailText,
+	)
+
+	if s := getStore(); s != nil {
+		detail, _ := json.Marshal(map[string]string{
+			"target_type": targetType,
+			"target_id":   targetID,
+			"details":     detailText,
+		})
+		if err := s.InsertAuditLog(ctx, actorID.String(), action, string(detail)); err != nil {
+			slog.Error("audit: failed to persist audit entry", "error", err, "action", action)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+const (
+	// clusterWarmupDeadline bounds the whole warm-up phase. /readyz reports
+	// ready once it fires even if some clusters never answered, so a single
+	// hung cluster cannot hold a rollout back.
+	clusterWarmupDeadline = 60 * time.Second
+	// clusterWarmupTimeout bounds the warm-up of one cluster.
+	clusterWarmupTimeout = 15 * time.Second
+	// maxConcurrentClusterWarmups caps how many clusters are primed at once.
+	maxConcurrentClusterWarmups = 8
+)
+
+// Warm-up phases reported by /readyz.
+const (
+	warmupStatePending  = "pending"
+	warmupStateRunning  = "running"
+	warmupStateComplete = "complete"
+)
+
+// clusterPrefetcher is the slice of k8s.MultiClusterClient the warm-up uses.
+type clusterPrefetcher interface {
+	HealthyClusters(ctx context.Context) ([]k8s.ClusterInfo, []k8s.ClusterInfo, error)
+	GetClusterHealth(ctx context.Context, contextName string) (*k8s.ClusterHealth, error)
+	GetNodes(ctx context.Context, contextName string) ([]k8s.NodeInfo, error)
+	ListNamespacesWithDetails(ctx context.Context, contextName string) ([]models.NamespaceDetails, error)
+	ListWorkloadsForCluster(ctx context.Context, contextName, namespace, workloadType string) ([]v1alpha1.Workload, error)
+}
+
+// clusterWarmup primes per-cluster state right after startup: the cached
+// cluster health (node, pod and PVC counts), and through node, namespace and
+// workload lists the clientsets, exec-plugin credentials and connections the
+// first dashboard load would otherwise pay for. Progress is served by /readyz.
+type clusterWarmup struct {
+	client clusterPrefetcher
+
+	mu         sync.Mutex
+	state      string
+	startedAt  time.Time
+	finishedAt time.Time
+	total      int
+	skipped    int
+	clusters   map[string]*clusterWarmupStatus
+}
+
+// clusterWarmupStatus is the warm-up outcome of one cluster.
+type clusterWarmupStatus struct {
+	Cluster    string `json:"cluster"`
+	Done       bool   `json:"done"`
+	Error      string `json:"error,omitempty"`
+	Nodes      int    `json:"nodes"`
+	Namespaces int    `json:"namespaces"`
+	Workloads  int    `json:"workloads"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+}
+
+// clusterWarmupProgress is the warm-up detail included in /readyz.
+type clusterWarmupProgress struct {
+	State          string                `json:"state"`
+	ClustersTotal  int                   `json:"clusters_total"`
+	ClustersDone   int                   `json:"clusters_done"`
+	ClustersFailed int                   `json:"clusters_failed"`
+	SkippedOffline int                   `json:"skipped_offline"`
+	DurationMs     int64                 `json:"duration_ms"`
+	Clusters       []clusterWarmupStatus `json:"clusters,omitempty"`
+}
+
+func newClusterWarmup(client clusterPrefetcher) *clusterWarmup {
+	return &clusterWarmup{client: client, state: warmupStatePending, clusters: map[string]*clusterWarmupStatus{}}
+}
+
+// start runs the warm-up in the background. It stops early when done closes.
+func (w *clusterWarmup) start(done <-chan struct{}) {
+	safego.GoWith("api/cluster-warmup", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterWarmupDeadline)
+		defer cancel()
+		safego.Go(func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		})
+		w.run(ctx)
+	})
+}
+
+// run primes every healthy cluster and returns once all have finished or
+// ctx is done.
+func (w *clusterWarmup) run(ctx context.Context) {
+	w.mu.Lock()
+	w.state = warmupStateRunning
+	w.startedAt = time.Now()
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.state = warmupStateComplete
+		w.finishedAt = time.Now()
+		w.mu.Unlock()
+	}()
+
+	healthy, offline, err := w.client.HealthyClusters(ctx)
+	if err != nil {
+		slog.Warn("[Warmup] skipping cluster prefetch — failed to list clusters", "error", err)
+		return
+	}
+	w.mu.Lock()
+	w.total = len(healthy)
+	w.skipped = len(offline)
+	for _, cl := range healthy {
+		w.clusters[cl.Name] = &clusterWarmupStatus{Cluster: cl.Name}
+	}
+	w.mu.Unlock()
+	slog.Info("[Warmup] prefetching cluster data", "clusters", len(healthy), "skippedOffline", len(offline))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentClusterWarmups)
+	for _, cl := range healthy {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		cl := cl
+		wg.Add(1)
+		safego.GoWith("api/cluster-warmup/"+cl.Name, func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			w.warmCluster(ctx, cl)
+		})
+	}
+	wg.Wait()
+
+	progress := w.progress(false)
+	slog.Info("[Warmup] cluster prefetch done", "clusters", progress.ClustersTotal, "done", progress.ClustersDone,
+		"failed", progress.ClustersFailed, "durationMs", progress.DurationMs, "cancelled", ctx.Err() != nil)
+}
+
+// warmCluster primes one cluster. The lists are run for their side effects;
+// a failure stops the remaining steps since they would hit the same problem.
+func (w *clusterWarmup) warmCluster(parent context.Context, cl k8s.ClusterInfo) {
+	ctx, cancel := context.WithTimeout(parent, clusterWarmupTimeout)
+	defer cancel()
+	start := time.Now()
+	status := clusterWarmupStatus{Cluster: cl.Name}
+
+	err := func() error {
+		if _, err := w.client.GetClusterHealth(ctx, cl.Context); err != nil {
+			return err
+		}
+		nodes, err := w.client.GetNodes(ctx, cl.Context)
+		if err != nil {
+			return err
+		}
+		status.Nodes = len(nodes)
+		namespaces, err := w.client.ListNamespacesWithDetails(ctx, cl.Context)
+		if err != nil {
+			return err
+		}
+		status.Namespaces = len(namespaces)
+		workloads, err := w.client.ListWorkloadsForCluster(ctx, cl.Context, "", "")
+		if err != nil {
+			return err
+		}
+		status.Workloads = len(workloads)
+		return nil
+	}()
+	if err != nil {
+		slog.Info("[Warmup] cluster prefetch failed", "cluster", cl.Name, "error", err)
+		status.Error = "prefetch failed"
+	}
+	status.Done = true
+	status.DurationMs = time.Since(start).Milliseconds()
+
+	w.mu.Lock()
+	w.clusters[cl.Name] = &status
+	w.mu.Unlock()
+}
+
+// ready reports whether the warm-up has finished (successfully or not).
+func (w *clusterWarmup) ready() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state == warmupStateComplete
+}
+
+// progress snapshots the warm-up; verbose adds the per-cluster breakdown.
+func (w *clusterWarmup) progress(verbose bool) clusterWarmupProgress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	p := clusterWarmupProgress{
+		State:          w.state,
+		ClustersTotal:  w.total,
+		SkippedOffline: w.skipped,
+	}
+	if !w.startedAt.IsZero() {
+		end := w.finishedAt
+		if end.IsZero() {
+			end = time.Now()
+		}
+		p.DurationMs = end.Sub(w.startedAt).Milliseconds()
+	}
+	for _, st := range w.clusters {
+		if st.Done {
+			p.ClustersDone++
+		}
+		if st.Error != "" {
+			p.ClustersFailed++
+		}
+		if verbose {
+			p.Clusters = append(p.Clusters, *st)
+		}
+	}
+	sort.Slice(p.Clusters, func(i, j int) bool { return p.Clusters[i].Cluster < p.Clusters[j].Cluster })
+	return p
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// stubPrefetcher serves two healthy clusters ("a", "b") and one offline.
+// Listing nodes on "b" fails; release, when set, blocks the health calls.
+type stubPrefetcher struct {
+	release     chan struct{}
+	healthCalls int32
+}
+
+func (s *stubPrefetcher) HealthyClusters(context.Context) ([]k8s.ClusterInfo, []k8s.ClusterInfo, error) {
+	return []k8s.ClusterInfo{{Name: "a", Context: "ctx-a"}, {Name: "b", Context: "ctx-b"}},
+		[]k8s.ClusterInfo{{Name: "down", Context: "ctx-down"}}, nil
+}
+
+func (s *stubPrefetcher) GetClusterHealth(ctx context.Context, contextName string) (*k8s.ClusterHealth, error) {
+	atomic.AddInt32(&s.healthCalls, 1)
+	if s.release != nil {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &k8s.ClusterHealth{Cluster: contextName}, nil
+}
+
+func (s *stubPrefetcher) GetNodes(_ context.Context, contextName string) ([]k8s.NodeInfo, error) {
+	if contextName == "ctx-b" {
+		return nil, errors.New("forbidden")
+	}
+	return make([]k8s.NodeInfo, 3), nil
+}
+
+func (s *stubPrefetcher) ListNamespacesWithDetails(context.Context, string) ([]models.NamespaceDetails, error) {
+	return make([]models.NamespaceDetails, 5), nil
+}
+
+func (s *stubPrefetcher) ListWorkloadsForCluster(context.Context, string, string, string) ([]v1alpha1.Workload, error) {
+	return make([]v1alpha1.Workload, 2), nil
+}
+
+func TestClusterWarmup_Run(t *testing.T) {
+	stub := &stubPrefetcher{}
+	w := newClusterWarmup(stub)
+	assert.False(t, w.ready())
+	assert.Equal(t, warmupStatePending, w.progress(false).State)
+
+	w.run(context.Background())
+
+	require.True(t, w.ready())
+	p := w.progress(true)
+	assert.Equal(t, warmupStateComplete, p.State)
+	assert.Equal(t, 2, p.ClustersTotal)
+	assert.Equal(t, 2, p.ClustersDone)
+	assert.Equal(t, 1, p.ClustersFailed)
+	assert.Equal(t, 1, p.SkippedOffline)
+	assert.EqualValues(t, 2, stub.healthCalls, "offline clusters are not primed")
+
+	require.Len(t, p.Clusters, 2)
+	assert.Equal(t, clusterWarmupStatus{Cluster: "a", Done: true, Nodes: 3, Namespaces: 5, Workloads: 2, DurationMs: p.Clusters[0].DurationMs}, p.Clusters[0])
+	assert.Equal(t, "prefetch failed", p.Clusters[1].Error)
+	assert.Nil(t, w.progress(false).Clusters)
+}
+
+func TestHandleReadyz_ReportsWarmupProgress(t *testing.T) {
+	server := newHealthTestServer(t, Config{})
+	get := func(path string) (int, map[string]any) {
+		resp, err := server.app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+
+	code, body := get("/readyz")
+	assert.Equal(t, http.StatusOK, code, "no Kubernetes client means nothing to warm up")
+	assert.Equal(t, "ready", body["status"])
+
+	stub := &stubPrefetcher{release: make(chan struct{})}
+	server.background = newBackgroundServices()
+	server.background.clusterWarmup = newClusterWarmup(stub)
+	done := make(chan struct{})
+	go func() {
+		server.background.clusterWarmup.run(context.Background())
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&stub.healthCalls) == 2 }, time.Second, 10*time.Millisecond)
+
+	code, body = get("/readyz?verbose=true")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "warming_up", body["status"])
+	warmup := body["warmup"].(map[string]any)
+	assert.Equal(t, warmupStateRunning, warmup["state"])
+	assert.Equal(t, 0.0, warmup["clusters_done"])
+	assert.Len(t, warmup["clusters"], 2)
+
+	close(stub.release)
+	<-done
+	code, body = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body["status"])
+	assert.Equal(t, 2.0, body["warmup"].(map[string]any)["clusters_done"])
+}
@@ -5,7 +5,12 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"time"
 
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/api/transport"
 	"github.com/kubestellar/console/pkg/settings"
 )
 
@@ -39,6 +44,12 @@ const (
 	// continue to work. Larger deployments can raise this for big form posts;
 	// smaller appliances can lower it to tighten the DoS surface.
 	envMaxBodyBytes = "MAX_BODY_BYTES"
+
+	// envSessionIdleTimeout and envSessionMaxLifetime override the
+	// server-side session timeouts enforced by the auth middleware. Values
+	// use Go duration syntax ("8h", "30m").
+	envSessionIdleTimeout = "SESSION_IDLE_TIMEOUT"
+	envSessionMaxLifetime = "SESSION_MAX_LIFETIME"
 )
 
 // ServerConfig holds infrastructure and runtime configuration
@@ -53,19 +64,29 @@ type ServerConfig struct {
 	ConsoleProject    string // White-label project context (e.g., "kubestellar", "crossplane", "istio")
 	NoLocalAgent        bool // Suppress local kc-agent connections in in-cluster deployments
 	DisableDynamicCards bool // Remove 'unsafe-eval' from CSP by disabling the dynamic cards feature
+	DeploymentPolicyFile string // DEPLOYMENT_POLICY_FILE — YAML of CEL/Rego policies gating WorkloadDeployment rollouts
+	HubBackplaneURL      string // HUB_BACKPLANE_URL — redis:// URL relaying WebSocket broadcasts between replicas (empty = single replica)
+	HubBackplaneStream   string // HUB_BACKPLANE_STREAM — Redis stream key shared by all replicas
+	FakeMode             bool   // FAKE_MODE — serve deterministic in-memory clusters, benchmarks and AI replies (see pkg/testharness)
+	FakeModeSeed         uint64 // FAKE_MODE_SEED — seed for the fake data set (0 = testharness.DefaultSeed)
+	AgentRelayAddr       string // AGENT_RELAY_ADDR — mTLS listener for paired kc-agents, e.g. ":8443" (empty = pairing and relay disabled)
+	AgentRelayHosts      string // AGENT_RELAY_HOSTS — comma-separated DNS names/IPs agents dial the relay on (default: localhost)
 }
 
 // AuthConfig holds authentication and authorization configuration
 type AuthConfig struct {
-	GitHubClientID  string
-	GitHubSecret    string
-	GitHubURL       string // GitHub base URL (e.g., "https://github.ibm.com"), defaults to "https://github.com"
-	JWTSecret       string
-	AgentToken      string // Shared secret for authenticating with kc-agent
-	BootstrapToken  string // CONSOLE_BOOTSTRAP_TOKEN — required to access manifest bootstrap flow (CWE-306 mitigation)
-	DevUserLogin    string // Dev mode user settings (used when GitHub OAuth not configured)
-	DevUserEmail    string
-	DevUserAvatar   string
+	GitHubClientID     string
+	GitHubSecret       string
+	GitHubURL          string // GitHub base URL (e.g., "https://github.ibm.com"), defaults to "https://github.com"
+	JWTSecret          string
+	AgentToken         string // Shared secret for authenticating with kc-agent
+	BootstrapToken     string // CONSOLE_BOOTSTRAP_TOKEN — required to access manifest bootstrap flow (CWE-306 mitigation)
+	MetricsToken       string // METRICS_TOKEN — when set, /metrics scrapes must send it as a bearer token
+	DevUserLogin       string // Dev mode user settings (used when GitHub OAuth not configured)
+	DevUserEmail       string
+	DevUserAvatar      string
+	SessionIdleTimeout time.Duration // SESSION_IDLE_TIMEOUT — sign a device out after this long without requests (0 = never; default 24h)
+	SessionMaxLifetime time.Duration // SESSION_MAX_LIFETIME — require a fresh login after this long, however active (default 720h)
 }
 
 // BrandConfig holds white-label branding configuration
@@ -98,18 +119,34 @@ type IntegrationsConfig struct {
 	RewardsGitHubOrgs   string // Org filter for GitHub search (e.g., "org:kubestellar org:llm-d")
 	// Google Drive benchmark data
 	BenchmarkGoogleDriveAPIKey string // API key for fetching benchmark data from Google Drive
+	BenchmarkGoogleCredentials string // GOOGLE_APPLICATION_CREDENTIALS — service-account JSON key file for private Drive folders; replaces the API key when set
 	BenchmarkFolderID          string // Google Drive folder ID containing benchmark results
+	BenchmarkSource            string // BENCHMARK_SOURCE — s3://, gs://, file:// or an HTTP index URL; replaces Google Drive when set
+	BenchmarkWebhookToken      string // BENCHMARK_WEBHOOK_TOKEN — bearer token for POST /api/benchmarks/webhook; unset disables it
 	// Kubara platform catalog
 	KubaraCatalogRepo string // GitHub owner/name of the catalog repo (e.g. "my-org/my-catalog")
 	KubaraCatalogPath string // Directory path inside the repo containing Helm chart subdirectories
 }
 
+// ProxyConfig holds CORS and reverse-proxy configuration for deployments
+// behind an ingress or path-prefix proxy.
+type ProxyConfig struct {
+	CORSAllowedOrigins string // CORS_ALLOWED_ORIGINS — comma-separated origins (default: FRONTEND_URL)
+	CORSNoCredentials  bool   // CORS_ALLOW_CREDENTIALS=false — stop sending Access-Control-Allow-Credentials
+	CORSAllowedHeaders string // CORS_ALLOWED_HEADERS — request headers allowed in addition to the built-in list
+	CORSExposedHeaders string // CORS_EXPOSED_HEADERS — response headers exposed in addition to X-Token-Refresh
+	TrustedProxies     string // TRUSTED_PROXIES — comma-separated IPs/CIDRs whose X-Forwarded-* headers are honored; "*" trusts all, "none" none (default: private ranges)
+	ProxyIPHeader      string // PROXY_IP_HEADER — header carrying the client IP from trusted proxies (default: X-Forwarded-For)
+	BasePath           string // BASE_PATH — path prefix the console is served under (e.g. "/console"); stripped before routing
+}
+
 // Config holds server configuration (composed of sub-configs for backward compatibility)
 type Config struct {
 	ServerConfig
 	AuthConfig
 	BrandConfig
 	IntegrationsConfig
+	ProxyConfig
 }
 
 // LoadConfigFromEnv loads configuration from environment variables
@@ -132,6 +169,15 @@ func LoadConfigFromEnv() Config {
 		}
 	}
 
+	var fakeModeSeed uint64
+	if p := os.Getenv("FAKE_MODE_SEED"); p != "" {
+		if v, err := strconv.ParseUint(p, 10, 64); err != nil {
+			slog.Warn("[Server] invalid FAKE_MODE_SEED, using default seed", "value", p, "error", err)
+		} else {
+			fakeModeSeed = v
+		}
+	}
+
 	dbPath := "./data/console.db"
 	if p := os.Getenv("DATABASE_PATH"); p != "" {
 		dbPath = p
@@ -231,17 +277,27 @@ func LoadConfigFromEnv() Config {
 			ConsoleProject:    getEnvOrDefault("CONSOLE_PROJECT", "kubestellar"),
 			NoLocalAgent:        os.Getenv("NO_LOCAL_AGENT") == "true",
 			DisableDynamicCards: os.Getenv("DISABLE_DYNAMIC_CARDS") == "true",
+			DeploymentPolicyFile: os.Getenv("DEPLOYMENT_POLICY_FILE"),
+			HubBackplaneURL:      os.Getenv("HUB_BACKPLANE_URL"),
+			HubBackplaneStream:   getEnvOrDefault("HUB_BACKPLANE_STREAM", transport.DefaultBackplaneStream),
+			FakeMode:             os.Getenv("FAKE_MODE") == "true",
+			FakeModeSeed:         fakeModeSeed,
+			AgentRelayAddr:       os.Getenv("AGENT_RELAY_ADDR"),
+			AgentRelayHosts:      getEnvOrDefault("AGENT_RELAY_HOSTS", "localhost"),
 		},
 		AuthConfig: AuthConfig{
-			GitHubClientID: githubClientID,
-			GitHubSecret:   githubSecret,
-			GitHubURL:      getEnvOrDefault("GITHUB_URL", "https://github.com"),
-			JWTSecret:      jwtSecret,
-			AgentToken:     os.Getenv("KC_AGENT_TOKEN"),
-			BootstrapToken: os.Getenv("CONSOLE_BOOTSTRAP_TOKEN"),
-			DevUserLogin:   getEnvOrDefault("DEV_USER_LOGIN", "dev-user"),
-			DevUserEmail:   getEnvOrDefault("DEV_USER_EMAIL", "dev@localhost"),
-			DevUserAvatar:  getEnvOrDefault("DEV_USER_AVATAR", ""),
+			GitHubClientID:     githubClientID,
+			GitHubSecret:       githubSecret,
+			GitHubURL:          getEnvOrDefault("GITHUB_URL", "https://github.com"),
+			JWTSecret:          jwtSecret,
+			AgentToken:         os.Getenv("KC_AGENT_TOKEN"),
+			BootstrapToken:     os.Getenv("CONSOLE_BOOTSTRAP_TOKEN"),
+			MetricsToken:       os.Getenv("METRICS_TOKEN"),
+			DevUserLogin:       getEnvOrDefault("DEV_USER_LOGIN", "dev-user"),
+			DevUserEmail:       getEnvOrDefault("DEV_USER_EMAIL", "dev@localhost"),
+			DevUserAvatar:      getEnvOrDefault("DEV_USER_AVATAR", ""),
+			SessionIdleTimeout: resolveSessionTimeout(envSessionIdleTimeout, middleware.DefaultSessionIdleTimeout, true),
+			SessionMaxLifetime: resolveSessionTimeout(envSessionMaxLifetime, middleware.DefaultSessionMaxLifetime, false),
 		},
 		BrandConfig: BrandConfig{
 			BrandAppName:      getEnvOrDefault("APP_NAME", "KubeStellar Console"),
@@ -268,10 +324,22 @@ func LoadConfigFromEnv() Config {
 			FeedbackRepoName:           getEnvOrDefault("FEEDBACK_REPO_NAME", "console"),
 			RewardsGitHubOrgs:          getEnvOrDefault("REWARDS_GITHUB_ORGS", "repo:kubestellar/console repo:kubestellar/console-marketplace repo:kubestellar/console-kb repo:kubestellar/docs"),
 			BenchmarkGoogleDriveAPIKey: os.Getenv("GOOGLE_DRIVE_API_KEY"),
+			BenchmarkGoogleCredentials: os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"),
 			BenchmarkFolderID:          getEnvOrDefault("BENCHMARK_FOLDER_ID", "1r2Z2Xp1L0KonUlvQHvEzed8AO9Xj8IPm"),
+			BenchmarkSource:            os.Getenv("BENCHMARK_SOURCE"),
+			BenchmarkWebhookToken:      os.Getenv("BENCHMARK_WEBHOOK_TOKEN"),
 			KubaraCatalogRepo:          os.Getenv("KUBARA_CATALOG_REPO"),
 			KubaraCatalogPath:          os.Getenv("KUBARA_CATALOG_PATH"),
 		},
+		ProxyConfig: ProxyConfig{
+			CORSAllowedOrigins: os.Getenv("CORS_ALLOWED_ORIGINS"),
+			CORSNoCredentials:  os.Getenv("CORS_ALLOW_CREDENTIALS") == "false",
+			CORSAllowedHeaders: os.Getenv("CORS_ALLOWED_HEADERS"),
+			CORSExposedHeaders: os.Getenv("CORS_EXPOSED_HEADERS"),
+			TrustedProxies:     os.Getenv("TRUSTED_PROXIES"),
+			ProxyIPHeader:      getEnvOrDefault("PROXY_IP_HEADER", fiber.HeaderXForwardedFor),
+			BasePath:           os.Getenv("BASE_PATH"),
+		},
 	}
 }
 
@@ -317,6 +385,23 @@ func resolveMaxBodyBytes() int {
 	return n
 }
 
+// resolveSessionTimeout reads a session timeout from env, falling back to
+// def when the value is unset, unparsable or negative. Zero is accepted only
+// when allowZero is set (the idle timeout can be disabled, the absolute
+// lifetime cannot).
+func resolveSessionTimeout(env string, def time.Duration, allowZero bool) time.Duration {
+	raw := os.Getenv(env)
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		slog.Warn("invalid session timeout env var; using default", "envVar", env, "value", raw, "default", def)
+		return def
+	}
+	return d
+}
+
 // warnDefaultEnvVars logs a warning for each env var that is not explicitly
 // set.  This helps fork and enterprise deployers notice that the defaults
 // point to the upstream kubestellar repositories so they can override them.
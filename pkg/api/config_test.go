@@ -1,11 +1,14 @@
 package api
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/testharness"
 )
 
 func TestResolveMaxBodyBytesDefaults(t *testing.T) {
@@ -116,3 +119,45 @@ func TestNewServer_ExplicitDevModeStartsWithoutOAuth(t *testing.T) {
 		require.NoError(t, server.Shutdown())
 	})
 }
+
+func TestLoadConfigFromEnv_FakeMode(t *testing.T) {
+	t.Setenv("FAKE_MODE", "true")
+	t.Setenv("FAKE_MODE_SEED", "7")
+	cfg := LoadConfigFromEnv()
+	assert.True(t, cfg.FakeMode)
+	assert.Equal(t, uint64(7), cfg.FakeModeSeed)
+
+	t.Setenv("FAKE_MODE", "")
+	t.Setenv("FAKE_MODE_SEED", "not-a-number")
+	cfg = LoadConfigFromEnv()
+	assert.False(t, cfg.FakeMode)
+	assert.Zero(t, cfg.FakeModeSeed, "an invalid seed falls back to the default")
+}
+
+func TestNewServer_FakeModeUsesHarness(t *testing.T) {
+	cfg := Config{
+		ServerConfig: ServerConfig{
+			Port:         0,
+			DatabasePath: filepath.Join(t.TempDir(), "console.db"),
+			DevMode:      true,
+			FakeMode:     true,
+		},
+		AuthConfig: AuthConfig{
+			JWTSecret: "test-jwt-secret",
+		},
+	}
+
+	server, err := NewServer(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, server.Shutdown())
+	})
+	require.NotNil(t, server.harness)
+	assert.Same(t, server.harness.Clusters, server.k8sClient,
+		"fake mode must never build a client from a kubeconfig")
+	assert.Nil(t, server.bridge, "fake mode skips the MCP bridge")
+
+	clusters, err := server.k8sClient.ListClusters(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, clusters, len(testharness.ClusterNames))
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubestellar/console/pkg/api/handlers/benchmarks"
+	"github.com/kubestellar/console/pkg/client"
+	"github.com/kubestellar/console/pkg/doctor"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// minJWTSecretLength is the secret length the startup error asks for.
+const minJWTSecretLength = 32
+
+// ConfigChecks returns the checks console --check runs against cfg: what
+// NewServer would need to start and serve with this configuration.
+func ConfigChecks(cfg Config) []doctor.Check {
+	// Check the configuration as it will run, air-gapped switches applied.
+	applyAirGap(&cfg)
+	return []doctor.Check{
+		{Name: "jwt-secret", Run: func(context.Context) (doctor.Status, string) { return checkJWTSecret(cfg) }},
+		{Name: "store", Run: func(context.Context) (doctor.Status, string) { return checkStore(cfg) }},
+		{Name: "kubeconfig", Run: func(context.Context) (doctor.Status, string) { return checkKubeconfig(cfg) }},
+		{Name: "console-crds", Run: func(context.Context) (doctor.Status, string) { return checkConsoleCRDs(cfg) }},
+		{Name: "benchmark-source", Run: func(ctx context.Context) (doctor.Status, string) { return checkBenchmarkSource(ctx, cfg) }},
+	}
+}
+
+func checkJWTSecret(cfg Config) (doctor.Status, string) {
+	switch {
+	case cfg.JWTSecret == "" && cfg.DevMode:
+		return doctor.StatusOK, "dev mode generates a secret"
+	case cfg.JWTSecret == "":
+		return doctor.StatusFail, "JWT_SECRET is required outside dev mode"
+	case len(cfg.JWTSecret) < minJWTSecretLength:
+		return doctor.StatusWarn, fmt.Sprintf("JWT_SECRET is shorter than %d characters", minJWTSecretLength)
+	}
+	return doctor.StatusOK, ""
+}
+
+// checkStore opens the store as startup does, which creates and migrates
+// the database if needed.
+func checkStore(cfg Config) (doctor.Status, string) {
+	db, err := store.NewSQLiteStore(cfg.DatabasePath)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+	if err := db.Close(); err != nil {
+		return doctor.StatusWarn, "opened " + cfg.DatabasePath + " but closing failed: " + err.Error()
+	}
+	return doctor.StatusOK, cfg.DatabasePath
+}
+
+// loadKubeconfig loads cfg's kubeconfig as NewServer does. It returns a nil
+// client, and no error, when there is no kubeconfig.
+func loadKubeconfig(cfg Config) (*k8s.MultiClusterClient, string, error) {
+	k8sClient, err := k8s.NewMultiClusterClient(cfg.Kubeconfig)
+	if err != nil {
+		return nil, "", err
+	}
+	if !k8sClient.HasClusterConfig() {
+		return nil, k8sClient.KubeconfigPath(), nil
+	}
+	if err := k8sClient.LoadConfig(); err != nil {
+		return nil, k8sClient.KubeconfigPath(), err
+	}
+	return k8sClient, k8sClient.KubeconfigPath(), nil
+}
+
+func checkKubeconfig(cfg Config) (doctor.Status, string) {
+	if cfg.FakeMode {
+		return doctor.StatusSkipped, "fake mode serves in-memory clusters"
+	}
+	k8sClient, path, err := loadKubeconfig(cfg)
+	if err != nil {
+		return doctor.StatusFail, err.Error()
+	}
+	if k8sClient == nil {
+		return doctor.StatusWarn, "no kubeconfig at " + path + "; the console starts with no clusters"
+	}
+	contexts := 0
+	if raw := k8sClient.GetRawConfig(); raw != nil {
+		contexts = len(raw.Contexts)
+	}
+	return doctor.StatusOK, fmt.Sprintf("%s: %d contexts", path, contexts)
+}
+
+// checkConsoleCRDs checks, when persistence is on, that its primary cluster
+// serves the console CRDs.
+func checkConsoleCRDs(cfg Config) (doctor.Status, string) {
+	persistenceStore := newPersistenceStore(cfg)
+	if err := persistenceStore.Load(); err != nil {
+		return doctor.StatusFail, "loading persistence config: " + err.Error()
+	}
+	persistence := persistenceStore.GetConfig()
+	if !persistence.Enabled || cfg.FakeMode {
+		return doctor.StatusSkipped, "persistence is off"
+	}
+	if persistence.PrimaryCluster == "" {
+		return doctor.StatusFail, "persistence is on but has no primary cluster"
+	}
+	k8sClient, _, err := loadKubeconfig(cfg)
+	if err != nil || k8sClient == nil {
+		return doctor.StatusFail, "no kubeconfig to reach primary cluster " + persistence.PrimaryCluster
+	}
+	crds, err := k8sClient.ConsoleCRDStatus(persistence.PrimaryCluster)
+	if err != nil {
+		return doctor.StatusFail, persistence.PrimaryCluster + ": " + err.Error()
+	}
+	var missing []string
+	for _, crd := range crds {
+		if crd.State != k8s.ConsoleCRDPresent {
+			missing = append(missing, crd.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return doctor.StatusFail, persistence.PrimaryCluster + " is missing " + strings.Join(missing, ", ")
+	}
+	return doctor.StatusOK, fmt.Sprintf("%s serves all %d console CRDs", persistence.PrimaryCluster, len(crds))
+}
+
+// checkBenchmarkSource lists the root of the benchmark source the console
+// would read.
+func checkBenchmarkSource(ctx context.Context, cfg Config) (doctor.Status, string) {
+	if cfg.FakeMode {
+		return doctor.StatusSkipped, "fake mode serves in-memory benchmarks"
+	}
+	h := benchmarks.NewBenchmarkHandlers(cfg.BenchmarkGoogleDriveAPIKey, cfg.BenchmarkFolderID)
+	if cfg.BenchmarkSource != "" {
+		src, err := benchmarks.NewBenchmarkSource(cfg.BenchmarkSource, client.External)
+		if err != nil {
+			return doctor.StatusFail, err.Error()
+		}
+		h.SetSource(src)
+	} else if cfg.BenchmarkGoogleDriveAPIKey == "" {
+		return doctor.StatusSkipped, "no benchmark source configured"
+	}
+	name, entries, err := h.Probe(ctx)
+	if err != nil {
+		return doctor.StatusFail, name + ": " + err.Error()
+	}
+	return doctor.StatusOK, fmt.Sprintf("%s: %d entries", name, entries)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/doctor"
+)
+
+func TestCheckJWTSecret(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want doctor.Status
+	}{
+		{"missing in production", Config{}, doctor.StatusFail},
+		{"missing in dev mode", Config{ServerConfig: ServerConfig{DevMode: true}}, doctor.StatusOK},
+		{"short", Config{AuthConfig: AuthConfig{JWTSecret: "short"}}, doctor.StatusWarn},
+		{"ok", Config{AuthConfig: AuthConfig{JWTSecret: "0123456789abcdef0123456789abcdef"}}, doctor.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, _ := checkJWTSecret(tc.cfg)
+			assert.Equal(t, tc.want, status)
+		})
+	}
+}
+
+func TestCheckStore(t *testing.T) {
+	dir := t.TempDir()
+	status, detail := checkStore(Config{ServerConfig: ServerConfig{DatabasePath: filepath.Join(dir, "console.db")}})
+	assert.Equal(t, doctor.StatusOK, status, detail)
+
+	notADir := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notADir, nil, 0o600))
+	status, _ = checkStore(Config{ServerConfig: ServerConfig{DatabasePath: filepath.Join(notADir, "console.db")}})
+	assert.Equal(t, doctor.StatusFail, status)
+}
+
+func TestCheckBenchmarkSource(t *testing.T) {
+	status, _ := checkBenchmarkSource(context.Background(), Config{})
+	assert.Equal(t, doctor.StatusSkipped, status)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "exp-a"), 0o755))
+	status, detail := checkBenchmarkSource(context.Background(), Config{IntegrationsConfig: IntegrationsConfig{BenchmarkSource: dir}})
+	assert.Equal(t, doctor.StatusOK, status)
+	assert.Contains(t, detail, dir)
+
+	status, _ = checkBenchmarkSource(context.Background(), Config{IntegrationsConfig: IntegrationsConfig{BenchmarkSource: filepath.Join(dir, "missing")}})
+	assert.Equal(t, doctor.StatusFail, status)
+}
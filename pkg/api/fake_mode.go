@@ -0,0 +1,34 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/kubestellar/console/pkg/ai"
+	"github.com/kubestellar/console/pkg/testharness"
+)
+
+// fakeModeDriveAPIKey satisfies the benchmark handlers' "API key configured"
+// check in fake mode; the fake Drive ignores it.
+const fakeModeDriveAPIKey = "fake-mode"
+
+// newFakeModeHarness builds the fake-mode harness and makes its AI provider
+// the default. The data is anchored at the start of the current UTC day, so
+// benchmark reports stay inside the retention window while every request in
+// a test run still sees the same timestamps.
+func newFakeModeHarness(cfg Config) *testharness.Harness {
+	h := testharness.New(testharness.Options{
+		Seed:     cfg.FakeModeSeed,
+		BaseTime: time.Now().UTC().Truncate(24 * time.Hour),
+	})
+	slog.Warn("[Server] FAKE MODE — serving in-memory clusters, benchmarks and AI replies; no real backend is contacted",
+		"seed", h.Seed, "clusters", testharness.ClusterNames)
+	if ai.GetRegistry == nil {
+		slog.Warn("[Server] AI registry not initialized — fake AI provider not registered")
+		return h
+	}
+	if err := h.RegisterProvider(ai.GetRegistry()); err != nil {
+		slog.Warn("[Server] failed to register fake AI provider", "error", err)
+	}
+	return h
+}
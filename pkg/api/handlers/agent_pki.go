@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/agentpki"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// agentPairingCodeTTL is how long an admin-issued pairing code stays
+	// valid. Codes are single use.
+	agentPairingCodeTTL = 10 * time.Minute
+	// agentPairingCodeBytes is the entropy of a pairing code before hex
+	// encoding.
+	agentPairingCodeBytes = 16
+	// agentCertLocal is the Fiber local holding the verified peer
+	// certificate on the relay listener.
+	agentCertLocal = "agentCert"
+)
+
+// AgentPKIHandlers pairs kc-agents with a hosted backend and manages the
+// client certificates they present on the mTLS relay channel. Admins issue
+// single-use pairing codes bound to an agent ID, agents trade a code and a
+// CSR for a certificate, and paired agents renew over the relay itself.
+// Codes are kept in the store, hashed, so they survive a restart.
+type AgentPKIHandlers struct {
+	authority *agentpki.Authority
+	store     store.Store
+	now       func() time.Time
+}
+
+// NewAgentPKIHandlers creates handlers backed by authority.
+func NewAgentPKIHandlers(authority *agentpki.Authority, s store.Store) *AgentPKIHandlers {
+	return &AgentPKIHandlers{
+		authority: authority,
+		store:     s,
+		now:       time.Now,
+	}
+}
+
+// AgentPairingCodeResponse is returned when an admin issues a pairing code.
+type AgentPairingCodeResponse struct {
+	AgentID   string    `json:"agentId"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CA        string    `json:"ca"`
+}
+
+// AgentCertificateResponse carries a signed agent certificate and the CA the
+// agent pins for the relay channel.
+type AgentCertificateResponse struct {
+	AgentID     string    `json:"agentId"`
+	Certificate string    `json:"certificate"`
+	CA          string    `json:"ca"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+type agentPairingCodeRequest struct {
+	AgentID string `json:"agentId"`
+}
+
+type agentPairRequest struct {
+	AgentID string `json:"agentId"`
+	Code    string `json:"code"`
+	CSR     string `json:"csr"`
+}
+
+type agentRotateRequest struct {
+	CSR string `json:"csr"`
+}
+
+// CreatePairingCode issues a single-use pairing code for one agent ID.
+// POST /api/admin/agents/pairing-codes
+func (h *AgentPKIHandlers) CreatePairingCode(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	var req agentPairingCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if !agentpki.ValidAgentID(req.AgentID) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": agentpki.ErrInvalidAgentID.Error()})
+	}
+	buf := make([]byte, agentPairingCodeBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to generate pairing code"})
+	}
+	code := hex.EncodeToString(buf)
+	now := h.now()
+	expires := now.Add(agentPairingCodeTTL)
+
+	ctx := c.UserContext()
+	if _, err := h.store.DeleteExpiredAgentPairingCodes(ctx, now); err != nil {
+		slog.Warn("[AgentPKI] failed to purge expired pairing codes", "error", err)
+	}
+	if err := h.store.CreateAgentPairingCode(ctx, hashPairingCode(code), req.AgentID, expires); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to store pairing code"})
+	}
+
+	audit.Log(c, audit.ActionCreateAgentPairingCode, "agent", req.AgentID, "")
+	return c.Status(fiber.StatusCreated).JSON(AgentPairingCodeResponse{
+		AgentID:   req.AgentID,
+		Code:      code,
+		ExpiresAt: expires,
+		CA:        string(h.authority.CAPEM()),
+	})
+}
+
+// Pair signs the agent's CSR in exchange for a pairing code. Agents have no
+// console session, so the code is the only credential, and the certificate
+// is always issued for the agent ID the code was bound to.
+// POST /api/agents/pair
+func (h *AgentPKIHandlers) Pair(c *fiber.Ctx) error {
+	var req agentPairRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.Code == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired pairing code"})
+	}
+	agentID, err := h.store.ConsumeAgentPairingCode(c.UserContext(), hashPairingCode(req.Code), h.now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check pairing code"})
+	}
+	if agentID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired pairing code"})
+	}
+	if req.AgentID != "" && req.AgentID != agentID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "pairing code was issued for another agent"})
+	}
+	certPEM, err := h.authority.Issue(agentID, []byte(req.CSR))
+	if err != nil {
+		return agentPKIError(c, err)
+	}
+	return h.certificateResponse(c, agentID, certPEM)
+}
+
+// Revoke rejects every certificate issued to an agent so far. The agent has
+// to pair again with a new code.
+// POST /api/admin/agents/:id/revoke
+func (h *AgentPKIHandlers) Revoke(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	agentID := c.Params("id")
+	if err := h.authority.Revoke(agentID); err != nil {
+		return agentPKIError(c, err)
+	}
+	audit.Log(c, audit.ActionRevokeAgentCertificate, "agent", agentID, "")
+	return c.JSON(fiber.Map{"agentId": agentID, "revoked": true})
+}
+
+// RequireAgentCertificate admits relay requests whose TLS peer presented a
+// valid, unrevoked agent certificate. The handshake already checked the
+// chain; checking again per request also catches revocations on
+// long-lived connections.
+func (h *AgentPKIHandlers) RequireAgentCertificate(c *fiber.Ctx) error {
+	var cert *x509.Certificate
+	if state := c.Context().TLSConnectionState(); state != nil && len(state.PeerCertificates) > 0 {
+		cert = state.PeerCertificates[0]
+	}
+	if err := h.authority.Verify(cert); err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	c.Locals(agentCertLocal, cert)
+	return c.Next()
+}
+
+// Rotate issues a fresh certificate for the agent on the other end of the
+// relay connection. Agents call it once agentpki.NeedsRotation reports true.
+// POST /relay/rotate
+func (h *AgentPKIHandlers) Rotate(c *fiber.Ctx) error {
+	cert, _ := c.Locals(agentCertLocal).(*x509.Certificate)
+	if cert == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "agent certificate required"})
+	}
+	var req agentRotateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	certPEM, err := h.authority.Rotate(cert, []byte(req.CSR))
+	if err != nil {
+		return agentPKIError(c, err)
+	}
+	return h.certificateResponse(c, cert.Subject.CommonName, certPEM)
+}
+
+func (h *AgentPKIHandlers) certificateResponse(c *fiber.Ctx, agentID string, certPEM []byte) error {
+	cert, err := agentpki.ParseCertPEM(certPEM)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to parse issued certificate"})
+	}
+	return c.JSON(AgentCertificateResponse{
+		AgentID:     agentID,
+		Certificate: string(certPEM),
+		CA:          string(h.authority.CAPEM()),
+		ExpiresAt:   cert.NotAfter,
+	})
+}
+
+// hashPairingCode is the form a pairing code is stored and looked up in.
+func hashPairingCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func agentPKIError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, agentpki.ErrInvalidAgentID), errors.Is(err, agentpki.ErrInvalidCSR):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, agentpki.ErrRevoked), errors.Is(err, agentpki.ErrUntrusted):
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "agent certificate operation failed"})
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/agentpki"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agentPKITestApp serves the pairing and revoke routes to userID.
+func agentPKITestApp(h *AgentPKIHandlers, userID uuid.UUID) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/api/admin/agents/pairing-codes", h.CreatePairingCode)
+	app.Post("/api/agents/pair", h.Pair)
+	app.Post("/api/admin/agents/:id/revoke", h.Revoke)
+	return app
+}
+
+// setupAgentPKITest returns an app signed in as an admin, backed by a real
+// store so pairing codes persist like they do in production.
+func setupAgentPKITest(t *testing.T) (*fiber.App, *AgentPKIHandlers, *agentpki.Authority) {
+	t.Helper()
+	s := store.OpenTestDB(t)
+	admin := &models.User{GitHubID: "1", GitHubLogin: "admin", Role: models.UserRoleAdmin}
+	require.NoError(t, s.CreateUser(context.Background(), admin))
+	authority, err := agentpki.Open(t.TempDir())
+	require.NoError(t, err)
+	h := NewAgentPKIHandlers(authority, s)
+	return agentPKITestApp(h, admin.ID), h, authority
+}
+
+// postAgentJSON sends body as JSON and decodes the response into out when
+// out is non-nil.
+func postAgentJSON(t *testing.T, app *fiber.App, path string, body interface{}, out interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	if out != nil && resp.StatusCode < http.StatusBadRequest {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+// createPairingCode issues a pairing code for agentID.
+func createPairingCode(t *testing.T, app *fiber.App, agentID string) AgentPairingCodeResponse {
+	t.Helper()
+	var code AgentPairingCodeResponse
+	require.Equal(t, http.StatusCreated, postAgentJSON(t, app, "/api/admin/agents/pairing-codes", agentPairingCodeRequest{AgentID: agentID}, &code))
+	assert.Equal(t, agentID, code.AgentID)
+	return code
+}
+
+// pairAgent pairs agentID through the HTTP endpoints and returns its
+// certificate and key.
+func pairAgent(t *testing.T, app *fiber.App, agentID string) (certPEM, keyPEM []byte, caPEM []byte) {
+	t.Helper()
+	code := createPairingCode(t, app, agentID)
+	csrPEM, keyPEM, err := agentpki.NewCSR(agentID)
+	require.NoError(t, err)
+	var issued AgentCertificateResponse
+	require.Equal(t, http.StatusOK, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		AgentID: agentID, Code: code.Code, CSR: string(csrPEM),
+	}, &issued))
+	assert.Equal(t, code.CA, issued.CA)
+	return []byte(issued.Certificate), keyPEM, []byte(issued.CA)
+}
+
+func TestAgentPKI_PairWithCode(t *testing.T) {
+	app, _, authority := setupAgentPKITest(t)
+
+	code := createPairingCode(t, app, "agent-1")
+	csrPEM, _, err := agentpki.NewCSR("agent-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		AgentID: "agent-1", Code: "not-a-code", CSR: string(csrPEM),
+	}, nil))
+
+	var issued AgentCertificateResponse
+	require.Equal(t, http.StatusOK, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		AgentID: "agent-1", Code: code.Code, CSR: string(csrPEM),
+	}, &issued))
+	cert, err := agentpki.ParseCertPEM([]byte(issued.Certificate))
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", cert.Subject.CommonName)
+	assert.NoError(t, authority.Verify(cert))
+
+	// Codes are single use.
+	assert.Equal(t, http.StatusUnauthorized, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		AgentID: "agent-1", Code: code.Code, CSR: string(csrPEM),
+	}, nil))
+}
+
+func TestAgentPKI_CodeIsBoundToAgentID(t *testing.T) {
+	app, _, _ := setupAgentPKITest(t)
+
+	assert.Equal(t, http.StatusBadRequest, postAgentJSON(t, app, "/api/admin/agents/pairing-codes", agentPairingCodeRequest{AgentID: "bad id"}, nil))
+
+	// A code for agent-2 cannot be used to take over agent-1.
+	code := createPairingCode(t, app, "agent-2")
+	csrPEM, _, err := agentpki.NewCSR("agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		AgentID: "agent-1", Code: code.Code, CSR: string(csrPEM),
+	}, nil))
+
+	// Whatever the CSR says, the certificate is issued for the bound ID.
+	code = createPairingCode(t, app, "agent-2")
+	var issued AgentCertificateResponse
+	require.Equal(t, http.StatusOK, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		Code: code.Code, CSR: string(csrPEM),
+	}, &issued))
+	assert.Equal(t, "agent-2", issued.AgentID)
+	cert, err := agentpki.ParseCertPEM([]byte(issued.Certificate))
+	require.NoError(t, err)
+	assert.Equal(t, "agent-2", cert.Subject.CommonName)
+}
+
+func TestAgentPKI_CodesSurviveRestart(t *testing.T) {
+	app, h, authority := setupAgentPKITest(t)
+	code := createPairingCode(t, app, "agent-1")
+
+	restarted := agentPKITestApp(NewAgentPKIHandlers(authority, h.store), uuid.Nil)
+	csrPEM, _, err := agentpki.NewCSR("agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, postAgentJSON(t, restarted, "/api/agents/pair", agentPairRequest{
+		AgentID: "agent-1", Code: code.Code, CSR: string(csrPEM),
+	}, nil))
+}
+
+func TestAgentPKI_PairingCodeExpires(t *testing.T) {
+	app, h, _ := setupAgentPKITest(t)
+
+	code := createPairingCode(t, app, "agent-1")
+	h.now = func() time.Time { return time.Now().Add(agentPairingCodeTTL + time.Minute) }
+
+	csrPEM, _, err := agentpki.NewCSR("agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, postAgentJSON(t, app, "/api/agents/pair", agentPairRequest{
+		AgentID: "agent-1", Code: code.Code, CSR: string(csrPEM),
+	}, nil))
+}
+
+func TestAgentPKI_AdminOnly(t *testing.T) {
+	_, h, _ := setupAgentPKITest(t)
+
+	viewer := &models.User{GitHubID: "2", GitHubLogin: "viewer", Role: models.UserRoleViewer}
+	require.NoError(t, h.store.CreateUser(context.Background(), viewer))
+	app := agentPKITestApp(h, viewer.ID)
+
+	assert.Equal(t, http.StatusForbidden, postAgentJSON(t, app, "/api/admin/agents/pairing-codes", agentPairingCodeRequest{AgentID: "agent-1"}, nil))
+	assert.Equal(t, http.StatusForbidden, postAgentJSON(t, app, "/api/admin/agents/agent-1/revoke", nil, nil))
+}
+
+// startRelay serves the relay routes over mutual TLS and returns a client
+// holding the agent's certificate.
+func startRelay(t *testing.T, h *AgentPKIHandlers, authority *agentpki.Authority, certPEM, keyPEM, caPEM []byte) (*http.Client, string) {
+	t.Helper()
+	serverTLS, err := authority.RelayTLSConfig("127.0.0.1")
+	require.NoError(t, err)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	require.NoError(t, err)
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	relay := app.Group("/relay", h.RequireAgentCertificate)
+	relay.Post("/rotate", h.Rotate)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	clientTLS, err := agentpki.ClientTLSConfig(certPEM, keyPEM, caPEM, "127.0.0.1")
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}, Timeout: 5 * time.Second}
+	return client, "https://" + ln.Addr().String()
+}
+
+func relayRotate(t *testing.T, client *http.Client, base, agentID string) (int, AgentCertificateResponse) {
+	t.Helper()
+	csrPEM, _, err := agentpki.NewCSR(agentID)
+	require.NoError(t, err)
+	data, err := json.Marshal(agentRotateRequest{CSR: string(csrPEM)})
+	require.NoError(t, err)
+	resp, err := client.Post(base+"/relay/rotate", "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var out AgentCertificateResponse
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out
+}
+
+func TestAgentPKI_RotateAndRevokeOverRelay(t *testing.T) {
+	app, h, authority := setupAgentPKITest(t)
+	certPEM, keyPEM, caPEM := pairAgent(t, app, "agent-1")
+	client, base := startRelay(t, h, authority, certPEM, keyPEM, caPEM)
+
+	// The relay identifies the agent by its certificate, whatever the CSR says.
+	status, rotated := relayRotate(t, client, base, "someone-else")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "agent-1", rotated.AgentID)
+	cert, err := agentpki.ParseCertPEM([]byte(rotated.Certificate))
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", cert.Subject.CommonName)
+
+	// Revocation applies to the connection that is already open.
+	require.Equal(t, http.StatusOK, postAgentJSON(t, app, "/api/admin/agents/agent-1/revoke", nil, nil))
+	assert.True(t, errors.Is(authority.Verify(cert), agentpki.ErrRevoked))
+	status, _ = relayRotate(t, client, base, "agent-1")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAgentPKI_RelayRejectsForeignCertificate(t *testing.T) {
+	_, h, authority := setupAgentPKITest(t)
+
+	other, err := agentpki.Open(t.TempDir())
+	require.NoError(t, err)
+	csrPEM, keyPEM, err := agentpki.NewCSR("agent-1")
+	require.NoError(t, err)
+	certPEM, err := other.Issue("agent-1", csrPEM)
+	require.NoError(t, err)
+
+	client, base := startRelay(t, h, authority, certPEM, keyPEM, authority.CAPEM())
+	_, err = client.Post(base+"/relay/rotate", "application/json", bytes.NewReader([]byte("{}")))
+	assert.Error(t, err, "handshake with a certificate from another authority should fail")
+}
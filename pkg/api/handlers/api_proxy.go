@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// apiProxyTimeout bounds a proxied get or list, including reading the
+	// response body.
+	apiProxyTimeout = 60 * time.Second
+	// apiProxyStreamTimeout bounds watches and followed pod logs.
+	apiProxyStreamTimeout = 30 * time.Minute
+)
+
+// apiProxyAllowedVerbs are the Kubernetes verbs the API proxy forwards.
+var apiProxyAllowedVerbs = map[string]bool{
+	"get":   true,
+	"list":  true,
+	"watch": true,
+}
+
+// apiProxyDeniedResources are resources the API proxy never reads, whatever
+// the verb, since the console's credentials can usually read more than the
+// user should see.
+var apiProxyDeniedResources = map[string]bool{
+	"secrets": true,
+}
+
+// apiProxyAllowedSubresources are the subresources the API proxy forwards.
+// Connect subresources such as exec, attach, portforward and proxy are
+// upgraded GETs, so they are left out explicitly.
+var apiProxyAllowedSubresources = map[string]bool{
+	"status": true,
+	"log":    true,
+	"scale":  true,
+}
+
+// apiProxyNonResourcePaths are the paths outside /api and /apis the API proxy
+// forwards, matched by prefix on whole segments.
+var apiProxyNonResourcePaths = []string{"version", "openapi/v2", "openapi/v3"}
+
+// apiProxyNamespaceSubresources are subresources of a namespace object, which
+// the API server tells apart from namespaced resources by name.
+var apiProxyNamespaceSubresources = map[string]bool{
+	"status":   true,
+	"finalize": true,
+}
+
+// apiProxyRequest is what an API proxy path resolves to, following the API
+// server's own request parsing.
+type apiProxyRequest struct {
+	Verb        string
+	Group       string
+	Namespace   string
+	Resource    string
+	Name        string
+	Subresource string
+	// NonResource is set for discovery, version and OpenAPI paths.
+	NonResource bool
+}
+
+// target is the audit target type of the request, e.g. "pods/log" or
+// "deployments.apps".
+func (r apiProxyRequest) target() string {
+	if r.NonResource {
+		return "nonresource"
+	}
+	t := r.Resource
+	if r.Group != "" {
+		t += "." + r.Group
+	}
+	if r.Subresource != "" {
+		t += "/" + r.Subresource
+	}
+	return t
+}
+
+// APIProxyHandler forwards read-only requests to a managed cluster's API
+// server with the console's credentials, so the UI can read any kind without
+// a dedicated endpoint. Only get, list and watch are forwarded, Secrets and
+// connect subresources are refused, and every request is audited.
+type APIProxyHandler struct {
+	k8sClient serviceProxyClient
+	store     store.Store
+	clients   proxyHTTPClients
+}
+
+// NewAPIProxyHandler creates an API server proxy handler.
+func NewAPIProxyHandler(k8sClient *k8s.MultiClusterClient, s store.Store) *APIProxyHandler {
+	h := &APIProxyHandler{store: s}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// parseAPIProxyPath resolves a path relative to the API server root, e.g.
+// "api/v1/namespaces/default/pods", and the request's watch parameter.
+func parseAPIProxyPath(p string, watch bool) (apiProxyRequest, error) {
+	p = strings.Trim(p, "/")
+	if p == "" || !safeProxyPath(p) {
+		return apiProxyRequest{}, fmt.Errorf("invalid path")
+	}
+	parts := strings.Split(p, "/")
+	for _, seg := range parts {
+		if seg == "" {
+			return apiProxyRequest{}, fmt.Errorf("invalid path")
+		}
+	}
+
+	var req apiProxyRequest
+	switch parts[0] {
+	case "api":
+		if len(parts) < 3 {
+			return apiProxyRequest{Verb: "get", NonResource: true}, nil
+		}
+		parts = parts[2:]
+	case "apis":
+		if len(parts) < 4 {
+			return apiProxyRequest{Verb: "get", NonResource: true}, nil
+		}
+		req.Group = parts[1]
+		parts = parts[3:]
+	default:
+		for _, allowed := range apiProxyNonResourcePaths {
+			if p == allowed || strings.HasPrefix(p, allowed+"/") {
+				return apiProxyRequest{Verb: "get", NonResource: true}, nil
+			}
+		}
+		return apiProxyRequest{}, fmt.Errorf("path is not allowed")
+	}
+
+	req.Verb = "get"
+	if parts[0] == "watch" {
+		// Deprecated /watch/ paths, e.g. api/v1/watch/pods.
+		req.Verb = "watch"
+		parts = parts[1:]
+		if len(parts) == 0 {
+			return apiProxyRequest{}, fmt.Errorf("invalid path")
+		}
+	}
+	if parts[0] == "namespaces" && len(parts) > 1 {
+		req.Namespace = parts[1]
+		if len(parts) > 2 && !apiProxyNamespaceSubresources[parts[2]] {
+			parts = parts[2:]
+		}
+	}
+	req.Resource = parts[0]
+	if len(parts) > 1 {
+		req.Name = parts[1]
+	}
+	if len(parts) > 2 {
+		req.Subresource = parts[2]
+	}
+	if len(parts) > 3 {
+		return apiProxyRequest{}, fmt.Errorf("invalid path")
+	}
+
+	if req.Name == "" && req.Verb == "get" {
+		req.Verb = "list"
+	}
+	if req.Verb == "list" && watch {
+		req.Verb = "watch"
+	}
+	return req, nil
+}
+
+// checkAPIProxyRequest applies the verb and path allowlists.
+func checkAPIProxyRequest(req apiProxyRequest) error {
+	if !apiProxyAllowedVerbs[req.Verb] {
+		return fmt.Errorf("verb %q is not allowed", req.Verb)
+	}
+	if req.NonResource {
+		return nil
+	}
+	if apiProxyDeniedResources[req.Resource] {
+		return fmt.Errorf("resource %q is not allowed", req.Resource)
+	}
+	if req.Subresource != "" && !apiProxyAllowedSubresources[req.Subresource] {
+		return fmt.Errorf("subresource %q is not allowed", req.Subresource)
+	}
+	return nil
+}
+
+// Proxy forwards a read-only request to the cluster's API server. The path
+// after /proxy/ is the API server path, e.g.
+// /api/clusters/prod/proxy/apis/apps/v1/namespaces/shop/deployments?watch=1.
+// Watches and followed logs are streamed.
+// ANY /api/clusters/:cluster/proxy/*
+func (h *APIProxyHandler) Proxy(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if method := c.Method(); method != fiber.MethodGet {
+		// The proxy is read-only, but a write from a viewer is a role
+		// failure first, as on every other mutating endpoint.
+		if err := RequireEditorOrAdmin(c, h.store); err != nil {
+			audit.Log(c, audit.ActionAPIProxyDenied, "api_proxy", cluster, "method="+method+" reason=role")
+			return err
+		}
+		audit.Log(c, audit.ActionAPIProxyDenied, "api_proxy", cluster, "method="+method)
+		return c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{"error": "only get, list and watch requests are allowed"})
+	}
+	path, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid path"})
+	}
+	watch := c.Query("watch")
+	req, err := parseAPIProxyPath(path, watch == "true" || watch == "1")
+	if err == nil {
+		err = checkAPIProxyRequest(req)
+	}
+	if err != nil {
+		audit.Log(c, audit.ActionAPIProxyDenied, "api_proxy", cluster, fmt.Sprintf("path=/%s reason=%q", path, err.Error()))
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+
+	config, err := h.k8sClient.GetRestConfig(cluster)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	client, err := h.clients.get(cluster, config)
+	if err != nil {
+		slog.Error("[APIProxy] failed to build transport", "cluster", cluster, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Failed to reach cluster"})
+	}
+	u, err := url.Parse(strings.TrimSuffix(config.Host, "/"))
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Failed to reach cluster"})
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.Trim(path, "/")
+	u.RawQuery = string(c.Request().URI().QueryString())
+
+	timeout := apiProxyTimeout
+	if req.Verb == "watch" || (req.Subresource == "log" && c.QueryBool("follow")) {
+		timeout = apiProxyStreamTimeout
+	}
+	ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+	upstreamReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		cancel()
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid proxy request"})
+	}
+	// Accept selects JSON, Table or protobuf output. Nothing else from the
+	// browser is forwarded.
+	if accept := c.Get(fiber.HeaderAccept); accept != "" {
+		upstreamReq.Header.Set(fiber.HeaderAccept, accept)
+	}
+
+	audit.Log(c, audit.ActionAPIProxyRequest, req.target(), cluster,
+		fmt.Sprintf("verb=%s namespace=%s name=%s", req.Verb, req.Namespace, req.Name))
+
+	resp, err := client.Do(upstreamReq)
+	if err != nil {
+		cancel()
+		slog.Info("[APIProxy] upstream request failed", "cluster", cluster, "path", path, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Upstream request failed"})
+	}
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != "" {
+		c.Set(fiber.HeaderContentType, ct)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Status(resp.StatusCode)
+	// The body is streamed after the handler returns, so the timeout is
+	// released when the stream is closed rather than here.
+	c.Response().SetBodyStream(&cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, -1)
+	return nil
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAPIProxyTest(t *testing.T, upstream http.HandlerFunc) *fiber.App {
+	t.Helper()
+	apiServer := httptest.NewServer(upstream)
+	t.Cleanup(apiServer.Close)
+
+	h := &APIProxyHandler{k8sClient: fakeRestConfigClient{host: apiServer.URL}}
+	app := fiber.New()
+	app.All("/api/clusters/:cluster/proxy/*", h.Proxy)
+	return app
+}
+
+func TestParseAPIProxyPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		watch bool
+		want  apiProxyRequest
+	}{
+		{"api", false, apiProxyRequest{Verb: "get", NonResource: true}},
+		{"apis/apps/v1", false, apiProxyRequest{Verb: "get", NonResource: true}},
+		{"version", false, apiProxyRequest{Verb: "get", NonResource: true}},
+		{"openapi/v3/apis/apps/v1", false, apiProxyRequest{Verb: "get", NonResource: true}},
+		{"api/v1/nodes", false, apiProxyRequest{Verb: "list", Resource: "nodes"}},
+		{"api/v1/namespaces", false, apiProxyRequest{Verb: "list", Resource: "namespaces"}},
+		{"api/v1/namespaces/shop", false, apiProxyRequest{Verb: "get", Namespace: "shop", Resource: "namespaces", Name: "shop"}},
+		{"api/v1/namespaces/shop/status", false, apiProxyRequest{Verb: "get", Namespace: "shop", Resource: "namespaces", Name: "shop", Subresource: "status"}},
+		{"api/v1/namespaces/shop/pods", true, apiProxyRequest{Verb: "watch", Namespace: "shop", Resource: "pods"}},
+		{"api/v1/namespaces/shop/pods/web-0/log", false, apiProxyRequest{Verb: "get", Namespace: "shop", Resource: "pods", Name: "web-0", Subresource: "log"}},
+		{"apis/apps/v1/namespaces/shop/deployments/web", true, apiProxyRequest{Verb: "get", Group: "apps", Namespace: "shop", Resource: "deployments", Name: "web"}},
+		{"api/v1/watch/namespaces/shop/pods", false, apiProxyRequest{Verb: "watch", Namespace: "shop", Resource: "pods"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseAPIProxyPath(tt.path, tt.watch)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, bad := range []string{"", "healthz", "metrics", "api/v1/namespaces/shop/pods/web-0/log/extra", "api/v1/../../healthz", "api//v1/pods"} {
+		_, err := parseAPIProxyPath(bad, false)
+		assert.Error(t, err, "path %q", bad)
+	}
+}
+
+func TestAPIProxy_Forwards(t *testing.T) {
+	var gotPath, gotQuery, gotAccept, gotCookie string
+	app := setupAPIProxyTest(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		gotAccept, gotCookie = r.Header.Get("Accept"), r.Header.Get("Cookie")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "upstream=1")
+		_, _ = io.WriteString(w, `{"kind":"DeploymentList","items":[]}`)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/clusters/prod/proxy/apis/apps/v1/namespaces/shop/deployments?labelSelector=app%3Dweb", nil)
+	req.Header.Set("Accept", "application/json;as=Table;v=v1;g=meta.k8s.io")
+	req.Header.Set("Cookie", "kc_auth=secret")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"kind":"DeploymentList","items":[]}`, string(body))
+	assert.Equal(t, "/apis/apps/v1/namespaces/shop/deployments", gotPath)
+	assert.Equal(t, "labelSelector=app%3Dweb", gotQuery)
+	assert.Equal(t, "application/json;as=Table;v=v1;g=meta.k8s.io", gotAccept)
+	assert.Empty(t, gotCookie, "console cookies are never forwarded")
+	assert.Empty(t, resp.Header.Get("Set-Cookie"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestAPIProxy_PassesUpstreamErrors(t *testing.T) {
+	app := setupAPIProxyTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"kind":"Status","reason":"NotFound"}`)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/clusters/prod/proxy/api/v1/namespaces/shop/pods/missing", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "NotFound")
+}
+
+func TestAPIProxy_Rejections(t *testing.T) {
+	app := setupAPIProxyTest(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream request %s %s", r.Method, r.URL.Path)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"write", http.MethodDelete, "api/v1/namespaces/shop/pods/web-0", http.StatusMethodNotAllowed},
+		{"create", http.MethodPost, "api/v1/namespaces/shop/pods", http.StatusMethodNotAllowed},
+		{"secrets", http.MethodGet, "api/v1/namespaces/shop/secrets", http.StatusForbidden},
+		{"secret watch", http.MethodGet, "api/v1/watch/secrets", http.StatusForbidden},
+		{"exec", http.MethodGet, "api/v1/namespaces/shop/pods/web-0/exec?command=sh", http.StatusForbidden},
+		{"service proxy", http.MethodGet, "api/v1/namespaces/shop/services/web:80/proxy", http.StatusForbidden},
+		{"node proxy", http.MethodGet, "api/v1/nodes/node-1/proxy", http.StatusForbidden},
+		{"non-resource", http.MethodGet, "healthz", http.StatusForbidden},
+		{"dot segments", http.MethodGet, "api/v1/namespaces/shop/pods/%2e%2e/%2e%2e/secrets", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/api/clusters/prod/proxy/"+tt.path, nil), 5000)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestAPIProxy_NoClusterAccess(t *testing.T) {
+	h := NewAPIProxyHandler(nil, nil)
+	app := fiber.New()
+	app.Get("/api/clusters/:cluster/proxy/*", h.Proxy)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/clusters/prod/proxy/api/v1/pods", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestAPIProxy_WritesRequireEditor(t *testing.T) {
+	env := setupTestEnv(t)
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream request %s %s", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(apiServer.Close)
+	h := &APIProxyHandler{k8sClient: fakeRestConfigClient{host: apiServer.URL}, store: env.Store}
+
+	viewerID := uuid.New()
+	env.Store.(*test.MockStore).On("GetUser", viewerID).Return(&models.User{ID: viewerID, Role: models.UserRoleViewer}, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", viewerID)
+		return c.Next()
+	})
+	app.All("/api/clusters/:cluster/proxy/*", h.Proxy)
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		resp, err := app.Test(httptest.NewRequest(method, "/api/clusters/prod/proxy/api/v1/namespaces/shop/pods", nil), 5000)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, method)
+	}
+
+	// Editors and admins pass the role check but the proxy stays read-only.
+	env.App.All("/api/clusters/:cluster/proxy/*", h.Proxy)
+	resp, err := env.App.Test(httptest.NewRequest(http.MethodPost, "/api/clusters/prod/proxy/api/v1/namespaces/shop/pods", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
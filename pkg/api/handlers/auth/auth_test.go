@@ -141,7 +141,7 @@ func TestRefreshToken(t *testing.T) {
 		// 1. Generate a valid token manually
 		uid := uuid.New()
 		user := &models.User{ID: uid, GitHubLogin: "test", Onboarded: true}
-		token, _ := handler.generateJWT(user)
+		token, _ := handler.generateJWT(user, "")
 
 		// 2. Setup mock
 		mockStore.On("GetUser", uid).Return(user, nil).Once()
@@ -185,7 +185,7 @@ func TestRefreshToken(t *testing.T) {
 		// at the CSRF gate before even looking at the token.
 		uid := uuid.New()
 		user := &models.User{ID: uid, GitHubLogin: "test"}
-		token, _ := handler.generateJWT(user)
+		token, _ := handler.generateJWT(user, "")
 
 		req, _ := http.NewRequest("POST", "/auth/refresh", nil)
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -215,7 +215,7 @@ func TestRefreshToken(t *testing.T) {
 	t.Run("User Not Found", func(t *testing.T) {
 		uid := uuid.New()
 		user := &models.User{ID: uid}
-		token, _ := handler.generateJWT(user)
+		token, _ := handler.generateJWT(user, "")
 
 		mockStore.On("GetUser", uid).Return(nil, nil).Once()
 
@@ -345,7 +345,7 @@ func TestGenerateJWT(t *testing.T) {
 		GitHubLogin: "test-user",
 	}
 
-	token, err := handler.generateJWT(user)
+	token, err := handler.generateJWT(user, "")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -464,7 +464,7 @@ func TestGitHubCallback_RecoversFromValidCookieOnStateFailure(t *testing.T) {
 
 	t.Run("valid cookie + invalid state redirects to /", func(t *testing.T) {
 		user := &models.User{ID: uuid.New(), GitHubLogin: "already-signed-in"}
-		cookieToken, err := handler.generateJWT(user)
+		cookieToken, err := handler.generateJWT(user, "")
 		assert.NoError(t, err)
 
 		req, _ := http.NewRequest("GET", "/auth/callback?code=123&state=bogus", nil)
@@ -544,7 +544,7 @@ func TestGitHubCallback_RecoversFromValidCookieOnStateFailure(t *testing.T) {
 	t.Run("empty state + valid cookie still recovers to /", func(t *testing.T) {
 		// state missing entirely (not just invalid) should also recover.
 		user := &models.User{ID: uuid.New(), GitHubLogin: "empty-state"}
-		cookieToken, err := handler.generateJWT(user)
+		cookieToken, err := handler.generateJWT(user, "")
 		assert.NoError(t, err)
 
 		req, _ := http.NewRequest("GET", "/auth/callback?code=123", nil)
@@ -774,7 +774,7 @@ func TestLogout_RequiresCSRFHeader(t *testing.T) {
 
 	uid := uuid.New()
 	user := &models.User{ID: uid, GitHubLogin: "test"}
-	token, _ := handler.generateJWT(user)
+	token, _ := handler.generateJWT(user, "")
 
 	// Without the CSRF header: 403.
 	req, err := http.NewRequest("POST", "/auth/logout", nil)
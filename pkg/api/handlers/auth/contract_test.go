@@ -36,7 +36,7 @@ func TestAuthRefreshContract_TokenNotInResponseBody(t *testing.T) {
 	// 1. Create a mock user and generate a valid JWT.
 	uid := uuid.New()
 	user := &models.User{ID: uid, GitHubLogin: "contract-test-user", Onboarded: true}
-	token, err := handler.generateJWT(user)
+	token, err := handler.generateJWT(user, "")
 	require.NoError(t, err, "generateJWT must succeed")
 
 	// 2. Setup mock: GetUser returns the user.
@@ -94,7 +94,7 @@ func TestAuthRefreshContract_OnboardedFalse(t *testing.T) {
 
 	uid := uuid.New()
 	user := &models.User{ID: uid, GitHubLogin: "new-user", Onboarded: false}
-	token, err := handler.generateJWT(user)
+	token, err := handler.generateJWT(user, "")
 	require.NoError(t, err)
 
 	mockStore.On("GetUser", uid).Return(user, nil).Once()
@@ -8,6 +8,7 @@ import (
 	"github.com/kubestellar/console/pkg/api/audit"
 	"github.com/kubestellar/console/pkg/api/middleware"
 	"github.com/kubestellar/console/pkg/client"
+	"github.com/kubestellar/console/pkg/limits"
 	"github.com/kubestellar/console/pkg/models"
 	"github.com/kubestellar/console/pkg/safego"
 	"github.com/kubestellar/console/pkg/store"
@@ -42,9 +43,11 @@ type AuthConfig struct {
 }
 
 // SessionDisconnecter is the subset of Hub needed to close WebSocket sessions
-// on logout. Defined as an interface to avoid a circular dependency.
+// on logout and session revocation. Defined as an interface to avoid a
+// circular dependency.
 type SessionDisconnecter interface {
 	DisconnectUser(userID uuid.UUID)
+	DisconnectSession(userID uuid.UUID, sessionID, reason string)
 }
 
 // AuthHandler handles authentication
@@ -62,6 +65,7 @@ type AuthHandler struct {
 	skipOnboarding bool
 	wsHub          SessionDisconnecter      // optional — set via SetHub to disconnect WS sessions on logout
 	onLogoutSSE    func(userID uuid.UUID)   // optional — tears down SSE streams on logout; set via SetSSECanceller
+	limits         *limits.Enforcer         // optional — caps the number of users; set via SetLimits
 	cleanupCtx     context.Context     // cancelled by Stop to terminate the OAuth state cleanup goroutine
 	cleanupCancel  context.CancelFunc  // call to stop the OAuth state cleanup goroutine
 	// githubHTTPClient is a shared HTTP client for GitHub API calls (#6582).
@@ -263,8 +267,14 @@ func (h *AuthHandler) devModeLogin(c *fiber.Ctx) error {
 			"user", user.ID, "error", err)
 	}
 
+	sessionID, err := h.startSession(c, user)
+	if err != nil {
+		slog.Error("[Auth] failed to create session (devMode)", "user", user.ID, "error", err)
+		return c.Redirect(h.frontendURL+"/login?error=db_error", fiber.StatusTemporaryRedirect)
+	}
+
 	// Generate JWT
-	jwtToken, err := h.generateJWT(user)
+	jwtToken, err := h.generateJWT(user, sessionID)
 	if err != nil {
 		return c.Redirect(h.frontendURL+"/login?error=jwt_failed", fiber.StatusTemporaryRedirect)
 	}
@@ -374,6 +384,16 @@ func (h *AuthHandler) GitHubCallback(c *fiber.Ctx) error {
 	}
 
 	if user == nil {
+		if err := h.checkUserLimit(c.UserContext()); err != nil {
+			slog.Warn("[Auth] refusing to create user", "user", ghUser.Login, "error", err)
+			switch limits.Code(err) {
+			case limits.CodeLimitExceeded:
+				return h.oauthErrorRedirect(c, "user_limit_reached", "")
+			case limits.CodeSourceUnavailable:
+				return h.oauthErrorRedirect(c, "license_invalid", "")
+			}
+			return h.oauthErrorRedirect(c, "db_error", "")
+		}
 		role := models.UserRoleViewer
 		if bootstrapAdmin {
 			role = models.UserRoleAdmin
@@ -414,8 +434,14 @@ func (h *AuthHandler) GitHubCallback(c *fiber.Ctx) error {
 			"user", user.ID, "error", err)
 	}
 
+	sessionID, err := h.startSession(c, user)
+	if err != nil {
+		slog.Error("[Auth] failed to create session", "user", user.ID, "error", err)
+		return h.oauthErrorRedirect(c, "db_error", "")
+	}
+
 	// Generate JWT
-	jwtToken, err := h.generateJWT(user)
+	jwtToken, err := h.generateJWT(user, sessionID)
 	if err != nil {
 		slog.Error("[Auth] JWT generation failed", "error", err)
 		return h.oauthErrorRedirect(c, "jwt_failed", "")
@@ -496,6 +522,7 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		expiresAt = claims.ExpiresAt.Time
 	}
 	middleware.RevokeToken(claims.ID, expiresAt)
+	h.endSession(c, claims.SessionID)
 
 	// Clear the HttpOnly cookies so the browser stops sending them.
 	h.clearJWTCookie(c)
@@ -594,8 +621,9 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, "User not found")
 	}
 
-	// Generate new token
-	newToken, err := h.generateJWT(user)
+	// Generate new token for the same session, so refreshing neither resets
+	// the session's absolute lifetime nor survives its revocation.
+	newToken, err := h.generateJWT(user, claims.SessionID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to generate token")
 	}
@@ -615,3 +643,21 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		"onboarded": user.Onboarded,
 	})
 }
+
+// SetLimits makes first sign-ins fail once the user limit is reached.
+// Existing users can still sign in.
+func (h *AuthHandler) SetLimits(l *limits.Enforcer) {
+	h.limits = l
+}
+
+// checkUserLimit reports whether one more user fits within the user limit.
+func (h *AuthHandler) checkUserLimit(ctx context.Context) error {
+	if h.limits == nil {
+		return nil
+	}
+	admins, editors, viewers, err := h.store.CountUsersByRole(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count users: %w", err)
+	}
+	return h.limits.Check(limits.Users, admins+editors+viewers, 1)
+}
@@ -175,11 +175,13 @@ func (h *AuthHandler) clearClientAuthCookie(c *fiber.Ctx) {
 	})
 }
 
-func (h *AuthHandler) generateJWT(user *models.User) (string, error) {
+// generateJWT signs a token for user bound to sessionID (see startSession).
+func (h *AuthHandler) generateJWT(user *models.User, sessionID string) (string, error) {
 	claims := middleware.UserClaims{
 		UserID:      user.ID,
 		GitHubLogin: user.GitHubLogin,
 		Role:        user.Role,
+		SessionID:   sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(), // jti — unique token identifier for revocation
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(jwtExpiration)),
@@ -10,6 +10,9 @@ import (
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/limits"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/settings"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -240,3 +243,51 @@ func TestAuthOAuth_GitHubLoginAndCallback_SuccessFlow(t *testing.T) {
 
 	assert.False(t, h.validateAndConsumeOAuthState(context.Background(), state), "OAuth state should be single-use after callback")
 }
+
+type staticLimits settings.LimitsSettings
+
+func (l staticLimits) GetLimits() settings.LimitsSettings { return settings.LimitsSettings(l) }
+
+func TestAuthOAuth_GitHubCallback_UserLimitReached(t *testing.T) {
+	app := fiber.New()
+	h, s := newRealStoreAuthHandler(t)
+	require.NoError(t, s.CreateUser(context.Background(), &models.User{GitHubID: "1", GitHubLogin: "first", Role: models.UserRoleAdmin}))
+	h.SetLimits(limits.NewEnforcer(limits.NewSettingsSource(staticLimits{MaxUsers: 1})))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "gh-access-token", "token_type": "bearer"})
+		case "/user":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 4242, "login": "octocat", "email": "octocat@example.com"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	h.oauthConfig.Endpoint.TokenURL = server.URL + "/login/oauth/access_token"
+	h.githubAPIBase = server.URL
+	h.githubHTTPClient = server.Client()
+	app.Get("/auth/github", h.GitHubLogin)
+	app.Get("/auth/github/callback", h.GitHubCallback)
+
+	loginResp, err := app.Test(httptest.NewRequest(http.MethodGet, "/auth/github", nil), 5000)
+	require.NoError(t, err)
+	loginLocation, err := loginResp.Location()
+	require.NoError(t, err)
+	state := loginLocation.Query().Get("state")
+	require.NotEmpty(t, state)
+
+	callbackResp, err := app.Test(httptest.NewRequest(http.MethodGet, "/auth/github/callback?code=test-code&state="+url.QueryEscape(state), nil), 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusTemporaryRedirect, callbackResp.StatusCode)
+	callbackLocation, err := callbackResp.Location()
+	require.NoError(t, err)
+	assert.Equal(t, "/login", callbackLocation.Path)
+	assert.Equal(t, "user_limit_reached", callbackLocation.Query().Get("error"))
+
+	user, err := s.GetUserByGitHubID(context.Background(), "4242")
+	require.NoError(t, err)
+	assert.Nil(t, user, "no user is created over the limit")
+}
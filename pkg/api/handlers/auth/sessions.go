@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/models"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSessionUserAgentLen caps the stored User-Agent so a client cannot
+	// bloat the sessions table with an oversized header.
+	maxSessionUserAgentLen = 512
+
+	// Reasons carried in the session_revoked WebSocket message.
+	sessionRevokedBySelf  = "signed_out"
+	sessionRevokedByAdmin = "revoked_by_admin"
+)
+
+// sessionResponse is a session as listed by the API. Current marks the
+// session of the request that asked.
+type sessionResponse struct {
+	models.UserSession
+	Current bool `json:"current"`
+}
+
+// startSession records a new login session for user on the requesting
+// device and purges stale sessions. It returns the session ID for the
+// token's sid claim.
+func (h *AuthHandler) startSession(c *fiber.Ctx, user *models.User) (string, error) {
+	policy := middleware.CurrentSessionPolicy()
+	now := time.Now()
+	ua := c.Get(fiber.HeaderUserAgent)
+	if len(ua) > maxSessionUserAgentLen {
+		ua = ua[:maxSessionUserAgentLen]
+	}
+	session := &models.UserSession{
+		UserID:    user.ID,
+		Device:    deviceLabel(ua),
+		UserAgent: ua,
+		IP:        c.IP(),
+		ExpiresAt: now.Add(policy.MaxLifetime),
+	}
+	if err := h.store.CreateSession(c.UserContext(), session); err != nil {
+		return "", err
+	}
+
+	var idleCutoff time.Time
+	if policy.IdleTimeout > 0 {
+		idleCutoff = now.Add(-policy.IdleTimeout)
+	}
+	if _, err := h.store.DeleteExpiredSessions(c.UserContext(), now, idleCutoff); err != nil {
+		slog.Warn("[Auth] failed to purge expired sessions", "error", err)
+	}
+	return session.ID.String(), nil
+}
+
+// endSession revokes the session named by a token's sid claim, if any.
+func (h *AuthHandler) endSession(c *fiber.Ctx, sessionID string) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return
+	}
+	if _, err := h.store.RevokeSession(c.UserContext(), id, time.Now()); err != nil {
+		slog.Warn("[Auth] failed to revoke session on logout", "session", id, "error", err)
+	}
+	middleware.ForgetSession(id)
+}
+
+// disconnectSessions drops revoked sessions from the lookup cache and signs
+// their WebSocket connections out with reason.
+func (h *AuthHandler) disconnectSessions(userID uuid.UUID, ids []uuid.UUID, reason string) {
+	for _, id := range ids {
+		middleware.ForgetSession(id)
+		if h.wsHub != nil {
+			h.wsHub.DisconnectSession(userID, id.String(), reason)
+		}
+	}
+}
+
+// listActiveSessions returns userID's sessions that are still usable, with
+// current marked.
+func (h *AuthHandler) listActiveSessions(c *fiber.Ctx, userID, current uuid.UUID) ([]sessionResponse, error) {
+	sessions, err := h.store.ListUserSessions(c.UserContext(), userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	policy := middleware.CurrentSessionPolicy()
+	now := time.Now()
+	out := make([]sessionResponse, 0, len(sessions))
+	for i := range sessions {
+		if !middleware.SessionActive(&sessions[i], policy, now) {
+			continue
+		}
+		out = append(out, sessionResponse{UserSession: sessions[i], Current: sessions[i].ID == current})
+	}
+	return out, nil
+}
+
+// ListSessions returns the caller's active sessions (GET /api/sessions).
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Not authenticated")
+	}
+	sessions, err := h.listActiveSessions(c, userID, middleware.GetSessionID(c))
+	if err != nil {
+		slog.Error("[Auth] failed to list sessions", "user", userID, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list sessions")
+	}
+	return c.JSON(fiber.Map{"sessions": sessions})
+}
+
+// RevokeSession signs one session out (DELETE /api/sessions/:id). Users may
+// revoke their own sessions; admins may revoke anyone's, and the affected
+// sockets are told they were signed out by an admin.
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Not authenticated")
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid session ID")
+	}
+	session, err := h.store.GetSession(c.UserContext(), id)
+	if err != nil {
+		slog.Error("[Auth] failed to load session", "session", id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to revoke session")
+	}
+	if session == nil {
+		return fiber.NewError(fiber.StatusNotFound, "Session not found")
+	}
+
+	reason := sessionRevokedBySelf
+	if session.UserID != userID {
+		// Report someone else's session as missing to non-admins so session
+		// IDs cannot be probed.
+		if err := requireAdmin(c, h.store); err != nil {
+			return fiber.NewError(fiber.StatusNotFound, "Session not found")
+		}
+		reason = sessionRevokedByAdmin
+	}
+
+	if _, err := h.store.RevokeSession(c.UserContext(), id, time.Now()); err != nil {
+		slog.Error("[Auth] failed to revoke session", "session", id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to revoke session")
+	}
+	h.disconnectSessions(session.UserID, []uuid.UUID{id}, reason)
+	if id == middleware.GetSessionID(c) {
+		h.clearJWTCookie(c)
+		h.clearClientAuthCookie(c)
+	}
+
+	audit.Log(c, audit.ActionRevokeSession, "session", id.String(), "user="+session.UserID.String())
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// RevokeOtherSessions signs out every session of the caller except the
+// current one (DELETE /api/sessions).
+func (h *AuthHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "Not authenticated")
+	}
+	ids, err := h.store.RevokeUserSessions(c.UserContext(), userID, middleware.GetSessionID(c), time.Now())
+	if err != nil {
+		slog.Error("[Auth] failed to revoke sessions", "user", userID, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to revoke sessions")
+	}
+	h.disconnectSessions(userID, ids, sessionRevokedBySelf)
+
+	audit.Log(c, audit.ActionRevokeSession, "user", userID.String(), "other_sessions")
+	return c.JSON(fiber.Map{"success": true, "revoked": len(ids)})
+}
+
+// ListUserSessions returns another user's active sessions
+// (GET /api/users/:id/sessions, admin only).
+func (h *AuthHandler) ListUserSessions(c *fiber.Ctx) error {
+	if err := requireAdmin(c, h.store); err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+	sessions, err := h.listActiveSessions(c, userID, middleware.GetSessionID(c))
+	if err != nil {
+		slog.Error("[Auth] failed to list sessions", "user", userID, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list sessions")
+	}
+	return c.JSON(fiber.Map{"sessions": sessions})
+}
+
+// RevokeUserSessions signs a user out everywhere
+// (DELETE /api/users/:id/sessions, admin only).
+func (h *AuthHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	if err := requireAdmin(c, h.store); err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid user ID")
+	}
+	ids, err := h.store.RevokeUserSessions(c.UserContext(), userID, uuid.Nil, time.Now())
+	if err != nil {
+		slog.Error("[Auth] failed to revoke sessions", "user", userID, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to revoke sessions")
+	}
+	h.disconnectSessions(userID, ids, sessionRevokedByAdmin)
+
+	audit.Log(c, audit.ActionRevokeSession, "user", userID.String(), "all_sessions")
+	return c.JSON(fiber.Map{"success": true, "revoked": len(ids)})
+}
+
+// deviceLabel turns a User-Agent into a short "Browser on OS" label for the
+// session list. Unknown agents get "Unknown device".
+func deviceLabel(ua string) string {
+	browsers := []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	systems := []struct{ token, name string }{
+		{"Windows", "Windows"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(ua, s.token) {
+			system = s.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
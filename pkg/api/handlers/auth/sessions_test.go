@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type disconnectCall struct {
+	userID    uuid.UUID
+	sessionID string
+	reason    string
+}
+
+type recordingHub struct {
+	mu       sync.Mutex
+	sessions []disconnectCall
+}
+
+func (h *recordingHub) DisconnectUser(uuid.UUID) {}
+
+func (h *recordingHub) DisconnectSession(userID uuid.UUID, sessionID, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions = append(h.sessions, disconnectCall{userID, sessionID, reason})
+}
+
+type sessionsTestEnv struct {
+	t     *testing.T
+	app   *fiber.App
+	store *store.SQLiteStore
+	hub   *recordingHub
+	h     *AuthHandler
+}
+
+func newSessionsTestEnv(t *testing.T) *sessionsTestEnv {
+	t.Helper()
+	s := store.OpenTestDB(t)
+	middleware.InitSessionTracking(s, middleware.SessionPolicy{IdleTimeout: time.Hour, MaxLifetime: 24 * time.Hour})
+	t.Cleanup(func() {
+		middleware.InitSessionTracking(nil, middleware.SessionPolicy{
+			IdleTimeout: middleware.DefaultSessionIdleTimeout,
+			MaxLifetime: middleware.DefaultSessionMaxLifetime,
+		})
+	})
+
+	h := NewAuthHandler(s, AuthConfig{JWTSecret: "test-secret", FrontendURL: "http://localhost:5174", DevMode: true})
+	t.Cleanup(h.Stop)
+	hub := &recordingHub{}
+	h.SetHub(hub)
+
+	app := fiber.New()
+	jwtAuth := middleware.JWTAuth("test-secret")
+	app.Post("/auth/refresh", h.RefreshToken)
+	app.Get("/api/sessions", jwtAuth, h.ListSessions)
+	app.Delete("/api/sessions", jwtAuth, h.RevokeOtherSessions)
+	app.Delete("/api/sessions/:id", jwtAuth, h.RevokeSession)
+	app.Get("/api/users/:id/sessions", jwtAuth, h.ListUserSessions)
+	return &sessionsTestEnv{t: t, app: app, store: s, hub: hub, h: h}
+}
+
+// login signs user in on a new device and returns the session's JWT.
+func (e *sessionsTestEnv) login(user *models.User, userAgent string) string {
+	e.t.Helper()
+	app := fiber.New()
+	var token string
+	app.Get("/login", func(c *fiber.Ctx) error {
+		sessionID, err := e.h.startSession(c, user)
+		if err != nil {
+			return err
+		}
+		token, err = e.h.generateJWT(user, sessionID)
+		return err
+	})
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := app.Test(req, 5000)
+	require.NoError(e.t, err)
+	require.Equal(e.t, fiber.StatusOK, resp.StatusCode)
+	return token
+}
+
+func (e *sessionsTestEnv) do(method, path, token string) (*http.Response, map[string]any) {
+	e.t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := e.app.Test(req, 5000)
+	require.NoError(e.t, err)
+	var body map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return resp, body
+}
+
+func (e *sessionsTestEnv) createUser(login string, role models.UserRole) *models.User {
+	e.t.Helper()
+	user := &models.User{GitHubID: "gh-" + login, GitHubLogin: login, Role: role}
+	require.NoError(e.t, e.store.CreateUser(context.Background(), user))
+	return user
+}
+
+func sessionIDs(t *testing.T, body map[string]any) (ids []string, current string) {
+	t.Helper()
+	list, ok := body["sessions"].([]any)
+	require.True(t, ok, "sessions array in %v", body)
+	for _, item := range list {
+		s := item.(map[string]any)
+		ids = append(ids, s["id"].(string))
+		if s["current"] == true {
+			current = s["id"].(string)
+		}
+	}
+	return ids, current
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	env := newSessionsTestEnv(t)
+	user := env.createUser("alice", models.UserRoleViewer)
+
+	laptop := env.login(user, "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15")
+	phone := env.login(user, "Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 Chrome/124.0 Mobile Safari/537.36")
+
+	resp, body := env.do(http.MethodGet, "/api/sessions", laptop)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	ids, current := sessionIDs(t, body)
+	require.Len(t, ids, 2)
+	laptopClaims, err := middleware.ValidateJWT(laptop, "test-secret")
+	require.NoError(t, err)
+	assert.Equal(t, laptopClaims.SessionID, current)
+	devices := []string{body["sessions"].([]any)[0].(map[string]any)["device"].(string), body["sessions"].([]any)[1].(map[string]any)["device"].(string)}
+	assert.ElementsMatch(t, []string{"Safari on macOS", "Chrome on Android"}, devices)
+
+	// Signing out the other devices leaves only the laptop, and the phone's
+	// sockets get a session_revoked notice.
+	resp, body = env.do(http.MethodDelete, "/api/sessions", laptop)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 1, body["revoked"])
+	phoneClaims, err := middleware.ParseJWT(phone, "test-secret")
+	require.NoError(t, err)
+	phoneSID := phoneClaims.Claims.(*middleware.UserClaims).SessionID
+	assert.Equal(t, []disconnectCall{{user.ID, phoneSID, sessionRevokedBySelf}}, env.hub.sessions)
+
+	resp, _ = env.do(http.MethodGet, "/api/sessions", phone)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, "revoked sessions are rejected immediately")
+
+	// Revoking the current session signs the caller out.
+	resp, _ = env.do(http.MethodDelete, "/api/sessions/"+current, laptop)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp, _ = env.do(http.MethodGet, "/api/sessions", laptop)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestSessions_RevokeOtherUsersSession(t *testing.T) {
+	env := newSessionsTestEnv(t)
+	alice := env.createUser("alice", models.UserRoleViewer)
+	bob := env.createUser("bob", models.UserRoleViewer)
+	admin := env.createUser("root", models.UserRoleAdmin)
+
+	aliceToken := env.login(alice, "curl/8.0")
+	bobToken := env.login(bob, "curl/8.0")
+	adminToken := env.login(admin, "curl/8.0")
+	claims, err := middleware.ValidateJWT(aliceToken, "test-secret")
+	require.NoError(t, err)
+
+	resp, _ := env.do(http.MethodDelete, "/api/sessions/"+claims.SessionID, bobToken)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, "other users' sessions look missing to non-admins")
+	resp, _ = env.do(http.MethodGet, "/api/users/"+alice.ID.String()+"/sessions", bobToken)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+
+	resp, body := env.do(http.MethodGet, "/api/users/"+alice.ID.String()+"/sessions", adminToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	ids, _ := sessionIDs(t, body)
+	assert.Equal(t, []string{claims.SessionID}, ids)
+
+	resp, _ = env.do(http.MethodDelete, "/api/sessions/"+claims.SessionID, adminToken)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, []disconnectCall{{alice.ID, claims.SessionID, sessionRevokedByAdmin}}, env.hub.sessions)
+	_, err = middleware.ValidateJWT(aliceToken, "test-secret")
+	assert.ErrorIs(t, err, middleware.ErrSessionRevoked)
+}
+
+func TestSessions_RefreshKeepsSession(t *testing.T) {
+	env := newSessionsTestEnv(t)
+	user := env.createUser("alice", models.UserRoleViewer)
+	token := env.login(user, "curl/8.0")
+	claims, err := middleware.ValidateJWT(token, "test-secret")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := env.app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var refreshed string
+	for _, c := range resp.Cookies() {
+		if c.Name == jwtCookieName {
+			refreshed = c.Value
+		}
+	}
+	require.NotEmpty(t, refreshed)
+	newClaims, err := middleware.ValidateJWT(refreshed, "test-secret")
+	require.NoError(t, err)
+	assert.Equal(t, claims.SessionID, newClaims.SessionID)
+
+	// Revoking the session also revokes the refreshed token.
+	id := uuid.MustParse(claims.SessionID)
+	_, err = env.store.RevokeSession(context.Background(), id, time.Now())
+	require.NoError(t, err)
+	middleware.ForgetSession(id)
+	_, err = middleware.ValidateJWT(refreshed, "test-secret")
+	assert.ErrorIs(t, err, middleware.ErrSessionRevoked)
+}
+
+func TestDeviceLabel(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/124.0 Safari/537.36 Edg/124.0": "Edge on Windows",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0":                            "Firefox on Linux",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 Safari/604.1":          "Safari on iOS",
+		"curl/8.4.0": "curl",
+		"":           "Unknown device",
+	}
+	for ua, want := range cases {
+		assert.Equal(t, want, deviceLabel(ua), ua)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// maxAuthzPreflightChecks caps the tuples in one preflight request. A page
+// asks about the buttons it renders, which is a few dozen at most.
+const maxAuthzPreflightChecks = 100
+
+// authzPreflightSubjectSelf is reported when reviews ran as the backend's
+// own kubeconfig identity, i.e. the user's kubeconfig in local installs.
+const authzPreflightSubjectSelf = "self"
+
+// accessReviewer is the subset of k8s.MultiClusterClient the preflight needs.
+type accessReviewer interface {
+	IsInCluster() bool
+	CheckAccessBatch(ctx context.Context, contextName string, subject *k8s.AccessSubject, checks []models.AuthzCheck) ([]models.AuthzCheckResult, error)
+}
+
+// AuthzPreflightHandler answers "can I?" for a batch of UI actions so the
+// frontend can disable buttons the user cannot actually use. It is a hint:
+// the cluster still enforces RBAC when the action is performed.
+type AuthzPreflightHandler struct {
+	k8sClient accessReviewer
+}
+
+// NewAuthzPreflightHandler creates a permission preflight handler.
+func NewAuthzPreflightHandler(k8sClient *k8s.MultiClusterClient) *AuthzPreflightHandler {
+	h := &AuthzPreflightHandler{}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// authzPreflightRequest is the body accepted by the preflight endpoint.
+type authzPreflightRequest struct {
+	Checks []models.AuthzCheck `json:"checks"`
+}
+
+// AuthzPreflightResponse is the response of POST
+// /api/clusters/:cluster/authz/preflight. Results are in request order.
+type AuthzPreflightResponse struct {
+	Cluster string `json:"cluster"`
+	// Subject is "self" when the reviews ran as the backend's kubeconfig
+	// identity, otherwise the Kubernetes user they were evaluated for.
+	Subject string                    `json:"subject"`
+	Results []models.AuthzCheckResult `json:"results"`
+}
+
+// Preflight evaluates each verb/resource/namespace tuple with an access
+// review and returns allow/deny per tuple. When the backend runs in-cluster
+// its own identity is the pod ServiceAccount, so the reviews are
+// SubjectAccessReviews for the console user (identified to the apiserver by
+// their GitHub login) rather than SelfSubjectAccessReviews.
+// POST /api/clusters/:cluster/authz/preflight
+func (h *AuthzPreflightHandler) Preflight(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var req authzPreflightRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Checks) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "checks is required")
+	}
+	if len(req.Checks) > maxAuthzPreflightChecks {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("at most %d checks per request", maxAuthzPreflightChecks))
+	}
+	for i, check := range req.Checks {
+		if err := validateAuthzCheck(check); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("checks[%d]: %s", i, err))
+		}
+	}
+
+	var subject *k8s.AccessSubject
+	subjectName := authzPreflightSubjectSelf
+	if h.k8sClient.IsInCluster() {
+		login := middleware.GetGitHubLogin(c)
+		if login == "" {
+			// Fail closed: never fall back to the ServiceAccount's permissions.
+			return fiber.NewError(fiber.StatusForbidden, "user identity required for access review")
+		}
+		subject = &k8s.AccessSubject{User: login}
+		subjectName = login
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), k8s.RBACDefaultTimeout)
+	defer cancel()
+	results, err := h.k8sClient.CheckAccessBatch(ctx, cluster, subject, req.Checks)
+	if err != nil {
+		return handleK8sError(c, err)
+	}
+	for i := range results {
+		if results[i].Error != "" {
+			slog.Info("[AuthzPreflight] access review failed", "cluster", cluster, "verb", results[i].Verb, "resource", results[i].Resource, "error", results[i].Error)
+			results[i].Error = "access review failed"
+		}
+	}
+
+	return c.JSON(AuthzPreflightResponse{Cluster: cluster, Subject: subjectName, Results: results})
+}
+
+// validateAuthzCheck rejects tuples the apiserver could not evaluate.
+func validateAuthzCheck(check models.AuthzCheck) error {
+	if check.Verb == "" {
+		return fmt.Errorf("verb is required")
+	}
+	if check.Resource == "" {
+		return fmt.Errorf("resource is required")
+	}
+	if check.Namespace != "" {
+		if err := validateDNSLabel("namespace", check.Namespace); err != nil {
+			return err
+		}
+	}
+	return validateK8sName("name", check.Name)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+type fakeAccessReviewer struct {
+	inCluster bool
+	subject   *k8s.AccessSubject
+	err       error
+	// deny lists the verbs the fake reports as denied; "fail" errors.
+	deny map[string]bool
+}
+
+func (f *fakeAccessReviewer) IsInCluster() bool { return f.inCluster }
+
+func (f *fakeAccessReviewer) CheckAccessBatch(_ context.Context, _ string, subject *k8s.AccessSubject, checks []models.AuthzCheck) ([]models.AuthzCheckResult, error) {
+	f.subject = subject
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([]models.AuthzCheckResult, len(checks))
+	for i, check := range checks {
+		out[i] = models.AuthzCheckResult{AuthzCheck: check, Allowed: !f.deny[check.Verb]}
+		if check.Verb == "fail" {
+			out[i].Allowed = false
+			out[i].Error = "subjectaccessreviews.authorization.k8s.io is forbidden: internal detail"
+		}
+	}
+	return out, nil
+}
+
+func postPreflight(t *testing.T, h *AuthzPreflightHandler, login, body string) (int, AuthzPreflightResponse) {
+	t.Helper()
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if login != "" {
+			c.Locals("githubLogin", login)
+		}
+		return c.Next()
+	})
+	app.Post("/api/clusters/:cluster/authz/preflight", h.Preflight)
+	req := httptest.NewRequest(http.MethodPost, "/api/clusters/prod/authz/preflight", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	var out AuthzPreflightResponse
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out
+}
+
+func TestAuthzPreflight_Results(t *testing.T) {
+	reviewer := &fakeAccessReviewer{deny: map[string]bool{"delete": true}}
+	h := &AuthzPreflightHandler{k8sClient: reviewer}
+
+	status, out := postPreflight(t, h, "octocat", `{"checks":[
+		{"verb":"get","resource":"pods","namespace":"shop"},
+		{"verb":"delete","resource":"deployments","group":"apps","namespace":"shop","name":"web"},
+		{"verb":"fail","resource":"secrets"}
+	]}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Nil(t, reviewer.subject, "local installs review the kubeconfig identity itself")
+	assert.Equal(t, "prod", out.Cluster)
+	assert.Equal(t, authzPreflightSubjectSelf, out.Subject)
+	require.Len(t, out.Results, 3)
+	assert.True(t, out.Results[0].Allowed)
+	assert.False(t, out.Results[1].Allowed)
+	assert.Equal(t, "web", out.Results[1].Name)
+	assert.False(t, out.Results[2].Allowed)
+	assert.Equal(t, "access review failed", out.Results[2].Error, "apiserver errors are not echoed to the client")
+}
+
+func TestAuthzPreflight_InClusterReviewsConsoleUser(t *testing.T) {
+	reviewer := &fakeAccessReviewer{inCluster: true}
+	h := &AuthzPreflightHandler{k8sClient: reviewer}
+
+	status, out := postPreflight(t, h, "octocat", `{"checks":[{"verb":"get","resource":"pods"}]}`)
+	require.Equal(t, http.StatusOK, status)
+	require.NotNil(t, reviewer.subject)
+	assert.Equal(t, "octocat", reviewer.subject.User)
+	assert.Equal(t, "octocat", out.Subject)
+
+	status, _ = postPreflight(t, h, "", `{"checks":[{"verb":"get","resource":"pods"}]}`)
+	assert.Equal(t, http.StatusForbidden, status, "never falls back to the ServiceAccount's permissions")
+}
+
+func TestAuthzPreflight_Errors(t *testing.T) {
+	tooMany := `{"checks":[` + strings.Repeat(`{"verb":"get","resource":"pods"},`, maxAuthzPreflightChecks) + `{"verb":"get","resource":"pods"}]}`
+	ok := &AuthzPreflightHandler{k8sClient: &fakeAccessReviewer{}}
+	cases := []struct {
+		name   string
+		h      *AuthzPreflightHandler
+		body   string
+		status int
+	}{
+		{"no cluster access", NewAuthzPreflightHandler(nil), `{"checks":[{"verb":"get","resource":"pods"}]}`, http.StatusServiceUnavailable},
+		{"bad body", ok, `not json`, http.StatusBadRequest},
+		{"no checks", ok, `{"checks":[]}`, http.StatusBadRequest},
+		{"too many checks", ok, tooMany, http.StatusBadRequest},
+		{"missing verb", ok, `{"checks":[{"resource":"pods"}]}`, http.StatusBadRequest},
+		{"missing resource", ok, `{"checks":[{"verb":"get"}]}`, http.StatusBadRequest},
+		{"bad namespace", ok, `{"checks":[{"verb":"get","resource":"pods","namespace":"Not_Valid"}]}`, http.StatusBadRequest},
+		{"unknown cluster", &AuthzPreflightHandler{k8sClient: &fakeAccessReviewer{
+			err: errors.New(`context "prod" not found`),
+		}}, `{"checks":[{"verb":"get","resource":"pods"}]}`, http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		status, _ := postPreflight(t, tc.h, "octocat", tc.body)
+		assert.Equal(t, tc.status, status, tc.name)
+	}
+}
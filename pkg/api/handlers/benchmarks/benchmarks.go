@@ -3,8 +3,6 @@ package benchmarks
 import (
 	"bufio"
 	"context"
-	"encoding/json"
-	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -13,9 +11,14 @@ import (
 	"time"
 
 	"github.com/kubestellar/console/pkg/client"
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/operations"
 	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/store"
 
 	"github.com/gofiber/fiber/v2"
+	"golang.org/x/oauth2"
 )
 
 // isDemoMode checks if the request has the X-Demo-Mode header set to "true"
@@ -44,10 +47,11 @@ const (
 	driveRetryBaseDelay = 2 * time.Second
 	driveUserAgent      = "KubeStellarConsole/1.0"
 
-	// driveFetchConcurrency bounds how many experiment/run folders are
-	// processed in parallel. The throttle() mutex still enforces per-request
-	// rate-limiting, so increasing this number speeds up folder listing and
-	// file downloads without exceeding the Drive API rate limit.
+	// driveFetchConcurrency is the default for how many experiment/run
+	// folders are processed in parallel; BENCHMARK_FETCH_CONCURRENCY
+	// overrides it. throttle() still rate-limits each host, so increasing
+	// this number speeds up folder listing and file downloads without
+	// exceeding the Drive API rate limit.
 	driveFetchConcurrency = 8
 )
 
@@ -91,7 +95,7 @@ func parseDriveTime(s string) (time.Time, bool) {
 
 // isAfterCutoff returns true if the file should be included (created after cutoff).
 // If cutoff is zero or the file has no created time, always includes it.
-func isAfterCutoff(f driveFile, cutoff time.Time) bool {
+func isAfterCutoff(f SourceEntry, cutoff time.Time) bool {
 	if cutoff.IsZero() {
 		return true
 	}
@@ -102,14 +106,40 @@ func isAfterCutoff(f driveFile, cutoff time.Time) bool {
 	return !created.Before(cutoff)
 }
 
-// BenchmarkHandlers provides endpoints for llm-d benchmark data from Google Drive.
+// BenchmarkHandlers provides endpoints for llm-d benchmark data from Google
+// Drive or another BenchmarkSource.
 type BenchmarkHandlers struct {
 	apiKey   string
 	folderID string
 	cache    *benchmarkCache
 	client   *http.Client
-	lastReq  time.Time
-	reqMu    sync.Mutex
+	// lastReq is when throttle() last let a request through, per host.
+	lastReq map[string]time.Time
+	reqMu   sync.Mutex
+	// fetchConcurrency bounds the fetch worker pools; see benchmarks_fetch.go.
+	fetchConcurrency int
+	// annotations stores stars, notes and labels; nil disables them. See
+	// benchmarks_annotations.go.
+	annotations store.BenchmarkAnnotationStore
+	// operations runs refreshes; see benchmarks_refresh.go.
+	operations *operations.Manager
+	// source, when set, replaces Google Drive; see benchmarks_source.go.
+	source BenchmarkSource
+	// alerts checks every fetch for regressions; nil disables them. See
+	// benchmarks_alerts.go.
+	alerts *regressionAlerter
+	// ingest authenticates POST /api/benchmarks/webhook; nil disables it.
+	// See benchmarks_ingest.go.
+	ingest *ingestWebhook
+	// driveToken authenticates Google Drive requests as a service account;
+	// nil uses apiKey. See benchmarks_drive_auth.go.
+	driveToken oauth2.TokenSource
+	// accelerators fills in accelerator specs of parsed reports. See
+	// benchmarks_accelerators.go.
+	accelerators *acceleratorCatalog
+	// goodputSLOs are the latency targets goodput is derived for. See
+	// benchmarks_efficiency.go.
+	goodputSLOs []GoodputSLO
 }
 
 type benchmarkCache struct {
@@ -118,6 +148,13 @@ type benchmarkCache struct {
 	since     string
 	fetchedAt time.Time
 	ttl       time.Duration
+	// retention bounds what set keeps; see benchmarks_retention.go.
+	retention    RetentionPolicy
+	prunedTotal  int
+	lastPrunedAt time.Time
+	// index is the search index over reports, rebuilt whenever reports
+	// changes; see benchmarks_search.go.
+	index *searchIndex
 }
 
 func (c *benchmarkCache) get(since string) ([]BenchmarkReport, bool) {
@@ -129,12 +166,49 @@ func (c *benchmarkCache) get(since string) ([]BenchmarkReport, bool) {
 	return c.reports, true
 }
 
-func (c *benchmarkCache) set(reports []BenchmarkReport, since string) {
+// set caches reports after applying the retention policy and returns the
+// reports it kept.
+func (c *benchmarkCache) set(reports []BenchmarkReport, since string) []BenchmarkReport {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.reports = reports
+	now := time.Now()
+	kept, pruned := c.retention.apply(reports, now)
+	if pruned > 0 {
+		slog.Info("[benchmarks] retention dropped reports", "pruned", pruned, "kept", len(kept))
+		c.prunedTotal += pruned
+	}
+	c.reports = kept
+	c.index = buildSearchIndex(kept)
 	c.since = since
-	c.fetchedAt = time.Now()
+	c.fetchedAt = now
+	return kept
+}
+
+// merge adds reports to the cache, replacing cached reports with the same
+// run UID, and returns the reports it kept. Unlike set it leaves fetchedAt
+// alone, so a merge does not postpone the next full fetch.
+func (c *benchmarkCache) merge(reports []BenchmarkReport) []BenchmarkReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	replaced := make(map[string]bool, len(reports))
+	for _, r := range reports {
+		replaced[r.Run.UID] = true
+	}
+	merged := make([]BenchmarkReport, 0, len(c.reports)+len(reports))
+	for _, r := range c.reports {
+		if !replaced[r.Run.UID] {
+			merged = append(merged, r)
+		}
+	}
+	merged = append(merged, reports...)
+	kept, pruned := c.retention.apply(merged, time.Now())
+	if pruned > 0 {
+		slog.Info("[benchmarks] retention dropped reports", "pruned", pruned, "kept", len(kept))
+		c.prunedTotal += pruned
+	}
+	c.reports = kept
+	c.index = buildSearchIndex(kept)
+	return kept
 }
 
 // NewBenchmarkHandlers creates a new benchmark data handler.
@@ -143,28 +217,54 @@ func NewBenchmarkHandlers(apiKey, folderID string) *BenchmarkHandlers {
 		apiKey:   apiKey,
 		folderID: folderID,
 		cache: &benchmarkCache{
-			ttl: defaultCacheTTL,
+			ttl:       defaultCacheTTL,
+			retention: retentionPolicyFromEnv(),
 		},
-		client: client.External,
+		client:           client.External,
+		fetchConcurrency: fetchConcurrencyFromEnv(),
+		accelerators:     acceleratorCatalogFromEnv(),
+		goodputSLOs:      goodputSLOsFromEnv(),
 	}
 }
 
-// GetReports returns benchmark reports adapted from Google Drive v0.1 data to v0.2 format.
+// SetHTTPClient replaces the client used to reach Google Drive. Fake mode
+// routes it to the test harness instead of the public API.
+func (h *BenchmarkHandlers) SetHTTPClient(c *http.Client) {
+	h.client = c
+}
+
+// driveUnavailable is the 503 for a request that needs benchmark data when
+// there is no source to read: neither BENCHMARK_SOURCE nor a Google Drive API
+// key is set, or air-gapped mode keeps the console off Google.
+func driveUnavailable(c *fiber.Ctx) error {
+	msg := "benchmark data not configured — set BENCHMARK_SOURCE, GOOGLE_DRIVE_API_KEY or GOOGLE_APPLICATION_CREDENTIALS"
+	if egress.AirGapped() {
+		msg = "benchmark data is unavailable in air-gapped mode — set BENCHMARK_SOURCE to an in-network source"
+	}
+	return c.Status(503).JSON(fiber.Map{"error": msg, "source": "unavailable"})
+}
+
+// GetReports returns benchmark reports adapted from v0.1 source data to v0.2
+// format, filtered by model, accelerator, tool, experiment, run and load
+// concurrency. group_by returns per-group TTFT and throughput summaries
+// instead of the reports. Responses carry an ETag; see sendConditional.
+// GET /api/benchmarks/reports?since=&model=&accelerator=&tool=&experiment=&run=&concurrency_min=&concurrency_max=&group_by=&starred=&label=
 func (h *BenchmarkHandlers) GetReports(c *fiber.Ctx) error {
+	q, err := parseReportQuery(c)
+	if err != nil {
+		return err
+	}
 	if isDemoMode(c) {
-		return c.JSON(fiber.Map{"reports": []interface{}{}, "source": "demo"})
+		return c.JSON(queryResponse([]BenchmarkReport{}, map[string]models.BenchmarkAnnotation{}, q, "demo"))
 	}
 
-	if h.apiKey == "" {
-		return c.Status(503).JSON(fiber.Map{
-			"error":  "benchmark data not configured — set GOOGLE_DRIVE_API_KEY",
-			"source": "unavailable",
-		})
+	if !h.configured() {
+		return driveUnavailable(c)
 	}
 
 	since := normalizeSinceKey(c.Query("since", "0"))
 	if reports, ok := h.cache.get(since); ok {
-		return c.JSON(fiber.Map{"reports": reports, "source": "cache"})
+		return sendConditional(c, h.reportsResponse(c, q, reports, "cache"))
 	}
 
 	var cutoff time.Time
@@ -174,51 +274,51 @@ func (h *BenchmarkHandlers) GetReports(c *fiber.Ctx) error {
 
 	reports, parseFailures, err := h.fetchAllReports(c.UserContext(), cutoff)
 	if err != nil {
-		slog.Error("[benchmarks] Google Drive fetch error", "error", err)
+		slog.Error("[benchmarks] fetch error", "source", h.reportSource().Name(), "error", err)
 		h.cache.mu.RLock()
 		stale := h.cache.reports
 		h.cache.mu.RUnlock()
 		if stale != nil {
-			return c.JSON(fiber.Map{"reports": stale, "source": "stale-cache", "error": "failed to refresh benchmark data"})
+			resp := h.reportsResponse(c, q, stale, "stale-cache")
+			resp["error"] = "failed to refresh benchmark data"
+			return sendConditional(c, resp)
 		}
 		return c.Status(502).JSON(fiber.Map{"error": "failed to fetch benchmark data"})
 	}
 
-	h.cache.set(reports, since)
-	slog.Info("[benchmarks] fetched reports from Google Drive", "count", len(reports), "since", since, "parseFailures", parseFailures)
-	resp := fiber.Map{"reports": reports, "source": "live"}
+	reports = h.cache.set(reports, since)
+	h.alerts.check(reports)
+	slog.Info("[benchmarks] fetched reports", "source", h.reportSource().Name(), "count", len(reports), "since", since, "parseFailures", parseFailures)
+	resp := h.reportsResponse(c, q, reports, "live")
 	if parseFailures > 0 {
 		resp["parse_failures"] = parseFailures
 	}
-	return c.JSON(resp)
+	return sendConditional(c, resp)
 }
 
-// StreamReports streams benchmark reports via SSE as they are fetched from Google Drive.
+// StreamReports streams benchmark reports via SSE as they are fetched from the source.
 // Sends individual reports as they are parsed for fast first paint.
 // Sends keepalive heartbeats every 5s so the connection doesn't drop during long fetches.
-// Events: "batch" (reports array), "progress" (status update), "done" (final summary), "error".
+// Events: "batch" (reports array), "progress" (status update), "annotations"
+// (run UID -> annotation, sent once before done), "done" (final summary), "error".
+// With Accept: application/x-ndjson (or ?format=ndjson) the same events are
+// written as NDJSON lines, one "report" line per report instead of batches.
 func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 	if isDemoMode(c) {
 		return c.JSON(fiber.Map{"reports": []interface{}{}, "source": "demo"})
 	}
-	if h.apiKey == "" {
-		return c.Status(503).JSON(fiber.Map{
-			"error":  "benchmark data not configured — set GOOGLE_DRIVE_API_KEY",
-			"source": "unavailable",
-		})
+	if !h.configured() {
+		return driveUnavailable(c)
 	}
 
 	since := normalizeSinceKey(c.Query("since", "0"))
+	stream := newReportStream(c)
 	if reports, ok := h.cache.get(since); ok {
-		c.Set("Content-Type", "text/event-stream")
-		c.Set("Cache-Control", "no-cache")
-		c.Set("Connection", "keep-alive")
-		batch, err := json.Marshal(reports)
-		if err != nil {
+		if err := stream.reports(reports); err != nil {
 			return fiber.NewError(fiber.StatusInternalServerError, "failed to marshal benchmark reports")
 		}
-		fmt.Fprintf(c, "event: batch\ndata: %s\n\n", batch)
-		fmt.Fprintf(c, "event: done\ndata: {\"total\":%d,\"source\":\"cache\"}\n\n", len(reports))
+		stream.annotations(h.loadAnnotations(c.UserContext()))
+		stream.event("done", fiber.Map{"total": len(reports), "source": "cache"})
 		return nil
 	}
 
@@ -227,12 +327,9 @@ func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 		cutoff = time.Now().Add(-d)
 	}
 
-	c.Set("Content-Type", "text/event-stream")
-	c.Set("Cache-Control", "no-cache")
-	c.Set("Connection", "keep-alive")
-
 	reqCtx := c.UserContext()
 	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		out := stream.to(w)
 		ctx, cancel := context.WithCancel(reqCtx)
 		defer cancel()
 
@@ -255,18 +352,16 @@ func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 			if len(pendingBatch) == 0 || ctx.Err() != nil {
 				return
 			}
-			batch, err := json.Marshal(pendingBatch)
-			if err != nil {
-				slog.Error("[benchmarks] failed to marshal batch", "error", err)
+			if err := out.reports(pendingBatch); err != nil {
+				slog.Error("[benchmarks] failed to write batch", "error", err)
 				return
 			}
-			fmt.Fprintf(w, "event: batch\ndata: %s\n\n", batch)
 			safeFlush()
 			slog.Info("[benchmarks] flushed batch", "batchSize", len(pendingBatch), "totalSent", totalSent)
 			pendingBatch = pendingBatch[:0]
 		}
 
-		fmt.Fprintf(w, "event: progress\ndata: {\"status\":\"connecting\",\"total\":0}\n\n")
+		out.event("progress", fiber.Map{"status": "connecting", "total": 0})
 		safeFlush()
 		if ctx.Err() != nil {
 			return
@@ -281,7 +376,7 @@ func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 				select {
 				case <-ticker.C:
 					streamMu.Lock()
-					fmt.Fprintf(w, ": keepalive\n\n")
+					out.keepalive()
 					safeFlush()
 					streamMu.Unlock()
 				case <-keepaliveDone:
@@ -293,21 +388,22 @@ func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 		})
 		defer close(keepaliveDone)
 
-		topLevel, err := h.listDriveFolder(ctx, h.folderID)
+		source := h.reportSource()
+		topLevel, err := source.List(ctx, "")
 		if err != nil {
 			if ctx.Err() != nil {
 				slog.Info("[benchmarks] stream cancelled during folder listing")
 				return
 			}
-			slog.Info("[benchmarks] error listing drive folder", "error", err)
-			fmt.Fprintf(w, "event: error\ndata: {\"error\":\"failed to fetch benchmark data\"}\n\n")
+			slog.Info("[benchmarks] error listing benchmark source", "source", source.Name(), "error", err)
+			out.event("error", fiber.Map{"error": "failed to fetch benchmark data"})
 			safeFlush()
 			return
 		}
 
-		experiments := make([]driveFile, 0, len(topLevel))
+		experiments := make([]SourceEntry, 0, len(topLevel))
 		for _, item := range topLevel {
-			if item.MimeType != driveFolderMIME {
+			if !item.Folder {
 				continue
 			}
 			if !isAfterCutoff(item, cutoff) {
@@ -320,103 +416,47 @@ func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 		if skippedFolders > 0 {
 			slog.Info("[benchmarks] skipped old experiment folders", "skipped", skippedFolders, "since", since)
 		}
-		fmt.Fprintf(w, "event: progress\ndata: {\"status\":\"fetching\",\"experiments\":%d,\"total\":0,\"skipped\":%d}\n\n", len(experiments), skippedFolders)
+		out.event("progress", fiber.Map{"status": "fetching", "experiments": len(experiments), "total": 0, "skipped": skippedFolders})
 		safeFlush()
 
-		var streamWg sync.WaitGroup
-		streamSem := make(chan struct{}, driveFetchConcurrency)
-		innerSem := make(chan struct{}, driveFetchConcurrency)
-
-		for _, item := range experiments {
-			if ctx.Err() != nil {
-				break
-			}
-			item := item
-			streamWg.Add(1)
-			select {
-			case streamSem <- struct{}{}:
-			case <-ctx.Done():
-				streamWg.Done()
-				continue
-			}
-			safego.Go(func() {
-				defer streamWg.Done()
-				defer func() { <-streamSem }()
-
-				if ctx.Err() != nil {
-					return
-				}
-				runFolders, listErr := h.listDriveFolder(ctx, item.ID)
-				if listErr != nil {
+		h.walkRunFolders(ctx, experiments, folderWalk{
+			cutoff: cutoff,
+			onSkip: func() {
+				streamMu.Lock()
+				skippedFolders++
+				streamMu.Unlock()
+			},
+			fetch: func(ctx context.Context, item, runItem SourceEntry) {
+				reports, failures, runErr := h.fetchRunFolderStreaming(ctx, runItem.ID, item.Name, runItem.Name, func(report BenchmarkReport) {
+					streamMu.Lock()
+					allReports = append(allReports, report)
+					totalSent++
+					pendingBatch = append(pendingBatch, report)
+					if len(pendingBatch) >= batchSize {
+						flushBatch()
+					}
+					streamMu.Unlock()
+				})
+				streamMu.Lock()
+				totalParseFailures += failures
+				streamMu.Unlock()
+				if runErr != nil {
 					if ctx.Err() == nil {
-						slog.Error("[benchmarks] error listing experiment", "experiment", item.Name, "error", listErr)
+						slog.Error("[benchmarks] error in experiment run", "experiment", item.Name, "run", runItem.Name, "error", runErr)
 					}
 					return
 				}
-
-				var innerWg sync.WaitGroup
-				for _, runItem := range runFolders {
-					if ctx.Err() != nil {
-						break
-					}
-					if runItem.MimeType != driveFolderMIME {
-						continue
-					}
-					if !isAfterCutoff(runItem, cutoff) {
-						streamMu.Lock()
-						skippedFolders++
-						streamMu.Unlock()
-						continue
-					}
-					runItem := runItem
-					innerWg.Add(1)
-					select {
-					case innerSem <- struct{}{}:
-					case <-ctx.Done():
-						innerWg.Done()
-						continue
-					}
-					safego.Go(func() {
-						defer innerWg.Done()
-						defer func() { <-innerSem }()
-
-						if ctx.Err() != nil {
-							return
-						}
-						reports, failures, runErr := h.fetchRunFolderStreaming(ctx, runItem.ID, item.Name, runItem.Name, func(report BenchmarkReport) {
-							streamMu.Lock()
-							allReports = append(allReports, report)
-							totalSent++
-							pendingBatch = append(pendingBatch, report)
-							if len(pendingBatch) >= batchSize {
-								flushBatch()
-							}
-							streamMu.Unlock()
-						})
-						streamMu.Lock()
-						totalParseFailures += failures
-						streamMu.Unlock()
-						if runErr != nil {
-							if ctx.Err() == nil {
-								slog.Error("[benchmarks] error in experiment run", "experiment", item.Name, "run", runItem.Name, "error", runErr)
-							}
-							return
-						}
-						streamMu.Lock()
-						if len(pendingBatch) > 0 {
-							flushBatch()
-						}
-						sentSnapshot := totalSent
-						streamMu.Unlock()
-						if len(reports) > 0 {
-							slog.Info("[benchmarks] streamed reports", "count", len(reports), "experiment", item.Name, "run", runItem.Name, "totalSent", sentSnapshot)
-						}
-					})
+				streamMu.Lock()
+				if len(pendingBatch) > 0 {
+					flushBatch()
 				}
-				innerWg.Wait()
-			})
-		}
-		streamWg.Wait()
+				sentSnapshot := totalSent
+				streamMu.Unlock()
+				if len(reports) > 0 {
+					slog.Info("[benchmarks] streamed reports", "count", len(reports), "experiment", item.Name, "run", runItem.Name, "totalSent", sentSnapshot)
+				}
+			},
+		})
 		flushBatch()
 
 		if ctx.Err() != nil {
@@ -424,9 +464,10 @@ func (h *BenchmarkHandlers) StreamReports(c *fiber.Ctx) error {
 			return
 		}
 
-		h.cache.set(allReports, since)
+		h.alerts.check(h.cache.set(allReports, since))
+		out.annotations(h.loadAnnotations(ctx))
 		slog.Info("[benchmarks] stream complete", "totalSent", totalSent, "skipped", skippedFolders, "parseFailures", totalParseFailures, "since", since)
-		fmt.Fprintf(w, "event: done\ndata: {\"total\":%d,\"source\":\"live\",\"parse_failures\":%d}\n\n", totalSent, totalParseFailures)
+		out.event("done", fiber.Map{"total": totalSent, "source": "live", "parse_failures": totalParseFailures})
 		safeFlush()
 	})
 
@@ -0,0 +1,227 @@
+package benchmarks
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envAcceleratorCatalog names a YAML or JSON file of AcceleratorSpecs that
+// extends and overrides the built-in catalog.
+const envAcceleratorCatalog = "BENCHMARK_ACCELERATOR_CATALOG"
+
+// secondsPerHour converts a token rate per second to tokens per hour of
+// accelerator cost.
+const secondsPerHour = 3600
+
+// AcceleratorSpec describes one accelerator model. Reports name
+// accelerators in many ways ("H100", "NVIDIA-H100-80GB-HBM3"), so a spec
+// matches every name that contains all the words of its model or of one of
+// its aliases. A zero field is unknown.
+type AcceleratorSpec struct {
+	Model   string   `json:"model" yaml:"model"`
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	// MemoryGB is the memory of one accelerator.
+	MemoryGB int `json:"memory_gb,omitempty" yaml:"memory_gb,omitempty"`
+	// TDPWatts is the thermal design power of one accelerator.
+	TDPWatts int `json:"tdp_watts,omitempty" yaml:"tdp_watts,omitempty"`
+	// CostPerHour is what one accelerator costs per hour, in the currency
+	// the operator prices in.
+	CostPerHour float64 `json:"cost_per_hour,omitempty" yaml:"cost_per_hour,omitempty"`
+}
+
+// builtinAccelerators are the public datacenter specs of common inference
+// accelerators. Costs differ between clouds and contracts, so none is set.
+var builtinAccelerators = []AcceleratorSpec{
+	{Model: "H100", MemoryGB: 80, TDPWatts: 700},
+	{Model: "H100 PCIe", MemoryGB: 80, TDPWatts: 350},
+	{Model: "H100 NVL", MemoryGB: 94, TDPWatts: 400},
+	{Model: "H200", MemoryGB: 141, TDPWatts: 700},
+	{Model: "B200", MemoryGB: 180, TDPWatts: 1000},
+	{Model: "A100 80GB", MemoryGB: 80, TDPWatts: 400},
+	{Model: "A100 40GB", MemoryGB: 40, TDPWatts: 400},
+	{Model: "L40S", MemoryGB: 48, TDPWatts: 350},
+	{Model: "L40", MemoryGB: 48, TDPWatts: 300},
+	{Model: "L4", MemoryGB: 24, TDPWatts: 72},
+	{Model: "A10G", MemoryGB: 24, TDPWatts: 300},
+	{Model: "A10", MemoryGB: 24, TDPWatts: 150},
+	{Model: "MI300X", MemoryGB: 192, TDPWatts: 750},
+	{Model: "MI325X", MemoryGB: 256, TDPWatts: 1000},
+	{Model: "Gaudi3", Aliases: []string{"Gaudi 3", "HL-325L"}, MemoryGB: 128, TDPWatts: 900},
+	{Model: "Gaudi2", Aliases: []string{"Gaudi 2", "HL-225H"}, MemoryGB: 96, TDPWatts: 600},
+}
+
+// acceleratorCatalog looks up the spec of an accelerator by the name a
+// report gives it.
+type acceleratorCatalog struct {
+	specs []AcceleratorSpec
+	// words holds, per spec, the word sets of its model and aliases.
+	words [][][]string
+}
+
+func newAcceleratorCatalog(specs []AcceleratorSpec) *acceleratorCatalog {
+	c := &acceleratorCatalog{specs: specs, words: make([][][]string, len(specs))}
+	for i, s := range specs {
+		for _, name := range append([]string{s.Model}, s.Aliases...) {
+			if w := nameWords(name); len(w) > 0 {
+				c.words[i] = append(c.words[i], w)
+			}
+		}
+	}
+	return c
+}
+
+// acceleratorCatalogFromEnv returns the built-in catalog with the specs of
+// the BENCHMARK_ACCELERATOR_CATALOG file added. A file spec replaces the
+// built-in one of the same model. A file that cannot be read is logged and
+// ignored.
+func acceleratorCatalogFromEnv() *acceleratorCatalog {
+	specs := append([]AcceleratorSpec(nil), builtinAccelerators...)
+	path := os.Getenv(envAcceleratorCatalog)
+	if path == "" {
+		return newAcceleratorCatalog(specs)
+	}
+	extra, err := readAcceleratorCatalog(path)
+	if err != nil {
+		slog.Error("[benchmarks] ignoring accelerator catalog", "path", path, "error", err)
+		return newAcceleratorCatalog(specs)
+	}
+	for _, s := range extra {
+		replaced := false
+		for i := range specs {
+			if strings.EqualFold(specs[i].Model, s.Model) {
+				specs[i], replaced = s, true
+				break
+			}
+		}
+		if !replaced {
+			specs = append(specs, s)
+		}
+	}
+	slog.Info("[benchmarks] loaded accelerator catalog", "path", path, "specs", len(extra))
+	return newAcceleratorCatalog(specs)
+}
+
+// readAcceleratorCatalog reads a list of specs from a YAML file, which also
+// accepts JSON.
+func readAcceleratorCatalog(path string) ([]AcceleratorSpec, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- operator-configured path
+	if err != nil {
+		return nil, err
+	}
+	var specs []AcceleratorSpec
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return nil, err
+	}
+	for i, s := range specs {
+		if strings.TrimSpace(s.Model) == "" {
+			return nil, fmt.Errorf("spec %d has no model", i)
+		}
+		if s.MemoryGB < 0 || s.TDPWatts < 0 || s.CostPerHour < 0 {
+			return nil, fmt.Errorf("spec %q has a negative value", s.Model)
+		}
+	}
+	return specs, nil
+}
+
+// nameWords splits an accelerator name into lower-case words of letters and
+// digits.
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// lookup returns the spec whose model or alias has all its words in name.
+// When several match, the one with the most words wins, so
+// "A100-SXM4-80GB" finds "A100 80GB" and "H100 NVL" beats "H100".
+func (c *acceleratorCatalog) lookup(name string) (AcceleratorSpec, bool) {
+	if c == nil {
+		return AcceleratorSpec{}, false
+	}
+	have := map[string]bool{}
+	for _, w := range nameWords(name) {
+		have[w] = true
+	}
+	best, bestWords := -1, 0
+	for i, sets := range c.words {
+	sets:
+		for _, words := range sets {
+			for _, w := range words {
+				if !have[w] {
+					continue sets
+				}
+			}
+			if len(words) > bestWords {
+				best, bestWords = i, len(words)
+			}
+		}
+	}
+	if best < 0 {
+		return AcceleratorSpec{}, false
+	}
+	return c.specs[best], true
+}
+
+// enrich fills in the memory, TDP and cost of the accelerators in r's stack
+// from the catalog. Values the report already has are kept.
+func (c *acceleratorCatalog) enrich(r *BenchmarkReport) {
+	for i := range r.Scenario.Stack {
+		a := r.Scenario.Stack[i].Standardized.Accelerator
+		if a == nil {
+			continue
+		}
+		spec, ok := c.lookup(a.Model)
+		if !ok {
+			continue
+		}
+		if a.Memory == nil && spec.MemoryGB > 0 {
+			memory := spec.MemoryGB
+			a.Memory = &memory
+		}
+		if a.TDP == nil && spec.TDPWatts > 0 {
+			tdp := spec.TDPWatts
+			a.TDP = &tdp
+		}
+		if a.CostPerHour == nil && spec.CostPerHour > 0 {
+			cost := spec.CostPerHour
+			a.CostPerHour = &cost
+		}
+	}
+}
+
+// stackCostPerHour returns what r's stack costs per hour: the cost of each
+// accelerator times how many its component runs on. It is false when the
+// stack has no accelerators or one without a cost.
+func stackCostPerHour(r BenchmarkReport) (float64, bool) {
+	total := 0.0
+	found := false
+	for _, s := range r.Scenario.Stack {
+		a := s.Standardized.Accelerator
+		if a == nil {
+			continue
+		}
+		n := componentAccelerators(s)
+		if a.CostPerHour == nil || n <= 0 {
+			return 0, false
+		}
+		total += *a.CostPerHour * float64(n)
+		found = true
+	}
+	return total, found && total > 0
+}
+
+// outputTokensPerDollar returns r's mean output token rate as tokens per
+// unit of accelerator cost, or nil when either is unknown.
+func outputTokensPerDollar(r BenchmarkReport) *BenchmarkStatistics {
+	rate := r.Results.RequestPerformance.Aggregate.Throughput.OutputTokenRate
+	cost, ok := stackCostPerHour(r)
+	if rate == nil || !ok {
+		return nil
+	}
+	return &BenchmarkStatistics{Units: "tokens/$", Mean: rate.Mean * secondsPerHour / cost}
+}
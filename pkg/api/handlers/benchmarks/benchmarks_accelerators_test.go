@@ -0,0 +1,92 @@
+package benchmarks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceleratorCatalogLookup(t *testing.T) {
+	c := newAcceleratorCatalog(builtinAccelerators)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"H100", "H100"},
+		{"NVIDIA-H100-80GB-HBM3", "H100"},
+		{"nvidia h100 nvl", "H100 NVL"},
+		{"NVIDIA-A100-SXM4-80GB", "A100 80GB"},
+		{"NVIDIA-L4", "L4"},
+		{"L40S", "L40S"},
+		{"AMD-Instinct-MI300X", "MI300X"},
+		{"HL-225H", "Gaudi2"},
+		{"A100", ""},
+		{"TPU-v5e", ""},
+	}
+	for _, tt := range tests {
+		spec, ok := c.lookup(tt.name)
+		assert.Equal(t, tt.want != "", ok, tt.name)
+		assert.Equal(t, tt.want, spec.Model, tt.name)
+	}
+}
+
+func TestAcceleratorCatalogFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- model: h100
+  memory_gb: 80
+  tdp_watts: 700
+  cost_per_hour: 2.5
+- model: TPU v5e
+  aliases: [v5litepod]
+  memory_gb: 16
+`), 0o600))
+	t.Setenv(envAcceleratorCatalog, path)
+	c := acceleratorCatalogFromEnv()
+
+	spec, ok := c.lookup("NVIDIA-H100-80GB-HBM3")
+	require.True(t, ok)
+	assert.Equal(t, 2.5, spec.CostPerHour, "the file replaces the built-in spec")
+	spec, ok = c.lookup("tpu-v5litepod")
+	require.True(t, ok)
+	assert.Equal(t, 16, spec.MemoryGB)
+	_, ok = c.lookup("L4")
+	assert.True(t, ok, "built-in specs stay")
+
+	require.NoError(t, os.WriteFile(path, []byte("- memory_gb: 1\n"), 0o600))
+	c = acceleratorCatalogFromEnv()
+	spec, ok = c.lookup("H100")
+	require.True(t, ok, "an invalid file falls back to the built-in specs")
+	assert.Zero(t, spec.CostPerHour)
+}
+
+func TestAcceleratorEnrichAndCost(t *testing.T) {
+	c := newAcceleratorCatalog([]AcceleratorSpec{{Model: "H100", MemoryGB: 80, TDPWatts: 700, CostPerHour: 2}})
+	r := searchReport("llama", "r1", "meta-llama/Llama-3.1-8B", "NVIDIA-H100-80GB-HBM3", 10, 1)
+	a := r.Scenario.Stack[0].Standardized.Accelerator
+	a.Count = 4
+	memory := 94
+	a.Memory = &memory
+	r.Results.RequestPerformance.Aggregate.Throughput.OutputTokenRate = &BenchmarkStatistics{Units: "tokens/s", Mean: 1000}
+
+	assert.Nil(t, outputTokensPerDollar(r), "no cost before enrichment")
+	c.enrich(&r)
+	assert.Equal(t, 94, *a.Memory, "reported memory is kept")
+	require.NotNil(t, a.TDP)
+	assert.Equal(t, 700, *a.TDP)
+	require.NotNil(t, a.CostPerHour)
+
+	perDollar := outputTokensPerDollar(r)
+	require.NotNil(t, perDollar)
+	assert.InDelta(t, 1000*3600/8.0, perDollar.Mean, 1e-9)
+
+	groups := groupReports([]BenchmarkReport{r, retentionReport("bare", "r1", time.Now())}, searchFieldExperiment)
+	require.Len(t, groups, 2)
+	assert.Nil(t, groups[0].OutputTokensPerDollar, "bare has no accelerators")
+	require.NotNil(t, groups[1].OutputTokensPerDollar)
+	assert.Equal(t, 1, groups[1].OutputTokensPerDollar.Runs)
+}
@@ -0,0 +1,53 @@
+package benchmarks
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/handlers/auth"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// maxExperimentNameLen bounds the :experiment path parameter.
+const maxExperimentNameLen = 256
+
+// BenchmarkAdminHandlers serves admin-only endpoints for the benchmark report
+// cache.
+type BenchmarkAdminHandlers struct {
+	bench *BenchmarkHandlers
+	store store.Store
+}
+
+// NewBenchmarkAdminHandlers creates admin handlers for bench's cache.
+func NewBenchmarkAdminHandlers(bench *BenchmarkHandlers, s store.Store) *BenchmarkAdminHandlers {
+	return &BenchmarkAdminHandlers{bench: bench, store: s}
+}
+
+// GetRetention returns the retention policy and per-experiment cache counts.
+// GET /api/admin/benchmarks/retention
+func (h *BenchmarkAdminHandlers) GetRetention(c *fiber.Ctx) error {
+	if err := auth.RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	return c.JSON(h.bench.cache.status())
+}
+
+// PurgeExperiment drops every cached report of one experiment. Reports still
+// in Google Drive (and within the retention policy) return on the next fetch.
+// DELETE /api/admin/benchmarks/experiments/:experiment
+func (h *BenchmarkAdminHandlers) PurgeExperiment(c *fiber.Ctx) error {
+	if err := auth.RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	experiment, err := url.PathUnescape(c.Params("experiment"))
+	if err != nil || experiment == "" || len(experiment) > maxExperimentNameLen {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid experiment name"})
+	}
+	purged := h.bench.cache.purgeExperiment(experiment)
+	audit.Log(c, audit.ActionPurgeBenchmarks, "benchmark_experiment", experiment,
+		fmt.Sprintf("purged=%d", purged))
+	return c.JSON(fiber.Map{"experiment": experiment, "purged": purged})
+}
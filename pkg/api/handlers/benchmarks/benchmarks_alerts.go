@@ -0,0 +1,361 @@
+package benchmarks
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/notifications"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// BenchmarkRegressionType is the WebSocket message type of a
+// RegressionAlert.
+const BenchmarkRegressionType = "benchmark_regression"
+
+const (
+	// defaultAlertThresholdPercent matches regressionThreshold.
+	defaultAlertThresholdPercent = 20
+	// defaultAlertBaselineRuns is how many earlier runs a median baseline
+	// spans.
+	defaultAlertBaselineRuns = 5
+	// alertLookback is how long ago a run may have ended and still alert.
+	// Without it the first fetch after a restart would alert on every
+	// group whose newest run ever regressed.
+	alertLookback = 24 * time.Hour
+
+	// alertBaselinePrevious compares a run with the run before it.
+	alertBaselinePrevious = "previous"
+	// alertBaselineMedian compares a run with the median of the
+	// BaselineRuns runs before it, which one noisy run cannot skew.
+	alertBaselineMedian = "median"
+
+	envAlertThreshold    = "BENCHMARK_ALERT_THRESHOLD_PERCENT"
+	envAlertBaseline     = "BENCHMARK_ALERT_BASELINE"
+	envAlertBaselineRuns = "BENCHMARK_ALERT_BASELINE_RUNS"
+	envAlertWebhookURL   = "BENCHMARK_ALERT_WEBHOOK_URL"
+
+	alertRuleID = "benchmark-regression"
+)
+
+// alertMetrics are the statistics a regression alert watches: p99 time to
+// first token and mean output throughput.
+var alertMetrics = []struct {
+	name           string
+	higherIsBetter bool
+	get            func(r *BenchmarkReport) (float64, string, bool)
+}{
+	{name: "time_to_first_token_p99", get: func(r *BenchmarkReport) (float64, string, bool) {
+		s := r.Results.RequestPerformance.Aggregate.Latency.TimeToFirstToken
+		if s == nil || s.P99 == nil {
+			return 0, "", false
+		}
+		return *s.P99, s.Units, true
+	}},
+	{name: "output_token_rate", higherIsBetter: true, get: func(r *BenchmarkReport) (float64, string, bool) {
+		s := r.Results.RequestPerformance.Aggregate.Throughput.OutputTokenRate
+		if s == nil {
+			return 0, "", false
+		}
+		return s.Mean, s.Units, true
+	}},
+}
+
+// AlertPolicy configures regression alerts.
+type AlertPolicy struct {
+	// ThresholdPercent is the change against the baseline that counts as a
+	// regression.
+	ThresholdPercent int `json:"thresholdPercent"`
+	// Baseline is alertBaselinePrevious or alertBaselineMedian.
+	Baseline string `json:"baseline"`
+	// BaselineRuns is how many earlier runs a median baseline spans.
+	BaselineRuns int `json:"baselineRuns"`
+}
+
+// alertPolicyFromEnv reads BENCHMARK_ALERT_THRESHOLD_PERCENT,
+// BENCHMARK_ALERT_BASELINE and BENCHMARK_ALERT_BASELINE_RUNS. Invalid values
+// fall back to the defaults.
+func alertPolicyFromEnv() AlertPolicy {
+	p := AlertPolicy{
+		ThresholdPercent: envNonNegativeInt(envAlertThreshold, defaultAlertThresholdPercent),
+		Baseline:         alertBaselinePrevious,
+		BaselineRuns:     envNonNegativeInt(envAlertBaselineRuns, defaultAlertBaselineRuns),
+	}
+	switch raw := strings.ToLower(strings.TrimSpace(os.Getenv(envAlertBaseline))); raw {
+	case "", alertBaselinePrevious:
+	case alertBaselineMedian:
+		p.Baseline = alertBaselineMedian
+	default:
+		slog.Warn("[benchmarks] invalid setting, using default", "key", envAlertBaseline, "value", raw, "default", alertBaselinePrevious)
+	}
+	if p.BaselineRuns == 0 {
+		p.BaselineRuns = defaultAlertBaselineRuns
+	}
+	return p
+}
+
+// BenchmarkGroup identifies runs that can be compared: the same models on the
+// same stack under the same load.
+type BenchmarkGroup struct {
+	Model string `json:"model"`
+	Stack string `json:"stack"`
+	Load  string `json:"load"`
+}
+
+func (g BenchmarkGroup) String() string {
+	s := g.Model + " on " + g.Stack
+	if g.Load != "" {
+		s += " (" + g.Load + ")"
+	}
+	return s
+}
+
+// reportGroup returns the group of r. Its fields list the models, the stack
+// components with their accelerators, and the load generator with its rate
+// or concurrency, sorted so that the order of the stack does not matter.
+func reportGroup(r BenchmarkReport) BenchmarkGroup {
+	models := reportFieldValues(r, searchFieldModel)
+	sort.Strings(models)
+	var stack []string
+	for _, c := range r.Scenario.Stack {
+		s := c.Standardized
+		part := s.Tool
+		if s.ToolVersion != "" {
+			part += " " + s.ToolVersion
+		}
+		if s.Accelerator != nil && s.Accelerator.Model != "" {
+			part += fmt.Sprintf(" %dx%s", s.Accelerator.Count, s.Accelerator.Model)
+		}
+		if part = strings.TrimSpace(part); part != "" && !slices.Contains(stack, part) {
+			stack = append(stack, part)
+		}
+	}
+	sort.Strings(stack)
+	load := r.Scenario.Load.Standardized
+	loadParts := []string{load.Tool}
+	if load.RateQPS != nil {
+		loadParts = append(loadParts, fmt.Sprintf("rate=%g", *load.RateQPS))
+	}
+	if load.Concurrency != nil {
+		loadParts = append(loadParts, fmt.Sprintf("concurrency=%d", *load.Concurrency))
+	}
+	return BenchmarkGroup{
+		Model: strings.Join(models, ", "),
+		Stack: strings.Join(stack, ", "),
+		Load:  strings.TrimSpace(strings.Join(loadParts, " ")),
+	}
+}
+
+// RegressionAlertMetric is one metric of a RegressionAlert. Change is the
+// relative change from Baseline to Current.
+type RegressionAlertMetric struct {
+	Metric         string  `json:"metric"`
+	Units          string  `json:"units,omitempty"`
+	HigherIsBetter bool    `json:"higher_is_better"`
+	Baseline       float64 `json:"baseline"`
+	Current        float64 `json:"current"`
+	Change         float64 `json:"change"`
+}
+
+// RegressionAlert is raised when the newest run of a group regressed
+// against its baseline on at least one alertMetrics metric.
+type RegressionAlert struct {
+	Group            BenchmarkGroup          `json:"group"`
+	Experiment       string                  `json:"experiment"`
+	RunUID           string                  `json:"run_uid"`
+	Baseline         string                  `json:"baseline"`
+	BaselineRunUIDs  []string                `json:"baseline_run_uids"`
+	ThresholdPercent int                     `json:"threshold_percent"`
+	Metrics          []RegressionAlertMetric `json:"metrics"`
+	At               time.Time               `json:"at"`
+}
+
+// regressionAlerter checks the newest run of each group after the reports
+// are fetched and raises a RegressionAlert when it regressed.
+type regressionAlerter struct {
+	policy    AlertPolicy
+	broadcast func(RegressionAlert)
+	// webhook, when set, also receives every alert.
+	webhook notifications.Notifier
+	now     func() time.Time
+
+	mu sync.Mutex
+	// checked holds the newest run of each group already checked, so a run
+	// alerts once however often the reports are refetched.
+	checked map[BenchmarkGroup]string
+}
+
+// SetRegressionAlerts enables regression alerts: after every fetch of the
+// reports, the newest run of each model and stack is compared with its
+// baseline, and regressions go to broadcast and, when
+// BENCHMARK_ALERT_WEBHOOK_URL is set, to that webhook. See
+// docs/benchmark-alerts.md.
+func (h *BenchmarkHandlers) SetRegressionAlerts(broadcast func(RegressionAlert)) {
+	a := &regressionAlerter{
+		policy:    alertPolicyFromEnv(),
+		broadcast: broadcast,
+		now:       time.Now,
+		checked:   make(map[BenchmarkGroup]string),
+	}
+	if raw := os.Getenv(envAlertWebhookURL); raw != "" {
+		if w, err := notifications.NewWebhookNotifier(raw); err != nil {
+			slog.Error("[benchmarks] regression alert webhook not usable, alerts are only broadcast", "error", err)
+		} else {
+			a.webhook = w
+		}
+	}
+	h.alerts = a
+}
+
+// check compares the newest run of each group in reports with its baseline
+// and sends an alert for each that regressed. Runs that ended more than
+// alertLookback ago, or were checked before, are skipped.
+func (a *regressionAlerter) check(reports []BenchmarkReport) {
+	if a == nil {
+		return
+	}
+	groups := make(map[BenchmarkGroup][]*BenchmarkReport)
+	for i := range reports {
+		r := &reports[i]
+		if reportTime(*r).IsZero() {
+			continue
+		}
+		g := reportGroup(*r)
+		groups[g] = append(groups[g], r)
+	}
+
+	cutoff := a.now().Add(-alertLookback)
+	var alerts []RegressionAlert
+	a.mu.Lock()
+	for g, runs := range groups {
+		if len(runs) < 2 {
+			continue
+		}
+		sort.SliceStable(runs, func(i, j int) bool { return reportTime(*runs[i]).Before(reportTime(*runs[j])) })
+		newest := runs[len(runs)-1]
+		if a.checked[g] == newest.Run.UID {
+			continue
+		}
+		a.checked[g] = newest.Run.UID
+		if reportTime(*newest).Before(cutoff) {
+			continue
+		}
+		if alert, ok := a.compare(g, runs); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	a.mu.Unlock()
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].At.Before(alerts[j].At) })
+	for _, alert := range alerts {
+		slog.Warn("[benchmarks] benchmark regression", "group", alert.Group.String(), "run", alert.RunUID, "baseline", alert.BaselineRunUIDs)
+		if a.broadcast != nil {
+			a.broadcast(alert)
+		}
+		a.notify(alert)
+	}
+}
+
+// compare compares the last of runs, sorted oldest first, with its baseline.
+func (a *regressionAlerter) compare(g BenchmarkGroup, runs []*BenchmarkReport) (RegressionAlert, bool) {
+	newest := runs[len(runs)-1]
+	baseline := runs[len(runs)-2 : len(runs)-1]
+	if a.policy.Baseline == alertBaselineMedian {
+		baseline = runs[max(0, len(runs)-1-a.policy.BaselineRuns) : len(runs)-1]
+	}
+	threshold := float64(a.policy.ThresholdPercent) / 100
+
+	alert := RegressionAlert{
+		Group:            g,
+		Experiment:       reportExperiment(*newest),
+		RunUID:           newest.Run.UID,
+		Baseline:         a.policy.Baseline,
+		ThresholdPercent: a.policy.ThresholdPercent,
+		At:               reportTime(*newest),
+	}
+	for _, r := range baseline {
+		alert.BaselineRunUIDs = append(alert.BaselineRunUIDs, r.Run.UID)
+	}
+	for _, m := range alertMetrics {
+		current, units, ok := m.get(newest)
+		if !ok {
+			continue
+		}
+		var values []float64
+		for _, r := range baseline {
+			if v, _, ok := m.get(r); ok {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		d := newDelta(median(values), current)
+		if d.Change == nil || d.verdict(m.higherIsBetter, threshold) != verdictRegressed {
+			continue
+		}
+		alert.Metrics = append(alert.Metrics, RegressionAlertMetric{
+			Metric:         m.name,
+			Units:          units,
+			HigherIsBetter: m.higherIsBetter,
+			Baseline:       d.Baseline,
+			Current:        d.Current,
+			Change:         *d.Change,
+		})
+	}
+	return alert, len(alert.Metrics) > 0
+}
+
+// median returns the median of values, which must not be empty.
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// notify sends alert to the webhook, if one is configured.
+func (a *regressionAlerter) notify(alert RegressionAlert) {
+	if a.webhook == nil {
+		return
+	}
+	changes := make([]string, 0, len(alert.Metrics))
+	for _, m := range alert.Metrics {
+		changes = append(changes, fmt.Sprintf("%s %+.1f%%", m.Metric, m.Change*100))
+	}
+	n := notifications.Alert{
+		ID:       alertRuleID + "/" + alert.RunUID,
+		RuleID:   alertRuleID,
+		RuleName: "Benchmark regression",
+		Severity: notifications.SeverityWarning,
+		Status:   "firing",
+		Message:  fmt.Sprintf("%s: %s", alert.Group, strings.Join(changes, ", ")),
+		Details: map[string]interface{}{
+			"experiment":        alert.Experiment,
+			"model":             alert.Group.Model,
+			"stack":             alert.Group.Stack,
+			"load":              alert.Group.Load,
+			"baseline":          alert.Baseline,
+			"baseline_run_uids": alert.BaselineRunUIDs,
+			"threshold_percent": alert.ThresholdPercent,
+			"metrics":           alert.Metrics,
+		},
+		Resource:     alert.RunUID,
+		ResourceKind: "BenchmarkRun",
+		FiredAt:      alert.At,
+	}
+	webhook, run := a.webhook, alert.RunUID
+	safego.Go(func() {
+		if err := webhook.Send(n); err != nil {
+			slog.Error("[benchmarks] failed to send regression alert", "run", run, "error", err)
+		}
+	})
+}
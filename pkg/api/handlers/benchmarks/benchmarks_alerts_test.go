@@ -0,0 +1,144 @@
+package benchmarks
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/notifications"
+)
+
+// alertReport is a run of llama on vllm that ended age ago.
+func alertReport(run string, age time.Duration, p99, tokenRate float64) BenchmarkReport {
+	r := compareReport(run, 100, p99, tokenRate, 0)
+	r.Run.Time.End = time.Now().Add(-age).Format(time.RFC3339)
+	var c BenchmarkStackComponent
+	c.Standardized.Tool = "vllm"
+	c.Standardized.Model = &BenchmarkModelRef{Name: "llama-3-8b"}
+	c.Standardized.Accelerator = &BenchmarkAccelerator{Model: "H100", Count: 2}
+	r.Scenario.Stack = []BenchmarkStackComponent{c}
+	return r
+}
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []notifications.Alert
+}
+
+func (n *recordingNotifier) Send(a notifications.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, a)
+	return nil
+}
+
+func (n *recordingNotifier) Test() error { return nil }
+
+func (n *recordingNotifier) sent() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.alerts)
+}
+
+func newTestAlerter(policy AlertPolicy) (*regressionAlerter, *[]RegressionAlert) {
+	var got []RegressionAlert
+	return &regressionAlerter{
+		policy:    policy,
+		broadcast: func(a RegressionAlert) { got = append(got, a) },
+		now:       time.Now,
+		checked:   make(map[BenchmarkGroup]string),
+	}, &got
+}
+
+func TestRegressionAlerter_Previous(t *testing.T) {
+	a, got := newTestAlerter(AlertPolicy{ThresholdPercent: 20, Baseline: alertBaselinePrevious})
+	webhook := &recordingNotifier{}
+	a.webhook = webhook
+
+	reports := []BenchmarkReport{
+		alertReport("r1", 3*time.Hour, 200, 1000),
+		alertReport("r2", 2*time.Hour, 210, 1000),
+		alertReport("r3", time.Hour, 300, 700),
+	}
+	a.check(reports)
+	require.Len(t, *got, 1)
+	alert := (*got)[0]
+	assert.Equal(t, "llama/r3/stage-0", alert.RunUID)
+	assert.Equal(t, []string{"llama/r2/stage-0"}, alert.BaselineRunUIDs)
+	assert.Equal(t, BenchmarkGroup{Model: "llama-3-8b", Stack: "vllm 2xH100", Load: ""}, alert.Group)
+	require.Len(t, alert.Metrics, 2)
+	assert.Equal(t, "time_to_first_token_p99", alert.Metrics[0].Metric)
+	assert.InDelta(t, 300.0/210-1, alert.Metrics[0].Change, 1e-9)
+	assert.Equal(t, "output_token_rate", alert.Metrics[1].Metric)
+	assert.InDelta(t, -0.3, alert.Metrics[1].Change, 1e-9)
+	require.Eventually(t, func() bool { return webhook.sent() == 1 }, time.Second, 10*time.Millisecond)
+
+	a.check(reports)
+	assert.Len(t, *got, 1, "a run alerts once")
+}
+
+func TestRegressionAlerter_Median(t *testing.T) {
+	a, got := newTestAlerter(AlertPolicy{ThresholdPercent: 20, Baseline: alertBaselineMedian, BaselineRuns: 3})
+	a.check([]BenchmarkReport{
+		alertReport("r1", 5*time.Hour, 100, 1000), // outside the baseline window
+		alertReport("r2", 4*time.Hour, 200, 1000),
+		alertReport("r3", 3*time.Hour, 400, 1000), // one noisy run
+		alertReport("r4", 2*time.Hour, 210, 1000),
+		alertReport("r5", time.Hour, 230, 1000),
+	})
+	assert.Empty(t, *got, "230 is within 20% of the median 210")
+
+	a.check([]BenchmarkReport{
+		alertReport("r3", 3*time.Hour, 400, 1000),
+		alertReport("r4", 2*time.Hour, 210, 1000),
+		alertReport("r5", time.Hour, 230, 1000),
+		alertReport("r6", time.Minute, 300, 1000),
+	})
+	require.Len(t, *got, 1)
+	assert.Equal(t, []string{"llama/r3/stage-0", "llama/r4/stage-0", "llama/r5/stage-0"}, (*got)[0].BaselineRunUIDs)
+	assert.InDelta(t, 230, (*got)[0].Metrics[0].Baseline, 1e-9)
+}
+
+func TestRegressionAlerter_Skips(t *testing.T) {
+	a, got := newTestAlerter(AlertPolicy{ThresholdPercent: 20, Baseline: alertBaselinePrevious})
+
+	// An old regression does not alert after a restart.
+	a.check([]BenchmarkReport{
+		alertReport("r1", 72*time.Hour, 200, 1000),
+		alertReport("r2", 48*time.Hour, 400, 1000),
+	})
+	assert.Empty(t, *got)
+
+	// Other stacks and loads are not compared.
+	other := alertReport("r3", time.Hour, 400, 1000)
+	other.Scenario.Stack[0].Standardized.Accelerator.Model = "A100"
+	rate := 4.0
+	loaded := alertReport("r4", time.Hour, 400, 1000)
+	loaded.Scenario.Load.Standardized.RateQPS = &rate
+	a.check([]BenchmarkReport{alertReport("r1", 72*time.Hour, 200, 1000), other, loaded})
+	assert.Empty(t, *got)
+	assert.Equal(t, "rate=4", reportGroup(loaded).Load)
+
+	var nilAlerter *regressionAlerter
+	assert.NotPanics(t, func() { nilAlerter.check([]BenchmarkReport{other}) })
+}
+
+func TestAlertPolicyFromEnv(t *testing.T) {
+	t.Setenv(envAlertThreshold, "")
+	t.Setenv(envAlertBaseline, "")
+	t.Setenv(envAlertBaselineRuns, "")
+	assert.Equal(t, AlertPolicy{ThresholdPercent: 20, Baseline: alertBaselinePrevious, BaselineRuns: 5}, alertPolicyFromEnv())
+
+	t.Setenv(envAlertThreshold, "10")
+	t.Setenv(envAlertBaseline, "Median")
+	t.Setenv(envAlertBaselineRuns, "3")
+	assert.Equal(t, AlertPolicy{ThresholdPercent: 10, Baseline: alertBaselineMedian, BaselineRuns: 3}, alertPolicyFromEnv())
+
+	t.Setenv(envAlertThreshold, "-1")
+	t.Setenv(envAlertBaseline, "best")
+	t.Setenv(envAlertBaselineRuns, "0")
+	assert.Equal(t, AlertPolicy{ThresholdPercent: 20, Baseline: alertBaselinePrevious, BaselineRuns: 5}, alertPolicyFromEnv())
+}
@@ -0,0 +1,260 @@
+package benchmarks
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// maxReportUIDLen bounds the :uid path parameter.
+	maxReportUIDLen = 512
+	// maxAnnotationNoteLen bounds a run's free-text note.
+	maxAnnotationNoteLen = 2000
+	// maxAnnotationLabels and maxAnnotationLabelLen bound a run's labels.
+	maxAnnotationLabels   = 20
+	maxAnnotationLabelLen = 64
+)
+
+// SetAnnotationStore enables stars, notes and labels on benchmark runs.
+// Without a store annotation endpoints report 503 and reports carry none.
+func (h *BenchmarkHandlers) SetAnnotationStore(s store.BenchmarkAnnotationStore) {
+	h.annotations = s
+}
+
+// annotationFilter selects reports by their annotations. The zero value
+// matches every report.
+type annotationFilter struct {
+	starred bool
+	labels  []string
+}
+
+// parseAnnotationFilter reads starred=true and label=a,b (every label must
+// be present) from the query string.
+func parseAnnotationFilter(c *fiber.Ctx) annotationFilter {
+	f := annotationFilter{starred: c.QueryBool("starred")}
+	for _, l := range strings.Split(c.Query("label"), ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			f.labels = append(f.labels, l)
+		}
+	}
+	return f
+}
+
+func (f annotationFilter) active() bool {
+	return f.starred || len(f.labels) > 0
+}
+
+func (f annotationFilter) matches(a models.BenchmarkAnnotation) bool {
+	if f.starred && !a.Starred {
+		return false
+	}
+	for _, want := range f.labels {
+		found := false
+		for _, l := range a.Labels {
+			if strings.EqualFold(l, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// loadAnnotations returns every annotation keyed by report UID. A store
+// error is logged and yields no annotations, so reports are still served.
+func (h *BenchmarkHandlers) loadAnnotations(ctx context.Context) map[string]models.BenchmarkAnnotation {
+	out := make(map[string]models.BenchmarkAnnotation)
+	if h.annotations == nil {
+		return out
+	}
+	list, err := h.annotations.ListBenchmarkAnnotations(ctx)
+	if err != nil {
+		slog.Error("[benchmarks] failed to load annotations", "error", err)
+		return out
+	}
+	for _, a := range list {
+		out[a.ReportUID] = a
+	}
+	return out
+}
+
+// annotatedUIDs returns the UIDs whose annotations match f.
+func annotatedUIDs(annotations map[string]models.BenchmarkAnnotation, f annotationFilter) map[string]bool {
+	uids := make(map[string]bool)
+	for uid, a := range annotations {
+		if f.matches(a) {
+			uids[uid] = true
+		}
+	}
+	return uids
+}
+
+// annotateReports filters reports by f and returns them with the
+// annotations of the reports kept.
+func annotateReports(reports []BenchmarkReport, annotations map[string]models.BenchmarkAnnotation, f annotationFilter) ([]BenchmarkReport, map[string]models.BenchmarkAnnotation) {
+	kept := reports
+	if f.active() {
+		allowed := annotatedUIDs(annotations, f)
+		kept = make([]BenchmarkReport, 0, len(allowed))
+		for _, r := range reports {
+			if allowed[r.Run.UID] {
+				kept = append(kept, r)
+			}
+		}
+	}
+	attached := make(map[string]models.BenchmarkAnnotation)
+	for _, r := range kept {
+		if a, ok := annotations[r.Run.UID]; ok {
+			attached[r.Run.UID] = a
+		}
+	}
+	return kept, attached
+}
+
+// reportsResponse is the GetReports body for reports from source, filtered
+// by q and annotated per the request's query string.
+func (h *BenchmarkHandlers) reportsResponse(c *fiber.Ctx, q BenchmarkReportQuery, reports []BenchmarkReport, source string) fiber.Map {
+	kept, annotations := annotateReports(q.filter(reports), h.loadAnnotations(c.UserContext()), parseAnnotationFilter(c))
+	return queryResponse(kept, annotations, q, source)
+}
+
+// ListAnnotations returns every benchmark run annotation.
+// GET /api/benchmarks/annotations?starred=true&label=baseline-H100
+func (h *BenchmarkHandlers) ListAnnotations(c *fiber.Ctx) error {
+	if isDemoMode(c) {
+		return c.JSON(fiber.Map{"annotations": []models.BenchmarkAnnotation{}, "source": "demo"})
+	}
+	if h.annotations == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "benchmark annotations are not available")
+	}
+	list, err := h.annotations.ListBenchmarkAnnotations(c.UserContext())
+	if err != nil {
+		slog.Error("[benchmarks] failed to list annotations", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to list annotations")
+	}
+	f := parseAnnotationFilter(c)
+	out := make([]models.BenchmarkAnnotation, 0, len(list))
+	for _, a := range list {
+		if f.matches(a) {
+			out = append(out, a)
+		}
+	}
+	return c.JSON(fiber.Map{"annotations": out})
+}
+
+type annotationRequest struct {
+	Starred bool     `json:"starred"`
+	Note    string   `json:"note"`
+	Labels  []string `json:"labels"`
+}
+
+// PutAnnotation creates or replaces the annotation of one run. The :uid
+// parameter is the path-escaped Run.UID.
+// PUT /api/benchmarks/annotations/:uid
+func (h *BenchmarkHandlers) PutAnnotation(c *fiber.Ctx) error {
+	if h.annotations == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "benchmark annotations are not available")
+	}
+	uid, err := reportUIDParam(c)
+	if err != nil {
+		return err
+	}
+	var req annotationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	labels, err := normalizeAnnotationLabels(req.Labels)
+	if err != nil {
+		return err
+	}
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxAnnotationNoteLen {
+		return fiber.NewError(fiber.StatusBadRequest, "note is too long")
+	}
+
+	a := &models.BenchmarkAnnotation{
+		ReportUID: uid,
+		Starred:   req.Starred,
+		Note:      note,
+		Labels:    labels,
+		UpdatedBy: middleware.GetUserID(c),
+	}
+	// An annotation with nothing left in it is the same as none.
+	if !a.Starred && a.Note == "" && len(a.Labels) == 0 {
+		if err := h.annotations.DeleteBenchmarkAnnotation(c.UserContext(), uid); err != nil {
+			slog.Error("[benchmarks] failed to clear annotation", "uid", uid, "error", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to save annotation")
+		}
+		return c.JSON(a)
+	}
+	if err := h.annotations.SaveBenchmarkAnnotation(c.UserContext(), a); err != nil {
+		slog.Error("[benchmarks] failed to save annotation", "uid", uid, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to save annotation")
+	}
+	return c.JSON(a)
+}
+
+// DeleteAnnotation removes the annotation of one run.
+// DELETE /api/benchmarks/annotations/:uid
+func (h *BenchmarkHandlers) DeleteAnnotation(c *fiber.Ctx) error {
+	if h.annotations == nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "benchmark annotations are not available")
+	}
+	uid, err := reportUIDParam(c)
+	if err != nil {
+		return err
+	}
+	if err := h.annotations.DeleteBenchmarkAnnotation(c.UserContext(), uid); err != nil {
+		slog.Error("[benchmarks] failed to delete annotation", "uid", uid, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete annotation")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func reportUIDParam(c *fiber.Ctx) (string, error) {
+	// Params aliases the request buffer, and the UID outlives the request.
+	uid, err := url.PathUnescape(utils.CopyString(c.Params("uid")))
+	if err != nil || strings.TrimSpace(uid) == "" || len(uid) > maxReportUIDLen {
+		return "", fiber.NewError(fiber.StatusBadRequest, "invalid report uid")
+	}
+	return uid, nil
+}
+
+// normalizeAnnotationLabels trims and de-duplicates labels (case-insensitive,
+// first spelling wins) and enforces the label limits. Commas are rejected
+// because the label filter is comma-separated.
+func normalizeAnnotationLabels(in []string) ([]string, error) {
+	labels := make([]string, 0, len(in))
+	seen := make(map[string]bool, len(in))
+	for _, l := range in {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if len(l) > maxAnnotationLabelLen || strings.Contains(l, ",") {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "invalid label")
+		}
+		key := strings.ToLower(l)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		labels = append(labels, l)
+	}
+	if len(labels) > maxAnnotationLabels {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "too many labels")
+	}
+	return labels, nil
+}
@@ -0,0 +1,221 @@
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// memAnnotationStore is an in-memory store.BenchmarkAnnotationStore.
+type memAnnotationStore struct {
+	byUID   map[string]models.BenchmarkAnnotation
+	listErr error
+}
+
+func newMemAnnotationStore() *memAnnotationStore {
+	return &memAnnotationStore{byUID: map[string]models.BenchmarkAnnotation{}}
+}
+
+func (s *memAnnotationStore) GetBenchmarkAnnotation(_ context.Context, uid string) (*models.BenchmarkAnnotation, error) {
+	a, ok := s.byUID[uid]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (s *memAnnotationStore) ListBenchmarkAnnotations(context.Context) ([]models.BenchmarkAnnotation, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	out := make([]models.BenchmarkAnnotation, 0, len(s.byUID))
+	for _, a := range s.byUID {
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (s *memAnnotationStore) SaveBenchmarkAnnotation(_ context.Context, a *models.BenchmarkAnnotation) error {
+	a.UpdatedAt = time.Now()
+	s.byUID[a.ReportUID] = *a
+	return nil
+}
+
+func (s *memAnnotationStore) DeleteBenchmarkAnnotation(_ context.Context, uid string) error {
+	delete(s.byUID, uid)
+	return nil
+}
+
+func setupAnnotationsApp(t *testing.T) (*fiber.App, *memAnnotationStore, uuid.UUID) {
+	t.Helper()
+	userID := uuid.New()
+	annotations := newMemAnnotationStore()
+	h := NewBenchmarkHandlers("key", "folder")
+	h.cache.retention = RetentionPolicy{}
+	h.cache.set(searchFixtures(), "0")
+	h.SetAnnotationStore(annotations)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/benchmarks/reports", h.GetReports)
+	app.Get("/api/benchmarks/search", h.SearchReports)
+	app.Get("/api/benchmarks/annotations", h.ListAnnotations)
+	app.Put("/api/benchmarks/annotations/:uid", h.PutAnnotation)
+	app.Delete("/api/benchmarks/annotations/:uid", h.DeleteAnnotation)
+	return app, annotations, userID
+}
+
+func putAnnotation(t *testing.T, app *fiber.App, uid, body string) int {
+	t.Helper()
+	req := httptest.NewRequest("PUT", "/api/benchmarks/annotations/"+uid, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestPutAnnotation(t *testing.T) {
+	app, annotations, userID := setupAnnotationsApp(t)
+
+	status := putAnnotation(t, app, "llama-sweep%2Fr1%2Fstage-0",
+		`{"starred":true,"note":"  good run ","labels":["baseline-H100"," ","Baseline-h100","regression"]}`)
+	require.Equal(t, fiber.StatusOK, status)
+	a := annotations.byUID["llama-sweep/r1/stage-0"]
+	assert.True(t, a.Starred)
+	assert.Equal(t, "good run", a.Note)
+	assert.Equal(t, []string{"baseline-H100", "regression"}, a.Labels, "labels are trimmed and de-duplicated")
+	assert.Equal(t, userID, a.UpdatedBy)
+
+	require.Equal(t, fiber.StatusOK, putAnnotation(t, app, "llama-sweep%2Fr1%2Fstage-0", `{"starred":false}`))
+	assert.NotContains(t, annotations.byUID, "llama-sweep/r1/stage-0", "an empty annotation is removed")
+
+	for name, body := range map[string]string{
+		"malformed":      `{"starred":`,
+		"long note":      `{"note":"` + strings.Repeat("n", maxAnnotationNoteLen+1) + `"}`,
+		"long label":     `{"labels":["` + strings.Repeat("l", maxAnnotationLabelLen+1) + `"]}`,
+		"comma in label": `{"labels":["a,b"]}`,
+		"too many":       `{"labels":["` + strings.Join(itemLabels(maxAnnotationLabels+1), `","`) + `"]}`,
+	} {
+		assert.Equal(t, fiber.StatusBadRequest, putAnnotation(t, app, "r", body), name)
+	}
+	assert.Equal(t, fiber.StatusBadRequest, putAnnotation(t, app, "%20", `{"starred":true}`), "blank uid")
+	assert.Empty(t, annotations.byUID)
+}
+
+func itemLabels(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = "l" + strings.Repeat("x", i)
+	}
+	return out
+}
+
+func TestListAndDeleteAnnotations(t *testing.T) {
+	app, annotations, _ := setupAnnotationsApp(t)
+	require.Equal(t, fiber.StatusOK, putAnnotation(t, app, "a", `{"starred":true,"labels":["baseline"]}`))
+	require.Equal(t, fiber.StatusOK, putAnnotation(t, app, "b", `{"note":"flaky","labels":["baseline","rerun"]}`))
+
+	list := func(query string) []string {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/annotations"+query, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var out struct {
+			Annotations []models.BenchmarkAnnotation `json:"annotations"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		var uids []string
+		for _, a := range out.Annotations {
+			uids = append(uids, a.ReportUID)
+		}
+		return uids
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, list(""))
+	assert.Equal(t, []string{"a"}, list("?starred=true"))
+	assert.Equal(t, []string{"b"}, list("?label=Baseline,rerun"))
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/benchmarks/annotations/a", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.NotContains(t, annotations.byUID, "a")
+}
+
+func TestAnnotationsUnavailableWithoutStore(t *testing.T) {
+	h := NewBenchmarkHandlers("key", "folder")
+	app := fiber.New()
+	app.Get("/api/benchmarks/annotations", h.ListAnnotations)
+	app.Put("/api/benchmarks/annotations/:uid", h.PutAnnotation)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/annotations", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, fiber.StatusServiceUnavailable, putAnnotation(t, app, "a", `{"starred":true}`))
+}
+
+func TestGetReports_FiltersByAnnotation(t *testing.T) {
+	app, annotations, _ := setupAnnotationsApp(t)
+	require.Equal(t, fiber.StatusOK, putAnnotation(t, app, "mixtral%2Fr1%2Fstage-0", `{"starred":true,"labels":["baseline-A100"]}`))
+	require.Equal(t, fiber.StatusOK, putAnnotation(t, app, "bare%2Fr1%2Fstage-0", `{"labels":["baseline-A100"]}`))
+
+	reports := func(query string) ([]string, map[string]models.BenchmarkAnnotation) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/reports"+query, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var out struct {
+			Reports     []BenchmarkReport                     `json:"reports"`
+			Annotations map[string]models.BenchmarkAnnotation `json:"annotations"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		var uids []string
+		for _, r := range out.Reports {
+			uids = append(uids, r.Run.UID)
+		}
+		return uids, out.Annotations
+	}
+
+	uids, attached := reports("")
+	assert.Len(t, uids, 4, "no filter returns every report")
+	assert.Len(t, attached, 2)
+	assert.True(t, attached["mixtral/r1/stage-0"].Starred)
+
+	uids, attached = reports("?starred=true")
+	assert.Equal(t, []string{"mixtral/r1/stage-0"}, uids)
+	assert.Len(t, attached, 1, "only annotations of returned reports are attached")
+
+	uids, _ = reports("?label=baseline-a100")
+	assert.Equal(t, []string{"mixtral/r1/stage-0", "bare/r1/stage-0"}, uids)
+
+	annotations.listErr = errors.New("db down")
+	uids, attached = reports("")
+	assert.Len(t, uids, 4, "a store error does not hide reports")
+	assert.Empty(t, attached)
+}
+
+func TestSearchReports_FiltersByAnnotation(t *testing.T) {
+	app, _, _ := setupAnnotationsApp(t)
+	require.Equal(t, fiber.StatusOK, putAnnotation(t, app, "llama-sweep%2Fr2%2Fstage-0", `{"starred":true}`))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/search?q=llama&starred=true", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var out struct {
+		UIDs  []string `json:"uids"`
+		Total int      `json:"total"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.Equal(t, []string{"llama-sweep/r2/stage-0"}, out.UIDs)
+	assert.Equal(t, 1, out.Total)
+}
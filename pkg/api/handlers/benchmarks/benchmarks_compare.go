@@ -0,0 +1,234 @@
+package benchmarks
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxCompareRuns bounds how many runs one comparison takes, the baseline
+// included.
+const maxCompareRuns = 10
+
+// Comparison verdicts, for a metric and for a run as a whole.
+const (
+	verdictRegressed = "regressed"
+	verdictImproved  = "improved"
+	verdictUnchanged = "unchanged"
+)
+
+// compareMetrics are the aggregate statistics a comparison reports, besides
+// the request failure rate.
+var compareMetrics = []regressionMetric{
+	{name: "time_to_first_token", get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Latency.TimeToFirstToken
+	}},
+	{name: "inter_token_latency", get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Latency.InterTokenLatency
+	}},
+	{name: "output_token_rate", higherIsBetter: true, get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Throughput.OutputTokenRate
+	}},
+	{name: "request_rate", higherIsBetter: true, get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Throughput.RequestRate
+	}},
+}
+
+// comparePercentiles are the percentiles a metric delta breaks out when both
+// runs report them.
+var comparePercentiles = []struct {
+	name string
+	get  func(s *BenchmarkStatistics) *float64
+}{
+	{"p50", func(s *BenchmarkStatistics) *float64 { return s.P50 }},
+	{"p90", func(s *BenchmarkStatistics) *float64 { return s.P90 }},
+	{"p95", func(s *BenchmarkStatistics) *float64 { return s.P95 }},
+	{"p99", func(s *BenchmarkStatistics) *float64 { return s.P99 }},
+}
+
+// BenchmarkDelta is one value of a run against the baseline. Change is the
+// relative change from Baseline to Current, nil when Baseline is 0.
+type BenchmarkDelta struct {
+	Baseline float64  `json:"baseline"`
+	Current  float64  `json:"current"`
+	Change   *float64 `json:"change"`
+}
+
+// BenchmarkMetricDelta compares one metric of a run with the baseline: its
+// mean, the percentiles both runs report, and whether the mean moved past
+// the threshold for the worse or the better.
+type BenchmarkMetricDelta struct {
+	Metric         string `json:"metric"`
+	Units          string `json:"units,omitempty"`
+	HigherIsBetter bool   `json:"higher_is_better"`
+	BenchmarkDelta
+	Percentiles map[string]BenchmarkDelta `json:"percentiles,omitempty"`
+	Verdict     string                    `json:"verdict"`
+}
+
+// BenchmarkComparison is a run compared with the baseline. Its verdict is
+// regressed when any metric regressed, otherwise improved when any
+// improved. Metrics either run lacks are left out.
+type BenchmarkComparison struct {
+	RunUID  string                 `json:"run_uid"`
+	Verdict string                 `json:"verdict"`
+	Metrics []BenchmarkMetricDelta `json:"metrics"`
+}
+
+func newDelta(baseline, current float64) BenchmarkDelta {
+	d := BenchmarkDelta{Baseline: baseline, Current: current}
+	if baseline != 0 {
+		change := (current - baseline) / baseline
+		d.Change = &change
+	}
+	return d
+}
+
+// verdict judges d against threshold. A change from 0 counts in full: any
+// rise in a latency or the failure rate from 0 is a regression.
+func (d BenchmarkDelta) verdict(higherIsBetter bool, threshold float64) string {
+	if d.Current == d.Baseline {
+		return verdictUnchanged
+	}
+	change := math.Inf(1)
+	switch {
+	case d.Change != nil:
+		change = *d.Change
+	case d.Current < d.Baseline:
+		change = math.Inf(-1)
+	}
+	if higherIsBetter {
+		change = -change
+	}
+	switch {
+	case change > threshold:
+		return verdictRegressed
+	case change < -threshold:
+		return verdictImproved
+	}
+	return verdictUnchanged
+}
+
+// compareReports compares run with baseline.
+func compareReports(baseline, run *BenchmarkReport, threshold float64) BenchmarkComparison {
+	out := BenchmarkComparison{RunUID: run.Run.UID, Verdict: verdictUnchanged, Metrics: []BenchmarkMetricDelta{}}
+	add := func(m BenchmarkMetricDelta) {
+		m.Verdict = m.BenchmarkDelta.verdict(m.HigherIsBetter, threshold)
+		switch {
+		case m.Verdict == verdictRegressed:
+			out.Verdict = verdictRegressed
+		case m.Verdict == verdictImproved && out.Verdict == verdictUnchanged:
+			out.Verdict = verdictImproved
+		}
+		out.Metrics = append(out.Metrics, m)
+	}
+
+	for _, metric := range compareMetrics {
+		base, cur := metric.get(baseline), metric.get(run)
+		if base == nil || cur == nil {
+			continue
+		}
+		m := BenchmarkMetricDelta{
+			Metric:         metric.name,
+			Units:          cur.Units,
+			HigherIsBetter: metric.higherIsBetter,
+			BenchmarkDelta: newDelta(base.Mean, cur.Mean),
+		}
+		for _, p := range comparePercentiles {
+			b, c := p.get(base), p.get(cur)
+			if b == nil || c == nil {
+				continue
+			}
+			if m.Percentiles == nil {
+				m.Percentiles = map[string]BenchmarkDelta{}
+			}
+			m.Percentiles[p.name] = newDelta(*b, *c)
+		}
+		add(m)
+	}
+
+	baseReq, curReq := baseline.Results.RequestPerformance.Aggregate.Requests, run.Results.RequestPerformance.Aggregate.Requests
+	if baseReq.Total > 0 && curReq.Total > 0 {
+		add(BenchmarkMetricDelta{
+			Metric: "failure_rate",
+			BenchmarkDelta: newDelta(float64(baseReq.Failures)/float64(baseReq.Total),
+				float64(curReq.Failures)/float64(curReq.Total)),
+		})
+	}
+	return out
+}
+
+// parseCompareRuns reads the runs query parameter: two to maxCompareRuns
+// distinct run UIDs, the baseline first.
+func parseCompareRuns(c *fiber.Ctx) ([]string, error) {
+	var runs []string
+	for _, uid := range strings.Split(c.Query("runs"), ",") {
+		uid = strings.TrimSpace(uid)
+		if uid == "" {
+			continue
+		}
+		if slices.Contains(runs, uid) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "run "+uid+" is listed twice")
+		}
+		runs = append(runs, uid)
+	}
+	if len(runs) < 2 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "runs needs at least two run UIDs")
+	}
+	if len(runs) > maxCompareRuns {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "runs takes at most "+strconv.Itoa(maxCompareRuns)+" run UIDs")
+	}
+	return runs, nil
+}
+
+// CompareReports compares cached benchmark runs with the first one listed:
+// the relative change of TTFT, inter-token latency, throughput and request
+// failure rate, per percentile where both runs report them, and a verdict
+// per metric and run. threshold is the relative change that counts as a
+// regression or improvement, regressionThreshold by default.
+// GET /api/benchmarks/compare?runs=baseline,run&threshold=
+func (h *BenchmarkHandlers) CompareReports(c *fiber.Ctx) error {
+	runs, err := parseCompareRuns(c)
+	if err != nil {
+		return err
+	}
+	threshold := regressionThreshold
+	if raw := c.Query("threshold"); raw != "" {
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(threshold) || math.IsInf(threshold, 0) || threshold < 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid threshold")
+		}
+	}
+	if isDemoMode(c) {
+		return c.JSON(fiber.Map{"baseline": runs[0], "threshold": threshold, "comparisons": []BenchmarkComparison{}, "source": "demo"})
+	}
+
+	byUID := make(map[string]*BenchmarkReport, len(runs))
+	h.cache.mu.RLock()
+	for i := range h.cache.reports {
+		r := &h.cache.reports[i]
+		if slices.Contains(runs, r.Run.UID) {
+			copied := *r
+			byUID[r.Run.UID] = &copied
+		}
+	}
+	h.cache.mu.RUnlock()
+	var missing []string
+	for _, uid := range runs {
+		if byUID[uid] == nil {
+			missing = append(missing, uid)
+		}
+	}
+	if len(missing) > 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "runs not in the benchmark cache", "missing": missing})
+	}
+
+	comparisons := make([]BenchmarkComparison, 0, len(runs)-1)
+	for _, uid := range runs[1:] {
+		comparisons = append(comparisons, compareReports(byUID[runs[0]], byUID[uid], threshold))
+	}
+	return sendConditional(c, fiber.Map{"baseline": runs[0], "threshold": threshold, "comparisons": comparisons, "source": "cache"})
+}
@@ -0,0 +1,107 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compareReport(run string, ttft, p99, tokenRate float64, failures int) BenchmarkReport {
+	r := retentionReport("llama", run, time.Now())
+	agg := &r.Results.RequestPerformance.Aggregate
+	agg.Latency.TimeToFirstToken = &BenchmarkStatistics{Units: "ms", Mean: ttft, P99: &p99}
+	agg.Throughput.OutputTokenRate = &BenchmarkStatistics{Units: "tokens/s", Mean: tokenRate}
+	agg.Requests = BenchmarkRequestStats{Total: 100, Failures: failures}
+	return r
+}
+
+func TestCompareReports(t *testing.T) {
+	baseline := compareReport("r1", 100, 200, 1000, 0)
+
+	slower := compareReport("r2", 130, 300, 950, 2)
+	got := compareReports(&baseline, &slower, regressionThreshold)
+	assert.Equal(t, "llama/r2/stage-0", got.RunUID)
+	assert.Equal(t, verdictRegressed, got.Verdict)
+	require.Len(t, got.Metrics, 3, "metrics either run lacks are left out")
+
+	ttft := got.Metrics[0]
+	assert.Equal(t, "time_to_first_token", ttft.Metric)
+	assert.Equal(t, "ms", ttft.Units)
+	require.NotNil(t, ttft.Change)
+	assert.InDelta(t, 0.3, *ttft.Change, 1e-9)
+	assert.Equal(t, verdictRegressed, ttft.Verdict)
+	require.Contains(t, ttft.Percentiles, "p99")
+	assert.InDelta(t, 0.5, *ttft.Percentiles["p99"].Change, 1e-9)
+	assert.NotContains(t, ttft.Percentiles, "p50", "neither run reports p50")
+
+	rate := got.Metrics[1]
+	assert.Equal(t, "output_token_rate", rate.Metric)
+	assert.True(t, rate.HigherIsBetter)
+	assert.Equal(t, verdictUnchanged, rate.Verdict, "a 5% drop is within the threshold")
+
+	failures := got.Metrics[2]
+	assert.Equal(t, "failure_rate", failures.Metric)
+	assert.Nil(t, failures.Change, "no relative change from a 0 baseline")
+	assert.Equal(t, verdictRegressed, failures.Verdict)
+
+	faster := compareReport("r3", 60, 100, 1300, 0)
+	got = compareReports(&baseline, &faster, regressionThreshold)
+	assert.Equal(t, verdictImproved, got.Verdict)
+	assert.Equal(t, verdictImproved, got.Metrics[1].Verdict, "higher throughput is an improvement")
+	assert.Equal(t, verdictUnchanged, got.Metrics[2].Verdict)
+
+	got = compareReports(&baseline, &slower, 0.5)
+	assert.Equal(t, verdictRegressed, got.Verdict, "the failure rate still rose from 0")
+	assert.Equal(t, verdictUnchanged, got.Metrics[0].Verdict)
+}
+
+func TestCompareReportsHandler(t *testing.T) {
+	h := NewBenchmarkHandlers("key", "folder")
+	h.cache.retention = RetentionPolicy{}
+	h.cache.set([]BenchmarkReport{
+		compareReport("r1", 100, 200, 1000, 0),
+		compareReport("r2", 130, 300, 950, 2),
+		compareReport("r3", 60, 100, 1300, 0),
+	}, "0")
+	app := fiber.New()
+	app.Get("/api/benchmarks/compare", h.CompareReports)
+
+	get := func(query string, out any) int {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/compare"+query, nil))
+		require.NoError(t, err)
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	var body struct {
+		Baseline    string                `json:"baseline"`
+		Threshold   float64               `json:"threshold"`
+		Comparisons []BenchmarkComparison `json:"comparisons"`
+	}
+	require.Equal(t, fiber.StatusOK, get("?runs=llama/r1/stage-0,llama/r2/stage-0,llama/r3/stage-0", &body))
+	assert.Equal(t, "llama/r1/stage-0", body.Baseline)
+	assert.Equal(t, regressionThreshold, body.Threshold)
+	require.Len(t, body.Comparisons, 2)
+	assert.Equal(t, verdictRegressed, body.Comparisons[0].Verdict)
+	assert.Equal(t, verdictImproved, body.Comparisons[1].Verdict)
+
+	var missing struct {
+		Missing []string `json:"missing"`
+	}
+	require.Equal(t, fiber.StatusNotFound, get("?runs=llama/r1/stage-0,llama/r9/stage-0", &missing))
+	assert.Equal(t, []string{"llama/r9/stage-0"}, missing.Missing)
+
+	for _, query := range []string{
+		"", "?runs=llama/r1/stage-0", "?runs=llama/r1/stage-0,llama/r1/stage-0",
+		"?runs=a,b,c,d,e,f,g,h,i,j,k", "?runs=llama/r1/stage-0,llama/r2/stage-0&threshold=-1",
+	} {
+		assert.Equal(t, fiber.StatusBadRequest, get(query, nil), query)
+	}
+}
@@ -402,13 +402,13 @@ func TestIsAfterCutoff_EdgeCases(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		file     driveFile
+		file     SourceEntry
 		cutoff   time.Time
 		expected bool
 	}{
 		{
 			name: "file created exactly at cutoff",
-			file: driveFile{
+			file: SourceEntry{
 				Name:        "test.yaml",
 				CreatedTime: exactCutoff.Format(time.RFC3339),
 			},
@@ -417,7 +417,7 @@ func TestIsAfterCutoff_EdgeCases(t *testing.T) {
 		},
 		{
 			name: "file created 1 second after cutoff",
-			file: driveFile{
+			file: SourceEntry{
 				Name:        "test.yaml",
 				CreatedTime: exactCutoff.Add(1 * time.Second).Format(time.RFC3339),
 			},
@@ -426,7 +426,7 @@ func TestIsAfterCutoff_EdgeCases(t *testing.T) {
 		},
 		{
 			name: "file created 1 second before cutoff",
-			file: driveFile{
+			file: SourceEntry{
 				Name:        "test.yaml",
 				CreatedTime: exactCutoff.Add(-1 * time.Second).Format(time.RFC3339),
 			},
@@ -435,7 +435,7 @@ func TestIsAfterCutoff_EdgeCases(t *testing.T) {
 		},
 		{
 			name: "malformed RFC3339",
-			file: driveFile{
+			file: SourceEntry{
 				Name:        "test.yaml",
 				CreatedTime: "2025-13-45T99:99:99Z", // Invalid date
 			},
@@ -444,7 +444,7 @@ func TestIsAfterCutoff_EdgeCases(t *testing.T) {
 		},
 		{
 			name: "partial timestamp",
-			file: driveFile{
+			file: SourceEntry{
 				Name:        "test.yaml",
 				CreatedTime: "2025-01-01", // Missing time component
 			},
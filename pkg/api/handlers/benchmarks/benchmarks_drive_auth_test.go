@@ -0,0 +1,96 @@
+package benchmarks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeServiceAccountKey writes a service-account key file with a fresh RSA
+// key and returns its path.
+func writeServiceAccountKey(t *testing.T, keyType string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	data, err := json.Marshal(serviceAccountKey{
+		Type:         keyType,
+		ClientEmail:  "console@project.iam.gserviceaccount.com",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		PrivateKeyID: "key-1",
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestServiceAccountDriveAuth(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		tokenGrants int
+		requests    []*http.Request
+	)
+	srv, client := newMockDriveServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/token" {
+			require.NoError(t, r.ParseForm())
+			assert.NotEmpty(t, r.Form.Get("assertion"))
+			tokenGrants++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"sa-token","token_type":"Bearer","expires_in":3600}`))
+			return
+		}
+		requests = append(requests, r.Clone(r.Context()))
+		if strings.Contains(r.URL.Query().Get("q"), "in parents") {
+			json.NewEncoder(w).Encode(driveFileList{Files: []driveFile{{ID: "report-1", Name: "benchmark_report_1.yaml"}}})
+			return
+		}
+		w.Write([]byte(validBenchmarkYAML))
+	}))
+	defer srv.Close()
+
+	h := NewBenchmarkHandlers("", "private-folder")
+	h.SetHTTPClient(client)
+	assert.False(t, h.configured())
+	require.NoError(t, h.SetServiceAccount(writeServiceAccountKey(t, "service_account")))
+	assert.True(t, h.configured(), "a service account is enough to read Drive")
+
+	source := h.reportSource()
+	entries, err := source.List(t.Context(), "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	_, err = source.Read(t.Context(), entries[0].ID)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, tokenGrants, "the token is reused until it expires")
+	require.Len(t, requests, 2)
+	for _, r := range requests {
+		assert.Equal(t, "Bearer sa-token", r.Header.Get("Authorization"))
+		assert.Empty(t, r.URL.Query().Get("key"))
+	}
+	assert.Equal(t, "/drive/v3/files/report-1", requests[1].URL.Path, "private files are downloaded through the API")
+	assert.Equal(t, "media", requests[1].URL.Query().Get("alt"))
+}
+
+func TestSetServiceAccount_Rejects(t *testing.T) {
+	h := NewBenchmarkHandlers("", "")
+	assert.Error(t, h.SetServiceAccount(filepath.Join(t.TempDir(), "missing.json")))
+	assert.Error(t, h.SetServiceAccount(writeServiceAccountKey(t, "authorized_user")))
+	assert.False(t, h.configured())
+}
@@ -0,0 +1,274 @@
+package benchmarks
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// envGoodputSLOs overrides defaultGoodputSLOs, as SLOs separated by ";",
+// each a comma-separated list of ttft=<duration> and tpot=<duration>, e.g.
+// "ttft=500ms,tpot=50ms;ttft=2s".
+const envGoodputSLOs = "BENCHMARK_GOODPUT_SLOS"
+
+// defaultGoodputSLOs are an interactive chat target and a relaxed batch
+// target.
+var defaultGoodputSLOs = []GoodputSLO{
+	{TTFT: 500 * time.Millisecond, TPOT: 50 * time.Millisecond},
+	{TTFT: 2 * time.Second, TPOT: 100 * time.Millisecond},
+}
+
+// GoodputSLO is a latency target a request meets when its time to first
+// token and time per output token are both within bounds. A zero bound is
+// not checked.
+type GoodputSLO struct {
+	TTFT time.Duration
+	TPOT time.Duration
+}
+
+// BenchmarkExtensions are metrics the console derives from a report, so
+// every client gets the same math. They are not part of the report format.
+type BenchmarkExtensions struct {
+	Efficiency *BenchmarkEfficiency `json:"efficiency,omitempty"`
+}
+
+// BenchmarkEfficiency normalizes throughput by the hardware that produced
+// it and by latency targets. A metric whose inputs the report lacks is
+// left out.
+type BenchmarkEfficiency struct {
+	// Accelerators is the number of accelerators in the stack.
+	Accelerators                  int      `json:"accelerators,omitempty"`
+	OutputTokenRatePerAccelerator *float64 `json:"output_token_rate_per_accelerator,omitempty"`
+	TotalTokenRatePerAccelerator  *float64 `json:"total_token_rate_per_accelerator,omitempty"`
+	// OutputTokenRatePerWatt divides by the accelerators' TDP, the most
+	// power they draw, so it is a lower bound.
+	OutputTokenRatePerWatt *float64           `json:"output_token_rate_per_watt,omitempty"`
+	Goodput                []BenchmarkGoodput `json:"goodput,omitempty"`
+}
+
+// BenchmarkGoodput is the throughput of the requests that meet an SLO.
+type BenchmarkGoodput struct {
+	TTFTMs *float64 `json:"ttft_ms,omitempty"`
+	TPOTMs *float64 `json:"tpot_ms,omitempty"`
+	// Attainment is the share of requests estimated to meet the SLO, from
+	// 0 to 1.
+	Attainment      float64  `json:"attainment"`
+	RequestRate     *float64 `json:"request_rate,omitempty"`
+	OutputTokenRate *float64 `json:"output_token_rate,omitempty"`
+}
+
+// goodputSLOsFromEnv reads BENCHMARK_GOODPUT_SLOS, falling back to the
+// defaults when it is unset or invalid.
+func goodputSLOsFromEnv() []GoodputSLO {
+	raw := strings.TrimSpace(os.Getenv(envGoodputSLOs))
+	if raw == "" {
+		return defaultGoodputSLOs
+	}
+	slos, err := parseGoodputSLOs(raw)
+	if err != nil {
+		slog.Warn("[benchmarks] invalid setting, using default", "key", envGoodputSLOs, "value", raw, "error", err)
+		return defaultGoodputSLOs
+	}
+	return slos
+}
+
+func parseGoodputSLOs(raw string) ([]GoodputSLO, error) {
+	var slos []GoodputSLO
+	for _, spec := range strings.Split(raw, ";") {
+		var slo GoodputSLO
+		for _, part := range strings.Split(spec, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				return nil, fmt.Errorf("%q is not name=duration", part)
+			}
+			d, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid duration %q", value)
+			}
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "ttft":
+				slo.TTFT = d
+			case "tpot":
+				slo.TPOT = d
+			default:
+				return nil, fmt.Errorf("unknown SLO %q", name)
+			}
+		}
+		slos = append(slos, slo)
+	}
+	return slos, nil
+}
+
+// prepareReport completes a parsed report: its accelerators are enriched
+// from the catalog, then its efficiency is derived.
+func (h *BenchmarkHandlers) prepareReport(r *BenchmarkReport) {
+	h.accelerators.enrich(r)
+	if e := deriveEfficiency(*r, h.goodputSLOs); e != nil {
+		if r.Extensions == nil {
+			r.Extensions = &BenchmarkExtensions{}
+		}
+		r.Extensions.Efficiency = e
+	}
+}
+
+// deriveEfficiency computes r's efficiency metrics, or nil when r has none
+// of their inputs.
+func deriveEfficiency(r BenchmarkReport, slos []GoodputSLO) *BenchmarkEfficiency {
+	agg := r.Results.RequestPerformance.Aggregate
+	e := &BenchmarkEfficiency{}
+	watts := 0
+	wattsKnown := true
+	for _, c := range r.Scenario.Stack {
+		a := c.Standardized.Accelerator
+		if a == nil {
+			continue
+		}
+		n := componentAccelerators(c)
+		e.Accelerators += n
+		if a.TDP == nil {
+			wattsKnown = false
+		} else {
+			watts += *a.TDP * n
+		}
+	}
+	perUnit := func(s *BenchmarkStatistics, units int) *float64 {
+		if s == nil || units <= 0 {
+			return nil
+		}
+		v := s.Mean / float64(units)
+		return &v
+	}
+	e.OutputTokenRatePerAccelerator = perUnit(agg.Throughput.OutputTokenRate, e.Accelerators)
+	e.TotalTokenRatePerAccelerator = perUnit(agg.Throughput.TotalTokenRate, e.Accelerators)
+	if wattsKnown {
+		e.OutputTokenRatePerWatt = perUnit(agg.Throughput.OutputTokenRate, watts)
+	}
+
+	for _, slo := range slos {
+		if g, ok := goodput(r, slo); ok {
+			e.Goodput = append(e.Goodput, g)
+		}
+	}
+	if e.Accelerators == 0 && len(e.Goodput) == 0 {
+		return nil
+	}
+	return e
+}
+
+// componentAccelerators is how many accelerators a stack component runs
+// on: its accelerator count times its replicas.
+func componentAccelerators(c BenchmarkStackComponent) int {
+	a := c.Standardized.Accelerator
+	if a == nil {
+		return 0
+	}
+	if c.Standardized.Replicas != nil && *c.Standardized.Replicas > 0 {
+		return a.Count * *c.Standardized.Replicas
+	}
+	return a.Count
+}
+
+// goodput estimates how many of r's requests meet slo, and the request and
+// output token rates they account for. Each bound is checked against its
+// latency percentiles; requests are assumed to meet both bounds as often
+// as they meet the stricter one, which makes the estimate an upper bound.
+// It is false when r lacks the percentiles of a bound.
+func goodput(r BenchmarkReport, slo GoodputSLO) (BenchmarkGoodput, bool) {
+	latency := r.Results.RequestPerformance.Aggregate.Latency
+	g := BenchmarkGoodput{Attainment: 1}
+	for _, bound := range []struct {
+		limit time.Duration
+		stats *BenchmarkStatistics
+		ms    **float64
+	}{
+		{slo.TTFT, latency.TimeToFirstToken, &g.TTFTMs},
+		{slo.TPOT, latency.TimePerOutputToken, &g.TPOTMs},
+	} {
+		if bound.limit <= 0 {
+			continue
+		}
+		ms := float64(bound.limit) / float64(time.Millisecond)
+		*bound.ms = &ms
+		share, ok := shareWithin(bound.stats, ms)
+		if !ok {
+			return BenchmarkGoodput{}, false
+		}
+		g.Attainment = min(g.Attainment, share)
+	}
+	if g.TTFTMs == nil && g.TPOTMs == nil {
+		return BenchmarkGoodput{}, false
+	}
+	throughput := r.Results.RequestPerformance.Aggregate.Throughput
+	if throughput.RequestRate != nil {
+		v := throughput.RequestRate.Mean * g.Attainment
+		g.RequestRate = &v
+	}
+	if throughput.OutputTokenRate != nil {
+		v := throughput.OutputTokenRate.Mean * g.Attainment
+		g.OutputTokenRate = &v
+	}
+	return g, true
+}
+
+// shareWithin estimates the share of requests whose latency is at most
+// limitMs, interpolating linearly between the percentiles of s. Below the
+// lowest known percentile the share counts as 0, and above the highest as
+// that percentile, unless it is the max. It is false when s has no
+// percentiles or units that are not a time.
+func shareWithin(s *BenchmarkStatistics, limitMs float64) (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	scale, ok := millisecondsPer(s.Units)
+	if !ok {
+		return 0, false
+	}
+	type point struct{ pct, ms float64 }
+	var points []point
+	for _, p := range []struct {
+		pct float64
+		v   *float64
+	}{
+		{0, s.Min}, {0.1, s.P0p1}, {1, s.P1}, {5, s.P5}, {10, s.P10}, {25, s.P25}, {50, s.P50},
+		{75, s.P75}, {90, s.P90}, {95, s.P95}, {99, s.P99}, {99.9, s.P99p9}, {100, s.Max},
+	} {
+		if p.v != nil {
+			points = append(points, point{p.pct, *p.v * scale})
+		}
+	}
+	if len(points) == 0 {
+		return 0, false
+	}
+	// Percentiles rise with latency; keep that order if rounding did not.
+	sort.SliceStable(points, func(i, j int) bool { return points[i].ms < points[j].ms })
+
+	if limitMs < points[0].ms {
+		return 0, true
+	}
+	for i := 1; i < len(points); i++ {
+		lo, hi := points[i-1], points[i]
+		if limitMs < hi.ms {
+			frac := (limitMs - lo.ms) / (hi.ms - lo.ms)
+			return (lo.pct + frac*(hi.pct-lo.pct)) / 100, true
+		}
+	}
+	return points[len(points)-1].pct / 100, true
+}
+
+// millisecondsPer returns how many milliseconds one unit of a latency is.
+func millisecondsPer(units string) (float64, bool) {
+	switch strings.ToLower(strings.TrimSpace(units)) {
+	case "ms", "msec", "milliseconds":
+		return 1, true
+	case "s", "sec", "seconds":
+		return 1000, true
+	case "us", "µs", "usec", "microseconds":
+		return 0.001, true
+	case "ns", "nanoseconds":
+		return 0.000001, true
+	}
+	return 0, false
+}
@@ -0,0 +1,97 @@
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// efficiencyReport is an H100 report with 4 accelerators, a TTFT
+// distribution in ms and a TPOT distribution in seconds.
+func efficiencyReport() BenchmarkReport {
+	f := func(v float64) *float64 { return &v }
+	r := searchReport("llama", "r1", "meta-llama/Llama-3.1-8B", "NVIDIA-H100-80GB-HBM3", 10, 1)
+	r.Scenario.Stack[0].Standardized.Accelerator.Count = 2
+	replicas := 2
+	r.Scenario.Stack[0].Standardized.Replicas = &replicas
+	agg := &r.Results.RequestPerformance.Aggregate
+	agg.Throughput.OutputTokenRate = &BenchmarkStatistics{Units: "tokens/s", Mean: 2000}
+	agg.Throughput.RequestRate = &BenchmarkStatistics{Units: "requests/s", Mean: 10}
+	agg.Latency.TimeToFirstToken = &BenchmarkStatistics{Units: "ms", Mean: 400, P50: f(300), P90: f(700), P99: f(1500)}
+	agg.Latency.TimePerOutputToken = &BenchmarkStatistics{Units: "s", Mean: 0.03, Min: f(0.01), P50: f(0.03), Max: f(0.06)}
+	return r
+}
+
+func TestPrepareReport_Efficiency(t *testing.T) {
+	h := NewBenchmarkHandlers("", "")
+	h.goodputSLOs = []GoodputSLO{
+		{TTFT: 500 * time.Millisecond, TPOT: 50 * time.Millisecond},
+		{TTFT: 2 * time.Second},
+	}
+	r := efficiencyReport()
+	h.prepareReport(&r)
+
+	require.NotNil(t, r.Extensions)
+	e := r.Extensions.Efficiency
+	require.NotNil(t, e)
+	assert.Equal(t, 4, e.Accelerators)
+	require.NotNil(t, e.OutputTokenRatePerAccelerator)
+	assert.InDelta(t, 500, *e.OutputTokenRatePerAccelerator, 1e-9)
+	assert.Nil(t, e.TotalTokenRatePerAccelerator, "the report has no total token rate")
+	require.NotNil(t, e.OutputTokenRatePerWatt, "the catalog knows the H100's TDP")
+	assert.InDelta(t, 2000.0/(4*700), *e.OutputTokenRatePerWatt, 1e-9)
+
+	require.Len(t, e.Goodput, 2)
+	// TTFT 500ms is halfway from p50 to p90: 70%. TPOT 50ms is two thirds
+	// from p50 to the max: 83%. The stricter one wins.
+	strict := e.Goodput[0]
+	assert.InDelta(t, 0.7, strict.Attainment, 1e-9)
+	assert.InDelta(t, 7, *strict.RequestRate, 1e-9)
+	assert.InDelta(t, 1400, *strict.OutputTokenRate, 1e-9)
+	assert.Equal(t, 50.0, *strict.TPOTMs)
+	// 2s is above the p99 but the max is unknown, so only 99% count.
+	relaxed := e.Goodput[1]
+	assert.InDelta(t, 0.99, relaxed.Attainment, 1e-9)
+	assert.Nil(t, relaxed.TPOTMs)
+}
+
+func TestDeriveEfficiency_MissingInputs(t *testing.T) {
+	assert.Nil(t, deriveEfficiency(retentionReport("bare", "r1", time.Now()), defaultGoodputSLOs))
+
+	r := efficiencyReport()
+	r.Results.RequestPerformance.Aggregate.Latency.TimePerOutputToken.Units = "tokens"
+	e := deriveEfficiency(r, defaultGoodputSLOs)
+	require.NotNil(t, e)
+	assert.Empty(t, e.Goodput, "a TPOT without time units cannot be checked")
+	assert.Nil(t, e.OutputTokenRatePerWatt, "no TDP before enrichment")
+}
+
+func TestShareWithin(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	s := &BenchmarkStatistics{Units: "ms", Min: f(100), P50: f(200), Max: f(400)}
+	for _, tt := range []struct{ limit, want float64 }{
+		{50, 0}, {100, 0}, {150, 0.25}, {200, 0.5}, {300, 0.75}, {400, 1}, {1000, 1},
+	} {
+		got, ok := shareWithin(s, tt.limit)
+		require.True(t, ok)
+		assert.InDelta(t, tt.want, got, 1e-9, "limit %v", tt.limit)
+	}
+	_, ok := shareWithin(&BenchmarkStatistics{Units: "ms", Mean: 10}, 100)
+	assert.False(t, ok, "the mean alone is no distribution")
+}
+
+func TestParseGoodputSLOs(t *testing.T) {
+	slos, err := parseGoodputSLOs("ttft=500ms,tpot=50ms; TTFT=2s")
+	require.NoError(t, err)
+	assert.Equal(t, []GoodputSLO{
+		{TTFT: 500 * time.Millisecond, TPOT: 50 * time.Millisecond},
+		{TTFT: 2 * time.Second},
+	}, slos)
+
+	for _, raw := range []string{"ttft", "ttft=fast", "ttft=-1s", "itl=5ms"} {
+		_, err := parseGoodputSLOs(raw)
+		assert.Error(t, err, raw)
+	}
+}
@@ -0,0 +1,51 @@
+package benchmarks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// etagBytes is how much of the SHA-256 of a response its ETag keeps.
+	etagBytes = 16
+	// benchmarkCacheControl lets browsers keep benchmark responses but makes
+	// them revalidate every time, which costs a 304 when nothing changed.
+	// The responses need a session, so shared caches must not keep them.
+	benchmarkCacheControl = "private, no-cache"
+)
+
+// sendConditional sends body as JSON with an ETag of its content. A request
+// whose If-None-Match lists that ETag gets 304 Not Modified without a body.
+// The ETag changes with anything in the body: the cached reports, their
+// annotations, and the query.
+func sendConditional(c *fiber.Ctx, body fiber.Map) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:etagBytes]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, benchmarkCacheControl)
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(data)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 asks for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
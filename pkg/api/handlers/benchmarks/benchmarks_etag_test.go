@@ -0,0 +1,62 @@
+package benchmarks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReports_ETag(t *testing.T) {
+	h := NewBenchmarkHandlers("api-key", "folder")
+	h.cache.set([]BenchmarkReport{retentionReport("llama", "r1", time.Now())}, "0")
+	app := fiber.New()
+	app.Get("/reports", h.GetReports)
+
+	get := func(url, ifNoneMatch string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := get("/reports", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	require.NotEmpty(t, etag)
+	assert.Equal(t, benchmarkCacheControl, resp.Header.Get(fiber.HeaderCacheControl))
+
+	resp = get("/reports", etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Empty(t, body)
+	assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+
+	resp = get("/reports", `"stale", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode, "weak and listed tags match")
+
+	resp = get("/reports?experiment=other", etag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "another query is another body")
+
+	h.cache.merge([]BenchmarkReport{retentionReport("llama", "r2", time.Now())})
+	resp = get("/reports", etag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "new reports change the tag")
+	assert.NotEqual(t, etag, resp.Header.Get(fiber.HeaderETag))
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"a"`, `"a"`))
+	assert.True(t, etagMatches(`W/"a"`, `"a"`))
+	assert.True(t, etagMatches(`"b", "a"`, `"a"`))
+	assert.True(t, etagMatches(`*`, `"a"`))
+	assert.False(t, etagMatches(``, `"a"`))
+	assert.False(t, etagMatches(`"b"`, `"a"`))
+}
@@ -0,0 +1,156 @@
+package benchmarks
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+const (
+	// maxFetchConcurrency caps BENCHMARK_FETCH_CONCURRENCY. Every worker
+	// still waits on the per-host throttle, so more workers than this only
+	// queue up behind it.
+	maxFetchConcurrency = 64
+
+	envFetchConcurrency = "BENCHMARK_FETCH_CONCURRENCY"
+)
+
+// fetchConcurrencyFromEnv reads BENCHMARK_FETCH_CONCURRENCY. "0" and
+// invalid values fall back to driveFetchConcurrency.
+func fetchConcurrencyFromEnv() int {
+	n := envNonNegativeInt(envFetchConcurrency, driveFetchConcurrency)
+	if n == 0 {
+		return driveFetchConcurrency
+	}
+	return min(n, maxFetchConcurrency)
+}
+
+// fetchWorkers is how many experiment folders, and separately how many run
+// folders, are fetched at once.
+func (h *BenchmarkHandlers) fetchWorkers() int {
+	if h.fetchConcurrency > 0 {
+		return h.fetchConcurrency
+	}
+	return driveFetchConcurrency
+}
+
+// folderWalk configures walkRunFolders.
+type folderWalk struct {
+	// cutoff skips run folders created before it; zero skips none.
+	cutoff time.Time
+	// fetch downloads one run folder. It is called from several workers at
+	// once.
+	fetch func(ctx context.Context, experiment, run SourceEntry)
+	// onSkip, if set, is called for each run folder older than cutoff.
+	onSkip func()
+	// onExperiment, if set, is called once an experiment folder has been
+	// listed and all of its run folders fetched.
+	onExperiment func(experiment SourceEntry)
+}
+
+// runFolderJob is a run folder queued for the fetch workers. done is called
+// once it has been fetched or dropped.
+type runFolderJob struct {
+	experiment SourceEntry
+	run        SourceEntry
+	done       func()
+}
+
+// walkRunFolders lists the run folders of experiments and fetches them on
+// two pools of fetchWorkers workers: one lists experiment folders and queues
+// their run folders, the other drains the queue. Once ctx is done no more
+// folders are listed or fetched and queued run folders are dropped, so a
+// cancelled walk returns as soon as the requests in flight do.
+func (h *BenchmarkHandlers) walkRunFolders(ctx context.Context, experiments []SourceEntry, w folderWalk) {
+	source := h.reportSource()
+	workers := h.fetchWorkers()
+	experimentJobs := make(chan SourceEntry)
+	runJobs := make(chan runFolderJob)
+
+	var fetchers sync.WaitGroup
+	for range workers {
+		fetchers.Add(1)
+		safego.Go(func() {
+			defer fetchers.Done()
+			for job := range runJobs {
+				if ctx.Err() == nil {
+					w.fetch(ctx, job.experiment, job.run)
+				}
+				job.done()
+			}
+		})
+	}
+
+	var listers sync.WaitGroup
+	for range workers {
+		listers.Add(1)
+		safego.Go(func() {
+			defer listers.Done()
+			for experiment := range experimentJobs {
+				h.queueRunFolders(ctx, source, experiment, w, runJobs)
+			}
+		})
+	}
+
+queue:
+	for _, experiment := range experiments {
+		select {
+		case experimentJobs <- experiment:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(experimentJobs)
+	listers.Wait()
+	close(runJobs)
+	fetchers.Wait()
+}
+
+// queueRunFolders lists experiment and queues its run folders on runJobs.
+func (h *BenchmarkHandlers) queueRunFolders(ctx context.Context, source BenchmarkSource, experiment SourceEntry, w folderWalk, runJobs chan<- runFolderJob) {
+	// pending counts the queued run folders plus one for the listing, so
+	// onExperiment runs after whichever finishes last.
+	var pending atomic.Int64
+	pending.Store(1)
+	done := func() {
+		if pending.Add(-1) == 0 && w.onExperiment != nil {
+			w.onExperiment(experiment)
+		}
+	}
+	defer done()
+
+	if ctx.Err() != nil {
+		return
+	}
+	runFolders, err := source.List(ctx, experiment.ID)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("[benchmarks] error listing experiment", "experiment", experiment.Name, "error", err)
+		}
+		return
+	}
+	for _, run := range runFolders {
+		if ctx.Err() != nil {
+			return
+		}
+		if !run.Folder {
+			continue
+		}
+		if !isAfterCutoff(run, w.cutoff) {
+			if w.onSkip != nil {
+				w.onSkip()
+			}
+			continue
+		}
+		pending.Add(1)
+		select {
+		case runJobs <- runFolderJob{experiment: experiment, run: run, done: done}:
+		case <-ctx.Done():
+			done()
+		}
+	}
+}
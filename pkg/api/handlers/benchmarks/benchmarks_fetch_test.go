@@ -0,0 +1,153 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// treeSource is a BenchmarkSource over folders in memory. Listing a run
+// folder takes delay, and block, when set, holds every listing until it is
+// closed or the context is done.
+type treeSource struct {
+	folders map[string][]SourceEntry
+	delay   time.Duration
+	block   chan struct{}
+
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64
+}
+
+func (s *treeSource) Name() string { return "tree" }
+
+func (s *treeSource) List(ctx context.Context, folderID string) ([]SourceEntry, error) {
+	if s.block != nil && folderID != "" {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if len(folderID) > 4 && folderID[:4] == "run:" {
+		n := s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		for {
+			peak := s.maxInFlight.Load()
+			if n <= peak || s.maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(s.delay)
+	}
+	return s.folders[folderID], nil
+}
+
+func (s *treeSource) Read(context.Context, string) ([]byte, error) {
+	return nil, fmt.Errorf("no files")
+}
+
+// newTreeSource has experiments exp-0..exp-N, each with runs run-0..run-M.
+// Run folders are empty.
+func newTreeSource(experiments, runs int) *treeSource {
+	s := &treeSource{folders: map[string][]SourceEntry{}, delay: 10 * time.Millisecond}
+	for e := range experiments {
+		exp := SourceEntry{ID: fmt.Sprintf("exp:%d", e), Name: fmt.Sprintf("exp-%d", e), Folder: true}
+		s.folders[""] = append(s.folders[""], exp)
+		for r := range runs {
+			s.folders[exp.ID] = append(s.folders[exp.ID], SourceEntry{ID: fmt.Sprintf("run:%d/%d", e, r), Name: fmt.Sprintf("run-%d", r), Folder: true})
+		}
+	}
+	return s
+}
+
+func TestWalkRunFolders(t *testing.T) {
+	src := newTreeSource(3, 6)
+	src.folders["exp:1"] = append(src.folders["exp:1"],
+		SourceEntry{ID: "run:old", Name: "old", Folder: true, CreatedTime: "2020-01-01T00:00:00Z"},
+		SourceEntry{ID: "notes.txt", Name: "notes.txt"})
+	h := &BenchmarkHandlers{source: src, fetchConcurrency: 4}
+
+	var mu sync.Mutex
+	fetched := map[string]bool{}
+	finished := map[string]int{}
+	var skipped atomic.Int64
+	h.walkRunFolders(context.Background(), src.folders[""], folderWalk{
+		cutoff: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		fetch: func(ctx context.Context, experiment, run SourceEntry) {
+			_, _, err := h.fetchRunFolder(ctx, run.ID, experiment.Name, run.Name)
+			assert.NoError(t, err)
+			mu.Lock()
+			defer mu.Unlock()
+			fetched[experiment.Name+"/"+run.Name] = true
+		},
+		onSkip: func() { skipped.Add(1) },
+		onExperiment: func(experiment SourceEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+			for r := range 6 {
+				assert.True(t, fetched[fmt.Sprintf("%s/run-%d", experiment.Name, r)], "run %d of %s fetched first", r, experiment.Name)
+			}
+			finished[experiment.Name]++
+		},
+	})
+
+	assert.Len(t, fetched, 18)
+	assert.Equal(t, map[string]int{"exp-0": 1, "exp-1": 1, "exp-2": 1}, finished)
+	assert.Equal(t, int64(1), skipped.Load())
+	assert.Equal(t, int64(4), src.maxInFlight.Load(), "run folders are fetched fetchConcurrency at a time")
+}
+
+func TestFetchAllReports_Cancelled(t *testing.T) {
+	src := newTreeSource(20, 20)
+	src.block = make(chan struct{})
+	h := &BenchmarkHandlers{source: src, fetchConcurrency: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := h.fetchAllReports(ctx, time.Time{})
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled, "a cancelled fetch is not a complete result")
+	case <-time.After(2 * time.Second):
+		t.Fatal("fetchAllReports did not return after cancellation")
+	}
+}
+
+func TestThrottle_PerHost(t *testing.T) {
+	h := &BenchmarkHandlers{}
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, h.throttle(ctx, "www.googleapis.com"))
+	require.NoError(t, h.throttle(ctx, "drive.google.com"))
+	assert.Less(t, time.Since(start), driveRequestDelay, "hosts are throttled separately")
+
+	require.NoError(t, h.throttle(ctx, "drive.google.com"))
+	assert.GreaterOrEqual(t, time.Since(start), driveRequestDelay)
+}
+
+func TestFetchConcurrencyFromEnv(t *testing.T) {
+	for raw, want := range map[string]int{
+		"":     driveFetchConcurrency,
+		"0":    driveFetchConcurrency,
+		"-3":   driveFetchConcurrency,
+		"many": driveFetchConcurrency,
+		"16":   16,
+		"1000": maxFetchConcurrency,
+	} {
+		t.Setenv(envFetchConcurrency, raw)
+		assert.Equal(t, want, fetchConcurrencyFromEnv(), raw)
+	}
+}
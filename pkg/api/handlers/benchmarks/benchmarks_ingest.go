@@ -0,0 +1,191 @@
+package benchmarks
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BenchmarksAddedType is the WebSocket message type sent when the webhook
+// ingests a run.
+const BenchmarksAddedType = "benchmarks_added"
+
+// ingestTimeout bounds fetching one run folder for the webhook.
+const ingestTimeout = 2 * time.Minute
+
+// errRunNotFound is returned by findRunFolder when the source has no such
+// experiment or run folder.
+var errRunNotFound = errors.New("run folder not found")
+
+// BenchmarksAdded is the data of a BenchmarksAddedType message.
+type BenchmarksAdded struct {
+	Experiment string   `json:"experiment"`
+	Run        string   `json:"run"`
+	RunUIDs    []string `json:"run_uids"`
+}
+
+// ingestRequest is the body of POST /api/benchmarks/webhook: a run and
+// either its report or nothing, in which case the run folder is read from
+// the source.
+type ingestRequest struct {
+	Experiment string `json:"experiment"`
+	Run        string `json:"run"`
+	// Report is a report as a JSON object, or the content of a report file
+	// as a JSON string.
+	Report json.RawMessage `json:"report,omitempty"`
+	// FolderID picks the run folder by its source ID, such as a Drive folder
+	// ID or an object key prefix, rather than by Run.
+	FolderID string `json:"folder_id,omitempty"`
+}
+
+// ingestWebhook authenticates and announces webhook ingestion.
+type ingestWebhook struct {
+	token     string
+	broadcast func(BenchmarksAdded)
+}
+
+// SetIngestWebhook enables POST /api/benchmarks/webhook for callers that
+// send token as a bearer token. Each ingested run is passed to broadcast.
+// An empty token (the default) leaves the webhook disabled.
+func (h *BenchmarkHandlers) SetIngestWebhook(token string, broadcast func(BenchmarksAdded)) {
+	if token == "" {
+		h.ingest = nil
+		return
+	}
+	h.ingest = &ingestWebhook{token: token, broadcast: broadcast}
+}
+
+// IngestWebhook adds one run to the cache without waiting for the next full
+// fetch. CI jobs call it with a bearer token rather than a console session.
+// The body carries either the run's report, or only the run, whose folder
+// is then read from the source. Cached reports with the same run UID are
+// replaced.
+// POST /api/benchmarks/webhook
+func (h *BenchmarkHandlers) IngestWebhook(c *fiber.Ctx) error {
+	if h.ingest == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "benchmark webhook not configured — set BENCHMARK_WEBHOOK_TOKEN"})
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+h.ingest.token)) != 1 {
+		slog.Warn("[benchmarks] webhook rejected: invalid token", "ip", c.IP())
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid benchmark webhook token")
+	}
+
+	var req ingestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	var (
+		reports       []BenchmarkReport
+		parseFailures int
+	)
+	if len(req.Report) > 0 {
+		data := []byte(req.Report)
+		var text string
+		if json.Unmarshal(data, &text) == nil {
+			data = []byte(text)
+		}
+		file := SourceEntry{Name: benchmarkFilePrefix + benchmarkFileSuffix, CreatedTime: time.Now().UTC().Format(time.RFC3339)}
+		report, err := parseReport(data, file, req.Experiment, req.Run)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid report: "+err.Error())
+		}
+		h.prepareReport(&report)
+		reports = []BenchmarkReport{report}
+	} else {
+		if !h.configured() {
+			return driveUnavailable(c)
+		}
+		ctx, cancel := context.WithTimeout(c.UserContext(), ingestTimeout)
+		defer cancel()
+		run, err := h.findRunFolder(ctx, req.Experiment, req.Run, req.FolderID)
+		if errors.Is(err, errRunNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "run folder not found in " + h.reportSource().Name()})
+		}
+		if err == nil {
+			req.Run = run.Name
+			reports, parseFailures, err = h.fetchRunFolder(ctx, run.ID, req.Experiment, run.Name)
+		}
+		if err != nil {
+			slog.Error("[benchmarks] webhook fetch failed", "experiment", req.Experiment, "run", req.Run, "error", err)
+			return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "failed to fetch benchmark run"})
+		}
+		if len(reports) == 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "run folder has no readable reports", "parse_failures": parseFailures})
+		}
+	}
+
+	h.alerts.check(h.cache.merge(reports))
+	added := BenchmarksAdded{Experiment: req.Experiment, Run: req.Run, RunUIDs: make([]string, 0, len(reports))}
+	for _, r := range reports {
+		added.RunUIDs = append(added.RunUIDs, r.Run.UID)
+	}
+	slog.Info("[benchmarks] webhook ingested run", "experiment", added.Experiment, "run", added.Run, "reports", len(reports), "parseFailures", parseFailures)
+	if h.ingest.broadcast != nil {
+		h.ingest.broadcast(added)
+	}
+	return c.JSON(fiber.Map{
+		"experiment":     added.Experiment,
+		"run":            added.Run,
+		"run_uids":       added.RunUIDs,
+		"parse_failures": parseFailures,
+	})
+}
+
+// validate checks the names of an ingest request. Run.EID is
+// "<experiment>/<run>", so neither may contain a slash.
+func (req *ingestRequest) validate() error {
+	req.Experiment = strings.TrimSpace(req.Experiment)
+	req.Run = strings.TrimSpace(req.Run)
+	req.FolderID = strings.TrimSpace(req.FolderID)
+	switch {
+	case req.Experiment == "":
+		return fiber.NewError(fiber.StatusBadRequest, "experiment is required")
+	case req.Run == "" && (len(req.Report) > 0 || req.FolderID == ""):
+		return fiber.NewError(fiber.StatusBadRequest, "run is required")
+	case strings.Contains(req.Experiment, "/") || strings.Contains(req.Run, "/"):
+		return fiber.NewError(fiber.StatusBadRequest, "experiment and run must not contain '/'")
+	case len(req.Report) > 0 && req.FolderID != "":
+		return fiber.NewError(fiber.StatusBadRequest, "report and folder_id are mutually exclusive")
+	}
+	return nil
+}
+
+// findRunFolder returns the run folder of experiment in the source: the one
+// with ID folderID or, without one, the one named run. The experiment is
+// looked up by name among the top-level folders, so a folder ID outside it
+// is not found.
+func (h *BenchmarkHandlers) findRunFolder(ctx context.Context, experiment, run, folderID string) (SourceEntry, error) {
+	source := h.reportSource()
+	topLevel, err := source.List(ctx, "")
+	if err != nil {
+		return SourceEntry{}, err
+	}
+	for _, e := range topLevel {
+		if !e.Folder || e.Name != experiment {
+			continue
+		}
+		runs, err := source.List(ctx, e.ID)
+		if err != nil {
+			return SourceEntry{}, err
+		}
+		for _, r := range runs {
+			if !r.Folder {
+				continue
+			}
+			if (folderID != "" && r.ID == folderID) || (folderID == "" && r.Name == run) {
+				return r, nil
+			}
+		}
+	}
+	return SourceEntry{}, errRunNotFound
+}
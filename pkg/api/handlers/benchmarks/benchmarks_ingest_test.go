@@ -0,0 +1,128 @@
+package benchmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebhookToken = "ci-token" // #nosec G101 -- test fixture
+
+func newIngestTestApp(t *testing.T, h *BenchmarkHandlers) (*fiber.App, *[]BenchmarksAdded) {
+	t.Helper()
+	var added []BenchmarksAdded
+	h.SetIngestWebhook(testWebhookToken, func(a BenchmarksAdded) { added = append(added, a) })
+	app := fiber.New()
+	app.Post("/webhook", h.IngestWebhook)
+	return app, &added
+}
+
+func postIngest(t *testing.T, app *fiber.App, token string, body any) *http.Response {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestIngestWebhook_InlineReport(t *testing.T) {
+	h := NewBenchmarkHandlers("", "")
+	h.cache.set([]BenchmarkReport{retentionReport("other", "r1", time.Now())}, "0")
+	app, added := newIngestTestApp(t, h)
+
+	resp := postIngest(t, app, testWebhookToken, map[string]any{
+		"experiment": "llama", "run": "r2", "report": validBenchmarkYAML,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, *added, 1)
+	assert.Equal(t, "llama", (*added)[0].Experiment)
+	require.Len(t, (*added)[0].RunUIDs, 1)
+
+	h.cache.mu.RLock()
+	cached := len(h.cache.reports)
+	h.cache.mu.RUnlock()
+	assert.Equal(t, 2, cached, "the run is added to the cached reports")
+
+	// Posting the same run again replaces it.
+	resp = postIngest(t, app, testWebhookToken, map[string]any{
+		"experiment": "llama", "run": "r2", "report": validBenchmarkYAML,
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	h.cache.mu.RLock()
+	cached = len(h.cache.reports)
+	h.cache.mu.RUnlock()
+	assert.Equal(t, 2, cached)
+}
+
+func TestIngestWebhook_FromSource(t *testing.T) {
+	dir := t.TempDir()
+	writeReportTree(t, dir,
+		"exp-a/run-1/benchmark_report_1.yaml",
+		"exp-a/run-2/results/result-0/benchmark_report_1.yaml",
+	)
+	src, err := NewDirSource(dir)
+	require.NoError(t, err)
+	h := NewBenchmarkHandlers("", "")
+	h.SetSource(src)
+	app, added := newIngestTestApp(t, h)
+
+	resp := postIngest(t, app, testWebhookToken, map[string]any{"experiment": "exp-a", "run": "run-2"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, *added, 1)
+	assert.Equal(t, "run-2", (*added)[0].Run)
+
+	resp = postIngest(t, app, testWebhookToken, map[string]any{"experiment": "exp-a", "folder_id": "exp-a/run-1"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, *added, 2)
+	assert.Equal(t, "run-1", (*added)[1].Run, "the run name comes from the folder")
+
+	for _, body := range []map[string]any{
+		{"experiment": "exp-a", "run": "run-9"},
+		{"experiment": "exp-b", "run": "run-1"},
+		{"experiment": "exp-b", "folder_id": "exp-a/run-1"},
+	} {
+		resp = postIngest(t, app, testWebhookToken, body)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, "%v", body)
+	}
+}
+
+func TestIngestWebhook_Rejects(t *testing.T) {
+	h := NewBenchmarkHandlers("", "")
+	app := fiber.New()
+	app.Post("/webhook", h.IngestWebhook)
+	resp := postIngest(t, app, testWebhookToken, map[string]any{"experiment": "e", "run": "r"})
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "disabled without a token")
+
+	app, added := newIngestTestApp(t, h)
+	resp = postIngest(t, app, "wrong", map[string]any{"experiment": "e", "run": "r"})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp = postIngest(t, app, "", map[string]any{"experiment": "e", "run": "r"})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	for _, body := range []map[string]any{
+		{"run": "r", "report": validBenchmarkYAML},
+		{"experiment": "e", "report": validBenchmarkYAML},
+		{"experiment": "e/x", "run": "r", "report": validBenchmarkYAML},
+		{"experiment": "e", "run": "r", "report": validBenchmarkYAML, "folder_id": "e/r"},
+		{"experiment": "e", "run": "r", "report": "version: \"9\""},
+	} {
+		resp = postIngest(t, app, testWebhookToken, body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "%v", body)
+	}
+	resp = postIngest(t, app, testWebhookToken, map[string]any{"experiment": "e", "run": "r"})
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "no source to read the run from")
+	assert.Empty(t, *added)
+}
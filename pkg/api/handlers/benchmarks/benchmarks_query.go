@@ -0,0 +1,264 @@
+package benchmarks
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// BenchmarkReportQuery filters and aggregates the reports GetReports
+// returns. Zero values do not filter.
+type BenchmarkReportQuery struct {
+	// Fields maps a structured search field to the value a report must
+	// have, compared case-insensitively.
+	Fields         map[string]string
+	ConcurrencyMin *int
+	ConcurrencyMax *int
+	// Run is a run UID, EID or the run part of an EID.
+	Run string
+	// GroupBy is the structured field reports are aggregated by, or empty
+	// to return the reports themselves.
+	GroupBy string
+}
+
+// parseReportQuery reads the GetReports filter and aggregation parameters.
+func parseReportQuery(c *fiber.Ctx) (BenchmarkReportQuery, error) {
+	q := BenchmarkReportQuery{
+		Fields:  map[string]string{},
+		Run:     strings.TrimSpace(c.Query("run")),
+		GroupBy: strings.TrimSpace(c.Query("group_by")),
+	}
+	for _, f := range searchFields {
+		if v := strings.TrimSpace(c.Query(f)); v != "" {
+			q.Fields[f] = v
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **int
+	}{{"concurrency_min", &q.ConcurrencyMin}, {"concurrency_max", &q.ConcurrencyMax}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return q, fiber.NewError(fiber.StatusBadRequest, "invalid "+p.name)
+		}
+		*p.dst = &v
+	}
+	if q.ConcurrencyMin != nil && q.ConcurrencyMax != nil && *q.ConcurrencyMin > *q.ConcurrencyMax {
+		return q, fiber.NewError(fiber.StatusBadRequest, "concurrency_min is above concurrency_max")
+	}
+	if q.GroupBy != "" && !slices.Contains(searchFields, q.GroupBy) {
+		return q, fiber.NewError(fiber.StatusBadRequest, "invalid group_by")
+	}
+	return q, nil
+}
+
+// reportFieldValues returns the values report has for a structured search
+// field: its experiment, or the tools, models or accelerators of its load
+// generator and stack. Empty values are left out.
+func reportFieldValues(r BenchmarkReport, field string) []string {
+	var values []string
+	add := func(v string) {
+		if v = strings.TrimSpace(v); v != "" && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	switch field {
+	case searchFieldExperiment:
+		add(reportExperiment(r))
+	case searchFieldTool:
+		add(r.Scenario.Load.Standardized.Tool)
+	}
+	for _, c := range r.Scenario.Stack {
+		s := c.Standardized
+		switch {
+		case field == searchFieldTool:
+			add(s.Tool)
+		case field == searchFieldModel && s.Model != nil:
+			add(s.Model.Name)
+		case field == searchFieldAccelerator && s.Accelerator != nil:
+			add(s.Accelerator.Model)
+		}
+	}
+	return values
+}
+
+// matches reports whether r passes every filter of q. A concurrency bound
+// excludes reports whose load has no concurrency.
+func (q BenchmarkReportQuery) matches(r BenchmarkReport) bool {
+	for field, want := range q.Fields {
+		if !slices.ContainsFunc(reportFieldValues(r, field), func(v string) bool {
+			return strings.EqualFold(v, want)
+		}) {
+			return false
+		}
+	}
+	if q.Run != "" {
+		_, run, _ := strings.Cut(r.Run.EID, "/")
+		if q.Run != r.Run.UID && q.Run != r.Run.EID && q.Run != run {
+			return false
+		}
+	}
+	if q.ConcurrencyMin != nil || q.ConcurrencyMax != nil {
+		n := r.Scenario.Load.Standardized.Concurrency
+		if n == nil {
+			return false
+		}
+		if (q.ConcurrencyMin != nil && *n < *q.ConcurrencyMin) || (q.ConcurrencyMax != nil && *n > *q.ConcurrencyMax) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the reports that match q, in order.
+func (q BenchmarkReportQuery) filter(reports []BenchmarkReport) []BenchmarkReport {
+	if len(q.Fields) == 0 && q.Run == "" && q.ConcurrencyMin == nil && q.ConcurrencyMax == nil {
+		return reports
+	}
+	kept := make([]BenchmarkReport, 0, len(reports))
+	for _, r := range reports {
+		if q.matches(r) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// BenchmarkMetricSummary summarizes one metric over the runs of a group,
+// from each run's mean.
+type BenchmarkMetricSummary struct {
+	Units string  `json:"units"`
+	Runs  int     `json:"runs"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P99   float64 `json:"p99"`
+}
+
+// BenchmarkReportGroup is the aggregate of the reports sharing a value of
+// the group_by field. A metric no run in the group reports is nil.
+type BenchmarkReportGroup struct {
+	Value           string                  `json:"value"`
+	Runs            int                     `json:"runs"`
+	UIDs            []string                `json:"uids"`
+	TTFT            *BenchmarkMetricSummary `json:"ttft"`
+	OutputTokenRate *BenchmarkMetricSummary `json:"output_token_rate"`
+	RequestRate     *BenchmarkMetricSummary `json:"request_rate"`
+	// OutputTokensPerDollar is the output token rate per hour of
+	// accelerator cost, for runs whose accelerators all have a cost.
+	OutputTokensPerDollar *BenchmarkMetricSummary `json:"output_tokens_per_dollar"`
+}
+
+// groupReports aggregates reports by field, in order of value. A report
+// with several values, such as a stack serving two models, counts in the
+// group of each; reports without any are grouped under an empty value.
+func groupReports(reports []BenchmarkReport, field string) []BenchmarkReportGroup {
+	type pending struct {
+		value                  string
+		uids                   []string
+		ttft, output, requests []*BenchmarkStatistics
+		perDollar              []*BenchmarkStatistics
+	}
+	byKey := map[string]*pending{}
+	for _, r := range reports {
+		values := reportFieldValues(r, field)
+		if len(values) == 0 {
+			values = []string{""}
+		}
+		seen := map[string]bool{}
+		for _, v := range values {
+			key := strings.ToLower(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			g, ok := byKey[key]
+			if !ok {
+				g = &pending{value: v}
+				byKey[key] = g
+			}
+			agg := r.Results.RequestPerformance.Aggregate
+			g.uids = append(g.uids, r.Run.UID)
+			g.ttft = append(g.ttft, agg.Latency.TimeToFirstToken)
+			g.output = append(g.output, agg.Throughput.OutputTokenRate)
+			g.requests = append(g.requests, agg.Throughput.RequestRate)
+			g.perDollar = append(g.perDollar, outputTokensPerDollar(r))
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for k := range byKey {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	groups := make([]BenchmarkReportGroup, 0, len(keys))
+	for _, k := range keys {
+		g := byKey[k]
+		groups = append(groups, BenchmarkReportGroup{
+			Value:                 g.value,
+			Runs:                  len(g.uids),
+			UIDs:                  g.uids,
+			TTFT:                  summarizeMetric(g.ttft),
+			OutputTokenRate:       summarizeMetric(g.output),
+			RequestRate:           summarizeMetric(g.requests),
+			OutputTokensPerDollar: summarizeMetric(g.perDollar),
+		})
+	}
+	return groups
+}
+
+// summarizeMetric returns the mean, p50 and p99 of the runs' means, skipping
+// runs without the metric, or nil when none has it. Percentiles interpolate
+// linearly between the closest runs.
+func summarizeMetric(stats []*BenchmarkStatistics) *BenchmarkMetricSummary {
+	var values []float64
+	units := ""
+	for _, s := range stats {
+		if s == nil || math.IsNaN(s.Mean) || math.IsInf(s.Mean, 0) {
+			continue
+		}
+		if units == "" {
+			units = s.Units
+		}
+		values = append(values, s.Mean)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	slices.Sort(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return &BenchmarkMetricSummary{
+		Units: units,
+		Runs:  len(values),
+		Mean:  sum / float64(len(values)),
+		P50:   percentile(values, 50),
+		P99:   percentile(values, 99),
+	}
+}
+
+// percentile returns the p-th percentile of sorted, which is not empty.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// queryResponse is the GetReports body for the reports matching q: the
+// reports with their annotations, or their groups when q groups them.
+func queryResponse(reports []BenchmarkReport, annotations map[string]models.BenchmarkAnnotation, q BenchmarkReportQuery, source string) fiber.Map {
+	if q.GroupBy != "" {
+		return fiber.Map{"groups": groupReports(reports, q.GroupBy), "group_by": q.GroupBy, "total": len(reports), "source": source}
+	}
+	return fiber.Map{"reports": reports, "annotations": annotations, "source": source}
+}
@@ -0,0 +1,127 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryFixtures are searchFixtures with load concurrency and TTFT and
+// output token rate means set.
+func queryFixtures() []BenchmarkReport {
+	reports := searchFixtures()
+	for i, m := range []struct {
+		concurrency  int
+		ttft, output float64
+	}{{8, 100, 900}, {32, 300, 1500}, {16, 50, 600}} {
+		n := m.concurrency
+		r := &reports[i]
+		r.Scenario.Load.Standardized.Concurrency = &n
+		r.Results.RequestPerformance.Aggregate.Latency.TimeToFirstToken = &BenchmarkStatistics{Units: "ms", Mean: m.ttft}
+		r.Results.RequestPerformance.Aggregate.Throughput.OutputTokenRate = &BenchmarkStatistics{Units: "tokens/s", Mean: m.output}
+	}
+	return reports
+}
+
+func TestReportQueryFilter(t *testing.T) {
+	i := func(v int) *int { return &v }
+	tests := []struct {
+		name string
+		q    BenchmarkReportQuery
+		want []string
+	}{
+		{name: "no filters returns everything", q: BenchmarkReportQuery{},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0", "mixtral/r1/stage-0", "bare/r1/stage-0"}},
+		{name: "model is case-insensitive", q: BenchmarkReportQuery{Fields: map[string]string{"model": "META-LLAMA/Llama-3.1-8B"}},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0"}},
+		{name: "model is not a prefix match", q: BenchmarkReportQuery{Fields: map[string]string{"model": "meta-llama"}},
+			want: []string{}},
+		{name: "accelerator and experiment", q: BenchmarkReportQuery{Fields: map[string]string{"accelerator": "a100", "experiment": "mixtral"}},
+			want: []string{"mixtral/r1/stage-0"}},
+		{name: "tool matches the stack", q: BenchmarkReportQuery{Fields: map[string]string{"tool": "vllm"}},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0", "mixtral/r1/stage-0"}},
+		{name: "run name", q: BenchmarkReportQuery{Run: "r1"},
+			want: []string{"llama-sweep/r1/stage-0", "mixtral/r1/stage-0", "bare/r1/stage-0"}},
+		{name: "run UID", q: BenchmarkReportQuery{Run: "llama-sweep/r2/stage-0"},
+			want: []string{"llama-sweep/r2/stage-0"}},
+		{name: "concurrency range excludes unknown", q: BenchmarkReportQuery{ConcurrencyMin: i(10), ConcurrencyMax: i(32)},
+			want: []string{"llama-sweep/r2/stage-0", "mixtral/r1/stage-0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uids := []string{}
+			for _, r := range tt.q.filter(queryFixtures()) {
+				uids = append(uids, r.Run.UID)
+			}
+			assert.Equal(t, tt.want, uids)
+		})
+	}
+}
+
+func TestGroupReports(t *testing.T) {
+	groups := groupReports(queryFixtures(), "model")
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "", groups[0].Value, "reports without a model are grouped under an empty value")
+	assert.Nil(t, groups[0].TTFT)
+
+	llama := groups[1]
+	assert.Equal(t, "meta-llama/Llama-3.1-8B", llama.Value)
+	assert.Equal(t, 2, llama.Runs)
+	assert.Equal(t, []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0"}, llama.UIDs)
+	require.NotNil(t, llama.TTFT)
+	assert.Equal(t, BenchmarkMetricSummary{Units: "ms", Runs: 2, Mean: 200, P50: 200, P99: 298}, *llama.TTFT)
+	require.NotNil(t, llama.OutputTokenRate)
+	assert.InDelta(t, 1200, llama.OutputTokenRate.Mean, 1e-9)
+	assert.Nil(t, llama.RequestRate)
+
+	assert.Equal(t, "mistralai/Mixtral-8x7B", groups[2].Value)
+	assert.Equal(t, BenchmarkMetricSummary{Units: "ms", Runs: 1, Mean: 50, P50: 50, P99: 50}, *groups[2].TTFT)
+}
+
+func TestGetReports_QueryParameters(t *testing.T) {
+	h := NewBenchmarkHandlers("key", "folder")
+	h.cache.retention = RetentionPolicy{}
+	h.cache.set(queryFixtures(), "0")
+	app := fiber.New()
+	app.Get("/api/benchmarks/reports", h.GetReports)
+
+	get := func(query string, out any) int {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/reports"+query, nil))
+		require.NoError(t, err)
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	var reports struct {
+		Reports []BenchmarkReport `json:"reports"`
+	}
+	require.Equal(t, fiber.StatusOK, get("?accelerator=h100&concurrency_min=16", &reports))
+	require.Len(t, reports.Reports, 1)
+	assert.Equal(t, "llama-sweep/r2/stage-0", reports.Reports[0].Run.UID)
+
+	var grouped struct {
+		Groups  []BenchmarkReportGroup `json:"groups"`
+		GroupBy string                 `json:"group_by"`
+		Total   int                    `json:"total"`
+		Reports []BenchmarkReport      `json:"reports"`
+	}
+	require.Equal(t, fiber.StatusOK, get("?group_by=accelerator&tool=vllm", &grouped))
+	assert.Equal(t, "accelerator", grouped.GroupBy)
+	assert.Equal(t, 3, grouped.Total)
+	assert.Nil(t, grouped.Reports, "grouped responses leave the reports out")
+	require.Len(t, grouped.Groups, 2)
+	assert.Equal(t, "A100", grouped.Groups[0].Value)
+	assert.Equal(t, "H100", grouped.Groups[1].Value)
+	assert.Equal(t, 2, grouped.Groups[1].Runs)
+
+	for _, query := range []string{"?concurrency_min=x", "?concurrency_max=-1", "?concurrency_min=8&concurrency_max=4", "?group_by=qps"} {
+		assert.Equal(t, fiber.StatusBadRequest, get(query, nil), query)
+	}
+}
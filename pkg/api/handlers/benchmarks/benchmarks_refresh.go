@@ -0,0 +1,87 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/operations"
+)
+
+// RefreshOperation is the operation kind of a benchmark refresh.
+const RefreshOperation = "benchmarks.refresh"
+
+// refreshTimeout bounds a full refresh. Google Drive fetches are throttled,
+// so a large folder takes minutes.
+const refreshTimeout = 15 * time.Minute
+
+// refreshResult is the result of a finished refresh operation.
+type refreshResult struct {
+	Reports       int    `json:"reports"`
+	ParseFailures int    `json:"parse_failures"`
+	Since         string `json:"since"`
+}
+
+// SetOperationManager enables POST /api/benchmarks/refresh, which refetches
+// the reports in the background. With a nil manager (the default) the
+// endpoint is unavailable.
+func (h *BenchmarkHandlers) SetOperationManager(m *operations.Manager) {
+	h.operations = m
+}
+
+// RefreshReports refetches every report from the source into the cache as
+// an operation and responds 202 with it. Only one refresh runs at a time;
+// a second request gets the running one.
+// POST /api/benchmarks/refresh
+func (h *BenchmarkHandlers) RefreshReports(c *fiber.Ctx) error {
+	if isDemoMode(c) {
+		return c.JSON(fiber.Map{"reports": []interface{}{}, "source": "demo"})
+	}
+	if !h.configured() {
+		return driveUnavailable(c)
+	}
+	if h.operations == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "background operations not available"})
+	}
+
+	since := normalizeSinceKey(c.Query("since", "0"))
+	op := h.operations.Start(operations.Spec{
+		Kind:      RefreshOperation,
+		Owner:     middleware.GetUserID(c).String(),
+		Exclusive: true,
+		Timeout:   refreshTimeout,
+	}, func(ctx context.Context, progress operations.Reporter) (any, error) {
+		return h.refresh(ctx, since, progress)
+	})
+	c.Location("/api/operations/" + op.ID)
+	return c.Status(fiber.StatusAccepted).JSON(op)
+}
+
+// refresh fetches the reports since the since key and replaces the cache,
+// reporting the experiment folders fetched.
+func (h *BenchmarkHandlers) refresh(ctx context.Context, since string, progress operations.Reporter) (*refreshResult, error) {
+	var cutoff time.Time
+	if d := parseSinceDuration(since); d > 0 {
+		cutoff = time.Now().Add(-d)
+	}
+	progress.Report(operations.Progress{Message: "Listing experiments"})
+	reports, parseFailures, err := h.fetchAllReportsWithProgress(ctx, cutoff, func(done, total int) {
+		progress.Report(operations.Progress{Completed: done, Total: total, Message: "Fetching experiments"})
+	})
+	if ctx.Err() != nil {
+		// A cancelled fetch is incomplete; report the cancellation rather
+		// than a fetch failure.
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		slog.Error("[benchmarks] refresh failed", "error", err)
+		return nil, fmt.Errorf("failed to fetch benchmark data")
+	}
+	reports = h.cache.set(reports, since)
+	h.alerts.check(reports)
+	slog.Info("[benchmarks] refreshed reports", "source", h.reportSource().Name(), "count", len(reports), "since", since, "parseFailures", parseFailures)
+	return &refreshResult{Reports: len(reports), ParseFailures: parseFailures, Since: since}, nil
+}
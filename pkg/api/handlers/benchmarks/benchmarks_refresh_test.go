@@ -0,0 +1,91 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshDriveFolders is a Drive tree of two experiments with one run and
+// one report each, by folder ID.
+var refreshDriveFolders = map[string][]driveFile{
+	"root": {
+		{ID: "exp-a", Name: "exp-a", MimeType: driveFolderMIME},
+		{ID: "exp-b", Name: "exp-b", MimeType: driveFolderMIME},
+	},
+	"exp-a": {{ID: "run-a", Name: "run-1", MimeType: driveFolderMIME}},
+	"exp-b": {{ID: "run-b", Name: "run-1", MimeType: driveFolderMIME}},
+	"run-a": {{ID: "report-a", Name: "benchmark_report_a.yaml", MimeType: "text/yaml"}},
+	"run-b": {{ID: "report-b", Name: "benchmark_report_b.yaml", MimeType: "text/yaml"}},
+}
+
+func newRefreshTestHandlers(t *testing.T) *BenchmarkHandlers {
+	t.Helper()
+	srv, client := newMockDriveServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		if folder, ok := strings.CutSuffix(q, "' in parents"); ok {
+			json.NewEncoder(w).Encode(driveFileList{Files: refreshDriveFolders[strings.TrimPrefix(folder, "'")]})
+			return
+		}
+		w.Write([]byte(validBenchmarkYAML))
+	}))
+	t.Cleanup(srv.Close)
+	h := NewBenchmarkHandlers("test-key", "root")
+	h.SetHTTPClient(client)
+	return h
+}
+
+func TestRefreshReports(t *testing.T) {
+	h := newRefreshTestHandlers(t)
+	manager := operations.NewManager(0, nil)
+	defer manager.Close()
+	h.SetOperationManager(manager)
+	app := fiber.New()
+	app.Post("/api/benchmarks/refresh", h.RefreshReports)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/benchmarks/refresh", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var op operations.Operation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&op))
+	assert.Equal(t, RefreshOperation, op.Kind)
+	assert.Equal(t, "/api/operations/"+op.ID, resp.Header.Get(fiber.HeaderLocation))
+
+	require.Eventually(t, func() bool {
+		op, err = manager.Get(op.ID)
+		return err == nil && op.State.Done()
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, operations.StateSucceeded, op.State, op.Error)
+	assert.Equal(t, operations.Progress{Completed: 2, Total: 2, Message: "Fetching experiments"}, op.Progress)
+	result, ok := op.Result.(*refreshResult)
+	require.True(t, ok)
+	assert.Equal(t, 2, result.Reports)
+
+	reports, cached := h.cache.get("0")
+	assert.True(t, cached)
+	assert.Len(t, reports, 2)
+}
+
+func TestRefreshReports_Unavailable(t *testing.T) {
+	app := fiber.New()
+	h := NewBenchmarkHandlers("", "root")
+	app.Post("/refresh", h.RefreshReports)
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/refresh", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	h = NewBenchmarkHandlers("test-key", "root")
+	app = fiber.New()
+	app.Post("/refresh", h.RefreshReports)
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/refresh", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "no operation manager")
+}
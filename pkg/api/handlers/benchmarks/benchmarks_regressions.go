@@ -0,0 +1,110 @@
+package benchmarks
+
+import (
+	"sort"
+	"time"
+)
+
+// regressionThreshold is the relative change against the previous run of the
+// same experiment that counts as a regression. Run-to-run noise on shared
+// accelerators is typically well under 10%.
+const regressionThreshold = 0.20
+
+// Regression is a benchmark run whose latency rose or throughput fell by more
+// than regressionThreshold compared to the previous run of its experiment.
+type Regression struct {
+	Experiment     string  `json:"experiment"`
+	RunUID         string  `json:"runUid"`
+	BaselineRunUID string  `json:"baselineRunUid"`
+	Metric         string  `json:"metric"`
+	Units          string  `json:"units,omitempty"`
+	Baseline       float64 `json:"baseline"`
+	Current        float64 `json:"current"`
+	// Change is the relative change from Baseline to Current. It is positive
+	// for latency regressions and negative for throughput regressions.
+	Change float64   `json:"change"`
+	At     time.Time `json:"at"`
+}
+
+// regressionMetric reads one aggregate statistic from a report.
+type regressionMetric struct {
+	name           string
+	higherIsBetter bool
+	get            func(r *BenchmarkReport) *BenchmarkStatistics
+}
+
+var regressionMetrics = []regressionMetric{
+	{name: "request_latency", get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Latency.RequestLatency
+	}},
+	{name: "time_to_first_token", get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Latency.TimeToFirstToken
+	}},
+	{name: "output_token_rate", higherIsBetter: true, get: func(r *BenchmarkReport) *BenchmarkStatistics {
+		return r.Results.RequestPerformance.Aggregate.Throughput.OutputTokenRate
+	}},
+}
+
+// Regressions compares every cached report that ended within [since, until]
+// with the previous report of the same experiment and returns the metrics
+// that regressed, oldest first. Only cached reports are considered, so the
+// result is empty until the reports have been fetched once.
+func (h *BenchmarkHandlers) Regressions(since, until time.Time) []Regression {
+	h.cache.mu.RLock()
+	reports := make([]BenchmarkReport, len(h.cache.reports))
+	copy(reports, h.cache.reports)
+	h.cache.mu.RUnlock()
+	return findRegressions(reports, since, until)
+}
+
+func findRegressions(reports []BenchmarkReport, since, until time.Time) []Regression {
+	byExperiment := make(map[string][]int)
+	for i, r := range reports {
+		if reportTime(r).IsZero() {
+			continue
+		}
+		e := reportExperiment(r)
+		byExperiment[e] = append(byExperiment[e], i)
+	}
+
+	var out []Regression
+	for experiment, idx := range byExperiment {
+		sort.SliceStable(idx, func(a, b int) bool {
+			return reportTime(reports[idx[a]]).Before(reportTime(reports[idx[b]]))
+		})
+		for n := 1; n < len(idx); n++ {
+			prev, cur := &reports[idx[n-1]], &reports[idx[n]]
+			at := reportTime(*cur)
+			if at.Before(since) || at.After(until) {
+				continue
+			}
+			for _, m := range regressionMetrics {
+				base, now := m.get(prev), m.get(cur)
+				if base == nil || now == nil || base.Mean <= 0 {
+					continue
+				}
+				change := (now.Mean - base.Mean) / base.Mean
+				worse := change > regressionThreshold
+				if m.higherIsBetter {
+					worse = change < -regressionThreshold
+				}
+				if !worse {
+					continue
+				}
+				out = append(out, Regression{
+					Experiment:     experiment,
+					RunUID:         cur.Run.UID,
+					BaselineRunUID: prev.Run.UID,
+					Metric:         m.name,
+					Units:          now.Units,
+					Baseline:       base.Mean,
+					Current:        now.Mean,
+					Change:         change,
+					At:             at,
+				})
+			}
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].At.Before(out[b].At) })
+	return out
+}
@@ -0,0 +1,67 @@
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func regressionReport(experiment, run string, ended time.Time, latency, tokenRate float64) BenchmarkReport {
+	r := retentionReport(experiment, run, ended)
+	agg := &r.Results.RequestPerformance.Aggregate
+	agg.Latency.RequestLatency = &BenchmarkStatistics{Units: "s", Mean: latency}
+	agg.Throughput.OutputTokenRate = &BenchmarkStatistics{Units: "tokens/s", Mean: tokenRate}
+	return r
+}
+
+func TestFindRegressions(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	reports := []BenchmarkReport{
+		// Out of order on purpose; runs are compared by time.
+		regressionReport("llama", "r2", now.Add(-2*time.Hour), 1.5, 100),
+		regressionReport("llama", "r1", now.Add(-3*time.Hour), 1.0, 110),
+		regressionReport("llama", "r3", now.Add(-1*time.Hour), 1.55, 70),
+		regressionReport("mixtral", "r1", now.Add(-2*time.Hour), 2.0, 50),
+		regressionReport("mixtral", "r2", now.Add(-1*time.Hour), 2.1, 48),
+	}
+
+	got := findRegressions(reports, now.Add(-24*time.Hour), now)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "llama", got[0].Experiment)
+	assert.Equal(t, "llama/r2/stage-0", got[0].RunUID)
+	assert.Equal(t, "llama/r1/stage-0", got[0].BaselineRunUID)
+	assert.Equal(t, "request_latency", got[0].Metric)
+	assert.InDelta(t, 0.5, got[0].Change, 1e-9)
+
+	assert.Equal(t, "llama/r3/stage-0", got[1].RunUID)
+	assert.Equal(t, "output_token_rate", got[1].Metric)
+	assert.InDelta(t, -0.3, got[1].Change, 1e-9, "throughput drops are negative")
+}
+
+func TestFindRegressions_Window(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	reports := []BenchmarkReport{
+		regressionReport("llama", "r1", now.Add(-48*time.Hour), 1.0, 100),
+		regressionReport("llama", "r2", now.Add(-30*time.Hour), 2.0, 100),
+		regressionReport("llama", "r3", now.Add(-1*time.Hour), 4.0, 100),
+	}
+	got := findRegressions(reports, now.Add(-2*time.Hour), now)
+	require.Len(t, got, 1, "only runs inside the window are reported")
+	assert.Equal(t, "llama/r3/stage-0", got[0].RunUID)
+	assert.Equal(t, "llama/r2/stage-0", got[0].BaselineRunUID, "baseline may predate the window")
+}
+
+func TestBenchmarkHandlers_Regressions_UsesCache(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	h := NewBenchmarkHandlers("", "")
+	assert.Empty(t, h.Regressions(now.Add(-time.Hour), now), "empty before the first fetch")
+
+	h.cache.set([]BenchmarkReport{
+		regressionReport("llama", "r1", now.Add(-2*time.Hour), 1.0, 100),
+		regressionReport("llama", "r2", now.Add(-30*time.Minute), 1.0, 100),
+	}, "")
+	assert.Empty(t, h.Regressions(now.Add(-time.Hour), now), "unchanged metrics are not regressions")
+}
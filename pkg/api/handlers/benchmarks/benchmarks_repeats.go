@@ -0,0 +1,301 @@
+package benchmarks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultMinScenarioRuns hides scenarios that were run once, which have
+// nothing to aggregate.
+const defaultMinScenarioRuns = 2
+
+// minOutlierRuns is the fewest runs a scenario needs before any of them can
+// be flagged as an outlier.
+const minOutlierRuns = 3
+
+// outlierScore is the modified z-score above which a run is an outlier
+// (Iglewicz and Hoaglin).
+const outlierScore = 3.5
+
+// Constants of the modified z-score: 0.6745 scales the median absolute
+// deviation to a standard deviation, and 1.253314 the mean absolute
+// deviation, used when more than half the runs share the median.
+const (
+	madScale    = 0.6745
+	meanADScale = 1.253314
+)
+
+// tCritical95 holds the two-sided 95% critical values of Student's t
+// distribution for 1 to 30 degrees of freedom. Beyond that the normal
+// value is close enough.
+var tCritical95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// zCritical95 is the two-sided 95% critical value of the normal
+// distribution.
+const zCritical95 = 1.96
+
+// BenchmarkRepeatStats aggregates one metric over the repeat runs of a
+// scenario, from each run's mean. Stddev is the sample standard deviation
+// and CI95 the 95% confidence interval of the mean; both need two runs.
+type BenchmarkRepeatStats struct {
+	Metric         string      `json:"metric"`
+	Units          string      `json:"units"`
+	HigherIsBetter bool        `json:"higher_is_better"`
+	Runs           int         `json:"runs"`
+	Mean           float64     `json:"mean"`
+	Stddev         *float64    `json:"stddev,omitempty"`
+	CI95           *[2]float64 `json:"ci95,omitempty"`
+	// CV is the coefficient of variation, Stddev over Mean, nil when the
+	// mean is 0.
+	CV *float64 `json:"cv,omitempty"`
+}
+
+// BenchmarkOutlier is a run whose mean of some metrics is far from that of
+// the scenario's other runs.
+type BenchmarkOutlier struct {
+	RunUID  string   `json:"run_uid"`
+	Metrics []string `json:"metrics"`
+}
+
+// BenchmarkScenarioGroup is the runs of one scenario: reports whose stack
+// and load are the same, whatever experiment they belong to.
+type BenchmarkScenarioGroup struct {
+	Fingerprint  string                 `json:"fingerprint"`
+	Models       []string               `json:"models"`
+	Accelerators []string               `json:"accelerators"`
+	Tools        []string               `json:"tools"`
+	RateQPS      *float64               `json:"rate_qps,omitempty"`
+	Concurrency  *int                   `json:"concurrency,omitempty"`
+	Runs         int                    `json:"runs"`
+	UIDs         []string               `json:"uids"`
+	Metrics      []BenchmarkRepeatStats `json:"metrics"`
+	Outliers     []BenchmarkOutlier     `json:"outliers"`
+}
+
+// scenarioFingerprint hashes what makes r's scenario repeatable: its stack
+// components, in any order, and its load. Run identity, descriptions and
+// the accelerator specs the catalog may fill in are left out.
+func scenarioFingerprint(r BenchmarkReport) string {
+	type accelerator struct {
+		Model       string                `json:"model"`
+		Count       int                   `json:"count"`
+		Parallelism *BenchmarkParallelism `json:"parallelism,omitempty"`
+	}
+	type component struct {
+		Kind        string             `json:"kind"`
+		Tool        string             `json:"tool"`
+		ToolVersion string             `json:"tool_version"`
+		Role        string             `json:"role"`
+		Replicas    *int               `json:"replicas,omitempty"`
+		Model       *BenchmarkModelRef `json:"model,omitempty"`
+		Accelerator *accelerator       `json:"accelerator,omitempty"`
+	}
+	stack := make([]string, 0, len(r.Scenario.Stack))
+	for _, c := range r.Scenario.Stack {
+		s := c.Standardized
+		comp := component{Kind: s.Kind, Tool: s.Tool, ToolVersion: s.ToolVersion, Role: s.Role, Replicas: s.Replicas, Model: s.Model}
+		if a := s.Accelerator; a != nil {
+			comp.Accelerator = &accelerator{Model: a.Model, Count: a.Count, Parallelism: a.Parallelism}
+		}
+		b, _ := json.Marshal(comp)
+		stack = append(stack, string(b))
+	}
+	slices.Sort(stack)
+	b, _ := json.Marshal(struct {
+		Stack []string `json:"stack"`
+		Load  any      `json:"load"`
+	}{stack, r.Scenario.Load.Standardized})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// groupScenarios groups reports by scenario fingerprint and aggregates the
+// groups with at least minRuns runs, the most runs first.
+func groupScenarios(reports []BenchmarkReport, minRuns int) []BenchmarkScenarioGroup {
+	byFingerprint := map[string][]*BenchmarkReport{}
+	var order []string
+	for i := range reports {
+		fp := scenarioFingerprint(reports[i])
+		if _, ok := byFingerprint[fp]; !ok {
+			order = append(order, fp)
+		}
+		byFingerprint[fp] = append(byFingerprint[fp], &reports[i])
+	}
+
+	groups := make([]BenchmarkScenarioGroup, 0, len(order))
+	for _, fp := range order {
+		runs := byFingerprint[fp]
+		if len(runs) < minRuns {
+			continue
+		}
+		first := *runs[0]
+		g := BenchmarkScenarioGroup{
+			Fingerprint:  fp,
+			Models:       nonNil(reportFieldValues(first, searchFieldModel)),
+			Accelerators: nonNil(reportFieldValues(first, searchFieldAccelerator)),
+			Tools:        nonNil(reportFieldValues(first, searchFieldTool)),
+			RateQPS:      first.Scenario.Load.Standardized.RateQPS,
+			Concurrency:  first.Scenario.Load.Standardized.Concurrency,
+			Runs:         len(runs),
+			UIDs:         make([]string, 0, len(runs)),
+			Metrics:      []BenchmarkRepeatStats{},
+			Outliers:     []BenchmarkOutlier{},
+		}
+		for _, r := range runs {
+			g.UIDs = append(g.UIDs, r.Run.UID)
+		}
+		outlying := map[string][]string{}
+		for _, m := range compareMetrics {
+			var uids []string
+			var values []float64
+			units := ""
+			for _, r := range runs {
+				s := m.get(r)
+				if s == nil || math.IsNaN(s.Mean) || math.IsInf(s.Mean, 0) {
+					continue
+				}
+				if units == "" {
+					units = s.Units
+				}
+				uids = append(uids, r.Run.UID)
+				values = append(values, s.Mean)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			stats := repeatStats(values)
+			stats.Metric, stats.Units, stats.HigherIsBetter = m.name, units, m.higherIsBetter
+			g.Metrics = append(g.Metrics, stats)
+			for _, i := range outliers(values) {
+				outlying[uids[i]] = append(outlying[uids[i]], m.name)
+			}
+		}
+		for _, uid := range g.UIDs {
+			if metrics := outlying[uid]; len(metrics) > 0 {
+				g.Outliers = append(g.Outliers, BenchmarkOutlier{RunUID: uid, Metrics: metrics})
+			}
+		}
+		groups = append(groups, g)
+	}
+	slices.SortStableFunc(groups, func(a, b BenchmarkScenarioGroup) int { return b.Runs - a.Runs })
+	return groups
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// repeatStats returns the mean of values, which is not empty, and with two
+// or more values their sample standard deviation, coefficient of variation
+// and the 95% confidence interval of the mean from Student's t.
+func repeatStats(values []float64) BenchmarkRepeatStats {
+	n := len(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	s := BenchmarkRepeatStats{Runs: n, Mean: sum / float64(n)}
+	if n < 2 {
+		return s
+	}
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - s.Mean) * (v - s.Mean)
+	}
+	stddev := math.Sqrt(squares / float64(n-1))
+	s.Stddev = &stddev
+	if s.Mean != 0 {
+		cv := stddev / math.Abs(s.Mean)
+		s.CV = &cv
+	}
+	t := zCritical95
+	if n-1 <= len(tCritical95) {
+		t = tCritical95[n-2]
+	}
+	margin := t * stddev / math.Sqrt(float64(n))
+	s.CI95 = &[2]float64{s.Mean - margin, s.Mean + margin}
+	return s
+}
+
+// outliers returns the indexes of the values whose modified z-score, their
+// distance from the median in robust standard deviations, is above
+// outlierScore. Unlike the z-score, it can flag one bad run among three, as
+// the bad run does not inflate the spread it is measured by.
+func outliers(values []float64) []int {
+	if len(values) < minOutlierRuns {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	median := percentile(sorted, 50)
+	deviations := make([]float64, len(values))
+	meanAD := 0.0
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+		meanAD += deviations[i]
+	}
+	meanAD /= float64(len(values))
+	sortedDev := slices.Clone(deviations)
+	slices.Sort(sortedDev)
+
+	scale := percentile(sortedDev, 50) / madScale
+	if scale == 0 {
+		scale = meanADScale * meanAD
+	}
+	if scale == 0 {
+		return nil
+	}
+	var out []int
+	for i, d := range deviations {
+		if d/scale > outlierScore {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// GetScenarios groups cached benchmark runs by scenario, so that repeats of
+// the same stack under the same load are aggregated: per metric, the mean,
+// standard deviation and 95% confidence interval over the runs, and the
+// runs that are statistical outliers. Scenarios with fewer than min_runs
+// runs (2 by default) are left out. The filters are those of GetReports.
+// GET /api/benchmarks/scenarios?model=&accelerator=&tool=&experiment=&concurrency_min=&concurrency_max=&min_runs=
+func (h *BenchmarkHandlers) GetScenarios(c *fiber.Ctx) error {
+	q, err := parseReportQuery(c)
+	if err != nil {
+		return err
+	}
+	if q.GroupBy != "" || q.Run != "" {
+		return fiber.NewError(fiber.StatusBadRequest, "scenarios do not take group_by or run")
+	}
+	minRuns := defaultMinScenarioRuns
+	if raw := strings.TrimSpace(c.Query("min_runs")); raw != "" {
+		minRuns, err = strconv.Atoi(raw)
+		if err != nil || minRuns < 1 {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid min_runs")
+		}
+	}
+	if isDemoMode(c) {
+		return c.JSON(fiber.Map{"scenarios": []BenchmarkScenarioGroup{}, "total": 0, "source": "demo"})
+	}
+
+	h.cache.mu.RLock()
+	cached := h.cache.reports
+	h.cache.mu.RUnlock()
+	reports := q.filter(cached)
+	return sendConditional(c, fiber.Map{"scenarios": groupScenarios(reports, minRuns), "total": len(reports), "source": "cache"})
+}
@@ -0,0 +1,114 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repeatReport is a run of the Llama H100 scenario at 10 QPS.
+func repeatReport(experiment, run string, ttft, tokenRate float64) BenchmarkReport {
+	r := searchReport(experiment, run, "meta-llama/Llama-3.1-8B", "H100", 10, 1)
+	agg := &r.Results.RequestPerformance.Aggregate
+	agg.Latency.TimeToFirstToken = &BenchmarkStatistics{Units: "ms", Mean: ttft}
+	agg.Throughput.OutputTokenRate = &BenchmarkStatistics{Units: "tokens/s", Mean: tokenRate}
+	return r
+}
+
+func TestScenarioFingerprint(t *testing.T) {
+	a := repeatReport("llama", "r1", 100, 1000)
+	b := repeatReport("llama-rerun", "r7", 120, 900)
+	tdp := 700
+	b.Scenario.Stack[0].Standardized.Accelerator.TDP = &tdp
+	b.Scenario.Stack[0].Metadata.Description = "rerun"
+	assert.Equal(t, scenarioFingerprint(a), scenarioFingerprint(b), "run identity, results and catalog values do not count")
+
+	other := searchReport("llama", "r2", "meta-llama/Llama-3.1-8B", "H100", 40, 1)
+	assert.NotEqual(t, scenarioFingerprint(a), scenarioFingerprint(other), "a different load is a different scenario")
+	other = searchReport("llama", "r2", "meta-llama/Llama-3.1-8B", "H100", 10, 2)
+	assert.NotEqual(t, scenarioFingerprint(a), scenarioFingerprint(other), "a different parallelism is a different scenario")
+
+	var prefill BenchmarkStackComponent
+	prefill.Standardized.Role = "prefill"
+	twoA, twoB := a, a
+	twoA.Scenario.Stack = []BenchmarkStackComponent{a.Scenario.Stack[0], prefill}
+	twoB.Scenario.Stack = []BenchmarkStackComponent{prefill, a.Scenario.Stack[0]}
+	assert.Equal(t, scenarioFingerprint(twoA), scenarioFingerprint(twoB), "stack order does not count")
+}
+
+func TestRepeatStats(t *testing.T) {
+	s := repeatStats([]float64{10, 12, 14})
+	assert.Equal(t, 3, s.Runs)
+	assert.InDelta(t, 12, s.Mean, 1e-9)
+	require.NotNil(t, s.Stddev)
+	assert.InDelta(t, 2, *s.Stddev, 1e-9)
+	require.NotNil(t, s.CV)
+	assert.InDelta(t, 2.0/12, *s.CV, 1e-9)
+	require.NotNil(t, s.CI95)
+	margin := 4.303 * 2 / 1.7320508075688772
+	assert.InDelta(t, 12-margin, s.CI95[0], 1e-9)
+	assert.InDelta(t, 12+margin, s.CI95[1], 1e-9)
+
+	one := repeatStats([]float64{5})
+	assert.Nil(t, one.Stddev)
+	assert.Nil(t, one.CI95)
+}
+
+func TestOutliers(t *testing.T) {
+	assert.Equal(t, []int{2}, outliers([]float64{100, 101, 150}), "one bad run among three")
+	assert.Empty(t, outliers([]float64{100, 101, 103, 99, 102}))
+	assert.Equal(t, []int{4}, outliers([]float64{100, 100, 100, 100, 160}), "the mean deviation stands in for a zero MAD")
+	assert.Empty(t, outliers([]float64{100, 100, 100}))
+	assert.Empty(t, outliers([]float64{100, 150}), "two runs cannot tell which is off")
+}
+
+func TestGetScenarios(t *testing.T) {
+	h := NewBenchmarkHandlers("key", "folder")
+	h.cache.retention = RetentionPolicy{}
+	h.cache.set([]BenchmarkReport{
+		repeatReport("llama", "r1", 100, 1000),
+		repeatReport("llama", "r2", 102, 1010),
+		repeatReport("llama", "r3", 250, 990),
+		searchReport("mixtral", "r1", "mistralai/Mixtral-8x7B", "A100", 20, 4),
+	}, "0")
+	app := fiber.New()
+	app.Get("/api/benchmarks/scenarios", h.GetScenarios)
+
+	get := func(query string, out any) int {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/scenarios"+query, nil))
+		require.NoError(t, err)
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	var body struct {
+		Scenarios []BenchmarkScenarioGroup `json:"scenarios"`
+		Total     int                      `json:"total"`
+	}
+	require.Equal(t, fiber.StatusOK, get("", &body))
+	assert.Equal(t, 4, body.Total)
+	require.Len(t, body.Scenarios, 1, "the single Mixtral run is left out")
+	g := body.Scenarios[0]
+	assert.Equal(t, 3, g.Runs)
+	assert.Equal(t, []string{"meta-llama/Llama-3.1-8B"}, g.Models)
+	assert.Equal(t, []string{"H100"}, g.Accelerators)
+	require.Len(t, g.Metrics, 2, "metrics no run reports are left out")
+	assert.Equal(t, "time_to_first_token", g.Metrics[0].Metric)
+	assert.InDelta(t, 150.667, g.Metrics[0].Mean, 1e-3)
+	assert.Equal(t, []BenchmarkOutlier{{RunUID: "llama/r3/stage-0", Metrics: []string{"time_to_first_token"}}}, g.Outliers)
+
+	require.Equal(t, fiber.StatusOK, get("?min_runs=1&model=mistralai/Mixtral-8x7B", &body))
+	require.Len(t, body.Scenarios, 1)
+	assert.Equal(t, 1, body.Scenarios[0].Runs)
+	assert.Empty(t, body.Scenarios[0].Metrics)
+
+	for _, query := range []string{"?min_runs=0", "?min_runs=x", "?group_by=model", "?run=r1"} {
+		assert.Equal(t, fiber.StatusBadRequest, get(query, nil), query)
+	}
+}
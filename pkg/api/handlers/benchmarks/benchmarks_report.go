@@ -1,11 +1,20 @@
 package benchmarks
 
 import (
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"path"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+// reportVersionV2 is the report format BenchmarkReport models. Files with
+// an older or no version are adapted with adaptV1ToV2.
+const reportVersionV2 = "0.2"
+
 // v0.2 output structs — match the TypeScript BenchmarkReport interface.
 type BenchmarkStatistics struct {
 	Units  string   `json:"units"`
@@ -31,6 +40,10 @@ type BenchmarkAccelerator struct {
 	Count       int                   `json:"count"`
 	Memory      *int                  `json:"memory,omitempty"`
 	Parallelism *BenchmarkParallelism `json:"parallelism,omitempty"`
+	// TDP (watts) and CostPerHour (per accelerator) come from the
+	// accelerator catalog; see benchmarks_accelerators.go.
+	TDP         *int     `json:"tdp,omitempty"`
+	CostPerHour *float64 `json:"cost_per_hour,omitempty"`
 }
 
 type BenchmarkParallelism struct {
@@ -136,6 +149,8 @@ type BenchmarkReport struct {
 		} `json:"observability,omitempty"`
 		ComponentHealth []interface{} `json:"component_health,omitempty"`
 	} `json:"results"`
+	// Extensions are derived by the console; see benchmarks_efficiency.go.
+	Extensions *BenchmarkExtensions `json:"extensions,omitempty"`
 }
 
 // v0.1 raw YAML structures — match the actual benchmark output.
@@ -241,6 +256,66 @@ type rawV1Report struct {
 	} `yaml:"scenario"`
 }
 
+// parseReport parses a benchmark report file. v0.2 reports are read as
+// they are; files without a version or with version 0.1 go through
+// adaptV1ToV2. Either way Run.EID is "<experiment>/<run>", the folders the
+// file was found in, which is what filtering and retention group by.
+func parseReport(data []byte, file SourceEntry, experimentName, runName string) (BenchmarkReport, error) {
+	var header struct {
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return BenchmarkReport{}, err
+	}
+	switch {
+	case header.Version == "" || header.Version == "0.1" || strings.HasPrefix(header.Version, "0.1."):
+		var raw rawV1Report
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return BenchmarkReport{}, err
+		}
+		return adaptV1ToV2(raw, experimentName, runName, file.CreatedTime), nil
+	case header.Version == reportVersionV2 || strings.HasPrefix(header.Version, reportVersionV2+"."):
+		return parseV2Report(data, header.Version, file, experimentName, runName)
+	default:
+		return BenchmarkReport{}, fmt.Errorf("unsupported benchmark report version %q", header.Version)
+	}
+}
+
+// parseV2Report reads a native v0.2 report. BenchmarkReport carries JSON
+// tags only, so the YAML is decoded generically and re-read as JSON.
+// Observability metrics and component health are kept as they are. A
+// missing run UID or time is filled in from the file.
+func parseV2Report(data []byte, version string, file SourceEntry, experimentName, runName string) (BenchmarkReport, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return BenchmarkReport{}, err
+	}
+	// An unquoted `version: 0.2` decodes as a number.
+	doc["version"] = version
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return BenchmarkReport{}, fmt.Errorf("converting v0.2 report: %w", err)
+	}
+	var report BenchmarkReport
+	if err := json.Unmarshal(encoded, &report); err != nil {
+		return BenchmarkReport{}, fmt.Errorf("decoding v0.2 report: %w", err)
+	}
+
+	report.Run.EID = fmt.Sprintf("%s/%s", experimentName, runName)
+	if report.Run.UID == "" {
+		report.Run.UID = fmt.Sprintf("%s/%s", report.Run.EID, strings.TrimSuffix(path.Base(file.Name), benchmarkFileSuffix))
+	}
+	if report.Run.Time.End == "" {
+		if created, ok := parseDriveTime(file.CreatedTime); ok {
+			report.Run.Time.End = created.Format(time.RFC3339)
+		}
+	}
+	if report.Run.Time.Start == "" {
+		report.Run.Time.Start = report.Run.Time.End
+	}
+	return report, nil
+}
+
 func adaptV1ToV2(raw rawV1Report, experimentName, runName, fileCreatedTime string) BenchmarkReport {
 	var report BenchmarkReport
 	report.Version = "0.2"
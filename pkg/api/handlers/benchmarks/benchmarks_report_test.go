@@ -453,3 +453,138 @@ func TestParseDriveTime_WithOffset(t *testing.T) {
 		t.Errorf("unexpected date: %v", ts)
 	}
 }
+
+// ---------- parseReport ----------
+
+const nativeV2ReportYAML = `version: "0.2"
+run:
+  uid: 7f3c2a
+  eid: upstream-experiment
+  time:
+    start: "2026-10-01T10:00:00Z"
+    end: "2026-10-01T10:05:00Z"
+    duration: PT300S
+  user: ci
+scenario:
+  stack:
+    - metadata:
+        label: decode-0
+        cfg_id: host-0
+      standardized:
+        kind: inference_engine
+        tool: vllm
+        tool_version: 0.11.0
+        accelerator:
+          model: H100
+          count: 8
+  load:
+    metadata:
+      cfg_id: stage-1
+    standardized:
+      tool: inference-perf
+      tool_version: 0.2.0
+      source: random
+      rate_qps: 4
+results:
+  request_performance:
+    aggregate:
+      requests:
+        total: 120
+        failures: 2
+      latency:
+        time_to_first_token:
+          units: s
+          mean: 0.21
+          p99: 0.48
+      throughput:
+        output_token_rate:
+          units: tokens/s
+          mean: 1850.5
+  observability:
+    metrics:
+      - name: kv_cache_usage
+        units: percent
+        mean: 63.2
+  component_health:
+    - label: decode-0
+      restarts: 0
+`
+
+func TestParseReport_NativeV2(t *testing.T) {
+	file := SourceEntry{Name: "benchmark_report_stage1.yaml", CreatedTime: "2026-10-01T11:00:00Z"}
+	report, err := parseReport([]byte(nativeV2ReportYAML), file, "exp", "run-1")
+	if err != nil {
+		t.Fatalf("parseReport: %v", err)
+	}
+	if report.Version != "0.2" {
+		t.Errorf("Version = %q, want 0.2", report.Version)
+	}
+	if report.Run.UID != "7f3c2a" {
+		t.Errorf("UID = %q, want the report's own", report.Run.UID)
+	}
+	if report.Run.EID != "exp/run-1" {
+		t.Errorf("EID = %q, want exp/run-1", report.Run.EID)
+	}
+	if report.Run.Time.End != "2026-10-01T10:05:00Z" {
+		t.Errorf("End = %q, want the report's own", report.Run.Time.End)
+	}
+	if len(report.Scenario.Stack) != 1 || report.Scenario.Stack[0].Standardized.Accelerator.Model != "H100" {
+		t.Errorf("Stack = %+v", report.Scenario.Stack)
+	}
+	if report.Scenario.Load.Standardized.RateQPS == nil || *report.Scenario.Load.Standardized.RateQPS != 4 {
+		t.Errorf("RateQPS = %v, want 4", report.Scenario.Load.Standardized.RateQPS)
+	}
+	agg := report.Results.RequestPerformance.Aggregate
+	if agg.Requests.Total != 120 || agg.Requests.Failures != 2 {
+		t.Errorf("Requests = %+v", agg.Requests)
+	}
+	if agg.Latency.TimeToFirstToken == nil || agg.Latency.TimeToFirstToken.P99 == nil || *agg.Latency.TimeToFirstToken.P99 != 0.48 {
+		t.Errorf("TimeToFirstToken = %+v", agg.Latency.TimeToFirstToken)
+	}
+	if agg.Throughput.OutputTokenRate == nil || agg.Throughput.OutputTokenRate.Mean != 1850.5 {
+		t.Errorf("OutputTokenRate = %+v", agg.Throughput.OutputTokenRate)
+	}
+	if report.Results.Observability == nil || len(report.Results.Observability.Metrics) != 1 {
+		t.Errorf("Observability = %+v, want one metric", report.Results.Observability)
+	}
+	if len(report.Results.ComponentHealth) != 1 {
+		t.Errorf("ComponentHealth = %+v, want one entry", report.Results.ComponentHealth)
+	}
+}
+
+func TestParseReport_NativeV2Defaults(t *testing.T) {
+	data := []byte("version: 0.2\nresults:\n  request_performance:\n    aggregate:\n      requests:\n        total: 5\n")
+	file := SourceEntry{Name: "benchmark_report_a.yaml", CreatedTime: "2026-10-01T11:00:00Z"}
+	report, err := parseReport(data, file, "exp", "run-1")
+	if err != nil {
+		t.Fatalf("parseReport: %v", err)
+	}
+	if report.Run.UID != "exp/run-1/benchmark_report_a" {
+		t.Errorf("UID = %q, want one derived from the file", report.Run.UID)
+	}
+	if report.Run.Time.End != "2026-10-01T11:00:00Z" || report.Run.Time.Start != report.Run.Time.End {
+		t.Errorf("Time = %+v, want the file's created time", report.Run.Time)
+	}
+}
+
+func TestParseReport_LegacyAdapted(t *testing.T) {
+	for _, version := range []string{"", "version: 0.1\n", "version: \"0.1.3\"\n"} {
+		data := []byte(version + "metrics:\n  requests:\n    total: 7\nscenario:\n  load:\n    metadata:\n      stage: 2\n")
+		report, err := parseReport(data, SourceEntry{Name: "benchmark_report.yaml"}, "exp", "run-1")
+		if err != nil {
+			t.Fatalf("parseReport(%q): %v", version, err)
+		}
+		if report.Run.UID != "exp/run-1/stage-2" {
+			t.Errorf("UID = %q, want the adapter's", report.Run.UID)
+		}
+		if report.Results.RequestPerformance.Aggregate.Requests.Total != 7 {
+			t.Errorf("Requests.Total = %d, want 7", report.Results.RequestPerformance.Aggregate.Requests.Total)
+		}
+	}
+}
+
+func TestParseReport_UnsupportedVersion(t *testing.T) {
+	if _, err := parseReport([]byte("version: \"1.0\"\n"), SourceEntry{}, "exp", "run"); err == nil {
+		t.Error("parseReport should reject an unknown version")
+	}
+}
@@ -0,0 +1,202 @@
+package benchmarks
+
+import (
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+const (
+	// defaultRetentionDays drops cached reports whose run ended longer ago.
+	defaultRetentionDays = 365
+	// defaultRetentionPerExperiment keeps only the newest reports of each
+	// experiment. Long-running experiments add reports on every CI run, so
+	// without a cap the cache grows for as long as the console stays up.
+	defaultRetentionPerExperiment = 500
+	// retentionPruneInterval is how often cached reports are re-checked
+	// against the age limit.
+	retentionPruneInterval = 1 * time.Hour
+
+	envRetentionDays          = "BENCHMARK_RETENTION_DAYS"
+	envRetentionPerExperiment = "BENCHMARK_RETENTION_MAX_PER_EXPERIMENT"
+)
+
+// RetentionPolicy bounds the cached benchmark reports. A zero field disables
+// that limit.
+type RetentionPolicy struct {
+	MaxAge           time.Duration `json:"maxAge"`
+	MaxPerExperiment int           `json:"maxPerExperiment"`
+}
+
+// retentionPolicyFromEnv reads BENCHMARK_RETENTION_DAYS and
+// BENCHMARK_RETENTION_MAX_PER_EXPERIMENT. "0" disables a limit; invalid
+// values fall back to the defaults.
+func retentionPolicyFromEnv() RetentionPolicy {
+	return RetentionPolicy{
+		MaxAge:           time.Duration(envNonNegativeInt(envRetentionDays, defaultRetentionDays)) * 24 * time.Hour,
+		MaxPerExperiment: envNonNegativeInt(envRetentionPerExperiment, defaultRetentionPerExperiment),
+	}
+}
+
+func envNonNegativeInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		slog.Warn("[benchmarks] invalid setting, using default", "key", key, "value", raw, "default", def)
+		return def
+	}
+	return n
+}
+
+// reportExperiment returns the experiment a report belongs to. Run.EID is
+// "<experiment>/<run>".
+func reportExperiment(r BenchmarkReport) string {
+	experiment, _, _ := strings.Cut(r.Run.EID, "/")
+	return experiment
+}
+
+// reportTime returns when a report's run ended, falling back to its start.
+// Reports without a parseable time are treated as oldest.
+func reportTime(r BenchmarkReport) time.Time {
+	if t, ok := parseDriveTime(r.Run.Time.End); ok {
+		return t
+	}
+	t, _ := parseDriveTime(r.Run.Time.Start)
+	return t
+}
+
+// apply returns the reports p keeps and how many it dropped. The input is not
+// modified and kept reports stay in their original order.
+func (p RetentionPolicy) apply(reports []BenchmarkReport, now time.Time) ([]BenchmarkReport, int) {
+	if reports == nil {
+		return nil, 0
+	}
+	drop := make([]bool, len(reports))
+	if p.MaxAge > 0 {
+		cutoff := now.Add(-p.MaxAge)
+		for i, r := range reports {
+			// Reports without a timestamp cannot be aged out.
+			if t := reportTime(r); !t.IsZero() && t.Before(cutoff) {
+				drop[i] = true
+			}
+		}
+	}
+	if p.MaxPerExperiment > 0 {
+		byExperiment := make(map[string][]int)
+		for i, r := range reports {
+			if !drop[i] {
+				e := reportExperiment(r)
+				byExperiment[e] = append(byExperiment[e], i)
+			}
+		}
+		for _, idx := range byExperiment {
+			if len(idx) <= p.MaxPerExperiment {
+				continue
+			}
+			sort.SliceStable(idx, func(a, b int) bool {
+				return reportTime(reports[idx[a]]).After(reportTime(reports[idx[b]]))
+			})
+			for _, i := range idx[p.MaxPerExperiment:] {
+				drop[i] = true
+			}
+		}
+	}
+	kept := make([]BenchmarkReport, 0, len(reports))
+	for i, r := range reports {
+		if !drop[i] {
+			kept = append(kept, r)
+		}
+	}
+	return kept, len(reports) - len(kept)
+}
+
+// prune re-applies the retention policy to the cached reports.
+func (c *benchmarkCache) prune(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept, pruned := c.retention.apply(c.reports, now)
+	if pruned > 0 {
+		c.reports = kept
+		c.index = buildSearchIndex(kept)
+		c.prunedTotal += pruned
+	}
+	c.lastPrunedAt = now
+	return pruned
+}
+
+// purgeExperiment drops every cached report of experiment.
+func (c *benchmarkCache) purgeExperiment(experiment string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reports == nil {
+		return 0
+	}
+	kept := make([]BenchmarkReport, 0, len(c.reports))
+	for _, r := range c.reports {
+		if reportExperiment(r) != experiment {
+			kept = append(kept, r)
+		}
+	}
+	purged := len(c.reports) - len(kept)
+	if purged > 0 {
+		c.reports = kept
+		c.index = buildSearchIndex(kept)
+	}
+	return purged
+}
+
+// RetentionStatus describes the retention policy and the cache it bounds.
+type RetentionStatus struct {
+	Policy       RetentionPolicy `json:"policy"`
+	CachedTotal  int             `json:"cachedTotal"`
+	Experiments  map[string]int  `json:"experiments"`
+	PrunedTotal  int             `json:"prunedTotal"`
+	LastPrunedAt *time.Time      `json:"lastPrunedAt,omitempty"`
+}
+
+func (c *benchmarkCache) status() RetentionStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	st := RetentionStatus{
+		Policy:      c.retention,
+		CachedTotal: len(c.reports),
+		Experiments: make(map[string]int),
+		PrunedTotal: c.prunedTotal,
+	}
+	for _, r := range c.reports {
+		st.Experiments[reportExperiment(r)]++
+	}
+	if !c.lastPrunedAt.IsZero() {
+		t := c.lastPrunedAt
+		st.LastPrunedAt = &t
+	}
+	return st
+}
+
+// StartRetentionPruner re-applies the retention policy to cached reports
+// every hour until done is closed, so reports age out even when no fresh
+// fetch replaces the cache.
+func (h *BenchmarkHandlers) StartRetentionPruner(done <-chan struct{}) {
+	safego.GoWith("benchmarks/retention-pruner", func() {
+		ticker := time.NewTicker(retentionPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if pruned := h.cache.prune(now); pruned > 0 {
+					slog.Info("[benchmarks] pruned cached reports", "pruned", pruned)
+				}
+			}
+		}
+	})
+}
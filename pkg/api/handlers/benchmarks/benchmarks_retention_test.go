@@ -0,0 +1,176 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retentionReport(experiment, run string, ended time.Time) BenchmarkReport {
+	var r BenchmarkReport
+	r.Run.EID = experiment + "/" + run
+	r.Run.UID = r.Run.EID + "/stage-0"
+	if !ended.IsZero() {
+		r.Run.Time.End = ended.Format(time.RFC3339)
+	}
+	return r
+}
+
+func eids(reports []BenchmarkReport) []string {
+	out := make([]string, len(reports))
+	for i, r := range reports {
+		out[i] = r.Run.EID
+	}
+	return out
+}
+
+func TestRetentionPolicy_MaxAge(t *testing.T) {
+	now := time.Now()
+	reports := []BenchmarkReport{
+		retentionReport("exp", "old", now.Add(-48*time.Hour)),
+		retentionReport("exp", "new", now.Add(-time.Hour)),
+		retentionReport("exp", "undated", time.Time{}),
+	}
+	kept, pruned := RetentionPolicy{MaxAge: 24 * time.Hour}.apply(reports, now)
+	assert.Equal(t, 1, pruned)
+	assert.Equal(t, []string{"exp/new", "exp/undated"}, eids(kept), "undated reports cannot age out")
+	assert.Len(t, reports, 3, "input is not modified")
+}
+
+func TestRetentionPolicy_MaxPerExperiment(t *testing.T) {
+	now := time.Now()
+	reports := []BenchmarkReport{
+		retentionReport("a", "r1", now.Add(-3*time.Hour)),
+		retentionReport("b", "r1", now.Add(-3*time.Hour)),
+		retentionReport("a", "r2", now.Add(-1*time.Hour)),
+		retentionReport("a", "r3", now.Add(-2*time.Hour)),
+	}
+	kept, pruned := RetentionPolicy{MaxPerExperiment: 2}.apply(reports, now)
+	assert.Equal(t, 1, pruned)
+	assert.Equal(t, []string{"b/r1", "a/r2", "a/r3"}, eids(kept), "newest per experiment kept in original order")
+}
+
+func TestRetentionPolicy_ZeroKeepsEverything(t *testing.T) {
+	reports := []BenchmarkReport{retentionReport("a", "r1", time.Unix(0, 0))}
+	kept, pruned := RetentionPolicy{}.apply(reports, time.Now())
+	assert.Zero(t, pruned)
+	assert.Len(t, kept, 1)
+
+	kept, _ = RetentionPolicy{MaxAge: time.Hour}.apply(nil, time.Now())
+	assert.Nil(t, kept, "an empty cache stays empty")
+}
+
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	t.Setenv(envRetentionDays, "30")
+	t.Setenv(envRetentionPerExperiment, "0")
+	p := retentionPolicyFromEnv()
+	assert.Equal(t, 30*24*time.Hour, p.MaxAge)
+	assert.Zero(t, p.MaxPerExperiment)
+
+	t.Setenv(envRetentionDays, "soon")
+	t.Setenv(envRetentionPerExperiment, "-1")
+	p = retentionPolicyFromEnv()
+	assert.Equal(t, defaultRetentionDays*24*time.Hour, p.MaxAge)
+	assert.Equal(t, defaultRetentionPerExperiment, p.MaxPerExperiment)
+}
+
+func TestBenchmarkCache_SetAppliesRetention(t *testing.T) {
+	now := time.Now()
+	c := &benchmarkCache{ttl: time.Hour, retention: RetentionPolicy{MaxPerExperiment: 1}}
+	kept := c.set([]BenchmarkReport{
+		retentionReport("a", "r1", now.Add(-2*time.Hour)),
+		retentionReport("a", "r2", now.Add(-time.Hour)),
+	}, "0")
+	assert.Equal(t, []string{"a/r2"}, eids(kept))
+
+	cached, ok := c.get("0")
+	require.True(t, ok)
+	assert.Equal(t, kept, cached)
+	assert.Equal(t, 1, c.status().PrunedTotal)
+}
+
+func TestBenchmarkCache_PruneAgesOut(t *testing.T) {
+	now := time.Now()
+	c := &benchmarkCache{ttl: time.Hour, retention: RetentionPolicy{MaxAge: 24 * time.Hour}}
+	c.set([]BenchmarkReport{retentionReport("a", "r1", now.Add(-23*time.Hour))}, "0")
+
+	assert.Zero(t, c.prune(now))
+	assert.Equal(t, 1, c.prune(now.Add(2*time.Hour)))
+	st := c.status()
+	assert.Zero(t, st.CachedTotal)
+	require.NotNil(t, st.LastPrunedAt)
+}
+
+func setupBenchmarkAdminApp(t *testing.T, role models.UserRole) (*fiber.App, *BenchmarkHandlers) {
+	t.Helper()
+	mockStore := new(test.MockStore)
+	userID := uuid.New()
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	bench := NewBenchmarkHandlers("key", "folder")
+	bench.cache.retention = RetentionPolicy{}
+	now := time.Now()
+	bench.cache.set([]BenchmarkReport{
+		retentionReport("a", "r1", now),
+		retentionReport("b", "r1", now),
+		retentionReport("a", "r2", now),
+	}, "0")
+
+	h := NewBenchmarkAdminHandlers(bench, mockStore)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/admin/benchmarks/retention", h.GetRetention)
+	app.Delete("/api/admin/benchmarks/experiments/:experiment", h.PurgeExperiment)
+	return app, bench
+}
+
+func TestPurgeExperiment(t *testing.T) {
+	app, bench := setupBenchmarkAdminApp(t, models.UserRoleAdmin)
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/admin/benchmarks/experiments/a", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	var out struct {
+		Purged int `json:"purged"`
+	}
+	require.NoError(t, json.Unmarshal(body, &out))
+	assert.Equal(t, 2, out.Purged)
+
+	cached, ok := bench.cache.get("0")
+	require.True(t, ok)
+	assert.Equal(t, []string{"b/r1"}, eids(cached))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/admin/benchmarks/retention", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var st RetentionStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&st))
+	assert.Equal(t, 1, st.CachedTotal)
+	assert.Equal(t, map[string]int{"b": 1}, st.Experiments)
+}
+
+func TestBenchmarkAdmin_RequiresAdmin(t *testing.T) {
+	app, bench := setupBenchmarkAdminApp(t, models.UserRoleViewer)
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/api/admin/benchmarks/experiments/a", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, 3, bench.cache.status().CachedTotal)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/admin/benchmarks/retention", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
@@ -146,14 +146,14 @@ func TestFetchAllReports(t *testing.T) {
 func TestDownloadAndParseReport(t *testing.T) {
 	tests := []struct {
 		name           string
-		file           driveFile
+		file           SourceEntry
 		experimentName string
 		runName        string
 		expectError    bool
 	}{
 		{
 			name: "empty file ID",
-			file: driveFile{
+			file: SourceEntry{
 				ID:   "",
 				Name: "benchmark_report_1.yaml",
 			},
@@ -163,7 +163,7 @@ func TestDownloadAndParseReport(t *testing.T) {
 		},
 		{
 			name: "invalid file ID format",
-			file: driveFile{
+			file: SourceEntry{
 				ID:   "invalid-id",
 				Name: "benchmark_report_1.yaml",
 			},
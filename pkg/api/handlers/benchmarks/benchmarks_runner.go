@@ -10,10 +10,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/kubestellar/console/pkg/safego"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Google Drive API response types.
@@ -29,22 +25,27 @@ type driveFile struct {
 	CreatedTime string `json:"createdTime"`
 }
 
-// throttle ensures a minimum delay between Google Drive API requests
-// to avoid triggering anti-bot protection.
+// throttle ensures a minimum delay between Google Drive API requests to the
+// same host to avoid triggering anti-bot protection. Folder listings
+// (www.googleapis.com) and downloads (drive.google.com) are spaced out
+// separately, however many fetch workers wait on them.
 // The lock is only held briefly to read/update timestamps; the actual
 // sleep (if needed) happens outside the lock so concurrent goroutines
 // are not blocked for the full delay.
 // The context is checked so that cancellation is not blocked by sleep.
-func (h *BenchmarkHandlers) throttle(ctx context.Context) error {
+func (h *BenchmarkHandlers) throttle(ctx context.Context, host string) error {
 	h.reqMu.Lock()
-	elapsed := time.Since(h.lastReq)
+	if h.lastReq == nil {
+		h.lastReq = make(map[string]time.Time)
+	}
+	elapsed := time.Since(h.lastReq[host])
 	if elapsed >= driveRequestDelay {
-		h.lastReq = time.Now()
+		h.lastReq[host] = time.Now()
 		h.reqMu.Unlock()
 		return ctx.Err()
 	}
 	delay := driveRequestDelay - elapsed
-	h.lastReq = time.Now().Add(delay)
+	h.lastReq[host] = time.Now().Add(delay)
 	h.reqMu.Unlock()
 
 	select {
@@ -52,7 +53,7 @@ func (h *BenchmarkHandlers) throttle(ctx context.Context) error {
 		return nil
 	case <-ctx.Done():
 		h.reqMu.Lock()
-		h.lastReq = time.Time{}
+		delete(h.lastReq, host)
 		h.reqMu.Unlock()
 		return ctx.Err()
 	}
@@ -64,14 +65,17 @@ func (h *BenchmarkHandlers) driveGet(ctx context.Context, url string) (*http.Res
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	if err := h.throttle(ctx); err != nil {
-		return nil, err
-	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	if err := h.throttle(ctx, req.URL.Host); err != nil {
+		return nil, err
+	}
 	req.Header.Set("User-Agent", driveUserAgent)
+	if err := h.authorizeDrive(req); err != nil {
+		return nil, err
+	}
 	return h.client.Do(req)
 }
 
@@ -129,18 +133,27 @@ func (h *BenchmarkHandlers) fetchRunFolderStreaming(ctx context.Context, folderI
 // cutoff filters out folders older than the given time; zero means no filter.
 // Returns reports and a count of files that failed to download or parse.
 //
-// Experiment and run folders are processed concurrently (bounded by
-// driveFetchConcurrency). The per-request throttle() still serialises
-// actual HTTP calls so the Drive API rate limit is respected.
+// Experiment and run folders are fetched by a bounded worker pool; see
+// walkRunFolders. The per-host throttle() still spaces out the actual HTTP
+// calls so the Drive API rate limit is respected. A cancelled ctx stops the
+// walk and returns ctx.Err().
 func (h *BenchmarkHandlers) fetchAllReports(ctx context.Context, cutoff time.Time) ([]BenchmarkReport, int, error) {
-	topLevel, err := h.listDriveFolder(ctx, h.folderID)
+	return h.fetchAllReportsWithProgress(ctx, cutoff, nil)
+}
+
+// fetchAllReportsWithProgress is fetchAllReports calling onExperiment, if
+// not nil, each time an experiment folder has been fetched. Calls are
+// serialized, with done increasing.
+func (h *BenchmarkHandlers) fetchAllReportsWithProgress(ctx context.Context, cutoff time.Time, onExperiment func(done, total int)) ([]BenchmarkReport, int, error) {
+	source := h.reportSource()
+	topLevel, err := source.List(ctx, "")
 	if err != nil {
 		return nil, 0, fmt.Errorf("listing top-level folder: %w", err)
 	}
 
-	experiments := make([]driveFile, 0, len(topLevel))
+	experiments := make([]SourceEntry, 0, len(topLevel))
 	for _, item := range topLevel {
-		if item.MimeType != driveFolderMIME {
+		if !item.Folder {
 			continue
 		}
 		if !isAfterCutoff(item, cutoff) {
@@ -153,56 +166,39 @@ func (h *BenchmarkHandlers) fetchAllReports(ctx context.Context, cutoff time.Tim
 		mu            sync.Mutex
 		allReports    = make([]BenchmarkReport, 0)
 		totalFailures int
-		wg            sync.WaitGroup
-		experimentSem = make(chan struct{}, driveFetchConcurrency)
-		runSem        = make(chan struct{}, driveFetchConcurrency)
+		doneCount     int
 	)
-
-	for _, item := range experiments {
-		item := item
-		wg.Add(1)
-		experimentSem <- struct{}{}
-		safego.Go(func() {
-			defer wg.Done()
-			defer func() { <-experimentSem }()
-
-			runFolders, listErr := h.listDriveFolder(ctx, item.ID)
-			if listErr != nil {
-				slog.Error("[benchmarks] error listing experiment", "experiment", item.Name, "error", listErr)
-				return
-			}
-
-			var innerWg sync.WaitGroup
-			for _, runItem := range runFolders {
-				if runItem.MimeType != driveFolderMIME {
-					continue
+	walk := folderWalk{
+		cutoff: cutoff,
+		fetch: func(ctx context.Context, experiment, run SourceEntry) {
+			reports, failures, runErr := h.fetchRunFolder(ctx, run.ID, experiment.Name, run.Name)
+			if runErr != nil {
+				if ctx.Err() == nil {
+					slog.Error("[benchmarks] error in experiment run", "experiment", experiment.Name, "run", run.Name, "error", runErr)
 				}
-				if !isAfterCutoff(runItem, cutoff) {
-					continue
-				}
-				runItem := runItem
-				innerWg.Add(1)
-				runSem <- struct{}{}
-				safego.Go(func() {
-					defer innerWg.Done()
-					defer func() { <-runSem }()
-
-					reports, failures, runErr := h.fetchRunFolder(ctx, runItem.ID, item.Name, runItem.Name)
-					if runErr != nil {
-						slog.Error("[benchmarks] error in experiment run", "experiment", item.Name, "run", runItem.Name, "error", runErr)
-						return
-					}
-					mu.Lock()
-					allReports = append(allReports, reports...)
-					totalFailures += failures
-					mu.Unlock()
-				})
+				return
 			}
-			innerWg.Wait()
-		})
+			mu.Lock()
+			allReports = append(allReports, reports...)
+			totalFailures += failures
+			mu.Unlock()
+		},
+	}
+	if onExperiment != nil {
+		walk.onExperiment = func(SourceEntry) {
+			mu.Lock()
+			defer mu.Unlock()
+			doneCount++
+			onExperiment(doneCount, len(experiments))
+		}
 	}
-	wg.Wait()
+	h.walkRunFolders(ctx, experiments, walk)
 
+	// A cancelled walk dropped folders; the partial result must not be
+	// cached as if it were complete.
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
 	return allReports, totalFailures, nil
 }
 
@@ -210,20 +206,21 @@ func (h *BenchmarkHandlers) fetchAllReports(ctx context.Context, cutoff time.Tim
 // Handles nested layouts: run → results → individual-result → benchmark_report*.yaml.
 // Returns reports and a count of files that failed to download or parse.
 func (h *BenchmarkHandlers) fetchRunFolder(ctx context.Context, folderID, experimentName, runName string) ([]BenchmarkReport, int, error) {
-	items, err := h.listDriveFolder(ctx, folderID)
+	source := h.reportSource()
+	items, err := source.List(ctx, folderID)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	reports := make([]BenchmarkReport, 0, len(items))
-	subfolders := make([]driveFile, 0, len(items)/2)
+	subfolders := make([]SourceEntry, 0, len(items)/2)
 	parseFailures := 0
 	for _, file := range items {
-		if file.MimeType == driveFolderMIME {
+		if file.Folder {
 			subfolders = append(subfolders, file)
 			continue
 		}
-		if strings.HasPrefix(file.Name, benchmarkFilePrefix) && strings.HasSuffix(file.Name, benchmarkFileSuffix) {
+		if isReportFile(file.Name) {
 			report, err := h.downloadAndParseReport(ctx, file, experimentName, runName)
 			if err != nil {
 				parseFailures++
@@ -240,13 +237,13 @@ func (h *BenchmarkHandlers) fetchRunFolder(ctx context.Context, folderID, experi
 		if !strings.EqualFold(subfolder.Name, "results") {
 			continue
 		}
-		resultFolders, err := h.listDriveFolder(ctx, subfolder.ID)
+		resultFolders, err := source.List(ctx, subfolder.ID)
 		if err != nil {
 			slog.Error("[benchmarks] error listing results", "experiment", experimentName, "run", runName, "error", err)
 			continue
 		}
 		for _, resultFolder := range resultFolders {
-			if resultFolder.MimeType != driveFolderMIME {
+			if !resultFolder.Folder {
 				continue
 			}
 			resultReports, failures, collectErr := h.collectBenchmarkFiles(ctx, resultFolder.ID, experimentName, runName)
@@ -263,7 +260,7 @@ func (h *BenchmarkHandlers) fetchRunFolder(ctx context.Context, folderID, experi
 // collectBenchmarkFiles finds and parses benchmark YAML files in a single folder.
 // Returns the parsed reports and a count of files that failed to parse.
 func (h *BenchmarkHandlers) collectBenchmarkFiles(ctx context.Context, folderID, experimentName, runName string) ([]BenchmarkReport, int, error) {
-	files, err := h.listDriveFolder(ctx, folderID)
+	files, err := h.reportSource().List(ctx, folderID)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -271,10 +268,10 @@ func (h *BenchmarkHandlers) collectBenchmarkFiles(ctx context.Context, folderID,
 	reports := make([]BenchmarkReport, 0, len(files))
 	parseFailures := 0
 	for _, file := range files {
-		if file.MimeType == driveFolderMIME {
+		if file.Folder {
 			continue
 		}
-		if strings.HasPrefix(file.Name, benchmarkFilePrefix) && strings.HasSuffix(file.Name, benchmarkFileSuffix) {
+		if isReportFile(file.Name) {
 			report, err := h.downloadAndParseReport(ctx, file, experimentName, runName)
 			if err != nil {
 				parseFailures++
@@ -287,19 +284,20 @@ func (h *BenchmarkHandlers) collectBenchmarkFiles(ctx context.Context, folderID,
 }
 
 // downloadAndParseReport downloads a single benchmark YAML file and parses it.
-func (h *BenchmarkHandlers) downloadAndParseReport(ctx context.Context, file driveFile, experimentName, runName string) (BenchmarkReport, error) {
-	data, err := h.downloadDriveFile(ctx, file.ID)
+func (h *BenchmarkHandlers) downloadAndParseReport(ctx context.Context, file SourceEntry, experimentName, runName string) (BenchmarkReport, error) {
+	data, err := h.reportSource().Read(ctx, file.ID)
 	if err != nil {
 		slog.Error("[benchmarks] error downloading file", "file", file.Name, "error", err)
 		return BenchmarkReport{}, err
 	}
 
-	var raw rawV1Report
-	if err := yaml.Unmarshal(data, &raw); err != nil {
+	report, err := parseReport(data, file, experimentName, runName)
+	if err != nil {
 		slog.Error("[benchmarks] error parsing file", "file", file.Name, "error", err)
 		return BenchmarkReport{}, err
 	}
-	return adaptV1ToV2(raw, experimentName, runName, file.CreatedTime), nil
+	h.prepareReport(&report)
+	return report, nil
 }
 
 // listDriveFolder lists all files in a Google Drive folder, handling pagination
@@ -308,8 +306,13 @@ func (h *BenchmarkHandlers) listDriveFolder(ctx context.Context, folderID string
 	allFiles := make([]driveFile, 0)
 	pageToken := ""
 
+	// A service account authenticates with a bearer token instead of the key.
+	key := ""
+	if h.driveToken == nil {
+		key = "&key=" + h.apiKey
+	}
 	for {
-		reqURL := fmt.Sprintf("%s?q='%s'+in+parents&key=%s&fields=files(id,name,mimeType,createdTime),nextPageToken&pageSize=1000&supportsAllDrives=true&includeItemsFromAllDrives=true", driveAPIBase, folderID, h.apiKey)
+		reqURL := fmt.Sprintf("%s?q='%s'+in+parents%s&fields=files(id,name,mimeType,createdTime),nextPageToken&pageSize=1000&supportsAllDrives=true&includeItemsFromAllDrives=true", driveAPIBase, folderID, key)
 		if pageToken != "" {
 			reqURL += "&pageToken=" + pageToken
 		}
@@ -354,8 +357,13 @@ func (h *BenchmarkHandlers) listDriveFolder(ctx context.Context, folderID string
 // downloadDriveFile downloads file content from Google Drive.
 // Uses webContentLink (drive.google.com/uc?id=...&export=download) which is more
 // resilient to Google's anti-bot protection than the API's alt=media endpoint.
+// The link only serves public files, so a service account, which reads
+// private ones, uses alt=media.
 func (h *BenchmarkHandlers) downloadDriveFile(ctx context.Context, fileID string) ([]byte, error) {
 	downloadURL := fmt.Sprintf("https://drive.google.com/uc?id=%s&export=download", fileID)
+	if h.driveToken != nil {
+		downloadURL = fmt.Sprintf("%s/%s?alt=media&supportsAllDrives=true", driveAPIBase, fileID)
+	}
 
 	resp, err := h.driveGet(ctx, downloadURL)
 	if err != nil {
@@ -371,12 +379,5 @@ func (h *BenchmarkHandlers) downloadDriveFile(ctx context.Context, fileID string
 		return nil, fmt.Errorf("Drive download returned %d: %s", resp.StatusCode, string(body))
 	}
 
-	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBenchmarkReportBytes+1))
-	if err != nil {
-		return nil, err
-	}
-	if int64(len(data)) > maxBenchmarkReportBytes {
-		return nil, fmt.Errorf("Drive download exceeded max size of %d bytes", maxBenchmarkReportBytes)
-	}
-	return data, nil
+	return readReport(resp.Body)
 }
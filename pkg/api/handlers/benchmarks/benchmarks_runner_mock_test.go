@@ -475,7 +475,7 @@ func TestDownloadAndParseReport_WithMockServer(t *testing.T) {
 		h := &BenchmarkHandlers{client: client, apiKey: "test-key"}
 		ctx := context.Background()
 
-		file := driveFile{ID: "f1", Name: "benchmark_report_test.yaml", CreatedTime: "2025-06-01T10:00:00Z"}
+		file := SourceEntry{ID: "f1", Name: "benchmark_report_test.yaml", CreatedTime: "2025-06-01T10:00:00Z"}
 		report, err := h.downloadAndParseReport(ctx, file, "exp1", "run1")
 		require.NoError(t, err)
 		assert.Contains(t, report.Run.EID, "exp1")
@@ -492,7 +492,7 @@ func TestDownloadAndParseReport_WithMockServer(t *testing.T) {
 		h := &BenchmarkHandlers{client: client, apiKey: "test-key"}
 		ctx := context.Background()
 
-		file := driveFile{ID: "f1", Name: "benchmark_report_bad.yaml", CreatedTime: "2025-06-01T10:00:00Z"}
+		file := SourceEntry{ID: "f1", Name: "benchmark_report_bad.yaml", CreatedTime: "2025-06-01T10:00:00Z"}
 		_, err := h.downloadAndParseReport(ctx, file, "exp1", "run1")
 		require.Error(t, err)
 	})
@@ -507,7 +507,7 @@ func TestDownloadAndParseReport_WithMockServer(t *testing.T) {
 		h := &BenchmarkHandlers{client: client, apiKey: "test-key"}
 		ctx := context.Background()
 
-		file := driveFile{ID: "missing", Name: "benchmark_report_missing.yaml", CreatedTime: "2025-06-01T10:00:00Z"}
+		file := SourceEntry{ID: "missing", Name: "benchmark_report_missing.yaml", CreatedTime: "2025-06-01T10:00:00Z"}
 		_, err := h.downloadAndParseReport(ctx, file, "exp1", "run1")
 		require.Error(t, err)
 	})
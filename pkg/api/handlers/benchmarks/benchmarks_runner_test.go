@@ -17,8 +17,8 @@ func TestThrottle(t *testing.T) {
 		ctx := context.Background()
 
 		start := time.Now()
-		require.NoError(t, h.throttle(ctx))
-		require.NoError(t, h.throttle(ctx))
+		require.NoError(t, h.throttle(ctx, "www.googleapis.com"))
+		require.NoError(t, h.throttle(ctx, "www.googleapis.com"))
 		elapsed := time.Since(start)
 
 		require.GreaterOrEqual(t, elapsed, driveRequestDelay, "should wait for minimum delay between requests")
@@ -28,10 +28,10 @@ func TestThrottle(t *testing.T) {
 		h := &BenchmarkHandlers{}
 		ctx, cancel := context.WithCancel(context.Background())
 
-		require.NoError(t, h.throttle(ctx))
+		require.NoError(t, h.throttle(ctx, "www.googleapis.com"))
 
 		cancel()
-		err := h.throttle(ctx)
+		err := h.throttle(ctx, "www.googleapis.com")
 		require.Error(t, err)
 		require.Equal(t, context.Canceled, err)
 	})
@@ -48,7 +48,7 @@ func TestThrottle(t *testing.T) {
 		for i := 0; i < numCalls; i++ {
 			go func() {
 				defer wg.Done()
-				_ = h.throttle(ctx)
+				_ = h.throttle(ctx, "www.googleapis.com")
 			}()
 		}
 		wg.Wait()
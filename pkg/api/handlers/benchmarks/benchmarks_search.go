@@ -0,0 +1,352 @@
+package benchmarks
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultSearchLimit and maxSearchLimit bound how many run UIDs one
+	// search returns.
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+	// maxSearchQueryLen bounds the free-text q parameter.
+	maxSearchQueryLen = 256
+)
+
+// searchIndex is an inverted index over the cached reports. It is rebuilt
+// whenever the cached report set changes, so a lookup never scans reports.
+// Positions refer to the report slice the index was built from.
+type searchIndex struct {
+	uids []string
+	// terms maps a lowercased free-text token to the sorted positions of
+	// the reports containing it; sortedTerms allows prefix matches.
+	terms       map[string][]int
+	sortedTerms []string
+	// fields maps a structured field ("model", "accelerator", "tool",
+	// "experiment") to lowercased values and their positions.
+	fields map[string]map[string][]int
+	// qps is each report's load rate, NaN when unknown.
+	qps []float64
+	// parallelism holds every dp/tp/pp/ep value seen in a report's stack.
+	parallelism []map[string][]int
+}
+
+// Structured search fields.
+const (
+	searchFieldModel       = "model"
+	searchFieldAccelerator = "accelerator"
+	searchFieldTool        = "tool"
+	searchFieldExperiment  = "experiment"
+)
+
+var (
+	searchFields          = []string{searchFieldModel, searchFieldAccelerator, searchFieldTool, searchFieldExperiment}
+	searchParallelismDims = []string{"dp", "tp", "pp", "ep"}
+)
+
+func buildSearchIndex(reports []BenchmarkReport) *searchIndex {
+	idx := &searchIndex{
+		uids:        make([]string, len(reports)),
+		terms:       make(map[string][]int),
+		fields:      make(map[string]map[string][]int, len(searchFields)),
+		qps:         make([]float64, len(reports)),
+		parallelism: make([]map[string][]int, len(reports)),
+	}
+	for _, f := range searchFields {
+		idx.fields[f] = make(map[string][]int)
+	}
+
+	for i := range reports {
+		r := &reports[i]
+		idx.uids[i] = r.Run.UID
+		idx.qps[i] = math.NaN()
+		if q := r.Scenario.Load.Standardized.RateQPS; q != nil {
+			idx.qps[i] = *q
+		}
+
+		text := []string{r.Run.UID, r.Run.EID, r.Scenario.Load.Standardized.Tool}
+		idx.addField(searchFieldExperiment, reportExperiment(*r), i)
+		idx.addField(searchFieldTool, r.Scenario.Load.Standardized.Tool, i)
+		dims := map[string][]int{}
+		for _, c := range r.Scenario.Stack {
+			s := c.Standardized
+			text = append(text, s.Tool, s.ToolVersion, s.Role, c.Metadata.Label)
+			idx.addField(searchFieldTool, s.Tool, i)
+			if s.Model != nil {
+				text = append(text, s.Model.Name, s.Model.Quantization)
+				idx.addField(searchFieldModel, s.Model.Name, i)
+			}
+			if s.Accelerator != nil {
+				text = append(text, s.Accelerator.Model)
+				idx.addField(searchFieldAccelerator, s.Accelerator.Model, i)
+				if p := s.Accelerator.Parallelism; p != nil {
+					dims["dp"] = append(dims["dp"], p.DP)
+					dims["tp"] = append(dims["tp"], p.TP)
+					dims["pp"] = append(dims["pp"], p.PP)
+					dims["ep"] = append(dims["ep"], p.EP)
+				}
+			}
+		}
+		idx.parallelism[i] = dims
+
+		seen := map[string]bool{}
+		for _, s := range text {
+			for _, tok := range searchTokens(s) {
+				if !seen[tok] {
+					seen[tok] = true
+					idx.terms[tok] = append(idx.terms[tok], i)
+				}
+			}
+		}
+	}
+
+	idx.sortedTerms = make([]string, 0, len(idx.terms))
+	for t := range idx.terms {
+		idx.sortedTerms = append(idx.sortedTerms, t)
+	}
+	sort.Strings(idx.sortedTerms)
+	return idx
+}
+
+// addField records value for position i. Positions are appended in order,
+// so each posting list stays sorted without further work.
+func (idx *searchIndex) addField(field, value string, i int) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return
+	}
+	postings := idx.fields[field][value]
+	if n := len(postings); n > 0 && postings[n-1] == i {
+		return
+	}
+	idx.fields[field][value] = append(postings, i)
+}
+
+// searchTokens splits s into lowercased letter/digit runs, keeping '.' inside
+// a run so versions like "0.8" stay one token.
+func searchTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+}
+
+// BenchmarkSearchQuery is a parsed search request. Zero values do not filter.
+type BenchmarkSearchQuery struct {
+	Text        string
+	Fields      map[string]string
+	QPSMin      *float64
+	QPSMax      *float64
+	Parallelism map[string]int
+	// UIDs restricts matches to these run UIDs when non-nil.
+	UIDs  map[string]bool
+	Limit int
+}
+
+// search returns the UIDs of matching reports in cache order and the total
+// number of matches before Limit is applied. Every free-text token must
+// prefix-match some indexed token, and every structured filter must match.
+func (idx *searchIndex) search(q BenchmarkSearchQuery) ([]string, int) {
+	var candidates []int
+	all := true
+	narrow := func(postings []int) {
+		if all {
+			candidates, all = postings, false
+			return
+		}
+		candidates = intersectPostings(candidates, postings)
+	}
+
+	for _, tok := range searchTokens(q.Text) {
+		narrow(idx.prefixPostings(tok))
+	}
+	for field, value := range q.Fields {
+		narrow(idx.fields[field][strings.ToLower(strings.TrimSpace(value))])
+	}
+	if all {
+		candidates = make([]int, len(idx.uids))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	uids := []string{}
+	total := 0
+	for _, i := range candidates {
+		if q.UIDs != nil && !q.UIDs[idx.uids[i]] {
+			continue
+		}
+		if !idx.matchesNumeric(i, q) {
+			continue
+		}
+		total++
+		if len(uids) < q.Limit {
+			uids = append(uids, idx.uids[i])
+		}
+	}
+	return uids, total
+}
+
+func (idx *searchIndex) matchesNumeric(i int, q BenchmarkSearchQuery) bool {
+	if q.QPSMin != nil || q.QPSMax != nil {
+		qps := idx.qps[i]
+		if math.IsNaN(qps) {
+			return false
+		}
+		if (q.QPSMin != nil && qps < *q.QPSMin) || (q.QPSMax != nil && qps > *q.QPSMax) {
+			return false
+		}
+	}
+	for dim, want := range q.Parallelism {
+		found := false
+		for _, v := range idx.parallelism[i][dim] {
+			if v == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// prefixPostings returns the sorted union of postings of every term that
+// starts with prefix.
+func (idx *searchIndex) prefixPostings(prefix string) []int {
+	var merged []int
+	for i := sort.SearchStrings(idx.sortedTerms, prefix); i < len(idx.sortedTerms); i++ {
+		term := idx.sortedTerms[i]
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		merged = unionPostings(merged, idx.terms[term])
+	}
+	return merged
+}
+
+func intersectPostings(a, b []int) []int {
+	out := make([]int, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return out
+}
+
+func unionPostings(a, b []int) []int {
+	out := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		default:
+			out = append(out, b[j])
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+// search runs q against the index of the cached reports.
+func (c *benchmarkCache) search(q BenchmarkSearchQuery) (uids []string, total, indexed int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.index == nil {
+		return []string{}, 0, 0
+	}
+	uids, total = c.index.search(q)
+	return uids, total, len(c.index.uids)
+}
+
+// parseSearchQuery reads the search query parameters.
+func parseSearchQuery(c *fiber.Ctx) (BenchmarkSearchQuery, error) {
+	q := BenchmarkSearchQuery{
+		Text:        strings.TrimSpace(c.Query("q")),
+		Fields:      map[string]string{},
+		Parallelism: map[string]int{},
+		Limit:       defaultSearchLimit,
+	}
+	if len(q.Text) > maxSearchQueryLen {
+		return q, fiber.NewError(fiber.StatusBadRequest, "q is too long")
+	}
+	for _, f := range searchFields {
+		if v := strings.TrimSpace(c.Query(f)); v != "" {
+			q.Fields[f] = v
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **float64
+	}{{"qps_min", &q.QPSMin}, {"qps_max", &q.QPSMax}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+			return q, fiber.NewError(fiber.StatusBadRequest, "invalid "+p.name)
+		}
+		*p.dst = &v
+	}
+	for _, dim := range searchParallelismDims {
+		raw := c.Query(dim)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return q, fiber.NewError(fiber.StatusBadRequest, "invalid "+dim)
+		}
+		q.Parallelism[dim] = v
+	}
+	if raw := c.Query("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return q, fiber.NewError(fiber.StatusBadRequest, "invalid limit")
+		}
+		q.Limit = min(v, maxSearchLimit)
+	}
+	return q, nil
+}
+
+// SearchReports returns the UIDs of cached benchmark runs matching a
+// free-text query and structured filters. Only reports already fetched into
+// the cache are searched; "indexed" says how many that is. starred and label
+// filter on run annotations.
+// GET /api/benchmarks/search?q=&model=&accelerator=&tool=&experiment=&qps_min=&qps_max=&dp=&tp=&pp=&ep=&starred=&label=&limit=
+func (h *BenchmarkHandlers) SearchReports(c *fiber.Ctx) error {
+	if isDemoMode(c) {
+		return c.JSON(fiber.Map{"uids": []string{}, "total": 0, "indexed": 0, "source": "demo"})
+	}
+	q, err := parseSearchQuery(c)
+	if err != nil {
+		return err
+	}
+	if f := parseAnnotationFilter(c); f.active() {
+		q.UIDs = annotatedUIDs(h.loadAnnotations(c.UserContext()), f)
+	}
+	uids, total, indexed := h.cache.search(q)
+	return sendConditional(c, fiber.Map{"uids": uids, "total": total, "indexed": indexed, "source": "cache"})
+}
@@ -0,0 +1,133 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchReport(experiment, run, model, accelerator string, qps float64, tp int) BenchmarkReport {
+	r := retentionReport(experiment, run, time.Now())
+	r.Scenario.Load.Standardized.Tool = "inference-perf"
+	r.Scenario.Load.Standardized.RateQPS = &qps
+	var c BenchmarkStackComponent
+	c.Metadata.Label = "decode-0"
+	c.Standardized.Tool = "vllm"
+	c.Standardized.ToolVersion = "0.8.5"
+	c.Standardized.Role = "decode"
+	c.Standardized.Model = &BenchmarkModelRef{Name: model}
+	c.Standardized.Accelerator = &BenchmarkAccelerator{
+		Model:       accelerator,
+		Count:       8,
+		Parallelism: &BenchmarkParallelism{DP: 1, TP: tp, PP: 1, EP: 1},
+	}
+	r.Scenario.Stack = []BenchmarkStackComponent{c}
+	return r
+}
+
+func searchFixtures() []BenchmarkReport {
+	return []BenchmarkReport{
+		searchReport("llama-sweep", "r1", "meta-llama/Llama-3.1-8B", "H100", 10, 1),
+		searchReport("llama-sweep", "r2", "meta-llama/Llama-3.1-8B", "H100", 40, 2),
+		searchReport("mixtral", "r1", "mistralai/Mixtral-8x7B", "A100", 20, 4),
+		retentionReport("bare", "r1", time.Now()),
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	idx := buildSearchIndex(searchFixtures())
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name string
+		q    BenchmarkSearchQuery
+		want []string
+	}{
+		{name: "no filters returns everything", q: BenchmarkSearchQuery{},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0", "mixtral/r1/stage-0", "bare/r1/stage-0"}},
+		{name: "free text", q: BenchmarkSearchQuery{Text: "llama"},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0"}},
+		{name: "free text is AND of prefixes", q: BenchmarkSearchQuery{Text: "Mixt a100"},
+			want: []string{"mixtral/r1/stage-0"}},
+		{name: "free text matches tool versions", q: BenchmarkSearchQuery{Text: "vllm 0.8"},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0", "mixtral/r1/stage-0"}},
+		{name: "unmatched text", q: BenchmarkSearchQuery{Text: "gemma"}, want: []string{}},
+		{name: "structured accelerator is case-insensitive", q: BenchmarkSearchQuery{Fields: map[string]string{"accelerator": "h100"}},
+			want: []string{"llama-sweep/r1/stage-0", "llama-sweep/r2/stage-0"}},
+		{name: "structured model is exact", q: BenchmarkSearchQuery{Fields: map[string]string{"model": "Llama"}}, want: []string{}},
+		{name: "experiment and tool", q: BenchmarkSearchQuery{Fields: map[string]string{"experiment": "mixtral", "tool": "inference-perf"}},
+			want: []string{"mixtral/r1/stage-0"}},
+		{name: "qps range", q: BenchmarkSearchQuery{QPSMin: f(15), QPSMax: f(40)},
+			want: []string{"llama-sweep/r2/stage-0", "mixtral/r1/stage-0"}},
+		{name: "parallelism", q: BenchmarkSearchQuery{Text: "llama", Parallelism: map[string]int{"tp": 2}},
+			want: []string{"llama-sweep/r2/stage-0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.q.Limit = defaultSearchLimit
+			got, total := idx.search(tt.q)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.want), total)
+		})
+	}
+
+	got, total := idx.search(BenchmarkSearchQuery{Limit: 1})
+	assert.Len(t, got, 1)
+	assert.Equal(t, 4, total, "total counts matches beyond the limit")
+}
+
+func TestBenchmarkCache_SearchIndexFollowsCache(t *testing.T) {
+	c := &benchmarkCache{ttl: time.Hour}
+	uids, _, indexed := c.search(BenchmarkSearchQuery{Limit: defaultSearchLimit})
+	assert.Empty(t, uids)
+	assert.Zero(t, indexed, "nothing is indexed before the first fetch")
+
+	c.set(searchFixtures(), "0")
+	_, total, indexed := c.search(BenchmarkSearchQuery{Text: "llama", Limit: defaultSearchLimit})
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 4, indexed)
+
+	c.purgeExperiment("llama-sweep")
+	_, total, indexed = c.search(BenchmarkSearchQuery{Text: "llama", Limit: defaultSearchLimit})
+	assert.Zero(t, total, "purged reports leave the index")
+	assert.Equal(t, 2, indexed)
+}
+
+func TestSearchReports_Handler(t *testing.T) {
+	h := NewBenchmarkHandlers("key", "folder")
+	h.cache.retention = RetentionPolicy{}
+	h.cache.set(searchFixtures(), "0")
+	app := fiber.New()
+	app.Get("/api/benchmarks/search", h.SearchReports)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/search?q=llama&accelerator=H100&qps_min=20&limit=5", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var out struct {
+		UIDs    []string `json:"uids"`
+		Total   int      `json:"total"`
+		Indexed int      `json:"indexed"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.Equal(t, []string{"llama-sweep/r2/stage-0"}, out.UIDs)
+	assert.Equal(t, 1, out.Total)
+	assert.Equal(t, 4, out.Indexed)
+
+	for _, bad := range []string{"qps_min=fast", "qps_max=-1", "tp=x", "limit=0"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/benchmarks/search?"+bad, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, bad)
+	}
+
+	req := httptest.NewRequest("GET", "/api/benchmarks/search?q=llama", nil)
+	req.Header.Set("X-Demo-Mode", "true")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.Empty(t, out.UIDs)
+}
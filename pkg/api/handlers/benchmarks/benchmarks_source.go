@@ -0,0 +1,154 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// EnvBenchmarkSource selects where benchmark reports are read from. Unset
+// means the Google Drive folder of GOOGLE_DRIVE_API_KEY and
+// BENCHMARK_FOLDER_ID; see NewBenchmarkSource for the other forms.
+const EnvBenchmarkSource = "BENCHMARK_SOURCE"
+
+// BenchmarkSource is a tree of benchmark_report YAML files laid out as
+// experiment/run/... folders, the layout the llm-d benchmark runs upload to
+// Google Drive. Implementations must be safe for concurrent use.
+type BenchmarkSource interface {
+	// Name identifies the source in logs, e.g. "s3://bucket/prefix".
+	Name() string
+	// List returns the entries directly inside a folder; "" is the root.
+	List(ctx context.Context, folderID string) ([]SourceEntry, error)
+	// Read returns the content of a file, failing for files larger than
+	// maxBenchmarkReportBytes.
+	Read(ctx context.Context, fileID string) ([]byte, error)
+}
+
+// SourceEntry is a file or folder of a BenchmarkSource.
+type SourceEntry struct {
+	// ID is what List or Read takes to open the entry.
+	ID     string
+	Name   string
+	Folder bool
+	// CreatedTime is RFC3339, or empty when the source has none. Folders
+	// created before a ?since= cutoff are skipped.
+	CreatedTime string
+}
+
+// NewBenchmarkSource returns the source spec names:
+//
+//	s3://bucket/prefix       Amazon S3 or an S3-compatible store
+//	gs://bucket/prefix       Google Cloud Storage
+//	file:///path or /path    a local directory
+//	https://host/index.json  an HTTP index of report files
+//
+// Object store credentials come from the environment; see
+// newS3SourceFromEnv and newGCSSourceFromEnv. Network sources use client.
+func NewBenchmarkSource(spec string, client *http.Client) (BenchmarkSource, error) {
+	if strings.HasPrefix(spec, "/") {
+		return NewDirSource(spec)
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvBenchmarkSource, err)
+	}
+	switch u.Scheme {
+	case "s3", "gs":
+		if u.Host == "" {
+			return nil, fmt.Errorf("%s: %q names no bucket", EnvBenchmarkSource, spec)
+		}
+		if u.Scheme == "s3" {
+			return newS3SourceFromEnv(u.Host, u.Path, client)
+		}
+		return newGCSSourceFromEnv(u.Host, u.Path, client)
+	case "file":
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("%s: %q is not a local path", EnvBenchmarkSource, spec)
+		}
+		return NewDirSource(u.Path)
+	case "http", "https":
+		return NewHTTPIndexSource(spec, client)
+	}
+	return nil, fmt.Errorf("%s: unsupported source %q", EnvBenchmarkSource, spec)
+}
+
+// SetSource makes h read reports from src instead of Google Drive.
+func (h *BenchmarkHandlers) SetSource(src BenchmarkSource) {
+	h.source = src
+}
+
+// reportSource is the source h reads reports from: the one set with
+// SetSource, else Google Drive.
+func (h *BenchmarkHandlers) reportSource() BenchmarkSource {
+	if h.source != nil {
+		return h.source
+	}
+	return driveSource{h}
+}
+
+// configured reports whether h has a source to read: one set with SetSource,
+// or Google Drive with an API key or a service account.
+func (h *BenchmarkHandlers) configured() bool {
+	return h.source != nil || h.apiKey != "" || h.driveToken != nil
+}
+
+// isReportFile reports whether name is a benchmark report.
+func isReportFile(name string) bool {
+	return strings.HasPrefix(name, benchmarkFilePrefix) && strings.HasSuffix(name, benchmarkFileSuffix)
+}
+
+// readReport reads r up to maxBenchmarkReportBytes, failing rather than
+// truncating a larger file.
+func readReport(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBenchmarkReportBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBenchmarkReportBytes {
+		return nil, fmt.Errorf("report exceeded max size of %d bytes", maxBenchmarkReportBytes)
+	}
+	return data, nil
+}
+
+// driveSource reads the Google Drive folder of its handler, throttled and
+// retried as Drive requires.
+type driveSource struct {
+	h *BenchmarkHandlers
+}
+
+func (s driveSource) Name() string {
+	return "google-drive:" + s.h.folderID
+}
+
+func (s driveSource) List(ctx context.Context, folderID string) ([]SourceEntry, error) {
+	if folderID == "" {
+		folderID = s.h.folderID
+	}
+	files, err := s.h.listDriveFolder(ctx, folderID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SourceEntry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, SourceEntry{ID: f.ID, Name: f.Name, Folder: f.MimeType == driveFolderMIME, CreatedTime: f.CreatedTime})
+	}
+	return entries, nil
+}
+
+func (s driveSource) Read(ctx context.Context, fileID string) ([]byte, error) {
+	return s.h.downloadDriveFile(ctx, fileID)
+}
+
+// Probe lists the root folder of h's source to check it can be reached,
+// and returns the source's name and the number of entries in the root.
+func (h *BenchmarkHandlers) Probe(ctx context.Context) (string, int, error) {
+	if !h.configured() {
+		return "", 0, fmt.Errorf("no benchmark source configured")
+	}
+	source := h.reportSource()
+	entries, err := source.List(ctx, "")
+	return source.Name(), len(entries), err
+}
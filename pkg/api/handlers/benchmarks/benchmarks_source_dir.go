@@ -0,0 +1,66 @@
+package benchmarks
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+)
+
+// fsSource reads reports from a file system, using slash-separated paths
+// relative to its root as entry IDs.
+type fsSource struct {
+	name string
+	fsys fs.FS
+}
+
+// NewDirSource returns a source reading the directory dir. Paths are
+// resolved inside dir, so a symlink cannot lead a read outside it.
+func NewDirSource(dir string) (BenchmarkSource, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvBenchmarkSource, err)
+	}
+	return &fsSource{name: "file://" + dir, fsys: root.FS()}, nil
+}
+
+func (s *fsSource) Name() string {
+	return s.name
+}
+
+func (s *fsSource) List(ctx context.Context, folderID string) ([]SourceEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	dir := folderID
+	if dir == "" {
+		dir = "."
+	}
+	items, err := fs.ReadDir(s.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]SourceEntry, 0, len(items))
+	for _, item := range items {
+		entry := SourceEntry{ID: path.Join(folderID, item.Name()), Name: item.Name(), Folder: item.IsDir()}
+		if info, err := item.Info(); err == nil {
+			entry.CreatedTime = info.ModTime().UTC().Format(time.RFC3339)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *fsSource) Read(ctx context.Context, fileID string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := s.fsys.Open(fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readReport(f)
+}
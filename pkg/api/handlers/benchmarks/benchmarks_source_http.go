@@ -0,0 +1,163 @@
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxErrorBodyBytes bounds the part of an error response kept for the log.
+const maxErrorBodyBytes = 4 * 1024
+
+// httpIndex is the document an HTTP index source serves: every report file,
+// with paths relative to the index URL.
+type httpIndex struct {
+	Files []httpIndexFile `json:"files"`
+}
+
+type httpIndexFile struct {
+	Path        string `json:"path"`
+	CreatedTime string `json:"createdTime,omitempty"`
+}
+
+// httpIndexSource reads reports listed by a JSON index and served next to
+// it, which any static web server can host. Folders are the directories of
+// the listed paths.
+type httpIndexSource struct {
+	index  *url.URL
+	client *http.Client
+
+	mu   sync.Mutex
+	tree map[string][]SourceEntry
+}
+
+// NewHTTPIndexSource returns a source reading the index at indexURL.
+func NewHTTPIndexSource(indexURL string, client *http.Client) (BenchmarkSource, error) {
+	u, err := url.Parse(indexURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s: invalid index URL %q", EnvBenchmarkSource, indexURL)
+	}
+	return &httpIndexSource{index: u, client: client}, nil
+}
+
+func (s *httpIndexSource) Name() string {
+	return s.index.String()
+}
+
+// List fetches the index when listing the root, which every fetch starts
+// with, and answers the folders below it from that copy.
+func (s *httpIndexSource) List(ctx context.Context, folderID string) ([]SourceEntry, error) {
+	s.mu.Lock()
+	tree := s.tree
+	s.mu.Unlock()
+	if folderID == "" || tree == nil {
+		var err error
+		if tree, err = s.fetchIndex(ctx); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.tree = tree
+		s.mu.Unlock()
+	}
+	return tree[folderID], nil
+}
+
+func (s *httpIndexSource) Read(ctx context.Context, fileID string) ([]byte, error) {
+	resp, err := s.get(ctx, s.index.ResolveReference(&url.URL{Path: fileID}).String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return readReport(resp.Body)
+}
+
+func (s *httpIndexSource) fetchIndex(ctx context.Context) (map[string][]SourceEntry, error) {
+	resp, err := s.get(ctx, s.index.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := readReport(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var index httpIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("decoding index: %w", err)
+	}
+	return buildIndexTree(index.Files)
+}
+
+// get GETs rawURL and returns the response when it is a 200.
+func (s *httpIndexSource) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", driveUserAgent)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, fmt.Errorf("GET %s returned %d: %s", rawURL, resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
+
+// buildIndexTree turns the index's file paths into folder listings keyed by
+// folder path, "" being the root. A folder's created time is that of the
+// oldest file below it. Paths must be relative and stay below the index.
+func buildIndexTree(files []httpIndexFile) (map[string][]SourceEntry, error) {
+	tree := make(map[string][]SourceEntry)
+	folders := make(map[string]*SourceEntry)
+	subfolders := make(map[string][]string)
+	for _, f := range files {
+		p := path.Clean(f.Path)
+		if f.Path == "" || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || strings.Contains(p, ":") {
+			return nil, fmt.Errorf("index path %q must be relative to the index", f.Path)
+		}
+		parent := ""
+		segments := strings.Split(p, "/")
+		for _, name := range segments[:len(segments)-1] {
+			id := path.Join(parent, name)
+			if folder, ok := folders[id]; !ok {
+				folders[id] = &SourceEntry{ID: id, Name: name, Folder: true, CreatedTime: f.CreatedTime}
+				subfolders[parent] = append(subfolders[parent], id)
+			} else if earlierTime(f.CreatedTime, folder.CreatedTime) {
+				folder.CreatedTime = f.CreatedTime
+			}
+			parent = id
+		}
+		tree[parent] = append(tree[parent], SourceEntry{ID: p, Name: segments[len(segments)-1], CreatedTime: f.CreatedTime})
+	}
+	for parent, ids := range subfolders {
+		for _, id := range ids {
+			tree[parent] = append(tree[parent], *folders[id])
+		}
+	}
+	for _, entries := range tree {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	}
+	return tree, nil
+}
+
+// earlierTime reports whether RFC3339 time a is before b. A missing or
+// unparseable b is replaced by any valid a.
+func earlierTime(a, b string) bool {
+	ta, ok := parseDriveTime(a)
+	if !ok {
+		return false
+	}
+	tb, ok := parseDriveTime(b)
+	return !ok || ta.Before(tb)
+}
@@ -0,0 +1,297 @@
+package benchmarks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// envS3Endpoint points an s3:// source at an S3-compatible store such
+	// as MinIO, addressed path-style as <endpoint>/<bucket>.
+	envS3Endpoint = "BENCHMARK_S3_ENDPOINT"
+	// envGCSAccessID and envGCSSecret are a Cloud Storage HMAC key; without
+	// one a gs:// bucket is read anonymously, which works for public ones.
+	envGCSAccessID = "BENCHMARK_GCS_HMAC_ACCESS_ID"
+	envGCSSecret   = "BENCHMARK_GCS_HMAC_SECRET"
+
+	defaultS3Region = "us-east-1"
+	// gcsEndpoint is Cloud Storage's S3-compatible XML API, which accepts
+	// SigV4 requests signed with an HMAC key for any region.
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsRegion   = "auto"
+
+	// emptySHA256 is the SHA-256 of an empty body, the payload hash of a GET.
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Credentials sign requests; an empty AccessKeyID sends them unsigned.
+type s3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// s3Source reads reports from a bucket through the S3 REST API, with
+// "/"-delimited key prefixes as folders and keys as file IDs.
+type s3Source struct {
+	name   string
+	base   *url.URL // the bucket's URL
+	prefix string   // the root folder; empty or ending in "/"
+	region string
+	creds  s3Credentials
+	client *http.Client
+}
+
+// s3ListResult is the part of a ListObjectsV2 response the source reads.
+type s3ListResult struct {
+	IsTruncated           bool
+	NextContinuationToken string
+	Contents              []struct {
+		Key          string
+		LastModified string
+	}
+	CommonPrefixes []struct {
+		Prefix string
+	}
+}
+
+// newS3SourceFromEnv returns a source reading s3://bucket/prefix with the
+// standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and
+// AWS_REGION variables, and BENCHMARK_S3_ENDPOINT for other stores.
+func newS3SourceFromEnv(bucket, prefix string, client *http.Client) (BenchmarkSource, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = defaultS3Region
+	}
+	base := "https://" + bucket + ".s3." + region + ".amazonaws.com"
+	if endpoint := os.Getenv(envS3Endpoint); endpoint != "" {
+		base = strings.TrimSuffix(endpoint, "/") + "/" + bucket
+	}
+	creds := s3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	return newS3Source("s3://"+bucket, base, prefix, region, creds, client)
+}
+
+// newGCSSourceFromEnv returns a source reading gs://bucket/prefix with the
+// HMAC key in BENCHMARK_GCS_HMAC_ACCESS_ID and BENCHMARK_GCS_HMAC_SECRET.
+func newGCSSourceFromEnv(bucket, prefix string, client *http.Client) (BenchmarkSource, error) {
+	creds := s3Credentials{
+		AccessKeyID:     os.Getenv(envGCSAccessID),
+		SecretAccessKey: os.Getenv(envGCSSecret),
+	}
+	return newS3Source("gs://"+bucket, gcsEndpoint+"/"+bucket, prefix, gcsRegion, creds, client)
+}
+
+func newS3Source(name, base, prefix, region string, creds s3Credentials, client *http.Client) (*s3Source, error) {
+	u, err := url.Parse(base)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s: invalid bucket URL %q", EnvBenchmarkSource, base)
+	}
+	if (creds.AccessKeyID == "") != (creds.SecretAccessKey == "") {
+		return nil, fmt.Errorf("%s: %s needs both an access key and a secret", EnvBenchmarkSource, name)
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &s3Source{
+		name:   name + "/" + prefix,
+		base:   u,
+		prefix: prefix,
+		region: region,
+		creds:  creds,
+		client: client,
+	}, nil
+}
+
+func (s *s3Source) Name() string {
+	return s.name
+}
+
+func (s *s3Source) List(ctx context.Context, folderID string) ([]SourceEntry, error) {
+	prefix := folderID
+	if prefix == "" {
+		prefix = s.prefix
+	}
+	entries := make([]SourceEntry, 0)
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.get(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(io.LimitReader(resp.Body, maxBenchmarkReportBytes)).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decoding listing: %w", err)
+		}
+
+		for _, p := range result.CommonPrefixes {
+			entries = append(entries, SourceEntry{ID: p.Prefix, Name: path.Base(p.Prefix), Folder: true})
+		}
+		for _, obj := range result.Contents {
+			if obj.Key == prefix {
+				continue // a folder marker object
+			}
+			entry := SourceEntry{ID: obj.Key, Name: path.Base(obj.Key)}
+			if t, err := time.Parse(time.RFC3339, obj.LastModified); err == nil {
+				entry.CreatedTime = t.UTC().Format(time.RFC3339)
+			}
+			entries = append(entries, entry)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return entries, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *s3Source) Read(ctx context.Context, fileID string) ([]byte, error) {
+	resp, err := s.get(ctx, fileID, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return readReport(resp.Body)
+}
+
+// get GETs key in the bucket, or the bucket itself for an empty key, and
+// returns the response when it is a 200.
+func (s *s3Source) get(ctx context.Context, key string, query url.Values) (*http.Response, error) {
+	u := *s.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = awsURIEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", driveUserAgent)
+	if s.creds.AccessKeyID != "" {
+		signV4(req, s.creds, s.region, "s3", time.Now())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, fmt.Errorf("%s returned %d: %s", s.name, resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to a
+// bodiless request whose URL is already in canonical form.
+func signV4(req *http.Request, creds s3Credentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptySHA256)
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": emptySHA256,
+		"x-amz-date":           amzDate,
+	}
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+		headers["x-amz-security-token"] = creds.SessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		emptySHA256,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by key, as SigV4 signs it.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsURIEncode percent-encodes every byte of s but the RFC 3986 unreserved
+// characters, and "/" unless encodeSlash is set.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/transport"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// reportStreamWriter writes StreamReports events either as SSE or, when the
+// client asked for NDJSON, as one JSON object per line with a "type" field.
+// In NDJSON every report is its own "report" line instead of a batch, so
+// clients can decode reports one at a time as they are parsed.
+type reportStreamWriter struct {
+	w      io.Writer
+	ndjson bool
+}
+
+// newReportStream picks the stream format from the request and sets the
+// matching response headers.
+func newReportStream(c *fiber.Ctx) reportStreamWriter {
+	s := reportStreamWriter{w: c, ndjson: transport.WantsNDJSON(c)}
+	if s.ndjson {
+		transport.SetNDJSONHeaders(c)
+	} else {
+		c.Set("Content-Type", "text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+	}
+	return s
+}
+
+// to returns a writer of the same format writing to w (the stream writer
+// handed to SetBodyStreamWriter).
+func (s reportStreamWriter) to(w io.Writer) reportStreamWriter {
+	return reportStreamWriter{w: w, ndjson: s.ndjson}
+}
+
+// event writes a status event (progress, done, error) whose payload is fields.
+func (s reportStreamWriter) event(name string, fields fiber.Map) {
+	if s.ndjson {
+		line := fiber.Map{"type": name}
+		for k, v := range fields {
+			line[k] = v
+		}
+		s.writeLine(line)
+		return
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data)
+}
+
+// reports writes a batch of reports: one "batch" SSE event, or one
+// "report" line per report.
+func (s reportStreamWriter) reports(batch []BenchmarkReport) error {
+	if s.ndjson {
+		for i := range batch {
+			if err := transport.WriteNDJSON(s.w, fiber.Map{"type": "report", "report": batch[i]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "event: batch\ndata: %s\n\n", data)
+	return err
+}
+
+// annotations writes the run UID -> annotation map.
+func (s reportStreamWriter) annotations(annotations map[string]models.BenchmarkAnnotation) {
+	if s.ndjson {
+		s.writeLine(fiber.Map{"type": "annotations", "annotations": annotations})
+		return
+	}
+	if data, err := json.Marshal(annotations); err == nil {
+		fmt.Fprintf(s.w, "event: annotations\ndata: %s\n\n", data)
+	}
+}
+
+// keepalive writes a heartbeat: an SSE comment, or a "keepalive" line since
+// NDJSON has no comments.
+func (s reportStreamWriter) keepalive() {
+	if s.ndjson {
+		s.writeLine(fiber.Map{"type": "keepalive"})
+		return
+	}
+	fmt.Fprintf(s.w, ": keepalive\n\n")
+}
+
+func (s reportStreamWriter) writeLine(v interface{}) {
+	_ = transport.WriteNDJSON(s.w, v)
+}
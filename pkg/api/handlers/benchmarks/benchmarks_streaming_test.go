@@ -165,6 +165,53 @@ func TestStreamReports_SinceParameter(t *testing.T) {
 	assert.Contains(t, bodyStr, `"source":"cache"`)
 }
 
+func TestStreamReports_NDJSON(t *testing.T) {
+	app := fiber.New()
+	handler := NewBenchmarkHandlers("test-key", "test-folder")
+	handler.cache.set([]BenchmarkReport{{Version: "0.2"}, {Version: "0.3"}}, "0")
+	app.Get("/stream", handler.StreamReports)
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	require.Len(t, lines, 4, "one line per report, then annotations and done")
+
+	var report struct {
+		Type   string          `json:"type"`
+		Report BenchmarkReport `json:"report"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &report))
+	assert.Equal(t, "report", report.Type)
+	assert.Equal(t, "0.3", report.Report.Version)
+	assert.Contains(t, lines[2], `"type":"annotations"`)
+
+	var done map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &done))
+	assert.Equal(t, "done", done["type"])
+	assert.Equal(t, "cache", done["source"])
+	assert.Equal(t, 2.0, done["total"])
+}
+
+func TestReportStreamWriter_NDJSONEvents(t *testing.T) {
+	var buf strings.Builder
+	out := reportStreamWriter{w: &buf, ndjson: true}
+	out.event("progress", fiber.Map{"status": "connecting", "total": 0})
+	out.keepalive()
+	assert.Equal(t, "{\"status\":\"connecting\",\"total\":0,\"type\":\"progress\"}\n{\"type\":\"keepalive\"}\n", buf.String())
+
+	buf.Reset()
+	out.ndjson = false
+	out.keepalive()
+	assert.Equal(t, ": keepalive\n\n", buf.String())
+}
+
 // ---------- fetchAllReports ----------
 
 func TestFetchAllReports_ContextCancellation(t *testing.T) {
@@ -232,19 +279,19 @@ func TestFetchAllReports_CutoffFilter(t *testing.T) {
 	cutoff := time.Now().Add(-7 * 24 * time.Hour)
 
 	// Recent file should pass
-	recentFile := driveFile{
+	recentFile := SourceEntry{
 		ID:          "f1",
 		Name:        "exp-001",
-		MimeType:    driveFolderMIME,
+		Folder:      true,
 		CreatedTime: time.Now().Format(time.RFC3339),
 	}
 	assert.True(t, isAfterCutoff(recentFile, cutoff))
 
 	// Old file should be filtered
-	oldFile := driveFile{
+	oldFile := SourceEntry{
 		ID:          "f2",
 		Name:        "exp-002",
-		MimeType:    driveFolderMIME,
+		Folder:      true,
 		CreatedTime: time.Now().Add(-30 * 24 * time.Hour).Format(time.RFC3339),
 	}
 	assert.False(t, isAfterCutoff(oldFile, cutoff))
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// BrandingStore persists the white-label configuration. The settings
+// manager implements it.
+type BrandingStore interface {
+	GetBranding() settings.BrandingSettings
+	SaveBranding(settings.BrandingSettings) error
+}
+
+// BrandingHandler serves the white-label configuration.
+type BrandingHandler struct {
+	branding BrandingStore
+	store    store.Store
+}
+
+// NewBrandingHandler creates a handler backed by the given branding store.
+func NewBrandingHandler(b BrandingStore, s store.Store) *BrandingHandler {
+	return &BrandingHandler{branding: b, store: s}
+}
+
+// GetBranding returns the branding configuration. It is public because the
+// login page renders it before anyone is signed in.
+// GET /api/branding
+func (h *BrandingHandler) GetBranding(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	return c.JSON(h.branding.GetBranding())
+}
+
+// UpdateBranding replaces the branding configuration. Sending empty fields
+// restores the built-in branding for those fields.
+// PUT /api/admin/branding
+func (h *BrandingHandler) UpdateBranding(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	var b settings.BrandingSettings
+	if err := c.BodyParser(&b); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	b.ProductName = strings.TrimSpace(b.ProductName)
+	b.LogoURL = strings.TrimSpace(b.LogoURL)
+	b.AccentColors.Primary = strings.TrimSpace(b.AccentColors.Primary)
+	b.AccentColors.Secondary = strings.TrimSpace(b.AccentColors.Secondary)
+	b.LoginMessage = strings.TrimSpace(b.LoginMessage)
+	if issues := settings.ValidateBranding(&b); len(issues) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid branding", "issues": issues})
+	}
+
+	if err := h.branding.SaveBranding(b); err != nil {
+		slog.Error("[Branding] failed to save branding settings", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save branding settings"})
+	}
+	audit.Log(c, audit.ActionUpdateBranding, "branding", "console",
+		fmt.Sprintf("product_name=%q logo_url=%q", b.ProductName, b.LogoURL))
+	return c.JSON(b)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memBrandingStore struct {
+	branding settings.BrandingSettings
+}
+
+func (m *memBrandingStore) GetBranding() settings.BrandingSettings { return m.branding }
+
+func (m *memBrandingStore) SaveBranding(b settings.BrandingSettings) error {
+	m.branding = b
+	return nil
+}
+
+func setupBrandingApp(t *testing.T, role models.UserRole) (*fiber.App, *memBrandingStore) {
+	t.Helper()
+	mockStore := new(test.MockStore)
+	userID := uuid.New()
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	branding := &memBrandingStore{}
+	h := NewBrandingHandler(branding, mockStore)
+	app := fiber.New()
+	app.Get("/api/branding", h.GetBranding)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Put("/api/admin/branding", h.UpdateBranding)
+	return app, branding
+}
+
+func TestBranding_UpdateAndServe(t *testing.T) {
+	app, branding := setupBrandingApp(t, models.UserRoleAdmin)
+
+	status, body := doFlagRequest(t, app, http.MethodPut, "/api/admin/branding",
+		`{"productName":"  Acme Console ","logoUrl":"https://cdn.acme.example/logo.svg","accentColors":{"primary":"#ff6600"},"loginMessage":"Authorized use only."}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, "Acme Console", branding.branding.ProductName, "fields are trimmed")
+
+	status, body = doFlagRequest(t, app, http.MethodGet, "/api/branding", "")
+	require.Equal(t, http.StatusOK, status)
+	var got settings.BrandingSettings
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, branding.branding, got)
+
+	status, body = doFlagRequest(t, app, http.MethodPut, "/api/admin/branding",
+		`{"logoUrl":"javascript:alert(1)","accentColors":{"primary":"orange"}}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	var invalid struct {
+		Issues []settings.ValidationIssue `json:"issues"`
+	}
+	require.NoError(t, json.Unmarshal(body, &invalid))
+	assert.Len(t, invalid.Issues, 2)
+	assert.Equal(t, "Acme Console", branding.branding.ProductName, "an invalid update is not saved")
+
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/branding", `{"productName":`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestBranding_ViewerForbidden(t *testing.T) {
+	app, branding := setupBrandingApp(t, models.UserRoleViewer)
+	status, _ := doFlagRequest(t, app, http.MethodPut, "/api/admin/branding", `{"productName":"Mine"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Empty(t, branding.branding.ProductName)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/ssrf"
 	"github.com/kubestellar/console/pkg/safego"
 	"github.com/kubestellar/console/pkg/store"
@@ -62,7 +63,7 @@ var cardProxyClient = &http.Client{
 		return http.ErrUseLastResponse
 	},
 	Transport: &http.Transport{
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		DialContext: egress.Dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
 			host, port, err := net.SplitHostPort(addr)
 			if err != nil {
 				return nil, err
@@ -82,7 +83,7 @@ var cardProxyClient = &http.Client{
 			// Connect to the first validated IP directly — no second DNS lookup
 			dialer := &net.Dialer{Timeout: cardProxyTimeout}
 			return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
-		},
+		}),
 	},
 }
 
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// CardDataMessageType is pushed to a card's owner when the result of the
+	// card's registered query changes.
+	CardDataMessageType = "card_data"
+
+	// defaultCardRefreshInterval, minCardRefreshInterval and
+	// maxCardRefreshInterval bound how often a registered query runs.
+	defaultCardRefreshInterval = 30 * time.Second
+	minCardRefreshInterval     = 10 * time.Second
+	maxCardRefreshInterval     = time.Hour
+	// cardRefreshLease is how long a registration lives without being
+	// renewed. Clients re-register while the card is mounted, so queries of
+	// closed tabs stop running on their own.
+	cardRefreshLease = 5 * time.Minute
+	// cardRefreshTick is how often the scheduler looks for due queries.
+	cardRefreshTick = time.Second
+	// maxCardRefreshPerUser and maxCardRefreshTotal bound the registrations
+	// one user and the whole console may hold.
+	maxCardRefreshPerUser = MaxCardsPerDashboard
+	maxCardRefreshTotal   = 2000
+)
+
+// CardData is the latest result of a card's query. Hash changes exactly when
+// the result does, so a client can skip re-rendering an identical push.
+type CardData struct {
+	CardID  uuid.UUID         `json:"card_id"`
+	Hash    string            `json:"hash"`
+	Results *SavedViewResults `json:"results,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// cardRefreshSub is one card's registration.
+type cardRefreshSub struct {
+	cardID    uuid.UUID
+	userID    uuid.UUID
+	key       string
+	interval  time.Duration
+	expiresAt time.Time
+	// lastHash is the hash last pushed to (or returned for) this card.
+	lastHash string
+}
+
+// cardRefreshQuery is a query shared by every card registered with it, so
+// identical cards cost one cluster fan-out per interval rather than one each.
+type cardRefreshQuery struct {
+	query   models.SavedViewQuery
+	lastRun time.Time
+	latest  *CardData
+}
+
+// CardRefreshHandler runs the queries cards register on a server-side
+// schedule and pushes results over the hub only when they change, replacing
+// per-card client polling.
+type CardRefreshHandler struct {
+	store     store.Store
+	views     *SavedViewHandler
+	broadcast func(userID uuid.UUID, msg Message)
+	now       func() time.Time
+
+	mu      sync.Mutex
+	subs    map[uuid.UUID]*cardRefreshSub
+	queries map[string]*cardRefreshQuery
+}
+
+// NewCardRefreshHandler creates a card refresh handler that executes queries
+// through views.
+func NewCardRefreshHandler(s store.Store, hub *Hub, views *SavedViewHandler) *CardRefreshHandler {
+	return &CardRefreshHandler{
+		store:     s,
+		views:     views,
+		broadcast: hub.Broadcast,
+		now:       time.Now,
+		subs:      make(map[uuid.UUID]*cardRefreshSub),
+		queries:   make(map[string]*cardRefreshQuery),
+	}
+}
+
+// Start runs due queries every second until done is closed.
+func (h *CardRefreshHandler) Start(done <-chan struct{}) {
+	// Cancelling on done aborts in-flight cluster queries on shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	safego.GoWith("cards/refresh-cancel", func() {
+		<-done
+		cancel()
+	})
+	safego.GoWith("cards/refresh", func() {
+		ticker := time.NewTicker(cardRefreshTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				h.runDue(ctx)
+			}
+		}
+	})
+}
+
+// runDue drops expired registrations and runs every query whose shortest
+// registered interval has elapsed.
+func (h *CardRefreshHandler) runDue(ctx context.Context) {
+	now := h.now()
+	h.mu.Lock()
+	intervals := make(map[string]time.Duration)
+	for id, sub := range h.subs {
+		if now.After(sub.expiresAt) {
+			delete(h.subs, id)
+			continue
+		}
+		if cur, ok := intervals[sub.key]; !ok || sub.interval < cur {
+			intervals[sub.key] = sub.interval
+		}
+	}
+	due := make(map[string]models.SavedViewQuery)
+	for key, q := range h.queries {
+		interval, ok := intervals[key]
+		if !ok {
+			delete(h.queries, key)
+			continue
+		}
+		if q.lastRun.IsZero() || now.Sub(q.lastRun) >= interval {
+			due[key] = q.query
+		}
+	}
+	h.mu.Unlock()
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(defaultClusterFanoutConcurrency)
+	for key, q := range due {
+		g.Go(func() error {
+			h.publish(key, h.run(gctx, q), now)
+			return nil
+		})
+	}
+	_ = g.Wait() // failures are reported to cards in CardData.Error.
+}
+
+// run executes q once across its target clusters.
+func (h *CardRefreshHandler) run(ctx context.Context, q models.SavedViewQuery) CardData {
+	ctx, cancel := context.WithTimeout(ctx, resourceExplorerTimeout)
+	defer cancel()
+	var data CardData
+	clusters, err := h.views.resolveClusters(ctx, q)
+	if err != nil {
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			data.Error = fe.Message
+		} else {
+			slog.Error("[CardRefresh] failed to resolve clusters", "resource", q.Resource, "error", err)
+			data.Error = "failed to resolve clusters"
+		}
+	} else {
+		results := h.views.execute(ctx, &models.SavedView{Query: q}, clusters)
+		data.Results = &results
+	}
+	data.Hash = cardDataHash(data)
+	return data
+}
+
+// cardDataHash hashes the parts of a result that matter to a card, leaving
+// out fetch timestamps so an unchanged result keeps its hash.
+func cardDataHash(d CardData) string {
+	var content struct {
+		Columns  []string             `json:"c,omitempty"`
+		Rows     []SavedViewResultRow `json:"r,omitempty"`
+		Clusters []string             `json:"k,omitempty"`
+		Errors   map[string]string    `json:"e,omitempty"`
+		Error    string               `json:"x,omitempty"`
+	}
+	if d.Results != nil {
+		content.Columns = d.Results.Columns
+		content.Rows = d.Results.Rows
+		content.Clusters = d.Results.Clusters
+		content.Errors = d.Results.Errors
+	}
+	content.Error = d.Error
+	raw, err := json.Marshal(content)
+	if err != nil {
+		// Unhashable cells: fall back to a value that never matches, so the
+		// card still receives the result.
+		return "unhashable-" + uuid.NewString()
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// publish stores the result of key and pushes it to every registered card
+// that has not seen this exact result yet.
+func (h *CardRefreshHandler) publish(key string, data CardData, ranAt time.Time) {
+	type push struct {
+		userID uuid.UUID
+		data   CardData
+	}
+	var pushes []push
+	h.mu.Lock()
+	q, ok := h.queries[key]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	q.lastRun = ranAt
+	q.latest = &data
+	for _, sub := range h.subs {
+		if sub.key != key || sub.lastHash == data.Hash {
+			continue
+		}
+		sub.lastHash = data.Hash
+		d := data
+		d.CardID = sub.cardID
+		pushes = append(pushes, push{userID: sub.userID, data: d})
+	}
+	h.mu.Unlock()
+
+	for _, p := range pushes {
+		h.broadcast(p.userID, Message{Type: CardDataMessageType, Data: p.data})
+	}
+}
+
+// loadOwnedCard returns the :id card if it belongs to the caller.
+func (h *CardRefreshHandler) loadOwnedCard(c *fiber.Ctx, userID uuid.UUID) (uuid.UUID, error) {
+	cardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid card ID")
+	}
+	card, err := h.store.GetCard(c.UserContext(), cardID)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get card")
+	}
+	if card == nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusNotFound, "Card not found")
+	}
+	dashboard, err := h.store.GetDashboard(c.UserContext(), card.DashboardID)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
+	}
+	if dashboard == nil || dashboard.UserID != userID {
+		return uuid.Nil, fiber.NewError(fiber.StatusForbidden, "Access denied")
+	}
+	return cardID, nil
+}
+
+// RegisterRefresh registers (or renews) the query a card displays. The
+// server runs it every interval_seconds and pushes a card_data message when
+// the result changes. Registrations expire after five minutes unless renewed,
+// so clients re-send this request while the card stays mounted. The latest
+// result, when one exists, is returned immediately.
+// POST /api/cards/:id/refresh {"query": {...}, "interval_seconds": 30}
+func (h *CardRefreshHandler) RegisterRefresh(c *fiber.Ctx) error {
+	if IsDemoMode(c) {
+		return c.JSON(fiber.Map{"status": "ok", "source": "demo"})
+	}
+	if h.views.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	userID := middleware.GetUserID(c)
+	cardID, err := h.loadOwnedCard(c, userID)
+	if err != nil {
+		return err
+	}
+
+	var input struct {
+		Query           models.SavedViewQuery `json:"query"`
+		IntervalSeconds int                   `json:"interval_seconds"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateSavedViewQuery(input.Query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	interval := defaultCardRefreshInterval
+	if input.IntervalSeconds != 0 {
+		interval = time.Duration(input.IntervalSeconds) * time.Second
+		if interval < minCardRefreshInterval || interval > maxCardRefreshInterval {
+			return fiber.NewError(fiber.StatusBadRequest, "interval_seconds must be between 10 and 3600")
+		}
+	}
+	rawKey, err := json.Marshal(input.Query)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query")
+	}
+	key := string(rawKey)
+
+	now := h.now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub, ok := h.subs[cardID]
+	if !ok {
+		if len(h.subs) >= maxCardRefreshTotal {
+			return fiber.NewError(fiber.StatusTooManyRequests, "Too many card refresh registrations")
+		}
+		owned := 0
+		for _, s := range h.subs {
+			if s.userID == userID {
+				owned++
+			}
+		}
+		if owned >= maxCardRefreshPerUser {
+			return fiber.NewError(fiber.StatusTooManyRequests, "Too many card refresh registrations")
+		}
+		sub = &cardRefreshSub{cardID: cardID, userID: userID}
+		h.subs[cardID] = sub
+	}
+	if sub.key != key {
+		sub.key = key
+		sub.lastHash = ""
+	}
+	sub.interval = interval
+	sub.expiresAt = now.Add(cardRefreshLease)
+
+	q, ok := h.queries[key]
+	if !ok {
+		q = &cardRefreshQuery{query: input.Query}
+		h.queries[key] = q
+	}
+	resp := fiber.Map{
+		"card_id":          cardID,
+		"interval_seconds": int(interval / time.Second),
+		"expires_at":       sub.expiresAt,
+	}
+	if q.latest != nil {
+		data := *q.latest
+		data.CardID = cardID
+		sub.lastHash = data.Hash
+		resp["data"] = data
+	}
+	return c.JSON(resp)
+}
+
+// UnregisterRefresh stops refreshing a card's query.
+// DELETE /api/cards/:id/refresh
+func (h *CardRefreshHandler) UnregisterRefresh(c *fiber.Ctx) error {
+	if IsDemoMode(c) {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	userID := middleware.GetUserID(c)
+	cardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid card ID")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub, ok := h.subs[cardID]
+	if !ok || sub.userID != userID {
+		return fiber.NewError(fiber.StatusNotFound, "Card refresh not registered")
+	}
+	delete(h.subs, cardID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type cardRefreshTestEnv struct {
+	env     *testEnv
+	handler *CardRefreshHandler
+	now     time.Time
+
+	mu     sync.Mutex
+	pushes []CardData
+}
+
+func (e *cardRefreshTestEnv) takePushes() []CardData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := e.pushes
+	e.pushes = nil
+	return out
+}
+
+// setupCardRefreshTest registers cards on one dashboard owned by the test
+// user and captures pushes instead of broadcasting them.
+func setupCardRefreshTest(t *testing.T, cards ...uuid.UUID) *cardRefreshTestEnv {
+	t.Helper()
+	env := setupTestEnv(t)
+	mockStore := env.Store.(*test.MockStore)
+	dashboard := &models.Dashboard{ID: uuid.New(), UserID: testAdminUserID}
+	for _, id := range cards {
+		mockStore.On("GetCard", id).Return(&models.Card{ID: id, DashboardID: dashboard.ID}, nil)
+	}
+	s := &versionedDashboardStore{MockStore: mockStore, dashboard: dashboard}
+
+	views := NewSavedViewHandler(s, env.K8sClient, testSavedViewProject)
+	te := &cardRefreshTestEnv{env: env, now: time.Now()}
+	te.handler = NewCardRefreshHandler(s, nil, views)
+	te.handler.now = func() time.Time { return te.now }
+	te.handler.broadcast = func(userID uuid.UUID, msg Message) {
+		assert.Equal(t, testAdminUserID, userID)
+		assert.Equal(t, CardDataMessageType, msg.Type)
+		te.mu.Lock()
+		te.pushes = append(te.pushes, msg.Data.(CardData))
+		te.mu.Unlock()
+	}
+	env.App.Post("/api/cards/:id/refresh", te.handler.RegisterRefresh)
+	env.App.Delete("/api/cards/:id/refresh", te.handler.UnregisterRefresh)
+	return te
+}
+
+func (e *cardRefreshTestEnv) register(t *testing.T, cardID uuid.UUID, body string) (int, map[string]json.RawMessage) {
+	t.Helper()
+	resp := sendDashboardJSON(t, e.env.App, "POST", "/api/cards/"+cardID.String()+"/refresh", body, nil)
+	var out map[string]json.RawMessage
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+const widgetsRefreshBody = `{"query":{"group":"example.com","version":"v1","resource":"widgets","namespace":"default","clusters":["test-cluster"]},"interval_seconds":10}`
+
+func TestCardRefresh_PushesOnlyOnChange(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	te := setupCardRefreshTest(t, first, second)
+	ctx := context.Background()
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	dyn := injectDynamicCluster(te.env, "test-cluster", map[schema.GroupVersionResource]string{widgets: "WidgetList"})
+	addWidget := func(name string) {
+		_, err := dyn.Resource(widgets).Namespace("default").Create(ctx, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+		}}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	addWidget("w1")
+	addWidget("w2")
+
+	status, out := te.register(t, first, widgetsRefreshBody)
+	require.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, out, "data", "no result exists before the first run")
+
+	te.handler.runDue(ctx)
+	pushes := te.takePushes()
+	require.Len(t, pushes, 1)
+	assert.Equal(t, first, pushes[0].CardID)
+	require.NotNil(t, pushes[0].Results)
+	assert.Len(t, pushes[0].Results.Rows, 2)
+
+	te.handler.runDue(ctx)
+	assert.Empty(t, te.takePushes(), "the query is not due again before its interval")
+
+	te.now = te.now.Add(10 * time.Second)
+	te.handler.runDue(ctx)
+	assert.Empty(t, te.takePushes(), "an unchanged result is not pushed")
+
+	// A second card with the same query shares the result and gets it at once.
+	status, out = te.register(t, second, widgetsRefreshBody)
+	require.Equal(t, http.StatusOK, status)
+	var data CardData
+	require.NoError(t, json.Unmarshal(out["data"], &data))
+	assert.Equal(t, second, data.CardID)
+	assert.Equal(t, pushes[0].Hash, data.Hash)
+
+	addWidget("w3")
+
+	te.now = te.now.Add(10 * time.Second)
+	te.handler.runDue(ctx)
+	pushes = te.takePushes()
+	require.Len(t, pushes, 2, "both cards receive the changed result")
+	for _, p := range pushes {
+		assert.Len(t, p.Results.Rows, 3)
+	}
+	assert.Len(t, te.handler.queries, 1, "identical queries run once")
+}
+
+func TestCardRefresh_LeaseAndUnregister(t *testing.T) {
+	card := uuid.New()
+	te := setupCardRefreshTest(t, card)
+
+	status, _ := te.register(t, card, widgetsRefreshBody)
+	require.Equal(t, http.StatusOK, status)
+
+	resp := sendDashboardJSON(t, te.env.App, "DELETE", "/api/cards/"+card.String()+"/refresh", "", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = sendDashboardJSON(t, te.env.App, "DELETE", "/api/cards/"+card.String()+"/refresh", "", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	status, _ = te.register(t, card, widgetsRefreshBody)
+	require.Equal(t, http.StatusOK, status)
+	te.now = te.now.Add(cardRefreshLease + time.Second)
+	te.handler.runDue(context.Background())
+	assert.Empty(t, te.handler.subs, "unrenewed registrations expire")
+	assert.Empty(t, te.handler.queries, "queries without cards stop running")
+	assert.Empty(t, te.takePushes())
+}
+
+func TestCardRefresh_RegisterValidation(t *testing.T) {
+	card := uuid.New()
+	te := setupCardRefreshTest(t, card)
+
+	for name, body := range map[string]string{
+		"malformed":     `{"query":`,
+		"bad resource":  `{"query":{"version":"v1","resource":"Not_Valid"}}`,
+		"fast interval": `{"query":{"version":"v1","resource":"pods"},"interval_seconds":1}`,
+		"slow interval": `{"query":{"version":"v1","resource":"pods"},"interval_seconds":7200}`,
+	} {
+		status, _ := te.register(t, card, body)
+		assert.Equal(t, http.StatusBadRequest, status, name)
+	}
+
+	unknown := uuid.New()
+	te.env.Store.(*test.MockStore).On("GetCard", unknown).Return(nil, nil)
+	status, _ := te.register(t, unknown, widgetsRefreshBody)
+	assert.Equal(t, http.StatusNotFound, status, "unknown card")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/cardschema"
 	"github.com/kubestellar/console/pkg/models"
 	"github.com/kubestellar/console/pkg/store"
 )
@@ -73,6 +74,16 @@ func isValidCardType(t models.CardType) bool {
 	return false
 }
 
+// invalidCardConfig responds 400 listing every way a card config fails its
+// card type's schema (see cardschema), so editors and importers can point at
+// the broken settings instead of saving a card that silently misbehaves.
+func invalidCardConfig(c *fiber.Ctx, violations []cardschema.Violation) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error":      "Invalid card config",
+		"violations": violations,
+	})
+}
+
 // ListCards returns all cards for a dashboard
 func (h *CardHandler) ListCards(c *fiber.Ctx) error {
 	if IsDemoMode(c) {
@@ -143,6 +154,9 @@ func (h *CardHandler) CreateCard(c *fiber.Ctx) error {
 	if !isValidCardType(input.CardType) {
 		return fiber.NewError(fiber.StatusBadRequest, "Unknown card_type")
 	}
+	if violations := cardschema.Validate(input.CardType, input.Config); len(violations) > 0 {
+		return invalidCardConfig(c, violations)
+	}
 
 	card := &models.Card{
 		DashboardID: dashboardID,
@@ -229,6 +243,13 @@ func (h *CardHandler) UpdateCard(c *fiber.Ctx) error {
 	if input.Position != nil {
 		card.Position = *input.Position
 	}
+	// A new type is checked against the config it keeps, so switching type
+	// cannot leave a config the new card cannot read.
+	if input.CardType != nil || input.Config != nil {
+		if violations := cardschema.Validate(card.CardType, card.Config); len(violations) > 0 {
+			return invalidCardConfig(c, violations)
+		}
+	}
 
 	if err := h.store.UpdateCard(c.UserContext(), card); err != nil {
 		// #6610: UpdateCard now returns sql.ErrNoRows when the row was
@@ -349,12 +370,46 @@ func (h *CardHandler) RecordFocus(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"status": "ok"})
 }
 
-// GetCardTypes returns available card types
+// cardTypeWithSchema is a card type as listed by GetCardTypes, with the
+// schema its config must match and the config a new card starts with.
+type cardTypeWithSchema struct {
+	models.CardTypeInfo
+	ConfigSchema  cardschema.Schema `json:"config_schema"`
+	DefaultConfig map[string]any    `json:"default_config"`
+}
+
+func cardTypesWithSchemas() []cardTypeWithSchema {
+	infos := models.GetCardTypes()
+	types := make([]cardTypeWithSchema, 0, len(infos))
+	for _, info := range infos {
+		schema, _ := cardschema.For(info.Type)
+		types = append(types, cardTypeWithSchema{CardTypeInfo: info, ConfigSchema: schema, DefaultConfig: schema.Defaults()})
+	}
+	return types
+}
+
+// GetCardTypes returns available card types with their config schemas and
+// defaults
 func (h *CardHandler) GetCardTypes(c *fiber.Ctx) error {
 	if IsDemoMode(c) {
-		return DemoResponse(c, "card_types", models.GetCardTypes())
+		return DemoResponse(c, "card_types", cardTypesWithSchemas())
+	}
+	return c.JSON(cardTypesWithSchemas())
+}
+
+// ValidateCardConfig checks a config against a card type's schema without
+// saving anything. The body is the config. Unknown types get 404.
+// POST /api/card-types/:type/validate
+func (h *CardHandler) ValidateCardConfig(c *fiber.Ctx) error {
+	schema, ok := cardschema.For(models.CardType(c.Params("type")))
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "Unknown card_type")
+	}
+	violations := schema.Validate(c.Body())
+	if violations == nil {
+		violations = []cardschema.Violation{}
 	}
-	return c.JSON(models.GetCardTypes())
+	return c.JSON(fiber.Map{"valid": len(violations) == 0, "violations": violations})
 }
 
 // GetHistory returns the user's card history
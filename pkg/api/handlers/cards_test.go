@@ -67,6 +67,83 @@ func TestGetCardTypes_ReturnsNonEmpty(t *testing.T) {
 	assert.Greater(t, len(types), 0, "Expected at least one card type")
 }
 
+func TestGetCardTypes_IncludesSchemaAndDefaults(t *testing.T) {
+	userID := uuid.New()
+	app, _, handler := setupCardTest(t, userID)
+	app.Get("/api/card-types", handler.GetCardTypes)
+
+	req, err := http.NewRequest("GET", "/api/card-types", nil)
+	require.NoError(t, err)
+
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var types []cardTypeWithSchema
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&types))
+	require.Len(t, types, len(models.GetCardTypes()))
+	for _, ct := range types {
+		assert.Equal(t, "object", ct.ConfigSchema.Type, ct.Type)
+		assert.NotEmpty(t, ct.ConfigSchema.Properties, ct.Type)
+		assert.NotNil(t, ct.DefaultConfig, ct.Type)
+	}
+	assert.Equal(t, true, types[0].DefaultConfig["autoRefresh"])
+}
+
+// ---------- ValidateCardConfig ----------
+
+func TestValidateCardConfig(t *testing.T) {
+	userID := uuid.New()
+	app, _, handler := setupCardTest(t, userID)
+	app.Post("/api/card-types/:type/validate", handler.ValidateCardConfig)
+
+	tests := []struct {
+		name       string
+		cardType   string
+		body       string
+		wantStatus int
+		wantValid  bool
+		wantFields []string
+	}{
+		{"valid", "event_stream", `{"cluster":"prod","limit":50,"warningsOnly":true}`, http.StatusOK, true, nil},
+		{"empty", "event_stream", ``, http.StatusOK, true, nil},
+		{"wrong types", "event_stream", `{"limit":0,"warningsOnly":"yes"}`, http.StatusOK, false, []string{"limit", "warningsOnly"}},
+		{"not an object", "event_stream", `[1]`, http.StatusOK, false, []string{""}},
+		{"unknown type", "no_such_card", `{}`, http.StatusNotFound, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/api/card-types/"+tt.cardType+"/validate", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req, fiberTestTimeout)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, resp.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Valid      bool `json:"valid"`
+				Violations []struct {
+					Field string `json:"field"`
+				} `json:"violations"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, tt.wantValid, result.Valid)
+			fields := []string{}
+			for _, v := range result.Violations {
+				fields = append(fields, v.Field)
+			}
+			if tt.wantFields == nil {
+				tt.wantFields = []string{}
+			}
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
 // ---------- ListCards ----------
 
 func TestListCards_InvalidDashboardID(t *testing.T) {
@@ -516,6 +593,44 @@ func TestUpdateCard_AdminAllowedWithConfig(t *testing.T) {
 	assert.Equal(t, 1, wrapper.lastUpdate.Position.X)
 }
 
+// --- Config schema (400) ---
+
+func TestCreateCard_InvalidConfigRejected(t *testing.T) {
+	dashID := uuid.New()
+	cardID := uuid.New()
+	app, wrapper, _ := newCardMutationApp(t, models.UserRoleAdmin, dashID, cardID)
+
+	body := `{"card_type":"cluster_health","config":{"autoRefresh":"sometimes"},"position":{"x":0,"y":0,"w":4,"h":3}}`
+	req, err := http.NewRequest("POST", "/api/dashboards/"+dashID.String()+"/cards",
+		strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.False(t, wrapper.createCalled)
+
+	respBody, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(respBody), `"field":"autoRefresh"`)
+}
+
+func TestUpdateCard_InvalidConfigRejected(t *testing.T) {
+	dashID := uuid.New()
+	cardID := uuid.New()
+	app, wrapper, _ := newCardMutationApp(t, models.UserRoleAdmin, dashID, cardID)
+
+	body := `{"card_type":"pod_issues","config":{"namespace":42}}`
+	req, err := http.NewRequest("PUT", "/api/cards/"+cardID.String(), strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Nil(t, wrapper.lastUpdate)
+}
+
 // --- Per-dashboard card limit (429) ---
 
 func TestCreateCard_LimitReached_Returns429(t *testing.T) {
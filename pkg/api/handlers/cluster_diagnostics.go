@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// clusterDiagnosticsTimeout bounds a diagnostics run, including waiting
+	// for the diagnostics pod's image to be pulled.
+	clusterDiagnosticsTimeout = 3 * time.Minute
+	// maxDiagnosticsNames bounds the dnsNames and registries of a request.
+	maxDiagnosticsNames = 5
+)
+
+// clusterDiagnosticsClient is the subset of k8s.MultiClusterClient cluster
+// diagnostics need.
+type clusterDiagnosticsClient interface {
+	RunClusterDiagnostics(ctx context.Context, contextName string, opts k8s.DiagnosticsOptions) (*k8s.DiagnosticsReport, error)
+}
+
+// clusterDiagnosticsRequest is the body accepted by RunDiagnostics. Every
+// field is optional.
+type clusterDiagnosticsRequest struct {
+	// Namespace is where the diagnostics pod runs; defaults to "default".
+	Namespace string `json:"namespace,omitempty"`
+	// DNSNames are extra names to resolve from inside the cluster.
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// Registries override the registries derived from running images.
+	Registries []string `json:"registries,omitempty"`
+	// SkipPodChecks runs only the checks that need no pod.
+	SkipPodChecks bool `json:"skipPodChecks,omitempty"`
+}
+
+// ClusterDiagnosticsHandler runs the cluster diagnostics suite and returns a
+// scored report, for triaging clusters that look unhealthy.
+type ClusterDiagnosticsHandler struct {
+	k8sClient clusterDiagnosticsClient
+	store     store.Store
+}
+
+// NewClusterDiagnosticsHandler creates a cluster diagnostics handler.
+func NewClusterDiagnosticsHandler(k8sClient *k8s.MultiClusterClient, s store.Store) *ClusterDiagnosticsHandler {
+	h := &ClusterDiagnosticsHandler{store: s}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// validateRegistryHost accepts a registry host with an optional port, e.g.
+// "ghcr.io" or "registry.internal:5000".
+func validateRegistryHost(field, s string) error {
+	host := s
+	if strings.Contains(s, ":") {
+		h, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return fmt.Errorf("%s must be a host or host:port", field)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("%s has an invalid port", field)
+		}
+		host = h
+	}
+	return validateDNSSubdomain(field, host)
+}
+
+// RunDiagnostics runs API server latency, clock skew, in-cluster DNS and
+// image registry checks against a cluster. The DNS and registry checks start
+// a short-lived pod, so they need an editor or admin; viewers can run the
+// rest with skipPodChecks.
+// POST /api/clusters/:cluster/diagnostics
+func (h *ClusterDiagnosticsHandler) RunDiagnostics(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	var req clusterDiagnosticsRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+	if !req.SkipPodChecks {
+		if err := RequireEditorOrAdmin(c, h.store); err != nil {
+			return err
+		}
+	}
+	if req.Namespace == "" {
+		req.Namespace = defaultDebugNamespace
+	}
+	if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if len(req.DNSNames) > maxDiagnosticsNames || len(req.Registries) > maxDiagnosticsNames {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("at most %d dnsNames and %d registries are checked", maxDiagnosticsNames, maxDiagnosticsNames),
+		})
+	}
+	for i, name := range req.DNSNames {
+		req.DNSNames[i] = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+		if err := validateDNSSubdomain("dnsNames", req.DNSNames[i]); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+	for i, registry := range req.Registries {
+		req.Registries[i] = strings.ToLower(strings.TrimSpace(registry))
+		if err := validateRegistryHost("registries", req.Registries[i]); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), clusterDiagnosticsTimeout)
+	defer cancel()
+	report, err := h.k8sClient.RunClusterDiagnostics(ctx, cluster, k8s.DiagnosticsOptions{
+		Namespace:     req.Namespace,
+		DNSNames:      req.DNSNames,
+		Registries:    req.Registries,
+		SkipPodChecks: req.SkipPodChecks,
+	})
+	if err != nil {
+		slog.Error("[Diagnostics] failed to run cluster diagnostics", "cluster", cluster, "error", err)
+		return HandleK8sError(c, err)
+	}
+	audit.Log(c, audit.ActionRunClusterDiagnostics, "cluster", cluster,
+		fmt.Sprintf("status=%s score=%d pod_checks=%t", report.Status, report.Score, !req.SkipPodChecks))
+	return c.JSON(report)
+}
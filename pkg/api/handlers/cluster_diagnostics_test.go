@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type fakeDiagnosticsClient struct {
+	opts *k8s.DiagnosticsOptions
+}
+
+func (f *fakeDiagnosticsClient) RunClusterDiagnostics(_ context.Context, contextName string, opts k8s.DiagnosticsOptions) (*k8s.DiagnosticsReport, error) {
+	f.opts = &opts
+	return &k8s.DiagnosticsReport{
+		Cluster: contextName,
+		Score:   100,
+		Status:  k8s.DiagnosticsHealthy,
+		Checks:  []k8s.DiagnosticCheck{{Name: k8s.DiagnosticAPILatency, Status: k8s.DiagnosticPass}},
+	}, nil
+}
+
+func runDiagnosticsRequest(t *testing.T, role models.UserRole, client *fakeDiagnosticsClient, body string) *http.Response {
+	t.Helper()
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	h := &ClusterDiagnosticsHandler{k8sClient: client, store: mockStore}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/api/clusters/:cluster/diagnostics", h.RunDiagnostics)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/clusters/prod/diagnostics", strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestClusterDiagnostics_Run(t *testing.T) {
+	client := &fakeDiagnosticsClient{}
+	resp := runDiagnosticsRequest(t, models.UserRoleEditor, client,
+		`{"dnsNames":["Internal.Example.com."],"registries":["registry.internal:5000"]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report k8s.DiagnosticsReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, "prod", report.Cluster)
+	assert.Equal(t, 100, report.Score)
+
+	require.NotNil(t, client.opts)
+	assert.Equal(t, "default", client.opts.Namespace)
+	assert.Equal(t, []string{"internal.example.com"}, client.opts.DNSNames)
+	assert.Equal(t, []string{"registry.internal:5000"}, client.opts.Registries)
+	assert.False(t, client.opts.SkipPodChecks)
+}
+
+func TestClusterDiagnostics_EmptyBody(t *testing.T) {
+	client := &fakeDiagnosticsClient{}
+	resp := runDiagnosticsRequest(t, models.UserRoleAdmin, client, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, client.opts)
+	assert.Empty(t, client.opts.Registries, "registries are derived from running images")
+}
+
+func TestClusterDiagnostics_ViewerNeedsSkipPodChecks(t *testing.T) {
+	client := &fakeDiagnosticsClient{}
+	resp := runDiagnosticsRequest(t, models.UserRoleViewer, client, `{}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Nil(t, client.opts)
+
+	resp = runDiagnosticsRequest(t, models.UserRoleViewer, client, `{"skipPodChecks":true}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, client.opts)
+	assert.True(t, client.opts.SkipPodChecks)
+}
+
+func TestClusterDiagnostics_Validation(t *testing.T) {
+	for name, body := range map[string]string{
+		"namespace":      `{"namespace":"Bad_NS"}`,
+		"dns name":       `{"dnsNames":["exa mple.com"]}`,
+		"registry port":  `{"registries":["ghcr.io:99999"]}`,
+		"registry path":  `{"registries":["ghcr.io/acme"]}`,
+		"too many names": `{"dnsNames":["a.io","b.io","c.io","d.io","e.io","f.io"]}`,
+		"malformed":      `{"dnsNames":`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &fakeDiagnosticsClient{}
+			resp := runDiagnosticsRequest(t, models.UserRoleEditor, client, body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Nil(t, client.opts)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/deploywindow"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// ClusterTimezoneHandler manages the per-cluster timezones that
+// WorkloadDeployment deployment windows are evaluated in.
+type ClusterTimezoneHandler struct {
+	store store.Store
+}
+
+// NewClusterTimezoneHandler creates a cluster timezone handler.
+func NewClusterTimezoneHandler(s store.Store) *ClusterTimezoneHandler {
+	return &ClusterTimezoneHandler{store: s}
+}
+
+// clusterTimezoneRequest is the body accepted by SetTimezone.
+type clusterTimezoneRequest struct {
+	Timezone string `json:"timezone"`
+}
+
+// ListTimezones returns the recorded timezone of every cluster that has one.
+// Clusters without one are evaluated in UTC.
+// GET /api/cluster-timezones
+func (h *ClusterTimezoneHandler) ListTimezones(c *fiber.Ctx) error {
+	timezones, err := h.store.ListClusterTimezones(c.UserContext())
+	if err != nil {
+		slog.Error("[ClusterTimezones] failed to list timezones", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list cluster timezones"})
+	}
+	return c.JSON(fiber.Map{"timezones": timezones})
+}
+
+// SetTimezone records the IANA timezone of a cluster.
+// PUT /api/clusters/:cluster/timezone
+func (h *ClusterTimezoneHandler) SetTimezone(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	var req clusterTimezoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if req.Timezone == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "timezone is required"})
+	}
+	if _, err := deploywindow.LoadLocation(req.Timezone); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.store.SetClusterTimezone(c.UserContext(), cluster, req.Timezone); err != nil {
+		slog.Error("[ClusterTimezones] failed to save timezone", "cluster", cluster, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save cluster timezone"})
+	}
+	audit.Log(c, audit.ActionSetClusterTimezone, "cluster", cluster, "timezone="+req.Timezone)
+	return c.JSON(fiber.Map{"cluster": cluster, "timezone": req.Timezone})
+}
+
+// DeleteTimezone clears a cluster's timezone so its deployment windows are
+// evaluated in UTC.
+// DELETE /api/clusters/:cluster/timezone
+func (h *ClusterTimezoneHandler) DeleteTimezone(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.store.DeleteClusterTimezone(c.UserContext(), cluster); err != nil {
+		slog.Error("[ClusterTimezones] failed to delete timezone", "cluster", cluster, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete cluster timezone"})
+	}
+	audit.Log(c, audit.ActionDeleteClusterTimezone, "cluster", cluster)
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupClusterTimezoneApp(t *testing.T, role models.UserRole) (*fiber.App, *test.MockStore) {
+	t.Helper()
+	mockStore := new(test.MockStore)
+	userID := uuid.New()
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	h := NewClusterTimezoneHandler(mockStore)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/cluster-timezones", h.ListTimezones)
+	app.Put("/api/clusters/:cluster/timezone", h.SetTimezone)
+	app.Delete("/api/clusters/:cluster/timezone", h.DeleteTimezone)
+	return app, mockStore
+}
+
+func TestClusterTimezones_SetListDelete(t *testing.T) {
+	app, mockStore := setupClusterTimezoneApp(t, models.UserRoleAdmin)
+	mockStore.On("SetClusterTimezone", "apac-1", "Asia/Tokyo").Return(nil).Once()
+	mockStore.On("ListClusterTimezones").Return(map[string]string{"apac-1": "Asia/Tokyo"}, nil).Once()
+	mockStore.On("DeleteClusterTimezone", "apac-1").Return(nil).Once()
+
+	status, body := doFlagRequest(t, app, http.MethodPut, "/api/clusters/apac-1/timezone", `{"timezone":" Asia/Tokyo "}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	status, body = doFlagRequest(t, app, http.MethodGet, "/api/cluster-timezones", "")
+	require.Equal(t, http.StatusOK, status)
+	var got struct {
+		Timezones map[string]string `json:"timezones"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "Asia/Tokyo", got.Timezones["apac-1"])
+
+	status, _ = doFlagRequest(t, app, http.MethodDelete, "/api/clusters/apac-1/timezone", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	mockStore.AssertExpectations(t)
+}
+
+func TestClusterTimezones_Rejected(t *testing.T) {
+	app, mockStore := setupClusterTimezoneApp(t, models.UserRoleAdmin)
+	for name, body := range map[string]string{
+		"unknown timezone": `{"timezone":"Mars/Olympus"}`,
+		"missing timezone": `{}`,
+		"bad body":         `not json`,
+	} {
+		status, _ := doFlagRequest(t, app, http.MethodPut, "/api/clusters/apac-1/timezone", body)
+		assert.Equal(t, http.StatusBadRequest, status, name)
+	}
+	mockStore.AssertNotCalled(t, "SetClusterTimezone", "apac-1", "Mars/Olympus")
+
+	viewer, _ := setupClusterTimezoneApp(t, models.UserRoleViewer)
+	status, _ := doFlagRequest(t, viewer, http.MethodPut, "/api/clusters/apac-1/timezone", `{"timezone":"UTC"}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = doFlagRequest(t, viewer, http.MethodDelete, "/api/clusters/apac-1/timezone", "")
+	assert.Equal(t, http.StatusForbidden, status)
+}
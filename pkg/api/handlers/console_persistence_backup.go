@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// preDeployBackupLabel marks the Velero backups taken before a
+// WorkloadDeployment rolls out, with the deployment's name as value.
+const preDeployBackupLabel = v1alpha1.Group + "/workload-deployment"
+
+// preDeployBackupPollInterval is how often a pre-deploy backup is polled
+// until it finishes. A var so tests can shorten it.
+var preDeployBackupPollInterval = 5 * time.Second
+
+// preDeployBackupClient is the subset of k8s.MultiClusterClient the
+// pre-deploy backup hook uses.
+type preDeployBackupClient interface {
+	CreateVeleroBackup(ctx context.Context, contextName, namespace string, req v1alpha1.VeleroBackupRequest) (*v1alpha1.VeleroBackup, error)
+	GetVeleroBackup(ctx context.Context, contextName, namespace, name string) (*v1alpha1.VeleroBackup, error)
+}
+
+// validatePreDeployBackup checks a WorkloadDeployment's PreDeployBackup.
+func validatePreDeployBackup(b *v1alpha1.PreDeployBackup) error {
+	if b == nil {
+		return nil
+	}
+	if b.Namespace != "" {
+		if err := validateDNSLabel("preDeployBackup.namespace", b.Namespace); err != nil {
+			return err
+		}
+	}
+	if b.StorageLocation != "" {
+		if err := validateDNSSubdomain("preDeployBackup.storageLocation", b.StorageLocation); err != nil {
+			return err
+		}
+	}
+	return b.Validate()
+}
+
+// takePreDeployBackups backs up the workload's namespace on each cluster in
+// parallel and waits for the backups to finish. It records each backup in
+// the cluster's status and returns the clusters whose backup failed, with
+// the reason; those must not be deployed to.
+func (h *ConsolePersistenceHandlers) takePreDeployBackups(
+	ctx context.Context,
+	wd *v1alpha1.WorkloadDeployment,
+	workload *v1alpha1.ManagedWorkload,
+	clusters []string,
+	updateFn func(*v1alpha1.WorkloadDeployment),
+) map[string]string {
+	cfg := wd.Spec.PreDeployBackup
+	var client preDeployBackupClient = h.backups
+	if client == nil && h.k8sClient != nil {
+		client = h.k8sClient
+	}
+	failed := make(map[string]string)
+	if client == nil {
+		for _, c := range clusters {
+			failed[c] = "Velero client not configured"
+		}
+		return failed
+	}
+	timeout, _ := cfg.WaitTimeout()
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = k8s.DefaultVeleroNamespace
+	}
+
+	statuses := make(map[string]*v1alpha1.ClusterRolloutStatus, len(wd.Status.ClusterStatuses))
+	for i := range wd.Status.ClusterStatuses {
+		statuses[wd.Status.ClusterStatuses[i].Cluster] = &wd.Status.ClusterStatuses[i]
+	}
+	name := k8s.VeleroBackupName(wd.Name+"-predeploy", h.currentTime())
+	for _, c := range clusters {
+		statuses[c].PreDeployBackup = name
+		h.setClusterStatus(wd, statuses[c], "InProgress", "0%", "Taking pre-deploy backup "+name)
+	}
+	updateFn(wd)
+
+	req := v1alpha1.VeleroBackupRequest{
+		Name:               name,
+		IncludedNamespaces: []string{workload.Spec.SourceNamespace},
+		StorageLocation:    cfg.StorageLocation,
+		TTL:                cfg.TTL,
+		Labels:             map[string]string{preDeployBackupLabel: wd.Name},
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := waitPreDeployBackup(ctx, client, c, namespace, req, timeout); err != nil {
+				slog.Warn("[reconcile] pre-deploy backup failed",
+					"name", wd.Name, "cluster", c, "backup", name, "error", err)
+				mu.Lock()
+				failed[c] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range clusters {
+		if reason, ok := failed[c]; ok {
+			h.setClusterStatus(wd, statuses[c], "Failed", "0%", "Pre-deploy backup failed: "+reason)
+		} else {
+			h.setClusterStatus(wd, statuses[c], "Pending", "0%", "Pre-deploy backup "+name+" completed")
+		}
+	}
+	updateFn(wd)
+	return failed
+}
+
+// waitPreDeployBackup creates a backup and polls it until Velero finishes it
+// or timeout elapses. Only a Completed backup counts as success.
+func waitPreDeployBackup(
+	ctx context.Context,
+	client preDeployBackupClient,
+	cluster, namespace string,
+	req v1alpha1.VeleroBackupRequest,
+	timeout time.Duration,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, err := client.CreateVeleroBackup(ctx, cluster, namespace, req); err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+	ticker := time.NewTicker(preDeployBackupPollInterval)
+	defer ticker.Stop()
+
+	phase := v1alpha1.VeleroPhaseNew
+	for {
+		b, err := client.GetVeleroBackup(ctx, cluster, namespace, req.Name)
+		if err == nil {
+			phase = b.Phase
+			if b.Phase == v1alpha1.VeleroPhaseCompleted {
+				return nil
+			}
+			if b.Failed() {
+				if b.FailureReason != "" {
+					return fmt.Errorf("backup %s %s: %s", req.Name, b.Phase, b.FailureReason)
+				}
+				return fmt.Errorf("backup %s %s with %d errors", req.Name, b.Phase, b.Errors)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("backup %s still %s after %s", req.Name, phase, timeout)
+		case <-ticker.C:
+		}
+	}
+}
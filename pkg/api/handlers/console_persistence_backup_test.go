@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// fakeBackupClient implements preDeployBackupClient. A backup reports
+// InProgress on its first poll and then the phase in phases, Completed by
+// default.
+type fakeBackupClient struct {
+	phases    map[string]string
+	createErr map[string]error
+	mu        sync.Mutex
+	created   map[string]v1alpha1.VeleroBackupRequest
+	polled    map[string]int
+}
+
+func (f *fakeBackupClient) CreateVeleroBackup(_ context.Context, cluster, namespace string, req v1alpha1.VeleroBackupRequest) (*v1alpha1.VeleroBackup, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.createErr[cluster]; err != nil {
+		return nil, err
+	}
+	if f.created == nil {
+		f.created = make(map[string]v1alpha1.VeleroBackupRequest)
+	}
+	f.created[cluster] = req
+	return &v1alpha1.VeleroBackup{Name: req.Name, Namespace: namespace, Cluster: cluster, Phase: v1alpha1.VeleroPhaseNew}, nil
+}
+
+func (f *fakeBackupClient) GetVeleroBackup(_ context.Context, cluster, namespace, name string) (*v1alpha1.VeleroBackup, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.polled == nil {
+		f.polled = make(map[string]int)
+	}
+	f.polled[cluster]++
+	b := &v1alpha1.VeleroBackup{Name: name, Namespace: namespace, Cluster: cluster, Phase: v1alpha1.VeleroPhaseInProgress}
+	if f.polled[cluster] > 1 {
+		b.Phase = v1alpha1.VeleroPhaseCompleted
+		if p, ok := f.phases[cluster]; ok {
+			b.Phase = p
+		}
+	}
+	return b, nil
+}
+
+func setupBackupEnv(t *testing.T, name string, targets []string, cfg *v1alpha1.PreDeployBackup) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	old := preDeployBackupPollInterval
+	preDeployBackupPollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { preDeployBackupPollInterval = old })
+
+	h, wd := setupRolloutEnv(t, name, targets, "", nil)
+	wd.Spec.PreDeployBackup = cfg
+	return h, wd
+}
+
+func TestReconcileDeployment_PreDeployBackup(t *testing.T) {
+	h, wd := setupBackupEnv(t, "wd-backup", []string{"cluster-a", "cluster-b"},
+		&v1alpha1.PreDeployBackup{StorageLocation: "s3", TTL: "168h"})
+	backups := &fakeBackupClient{}
+	h.backups = backups
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a", "cluster-b"}}, deployer.batches)
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	req := backups.created["cluster-a"]
+	assert.Regexp(t, `^wd-backup-predeploy-\d{14}$`, req.Name)
+	assert.Equal(t, []string{"default"}, req.IncludedNamespaces, "the workload's namespace is backed up")
+	assert.Equal(t, "s3", req.StorageLocation)
+	assert.Equal(t, "168h", req.TTL)
+	assert.Equal(t, "wd-backup", req.Labels[preDeployBackupLabel])
+	for _, cs := range wd.Status.ClusterStatuses {
+		assert.Equal(t, req.Name, cs.PreDeployBackup, cs.Cluster)
+	}
+}
+
+func TestReconcileDeployment_PreDeployBackupFailureSkipsCluster(t *testing.T) {
+	h, wd := setupBackupEnv(t, "wd-backup-fail", []string{"cluster-a", "cluster-b", "cluster-c"},
+		&v1alpha1.PreDeployBackup{})
+	h.backups = &fakeBackupClient{
+		phases:    map[string]string{"cluster-b": v1alpha1.VeleroPhasePartiallyFailed},
+		createErr: map[string]error{"cluster-c": fmt.Errorf("no matches for kind Backup")},
+	}
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a"}}, deployer.batches)
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Complete", statuses["cluster-a"].Phase)
+	assert.Equal(t, "Failed", statuses["cluster-b"].Phase)
+	assert.Contains(t, statuses["cluster-b"].Message, "Pre-deploy backup failed")
+	assert.Contains(t, statuses["cluster-b"].Message, "PartiallyFailed")
+	assert.Contains(t, statuses["cluster-c"].Message, "no matches for kind Backup")
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Equal(t, "1/3 clusters", wd.Status.Progress)
+}
+
+func TestReconcileDeployment_PreDeployBackupTimeout(t *testing.T) {
+	h, wd := setupBackupEnv(t, "wd-backup-slow", []string{"cluster-a"},
+		&v1alpha1.PreDeployBackup{Timeout: "30ms"})
+	h.backups = &fakeBackupClient{phases: map[string]string{"cluster-a": v1alpha1.VeleroPhaseInProgress}}
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Empty(t, deployer.batches)
+	require.Len(t, wd.Status.ClusterStatuses, 1)
+	assert.Contains(t, wd.Status.ClusterStatuses[0].Message, "still InProgress after 30ms")
+	assert.Equal(t, "Failed", wd.Status.Phase)
+}
+
+func TestReconcileDeployment_InvalidPreDeployBackup(t *testing.T) {
+	h, wd := setupBackupEnv(t, "wd-backup-invalid", []string{"cluster-a"},
+		&v1alpha1.PreDeployBackup{TTL: "forever"})
+	backups := &fakeBackupClient{}
+	h.backups = backups
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Empty(t, deployer.batches)
+	assert.Empty(t, backups.created)
+	require.NotEmpty(t, wd.Status.History)
+	assert.Contains(t, wd.Status.History[0].Message, "Invalid pre-deploy backup config")
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// phasePaused is a canary deployment waiting for its next step or for
+// promotion.
+const phasePaused = "Paused"
+
+// canaryPlan is a validated CanaryConfig.
+type canaryPlan struct {
+	weights       []int
+	interval      time.Duration
+	healthTimeout time.Duration
+	autoPromote   bool
+}
+
+// newCanaryPlan returns the canary plan for spec, or nil when spec is not a
+// canary. The health check timeout comes from rolloutConfig when set.
+func newCanaryPlan(spec v1alpha1.WorkloadDeploymentSpec) (*canaryPlan, error) {
+	if !spec.IsCanary() {
+		return nil, nil
+	}
+	cc := spec.CanaryConfig
+	if err := cc.Validate(); err != nil {
+		return nil, err
+	}
+	interval, _ := cc.Interval()
+	timeout := v1alpha1.DefaultHealthCheckTimeout
+	if rc := spec.RolloutConfig; rc != nil {
+		t, err := rc.HealthTimeout()
+		if err != nil {
+			return nil, err
+		}
+		timeout = t
+	}
+	return &canaryPlan{
+		weights:       cc.Weights(),
+		interval:      interval,
+		healthTimeout: timeout,
+		autoPromote:   spec.AutoPromote,
+	}, nil
+}
+
+// nextStep returns the step a pass rolls out and its weight. Steps count
+// from 1; step len(weights)+1 is the promotion to every cluster.
+func (p *canaryPlan) nextStep(st *v1alpha1.CanaryStatus) (int, int) {
+	total := len(p.weights)
+	switch {
+	case st == nil || st.CurrentStep < 1:
+		return 1, p.weights[0]
+	case st.Phase == v1alpha1.CanaryPhasePromoting, st.Phase == v1alpha1.CanaryPhasePromoted,
+		st.CurrentStep >= total && p.autoPromote:
+		return total + 1, 100
+	case st.CurrentStep >= total:
+		return total, p.weights[total-1]
+	default:
+		return st.CurrentStep + 1, p.weights[st.CurrentStep]
+	}
+}
+
+// canaryBatch splits the eligible clusters into those a step at weight
+// deploys to and those it holds back. done clusters of the total already run
+// the workload from earlier steps.
+func canaryBatch(total, done int, eligible []string, weight int) ([]string, map[string]bool) {
+	n := v1alpha1.CanaryClusterCount(weight, total) - done
+	n = min(max(n, 0), len(eligible))
+	held := make(map[string]bool, len(eligible)-n)
+	for _, c := range eligible[n:] {
+		held[c] = true
+	}
+	return eligible[:n], held
+}
+
+// recordCanaryStep updates the canary status after a pass and returns it.
+// A halted step aborts the canary; with no cluster held back it is promoted.
+func (h *ConsolePersistenceHandlers) recordCanaryStep(
+	wd *v1alpha1.WorkloadDeployment, plan *canaryPlan, step, weight int,
+	halted bool, deployed, failed, held int,
+) *v1alpha1.CanaryStatus {
+	now := h.currentTime()
+	at := metav1.NewTime(now)
+	st := wd.Status.CanaryStatus
+	if st == nil {
+		st = &v1alpha1.CanaryStatus{}
+		wd.Status.CanaryStatus = st
+	}
+	st.TotalSteps = len(plan.weights)
+	st.CurrentStep = min(step, st.TotalSteps)
+	st.CurrentWeight = weight
+	st.LastStepTime = &at
+	st.NextStepAt = nil
+	st.Metrics = map[string]interface{}{
+		"targetClusters":   deployed + failed + held,
+		"deployedClusters": deployed,
+		"failedClusters":   failed,
+		"heldClusters":     held,
+	}
+	switch {
+	case halted:
+		st.Phase = v1alpha1.CanaryPhaseAborted
+	case held == 0:
+		st.Phase = v1alpha1.CanaryPhasePromoted
+		st.CurrentWeight = 100
+	case step >= st.TotalSteps && !plan.autoPromote:
+		st.Phase = v1alpha1.CanaryPhaseAwaitingPromotion
+	default:
+		next := metav1.NewTime(now.Add(plan.interval))
+		st.Phase = v1alpha1.CanaryPhaseProgressing
+		st.NextStepAt = &next
+	}
+	return st
+}
+
+// setPausedStatus parks a canary between steps. A progressing canary is
+// resumed at its next step; one awaiting promotion waits for PromoteCanary.
+func (h *ConsolePersistenceHandlers) setPausedStatus(
+	wd *v1alpha1.WorkloadDeployment,
+	updateFn func(*v1alpha1.WorkloadDeployment),
+) {
+	st := wd.Status.CanaryStatus
+	wd.Status.Phase = phasePaused
+	wd.Status.NextEligibleAt = nil
+	h.metrics.observePhase(wd, time.Now())
+	slog.Info("[reconcile] canary paused",
+		"name", wd.Name, "canaryPhase", st.Phase, "step", st.CurrentStep, "weight", st.CurrentWeight)
+	updateFn(wd)
+	if st.NextStepAt != nil {
+		h.scheduleQueuedDeployment(wd.Namespace, wd.Name, st.NextStepAt.Time)
+	}
+}
+
+// persistStatus writes a deployment's status and takes its new
+// resourceVersion.
+func (h *ConsolePersistenceHandlers) persistStatus(ctx context.Context, wd *v1alpha1.WorkloadDeployment) error {
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return err
+	}
+	updated, err := k8s.NewConsolePersistence(client).UpdateWorkloadDeploymentStatus(ctx, wd)
+	if err != nil {
+		return err
+	}
+	wd.ResourceVersion = updated.ResourceVersion
+	return nil
+}
+
+// pausedCanary loads the deployment named in the request and checks that it
+// is a canary paused between steps. On failure the returned deployment is
+// nil and the caller returns err as its response.
+func (h *ConsolePersistenceHandlers) pausedCanary(c *fiber.Ctx) (*v1alpha1.WorkloadDeployment, error) {
+	name := c.Params("name")
+	if err := validateDNSSubdomain("name", name); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return nil, err
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
+	if err != nil {
+		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
+		return nil, localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	}
+	wd, err := k8s.NewConsolePersistence(client).GetWorkloadDeployment(c.UserContext(), namespace, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, localizedError(c, fiber.StatusNotFound, "persistence.deploymentNotFound")
+		}
+		slog.Warn("[ConsolePersistence] internal error", "error", err)
+		return nil, localizedError(c, fiber.StatusInternalServerError, "server.internalError")
+	}
+	if wd.Status.Phase != phasePaused || wd.Status.CanaryStatus == nil {
+		return nil, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("workload deployment is %s, not a paused canary", wd.Status.Phase),
+		})
+	}
+	return wd, nil
+}
+
+// PromoteCanary deploys a paused canary to every remaining cluster without
+// waiting for its remaining steps. Editor or admin only.
+// POST /api/persistence/deployments/:name/promote
+func (h *ConsolePersistenceHandlers) PromoteCanary(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.userStore); err != nil {
+		return err
+	}
+	wd, err := h.pausedCanary(c)
+	if wd == nil {
+		return err
+	}
+	h.cancelQueuedDeployment(wd.Namespace, wd.Name)
+
+	st := wd.Status.CanaryStatus
+	st.Phase = v1alpha1.CanaryPhasePromoting
+	st.NextStepAt = nil
+	if err := h.persistStatus(c.UserContext(), wd); err != nil {
+		// Most likely a step started meanwhile and rewrote the status.
+		slog.Warn("[ConsolePersistence] failed to promote canary", "name", wd.Name, "error", err)
+		return localizedError(c, fiber.StatusConflict, "persistence.deploymentChanged")
+	}
+	audit.Log(c, audit.ActionPromoteCanary, "workload_deployment", wd.Namespace+"/"+wd.Name,
+		fmt.Sprintf("weight=%d step=%d/%d", st.CurrentWeight, st.CurrentStep, st.TotalSteps))
+
+	// Encode the response before the promotion pass starts changing wd.
+	if err := c.Status(fiber.StatusAccepted).JSON(wd); err != nil {
+		return err
+	}
+	reconcileCtx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	safego.Go(func() {
+		defer cancel()
+		h.reconcileDeployment(reconcileCtx, wd)
+	})
+	return nil
+}
+
+// AbortCanary stops a paused canary. Clusters it has not reached are
+// Skipped and the deployment fails; clusters already deployed to are left as
+// they are. Editor or admin only.
+// POST /api/persistence/deployments/:name/abort
+func (h *ConsolePersistenceHandlers) AbortCanary(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.userStore); err != nil {
+		return err
+	}
+	wd, err := h.pausedCanary(c)
+	if wd == nil {
+		return err
+	}
+	h.cancelQueuedDeployment(wd.Namespace, wd.Name)
+
+	st := wd.Status.CanaryStatus
+	st.Phase = v1alpha1.CanaryPhaseAborted
+	st.NextStepAt = nil
+	h.metrics.observeAbort(abortReasonManual)
+	succeeded, skipped := 0, 0
+	for i := range wd.Status.ClusterStatuses {
+		cs := &wd.Status.ClusterStatuses[i]
+		switch cs.Phase {
+		case "Complete":
+			succeeded++
+		case "Pending", phaseQueued:
+			cs.NextEligibleAt = nil
+			h.setClusterStatus(wd, cs, phaseSkipped, "", "Canary aborted")
+			skipped++
+		}
+	}
+	wd.Status.Progress = fmt.Sprintf("%d/%d clusters", succeeded, len(wd.Status.ClusterStatuses))
+	var updateErr error
+	h.setTerminalStatus(wd, "Failed",
+		fmt.Sprintf("Canary aborted at %d%%: %d succeeded, %d skipped", st.CurrentWeight, succeeded, skipped),
+		func(wd *v1alpha1.WorkloadDeployment) { updateErr = h.persistStatus(c.UserContext(), wd) })
+	if updateErr != nil {
+		slog.Warn("[ConsolePersistence] failed to abort canary", "name", wd.Name, "error", updateErr)
+		return localizedError(c, fiber.StatusConflict, "persistence.deploymentChanged")
+	}
+	audit.Log(c, audit.ActionAbortCanary, "workload_deployment", wd.Namespace+"/"+wd.Name,
+		fmt.Sprintf("weight=%d step=%d/%d", st.CurrentWeight, st.CurrentStep, st.TotalSteps))
+	return c.JSON(wd)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+func setupCanaryEnv(t *testing.T, name string, targets []string, cc *v1alpha1.CanaryConfig, autoPromote bool) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment, *batchDeployer) {
+	t.Helper()
+	h, wd := setupRolloutEnv(t, name, targets, v1alpha1.StrategyCanary, nil, withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Spec.CanaryConfig = cc
+		wd.Spec.AutoPromote = autoPromote
+	}))
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+	h.healthChecker = &fakeHealthChecker{}
+	t.Cleanup(h.stopQueuedDeployments)
+	return h, wd, deployer
+}
+
+// canaryRequest posts to a promote or abort endpoint as a user with role.
+func canaryRequest(t *testing.T, h *ConsolePersistenceHandlers, role models.UserRole, path string) *http.Response {
+	t.Helper()
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+	mockStore.On("GetChangePolicy", "").Return(nil, nil).Maybe()
+	h.userStore = mockStore
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/api/persistence/deployments/:name/promote", h.PromoteCanary)
+	app.Post("/api/persistence/deployments/:name/abort", h.AbortCanary)
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, path, nil), 5000)
+	require.NoError(t, err)
+	return resp
+}
+
+func storedDeployment(t *testing.T, h *ConsolePersistenceHandlers, name string) *v1alpha1.WorkloadDeployment {
+	t.Helper()
+	client, _, err := h.persistenceStore.GetActiveClient(context.Background())
+	require.NoError(t, err)
+	wd, err := k8s.NewConsolePersistence(client).GetWorkloadDeployment(context.Background(), "test-ns", name)
+	require.NoError(t, err)
+	return wd
+}
+
+func TestReconcileDeployment_CanarySteps(t *testing.T) {
+	h, wd, deployer := setupCanaryEnv(t, "wd-canary", []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d"},
+		&v1alpha1.CanaryConfig{InitialWeight: 25, StepWeight: 25, MaxWeight: 50, StepInterval: "10m"}, false)
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a"}}, deployer.batches)
+	assert.Equal(t, phasePaused, wd.Status.Phase)
+	st := wd.Status.CanaryStatus
+	require.NotNil(t, st)
+	assert.Equal(t, v1alpha1.CanaryPhaseProgressing, st.Phase)
+	assert.Equal(t, 1, st.CurrentStep)
+	assert.Equal(t, 2, st.TotalSteps)
+	assert.Equal(t, 25, st.CurrentWeight)
+	require.NotNil(t, st.NextStepAt)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), st.NextStepAt.Time, time.Minute)
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Complete", statuses["cluster-a"].Phase)
+	assert.Equal(t, "Pending", statuses["cluster-b"].Phase)
+	assert.Equal(t, "Held back at canary weight 25%", statuses["cluster-b"].Message)
+	assert.Equal(t, "1/4 clusters", wd.Status.Progress)
+
+	// The next step's pass, as the resume timer would run it.
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a"}, {"cluster-b"}}, deployer.batches)
+	assert.Equal(t, phasePaused, wd.Status.Phase)
+	assert.Equal(t, v1alpha1.CanaryPhaseAwaitingPromotion, wd.Status.CanaryStatus.Phase)
+	assert.Equal(t, 50, wd.Status.CanaryStatus.CurrentWeight)
+	assert.Nil(t, wd.Status.CanaryStatus.NextStepAt, "a canary awaiting promotion has no next step")
+
+	resp := canaryRequest(t, h, models.UserRoleEditor, "/api/persistence/deployments/wd-canary/promote")
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Eventually(t, func() bool {
+		return storedDeployment(t, h, "wd-canary").Status.Phase == "Complete"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	promoted := storedDeployment(t, h, "wd-canary")
+	assert.Equal(t, v1alpha1.CanaryPhasePromoted, promoted.Status.CanaryStatus.Phase)
+	assert.Equal(t, 100, promoted.Status.CanaryStatus.CurrentWeight)
+	assert.Equal(t, "4/4 clusters", promoted.Status.Progress)
+	assert.Equal(t, [][]string{{"cluster-a"}, {"cluster-b"}, {"cluster-c", "cluster-d"}}, deployer.batches)
+}
+
+func TestReconcileDeployment_CanaryAutoPromote(t *testing.T) {
+	h, wd, deployer := setupCanaryEnv(t, "wd-canary-auto", []string{"cluster-a", "cluster-b"},
+		&v1alpha1.CanaryConfig{InitialWeight: 50, MaxWeight: 50}, true)
+
+	h.reconcileDeployment(context.Background(), wd)
+	assert.Equal(t, phasePaused, wd.Status.Phase)
+	assert.Equal(t, v1alpha1.CanaryPhaseProgressing, wd.Status.CanaryStatus.Phase,
+		"an auto-promoting canary waits one interval at its last step")
+
+	h.reconcileDeployment(context.Background(), wd)
+	assert.Equal(t, [][]string{{"cluster-a"}, {"cluster-b"}}, deployer.batches)
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	assert.Equal(t, v1alpha1.CanaryPhasePromoted, wd.Status.CanaryStatus.Phase)
+}
+
+func TestReconcileDeployment_CanaryUnhealthyAborts(t *testing.T) {
+	h, wd, deployer := setupCanaryEnv(t, "wd-canary-bad", []string{"cluster-a", "cluster-b", "cluster-c"},
+		&v1alpha1.CanaryConfig{InitialWeight: 10}, false)
+	h.healthChecker = &fakeHealthChecker{unready: map[string]bool{"cluster-a": true}}
+	wd.Spec.RolloutConfig = &v1alpha1.RolloutConfig{HealthCheckTimeout: "50ms"}
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a"}}, deployer.batches)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Equal(t, v1alpha1.CanaryPhaseAborted, wd.Status.CanaryStatus.Phase)
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Failed", statuses["cluster-a"].Phase)
+	assert.Equal(t, phaseSkipped, statuses["cluster-b"].Phase)
+	assert.Equal(t, phaseSkipped, statuses["cluster-c"].Phase)
+}
+
+func TestReconcileDeployment_InvalidCanaryConfig(t *testing.T) {
+	h, wd, deployer := setupCanaryEnv(t, "wd-canary-invalid", []string{"cluster-a"},
+		&v1alpha1.CanaryConfig{InitialWeight: 60, MaxWeight: 40}, false)
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Empty(t, deployer.batches)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	require.NotEmpty(t, wd.Status.History)
+	assert.Contains(t, wd.Status.History[0].Message, "Invalid canary config")
+}
+
+func TestAbortCanary(t *testing.T) {
+	h, wd, deployer := setupCanaryEnv(t, "wd-canary-abort", []string{"cluster-a", "cluster-b"},
+		&v1alpha1.CanaryConfig{InitialWeight: 50}, false)
+	h.reconcileDeployment(context.Background(), wd)
+	require.Equal(t, phasePaused, wd.Status.Phase)
+
+	resp := canaryRequest(t, h, models.UserRoleViewer, "/api/persistence/deployments/wd-canary-abort/abort")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = canaryRequest(t, h, models.UserRoleEditor, "/api/persistence/deployments/wd-canary-abort/abort")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	aborted := storedDeployment(t, h, "wd-canary-abort")
+	assert.Equal(t, "Failed", aborted.Status.Phase)
+	assert.Equal(t, v1alpha1.CanaryPhaseAborted, aborted.Status.CanaryStatus.Phase)
+	statuses := rolloutStatuses(aborted)
+	assert.Equal(t, "Complete", statuses["cluster-a"].Phase, "clusters already deployed to are left as they are")
+	assert.Equal(t, phaseSkipped, statuses["cluster-b"].Phase)
+	require.NotEmpty(t, aborted.Status.History)
+	assert.Equal(t, "Canary aborted at 50%: 1 succeeded, 1 skipped", aborted.Status.History[len(aborted.Status.History)-1].Message)
+	assert.Equal(t, [][]string{{"cluster-a"}}, deployer.batches)
+
+	resp = canaryRequest(t, h, models.UserRoleEditor, "/api/persistence/deployments/wd-canary-abort/promote")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "only a paused canary can be promoted")
+
+	resp = canaryRequest(t, h, models.UserRoleEditor, "/api/persistence/deployments/missing/abort")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestCanaryPlanNextStep(t *testing.T) {
+	plan := &canaryPlan{weights: []int{10, 30}}
+	tests := []struct {
+		status       *v1alpha1.CanaryStatus
+		autoPromote  bool
+		step, weight int
+	}{
+		{nil, false, 1, 10},
+		{&v1alpha1.CanaryStatus{CurrentStep: 1}, false, 2, 30},
+		{&v1alpha1.CanaryStatus{CurrentStep: 2}, false, 2, 30},
+		{&v1alpha1.CanaryStatus{CurrentStep: 2}, true, 3, 100},
+		{&v1alpha1.CanaryStatus{CurrentStep: 1, Phase: v1alpha1.CanaryPhasePromoting}, false, 3, 100},
+	}
+	for _, tt := range tests {
+		plan.autoPromote = tt.autoPromote
+		step, weight := plan.nextStep(tt.status)
+		assert.Equal(t, tt.step, step)
+		assert.Equal(t, tt.weight, weight)
+	}
+}
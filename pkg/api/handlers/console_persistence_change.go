@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/notifications"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// SetProject scopes the change metadata policy to a console project.
+func (h *ConsolePersistenceHandlers) SetProject(project string) {
+	h.project = project
+}
+
+// SetNotificationService sends WorkloadDeployment outcomes, with their change
+// metadata, to the configured notification channels. With a nil service (the
+// default) nothing is sent.
+func (h *ConsolePersistenceHandlers) SetNotificationService(svc *notifications.Service) {
+	h.notifier = svc
+}
+
+// changePolicy returns the change metadata policy of namespace's project,
+// or nil when it has none or there is no user store.
+func (h *ConsolePersistenceHandlers) changePolicy(ctx context.Context, namespace string) (*models.ChangePolicy, error) {
+	if h.userStore == nil {
+		return nil, nil
+	}
+	return h.userStore.GetChangePolicy(ctx, h.namespaceProject(namespace))
+}
+
+// checkChangeMetadata validates meta, which may be nil, against policy.
+func checkChangeMetadata(policy *models.ChangePolicy, meta *v1alpha1.ChangeMetadata) error {
+	var m v1alpha1.ChangeMetadata
+	if meta != nil {
+		m = *meta
+	}
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+
+	var missing []string
+	if policy.RequireDescription && m.Description == "" {
+		missing = append(missing, "description")
+	}
+	if policy.RequireTicket && m.TicketURL == "" {
+		missing = append(missing, "ticket URL")
+	}
+	if policy.RequireApprover && m.Approver == "" {
+		missing = append(missing, "approver")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("change %s required by project policy", strings.Join(missing, ", "))
+	}
+	if policy.TicketURLPrefix != "" && m.TicketURL != "" && !strings.HasPrefix(m.TicketURL, policy.TicketURLPrefix) {
+		return fmt.Errorf("change ticket must start with %s", policy.TicketURLPrefix)
+	}
+	return nil
+}
+
+// changePolicyRequest is the body accepted by UpdateChangePolicy.
+type changePolicyRequest struct {
+	RequireDescription bool   `json:"require_description"`
+	RequireTicket      bool   `json:"require_ticket"`
+	RequireApprover    bool   `json:"require_approver"`
+	TicketURLPrefix    string `json:"ticket_url_prefix"`
+}
+
+// GetChangePolicy returns the change metadata the project of the selected
+// persistence namespace requires on WorkloadDeployments, so the UI can mark
+// fields as required.
+// GET /api/persistence/change-policy
+func (h *ConsolePersistenceHandlers) GetChangePolicy(c *fiber.Ctx) error {
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+	project := h.namespaceProject(namespace)
+	policy, err := h.changePolicy(c.UserContext(), namespace)
+	if err != nil {
+		slog.Error("[ConsolePersistence] failed to load change policy", "project", project, "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "change.policyLoadFailed")
+	}
+	if policy == nil {
+		policy = &models.ChangePolicy{Project: project}
+	}
+	return c.JSON(policy)
+}
+
+// UpdateChangePolicy replaces the change metadata policy of the selected
+// persistence namespace's project.
+// PUT /api/persistence/change-policy
+func (h *ConsolePersistenceHandlers) UpdateChangePolicy(c *fiber.Ctx) error {
+	if err := h.RequireAdmin(c); err != nil {
+		return err
+	}
+	if h.userStore == nil {
+		return localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	}
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+	project := h.namespaceProject(namespace)
+	var req changePolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return localizedError(c, fiber.StatusBadRequest, "request.invalidBody")
+	}
+	req.TicketURLPrefix = strings.TrimSpace(req.TicketURLPrefix)
+	if req.TicketURLPrefix != "" {
+		u, err := url.Parse(req.TicketURLPrefix)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return localizedError(c, fiber.StatusBadRequest, "change.invalidTicketURLPrefix")
+		}
+	}
+
+	policy := &models.ChangePolicy{
+		Project:            project,
+		RequireDescription: req.RequireDescription,
+		RequireTicket:      req.RequireTicket,
+		RequireApprover:    req.RequireApprover,
+		TicketURLPrefix:    req.TicketURLPrefix,
+		UpdatedBy:          middleware.GetGitHubLogin(c),
+	}
+	if err := h.userStore.SaveChangePolicy(c.UserContext(), policy); err != nil {
+		slog.Error("[ConsolePersistence] failed to save change policy", "project", project, "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "change.policySaveFailed")
+	}
+	audit.Log(c, audit.ActionUpdateChangePolicy, "change_policy", project,
+		fmt.Sprintf("description=%t ticket=%t approver=%t", req.RequireDescription, req.RequireTicket, req.RequireApprover))
+	return c.JSON(policy)
+}
+
+// notifyDeploymentOutcome sends a terminal WorkloadDeployment outcome to the
+// notification channels. The change metadata goes in the alert details, which
+// webhook, PagerDuty and OpsGenie channels forward as-is.
+func (h *ConsolePersistenceHandlers) notifyDeploymentOutcome(wd *v1alpha1.WorkloadDeployment, entry v1alpha1.DeploymentHistoryEntry) {
+	if h.notifier == nil {
+		return
+	}
+	severity := notifications.SeverityInfo
+	if entry.Phase != "Complete" {
+		severity = notifications.SeverityWarning
+	}
+	details := map[string]interface{}{
+		"revision": entry.Revision,
+		"workload": wd.Spec.WorkloadRef.Name,
+	}
+	if entry.Change != nil {
+		if entry.Change.Description != "" {
+			details["change_description"] = entry.Change.Description
+		}
+		if entry.Change.TicketURL != "" {
+			details["change_ticket"] = entry.Change.TicketURL
+		}
+		if entry.Change.Approver != "" {
+			details["change_approver"] = entry.Change.Approver
+		}
+	}
+	alert := notifications.Alert{
+		ID:           fmt.Sprintf("%s/%s/%d", wd.Namespace, wd.Name, entry.Revision),
+		RuleName:     "WorkloadDeployment " + entry.Phase,
+		Severity:     severity,
+		Status:       strings.ToLower(entry.Phase),
+		Message:      fmt.Sprintf("%s/%s: %s", wd.Namespace, wd.Name, entry.Message),
+		Details:      details,
+		Namespace:    wd.Namespace,
+		Resource:     wd.Name,
+		ResourceKind: "WorkloadDeployment",
+		FiredAt:      time.Now(),
+	}
+	notifier, name := h.notifier, wd.Name
+	safego.Go(func() {
+		if err := notifier.SendAlert(alert); err != nil {
+			slog.Error("[reconcile] failed to send deployment notification", "name", name, "error", err)
+		}
+	})
+}
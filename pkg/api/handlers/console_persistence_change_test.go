@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/notifications"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckChangeMetadata(t *testing.T) {
+	policy := &models.ChangePolicy{RequireTicket: true, RequireApprover: true, TicketURLPrefix: "https://jira.example.com/"}
+	ticket := "https://jira.example.com/browse/OPS-12"
+
+	assert.NoError(t, checkChangeMetadata(nil, nil), "no policy requires nothing")
+	assert.NoError(t, checkChangeMetadata(policy, &v1alpha1.ChangeMetadata{TicketURL: ticket, Approver: "alex"}))
+
+	err := checkChangeMetadata(policy, nil)
+	require.Error(t, err)
+	assert.Equal(t, "change ticket URL, approver required by project policy", err.Error())
+
+	err = checkChangeMetadata(policy, &v1alpha1.ChangeMetadata{TicketURL: "https://tracker.example.com/1", Approver: "alex"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must start with https://jira.example.com/")
+
+	assert.Error(t, checkChangeMetadata(nil, &v1alpha1.ChangeMetadata{TicketURL: "OPS-12"}),
+		"a malformed ticket is rejected even without a policy")
+}
+
+func setupChangePolicyReconcile(t *testing.T, policy *models.ChangePolicy) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment, *recordingDeployer) {
+	t.Helper()
+	h, wd := newReconcileFixture(t, withTargets("prod-a"))
+	deployer := &recordingDeployer{}
+	h.deployer = deployer
+	h.project = "kubestellar"
+	mockStore := new(test.MockStore)
+	mockStore.On("GetChangePolicy", "kubestellar").Return(policy, nil)
+	mockStore.On("GetClusterTimezone", mock.Anything).Return("", nil).Maybe()
+	h.userStore = mockStore
+	return h, wd, deployer
+}
+
+func TestReconcileDeployment_ChangePolicyRejectsMissingTicket(t *testing.T) {
+	h, wd, deployer := setupChangePolicyReconcile(t, &models.ChangePolicy{Project: "kubestellar", RequireTicket: true})
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls, "nothing is deployed without the required metadata")
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	require.Len(t, wd.Status.History, 1)
+	assert.Equal(t, "Change metadata rejected: change ticket URL required by project policy", wd.Status.History[0].Message)
+}
+
+func TestReconcileDeployment_ChangeMetadataInHistoryAndNotification(t *testing.T) {
+	h, wd, _ := setupChangePolicyReconcile(t, &models.ChangePolicy{Project: "kubestellar", RequireTicket: true})
+	wd.Annotations = map[string]string{
+		v1alpha1.AnnotationChangeDescription: "Roll out 1.4",
+		v1alpha1.AnnotationChangeTicket:      "https://jira.example.com/browse/OPS-12",
+		v1alpha1.AnnotationChangeApprover:    "alex",
+	}
+
+	received := make(chan map[string]interface{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	svc := notifications.NewService()
+	svc.RegisterWebhookNotifier("deployments", ts.URL)
+	h.SetNotificationService(svc)
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	require.Len(t, wd.Status.History, 1)
+	assert.Equal(t, &v1alpha1.ChangeMetadata{
+		Description: "Roll out 1.4",
+		TicketURL:   "https://jira.example.com/browse/OPS-12",
+		Approver:    "alex",
+	}, wd.Status.History[0].Change)
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, "WorkloadDeployment Complete", payload["alert"])
+		details, ok := payload["details"].(map[string]interface{})
+		require.True(t, ok, "payload has details: %v", payload)
+		assert.Equal(t, "https://jira.example.com/browse/OPS-12", details["change_ticket"])
+		assert.Equal(t, "alex", details["change_approver"])
+		assert.Equal(t, "Roll out 1.4", details["change_description"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no deployment notification received")
+	}
+}
+
+func TestChangePolicyEndpoints(t *testing.T) {
+	mockStore := new(test.MockStore)
+	adminID, viewerID := uuid.New(), uuid.New()
+	mockStore.On("GetUser", adminID).Return(&models.User{ID: adminID, Role: models.UserRoleAdmin}, nil).Maybe()
+	mockStore.On("GetUser", viewerID).Return(&models.User{ID: viewerID, Role: models.UserRoleViewer}, nil).Maybe()
+	mockStore.On("GetChangePolicy", "kubestellar").Return(nil, nil).Once()
+	mockStore.On("SaveChangePolicy", mock.MatchedBy(func(p *models.ChangePolicy) bool {
+		return p.Project == "kubestellar" && p.RequireTicket && p.TicketURLPrefix == "https://jira.example.com/"
+	})).Return(nil).Once()
+
+	h := &ConsolePersistenceHandlers{persistenceStore: store.NewPersistenceStore(""), userStore: mockStore, project: "kubestellar"}
+	newApp := func(userID uuid.UUID) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("userID", userID)
+			return c.Next()
+		})
+		app.Get("/api/persistence/change-policy", h.GetChangePolicy)
+		app.Put("/api/persistence/change-policy", h.UpdateChangePolicy)
+		return app
+	}
+	admin, viewer := newApp(adminID), newApp(viewerID)
+
+	status, body := doFlagRequest(t, viewer, http.MethodGet, "/api/persistence/change-policy", "")
+	require.Equal(t, http.StatusOK, status)
+	var got models.ChangePolicy
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, models.ChangePolicy{Project: "kubestellar"}, got, "no saved policy requires nothing")
+
+	status, _ = doFlagRequest(t, viewer, http.MethodPut, "/api/persistence/change-policy", `{"require_ticket":true}`)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, _ = doFlagRequest(t, admin, http.MethodPut, "/api/persistence/change-policy", `{"ticket_url_prefix":"jira"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body = doFlagRequest(t, admin, http.MethodPut, "/api/persistence/change-policy",
+		`{"require_ticket":true,"ticket_url_prefix":"https://jira.example.com/"}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	mockStore.AssertExpectations(t)
+}
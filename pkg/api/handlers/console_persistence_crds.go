@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// consoleCRDInstallTimeout bounds an install, including the wait for the new
+// CRDs to be established.
+const consoleCRDInstallTimeout = 45 * time.Second
+
+// consoleCRDInstaller is the subset of k8s.MultiClusterClient that checks
+// for and installs the console CRDs.
+type consoleCRDInstaller interface {
+	ConsoleCRDStatus(contextName string) ([]k8s.ConsoleCRD, error)
+	InstallConsoleCRDs(ctx context.Context, contextName string) ([]k8s.ConsoleCRD, error)
+}
+
+// consoleCRDReport is the response of GetCRDStatus and InstallCRDs. Ready
+// is true when the cluster serves every console CRD.
+type consoleCRDReport struct {
+	Cluster string           `json:"cluster"`
+	Ready   bool             `json:"ready"`
+	CRDs    []k8s.ConsoleCRD `json:"crds"`
+}
+
+func newConsoleCRDReport(cluster string, crds []k8s.ConsoleCRD) consoleCRDReport {
+	report := consoleCRDReport{Cluster: cluster, Ready: true, CRDs: crds}
+	for _, crd := range crds {
+		if crd.State != k8s.ConsoleCRDPresent && crd.State != k8s.ConsoleCRDInstalled {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+// crdInstallerOrDefault returns the CRD installer, falling back to
+// k8sClient; nil when neither is set.
+func (h *ConsolePersistenceHandlers) crdInstallerOrDefault() consoleCRDInstaller {
+	if h.crdInstaller != nil {
+		return h.crdInstaller
+	}
+	if h.k8sClient != nil {
+		return h.k8sClient
+	}
+	return nil
+}
+
+// crdTargetCluster is the cluster the CRD endpoints act on: the one the
+// request names, or else the configured primary cluster. Persistence need
+// not be enabled, since the CRDs are installed before it is.
+func (h *ConsolePersistenceHandlers) crdTargetCluster(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return h.persistenceStore.GetConfig().PrimaryCluster
+}
+
+// GetCRDStatus reports, per console CRD, whether the cluster serves it
+// GET /api/persistence/crds?cluster=
+func (h *ConsolePersistenceHandlers) GetCRDStatus(c *fiber.Ctx) error {
+	if err := h.RequireAdmin(c); err != nil {
+		return err
+	}
+
+	cluster := h.crdTargetCluster(c.Query("cluster"))
+	if cluster == "" {
+		return localizedError(c, 400, "persistence.noCluster")
+	}
+	installer := h.crdInstallerOrDefault()
+	if installer == nil {
+		return localizedError(c, 503, "server.unavailable")
+	}
+
+	crds, err := installer.ConsoleCRDStatus(cluster)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] CRD check failed", "cluster", cluster, "error", err)
+		return localizedError(c, 503, "persistence.crdCheckFailed")
+	}
+	return c.JSON(newConsoleCRDReport(cluster, crds))
+}
+
+// InstallCRDs installs the console CRDs the cluster is missing from the
+// manifests built into the console and reports the state of each. CRDs the
+// cluster already serves are left as they are.
+// POST /api/persistence/install-crds
+func (h *ConsolePersistenceHandlers) InstallCRDs(c *fiber.Ctx) error {
+	if err := h.RequireAdmin(c); err != nil {
+		return err
+	}
+
+	var req struct {
+		Cluster string `json:"cluster"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return localizedError(c, 400, "request.invalidBody")
+		}
+	}
+	cluster := h.crdTargetCluster(req.Cluster)
+	if cluster == "" {
+		return localizedError(c, 400, "persistence.noCluster")
+	}
+	installer := h.crdInstallerOrDefault()
+	if installer == nil {
+		return localizedError(c, 503, "server.unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), consoleCRDInstallTimeout)
+	defer cancel()
+	crds, err := installer.InstallConsoleCRDs(ctx, cluster)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] CRD install failed", "cluster", cluster, "error", err)
+		return localizedError(c, 503, "persistence.crdCheckFailed")
+	}
+	for _, crd := range crds {
+		if crd.State == k8s.ConsoleCRDFailed {
+			slog.Warn("[ConsolePersistence] CRD not installed", "cluster", cluster, "crd", crd.Name, "error", crd.Error)
+		}
+	}
+	return c.JSON(newConsoleCRDReport(cluster, crds))
+}
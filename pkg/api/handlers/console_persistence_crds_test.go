@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCRDInstaller reports ClusterGroup missing until it is installed.
+type fakeCRDInstaller struct {
+	installedOn []string
+	err         error
+}
+
+func (f *fakeCRDInstaller) ConsoleCRDStatus(string) ([]k8s.ConsoleCRD, error) {
+	return []k8s.ConsoleCRD{
+		{Name: "clustergroups.console.kubestellar.io", Kind: "ClusterGroup", State: k8s.ConsoleCRDMissing},
+		{Name: "managedworkloads.console.kubestellar.io", Kind: "ManagedWorkload", State: k8s.ConsoleCRDPresent},
+	}, f.err
+}
+
+func (f *fakeCRDInstaller) InstallConsoleCRDs(_ context.Context, cluster string) ([]k8s.ConsoleCRD, error) {
+	f.installedOn = append(f.installedOn, cluster)
+	crds, err := f.ConsoleCRDStatus(cluster)
+	if err != nil {
+		return nil, err
+	}
+	crds[0].State = k8s.ConsoleCRDInstalled
+	return crds, nil
+}
+
+func newCRDTestApp(t *testing.T, primary string, installer *fakeCRDInstaller) *fiber.App {
+	t.Helper()
+	ps := store.NewPersistenceStore(filepath.Join(t.TempDir(), "persistence.json"))
+	require.NoError(t, ps.UpdateConfig(store.PersistenceConfig{PrimaryCluster: primary, Namespace: "test-ns"}))
+	h := &ConsolePersistenceHandlers{persistenceStore: ps, crdInstaller: installer}
+
+	app := fiber.New()
+	app.Get("/api/persistence/crds", h.GetCRDStatus)
+	app.Post("/api/persistence/install-crds", h.InstallCRDs)
+	return app
+}
+
+func TestGetCRDStatus(t *testing.T) {
+	app := newCRDTestApp(t, "persist-cluster", &fakeCRDInstaller{})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/crds", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var report consoleCRDReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, "persist-cluster", report.Cluster, "defaults to the primary cluster")
+	assert.False(t, report.Ready)
+	assert.Len(t, report.CRDs, 2)
+
+	app = newCRDTestApp(t, "", &fakeCRDInstaller{})
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/crds", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "no cluster requested or configured")
+
+	app = newCRDTestApp(t, "persist-cluster", &fakeCRDInstaller{err: errors.New("connection refused")})
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/crds", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestInstallCRDs(t *testing.T) {
+	installer := &fakeCRDInstaller{}
+	app := newCRDTestApp(t, "persist-cluster", installer)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/persistence/install-crds", bytes.NewBufferString(`{"cluster":"other-cluster"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var report consoleCRDReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, "other-cluster", report.Cluster)
+	assert.True(t, report.Ready)
+	assert.Equal(t, k8s.ConsoleCRDInstalled, report.CRDs[0].State)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/api/persistence/install-crds", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"other-cluster", "persist-cluster"}, installer.installedOn)
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// dependencyPollInterval is how often a deployment waiting on its
+// dependencies re-reads them. A var so tests can shorten it.
+var dependencyPollInterval = 5 * time.Second
+
+// checkDependencyGraph walks the WorkloadDeployments wd depends on, directly
+// and transitively, and fails when one does not exist or the dependencies
+// form a cycle, which would leave every deployment in it waiting forever.
+func (h *ConsolePersistenceHandlers) checkDependencyGraph(ctx context.Context, wd *v1alpha1.WorkloadDeployment) error {
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get persistence client: %w", err)
+	}
+	persistence := k8s.NewConsolePersistence(client)
+
+	deps := map[string][]string{wd.Name: wd.Spec.DependsOn}
+	var path []string
+	done := make(map[string]bool)
+	var visit func(name string) error
+	visit = func(name string) error {
+		if i := slices.Index(path, name); i >= 0 {
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path[i:], name), " -> "))
+		}
+		if done[name] {
+			return nil
+		}
+		if _, ok := deps[name]; !ok {
+			dep, err := persistence.GetWorkloadDeployment(ctx, wd.Namespace, name)
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("WorkloadDeployment %s/%s, a dependency of %s, does not exist",
+					wd.Namespace, name, path[len(path)-1])
+			}
+			if err != nil {
+				return err
+			}
+			deps[name] = dep.Spec.DependsOn
+		}
+		path = append(path, name)
+		for _, next := range deps[name] {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[name] = true
+		return nil
+	}
+	return visit(wd.Name)
+}
+
+// dependency is a WorkloadDeployment another one waits on, with the
+// ManagedWorkload it deploys once that has been resolved.
+type dependency struct {
+	name     string
+	wd       *v1alpha1.WorkloadDeployment
+	workload *v1alpha1.ManagedWorkload
+}
+
+// waitForDependencies holds each target cluster until every deployment wd
+// depends on is healthy there: Complete on the cluster and, for kinds with
+// a readiness check, rolled out. A dependency that does not target the
+// cluster does not hold it. A cluster fails as soon as a dependency failed
+// or was skipped there, and once the dependency timeout passes; the clusters
+// that failed are returned with their reason. An error means the
+// dependencies could not be resolved and nothing should be deployed.
+//
+// The wait can outlive the reconcile deadline, so it is bounded by the
+// dependency timeout instead.
+func (h *ConsolePersistenceHandlers) waitForDependencies(
+	ctx context.Context,
+	wd *v1alpha1.WorkloadDeployment,
+	targets []string,
+	updateFn func(*v1alpha1.WorkloadDeployment),
+) (map[string]string, error) {
+	timeout, err := wd.Spec.DependencyWait()
+	if err != nil {
+		return nil, err
+	}
+	if err := h.checkDependencyGraph(ctx, wd); err != nil {
+		return nil, err
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get persistence client: %w", err)
+	}
+	persistence := k8s.NewConsolePersistence(client)
+
+	statuses := make(map[string]*v1alpha1.ClusterRolloutStatus, len(wd.Status.ClusterStatuses))
+	for i := range wd.Status.ClusterStatuses {
+		statuses[wd.Status.ClusterStatuses[i].Cluster] = &wd.Status.ClusterStatuses[i]
+	}
+	deps := make([]*dependency, len(wd.Spec.DependsOn))
+	for i, name := range wd.Spec.DependsOn {
+		deps[i] = &dependency{name: name}
+	}
+	waiting := slices.Clone(targets)
+	blockedBy := make(map[string]string, len(targets))
+	failed := make(map[string]string)
+
+	waitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	ticker := time.NewTicker(dependencyPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, dep := range deps {
+			current, err := persistence.GetWorkloadDeployment(waitCtx, wd.Namespace, dep.name)
+			if err != nil {
+				// Keep the last state read; the dependency is polled again.
+				slog.Warn("[reconcile] failed to read dependency",
+					"name", wd.Name, "dependency", dep.name, "error", err)
+				continue
+			}
+			dep.wd = current
+		}
+
+		still := waiting[:0]
+		for _, c := range waiting {
+			blocking, reason := h.clusterDependencies(waitCtx, c, deps)
+			switch {
+			case reason != "":
+				failed[c] = reason
+				h.setClusterStatus(wd, statuses[c], "Failed", "0%", reason)
+			case blocking != "":
+				blockedBy[c] = blocking
+				h.setClusterStatus(wd, statuses[c], "Pending", "", "Waiting for dependency "+blocking)
+				still = append(still, c)
+			default:
+				h.setClusterStatus(wd, statuses[c], "Pending", "", "Dependencies are healthy")
+			}
+		}
+		waiting = still
+		updateFn(wd)
+		if len(waiting) == 0 {
+			return failed, nil
+		}
+
+		select {
+		case <-waitCtx.Done():
+			for _, c := range waiting {
+				reason := fmt.Sprintf("Dependency %s did not become healthy within %s", blockedBy[c], timeout)
+				failed[c] = reason
+				h.setClusterStatus(wd, statuses[c], "Failed", "0%", reason)
+			}
+			updateFn(wd)
+			slog.Warn("[reconcile] dependencies did not become healthy",
+				"name", wd.Name, "clusters", waiting, "timeout", timeout)
+			return failed, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// clusterDependencies evaluates deps on cluster. It returns the first
+// dependency still blocking the cluster, or why the cluster cannot be
+// deployed to; both are empty once every dependency is healthy there.
+func (h *ConsolePersistenceHandlers) clusterDependencies(
+	ctx context.Context, cluster string, deps []*dependency,
+) (blocking, reason string) {
+	for _, dep := range deps {
+		if dep.wd == nil {
+			return dep.name, ""
+		}
+		status := dep.wd.Status
+		idx := slices.IndexFunc(status.ClusterStatuses, func(cs v1alpha1.ClusterRolloutStatus) bool {
+			return cs.Cluster == cluster
+		})
+		if idx < 0 {
+			if len(status.ClusterStatuses) > 0 {
+				// Resolved and does not target this cluster.
+				continue
+			}
+			if status.Phase == "Failed" {
+				return "", fmt.Sprintf("Dependency %s failed: %s", dep.name, lastHistoryMessage(status))
+			}
+			return dep.name, ""
+		}
+		cs := status.ClusterStatuses[idx]
+		switch cs.Phase {
+		case "Complete":
+			if !h.dependencyHealthy(ctx, dep, cluster) {
+				return dep.name, ""
+			}
+		case "Failed", phaseSkipped, "NotProcessed":
+			message := cs.Message
+			if message == "" {
+				message = cs.Phase
+			}
+			return "", fmt.Sprintf("Dependency %s failed on %s: %s", dep.name, cluster, message)
+		default:
+			return dep.name, ""
+		}
+	}
+	return "", ""
+}
+
+// dependencyHealthy reports whether the workload dep deployed to cluster is
+// rolled out. Kinds without a readiness check are healthy once deployed.
+func (h *ConsolePersistenceHandlers) dependencyHealthy(ctx context.Context, dep *dependency, cluster string) bool {
+	var checker workloadHealthChecker = h.healthChecker
+	if checker == nil && h.k8sClient != nil {
+		checker = h.k8sClient
+	}
+	if checker == nil {
+		return true
+	}
+	if dep.workload == nil {
+		workload, err := h.resolveManagedWorkload(ctx, dep.wd)
+		if err != nil {
+			slog.Warn("[reconcile] failed to resolve dependency workload",
+				"dependency", dep.name, "error", err)
+			return false
+		}
+		dep.workload = workload
+	}
+	ref := dep.workload.Spec.WorkloadRef
+	if !healthCheckableKinds[ref.Kind] {
+		return true
+	}
+	r, err := checker.GetWorkloadReadiness(ctx, cluster, ref.Kind, dep.workload.Spec.SourceNamespace, ref.Name)
+	return err == nil && r.RolledOut()
+}
+
+// lastHistoryMessage is the message of a deployment's latest terminal
+// phase, which is where setTerminalStatus records why it failed.
+func lastHistoryMessage(status v1alpha1.WorkloadDeploymentStatus) string {
+	if len(status.History) == 0 {
+		return status.Phase
+	}
+	return status.History[len(status.History)-1].Message
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// dependencyDeployment returns a WorkloadDeployment of my-app named name
+// that depends on dependsOn and has status.
+func dependencyDeployment(name string, dependsOn []string, status v1alpha1.WorkloadDeploymentStatus) *v1alpha1.WorkloadDeployment {
+	return &v1alpha1.WorkloadDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "WorkloadDeployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadDeploymentSpec{
+			WorkloadRef: v1alpha1.ResourceReference{Name: "my-app"},
+			DependsOn:   dependsOn,
+		},
+		Status: status,
+	}
+}
+
+// setupDependencyEnv seeds deps and returns the router deployment, which
+// targets targets and depends on model-server.
+func setupDependencyEnv(t *testing.T, targets []string, deps ...*v1alpha1.WorkloadDeployment) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	old := dependencyPollInterval
+	dependencyPollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { dependencyPollInterval = old })
+
+	var seeded []unstructuredObject
+	for _, d := range deps {
+		seeded = append(seeded, d)
+	}
+	return newReconcileFixture(t, withTargets(targets...), withObjects(seeded...), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Name = "router"
+		wd.Spec.DependsOn = []string{"model-server"}
+		wd.Status.Phase = "Pending"
+	}))
+}
+
+func TestCheckDependencyGraph(t *testing.T) {
+	h, wd := setupDependencyEnv(t, []string{"cluster-a"},
+		dependencyDeployment("model-server", []string{"cache"}, v1alpha1.WorkloadDeploymentStatus{}),
+		dependencyDeployment("cache", nil, v1alpha1.WorkloadDeploymentStatus{}))
+	assert.NoError(t, h.checkDependencyGraph(context.Background(), wd))
+
+	h, wd = setupDependencyEnv(t, []string{"cluster-a"},
+		dependencyDeployment("model-server", []string{"cache"}, v1alpha1.WorkloadDeploymentStatus{}))
+	err := h.checkDependencyGraph(context.Background(), wd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test-ns/cache, a dependency of model-server, does not exist")
+
+	h, wd = setupDependencyEnv(t, []string{"cluster-a"},
+		dependencyDeployment("model-server", []string{"cache"}, v1alpha1.WorkloadDeploymentStatus{}),
+		dependencyDeployment("cache", []string{"router"}, v1alpha1.WorkloadDeploymentStatus{}))
+	err = h.checkDependencyGraph(context.Background(), wd)
+	require.Error(t, err)
+	assert.Equal(t, "dependency cycle: router -> model-server -> cache -> router", err.Error())
+}
+
+func TestReconcileDeployment_WaitsForDependencies(t *testing.T) {
+	// model-server is deployed to cluster-a and failed on cluster-b; it
+	// does not target cluster-c.
+	h, wd := setupDependencyEnv(t, []string{"cluster-a", "cluster-b", "cluster-c"},
+		dependencyDeployment("model-server", nil, v1alpha1.WorkloadDeploymentStatus{
+			Phase: "Failed",
+			ClusterStatuses: []v1alpha1.ClusterRolloutStatus{
+				{Cluster: "cluster-a", Phase: "Complete"},
+				{Cluster: "cluster-b", Phase: "Failed", Message: "Deployment failed"},
+			},
+		}))
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+	checker := &fakeHealthChecker{}
+	h.healthChecker = checker
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a", "cluster-c"}}, deployer.batches)
+	assert.GreaterOrEqual(t, checker.polled["cluster-a"], 2, "the dependency is deployed only once it is ready")
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Complete", statuses["cluster-a"].Phase)
+	assert.Equal(t, "Failed", statuses["cluster-b"].Phase)
+	assert.Equal(t, "Dependency model-server failed on cluster-b: Deployment failed", statuses["cluster-b"].Message)
+	assert.Equal(t, "Complete", statuses["cluster-c"].Phase)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Equal(t, "2/3 clusters", wd.Status.Progress)
+}
+
+func TestReconcileDeployment_DependencyTimesOut(t *testing.T) {
+	h, wd := setupDependencyEnv(t, []string{"cluster-a"},
+		dependencyDeployment("model-server", nil, v1alpha1.WorkloadDeploymentStatus{
+			Phase:           "InProgress",
+			ClusterStatuses: []v1alpha1.ClusterRolloutStatus{{Cluster: "cluster-a", Phase: "InProgress"}},
+		}))
+	wd.Spec.DependencyTimeout = "30ms"
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Empty(t, deployer.batches)
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Failed", statuses["cluster-a"].Phase)
+	assert.Equal(t, "Dependency model-server did not become healthy within 30ms", statuses["cluster-a"].Message)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+}
+
+func TestReconcileDeployment_DependencyCycle(t *testing.T) {
+	h, wd := setupDependencyEnv(t, []string{"cluster-a"},
+		dependencyDeployment("model-server", []string{"router"}, v1alpha1.WorkloadDeploymentStatus{}))
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Empty(t, deployer.batches)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	require.NotEmpty(t, wd.Status.History)
+	assert.Equal(t, "Dependency check failed: dependency cycle: router -> model-server -> router",
+		wd.Status.History[0].Message)
+	assert.Equal(t, "Dependencies could not be resolved", rolloutStatuses(wd)["cluster-a"].Message)
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/deploywindow"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileTimeout bounds one detached reconciliation pass.
+const reconcileTimeout = 5 * time.Minute
+
+// queuedResumeDelay is added to a queued deployment's next eligible time so
+// the resumed pass lands inside the window rather than on its edge.
+const queuedResumeDelay = time.Second
+
+// phaseQueued marks a deployment, or a cluster within one, that is waiting
+// for its deployment window to open.
+const phaseQueued = "Queued"
+
+// queuedCluster is a target cluster held back by its deployment windows.
+type queuedCluster struct {
+	next    time.Time
+	windows []deploywindow.Window
+}
+
+// currentTime returns the reconciler's clock. Tests inject now.
+func (h *ConsolePersistenceHandlers) currentTime() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// checkDeploymentWindows evaluates wd's deployment windows for each target
+// cluster in the cluster's own timezone and returns the clusters that are
+// outside every window applying to them. An error means the windows could
+// not be evaluated and nothing should be deployed.
+func (h *ConsolePersistenceHandlers) checkDeploymentWindows(
+	ctx context.Context, wd *v1alpha1.WorkloadDeployment, targets []string, now time.Time,
+) (map[string]queuedCluster, error) {
+	if len(wd.Spec.DeploymentWindows) == 0 {
+		return nil, nil
+	}
+
+	windows := make([]deploywindow.Window, len(wd.Spec.DeploymentWindows))
+	for i, spec := range wd.Spec.DeploymentWindows {
+		w, err := deploywindow.Parse(spec.Start, spec.End, spec.Days, spec.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("deploymentWindows[%d]: %w", i, err)
+		}
+		windows[i] = w
+	}
+
+	members, err := h.deploymentWindowGroups(ctx, wd)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := make(map[string]queuedCluster)
+	for _, cluster := range targets {
+		var applicable []deploywindow.Window
+		for i, spec := range wd.Spec.DeploymentWindows {
+			if windowAppliesTo(spec, cluster, members) {
+				applicable = append(applicable, windows[i])
+			}
+		}
+		if len(applicable) == 0 {
+			continue
+		}
+		loc, err := h.clusterLocation(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+		if ok, next := deploywindow.Eligible(applicable, now, loc); !ok {
+			queued[cluster] = queuedCluster{next: next, windows: applicable}
+		}
+	}
+	return queued, nil
+}
+
+// deploymentWindowGroups resolves the members of every ClusterGroup named by
+// wd's deployment windows, keyed by group name.
+func (h *ConsolePersistenceHandlers) deploymentWindowGroups(
+	ctx context.Context, wd *v1alpha1.WorkloadDeployment,
+) (map[string]map[string]bool, error) {
+	members := make(map[string]map[string]bool)
+	for _, spec := range wd.Spec.DeploymentWindows {
+		if spec.ClusterGroup == "" || members[spec.ClusterGroup] != nil {
+			continue
+		}
+		client, _, err := h.persistenceStore.GetActiveClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get persistence client: %w", err)
+		}
+		group, err := k8s.NewConsolePersistence(client).GetClusterGroup(ctx, wd.Namespace, spec.ClusterGroup)
+		if err != nil {
+			return nil, fmt.Errorf("ClusterGroup %s/%s not found: %w", wd.Namespace, spec.ClusterGroup, err)
+		}
+		if group == nil {
+			return nil, fmt.Errorf("ClusterGroup %s/%s does not exist", wd.Namespace, spec.ClusterGroup)
+		}
+		set := make(map[string]bool)
+		for _, c := range h.evaluateClusterGroup(ctx, group) {
+			set[c] = true
+		}
+		members[spec.ClusterGroup] = set
+	}
+	return members, nil
+}
+
+// windowAppliesTo reports whether a deployment window constrains cluster.
+func windowAppliesTo(spec v1alpha1.DeploymentWindow, cluster string, members map[string]map[string]bool) bool {
+	if len(spec.Clusters) == 0 && spec.ClusterGroup == "" {
+		return true
+	}
+	for _, c := range spec.Clusters {
+		if c == cluster {
+			return true
+		}
+	}
+	return spec.ClusterGroup != "" && members[spec.ClusterGroup][cluster]
+}
+
+// clusterLocation returns the recorded timezone of a cluster, UTC when none
+// is set.
+func (h *ConsolePersistenceHandlers) clusterLocation(ctx context.Context, cluster string) (*time.Location, error) {
+	if h.userStore == nil {
+		return time.UTC, nil
+	}
+	name, err := h.userStore.GetClusterTimezone(ctx, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timezone of cluster %q: %w", cluster, err)
+	}
+	loc, err := deploywindow.LoadLocation(name)
+	if err != nil {
+		// Timezones are validated on write, so this only happens when the
+		// host's tzdata lacks the zone.
+		slog.Warn("[reconcile] unknown cluster timezone, using UTC",
+			"cluster", cluster, "timezone", name)
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+// queuedMessage describes why a cluster is waiting for its next window.
+func queuedMessage(q queuedCluster) string {
+	names := make([]string, len(q.windows))
+	for i, w := range q.windows {
+		names[i] = w.String()
+	}
+	return fmt.Sprintf("Queued until %s (outside deployment window %v)", q.next.UTC().Format(time.RFC3339), names)
+}
+
+// setQueuedStatus parks a deployment until the earliest window among its
+// queued clusters opens, then schedules the pass that resumes it.
+func (h *ConsolePersistenceHandlers) setQueuedStatus(
+	wd *v1alpha1.WorkloadDeployment,
+	next time.Time, message string,
+	updateFn func(*v1alpha1.WorkloadDeployment),
+) {
+	at := metav1.NewTime(next)
+	wd.Status.Phase = phaseQueued
+	wd.Status.NextEligibleAt = &at
+	h.metrics.observePhase(wd, time.Now())
+	slog.Info("[reconcile] deployment queued for its deployment window",
+		"name", wd.Name, "nextEligibleAt", next, "message", message)
+	updateFn(wd)
+	h.scheduleQueuedDeployment(wd.Namespace, wd.Name, next)
+}
+
+// scheduleQueuedDeployment arranges for a queued deployment to be reconciled
+// again when its next window opens, replacing any earlier schedule.
+func (h *ConsolePersistenceHandlers) scheduleQueuedDeployment(namespace, name string, at time.Time) {
+	key := namespace + "/" + name
+	delay := at.Sub(h.currentTime())
+	if delay < 0 {
+		delay = 0
+	}
+
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	if h.queueTimers == nil {
+		h.queueTimers = make(map[string]*time.Timer)
+	}
+	if t, ok := h.queueTimers[key]; ok {
+		t.Stop()
+	}
+	h.queueTimers[key] = time.AfterFunc(delay+queuedResumeDelay, func() {
+		h.resumeQueuedDeployment(namespace, name)
+	})
+}
+
+// cancelQueuedDeployment drops the pending resume of a deployment, if any.
+func (h *ConsolePersistenceHandlers) cancelQueuedDeployment(namespace, name string) {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	key := namespace + "/" + name
+	if t, ok := h.queueTimers[key]; ok {
+		t.Stop()
+		delete(h.queueTimers, key)
+	}
+}
+
+// stopQueuedDeployments drops every pending resume.
+func (h *ConsolePersistenceHandlers) stopQueuedDeployments() {
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	for key, t := range h.queueTimers {
+		t.Stop()
+		delete(h.queueTimers, key)
+	}
+}
+
+// resumeQueuedDeployment re-reads a queued deployment, or a paused canary,
+// and reconciles it. Deployments deleted or no longer waiting in the meantime
+// are left alone.
+func (h *ConsolePersistenceHandlers) resumeQueuedDeployment(namespace, name string) {
+	h.queueMu.Lock()
+	delete(h.queueTimers, namespace+"/"+name)
+	h.queueMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	safego.Go(func() {
+		defer cancel()
+		client, _, err := h.persistenceStore.GetActiveClient(ctx)
+		if err != nil {
+			slog.Error("[reconcile] failed to get client to resume queued deployment",
+				"name", name, "error", err)
+			return
+		}
+		wd, err := k8s.NewConsolePersistence(client).GetWorkloadDeployment(ctx, namespace, name)
+		if err != nil || wd == nil || (wd.Status.Phase != phaseQueued && wd.Status.Phase != phasePaused) {
+			return
+		}
+		h.reconcileDeployment(ctx, wd)
+	})
+}
+
+// requeueQueuedDeployments schedules every deployment that was left queued,
+// e.g. by a previous process, so it resumes when its window opens. Paused
+// canaries resume at their next step.
+func (h *ConsolePersistenceHandlers) requeueQueuedDeployments(ctx context.Context) {
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot list queued deployments", "error", err)
+		return
+	}
+	var deployments []v1alpha1.WorkloadDeployment
+	for _, namespace := range h.persistenceStore.GetNamespaces() {
+		list, err := k8s.NewConsolePersistence(client).ListWorkloadDeployments(ctx, namespace)
+		if err != nil {
+			slog.Warn("[ConsolePersistence] cannot list queued deployments", "namespace", namespace, "error", err)
+			continue
+		}
+		deployments = append(deployments, list...)
+	}
+	for i := range deployments {
+		wd := &deployments[i]
+		next := h.currentTime()
+		switch {
+		case wd.Status.Phase == phaseQueued:
+			if wd.Status.NextEligibleAt != nil {
+				next = wd.Status.NextEligibleAt.Time
+			}
+		case wd.Status.Phase == phasePaused && wd.Status.CanaryStatus != nil:
+			// A canary awaiting promotion has no next step to schedule.
+			if wd.Status.CanaryStatus.NextStepAt == nil {
+				continue
+			}
+			next = wd.Status.CanaryStatus.NextStepAt.Time
+		default:
+			continue
+		}
+		h.scheduleQueuedDeployment(wd.Namespace, wd.Name, next)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWindowReconcile(t *testing.T, windows []v1alpha1.DeploymentWindow, targets ...string) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment, *recordingDeployer, *time.Time) {
+	t.Helper()
+	h, wd := newReconcileFixture(t, withTargets(targets...), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Name = "wd-window"
+		wd.Spec.DeploymentWindows = windows
+	}))
+	deployer := &recordingDeployer{}
+	h.deployer = deployer
+
+	mockStore := new(test.MockStore)
+	mockStore.On("GetClusterTimezone", "apac-1").Return("Asia/Tokyo", nil).Maybe()
+	mockStore.On("GetClusterTimezone", "eu-1").Return("", nil).Maybe()
+	mockStore.On("GetChangePolicy", "").Return(nil, nil).Maybe()
+	h.userStore = mockStore
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	t.Cleanup(h.stopQueuedDeployments)
+	return h, wd, deployer, &now
+}
+
+func clusterStatuses(wd *v1alpha1.WorkloadDeployment) map[string]v1alpha1.ClusterRolloutStatus {
+	out := map[string]v1alpha1.ClusterRolloutStatus{}
+	for _, cs := range wd.Status.ClusterStatuses {
+		out[cs.Cluster] = cs
+	}
+	return out
+}
+
+func TestReconcileDeployment_QueuesUntilClusterLocalWindow(t *testing.T) {
+	apacNights := []v1alpha1.DeploymentWindow{{Clusters: []string{"apac-1"}, Start: "02:00", End: "05:00"}}
+	h, wd, deployer, now := setupWindowReconcile(t, apacNights, "apac-1", "eu-1")
+
+	// 12:00 UTC is 21:00 in Tokyo: apac-1 waits, eu-1 has no window.
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, []string{"eu-1"}, deployer.targets)
+	assert.Equal(t, phaseQueued, wd.Status.Phase)
+	opens := time.Date(2026, 10, 15, 2, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	require.NotNil(t, wd.Status.NextEligibleAt)
+	assert.True(t, wd.Status.NextEligibleAt.Time.Equal(opens), "next eligible %v, want %v", wd.Status.NextEligibleAt, opens)
+
+	statuses := clusterStatuses(wd)
+	assert.Equal(t, "Complete", statuses["eu-1"].Phase)
+	assert.Equal(t, phaseQueued, statuses["apac-1"].Phase)
+	assert.Contains(t, statuses["apac-1"].Message, "02:00-05:00")
+	require.NotNil(t, statuses["apac-1"].NextEligibleAt)
+	assert.True(t, statuses["apac-1"].NextEligibleAt.Time.Equal(opens))
+
+	h.queueMu.Lock()
+	_, scheduled := h.queueTimers["test-ns/wd-window"]
+	h.queueMu.Unlock()
+	assert.True(t, scheduled, "a resume is scheduled for when the window opens")
+	assert.Empty(t, wd.Status.History, "a queued deployment is not terminal")
+
+	// 02:30 in Tokyo: the resumed pass deploys only the queued cluster.
+	*now = opens.Add(30 * time.Minute)
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, []string{"apac-1"}, deployer.targets)
+	assert.Equal(t, 2, deployer.calls)
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	assert.Nil(t, wd.Status.NextEligibleAt)
+	assert.Equal(t, "2/2 clusters", wd.Status.Progress)
+	statuses = clusterStatuses(wd)
+	assert.Equal(t, "Complete", statuses["apac-1"].Phase)
+	assert.Nil(t, statuses["apac-1"].NextEligibleAt)
+	assert.Equal(t, "Complete", statuses["eu-1"].Phase)
+}
+
+func TestReconcileDeployment_WindowApplyingToEveryCluster(t *testing.T) {
+	everywhere := []v1alpha1.DeploymentWindow{{Start: "02:00", End: "05:00"}}
+	h, wd, deployer, _ := setupWindowReconcile(t, everywhere, "apac-1", "eu-1")
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls, "nothing is deployed while every cluster is queued")
+	assert.Equal(t, phaseQueued, wd.Status.Phase)
+	// eu-1 has no timezone and waits for 02:00 UTC; apac-1's 02:00 in Tokyo
+	// comes first and is the deployment's next eligible time.
+	require.NotNil(t, wd.Status.NextEligibleAt)
+	assert.True(t, wd.Status.NextEligibleAt.Time.Equal(time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC)))
+	statuses := clusterStatuses(wd)
+	assert.True(t, statuses["eu-1"].NextEligibleAt.Time.Equal(time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)))
+	assert.True(t, statuses["apac-1"].NextEligibleAt.Time.Equal(time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC)))
+}
+
+func TestReconcileDeployment_InvalidWindowFailsClosed(t *testing.T) {
+	bad := []v1alpha1.DeploymentWindow{{Start: "2am", End: "05:00"}}
+	h, wd, deployer, _ := setupWindowReconcile(t, bad, "eu-1")
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Contains(t, wd.Status.History[0].Message, "Deployment window check failed")
+	assert.Equal(t, "Failed", clusterStatuses(wd)["eu-1"].Phase)
+}
+
+func TestHandleResourceEvent_DeleteCancelsQueuedResume(t *testing.T) {
+	h, _, _, now := setupWindowReconcile(t, nil)
+	h.scheduleQueuedDeployment("test-ns", "wd-window", now.Add(time.Hour))
+
+	h.handleResourceEvent(k8s.ConsoleResourceEvent{
+		Type: "DELETED", ResourceType: "WorkloadDeployment", Namespace: "test-ns", Name: "wd-window",
+	})
+
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	assert.Empty(t, h.queueTimers)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/internal/envtest"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// TestReconcileDeployment_Envtest drives the reconciler through the real
+// watch path: a WorkloadDeployment created on the API server is picked up by
+// StartWatcher, reconciled, and its status written back via the status
+// subresource. Skipped unless KUBEBUILDER_ASSETS is set.
+func TestReconcileDeployment_Envtest(t *testing.T) {
+	const (
+		hubCluster    = "envtest-hub"
+		targetCluster = "envtest-target"
+	)
+	env := envtest.New(t, envtest.Options{})
+	ctx := context.Background()
+	ns := env.CreateNamespace(t, "reconcile")
+
+	replicas := int32(1)
+	_, err := env.Kubernetes.AppsV1().Deployments(ns).Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx:1.27"}}},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	clusters, err := env.MultiClusterClient(hubCluster, targetCluster)
+	require.NoError(t, err)
+	persistenceStore := store.NewPersistenceStore(filepath.Join(t.TempDir(), "persistence.json"))
+	require.NoError(t, persistenceStore.UpdateConfig(store.PersistenceConfig{
+		Enabled:        true,
+		PrimaryCluster: hubCluster,
+		Namespace:      ns,
+		SyncMode:       "primary-only",
+	}))
+	h := NewConsolePersistenceHandlers(persistenceStore, clusters, nil, nil)
+	// In-memory clients carry no health cache, so report the hub healthy
+	// directly rather than waiting on a probe.
+	persistenceStore.SetClusterHealthChecker(func(context.Context, string) store.ClusterHealth {
+		return store.ClusterHealthHealthy
+	})
+	require.NoError(t, h.StartWatcher(ctx))
+	t.Cleanup(h.StopWatcher)
+
+	p := env.Persistence()
+	_, err = p.CreateManagedWorkload(ctx, &v1alpha1.ManagedWorkload{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns},
+		Spec: v1alpha1.ManagedWorkloadSpec{
+			SourceCluster:   hubCluster,
+			SourceNamespace: ns,
+			WorkloadRef:     v1alpha1.WorkloadReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("deploys to the target cluster", func(t *testing.T) {
+		_, err := p.CreateWorkloadDeployment(ctx, &v1alpha1.WorkloadDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-rollout", Namespace: ns},
+			Spec: v1alpha1.WorkloadDeploymentSpec{
+				WorkloadRef:    v1alpha1.ResourceReference{Name: "web"},
+				TargetClusters: []string{targetCluster},
+			},
+		})
+		require.NoError(t, err)
+
+		wd := env.WaitForDeploymentPhase(t, ns, "web-rollout", "Complete", "Failed")
+		assert.Equal(t, "Complete", wd.Status.Phase)
+		require.Len(t, wd.Status.ClusterStatuses, 1)
+		assert.Equal(t, targetCluster, wd.Status.ClusterStatuses[0].Cluster)
+		assert.Equal(t, "Complete", wd.Status.ClusterStatuses[0].Phase)
+	})
+
+	t.Run("missing ManagedWorkload fails the rollout", func(t *testing.T) {
+		_, err := p.CreateWorkloadDeployment(ctx, &v1alpha1.WorkloadDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "orphan-rollout", Namespace: ns},
+			Spec: v1alpha1.WorkloadDeploymentSpec{
+				WorkloadRef:    v1alpha1.ResourceReference{Name: "does-not-exist"},
+				TargetClusters: []string{targetCluster},
+			},
+		})
+		require.NoError(t, err)
+
+		wd := env.WaitForDeploymentPhase(t, ns, "orphan-rollout", "Complete", "Failed")
+		assert.Equal(t, "Failed", wd.Status.Phase)
+	})
+}
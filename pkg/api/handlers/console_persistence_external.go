@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManagedWorkloadModifiedExternallyType is the WebSocket message type
+// warning that a managed workload was changed outside the console.
+const ManagedWorkloadModifiedExternallyType = "managed_workload_modified_externally"
+
+// externalEditTimeout bounds recording one outside change.
+const externalEditTimeout = 30 * time.Second
+
+// externalEditEvent is the data of a ManagedWorkloadModifiedExternallyType
+// message.
+type externalEditEvent struct {
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	ModifiedBy string `json:"modifiedBy"`
+	ModifiedAt string `json:"modifiedAt,omitempty"`
+	Generation int64  `json:"generation"`
+	// Invalid lists why the changed spec fails validation; empty when it
+	// is valid.
+	Invalid []string `json:"invalid,omitempty"`
+}
+
+// checkExternalEdits records the outside changes to every ManagedWorkload,
+// including those made while the watcher was not running.
+func (h *ConsolePersistenceHandlers) checkExternalEdits(ctx context.Context) {
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot check for outside changes", "error", err)
+		return
+	}
+	persistence := k8s.NewConsolePersistence(client)
+	for _, namespace := range h.persistenceStore.GetNamespaces() {
+		workloads, err := persistence.ListManagedWorkloads(ctx, namespace)
+		if err != nil {
+			slog.Warn("[ConsolePersistence] cannot check for outside changes", "namespace", namespace, "error", err)
+			continue
+		}
+		for i := range workloads {
+			h.recordExternalEdit(ctx, persistence, &workloads[i])
+		}
+	}
+}
+
+// handleExternalEdit records an outside change to the ManagedWorkload of a
+// watch event, off the watcher's goroutine.
+func (h *ConsolePersistenceHandlers) handleExternalEdit(mw *v1alpha1.ManagedWorkload) {
+	ctx, cancel := context.WithTimeout(context.Background(), externalEditTimeout)
+	defer cancel()
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot check for outside changes", "error", err)
+		return
+	}
+	h.recordExternalEdit(ctx, k8s.NewConsolePersistence(client), mw)
+}
+
+// recordExternalEdit compares who last changed mw's spec with the
+// ModifiedOutsideConsole condition. When the spec was last changed outside
+// the console it validates it, sets the last-modified annotations, raises
+// the condition and warns the users who may see the namespace. When the
+// console changed it since, the condition is cleared. An outside change
+// already recorded for the current generation is left alone, so the
+// console's own annotation and status writes do not loop.
+func (h *ConsolePersistenceHandlers) recordExternalEdit(ctx context.Context, persistence k8s.ConsolePersistence, mw *v1alpha1.ManagedWorkload) {
+	editor, ok := k8s.LastSpecEditor(mw.ManagedFields)
+	if !ok {
+		return
+	}
+	current := meta.FindStatusCondition(mw.Status.Conditions, v1alpha1.ConditionModifiedOutsideConsole)
+
+	if k8s.IsConsoleFieldManager(editor.Manager) {
+		if current == nil || current.Status != metav1.ConditionTrue {
+			return
+		}
+		updated, err := persistence.AnnotateManagedWorkload(ctx, mw.Namespace, mw.Name, editAnnotations(editor))
+		if err != nil {
+			slog.Warn("[ConsolePersistence] failed to annotate managed workload", "namespace", mw.Namespace, "name", mw.Name, "error", err)
+			return
+		}
+		setExternalEditCondition(updated, metav1.ConditionFalse, v1alpha1.ReasonEditedInConsole,
+			"The spec was changed through the console since")
+		if _, err := persistence.UpdateManagedWorkloadStatus(ctx, updated); err != nil {
+			slog.Warn("[ConsolePersistence] failed to clear outside change condition", "namespace", mw.Namespace, "name", mw.Name, "error", err)
+		}
+		return
+	}
+
+	if current != nil && current.Status == metav1.ConditionTrue && current.ObservedGeneration == mw.Generation {
+		return
+	}
+
+	var invalid []string
+	for _, err := range mw.Validate() {
+		invalid = append(invalid, err.Error())
+	}
+	reason := v1alpha1.ReasonExternalEdit
+	message := fmt.Sprintf("The spec was changed outside the console by %s", editor.Manager)
+	if len(invalid) > 0 {
+		reason = v1alpha1.ReasonInvalidExternalEdit
+		message += " and is invalid: " + summarizeViolations(invalid)
+	}
+	slog.Warn("[ConsolePersistence] managed workload changed outside the console",
+		"namespace", mw.Namespace, "name", mw.Name, "manager", editor.Manager, "generation", mw.Generation, "valid", len(invalid) == 0)
+
+	updated, err := persistence.AnnotateManagedWorkload(ctx, mw.Namespace, mw.Name, editAnnotations(editor))
+	if err != nil {
+		slog.Warn("[ConsolePersistence] failed to annotate managed workload", "namespace", mw.Namespace, "name", mw.Name, "error", err)
+		return
+	}
+	setExternalEditCondition(updated, metav1.ConditionTrue, reason, message)
+	if _, err := persistence.UpdateManagedWorkloadStatus(ctx, updated); err != nil {
+		slog.Warn("[ConsolePersistence] failed to set outside change condition", "namespace", mw.Namespace, "name", mw.Name, "error", err)
+	}
+
+	h.broadcastToNamespace(mw.Namespace, Message{
+		Type: ManagedWorkloadModifiedExternallyType,
+		Data: externalEditEvent{
+			Namespace:  mw.Namespace,
+			Name:       mw.Name,
+			ModifiedBy: editor.Manager,
+			ModifiedAt: formatEditTime(editor.Time),
+			Generation: mw.Generation,
+			Invalid:    invalid,
+		},
+	})
+}
+
+// externalEditBlocksDeploy returns why a ManagedWorkload must not be
+// deployed because an outside change left it invalid, or "" when it may be.
+func externalEditBlocksDeploy(mw *v1alpha1.ManagedWorkload) string {
+	cond := meta.FindStatusCondition(mw.Status.Conditions, v1alpha1.ConditionModifiedOutsideConsole)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != v1alpha1.ReasonInvalidExternalEdit ||
+		cond.ObservedGeneration != mw.Generation {
+		return ""
+	}
+	return cond.Message
+}
+
+func setExternalEditCondition(mw *v1alpha1.ManagedWorkload, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&mw.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.ConditionModifiedOutsideConsole,
+		Status:             status,
+		ObservedGeneration: mw.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// editAnnotations returns the last-modified annotations naming editor.
+func editAnnotations(editor k8s.SpecEditor) map[string]string {
+	annotations := map[string]string{v1alpha1.AnnotationLastModifiedBy: editor.Manager}
+	if at := formatEditTime(editor.Time); at != "" {
+		annotations[v1alpha1.AnnotationLastModifiedAt] = at
+	}
+	return annotations
+}
+
+// formatEditTime formats a managedFields timestamp for an annotation, or ""
+// when the entry had none.
+func formatEditTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (b *captureBackplane) externalEditEvents() []externalEditEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var events []externalEditEvent
+	for _, msg := range b.messages {
+		if msg.Type != ManagedWorkloadModifiedExternallyType {
+			continue
+		}
+		raw, _ := json.Marshal(msg.Data)
+		var ev externalEditEvent
+		if json.Unmarshal(raw, &ev) == nil {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// specEditedBy returns managedFields recording a spec change by manager at.
+func specEditedBy(manager string, at time.Time) []metav1.ManagedFieldsEntry {
+	when := metav1.NewTime(at)
+	return []metav1.ManagedFieldsEntry{{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		Time:       &when,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+	}}
+}
+
+// setupExternalEditEnv seeds a ManagedWorkload at generation 2 and returns
+// the handlers, its persistence and the backplane capturing broadcasts.
+func setupExternalEditEnv(t *testing.T, spec v1alpha1.ManagedWorkloadSpec) (*ConsolePersistenceHandlers, k8s.ConsolePersistence, *captureBackplane) {
+	t.Helper()
+	h, _ := newReconcileFixture(t, withoutDeployment(), withWorkload(func(mw *v1alpha1.ManagedWorkload) {
+		mw.Generation = 2
+		mw.Spec = spec
+	}))
+
+	bp := &captureBackplane{}
+	h.hub = NewHub()
+	h.hub.AttachBackplane(bp)
+	t.Cleanup(h.hub.Close)
+
+	client, _, err := h.persistenceStore.GetActiveClient(context.Background())
+	require.NoError(t, err)
+	return h, k8s.NewConsolePersistence(client), bp
+}
+
+// editedWorkload reads the seeded workload back with managedFields naming
+// manager, which the fake client does not record itself.
+func editedWorkload(t *testing.T, persistence k8s.ConsolePersistence, manager string, at time.Time) *v1alpha1.ManagedWorkload {
+	t.Helper()
+	mw, err := persistence.GetManagedWorkload(context.Background(), "test-ns", "my-app")
+	require.NoError(t, err)
+	mw.ManagedFields = specEditedBy(manager, at)
+	return mw
+}
+
+func TestRecordExternalEdit(t *testing.T) {
+	ctx := context.Background()
+	editedAt := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	h, persistence, bp := setupExternalEditEnv(t, v1alpha1.ManagedWorkloadSpec{
+		SourceCluster: "source-cluster",
+		WorkloadRef:   v1alpha1.WorkloadReference{Kind: "Deployment", Name: "nginx"},
+	})
+
+	h.recordExternalEdit(ctx, persistence, editedWorkload(t, persistence, "kubectl-edit", editedAt))
+
+	mw, err := persistence.GetManagedWorkload(ctx, "test-ns", "my-app")
+	require.NoError(t, err)
+	assert.Equal(t, "kubectl-edit", mw.Annotations[v1alpha1.AnnotationLastModifiedBy])
+	assert.Equal(t, "2026-10-14T12:00:00Z", mw.Annotations[v1alpha1.AnnotationLastModifiedAt])
+	cond := meta.FindStatusCondition(mw.Status.Conditions, v1alpha1.ConditionModifiedOutsideConsole)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonExternalEdit, cond.Reason)
+	assert.Equal(t, int64(2), cond.ObservedGeneration)
+	assert.Empty(t, externalEditBlocksDeploy(mw), "a valid outside change may be deployed")
+
+	require.Eventually(t, func() bool { return len(bp.externalEditEvents()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, externalEditEvent{
+		Namespace: "test-ns", Name: "my-app", ModifiedBy: "kubectl-edit",
+		ModifiedAt: "2026-10-14T12:00:00Z", Generation: 2,
+	}, bp.externalEditEvents()[0])
+
+	// The console's own annotation and status writes are seen again.
+	h.recordExternalEdit(ctx, persistence, editedWorkload(t, persistence, "kubectl-edit", editedAt))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, bp.externalEditEvents(), 1, "an outside change is recorded once per generation")
+
+	// The console changes the spec again.
+	h.recordExternalEdit(ctx, persistence, editedWorkload(t, persistence, k8s.ConsoleFieldManager, editedAt.Add(time.Minute)))
+	mw, err = persistence.GetManagedWorkload(ctx, "test-ns", "my-app")
+	require.NoError(t, err)
+	assert.Equal(t, k8s.ConsoleFieldManager, mw.Annotations[v1alpha1.AnnotationLastModifiedBy])
+	cond = meta.FindStatusCondition(mw.Status.Conditions, v1alpha1.ConditionModifiedOutsideConsole)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonEditedInConsole, cond.Reason)
+}
+
+func TestRecordExternalEdit_Invalid(t *testing.T) {
+	ctx := context.Background()
+	h, persistence, bp := setupExternalEditEnv(t, v1alpha1.ManagedWorkloadSpec{
+		SourceCluster:  "source-cluster",
+		WorkloadRef:    v1alpha1.WorkloadReference{Kind: "Deployment"},
+		TargetClusters: []string{"east"},
+		TargetGroups:   []string{"prod"},
+	})
+
+	h.recordExternalEdit(ctx, persistence, editedWorkload(t, persistence, "kubectl-edit", time.Now()))
+
+	mw, err := persistence.GetManagedWorkload(ctx, "test-ns", "my-app")
+	require.NoError(t, err)
+	cond := meta.FindStatusCondition(mw.Status.Conditions, v1alpha1.ConditionModifiedOutsideConsole)
+	require.NotNil(t, cond)
+	assert.Equal(t, v1alpha1.ReasonInvalidExternalEdit, cond.Reason)
+	assert.Contains(t, cond.Message, "spec.workloadRef.name")
+	assert.Equal(t, cond.Message, externalEditBlocksDeploy(mw))
+
+	require.Eventually(t, func() bool { return len(bp.externalEditEvents()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	assert.Len(t, bp.externalEditEvents()[0].Invalid, 2)
+
+	mw.Generation++
+	assert.Empty(t, externalEditBlocksDeploy(mw), "a newer generation is checked again")
+}
+
+func TestRecordExternalEdit_ConsoleEditIgnored(t *testing.T) {
+	ctx := context.Background()
+	h, persistence, bp := setupExternalEditEnv(t, v1alpha1.ManagedWorkloadSpec{
+		WorkloadRef: v1alpha1.WorkloadReference{Kind: "Deployment", Name: "nginx"},
+	})
+
+	h.recordExternalEdit(ctx, persistence, editedWorkload(t, persistence, k8s.ConsoleFieldManager, time.Now()))
+
+	mw, err := persistence.GetManagedWorkload(ctx, "test-ns", "my-app")
+	require.NoError(t, err)
+	assert.Empty(t, mw.Annotations)
+	assert.Nil(t, meta.FindStatusCondition(mw.Status.Conditions, v1alpha1.ConditionModifiedOutsideConsole))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, bp.externalEditEvents())
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultClusterGroupEvalInterval is how often ClusterGroup membership is
+	// re-evaluated when CLUSTER_GROUP_EVAL_INTERVAL is unset.
+	defaultClusterGroupEvalInterval = 5 * time.Minute
+	// clusterGroupEvalTimeout bounds one pass over every group.
+	clusterGroupEvalTimeout = 2 * time.Minute
+)
+
+// clusterGroupEvalInterval reads CLUSTER_GROUP_EVAL_INTERVAL. Zero disables
+// periodic re-evaluation.
+func clusterGroupEvalInterval() time.Duration {
+	raw := os.Getenv("CLUSTER_GROUP_EVAL_INTERVAL")
+	if raw == "" {
+		return defaultClusterGroupEvalInterval
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		slog.Warn("[ConsolePersistence] invalid CLUSTER_GROUP_EVAL_INTERVAL, using default",
+			"value", raw, "default", defaultClusterGroupEvalInterval)
+		return defaultClusterGroupEvalInterval
+	}
+	return d
+}
+
+// startClusterGroupEvaluator re-evaluates ClusterGroup membership now and
+// then every groupEvalInterval until ctx is done or the evaluator is stopped,
+// rebalancing the replicas of deployments whose group changed.
+func (h *ConsolePersistenceHandlers) startClusterGroupEvaluator(ctx context.Context) {
+	if h.groupEvalInterval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	h.groupEvalCancel = cancel
+	interval := h.groupEvalInterval
+	safego.GoWith("persistence/cluster-group-eval", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			for _, namespace := range h.persistenceStore.GetNamespaces() {
+				if changed := h.reevaluateClusterGroups(ctx, namespace); len(changed) > 0 {
+					h.rebalanceDeployments(ctx, namespace, changed)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	slog.Info("[ConsolePersistence] cluster group evaluator started", "interval", interval)
+}
+
+// rebalanceDeployments redeploys the completed deployments in namespace
+// targeting one of its groups whose replica split no longer matches their
+// clusters, so a workload's replica distribution follows its group's
+// membership. It returns the names of the deployments it redeployed.
+func (h *ConsolePersistenceHandlers) rebalanceDeployments(ctx context.Context, namespace string, groups []string) []string {
+	ctx, cancel := context.WithTimeout(ctx, clusterGroupEvalTimeout)
+	defer cancel()
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping replica rebalancing", "error", err)
+		return nil
+	}
+	deployments, err := k8s.NewConsolePersistence(client).ListWorkloadDeployments(ctx, namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping replica rebalancing: cannot list deployments", "error", err)
+		return nil
+	}
+
+	var rebalanced []string
+	for i := range deployments {
+		wd := &deployments[i]
+		ref := wd.Spec.TargetGroupRef
+		if ref == nil || !slices.Contains(groups, ref.Name) || (ref.Namespace != "" && ref.Namespace != namespace) {
+			continue
+		}
+		// Like a sync, a finished canary is left to a new deployment.
+		if wd.Status.Phase != "Complete" || wd.Spec.DryRun || wd.Spec.Suspend || wd.Spec.IsCanary() {
+			continue
+		}
+		drift, _, err := h.checkDeploymentDrift(ctx, wd)
+		if err != nil {
+			slog.Warn("[ConsolePersistence] cannot check replica split", "deployment", wd.Name, "error", err)
+			continue
+		}
+		if !slices.ContainsFunc(drift, func(d driftedCluster) bool { return d.Reason == driftReplicas }) {
+			continue
+		}
+		rebalanced = append(rebalanced, wd.Name)
+		reconcileCtx, reconcileCancel := context.WithTimeout(context.Background(), reconcileTimeout)
+		safego.Go(func() {
+			defer reconcileCancel()
+			h.reconcileDeployment(reconcileCtx, wd)
+		})
+	}
+	if len(rebalanced) > 0 {
+		slog.Info("[ConsolePersistence] rebalancing replicas after membership change", "deployments", rebalanced)
+	}
+	return rebalanced
+}
+
+// stopClusterGroupEvaluator stops the evaluator started by
+// startClusterGroupEvaluator, if any.
+func (h *ConsolePersistenceHandlers) stopClusterGroupEvaluator() {
+	if h.groupEvalCancel != nil {
+		h.groupEvalCancel()
+		h.groupEvalCancel = nil
+	}
+}
+
+// reevaluateClusterGroups records the current members of every group in
+// namespace whose membership can have changed in its status, and broadcasts
+// a console_resource_changed event for each group whose members differ from
+// the last evaluation. It returns the names of those groups.
+func (h *ConsolePersistenceHandlers) reevaluateClusterGroups(ctx context.Context, namespace string) []string {
+	if h.k8sClient == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, clusterGroupEvalTimeout)
+	defer cancel()
+
+	// Without the cluster list every dynamic group would match only its
+	// static members, so skip the pass rather than empty the groups.
+	if _, err := h.k8sClient.ListClusters(ctx); err != nil {
+		slog.Warn("[ConsolePersistence] skipping cluster group evaluation: cannot list clusters", "error", err)
+		return nil
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping cluster group evaluation", "error", err)
+		return nil
+	}
+	persistence := k8s.NewConsolePersistence(client)
+	groups, err := persistence.ListClusterGroups(ctx, namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping cluster group evaluation: cannot list groups", "error", err)
+		return nil
+	}
+
+	var changed []string
+	for i := range groups {
+		group := &groups[i]
+		if !clusterGroupNeedsEvaluation(group) {
+			continue
+		}
+		matched := h.evaluateClusterGroup(ctx, group)
+		membershipChanged := group.Status.LastEvaluated == nil || !slices.Equal(matched, group.Status.MatchedClusters)
+
+		now := metav1.NewTime(h.currentTime())
+		group.Status.MatchedClusters = matched
+		group.Status.MatchedClusterCount = len(matched)
+		group.Status.LastEvaluated = &now
+		group.Status.ObservedGeneration = group.Generation
+		updated, err := persistence.UpdateClusterGroupStatus(ctx, group)
+		if err != nil {
+			slog.Warn("[ConsolePersistence] failed to update cluster group status",
+				"group", group.Name, "error", err)
+			continue
+		}
+		if !membershipChanged {
+			continue
+		}
+		changed = append(changed, group.Name)
+		h.handleResourceEvent(k8s.ConsoleResourceEvent{
+			Type:         "MODIFIED",
+			ResourceType: "ClusterGroup",
+			Name:         updated.Name,
+			Namespace:    updated.Namespace,
+			Resource:     updated,
+		})
+	}
+	if len(changed) > 0 {
+		slog.Info("[ConsolePersistence] cluster group membership changed", "groups", changed)
+	}
+	return changed
+}
+
+// clusterGroupNeedsEvaluation reports whether group's members can differ
+// from its recorded status: it matches on live cluster state, includes other
+// groups, or has not been evaluated since its spec last changed.
+func clusterGroupNeedsEvaluation(group *v1alpha1.ClusterGroup) bool {
+	return hasDynamicCriteria(group.Spec) ||
+		len(group.Spec.MemberGroups) > 0 ||
+		group.Status.LastEvaluated == nil ||
+		group.Status.ObservedGeneration != group.Generation
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// setupGroupEval returns a handler that knows the clusters of
+// newExpressionTestHandler and persists the given groups.
+func setupGroupEval(t *testing.T, groups ...*v1alpha1.ClusterGroup) (*ConsolePersistenceHandlers, k8s.ConsolePersistence) {
+	t.Helper()
+	objects := make([]runtime.Object, 0, len(groups))
+	for _, g := range groups {
+		g.TypeMeta = metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"}
+		g.Namespace = "test-ns"
+		u, err := g.ToUnstructured()
+		require.NoError(t, err)
+		objects = append(objects, u)
+	}
+	h, fakeDyn := setupReconcileEnv(t, objects...)
+	h.k8sClient = newExpressionTestHandler(t).k8sClient
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	return h, k8s.NewConsolePersistence(fakeDyn)
+}
+
+func TestReevaluateClusterGroups_RecordsMembership(t *testing.T) {
+	dynamic := &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+		Spec:       v1alpha1.ClusterGroupSpec{Expression: `cluster.gpuCount >= 8`},
+	}
+	static := &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		Spec:       v1alpha1.ClusterGroupSpec{StaticMembers: []string{"cpu-dev"}},
+	}
+	h, persistence := setupGroupEval(t, dynamic, static)
+
+	changed := h.reevaluateClusterGroups(context.Background(), "test-ns")
+	assert.ElementsMatch(t, []string{"gpu", "dev"}, changed)
+
+	got, err := persistence.GetClusterGroup(context.Background(), "test-ns", "gpu")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu-prod"}, got.Status.MatchedClusters)
+	assert.Equal(t, 1, got.Status.MatchedClusterCount)
+	require.NotNil(t, got.Status.LastEvaluated)
+	assert.True(t, got.Status.LastEvaluated.Time.Equal(h.currentTime()))
+
+	// Nothing changed since: the dynamic group is re-evaluated but not
+	// reported, and the static one is left alone.
+	assert.Empty(t, h.reevaluateClusterGroups(context.Background(), "test-ns"))
+}
+
+func TestReevaluateClusterGroups_ReportsMembershipChange(t *testing.T) {
+	group := &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: v1alpha1.ClusterGroupSpec{DynamicFilters: []v1alpha1.ClusterFilter{
+			{Field: "name", Operator: "contains", Value: "-"},
+		}},
+		Status: v1alpha1.ClusterGroupStatus{
+			// Recorded before cpu-dev joined the fleet.
+			MatchedClusters:     []string{"gpu-prod"},
+			MatchedClusterCount: 1,
+			LastEvaluated:       &metav1.Time{Time: time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		},
+	}
+	h, persistence := setupGroupEval(t, group)
+
+	assert.Equal(t, []string{"prod"}, h.reevaluateClusterGroups(context.Background(), "test-ns"))
+	got, err := persistence.GetClusterGroup(context.Background(), "test-ns", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu-dev", "gpu-prod"}, got.Status.MatchedClusters)
+	assert.Equal(t, 2, got.Status.MatchedClusterCount)
+}
+
+func TestReevaluateClusterGroups_NoClusterAccess(t *testing.T) {
+	h, _ := setupGroupEval(t, &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu"},
+		Spec:       v1alpha1.ClusterGroupSpec{Expression: `cluster.gpuCount >= 8`},
+	})
+	h.k8sClient = nil
+	assert.Empty(t, h.reevaluateClusterGroups(context.Background(), "test-ns"))
+}
+
+func TestClusterGroupEvalInterval(t *testing.T) {
+	t.Setenv("CLUSTER_GROUP_EVAL_INTERVAL", "")
+	assert.Equal(t, defaultClusterGroupEvalInterval, clusterGroupEvalInterval())
+	t.Setenv("CLUSTER_GROUP_EVAL_INTERVAL", "30s")
+	assert.Equal(t, 30*time.Second, clusterGroupEvalInterval())
+	t.Setenv("CLUSTER_GROUP_EVAL_INTERVAL", "0")
+	assert.Equal(t, time.Duration(0), clusterGroupEvalInterval())
+	t.Setenv("CLUSTER_GROUP_EVAL_INTERVAL", "soon")
+	assert.Equal(t, defaultClusterGroupEvalInterval, clusterGroupEvalInterval())
+}
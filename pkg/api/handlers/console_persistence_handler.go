@@ -4,9 +4,20 @@ import (
 	"context"
 	"github.com/gofiber/fiber/v2"
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/clusterexpr"
+	"github.com/kubestellar/console/pkg/clustergroup"
+	"github.com/kubestellar/console/pkg/compliance/manifestpolicy"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
+	"github.com/kubestellar/console/pkg/notifications"
+	"github.com/kubestellar/console/pkg/operations"
 	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/vulnscan"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"log/slog"
+	"sync"
 	"time"
 )
 
@@ -22,12 +33,71 @@ type workloadDeployer interface {
 type ConsolePersistenceHandlers struct {
 	persistenceStore *store.PersistenceStore
 	k8sClient        *k8s.MultiClusterClient
-	watcher          *k8s.ConsoleWatcher
-	hub              *Hub
-	userStore        store.Store
+	// watchers watch the console CRs, one per persistence namespace.
+	watchers []*k8s.ConsoleWatcher
+	// audiences caches who may see each restricted persistence namespace.
+	audiences namespaceAudiences
+	hub       *Hub
+	userStore store.Store
 	// deployer is used by reconcileDeployment. When nil, k8sClient is used.
 	// Tests can inject a fake to exercise per-cluster failure paths.
 	deployer workloadDeployer
+	// healthChecker gates each batch of a rolling rollout. When nil,
+	// k8sClient is used.
+	healthChecker workloadHealthChecker
+	// backups takes pre-deploy Velero backups. When nil, k8sClient is used.
+	backups preDeployBackupClient
+	// renderer feeds the policy stage. When nil, k8sClient is used.
+	renderer workloadRenderer
+	// capacity weighs clusters for the Capacity replica distribution. When
+	// nil, k8sClient is used.
+	capacity clusterCapacityReader
+	// topology reads cluster regions and zones for placement constraints.
+	// When nil, k8sClient is used.
+	topology clusterNodeReader
+	// policies gates rollouts per target cluster; nil disables the stage.
+	policies *manifestpolicy.Engine
+	// vulnScanner feeds image vulnerability counts to the policy stage;
+	// nil leaves them out.
+	vulnScanner vulnscan.Scanner
+	// project selects the change metadata policy checked before rollout.
+	project string
+	// notifier receives rollout outcomes; nil disables notifications.
+	notifier *notifications.Service
+	// metrics exports deployment phases and durations; nil records nothing.
+	metrics *deploymentMetrics
+	// now is the reconciler's clock for deployment windows; nil is time.Now.
+	now func() time.Time
+	// queueTimers resume queued deployments when their window opens, keyed
+	// by namespace/name.
+	queueMu     sync.Mutex
+	queueTimers map[string]*time.Timer
+	// groupEvalInterval is how often ClusterGroup membership is
+	// re-evaluated while the watcher runs; zero disables it.
+	groupEvalInterval time.Duration
+	groupEvalCancel   context.CancelFunc
+	// healthInterval is how often the health of deployed workloads is
+	// read while the watcher runs; zero disables it.
+	healthInterval time.Duration
+	healthCancel   context.CancelFunc
+	// operations runs syncs requested with async=true; nil makes every
+	// sync synchronous.
+	operations *operations.Manager
+	// crdInstaller checks for and installs the console CRDs. When nil,
+	// k8sClient is used.
+	crdInstaller consoleCRDInstaller
+	// capturer reads the live workloads imports turn into ManagedWorkloads.
+	// When nil, k8sClient is used.
+	capturer workloadCapturer
+	// limits caps the ManagedWorkloads imports create; nil leaves them
+	// unlimited.
+	limits *limits.Enforcer
+	// images reads the image digests deployed workloads run. When nil,
+	// k8sClient is used.
+	images workloadImageReader
+	// provenance builds the SBOM and attestation references recorded with
+	// each deployed image.
+	provenance provenanceTemplates
 }
 
 // NewConsolePersistenceHandlers creates a new console persistence handlers instance
@@ -42,6 +112,11 @@ func NewConsolePersistenceHandlers(
 		k8sClient:        k8sClient,
 		hub:              hub,
 		userStore:        userStore,
+
+		groupEvalInterval: clusterGroupEvalInterval(),
+		healthInterval:    workloadHealthInterval(),
+		metrics:           newDeploymentMetrics(prometheus.DefaultRegisterer),
+		provenance:        imageProvenanceTemplates(),
 	}
 
 	// Set up cluster health checker
@@ -75,12 +150,12 @@ func (h *ConsolePersistenceHandlers) UpdateConfig(c *fiber.Ctx) error {
 
 	var config store.PersistenceConfig
 	if err := c.BodyParser(&config); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+		return localizedError(c, 400, "request.invalidBody")
 	}
 
 	if err := h.persistenceStore.UpdateConfig(config); err != nil {
 		slog.Warn("[ConsolePersistence] bad request", "error", err)
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		return localizedError(c, 400, "request.invalid")
 	}
 
 	// Restart watcher if needed. Use a background context instead of the
@@ -109,25 +184,11 @@ func (h *ConsolePersistenceHandlers) GetStatus(c *fiber.Ctx) error {
 	return c.JSON(status)
 }
 
-// ListManagedWorkloads returns all managed workloads
+// ListManagedWorkloads returns the managed workloads, optionally a page of
+// them narrowed by selectors (see parsePersistenceListQuery)
 // GET /api/persistence/workloads
 func (h *ConsolePersistenceHandlers) ListManagedWorkloads(c *fiber.Ctx) error {
-	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
-	if err != nil {
-		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
-		return c.Status(503).JSON(fiber.Map{"error": "service unavailable"})
-	}
-
-	namespace := h.persistenceStore.GetNamespace()
-	persistence := k8s.NewConsolePersistence(client)
-
-	workloads, err := persistence.ListManagedWorkloads(c.UserContext(), namespace)
-	if err != nil {
-		slog.Warn("[ConsolePersistence] internal error", "error", err)
-		return c.Status(500).JSON(fiber.Map{"error": "internal server error"})
-	}
-
-	return c.JSON(workloads)
+	return listPersistence(h, c, k8s.ConsolePersistence.ListManagedWorkloadsPage, nil)
 }
 
 // GetManagedWorkload returns a specific managed workload
@@ -135,49 +196,111 @@ func (h *ConsolePersistenceHandlers) ListManagedWorkloads(c *fiber.Ctx) error {
 func (h *ConsolePersistenceHandlers) GetManagedWorkload(c *fiber.Ctx) error {
 	name := c.Params("name")
 
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
 	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
 	if err != nil {
 		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
-		return c.Status(503).JSON(fiber.Map{"error": "service unavailable"})
+		return localizedError(c, 503, "server.unavailable")
 	}
 
-	namespace := h.persistenceStore.GetNamespace()
 	persistence := k8s.NewConsolePersistence(client)
 
 	workload, err := persistence.GetManagedWorkload(c.UserContext(), namespace, name)
 	if err != nil {
 		slog.Warn("[ConsolePersistence] internal error", "error", err)
-		return c.Status(500).JSON(fiber.Map{"error": "internal server error"})
+		return localizedError(c, 500, "server.internalError")
 	}
 	// A nil workload with nil error means the resource wasn't found.
 	// Return 404 instead of a 200 + JSON null so clients can distinguish
 	// "no such workload" from "empty payload".
 	if workload == nil {
-		return c.Status(404).JSON(fiber.Map{"error": "managed workload not found"})
+		return localizedError(c, 404, "persistence.workloadNotFound")
 	}
 
 	return c.JSON(workload)
 }
 
-// ListClusterGroups returns all cluster groups
-// GET /api/persistence/groups
-func (h *ConsolePersistenceHandlers) ListClusterGroups(c *fiber.Ctx) error {
+// workloadDryRunResponse is the manifest preview of a managed workload.
+type workloadDryRunResponse struct {
+	Workload string `json:"workload"`
+	// Objects are the manifests applied to each target cluster, in order.
+	Objects []*unstructured.Unstructured `json:"objects"`
+	// NetworkPolicies names the generated baseline NetworkPolicies among
+	// Objects.
+	NetworkPolicies []string `json:"networkPolicies"`
+}
+
+// DryRunManagedWorkload renders the manifests a managed workload deploys,
+// including its generated NetworkPolicies, without applying anything
+// GET /api/persistence/workloads/:name/dry-run
+func (h *ConsolePersistenceHandlers) DryRunManagedWorkload(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
 	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
 	if err != nil {
 		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
-		return c.Status(503).JSON(fiber.Map{"error": "service unavailable"})
+		return localizedError(c, 503, "server.unavailable")
 	}
 
-	namespace := h.persistenceStore.GetNamespace()
 	persistence := k8s.NewConsolePersistence(client)
 
-	groups, err := persistence.ListClusterGroups(c.UserContext(), namespace)
+	workload, err := persistence.GetManagedWorkload(c.UserContext(), namespace, name)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return localizedError(c, 404, "persistence.workloadNotFound")
+		}
 		slog.Warn("[ConsolePersistence] internal error", "error", err)
-		return c.Status(500).JSON(fiber.Map{"error": "internal server error"})
+		return localizedError(c, 500, "server.internalError")
+	}
+	if workload == nil {
+		return localizedError(c, 404, "persistence.workloadNotFound")
 	}
 
-	return c.JSON(groups)
+	renderer := h.renderer
+	if renderer == nil && h.k8sClient != nil {
+		renderer = h.k8sClient
+	}
+	if renderer == nil {
+		return localizedError(c, 503, "server.unavailable")
+	}
+	replicas := int32(0)
+	if workload.Spec.Replicas != nil {
+		replicas = *workload.Spec.Replicas
+	}
+	objects, err := renderer.RenderWorkload(c.UserContext(), workload.Spec.SourceCluster, workload.Spec.SourceNamespace,
+		workload.Spec.WorkloadRef.Name, replicas, &k8s.DeployOptions{
+			DeployedBy:    "dry-run",
+			NetworkPolicy: workload.Spec.NetworkPolicy,
+			Manifest:      capturedManifest(workload),
+		})
+	if err != nil {
+		slog.Warn("[ConsolePersistence] render failed", "name", name, "error", err)
+		return localizedError(c, 502, "persistence.renderFailed")
+	}
+
+	resp := workloadDryRunResponse{Workload: name, Objects: objects, NetworkPolicies: []string{}}
+	for _, obj := range objects {
+		if obj.GetKind() == "NetworkPolicy" && obj.GetLabels()[k8s.GeneratedNetworkPolicyLabel] == "true" {
+			resp.NetworkPolicies = append(resp.NetworkPolicies, obj.GetName())
+		}
+	}
+	return c.JSON(resp)
+}
+
+// ListClusterGroups returns the cluster groups, optionally a page of them
+// narrowed by selectors
+// GET /api/persistence/groups
+func (h *ConsolePersistenceHandlers) ListClusterGroups(c *fiber.Ctx) error {
+	return listPersistence(h, c, k8s.ConsolePersistence.ListClusterGroupsPage, nil)
 }
 
 // GetClusterGroup returns a specific cluster group
@@ -185,47 +308,91 @@ func (h *ConsolePersistenceHandlers) ListClusterGroups(c *fiber.Ctx) error {
 func (h *ConsolePersistenceHandlers) GetClusterGroup(c *fiber.Ctx) error {
 	name := c.Params("name")
 
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
 	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
 	if err != nil {
 		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
-		return c.Status(503).JSON(fiber.Map{"error": "service unavailable"})
+		return localizedError(c, 503, "server.unavailable")
 	}
 
-	namespace := h.persistenceStore.GetNamespace()
 	persistence := k8s.NewConsolePersistence(client)
 
 	group, err := persistence.GetClusterGroup(c.UserContext(), namespace, name)
 	if err != nil {
 		slog.Warn("[ConsolePersistence] internal error", "error", err)
-		return c.Status(500).JSON(fiber.Map{"error": "internal server error"})
+		return localizedError(c, 500, "server.internalError")
 	}
 	// A nil group with nil error means the resource wasn't found.
 	if group == nil {
-		return c.Status(404).JSON(fiber.Map{"error": "cluster group not found"})
+		return localizedError(c, 404, "persistence.groupNotFound")
 	}
 
 	return c.JSON(group)
 }
 
-// ListWorkloadDeployments returns all workload deployments
-// GET /api/persistence/deployments
-func (h *ConsolePersistenceHandlers) ListWorkloadDeployments(c *fiber.Ctx) error {
-	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
-	if err != nil {
-		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
-		return c.Status(503).JSON(fiber.Map{"error": "service unavailable"})
+// clusterGroupPreview is the response of PreviewClusterGroup.
+type clusterGroupPreview struct {
+	Clusters    []string `json:"clusters"`
+	Count       int      `json:"count"`
+	EvaluatedAt string   `json:"evaluatedAt"`
+	// Explain shows, per cluster, how the CEL expression evaluated. It is
+	// omitted when the spec has no expression.
+	Explain []clusterexpr.Explanation `json:"explain,omitempty"`
+}
+
+// PreviewClusterGroup evaluates a ClusterGroupSpec against current cluster
+// state without saving it, so the UI can show membership while editing.
+// POST /api/persistence/groups/preview
+func (h *ConsolePersistenceHandlers) PreviewClusterGroup(c *fiber.Ctx) error {
+	var spec v1alpha1.ClusterGroupSpec
+	if err := c.BodyParser(&spec); err != nil {
+		return localizedError(c, 400, "request.invalidBody")
 	}
 
-	namespace := h.persistenceStore.GetNamespace()
-	persistence := k8s.NewConsolePersistence(client)
+	// Report a bad expression or filter instead of previewing an empty group.
+	if spec.Expression != "" {
+		if _, err := clusterexpr.Compile(spec.Expression); err != nil {
+			body := localizedErrorBody(c, "persistence.invalidExpression", nil)
+			body["details"] = err.Error()
+			return c.Status(400).JSON(body)
+		}
+	}
+	if err := clustergroup.ValidateFilters(spec.DynamicFilters); err != nil {
+		body := localizedErrorBody(c, "persistence.invalidFilter", nil)
+		body["details"] = err.Error()
+		return c.Status(400).JSON(body)
+	}
 
-	deployments, err := persistence.ListWorkloadDeployments(c.UserContext(), namespace)
-	if err != nil {
-		slog.Warn("[ConsolePersistence] internal error", "error", err)
-		return c.Status(500).JSON(fiber.Map{"error": "internal server error"})
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
 	}
+	group := &v1alpha1.ClusterGroup{Spec: spec}
+	group.Namespace = namespace
+	clusters, explain := h.matchClusterGroup(c.UserContext(), group, true)
+	resp := clusterGroupPreview{
+		Clusters:    clusters,
+		Count:       len(clusters),
+		EvaluatedAt: time.Now().UTC().Format(time.RFC3339),
+		Explain:     explain,
+	}
+	return c.JSON(resp)
+}
 
-	return c.JSON(deployments)
+// ListWorkloadDeployments returns the workload deployments, optionally a
+// page of them narrowed by selectors
+// GET /api/persistence/deployments
+func (h *ConsolePersistenceHandlers) ListWorkloadDeployments(c *fiber.Ctx) error {
+	lang := requestLanguage(c)
+	return listPersistence(h, c, k8s.ConsolePersistence.ListWorkloadDeploymentsPage, func(deployments []v1alpha1.WorkloadDeployment) {
+		for i := range deployments {
+			localizeDeploymentStatus(lang, &deployments[i])
+		}
+	})
 }
 
 // GetWorkloadDeployment returns a specific workload deployment
@@ -233,44 +400,29 @@ func (h *ConsolePersistenceHandlers) ListWorkloadDeployments(c *fiber.Ctx) error
 func (h *ConsolePersistenceHandlers) GetWorkloadDeployment(c *fiber.Ctx) error {
 	name := c.Params("name")
 
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
 	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
 	if err != nil {
 		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
-		return c.Status(503).JSON(fiber.Map{"error": "service unavailable"})
+		return localizedError(c, 503, "server.unavailable")
 	}
 
-	namespace := h.persistenceStore.GetNamespace()
 	persistence := k8s.NewConsolePersistence(client)
 
 	deployment, err := persistence.GetWorkloadDeployment(c.UserContext(), namespace, name)
 	if err != nil {
 		slog.Warn("[ConsolePersistence] internal error", "error", err)
-		return c.Status(500).JSON(fiber.Map{"error": "internal server error"})
+		return localizedError(c, 500, "server.internalError")
 	}
 
+	localizeDeploymentStatus(requestLanguage(c), deployment)
 	return c.JSON(deployment)
 }
 
-// SyncNow triggers an immediate sync of all console resources
-// POST /api/persistence/sync
-func (h *ConsolePersistenceHandlers) SyncNow(c *fiber.Ctx) error {
-	if err := h.RequireAdmin(c); err != nil {
-		return err
-	}
-
-	if !h.persistenceStore.IsEnabled() {
-		return c.Status(400).JSON(fiber.Map{"error": "Persistence not enabled"})
-	}
-
-	// Sync logic is not yet implemented — return a clear, machine-readable status
-	return c.Status(501).JSON(fiber.Map{
-		"synced":    false,
-		"error":     "Sync operation is not implemented for this API endpoint. Please upgrade the console backend to a version that supports /api/persistence/sync.",
-		"errorCode": "SYNC_NOT_IMPLEMENTED",
-		"namespace": h.persistenceStore.GetNamespace(),
-	})
-}
-
 // TestConnection tests the connection to the persistence cluster
 // POST /api/persistence/test
 func (h *ConsolePersistenceHandlers) TestConnection(c *fiber.Ctx) error {
@@ -283,7 +435,7 @@ func (h *ConsolePersistenceHandlers) TestConnection(c *fiber.Ctx) error {
 		Cluster string `json:"cluster"`
 	}
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+		return localizedError(c, 400, "request.invalidBody")
 	}
 
 	// persistenceProbeTimeout is the timeout for a single-cluster health probe.
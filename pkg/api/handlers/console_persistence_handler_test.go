@@ -1,10 +1,19 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestSetTerminalStatusHistory(t *testing.T) {
@@ -122,3 +131,53 @@ func TestMaxDeploymentHistoryConstant(t *testing.T) {
 	// Verify the constant is set to a reasonable value
 	assert.Equal(t, 50, maxDeploymentHistory, "maxDeploymentHistory should be 50 to prevent etcd object-size issues")
 }
+
+func TestDryRunManagedWorkload(t *testing.T) {
+	policy := &v1alpha1.GeneratedNetworkPolicy{Enabled: true}
+	mw := &v1alpha1.ManagedWorkload{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "ManagedWorkload",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "test-ns"},
+		Spec: v1alpha1.ManagedWorkloadSpec{
+			SourceCluster:   "source-cluster",
+			SourceNamespace: "default",
+			WorkloadRef:     v1alpha1.WorkloadReference{Kind: "Deployment", Name: "nginx"},
+			NetworkPolicy:   policy,
+		},
+	}
+	mwU, err := mw.ToUnstructured()
+	require.NoError(t, err)
+	h, _ := setupReconcileEnv(t, mwU)
+	renderer := &fakeRenderer{objs: []*unstructured.Unstructured{
+		{Object: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "nginx"}}},
+		{Object: map[string]interface{}{"apiVersion": "networking.k8s.io/v1", "kind": "NetworkPolicy", "metadata": map[string]interface{}{
+			"name": "nginx-console-baseline", "labels": map[string]interface{}{k8s.GeneratedNetworkPolicyLabel: "true"},
+		}}},
+		{Object: map[string]interface{}{"apiVersion": "networking.k8s.io/v1", "kind": "NetworkPolicy", "metadata": map[string]interface{}{"name": "copied"}}},
+	}}
+	h.renderer = renderer
+	app := fiber.New()
+	app.Get("/api/persistence/workloads/:name/dry-run", h.DryRunManagedWorkload)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/workloads/my-app/dry-run", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body workloadDryRunResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "my-app", body.Workload)
+	assert.Len(t, body.Objects, 3)
+	assert.Equal(t, []string{"nginx-console-baseline"}, body.NetworkPolicies, "copied policies are not listed")
+	require.NotNil(t, renderer.opts)
+	assert.Equal(t, policy, renderer.opts.NetworkPolicy)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/workloads/missing/dry-run", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	renderer.err = errors.New("source cluster unreachable")
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/workloads/my-app/dry-run", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
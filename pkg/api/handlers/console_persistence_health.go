@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultWorkloadHealthInterval is how often the health of deployed
+	// workloads is read when WORKLOAD_HEALTH_INTERVAL is unset.
+	defaultWorkloadHealthInterval = 30 * time.Second
+	// workloadHealthTimeout bounds one pass over every workload.
+	workloadHealthTimeout = 2 * time.Minute
+)
+
+// ManagedWorkloadHealthType is the WebSocket message type for a change in a
+// managed workload's health on one of its clusters.
+const ManagedWorkloadHealthType = "managed_workload_health"
+
+// Health of a managed workload on a cluster it is deployed to, recorded as
+// ClusterDeploymentStatus.Status.
+const (
+	workloadHealthAvailable   = "Available"   // every desired pod runs the latest spec and is ready
+	workloadHealthProgressing = "Progressing" // every desired pod is ready, some still run an older spec
+	workloadHealthDegraded    = "Degraded"    // fewer pods are ready than desired
+	workloadHealthMissing     = "Missing"     // the workload is gone from the cluster
+	workloadHealthUnknown     = "Unknown"     // the workload could not be read yet
+)
+
+// workloadHealthEvent is the data of a ManagedWorkloadHealthType message.
+type workloadHealthEvent struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	Cluster        string `json:"cluster"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previousStatus"`
+	Replicas       string `json:"replicas,omitempty"`
+	Message        string `json:"message,omitempty"`
+}
+
+// workloadHealthInterval reads WORKLOAD_HEALTH_INTERVAL. Zero disables the
+// health watch.
+func workloadHealthInterval() time.Duration {
+	raw := os.Getenv("WORKLOAD_HEALTH_INTERVAL")
+	if raw == "" {
+		return defaultWorkloadHealthInterval
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		slog.Warn("[ConsolePersistence] invalid WORKLOAD_HEALTH_INTERVAL, using default",
+			"value", raw, "default", defaultWorkloadHealthInterval)
+		return defaultWorkloadHealthInterval
+	}
+	return d
+}
+
+// startWorkloadHealthWatch reads the health of every deployed workload now
+// and then every healthInterval until ctx is done or the watch is stopped.
+func (h *ConsolePersistenceHandlers) startWorkloadHealthWatch(ctx context.Context) {
+	if h.healthInterval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	h.healthCancel = cancel
+	interval := h.healthInterval
+	safego.GoWith("persistence/workload-health", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			h.pollWorkloadHealth(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	slog.Info("[ConsolePersistence] workload health watch started", "interval", interval)
+}
+
+// stopWorkloadHealthWatch stops the watch started by
+// startWorkloadHealthWatch, if any.
+func (h *ConsolePersistenceHandlers) stopWorkloadHealthWatch() {
+	if h.healthCancel != nil {
+		h.healthCancel()
+		h.healthCancel = nil
+	}
+}
+
+// pollWorkloadHealth reads each ManagedWorkload on the clusters a
+// WorkloadDeployment completed on and records the result in its
+// status.deployedClusters. Clusters it is no longer deployed to are
+// dropped. A cluster whose health changed is broadcast as a
+// ManagedWorkloadHealthType message.
+func (h *ConsolePersistenceHandlers) pollWorkloadHealth(ctx context.Context) {
+	var checker workloadHealthChecker = h.healthChecker
+	if checker == nil && h.k8sClient != nil {
+		checker = h.k8sClient
+	}
+	if checker == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, workloadHealthTimeout)
+	defer cancel()
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping workload health check", "error", err)
+		return
+	}
+	persistence := k8s.NewConsolePersistence(client)
+	for _, namespace := range h.persistenceStore.GetNamespaces() {
+		h.pollNamespaceWorkloadHealth(ctx, checker, persistence, namespace)
+	}
+}
+
+// pollNamespaceWorkloadHealth is pollWorkloadHealth for the console CRs of
+// one persistence namespace.
+func (h *ConsolePersistenceHandlers) pollNamespaceWorkloadHealth(ctx context.Context, checker workloadHealthChecker, persistence k8s.ConsolePersistence, namespace string) {
+	deployments, err := persistence.ListWorkloadDeployments(ctx, namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping workload health check: cannot list deployments",
+			"namespace", namespace, "error", err)
+		return
+	}
+	workloads, err := persistence.ListManagedWorkloads(ctx, namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] skipping workload health check: cannot list workloads",
+			"namespace", namespace, "error", err)
+		return
+	}
+
+	// deployed holds the clusters each workload is deployed to, keyed by
+	// namespace/name.
+	deployed := make(map[string][]string)
+	for i := range deployments {
+		wd := &deployments[i]
+		// The watcher only reports deployments created after it started.
+		h.metrics.track(wd)
+		if wd.Spec.DryRun {
+			continue
+		}
+		ns := wd.Spec.WorkloadRef.Namespace
+		if ns == "" {
+			ns = wd.Namespace
+		}
+		key := deploymentKey(ns, wd.Spec.WorkloadRef.Name)
+		for _, cs := range wd.Status.ClusterStatuses {
+			if cs.Phase == "Complete" && !slices.Contains(deployed[key], cs.Cluster) {
+				deployed[key] = append(deployed[key], cs.Cluster)
+			}
+		}
+	}
+
+	for i := range workloads {
+		mw := &workloads[i]
+		if !healthCheckableKinds[mw.Spec.WorkloadRef.Kind] {
+			continue
+		}
+		clusters := deployed[deploymentKey(mw.Namespace, mw.Name)]
+		if len(clusters) == 0 && len(mw.Status.DeployedClusters) == 0 {
+			continue
+		}
+		statuses, changed := h.readWorkloadHealth(ctx, checker, mw, clusters)
+		if !changed {
+			continue
+		}
+		previous := mw.Status.DeployedClusters
+		mw.Status.DeployedClusters = statuses
+		if _, err := persistence.UpdateManagedWorkloadStatus(ctx, mw); err != nil {
+			slog.Warn("[ConsolePersistence] failed to record workload health", "workload", mw.Name, "error", err)
+			continue
+		}
+		h.publishWorkloadHealth(mw, previous)
+	}
+}
+
+// readWorkloadHealth returns mw's health on clusters, sorted by cluster,
+// and whether it differs from mw.Status.DeployedClusters. An entry keeps its
+// LastUpdateTime until its status, replicas or message change. A cluster
+// that cannot be read keeps its last recorded health.
+func (h *ConsolePersistenceHandlers) readWorkloadHealth(
+	ctx context.Context, checker workloadHealthChecker, mw *v1alpha1.ManagedWorkload, clusters []string,
+) ([]v1alpha1.ClusterDeploymentStatus, bool) {
+	prev := make(map[string]v1alpha1.ClusterDeploymentStatus, len(mw.Status.DeployedClusters))
+	for _, s := range mw.Status.DeployedClusters {
+		prev[s.Cluster] = s
+	}
+	clusters = slices.Sorted(slices.Values(clusters))
+	changed := len(clusters) != len(prev)
+	now := metav1.NewTime(h.currentTime())
+	ref := mw.Spec.WorkloadRef
+	statuses := make([]v1alpha1.ClusterDeploymentStatus, 0, len(clusters))
+	for _, cluster := range clusters {
+		old, seen := prev[cluster]
+		next := v1alpha1.ClusterDeploymentStatus{Cluster: cluster}
+		r, err := checker.GetWorkloadReadiness(ctx, cluster, ref.Kind, mw.Spec.SourceNamespace, ref.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			next.Status = workloadHealthMissing
+			next.Message = ref.Kind + " " + mw.Spec.SourceNamespace + "/" + ref.Name + " not found"
+		case err != nil:
+			slog.Debug("[ConsolePersistence] cannot read workload health",
+				"workload", mw.Name, "cluster", cluster, "error", err)
+			if seen {
+				statuses = append(statuses, old)
+				continue
+			}
+			next.Status = workloadHealthUnknown
+			next.Message = "Workload health could not be read"
+		default:
+			next.Status, next.Message = workloadHealth(r)
+			next.Replicas = fmt.Sprintf("%d/%d", r.Ready, r.Desired)
+		}
+		if seen && old.Status == next.Status && old.Replicas == next.Replicas && old.Message == next.Message {
+			statuses = append(statuses, old)
+			continue
+		}
+		next.LastUpdateTime = &now
+		statuses = append(statuses, next)
+		changed = true
+	}
+	return statuses, changed
+}
+
+// workloadHealth maps a workload's readiness to its health and a message.
+func workloadHealth(r k8s.WorkloadReadiness) (string, string) {
+	switch {
+	case r.Ready < r.Desired:
+		return workloadHealthDegraded, fmt.Sprintf("%d/%d pods ready", r.Ready, r.Desired)
+	case r.Updated < r.Desired:
+		return workloadHealthProgressing, fmt.Sprintf("%d/%d pods updated", r.Updated, r.Desired)
+	default:
+		return workloadHealthAvailable, ""
+	}
+}
+
+// publishWorkloadHealth broadcasts the clusters whose health changed from
+// previous. Clusters seen for the first time are not broadcast.
+func (h *ConsolePersistenceHandlers) publishWorkloadHealth(mw *v1alpha1.ManagedWorkload, previous []v1alpha1.ClusterDeploymentStatus) {
+	if h.hub == nil {
+		return
+	}
+	for _, s := range mw.Status.DeployedClusters {
+		i := slices.IndexFunc(previous, func(p v1alpha1.ClusterDeploymentStatus) bool { return p.Cluster == s.Cluster })
+		if i < 0 || previous[i].Status == s.Status {
+			continue
+		}
+		slog.Info("[ConsolePersistence] workload health changed",
+			"workload", mw.Name, "cluster", s.Cluster, "from", previous[i].Status, "to", s.Status)
+		h.broadcastToNamespace(mw.Namespace, Message{
+			Type: ManagedWorkloadHealthType,
+			Data: workloadHealthEvent{
+				Namespace:      mw.Namespace,
+				Name:           mw.Name,
+				Cluster:        s.Cluster,
+				Status:         s.Status,
+				PreviousStatus: previous[i].Status,
+				Replicas:       s.Replicas,
+				Message:        s.Message,
+			},
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// healthReadiness implements workloadHealthChecker with a result per
+// cluster that tests change between polls.
+type healthReadiness struct {
+	mu     sync.Mutex
+	ready  map[string]k8s.WorkloadReadiness
+	errors map[string]error
+}
+
+func (f *healthReadiness) GetWorkloadReadiness(_ context.Context, cluster, _, _, _ string) (k8s.WorkloadReadiness, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.errors[cluster]; err != nil {
+		return k8s.WorkloadReadiness{}, err
+	}
+	return f.ready[cluster], nil
+}
+
+func (f *healthReadiness) set(cluster string, r k8s.WorkloadReadiness, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ready[cluster] = r
+	f.errors[cluster] = err
+}
+
+func (b *captureBackplane) healthEvents() []workloadHealthEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var events []workloadHealthEvent
+	for _, msg := range b.messages {
+		if msg.Type != ManagedWorkloadHealthType {
+			continue
+		}
+		raw, _ := json.Marshal(msg.Data)
+		var ev workloadHealthEvent
+		if json.Unmarshal(raw, &ev) == nil {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func deployedClusters(t *testing.T, persistence k8s.ConsolePersistence) map[string]v1alpha1.ClusterDeploymentStatus {
+	t.Helper()
+	mw, err := persistence.GetManagedWorkload(context.Background(), "test-ns", "my-app")
+	require.NoError(t, err)
+	m := make(map[string]v1alpha1.ClusterDeploymentStatus, len(mw.Status.DeployedClusters))
+	for _, s := range mw.Status.DeployedClusters {
+		m[s.Cluster] = s
+	}
+	return m
+}
+
+func TestPollWorkloadHealth(t *testing.T) {
+	h := setupSyncEnv(t, []string{"east", "west", "north"}, []string{"east", "west"}, false)
+	client, _, err := h.persistenceStore.GetActiveClient(context.Background())
+	require.NoError(t, err)
+	persistence := k8s.NewConsolePersistence(client)
+
+	rolledOut := k8s.WorkloadReadiness{Desired: 3, Ready: 3, Updated: 3}
+	checker := &healthReadiness{
+		ready:  map[string]k8s.WorkloadReadiness{"east": rolledOut, "west": rolledOut},
+		errors: map[string]error{},
+	}
+	h.healthChecker = checker
+	bp := &captureBackplane{}
+	h.hub = NewHub()
+	h.hub.AttachBackplane(bp)
+	t.Cleanup(h.hub.Close)
+
+	h.pollWorkloadHealth(context.Background())
+	got := deployedClusters(t, persistence)
+	require.Len(t, got, 2, "only clusters the deployment completed on are watched")
+	assert.Equal(t, workloadHealthAvailable, got["east"].Status)
+	assert.Equal(t, "3/3", got["east"].Replicas)
+	require.NotNil(t, got["east"].LastUpdateTime)
+	firstUpdate := got["east"].LastUpdateTime.Time
+
+	// east loses a pod, west becomes unreadable and keeps its last health.
+	checker.set("east", k8s.WorkloadReadiness{Desired: 3, Ready: 2, Updated: 3}, nil)
+	checker.set("west", k8s.WorkloadReadiness{}, errors.New("connection refused"))
+	h.now = func() time.Time { return time.Date(2026, 10, 14, 12, 1, 0, 0, time.UTC) }
+	h.pollWorkloadHealth(context.Background())
+	got = deployedClusters(t, persistence)
+	assert.Equal(t, workloadHealthDegraded, got["east"].Status)
+	assert.Equal(t, "2/3", got["east"].Replicas)
+	assert.Equal(t, "2/3 pods ready", got["east"].Message)
+	assert.True(t, got["east"].LastUpdateTime.Time.After(firstUpdate))
+	assert.Equal(t, workloadHealthAvailable, got["west"].Status)
+	assert.True(t, got["west"].LastUpdateTime.Time.Equal(firstUpdate))
+
+	// west is deleted from its cluster.
+	checker.set("west", k8s.WorkloadReadiness{}, apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx"))
+	h.pollWorkloadHealth(context.Background())
+	got = deployedClusters(t, persistence)
+	assert.Equal(t, workloadHealthMissing, got["west"].Status)
+	assert.Equal(t, "Deployment default/nginx not found", got["west"].Message)
+
+	require.Eventually(t, func() bool { return len(bp.healthEvents()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	events := bp.healthEvents()
+	assert.Equal(t, workloadHealthEvent{
+		Namespace: "test-ns", Name: "my-app", Cluster: "east",
+		Status: workloadHealthDegraded, PreviousStatus: workloadHealthAvailable,
+		Replicas: "2/3", Message: "2/3 pods ready",
+	}, events[0])
+	assert.Equal(t, "west", events[1].Cluster)
+	assert.Equal(t, workloadHealthMissing, events[1].Status)
+}
+
+func TestPollWorkloadHealth_DropsUndeployedClusters(t *testing.T) {
+	h := setupSyncEnv(t, []string{"east"}, nil, false)
+	client, _, err := h.persistenceStore.GetActiveClient(context.Background())
+	require.NoError(t, err)
+	persistence := k8s.NewConsolePersistence(client)
+	mw, err := persistence.GetManagedWorkload(context.Background(), "test-ns", "my-app")
+	require.NoError(t, err)
+	mw.Status.DeployedClusters = []v1alpha1.ClusterDeploymentStatus{{Cluster: "gone", Status: workloadHealthAvailable}}
+	_, err = persistence.UpdateManagedWorkloadStatus(context.Background(), mw)
+	require.NoError(t, err)
+	h.healthChecker = &healthReadiness{}
+
+	h.pollWorkloadHealth(context.Background())
+	assert.Empty(t, deployedClusters(t, persistence))
+}
+
+func TestWorkloadHealth(t *testing.T) {
+	for _, tc := range []struct {
+		r    k8s.WorkloadReadiness
+		want string
+	}{
+		{k8s.WorkloadReadiness{Desired: 3, Ready: 3, Updated: 3}, workloadHealthAvailable},
+		{k8s.WorkloadReadiness{Desired: 3, Ready: 3, Updated: 1}, workloadHealthProgressing},
+		{k8s.WorkloadReadiness{Desired: 3, Ready: 0, Updated: 3}, workloadHealthDegraded},
+		{k8s.WorkloadReadiness{}, workloadHealthAvailable},
+	} {
+		got, _ := workloadHealth(tc.r)
+		assert.Equal(t, tc.want, got, "%+v", tc.r)
+	}
+}
+
+func TestWorkloadHealthInterval(t *testing.T) {
+	t.Setenv("WORKLOAD_HEALTH_INTERVAL", "")
+	assert.Equal(t, defaultWorkloadHealthInterval, workloadHealthInterval())
+	t.Setenv("WORKLOAD_HEALTH_INTERVAL", "10s")
+	assert.Equal(t, 10*time.Second, workloadHealthInterval())
+	t.Setenv("WORKLOAD_HEALTH_INTERVAL", "0")
+	assert.Zero(t, workloadHealthInterval())
+	t.Setenv("WORKLOAD_HEALTH_INTERVAL", "often")
+	assert.Equal(t, defaultWorkloadHealthInterval, workloadHealthInterval())
+}
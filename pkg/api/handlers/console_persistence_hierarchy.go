@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/clustergroup"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// clusterGroupHierarchy lists the ClusterGroups of namespace, the persistence
+// namespace when empty, and indexes their memberGroups edges.
+func (h *ConsolePersistenceHandlers) clusterGroupHierarchy(ctx context.Context, namespace string) (*clustergroup.Hierarchy, error) {
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get persistence client: %w", err)
+	}
+	if namespace == "" {
+		namespace = h.persistenceStore.GetNamespace()
+	}
+	groups, err := k8s.NewConsolePersistence(client).ListClusterGroups(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster groups: %w", err)
+	}
+	return clustergroup.New(groups), nil
+}
+
+// clusterGroupSettingsResponse is the response of GetClusterGroupSettings.
+type clusterGroupSettingsResponse struct {
+	Group string `json:"group"`
+	clustergroup.Effective
+	// Descendants are the groups that inherit from this one.
+	Descendants []string `json:"descendants,omitempty"`
+}
+
+// GetClusterGroupSettings returns a group's settings after inheritance from
+// the groups that include it.
+// GET /api/persistence/groups/:name/settings
+func (h *ConsolePersistenceHandlers) GetClusterGroupSettings(c *fiber.Ctx) error {
+	name := c.Params("name")
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
+	hierarchy, err := h.clusterGroupHierarchy(c.UserContext(), namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
+		return localizedError(c, 503, "server.unavailable")
+	}
+	if hierarchy.Group(name) == nil {
+		return localizedError(c, 404, "persistence.groupNotFound")
+	}
+
+	return c.JSON(clusterGroupSettingsResponse{
+		Group:       name,
+		Effective:   hierarchy.Effective(name),
+		Descendants: hierarchy.Descendants(name),
+	})
+}
+
+// frozenClusters returns, for each of clusters that belongs to a frozen
+// group, a description of the freeze. A group is frozen by its own settings
+// or by inheritance; a member group that sets frozen: false does not freeze
+// its own members.
+func (h *ConsolePersistenceHandlers) frozenClusters(ctx context.Context, namespace string, clusters []string) (map[string]string, error) {
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+	hierarchy, err := h.clusterGroupHierarchy(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(clusters))
+	for _, c := range clusters {
+		wanted[c] = true
+	}
+
+	frozen := make(map[string]string)
+	for _, name := range hierarchy.Names() {
+		eff := hierarchy.Effective(name)
+		if !eff.IsFrozen() {
+			continue
+		}
+		reason := "Frozen by cluster group " + eff.FrozenBy
+		if eff.Settings.FreezeReason != "" {
+			reason += ": " + eff.Settings.FreezeReason
+		}
+		members, _ := h.matchOwnMembers(ctx, hierarchy.Group(name), false)
+		for _, c := range members {
+			if _, seen := frozen[c]; wanted[c] && !seen {
+				frozen[c] = reason
+			}
+		}
+	}
+	return frozen, nil
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func clusterGroupObject(t *testing.T, name string, spec v1alpha1.ClusterGroupSpec) runtime.Object {
+	t.Helper()
+	cg := &v1alpha1.ClusterGroup{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec:       spec,
+	}
+	u, err := cg.ToUnstructured()
+	require.NoError(t, err)
+	return u
+}
+
+// orgHierarchy is region-emea -> env-prod (frozen) -> team-a, with team-b
+// opting out of the freeze.
+func orgHierarchy(t *testing.T) []runtime.Object {
+	frozen, thawed := true, false
+	return []runtime.Object{
+		clusterGroupObject(t, "region-emea", v1alpha1.ClusterGroupSpec{
+			StaticMembers: []string{"emea-edge"},
+			MemberGroups:  []string{"env-prod"},
+			Settings: &v1alpha1.ClusterGroupSettings{
+				NotificationRoutes: []v1alpha1.NotificationRoute{{Type: "slack", Target: "#emea-ops"}},
+			},
+		}),
+		clusterGroupObject(t, "env-prod", v1alpha1.ClusterGroupSpec{
+			MemberGroups: []string{"team-a", "team-b"},
+			Settings:     &v1alpha1.ClusterGroupSettings{Frozen: &frozen, FreezeReason: "year-end"},
+		}),
+		clusterGroupObject(t, "team-a", v1alpha1.ClusterGroupSpec{StaticMembers: []string{"prod-a"}}),
+		clusterGroupObject(t, "team-b", v1alpha1.ClusterGroupSpec{
+			StaticMembers: []string{"prod-b"},
+			Settings:      &v1alpha1.ClusterGroupSettings{Frozen: &thawed},
+		}),
+	}
+}
+
+func TestMatchClusterGroup_IncludesMemberGroups(t *testing.T) {
+	h, _ := setupReconcileEnv(t, orgHierarchy(t)...)
+
+	hierarchy, err := h.clusterGroupHierarchy(context.Background(), "test-ns")
+	require.NoError(t, err)
+	members := h.evaluateClusterGroup(context.Background(), hierarchy.Group("region-emea"))
+	assert.Equal(t, []string{"emea-edge", "prod-a", "prod-b"}, members)
+}
+
+func TestReconcileDeployment_FrozenGroupBlocksClusters(t *testing.T) {
+	h, wd, deployer := setupPolicyReconcile(t, "prod-a", "prod-b", "emea-edge")
+	h.policies = nil
+	// Seed the hierarchy alongside the workload and deployment.
+	client, _, err := h.persistenceStore.GetActiveClient(context.Background())
+	require.NoError(t, err)
+	for _, obj := range orgHierarchy(t) {
+		_, err := client.Resource(v1alpha1.ClusterGroupGVR).Namespace("test-ns").
+			Create(context.Background(), obj.(*unstructured.Unstructured), metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.ElementsMatch(t, []string{"prod-b", "emea-edge"}, deployer.targets,
+		"team-b opts out of the inherited freeze; the region itself is not frozen")
+	statuses := clusterStatuses(wd)
+	assert.Equal(t, "Failed", statuses["prod-a"].Phase)
+	assert.Equal(t, "Frozen by cluster group env-prod: year-end", statuses["prod-a"].Message)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Contains(t, wd.Status.History[0].Message, "2 succeeded, 1 failed")
+}
+
+func TestGetClusterGroupSettings(t *testing.T) {
+	h, _ := setupReconcileEnv(t, orgHierarchy(t)...)
+	app := fiber.New()
+	app.Get("/api/persistence/groups/:name/settings", h.GetClusterGroupSettings)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/groups/team-a/settings", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got clusterGroupSettingsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.True(t, got.IsFrozen())
+	assert.Equal(t, "env-prod", got.FrozenBy)
+	assert.Equal(t, []string{"env-prod", "region-emea"}, got.Ancestors)
+	assert.Equal(t, []v1alpha1.NotificationRoute{{Type: "slack", Target: "#emea-ops"}}, got.Settings.NotificationRoutes)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/groups/missing/settings", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// workloadCapturer abstracts CaptureWorkload so imports can be tested
+// without a live cluster.
+type workloadCapturer interface {
+	CaptureWorkload(ctx context.Context, contextName, kind, namespace, name string) (*unstructured.Unstructured, error)
+}
+
+// importWorkloadRequest names the live workload to turn into a
+// ManagedWorkload.
+type importWorkloadRequest struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	// WorkloadName names the ManagedWorkload; the workload's own name when
+	// empty.
+	WorkloadName   string   `json:"workloadName,omitempty"`
+	TargetClusters []string `json:"targetClusters,omitempty"`
+	TargetGroups   []string `json:"targetGroups,omitempty"`
+}
+
+// SetLimits caps how many ManagedWorkloads imports may create, overall and
+// per project. With a nil enforcer (the default) imports are not limited.
+func (h *ConsolePersistenceHandlers) SetLimits(enforcer *limits.Enforcer) {
+	h.limits = enforcer
+}
+
+// ImportManagedWorkload captures a live Deployment, StatefulSet or DaemonSet
+// and creates a ManagedWorkload that deploys the captured manifest, so an
+// existing workload can be rolled out to other clusters. Editor or admin
+// only.
+// POST /api/persistence/workloads/import-from-cluster
+func (h *ConsolePersistenceHandlers) ImportManagedWorkload(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.userStore); err != nil {
+		return err
+	}
+	var req importWorkloadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return localizedError(c, fiber.StatusBadRequest, "request.invalidBody")
+	}
+	if req.WorkloadName == "" {
+		req.WorkloadName = req.Name
+	}
+	if err := validateClusterName("cluster", req.Cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	for _, f := range []struct{ field, value string }{
+		{"namespace", req.Namespace}, {"name", req.Name}, {"workloadName", req.WorkloadName},
+	} {
+		if err := validateDNSSubdomain(f.field, f.value); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+	kind, err := k8s.NormalizeSnapshotKind(req.Kind)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+	capturer := h.capturer
+	if capturer == nil && h.k8sClient != nil {
+		capturer = h.k8sClient
+	}
+	if capturer == nil {
+		return localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
+	if err != nil {
+		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
+		return localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	}
+	persistence := k8s.NewConsolePersistence(client)
+	ctx := c.UserContext()
+
+	manifest, err := capturer.CaptureWorkload(ctx, req.Cluster, kind, req.Namespace, req.Name)
+	if err != nil {
+		if errors.Is(err, k8s.ErrWorkloadNotFound) {
+			return localizedError(c, fiber.StatusNotFound, "persistence.sourceWorkloadNotFound")
+		}
+		slog.Warn("[ConsolePersistence] failed to capture workload",
+			"cluster", req.Cluster, "kind", kind, "namespace", req.Namespace, "name", req.Name, "error", err)
+		return localizedError(c, fiber.StatusBadGateway, "persistence.captureFailed")
+	}
+
+	mw := &v1alpha1.ManagedWorkload{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "ManagedWorkload",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: req.WorkloadName, Namespace: namespace},
+		Spec: v1alpha1.ManagedWorkloadSpec{
+			SourceCluster:   req.Cluster,
+			SourceNamespace: req.Namespace,
+			WorkloadRef: v1alpha1.WorkloadReference{
+				APIVersion: manifest.GetAPIVersion(),
+				Kind:       kind,
+				Name:       req.Name,
+			},
+			TargetClusters: req.TargetClusters,
+			TargetGroups:   req.TargetGroups,
+			Manifest:       manifest.Object,
+		},
+	}
+	if errs := mw.Validate(); len(errs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": errs.ToAggregate().Error()})
+	}
+
+	if err := h.limits.CheckManagedWorkload(namespace, k8s.ManagedWorkloadCounter(ctx, persistence)); err != nil {
+		if limits.Code(err) == "" {
+			slog.Warn("[ConsolePersistence] failed to count managed workloads", "namespace", namespace, "error", err)
+			return localizedError(c, fiber.StatusInternalServerError, "server.internalError")
+		}
+		return limitError(c, err)
+	}
+
+	created, err := persistence.CreateManagedWorkload(ctx, mw)
+	if err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return localizedError(c, fiber.StatusConflict, "persistence.workloadExists")
+		}
+		slog.Warn("[ConsolePersistence] failed to create imported workload", "name", mw.Name, "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "server.internalError")
+	}
+	audit.Log(c, audit.ActionImportManagedWorkload, "managed_workload", namespace+"/"+mw.Name,
+		fmt.Sprintf("source=%s %s %s/%s", req.Cluster, kind, req.Namespace, req.Name))
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// capturedManifest returns the manifest an imported workload deploys, or
+// nil when it is read from its source cluster.
+func capturedManifest(workload *v1alpha1.ManagedWorkload) *unstructured.Unstructured {
+	if workload.Spec.Manifest == nil {
+		return nil
+	}
+	return &unstructured.Unstructured{Object: workload.Spec.Manifest}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+// fakeCapturer serves captured workloads keyed by cluster/kind/namespace/name.
+type fakeCapturer map[string]*unstructured.Unstructured
+
+func (f fakeCapturer) CaptureWorkload(_ context.Context, contextName, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	obj, ok := f[contextName+"/"+kind+"/"+namespace+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s/%s", k8s.ErrWorkloadNotFound, kind, namespace, name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func setupImportEnv(t *testing.T) *ConsolePersistenceHandlers {
+	t.Helper()
+	h, _ := setupReconcileEnv(t)
+	h.capturer = fakeCapturer{
+		"prod/Deployment/shop/web": {Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": "shop"},
+			"spec":       map[string]interface{}{"replicas": int64(2)},
+		}},
+	}
+	return h
+}
+
+// importRequest posts body to the import endpoint as a user with role.
+func importRequest(t *testing.T, h *ConsolePersistenceHandlers, role models.UserRole, body string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+	h.userStore = mockStore
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Post("/api/persistence/workloads/import-from-cluster", h.ImportManagedWorkload)
+	req := httptest.NewRequest(http.MethodPost, "/api/persistence/workloads/import-from-cluster", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	var out map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func TestImportManagedWorkload(t *testing.T) {
+	h := setupImportEnv(t)
+
+	resp, _ := importRequest(t, h, models.UserRoleEditor,
+		`{"cluster":"prod","namespace":"shop","kind":"deployments","name":"web","targetClusters":["edge-1","edge-2"]}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	client, _, err := h.persistenceStore.GetActiveClient(context.Background())
+	require.NoError(t, err)
+	mw, err := k8s.NewConsolePersistence(client).GetManagedWorkload(context.Background(), "test-ns", "web")
+	require.NoError(t, err)
+	require.NotNil(t, mw)
+	assert.Equal(t, "prod", mw.Spec.SourceCluster)
+	assert.Equal(t, "shop", mw.Spec.SourceNamespace)
+	assert.Equal(t, "Deployment", mw.Spec.WorkloadRef.Kind)
+	assert.Equal(t, "apps/v1", mw.Spec.WorkloadRef.APIVersion)
+	assert.Equal(t, []string{"edge-1", "edge-2"}, mw.Spec.TargetClusters)
+	manifest := capturedManifest(mw)
+	require.NotNil(t, manifest)
+	assert.Equal(t, "web", manifest.GetName())
+
+	resp, _ = importRequest(t, h, models.UserRoleEditor,
+		`{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"web"}`)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode, "a second import under the same name")
+
+	resp, out := importRequest(t, h, models.UserRoleAdmin,
+		`{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"web","workloadName":"web-copy"}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "web-copy", out["metadata"].(map[string]interface{})["name"])
+}
+
+func TestImportManagedWorkload_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		role models.UserRole
+		body string
+		want int
+	}{
+		{"viewer", models.UserRoleViewer, `{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"web"}`, fiber.StatusForbidden},
+		{"invalid body", models.UserRoleEditor, `{`, fiber.StatusBadRequest},
+		{"no cluster", models.UserRoleEditor, `{"namespace":"shop","kind":"Deployment","name":"web"}`, fiber.StatusBadRequest},
+		{"invalid name", models.UserRoleEditor, `{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"Web_1"}`, fiber.StatusBadRequest},
+		{"unsupported kind", models.UserRoleEditor, `{"cluster":"prod","namespace":"shop","kind":"CronJob","name":"web"}`, fiber.StatusBadRequest},
+		{"both targets", models.UserRoleEditor, `{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"web","targetClusters":["a"],"targetGroups":["g"]}`, fiber.StatusBadRequest},
+		{"not found", models.UserRoleEditor, `{"cluster":"prod","namespace":"shop","kind":"StatefulSet","name":"web"}`, fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := importRequest(t, setupImportEnv(t), tt.role, tt.body)
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
+
+func TestImportManagedWorkload_Limits(t *testing.T) {
+	h := setupImportEnv(t)
+	h.SetLimits(limits.NewEnforcer(limits.NewSettingsSource(&memLimitsStore{limits: settings.LimitsSettings{
+		Projects: map[string]settings.ProjectQuotaSettings{
+			"shop": {Namespaces: []string{"test-ns"}, MaxManagedWorkloads: 1},
+		},
+	}})))
+
+	resp, _ := importRequest(t, h, models.UserRoleEditor,
+		`{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"web"}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	resp, out := importRequest(t, h, models.UserRoleEditor,
+		`{"cluster":"prod","namespace":"shop","kind":"Deployment","name":"web","workloadName":"web-2"}`)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, limits.CodeLimitExceeded, out["code"])
+	assert.Equal(t, "shop", out["project"])
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// persistenceListPage is the response of a persistence list endpoint when
+// the caller asks for a page. Continue is passed back as ?continue= to get
+// the next page and is empty on the last one.
+type persistenceListPage[T any] struct {
+	Items    []T    `json:"items"`
+	Continue string `json:"continue,omitempty"`
+}
+
+// listQueryError is a bad list query param. key is its catalog message.
+type listQueryError struct {
+	key string
+	err error
+}
+
+// parsePersistenceListQuery parses the limit, continue, labelSelector and
+// fieldSelector query params of the persistence list endpoints. paged is
+// true when the caller passed limit or continue.
+func parsePersistenceListQuery(c *fiber.Ctx) (opts k8s.ResourceListOptions, paged bool, qerr *listQueryError) {
+	if raw := c.Query("limit"); raw != "" {
+		n, parseErr := strconv.ParseInt(raw, 10, 64)
+		if parseErr != nil || n <= 0 {
+			return opts, false, &listQueryError{key: "request.invalidLimit", err: errors.New("limit must be a positive integer")}
+		}
+		if n > maxClientPageLimit {
+			return opts, false, &listQueryError{key: "request.limitTooLarge", err: errors.New("limit must be at most " + strconv.Itoa(maxClientPageLimit))}
+		}
+		opts.Limit = n
+	}
+	opts.Continue = c.Query("continue")
+	if opts.LabelSelector = c.Query("labelSelector"); opts.LabelSelector != "" {
+		if _, parseErr := labels.Parse(opts.LabelSelector); parseErr != nil {
+			return opts, false, &listQueryError{key: "persistence.invalidSelector", err: parseErr}
+		}
+	}
+	if opts.FieldSelector = c.Query("fieldSelector"); opts.FieldSelector != "" {
+		if _, parseErr := fields.ParseSelector(opts.FieldSelector); parseErr != nil {
+			return opts, false, &listQueryError{key: "persistence.invalidSelector", err: parseErr}
+		}
+	}
+	return opts, opts.Limit > 0 || opts.Continue != "", nil
+}
+
+// listPersistence serves a persistence list endpoint. It lists the items
+// matching the request's list query with list and, if prepare is not nil,
+// passes them to prepare before responding. Unpaged requests get a plain
+// array, as before pagination existed; paged ones a persistenceListPage.
+func listPersistence[T any](
+	h *ConsolePersistenceHandlers,
+	c *fiber.Ctx,
+	list func(p k8s.ConsolePersistence, ctx context.Context, namespace string, opts k8s.ResourceListOptions) ([]T, string, error),
+	prepare func([]T),
+) error {
+	opts, paged, qerr := parsePersistenceListQuery(c)
+	if qerr != nil {
+		body := localizedErrorBody(c, qerr.key, nil)
+		body["details"] = qerr.err.Error()
+		return c.Status(400).JSON(body)
+	}
+
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
+	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
+	if err != nil {
+		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
+		return localizedError(c, 503, "server.unavailable")
+	}
+
+	persistence := k8s.NewConsolePersistence(client)
+
+	items, next, err := list(persistence, c.UserContext(), namespace, opts)
+	switch {
+	case apierrors.IsResourceExpired(err) || apierrors.IsGone(err):
+		return localizedError(c, 410, "persistence.continueExpired")
+	case apierrors.IsBadRequest(err):
+		// The apiserver rejects field selectors on fields the CRD does not
+		// declare selectable.
+		slog.Warn("[ConsolePersistence] list query rejected", "error", err)
+		return localizedError(c, 400, "persistence.invalidSelector")
+	case err != nil:
+		slog.Warn("[ConsolePersistence] internal error", "error", err)
+		return localizedError(c, 500, "server.internalError")
+	}
+
+	if prepare != nil {
+		prepare(items)
+	}
+	if !paged {
+		return c.JSON(items)
+	}
+	return c.JSON(persistenceListPage[T]{Items: items, Continue: next})
+}
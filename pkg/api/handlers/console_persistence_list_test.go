@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newPersistenceListApp serves the persistence list endpoints over the
+// given ClusterGroups.
+func newPersistenceListApp(t *testing.T, groups ...*v1alpha1.ClusterGroup) *fiber.App {
+	t.Helper()
+	objects := make([]runtime.Object, 0, len(groups))
+	for _, g := range groups {
+		g.TypeMeta = metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"}
+		g.Namespace = "test-ns"
+		u, err := g.ToUnstructured()
+		require.NoError(t, err)
+		objects = append(objects, u)
+	}
+	h, _ := setupReconcileEnv(t, objects...)
+	app := fiber.New()
+	app.Get("/api/persistence/groups", h.ListClusterGroups)
+	app.Get("/api/persistence/deployments", h.ListWorkloadDeployments)
+	return app
+}
+
+func TestListClusterGroups_Query(t *testing.T) {
+	app := newPersistenceListApp(t,
+		&v1alpha1.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"tier": "prod"}}},
+		&v1alpha1.ClusterGroup{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"tier": "dev"}}},
+	)
+
+	// Without limit or continue the response stays a plain array.
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/groups?labelSelector=tier%3Dprod", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var groups []v1alpha1.ClusterGroup
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, "prod", groups[0].Name)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/groups?limit=10", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var page persistenceListPage[v1alpha1.ClusterGroup]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	assert.Len(t, page.Items, 2)
+	assert.Empty(t, page.Continue)
+}
+
+func TestListPersistence_InvalidQuery(t *testing.T) {
+	app := newPersistenceListApp(t)
+	for _, query := range []string{
+		"limit=abc",
+		"limit=0",
+		"limit=100000",
+		"labelSelector=tier%3D%3D%3D",
+		"fieldSelector=metadata.name%3D%3D%3D",
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/deployments?"+query, nil), fiberTestTimeout)
+		require.NoError(t, err, query)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body), query)
+		assert.NotEmpty(t, body["code"], query)
+		assert.NotEmpty(t, body["details"], query)
+	}
+}
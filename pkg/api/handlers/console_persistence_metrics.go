@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// Abort reasons for kc_workload_deployment_aborts_total.
+const (
+	abortReasonHalted = "halted" // a failed deploy or health check halted the rollout
+	abortReasonManual = "manual" // a canary was aborted through the API
+)
+
+// deploymentMetrics exports the deployment engine's phase transitions,
+// durations and aborts. A nil *deploymentMetrics records nothing, so
+// handlers built without NewConsolePersistenceHandlers need no setup.
+type deploymentMetrics struct {
+	byPhase        *prometheus.GaugeVec
+	phaseDuration  *prometheus.HistogramVec
+	duration       *prometheus.HistogramVec
+	clusterRollout *prometheus.HistogramVec
+	aborts         *prometheus.CounterVec
+
+	// phases is the last phase seen per deployment, keyed by
+	// namespace/name. A zero since means the phase was already set when the
+	// deployment was first seen, so its duration is unknown.
+	mu     sync.Mutex
+	phases map[string]trackedPhase
+}
+
+type trackedPhase struct {
+	phase string
+	since time.Time
+}
+
+// newDeploymentMetrics registers the deployment metrics with reg, reusing
+// collectors that are already registered.
+func newDeploymentMetrics(reg prometheus.Registerer) *deploymentMetrics {
+	return &deploymentMetrics{
+		byPhase: middleware.RegisterOrExisting(reg, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kc_workload_deployments",
+				Help: "WorkloadDeployments by current phase",
+			},
+			[]string{"phase"},
+		)),
+		phaseDuration: middleware.RegisterOrExisting(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kc_workload_deployment_phase_duration_seconds",
+				Help:    "Time WorkloadDeployments spent in a phase before leaving it",
+				Buckets: prometheus.ExponentialBuckets(1, 4, 9), // 1s … 18h
+			},
+			[]string{"phase"},
+		)),
+		duration: middleware.RegisterOrExisting(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kc_workload_deployment_duration_seconds",
+				Help:    "Time from a WorkloadDeployment starting to reaching Complete or Failed",
+				Buckets: prometheus.ExponentialBuckets(1, 4, 9),
+			},
+			[]string{"phase"},
+		)),
+		clusterRollout: middleware.RegisterOrExisting(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kc_workload_deployment_cluster_rollout_duration_seconds",
+				Help:    "Time from deploying to a target cluster to its rollout completing or failing",
+				Buckets: prometheus.ExponentialBuckets(1, 2, 13), // 1s … 68m
+			},
+			[]string{"cluster", "phase"},
+		)),
+		aborts: middleware.RegisterOrExisting(reg, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kc_workload_deployment_aborts_total",
+				Help: "WorkloadDeployment rollouts stopped before reaching every target cluster",
+			},
+			[]string{"reason"},
+		)),
+		phases: make(map[string]trackedPhase),
+	}
+}
+
+func deploymentKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// observePhase records wd entering its current phase at now, and the time
+// it spent in the previous one.
+func (m *deploymentMetrics) observePhase(wd *v1alpha1.WorkloadDeployment, now time.Time) {
+	if m == nil || wd.Status.Phase == "" {
+		return
+	}
+	key := deploymentKey(wd.Namespace, wd.Name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev, ok := m.phases[key]
+	if ok && prev.phase == wd.Status.Phase {
+		return
+	}
+	if ok {
+		m.byPhase.WithLabelValues(prev.phase).Dec()
+		if !prev.since.IsZero() {
+			m.phaseDuration.WithLabelValues(prev.phase).Observe(now.Sub(prev.since).Seconds())
+		}
+	}
+	m.byPhase.WithLabelValues(wd.Status.Phase).Inc()
+	m.phases[key] = trackedPhase{phase: wd.Status.Phase, since: now}
+}
+
+// track counts a deployment the watcher reported in its stored phase, such
+// as one that already existed when the console started.
+func (m *deploymentMetrics) track(wd *v1alpha1.WorkloadDeployment) {
+	if m == nil || wd.Status.Phase == "" {
+		return
+	}
+	key := deploymentKey(wd.Namespace, wd.Name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.phases[key]; ok {
+		return
+	}
+	m.byPhase.WithLabelValues(wd.Status.Phase).Inc()
+	m.phases[key] = trackedPhase{phase: wd.Status.Phase}
+}
+
+// forget stops counting a deleted deployment.
+func (m *deploymentMetrics) forget(namespace, name string) {
+	if m == nil {
+		return
+	}
+	key := deploymentKey(namespace, name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if prev, ok := m.phases[key]; ok {
+		m.byPhase.WithLabelValues(prev.phase).Dec()
+		delete(m.phases, key)
+	}
+}
+
+// observeCompletion records how long wd took to reach its terminal phase.
+func (m *deploymentMetrics) observeCompletion(wd *v1alpha1.WorkloadDeployment) {
+	if m == nil || wd.Status.StartedAt == nil || wd.Status.CompletedAt == nil {
+		return
+	}
+	m.duration.WithLabelValues(wd.Status.Phase).
+		Observe(wd.Status.CompletedAt.Sub(wd.Status.StartedAt.Time).Seconds())
+}
+
+// observeClusterRollout records how long a cluster took to reach Complete
+// or Failed after deploying to it started. Clusters that failed before
+// deploying, for example on a policy or backup, have no start time and are
+// left out.
+func (m *deploymentMetrics) observeClusterRollout(cs *v1alpha1.ClusterRolloutStatus) {
+	if m == nil || cs.StartedAt == nil || cs.CompletedAt == nil {
+		return
+	}
+	if cs.Phase != "Complete" && cs.Phase != "Failed" {
+		return
+	}
+	m.clusterRollout.WithLabelValues(cs.Cluster, cs.Phase).
+		Observe(cs.CompletedAt.Sub(cs.StartedAt.Time).Seconds())
+}
+
+// observeAbort counts a rollout stopped for reason.
+func (m *deploymentMetrics) observeAbort(reason string) {
+	if m == nil {
+		return
+	}
+	m.aborts.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+func metricsDeployment(name, phase string) *v1alpha1.WorkloadDeployment {
+	wd := &v1alpha1.WorkloadDeployment{}
+	wd.Namespace = "default"
+	wd.Name = name
+	wd.Status.Phase = phase
+	return wd
+}
+
+func TestDeploymentMetrics_Phases(t *testing.T) {
+	m := newDeploymentMetrics(prometheus.NewRegistry())
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	wd := metricsDeployment("web", "InProgress")
+	m.observePhase(wd, start)
+	m.observePhase(wd, start.Add(time.Second)) // unchanged phase is not a transition
+	wd.Status.Phase = "Complete"
+	m.observePhase(wd, start.Add(30*time.Second))
+
+	// Already Failed when first seen: counted, but its time in phase is unknown.
+	old := metricsDeployment("old", "Failed")
+	m.track(old)
+	old.Status.Phase = "InProgress"
+	m.observePhase(old, start)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.byPhase.WithLabelValues("Complete")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.byPhase.WithLabelValues("InProgress")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.byPhase.WithLabelValues("Failed")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.phaseDuration), "only InProgress has an observed duration")
+
+	m.forget("default", "web")
+	m.forget("default", "missing")
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.byPhase.WithLabelValues("Complete")))
+}
+
+func TestDeploymentMetrics_Durations(t *testing.T) {
+	m := newDeploymentMetrics(prometheus.NewRegistry())
+	start := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(2 * time.Minute))
+
+	wd := metricsDeployment("web", "Failed")
+	m.observeCompletion(wd) // never started
+	wd.Status.StartedAt, wd.Status.CompletedAt = &start, &end
+	m.observeCompletion(wd)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.duration))
+
+	for _, cs := range []v1alpha1.ClusterRolloutStatus{
+		{Cluster: "east", Phase: "Complete", StartedAt: &start, CompletedAt: &end},
+		{Cluster: "west", Phase: "Failed", StartedAt: &start, CompletedAt: &end},
+		{Cluster: "north", Phase: "Failed", CompletedAt: &end},                        // failed before deploying
+		{Cluster: "south", Phase: phaseSkipped, StartedAt: &start, CompletedAt: &end}, // never reached
+	} {
+		m.observeClusterRollout(&cs)
+	}
+	assert.Equal(t, 2, testutil.CollectAndCount(m.clusterRollout))
+
+	m.observeAbort(abortReasonHalted)
+	m.observeAbort(abortReasonManual)
+	m.observeAbort(abortReasonManual)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.aborts.WithLabelValues(abortReasonManual)))
+}
+
+func TestDeploymentMetrics_Nil(t *testing.T) {
+	var m *deploymentMetrics
+	wd := metricsDeployment("web", "InProgress")
+	assert.NotPanics(t, func() {
+		m.observePhase(wd, time.Now())
+		m.track(wd)
+		m.forget("default", "web")
+		m.observeCompletion(wd)
+		m.observeClusterRollout(&v1alpha1.ClusterRolloutStatus{})
+		m.observeAbort(abortReasonHalted)
+	})
+}
+
+func TestDeploymentMetrics_SharedRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	assert.Same(t, newDeploymentMetrics(reg).byPhase, newDeploymentMetrics(reg).byPhase)
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/middleware"
+)
+
+const (
+	// namespaceAudienceTTL is how long the users who may see a restricted
+	// persistence namespace are cached for broadcasts. Team changes reach
+	// live events this late; requests always check current membership.
+	namespaceAudienceTTL = 30 * time.Second
+	// namespaceAudienceTimeout bounds resolving one namespace's audience.
+	namespaceAudienceTimeout = 10 * time.Second
+	// namespaceAudiencePageSize is how many users or teams one store read
+	// returns while resolving an audience.
+	namespaceAudiencePageSize = 500
+)
+
+// namespaceAudience is the cached set of users who may see a restricted
+// persistence namespace.
+type namespaceAudience struct {
+	users   []uuid.UUID
+	expires time.Time
+}
+
+// namespaceAudiences caches namespaceAudience by namespace.
+type namespaceAudiences struct {
+	mu      sync.Mutex
+	entries map[string]namespaceAudience
+}
+
+// requestNamespace returns the persistence namespace the request selects
+// with ?namespace=, the default one when it has none, after checking the
+// user may use it. On failure the error response has been written and the
+// returned namespace is empty.
+func (h *ConsolePersistenceHandlers) requestNamespace(c *fiber.Ctx) (string, error) {
+	namespace := c.Query("namespace")
+	if namespace == "" {
+		return h.persistenceStore.GetNamespace(), nil
+	}
+	if _, _, ok := h.persistenceStore.LookupNamespace(namespace); !ok {
+		return "", localizedError(c, fiber.StatusNotFound, "persistence.namespaceNotFound")
+	}
+	allowed, err := h.canUseNamespace(c.UserContext(), middleware.GetUserID(c), namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] failed to check namespace access",
+			"namespace", namespace, "error", err)
+		return "", localizedError(c, fiber.StatusInternalServerError, "server.internalError")
+	}
+	if !allowed {
+		return "", localizedError(c, fiber.StatusForbidden, "persistence.namespaceForbidden")
+	}
+	return namespace, nil
+}
+
+// canUseNamespace reports whether userID may see and use the console CRs
+// in namespace: every user may use the default namespace, admins may use
+// any, and other users the ones mapped to a team they belong to.
+func (h *ConsolePersistenceHandlers) canUseNamespace(ctx context.Context, userID uuid.UUID, namespace string) (bool, error) {
+	ns, restricted, ok := h.persistenceStore.LookupNamespace(namespace)
+	if !ok {
+		return false, nil
+	}
+	if !restricted || h.userStore == nil {
+		return true, nil
+	}
+	user, err := h.userStore.GetUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, nil
+	}
+	if user.Role == "admin" {
+		return true, nil
+	}
+	teams, err := h.userStore.GetUserTeams(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, team := range teams {
+		if slices.Contains(ns.Teams, team.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// broadcastToNamespace sends msg, about a console CR in namespace, to the
+// users who may see that namespace. Events from the default namespace go
+// to everyone, and those from a namespace no longer configured to no one.
+func (h *ConsolePersistenceHandlers) broadcastToNamespace(namespace string, msg Message) {
+	if h.hub == nil {
+		return
+	}
+	if h.persistenceStore == nil {
+		h.hub.BroadcastAll(msg)
+		return
+	}
+	if namespace == "" {
+		namespace = h.persistenceStore.GetNamespace()
+	}
+	_, restricted, ok := h.persistenceStore.LookupNamespace(namespace)
+	if !ok {
+		return
+	}
+	if !restricted || h.userStore == nil {
+		h.hub.BroadcastAll(msg)
+		return
+	}
+	users, err := h.namespaceAudience(namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot resolve namespace audience, event not sent",
+			"namespace", namespace, "type", msg.Type, "error", err)
+		return
+	}
+	for _, userID := range users {
+		h.hub.Broadcast(userID, msg)
+	}
+}
+
+// namespaceAudience returns the admins and the members of the teams mapped
+// to the restricted namespace, cached for namespaceAudienceTTL.
+func (h *ConsolePersistenceHandlers) namespaceAudience(namespace string) ([]uuid.UUID, error) {
+	now := time.Now()
+	h.audiences.mu.Lock()
+	if entry, ok := h.audiences.entries[namespace]; ok && now.Before(entry.expires) {
+		h.audiences.mu.Unlock()
+		return entry.users, nil
+	}
+	h.audiences.mu.Unlock()
+
+	ns, _, _ := h.persistenceStore.LookupNamespace(namespace)
+	ctx, cancel := context.WithTimeout(context.Background(), namespaceAudienceTimeout)
+	defer cancel()
+
+	seen := make(map[uuid.UUID]bool)
+	var users []uuid.UUID
+	add := func(id uuid.UUID) {
+		if !seen[id] {
+			seen[id] = true
+			users = append(users, id)
+		}
+	}
+	for offset := 0; ; offset += namespaceAudiencePageSize {
+		page, err := h.userStore.ListUsers(ctx, namespaceAudiencePageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page {
+			if u.Role == "admin" {
+				add(u.ID)
+			}
+		}
+		if len(page) < namespaceAudiencePageSize {
+			break
+		}
+	}
+	for offset := 0; len(ns.Teams) > 0; offset += namespaceAudiencePageSize {
+		teams, err := h.userStore.ListTeams(ctx, nil, namespaceAudiencePageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			if !slices.Contains(ns.Teams, team.Name) {
+				continue
+			}
+			members, err := h.userStore.ListTeamMembers(ctx, team.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range members {
+				add(m.UserID)
+			}
+		}
+		if len(teams) < namespaceAudiencePageSize {
+			break
+		}
+	}
+
+	h.audiences.mu.Lock()
+	if h.audiences.entries == nil {
+		h.audiences.entries = make(map[string]namespaceAudience)
+	}
+	h.audiences.entries[namespace] = namespaceAudience{users: users, expires: now.Add(namespaceAudienceTTL)}
+	h.audiences.mu.Unlock()
+	return users, nil
+}
+
+// namespaceProject returns the console project whose change policy applies
+// to namespace.
+func (h *ConsolePersistenceHandlers) namespaceProject(namespace string) string {
+	if h.persistenceStore == nil {
+		return h.project
+	}
+	if ns, _, ok := h.persistenceStore.LookupNamespace(namespace); ok && ns.Project != "" {
+		return ns.Project
+	}
+	return h.project
+}
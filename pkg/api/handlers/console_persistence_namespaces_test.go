@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupNamespaceHandlers returns a handler whose persistence config maps
+// team-a to the payments team and project, and team-b to no team.
+func setupNamespaceHandlers(t *testing.T) (*ConsolePersistenceHandlers, *test.MockStore) {
+	t.Helper()
+	ps := store.NewPersistenceStore("")
+	require.NoError(t, ps.UpdateConfig(store.PersistenceConfig{
+		Namespace: "console",
+		Namespaces: []store.PersistenceNamespace{
+			{Namespace: "team-a", Project: "payments", Teams: []string{"payments"}},
+			{Namespace: "team-b"},
+		},
+	}))
+	mockStore := new(test.MockStore)
+	return &ConsolePersistenceHandlers{persistenceStore: ps, userStore: mockStore, project: "kubestellar"}, mockStore
+}
+
+func TestCanUseNamespace(t *testing.T) {
+	h, mockStore := setupNamespaceHandlers(t)
+	adminID, memberID, otherID := uuid.New(), uuid.New(), uuid.New()
+	mockStore.On("GetUser", adminID).Return(&models.User{ID: adminID, Role: models.UserRoleAdmin}, nil)
+	mockStore.On("GetUser", memberID).Return(&models.User{ID: memberID, Role: models.UserRoleViewer}, nil)
+	mockStore.On("GetUser", otherID).Return(&models.User{ID: otherID, Role: models.UserRoleEditor}, nil)
+	mockStore.On("GetUserTeams", mock.Anything, memberID).Return([]models.Team{{Name: "payments"}}, nil)
+	mockStore.On("GetUserTeams", mock.Anything, otherID).Return([]models.Team{{Name: "search"}}, nil)
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		user      uuid.UUID
+		namespace string
+		want      bool
+	}{
+		{otherID, "console", true},
+		{adminID, "team-a", true},
+		{adminID, "team-b", true},
+		{memberID, "team-a", true},
+		{memberID, "team-b", false},
+		{otherID, "team-a", false},
+		{adminID, "kube-system", false},
+	} {
+		got, err := h.canUseNamespace(ctx, tt.user, tt.namespace)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "user %s, namespace %s", tt.user, tt.namespace)
+	}
+}
+
+func TestRequestNamespace(t *testing.T) {
+	h, mockStore := setupNamespaceHandlers(t)
+	viewerID := uuid.New()
+	mockStore.On("GetUser", viewerID).Return(&models.User{ID: viewerID, Role: models.UserRoleViewer}, nil)
+	mockStore.On("GetUserTeams", mock.Anything, viewerID).Return([]models.Team{{Name: "payments"}}, nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", viewerID)
+		return c.Next()
+	})
+	app.Get("/ns", func(c *fiber.Ctx) error {
+		namespace, err := h.requestNamespace(c)
+		if namespace == "" {
+			return err
+		}
+		return c.SendString(namespace)
+	})
+
+	for _, tt := range []struct {
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"", http.StatusOK, "console"},
+		{"?namespace=team-a", http.StatusOK, "team-a"},
+		{"?namespace=team-b", http.StatusForbidden, ""},
+		{"?namespace=kube-system", http.StatusNotFound, ""},
+	} {
+		status, body := doFlagRequest(t, app, http.MethodGet, "/ns"+tt.query, "")
+		assert.Equal(t, tt.wantStatus, status, tt.query)
+		if tt.wantBody != "" {
+			assert.Equal(t, tt.wantBody, string(body), tt.query)
+		}
+	}
+}
+
+func TestNamespaceAudience(t *testing.T) {
+	h, mockStore := setupNamespaceHandlers(t)
+	teamID, memberID := uuid.New(), uuid.New()
+	mockStore.On("ListTeams", mock.Anything, (*uuid.UUID)(nil), namespaceAudiencePageSize, 0).
+		Return([]models.Team{{ID: uuid.New(), Name: "search"}, {ID: teamID, Name: "payments"}}, nil).Once()
+	mockStore.On("ListTeamMembers", mock.Anything, teamID).
+		Return([]models.TeamMemberInfo{{UserID: memberID}}, nil).Once()
+
+	users, err := h.namespaceAudience("team-a")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{memberID}, users)
+
+	// Cached: the store is not read again.
+	users, err = h.namespaceAudience("team-a")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{memberID}, users)
+	mockStore.AssertExpectations(t)
+
+	users, err = h.namespaceAudience("team-b")
+	require.NoError(t, err)
+	assert.Empty(t, users, "a namespace without teams is for admins only")
+}
+
+func TestNamespaceProject(t *testing.T) {
+	h, _ := setupNamespaceHandlers(t)
+	assert.Equal(t, "payments", h.namespaceProject("team-a"))
+	assert.Equal(t, "kubestellar", h.namespaceProject("team-b"))
+	assert.Equal(t, "kubestellar", h.namespaceProject("console"))
+}
@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clusterNodeReader is the subset of k8s.MultiClusterClient placement
+// constraints use to read a cluster's regions and zones from its nodes.
+type clusterNodeReader interface {
+	GetNodes(ctx context.Context, contextName string) ([]k8s.NodeInfo, error)
+}
+
+// Condition recorded on WorkloadDeployment.Status by the placement stage.
+const (
+	conditionPlacement = "PlacementConstraints"
+
+	reasonPlacementSatisfied = "ConstraintsSatisfied"
+	reasonPlacementPreferred = "PreferencesViolated"
+	reasonPlacementRequired  = "RequiredConstraintsViolated"
+)
+
+// placementViolation is a target cluster that breaks a placement constraint.
+type placementViolation struct {
+	Cluster  string `json:"cluster"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Message  string `json:"message"`
+}
+
+// placementResult is how a workload's placement constraints judge a
+// deployment's targets. Allowed are the targets no required constraint
+// rules out, in order; Recommended are those that also break no
+// preference, or Allowed when every one of them does.
+type placementResult struct {
+	Allowed     []string             `json:"allowed"`
+	Recommended []string             `json:"recommended"`
+	Violations  []placementViolation `json:"violations"`
+
+	// constrained is set when any constraint applied to the workload.
+	constrained bool
+}
+
+// placementTerm is a constraint with the workload that declared it, which
+// differs from the placed workload for an anti-affinity declared the other
+// way round.
+type placementTerm struct {
+	v1alpha1.PlacementConstraint
+	declaredBy string
+}
+
+// evaluatePlacement checks targets against workload's placement constraints
+// and the AntiAffinity constraints other workloads declare against it, which
+// apply both ways. Affinity and AntiAffinity look at the clusters the other
+// workloads' deployments are placed on. Spread is evaluated after them, over
+// the clusters they allow.
+func (h *ConsolePersistenceHandlers) evaluatePlacement(ctx context.Context, wd *v1alpha1.WorkloadDeployment, workload *v1alpha1.ManagedWorkload, targets []string) (placementResult, error) {
+	res := placementResult{Allowed: targets, Recommended: targets, Violations: []placementViolation{}}
+	terms, err := h.placementTerms(ctx, workload)
+	if err != nil {
+		return res, err
+	}
+	if len(terms) == 0 {
+		return res, nil
+	}
+	res.constrained = true
+
+	var placed map[string][]string
+	if slices.ContainsFunc(terms, func(t placementTerm) bool { return t.Type != v1alpha1.PlacementSpread }) {
+		if placed, err = h.workloadPlacements(ctx, wd); err != nil {
+			return res, err
+		}
+	}
+	var nodes clusterNodeReader = h.topology
+	if nodes == nil && h.k8sClient != nil {
+		nodes = h.k8sClient
+	}
+	topo := &clusterTopology{nodes: nodes, cache: map[string]map[string][]string{}}
+
+	excluded := map[string]bool{}
+	unpreferred := map[string]bool{}
+	record := func(t placementTerm, cluster, message string) {
+		if t.declaredBy != workload.Name {
+			message += " (constraint of workload " + t.declaredBy + ")"
+		}
+		res.Violations = append(res.Violations, placementViolation{
+			Cluster: cluster, Type: t.Type, Required: t.Required, Message: message,
+		})
+		if t.Required {
+			excluded[cluster] = true
+		} else {
+			unpreferred[cluster] = true
+		}
+	}
+
+	for _, t := range terms {
+		if t.Type == v1alpha1.PlacementSpread {
+			continue
+		}
+		for _, cluster := range targets {
+			if msg := topo.affinityViolation(ctx, t, cluster, placed); msg != "" {
+				record(t, cluster, msg)
+			}
+		}
+	}
+	allowed := withoutClusters(targets, excluded)
+	for _, t := range terms {
+		if t.Type != v1alpha1.PlacementSpread {
+			continue
+		}
+		key := t.EffectiveTopologyKey()
+		first := map[string]string{}
+		for _, cluster := range allowed {
+			values := topo.values(ctx, cluster, key)
+			if len(values) == 0 {
+				continue
+			}
+			var taken []string
+			for _, v := range values {
+				if _, ok := first[v]; ok {
+					taken = append(taken, v)
+				}
+			}
+			if len(taken) == len(values) {
+				record(t, cluster, fmt.Sprintf("%s %s already has cluster %s", key, taken[0], first[taken[0]]))
+				continue
+			}
+			for _, v := range values {
+				if _, ok := first[v]; !ok {
+					first[v] = cluster
+				}
+			}
+		}
+		allowed = withoutClusters(allowed, excluded)
+	}
+
+	res.Allowed = allowed
+	res.Recommended = withoutClusters(allowed, unpreferred)
+	if len(res.Recommended) == 0 {
+		res.Recommended = allowed
+	}
+	return res, nil
+}
+
+// placementTerms returns workload's own constraints followed by the
+// AntiAffinity constraints other workloads in its namespace declare against
+// it. When the other workloads cannot be listed only the workload's own
+// constraints apply.
+func (h *ConsolePersistenceHandlers) placementTerms(ctx context.Context, workload *v1alpha1.ManagedWorkload) ([]placementTerm, error) {
+	terms := make([]placementTerm, 0, len(workload.Spec.Placement))
+	for _, p := range workload.Spec.Placement {
+		terms = append(terms, placementTerm{PlacementConstraint: p, declaredBy: workload.Name})
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get persistence client: %w", err)
+	}
+	others, err := k8s.NewConsolePersistence(client).ListManagedWorkloads(ctx, workload.Namespace)
+	if err != nil {
+		slog.Warn("[reconcile] cannot list workloads for their placement constraints",
+			"workload", workload.Name, "error", err)
+		return terms, nil
+	}
+	for _, other := range others {
+		if other.Name == workload.Name {
+			continue
+		}
+		for _, p := range other.Spec.Placement {
+			if p.Type != v1alpha1.PlacementAntiAffinity || !slices.Contains(p.Workloads, workload.Name) {
+				continue
+			}
+			reversed := p
+			reversed.Workloads = []string{other.Name}
+			terms = append(terms, placementTerm{PlacementConstraint: reversed, declaredBy: other.Name})
+		}
+	}
+	return terms, nil
+}
+
+// workloadPlacements maps each ManagedWorkload in wd's namespace to the
+// clusters its deployments, other than wd, are placed or being placed on.
+// Clusters a deployment failed, skipped or did not process are left out.
+func (h *ConsolePersistenceHandlers) workloadPlacements(ctx context.Context, wd *v1alpha1.WorkloadDeployment) (map[string][]string, error) {
+	client, _, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get persistence client: %w", err)
+	}
+	deployments, err := k8s.NewConsolePersistence(client).ListWorkloadDeployments(ctx, wd.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	placed := map[string][]string{}
+	for _, other := range deployments {
+		ref := other.Spec.WorkloadRef
+		if other.Name == wd.Name || (ref.Namespace != "" && ref.Namespace != wd.Namespace) {
+			continue
+		}
+		for _, cs := range other.Status.ClusterStatuses {
+			switch cs.Phase {
+			case "Failed", phaseSkipped, "NotProcessed":
+				continue
+			}
+			if !slices.Contains(placed[ref.Name], cs.Cluster) {
+				placed[ref.Name] = append(placed[ref.Name], cs.Cluster)
+			}
+		}
+	}
+	return placed, nil
+}
+
+// clusterTopology reads and caches the regions and zones of clusters.
+type clusterTopology struct {
+	nodes clusterNodeReader
+	cache map[string]map[string][]string
+}
+
+// values returns where cluster is under key: its name, or the regions or
+// zones of its nodes. A cluster whose nodes cannot be read has no regions or
+// zones, so it shares them with no other cluster.
+func (t *clusterTopology) values(ctx context.Context, cluster, key string) []string {
+	if key == v1alpha1.PlacementTopologyCluster {
+		return []string{cluster}
+	}
+	byKey, ok := t.cache[cluster]
+	if !ok {
+		byKey = map[string][]string{}
+		t.cache[cluster] = byKey
+		if t.nodes != nil {
+			nodes, err := t.nodes.GetNodes(ctx, cluster)
+			if err != nil {
+				slog.Warn("[reconcile] cannot read cluster topology for placement constraints",
+					"cluster", cluster, "error", err)
+			} else {
+				byKey[v1alpha1.PlacementTopologyRegion] = k8s.ClusterRegions(nodes)
+				byKey[v1alpha1.PlacementTopologyZone] = k8s.ClusterZones(nodes)
+			}
+		}
+	}
+	return byKey[key]
+}
+
+// affinityViolation describes how cluster breaks an Affinity or
+// AntiAffinity term given where the other workloads are placed, or returns
+// "" when it does not.
+func (t *clusterTopology) affinityViolation(ctx context.Context, term placementTerm, cluster string, placed map[string][]string) string {
+	key := term.EffectiveTopologyKey()
+	here := t.values(ctx, cluster, key)
+	for _, w := range term.Workloads {
+		var shared, on string
+		for _, p := range placed[w] {
+			if v := firstShared(here, t.values(ctx, p, key)); v != "" {
+				shared, on = v, p
+				break
+			}
+		}
+		switch {
+		case term.Type == v1alpha1.PlacementAntiAffinity && shared != "":
+			if key == v1alpha1.PlacementTopologyCluster {
+				return "workload " + w + " runs on this cluster"
+			}
+			return fmt.Sprintf("workload %s runs in %s %s on cluster %s", w, key, shared, on)
+		case term.Type == v1alpha1.PlacementAffinity && len(placed[w]) == 0:
+			return "workload " + w + " is not placed on any cluster"
+		case term.Type == v1alpha1.PlacementAffinity && shared == "":
+			if key == v1alpha1.PlacementTopologyCluster {
+				return "workload " + w + " does not run on this cluster"
+			}
+			return fmt.Sprintf("workload %s does not run in the same %s", w, key)
+		}
+	}
+	return ""
+}
+
+func firstShared(a, b []string) string {
+	for _, v := range a {
+		if slices.Contains(b, v) {
+			return v
+		}
+	}
+	return ""
+}
+
+func withoutClusters(clusters []string, drop map[string]bool) []string {
+	out := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		if !drop[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// setPlacementCondition records res on wd, or removes the condition when no
+// placement constraint applies.
+func setPlacementCondition(wd *v1alpha1.WorkloadDeployment, res placementResult) {
+	if !res.constrained {
+		meta.RemoveStatusCondition(&wd.Status.Conditions, conditionPlacement)
+		return
+	}
+	status, reason := metav1.ConditionTrue, reasonPlacementSatisfied
+	message := fmt.Sprintf("All %d target clusters satisfy the placement constraints", len(res.Allowed))
+	if len(res.Violations) > 0 {
+		status, reason = metav1.ConditionFalse, reasonPlacementPreferred
+		lines := make([]string, 0, len(res.Violations))
+		for _, v := range res.Violations {
+			if v.Required {
+				reason = reasonPlacementRequired
+			}
+			lines = append(lines, v.Cluster+": "+v.Message)
+		}
+		message = summarizeViolations(lines)
+	}
+	meta.SetStatusCondition(&wd.Status.Conditions, metav1.Condition{
+		Type:               conditionPlacement,
+		Status:             status,
+		ObservedGeneration: wd.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// placementRecommendation is the GetDeploymentPlacement response.
+type placementRecommendation struct {
+	Deployment string   `json:"deployment"`
+	Workload   string   `json:"workload"`
+	Targets    []string `json:"targets"`
+	placementResult
+}
+
+// GetDeploymentPlacement resolves a deployment's target clusters and
+// evaluates its workload's placement constraints against them without
+// deploying: the clusters a reconcile would deploy to, the ones that also
+// satisfy every preference, and each violation.
+// GET /api/persistence/deployments/:name/placement
+func (h *ConsolePersistenceHandlers) GetDeploymentPlacement(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if err := validateDNSSubdomain("name", name); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
+	if err != nil {
+		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
+		return localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	}
+	ctx := c.UserContext()
+	wd, err := k8s.NewConsolePersistence(client).GetWorkloadDeployment(ctx, namespace, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return localizedError(c, fiber.StatusNotFound, "persistence.deploymentNotFound")
+		}
+		slog.Warn("[ConsolePersistence] internal error", "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "server.internalError")
+	}
+	workload, err := h.resolveManagedWorkload(ctx, wd)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot resolve workload for placement", "name", name, "error", err)
+		return localizedError(c, fiber.StatusNotFound, "persistence.workloadNotFound")
+	}
+	targets, err := h.resolveTargetClusters(ctx, wd)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot resolve targets for placement", "name", name, "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "persistence.placementFailed")
+	}
+	placement, err := h.evaluatePlacement(ctx, wd, workload, targets)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot evaluate placement", "name", name, "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "persistence.placementFailed")
+	}
+	if targets == nil {
+		targets = []string{}
+	}
+	return c.JSON(placementRecommendation{
+		Deployment:      wd.Name,
+		Workload:        workload.Name,
+		Targets:         targets,
+		placementResult: placement,
+	})
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeTopology implements clusterNodeReader with one node per cluster in
+// the mapped region; clusters without an entry cannot be read.
+type fakeTopology map[string]string
+
+func (f fakeTopology) GetNodes(_ context.Context, cluster string) ([]k8s.NodeInfo, error) {
+	region, ok := f[cluster]
+	if !ok {
+		return nil, errors.New("cluster unreachable")
+	}
+	return []k8s.NodeInfo{{Name: cluster + "-node", Labels: map[string]string{"topology.kubernetes.io/region": region}}}, nil
+}
+
+// setupPlacementEnv persists my-app with placement, deployed by wd-app to
+// cluster-a..d; db, placed on cluster-a; and cache, placed on cluster-c,
+// which prefers not to share a cluster with my-app. cluster-a and cluster-b
+// are in us-east, cluster-c in eu-west and cluster-d's region is unknown.
+func setupPlacementEnv(t *testing.T, placement []v1alpha1.PlacementConstraint, status v1alpha1.WorkloadDeploymentStatus) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	// The other workloads the placement constraints refer to.
+	workload := func(name string, placement []v1alpha1.PlacementConstraint) *v1alpha1.ManagedWorkload {
+		return &v1alpha1.ManagedWorkload{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ManagedWorkload"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: v1alpha1.ManagedWorkloadSpec{
+				SourceCluster:   "source-cluster",
+				SourceNamespace: "default",
+				WorkloadRef:     v1alpha1.WorkloadReference{Kind: "Deployment", Name: name},
+				Placement:       placement,
+			},
+		}
+	}
+	deployment := func(name, workload string, targets []string, status v1alpha1.WorkloadDeploymentStatus) *v1alpha1.WorkloadDeployment {
+		return &v1alpha1.WorkloadDeployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "WorkloadDeployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadDeploymentSpec{
+				WorkloadRef:    v1alpha1.ResourceReference{Name: workload},
+				TargetClusters: targets,
+			},
+			Status: status,
+		}
+	}
+	placed := func(clusters ...string) v1alpha1.WorkloadDeploymentStatus {
+		st := v1alpha1.WorkloadDeploymentStatus{Phase: "Complete"}
+		for _, c := range clusters {
+			st.ClusterStatuses = append(st.ClusterStatuses, v1alpha1.ClusterRolloutStatus{Cluster: c, Phase: "Complete"})
+		}
+		return st
+	}
+	dbStatus := placed("cluster-a")
+	dbStatus.ClusterStatuses = append(dbStatus.ClusterStatuses, v1alpha1.ClusterRolloutStatus{Cluster: "cluster-d", Phase: "Failed"})
+
+	h, wd := newReconcileFixture(t,
+		withTargets("cluster-a", "cluster-b", "cluster-c", "cluster-d"),
+		withWorkload(func(mw *v1alpha1.ManagedWorkload) { mw.Spec.Placement = placement }),
+		withDeployment(func(wd *v1alpha1.WorkloadDeployment) { wd.Status = status }),
+		withObjects(
+			workload("db", nil),
+			workload("cache", []v1alpha1.PlacementConstraint{{Type: v1alpha1.PlacementAntiAffinity, Workloads: []string{"my-app"}}}),
+			deployment("wd-db", "db", []string{"cluster-a", "cluster-d"}, dbStatus),
+			deployment("wd-cache", "cache", []string{"cluster-c"}, placed("cluster-c")),
+		),
+	)
+	h.topology = fakeTopology{"cluster-a": "us-east", "cluster-b": "us-east", "cluster-c": "eu-west"}
+	return h, wd
+}
+
+func TestEvaluatePlacement(t *testing.T) {
+	cacheViolation := placementViolation{Cluster: "cluster-c", Type: v1alpha1.PlacementAntiAffinity,
+		Message: "workload cache runs on this cluster (constraint of workload cache)"}
+	tests := []struct {
+		name        string
+		placement   []v1alpha1.PlacementConstraint
+		allowed     []string
+		recommended []string
+		violations  []placementViolation
+	}{
+		{
+			name:        "anti-affinity declared by another workload",
+			allowed:     []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d"},
+			recommended: []string{"cluster-a", "cluster-b", "cluster-d"},
+			violations:  []placementViolation{cacheViolation},
+		},
+		{
+			name: "required anti-affinity by region",
+			placement: []v1alpha1.PlacementConstraint{{Type: v1alpha1.PlacementAntiAffinity, Workloads: []string{"db"},
+				TopologyKey: v1alpha1.PlacementTopologyRegion, Required: true}},
+			allowed:     []string{"cluster-c", "cluster-d"},
+			recommended: []string{"cluster-d"},
+			violations: []placementViolation{
+				{Cluster: "cluster-a", Type: v1alpha1.PlacementAntiAffinity, Required: true, Message: "workload db runs in region us-east on cluster cluster-a"},
+				{Cluster: "cluster-b", Type: v1alpha1.PlacementAntiAffinity, Required: true, Message: "workload db runs in region us-east on cluster cluster-a"},
+				cacheViolation,
+			},
+		},
+		{
+			name:        "required spread across regions",
+			placement:   []v1alpha1.PlacementConstraint{{Type: v1alpha1.PlacementSpread, TopologyKey: v1alpha1.PlacementTopologyRegion, Required: true}},
+			allowed:     []string{"cluster-a", "cluster-c", "cluster-d"},
+			recommended: []string{"cluster-a", "cluster-d"},
+			violations: []placementViolation{
+				cacheViolation,
+				{Cluster: "cluster-b", Type: v1alpha1.PlacementSpread, Required: true, Message: "region us-east already has cluster cluster-a"},
+			},
+		},
+		{
+			name:        "preferred affinity by region",
+			placement:   []v1alpha1.PlacementConstraint{{Type: v1alpha1.PlacementAffinity, Workloads: []string{"db"}, TopologyKey: v1alpha1.PlacementTopologyRegion}},
+			allowed:     []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d"},
+			recommended: []string{"cluster-a", "cluster-b"},
+			violations: []placementViolation{
+				{Cluster: "cluster-c", Type: v1alpha1.PlacementAffinity, Message: "workload db does not run in the same region"},
+				{Cluster: "cluster-d", Type: v1alpha1.PlacementAffinity, Message: "workload db does not run in the same region"},
+				cacheViolation,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, wd := setupPlacementEnv(t, tt.placement, v1alpha1.WorkloadDeploymentStatus{})
+			workload, err := h.resolveManagedWorkload(context.Background(), wd)
+			require.NoError(t, err)
+
+			res, err := h.evaluatePlacement(context.Background(), wd, workload, wd.Spec.TargetClusters)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, res.Allowed)
+			assert.Equal(t, tt.recommended, res.Recommended)
+			assert.Equal(t, tt.violations, res.Violations)
+		})
+	}
+}
+
+func TestEvaluatePlacement_UnplacedAffinity(t *testing.T) {
+	h, wd := setupPlacementEnv(t, []v1alpha1.PlacementConstraint{
+		{Type: v1alpha1.PlacementAffinity, Workloads: []string{"queue"}, Required: true},
+	}, v1alpha1.WorkloadDeploymentStatus{})
+	workload, err := h.resolveManagedWorkload(context.Background(), wd)
+	require.NoError(t, err)
+
+	res, err := h.evaluatePlacement(context.Background(), wd, workload, []string{"cluster-a", "cluster-b"})
+	require.NoError(t, err)
+	assert.Empty(t, res.Allowed)
+	assert.Empty(t, res.Recommended)
+	require.Len(t, res.Violations, 2)
+	assert.Equal(t, "workload queue is not placed on any cluster", res.Violations[0].Message)
+}
+
+func TestReconcileDeployment_PlacementConstraints(t *testing.T) {
+	h, wd := setupPlacementEnv(t, []v1alpha1.PlacementConstraint{
+		{Type: v1alpha1.PlacementAntiAffinity, Workloads: []string{"db"}, Required: true},
+	}, v1alpha1.WorkloadDeploymentStatus{Phase: "Pending"})
+	deployer := &recordingDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, []string{"cluster-b", "cluster-c", "cluster-d"}, deployer.targets, "cluster-a runs db")
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	cond := meta.FindStatusCondition(wd.Status.Conditions, conditionPlacement)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, reasonPlacementRequired, cond.Reason)
+	assert.Equal(t, "cluster-a: workload db runs on this cluster; cluster-c: workload cache runs on this cluster (constraint of workload cache)", cond.Message)
+
+	h, wd = setupPlacementEnv(t, []v1alpha1.PlacementConstraint{
+		{Type: v1alpha1.PlacementAffinity, Workloads: []string{"queue"}, Required: true},
+	}, v1alpha1.WorkloadDeploymentStatus{Phase: "Pending"})
+	deployer = &recordingDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	require.NotEmpty(t, wd.Status.History)
+	assert.Equal(t, "No target cluster satisfies the required placement constraints", wd.Status.History[len(wd.Status.History)-1].Message)
+}
+
+func TestCheckDeploymentDrift_Placement(t *testing.T) {
+	complete := func(c string) v1alpha1.ClusterRolloutStatus {
+		return v1alpha1.ClusterRolloutStatus{Cluster: c, Phase: "Complete"}
+	}
+	h, wd := setupPlacementEnv(t, []v1alpha1.PlacementConstraint{
+		{Type: v1alpha1.PlacementAntiAffinity, Workloads: []string{"db"}, Required: true},
+	}, v1alpha1.WorkloadDeploymentStatus{
+		Phase:           "Complete",
+		ClusterStatuses: []v1alpha1.ClusterRolloutStatus{complete("cluster-a"), complete("cluster-b"), complete("cluster-c"), complete("cluster-d")},
+	})
+	h.healthChecker = syncReadiness{}
+
+	drift, _, err := h.checkDeploymentDrift(context.Background(), wd)
+	require.NoError(t, err)
+	assert.Equal(t, []driftedCluster{
+		{Deployment: "wd-app", Cluster: "cluster-a", Reason: driftPlacement, Message: "workload db runs on this cluster"},
+	}, drift, "db was deployed to cluster-a after my-app; a preference is not drift")
+	assert.False(t, repairableDrift(drift))
+}
+
+func TestGetDeploymentPlacement(t *testing.T) {
+	h, _ := setupPlacementEnv(t, []v1alpha1.PlacementConstraint{
+		{Type: v1alpha1.PlacementSpread, TopologyKey: v1alpha1.PlacementTopologyRegion},
+	}, v1alpha1.WorkloadDeploymentStatus{})
+	app := fiber.New()
+	app.Get("/api/persistence/deployments/:name/placement", h.GetDeploymentPlacement)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/deployments/wd-app/placement", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Deployment  string               `json:"deployment"`
+		Workload    string               `json:"workload"`
+		Targets     []string             `json:"targets"`
+		Allowed     []string             `json:"allowed"`
+		Recommended []string             `json:"recommended"`
+		Violations  []placementViolation `json:"violations"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "wd-app", body.Deployment)
+	assert.Equal(t, "my-app", body.Workload)
+	assert.Equal(t, []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d"}, body.Targets)
+	assert.Equal(t, body.Targets, body.Allowed, "no constraint is required")
+	assert.Equal(t, []string{"cluster-a", "cluster-d"}, body.Recommended)
+	assert.Len(t, body.Violations, 2)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/deployments/missing/placement", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/compliance/manifestpolicy"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/vulnscan"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// workloadRenderer abstracts RenderWorkload so the policy stage can be tested
+// without a source cluster.
+type workloadRenderer interface {
+	RenderWorkload(ctx context.Context, sourceCluster, namespace, name string,
+		replicas int32, opts *k8s.DeployOptions,
+	) ([]*unstructured.Unstructured, error)
+}
+
+// Condition recorded on WorkloadDeployment.Status by the policy stage.
+const (
+	conditionPolicyCheck = "PolicyCheck"
+
+	reasonPolicyPassed      = "PolicyPassed"
+	reasonPolicyWarnings    = "PolicyWarnings"
+	reasonPolicyViolations  = "PolicyViolations"
+	reasonPolicyCheckFailed = "PolicyCheckFailed"
+)
+
+// maxPolicyConditionViolations caps how many violations are spelled out in
+// the PolicyCheck condition message.
+const maxPolicyConditionViolations = 10
+
+// SetPolicyEngine enables the policy stage of WorkloadDeployment
+// reconciliation. With a nil engine (the default) no policies are evaluated.
+func (h *ConsolePersistenceHandlers) SetPolicyEngine(engine *manifestpolicy.Engine) {
+	h.policies = engine
+}
+
+// SetVulnerabilityScanner makes the scanned vulnerability counts of the
+// workload's images available to the policy stage. With a nil scanner (the
+// default) images are evaluated without them.
+func (h *ConsolePersistenceHandlers) SetVulnerabilityScanner(scanner vulnscan.Scanner) {
+	h.vulnScanner = scanner
+}
+
+// checkDeploymentPolicies renders the workload and evaluates the configured
+// policies once per target cluster. It returns, per cluster, the IDs of the
+// enforce-mode policies that block it, and records the outcome (including
+// audit-mode warnings) as the PolicyCheck condition on wd.
+func (h *ConsolePersistenceHandlers) checkDeploymentPolicies(
+	ctx context.Context, wd *v1alpha1.WorkloadDeployment, workload *v1alpha1.ManagedWorkload,
+	targets []string, opts *k8s.DeployOptions,
+) (map[string][]string, error) {
+	renderer := h.renderer
+	if renderer == nil && h.k8sClient != nil {
+		renderer = h.k8sClient
+	}
+	if renderer == nil {
+		err := errors.New("multi-cluster client not configured")
+		setPolicyCondition(wd, metav1.ConditionFalse, reasonPolicyCheckFailed, "Policy check could not run: "+err.Error())
+		return nil, err
+	}
+
+	replicas := int32(0)
+	if workload.Spec.Replicas != nil {
+		replicas = *workload.Spec.Replicas
+	}
+	rendered, err := renderer.RenderWorkload(ctx, workload.Spec.SourceCluster, workload.Spec.SourceNamespace,
+		workload.Spec.WorkloadRef.Name, replicas, opts)
+	if err != nil {
+		setPolicyCondition(wd, metav1.ConditionFalse, reasonPolicyCheckFailed, "Policy check could not run: "+err.Error())
+		return nil, err
+	}
+	objects := make([]manifestpolicy.Object, 0, len(rendered))
+	for i, obj := range rendered {
+		objects = append(objects, manifestpolicy.Object{Index: i, Content: obj.Object})
+	}
+
+	// The same images go to every cluster, so they are scanned once.
+	vulns := h.imageVulnerabilities(ctx, objects)
+
+	blocked := make(map[string][]string)
+	var enforced, warned []string
+	for _, cluster := range targets {
+		report, err := h.policies.Evaluate(objects, nil, manifestpolicy.Environment{Cluster: cluster, Vulnerabilities: vulns})
+		if err != nil {
+			setPolicyCondition(wd, metav1.ConditionFalse, reasonPolicyCheckFailed, "Policy check could not run: "+err.Error())
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, v := range report.Violations {
+			line := fmt.Sprintf("%s: %s: %s/%s: %s", cluster, v.PolicyID, v.Kind, v.Name, v.Message)
+			if v.Action != manifestpolicy.ActionEnforce {
+				warned = append(warned, line)
+				continue
+			}
+			enforced = append(enforced, line)
+			if !seen[v.PolicyID] {
+				seen[v.PolicyID] = true
+				blocked[cluster] = append(blocked[cluster], v.PolicyID)
+			}
+		}
+	}
+	for _, ids := range blocked {
+		sort.Strings(ids)
+	}
+
+	switch {
+	case len(enforced) > 0:
+		setPolicyCondition(wd, metav1.ConditionFalse, reasonPolicyViolations,
+			fmt.Sprintf("%d of %d clusters blocked by policy: %s", len(blocked), len(targets),
+				summarizeViolations(append(enforced, warned...))))
+	case len(warned) > 0:
+		setPolicyCondition(wd, metav1.ConditionTrue, reasonPolicyWarnings,
+			"Policy warnings: "+summarizeViolations(warned))
+	default:
+		setPolicyCondition(wd, metav1.ConditionTrue, reasonPolicyPassed,
+			fmt.Sprintf("All policies passed on %d clusters", len(targets)))
+	}
+	return blocked, nil
+}
+
+// imageVulnerabilities scans the images of objects, or returns nil without
+// a scanner. Images the scanner has no report for are left out; those it
+// could not be queried for carry the error.
+func (h *ConsolePersistenceHandlers) imageVulnerabilities(
+	ctx context.Context, objects []manifestpolicy.Object,
+) map[string]manifestpolicy.ImageVulnerabilities {
+	if h.vulnScanner == nil {
+		return nil
+	}
+	out := make(map[string]manifestpolicy.ImageVulnerabilities)
+	for image, r := range vulnscan.ScanAll(ctx, h.vulnScanner, manifestpolicy.Images(objects)) {
+		switch {
+		case r.Err == nil:
+			out[image] = manifestpolicy.ImageVulnerabilities{
+				Critical: r.Summary.Critical,
+				High:     r.Summary.High,
+				Medium:   r.Summary.Medium,
+				Low:      r.Summary.Low,
+				Unknown:  r.Summary.Unknown,
+			}
+		case errors.Is(r.Err, vulnscan.ErrNotFound):
+		default:
+			slog.Warn("[reconcile] image vulnerability scan failed", "image", image, "error", r.Err)
+			out[image] = manifestpolicy.ImageVulnerabilities{Error: r.Err.Error()}
+		}
+	}
+	return out
+}
+
+func setPolicyCondition(wd *v1alpha1.WorkloadDeployment, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&wd.Status.Conditions, metav1.Condition{
+		Type:               conditionPolicyCheck,
+		Status:             status,
+		ObservedGeneration: wd.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func summarizeViolations(lines []string) string {
+	if len(lines) <= maxPolicyConditionViolations {
+		return strings.Join(lines, "; ")
+	}
+	return strings.Join(lines[:maxPolicyConditionViolations], "; ") +
+		fmt.Sprintf("; and %d more", len(lines)-maxPolicyConditionViolations)
+}
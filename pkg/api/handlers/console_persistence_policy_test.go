@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/compliance/manifestpolicy"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/vulnscan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeRenderer implements workloadRenderer with a fixed result and records
+// the options it was last called with.
+type fakeRenderer struct {
+	objs []*unstructured.Unstructured
+	err  error
+	opts *k8s.DeployOptions
+}
+
+func (f *fakeRenderer) RenderWorkload(_ context.Context, _, _, _ string, _ int32, opts *k8s.DeployOptions) ([]*unstructured.Unstructured, error) {
+	f.opts = opts
+	return f.objs, f.err
+}
+
+// recordingDeployer deploys successfully to whatever targets it is given.
+type recordingDeployer struct {
+	targets []string
+	calls   int
+	opts    *k8s.DeployOptions
+}
+
+func (r *recordingDeployer) DeployWorkload(_ context.Context, _, _, _ string,
+	targets []string, _ int32, opts *k8s.DeployOptions,
+) (*v1alpha1.DeployResponse, error) {
+	r.calls++
+	r.targets = targets
+	r.opts = opts
+	return &v1alpha1.DeployResponse{Success: true, DeployedTo: targets}, nil
+}
+
+func setupPolicyReconcile(t *testing.T, targets ...string) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment, *recordingDeployer) {
+	t.Helper()
+	h, wd := newReconcileFixture(t, withTargets(targets...), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Name = "wd-policy"
+		wd.Generation = 3
+	}))
+	deployer := &recordingDeployer{}
+	h.deployer = deployer
+	h.renderer = &fakeRenderer{objs: []*unstructured.Unstructured{{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "nginx", "namespace": "default"},
+		"spec":       map[string]interface{}{"replicas": int64(1)},
+	}}}}
+
+	engine, err := manifestpolicy.NewCustomEngine(manifestpolicy.PolicyFile{Policies: []manifestpolicy.Definition{
+		{
+			ID:       "prod-min-replicas",
+			Severity: manifestpolicy.SeverityCritical,
+			Message:  "production needs at least 2 replicas",
+			CEL:      `cluster.name != "cluster-prod" || object.spec.replicas >= 2`,
+		},
+		{
+			ID:       "team-label",
+			Severity: manifestpolicy.SeverityLow,
+			Kinds:    []string{"Deployment"},
+			Message:  "missing team label",
+			CEL:      `has(object.metadata.labels) && "team" in object.metadata.labels`,
+		},
+	}})
+	require.NoError(t, err)
+	h.SetPolicyEngine(engine)
+	return h, wd, deployer
+}
+
+func TestReconcileDeployment_PolicyBlocksCluster(t *testing.T) {
+	h, wd, deployer := setupPolicyReconcile(t, "cluster-dev", "cluster-prod")
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, []string{"cluster-dev"}, deployer.targets, "blocked cluster must not be deployed")
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Contains(t, wd.Status.History[0].Message, "1 succeeded")
+
+	statuses := map[string]v1alpha1.ClusterRolloutStatus{}
+	for _, cs := range wd.Status.ClusterStatuses {
+		statuses[cs.Cluster] = cs
+	}
+	assert.Equal(t, "Complete", statuses["cluster-dev"].Phase)
+	assert.Equal(t, "Failed", statuses["cluster-prod"].Phase)
+	assert.Equal(t, "Blocked by policy: prod-min-replicas", statuses["cluster-prod"].Message)
+
+	cond := meta.FindStatusCondition(wd.Status.Conditions, conditionPolicyCheck)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, reasonPolicyViolations, cond.Reason)
+	assert.Equal(t, int64(3), cond.ObservedGeneration)
+	assert.Contains(t, cond.Message, "cluster-prod: prod-min-replicas")
+	assert.Contains(t, cond.Message, "team-label", "audit warnings are recorded alongside violations")
+}
+
+func TestReconcileDeployment_PolicyWarningsOnly(t *testing.T) {
+	h, wd, deployer := setupPolicyReconcile(t, "cluster-dev")
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, []string{"cluster-dev"}, deployer.targets)
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	cond := meta.FindStatusCondition(wd.Status.Conditions, conditionPolicyCheck)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, reasonPolicyWarnings, cond.Reason)
+}
+
+func TestReconcileDeployment_AllClustersBlocked(t *testing.T) {
+	h, wd, deployer := setupPolicyReconcile(t, "cluster-prod")
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Contains(t, wd.Status.History[0].Message, "All 1 clusters blocked by policy")
+}
+
+func TestReconcileDeployment_PolicyRenderFailureFailsClosed(t *testing.T) {
+	h, wd, deployer := setupPolicyReconcile(t, "cluster-dev")
+	h.renderer = &fakeRenderer{err: errors.New("source cluster unreachable")}
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	cond := meta.FindStatusCondition(wd.Status.Conditions, conditionPolicyCheck)
+	require.NotNil(t, cond)
+	assert.Equal(t, reasonPolicyCheckFailed, cond.Reason)
+	for _, cs := range wd.Status.ClusterStatuses {
+		assert.Equal(t, "Failed", cs.Phase)
+	}
+}
+
+func TestReconcileDeployment_CriticalVulnerabilitiesBlock(t *testing.T) {
+	h, wd, deployer := setupPolicyReconcile(t, "cluster-dev")
+	h.renderer = &fakeRenderer{objs: []*unstructured.Unstructured{{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "nginx", "namespace": "default"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "app", "image": "ghcr.io/acme/nginx:1.27"}},
+		}}},
+	}}}}
+	engine, err := manifestpolicy.NewCustomEngine(manifestpolicy.PolicyFile{
+		Builtins: []string{manifestpolicy.PolicyDisallowCriticalVulnerabilities},
+	})
+	require.NoError(t, err)
+	h.SetPolicyEngine(engine)
+	h.SetVulnerabilityScanner(&fakeVulnScanner{summaries: map[string]*vulnscan.Summary{
+		"ghcr.io/acme/nginx:1.27": {Image: "ghcr.io/acme/nginx:1.27", Critical: 2},
+	}})
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Zero(t, deployer.calls)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	cond := meta.FindStatusCondition(wd.Status.Conditions, conditionPolicyCheck)
+	require.NotNil(t, cond)
+	assert.Contains(t, cond.Message, `image "ghcr.io/acme/nginx:1.27" has 2 critical vulnerabilities`)
+}
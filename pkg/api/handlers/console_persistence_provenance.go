@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// provenanceTimeout bounds reading the images of one cluster.
+const provenanceTimeout = 15 * time.Second
+
+// workloadImageReader is the subset of k8s.MultiClusterClient the
+// reconciler uses to read the image digests a deployed workload runs.
+type workloadImageReader interface {
+	GetWorkloadImages(ctx context.Context, contextName, kind, namespace, name string) ([]k8s.DeployedImage, error)
+}
+
+// provenanceTemplates build the SBOM and attestation references recorded
+// for an image digest. Empty templates record none.
+type provenanceTemplates struct {
+	sbom        string
+	attestation string
+}
+
+// imageProvenanceTemplates reads the reference templates from
+// PROVENANCE_SBOM_TEMPLATE and PROVENANCE_ATTESTATION_TEMPLATE.
+func imageProvenanceTemplates() provenanceTemplates {
+	return provenanceTemplates{
+		sbom:        os.Getenv("PROVENANCE_SBOM_TEMPLATE"),
+		attestation: os.Getenv("PROVENANCE_ATTESTATION_TEMPLATE"),
+	}
+}
+
+// expand fills a template's {repository}, {digest} and {digestTag}
+// placeholders for image. {digestTag} is the digest as a tag, e.g.
+// "sha256-4c1e...", as cosign stores attachments. Images without a digest
+// have no reference.
+func (t provenanceTemplates) expand(tmpl string, image k8s.DeployedImage) string {
+	if tmpl == "" || image.Digest == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{repository}", imageRepository(image.Image),
+		"{digest}", image.Digest,
+		"{digestTag}", strings.Replace(image.Digest, ":", "-", 1),
+	).Replace(tmpl)
+}
+
+// imageRepository strips the tag and digest from an image reference.
+func imageRepository(ref string) string {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// recordImageProvenance records in the cluster statuses of wd the images
+// workload runs on each of clusters, with their digests and SBOM and
+// attestation references. A cluster whose images cannot be read is logged
+// and left without them; provenance never fails a rollout.
+func (h *ConsolePersistenceHandlers) recordImageProvenance(
+	ctx context.Context, wd *v1alpha1.WorkloadDeployment, workload *v1alpha1.ManagedWorkload, clusters []string,
+) {
+	if len(clusters) == 0 || wd.Spec.DryRun || !healthCheckableKinds[workload.Spec.WorkloadRef.Kind] {
+		return
+	}
+	var reader workloadImageReader = h.images
+	if reader == nil && h.k8sClient != nil {
+		reader = h.k8sClient
+	}
+	if reader == nil {
+		return
+	}
+
+	ref := workload.Spec.WorkloadRef
+	found := make(map[string][]v1alpha1.ImageProvenance, len(clusters))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), provenanceTimeout)
+			defer cancel()
+			deployed, err := reader.GetWorkloadImages(readCtx, c, ref.Kind, workload.Spec.SourceNamespace, ref.Name)
+			if err != nil {
+				slog.Warn("[reconcile] cannot record image provenance",
+					"name", wd.Name, "cluster", c, "error", err)
+				return
+			}
+			images := make([]v1alpha1.ImageProvenance, 0, len(deployed))
+			for _, img := range deployed {
+				images = append(images, v1alpha1.ImageProvenance{
+					Container:   img.Container,
+					Image:       img.Image,
+					Digest:      img.Digest,
+					SBOM:        h.provenance.expand(h.provenance.sbom, img),
+					Attestation: h.provenance.expand(h.provenance.attestation, img),
+				})
+			}
+			mu.Lock()
+			found[c] = images
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for i := range wd.Status.ClusterStatuses {
+		cs := &wd.Status.ClusterStatuses[i]
+		if images, ok := found[cs.Cluster]; ok {
+			cs.Images = images
+		}
+	}
+}
+
+// clusterProvenance is what ran on one cluster of a deployment.
+type clusterProvenance struct {
+	Cluster    string                     `json:"cluster"`
+	Phase      string                     `json:"phase,omitempty"`
+	DeployedAt *metav1.Time               `json:"deployedAt,omitempty"`
+	Images     []v1alpha1.ImageProvenance `json:"images"`
+}
+
+// deploymentProvenance is the response of GetDeploymentProvenance.
+type deploymentProvenance struct {
+	Deployment string              `json:"deployment"`
+	Workload   string              `json:"workload"`
+	Clusters   []clusterProvenance `json:"clusters"`
+}
+
+// GetDeploymentProvenance returns the images and digests a deployment ran
+// on each target cluster, and when, with their SBOM and attestation
+// references.
+// GET /api/persistence/deployments/:name/provenance
+func (h *ConsolePersistenceHandlers) GetDeploymentProvenance(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if err := validateDNSSubdomain("name", name); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+	client, _, err := h.persistenceStore.GetActiveClient(c.UserContext())
+	if err != nil {
+		slog.Warn("[ConsolePersistence] service unavailable", "error", err)
+		return localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	}
+	wd, err := k8s.NewConsolePersistence(client).GetWorkloadDeployment(c.UserContext(), namespace, name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return localizedError(c, fiber.StatusNotFound, "persistence.deploymentNotFound")
+		}
+		slog.Warn("[ConsolePersistence] internal error", "error", err)
+		return localizedError(c, fiber.StatusInternalServerError, "server.internalError")
+	}
+
+	clusters := make([]clusterProvenance, 0, len(wd.Status.ClusterStatuses))
+	for _, cs := range wd.Status.ClusterStatuses {
+		images := cs.Images
+		if images == nil {
+			images = []v1alpha1.ImageProvenance{}
+		}
+		p := clusterProvenance{Cluster: cs.Cluster, Phase: cs.Phase, Images: images}
+		if cs.Phase == "Complete" {
+			p.DeployedAt = cs.CompletedAt
+		}
+		clusters = append(clusters, p)
+	}
+	return c.JSON(deploymentProvenance{
+		Deployment: wd.Name,
+		Workload:   wd.Spec.WorkloadRef.Name,
+		Clusters:   clusters,
+	})
+}
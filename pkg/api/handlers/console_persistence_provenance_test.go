@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeImageReader returns the images of each cluster, or fails for
+// clusters without any.
+type fakeImageReader struct {
+	images map[string][]k8s.DeployedImage
+}
+
+func (f *fakeImageReader) GetWorkloadImages(_ context.Context, contextName, _, _, _ string) ([]k8s.DeployedImage, error) {
+	images, ok := f.images[contextName]
+	if !ok {
+		return nil, errors.New("cluster unreachable")
+	}
+	return images, nil
+}
+
+// setupProvenanceEnv seeds a Deployment workload and a deployment of it to
+// cluster-a and cluster-b.
+func setupProvenanceEnv(t *testing.T) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	h, wd := newReconcileFixture(t, withTargets("cluster-a", "cluster-b"), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Status.Phase = "Pending"
+	}))
+	h.deployer = &fakeDeployer{resp: &v1alpha1.DeployResponse{DeployedTo: []string{"cluster-a", "cluster-b"}}}
+	h.images = &fakeImageReader{images: map[string][]k8s.DeployedImage{
+		"cluster-a": {
+			{Container: "web", Image: "ghcr.io/acme/web:1.4", Digest: "sha256:aaa"},
+			{Container: "log", Image: "busybox"},
+		},
+	}}
+	h.provenance = provenanceTemplates{
+		sbom:        "{repository}:{digestTag}.sbom",
+		attestation: "https://rekor.example/search?hash={digest}",
+	}
+	return h, wd
+}
+
+func TestReconcileDeployment_RecordsImageProvenance(t *testing.T) {
+	h, wd := setupProvenanceEnv(t)
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	require.Equal(t, "Complete", wd.Status.Phase, "an unreadable cluster does not fail the rollout")
+	statuses := map[string]v1alpha1.ClusterRolloutStatus{}
+	for _, cs := range wd.Status.ClusterStatuses {
+		statuses[cs.Cluster] = cs
+	}
+	assert.Equal(t, []v1alpha1.ImageProvenance{
+		{
+			Container:   "web",
+			Image:       "ghcr.io/acme/web:1.4",
+			Digest:      "sha256:aaa",
+			SBOM:        "ghcr.io/acme/web:sha256-aaa.sbom",
+			Attestation: "https://rekor.example/search?hash=sha256:aaa",
+		},
+		{Container: "log", Image: "busybox"},
+	}, statuses["cluster-a"].Images)
+	assert.Empty(t, statuses["cluster-b"].Images)
+}
+
+func TestReconcileDeployment_DryRunRecordsNoProvenance(t *testing.T) {
+	h, wd := setupProvenanceEnv(t)
+	wd.Spec.DryRun = true
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	for _, cs := range wd.Status.ClusterStatuses {
+		assert.Empty(t, cs.Images, cs.Cluster)
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	for ref, want := range map[string]string{
+		"nginx":                      "nginx",
+		"ghcr.io/acme/web:1.4":       "ghcr.io/acme/web",
+		"localhost:5000/web":         "localhost:5000/web",
+		"localhost:5000/web:1.4":     "localhost:5000/web",
+		"ghcr.io/acme/web@sha256:aa": "ghcr.io/acme/web",
+	} {
+		assert.Equal(t, want, imageRepository(ref), ref)
+	}
+}
+
+func TestGetDeploymentProvenance(t *testing.T) {
+	h, wd := setupProvenanceEnv(t)
+	h.reconcileDeployment(context.Background(), wd)
+	app := fiber.New()
+	app.Get("/api/persistence/deployments/:name/provenance", h.GetDeploymentProvenance)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/deployments/wd-app/provenance", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body deploymentProvenance
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "wd-app", body.Deployment)
+	assert.Equal(t, "my-app", body.Workload)
+	require.Len(t, body.Clusters, 2)
+	for _, c := range body.Clusters {
+		assert.Equal(t, "Complete", c.Phase, c.Cluster)
+		assert.NotNil(t, c.DeployedAt, c.Cluster)
+		if c.Cluster == "cluster-a" {
+			require.Len(t, c.Images, 2)
+			assert.Equal(t, "sha256:aaa", c.Images[0].Digest)
+		} else {
+			assert.Empty(t, c.Images)
+		}
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/persistence/deployments/missing/provenance", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
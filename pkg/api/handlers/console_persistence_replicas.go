@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// clusterCapacityReader is the subset of k8s.MultiClusterClient the Capacity
+// replica distribution uses to weigh clusters.
+type clusterCapacityReader interface {
+	GetClusterHealth(ctx context.Context, contextName string) (*k8s.ClusterHealth, error)
+}
+
+// replicaSplit returns the replicas workload's replica distribution assigns
+// to each of targets, or nil when the workload has none. Clusters a Fixed
+// distribution does not list are left out when the workload has no
+// replicas, so they keep the source's count.
+func (h *ConsolePersistenceHandlers) replicaSplit(ctx context.Context, workload *v1alpha1.ManagedWorkload, targets []string) map[string]int32 {
+	d := workload.Spec.ReplicaDistribution
+	if d == nil {
+		return nil
+	}
+	split := make(map[string]int32, len(targets))
+	var rest []string
+	var pinned int32
+	for _, cluster := range targets {
+		if n, ok := d.Clusters[cluster]; ok {
+			split[cluster] = n
+			pinned += n
+		} else {
+			rest = append(rest, cluster)
+		}
+	}
+
+	strategy := d.EffectiveStrategy()
+	if strategy == v1alpha1.ReplicaStrategyFixed {
+		if workload.Spec.Replicas != nil {
+			for _, cluster := range rest {
+				split[cluster] = *workload.Spec.Replicas
+			}
+		}
+		return split
+	}
+	if len(rest) == 0 {
+		return split
+	}
+	total := int32(0)
+	if workload.Spec.Replicas != nil {
+		total = max(*workload.Spec.Replicas-pinned, 0)
+	}
+	weights := make([]int64, len(rest))
+	for i := range weights {
+		weights[i] = 1
+	}
+	if strategy == v1alpha1.ReplicaStrategyCapacity {
+		weights = h.clusterCapacities(ctx, rest, d.EffectiveCapacityResource())
+	}
+	for i, n := range distributeReplicas(total, weights) {
+		split[rest[i]] = n
+	}
+	return split
+}
+
+// clusterCapacities returns the allocatable resource of each cluster: CPU
+// cores or memory MiB, which keeps total times the weight within an int64.
+// A cluster whose capacity cannot be read weighs 0.
+func (h *ConsolePersistenceHandlers) clusterCapacities(ctx context.Context, clusters []string, resource string) []int64 {
+	var reader clusterCapacityReader = h.capacity
+	if reader == nil && h.k8sClient != nil {
+		reader = h.k8sClient
+	}
+	weights := make([]int64, len(clusters))
+	if reader == nil {
+		return weights
+	}
+	for i, cluster := range clusters {
+		health, err := reader.GetClusterHealth(ctx, cluster)
+		if err != nil || health == nil {
+			slog.Warn("[reconcile] cannot read cluster capacity for replica distribution",
+				"cluster", cluster, "error", err)
+			continue
+		}
+		if resource == v1alpha1.ReplicaCapacityMemory {
+			weights[i] = health.MemoryBytes >> 20
+		} else {
+			weights[i] = int64(health.CpuCores)
+		}
+	}
+	return weights
+}
+
+// distributeReplicas splits total in proportion to weights, giving the
+// replicas left over after rounding down to the largest remainders, earlier
+// entries first on a tie. When every weight is 0 the split is even.
+func distributeReplicas(total int32, weights []int64) []int32 {
+	out := make([]int32, len(weights))
+	if len(weights) == 0 {
+		return out
+	}
+	var sum int64
+	for _, w := range weights {
+		sum += max(w, 0)
+	}
+	if sum == 0 {
+		weights = make([]int64, len(weights))
+		for i := range weights {
+			weights[i] = 1
+		}
+		sum = int64(len(weights))
+	}
+
+	remainders := make([]int64, len(weights))
+	left := int64(total)
+	for i, w := range weights {
+		share := int64(total) * max(w, 0)
+		out[i] = int32(share / sum)
+		remainders[i] = share % sum
+		left -= int64(out[i])
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case remainders[a] > remainders[b]:
+			return -1
+		case remainders[a] < remainders[b]:
+			return 1
+		}
+		return 0
+	})
+	for _, i := range order[:left] {
+		out[i]++
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeCapacity implements clusterCapacityReader with fixed CPU cores per
+// cluster; clusters without an entry cannot be read.
+type fakeCapacity map[string]int
+
+func (f fakeCapacity) GetClusterHealth(_ context.Context, cluster string) (*k8s.ClusterHealth, error) {
+	cores, ok := f[cluster]
+	if !ok {
+		return nil, errors.New("cluster unreachable")
+	}
+	return &k8s.ClusterHealth{Cluster: cluster, CpuCores: cores, MemoryBytes: int64(cores) << 31}, nil
+}
+
+func TestDistributeReplicas(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int32
+		weights []int64
+		want    []int32
+	}{
+		{name: "even", total: 6, weights: []int64{1, 1, 1}, want: []int32{2, 2, 2}},
+		{name: "remainder goes to the first", total: 7, weights: []int64{1, 1, 1}, want: []int32{3, 2, 2}},
+		{name: "fewer replicas than clusters", total: 2, weights: []int64{1, 1, 1}, want: []int32{1, 1, 0}},
+		{name: "weighted", total: 10, weights: []int64{8, 2}, want: []int32{8, 2}},
+		{name: "largest remainder", total: 5, weights: []int64{16, 32, 16}, want: []int32{1, 3, 1}},
+		{name: "unknown weights split evenly", total: 4, weights: []int64{0, 0}, want: []int32{2, 2}},
+		{name: "zero weight gets none", total: 4, weights: []int64{0, 4}, want: []int32{0, 4}},
+		{name: "no clusters", total: 4, weights: nil, want: []int32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, distributeReplicas(tt.total, tt.weights))
+		})
+	}
+}
+
+func TestReplicaSplit(t *testing.T) {
+	six := int32(6)
+	h := &ConsolePersistenceHandlers{capacity: fakeCapacity{"cluster-a": 32, "cluster-b": 16}}
+	workload := func(replicas *int32, d v1alpha1.ReplicaDistribution) *v1alpha1.ManagedWorkload {
+		return &v1alpha1.ManagedWorkload{Spec: v1alpha1.ManagedWorkloadSpec{Replicas: replicas, ReplicaDistribution: &d}}
+	}
+	targets := []string{"cluster-a", "cluster-b", "cluster-c"}
+	ctx := context.Background()
+
+	assert.Nil(t, h.replicaSplit(ctx, &v1alpha1.ManagedWorkload{Spec: v1alpha1.ManagedWorkloadSpec{Replicas: &six}}, targets))
+	assert.Equal(t, map[string]int32{"cluster-a": 2, "cluster-b": 2, "cluster-c": 2},
+		h.replicaSplit(ctx, workload(&six, v1alpha1.ReplicaDistribution{}), targets))
+	assert.Equal(t, map[string]int32{"cluster-a": 3, "cluster-b": 2, "cluster-c": 1},
+		h.replicaSplit(ctx, workload(&six, v1alpha1.ReplicaDistribution{Clusters: map[string]int32{"cluster-c": 1}}), targets),
+		"the pinned cluster is left out of the split")
+	assert.Equal(t, map[string]int32{"cluster-a": 4, "cluster-b": 2, "cluster-c": 0},
+		h.replicaSplit(ctx, workload(&six, v1alpha1.ReplicaDistribution{Strategy: v1alpha1.ReplicaStrategyCapacity}), targets),
+		"cluster-c's capacity cannot be read")
+	assert.Equal(t, map[string]int32{"cluster-a": 4, "cluster-b": 2, "cluster-c": 0},
+		h.replicaSplit(ctx, workload(&six, v1alpha1.ReplicaDistribution{
+			Strategy: v1alpha1.ReplicaStrategyCapacity, CapacityResource: v1alpha1.ReplicaCapacityMemory,
+		}), targets))
+	assert.Equal(t, map[string]int32{"cluster-a": 5},
+		h.replicaSplit(ctx, workload(nil, v1alpha1.ReplicaDistribution{
+			Strategy: v1alpha1.ReplicaStrategyFixed, Clusters: map[string]int32{"cluster-a": 5, "cluster-z": 1},
+		}), targets), "unlisted clusters keep the source's count")
+	assert.Equal(t, map[string]int32{"cluster-a": 5, "cluster-b": 6, "cluster-c": 6},
+		h.replicaSplit(ctx, workload(&six, v1alpha1.ReplicaDistribution{
+			Strategy: v1alpha1.ReplicaStrategyFixed, Clusters: map[string]int32{"cluster-a": 5},
+		}), targets), "unlisted clusters run spec.replicas")
+}
+
+// setupReplicaEnv persists my-app with six replicas split evenly and the
+// pool group of members, and returns deployment wd-pool targeting it.
+func setupReplicaEnv(t *testing.T, members []string, status v1alpha1.WorkloadDeploymentStatus) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	six := int32(6)
+	group := &v1alpha1.ClusterGroup{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "test-ns"},
+		Spec:       v1alpha1.ClusterGroupSpec{StaticMembers: members},
+	}
+	return newReconcileFixture(t,
+		withWorkload(func(mw *v1alpha1.ManagedWorkload) {
+			mw.Spec.Replicas = &six
+			mw.Spec.ReplicaDistribution = &v1alpha1.ReplicaDistribution{}
+		}),
+		withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+			wd.Name = "wd-pool"
+			wd.Spec.TargetGroupRef = &v1alpha1.ResourceReference{Name: "pool"}
+			wd.Status = status
+		}),
+		withObjects(group),
+	)
+}
+
+func TestReconcileDeployment_SplitsReplicas(t *testing.T) {
+	h, wd := setupReplicaEnv(t, []string{"cluster-a", "cluster-b", "cluster-c", "cluster-d"},
+		v1alpha1.WorkloadDeploymentStatus{Phase: "Pending"})
+	deployer := &recordingDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	require.NotNil(t, deployer.opts)
+	want := map[string]int32{"cluster-a": 2, "cluster-b": 2, "cluster-c": 1, "cluster-d": 1}
+	assert.Equal(t, want, deployer.opts.ClusterReplicas)
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	for cluster, cs := range rolloutStatuses(wd) {
+		require.NotNil(t, cs.Replicas, cluster)
+		assert.Equal(t, want[cluster], *cs.Replicas, cluster)
+	}
+}
+
+func TestRebalanceDeployments(t *testing.T) {
+	three := int32(3)
+	complete := func(c string) v1alpha1.ClusterRolloutStatus {
+		return v1alpha1.ClusterRolloutStatus{Cluster: c, Phase: "Complete", Replicas: &three}
+	}
+	// The deployment split six replicas over two clusters before cluster-c
+	// joined the group.
+	h, wd := setupReplicaEnv(t, []string{"cluster-a", "cluster-b", "cluster-c"}, v1alpha1.WorkloadDeploymentStatus{
+		Phase:           "Complete",
+		ClusterStatuses: []v1alpha1.ClusterRolloutStatus{complete("cluster-a"), complete("cluster-b")},
+	})
+	h.healthChecker = syncReadiness{}
+	h.deployer = &recordingDeployer{}
+
+	drift, _, err := h.checkDeploymentDrift(context.Background(), wd)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []driftedCluster{
+		{Deployment: "wd-pool", Cluster: "cluster-a", Reason: driftReplicas, Message: "3 replicas assigned, 2 expected"},
+		{Deployment: "wd-pool", Cluster: "cluster-b", Reason: driftReplicas, Message: "3 replicas assigned, 2 expected"},
+		{Deployment: "wd-pool", Cluster: "cluster-c", Reason: driftNotDeployed},
+	}, drift)
+
+	assert.Empty(t, h.rebalanceDeployments(context.Background(), "test-ns", []string{"other"}))
+	assert.Equal(t, []string{"wd-pool"}, h.rebalanceDeployments(context.Background(), "test-ns", []string{"pool"}))
+}
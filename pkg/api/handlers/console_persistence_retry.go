@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// phaseRetrying marks a cluster whose apply failed and that is deployed to
+// again at its NextRetryAt.
+const phaseRetrying = "Retrying"
+
+// retryPolicies returns the retry policy of each of clusters that has one.
+// The deployment's own policy applies to every cluster. Otherwise a cluster
+// takes the effective policy of the group that lists it, which a member
+// group inherits from the groups including it. A cluster listed by several
+// groups takes the deployment's target group's, or else the first by name.
+func (h *ConsolePersistenceHandlers) retryPolicies(
+	ctx context.Context, wd *v1alpha1.WorkloadDeployment, clusters []string,
+) (map[string]v1alpha1.RetryPolicy, error) {
+	policies := make(map[string]v1alpha1.RetryPolicy, len(clusters))
+	if rp := wd.Spec.RetryPolicy; rp != nil {
+		for _, c := range clusters {
+			policies[c] = *rp
+		}
+		return policies, nil
+	}
+	if len(clusters) == 0 {
+		return policies, nil
+	}
+	hierarchy, err := h.clusterGroupHierarchy(ctx, wd.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(clusters))
+	for _, c := range clusters {
+		wanted[c] = true
+	}
+
+	assign := func(rp *v1alpha1.RetryPolicy, members []string) {
+		for _, c := range members {
+			if _, seen := policies[c]; wanted[c] && !seen {
+				policies[c] = *rp
+			}
+		}
+	}
+	if ref := wd.Spec.TargetGroupRef; ref != nil && hierarchy.Group(ref.Name) != nil {
+		if rp := hierarchy.Effective(ref.Name).Settings.RetryPolicy; rp != nil {
+			members, _ := h.matchOwnMembers(ctx, hierarchy.Group(ref.Name), false)
+			assign(rp, members)
+		}
+	}
+	for _, name := range hierarchy.Names() {
+		if rp := hierarchy.Effective(name).Settings.RetryPolicy; rp != nil {
+			members, _ := h.matchOwnMembers(ctx, hierarchy.Group(name), false)
+			assign(rp, members)
+		}
+	}
+	return policies, nil
+}
+
+// scheduleClusterRetry sets a cluster whose apply failed to Retrying when
+// its policy allows another attempt, and returns when it is retried. It
+// returns false when the cluster has used up its attempts.
+func scheduleClusterRetry(cs *v1alpha1.ClusterRolloutStatus, policy v1alpha1.RetryPolicy, now time.Time) (time.Time, bool) {
+	if cs.Attempts >= policy.Attempts() {
+		return time.Time{}, false
+	}
+	delay, err := policy.Delay(cs.Attempts)
+	if err != nil {
+		slog.Warn("[reconcile] invalid retry policy, not retrying",
+			"cluster", cs.Cluster, "error", err)
+		return time.Time{}, false
+	}
+	at := now.Add(delay)
+	next := metav1.NewTime(at)
+	cs.Phase = phaseRetrying
+	cs.Progress = "0%"
+	cs.Message = fmt.Sprintf("Attempt %d of %d failed; retrying at %s",
+		cs.Attempts, policy.Attempts(), at.UTC().Format(time.RFC3339))
+	cs.NextRetryAt = &next
+	cs.CompletedAt = nil
+	return at, true
+}
+
+// retryWarned reports whether a Failed cluster used up the attempts of a
+// policy that only warns, so it does not fail the deployment.
+func retryWarned(cs v1alpha1.ClusterRolloutStatus, policies map[string]v1alpha1.RetryPolicy) bool {
+	policy, ok := policies[cs.Cluster]
+	return ok && cs.Phase == "Failed" && cs.Attempts > 0 && policy.WarnOnly()
+}
+
+// pendingRetries returns when each of targets that an earlier pass set to
+// Retrying is due, for those not due by now.
+func pendingRetries(prior map[string]v1alpha1.ClusterRolloutStatus, targets []string, now time.Time) map[string]time.Time {
+	var waiting map[string]time.Time
+	for _, cluster := range targets {
+		prev, ok := prior[cluster]
+		if !ok || prev.Phase != phaseRetrying || prev.NextRetryAt == nil || !prev.NextRetryAt.After(now) {
+			continue
+		}
+		if waiting == nil {
+			waiting = make(map[string]time.Time)
+		}
+		waiting[cluster] = prev.NextRetryAt.Time
+	}
+	return waiting
+}
+
+// cancelRetries fails every Retrying cluster of wd, once another cluster
+// failed for good.
+func cancelRetries(wd *v1alpha1.WorkloadDeployment, now metav1.Time) {
+	for i := range wd.Status.ClusterStatuses {
+		cs := &wd.Status.ClusterStatuses[i]
+		if cs.Phase != phaseRetrying {
+			continue
+		}
+		cs.Phase = "Failed"
+		cs.Message = "Retry cancelled: another cluster failed"
+		cs.NextRetryAt = nil
+		cs.CompletedAt = &now
+	}
+}
+
+// earliest returns the earlier of a and b, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// waitingMessage describes a deployment parked until its queued clusters'
+// windows open or its retrying clusters are due.
+func waitingMessage(queued, retrying int) string {
+	switch {
+	case retrying == 0:
+		return fmt.Sprintf("%d clusters queued for their deployment window", queued)
+	case queued == 0:
+		return fmt.Sprintf("%d clusters retrying", retrying)
+	default:
+		return fmt.Sprintf("%d clusters queued for their deployment window, %d retrying", queued, retrying)
+	}
+}
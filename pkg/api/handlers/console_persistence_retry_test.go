@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// flakyDeployer fails each cluster in failures that many times, or always
+// for a negative count, and deploys the rest.
+type flakyDeployer struct {
+	failures map[string]int
+	calls    [][]string
+}
+
+func (f *flakyDeployer) DeployWorkload(_ context.Context, _, _, _ string,
+	targets []string, _ int32, _ *k8s.DeployOptions,
+) (*v1alpha1.DeployResponse, error) {
+	f.calls = append(f.calls, slices.Clone(targets))
+	resp := &v1alpha1.DeployResponse{}
+	for _, c := range targets {
+		if n := f.failures[c]; n != 0 {
+			f.failures[c] = n - 1
+			resp.FailedClusters = append(resp.FailedClusters, c)
+			continue
+		}
+		resp.DeployedTo = append(resp.DeployedTo, c)
+	}
+	return resp, nil
+}
+
+// setupRetryReconcile seeds a deployment of my-app to east and west with
+// the given retry policy, and the given cluster groups.
+func setupRetryReconcile(t *testing.T, policy *v1alpha1.RetryPolicy, failures map[string]int, groups ...*v1alpha1.ClusterGroup) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment, *flakyDeployer, *time.Time) {
+	t.Helper()
+	var seeded []unstructuredObject
+	for _, g := range groups {
+		g.TypeMeta = metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ClusterGroup"}
+		g.Namespace = "test-ns"
+		seeded = append(seeded, g)
+	}
+	h, wd := newReconcileFixture(t, withTargets("east", "west"), withObjects(seeded...), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Name = "wd-retry"
+		wd.Spec.RetryPolicy = policy
+	}))
+	deployer := &flakyDeployer{failures: failures}
+	h.deployer = deployer
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	t.Cleanup(h.stopQueuedDeployments)
+	return h, wd, deployer, &now
+}
+
+func TestReconcileDeployment_RetriesFailedCluster(t *testing.T) {
+	policy := &v1alpha1.RetryPolicy{MaxAttempts: 3, Backoff: "30s"}
+	h, wd, deployer, now := setupRetryReconcile(t, policy, map[string]int{"west": 1})
+	start := *now
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, phaseQueued, wd.Status.Phase, "a retrying deployment waits like a queued one")
+	require.NotNil(t, wd.Status.NextEligibleAt)
+	assert.True(t, wd.Status.NextEligibleAt.Time.Equal(start.Add(30*time.Second)))
+	statuses := clusterStatuses(wd)
+	assert.Equal(t, "Complete", statuses["east"].Phase)
+	assert.Equal(t, int32(1), statuses["east"].Attempts)
+	west := statuses["west"]
+	assert.Equal(t, phaseRetrying, west.Phase)
+	assert.Equal(t, int32(1), west.Attempts)
+	assert.Equal(t, "Attempt 1 of 3 failed; retrying at 2026-10-14T12:00:30Z", west.Message)
+	require.NotNil(t, west.NextRetryAt)
+	assert.True(t, west.NextRetryAt.Time.Equal(start.Add(30*time.Second)))
+	assert.Nil(t, west.CompletedAt)
+	h.queueMu.Lock()
+	_, scheduled := h.queueTimers["test-ns/wd-retry"]
+	h.queueMu.Unlock()
+	assert.True(t, scheduled, "a resume is scheduled for the retry")
+
+	// Resumed before the retry is due: nothing is deployed.
+	*now = start.Add(10 * time.Second)
+	h.reconcileDeployment(context.Background(), wd)
+	assert.Len(t, deployer.calls, 1)
+	assert.Equal(t, phaseQueued, wd.Status.Phase)
+	assert.Equal(t, phaseRetrying, clusterStatuses(wd)["west"].Phase)
+
+	*now = start.Add(31 * time.Second)
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, []string{"west"}, deployer.calls[1], "only the retrying cluster is deployed to")
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	assert.Equal(t, "2/2 clusters", wd.Status.Progress)
+	west = clusterStatuses(wd)["west"]
+	assert.Equal(t, "Complete", west.Phase)
+	assert.Equal(t, int32(2), west.Attempts)
+	assert.Nil(t, west.NextRetryAt)
+}
+
+func TestReconcileDeployment_RetryWarnOnly(t *testing.T) {
+	policy := &v1alpha1.RetryPolicy{MaxAttempts: 2, Backoff: "30s", OnFailure: v1alpha1.RetryWarn}
+	h, wd, _, now := setupRetryReconcile(t, policy, map[string]int{"west": -1})
+
+	h.reconcileDeployment(context.Background(), wd)
+	require.Equal(t, phaseQueued, wd.Status.Phase)
+
+	*now = now.Add(time.Minute)
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, "Complete", wd.Status.Phase, "a cluster that only warns does not fail the deployment")
+	assert.Equal(t, "1 clusters deployed successfully, 1 failed with warnings", wd.Status.History[0].Message)
+	west := clusterStatuses(wd)["west"]
+	assert.Equal(t, "Failed", west.Phase)
+	assert.Equal(t, "Deployment failed after 2 attempts (treated as a warning)", west.Message)
+	assert.Equal(t, int32(2), west.Attempts)
+}
+
+func TestReconcileDeployment_GroupRetryPolicyFailFast(t *testing.T) {
+	// west inherits edge's policy through edge-west; east has none.
+	edge := &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+		Spec: v1alpha1.ClusterGroupSpec{
+			MemberGroups: []string{"edge-west"},
+			Settings:     &v1alpha1.ClusterGroupSettings{RetryPolicy: &v1alpha1.RetryPolicy{MaxAttempts: 3}},
+		},
+	}
+	edgeWest := &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-west"},
+		Spec:       v1alpha1.ClusterGroupSpec{StaticMembers: []string{"west"}},
+	}
+	h, wd, _, _ := setupRetryReconcile(t, nil, map[string]int{"east": 1, "west": 1}, edge, edgeWest)
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	assert.Equal(t, "All 2 clusters failed", wd.Status.History[0].Message)
+	statuses := clusterStatuses(wd)
+	assert.Equal(t, "Deployment failed", statuses["east"].Message)
+	assert.Zero(t, statuses["east"].Attempts, "east has no retry policy")
+	west := statuses["west"]
+	assert.Equal(t, "Failed", west.Phase)
+	assert.Equal(t, "Retry cancelled: another cluster failed", west.Message)
+	assert.Equal(t, int32(1), west.Attempts)
+	assert.Nil(t, west.NextRetryAt)
+	h.queueMu.Lock()
+	defer h.queueMu.Unlock()
+	assert.Empty(t, h.queueTimers)
+}
+
+func TestRetryPolicies_DeploymentOverridesGroups(t *testing.T) {
+	group := &v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge"},
+		Spec: v1alpha1.ClusterGroupSpec{
+			StaticMembers: []string{"west"},
+			Settings:      &v1alpha1.ClusterGroupSettings{RetryPolicy: &v1alpha1.RetryPolicy{MaxAttempts: 5}},
+		},
+	}
+	own := &v1alpha1.RetryPolicy{MaxAttempts: 2}
+	h, wd, _, _ := setupRetryReconcile(t, own, nil, group)
+
+	policies, err := h.retryPolicies(context.Background(), wd, []string{"east", "west"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]v1alpha1.RetryPolicy{"east": *own, "west": *own}, policies)
+
+	wd.Spec.RetryPolicy = nil
+	policies, err = h.retryPolicies(context.Background(), wd, []string{"east", "west"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]v1alpha1.RetryPolicy{"west": {MaxAttempts: 5}}, policies)
+}
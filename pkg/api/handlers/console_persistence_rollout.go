@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/i18n"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// WorkloadDeploymentClusterStatusType is the WebSocket message type for a
+// per-cluster status change while a rolling rollout or pre-deploy backups
+// run.
+const WorkloadDeploymentClusterStatusType = "workload_deployment_cluster_status"
+
+// phaseSkipped marks a cluster a halted rolling rollout never deployed to.
+const phaseSkipped = "Skipped"
+
+// rolloutHealthPollInterval is how often a rolling rollout polls a deployed
+// workload's readiness. A var so tests can shorten it.
+var rolloutHealthPollInterval = 5 * time.Second
+
+// workloadHealthChecker is the subset of k8s.MultiClusterClient a rolling
+// rollout uses to check that a deployed workload is ready.
+type workloadHealthChecker interface {
+	GetWorkloadReadiness(ctx context.Context, contextName, kind, namespace, name string) (k8s.WorkloadReadiness, error)
+}
+
+// clusterStatusEvent is the data of a WorkloadDeploymentClusterStatusType
+// message.
+type clusterStatusEvent struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	Phase     string `json:"phase"`
+	Progress  string `json:"progress,omitempty"`
+	Message   string `json:"message,omitempty"`
+	// MessageKey is the catalog key Message was rendered from, so clients
+	// can show it in their own language; empty for uncataloged messages.
+	MessageKey string `json:"messageKey,omitempty"`
+}
+
+// rolloutPlan is a validated RolloutConfig.
+type rolloutPlan struct {
+	batchSize     int
+	pause         time.Duration
+	healthTimeout time.Duration
+}
+
+// newRolloutPlan returns the rolling rollout plan for spec, or nil when spec
+// deploys every cluster at once.
+func newRolloutPlan(spec v1alpha1.WorkloadDeploymentSpec) (*rolloutPlan, error) {
+	if !spec.IsRolling() {
+		return nil, nil
+	}
+	rc := spec.RolloutConfig
+	if err := rc.Validate(); err != nil {
+		return nil, err
+	}
+	pause, _ := rc.Pause()
+	timeout, _ := rc.HealthTimeout()
+	return &rolloutPlan{batchSize: rc.BatchSize(), pause: pause, healthTimeout: timeout}, nil
+}
+
+// healthCheckableKinds are the workload kinds GetWorkloadReadiness reports on.
+var healthCheckableKinds = map[string]bool{
+	k8s.WorkloadKindDeployment:  true,
+	k8s.WorkloadKindStatefulSet: true,
+	k8s.WorkloadKindDaemonSet:   true,
+	k8s.WorkloadKindReplicaSet:  true,
+}
+
+// setClusterStatus updates a cluster's rollout status and, when it changed,
+// publishes it to connected clients.
+func (h *ConsolePersistenceHandlers) setClusterStatus(
+	wd *v1alpha1.WorkloadDeployment, cs *v1alpha1.ClusterRolloutStatus, phase, progress, message string,
+) {
+	if cs.Phase == phase && cs.Progress == progress && cs.Message == message {
+		return
+	}
+	now := metav1.Now()
+	switch phase {
+	case "InProgress":
+		if cs.Phase != "InProgress" {
+			cs.StartedAt = &now
+		}
+	case "Complete", "Failed", phaseSkipped:
+		cs.CompletedAt = &now
+	}
+	cs.Phase = phase
+	cs.Progress = progress
+	cs.Message = message
+	h.metrics.observeClusterRollout(cs)
+	h.publishClusterStatus(wd, *cs)
+}
+
+// publishClusterStatus broadcasts a cluster's rollout status.
+func (h *ConsolePersistenceHandlers) publishClusterStatus(wd *v1alpha1.WorkloadDeployment, cs v1alpha1.ClusterRolloutStatus) {
+	if h.hub == nil {
+		return
+	}
+	key, _, _ := i18n.Default().Match(cs.Message)
+	h.broadcastToNamespace(wd.Namespace, Message{
+		Type: WorkloadDeploymentClusterStatusType,
+		Data: clusterStatusEvent{
+			Namespace:  wd.Namespace,
+			Name:       wd.Name,
+			Cluster:    cs.Cluster,
+			Phase:      cs.Phase,
+			Progress:   cs.Progress,
+			Message:    cs.Message,
+			MessageKey: key,
+		},
+	})
+}
+
+// rollOut deploys the workload to targets plan.batchSize clusters at a time.
+// Each batch must become healthy within plan.healthTimeout before the next
+// one starts, plan.pause later. A failed cluster halts the rollout and the
+// clusters after its batch are Skipped. rollOut settles the status of every
+// target and reports whether the rollout halted.
+//
+// A rollout with pauses and health checks can outlive the reconcile
+// deadline, so each batch is bounded on its own instead.
+func (h *ConsolePersistenceHandlers) rollOut(
+	ctx context.Context,
+	wd *v1alpha1.WorkloadDeployment,
+	workload *v1alpha1.ManagedWorkload,
+	plan *rolloutPlan,
+	deployer workloadDeployer,
+	targets []string,
+	replicas int32,
+	opts *k8s.DeployOptions,
+	updateFn func(*v1alpha1.WorkloadDeployment),
+) bool {
+	statuses := make(map[string]*v1alpha1.ClusterRolloutStatus, len(wd.Status.ClusterStatuses))
+	for i := range wd.Status.ClusterStatuses {
+		statuses[wd.Status.ClusterStatuses[i].Cluster] = &wd.Status.ClusterStatuses[i]
+	}
+	rolloutCtx := context.WithoutCancel(ctx)
+	spec := workload.Spec
+
+	for start := 0; start < len(targets); start += plan.batchSize {
+		if start > 0 && plan.pause > 0 {
+			time.Sleep(plan.pause)
+		}
+		batch := targets[start:min(start+plan.batchSize, len(targets))]
+		for _, c := range batch {
+			h.setClusterStatus(wd, statuses[c], "InProgress", "0%", "Deploying")
+		}
+		updateFn(wd)
+
+		batchCtx, cancel := context.WithTimeout(rolloutCtx, reconcileTimeout+plan.healthTimeout)
+		result, err := deployer.DeployWorkload(batchCtx, spec.SourceCluster, spec.SourceNamespace,
+			spec.WorkloadRef.Name, batch, replicas, opts)
+		if err != nil {
+			slog.Error("[reconcile] rolling batch deployment failed",
+				"name", wd.Name, "clusters", batch, "error", err)
+		}
+		deployed := make(map[string]bool, len(batch))
+		if result != nil {
+			for _, c := range result.DeployedTo {
+				deployed[c] = true
+			}
+		}
+		var checking []string
+		var failed []string
+		for _, c := range batch {
+			if !deployed[c] {
+				h.setClusterStatus(wd, statuses[c], "Failed", "0%", "Deployment failed")
+				failed = append(failed, c)
+				continue
+			}
+			h.setClusterStatus(wd, statuses[c], "InProgress", "50%", "Waiting for the workload to become ready")
+			checking = append(checking, c)
+		}
+		updateFn(wd)
+
+		healthErrs := h.checkBatchHealth(batchCtx, wd, workload, plan.healthTimeout, checking, statuses)
+		cancel()
+		for _, c := range checking {
+			if err := healthErrs[c]; err != nil {
+				h.setClusterStatus(wd, statuses[c], "Failed", statuses[c].Progress, "Health check failed: "+err.Error())
+				failed = append(failed, c)
+			} else {
+				h.setClusterStatus(wd, statuses[c], "Complete", "100%", "Deployed successfully")
+			}
+		}
+
+		if len(failed) > 0 {
+			halted := fmt.Sprintf("Rollout halted after %s failed", failed[0])
+			for _, c := range targets[start+len(batch):] {
+				h.setClusterStatus(wd, statuses[c], phaseSkipped, "", halted)
+			}
+			updateFn(wd)
+			slog.Info("[reconcile] rolling rollout halted",
+				"name", wd.Name, "failed", failed, "skipped", len(targets)-start-len(batch))
+			return true
+		}
+		updateFn(wd)
+	}
+	return false
+}
+
+// checkBatchHealth waits for the workload to roll out on each cluster in
+// parallel and returns the clusters whose check failed. Kinds without a
+// readiness check pass once deployed.
+func (h *ConsolePersistenceHandlers) checkBatchHealth(
+	ctx context.Context,
+	wd *v1alpha1.WorkloadDeployment,
+	workload *v1alpha1.ManagedWorkload,
+	timeout time.Duration,
+	clusters []string,
+	statuses map[string]*v1alpha1.ClusterRolloutStatus,
+) map[string]error {
+	var checker workloadHealthChecker = h.healthChecker
+	if checker == nil && h.k8sClient != nil {
+		checker = h.k8sClient
+	}
+	ref := workload.Spec.WorkloadRef
+	if checker == nil || !healthCheckableKinds[ref.Kind] {
+		slog.Info("[reconcile] no health check for rolling rollout",
+			"name", wd.Name, "kind", ref.Kind)
+		return nil
+	}
+
+	var mu sync.Mutex
+	errs := make(map[string]error)
+	var wg sync.WaitGroup
+	for _, c := range clusters {
+		cs := *statuses[c]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := waitWorkloadRolledOut(ctx, checker, c, ref.Kind, workload.Spec.SourceNamespace, ref.Name, timeout,
+				func(r k8s.WorkloadReadiness) {
+					cs.Progress = readinessProgress(r)
+					cs.Message = fmt.Sprintf("%d/%d pods updated and ready", min(r.Ready, r.Updated), r.Desired)
+					h.publishClusterStatus(wd, cs)
+				})
+			if err != nil {
+				mu.Lock()
+				errs[c] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// readinessProgress maps readiness onto the second half of a cluster's
+// progress; the first half is the deploy itself.
+func readinessProgress(r k8s.WorkloadReadiness) string {
+	if r.Desired <= 0 || r.RolledOut() {
+		return "100%"
+	}
+	done := min(r.Ready, r.Updated)
+	return fmt.Sprintf("%d%%", 50+50*done/r.Desired)
+}
+
+// waitWorkloadRolledOut polls a workload until every desired pod is updated
+// and ready, calling onProgress whenever the counts change. Errors reading
+// the workload are retried until the timeout, since it may not be visible
+// right after the deploy.
+func waitWorkloadRolledOut(
+	ctx context.Context,
+	checker workloadHealthChecker,
+	cluster, kind, namespace, name string,
+	timeout time.Duration,
+	onProgress func(k8s.WorkloadReadiness),
+) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(rolloutHealthPollInterval)
+	defer ticker.Stop()
+
+	var last *k8s.WorkloadReadiness
+	var lastErr error
+	for {
+		r, err := checker.GetWorkloadReadiness(ctx, cluster, kind, namespace, name)
+		if err == nil {
+			lastErr = nil
+			if last == nil || *last != r {
+				last = &r
+				onProgress(r)
+			}
+			if r.RolledOut() {
+				return nil
+			}
+		} else {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			switch {
+			case lastErr != nil:
+				return lastErr
+			case last != nil:
+				return fmt.Errorf("%d/%d pods updated and ready after %s", min(last.Ready, last.Updated), last.Desired, timeout)
+			default:
+				return ctx.Err()
+			}
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/api/transport"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// batchDeployer implements workloadDeployer, recording each call's targets
+// and failing the clusters in fail.
+type batchDeployer struct {
+	fail    map[string]bool
+	batches [][]string
+}
+
+func (f *batchDeployer) DeployWorkload(_ context.Context, _, _, _ string,
+	targets []string, _ int32, _ *k8s.DeployOptions,
+) (*v1alpha1.DeployResponse, error) {
+	f.batches = append(f.batches, append([]string(nil), targets...))
+	resp := &v1alpha1.DeployResponse{}
+	for _, c := range targets {
+		if f.fail[c] {
+			resp.FailedClusters = append(resp.FailedClusters, c)
+		} else {
+			resp.DeployedTo = append(resp.DeployedTo, c)
+		}
+	}
+	return resp, nil
+}
+
+// fakeHealthChecker implements workloadHealthChecker. Clusters in unready
+// never roll out; the rest report NotFound on their first poll.
+type fakeHealthChecker struct {
+	unready map[string]bool
+	mu      sync.Mutex
+	polled  map[string]int
+}
+
+func (f *fakeHealthChecker) GetWorkloadReadiness(_ context.Context, cluster, kind, namespace, name string) (k8s.WorkloadReadiness, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if kind != "Deployment" || namespace != "default" || name != "nginx" {
+		return k8s.WorkloadReadiness{}, fmt.Errorf("unexpected workload %s %s/%s", kind, namespace, name)
+	}
+	if f.polled == nil {
+		f.polled = make(map[string]int)
+	}
+	f.polled[cluster]++
+	if f.unready[cluster] {
+		return k8s.WorkloadReadiness{Desired: 3, Ready: 3, Updated: 1}, nil
+	}
+	if f.polled[cluster] == 1 {
+		return k8s.WorkloadReadiness{}, fmt.Errorf("deployments.apps %q not found", name)
+	}
+	return k8s.WorkloadReadiness{Desired: 3, Ready: 3, Updated: 3}, nil
+}
+
+// captureBackplane implements transport.Backplane and records published
+// message types.
+type captureBackplane struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (b *captureBackplane) Publish(_ context.Context, env transport.Envelope) error {
+	var msg Message
+	if err := json.Unmarshal(env.Message, &msg); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.messages = append(b.messages, msg)
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *captureBackplane) Subscribe(ctx context.Context, _ func(transport.Envelope)) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (b *captureBackplane) Close() error { return nil }
+
+// clusterEvents returns the cluster status events published for cluster.
+func (b *captureBackplane) clusterEvents(cluster string) []clusterStatusEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var events []clusterStatusEvent
+	for _, msg := range b.messages {
+		if msg.Type != WorkloadDeploymentClusterStatusType {
+			continue
+		}
+		raw, _ := json.Marshal(msg.Data)
+		var ev clusterStatusEvent
+		if json.Unmarshal(raw, &ev) == nil && ev.Cluster == cluster {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func setupRolloutEnv(t *testing.T, name string, targets []string, strategy string, rc *v1alpha1.RolloutConfig, opts ...reconcileOption) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	old := rolloutHealthPollInterval
+	rolloutHealthPollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { rolloutHealthPollInterval = old })
+
+	opts = append([]reconcileOption{withTargets(targets...), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Name = name
+		wd.Spec.Strategy = strategy
+		wd.Spec.RolloutConfig = rc
+		wd.Status.Phase = "Pending"
+	})}, opts...)
+	return newReconcileFixture(t, opts...)
+}
+
+func rolloutStatuses(wd *v1alpha1.WorkloadDeployment) map[string]v1alpha1.ClusterRolloutStatus {
+	m := make(map[string]v1alpha1.ClusterRolloutStatus, len(wd.Status.ClusterStatuses))
+	for _, cs := range wd.Status.ClusterStatuses {
+		m[cs.Cluster] = cs
+	}
+	return m
+}
+
+func TestReconcileDeployment_RollingUpdateBatches(t *testing.T) {
+	two := int32(2)
+	h, wd := setupRolloutEnv(t, "wd-rolling", []string{"cluster-a", "cluster-b", "cluster-c"},
+		v1alpha1.StrategyRollingUpdate,
+		&v1alpha1.RolloutConfig{MaxUnavailable: &two, PauseBetweenClusters: "20ms", HealthCheckTimeout: "1s"})
+
+	bp := &captureBackplane{}
+	h.hub = NewHub()
+	h.hub.AttachBackplane(bp)
+	t.Cleanup(h.hub.Close)
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+	checker := &fakeHealthChecker{}
+	h.healthChecker = checker
+
+	started := time.Now()
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a", "cluster-b"}, {"cluster-c"}}, deployer.batches)
+	assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond, "the rollout pauses between batches")
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	assert.Equal(t, "3/3 clusters", wd.Status.Progress)
+	for _, cs := range wd.Status.ClusterStatuses {
+		assert.Equal(t, "Complete", cs.Phase, "cluster %s", cs.Cluster)
+		assert.Equal(t, "100%", cs.Progress)
+		assert.NotNil(t, cs.StartedAt)
+		assert.NotNil(t, cs.CompletedAt)
+		assert.GreaterOrEqual(t, checker.polled[cs.Cluster], 2, "a NotFound poll is retried")
+	}
+
+	require.Eventually(t, func() bool {
+		events := bp.clusterEvents("cluster-c")
+		return len(events) > 0 && events[len(events)-1].Phase == "Complete"
+	}, 2*time.Second, 10*time.Millisecond)
+	var phases []string
+	for _, ev := range bp.clusterEvents("cluster-c") {
+		assert.Equal(t, "test-ns", ev.Namespace)
+		assert.Equal(t, "wd-rolling", ev.Name)
+		if len(phases) == 0 || phases[len(phases)-1] != ev.Phase {
+			phases = append(phases, ev.Phase)
+		}
+	}
+	assert.Equal(t, []string{"InProgress", "Complete"}, phases)
+}
+
+func TestReconcileDeployment_RollingUpdateHaltsOnUnhealthyCluster(t *testing.T) {
+	h, wd := setupRolloutEnv(t, "wd-halt", []string{"cluster-a", "cluster-b", "cluster-c"}, "",
+		&v1alpha1.RolloutConfig{HealthCheckTimeout: "50ms"})
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+	h.healthChecker = &fakeHealthChecker{unready: map[string]bool{"cluster-b": true}}
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a"}, {"cluster-b"}}, deployer.batches)
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Complete", statuses["cluster-a"].Phase)
+	assert.Equal(t, "Failed", statuses["cluster-b"].Phase)
+	assert.Contains(t, statuses["cluster-b"].Message, "Health check failed: 1/3 pods updated and ready")
+	assert.Equal(t, phaseSkipped, statuses["cluster-c"].Phase)
+	assert.Contains(t, statuses["cluster-c"].Message, "cluster-b")
+	assert.Nil(t, statuses["cluster-c"].StartedAt, "a skipped cluster never started")
+
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	require.NotEmpty(t, wd.Status.History)
+	assert.Equal(t, "Rollout halted: 1 succeeded, 1 failed, 1 skipped", wd.Status.History[0].Message)
+}
+
+func TestReconcileDeployment_RollingUpdateHaltsOnDeployFailure(t *testing.T) {
+	h, wd := setupRolloutEnv(t, "wd-deploy-fail", []string{"cluster-a", "cluster-b"}, "",
+		&v1alpha1.RolloutConfig{})
+	deployer := &batchDeployer{fail: map[string]bool{"cluster-a": true}}
+	h.deployer = deployer
+	h.healthChecker = &fakeHealthChecker{}
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a"}}, deployer.batches)
+	statuses := rolloutStatuses(wd)
+	assert.Equal(t, "Failed", statuses["cluster-a"].Phase)
+	assert.Equal(t, "Deployment failed", statuses["cluster-a"].Message)
+	assert.Equal(t, phaseSkipped, statuses["cluster-b"].Phase)
+	assert.Equal(t, "0/2 clusters", wd.Status.Progress)
+}
+
+func TestReconcileDeployment_RollingUpdateWithoutConfigDeploysAtOnce(t *testing.T) {
+	// The UI sets strategy RollingUpdate on every deployment; without a
+	// rolloutConfig the clusters are still deployed in one call.
+	h, wd := setupRolloutEnv(t, "wd-all", []string{"cluster-a", "cluster-b"}, v1alpha1.StrategyRollingUpdate, nil)
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Equal(t, [][]string{{"cluster-a", "cluster-b"}}, deployer.batches)
+	assert.Equal(t, "Complete", wd.Status.Phase)
+}
+
+func TestReconcileDeployment_InvalidRolloutConfig(t *testing.T) {
+	h, wd := setupRolloutEnv(t, "wd-invalid", []string{"cluster-a"}, "",
+		&v1alpha1.RolloutConfig{PauseBetweenClusters: "soon"})
+	deployer := &batchDeployer{}
+	h.deployer = deployer
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	assert.Empty(t, deployer.batches)
+	assert.Equal(t, "Failed", wd.Status.Phase)
+	require.NotEmpty(t, wd.Status.History)
+	assert.Contains(t, wd.Status.History[0].Message, "Invalid rollout config")
+	assert.Equal(t, "Failed", wd.Status.ClusterStatuses[0].Phase)
+}
+
+func TestReadinessProgress(t *testing.T) {
+	assert.Equal(t, "50%", readinessProgress(k8s.WorkloadReadiness{Desired: 4}))
+	assert.Equal(t, "75%", readinessProgress(k8s.WorkloadReadiness{Desired: 4, Ready: 3, Updated: 2}))
+	assert.Equal(t, "100%", readinessProgress(k8s.WorkloadReadiness{Desired: 4, Ready: 4, Updated: 4}))
+	assert.Equal(t, "100%", readinessProgress(k8s.WorkloadReadiness{}))
+}
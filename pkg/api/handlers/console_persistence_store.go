@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/api/transport"
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
 	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/kubestellar/console/pkg/safego"
@@ -109,21 +111,91 @@ func (h *ConsolePersistenceHandlers) StartWatcher(ctx context.Context) error {
 		return err
 	}
 
-	namespace := h.persistenceStore.GetNamespace()
-
-	h.watcher = k8s.NewConsoleWatcher(client, namespace, h.handleResourceEvent)
-	return h.watcher.Start(ctx)
+	for _, namespace := range h.persistenceStore.GetNamespaces() {
+		watcher := k8s.NewConsoleWatcher(client, namespace, h.handleResourceEvent)
+		if err := watcher.Start(ctx); err != nil {
+			h.stopWatchers()
+			return fmt.Errorf("failed to watch namespace %s: %w", namespace, err)
+		}
+		h.watchers = append(h.watchers, watcher)
+	}
+	// The watcher only reports changes after its initial list, so
+	// deployments queued before a restart are picked up here.
+	h.requeueQueuedDeployments(ctx)
+	h.checkExternalEdits(ctx)
+	h.startClusterGroupEvaluator(ctx)
+	h.startWorkloadHealthWatch(ctx)
+	return nil
 }
 
 // StopWatcher stops the console resource watcher
 func (h *ConsolePersistenceHandlers) StopWatcher() {
-	if h.watcher != nil {
-		h.watcher.Stop()
-		h.watcher = nil
+	h.stopWatchers()
+	h.stopQueuedDeployments()
+	h.stopClusterGroupEvaluator()
+	h.stopWorkloadHealthWatch()
+}
+
+// stopWatchers stops the watcher of every persistence namespace.
+func (h *ConsolePersistenceHandlers) stopWatchers() {
+	for _, watcher := range h.watchers {
+		watcher.Stop()
 	}
+	h.watchers = nil
 }
 
-// handleResourceEvent broadcasts resource changes to connected clients and,
+// ConsoleResourceChangedType is the WebSocket message type for console CR
+// watch events.
+const ConsoleResourceChangedType = "console_resource_changed"
+
+const (
+	// consoleResourceBatchWindow is how long watch events are held so a burst
+	// (e.g. mass pod churn rewriting workload status) is sent as one frame.
+	consoleResourceBatchWindow = 250 * time.Millisecond
+	// consoleResourceBatchMax flushes a batch early so a long storm still
+	// streams instead of arriving all at once.
+	consoleResourceBatchMax = 100
+)
+
+// ConsoleResourceBatching returns the hub batching config for
+// ConsoleResourceChangedType. Events for the same resource within a window
+// coalesce to the latest one.
+func ConsoleResourceBatching() transport.BatchConfig {
+	return transport.BatchConfig{
+		Window:    consoleResourceBatchWindow,
+		MaxEvents: consoleResourceBatchMax,
+		Key:       consoleResourceKey,
+	}
+}
+
+// consoleResourceKey identifies the resource an event is about. Events
+// relayed from another replica arrive as raw JSON.
+func consoleResourceKey(data any) string {
+	var ev k8s.ConsoleResourceEvent
+	switch d := data.(type) {
+	case k8s.ConsoleResourceEvent:
+		ev = d
+	case json.RawMessage:
+		var ref struct {
+			ResourceType string `json:"resourceType"`
+			Name         string `json:"name"`
+			Namespace    string `json:"namespace"`
+		}
+		if json.Unmarshal(d, &ref) != nil {
+			return ""
+		}
+		ev = k8s.ConsoleResourceEvent{ResourceType: ref.ResourceType, Name: ref.Name, Namespace: ref.Namespace}
+	default:
+		return ""
+	}
+	if ev.Name == "" {
+		return ""
+	}
+	return ev.ResourceType + "/" + ev.Namespace + "/" + ev.Name
+}
+
+// handleResourceEvent broadcasts resource changes to the connected clients
+// that may see their namespace and,
 // for newly created WorkloadDeployment resources, kicks off reconciliation.
 //
 // The reconcile-on-ADDED path is the Phase 2.5 replacement for the inline
@@ -133,18 +205,33 @@ func (h *ConsolePersistenceHandlers) StopWatcher() {
 // reconciles it as a proper controller. The reconciler still uses the pod SA
 // because it's system-internal (not user-initiated).
 func (h *ConsolePersistenceHandlers) handleResourceEvent(event k8s.ConsoleResourceEvent) {
-	if h.hub != nil {
-		msg := Message{
-			Type: "console_resource_changed",
-			Data: event,
+	h.broadcastToNamespace(event.Namespace, Message{
+		Type: ConsoleResourceChangedType,
+		Data: event,
+	})
+
+	// A ManagedWorkload may have been changed with kubectl instead of the
+	// console; record it rather than deploying it as if nothing happened.
+	if event.ResourceType == "ManagedWorkload" && event.Type != "DELETED" {
+		if mw, ok := event.Resource.(*v1alpha1.ManagedWorkload); ok {
+			safego.Go(func() { h.handleExternalEdit(mw) })
 		}
-		h.hub.BroadcastAll(msg)
+		return
 	}
 
 	// Trigger reconciliation on newly observed WorkloadDeployment CRs.
 	// Only act on ADDED events — MODIFIED covers status updates from the
-	// reconciler itself and would cause reconcile loops, DELETED is a no-op.
-	if event.Type != "ADDED" || event.ResourceType != "WorkloadDeployment" {
+	// reconciler itself and would cause reconcile loops. DELETED only drops
+	// a pending deployment-window resume.
+	if event.ResourceType != "WorkloadDeployment" {
+		return
+	}
+	if event.Type == "DELETED" {
+		h.cancelQueuedDeployment(event.Namespace, event.Name)
+		h.metrics.forget(event.Namespace, event.Name)
+		return
+	}
+	if event.Type != "ADDED" {
 		return
 	}
 	wd, ok := event.Resource.(*v1alpha1.WorkloadDeployment)
@@ -153,11 +240,11 @@ func (h *ConsolePersistenceHandlers) handleResourceEvent(event k8s.ConsoleResour
 			"type", event.ResourceType, "name", event.Name)
 		return
 	}
+	h.metrics.track(wd)
 	// Use a detached context with a wall-clock bound so reconciliation
 	// survives independently of the watcher's event dispatch goroutine and
 	// cannot run forever. 5 minutes matches the prior CreateWorkloadDeployment
 	// detached timeout.
-	const reconcileTimeout = 5 * time.Minute
 	reconcileCtx, reconcileCancel := context.WithTimeout(context.Background(), reconcileTimeout)
 	safego.Go(func() {
 		defer reconcileCancel()
@@ -167,11 +254,30 @@ func (h *ConsolePersistenceHandlers) handleResourceEvent(event k8s.ConsoleResour
 
 // reconcileDeployment handles the full lifecycle of deploying a workload to
 // target clusters. It:
-//  1. Resolves the ManagedWorkload referenced by workloadRef
-//  2. Resolves target clusters (from targetGroupRef or targetClusters)
-//  3. Deploys manifests to each target cluster via the multi-cluster client
-//  4. Updates WorkloadDeployment.Status with per-cluster progress
-//  5. Persists terminal state (Complete / Failed) — no retry on failure
+//  1. Checks the change metadata annotations against the project's change
+//     policy
+//  2. Resolves the ManagedWorkload referenced by workloadRef
+//  3. Resolves target clusters (from targetGroupRef or targetClusters) and
+//     splits the workload's replicas across them (see replicaSplit)
+//  4. Fails clusters that belong to a frozen ClusterGroup, directly or by
+//     inheritance
+//  5. Evaluates configured policies against the rendered manifests per
+//     target cluster; clusters with enforce-mode violations are not deployed
+//     and the outcome is recorded as the PolicyCheck condition
+//  6. Queues clusters outside their deployment windows
+//  7. Waits on each remaining target cluster for the deployments in
+//     dependsOn to be healthy there, failing the clusters a dependency failed
+//     on or did not become healthy on in time (see waitForDependencies);
+//     takes a Velero backup on each cluster left when preDeployBackup is
+//     set, failing the clusters whose backup fails, then marks the rest
+//     InProgress and deploys manifests to them via the multi-cluster
+//     client; a rolling deployment goes a batch at a time and halts on the
+//     first unhealthy batch (see rollOut), and a canary deploys only its
+//     current step's share of the clusters
+//  8. Updates WorkloadDeployment.Status with per-cluster progress
+//  9. Persists terminal state (Complete / Failed) and notifies the configured
+//     channels, Queued with a resume scheduled for the next window, or Paused
+//     until the canary's next step or promotion — no retry on failure
 func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd *v1alpha1.WorkloadDeployment) {
 	slog.Info("[ConsolePersistence] reconciling deployment",
 		"namespace", wd.Namespace, "name", wd.Name)
@@ -200,11 +306,37 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 		wd.ResourceVersion = updated.ResourceVersion
 	}
 
-	// Transition to InProgress
+	// A queued deployment resumes here once its window opens, and a canary
+	// at its next step; clusters already settled on an earlier pass keep
+	// their outcome.
+	resuming := wd.Status.Phase == phaseQueued || wd.Status.Phase == phasePaused
+
+	// Transition to InProgress. A fresh pass is a new attempt and restarts
+	// the lead time clock.
+	if !resuming || wd.Status.StartedAt == nil {
+		started := metav1.Now()
+		wd.Status.StartedAt = &started
+		wd.Status.CompletedAt = nil
+	}
 	wd.Status.Phase = "InProgress"
+	h.metrics.observePhase(wd, time.Now())
 	updateStatus(wd)
 
-	// ---- Step 1: Resolve the referenced ManagedWorkload ----
+	// ---- Step 1: Change metadata ----
+	policy, err := h.changePolicy(ctx, wd.Namespace)
+	if err != nil {
+		slog.Error("[reconcile] failed to load change policy",
+			"name", wd.Name, "error", err)
+		// Fail closed, like the policy gate.
+		h.setTerminalStatus(wd, "Failed", "Change policy could not be loaded", updateStatus)
+		return
+	}
+	if err := checkChangeMetadata(policy, wd.ChangeMetadata()); err != nil {
+		h.setTerminalStatus(wd, "Failed", "Change metadata rejected: "+err.Error(), updateStatus)
+		return
+	}
+
+	// ---- Step 2: Resolve the referenced ManagedWorkload ----
 	workload, err := h.resolveManagedWorkload(ctx, wd)
 	if err != nil {
 		slog.Error("[reconcile] failed to resolve ManagedWorkload",
@@ -212,8 +344,12 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 		h.setTerminalStatus(wd, "Failed", "Failed to resolve ManagedWorkload", updateStatus)
 		return
 	}
+	if reason := externalEditBlocksDeploy(workload); reason != "" {
+		h.setTerminalStatus(wd, "Failed", "ManagedWorkload "+workload.Name+" cannot be deployed: "+reason, updateStatus)
+		return
+	}
 
-	// ---- Step 2: Resolve target clusters ----
+	// ---- Step 3: Resolve target clusters ----
 	targets, err := h.resolveTargetClusters(ctx, wd)
 	if err != nil {
 		slog.Error("[reconcile] failed to resolve target clusters",
@@ -225,19 +361,231 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 		h.setTerminalStatus(wd, "Failed", "No target clusters resolved", updateStatus)
 		return
 	}
+	placement, err := h.evaluatePlacement(ctx, wd, workload, targets)
+	if err != nil {
+		slog.Error("[reconcile] failed to evaluate placement constraints",
+			"name", wd.Name, "error", err)
+		// Fail closed: a required constraint must not be skipped.
+		h.setTerminalStatus(wd, "Failed", "Placement constraints could not be evaluated", updateStatus)
+		return
+	}
+	setPlacementCondition(wd, placement)
+	targets = placement.Allowed
+	if len(targets) == 0 {
+		h.setTerminalStatus(wd, "Failed", "No target cluster satisfies the required placement constraints", updateStatus)
+		return
+	}
+
+	// The split covers every target, so it stays the same across queued and
+	// canary passes.
+	split := h.replicaSplit(ctx, workload, targets)
 
 	// Initialize per-cluster statuses
+	settled := make(map[string]v1alpha1.ClusterRolloutStatus)
+	// prior holds the other clusters' statuses, whose retry attempts carry
+	// over to this pass.
+	prior := make(map[string]v1alpha1.ClusterRolloutStatus)
+	if resuming {
+		for _, cs := range wd.Status.ClusterStatuses {
+			if cs.Phase == "Complete" || cs.Phase == "Failed" {
+				settled[cs.Cluster] = cs
+			} else {
+				prior[cs.Cluster] = cs
+			}
+		}
+	}
+	pending := make([]string, 0, len(targets))
 	wd.Status.ClusterStatuses = make([]v1alpha1.ClusterRolloutStatus, len(targets))
 	for i, cluster := range targets {
+		if cs, ok := settled[cluster]; ok {
+			wd.Status.ClusterStatuses[i] = cs
+			continue
+		}
 		wd.Status.ClusterStatuses[i] = v1alpha1.ClusterRolloutStatus{
-			Cluster: cluster,
-			Phase:   "Pending",
+			Cluster:  cluster,
+			Phase:    "Pending",
+			Attempts: prior[cluster].Attempts,
+		}
+		if n, ok := split[cluster]; ok {
+			wd.Status.ClusterStatuses[i].Replicas = &n
 		}
+		pending = append(pending, cluster)
+	}
+	if !resuming {
+		wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
 	}
-	wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
 	updateStatus(wd)
 
-	// ---- Step 3: Deploy to each target cluster ----
+	// failUnsettled marks every cluster this pass was responsible for as
+	// Failed, so ClusterStatuses stay consistent with a terminal Failed phase.
+	failUnsettled := func(message string) {
+		now := metav1.Now()
+		for i := range wd.Status.ClusterStatuses {
+			cs := &wd.Status.ClusterStatuses[i]
+			if _, ok := settled[cs.Cluster]; ok {
+				continue
+			}
+			cs.Phase = "Failed"
+			cs.Message = message
+			cs.NextEligibleAt = nil
+			cs.CompletedAt = &now
+		}
+	}
+
+	deployOpts := &k8s.DeployOptions{
+		DeployedBy:      "console-reconciler",
+		NetworkPolicy:   workload.Spec.NetworkPolicy,
+		ClusterReplicas: split,
+		Manifest:        capturedManifest(workload),
+	}
+
+	// ---- Step 4: Cluster group freezes ----
+	frozen, err := h.frozenClusters(ctx, wd.Namespace, pending)
+	if err != nil {
+		slog.Error("[reconcile] freeze check failed",
+			"name", wd.Name, "error", err)
+		failUnsettled("Cluster group freeze could not be checked")
+		h.setTerminalStatus(wd, "Failed", "Freeze check failed: "+err.Error(), updateStatus)
+		return
+	}
+	if len(frozen) > 0 {
+		now := metav1.Now()
+		unfrozen := make([]string, 0, len(pending))
+		for i := range wd.Status.ClusterStatuses {
+			cs := &wd.Status.ClusterStatuses[i]
+			if _, ok := settled[cs.Cluster]; ok {
+				continue
+			}
+			reason, isFrozen := frozen[cs.Cluster]
+			if !isFrozen {
+				unfrozen = append(unfrozen, cs.Cluster)
+				continue
+			}
+			cs.Phase = "Failed"
+			cs.Progress = "0%"
+			cs.Message = reason
+			cs.CompletedAt = &now
+		}
+		pending = unfrozen
+		slog.Warn("[reconcile] clusters frozen by cluster group",
+			"name", wd.Name, "frozen", len(frozen), "targets", len(targets))
+		updateStatus(wd)
+		if len(pending) == 0 && len(settled) == 0 {
+			h.setTerminalStatus(wd, "Failed",
+				fmt.Sprintf("All %d clusters frozen", len(targets)), updateStatus)
+			return
+		}
+	}
+
+	// ---- Step 5: Policy gate ----
+	var blocked map[string][]string
+	deployTargets := pending
+	if h.policies != nil && len(pending) > 0 {
+		blocked, err = h.checkDeploymentPolicies(ctx, wd, workload, pending, deployOpts)
+		if err != nil {
+			slog.Error("[reconcile] policy check failed",
+				"name", wd.Name, "error", err)
+			// Fail closed: nothing is deployed when policies cannot be
+			// evaluated.
+			failUnsettled("Policy check could not run")
+			h.setTerminalStatus(wd, "Failed", "Policy check failed: "+err.Error(), updateStatus)
+			return
+		}
+		if len(blocked) > 0 {
+			now := metav1.Now()
+			deployTargets = make([]string, 0, len(pending))
+			for i := range wd.Status.ClusterStatuses {
+				cs := &wd.Status.ClusterStatuses[i]
+				if _, ok := settled[cs.Cluster]; ok {
+					continue
+				}
+				ids, isBlocked := blocked[cs.Cluster]
+				if !isBlocked {
+					deployTargets = append(deployTargets, cs.Cluster)
+					continue
+				}
+				cs.Phase = "Failed"
+				cs.Progress = "0%"
+				cs.Message = "Blocked by policy: " + strings.Join(ids, ", ")
+				cs.CompletedAt = &now
+			}
+			slog.Warn("[reconcile] clusters blocked by policy",
+				"name", wd.Name, "blocked", len(blocked), "targets", len(targets))
+		}
+		updateStatus(wd)
+		if len(deployTargets) == 0 && len(settled) == 0 {
+			h.setTerminalStatus(wd, "Failed",
+				fmt.Sprintf("All %d clusters blocked by policy", len(targets)), updateStatus)
+			return
+		}
+	}
+
+	// ---- Step 6: Deployment windows ----
+	queued, err := h.checkDeploymentWindows(ctx, wd, deployTargets, h.currentTime())
+	if err != nil {
+		slog.Error("[reconcile] deployment window check failed",
+			"name", wd.Name, "error", err)
+		// Fail closed, like the policy gate: a window that cannot be
+		// evaluated must not let a rollout through at the wrong time.
+		failUnsettled("Deployment window could not be evaluated")
+		h.setTerminalStatus(wd, "Failed", "Deployment window check failed: "+err.Error(), updateStatus)
+		return
+	}
+	var nextEligible time.Time
+	if len(queued) > 0 {
+		eligible := make([]string, 0, len(deployTargets))
+		for _, cluster := range deployTargets {
+			if _, ok := queued[cluster]; !ok {
+				eligible = append(eligible, cluster)
+			}
+		}
+		deployTargets = eligible
+		for i := range wd.Status.ClusterStatuses {
+			cs := &wd.Status.ClusterStatuses[i]
+			q, ok := queued[cs.Cluster]
+			if !ok {
+				continue
+			}
+			at := metav1.NewTime(q.next)
+			cs.Phase = phaseQueued
+			cs.Message = queuedMessage(q)
+			cs.NextEligibleAt = &at
+			if nextEligible.IsZero() || q.next.Before(nextEligible) {
+				nextEligible = q.next
+			}
+		}
+		slog.Info("[reconcile] clusters outside their deployment window",
+			"name", wd.Name, "queued", len(queued), "eligible", len(deployTargets))
+		updateStatus(wd)
+	}
+
+	// Clusters whose retry is not due yet keep waiting, like queued ones.
+	retryWait := pendingRetries(prior, deployTargets, h.currentTime())
+	if len(retryWait) > 0 {
+		due := make([]string, 0, len(deployTargets))
+		for _, cluster := range deployTargets {
+			if _, ok := retryWait[cluster]; !ok {
+				due = append(due, cluster)
+			}
+		}
+		deployTargets = due
+		for i := range wd.Status.ClusterStatuses {
+			cs := &wd.Status.ClusterStatuses[i]
+			if _, ok := retryWait[cs.Cluster]; ok {
+				prev := prior[cs.Cluster]
+				cs.Phase, cs.Message, cs.NextRetryAt = prev.Phase, prev.Message, prev.NextRetryAt
+			}
+		}
+		updateStatus(wd)
+	}
+	retries, retryErr := h.retryPolicies(ctx, wd, targets)
+	if retryErr != nil {
+		// Retries are best effort; the clusters are deployed to once.
+		slog.Warn("[reconcile] cannot resolve retry policies",
+			"name", wd.Name, "error", retryErr)
+	}
+
+	// ---- Step 7: Deploy to each eligible target cluster ----
 	deployer := h.deployer
 	if deployer == nil && h.k8sClient != nil {
 		deployer = h.k8sClient
@@ -246,16 +594,102 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 		slog.Error("[reconcile] k8sClient is nil, cannot deploy workload", "name", wd.Name)
 		// Mark every cluster as Failed so ClusterStatuses are consistent with
 		// the terminal Failed phase (not left in Pending).
-		now := metav1.Now()
-		for i := range wd.Status.ClusterStatuses {
-			wd.Status.ClusterStatuses[i].Phase = "Failed"
-			wd.Status.ClusterStatuses[i].Message = "Multi-cluster client not configured"
-			wd.Status.ClusterStatuses[i].CompletedAt = &now
-		}
+		failUnsettled("Multi-cluster client not configured")
 		wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
 		h.setTerminalStatus(wd, "Failed", "Internal error: multi-cluster client not configured", updateStatus)
 		return
 	}
+	plan, err := newRolloutPlan(wd.Spec)
+	if err != nil {
+		failUnsettled("Invalid rollout config")
+		wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
+		h.setTerminalStatus(wd, "Failed", "Invalid rollout config: "+err.Error(), updateStatus)
+		return
+	}
+	canary, err := newCanaryPlan(wd.Spec)
+	if err != nil {
+		failUnsettled("Invalid canary config")
+		wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
+		h.setTerminalStatus(wd, "Failed", "Invalid canary config: "+err.Error(), updateStatus)
+		return
+	}
+	if err := validatePreDeployBackup(wd.Spec.PreDeployBackup); err != nil {
+		failUnsettled("Invalid pre-deploy backup config")
+		wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
+		h.setTerminalStatus(wd, "Failed", "Invalid pre-deploy backup config: "+err.Error(), updateStatus)
+		return
+	}
+
+	// A canary step deploys to its weight's share of the targets and holds
+	// back the rest until a later step.
+	var canaryHeld map[string]bool
+	var canaryStep, canaryWeight int
+	if canary != nil {
+		done := 0
+		for _, cs := range settled {
+			if cs.Phase == "Complete" {
+				done++
+			}
+		}
+		canaryStep, canaryWeight = canary.nextStep(wd.Status.CanaryStatus)
+		deployTargets, canaryHeld = canaryBatch(len(targets), done, deployTargets, canaryWeight)
+		for i := range wd.Status.ClusterStatuses {
+			cs := &wd.Status.ClusterStatuses[i]
+			if canaryHeld[cs.Cluster] {
+				cs.Message = fmt.Sprintf("Held back at canary weight %d%%", canaryWeight)
+			}
+		}
+		slog.Info("[reconcile] canary step",
+			"name", wd.Name, "step", canaryStep, "weight", canaryWeight,
+			"deploying", len(deployTargets), "held", len(canaryHeld))
+		if len(deployTargets) > 0 {
+			// The step's clusters go out as one health-checked batch.
+			plan = &rolloutPlan{batchSize: len(deployTargets), healthTimeout: canary.healthTimeout}
+		}
+	}
+
+	// A cluster is only deployed to once the deployments it depends on are
+	// healthy there.
+	var dependencyFailed map[string]string
+	if len(wd.Spec.DependsOn) > 0 && len(deployTargets) > 0 {
+		dependencyFailed, err = h.waitForDependencies(ctx, wd, deployTargets, updateStatus)
+		if err != nil {
+			slog.Error("[reconcile] dependency check failed",
+				"name", wd.Name, "error", err)
+			failUnsettled("Dependencies could not be resolved")
+			wd.Status.Progress = fmt.Sprintf("0/%d clusters", len(targets))
+			h.setTerminalStatus(wd, "Failed", "Dependency check failed: "+err.Error(), updateStatus)
+			return
+		}
+		if len(dependencyFailed) > 0 {
+			ready := make([]string, 0, len(deployTargets))
+			for _, c := range deployTargets {
+				if _, ok := dependencyFailed[c]; !ok {
+					ready = append(ready, c)
+				}
+			}
+			deployTargets = ready
+		}
+		// The wait had its own deadline; the deploy gets a fresh one.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), reconcileTimeout)
+		defer cancel()
+	}
+
+	// A cluster is only deployed to once its backup completed.
+	var backupFailed map[string]string
+	if wd.Spec.PreDeployBackup != nil && len(deployTargets) > 0 {
+		backupFailed = h.takePreDeployBackups(ctx, wd, workload, deployTargets, updateStatus)
+		if len(backupFailed) > 0 {
+			backedUp := make([]string, 0, len(deployTargets))
+			for _, c := range deployTargets {
+				if _, ok := backupFailed[c]; !ok {
+					backedUp = append(backedUp, c)
+				}
+			}
+			deployTargets = backedUp
+		}
+	}
 
 	ref := workload.Spec.WorkloadRef
 	replicas := int32(0)
@@ -263,21 +697,46 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 		replicas = *workload.Spec.Replicas
 	}
 
-	deployOpts := &k8s.DeployOptions{
-		DeployedBy: "console-reconciler",
-	}
+	var result *v1alpha1.DeployResponse
+	rolled := make(map[string]bool)
+	halted := false
+	if len(deployTargets) > 0 && plan != nil {
+		halted = h.rollOut(ctx, wd, workload, plan, deployer, deployTargets, replicas, deployOpts, updateStatus)
+		for _, c := range deployTargets {
+			rolled[c] = true
+		}
+	} else if len(deployTargets) > 0 {
+		// Report the clusters being deployed to as InProgress so the UI can
+		// tell them apart from clusters still waiting on a window.
+		started := metav1.Now()
+		inFlight := make(map[string]bool, len(deployTargets))
+		for _, c := range deployTargets {
+			inFlight[c] = true
+		}
+		for i := range wd.Status.ClusterStatuses {
+			cs := &wd.Status.ClusterStatuses[i]
+			if !inFlight[cs.Cluster] {
+				continue
+			}
+			cs.Phase = "InProgress"
+			cs.Progress = "0%"
+			cs.Message = "Deploying"
+			cs.StartedAt = &started
+		}
+		updateStatus(wd)
 
-	result, err := deployer.DeployWorkload(
-		ctx,
-		workload.Spec.SourceCluster,
-		workload.Spec.SourceNamespace,
-		ref.Name,
-		targets,
-		replicas,
-		deployOpts,
-	)
+		result, err = deployer.DeployWorkload(
+			ctx,
+			workload.Spec.SourceCluster,
+			workload.Spec.SourceNamespace,
+			ref.Name,
+			deployTargets,
+			replicas,
+			deployOpts,
+		)
+	}
 
-	// ---- Step 4: Map deploy results to per-cluster statuses ----
+	// ---- Step 8: Map deploy results to per-cluster statuses ----
 	deployedSet := make(map[string]bool)
 	failedSet := make(map[string]bool)
 
@@ -291,7 +750,7 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 	} else if err != nil {
 		// If DeployWorkload itself returned an error with no result,
 		// mark all clusters as failed.
-		for _, c := range targets {
+		for _, c := range deployTargets {
 			failedSet[c] = true
 		}
 	}
@@ -299,24 +758,131 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 	now := metav1.Now()
 	succeededCount := 0
 	failedCount := 0
+	queuedCount := 0
+	skippedCount := 0
+	heldCount := 0
+	warnedCount := 0
+	retryingCount := 0
+	var nextRetry time.Time
+	// failFast is set when a cluster's apply failed for good, which cancels
+	// the retries of the others.
+	failFast := false
+	// completed are the clusters deployed to on this pass.
+	var completed []string
 
 	for i := range wd.Status.ClusterStatuses {
 		cs := &wd.Status.ClusterStatuses[i]
+		if prev, ok := settled[cs.Cluster]; ok {
+			// Settled on an earlier pass, before the deployment was queued.
+			if prev.Phase == "Complete" {
+				succeededCount++
+			} else if retryWarned(prev, retries) {
+				warnedCount++
+			} else {
+				failedCount++
+			}
+			continue
+		}
+		if _, isFrozen := frozen[cs.Cluster]; isFrozen {
+			// Already marked Failed by the freeze gate.
+			failedCount++
+			continue
+		}
+		if _, isBlocked := blocked[cs.Cluster]; isBlocked {
+			// Already marked Failed by the policy stage.
+			failedCount++
+			continue
+		}
+		if _, ok := dependencyFailed[cs.Cluster]; ok {
+			// Already marked Failed while waiting on its dependencies.
+			failedCount++
+			continue
+		}
+		if _, ok := backupFailed[cs.Cluster]; ok {
+			// Already marked Failed by the pre-deploy backup.
+			failedCount++
+			continue
+		}
+		if canaryHeld[cs.Cluster] {
+			if halted {
+				h.setClusterStatus(wd, cs, phaseSkipped, "", "Canary halted before reaching this cluster")
+				skippedCount++
+				continue
+			}
+			heldCount++
+			continue
+		}
+		if rolled[cs.Cluster] {
+			// Settled by the rolling rollout.
+			switch cs.Phase {
+			case "Complete":
+				succeededCount++
+				completed = append(completed, cs.Cluster)
+			case phaseSkipped:
+				skippedCount++
+			default:
+				failedCount++
+			}
+			continue
+		}
+		if _, isQueued := queued[cs.Cluster]; isQueued {
+			if halted {
+				// A halted rollout does not resume when the window opens.
+				cs.NextEligibleAt = nil
+				h.setClusterStatus(wd, cs, phaseSkipped, "", "Rollout halted before its deployment window opened")
+				skippedCount++
+				continue
+			}
+			queuedCount++
+			continue
+		}
+		if at, ok := retryWait[cs.Cluster]; ok {
+			retryingCount++
+			nextRetry = earliest(nextRetry, at)
+			continue
+		}
+		policy, hasPolicy := retries[cs.Cluster]
 		cs.CompletedAt = &now
 		if deployedSet[cs.Cluster] {
+			if hasPolicy {
+				cs.Attempts++
+			}
 			cs.Phase = "Complete"
 			cs.Progress = "100%"
 			cs.Message = "Deployed successfully"
+			cs.NextRetryAt = nil
 			succeededCount++
+			completed = append(completed, cs.Cluster)
+			h.metrics.observeClusterRollout(cs)
 		} else if failedSet[cs.Cluster] {
-			cs.Phase = "Failed"
-			cs.Progress = "0%"
 			if err != nil {
 				slog.Error("[reconcile] cluster deployment failed",
 					"cluster", cs.Cluster, "name", wd.Name, "error", err)
 			}
+			if hasPolicy {
+				cs.Attempts++
+				if at, ok := scheduleClusterRetry(cs, policy, h.currentTime()); ok {
+					retryingCount++
+					nextRetry = earliest(nextRetry, at)
+					h.publishClusterStatus(wd, *cs)
+					continue
+				}
+			}
+			cs.Phase = "Failed"
+			cs.Progress = "0%"
 			cs.Message = "Deployment failed"
-			failedCount++
+			cs.NextRetryAt = nil
+			if cs.Attempts > 1 {
+				cs.Message = fmt.Sprintf("Deployment failed after %d attempts", cs.Attempts)
+			}
+			if retryWarned(*cs, retries) {
+				cs.Message += " (treated as a warning)"
+				warnedCount++
+			} else {
+				failedCount++
+				failFast = true
+			}
+			h.metrics.observeClusterRollout(cs)
 		} else {
 			// Not in either list — cluster was in targets but not reported by
 			// DeployWorkload in either deployedTo or failedClusters. Flag as
@@ -327,10 +893,43 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 		}
 	}
 
+	if failFast && retryingCount > 0 {
+		cancelRetries(wd, now)
+		failedCount += retryingCount
+		retryingCount = 0
+	}
+	// A warning only lets a deployment complete that reached a cluster
+	// and failed nowhere else.
+	if failedCount > 0 || succeededCount == 0 {
+		failedCount += warnedCount
+		warnedCount = 0
+	}
+
+	h.recordImageProvenance(ctx, wd, workload, completed)
+
 	wd.Status.Progress = fmt.Sprintf("%d/%d clusters", succeededCount, len(targets))
+	if halted {
+		h.metrics.observeAbort(abortReasonHalted)
+	}
 
-	// ---- Step 5: Determine terminal phase ----
-	if failedCount == 0 {
+	// ---- Step 9: Determine terminal phase ----
+	if canary != nil {
+		st := h.recordCanaryStep(wd, canary, canaryStep, canaryWeight, halted, succeededCount, failedCount, heldCount)
+		if st.Phase == v1alpha1.CanaryPhaseProgressing || st.Phase == v1alpha1.CanaryPhaseAwaitingPromotion {
+			h.setPausedStatus(wd, updateStatus)
+			return
+		}
+	}
+	if skippedCount > 0 {
+		h.setTerminalStatus(wd, "Failed",
+			fmt.Sprintf("Rollout halted: %d succeeded, %d failed, %d skipped", succeededCount, failedCount, skippedCount), updateStatus)
+	} else if queuedCount > 0 || retryingCount > 0 {
+		h.setQueuedStatus(wd, earliest(nextEligible, nextRetry),
+			waitingMessage(queuedCount, retryingCount), updateStatus)
+	} else if failedCount == 0 && warnedCount > 0 {
+		h.setTerminalStatus(wd, "Complete",
+			fmt.Sprintf("%d clusters deployed successfully, %d failed with warnings", succeededCount, warnedCount), updateStatus)
+	} else if failedCount == 0 {
 		h.setTerminalStatus(wd, "Complete",
 			fmt.Sprintf("All %d clusters deployed successfully", succeededCount), updateStatus)
 	} else if succeededCount > 0 {
@@ -348,7 +947,6 @@ func (h *ConsolePersistenceHandlers) reconcileDeployment(ctx context.Context, wd
 	}
 }
 
-
 // maxDeploymentHistory is the maximum number of history entries kept per workload deployment.
 // This prevents unbounded growth that could exceed the etcd object-size limit (1.5 MB).
 const maxDeploymentHistory = 50
@@ -362,6 +960,9 @@ func (h *ConsolePersistenceHandlers) setTerminalStatus(
 	now := metav1.Now()
 	wd.Status.Phase = phase
 	wd.Status.CompletedAt = &now
+	wd.Status.NextEligibleAt = nil
+	h.metrics.observePhase(wd, now.Time)
+	h.metrics.observeCompletion(wd)
 
 	// Compute next revision number
 	nextRevision := 1
@@ -371,13 +972,15 @@ func (h *ConsolePersistenceHandlers) setTerminalStatus(
 		}
 	}
 
-	wd.Status.History = append(wd.Status.History, v1alpha1.DeploymentHistoryEntry{
+	entry := v1alpha1.DeploymentHistoryEntry{
 		Revision:    nextRevision,
 		StartedAt:   wd.Status.StartedAt,
 		CompletedAt: &now,
 		Phase:       phase,
 		Message:     message,
-	})
+		Change:      wd.ChangeMetadata(),
+	}
+	wd.Status.History = append(wd.Status.History, entry)
 
 	// Cap history to prevent unbounded growth on flapping workloads
 	if len(wd.Status.History) > maxDeploymentHistory {
@@ -387,6 +990,7 @@ func (h *ConsolePersistenceHandlers) setTerminalStatus(
 	slog.Info("[reconcile] deployment reached terminal state",
 		"name", wd.Name, "phase", phase, "message", message)
 	updateFn(wd)
+	h.notifyDeploymentOutcome(wd, entry)
 }
 
 // resolveManagedWorkload fetches the ManagedWorkload referenced by the
@@ -426,11 +1030,19 @@ func (h *ConsolePersistenceHandlers) resolveManagedWorkload(
 func (h *ConsolePersistenceHandlers) resolveTargetClusters(
 	ctx context.Context, wd *v1alpha1.WorkloadDeployment,
 ) ([]string, error) {
+	// Keep the order clusters are listed in; rolling rollouts follow it.
 	clusterSet := make(map[string]bool)
+	var result []string
+	add := func(c string) {
+		if !clusterSet[c] {
+			clusterSet[c] = true
+			result = append(result, c)
+		}
+	}
 
 	// Add explicit target clusters
 	for _, c := range wd.Spec.TargetClusters {
-		clusterSet[c] = true
+		add(c)
 	}
 
 	// #7180/#7199 — Warn when both targetClusters and targetGroupRef are
@@ -469,13 +1081,8 @@ func (h *ConsolePersistenceHandlers) resolveTargetClusters(
 		// Re-evaluate the group to get fresh cluster matches
 		matched := h.evaluateClusterGroup(ctx, group)
 		for _, c := range matched {
-			clusterSet[c] = true
+			add(c)
 		}
 	}
-
-	result := make([]string, 0, len(clusterSet))
-	for c := range clusterSet {
-		result = append(result, c)
-	}
 	return result, nil
 }
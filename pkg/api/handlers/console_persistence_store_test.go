@@ -349,10 +349,10 @@ func TestStopWatcher(t *testing.T) {
 		})
 	})
 
-	t.Run("stop watcher sets watcher to nil", func(t *testing.T) {
+	t.Run("stop watcher clears the watchers", func(t *testing.T) {
 		h := &ConsolePersistenceHandlers{}
-		h.watcher = nil
+		h.watchers = []*k8s.ConsoleWatcher{k8s.NewConsoleWatcher(nil, "test-ns", nil)}
 		h.StopWatcher()
-		assert.Nil(t, h.watcher)
+		assert.Empty(t, h.watchers)
 	})
 }
@@ -215,7 +215,7 @@ func TestStartWatcher(t *testing.T) {
 
 		err := handler.StartWatcher(context.Background())
 		require.NoError(t, err)
-		assert.Nil(t, handler.watcher)
+		assert.Empty(t, handler.watchers)
 	})
 
 	t.Run("fails when persistence is enabled but no cluster client is configured", func(t *testing.T) {
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/operations"
+	"github.com/kubestellar/console/pkg/safego"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PersistenceSyncType is the WebSocket message type of the report of a
+// completed POST /api/persistence/sync.
+const PersistenceSyncType = "persistence_sync"
+
+// PersistenceSyncOperation is the operation kind of an asynchronous sync.
+const PersistenceSyncOperation = "persistence.sync"
+
+// persistenceSyncTimeout bounds listing the console resources and checking
+// every deployment on its target clusters. Reconciles started by the sync
+// run on their own reconcileTimeout.
+const persistenceSyncTimeout = 2 * time.Minute
+
+// Drift reasons of a driftedCluster.
+const (
+	// driftMissing is a cluster the deployment completed on that no longer
+	// runs the workload.
+	driftMissing = "missing"
+	// driftNotDeployed is a current target, e.g. a cluster that joined the
+	// target group, the deployment never deployed to.
+	driftNotDeployed = "notDeployed"
+	// driftNotReady is a cluster whose workload exists but is not rolled
+	// out. It is reported only; a redeploy would not fix it.
+	driftNotReady = "notReady"
+	// driftReplicas is a cluster whose replica count no longer matches the
+	// workload's replica distribution, e.g. after its target group changed.
+	driftReplicas = "replicas"
+	// driftPlacement is a cluster the deployment completed on that a
+	// required placement constraint now rules out, e.g. after a workload it
+	// must not share a cluster with was deployed there. It is reported
+	// only; a redeploy does not remove the workload.
+	driftPlacement = "placement"
+)
+
+// persistenceSyncReport is the result of a sync, returned to the caller and
+// broadcast as a PersistenceSyncType message.
+type persistenceSyncReport struct {
+	SyncedAt            time.Time `json:"syncedAt"`
+	ActiveCluster       string    `json:"activeCluster"`
+	Namespace           string    `json:"namespace"`
+	ManagedWorkloads    int       `json:"managedWorkloads"`
+	ClusterGroups       int       `json:"clusterGroups"`
+	WorkloadDeployments int       `json:"workloadDeployments"`
+	// ChangedGroups are the groups whose members changed on re-evaluation.
+	ChangedGroups []string         `json:"changedGroups"`
+	Drift         []driftedCluster `json:"drift"`
+	// Reconciled are the deployments redeployed to repair their drift.
+	Reconciled []string `json:"reconciled"`
+	// Errors are the deployments or clusters that could not be checked.
+	Errors []string `json:"errors"`
+}
+
+// driftedCluster is a target cluster whose state differs from what its
+// WorkloadDeployment recorded.
+type driftedCluster struct {
+	Deployment string `json:"deployment"`
+	Cluster    string `json:"cluster"`
+	Reason     string `json:"reason"`
+	Message    string `json:"message,omitempty"`
+}
+
+// SetOperationManager lets POST /api/persistence/sync run in the background
+// when called with async=true. With a nil manager (the default) syncs always
+// run within the request.
+func (h *ConsolePersistenceHandlers) SetOperationManager(m *operations.Manager) {
+	h.operations = m
+}
+
+// SyncNow re-lists every console resource of the selected persistence
+// namespace from the active persistence cluster, re-evaluates ClusterGroup
+// membership, checks completed deployments against their target clusters
+// and redeploys the ones that drifted. The report is returned and broadcast
+// to the connected clients that may see the namespace.
+// With async=true the sync runs as a PersistenceSyncOperation instead and
+// the response is the operation to poll.
+// POST /api/persistence/sync
+func (h *ConsolePersistenceHandlers) SyncNow(c *fiber.Ctx) error {
+	if err := h.RequireAdmin(c); err != nil {
+		return err
+	}
+
+	if !h.persistenceStore.IsEnabled() {
+		return localizedError(c, 400, "persistence.notEnabled")
+	}
+	namespace, err := h.requestNamespace(c)
+	if namespace == "" {
+		return err
+	}
+
+	if c.QueryBool("async") && h.operations != nil {
+		spec := operations.Spec{Kind: PersistenceSyncOperation, Exclusive: true, Timeout: persistenceSyncTimeout}
+		return startOperation(c, h.operations, spec, func(ctx context.Context, progress operations.Reporter) (any, error) {
+			report, err := h.syncConsoleResources(ctx, namespace, progress)
+			if err != nil {
+				slog.Warn("[ConsolePersistence] sync failed", "error", err)
+				return nil, errors.New("failed to list console resources")
+			}
+			return report, nil
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), persistenceSyncTimeout)
+	defer cancel()
+	report, err := h.syncConsoleResources(ctx, namespace, nil)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] sync failed", "error", err)
+		return localizedError(c, fiber.StatusServiceUnavailable, "persistence.listFailed")
+	}
+	return c.JSON(report)
+}
+
+// syncConsoleResources performs a sync of the console resources in
+// namespace, reporting the deployments checked to progress if it is not
+// nil. It fails only when the console resources cannot be listed; problems
+// with single deployments or clusters are listed in the report.
+func (h *ConsolePersistenceHandlers) syncConsoleResources(ctx context.Context, namespace string, progress operations.Reporter) (*persistenceSyncReport, error) {
+	client, activeCluster, err := h.persistenceStore.GetActiveClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	persistence := k8s.NewConsolePersistence(client)
+
+	workloads, err := persistence.ListManagedWorkloads(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := persistence.ListClusterGroups(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &persistenceSyncReport{
+		ActiveCluster:    activeCluster,
+		Namespace:        namespace,
+		ManagedWorkloads: len(workloads),
+		ClusterGroups:    len(groups),
+		ChangedGroups:    []string{},
+		Drift:            []driftedCluster{},
+		Reconciled:       []string{},
+		Errors:           []string{},
+	}
+	// Membership first, so deployments are checked against current targets.
+	if changed := h.reevaluateClusterGroups(ctx, namespace); changed != nil {
+		report.ChangedGroups = changed
+	}
+
+	deployments, err := persistence.ListWorkloadDeployments(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	report.WorkloadDeployments = len(deployments)
+	for i := range deployments {
+		if progress != nil {
+			progress.Report(operations.Progress{Completed: i, Total: len(deployments), Message: "Checking deployments for drift"})
+		}
+		wd := &deployments[i]
+		if wd.Status.Phase != "Complete" || wd.Spec.DryRun || wd.Spec.Suspend {
+			continue
+		}
+		drift, unchecked, err := h.checkDeploymentDrift(ctx, wd)
+		if err != nil {
+			slog.Warn("[ConsolePersistence] cannot check deployment drift", "deployment", wd.Name, "error", err)
+			report.Errors = append(report.Errors, wd.Name+": cannot resolve workload or targets")
+			continue
+		}
+		for _, cluster := range unchecked {
+			report.Errors = append(report.Errors, wd.Name+": cannot check cluster "+cluster)
+		}
+		report.Drift = append(report.Drift, drift...)
+		if !repairableDrift(drift) {
+			continue
+		}
+		// Redeploying a finished canary would restart its steps; report it
+		// and leave the repair to a new deployment.
+		if wd.Spec.IsCanary() {
+			continue
+		}
+		report.Reconciled = append(report.Reconciled, wd.Name)
+		reconcileCtx, reconcileCancel := context.WithTimeout(context.Background(), reconcileTimeout)
+		safego.Go(func() {
+			defer reconcileCancel()
+			h.reconcileDeployment(reconcileCtx, wd)
+		})
+	}
+
+	report.SyncedAt = h.currentTime()
+	h.persistenceStore.RecordSync(report.SyncedAt)
+	h.broadcastToNamespace(namespace, Message{Type: PersistenceSyncType, Data: report})
+	slog.Info("[ConsolePersistence] synced console resources",
+		"workloads", report.ManagedWorkloads, "groups", report.ClusterGroups,
+		"deployments", report.WorkloadDeployments, "drifted", len(report.Drift),
+		"reconciled", len(report.Reconciled))
+	return report, nil
+}
+
+// checkDeploymentDrift compares a completed deployment's cluster statuses
+// with its current targets, the placement constraints and replicas its
+// workload now puts on them, and the workload on each cluster. Clusters whose workload
+// cannot be read are returned as unchecked, not drifted.
+func (h *ConsolePersistenceHandlers) checkDeploymentDrift(ctx context.Context, wd *v1alpha1.WorkloadDeployment) (drift []driftedCluster, unchecked []string, err error) {
+	workload, err := h.resolveManagedWorkload(ctx, wd)
+	if err != nil {
+		return nil, nil, err
+	}
+	targets, err := h.resolveTargetClusters(ctx, wd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	completed := make(map[string]bool, len(wd.Status.ClusterStatuses))
+	assigned := make(map[string]*int32, len(wd.Status.ClusterStatuses))
+	for _, cs := range wd.Status.ClusterStatuses {
+		if cs.Phase == "Complete" {
+			completed[cs.Cluster] = true
+			assigned[cs.Cluster] = cs.Replicas
+		}
+	}
+
+	placement, err := h.evaluatePlacement(ctx, wd, workload, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+	ruledOut := map[string]bool{}
+	for _, v := range placement.Violations {
+		if v.Required && completed[v.Cluster] && !ruledOut[v.Cluster] {
+			ruledOut[v.Cluster] = true
+			drift = append(drift, driftedCluster{Deployment: wd.Name, Cluster: v.Cluster, Reason: driftPlacement, Message: v.Message})
+		}
+	}
+	targets = placement.Allowed
+	split := h.replicaSplit(ctx, workload, targets)
+
+	var checker workloadHealthChecker = h.healthChecker
+	if checker == nil && h.k8sClient != nil {
+		checker = h.k8sClient
+	}
+	ref := workload.Spec.WorkloadRef
+	canCheck := checker != nil && healthCheckableKinds[ref.Kind]
+
+	for _, cluster := range targets {
+		if !completed[cluster] {
+			drift = append(drift, driftedCluster{Deployment: wd.Name, Cluster: cluster, Reason: driftNotDeployed})
+			continue
+		}
+		if want, ok := split[cluster]; ok && (assigned[cluster] == nil || *assigned[cluster] != want) {
+			got := "no"
+			if assigned[cluster] != nil {
+				got = fmt.Sprint(*assigned[cluster])
+			}
+			drift = append(drift, driftedCluster{Deployment: wd.Name, Cluster: cluster, Reason: driftReplicas,
+				Message: fmt.Sprintf("%s replicas assigned, %d expected", got, want)})
+			continue
+		}
+		if !canCheck {
+			continue
+		}
+		r, err := checker.GetWorkloadReadiness(ctx, cluster, ref.Kind, workload.Spec.SourceNamespace, ref.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			drift = append(drift, driftedCluster{Deployment: wd.Name, Cluster: cluster, Reason: driftMissing,
+				Message: ref.Kind + " " + workload.Spec.SourceNamespace + "/" + ref.Name + " not found"})
+		case err != nil:
+			slog.Debug("[ConsolePersistence] cannot check deployment drift",
+				"deployment", wd.Name, "cluster", cluster, "error", err)
+			unchecked = append(unchecked, cluster)
+		case !r.RolledOut():
+			drift = append(drift, driftedCluster{Deployment: wd.Name, Cluster: cluster, Reason: driftNotReady,
+				Message: fmt.Sprintf("%d/%d pods updated and ready", min(r.Ready, r.Updated), r.Desired)})
+		}
+	}
+	return drift, unchecked, nil
+}
+
+// repairableDrift reports whether redeploying fixes any of drift.
+func repairableDrift(drift []driftedCluster) bool {
+	for _, d := range drift {
+		if d.Reason == driftMissing || d.Reason == driftNotDeployed || d.Reason == driftReplicas {
+			return true
+		}
+	}
+	return false
+}
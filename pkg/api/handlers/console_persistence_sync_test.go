@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// syncReadiness implements workloadHealthChecker with a fixed result per
+// cluster; clusters without one are rolled out.
+type syncReadiness map[string]error
+
+func (s syncReadiness) GetWorkloadReadiness(_ context.Context, cluster, _, _, _ string) (k8s.WorkloadReadiness, error) {
+	if err := s[cluster]; err != nil {
+		return k8s.WorkloadReadiness{}, err
+	}
+	return k8s.WorkloadReadiness{Desired: 1, Ready: 1, Updated: 1}, nil
+}
+
+// setupSyncEnv persists my-app and a Complete deployment of it targeting
+// targets that completed on the clusters in completed.
+func setupSyncEnv(t *testing.T, targets, completed []string, canary bool) *ConsolePersistenceHandlers {
+	t.Helper()
+	h, _ := newReconcileFixture(t, withTargets(targets...), withDeployment(func(wd *v1alpha1.WorkloadDeployment) {
+		wd.Name = "wd-sync"
+		wd.Status.Phase = "Complete"
+		if canary {
+			wd.Spec.Strategy = v1alpha1.StrategyCanary
+			wd.Spec.CanaryConfig = &v1alpha1.CanaryConfig{InitialWeight: 50}
+		}
+		for _, c := range completed {
+			wd.Status.ClusterStatuses = append(wd.Status.ClusterStatuses, v1alpha1.ClusterRolloutStatus{Cluster: c, Phase: "Complete"})
+		}
+	}))
+	h.deployer = &recordingDeployer{}
+	h.now = func() time.Time { return time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) }
+	return h
+}
+
+func TestSyncConsoleResources_RepairsDrift(t *testing.T) {
+	h := setupSyncEnv(t, []string{"cluster-a", "cluster-b", "cluster-c"}, []string{"cluster-a", "cluster-c"}, false)
+	h.healthChecker = syncReadiness{
+		"cluster-a": apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "nginx"),
+	}
+
+	report, err := h.syncConsoleResources(context.Background(), "test-ns", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.ManagedWorkloads)
+	assert.Equal(t, 1, report.WorkloadDeployments)
+	assert.ElementsMatch(t, []driftedCluster{
+		{Deployment: "wd-sync", Cluster: "cluster-a", Reason: driftMissing, Message: "Deployment default/nginx not found"},
+		{Deployment: "wd-sync", Cluster: "cluster-b", Reason: driftNotDeployed},
+	}, report.Drift)
+	assert.Equal(t, []string{"wd-sync"}, report.Reconciled)
+	assert.Empty(t, report.Errors)
+
+	status := h.persistenceStore.GetStatus(context.Background())
+	require.NotNil(t, status.LastSync)
+	assert.True(t, status.LastSync.Equal(report.SyncedAt))
+}
+
+func TestSyncConsoleResources_NoDrift(t *testing.T) {
+	h := setupSyncEnv(t, []string{"cluster-a"}, []string{"cluster-a"}, false)
+	h.healthChecker = syncReadiness{}
+
+	report, err := h.syncConsoleResources(context.Background(), "test-ns", nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Drift)
+	assert.Empty(t, report.Reconciled)
+}
+
+func TestSyncConsoleResources_UnreachableClusterIsNotDrift(t *testing.T) {
+	h := setupSyncEnv(t, []string{"cluster-a"}, []string{"cluster-a"}, false)
+	h.healthChecker = syncReadiness{"cluster-a": context.DeadlineExceeded}
+
+	report, err := h.syncConsoleResources(context.Background(), "test-ns", nil)
+	require.NoError(t, err)
+	assert.Empty(t, report.Drift)
+	assert.Empty(t, report.Reconciled)
+	assert.Equal(t, []string{"wd-sync: cannot check cluster cluster-a"}, report.Errors)
+}
+
+func TestSyncConsoleResources_CanaryDriftIsReportedOnly(t *testing.T) {
+	h := setupSyncEnv(t, []string{"cluster-a", "cluster-b"}, []string{"cluster-a"}, true)
+	h.healthChecker = syncReadiness{}
+
+	report, err := h.syncConsoleResources(context.Background(), "test-ns", nil)
+	require.NoError(t, err)
+	require.Len(t, report.Drift, 1)
+	assert.Equal(t, driftNotDeployed, report.Drift[0].Reason)
+	assert.Empty(t, report.Reconciled)
+}
+
+func TestSyncNow(t *testing.T) {
+	h := setupSyncEnv(t, []string{"cluster-a"}, []string{"cluster-a"}, false)
+	h.healthChecker = syncReadiness{}
+	app := fiber.New()
+	app.Post("/api/persistence/sync", h.SyncNow)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/persistence/sync", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var report persistenceSyncReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, "persist-cluster", report.ActiveCluster)
+	assert.Equal(t, "test-ns", report.Namespace)
+	assert.Equal(t, 1, report.WorkloadDeployments)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
@@ -150,11 +151,96 @@ func TestClusterMatchesFilter_UnknownField(t *testing.T) {
 	cluster := k8s.ClusterInfo{Name: "c"}
 
 	// Unknown fields should return false (not silently pass)
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "unknownField", Operator: "eq", Value: "value"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "unknownField", Operator: "neq", Value: "value"}))
+
+	// Node-backed fields do not match a cluster without node data
 	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "region", Operator: "eq", Value: "us-east-1"}))
 	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "zone", Operator: "eq", Value: "us-east-1a"}))
-	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "provider", Operator: "eq", Value: "aws"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "provider", Operator: "neq", Value: "eks"}))
 	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "version", Operator: "eq", Value: "1.28"}))
-	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "unknownField", Operator: "eq", Value: "value"}))
+}
+
+func TestClusterMatchesFilter_CPUCount(t *testing.T) {
+	h := newTestHandler()
+	cluster := k8s.ClusterInfo{Name: "c"}
+	health := &k8s.ClusterHealth{CpuCores: 32}
+
+	assert.True(t, h.clusterMatchesFilter(cluster, health, nil, v1alpha1.ClusterFilter{Field: "cpuCount", Operator: "gte", Value: "32"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, health, nil, v1alpha1.ClusterFilter{Field: "cpuCount", Operator: "gt", Value: "32"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "cpuCount", Operator: "gte", Value: "1"}))
+}
+
+func TestClusterMatchesFilter_RegionZone(t *testing.T) {
+	h := newTestHandler()
+	cluster := k8s.ClusterInfo{Name: "c"}
+	nodes := []k8s.NodeInfo{
+		{Name: "n1", Labels: map[string]string{"topology.kubernetes.io/region": "us-east-1", "topology.kubernetes.io/zone": "us-east-1a"}},
+		{Name: "n2", Labels: map[string]string{"failure-domain.beta.kubernetes.io/region": "us-east-1", "failure-domain.beta.kubernetes.io/zone": "us-east-1b"}},
+	}
+
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "region", Operator: "eq", Value: "us-east-1"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "region", Operator: "eq", Value: "eu-west-1"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "region", Operator: "regex", Value: "^us-"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "zone", Operator: "eq", Value: "us-east-1b"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "zone", Operator: "contains", Value: "east-1a"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "zone", Operator: "regex", Value: "1c$"}))
+}
+
+func TestClusterMatchesFilter_Provider(t *testing.T) {
+	h := newTestHandler()
+	eks := k8s.ClusterInfo{Name: "eks", Server: "https://ABC123.gr7.us-east-1.eks.amazonaws.com"}
+	onPrem := k8s.ClusterInfo{Name: "lab", Server: "https://10.0.0.1:6443"}
+	gkeNodes := []k8s.NodeInfo{{Name: "n1", Labels: map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}}}
+	plainNodes := []k8s.NodeInfo{{Name: "n1", Labels: map[string]string{"kubernetes.io/os": "linux"}}}
+
+	// The server URL is enough for managed services
+	assert.True(t, h.clusterMatchesFilter(eks, nil, nil, v1alpha1.ClusterFilter{Field: "provider", Operator: "eq", Value: "eks"}))
+	assert.True(t, h.clusterMatchesFilter(onPrem, nil, gkeNodes, v1alpha1.ClusterFilter{Field: "provider", Operator: "eq", Value: "gke"}))
+	assert.True(t, h.clusterMatchesFilter(onPrem, nil, plainNodes, v1alpha1.ClusterFilter{Field: "provider", Operator: "eq", Value: "kubernetes"}))
+	assert.True(t, h.clusterMatchesFilter(onPrem, nil, plainNodes, v1alpha1.ClusterFilter{Field: "provider", Operator: "neq", Value: "eks"}))
+	assert.True(t, h.clusterMatchesFilter(eks, nil, nil, v1alpha1.ClusterFilter{Field: "provider", Operator: "regex", Value: "^(eks|gke)$"}))
+}
+
+func TestClusterMatchesFilter_Version(t *testing.T) {
+	h := newTestHandler()
+	cluster := k8s.ClusterInfo{Name: "c"}
+	nodes := []k8s.NodeInfo{
+		{Name: "n1", KubeletVersion: "v1.29.4-eks-036c24b"},
+		{Name: "n2", KubeletVersion: "v1.30.1-eks-036c24b"},
+	}
+
+	// The lowest kubelet version (1.29.4) stands for the cluster
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "eq", Value: "1.29"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "eq", Value: "1.30"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "gte", Value: "1.29"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "gt", Value: "1.29"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "gt", Value: "1.29.3"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "lt", Value: "1.30"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "lte", Value: "1.29.4"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "contains", Value: "eks"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "version", Operator: "gt", Value: "latest"}))
+}
+
+func TestClusterMatchesFilter_LabelOperators(t *testing.T) {
+	h := newTestHandler()
+	cluster := k8s.ClusterInfo{Name: "c"}
+	nodes := []k8s.NodeInfo{{Name: "n1", Labels: map[string]string{"team": "payments-core"}}}
+
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "label", LabelKey: "team", Operator: "contains", Value: "payments"}))
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "label", LabelKey: "team", Operator: "regex", Value: "^payments-(core|edge)$"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "label", LabelKey: "team", Operator: "regex", Value: "^core"}))
+	// An invalid pattern matches nothing
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nodes, v1alpha1.ClusterFilter{Field: "label", LabelKey: "team", Operator: "regex", Value: "("}))
+}
+
+func TestClusterMatchesFilter_NameRegex(t *testing.T) {
+	h := newTestHandler()
+	cluster := k8s.ClusterInfo{Name: "prod-us-east"}
+
+	assert.True(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "name", Operator: "regex", Value: "^prod-"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "name", Operator: "regex", Value: "^staging-"}))
+	assert.False(t, h.clusterMatchesFilter(cluster, nil, nil, v1alpha1.ClusterFilter{Field: "name", Operator: "like", Value: "prod"}))
 }
 
 func TestClusterMatchesFilters_AllMatch(t *testing.T) {
@@ -225,3 +311,15 @@ func TestSetTerminalStatus_SanitizedMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestConsoleResourceKey(t *testing.T) {
+	ev := k8s.ConsoleResourceEvent{Type: "MODIFIED", ResourceType: "ManagedWorkload", Name: "web", Namespace: "apps"}
+	want := "ManagedWorkload/apps/web"
+
+	assert.Equal(t, want, consoleResourceKey(ev))
+	raw, err := json.Marshal(ev)
+	assert.NoError(t, err)
+	assert.Equal(t, want, consoleResourceKey(json.RawMessage(raw)), "events relayed from other replicas coalesce too")
+	assert.Empty(t, consoleResourceKey(json.RawMessage(`not json`)))
+	assert.Empty(t, consoleResourceKey("other"))
+}
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"context"
+	"log/slog"
 	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"log/slog"
-	
+
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/clusterexpr"
 	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/kubestellar/console/pkg/safego"
 )
@@ -18,65 +21,217 @@ import (
 // cancelled when the client disconnects (previously used context.Background,
 // which leaked goroutines on cancellation).
 func (h *ConsolePersistenceHandlers) evaluateClusterGroup(ctx context.Context, group *v1alpha1.ClusterGroup) []string {
+	matched, _ := h.matchClusterGroup(ctx, group, false)
+	return matched
+}
+
+// matchClusterGroup returns the sorted members of group, including the
+// members of its member groups. With explain set it also returns how the
+// group's own CEL expression evaluated for every cluster.
+func (h *ConsolePersistenceHandlers) matchClusterGroup(ctx context.Context, group *v1alpha1.ClusterGroup, explain bool) ([]string, []clusterexpr.Explanation) {
+	matched, explanations := h.matchOwnMembers(ctx, group, explain)
+	if len(group.Spec.MemberGroups) == 0 {
+		return matched, explanations
+	}
+	hierarchy, err := h.clusterGroupHierarchy(ctx, group.Namespace)
+	if err != nil {
+		slog.Warn("[ConsolePersistence] cannot resolve member groups",
+			"group", group.Name, "error", err)
+		return matched, explanations
+	}
+	set := make(map[string]bool, len(matched))
+	for _, c := range matched {
+		set[c] = true
+	}
+	for _, name := range hierarchy.Expand(group.Spec.MemberGroups) {
+		if name == group.Name {
+			continue
+		}
+		members, _ := h.matchOwnMembers(ctx, hierarchy.Group(name), false)
+		for _, c := range members {
+			set[c] = true
+		}
+	}
+	return sortedKeys(set), explanations
+}
+
+// matchOwnMembers returns the clusters group matches through its own static
+// members, filters and expression, ignoring member groups.
+func (h *ConsolePersistenceHandlers) matchOwnMembers(ctx context.Context, group *v1alpha1.ClusterGroup, explain bool) ([]string, []clusterexpr.Explanation) {
 	matched := make(map[string]bool)
 
 	// Add static members
 	for _, member := range group.Spec.StaticMembers {
 		matched[member] = true
 	}
+	if h.k8sClient == nil || !hasDynamicCriteria(group.Spec) {
+		return sortedKeys(matched), nil
+	}
 
-	// Apply dynamic filters
-	if h.k8sClient != nil && len(group.Spec.DynamicFilters) > 0 {
-		clusters, err := h.k8sClient.ListClusters(ctx)
-		if err == nil {
-			// Get cached health data (no extra network calls).
-			// GetCachedHealth always returns a non-nil map; individual entries
-			// may be nil for clusters that have not yet been health-checked.
-			healthMap := h.k8sClient.GetCachedHealth()
-
-			// Fetch nodes per cluster only when a filter requires node-level data.
-			// Queries run in parallel to avoid sequential latency on large fleets.
-			const maxConcurrentNodeQueries = 10 // cap parallel k8s API calls per reconcile
-			nodesByCluster := make(map[string][]k8s.NodeInfo)
-			if clusterFilterNeedsNodes(group.Spec.DynamicFilters) {
-				var wg sync.WaitGroup
-				var mu sync.Mutex
-				sem := make(chan struct{}, maxConcurrentNodeQueries)
+	var expr *clusterexpr.Expression
+	if group.Spec.Expression != "" {
+		var err error
+		expr, err = clusterexpr.Compile(group.Spec.Expression)
+		if err != nil {
+			// Groups are validated on create, so this only happens for CRs
+			// written outside the console. Match no dynamic members rather
+			// than ignoring the expression and widening the group.
+			slog.Warn("[ConsolePersistence] invalid cluster group expression",
+				"group", group.Name, "error", err)
+			return sortedKeys(matched), nil
+		}
+	}
 
-				for _, cluster := range clusters {
-					wg.Add(1)
-					sem <- struct{}{} // acquire semaphore slot
-					clusterName := cluster.Name
-					safego.GoWith("persistence/"+clusterName, func() {
-						defer wg.Done()
-						defer func() { <-sem }() // release semaphore slot
-						nodes, nodeErr := h.k8sClient.GetNodes(ctx, clusterName)
-						if nodeErr == nil {
-							mu.Lock()
-							nodesByCluster[clusterName] = nodes
-							mu.Unlock()
-						}
-					})
-				}
-				wg.Wait()
+	// Apply dynamic filters and the expression
+	needNodes := clusterFilterNeedsNodes(group.Spec.DynamicFilters) || (expr != nil && expr.NeedsNodes())
+	facts, err := h.gatherClusterFacts(ctx, needNodes)
+	if err != nil {
+		return sortedKeys(matched), nil
+	}
+	var explanations []clusterexpr.Explanation
+	for _, f := range facts {
+		filtersMatch := h.clusterMatchesFilters(f.info, f.health, f.nodes, group.Spec.DynamicFilters)
+		if expr == nil {
+			if filtersMatch {
+				matched[f.info.Name] = true
 			}
+			continue
+		}
+		metadata := clusterMetadata(f)
+		if explain {
+			explanations = append(explanations, expr.Explain(metadata))
+		}
+		if !filtersMatch {
+			continue
+		}
+		ok, evalErr := expr.Matches(metadata)
+		if evalErr != nil {
+			slog.Debug("[ConsolePersistence] cluster group expression failed",
+				"group", group.Name, "cluster", f.info.Name, "error", evalErr)
+		}
+		if ok {
+			matched[f.info.Name] = true
+		}
+	}
+	return sortedKeys(matched), explanations
+}
 
-			for _, cluster := range clusters {
-				health := healthMap[cluster.Name]
-				nodes := nodesByCluster[cluster.Name]
-				if h.clusterMatchesFilters(cluster, health, nodes, group.Spec.DynamicFilters) {
-					matched[cluster.Name] = true
+// hasDynamicCriteria reports whether membership depends on live cluster state.
+func hasDynamicCriteria(spec v1alpha1.ClusterGroupSpec) bool {
+	return len(spec.DynamicFilters) > 0 || spec.Expression != ""
+}
+
+// clusterFacts is the live state a group's dynamic criteria are evaluated against.
+type clusterFacts struct {
+	info   k8s.ClusterInfo
+	health *k8s.ClusterHealth
+	nodes  []k8s.NodeInfo
+}
+
+// gatherClusterFacts lists clusters with their cached health and, when
+// needNodes is set, their nodes.
+func (h *ConsolePersistenceHandlers) gatherClusterFacts(ctx context.Context, needNodes bool) ([]clusterFacts, error) {
+	clusters, err := h.k8sClient.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Get cached health data (no extra network calls).
+	// GetCachedHealth always returns a non-nil map; individual entries
+	// may be nil for clusters that have not yet been health-checked.
+	healthMap := h.k8sClient.GetCachedHealth()
+
+	// Fetch nodes per cluster only when a criterion requires node-level data.
+	// Queries run in parallel to avoid sequential latency on large fleets.
+	const maxConcurrentNodeQueries = 10 // cap parallel k8s API calls per reconcile
+	nodesByCluster := make(map[string][]k8s.NodeInfo)
+	if needNodes {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		sem := make(chan struct{}, maxConcurrentNodeQueries)
+
+		for _, cluster := range clusters {
+			wg.Add(1)
+			sem <- struct{}{} // acquire semaphore slot
+			clusterName := cluster.Name
+			safego.GoWith("persistence/"+clusterName, func() {
+				defer wg.Done()
+				defer func() { <-sem }() // release semaphore slot
+				nodes, nodeErr := h.k8sClient.GetNodes(ctx, clusterName)
+				if nodeErr == nil {
+					mu.Lock()
+					nodesByCluster[clusterName] = nodes
+					mu.Unlock()
 				}
+			})
+		}
+		wg.Wait()
+	}
+
+	facts := make([]clusterFacts, 0, len(clusters))
+	for _, cluster := range clusters {
+		facts = append(facts, clusterFacts{
+			info:   cluster,
+			health: healthMap[cluster.Name],
+			nodes:  nodesByCluster[cluster.Name],
+		})
+	}
+	return facts, nil
+}
+
+// clusterMetadata builds the CEL environment for one cluster. Fields backed
+// by health data are zero when the cluster has not been health-checked yet.
+func clusterMetadata(f clusterFacts) clusterexpr.Cluster {
+	c := clusterexpr.Cluster{
+		Name:      f.info.Name,
+		Healthy:   f.info.Healthy,
+		NodeCount: f.info.NodeCount,
+		PodCount:  f.info.PodCount,
+		GPUCount:  clusterGPUCount(f.nodes),
+		GPUTypes:  clusterGPUTypes(f.nodes),
+		Labels:    commonNodeLabels(f.nodes),
+		Nodes:     make([]clusterexpr.Node, 0, len(f.nodes)),
+	}
+	if f.health != nil {
+		c.Reachable = f.health.Reachable
+		c.CPUCores = f.health.CpuCores
+		c.MemoryGB = f.health.MemoryGB
+	}
+	for _, node := range f.nodes {
+		c.Nodes = append(c.Nodes, clusterexpr.Node{
+			Name:     node.Name,
+			Labels:   node.Labels,
+			GPUCount: node.GPUCount,
+			GPUType:  node.GPUType,
+		})
+	}
+	return c
+}
+
+// commonNodeLabels returns the labels every node carries with the same value.
+func commonNodeLabels(nodes []k8s.NodeInfo) map[string]string {
+	common := make(map[string]string)
+	if len(nodes) == 0 {
+		return common
+	}
+	for k, v := range nodes[0].Labels {
+		common[k] = v
+	}
+	for _, node := range nodes[1:] {
+		for k, v := range common {
+			if node.Labels[k] != v {
+				delete(common, k)
 			}
 		}
 	}
+	return common
+}
 
-	// Convert to slice
-	result := make([]string, 0, len(matched))
-	for name := range matched {
+func sortedKeys(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for name := range set {
 		result = append(result, name)
 	}
-
+	sort.Strings(result)
 	return result
 }
 
@@ -90,7 +245,8 @@ func (h *ConsolePersistenceHandlers) clusterMatchesFilters(cluster k8s.ClusterIn
 	return true
 }
 
-// clusterMatchesFilter checks if a cluster matches a single filter
+// clusterMatchesFilter checks if a cluster matches a single filter. Fields
+// backed by data the cluster does not have yet (health, nodes) never match.
 func (h *ConsolePersistenceHandlers) clusterMatchesFilter(cluster k8s.ClusterInfo, health *k8s.ClusterHealth, nodes []k8s.NodeInfo, filter v1alpha1.ClusterFilter) bool {
 	switch filter.Field {
 	case "name":
@@ -106,7 +262,7 @@ func (h *ConsolePersistenceHandlers) clusterMatchesFilter(cluster k8s.ClusterInf
 		return compareInt(int64(cluster.NodeCount), filter.Operator, filter.Value)
 	case "podCount":
 		return compareInt(int64(cluster.PodCount), filter.Operator, filter.Value)
-	case "cpuCores":
+	case "cpuCores", "cpuCount":
 		if health == nil {
 			return false
 		}
@@ -122,6 +278,22 @@ func (h *ConsolePersistenceHandlers) clusterMatchesFilter(cluster k8s.ClusterInf
 	case "gpuType":
 		types := clusterGPUTypes(nodes)
 		return compareStringSet(types, filter.Operator, filter.Value)
+	case "region":
+		return compareStringSet(k8s.ClusterRegions(nodes), filter.Operator, filter.Value)
+	case "zone":
+		return compareStringSet(k8s.ClusterZones(nodes), filter.Operator, filter.Value)
+	case "provider":
+		provider := k8s.ClusterProvider(cluster.Server, nodes)
+		if provider == "" {
+			return false
+		}
+		return matchString(provider, filter.Operator, filter.Value)
+	case "version":
+		version := k8s.ClusterVersion(nodes)
+		if version == "" {
+			return false
+		}
+		return compareVersion(version, filter.Operator, filter.Value)
 	case "label":
 		// Returns true when any node in the cluster carries a label whose key
 		// matches filter.LabelKey and whose value satisfies the operator/value pair.
@@ -134,15 +306,15 @@ func (h *ConsolePersistenceHandlers) clusterMatchesFilter(cluster k8s.ClusterInf
 		}
 		return false
 	default:
-		// Fields like "region", "zone", "provider", and "version" are referenced
-		// in the original issue but are not yet present in the ClusterInfo or
-		// ClusterHealth data models. Until those fields are added, filters on
-		// them intentionally return false so they do not silently match all clusters.
+		// Unknown fields return false so a typo does not silently match
+		// every cluster.
 		slog.Info("[ConsolePersistence] unsupported filter field, skipping cluster", "field", filter.Field, "cluster", cluster.Name)
 		return false
 	}
 }
 
+// matchString compares strings exactly. regex matches when the pattern
+// matches anywhere in actual; anchor it with ^ and $ for a full match.
 func matchString(actual, operator, expected string) bool {
 	switch operator {
 	case "eq":
@@ -151,22 +323,58 @@ func matchString(actual, operator, expected string) bool {
 		return actual != expected
 	case "contains":
 		return strings.Contains(actual, expected)
+	case "regex":
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(actual)
 	default:
 		return false
 	}
 }
 
 // clusterFilterNeedsNodes returns true if any filter in the slice requires
-// per-node data (GPU counts/types or node label matching).
+// per-node data (GPUs, topology, provider, kubelet version or node labels).
 func clusterFilterNeedsNodes(filters []v1alpha1.ClusterFilter) bool {
 	for _, f := range filters {
-		if f.Field == "gpuCount" || f.Field == "gpuType" || f.Field == "label" {
+		switch f.Field {
+		case "gpuCount", "gpuType", "region", "zone", "provider", "version", "label":
 			return true
 		}
 	}
 	return false
 }
 
+// compareVersion compares a kubelet version against a filter value with
+// k8s.CompareVersionPrefix, so "eq 1.29" matches every 1.29 patch release
+// and "gt 1.29" starts at 1.30. contains and regex match the raw string.
+func compareVersion(actual, op, value string) bool {
+	if op == "contains" || op == "regex" {
+		return matchString(actual, op, value)
+	}
+	cmp, err := k8s.CompareVersionPrefix(actual, value)
+	if err != nil {
+		return false
+	}
+	switch op {
+	case "eq":
+		return cmp == 0
+	case "neq":
+		return cmp != 0
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
 const floatEpsilon = 1e-9
 
 func compareBool(actual bool, op, value string) bool {
@@ -1,17 +1,30 @@
 package handlers
 
 // Tests for console_persistence_validation.go: matchString, clusterFilterNeedsNodes,
-// and evaluateClusterGroup (static-member and nil-client paths).
+// evaluateClusterGroup (static-member, nil-client and CEL expression paths)
+// and PreviewClusterGroup.
 // The clusterMatchesFilter/clusterMatchesFilters tests live in console_persistence_test.go.
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"sort"
 	"testing"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 // ---------- matchString ----------
@@ -31,7 +44,11 @@ func TestMatchString(t *testing.T) {
 		{"contains_match", "us-west-1", "contains", "west", true},
 		{"contains_no_match", "us-west-1", "contains", "east", false},
 		{"contains_empty_pattern", "us-west-1", "contains", "", true},
-		{"unknown_operator", "us-west-1", "regex", "us.*", false},
+		{"regex_match", "us-west-1", "regex", "^us-.*-1$", true},
+		{"regex_partial_match", "us-west-1", "regex", "west", true},
+		{"regex_no_match", "us-west-1", "regex", "^eu-", false},
+		{"regex_invalid", "us-west-1", "regex", "us-(", false},
+		{"unknown_operator", "us-west-1", "like", "us%", false},
 		{"empty_actual_eq", "", "eq", "", true},
 		{"empty_actual_neq", "", "neq", "something", true},
 	}
@@ -69,6 +86,21 @@ func TestClusterFilterNeedsNodes(t *testing.T) {
 			{Field: "name", Operator: "eq", Value: "prod"},
 			{Field: "gpuCount", Operator: "gt", Value: "0"},
 		}, true},
+		{"cpuCount_from_health", []v1alpha1.ClusterFilter{
+			{Field: "cpuCount", Operator: "gte", Value: "8"},
+		}, false},
+		{"region_needs_nodes", []v1alpha1.ClusterFilter{
+			{Field: "region", Operator: "eq", Value: "us-east-1"},
+		}, true},
+		{"zone_needs_nodes", []v1alpha1.ClusterFilter{
+			{Field: "zone", Operator: "eq", Value: "us-east-1a"},
+		}, true},
+		{"provider_needs_nodes", []v1alpha1.ClusterFilter{
+			{Field: "provider", Operator: "eq", Value: "gke"},
+		}, true},
+		{"version_needs_nodes", []v1alpha1.ClusterFilter{
+			{Field: "version", Operator: "gte", Value: "1.29"},
+		}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -208,3 +240,104 @@ func TestCompareStringSet(t *testing.T) {
 		})
 	}
 }
+
+// ---------- CEL expressions ----------
+
+// newExpressionTestHandler returns a handler whose client knows two clusters:
+// gpu-prod (two H100 nodes labelled tier=prod) and cpu-dev (one plain node).
+func newExpressionTestHandler(t *testing.T) *ConsolePersistenceHandlers {
+	t.Helper()
+	k8sClient, err := k8s.NewMultiClusterClient("")
+	require.NoError(t, err)
+	k8sClient.SetRawConfig(&api.Config{
+		Clusters: map[string]*api.Cluster{
+			"gpu-prod": {Server: "https://gpu-prod.example"},
+			"cpu-dev":  {Server: "https://cpu-dev.example"},
+		},
+		Contexts: map[string]*api.Context{
+			"gpu-prod": {Cluster: "gpu-prod", AuthInfo: "user"},
+			"cpu-dev":  {Cluster: "cpu-dev", AuthInfo: "user"},
+		},
+		AuthInfos: map[string]*api.AuthInfo{"user": {}},
+	})
+
+	gpuNode := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+				"tier": "prod", "nvidia.com/gpu.product": "H100",
+			}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				"nvidia.com/gpu": resource.MustParse("8"),
+			}},
+		}
+	}
+	k8sClient.InjectClient("gpu-prod", k8sfake.NewSimpleClientset(gpuNode("g1"), gpuNode("g2")))
+	k8sClient.InjectClient("cpu-dev", k8sfake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Labels: map[string]string{"tier": "dev"}},
+	}))
+	return &ConsolePersistenceHandlers{k8sClient: k8sClient}
+}
+
+func TestEvaluateClusterGroup_Expression(t *testing.T) {
+	h := newExpressionTestHandler(t)
+	group := &v1alpha1.ClusterGroup{Spec: v1alpha1.ClusterGroupSpec{
+		Expression: `cluster.labels["tier"] == "prod" && cluster.gpuCount >= 8`,
+	}}
+	assert.Equal(t, []string{"gpu-prod"}, h.evaluateClusterGroup(context.Background(), group))
+
+	// Filters and the expression are ANDed.
+	group.Spec.DynamicFilters = []v1alpha1.ClusterFilter{{Field: "name", Operator: "eq", Value: "cpu-dev"}}
+	assert.Empty(t, h.evaluateClusterGroup(context.Background(), group))
+}
+
+func TestEvaluateClusterGroup_InvalidExpressionMatchesStaticOnly(t *testing.T) {
+	h := newExpressionTestHandler(t)
+	group := &v1alpha1.ClusterGroup{Spec: v1alpha1.ClusterGroupSpec{
+		StaticMembers: []string{"cpu-dev"},
+		Expression:    `cluster.gpuCnt >= 8`,
+	}}
+	assert.Equal(t, []string{"cpu-dev"}, h.evaluateClusterGroup(context.Background(), group))
+}
+
+func TestPreviewClusterGroup(t *testing.T) {
+	h := newExpressionTestHandler(t)
+	h.persistenceStore = store.NewPersistenceStore("")
+	app := fiber.New()
+	app.Post("/api/persistence/groups/preview", h.PreviewClusterGroup)
+
+	post := func(spec v1alpha1.ClusterGroupSpec) *http.Response {
+		body, _ := json.Marshal(spec)
+		req := httptest.NewRequest(http.MethodPost, "/api/persistence/groups/preview", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := post(v1alpha1.ClusterGroupSpec{Expression: `cluster.gpuCount >= 8 && cluster.name.endsWith("-prod")`})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var preview clusterGroupPreview
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&preview))
+	assert.Equal(t, []string{"gpu-prod"}, preview.Clusters)
+	require.Len(t, preview.Explain, 2)
+	for _, exp := range preview.Explain {
+		require.Len(t, exp.Clauses, 2, "each top-level && operand is explained")
+		if exp.Cluster == "cpu-dev" {
+			assert.False(t, exp.Matched)
+			assert.Equal(t, "false", exp.Clauses[0].Value)
+			assert.Equal(t, "0", exp.Inputs["cluster.gpuCount"])
+		}
+	}
+
+	resp = post(v1alpha1.ClusterGroupSpec{Expression: `cluster.gpuCnt >= 8`})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = post(v1alpha1.ClusterGroupSpec{DynamicFilters: []v1alpha1.ClusterFilter{{Field: "gpuCnt", Operator: "gte", Value: "8"}}})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = post(v1alpha1.ClusterGroupSpec{DynamicFilters: []v1alpha1.ClusterFilter{{Field: "name", Operator: "regex", Value: "-prod$"}}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	preview = clusterGroupPreview{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&preview))
+	assert.Equal(t, []string{"gpu-prod"}, preview.Clusters)
+}
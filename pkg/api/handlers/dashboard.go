@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/cardschema"
 	"github.com/kubestellar/console/pkg/models"
 	"github.com/kubestellar/console/pkg/store"
 )
@@ -41,12 +43,21 @@ type CardExport struct {
 
 // DashboardHandler handles dashboard operations
 type DashboardHandler struct {
-	store store.Store
+	store    store.Store
+	hub      *Hub
+	presence *dashboardPresenceTracker
 }
 
-// NewDashboardHandler creates a new dashboard handler
-func NewDashboardHandler(s store.Store) *DashboardHandler {
-	return &DashboardHandler{store: s}
+// NewDashboardHandler creates a new dashboard handler. hub may be nil, in
+// which case presence and update notifications are not broadcast.
+func NewDashboardHandler(s store.Store, hub *Hub) *DashboardHandler {
+	return &DashboardHandler{store: s, hub: hub, presence: newDashboardPresenceTracker()}
+}
+
+func (h *DashboardHandler) broadcast(userID uuid.UUID, msg Message) {
+	if h.hub != nil {
+		h.hub.Broadcast(userID, msg)
+	}
 }
 
 // ListDashboards returns a page of dashboards for the current user.
@@ -153,7 +164,62 @@ func (h *DashboardHandler) CreateDashboard(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(dashboard)
 }
 
-// UpdateDashboard updates a dashboard
+// dashboardUpdateInput is the UpdateDashboard body. Version is the version
+// the client last read; when it is set (or sent as If-Match) the update is
+// rejected with 409 if the dashboard has changed since. Base optionally holds
+// the field values the client started editing from, which lets the conflict
+// response tell concurrent changes apart from the client's own.
+type dashboardUpdateInput struct {
+	Name      *string `json:"name"`
+	IsDefault *bool   `json:"is_default"`
+	Version   *int    `json:"version"`
+	Base      *struct {
+		Name      *string `json:"name"`
+		IsDefault *bool   `json:"is_default"`
+	} `json:"base"`
+}
+
+// DashboardFieldConflict is a field both the client and a concurrent writer
+// changed to different values.
+type DashboardFieldConflict struct {
+	Field  string `json:"field"`
+	Yours  any    `json:"yours"`
+	Theirs any    `json:"theirs"`
+}
+
+// DashboardConflict is the 409 body of a stale UpdateDashboard. Merged is the
+// client's update rebased onto Current: non-conflicting changes are kept and
+// conflicting fields take the client's value. Resending it as-is overwrites
+// the concurrent change; the client should resolve Conflicts first.
+type DashboardConflict struct {
+	Error     string                   `json:"error"`
+	Current   models.Dashboard         `json:"current"`
+	Conflicts []DashboardFieldConflict `json:"conflicts"`
+	Merged    fiber.Map                `json:"merged"`
+}
+
+// expectedVersion returns the version precondition from the body or the
+// If-Match header, or 0 when the client sent none.
+func (in dashboardUpdateInput) expectedVersion(c *fiber.Ctx) (int, error) {
+	if in.Version != nil {
+		if *in.Version <= 0 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid dashboard version")
+		}
+		return *in.Version, nil
+	}
+	raw := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(raw, "W/"), `"`))
+	if err != nil || v <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Invalid If-Match version")
+	}
+	return v, nil
+}
+
+// UpdateDashboard updates a dashboard. Without a version precondition the
+// write is unconditional, as before versions existed.
 func (h *DashboardHandler) UpdateDashboard(c *fiber.Ctx) error {
 	if IsDemoMode(c) {
 		return c.JSON(fiber.Map{"status": "ok", "source": "demo"})
@@ -175,31 +241,80 @@ func (h *DashboardHandler) UpdateDashboard(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusForbidden, "Access denied")
 	}
 
-	var input struct {
-		Name      *string `json:"name"`
-		IsDefault *bool   `json:"is_default"`
-	}
+	var input dashboardUpdateInput
 	if err := c.BodyParser(&input); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
 	}
+	expected, err := input.expectedVersion(c)
+	if err != nil {
+		return err
+	}
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Dashboard name cannot be empty")
+	}
+	if expected > 0 && expected != dashboard.Version {
+		return c.Status(fiber.StatusConflict).JSON(dashboardConflict(*dashboard, input))
+	}
 
 	if input.Name != nil {
-		if strings.TrimSpace(*input.Name) == "" {
-			return fiber.NewError(fiber.StatusBadRequest, "Dashboard name cannot be empty")
-		}
 		dashboard.Name = *input.Name
 	}
 	if input.IsDefault != nil {
 		dashboard.IsDefault = *input.IsDefault
 	}
 
-	if err := h.store.UpdateDashboard(c.UserContext(), dashboard); err != nil {
+	if expected > 0 {
+		err = h.store.UpdateDashboardIfVersion(c.UserContext(), dashboard, expected)
+	} else {
+		err = h.store.UpdateDashboard(c.UserContext(), dashboard)
+	}
+	if errors.Is(err, store.ErrDashboardVersionConflict) {
+		// Another write landed between our read and this one.
+		current, getErr := h.store.GetDashboard(c.UserContext(), dashboardID)
+		if getErr != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
+		}
+		if current == nil {
+			return fiber.NewError(fiber.StatusNotFound, "Dashboard not found")
+		}
+		return c.Status(fiber.StatusConflict).JSON(dashboardConflict(*current, input))
+	}
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update dashboard")
 	}
 
+	h.broadcast(userID, Message{Type: DashboardUpdatedMessageType, Data: dashboard})
 	return c.JSON(dashboard)
 }
 
+// dashboardConflict compares a stale update with the current dashboard. A
+// field conflicts when the client changes it to a value other than the
+// current one, unless Base shows the current value is what the client
+// started from (then only the client changed it).
+func dashboardConflict(current models.Dashboard, in dashboardUpdateInput) DashboardConflict {
+	out := DashboardConflict{
+		Error:     "Dashboard was changed by another session",
+		Current:   current,
+		Conflicts: []DashboardFieldConflict{},
+		Merged:    fiber.Map{"name": current.Name, "is_default": current.IsDefault, "version": current.Version},
+	}
+	if in.Name != nil {
+		out.Merged["name"] = *in.Name
+		unchanged := in.Base != nil && in.Base.Name != nil && *in.Base.Name == current.Name
+		if *in.Name != current.Name && !unchanged {
+			out.Conflicts = append(out.Conflicts, DashboardFieldConflict{Field: "name", Yours: *in.Name, Theirs: current.Name})
+		}
+	}
+	if in.IsDefault != nil {
+		out.Merged["is_default"] = *in.IsDefault
+		unchanged := in.Base != nil && in.Base.IsDefault != nil && *in.Base.IsDefault == current.IsDefault
+		if *in.IsDefault != current.IsDefault && !unchanged {
+			out.Conflicts = append(out.Conflicts, DashboardFieldConflict{Field: "is_default", Yours: *in.IsDefault, Theirs: current.IsDefault})
+		}
+	}
+	return out
+}
+
 // DeleteDashboard deletes a dashboard
 func (h *DashboardHandler) DeleteDashboard(c *fiber.Ctx) error {
 	if IsDemoMode(c) {
@@ -225,6 +340,7 @@ func (h *DashboardHandler) DeleteDashboard(c *fiber.Ctx) error {
 	if err := h.store.DeleteDashboard(c.UserContext(), dashboardID); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete dashboard")
 	}
+	h.presence.forget(dashboardID)
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -320,12 +436,22 @@ func (h *DashboardHandler) ImportDashboard(c *fiber.Ctx) error {
 		)
 	}
 
-	// Validate card types before persisting anything.
+	// Validate card types and configs before persisting anything. Config
+	// problems are collected across every card so the whole export can be
+	// fixed in one pass.
+	var violations []cardschema.Violation
 	for i, ce := range input.Cards {
 		if !isValidCardType(models.CardType(ce.CardType)) {
 			return fiber.NewError(fiber.StatusBadRequest,
 				fmt.Sprintf("card[%d]: unknown card_type %q", i, ce.CardType))
 		}
+		for _, v := range cardschema.Validate(models.CardType(ce.CardType), ce.Config) {
+			v.Field = strings.TrimSuffix(fmt.Sprintf("card[%d].config.%s", i, v.Field), ".")
+			violations = append(violations, v)
+		}
+	}
+	if len(violations) > 0 {
+		return invalidCardConfig(c, violations)
 	}
 
 	dashboard := &models.Dashboard{
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+)
+
+const (
+	// dashboardPresenceTTL is how long an editing session counts as present
+	// without a heartbeat. Clients heartbeat well inside this window, so an
+	// entry only expires when a tab closed without saying so.
+	dashboardPresenceTTL = 60 * time.Second
+	// maxDashboardEditors bounds the editing sessions tracked per dashboard.
+	maxDashboardEditors = 32
+	// maxDashboardSessionIDLen bounds the client-chosen session ID.
+	maxDashboardSessionIDLen = 128
+
+	// DashboardPresenceMessageType is broadcast to the owner's sessions when
+	// the set of sessions editing a dashboard changes.
+	DashboardPresenceMessageType = "dashboard_presence"
+	// DashboardUpdatedMessageType is broadcast after a dashboard update so
+	// other sessions know the version they hold is stale.
+	DashboardUpdatedMessageType = "dashboard_updated"
+)
+
+// DashboardEditor is one session editing a dashboard.
+type DashboardEditor struct {
+	SessionID string    `json:"session_id"`
+	Since     time.Time `json:"since"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// DashboardPresence lists the sessions editing a dashboard.
+type DashboardPresence struct {
+	DashboardID uuid.UUID         `json:"dashboard_id"`
+	Version     int               `json:"version,omitempty"`
+	Editors     []DashboardEditor `json:"editors"`
+}
+
+// dashboardPresenceTracker is an in-memory soft lock: it records which
+// sessions have a dashboard open for editing so others can be warned. It
+// never blocks a write; stale writes are caught by the version precondition.
+type dashboardPresenceTracker struct {
+	mu      sync.Mutex
+	editors map[uuid.UUID]map[string]DashboardEditor
+	now     func() time.Time
+}
+
+func newDashboardPresenceTracker() *dashboardPresenceTracker {
+	return &dashboardPresenceTracker{
+		editors: make(map[uuid.UUID]map[string]DashboardEditor),
+		now:     time.Now,
+	}
+}
+
+// update records that sessionID started, continued or stopped editing
+// dashboardID. It returns the current editors, whether the set of sessions
+// changed, and false when the dashboard already has too many editors.
+func (t *dashboardPresenceTracker) update(dashboardID uuid.UUID, sessionID string, editing bool) ([]DashboardEditor, bool, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	sessions := t.editors[dashboardID]
+	changed := t.expireLocked(sessions, now)
+
+	if editing {
+		e, ok := sessions[sessionID]
+		if !ok {
+			if len(sessions) >= maxDashboardEditors {
+				return t.listLocked(dashboardID), changed, false
+			}
+			if sessions == nil {
+				sessions = make(map[string]DashboardEditor)
+				t.editors[dashboardID] = sessions
+			}
+			e = DashboardEditor{SessionID: sessionID, Since: now}
+			changed = true
+		}
+		e.LastSeen = now
+		sessions[sessionID] = e
+	} else if _, ok := sessions[sessionID]; ok {
+		delete(sessions, sessionID)
+		changed = true
+	}
+	if len(sessions) == 0 {
+		delete(t.editors, dashboardID)
+	}
+	return t.listLocked(dashboardID), changed, true
+}
+
+// forget drops every editor of a deleted dashboard.
+func (t *dashboardPresenceTracker) forget(dashboardID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.editors, dashboardID)
+}
+
+func (t *dashboardPresenceTracker) expireLocked(sessions map[string]DashboardEditor, now time.Time) bool {
+	expired := false
+	for id, e := range sessions {
+		if now.Sub(e.LastSeen) > dashboardPresenceTTL {
+			delete(sessions, id)
+			expired = true
+		}
+	}
+	return expired
+}
+
+// listLocked returns the editors of dashboardID, longest-present first.
+func (t *dashboardPresenceTracker) listLocked(dashboardID uuid.UUID) []DashboardEditor {
+	out := make([]DashboardEditor, 0, len(t.editors[dashboardID]))
+	for _, e := range t.editors[dashboardID] {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Since.Equal(out[j].Since) {
+			return out[i].Since.Before(out[j].Since)
+		}
+		return out[i].SessionID < out[j].SessionID
+	})
+	return out
+}
+
+// UpdatePresence records that a session opened, is still on, or left a
+// dashboard's edit mode, and returns every session editing it so the client
+// can warn when it is not alone. Clients send editing=true on entering edit
+// mode and then every 20s, and editing=false on leaving.
+// POST /api/dashboards/:id/presence {"session_id": "...", "editing": true}
+func (h *DashboardHandler) UpdatePresence(c *fiber.Ctx) error {
+	if IsDemoMode(c) {
+		return c.JSON(DashboardPresence{Editors: []DashboardEditor{}})
+	}
+	userID := middleware.GetUserID(c)
+	dashboardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid dashboard ID")
+	}
+
+	var input struct {
+		SessionID string `json:"session_id"`
+		Editing   bool   `json:"editing"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	sessionID := strings.TrimSpace(input.SessionID)
+	if sessionID == "" || len(sessionID) > maxDashboardSessionIDLen {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid session ID")
+	}
+
+	dashboard, err := h.store.GetDashboard(c.UserContext(), dashboardID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
+	}
+	if dashboard == nil {
+		return fiber.NewError(fiber.StatusNotFound, "Dashboard not found")
+	}
+	if dashboard.UserID != userID {
+		return fiber.NewError(fiber.StatusForbidden, "Access denied")
+	}
+
+	editors, changed, ok := h.presence.update(dashboardID, sessionID, input.Editing)
+	if !ok {
+		return fiber.NewError(fiber.StatusTooManyRequests, "Too many sessions editing this dashboard")
+	}
+	presence := DashboardPresence{DashboardID: dashboardID, Version: dashboard.Version, Editors: editors}
+	if changed {
+		h.broadcast(userID, Message{Type: DashboardPresenceMessageType, Data: presence})
+	}
+	return c.JSON(presence)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedDashboardStore keeps one dashboard in memory and enforces the
+// version precondition like the SQLite store does.
+type versionedDashboardStore struct {
+	*test.MockStore
+	dashboard *models.Dashboard
+}
+
+func (s *versionedDashboardStore) GetDashboard(_ context.Context, id uuid.UUID) (*models.Dashboard, error) {
+	if s.dashboard == nil || s.dashboard.ID != id {
+		return nil, nil
+	}
+	d := *s.dashboard
+	return &d, nil
+}
+
+func (s *versionedDashboardStore) UpdateDashboard(_ context.Context, d *models.Dashboard) error {
+	d.Version = s.dashboard.Version + 1
+	stored := *d
+	s.dashboard = &stored
+	return nil
+}
+
+func (s *versionedDashboardStore) UpdateDashboardIfVersion(ctx context.Context, d *models.Dashboard, expected int) error {
+	if s.dashboard.Version != expected {
+		return store.ErrDashboardVersionConflict
+	}
+	return s.UpdateDashboard(ctx, d)
+}
+
+func setupVersionedDashboardTest(t *testing.T) (*fiber.App, *versionedDashboardStore, *Hub) {
+	t.Helper()
+	userID := uuid.New()
+	vs := &versionedDashboardStore{
+		MockStore: new(test.MockStore),
+		dashboard: &models.Dashboard{ID: uuid.New(), UserID: userID, Name: "Ops", Version: 3},
+	}
+	hub := NewHub()
+	go hub.Run()
+	t.Cleanup(func() { hub.Close() })
+
+	handler := NewDashboardHandler(vs, hub)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Put("/api/dashboards/:id", handler.UpdateDashboard)
+	app.Post("/api/dashboards/:id/presence", handler.UpdatePresence)
+	return app, vs, hub
+}
+
+func sendDashboardJSON(t *testing.T, app *fiber.App, method, path, body string, header map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestUpdateDashboard_VersionPrecondition(t *testing.T) {
+	app, vs, _ := setupVersionedDashboardTest(t)
+	path := "/api/dashboards/" + vs.dashboard.ID.String()
+
+	resp := sendDashboardJSON(t, app, "PUT", path, `{"name":"Renamed","version":3}`, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var updated models.Dashboard
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+	assert.Equal(t, 4, updated.Version)
+	assert.Equal(t, "Renamed", vs.dashboard.Name)
+
+	// A second session still holding version 3 is rejected.
+	resp = sendDashboardJSON(t, app, "PUT", path, `{"name":"Other","is_default":true,"version":3,"base":{"name":"Ops","is_default":false}}`, nil)
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+	var conflict DashboardConflict
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&conflict))
+	assert.Equal(t, "Renamed", conflict.Current.Name)
+	assert.Equal(t, []DashboardFieldConflict{{Field: "name", Yours: "Other", Theirs: "Renamed"}}, conflict.Conflicts,
+		"is_default only changed on the client side, so it merges cleanly")
+	assert.Equal(t, true, conflict.Merged["is_default"])
+	assert.EqualValues(t, 4, conflict.Merged["version"])
+	assert.Equal(t, "Renamed", vs.dashboard.Name, "the stale write must not land")
+
+	resp = sendDashboardJSON(t, app, "PUT", path, `{"name":"Other"}`, map[string]string{"If-Match": `"4"`})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = sendDashboardJSON(t, app, "PUT", path, `{"name":"Again"}`, map[string]string{"If-Match": `"4"`})
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	for _, bad := range []struct{ body, ifMatch string }{
+		{`{"version":0}`, ""},
+		{`{"name":"x"}`, "abc"},
+	} {
+		resp = sendDashboardJSON(t, app, "PUT", path, bad.body, map[string]string{"If-Match": bad.ifMatch})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, bad)
+	}
+
+	// Writes without a precondition keep last-write-wins behaviour.
+	resp = sendDashboardJSON(t, app, "PUT", path, `{"name":"Blind"}`, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 6, vs.dashboard.Version)
+}
+
+func TestDashboardConflict_WithoutBase(t *testing.T) {
+	name, isDefault := "Mine", false
+	got := dashboardConflict(models.Dashboard{Name: "Theirs", IsDefault: false, Version: 7},
+		dashboardUpdateInput{Name: &name, IsDefault: &isDefault})
+	assert.Equal(t, []DashboardFieldConflict{{Field: "name", Yours: "Mine", Theirs: "Theirs"}}, got.Conflicts,
+		"fields already equal to the current value never conflict")
+	assert.Equal(t, fiber.Map{"name": "Mine", "is_default": false, "version": 7}, got.Merged)
+}
+
+func TestUpdatePresence(t *testing.T) {
+	app, vs, _ := setupVersionedDashboardTest(t)
+	path := "/api/dashboards/" + vs.dashboard.ID.String() + "/presence"
+
+	presence := func(body string) DashboardPresence {
+		resp := sendDashboardJSON(t, app, "POST", path, body, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var out DashboardPresence
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return out
+	}
+
+	got := presence(`{"session_id":"tab-a","editing":true}`)
+	assert.Equal(t, 3, got.Version)
+	require.Len(t, got.Editors, 1)
+
+	got = presence(`{"session_id":"tab-b","editing":true}`)
+	require.Len(t, got.Editors, 2, "the second tab sees the first one editing")
+	assert.Equal(t, "tab-a", got.Editors[0].SessionID)
+
+	got = presence(`{"session_id":"tab-a","editing":false}`)
+	require.Len(t, got.Editors, 1)
+	assert.Equal(t, "tab-b", got.Editors[0].SessionID)
+
+	resp := sendDashboardJSON(t, app, "POST", path, `{"session_id":"  ","editing":true}`, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = sendDashboardJSON(t, app, "POST", "/api/dashboards/"+uuid.NewString()+"/presence", `{"session_id":"tab-a","editing":true}`, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDashboardPresenceTracker(t *testing.T) {
+	tr := newDashboardPresenceTracker()
+	now := time.Now()
+	tr.now = func() time.Time { return now }
+	id := uuid.New()
+
+	_, changed, ok := tr.update(id, "a", true)
+	assert.True(t, changed)
+	assert.True(t, ok)
+	_, changed, _ = tr.update(id, "a", true)
+	assert.False(t, changed, "a heartbeat does not change the editor set")
+
+	now = now.Add(dashboardPresenceTTL + time.Second)
+	editors, changed, _ := tr.update(id, "b", true)
+	assert.True(t, changed)
+	require.Len(t, editors, 1, "a session that stopped heartbeating expires")
+	assert.Equal(t, "b", editors[0].SessionID)
+
+	for i := 1; i < maxDashboardEditors; i++ {
+		_, _, ok = tr.update(id, uuid.NewString(), true)
+		require.True(t, ok)
+	}
+	_, _, ok = tr.update(id, "overflow", true)
+	assert.False(t, ok)
+
+	tr.forget(id)
+	editors, _, _ = tr.update(id, "b", false)
+	assert.Empty(t, editors)
+	assert.Empty(t, tr.editors, "dashboards without editors are not kept")
+}
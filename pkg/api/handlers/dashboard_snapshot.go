@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// defaultSnapshotTTL is how long a snapshot link works when the request
+	// does not say.
+	defaultSnapshotTTL = 24 * time.Hour
+	// minSnapshotTTL and maxSnapshotTTL bound the requested link lifetime.
+	minSnapshotTTL = 5 * time.Minute
+	maxSnapshotTTL = 30 * 24 * time.Hour
+	// maxSnapshotCardDataBytes bounds the captured data of a single card.
+	maxSnapshotCardDataBytes = 64 * 1024
+	// maxUserDashboardSnapshots bounds the unexpired snapshots per user.
+	maxUserDashboardSnapshots = 100
+
+	// snapshotKeyContext separates the snapshot signing key from other uses
+	// of the server secret.
+	snapshotKeyContext = "kubestellar-console/dashboard-snapshot"
+	// PublicSnapshotPath is where signed snapshot links point.
+	PublicSnapshotPath = "/api/public/snapshots/"
+)
+
+// DashboardSnapshotHandler creates immutable dashboard snapshots and serves
+// them, without authentication, to anyone holding an unexpired signed link.
+type DashboardSnapshotHandler struct {
+	store store.Store
+	key   []byte
+	now   func() time.Time
+}
+
+// NewDashboardSnapshotHandler creates a snapshot handler that signs links
+// with a key derived from secret. With an empty secret no links are issued.
+func NewDashboardSnapshotHandler(s store.Store, secret string) *DashboardSnapshotHandler {
+	h := &DashboardSnapshotHandler{store: s, now: time.Now}
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(snapshotKeyContext))
+		h.key = mac.Sum(nil)
+	}
+	return h
+}
+
+// DashboardSnapshotLink is returned when a snapshot is created.
+type DashboardSnapshotLink struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// sign returns the hex HMAC binding a snapshot ID to its expiry.
+func (h *DashboardSnapshotHandler) sign(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(id.String() + "." + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signedURL returns the public path of a snapshot with its signature.
+func (h *DashboardSnapshotHandler) signedURL(id uuid.UUID, expiresAt time.Time) string {
+	expires := expiresAt.Unix()
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("sig", h.sign(id, expires))
+	return PublicSnapshotPath + id.String() + "?" + q.Encode()
+}
+
+// CreateSnapshot captures a dashboard and the data its cards currently show
+// into an immutable snapshot and returns a signed link that works without
+// logging in until it expires. card_data maps card IDs to the data the
+// client rendered; cards without an entry keep only their config and summary.
+// POST /api/dashboards/:id/snapshot {"expires_in_seconds": 86400, "card_data": {"<card id>": {...}}}
+func (h *DashboardSnapshotHandler) CreateSnapshot(c *fiber.Ctx) error {
+	if IsDemoMode(c) {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"status": "ok", "source": "demo"})
+	}
+	if len(h.key) == 0 {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Dashboard snapshots are not configured")
+	}
+	userID := middleware.GetUserID(c)
+	dashboardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid dashboard ID")
+	}
+
+	var input struct {
+		ExpiresInSeconds int                        `json:"expires_in_seconds"`
+		CardData         map[string]json.RawMessage `json:"card_data"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+	ttl := defaultSnapshotTTL
+	if input.ExpiresInSeconds != 0 {
+		ttl = time.Duration(input.ExpiresInSeconds) * time.Second
+		if ttl < minSnapshotTTL || ttl > maxSnapshotTTL {
+			return fiber.NewError(fiber.StatusBadRequest, "expires_in_seconds must be between 300 and 2592000")
+		}
+	}
+
+	ctx := c.UserContext()
+	dashboard, err := h.store.GetDashboard(ctx, dashboardID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get dashboard")
+	}
+	if dashboard == nil {
+		return fiber.NewError(fiber.StatusNotFound, "Dashboard not found")
+	}
+	if dashboard.UserID != userID {
+		return fiber.NewError(fiber.StatusForbidden, "Access denied")
+	}
+	cards, err := h.store.GetDashboardCards(ctx, dashboardID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get cards")
+	}
+
+	data := make(map[uuid.UUID]json.RawMessage, len(input.CardData))
+	for key, raw := range input.CardData {
+		cardID, err := uuid.Parse(key)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid card ID in card_data")
+		}
+		if len(raw) > maxSnapshotCardDataBytes {
+			return fiber.NewError(fiber.StatusRequestEntityTooLarge, "Card data exceeds 64 KB")
+		}
+		data[cardID] = raw
+	}
+	snapshotCards := make([]models.SnapshotCard, 0, len(cards))
+	for _, card := range cards {
+		snapshotCards = append(snapshotCards, models.SnapshotCard{
+			ID:       card.ID,
+			CardType: card.CardType,
+			Config:   card.Config,
+			Position: card.Position,
+			Summary:  card.LastSummary,
+			Data:     data[card.ID],
+		})
+		delete(data, card.ID)
+	}
+	if len(data) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "card_data references a card that is not on this dashboard")
+	}
+
+	now := h.now()
+	if _, err := h.store.DeleteExpiredDashboardSnapshots(ctx, now); err != nil {
+		slog.Warn("[Dashboards] failed to purge expired snapshots", "error", err)
+	}
+	count, err := h.store.CountUserDashboardSnapshots(ctx, userID, now)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create snapshot")
+	}
+	if count >= maxUserDashboardSnapshots {
+		return fiber.NewError(fiber.StatusTooManyRequests, "Too many active snapshots; wait for older ones to expire")
+	}
+
+	snapshot := &models.DashboardSnapshot{
+		DashboardID: dashboardID,
+		UserID:      userID,
+		Name:        dashboard.Name,
+		Layout:      dashboard.Layout,
+		Cards:       snapshotCards,
+		// Links carry whole seconds, so the stored expiry must too.
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+	if err := h.store.CreateDashboardSnapshot(ctx, snapshot); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create snapshot")
+	}
+	return c.Status(fiber.StatusCreated).JSON(DashboardSnapshotLink{
+		ID:        snapshot.ID,
+		URL:       h.signedURL(snapshot.ID, snapshot.ExpiresAt),
+		ExpiresAt: snapshot.ExpiresAt,
+	})
+}
+
+// GetPublicSnapshot serves a snapshot to an unauthenticated caller holding
+// its signed link. A link with a wrong signature, or one whose expiry does
+// not match the stored snapshot, is indistinguishable from a missing one.
+// GET /api/public/snapshots/:id?expires=<unix seconds>&sig=<hex hmac>
+func (h *DashboardSnapshotHandler) GetPublicSnapshot(c *fiber.Ctx) error {
+	notFound := fiber.NewError(fiber.StatusNotFound, "Snapshot not found")
+	if len(h.key) == 0 {
+		return notFound
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return notFound
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		return notFound
+	}
+	sig, err := hex.DecodeString(c.Query("sig"))
+	if err != nil {
+		return notFound
+	}
+	want, _ := hex.DecodeString(h.sign(id, expires))
+	if !hmac.Equal(sig, want) {
+		return notFound
+	}
+	if h.now().Unix() >= expires {
+		return fiber.NewError(fiber.StatusGone, "Snapshot link has expired")
+	}
+
+	snapshot, err := h.store.GetDashboardSnapshot(c.UserContext(), id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to get snapshot")
+	}
+	if snapshot == nil || snapshot.ExpiresAt.Unix() != expires {
+		return notFound
+	}
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set("X-Robots-Tag", "noindex")
+	return c.JSON(snapshot)
+}
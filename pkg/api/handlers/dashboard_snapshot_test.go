@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+// snapshotTestStore keeps one dashboard, its cards and created snapshots in
+// memory.
+type snapshotTestStore struct {
+	versionedDashboardStore
+	cards     []models.Card
+	snapshots map[uuid.UUID]models.DashboardSnapshot
+}
+
+func (s *snapshotTestStore) GetDashboardCards(context.Context, uuid.UUID) ([]models.Card, error) {
+	return s.cards, nil
+}
+
+func (s *snapshotTestStore) CreateDashboardSnapshot(_ context.Context, snapshot *models.DashboardSnapshot) error {
+	snapshot.ID = uuid.New()
+	snapshot.CreatedAt = time.Now()
+	s.snapshots[snapshot.ID] = *snapshot
+	return nil
+}
+
+func (s *snapshotTestStore) GetDashboardSnapshot(_ context.Context, id uuid.UUID) (*models.DashboardSnapshot, error) {
+	snapshot, ok := s.snapshots[id]
+	if !ok {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
+
+func (s *snapshotTestStore) CountUserDashboardSnapshots(_ context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	count := 0
+	for _, snapshot := range s.snapshots {
+		if snapshot.UserID == userID && snapshot.ExpiresAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *snapshotTestStore) DeleteExpiredDashboardSnapshots(_ context.Context, cutoff time.Time) (int64, error) {
+	var removed int64
+	for id, snapshot := range s.snapshots {
+		if snapshot.ExpiresAt.Before(cutoff) {
+			delete(s.snapshots, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+type snapshotTestEnv struct {
+	app     *fiber.App
+	store   *snapshotTestStore
+	handler *DashboardSnapshotHandler
+	now     time.Time
+}
+
+func setupSnapshotTest(t *testing.T) *snapshotTestEnv {
+	t.Helper()
+	userID := uuid.New()
+	dashboardID := uuid.New()
+	s := &snapshotTestStore{
+		versionedDashboardStore: versionedDashboardStore{
+			MockStore: new(test.MockStore),
+			dashboard: &models.Dashboard{ID: dashboardID, UserID: userID, Name: "Ops", Version: 1},
+		},
+		cards: []models.Card{
+			{ID: uuid.New(), DashboardID: dashboardID, CardType: models.CardTypeClusterHealth, LastSummary: "3 healthy"},
+			{ID: uuid.New(), DashboardID: dashboardID, CardType: models.CardTypePodIssues},
+		},
+		snapshots: map[uuid.UUID]models.DashboardSnapshot{},
+	}
+	te := &snapshotTestEnv{store: s, now: time.Now()}
+	te.handler = NewDashboardSnapshotHandler(s, "test-secret")
+	te.handler.now = func() time.Time { return te.now }
+
+	te.app = fiber.New()
+	te.app.Get(PublicSnapshotPath+":id", te.handler.GetPublicSnapshot)
+	api := te.app.Group("/api", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	api.Post("/dashboards/:id/snapshot", te.handler.CreateSnapshot)
+	return te
+}
+
+func (e *snapshotTestEnv) create(t *testing.T, body string) (int, DashboardSnapshotLink) {
+	t.Helper()
+	resp := sendDashboardJSON(t, e.app, "POST", "/api/dashboards/"+e.store.dashboard.ID.String()+"/snapshot", body, nil)
+	var link DashboardSnapshotLink
+	_ = json.NewDecoder(resp.Body).Decode(&link)
+	return resp.StatusCode, link
+}
+
+func (e *snapshotTestEnv) fetch(t *testing.T, link string) *http.Response {
+	t.Helper()
+	return sendDashboardJSON(t, e.app, "GET", link, "", nil)
+}
+
+func TestDashboardSnapshot_CreateAndFetch(t *testing.T) {
+	te := setupSnapshotTest(t)
+	first := te.store.cards[0].ID
+
+	status, link := te.create(t, `{"expires_in_seconds":3600,"card_data":{"`+first.String()+`":{"healthy":3}}}`)
+	require.Equal(t, http.StatusCreated, status)
+	assert.True(t, strings.HasPrefix(link.URL, PublicSnapshotPath+link.ID.String()+"?"))
+	assert.Equal(t, te.now.Add(time.Hour).Unix(), link.ExpiresAt.Unix())
+
+	resp := te.fetch(t, link.URL)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "private, no-store", resp.Header.Get(fiber.HeaderCacheControl))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "user_id", "public snapshots do not expose the owner")
+	var snapshot models.DashboardSnapshot
+	require.NoError(t, json.Unmarshal(body, &snapshot))
+	assert.Equal(t, "Ops", snapshot.Name)
+	require.Len(t, snapshot.Cards, 2)
+	assert.JSONEq(t, `{"healthy":3}`, string(snapshot.Cards[0].Data))
+	assert.Equal(t, "3 healthy", snapshot.Cards[0].Summary)
+	assert.Empty(t, snapshot.Cards[1].Data)
+
+	// Later dashboard changes do not reach the snapshot.
+	te.store.dashboard.Name = "Renamed"
+	resp = te.fetch(t, link.URL)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&snapshot))
+	assert.Equal(t, "Ops", snapshot.Name)
+
+	te.now = te.now.Add(time.Hour)
+	assert.Equal(t, http.StatusGone, te.fetch(t, link.URL).StatusCode)
+}
+
+func TestDashboardSnapshot_RejectsTamperedLinks(t *testing.T) {
+	te := setupSnapshotTest(t)
+	status, link := te.create(t, "")
+	require.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, te.now.Add(defaultSnapshotTTL).Unix(), link.ExpiresAt.Unix())
+
+	u, err := url.Parse(link.URL)
+	require.NoError(t, err)
+	q := u.Query()
+	extended := strings.Replace(u.Path+"?"+q.Encode(), "expires="+q.Get("expires"), "expires="+q.Get("expires")+"0", 1)
+
+	for name, path := range map[string]string{
+		"no signature":    u.Path,
+		"bad signature":   u.Path + "?expires=" + q.Get("expires") + "&sig=" + strings.Repeat("0", 64),
+		"extended expiry": extended,
+		"other snapshot":  PublicSnapshotPath + uuid.NewString() + "?" + q.Encode(),
+	} {
+		assert.Equal(t, http.StatusNotFound, te.fetch(t, path).StatusCode, name)
+	}
+
+	other := NewDashboardSnapshotHandler(te.store, "another-secret")
+	assert.NotEqual(t, other.signedURL(link.ID, link.ExpiresAt), link.URL, "links depend on the server secret")
+}
+
+func TestDashboardSnapshot_CreateValidation(t *testing.T) {
+	te := setupSnapshotTest(t)
+
+	for name, tc := range map[string]struct {
+		body   string
+		status int
+	}{
+		"malformed":    {`{"card_data":`, http.StatusBadRequest},
+		"short ttl":    {`{"expires_in_seconds":60}`, http.StatusBadRequest},
+		"long ttl":     {`{"expires_in_seconds":99999999}`, http.StatusBadRequest},
+		"bad card id":  {`{"card_data":{"nope":{}}}`, http.StatusBadRequest},
+		"foreign card": {`{"card_data":{"` + uuid.NewString() + `":{}}}`, http.StatusBadRequest},
+		"large data": {`{"card_data":{"` + te.store.cards[0].ID.String() + `":"` + strings.Repeat("x", maxSnapshotCardDataBytes) + `"}}`,
+			http.StatusRequestEntityTooLarge},
+	} {
+		status, _ := te.create(t, tc.body)
+		assert.Equal(t, tc.status, status, name)
+	}
+	assert.Empty(t, te.store.snapshots)
+
+	te.store.dashboard.UserID = uuid.New()
+	status, _ := te.create(t, "")
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestDashboardSnapshot_LimitsActiveSnapshots(t *testing.T) {
+	te := setupSnapshotTest(t)
+	for i := 0; i < maxUserDashboardSnapshots; i++ {
+		status, _ := te.create(t, `{"expires_in_seconds":300}`)
+		require.Equal(t, http.StatusCreated, status)
+	}
+	status, _ := te.create(t, "")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+
+	te.now = te.now.Add(time.Hour)
+	status, _ = te.create(t, "")
+	assert.Equal(t, http.StatusCreated, status, "expired snapshots are purged and stop counting")
+	assert.Len(t, te.store.snapshots, 1)
+}
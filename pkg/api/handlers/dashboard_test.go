@@ -31,7 +31,7 @@ func setupDashboardTest(userID uuid.UUID) (*fiber.App, *test.MockStore, *Dashboa
 	// higher return value before calling the handler.
 	mockStore.On("CountUserDashboards", userID).Return(0, nil).Maybe()
 
-	handler := NewDashboardHandler(mockStore)
+	handler := NewDashboardHandler(mockStore, nil)
 
 	// Inject userID into context (simulates auth middleware)
 	app.Use(func(c *fiber.Ctx) error {
@@ -225,6 +225,38 @@ func TestImportDashboard_Success(t *testing.T) {
 	assert.Equal(t, "Imported", result.Name)
 }
 
+// TestImportDashboard_InvalidCardConfig verifies that an import is rejected
+// before anything is created when a card's config fails its schema, with
+// each violation naming the card it belongs to.
+func TestImportDashboard_InvalidCardConfig(t *testing.T) {
+	userID := uuid.New()
+	app, mockStore, handler := setupDashboardTest(userID)
+	app.Post("/api/dashboards/import", handler.ImportDashboard)
+
+	body := `{"format":"kc-dashboard-v1","name":"Broken","cards":[` +
+		`{"card_type":"cluster_health","config":{"autoRefresh":true},"position":{"x":0,"y":0,"w":4,"h":3}},` +
+		`{"card_type":"event_stream","config":{"limit":"50"},"position":{"x":4,"y":0,"w":4,"h":3}},` +
+		`{"card_type":"pod_issues","config":["default"],"position":{"x":8,"y":0,"w":4,"h":3}}]}`
+	req, err := http.NewRequest("POST", "/api/dashboards/import", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result struct {
+		Violations []struct {
+			Field string `json:"field"`
+		} `json:"violations"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Len(t, result.Violations, 2)
+	assert.Equal(t, "card[1].config.limit", result.Violations[0].Field)
+	assert.Equal(t, "card[2].config", result.Violations[1].Field)
+	mockStore.AssertNotCalled(t, "CreateDashboard")
+}
+
 // TestImportDashboard_ExceedsCardLimit verifies that an import payload
 // containing more than MaxCardsPerDashboard cards is rejected up-front with
 // a 400 instead of being partially imported (#6553).
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	defaultDebugResourceTTL = 30 * time.Minute
+	minDebugResourceTTL     = time.Minute
+	maxDebugResourceTTL     = 4 * time.Hour
+	// MaxDebugResourcesPerUser caps the live debug resources one user can
+	// have on a cluster.
+	MaxDebugResourcesPerUser = 3
+	// debugResourceSweepInterval is how often expired debug resources are
+	// deleted.
+	debugResourceSweepInterval = time.Minute
+	// debugResourceRequestTimeout bounds each cluster call.
+	debugResourceRequestTimeout = 15 * time.Second
+	// defaultDebugNamespace is used when a request names no namespace.
+	defaultDebugNamespace = "default"
+)
+
+// debugResourceClient is the subset of k8s.MultiClusterClient debug
+// resources need.
+type debugResourceClient interface {
+	HealthyClusters(ctx context.Context) ([]k8s.ClusterInfo, []k8s.ClusterInfo, error)
+	CreateDebugResource(ctx context.Context, contextName string, spec k8s.DebugResourceSpec) (*k8s.DebugResource, error)
+	ListDebugResources(ctx context.Context, contextName, owner string) ([]k8s.DebugResource, error)
+	GetDebugResource(ctx context.Context, contextName, kind, namespace, name string) (*k8s.DebugResource, error)
+	DeleteDebugResource(ctx context.Context, contextName, kind, namespace, name string) error
+	SweepExpiredDebugResources(ctx context.Context, contextName string, now time.Time) (int, error)
+}
+
+// debugResourceRequest is the body accepted by CreateDebugResource.
+type debugResourceRequest struct {
+	Template  string `json:"template"`
+	Namespace string `json:"namespace,omitempty"`
+	// TTLMinutes is how long the resource lives; zero uses the default.
+	TTLMinutes int `json:"ttlMinutes,omitempty"`
+}
+
+// DebugResourceHandler lets editors and admins start short-lived
+// troubleshooting pods and jobs from fixed templates. Resources are labeled
+// with their owner and expiry, counted against a per-user quota, and
+// deleted by a background sweep once they expire.
+type DebugResourceHandler struct {
+	k8sClient debugResourceClient
+	store     store.Store
+	now       func() time.Time
+}
+
+// NewDebugResourceHandler creates a debug resource handler.
+func NewDebugResourceHandler(k8sClient *k8s.MultiClusterClient, s store.Store) *DebugResourceHandler {
+	h := &DebugResourceHandler{store: s, now: time.Now}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// debugOwner returns the owner label value for the requesting user: the
+// GitHub login, or the user ID when there is none.
+func debugOwner(c *fiber.Ctx) string {
+	if owner := k8s.DebugOwnerLabelValue(middleware.GetGitHubLogin(c)); owner != "" {
+		return owner
+	}
+	return middleware.GetUserID(c).String()
+}
+
+// ListTemplates returns the templates debug resources can be created from.
+// GET /api/debug-resources/templates
+func (h *DebugResourceHandler) ListTemplates(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"templates":     k8s.DebugTemplates,
+		"defaultTtl":    int(defaultDebugResourceTTL.Minutes()),
+		"maxTtl":        int(maxDebugResourceTTL.Minutes()),
+		"maxPerUser":    MaxDebugResourcesPerUser,
+		"sweepInterval": int(debugResourceSweepInterval.Seconds()),
+	})
+}
+
+// CreateDebugResource starts a debug pod or job on a cluster. Returns 409
+// when the user already has MaxDebugResourcesPerUser live resources there.
+// POST /api/clusters/:cluster/debug-resources
+func (h *DebugResourceHandler) CreateDebugResource(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	var req debugResourceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if _, ok := k8s.LookupDebugTemplate(req.Template); !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("unknown template %q", req.Template)})
+	}
+	if req.Namespace == "" {
+		req.Namespace = defaultDebugNamespace
+	}
+	if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	ttl := defaultDebugResourceTTL
+	if req.TTLMinutes != 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	if ttl < minDebugResourceTTL || ttl > maxDebugResourceTTL {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("ttlMinutes must be between %d and %d", int(minDebugResourceTTL.Minutes()), int(maxDebugResourceTTL.Minutes())),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), debugResourceRequestTimeout)
+	defer cancel()
+
+	owner := debugOwner(c)
+	existing, err := h.k8sClient.ListDebugResources(ctx, cluster, owner)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	live := 0
+	for _, r := range existing {
+		if !r.Expired(h.now()) {
+			live++
+		}
+	}
+	if live >= MaxDebugResourcesPerUser {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("you already have %d debug resources on %s; delete one first", MaxDebugResourcesPerUser, cluster),
+		})
+	}
+
+	res, err := h.k8sClient.CreateDebugResource(ctx, cluster, k8s.DebugResourceSpec{
+		Template: req.Template, Namespace: req.Namespace, Owner: owner, TTL: ttl,
+	})
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	audit.Log(c, audit.ActionCreateDebugResource, "debug_resource", cluster+"/"+res.Namespace+"/"+res.Name,
+		fmt.Sprintf("template=%s ttl=%s", res.Template, ttl))
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// ListDebugResources returns every debug resource on a cluster.
+// GET /api/clusters/:cluster/debug-resources
+func (h *DebugResourceHandler) ListDebugResources(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(c.UserContext(), debugResourceRequestTimeout)
+	defer cancel()
+	resources, err := h.k8sClient.ListDebugResources(ctx, cluster, "")
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	return c.JSON(fiber.Map{"resources": resources})
+}
+
+// DeleteDebugResource deletes a debug resource before it expires. Editors
+// can delete their own; admins can delete anyone's. Objects that are not
+// debug resources return 404.
+// DELETE /api/clusters/:cluster/debug-resources/:kind/:namespace/:name
+func (h *DebugResourceHandler) DeleteDebugResource(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster, kind, namespace, name := c.Params("cluster"), c.Params("kind"), c.Params("namespace"), c.Params("name")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validateEnum("kind", kind, []string{"Pod", "Job"}); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validateDNSLabel("namespace", namespace); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), debugResourceRequestTimeout)
+	defer cancel()
+	res, err := h.k8sClient.GetDebugResource(ctx, cluster, kind, namespace, name)
+	if errors.Is(err, k8s.ErrNotDebugResource) || apierrors.IsNotFound(err) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "debug resource not found"})
+	}
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	if res.Owner != debugOwner(c) {
+		if err := RequireAdmin(c, h.store); err != nil {
+			return err
+		}
+	}
+	if err := h.k8sClient.DeleteDebugResource(ctx, cluster, kind, namespace, name); err != nil && !apierrors.IsNotFound(err) {
+		return HandleK8sError(c, err)
+	}
+	audit.Log(c, audit.ActionDeleteDebugResource, "debug_resource", cluster+"/"+namespace+"/"+name, "owner="+res.Owner)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// StartSweeper deletes expired debug resources on every healthy cluster
+// until done closes.
+func (h *DebugResourceHandler) StartSweeper(done <-chan struct{}) {
+	if h.k8sClient == nil {
+		return
+	}
+	safego.GoWith("handlers/debug-resource-sweeper", func() {
+		ticker := time.NewTicker(debugResourceSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				h.sweep(context.Background())
+			}
+		}
+	})
+}
+
+// sweep deletes expired debug resources on every healthy cluster. Offline
+// clusters are picked up once they come back.
+func (h *DebugResourceHandler) sweep(ctx context.Context) {
+	listCtx, cancel := context.WithTimeout(ctx, debugResourceRequestTimeout)
+	healthy, _, err := h.k8sClient.HealthyClusters(listCtx)
+	cancel()
+	if err != nil {
+		slog.Warn("[DebugResources] failed to list clusters for sweep", "error", err)
+		return
+	}
+	for _, cl := range healthy {
+		sweepCtx, cancel := context.WithTimeout(ctx, debugResourceRequestTimeout)
+		deleted, err := h.k8sClient.SweepExpiredDebugResources(sweepCtx, cl.Name, h.now())
+		cancel()
+		if err != nil {
+			slog.Warn("[DebugResources] sweep failed", "cluster", cl.Name, "error", err)
+		}
+		if deleted > 0 {
+			slog.Info("[DebugResources] deleted expired debug resources", "cluster", cl.Name, "deleted", deleted)
+		}
+	}
+}
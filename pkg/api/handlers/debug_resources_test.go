@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type fakeDebugClient struct {
+	resources []k8s.DebugResource
+	created   *k8s.DebugResourceSpec
+	deleted   []string
+	swept     []string
+}
+
+func (f *fakeDebugClient) HealthyClusters(context.Context) ([]k8s.ClusterInfo, []k8s.ClusterInfo, error) {
+	return []k8s.ClusterInfo{{Name: "prod"}, {Name: "dev"}}, []k8s.ClusterInfo{{Name: "offline"}}, nil
+}
+
+func (f *fakeDebugClient) CreateDebugResource(_ context.Context, contextName string, spec k8s.DebugResourceSpec) (*k8s.DebugResource, error) {
+	f.created = &spec
+	return &k8s.DebugResource{Cluster: contextName, Kind: "Pod", Namespace: spec.Namespace, Name: "debug-netshoot-x", Template: spec.Template, Owner: spec.Owner}, nil
+}
+
+func (f *fakeDebugClient) ListDebugResources(_ context.Context, _, owner string) ([]k8s.DebugResource, error) {
+	var out []k8s.DebugResource
+	for _, r := range f.resources {
+		if owner == "" || r.Owner == owner {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDebugClient) GetDebugResource(_ context.Context, _, kind, namespace, name string) (*k8s.DebugResource, error) {
+	for _, r := range f.resources {
+		if r.Kind == kind && r.Namespace == namespace && r.Name == name {
+			return &r, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+}
+
+func (f *fakeDebugClient) DeleteDebugResource(_ context.Context, _, _, _, name string) error {
+	f.deleted = append(f.deleted, strings.Clone(name))
+	return nil
+}
+
+func (f *fakeDebugClient) SweepExpiredDebugResources(_ context.Context, contextName string, _ time.Time) (int, error) {
+	f.swept = append(f.swept, strings.Clone(contextName))
+	return 0, nil
+}
+
+func setupDebugResourceTest(t *testing.T, role models.UserRole, client *fakeDebugClient) *fiber.App {
+	t.Helper()
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	h := &DebugResourceHandler{k8sClient: client, store: mockStore, now: time.Now}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("githubLogin", "OctoCat")
+		return c.Next()
+	})
+	app.Post("/api/clusters/:cluster/debug-resources", h.CreateDebugResource)
+	app.Delete("/api/clusters/:cluster/debug-resources/:kind/:namespace/:name", h.DeleteDebugResource)
+	return app
+}
+
+func debugRequest(t *testing.T, app *fiber.App, method, path, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestCreateDebugResource(t *testing.T) {
+	client := &fakeDebugClient{}
+	app := setupDebugResourceTest(t, models.UserRoleEditor, client)
+
+	status := debugRequest(t, app, http.MethodPost, "/api/clusters/prod/debug-resources", `{"template":"netshoot"}`)
+	require.Equal(t, http.StatusCreated, status)
+	require.NotNil(t, client.created)
+	assert.Equal(t, "octocat", client.created.Owner)
+	assert.Equal(t, "default", client.created.Namespace)
+	assert.Equal(t, defaultDebugResourceTTL, client.created.TTL)
+
+	for _, body := range []string{
+		`{"template":"kali"}`,
+		`{"template":"busybox","ttlMinutes":100000}`,
+		`{"template":"busybox","namespace":"Bad_NS"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, debugRequest(t, app, http.MethodPost, "/api/clusters/prod/debug-resources", body), body)
+	}
+}
+
+func TestCreateDebugResource_Quota(t *testing.T) {
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+	client := &fakeDebugClient{}
+	for i := 0; i < MaxDebugResourcesPerUser; i++ {
+		client.resources = append(client.resources, k8s.DebugResource{Owner: "octocat", ExpiresAt: future})
+	}
+	client.resources = append(client.resources, k8s.DebugResource{Owner: "hubot", ExpiresAt: future})
+	app := setupDebugResourceTest(t, models.UserRoleEditor, client)
+
+	assert.Equal(t, http.StatusConflict, debugRequest(t, app, http.MethodPost, "/api/clusters/prod/debug-resources", `{"template":"netshoot"}`))
+
+	client.resources[0].ExpiresAt = past
+	assert.Equal(t, http.StatusCreated, debugRequest(t, app, http.MethodPost, "/api/clusters/prod/debug-resources", `{"template":"netshoot"}`),
+		"expired resources awaiting the sweep do not count")
+}
+
+func TestDeleteDebugResource_Ownership(t *testing.T) {
+	client := &fakeDebugClient{resources: []k8s.DebugResource{
+		{Kind: "Pod", Namespace: "shop", Name: "debug-mine", Owner: "octocat"},
+		{Kind: "Pod", Namespace: "shop", Name: "debug-theirs", Owner: "hubot"},
+	}}
+	app := setupDebugResourceTest(t, models.UserRoleEditor, client)
+
+	assert.Equal(t, http.StatusNoContent, debugRequest(t, app, http.MethodDelete, "/api/clusters/prod/debug-resources/Pod/shop/debug-mine", ""))
+	assert.Equal(t, http.StatusForbidden, debugRequest(t, app, http.MethodDelete, "/api/clusters/prod/debug-resources/Pod/shop/debug-theirs", ""))
+	assert.Equal(t, http.StatusNotFound, debugRequest(t, app, http.MethodDelete, "/api/clusters/prod/debug-resources/Pod/shop/api", ""))
+	assert.Equal(t, http.StatusBadRequest, debugRequest(t, app, http.MethodDelete, "/api/clusters/prod/debug-resources/Deployment/shop/api", ""))
+	assert.Equal(t, []string{"debug-mine"}, client.deleted)
+
+	admin := setupDebugResourceTest(t, models.UserRoleAdmin, client)
+	assert.Equal(t, http.StatusNoContent, debugRequest(t, admin, http.MethodDelete, "/api/clusters/prod/debug-resources/Pod/shop/debug-theirs", ""))
+}
+
+func TestDebugResourceSweep(t *testing.T) {
+	client := &fakeDebugClient{}
+	h := &DebugResourceHandler{k8sClient: client, now: time.Now}
+	h.sweep(context.Background())
+	assert.Equal(t, []string{"prod", "dev"}, client.swept, "offline clusters are skipped")
+}
@@ -0,0 +1,502 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// Disruption actions.
+const (
+	DisruptionEvictPod   = "evict_pod"
+	DisruptionCordonNode = "cordon_node"
+)
+
+// Disruption experiment states.
+const (
+	DisruptionRunning   = "running"
+	DisruptionRecovered = "recovered"
+	DisruptionTimedOut  = "timed_out"
+	DisruptionFailed    = "failed"
+)
+
+const (
+	defaultDisruptionTimeout = 5 * time.Minute
+	maxDisruptionTimeout     = 30 * time.Minute
+	defaultCordonHold        = 30 * time.Second
+	maxCordonHold            = 10 * time.Minute
+	// disruptionPollInterval is how often recovery is checked.
+	disruptionPollInterval = 2 * time.Second
+	// disruptionRequestTimeout bounds each API call of an experiment.
+	disruptionRequestTimeout = 15 * time.Second
+	// maxRunningDisruptions caps experiments in flight across all clusters.
+	maxRunningDisruptions = 3
+	// maxDisruptionHistory is how many experiments are kept in memory.
+	maxDisruptionHistory = 50
+)
+
+// disruptableWorkloadKinds are the controllers whose recovery can be timed.
+var disruptableWorkloadKinds = []string{
+	k8s.WorkloadKindDeployment, k8s.WorkloadKindStatefulSet, k8s.WorkloadKindDaemonSet, k8s.WorkloadKindReplicaSet,
+}
+
+// disruptionClient is the subset of k8s.MultiClusterClient disruption
+// experiments need.
+type disruptionClient interface {
+	GetPodTarget(ctx context.Context, contextName, namespace, name string) (*k8s.PodTarget, error)
+	GetPodUID(ctx context.Context, contextName, namespace, name string) (types.UID, error)
+	EvictPod(ctx context.Context, contextName, namespace, name string) error
+	GetWorkloadReadiness(ctx context.Context, contextName, kind, namespace, name string) (k8s.WorkloadReadiness, error)
+	SetNodeUnschedulable(ctx context.Context, contextName, name string, unschedulable bool) (bool, error)
+	GetNodeSchedulingStatus(ctx context.Context, contextName, name string) (k8s.NodeSchedulingStatus, error)
+}
+
+// DisruptionWorkload is the workload expected to replace an evicted pod.
+type DisruptionWorkload struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Desired     int32  `json:"desired"`
+	ReadyBefore int32  `json:"readyBefore"`
+	ReadyNow    int32  `json:"readyNow"`
+}
+
+// DisruptionExperiment is one controlled disruption and what followed it.
+type DisruptionExperiment struct {
+	ID        string              `json:"id"`
+	Action    string              `json:"action"`
+	Cluster   string              `json:"cluster"`
+	Namespace string              `json:"namespace,omitempty"`
+	Pod       string              `json:"pod,omitempty"`
+	Node      string              `json:"node,omitempty"`
+	Workload  *DisruptionWorkload `json:"workload,omitempty"`
+	Status    string              `json:"status"`
+	Message   string              `json:"message,omitempty"`
+	StartedBy string              `json:"startedBy"`
+	StartedAt time.Time           `json:"startedAt"`
+	// DisruptedAt is when the pod was evicted or the node cordoned.
+	DisruptedAt *time.Time `json:"disruptedAt,omitempty"`
+	// RestoredAt is when a cordoned node was uncordoned.
+	RestoredAt  *time.Time `json:"restoredAt,omitempty"`
+	RecoveredAt *time.Time `json:"recoveredAt,omitempty"`
+	// RecoverySeconds runs from DisruptedAt for evictions and from
+	// RestoredAt for cordons.
+	RecoverySeconds *float64 `json:"recoverySeconds,omitempty"`
+	TimeoutSeconds  int      `json:"timeoutSeconds"`
+	HoldSeconds     int      `json:"holdSeconds,omitempty"`
+
+	actor   uuid.UUID
+	podUID  types.UID
+	timeout time.Duration
+	hold    time.Duration
+}
+
+func (e *DisruptionExperiment) targetType() string {
+	if e.Action == DisruptionCordonNode {
+		return "node"
+	}
+	return "pod"
+}
+
+func (e *DisruptionExperiment) targetID() string {
+	if e.Action == DisruptionCordonNode {
+		return e.Cluster + "/" + e.Node
+	}
+	return e.Cluster + "/" + e.Namespace + "/" + e.Pod
+}
+
+// disruptionRequest is the body accepted by POST /api/admin/disruptions.
+type disruptionRequest struct {
+	Action    string `json:"action"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Node      string `json:"node,omitempty"`
+	// TimeoutSeconds bounds the wait for recovery; nil uses the default.
+	TimeoutSeconds *int `json:"timeoutSeconds,omitempty"`
+	// HoldSeconds is how long a node stays cordoned; nil uses the default.
+	HoldSeconds *int `json:"holdSeconds,omitempty"`
+}
+
+// DisruptionHandler runs admin-only disruption experiments: evict one pod
+// or cordon one node, then time how long the cluster takes to recover.
+// Every experiment is audit logged when it starts and when it ends.
+type DisruptionHandler struct {
+	k8sClient    disruptionClient
+	store        store.Store
+	pollInterval time.Duration
+
+	mu          sync.Mutex
+	experiments map[string]*DisruptionExperiment
+	order       []string
+}
+
+// NewDisruptionHandler creates a disruption experiment handler.
+func NewDisruptionHandler(k8sClient *k8s.MultiClusterClient, s store.Store) *DisruptionHandler {
+	h := &DisruptionHandler{
+		store:        s,
+		pollInterval: disruptionPollInterval,
+		experiments:  make(map[string]*DisruptionExperiment),
+	}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+func validateDisruption(req *disruptionRequest) error {
+	if err := validateEnum("action", req.Action, []string{DisruptionEvictPod, DisruptionCordonNode}); err != nil {
+		return err
+	}
+	if err := validateClusterName("cluster", req.Cluster); err != nil {
+		return err
+	}
+	if req.Action == DisruptionEvictPod {
+		if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+			return err
+		}
+		if err := validateDNSSubdomain("pod", req.Pod); err != nil {
+			return err
+		}
+	} else if err := validateDNSSubdomain("node", req.Node); err != nil {
+		return err
+	}
+	if s := req.TimeoutSeconds; s != nil && (*s < 1 || time.Duration(*s)*time.Second > maxDisruptionTimeout) {
+		return fmt.Errorf("timeoutSeconds must be between 1 and %d", int(maxDisruptionTimeout.Seconds()))
+	}
+	if s := req.HoldSeconds; s != nil && (*s < 0 || time.Duration(*s)*time.Second > maxCordonHold) {
+		return fmt.Errorf("holdSeconds must be between 0 and %d", int(maxCordonHold.Seconds()))
+	}
+	return nil
+}
+
+// StartExperiment disrupts the target and returns 202 with the experiment;
+// recovery is watched in the background.
+// POST /api/admin/disruptions
+func (h *DisruptionHandler) StartExperiment(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	var req disruptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateDisruption(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	exp := &DisruptionExperiment{
+		ID:        uuid.NewString(),
+		Action:    req.Action,
+		Cluster:   req.Cluster,
+		Namespace: req.Namespace,
+		Pod:       req.Pod,
+		Node:      req.Node,
+		Status:    DisruptionRunning,
+		StartedAt: time.Now().UTC(),
+		actor:     middleware.GetUserID(c),
+		timeout:   defaultDisruptionTimeout,
+	}
+	exp.StartedBy = exp.actor.String()
+	if req.TimeoutSeconds != nil {
+		exp.timeout = time.Duration(*req.TimeoutSeconds) * time.Second
+	}
+	exp.TimeoutSeconds = int(exp.timeout.Seconds())
+	if req.Action == DisruptionCordonNode {
+		exp.hold = defaultCordonHold
+		if req.HoldSeconds != nil {
+			exp.hold = time.Duration(*req.HoldSeconds) * time.Second
+		}
+		exp.HoldSeconds = int(exp.hold.Seconds())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), disruptionRequestTimeout)
+	defer cancel()
+	if err := h.prepare(ctx, exp); err != nil {
+		return err
+	}
+	if err := h.reserve(exp); err != nil {
+		return err
+	}
+
+	var err error
+	if exp.Action == DisruptionEvictPod {
+		err = h.k8sClient.EvictPod(ctx, exp.Cluster, exp.Namespace, exp.Pod)
+	} else {
+		_, err = h.k8sClient.SetNodeUnschedulable(ctx, exp.Cluster, exp.Node, true)
+	}
+	action := audit.ActionChaosEvictPod
+	if exp.Action == DisruptionCordonNode {
+		action = audit.ActionChaosCordonNode
+	}
+	if err != nil {
+		h.finish(exp, DisruptionFailed, disruptionError(err))
+		audit.Log(c, action, exp.targetType(), exp.targetID(), "experiment", exp.ID, "rejected:", exp.Message)
+		if apierrors.IsTooManyRequests(err) {
+			return fiber.NewError(fiber.StatusConflict, exp.Message)
+		}
+		slog.Warn("[Disruption] disruption failed", "experiment", exp.ID, "target", exp.targetID(), "error", err)
+		return fiber.NewError(fiber.StatusBadGateway, exp.Message)
+	}
+	audit.Log(c, action, exp.targetType(), exp.targetID(), "experiment", exp.ID)
+
+	h.mu.Lock()
+	now := time.Now().UTC()
+	exp.DisruptedAt = &now
+	snapshot := h.copyLocked(exp)
+	h.mu.Unlock()
+
+	safego.GoWith("disruption/"+exp.ID, func() { h.watch(exp) })
+	return c.Status(fiber.StatusAccepted).JSON(snapshot)
+}
+
+// prepare checks the target before anything is disrupted: an evicted pod
+// must have a controller to replace it, and a node is only cordoned if no
+// one else cordoned it, because the experiment uncordons it afterwards.
+func (h *DisruptionHandler) prepare(ctx context.Context, exp *DisruptionExperiment) error {
+	if exp.Action == DisruptionCordonNode {
+		st, err := h.k8sClient.GetNodeSchedulingStatus(ctx, exp.Cluster, exp.Node)
+		if err != nil {
+			return disruptionLookupError(err, "node not found")
+		}
+		if st.Unschedulable {
+			return fiber.NewError(fiber.StatusConflict, "node is already cordoned")
+		}
+		return nil
+	}
+
+	target, err := h.k8sClient.GetPodTarget(ctx, exp.Cluster, exp.Namespace, exp.Pod)
+	if err != nil {
+		return disruptionLookupError(err, "pod not found")
+	}
+	if target.WorkloadKind == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "pod has no controller and would not be replaced")
+	}
+	if !slices.Contains(disruptableWorkloadKinds, target.WorkloadKind) {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("pods of a %s are not supported", target.WorkloadKind))
+	}
+	ready, err := h.k8sClient.GetWorkloadReadiness(ctx, exp.Cluster, target.WorkloadKind, exp.Namespace, target.WorkloadName)
+	if err != nil {
+		return disruptionLookupError(err, "pod's workload not found")
+	}
+	exp.podUID = target.UID
+	exp.Node = target.NodeName
+	exp.Workload = &DisruptionWorkload{
+		Kind:        target.WorkloadKind,
+		Name:        target.WorkloadName,
+		Desired:     ready.Desired,
+		ReadyBefore: ready.Ready,
+		ReadyNow:    ready.Ready,
+	}
+	return nil
+}
+
+// reserve records exp as running unless too many experiments are in flight
+// or one is already running against the same target.
+func (h *DisruptionHandler) reserve(exp *DisruptionExperiment) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	running := 0
+	for _, other := range h.experiments {
+		if other.Status != DisruptionRunning {
+			continue
+		}
+		running++
+		if other.targetID() == exp.targetID() || (exp.Action == DisruptionCordonNode && other.Cluster == exp.Cluster && other.Node == exp.Node) {
+			return fiber.NewError(fiber.StatusConflict, "an experiment is already running against this target")
+		}
+	}
+	if running >= maxRunningDisruptions {
+		return fiber.NewError(fiber.StatusTooManyRequests, fmt.Sprintf("at most %d experiments can run at once", maxRunningDisruptions))
+	}
+	h.experiments[exp.ID] = exp
+	h.order = append(h.order, exp.ID)
+	for len(h.order) > maxDisruptionHistory {
+		oldest := h.experiments[h.order[0]]
+		if oldest != nil && oldest.Status == DisruptionRunning {
+			break
+		}
+		delete(h.experiments, h.order[0])
+		h.order = h.order[1:]
+	}
+	return nil
+}
+
+// watch waits for recovery and records the outcome. A cordoned node is
+// uncordoned after the hold even when the experiment fails.
+func (h *DisruptionHandler) watch(exp *DisruptionExperiment) {
+	ctx := context.Background()
+	since := *exp.DisruptedAt
+
+	if exp.Action == DisruptionCordonNode {
+		time.Sleep(exp.hold)
+		uncordonCtx, cancel := context.WithTimeout(ctx, disruptionRequestTimeout)
+		_, err := h.k8sClient.SetNodeUnschedulable(uncordonCtx, exp.Cluster, exp.Node, false)
+		cancel()
+		if err != nil {
+			slog.Error("[Disruption] failed to uncordon node", "experiment", exp.ID, "target", exp.targetID(), "error", err)
+			h.finish(exp, DisruptionFailed, "failed to uncordon node; uncordon it manually")
+			return
+		}
+		h.mu.Lock()
+		now := time.Now().UTC()
+		exp.RestoredAt = &now
+		h.mu.Unlock()
+		since = now
+	}
+
+	deadline := since.Add(exp.timeout)
+	ticker := time.NewTicker(h.pollInterval)
+	defer ticker.Stop()
+	for {
+		recovered, err := h.recovered(ctx, exp)
+		if err != nil {
+			slog.Info("[Disruption] recovery check failed", "experiment", exp.ID, "error", err)
+		}
+		if recovered {
+			now := time.Now().UTC()
+			secs := now.Sub(since).Seconds()
+			h.mu.Lock()
+			exp.RecoveredAt, exp.RecoverySeconds = &now, &secs
+			h.mu.Unlock()
+			h.finish(exp, DisruptionRecovered, fmt.Sprintf("recovered in %.1fs", secs))
+			return
+		}
+		if time.Now().After(deadline) {
+			h.finish(exp, DisruptionTimedOut, fmt.Sprintf("not recovered within %s", exp.timeout))
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// recovered reports whether an evicted pod is gone and its workload is back
+// to the ready count it had before, or a node is Ready and schedulable.
+func (h *DisruptionHandler) recovered(ctx context.Context, exp *DisruptionExperiment) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, disruptionRequestTimeout)
+	defer cancel()
+
+	if exp.Action == DisruptionCordonNode {
+		st, err := h.k8sClient.GetNodeSchedulingStatus(ctx, exp.Cluster, exp.Node)
+		if err != nil {
+			return false, err
+		}
+		return st.Ready && !st.Unschedulable, nil
+	}
+
+	uid, err := h.k8sClient.GetPodUID(ctx, exp.Cluster, exp.Namespace, exp.Pod)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil && uid == exp.podUID {
+		return false, nil
+	}
+	w := exp.Workload
+	ready, err := h.k8sClient.GetWorkloadReadiness(ctx, exp.Cluster, w.Kind, exp.Namespace, w.Name)
+	if err != nil {
+		return false, err
+	}
+	h.mu.Lock()
+	w.ReadyNow = ready.Ready
+	h.mu.Unlock()
+	return ready.Ready >= min(w.ReadyBefore, ready.Desired), nil
+}
+
+// finish records the outcome of exp and audit logs it.
+func (h *DisruptionHandler) finish(exp *DisruptionExperiment, status, message string) {
+	h.mu.Lock()
+	exp.Status, exp.Message = status, message
+	h.mu.Unlock()
+	if exp.DisruptedAt == nil {
+		// Nothing was disrupted; StartExperiment audit logs the rejection.
+		return
+	}
+	audit.LogAsync(context.Background(), exp.actor, audit.ActionChaosExperimentResult, exp.targetType(), exp.targetID(),
+		"experiment", exp.ID, status+":", message)
+}
+
+// ListExperiments returns the retained experiments, newest first.
+// GET /api/admin/disruptions
+func (h *DisruptionHandler) ListExperiments(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	out := make([]DisruptionExperiment, 0, len(h.order))
+	for i := len(h.order) - 1; i >= 0; i-- {
+		out = append(out, h.copyLocked(h.experiments[h.order[i]]))
+	}
+	h.mu.Unlock()
+	return c.JSON(fiber.Map{"experiments": out})
+}
+
+// GetExperiment returns one experiment.
+// GET /api/admin/disruptions/:id
+func (h *DisruptionHandler) GetExperiment(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	exp, ok := h.experiments[c.Params("id")]
+	var out DisruptionExperiment
+	if ok {
+		out = h.copyLocked(exp)
+	}
+	h.mu.Unlock()
+	if !ok {
+		return fiber.NewError(fiber.StatusNotFound, "experiment not found")
+	}
+	return c.JSON(out)
+}
+
+// copyLocked returns a copy of exp that is safe to serialise after h.mu is
+// released. Callers must hold h.mu.
+func (h *DisruptionHandler) copyLocked(exp *DisruptionExperiment) DisruptionExperiment {
+	out := *exp
+	if exp.Workload != nil {
+		w := *exp.Workload
+		out.Workload = &w
+	}
+	return out
+}
+
+// disruptionLookupError maps a failed target lookup to a response.
+func disruptionLookupError(err error, notFound string) error {
+	if apierrors.IsNotFound(err) {
+		return fiber.NewError(fiber.StatusNotFound, notFound)
+	}
+	slog.Info("[Disruption] target lookup failed", "error", err)
+	return fiber.NewError(fiber.StatusBadGateway, disruptionError(err))
+}
+
+// disruptionError turns a cluster error into a message safe to return.
+func disruptionError(err error) string {
+	if apierrors.IsTooManyRequests(err) {
+		return "eviction blocked by a PodDisruptionBudget"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "cluster did not respond in time"
+	}
+	if msg, ok := SanitizedErrorMessages[k8s.ClassifyError(err.Error())]; ok {
+		return msg
+	}
+	return "cluster request failed"
+}
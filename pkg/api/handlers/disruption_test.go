@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+// fakeDisruptionClient models one pod of a two-replica Deployment and one
+// node. An evicted pod is replaced after readyAfter readiness polls.
+type fakeDisruptionClient struct {
+	mu         sync.Mutex
+	evicted    bool
+	evictErr   error
+	readyAfter int
+	readyPolls int
+	cordoned   bool
+}
+
+func (f *fakeDisruptionClient) GetPodTarget(_ context.Context, _, _, name string) (*k8s.PodTarget, error) {
+	switch name {
+	case "api-1":
+		return &k8s.PodTarget{UID: "uid-1", NodeName: "node-a", WorkloadKind: k8s.WorkloadKindDeployment, WorkloadName: "api"}, nil
+	case "debug":
+		return &k8s.PodTarget{UID: "uid-2"}, nil
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+}
+
+func (f *fakeDisruptionClient) GetPodUID(_ context.Context, _, _, name string) (types.UID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.evicted {
+		return "", apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, name)
+	}
+	return "uid-1", nil
+}
+
+func (f *fakeDisruptionClient) EvictPod(context.Context, string, string, string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.evictErr != nil {
+		return f.evictErr
+	}
+	f.evicted = true
+	return nil
+}
+
+func (f *fakeDisruptionClient) GetWorkloadReadiness(context.Context, string, string, string, string) (k8s.WorkloadReadiness, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.evicted {
+		return k8s.WorkloadReadiness{Desired: 2, Ready: 2}, nil
+	}
+	f.readyPolls++
+	if f.readyPolls > f.readyAfter {
+		return k8s.WorkloadReadiness{Desired: 2, Ready: 2}, nil
+	}
+	return k8s.WorkloadReadiness{Desired: 2, Ready: 1}, nil
+}
+
+func (f *fakeDisruptionClient) SetNodeUnschedulable(_ context.Context, _, _ string, unschedulable bool) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	was := f.cordoned
+	f.cordoned = unschedulable
+	return was, nil
+}
+
+func (f *fakeDisruptionClient) GetNodeSchedulingStatus(context.Context, string, string) (k8s.NodeSchedulingStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return k8s.NodeSchedulingStatus{Ready: true, Unschedulable: f.cordoned}, nil
+}
+
+// auditRecordingStore captures the actions written to the audit log.
+type auditRecordingStore struct {
+	*test.MockStore
+	mu      sync.Mutex
+	actions []string
+}
+
+func (s *auditRecordingStore) InsertAuditLog(_ context.Context, _, action, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, action)
+	return nil
+}
+
+func (s *auditRecordingStore) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.actions...)
+}
+
+func setupDisruptionApp(t *testing.T, client *fakeDisruptionClient) (*testEnv, *DisruptionHandler, *auditRecordingStore) {
+	t.Helper()
+	env := setupTestEnv(t)
+	rec := &auditRecordingStore{MockStore: env.Store.(*test.MockStore)}
+	audit.SetStore(rec)
+	t.Cleanup(func() { audit.SetStore(nil) })
+
+	h := NewDisruptionHandler(nil, env.Store)
+	h.k8sClient = client
+	h.pollInterval = 5 * time.Millisecond
+	env.App.Post("/api/admin/disruptions", h.StartExperiment)
+	env.App.Get("/api/admin/disruptions", h.ListExperiments)
+	env.App.Get("/api/admin/disruptions/:id", h.GetExperiment)
+	return env, h, rec
+}
+
+func startDisruption(t *testing.T, env *testEnv, body map[string]any) (int, DisruptionExperiment) {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/disruptions", bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	var out DisruptionExperiment
+	if resp.StatusCode == http.StatusAccepted {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out
+}
+
+func waitForExperiment(t *testing.T, env *testEnv, id string) DisruptionExperiment {
+	t.Helper()
+	var out DisruptionExperiment
+	require.Eventually(t, func() bool {
+		resp, err := env.App.Test(httptest.NewRequest(http.MethodGet, "/api/admin/disruptions/"+id, nil), 5000)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return out.Status != DisruptionRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return out
+}
+
+func TestDisruption_EvictPodRecovers(t *testing.T) {
+	client := &fakeDisruptionClient{readyAfter: 3}
+	env, _, rec := setupDisruptionApp(t, client)
+
+	status, started := startDisruption(t, env, map[string]any{
+		"action": DisruptionEvictPod, "cluster": "prod", "namespace": "shop", "pod": "api-1",
+	})
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, DisruptionRunning, started.Status)
+	assert.Equal(t, "node-a", started.Node)
+	require.NotNil(t, started.Workload)
+	assert.Equal(t, int32(2), started.Workload.ReadyBefore)
+
+	done := waitForExperiment(t, env, started.ID)
+	assert.Equal(t, DisruptionRecovered, done.Status)
+	require.NotNil(t, done.RecoverySeconds)
+	assert.Greater(t, *done.RecoverySeconds, 0.0)
+	assert.Equal(t, int32(2), done.Workload.ReadyNow)
+	assert.Equal(t, testAdminUserID.String(), done.StartedBy)
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{audit.ActionChaosEvictPod, audit.ActionChaosExperimentResult}, rec.recorded())
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestDisruption_CordonNodeIsUncordoned(t *testing.T) {
+	client := &fakeDisruptionClient{}
+	env, _, _ := setupDisruptionApp(t, client)
+
+	status, started := startDisruption(t, env, map[string]any{
+		"action": DisruptionCordonNode, "cluster": "prod", "node": "node-a", "holdSeconds": 0,
+	})
+	require.Equal(t, http.StatusAccepted, status)
+	done := waitForExperiment(t, env, started.ID)
+	assert.Equal(t, DisruptionRecovered, done.Status)
+	require.NotNil(t, done.RestoredAt)
+	client.mu.Lock()
+	assert.False(t, client.cordoned, "the node is uncordoned after the hold")
+	client.mu.Unlock()
+
+	status, _ = startDisruption(t, env, map[string]any{"action": DisruptionCordonNode, "cluster": "prod", "node": "node-a", "holdSeconds": 1})
+	require.Equal(t, http.StatusAccepted, status)
+	status, _ = startDisruption(t, env, map[string]any{"action": DisruptionCordonNode, "cluster": "prod", "node": "node-a"})
+	assert.Equal(t, http.StatusConflict, status, "a node cordoned by someone else is left alone")
+}
+
+func TestDisruption_Rejections(t *testing.T) {
+	client := &fakeDisruptionClient{evictErr: apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)}
+	env, h, _ := setupDisruptionApp(t, client)
+
+	cases := []struct {
+		name   string
+		body   map[string]any
+		status int
+	}{
+		{"unknown action", map[string]any{"action": "delete_namespace", "cluster": "prod"}, http.StatusBadRequest},
+		{"bad namespace", map[string]any{"action": DisruptionEvictPod, "cluster": "prod", "namespace": "Bad_NS", "pod": "api-1"}, http.StatusBadRequest},
+		{"timeout too long", map[string]any{"action": DisruptionCordonNode, "cluster": "prod", "node": "n", "timeoutSeconds": 7200}, http.StatusBadRequest},
+		{"missing pod", map[string]any{"action": DisruptionEvictPod, "cluster": "prod", "namespace": "shop", "pod": "gone"}, http.StatusNotFound},
+		{"bare pod", map[string]any{"action": DisruptionEvictPod, "cluster": "prod", "namespace": "shop", "pod": "debug"}, http.StatusBadRequest},
+		{"blocked by PDB", map[string]any{"action": DisruptionEvictPod, "cluster": "prod", "namespace": "shop", "pod": "api-1"}, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		status, _ := startDisruption(t, env, tc.body)
+		assert.Equal(t, tc.status, status, tc.name)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	require.Len(t, h.order, 1, "only the PDB-blocked eviction got as far as the cluster")
+	exp := h.experiments[h.order[0]]
+	assert.Equal(t, DisruptionFailed, exp.Status)
+	assert.Equal(t, "eviction blocked by a PodDisruptionBudget", exp.Message)
+}
+
+func TestDisruption_RequiresAdmin(t *testing.T) {
+	env := setupTestEnv(t)
+	viewer := uuid.New()
+	env.Store.(*test.MockStore).On("GetUser", viewer).Return(&models.User{ID: viewer, Role: models.UserRoleViewer}, nil)
+	h := NewDisruptionHandler(nil, env.Store)
+	h.k8sClient = &fakeDisruptionClient{}
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", viewer)
+		return c.Next()
+	})
+	app.Get("/api/admin/disruptions", h.ListExperiments)
+	app.Post("/api/admin/disruptions", h.StartExperiment)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/admin/disruptions", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/disruptions",
+		bytes.NewReader([]byte(`{"action":"cordon_node","cluster":"prod","node":"node-a"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, h.order)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/featureflags"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// FeatureFlagsHandler serves the feature flag admin API and /api/capabilities.
+type FeatureFlagsHandler struct {
+	flags *featureflags.Manager
+	store store.Store
+}
+
+// NewFeatureFlagsHandler creates a handler for the given flag manager.
+func NewFeatureFlagsHandler(flags *featureflags.Manager, s store.Store) *FeatureFlagsHandler {
+	return &FeatureFlagsHandler{flags: flags, store: s}
+}
+
+// flagUser identifies the caller for flag evaluation.
+func flagUser(c *fiber.Ctx) featureflags.User {
+	user := featureflags.User{Login: middleware.GetGitHubLogin(c)}
+	if id := middleware.GetUserID(c); id != uuid.Nil {
+		user.ID = id.String()
+	}
+	return user
+}
+
+// RequireFeature rejects requests with 403 while flag key is off for the caller.
+func (h *FeatureFlagsHandler) RequireFeature(key string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !h.flags.IsEnabled(key, flagUser(c)) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   "feature disabled",
+				"feature": key,
+			})
+		}
+		return c.Next()
+	}
+}
+
+// GetCapabilities reports which optional features are on for the caller.
+// GET /api/capabilities
+func (h *FeatureFlagsHandler) GetCapabilities(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"featureFlags": h.flags.Active(flagUser(c)),
+	})
+}
+
+// ListFlags returns every flag with its configuration.
+// GET /api/admin/feature-flags
+func (h *FeatureFlagsHandler) ListFlags(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"flags": h.flags.List(flagUser(c))})
+}
+
+// UpdateFlag changes a flag's global state.
+// PUT /api/admin/feature-flags/:key
+func (h *FeatureFlagsHandler) UpdateFlag(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	key := c.Params("key")
+	var update featureflags.Update
+	if err := c.BodyParser(&update); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if update.Enabled == nil && update.Percentage == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "enabled or percentage is required"})
+	}
+	if err := h.flags.Set(key, update); err != nil {
+		return flagError(c, err)
+	}
+	audit.Log(c, audit.ActionUpdateFeatureFlag, "feature_flag", key, describeUpdate(update))
+	return h.respondFlag(c, key)
+}
+
+// ResetFlag returns a flag to its default state.
+// DELETE /api/admin/feature-flags/:key
+func (h *FeatureFlagsHandler) ResetFlag(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	key := c.Params("key")
+	if err := h.flags.Reset(key); err != nil {
+		return flagError(c, err)
+	}
+	audit.Log(c, audit.ActionUpdateFeatureFlag, "feature_flag", key, "reset")
+	return h.respondFlag(c, key)
+}
+
+// SetUserOverride forces a flag on or off for one user (ID or GitHub login).
+// PUT /api/admin/feature-flags/:key/users/:user
+func (h *FeatureFlagsHandler) SetUserOverride(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	key, user := c.Params("key"), c.Params("user")
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "enabled is required"})
+	}
+	if err := h.flags.SetUserOverride(key, user, body.Enabled); err != nil {
+		return flagError(c, err)
+	}
+	audit.Log(c, audit.ActionUpdateFeatureFlag, "feature_flag", key, fmt.Sprintf("user %s enabled=%t", user, *body.Enabled))
+	return h.respondFlag(c, key)
+}
+
+// DeleteUserOverride removes a per-user override.
+// DELETE /api/admin/feature-flags/:key/users/:user
+func (h *FeatureFlagsHandler) DeleteUserOverride(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	key, user := c.Params("key"), c.Params("user")
+	if err := h.flags.SetUserOverride(key, user, nil); err != nil {
+		return flagError(c, err)
+	}
+	audit.Log(c, audit.ActionUpdateFeatureFlag, "feature_flag", key, fmt.Sprintf("user %s override removed", user))
+	return h.respondFlag(c, key)
+}
+
+func (h *FeatureFlagsHandler) respondFlag(c *fiber.Ctx, key string) error {
+	for _, s := range h.flags.List(flagUser(c)) {
+		if s.Key == key {
+			return c.JSON(s)
+		}
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "unknown feature flag"})
+}
+
+func flagError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, featureflags.ErrUnknownFlag):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "unknown feature flag"})
+	case errors.Is(err, featureflags.ErrInvalidUpdate):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		slog.Error("[FeatureFlags] failed to save feature flags", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save feature flags"})
+	}
+}
+
+func describeUpdate(u featureflags.Update) string {
+	desc := ""
+	if u.Enabled != nil {
+		desc = fmt.Sprintf("enabled=%t", *u.Enabled)
+	}
+	if u.Percentage != nil {
+		if desc != "" {
+			desc += " "
+		}
+		desc += fmt.Sprintf("percentage=%d", *u.Percentage)
+	}
+	return desc
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/featureflags"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memFlagStore struct {
+	flags map[string]settings.FeatureFlagSetting
+}
+
+func (m *memFlagStore) GetFeatureFlags() map[string]settings.FeatureFlagSetting {
+	out := make(map[string]settings.FeatureFlagSetting, len(m.flags))
+	for k, v := range m.flags {
+		out[k] = v
+	}
+	return out
+}
+
+func (m *memFlagStore) SaveFeatureFlags(flags map[string]settings.FeatureFlagSetting) error {
+	m.flags = flags
+	return nil
+}
+
+func setupFeatureFlagsApp(t *testing.T, role models.UserRole) (*fiber.App, *featureflags.Manager) {
+	t.Helper()
+	app := fiber.New()
+	mockStore := new(test.MockStore)
+	userID := uuid.New()
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	flags := featureflags.NewManager(&memFlagStore{})
+	h := NewFeatureFlagsHandler(flags, mockStore)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/capabilities", h.GetCapabilities)
+	app.Get("/api/admin/feature-flags", h.ListFlags)
+	app.Put("/api/admin/feature-flags/:key", h.UpdateFlag)
+	app.Delete("/api/admin/feature-flags/:key", h.ResetFlag)
+	app.Put("/api/admin/feature-flags/:key/users/:user", h.SetUserOverride)
+	app.Delete("/api/admin/feature-flags/:key/users/:user", h.DeleteUserOverride)
+	app.Post("/api/tools", h.RequireFeature(featureflags.AITools), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	return app, flags
+}
+
+func doFlagRequest(t *testing.T, app *fiber.App, method, path, body string) (int, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, data
+}
+
+func TestFeatureFlags_ViewerForbidden(t *testing.T) {
+	app, _ := setupFeatureFlagsApp(t, models.UserRoleViewer)
+	for _, r := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/admin/feature-flags", ""},
+		{http.MethodPut, "/api/admin/feature-flags/relay-mode", `{"enabled":true}`},
+		{http.MethodDelete, "/api/admin/feature-flags/relay-mode", ""},
+		{http.MethodPut, "/api/admin/feature-flags/relay-mode/users/octocat", `{"enabled":true}`},
+	} {
+		status, _ := doFlagRequest(t, app, r.method, r.path, r.body)
+		assert.Equal(t, http.StatusForbidden, status, "%s %s", r.method, r.path)
+	}
+
+	// Capabilities are readable by everyone.
+	status, body := doFlagRequest(t, app, http.MethodGet, "/api/capabilities", "")
+	require.Equal(t, http.StatusOK, status)
+	var caps struct {
+		FeatureFlags map[string]bool `json:"featureFlags"`
+	}
+	require.NoError(t, json.Unmarshal(body, &caps))
+	assert.True(t, caps.FeatureFlags[featureflags.AITools])
+	assert.False(t, caps.FeatureFlags[featureflags.CanaryEngine])
+}
+
+func TestFeatureFlags_AdminUpdate(t *testing.T) {
+	app, flags := setupFeatureFlagsApp(t, models.UserRoleAdmin)
+
+	status, _ := doFlagRequest(t, app, http.MethodPut, "/api/admin/feature-flags/canary-engine", `{"enabled":true,"percentage":100}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, flags.IsEnabled(featureflags.CanaryEngine, featureflags.User{ID: "any"}))
+
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/feature-flags/relay-mode", `{"percentage":50}`)
+	assert.Equal(t, http.StatusBadRequest, status, "boolean flags take no percentage")
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/feature-flags/relay-mode", `{}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/feature-flags/nope", `{"enabled":true}`)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/feature-flags/relay-mode/users/octocat", `{"enabled":true}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, flags.IsEnabled(featureflags.RelayMode, featureflags.User{Login: "octocat"}))
+	status, _ = doFlagRequest(t, app, http.MethodDelete, "/api/admin/feature-flags/relay-mode/users/octocat", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, flags.IsEnabled(featureflags.RelayMode, featureflags.User{Login: "octocat"}))
+
+	status, body := doFlagRequest(t, app, http.MethodGet, "/api/admin/feature-flags", "")
+	require.Equal(t, http.StatusOK, status)
+	var list struct {
+		Flags []featureflags.State `json:"flags"`
+	}
+	require.NoError(t, json.Unmarshal(body, &list))
+	assert.Len(t, list.Flags, len(featureflags.Definitions()))
+
+	status, _ = doFlagRequest(t, app, http.MethodDelete, "/api/admin/feature-flags/canary-engine", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, flags.IsEnabled(featureflags.CanaryEngine, featureflags.User{ID: "any"}))
+}
+
+func TestFeatureFlags_RequireFeature(t *testing.T) {
+	app, flags := setupFeatureFlagsApp(t, models.UserRoleViewer)
+
+	status, _ := doFlagRequest(t, app, http.MethodPost, "/api/tools", "")
+	assert.Equal(t, http.StatusNoContent, status)
+
+	disabled := false
+	require.NoError(t, flags.Set(featureflags.AITools, featureflags.Update{Enabled: &disabled}))
+	status, body := doFlagRequest(t, app, http.MethodPost, "/api/tools", "")
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, string(body), featureflags.AITools)
+}
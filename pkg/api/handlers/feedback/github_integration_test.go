@@ -843,6 +843,52 @@ func TestCreateGitHubIssueInRepo_WithConsoleErrors(t *testing.T) {
 	assert.Contains(t, capturedBody, "(react)")
 }
 
+func TestCreateGitHubIssueInRepo_RedactsSecrets(t *testing.T) {
+	var capturedBody string
+	handler := &FeedbackHandler{
+		githubToken: "test-token",
+		repoOwner:   "kubestellar",
+		repoName:    "console",
+		httpClient: &http.Client{
+			Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+				body, _ := io.ReadAll(req.Body)
+				var payload map[string]interface{}
+				_ = json.Unmarshal(body, &payload)
+				capturedBody = payload["body"].(string)
+
+				return &http.Response{
+					StatusCode: http.StatusCreated,
+					Body:       io.NopCloser(strings.NewReader(`{"id":1,"number":46,"html_url":"https://github.com/kubestellar/console/issues/46"}`)),
+					Header:     make(http.Header),
+				}
+			}),
+		},
+	}
+
+	request := &models.FeatureRequest{
+		ID:          uuid.New(),
+		Title:       "Leaky errors",
+		Description: "Description here",
+		RequestType: models.RequestTypeBug,
+		TargetRepo:  models.TargetRepoConsole,
+	}
+	user := &models.User{ID: uuid.New(), GitHubLogin: "user1"}
+	consoleErrors := []models.ConsoleError{
+		{Timestamp: "2026-01-01T00:00:00Z", Level: "error", Message: "401 for Bearer abcdef0123456789xyz"},
+	}
+	failedCalls := []models.FailedApiCall{
+		{Timestamp: "2026-01-01T00:00:01Z", Status: "403", Endpoint: "/api/stream?token=s3cr3t-value", Detail: "forbidden"},
+	}
+
+	_, _, _, _, err := handler.createGitHubIssueInRepo(context.Background(), request, user, "kubestellar", "console", nil, consoleErrors, failedCalls, nil, nil, "")
+	require.NoError(t, err)
+
+	assert.NotContains(t, capturedBody, "abcdef0123456789xyz")
+	assert.NotContains(t, capturedBody, "s3cr3t-value")
+	assert.Contains(t, capturedBody, "Bearer [REDACTED]")
+	assert.Contains(t, capturedBody, "/api/stream?token=[REDACTED]")
+}
+
 func TestCreateGitHubIssueInRepo_WithDiagnostics(t *testing.T) {
 	var capturedBody string
 	handler := &FeedbackHandler{
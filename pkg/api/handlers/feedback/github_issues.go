@@ -12,6 +12,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/sanitize"
 )
 
 func (h *FeedbackHandler) handleIssueEvent(ctx context.Context, payload map[string]interface{}) error {
@@ -472,7 +473,8 @@ func (h *FeedbackHandler) createGitHubIssueInRepo(ctx context.Context, request *
 %s%s%s%s
 ---
 *This issue was automatically created from the KubeStellar Console.*
-`, request.RequestType, repoLabel, user.GitHubLogin, request.ID.String(), request.Description, shaLine, consoleErrorBlock, failedApiBlock, diagnosticsBlock)
+`, request.RequestType, repoLabel, user.GitHubLogin, request.ID.String(), request.Description, shaLine,
+		sanitize.Secrets(consoleErrorBlock), sanitize.Secrets(failedApiBlock), sanitize.Secrets(diagnosticsBlock))
 
 	// First attempt: create issue with labels
 	createdIssue, err := h.postGitHubIssue(ctx, repoOwner, repoName, request.Title, issueBody, labels, parentIssueNumber, clientAuth)
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/i18n"
+)
+
+// requestLanguage negotiates the language of a response from the caller's
+// Accept-Language header and marks the response as varying by it.
+func requestLanguage(c *fiber.Ctx) string {
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return i18n.Default().Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+}
+
+// localizedErrorBody is an error response of the catalog message key in the
+// caller's language. code carries the key so the UI can render the error from
+// its own catalogs instead.
+func localizedErrorBody(c *fiber.Ctx, key string, params i18n.Params) fiber.Map {
+	return fiber.Map{
+		"error": i18n.Default().Localize(requestLanguage(c), key, params),
+		"code":  key,
+	}
+}
+
+// localizedError responds with status and the localized message of key.
+func localizedError(c *fiber.Ctx, status int, key string) error {
+	return c.Status(status).JSON(localizedErrorBody(c, key, nil))
+}
+
+// localizeDeploymentStatus translates the rollout messages of wd, which the
+// reconciler records in English, into lang. Messages no catalog template
+// matches are left as stored, as are error details and freeze reasons
+// embedded in the ones that match.
+func localizeDeploymentStatus(lang string, wd *v1alpha1.WorkloadDeployment) {
+	if wd == nil || lang == i18n.DefaultLanguage {
+		return
+	}
+	loc := i18n.Default()
+	for i := range wd.Status.ClusterStatuses {
+		_, wd.Status.ClusterStatuses[i].Message = loc.Translate(lang, wd.Status.ClusterStatuses[i].Message)
+	}
+	for i := range wd.Status.History {
+		_, wd.Status.History[i].Message = loc.Translate(lang, wd.Status.History[i].Message)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizedError(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return localizedError(c, fiber.StatusServiceUnavailable, "server.unavailable")
+	})
+
+	for _, tc := range []struct {
+		acceptLanguage, want string
+	}{
+		{"", "service unavailable"},
+		{"es-ES,es;q=0.9", "servicio no disponible"},
+		{"xx", "service unavailable"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderAcceptLanguage, tc.acceptLanguage)
+		resp, err := app.Test(req, fiberTestTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get(fiber.HeaderVary), fiber.HeaderAcceptLanguage)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, tc.want, body["error"], tc.acceptLanguage)
+		assert.Equal(t, "server.unavailable", body["code"])
+	}
+}
+
+func TestLocalizeDeploymentStatus(t *testing.T) {
+	wd := &v1alpha1.WorkloadDeployment{Status: v1alpha1.WorkloadDeploymentStatus{
+		ClusterStatuses: []v1alpha1.ClusterRolloutStatus{
+			{Cluster: "a", Message: "Deployed successfully"},
+			{Cluster: "b", Message: "Health check failed: context deadline exceeded"},
+			{Cluster: "c", Message: "not a catalog message"},
+		},
+		History: []v1alpha1.DeploymentHistoryEntry{{Message: "Partial deployment: 1 succeeded, 1 failed"}},
+	}}
+
+	localizeDeploymentStatus("en", wd)
+	assert.Equal(t, "Deployed successfully", wd.Status.ClusterStatuses[0].Message)
+
+	localizeDeploymentStatus("es", wd)
+	assert.Equal(t, "Desplegado correctamente", wd.Status.ClusterStatuses[0].Message)
+	assert.Equal(t, "Falló la comprobación de estado: context deadline exceeded", wd.Status.ClusterStatuses[1].Message)
+	assert.Equal(t, "not a catalog message", wd.Status.ClusterStatuses[2].Message)
+	assert.Equal(t, "Despliegue parcial: 1 correctos, 1 fallidos", wd.Status.History[0].Message)
+}
@@ -0,0 +1,527 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/handlers/benchmarks"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// maxIncidentWindow bounds :window to the default event retention; older
+	// events have been swept from the journal.
+	maxIncidentWindow = 7 * 24 * time.Hour
+	// minIncidentWindow keeps the window meaningful at a one-minute poll.
+	minIncidentWindow = time.Minute
+	// correlationLookahead is how soon after a change an effect must follow
+	// on the same cluster to be hinted as related.
+	correlationLookahead = 30 * time.Minute
+	// minWarningSpike is the number of warning events after a deployment that
+	// counts as an error spike.
+	minWarningSpike = 3
+	// maxCorrelationHints bounds the hints in one response.
+	maxCorrelationHints = 100
+	// maxHintEffects bounds the entries one hint references.
+	maxHintEffects = 20
+	// incidentAuditLimit is how many recent manifest applies are read.
+	incidentAuditLimit = 200
+	// incidentSourceTimeout bounds the prediction fetch from kc-agent.
+	incidentSourceTimeout = 5 * time.Second
+	// maxPredictionResponseSize caps the kc-agent predictions response.
+	maxPredictionResponseSize = 1 << 20
+)
+
+// Incident timeline entry categories.
+const (
+	IncidentCategoryDeployment    = "deployment"
+	IncidentCategoryClusterHealth = "cluster_health"
+	IncidentCategoryPrediction    = "prediction"
+	IncidentCategoryEvent         = "kubernetes_event"
+	IncidentCategoryRegression    = "benchmark_regression"
+)
+
+// Source states reported in the response so clients can tell an empty
+// category from one that could not be read.
+const (
+	incidentSourceOK          = "ok"
+	incidentSourceUnavailable = "unavailable"
+	incidentSourceDisabled    = "disabled"
+)
+
+// IncidentPrediction is an AI prediction as served by kc-agent's
+// /predictions/ai endpoint.
+type IncidentPrediction struct {
+	ID          string `json:"id"`
+	Category    string `json:"category"`
+	Severity    string `json:"severity"`
+	Name        string `json:"name"`
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace,omitempty"`
+	Reason      string `json:"reason"`
+	Confidence  int    `json:"confidence"`
+	GeneratedAt string `json:"generatedAt"`
+}
+
+// PredictionSource returns the current AI predictions.
+type PredictionSource interface {
+	Predictions(ctx context.Context) ([]IncidentPrediction, error)
+}
+
+// RegressionSource returns the benchmark regressions of runs that ended in a
+// time range. *benchmarks.BenchmarkHandlers satisfies it.
+type RegressionSource interface {
+	Regressions(since, until time.Time) []benchmarks.Regression
+}
+
+// AgentPredictionSource reads predictions from the co-located kc-agent.
+type AgentPredictionSource struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewAgentPredictionSource returns a source for the kc-agent at baseURL.
+// token is sent as a bearer token when set.
+func NewAgentPredictionSource(baseURL, token string) *AgentPredictionSource {
+	return &AgentPredictionSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: incidentSourceTimeout},
+	}
+}
+
+// Predictions fetches the predictions kc-agent last generated.
+func (a *AgentPredictionSource) Predictions(ctx context.Context) ([]IncidentPrediction, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/predictions/ai", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kc-agent returned status %d", resp.StatusCode)
+	}
+	var body struct {
+		Predictions []IncidentPrediction `json:"predictions"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPredictionResponseSize)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode predictions: %w", err)
+	}
+	return body.Predictions, nil
+}
+
+// IncidentEntry is one item of the merged incident timeline.
+type IncidentEntry struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Category  string    `json:"category"`
+	Severity  string    `json:"severity"` // info, warning, critical
+	Cluster   string    `json:"cluster,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Kind      string    `json:"kind,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Summary   string    `json:"summary"`
+	Count     int       `json:"count,omitempty"`
+}
+
+// CorrelationHint links a change to the entries that followed it on the same
+// cluster. Hints are heuristics: they show temporal order, not causation.
+type CorrelationHint struct {
+	Type       string   `json:"type"`
+	Cluster    string   `json:"cluster,omitempty"`
+	CauseID    string   `json:"cause_id"`
+	EffectIDs  []string `json:"effect_ids"`
+	LagSeconds int64    `json:"lag_seconds"`
+	Message    string   `json:"message"`
+}
+
+// Correlation hint types.
+const (
+	hintDeploymentWarnings   = "deployment_preceded_warnings"
+	hintDeploymentUnhealthy  = "deployment_preceded_unhealthy"
+	hintDeploymentRegression = "deployment_preceded_regression"
+	hintPredictionUnhealthy  = "prediction_preceded_unhealthy"
+)
+
+// IncidentCorrelation is the response of GET /api/incidents/:window/correlate.
+type IncidentCorrelation struct {
+	Window  string            `json:"window"`
+	Since   time.Time         `json:"since"`
+	Until   time.Time         `json:"until"`
+	Entries []IncidentEntry   `json:"entries"`
+	Hints   []CorrelationHint `json:"hints"`
+	Sources map[string]string `json:"sources"`
+	// Truncated is set when the event journal held more events in the window
+	// than one response returns.
+	Truncated bool `json:"truncated"`
+}
+
+// IncidentHandler merges the change and failure signals of the console into
+// one timeline for incident review.
+type IncidentHandler struct {
+	store       store.Store
+	predictions PredictionSource
+	regressions RegressionSource
+}
+
+// NewIncidentHandler creates an incident handler. predictions and regressions
+// may be nil; their categories are then reported as disabled.
+func NewIncidentHandler(s store.Store, predictions PredictionSource, regressions RegressionSource) *IncidentHandler {
+	return &IncidentHandler{store: s, predictions: predictions, regressions: regressions}
+}
+
+// parseIncidentWindow accepts a Go duration ("90m", "2h") or a whole number
+// of days ("3d").
+func parseIncidentWindow(raw string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.New("window must be a duration such as 30m, 2h or 1d")
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(raw); err != nil {
+			return 0, errors.New("window must be a duration such as 30m, 2h or 1d")
+		}
+	}
+	if d < minIncidentWindow || d > maxIncidentWindow {
+		return 0, fmt.Errorf("window must be between %s and %s", minIncidentWindow, maxIncidentWindow)
+	}
+	return d, nil
+}
+
+// Correlate returns every deployment, cluster health change, prediction,
+// Kubernetes event and benchmark regression within the window ending at
+// until (default now), oldest first, with hints linking changes to what
+// followed them.
+// GET /api/incidents/:window/correlate
+// Query params: until (RFC 3339), cluster.
+func (h *IncidentHandler) Correlate(c *fiber.Ctx) error {
+	window, err := parseIncidentWindow(c.Params("window"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	until := time.Now().UTC()
+	if raw := c.Query("until"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "until must be an RFC 3339 timestamp")
+		}
+		until = t.UTC()
+	}
+	cluster := c.Query("cluster")
+	if cluster != "" {
+		if err := validateClusterName("cluster", cluster); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+	since := until.Add(-window)
+
+	out := &IncidentCorrelation{
+		Window:  c.Params("window"),
+		Since:   since,
+		Until:   until,
+		Entries: make([]IncidentEntry, 0),
+		Sources: make(map[string]string),
+	}
+	ctx := c.UserContext()
+
+	var events []store.ClusterEvent
+	if IsDemoMode(c) {
+		events = demoTimelineEvents()
+	} else {
+		events, err = h.store.QueryTimeline(ctx, store.TimelineFilter{
+			Cluster: cluster,
+			Since:   since.Format(time.RFC3339),
+			Until:   until.Format(time.RFC3339),
+			Limit:   timelineMaxLimit,
+		})
+		if err != nil {
+			slog.Error("[Incidents] timeline query failed", "error", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to query timeline")
+		}
+		out.Truncated = len(events) >= timelineMaxLimit
+	}
+	for _, ev := range events {
+		if e, ok := eventEntry(ev, since, until); ok {
+			out.Entries = append(out.Entries, e)
+		}
+	}
+	out.Sources[IncidentCategoryEvent] = incidentSourceOK
+
+	if !IsDemoMode(c) {
+		out.Entries = append(out.Entries, h.manifestApplies(ctx, cluster, since, until, out.Sources)...)
+		out.Entries = append(out.Entries, h.predictionEntries(ctx, cluster, since, until, out.Sources)...)
+		out.Entries = append(out.Entries, h.regressionEntries(since, until, out.Sources)...)
+	}
+
+	sort.SliceStable(out.Entries, func(i, j int) bool { return out.Entries[i].Time.Before(out.Entries[j].Time) })
+	out.Hints = correlate(out.Entries)
+	return c.JSON(out)
+}
+
+// eventEntry converts a journaled event. Events that recur are placed at
+// their first occurrence when it falls within the window.
+func eventEntry(ev store.ClusterEvent, since, until time.Time) (IncidentEntry, bool) {
+	at, err := time.Parse(time.RFC3339, ev.FirstSeen)
+	if err != nil || at.Before(since) {
+		at, err = time.Parse(time.RFC3339, ev.LastSeen)
+		if err != nil {
+			return IncidentEntry{}, false
+		}
+	}
+	if at.Before(since) || at.After(until) {
+		return IncidentEntry{}, false
+	}
+
+	e := IncidentEntry{
+		ID:        ev.ID,
+		Time:      at.UTC(),
+		Category:  IncidentCategoryEvent,
+		Severity:  "info",
+		Cluster:   ev.ClusterName,
+		Namespace: ev.Namespace,
+		Kind:      ev.InvolvedObjectKind,
+		Name:      ev.InvolvedObjectName,
+		Reason:    ev.Reason,
+		Summary:   ev.Message,
+		Count:     int(ev.EventCount),
+	}
+	if ev.EventType == "Warning" {
+		e.Severity = "warning"
+	}
+	switch {
+	case ev.InvolvedObjectKind == clusterHealthObjectKind &&
+		(ev.Reason == clusterHealthReasonDown || ev.Reason == clusterHealthReasonUp):
+		e.Category = IncidentCategoryClusterHealth
+		if ev.Reason == clusterHealthReasonDown {
+			e.Severity = "critical"
+		}
+	case ev.InvolvedObjectKind == "Deployment" && ev.Reason == "ScalingReplicaSet":
+		e.Category = IncidentCategoryDeployment
+	}
+	return e, true
+}
+
+// manifestApplies returns the manifests applied through the console, read
+// from the most recent audit entries.
+func (h *IncidentHandler) manifestApplies(ctx context.Context, cluster string, since, until time.Time, sources map[string]string) []IncidentEntry {
+	logs, err := h.store.QueryAuditLogs(ctx, incidentAuditLimit, "", audit.ActionApplyManifest)
+	if err != nil {
+		slog.Warn("[Incidents] audit query failed", "error", err)
+		sources[IncidentCategoryDeployment] = incidentSourceUnavailable
+		return nil
+	}
+	sources[IncidentCategoryDeployment] = incidentSourceOK
+
+	var out []IncidentEntry
+	for _, l := range logs {
+		at, err := time.Parse(time.RFC3339, l.Timestamp)
+		if err != nil || at.Before(since) || at.After(until) {
+			continue
+		}
+		var detail struct {
+			TargetID string `json:"target_id"`
+			Details  string `json:"details"`
+		}
+		if err := json.Unmarshal([]byte(l.Detail), &detail); err != nil {
+			continue
+		}
+		if cluster != "" && detail.TargetID != cluster {
+			continue
+		}
+		summary := "Manifest applied through the console"
+		if detail.Details != "" {
+			summary += " (" + detail.Details + ")"
+		}
+		out = append(out, IncidentEntry{
+			ID:       "audit-" + strconv.FormatInt(l.ID, 10),
+			Time:     at.UTC(),
+			Category: IncidentCategoryDeployment,
+			Severity: "info",
+			Cluster:  detail.TargetID,
+			Reason:   audit.ActionApplyManifest,
+			Summary:  summary,
+		})
+	}
+	return out
+}
+
+func (h *IncidentHandler) predictionEntries(ctx context.Context, cluster string, since, until time.Time, sources map[string]string) []IncidentEntry {
+	if h.predictions == nil {
+		sources[IncidentCategoryPrediction] = incidentSourceDisabled
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, incidentSourceTimeout)
+	defer cancel()
+	preds, err := h.predictions.Predictions(ctx)
+	if err != nil {
+		slog.Warn("[Incidents] prediction fetch failed", "error", err)
+		sources[IncidentCategoryPrediction] = incidentSourceUnavailable
+		return nil
+	}
+	sources[IncidentCategoryPrediction] = incidentSourceOK
+
+	var out []IncidentEntry
+	for _, p := range preds {
+		at, err := time.Parse(time.RFC3339, p.GeneratedAt)
+		if err != nil || at.Before(since) || at.After(until) {
+			continue
+		}
+		if cluster != "" && p.Cluster != cluster {
+			continue
+		}
+		out = append(out, IncidentEntry{
+			ID:        "prediction-" + p.ID,
+			Time:      at.UTC(),
+			Category:  IncidentCategoryPrediction,
+			Severity:  p.Severity,
+			Cluster:   p.Cluster,
+			Namespace: p.Namespace,
+			Name:      p.Name,
+			Reason:    p.Category,
+			Summary:   fmt.Sprintf("%s (%d%% confidence)", p.Reason, p.Confidence),
+		})
+	}
+	return out
+}
+
+// regressionEntries returns benchmark regressions. Benchmark runs are not
+// tied to a console cluster, so these entries are kept when the timeline is
+// filtered by cluster.
+func (h *IncidentHandler) regressionEntries(since, until time.Time, sources map[string]string) []IncidentEntry {
+	if h.regressions == nil {
+		sources[IncidentCategoryRegression] = incidentSourceDisabled
+		return nil
+	}
+	sources[IncidentCategoryRegression] = incidentSourceOK
+
+	var out []IncidentEntry
+	for _, r := range h.regressions.Regressions(since, until) {
+		out = append(out, IncidentEntry{
+			ID:       "benchmark-" + r.RunUID + "-" + r.Metric,
+			Time:     r.At.UTC(),
+			Category: IncidentCategoryRegression,
+			Severity: "warning",
+			Name:     r.Experiment,
+			Reason:   r.Metric,
+			Summary: fmt.Sprintf("%s changed %+.0f%% (%.4g → %.4g %s) against run %s",
+				r.Metric, r.Change*100, r.Baseline, r.Current, r.Units, r.BaselineRunUID),
+		})
+	}
+	return out
+}
+
+// correlate derives hints from a time-ordered timeline:
+//   - a deployment followed by at least minWarningSpike warning events, or by
+//     the cluster going unhealthy, on the same cluster;
+//   - a deployment on any cluster followed by a benchmark regression;
+//   - a prediction followed by its cluster going unhealthy.
+//
+// Each effect must follow within correlationLookahead.
+func correlate(entries []IncidentEntry) []CorrelationHint {
+	hints := make([]CorrelationHint, 0)
+	add := func(h CorrelationHint) bool {
+		if len(hints) >= maxCorrelationHints {
+			return false
+		}
+		hints = append(hints, h)
+		return true
+	}
+
+	for i, cause := range entries {
+		if cause.Category != IncidentCategoryDeployment && cause.Category != IncidentCategoryPrediction {
+			continue
+		}
+		var warnings, warningIDs []string
+		warningCount := 0
+		var firstWarning time.Time
+		for _, eff := range entries[i+1:] {
+			lag := eff.Time.Sub(cause.Time)
+			if lag > correlationLookahead {
+				break
+			}
+			sameCluster := cause.Cluster != "" && eff.Cluster == cause.Cluster
+			switch {
+			case sameCluster && eff.Category == IncidentCategoryClusterHealth && eff.Severity == "critical":
+				typ, msg := hintDeploymentUnhealthy, fmt.Sprintf("%s preceded cluster %s becoming unhealthy by %s",
+					describeCause(cause), cause.Cluster, roundLag(lag))
+				if cause.Category == IncidentCategoryPrediction {
+					typ = hintPredictionUnhealthy
+				}
+				if !add(CorrelationHint{Type: typ, Cluster: cause.Cluster, CauseID: cause.ID,
+					EffectIDs: []string{eff.ID}, LagSeconds: int64(lag.Seconds()), Message: msg}) {
+					return hints
+				}
+			case cause.Category == IncidentCategoryDeployment && eff.Category == IncidentCategoryRegression:
+				msg := fmt.Sprintf("%s preceded a %s regression in benchmark %s by %s",
+					describeCause(cause), eff.Reason, eff.Name, roundLag(lag))
+				if !add(CorrelationHint{Type: hintDeploymentRegression, Cluster: cause.Cluster, CauseID: cause.ID,
+					EffectIDs: []string{eff.ID}, LagSeconds: int64(lag.Seconds()), Message: msg}) {
+					return hints
+				}
+			case cause.Category == IncidentCategoryDeployment && sameCluster &&
+				eff.Category == IncidentCategoryEvent && eff.Severity == "warning":
+				if warningCount == 0 {
+					firstWarning = eff.Time
+				}
+				warningCount += max(eff.Count, 1)
+				if len(warningIDs) < maxHintEffects {
+					warningIDs = append(warningIDs, eff.ID)
+				}
+				if eff.Reason != "" && !slices.Contains(warnings, eff.Reason) {
+					warnings = append(warnings, eff.Reason)
+				}
+			}
+		}
+		if warningCount >= minWarningSpike {
+			lag := firstWarning.Sub(cause.Time)
+			msg := fmt.Sprintf("%s preceded %d warning events on cluster %s (%s) within %s",
+				describeCause(cause), warningCount, cause.Cluster, strings.Join(warnings, ", "), correlationLookahead)
+			if !add(CorrelationHint{Type: hintDeploymentWarnings, Cluster: cause.Cluster, CauseID: cause.ID,
+				EffectIDs: warningIDs, LagSeconds: int64(lag.Seconds()), Message: msg}) {
+				return hints
+			}
+		}
+	}
+	return hints
+}
+
+func describeCause(e IncidentEntry) string {
+	switch {
+	case e.Category == IncidentCategoryPrediction:
+		return fmt.Sprintf("Prediction %q", e.Summary)
+	case e.Name != "":
+		return fmt.Sprintf("Deployment %s on cluster %s", e.Name, e.Cluster)
+	default:
+		return fmt.Sprintf("Manifest apply on cluster %s", e.Cluster)
+	}
+}
+
+func roundLag(d time.Duration) time.Duration {
+	return d.Round(time.Second)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/handlers/benchmarks"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type fakePredictionSource struct {
+	preds []IncidentPrediction
+	err   error
+}
+
+func (f *fakePredictionSource) Predictions(context.Context) ([]IncidentPrediction, error) {
+	return f.preds, f.err
+}
+
+type fakeRegressionSource []benchmarks.Regression
+
+func (f fakeRegressionSource) Regressions(since, until time.Time) []benchmarks.Regression {
+	return f
+}
+
+func incidentEvent(id, cluster, kind, name, reason, evType string, at time.Time) store.ClusterEvent {
+	ts := at.Format(time.RFC3339)
+	return store.ClusterEvent{
+		ID: id, ClusterName: cluster, Namespace: "default", EventType: evType, Reason: reason,
+		InvolvedObjectKind: kind, InvolvedObjectName: name, EventCount: 1, FirstSeen: ts, LastSeen: ts,
+	}
+}
+
+func getCorrelation(t *testing.T, app interface {
+	Test(*http.Request, ...int) (*http.Response, error)
+}, path string) (*http.Response, IncidentCorrelation) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), 5000)
+	require.NoError(t, err)
+	var out IncidentCorrelation
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp, out
+}
+
+func TestIncidentCorrelate_MergesSourcesAndHints(t *testing.T) {
+	env := setupTestEnv(t)
+	mockStore := env.Store.(*test.MockStore)
+
+	until := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	deployAt := until.Add(-50 * time.Minute)
+	events := []store.ClusterEvent{
+		incidentEvent("w3", "prod", "Pod", "api-3", "BackOff", "Warning", deployAt.Add(12*time.Minute)),
+		incidentEvent("w2", "prod", "Pod", "api-2", "BackOff", "Warning", deployAt.Add(10*time.Minute)),
+		incidentEvent("w1", "prod", "Pod", "api-1", "Unhealthy", "Warning", deployAt.Add(5*time.Minute)),
+		incidentEvent("other", "staging", "Pod", "x", "BackOff", "Warning", deployAt.Add(5*time.Minute)),
+		incidentEvent("down", "prod", clusterHealthObjectKind, "prod", clusterHealthReasonDown, "Warning", deployAt.Add(20*time.Minute)),
+		incidentEvent("deploy", "prod", "Deployment", "api", "ScalingReplicaSet", "Normal", deployAt),
+	}
+	mockStore.On("QueryTimeline", mock.MatchedBy(func(f store.TimelineFilter) bool {
+		return f.Since == "2026-05-01T10:00:00Z" && f.Until == "2026-05-01T12:00:00Z" && f.Limit == timelineMaxLimit
+	})).Return(events, nil)
+	mockStore.On("QueryAuditLogs", incidentAuditLimit, "", audit.ActionApplyManifest).Return([]store.AuditEntry{
+		{ID: 7, Timestamp: deployAt.Add(-5 * time.Minute).Format(time.RFC3339), Action: audit.ActionApplyManifest,
+			Detail: `{"target_type":"cluster","target_id":"staging","details":"objects=2"}`},
+		{ID: 6, Timestamp: until.Add(-3 * time.Hour).Format(time.RFC3339), Action: audit.ActionApplyManifest,
+			Detail: `{"target_type":"cluster","target_id":"staging"}`},
+	}, nil)
+
+	preds := &fakePredictionSource{preds: []IncidentPrediction{
+		{ID: "p1", Category: "pod-crash", Severity: "warning", Name: "api-1", Cluster: "prod",
+			Reason: "Restart count rising", Confidence: 80, GeneratedAt: deployAt.Add(15 * time.Minute).Format(time.RFC3339)},
+		{ID: "old", Cluster: "prod", GeneratedAt: until.Add(-5 * time.Hour).Format(time.RFC3339)},
+	}}
+	regressions := fakeRegressionSource{{Experiment: "llama", RunUID: "llama/r2", BaselineRunUID: "llama/r1",
+		Metric: "request_latency", Units: "s", Baseline: 1, Current: 1.5, Change: 0.5, At: deployAt.Add(25 * time.Minute)}}
+
+	h := NewIncidentHandler(env.Store, preds, regressions)
+	env.App.Get("/api/incidents/:window/correlate", h.Correlate)
+
+	resp, out := getCorrelation(t, env.App, "/api/incidents/2h/correlate?until=2026-05-01T12:00:00Z")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var ids []string
+	for i, e := range out.Entries {
+		ids = append(ids, e.ID)
+		if i > 0 {
+			assert.False(t, e.Time.Before(out.Entries[i-1].Time), "entries are ordered by time")
+		}
+	}
+	assert.Equal(t, []string{"audit-7", "deploy", "w1", "other", "w2", "w3", "prediction-p1", "down", "benchmark-llama/r2-request_latency"}, ids)
+
+	byID := make(map[string]IncidentEntry)
+	for _, e := range out.Entries {
+		byID[e.ID] = e
+	}
+	assert.Equal(t, IncidentCategoryDeployment, byID["deploy"].Category)
+	assert.Equal(t, IncidentCategoryDeployment, byID["audit-7"].Category)
+	assert.Equal(t, "staging", byID["audit-7"].Cluster)
+	assert.Equal(t, IncidentCategoryClusterHealth, byID["down"].Category)
+	assert.Equal(t, "critical", byID["down"].Severity)
+	assert.Equal(t, IncidentCategoryPrediction, byID["prediction-p1"].Category)
+	assert.Equal(t, IncidentCategoryRegression, byID["benchmark-llama/r2-request_latency"].Category)
+
+	hints := make(map[string]CorrelationHint)
+	for _, h := range out.Hints {
+		hints[h.Type+":"+h.CauseID] = h
+	}
+	spike, ok := hints[hintDeploymentWarnings+":deploy"]
+	require.True(t, ok, "deployment followed by warnings is hinted: %+v", out.Hints)
+	assert.Equal(t, []string{"w1", "w2", "w3"}, spike.EffectIDs, "warnings on other clusters are not counted")
+	assert.Equal(t, int64(5*60), spike.LagSeconds)
+	assert.Contains(t, spike.Message, "Deployment api on cluster prod preceded 3 warning events on cluster prod")
+
+	assert.Contains(t, hints, hintDeploymentUnhealthy+":deploy")
+	assert.Contains(t, hints, hintDeploymentRegression+":deploy")
+	assert.Contains(t, hints, hintPredictionUnhealthy+":prediction-p1")
+	_, stagingSpike := hints[hintDeploymentWarnings+":audit-7"]
+	assert.False(t, stagingSpike, "a single warning is not a spike")
+
+	assert.Equal(t, map[string]string{
+		IncidentCategoryEvent:      incidentSourceOK,
+		IncidentCategoryDeployment: incidentSourceOK,
+		IncidentCategoryPrediction: incidentSourceOK,
+		IncidentCategoryRegression: incidentSourceOK,
+	}, out.Sources)
+	assert.False(t, out.Truncated)
+}
+
+func TestIncidentCorrelate_ClusterFilterAndDegradedSources(t *testing.T) {
+	env := setupTestEnv(t)
+	mockStore := env.Store.(*test.MockStore)
+
+	mockStore.On("QueryTimeline", mock.MatchedBy(func(f store.TimelineFilter) bool {
+		return f.Cluster == "prod"
+	})).Return([]store.ClusterEvent{}, nil)
+	mockStore.On("QueryAuditLogs", incidentAuditLimit, "", audit.ActionApplyManifest).Return(nil, assert.AnError)
+
+	h := NewIncidentHandler(env.Store, &fakePredictionSource{err: assert.AnError}, nil)
+	env.App.Get("/api/incidents/:window/correlate", h.Correlate)
+
+	resp, out := getCorrelation(t, env.App, "/api/incidents/1d/correlate?cluster=prod")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, out.Entries)
+	assert.NotNil(t, out.Hints)
+	assert.Equal(t, incidentSourceUnavailable, out.Sources[IncidentCategoryDeployment])
+	assert.Equal(t, incidentSourceUnavailable, out.Sources[IncidentCategoryPrediction])
+	assert.Equal(t, incidentSourceDisabled, out.Sources[IncidentCategoryRegression])
+	assert.Equal(t, 24*time.Hour, out.Until.Sub(out.Since))
+}
+
+func TestIncidentCorrelate_Validation(t *testing.T) {
+	env := setupTestEnv(t)
+	h := NewIncidentHandler(env.Store, nil, nil)
+	env.App.Get("/api/incidents/:window/correlate", h.Correlate)
+
+	for _, path := range []string{
+		"/api/incidents/soon/correlate",
+		"/api/incidents/30s/correlate",
+		"/api/incidents/8d/correlate",
+		"/api/incidents/2h/correlate?until=yesterday",
+		"/api/incidents/2h/correlate?cluster=bad%0Aname",
+	} {
+		resp, _ := getCorrelation(t, env.App, path)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+	}
+}
+
+func TestIncidentCorrelate_StoreError(t *testing.T) {
+	env := setupTestEnv(t)
+	env.Store.(*test.MockStore).On("QueryTimeline", mock.Anything).Return(nil, assert.AnError)
+	h := NewIncidentHandler(env.Store, nil, nil)
+	env.App.Get("/api/incidents/:window/correlate", h.Correlate)
+
+	resp, _ := getCorrelation(t, env.App, "/api/incidents/2h/correlate")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestAgentPredictionSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions/ai", r.URL.Path)
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"predictions":[{"id":"p1","cluster":"prod","generatedAt":"2026-05-01T10:00:00Z"}],"stale":false}`))
+	}))
+	defer srv.Close()
+
+	preds, err := NewAgentPredictionSource(srv.URL+"/", "tok").Predictions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, preds, 1)
+	assert.Equal(t, "prod", preds[0].Cluster)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// LimitsStore persists the admin-configured resource limits. The settings
+// manager implements it.
+type LimitsStore interface {
+	GetLimits() settings.LimitsSettings
+	SaveLimits(settings.LimitsSettings) error
+}
+
+// usageCounter counts the resources of one kind in use.
+type usageCounter func(ctx context.Context) (int, error)
+
+// resourceLimit is one resource's entry in GET /api/limits. Used is nil
+// when it could not be counted.
+type resourceLimit struct {
+	Limit  int    `json:"limit"`
+	Source string `json:"source,omitempty"`
+	Used   *int   `json:"used"`
+}
+
+// LimitsHandler serves the resource limits and their usage.
+type LimitsHandler struct {
+	enforcer *limits.Enforcer
+	limits   LimitsStore
+	store    store.Store
+	usage    map[limits.Resource]usageCounter
+}
+
+// NewLimitsHandler creates a limits handler. Usage of a resource whose
+// backing client is nil is reported as unknown.
+func NewLimitsHandler(enforcer *limits.Enforcer, ls LimitsStore, s store.Store, k8sClient *k8s.MultiClusterClient, persistenceStore *store.PersistenceStore) *LimitsHandler {
+	h := &LimitsHandler{enforcer: enforcer, limits: ls, store: s, usage: make(map[limits.Resource]usageCounter)}
+	if s != nil {
+		h.usage[limits.Users] = func(ctx context.Context) (int, error) {
+			admins, editors, viewers, err := s.CountUsersByRole(ctx)
+			return admins + editors + viewers, err
+		}
+	}
+	if k8sClient != nil {
+		h.usage[limits.Clusters] = func(ctx context.Context) (int, error) {
+			clusters, err := k8sClient.ListClusters(ctx)
+			return len(clusters), err
+		}
+	}
+	if persistenceStore != nil {
+		h.usage[limits.ManagedWorkloads] = func(ctx context.Context) (int, error) {
+			client, _, err := persistenceStore.GetActiveClient(ctx)
+			if err != nil {
+				return 0, err
+			}
+			count := 0
+			for _, namespace := range persistenceStore.GetNamespaces() {
+				workloads, err := k8s.NewConsolePersistence(client).ListManagedWorkloads(ctx, namespace)
+				if err != nil {
+					return 0, err
+				}
+				count += len(workloads)
+			}
+			return count, nil
+		}
+	}
+	return h
+}
+
+// GetLimits returns the effective limit on each resource, the source
+// setting it and how much is in use, plus the license if one is configured.
+// GET /api/limits
+func (h *LimitsHandler) GetLimits(c *fiber.Ctx) error {
+	effective, sourceErr := h.enforcer.Effective()
+	resources := make(map[limits.Resource]resourceLimit, len(effective))
+	for r, l := range effective {
+		entry := resourceLimit{Limit: l.Max, Source: l.Source}
+		if count, ok := h.usage[r]; ok {
+			if n, err := count(c.UserContext()); err != nil {
+				slog.Warn("[Limits] failed to count usage", "resource", r, "error", err)
+			} else {
+				entry.Used = &n
+			}
+		}
+		resources[r] = entry
+	}
+
+	resp := fiber.Map{
+		"resources": resources,
+		"settings":  h.limits.GetLimits(),
+	}
+	if sourceErr != nil {
+		// Creations are refused until the source is fixed; say why.
+		resp["error"] = sourceErr.Error()
+	}
+	if ls := h.enforcer.License(); ls != nil {
+		if lic, err := ls.License(); err == nil {
+			resp["license"] = lic
+		}
+	}
+	return c.JSON(resp)
+}
+
+// UpdateLimits replaces the admin-configured limits and project quotas.
+// Zero means unlimited; a license can still cap a resource below what is
+// set here.
+// PUT /api/admin/limits
+func (h *LimitsHandler) UpdateLimits(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	var l settings.LimitsSettings
+	if err := c.BodyParser(&l); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := (limits.Limits{MaxClusters: l.MaxClusters, MaxManagedWorkloads: l.MaxManagedWorkloads, MaxUsers: l.MaxUsers}).Validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	quotas := make(limits.Quotas, len(l.Projects))
+	for p, q := range l.Projects {
+		quotas[p] = limits.ProjectQuota(q)
+	}
+	if err := quotas.Validate(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.limits.SaveLimits(l); err != nil {
+		slog.Error("[Limits] failed to save limits", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save limits"})
+	}
+	audit.Log(c, audit.ActionUpdateLimits, "limits", "console",
+		fmt.Sprintf("max_clusters=%d max_managed_workloads=%d max_users=%d project_quotas=%d",
+			l.MaxClusters, l.MaxManagedWorkloads, l.MaxUsers, len(l.Projects)))
+	return c.JSON(l)
+}
+
+// limitError responds to a creation refused by the resource limits or a
+// project quota the way kc-agent does: 429 with Retry-After when the project
+// created too many recently, 403 otherwise, with limits.Payload as the body.
+func limitError(c *fiber.Ctx, err error) error {
+	var rate *limits.RateExceededError
+	if errors.As(err, &rate) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(rate.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(limits.Payload(err))
+	}
+	return c.Status(fiber.StatusForbidden).JSON(limits.Payload(err))
+}
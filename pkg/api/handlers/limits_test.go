@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/limits"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memLimitsStore struct {
+	limits settings.LimitsSettings
+}
+
+func (m *memLimitsStore) GetLimits() settings.LimitsSettings { return m.limits }
+
+func (m *memLimitsStore) SaveLimits(l settings.LimitsSettings) error {
+	m.limits = l
+	return nil
+}
+
+func setupLimitsApp(t *testing.T, role models.UserRole) (*fiber.App, *memLimitsStore) {
+	t.Helper()
+	mockStore := new(test.MockStore)
+	userID := uuid.New()
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	ls := &memLimitsStore{}
+	h := NewLimitsHandler(limits.NewEnforcer(limits.NewSettingsSource(ls)), ls, mockStore, nil, nil)
+	h.usage = map[limits.Resource]usageCounter{
+		limits.Users:    func(context.Context) (int, error) { return 4, nil },
+		limits.Clusters: func(context.Context) (int, error) { return 0, errors.New("kubeconfig unreadable") },
+	}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/limits", h.GetLimits)
+	app.Put("/api/admin/limits", h.UpdateLimits)
+	return app, ls
+}
+
+func TestLimits_UpdateAndGet(t *testing.T) {
+	app, ls := setupLimitsApp(t, models.UserRoleAdmin)
+
+	status, body := doFlagRequest(t, app, http.MethodPut, "/api/admin/limits", `{"maxClusters":10,"maxUsers":5}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, settings.LimitsSettings{MaxClusters: 10, MaxUsers: 5}, ls.limits)
+
+	status, body = doFlagRequest(t, app, http.MethodGet, "/api/limits", "")
+	require.Equal(t, http.StatusOK, status)
+	var got struct {
+		Resources map[limits.Resource]resourceLimit `json:"resources"`
+		Error     string                            `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(body, &got))
+	assert.Empty(t, got.Error)
+
+	users := got.Resources[limits.Users]
+	assert.Equal(t, 5, users.Limit)
+	assert.Equal(t, limits.SettingsSourceName, users.Source)
+	require.NotNil(t, users.Used)
+	assert.Equal(t, 4, *users.Used)
+
+	clusters := got.Resources[limits.Clusters]
+	assert.Equal(t, 10, clusters.Limit)
+	assert.Nil(t, clusters.Used, "usage that cannot be counted is unknown")
+
+	workloads := got.Resources[limits.ManagedWorkloads]
+	assert.Equal(t, 0, workloads.Limit, "unset limits are unlimited")
+}
+
+func TestLimits_UpdateRejectsNegative(t *testing.T) {
+	app, ls := setupLimitsApp(t, models.UserRoleAdmin)
+	status, _ := doFlagRequest(t, app, http.MethodPut, "/api/admin/limits", `{"maxClusters":-1}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, settings.LimitsSettings{}, ls.limits)
+
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/limits", `{"maxClusters":`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestLimits_UpdateProjectQuotas(t *testing.T) {
+	app, ls := setupLimitsApp(t, models.UserRoleAdmin)
+	status, body := doFlagRequest(t, app, http.MethodPut, "/api/admin/limits",
+		`{"projects":{"payments":{"namespaces":["payments-console"],"maxDeploymentsPerDay":20,"maxTargetClusters":5}}}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, settings.ProjectQuotaSettings{Namespaces: []string{"payments-console"}, MaxDeploymentsPerDay: 20, MaxTargetClusters: 5},
+		ls.limits.Projects["payments"])
+
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/limits",
+		`{"projects":{"a":{"namespaces":["shared"]},"b":{"namespaces":["shared"]}}}`)
+	assert.Equal(t, http.StatusBadRequest, status, "a namespace belongs to one project")
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/limits",
+		`{"projects":{"a":{"namespaces":["a-console"],"maxTargetClusters":-1}}}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, ls.limits.Projects, "payments", "rejected updates keep the stored quotas")
+}
+
+func TestLimits_ViewerForbidden(t *testing.T) {
+	app, ls := setupLimitsApp(t, models.UserRoleViewer)
+	status, _ := doFlagRequest(t, app, http.MethodPut, "/api/admin/limits", `{"maxUsers":1}`)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, settings.LimitsSettings{}, ls.limits)
+
+	status, _ = doFlagRequest(t, app, http.MethodGet, "/api/limits", "")
+	assert.Equal(t, http.StatusOK, status, "any signed-in user can read the limits")
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/compliance/manifestpolicy"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// manifestClient defines the narrow subset of k8s.MultiClusterClient used by
+// ManifestHandlers.
+type manifestClient interface {
+	ValidateManifest(ctx context.Context, contextName string, manifest []byte, defaultNamespace string) (*k8s.ManifestValidation, error)
+	ApplyManifest(ctx context.Context, contextName string, manifest []byte, defaultNamespace string) (*k8s.ManifestApplyResult, error)
+}
+
+// ManifestHandlers backs the in-console YAML editor. Validation is a
+// server-side dry-run and never persists anything, but it runs under the
+// console's credentials and diffs against live objects, so both validate and
+// apply are restricted to editors and admins. Apply also runs local policy
+// checks before touching the cluster.
+type ManifestHandlers struct {
+	k8sClient manifestClient
+	store     store.Store
+	policies  *manifestpolicy.Engine
+}
+
+// NewManifestHandlers creates a new manifest handlers instance.
+func NewManifestHandlers(k8sClient *k8s.MultiClusterClient, s store.Store) *ManifestHandlers {
+	h := &ManifestHandlers{store: s, policies: manifestpolicy.NewEngine()}
+	// Avoid storing a typed nil pointer in the interface so the nil check in
+	// each handler behaves as expected.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// validateManifestRequest is the body accepted by validate-manifest.
+type validateManifestRequest struct {
+	Manifest  string `json:"manifest"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// applyManifestRequest is the body accepted by apply.
+type applyManifestRequest struct {
+	Manifest  string `json:"manifest"`
+	Namespace string `json:"namespace,omitempty"`
+	// Policies selects which local policies to evaluate; empty runs all.
+	Policies []string `json:"policies,omitempty"`
+	// SkipPolicies disables local policy checks. Admin only.
+	SkipPolicies bool `json:"skipPolicies,omitempty"`
+}
+
+// applyManifestResponse is the apply result plus the local policy report,
+// which carries audit-mode violations even when the apply went through.
+type applyManifestResponse struct {
+	*k8s.ManifestApplyResult
+	Policy *manifestpolicy.Report `json:"policy,omitempty"`
+}
+
+// ValidateManifest validates a YAML or JSON manifest against the cluster's
+// schema with a server-side dry-run apply and returns per-object errors and
+// the diff an apply would produce. Secret values are redacted from the diff.
+// The response is 200 even when objects are invalid; callers check the
+// top-level valid flag.
+// POST /api/clusters/:cluster/validate-manifest
+func (h *ManifestHandlers) ValidateManifest(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var req validateManifestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if strings.TrimSpace(req.Manifest) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "manifest is required"})
+	}
+	if req.Namespace != "" {
+		if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	result, err := h.k8sClient.ValidateManifest(ctx, cluster, []byte(req.Manifest), req.Namespace)
+	if errors.Is(err, k8s.ErrInvalidManifest) || errors.Is(err, k8s.ErrTooManyManifestDocuments) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// ListPolicies returns the local policies the apply endpoint can evaluate.
+// GET /api/manifest-policies
+func (h *ManifestHandlers) ListPolicies(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"policies": h.policies.Policies()})
+}
+
+// ApplyManifest server-side applies a YAML or JSON manifest to a cluster.
+// Local policies run first; an enforce-mode violation returns 422 with the
+// violations and nothing is sent to the cluster. Every object is then
+// dry-run, and only if all pass are they applied. As with validation, Secret
+// values are redacted from the returned diff.
+// POST /api/clusters/:cluster/apply
+func (h *ManifestHandlers) ApplyManifest(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var req applyManifestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if strings.TrimSpace(req.Manifest) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "manifest is required"})
+	}
+	if req.Namespace != "" {
+		if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+	if req.SkipPolicies {
+		if err := RequireAdmin(c, h.store); err != nil {
+			return err
+		}
+	}
+
+	objects, err := k8s.ParseManifest([]byte(req.Manifest))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var report *manifestpolicy.Report
+	if !req.SkipPolicies {
+		inputs := make([]manifestpolicy.Object, 0, len(objects))
+		for _, obj := range objects {
+			inputs = append(inputs, manifestpolicy.Object{Index: obj.Index, Content: obj.Object.Object})
+		}
+		report, err = h.policies.Evaluate(inputs, req.Policies, manifestpolicy.Environment{Cluster: cluster})
+		if errors.Is(err, manifestpolicy.ErrUnknownPolicy) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "policy evaluation failed"})
+		}
+		if report.Denied {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":  "manifest violates policy",
+				"policy": report,
+			})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	result, err := h.k8sClient.ApplyManifest(ctx, cluster, []byte(req.Manifest), req.Namespace)
+	if errors.Is(err, k8s.ErrInvalidManifest) || errors.Is(err, k8s.ErrTooManyManifestDocuments) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+
+	resp := applyManifestResponse{ManifestApplyResult: result, Policy: report}
+	if !result.Applied {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(resp)
+	}
+	detail := fmt.Sprintf("objects=%d", len(result.Objects))
+	if req.SkipPolicies {
+		detail += " policies=skipped"
+	}
+	audit.Log(c, audit.ActionApplyManifest, "cluster", cluster, detail)
+	return c.JSON(resp)
+}
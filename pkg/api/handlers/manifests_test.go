@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/compliance/manifestpolicy"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func setupManifestTest(t *testing.T) (*testEnv, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	env := setupTestEnv(t)
+	handler := NewManifestHandlers(env.K8sClient, env.Store)
+	env.App.Post("/api/clusters/:cluster/validate-manifest", handler.ValidateManifest)
+	env.App.Post("/api/clusters/:cluster/apply", handler.ApplyManifest)
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	dynClient := injectDynamicCluster(env, "test-cluster", map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+		secrets:    "SecretList",
+	})
+	dynClient.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+		return true, obj, nil
+	})
+
+	cs := k8sfake.NewSimpleClientset()
+	cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "patch"}},
+			{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"get", "patch"}},
+		},
+	}}
+	env.K8sClient.InjectClient("test-cluster", cs)
+	return env, dynClient
+}
+
+func postManifest(t *testing.T, env *testEnv, path string, body interface{}) *http.Response {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestValidateManifest_DryRun(t *testing.T) {
+	env, _ := setupManifestTest(t)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/validate-manifest", validateManifestRequest{
+		Manifest:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\ndata:\n  a: b\n---\napiVersion: v1\nkind: Widget\nmetadata:\n  name: w\n",
+		Namespace: "apps",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result k8s.ManifestValidation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.False(t, result.Valid)
+	require.Len(t, result.Objects, 2)
+	assert.True(t, result.Objects[0].Valid)
+	assert.Equal(t, k8s.ManifestActionCreate, result.Objects[0].Action)
+	assert.Equal(t, "apps", result.Objects[0].Namespace)
+	assert.NotEmpty(t, result.Objects[0].Changes)
+	require.Len(t, result.Objects[1].Errors, 1)
+	assert.Equal(t, k8s.ManifestStageDiscovery, result.Objects[1].Errors[0].Stage)
+}
+
+func TestValidateManifest_BadRequests(t *testing.T) {
+	env, _ := setupManifestTest(t)
+
+	cases := map[string]validateManifestRequest{
+		"empty manifest": {Manifest: "   "},
+		"bad namespace":  {Manifest: "kind: ConfigMap", Namespace: "Not_Valid"},
+	}
+	for name, body := range cases {
+		resp := postManifest(t, env, "/api/clusters/test-cluster/validate-manifest", body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+	}
+}
+
+func TestValidateManifest_NoClient(t *testing.T) {
+	env := setupTestEnv(t)
+	handler := NewManifestHandlers(nil, env.Store)
+	env.App.Post("/api/clusters/:cluster/validate-manifest", handler.ValidateManifest)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/validate-manifest", validateManifestRequest{Manifest: "kind: ConfigMap"})
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+// seedLiveSecret creates the Secret the redaction tests overwrite and
+// returns its encoded values.
+func seedLiveSecret(t *testing.T, dyn *dynamicfake.FakeDynamicClient) []string {
+	t.Helper()
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db", "namespace": "apps"},
+		"data":       map[string]interface{}{"password": "bGl2ZS1wYXNzd29yZA==", "user": "YWRtaW4="},
+	}}
+	_, err := dyn.Resource(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}).Namespace("apps").
+		Create(context.Background(), secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+	return []string{"bGl2ZS1wYXNzd29yZA==", "YWRtaW4="}
+}
+
+const secretManifest = "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: apps\ndata:\n  password: bmV3\n"
+
+func TestValidateManifest_RedactsSecretValues(t *testing.T) {
+	env, dyn := setupManifestTest(t)
+	liveValues := seedLiveSecret(t, dyn)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/validate-manifest", validateManifestRequest{Manifest: secretManifest})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	for _, value := range liveValues {
+		assert.NotContains(t, string(body), value)
+	}
+
+	var result k8s.ManifestValidation
+	require.NoError(t, json.Unmarshal(body, &result))
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, k8s.ManifestActionUpdate, result.Objects[0].Action)
+	assert.NotEmpty(t, result.Objects[0].Changes)
+}
+
+func TestValidateManifest_RequiresEditor(t *testing.T) {
+	env, dyn := setupManifestTest(t)
+	mockStore := env.Store.(*test.MockStore)
+	mockStore.ExpectedCalls = nil
+	mockStore.On("GetUser", testAdminUserID).Return(&models.User{ID: testAdminUserID, Role: models.UserRoleViewer}, nil)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/validate-manifest", validateManifestRequest{Manifest: secretManifest})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, dyn.Actions())
+}
+
+const privilegedPodManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  hostPID: true
+  containers:
+  - name: shell
+    image: busybox:1.36
+    securityContext:
+      privileged: true
+`
+
+func patchActions(dyn *dynamicfake.FakeDynamicClient) int {
+	n := 0
+	for _, action := range dyn.Actions() {
+		if action.GetVerb() == "patch" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestApplyManifest_Applies(t *testing.T) {
+	env, dyn := setupManifestTest(t)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/apply", applyManifestRequest{
+		Manifest:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\ndata:\n  a: b\n",
+		Namespace: "apps",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result applyManifestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.True(t, result.Applied)
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, k8s.ManifestActionCreate, result.Objects[0].Action)
+	require.NotNil(t, result.Policy)
+	assert.Empty(t, result.Policy.Violations)
+	// One dry-run plus one real apply.
+	assert.Equal(t, 2, patchActions(dyn))
+}
+
+func TestApplyManifest_RedactsSecretValues(t *testing.T) {
+	env, dyn := setupManifestTest(t)
+	liveValues := seedLiveSecret(t, dyn)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/apply", applyManifestRequest{Manifest: secretManifest})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	for _, value := range liveValues {
+		assert.NotContains(t, string(body), value)
+	}
+
+	var result applyManifestResponse
+	require.NoError(t, json.Unmarshal(body, &result))
+	require.True(t, result.Applied)
+	assert.NotEmpty(t, result.Objects[0].Changes)
+}
+
+func TestApplyManifest_PolicyViolationsBlockApply(t *testing.T) {
+	env, dyn := setupManifestTest(t)
+
+	resp := postManifest(t, env, "/api/clusters/test-cluster/apply", applyManifestRequest{Manifest: privilegedPodManifest})
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var body struct {
+		Policy manifestpolicy.Report `json:"policy"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.True(t, body.Policy.Denied)
+	policies := map[string]bool{}
+	for _, v := range body.Policy.Violations {
+		policies[v.PolicyID] = true
+	}
+	assert.True(t, policies[manifestpolicy.PolicyDisallowPrivileged])
+	assert.True(t, policies[manifestpolicy.PolicyDisallowHostNamespaces])
+	assert.True(t, policies[manifestpolicy.PolicyRequireResourceLimits])
+	assert.Empty(t, dyn.Actions(), "cluster must not be contacted when policy denies")
+}
+
+func TestApplyManifest_BadRequests(t *testing.T) {
+	env, _ := setupManifestTest(t)
+
+	cases := map[string]applyManifestRequest{
+		"empty manifest": {Manifest: " "},
+		"bad document":   {Manifest: "apiVersion: v1\nkind: ConfigMap\n---\n- a\n"},
+		"unknown policy": {Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n", Policies: []string{"nope"}},
+	}
+	for name, body := range cases {
+		resp := postManifest(t, env, "/api/clusters/test-cluster/apply", body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, name)
+	}
+}
+
+func TestApplyManifest_RoleChecks(t *testing.T) {
+	for name, tc := range map[string]struct {
+		role models.UserRole
+		req  applyManifestRequest
+	}{
+		"viewer cannot apply":           {models.UserRoleViewer, applyManifestRequest{Manifest: "kind: ConfigMap"}},
+		"editor cannot skip the policy": {models.UserRoleEditor, applyManifestRequest{Manifest: privilegedPodManifest, SkipPolicies: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			env, dyn := setupManifestTest(t)
+			mockStore := env.Store.(*test.MockStore)
+			mockStore.ExpectedCalls = nil
+			mockStore.On("GetUser", testAdminUserID).Return(&models.User{ID: testAdminUserID, Role: tc.role}, nil)
+			mockStore.On("CountUsersByRole").Return(1, 0, 1, nil)
+
+			resp := postManifest(t, env, "/api/clusters/test-cluster/apply", tc.req)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			assert.Empty(t, dyn.Actions())
+		})
+	}
+}
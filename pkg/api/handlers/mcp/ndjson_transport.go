@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"bufio"
+	"log/slog"
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/transport"
+)
+
+// NDJSON line types. Lines for the other stream events (cluster_skipped,
+// cluster_error, done) use the SSE event name as their type.
+const (
+	// ndjsonTypeItem carries a single list element from one cluster.
+	ndjsonTypeItem = "item"
+	// ndjsonTypeClusterDone follows the last item of a cluster, so clients
+	// know how many items to expect and when a cluster is complete.
+	ndjsonTypeClusterDone = "cluster_done"
+)
+
+// writeNDJSONEvent writes the NDJSON form of one stream event and flushes.
+//
+// A cluster_data event is split into one "item" line per element of
+// payload[dataKey] followed by a "cluster_done" line, so clients can render
+// items as soon as each cluster answers without parsing a per-cluster array.
+// Every other event becomes a single line: the payload plus a "type" field.
+func writeNDJSONEvent(w *bufio.Writer, eventName, dataKey string, payload fiber.Map) error {
+	if eventName != sseEventClusterData {
+		line := fiber.Map{"type": eventName}
+		for k, v := range payload {
+			line[k] = v
+		}
+		if err := transport.WriteNDJSON(w, line); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	count := 0
+	err := forEachItem(payload[dataKey], func(item interface{}) error {
+		count++
+		return transport.WriteNDJSON(w, fiber.Map{
+			"type":    ndjsonTypeItem,
+			"cluster": payload["cluster"],
+			"source":  payload["source"],
+			"item":    item,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := transport.WriteNDJSON(w, fiber.Map{
+		"type":    ndjsonTypeClusterDone,
+		"cluster": payload["cluster"],
+		"source":  payload["source"],
+		"count":   count,
+	}); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// forEachItem calls fn for every element when data is a slice or array, and
+// once for any other non-nil value. Fetchers return concrete slice types
+// ([]k8s.PodInfo, []k8s.Event, ...) behind interface{}, hence reflection.
+func forEachItem(data interface{}, fn func(item interface{}) error) error {
+	if data == nil {
+		return nil
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fn(data)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := fn(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamDemoNDJSON is the NDJSON form of the demo SSE streams: the demo
+// data as items of a single "demo" cluster.
+func streamDemoNDJSON(c *fiber.Ctx, dataKey string, demoData interface{}) error {
+	transport.SetNDJSONHeaders(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := writeNDJSONEvent(w, sseEventClusterData, dataKey, fiber.Map{
+			"cluster": "demo",
+			dataKey:   demoData,
+			"source":  "demo",
+		}); err != nil {
+			slog.Info("[SSE] demo stream write failed", "event", sseEventClusterData, "error", err)
+			return
+		}
+		if err := writeNDJSONEvent(w, sseEventDone, dataKey, fiber.Map{
+			"totalClusters":     1,
+			"completedClusters": 1,
+		}); err != nil {
+			slog.Info("[SSE] demo stream write failed", "event", sseEventDone, "error", err)
+		}
+	})
+	return nil
+}
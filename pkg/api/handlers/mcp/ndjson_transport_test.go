@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubestellar/console/pkg/api/transport"
+)
+
+// readNDJSON decodes every line of an NDJSON body.
+func readNDJSON(t *testing.T, body *bufio.Scanner) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for body.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(body.Bytes(), &line), body.Text())
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestGetPodsStream_NDJSON(t *testing.T) {
+	env := setupTestEnv(t)
+	env.K8sClient.InjectClient("test-cluster", k8sfake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "ndjson"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "ndjson"}},
+	))
+	h := NewMCPHandlers(nil, env.K8sClient, env.Store)
+	env.App.Get("/mcp/pods/stream", h.GetPodsStream)
+
+	for name, tc := range map[string]struct {
+		target string
+		accept string
+	}{
+		"accept header": {"/mcp/pods/stream?namespace=ndjson", transport.NDJSONContentType},
+		"format param":  {"/mcp/pods/stream?namespace=ndjson&format=ndjson", ""},
+	} {
+		req := httptest.NewRequest("GET", tc.target, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		resp, err := env.App.Test(req, -1)
+		require.NoError(t, err)
+		assert.Equal(t, transport.NDJSONContentType, resp.Header.Get("Content-Type"), name)
+
+		lines := readNDJSON(t, bufio.NewScanner(resp.Body))
+		resp.Body.Close()
+		require.Len(t, lines, 4, name)
+		for _, line := range lines[:2] {
+			assert.Equal(t, "item", line["type"], name)
+			assert.Equal(t, "test-cluster", line["cluster"], name)
+			assert.Equal(t, "ndjson", line["item"].(map[string]interface{})["namespace"], name)
+		}
+		assert.Equal(t, "cluster_done", lines[2]["type"], name)
+		assert.Equal(t, 2.0, lines[2]["count"], name)
+		assert.Equal(t, "done", lines[3]["type"], name)
+		assert.Equal(t, 1.0, lines[3]["completedClusters"], name)
+	}
+}
+
+func TestStreamDemoSSE_NDJSON(t *testing.T) {
+	env := setupTestEnv(t)
+	env.App.Get("/demo", func(c *fiber.Ctx) error {
+		return streamDemoSSE(c, "pods", []string{"a", "b", "c"})
+	})
+
+	resp, err := env.App.Test(httptest.NewRequest("GET", "/demo?format=ndjson", nil), -1)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	lines := readNDJSON(t, bufio.NewScanner(resp.Body))
+	require.Len(t, lines, 5)
+	assert.Equal(t, "c", lines[2]["item"])
+	assert.Equal(t, "demo", lines[2]["source"])
+	assert.Equal(t, 3.0, lines[3]["count"])
+	assert.Equal(t, "done", lines[4]["type"])
+}
+
+func TestForEachItem(t *testing.T) {
+	var got []interface{}
+	collect := func(item interface{}) error {
+		got = append(got, item)
+		return nil
+	}
+
+	require.NoError(t, forEachItem(nil, collect))
+	assert.Empty(t, got)
+
+	require.NoError(t, forEachItem([]int{1, 2}, collect))
+	assert.Equal(t, []interface{}{1, 2}, got)
+
+	got = nil
+	require.NoError(t, forEachItem(fiber.Map{"healthy": true}, collect))
+	assert.Len(t, got, 1, "non-slice payloads are a single item")
+}
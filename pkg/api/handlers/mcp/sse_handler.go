@@ -8,6 +8,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/kubestellar/console/pkg/api/handlers"
+	"github.com/kubestellar/console/pkg/api/transport"
 	"log/slog"
 	"strconv"
 	"strings"
@@ -39,6 +40,20 @@ func WriteSSEEvent(w *bufio.Writer, eventName string, data interface{}) error {
 // streamEmptySSE returns an empty SSE stream with just a done event.
 // Used when no clusters are configured to avoid error states on the frontend.
 func streamEmptySSE(c *fiber.Ctx) error {
+	if transport.WantsNDJSON(c) {
+		transport.SetNDJSONHeaders(c)
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			if err := writeNDJSONEvent(w, sseEventDone, "", fiber.Map{
+				"totalClusters":     0,
+				"completedClusters": 0,
+				"skippedOffline":    0,
+			}); err != nil {
+				slog.Info("[SSE] empty stream write failed", "event", sseEventDone, "error", err)
+			}
+		})
+		return nil
+	}
+
 	c.Set("Content-Type", "text/event-stream")
 	c.Set("Cache-Control", "no-cache")
 	c.Set("Connection", "keep-alive")
@@ -59,6 +74,9 @@ func streamEmptySSE(c *fiber.Ctx) error {
 // StreamDemoSSE sends demo data as a single instant SSE event.
 // Exported for use in sub-packages like gitops.
 func StreamDemoSSE(c *fiber.Ctx, dataKey string, demoData interface{}) error {
+	if transport.WantsNDJSON(c) {
+		return streamDemoNDJSON(c, dataKey, demoData)
+	}
 	c.Set("Content-Type", "text/event-stream")
 	c.Set("Cache-Control", "no-cache")
 	c.Set("Connection", "keep-alive")
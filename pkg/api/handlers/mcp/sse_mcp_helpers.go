@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/transport"
 	"github.com/kubestellar/console/pkg/safego"
 )
 
@@ -45,6 +46,9 @@ func waitWithDeadline(wg *sync.WaitGroup, cancel context.CancelFunc, deadline ti
 // streamDemoSSE streams demo data as a single SSE event for endpoints
 // that support server-sent events.
 func streamDemoSSE(c *fiber.Ctx, dataKey string, demoData interface{}) error {
+	if transport.WantsNDJSON(c) {
+		return streamDemoNDJSON(c, dataKey, demoData)
+	}
 	c.Set("Content-Type", "text/event-stream")
 	c.Set("Cache-Control", "no-cache")
 	c.Set("Connection", "keep-alive")
@@ -157,6 +157,8 @@ func CancelUserSSEStreams(userID uuid.UUID) {
 }
 
 // streamClusters is a generic helper that streams per-cluster results as SSE events.
+// With Accept: application/x-ndjson (or ?format=ndjson) the same events are
+// written as NDJSON lines instead; see writeNDJSONEvent.
 //
 // It uses HealthyClusters() to skip known-offline clusters (emitting
 // "cluster_skipped" events for them instantly), then spawns goroutines only for
@@ -229,10 +231,17 @@ func streamClusters(
 	// stream context inside the callback.
 	requestCtx := c.UserContext()
 
-	c.Set("Content-Type", "text/event-stream")
-	c.Set("Cache-Control", "no-cache")
-	c.Set("Connection", "keep-alive")
-	c.Set("X-Accel-Buffering", "no")
+	// Clients asking for NDJSON get the same events as one JSON object per
+	// line, with each cluster's list split into individual items.
+	ndjson := transport.WantsNDJSON(c)
+	if ndjson {
+		transport.SetNDJSONHeaders(c)
+	} else {
+		c.Set("Content-Type", "text/event-stream")
+		c.Set("Cache-Control", "no-cache")
+		c.Set("Connection", "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+	}
 
 	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
 		// Create a cancellable context with the overall deadline so that all
@@ -265,8 +274,14 @@ func streamClusters(
 		// goroutine aborts immediately instead of continuing to burn
 		// cluster-side work nobody will read (#6480). Returns true on
 		// success so callers can early-return on failure.
-		emitEvent := func(name string, data interface{}) bool {
-			if err := writeSSEEvent(w, name, data); err != nil {
+		emitEvent := func(name string, data fiber.Map) bool {
+			var err error
+			if ndjson {
+				err = writeNDJSONEvent(w, name, cfg.demoKey, data)
+			} else {
+				err = writeSSEEvent(w, name, data)
+			}
+			if err != nil {
 				slog.Info("[SSE] write failed, cancelling stream", "event", name, "error", err)
 				streamCancel()
 				return false
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/operations"
+)
+
+// OperationFinishedType is the WebSocket message type sent to the owner of
+// an operation when it succeeds, fails or is cancelled.
+const OperationFinishedType = "operation_finished"
+
+// NewOperationManager creates the operation manager shared by the handlers
+// that start long-running work. Finished operations are sent to their
+// owner's connections on hub.
+func NewOperationManager(hub *Hub) *operations.Manager {
+	return operations.NewManager(operations.DefaultRetention, func(op operations.Operation) {
+		if hub == nil {
+			return
+		}
+		owner, err := uuid.Parse(op.Owner)
+		if err != nil {
+			slog.Debug("[operations] not notifying operation without a user owner", "id", op.ID, "owner", op.Owner)
+			return
+		}
+		hub.Broadcast(owner, Message{Type: OperationFinishedType, Data: op})
+	})
+}
+
+// OperationsHandler serves the status of long-running operations.
+type OperationsHandler struct {
+	manager *operations.Manager
+}
+
+// NewOperationsHandler creates a handler over manager.
+func NewOperationsHandler(manager *operations.Manager) *OperationsHandler {
+	return &OperationsHandler{manager: manager}
+}
+
+// operationOwner is the owner recorded for operations the caller starts.
+func operationOwner(c *fiber.Ctx) string {
+	return middleware.GetUserID(c).String()
+}
+
+// startOperation starts fn for the caller and responds 202 with the
+// operation, which the caller polls at its Location.
+func startOperation(c *fiber.Ctx, manager *operations.Manager, spec operations.Spec, fn operations.Func) error {
+	spec.Owner = operationOwner(c)
+	op := manager.Start(spec, fn)
+	c.Location("/api/operations/" + op.ID)
+	return c.Status(fiber.StatusAccepted).JSON(op)
+}
+
+// ListOperations returns the caller's operations, newest first.
+// GET /api/operations
+func (h *OperationsHandler) ListOperations(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"operations": h.manager.List(operationOwner(c))})
+}
+
+// GetOperation returns the progress of an operation and, once it
+// finished, its result or error.
+// GET /api/operations/:id
+func (h *OperationsHandler) GetOperation(c *fiber.Ctx) error {
+	op, err := h.ownedOperation(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(op)
+}
+
+// CancelOperation asks a running operation to stop. It reports cancelled
+// once its work returns.
+// POST /api/operations/:id/cancel
+func (h *OperationsHandler) CancelOperation(c *fiber.Ctx) error {
+	op, err := h.ownedOperation(c)
+	if err != nil {
+		return err
+	}
+	switch err := h.manager.Cancel(op.ID); {
+	case errors.Is(err, operations.ErrFinished):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "operation already finished"})
+	case err != nil:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "operation not found"})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"id": op.ID, "cancelling": true})
+}
+
+// ownedOperation loads the operation of the :id param. Operations of other
+// users are reported as not found, not forbidden, so IDs cannot be probed.
+func (h *OperationsHandler) ownedOperation(c *fiber.Ctx) (operations.Operation, error) {
+	op, err := h.manager.Get(c.Params("id"))
+	if err != nil || op.Owner != operationOwner(c) {
+		return operations.Operation{}, fiber.NewError(fiber.StatusNotFound, "operation not found")
+	}
+	return op, nil
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOperationsTestApp serves the operations endpoints as user.
+func newOperationsTestApp(manager *operations.Manager, user uuid.UUID) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", user)
+		return c.Next()
+	})
+	h := NewOperationsHandler(manager)
+	app.Get("/api/operations", h.ListOperations)
+	app.Get("/api/operations/:id", h.GetOperation)
+	app.Post("/api/operations/:id/cancel", h.CancelOperation)
+	return app
+}
+
+func waitOperation(t *testing.T, manager *operations.Manager, id string) operations.Operation {
+	t.Helper()
+	var op operations.Operation
+	require.Eventually(t, func() bool {
+		var err error
+		op, err = manager.Get(id)
+		return err == nil && op.State.Done()
+	}, 5*time.Second, time.Millisecond)
+	return op
+}
+
+func TestOperationsHandler(t *testing.T) {
+	manager := operations.NewManager(0, nil)
+	defer manager.Close()
+	owner := uuid.New()
+	op := manager.Start(operations.Spec{Kind: "test", Owner: owner.String()}, func(ctx context.Context, p operations.Reporter) (any, error) {
+		p.Report(operations.Progress{Completed: 1, Total: 3})
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	app := newOperationsTestApp(manager, owner)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/operations/"+op.ID, nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var got operations.Operation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Equal(t, op.ID, got.ID)
+	assert.Equal(t, operations.StateRunning, got.State)
+
+	// Another user cannot see or cancel it.
+	other := newOperationsTestApp(manager, uuid.New())
+	resp, err = other.Test(httptest.NewRequest(http.MethodGet, "/api/operations/"+op.ID, nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, err = other.Test(httptest.NewRequest(http.MethodPost, "/api/operations/"+op.ID+"/cancel", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/api/operations/"+op.ID+"/cancel", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, operations.StateCancelled, waitOperation(t, manager, op.ID).State)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, "/api/operations/"+op.ID+"/cancel", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/operations", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	var list struct {
+		Operations []operations.Operation `json:"operations"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	require.Len(t, list.Operations, 1)
+	assert.Equal(t, op.ID, list.Operations[0].ID)
+}
+
+func TestSyncNow_Async(t *testing.T) {
+	h := setupSyncEnv(t, []string{"cluster-a"}, []string{"cluster-a"}, false)
+	h.healthChecker = syncReadiness{}
+	manager := operations.NewManager(0, nil)
+	defer manager.Close()
+	h.SetOperationManager(manager)
+	app := fiber.New()
+	app.Post("/api/persistence/sync", h.SyncNow)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/persistence/sync?async=true", nil), fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var op operations.Operation
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&op))
+	assert.Equal(t, PersistenceSyncOperation, op.Kind)
+
+	done := waitOperation(t, manager, op.ID)
+	require.Equal(t, operations.StateSucceeded, done.State, done.Error)
+	report, ok := done.Result.(*persistenceSyncReport)
+	require.True(t, ok)
+	assert.Equal(t, 1, report.WorkloadDeployments)
+}
@@ -37,6 +37,7 @@ func newPersistenceAuthTestApp(t *testing.T, role models.UserRole) *fiber.App {
 	})
 	app.Get("/api/persistence/config", handler.GetConfig)
 	app.Post("/api/persistence/test", handler.TestConnection)
+	app.Post("/api/persistence/install-crds", handler.InstallCRDs)
 
 	return app
 }
@@ -62,6 +63,13 @@ func TestPersistenceEndpointsRequireAdmin(t *testing.T) {
 			body:       `{"cluster":"test-cluster"}`,
 			wantStatus: http.StatusForbidden,
 		},
+		{
+			name:       "InstallCRDsForbiddenForViewer",
+			method:     http.MethodPost,
+			path:       "/api/persistence/install-crds",
+			body:       `{"cluster":"test-cluster"}`,
+			wantStatus: http.StatusForbidden,
+		},
 	}
 
 	app := newPersistenceAuthTestApp(t, models.UserRoleViewer)
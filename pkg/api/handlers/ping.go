@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/egress"
 )
 
 // pingClient is a shared HTTP client for ping requests.
@@ -22,7 +24,7 @@ var pingClient = &http.Client{
 	Transport: &http.Transport{
 		TLSClientConfig:   &tls.Config{MinVersion: tls.VersionTLS12},
 		DisableKeepAlives: true,
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		DialContext: egress.Dial(func(ctx context.Context, network, addr string) (net.Conn, error) {
 			host, port, err := net.SplitHostPort(addr)
 			if err != nil {
 				return nil, err
@@ -42,7 +44,7 @@ var pingClient = &http.Client{
 			// Connect to the first validated IP directly — no second DNS lookup.
 			dialer := &net.Dialer{Timeout: 5 * time.Second}
 			return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
-		},
+		}),
 	},
 	// Do not follow redirects — we only care about reachability
 	CheckRedirect: func(req *http.Request, via []*http.Request) error {
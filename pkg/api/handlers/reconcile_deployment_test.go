@@ -12,7 +12,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/rest"
@@ -24,7 +26,13 @@ func setupReconcileEnv(t *testing.T, persistenceObjects ...runtime.Object) (*Con
 	t.Helper()
 
 	scheme := runtime.NewScheme()
-	fakeDyn := fake.NewSimpleDynamicClient(scheme, persistenceObjects...)
+	// Register the console list kinds so ClusterGroups can be listed even
+	// when none are seeded.
+	fakeDyn := fake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		v1alpha1.ManagedWorkloadGVR:    "ManagedWorkloadList",
+		v1alpha1.ClusterGroupGVR:       "ClusterGroupList",
+		v1alpha1.WorkloadDeploymentGVR: "WorkloadDeploymentList",
+	}, persistenceObjects...)
 
 	// Build a persistence store that points at "persist-cluster"
 	configPath := filepath.Join(t.TempDir(), "persistence.json")
@@ -55,6 +63,91 @@ func setupReconcileEnv(t *testing.T, persistenceObjects ...runtime.Object) (*Con
 	return h, fakeDyn
 }
 
+// reconcileFixture is what most reconcile tests seed: the my-app
+// ManagedWorkload, which deploys the nginx Deployment from source-cluster,
+// and one WorkloadDeployment of it, both in test-ns.
+type reconcileFixture struct {
+	workload   *v1alpha1.ManagedWorkload
+	deployment *v1alpha1.WorkloadDeployment // nil to seed none
+	extra      []unstructuredObject
+}
+
+// unstructuredObject is a console CR that can be seeded into the fake
+// dynamic client.
+type unstructuredObject interface {
+	ToUnstructured() (*unstructured.Unstructured, error)
+}
+
+// reconcileOption adjusts a reconcileFixture before it is seeded.
+type reconcileOption func(*reconcileFixture)
+
+// withWorkload edits the my-app ManagedWorkload.
+func withWorkload(edit func(*v1alpha1.ManagedWorkload)) reconcileOption {
+	return func(f *reconcileFixture) { edit(f.workload) }
+}
+
+// withDeployment edits the WorkloadDeployment.
+func withDeployment(edit func(*v1alpha1.WorkloadDeployment)) reconcileOption {
+	return func(f *reconcileFixture) { edit(f.deployment) }
+}
+
+// withTargets sets the clusters the WorkloadDeployment targets.
+func withTargets(clusters ...string) reconcileOption {
+	return withDeployment(func(wd *v1alpha1.WorkloadDeployment) { wd.Spec.TargetClusters = clusters })
+}
+
+// withoutDeployment seeds only the ManagedWorkload.
+func withoutDeployment() reconcileOption {
+	return func(f *reconcileFixture) { f.deployment = nil }
+}
+
+// withObjects seeds more console CRs, such as ClusterGroups or other
+// deployments, after the fixture's own.
+func withObjects(objs ...unstructuredObject) reconcileOption {
+	return func(f *reconcileFixture) { f.extra = append(f.extra, objs...) }
+}
+
+// newReconcileFixture seeds the fixture adjusted by opts through
+// setupReconcileEnv. It returns the WorkloadDeployment as seeded, nil
+// with withoutDeployment.
+func newReconcileFixture(t *testing.T, opts ...reconcileOption) (*ConsolePersistenceHandlers, *v1alpha1.WorkloadDeployment) {
+	t.Helper()
+	f := &reconcileFixture{
+		workload: &v1alpha1.ManagedWorkload{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "ManagedWorkload"},
+			ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "test-ns"},
+			Spec: v1alpha1.ManagedWorkloadSpec{
+				SourceCluster:   "source-cluster",
+				SourceNamespace: "default",
+				WorkloadRef:     v1alpha1.WorkloadReference{Kind: "Deployment", Name: "nginx"},
+			},
+		},
+		deployment: &v1alpha1.WorkloadDeployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "WorkloadDeployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "wd-app", Namespace: "test-ns"},
+			Spec: v1alpha1.WorkloadDeploymentSpec{
+				WorkloadRef: v1alpha1.ResourceReference{Name: "my-app"},
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	seed := []unstructuredObject{f.workload}
+	if f.deployment != nil {
+		seed = append(seed, f.deployment)
+	}
+	var objects []runtime.Object
+	for _, obj := range append(seed, f.extra...) {
+		u, err := obj.ToUnstructured()
+		require.NoError(t, err)
+		objects = append(objects, u)
+	}
+	h, _ := setupReconcileEnv(t, objects...)
+	return h, f.deployment
+}
+
 func TestResolveTargetClusters_ExplicitOnly(t *testing.T) {
 	h, _ := setupReconcileEnv(t)
 
@@ -453,6 +546,87 @@ func TestReconcileDeployment_DeployAllClustersFail(t *testing.T) {
 	assert.Equal(t, "0/2 clusters", wd.Status.Progress)
 }
 
+// observingDeployer implements workloadDeployer and runs onDeploy before
+// reporting every target as deployed.
+type observingDeployer struct {
+	onDeploy func(targets []string)
+}
+
+func (f *observingDeployer) DeployWorkload(_ context.Context, _, _, _ string,
+	targets []string, _ int32, _ *k8s.DeployOptions,
+) (*v1alpha1.DeployResponse, error) {
+	f.onDeploy(targets)
+	return &v1alpha1.DeployResponse{DeployedTo: targets}, nil
+}
+
+func TestReconcileDeployment_ReportsInProgress(t *testing.T) {
+	// While DeployWorkload runs, the persisted status must show every target
+	// as InProgress with a start time, not Pending.
+	mw := &v1alpha1.ManagedWorkload{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "ManagedWorkload",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "test-ns"},
+		Spec: v1alpha1.ManagedWorkloadSpec{
+			SourceCluster:   "source-cluster",
+			SourceNamespace: "default",
+			WorkloadRef: v1alpha1.WorkloadReference{
+				Kind: "Deployment",
+				Name: "nginx",
+			},
+		},
+	}
+	mwU, _ := mw.ToUnstructured()
+
+	wd := &v1alpha1.WorkloadDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "WorkloadDeployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "wd-progress", Namespace: "test-ns"},
+		Spec: v1alpha1.WorkloadDeploymentSpec{
+			WorkloadRef:    v1alpha1.ResourceReference{Name: "my-app"},
+			TargetClusters: []string{"cluster-a", "cluster-b"},
+		},
+		Status: v1alpha1.WorkloadDeploymentStatus{
+			Phase: "Pending",
+		},
+	}
+	wdU, _ := wd.ToUnstructured()
+
+	h, fakeDyn := setupReconcileEnv(t, mwU, wdU)
+
+	var during []v1alpha1.ClusterRolloutStatus
+	h.deployer = &observingDeployer{onDeploy: func(targets []string) {
+		assert.ElementsMatch(t, []string{"cluster-a", "cluster-b"}, targets)
+		u, err := fakeDyn.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace("test-ns").
+			Get(context.Background(), "wd-progress", metav1.GetOptions{})
+		require.NoError(t, err)
+		persisted, err := v1alpha1.WorkloadDeploymentFromUnstructured(u)
+		require.NoError(t, err)
+		during = persisted.Status.ClusterStatuses
+	}}
+
+	h.reconcileDeployment(context.Background(), wd)
+
+	require.Len(t, during, 2)
+	for _, cs := range during {
+		assert.Equal(t, "InProgress", cs.Phase, "cluster %s", cs.Cluster)
+		assert.Equal(t, "Deploying", cs.Message)
+		assert.NotNil(t, cs.StartedAt, "cluster %s should have StartedAt", cs.Cluster)
+	}
+
+	assert.Equal(t, "Complete", wd.Status.Phase)
+	require.Len(t, wd.Status.ClusterStatuses, 2)
+	for _, cs := range wd.Status.ClusterStatuses {
+		assert.Equal(t, "Complete", cs.Phase, "cluster %s", cs.Cluster)
+		assert.NotNil(t, cs.StartedAt, "StartedAt is kept once the cluster settles")
+		assert.NotNil(t, cs.CompletedAt)
+	}
+	assert.Equal(t, "2/2 clusters", wd.Status.Progress)
+}
+
 // TestSetTerminalStatus_ReconcileDeployment tests setTerminalStatus in the context
 // of reconcile_deployment. The canonical TestSetTerminalStatus lives in
 // console_persistence_helpers_test.go.
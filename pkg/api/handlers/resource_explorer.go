@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceExplorerTimeout bounds a single explorer request against one cluster.
+const resourceExplorerTimeout = 30 * time.Second
+
+// resourceExplorerClient defines the narrow subset of k8s.MultiClusterClient
+// used by ResourceExplorerHandlers.
+type resourceExplorerClient interface {
+	DiscoverAPIResources(ctx context.Context, contextName string) ([]k8s.APIResourceInfo, error)
+	ListResourceTable(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string, opts k8s.ResourceListOptions) (*k8s.ResourceTable, error)
+	GetResource(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error)
+	GetResourceSchema(ctx context.Context, contextName string, gvr schema.GroupVersionResource) (map[string]interface{}, error)
+}
+
+// ResourceExplorerHandlers serves the read side of the generic resource
+// explorer: CRD-aware discovery, server-printed lists, single-object gets,
+// and schema retrieval for arbitrary GVRs. Patches and RBAC capability
+// hints are served by kc-agent (/resources/patch, /resources/capabilities)
+// so they run under the user's kubeconfig (#7993).
+type ResourceExplorerHandlers struct {
+	k8sClient resourceExplorerClient
+}
+
+// NewResourceExplorerHandlers creates a new resource explorer handlers instance.
+func NewResourceExplorerHandlers(k8sClient *k8s.MultiClusterClient) *ResourceExplorerHandlers {
+	h := &ResourceExplorerHandlers{}
+	// Avoid storing a typed nil pointer in the interface so the nil check in
+	// each handler behaves as expected.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// explorerDeniedResources are resources the explorer never serves. It reads
+// with the console's credentials, which can usually see Secret values the
+// signed-in user should not.
+var explorerDeniedResources = map[string]bool{
+	"secrets": true,
+}
+
+// APIResourceListResponse is the response for GET /api/explorer/:cluster/resources
+type APIResourceListResponse struct {
+	Cluster   string                `json:"cluster"`
+	Resources []k8s.APIResourceInfo `json:"resources"`
+}
+
+// parseExplorerGVR reads group/version/resource query parameters. Group may
+// be empty for the core API group.
+func parseExplorerGVR(c *fiber.Ctx) (schema.GroupVersionResource, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    c.Query("group"),
+		Version:  c.Query("version"),
+		Resource: c.Query("resource"),
+	}
+	if gvr.Group != "" {
+		if err := validateDNSSubdomain("group", gvr.Group); err != nil {
+			return gvr, err
+		}
+	}
+	if err := validateDNSLabel("version", gvr.Version); err != nil {
+		return gvr, err
+	}
+	if err := validateDNSLabel("resource", gvr.Resource); err != nil {
+		return gvr, err
+	}
+	if explorerDeniedResources[gvr.Resource] {
+		return gvr, fmt.Errorf("resource %q is not allowed", gvr.Resource)
+	}
+	return gvr, nil
+}
+
+// explorerTarget validates the cluster path parameter and optional namespace
+// shared by every explorer route.
+func (h *ResourceExplorerHandlers) explorerTarget(c *fiber.Ctx) (string, string, error) {
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return "", "", err
+	}
+	namespace := c.Query("namespace")
+	if namespace != "" {
+		if err := validateDNSLabel("namespace", namespace); err != nil {
+			return "", "", err
+		}
+	}
+	return cluster, namespace, nil
+}
+
+// ListAPIResources returns every listable resource kind on a cluster,
+// including CRD-backed kinds.
+// GET /api/explorer/:cluster/resources
+func (h *ResourceExplorerHandlers) ListAPIResources(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster, _, err := h.explorerTarget(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	resources, err := h.k8sClient.DiscoverAPIResources(ctx, cluster)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	return c.JSON(APIResourceListResponse{Cluster: cluster, Resources: resources})
+}
+
+// ListObjects lists objects of any resource kind rendered with the
+// apiserver's printer columns.
+// GET /api/explorer/:cluster/objects?group=&version=&resource=&namespace=&labelSelector=&limit=&continue=
+func (h *ResourceExplorerHandlers) ListObjects(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster, namespace, err := h.explorerTarget(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	gvr, err := parseExplorerGVR(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	opts := k8s.ResourceListOptions{
+		LabelSelector: c.Query("labelSelector"),
+		FieldSelector: c.Query("fieldSelector"),
+		Continue:      c.Query("continue"),
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, parseErr := strconv.ParseInt(raw, 10, 64)
+		if parseErr != nil || limit < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be a non-negative integer"})
+		}
+		opts.Limit = limit
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	table, err := h.k8sClient.ListResourceTable(ctx, cluster, gvr, namespace, opts)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	return c.JSON(table)
+}
+
+// GetObject returns a single object of any resource kind.
+// GET /api/explorer/:cluster/object?group=&version=&resource=&namespace=&name=
+func (h *ResourceExplorerHandlers) GetObject(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster, namespace, err := h.explorerTarget(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	gvr, err := parseExplorerGVR(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	name := c.Query("name")
+	if err := validateDNSSubdomain("name", name); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	obj, err := h.k8sClient.GetResource(ctx, cluster, gvr, namespace, name)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	return c.JSON(obj.Object)
+}
+
+// GetSchema returns the openAPIV3Schema of a CRD-backed resource kind so the
+// frontend can generate an edit form.
+// GET /api/explorer/:cluster/schema?group=&version=&resource=
+func (h *ResourceExplorerHandlers) GetSchema(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster, _, err := h.explorerTarget(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	gvr, err := parseExplorerGVR(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	s, err := h.k8sClient.GetResourceSchema(ctx, cluster, gvr)
+	if errors.Is(err, k8s.ErrSchemaUnavailable) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no schema available for this resource"})
+	}
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"cluster":  cluster,
+		"group":    gvr.Group,
+		"version":  gvr.Version,
+		"resource": gvr.Resource,
+		"schema":   s,
+	})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func setupResourceExplorerTest(t *testing.T) (*testEnv, schema.GroupVersionResource) {
+	t.Helper()
+	env := setupTestEnv(t)
+	handler := NewResourceExplorerHandlers(env.K8sClient)
+	env.App.Get("/api/explorer/:cluster/resources", handler.ListAPIResources)
+	env.App.Get("/api/explorer/:cluster/objects", handler.ListObjects)
+	env.App.Get("/api/explorer/:cluster/object", handler.GetObject)
+	env.App.Get("/api/explorer/:cluster/schema", handler.GetSchema)
+
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	gvrKinds := map[schema.GroupVersionResource]string{
+		crdGVR:  "CustomResourceDefinitionList",
+		widgets: "WidgetList",
+		secrets: "SecretList",
+	}
+	dynClient := injectDynamicCluster(env, "test-cluster", gvrKinds)
+
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets.example.com"},
+		"spec": map[string]interface{}{
+			"group": "example.com",
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name":   "v1",
+					"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{"type": "object"}},
+				},
+			},
+		},
+	}}
+	_, err := dynClient.Resource(crdGVR).Create(context.Background(), crd, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w1", "namespace": "default"},
+	}}
+	_, err = dynClient.Resource(widgets).Namespace("default").Create(context.Background(), widget, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db-creds", "namespace": "default"},
+		"data":       map[string]interface{}{"password": "aHVudGVyMg=="},
+	}}
+	_, err = dynClient.Resource(secrets).Namespace("default").Create(context.Background(), secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	return env, widgets
+}
+
+func TestResourceExplorer_ListObjects(t *testing.T) {
+	env, _ := setupResourceExplorerTest(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/explorer/test-cluster/objects?group=example.com&version=v1&resource=widgets&namespace=default", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var table k8s.ResourceTable
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&table))
+	require.Len(t, table.Rows, 1)
+	assert.Equal(t, "w1", table.Rows[0].Name)
+	assert.NotEmpty(t, table.Columns)
+}
+
+func TestResourceExplorer_GetObject(t *testing.T) {
+	env, _ := setupResourceExplorerTest(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/explorer/test-cluster/object?group=example.com&version=v1&resource=widgets&namespace=default&name=w1", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var obj map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&obj))
+	assert.Equal(t, "Widget", obj["kind"])
+}
+
+func TestResourceExplorer_RefusesSecrets(t *testing.T) {
+	env, _ := setupResourceExplorerTest(t)
+
+	// The explorer reads with the console's credentials, so a plain viewer
+	// must not be able to pull Secret values through it.
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", uuid.New())
+		return c.Next()
+	})
+	handler := NewResourceExplorerHandlers(env.K8sClient)
+	app.Get("/api/explorer/:cluster/objects", handler.ListObjects)
+	app.Get("/api/explorer/:cluster/object", handler.GetObject)
+
+	for _, path := range []string{
+		"/api/explorer/test-cluster/object?version=v1&resource=secrets&namespace=default&name=db-creds",
+		"/api/explorer/test-cluster/objects?version=v1&resource=secrets&namespace=default",
+	} {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "aHVudGVyMg==", path)
+	}
+}
+
+func TestResourceExplorer_GetSchema(t *testing.T) {
+	env, _ := setupResourceExplorerTest(t)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/explorer/test-cluster/schema?group=example.com&version=v1&resource=widgets", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Built-in kinds have no CRD schema.
+	req, err = http.NewRequest(http.MethodGet, "/api/explorer/test-cluster/schema?version=v1&resource=pods", nil)
+	require.NoError(t, err)
+	resp, err = env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestResourceExplorer_InvalidParams(t *testing.T) {
+	env, _ := setupResourceExplorerTest(t)
+
+	cases := []string{
+		"/api/explorer/test-cluster/objects?version=v1",                                // missing resource
+		"/api/explorer/test-cluster/objects?version=v1&resource=Widgets",               // not a DNS label
+		"/api/explorer/test-cluster/objects?version=v1&resource=pods&limit=-1",         // bad limit
+		"/api/explorer/test-cluster/object?version=v1&resource=pods&namespace=default", // missing name
+	}
+	for _, path := range cases {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		resp, err := env.App.Test(req, 5000)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+	}
+}
+
+func TestResourceExplorer_NoClient(t *testing.T) {
+	env := setupTestEnv(t)
+	handler := NewResourceExplorerHandlers(nil)
+	env.App.Get("/api/explorer/:cluster/resources", handler.ListAPIResources)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/explorer/test-cluster/resources", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
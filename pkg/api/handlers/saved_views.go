@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// MaxSavedViewsPerProject caps how many saved views a console project may
+	// hold so a runaway client cannot exhaust storage.
+	MaxSavedViewsPerProject = 200
+	// maxSavedViewNameLen bounds the display name of a saved view.
+	maxSavedViewNameLen = 128
+	// maxSavedViewClusters bounds the explicit cluster list of a saved view.
+	maxSavedViewClusters = 100
+	// maxSavedViewColumns bounds the column projection of a saved view.
+	maxSavedViewColumns = 32
+	// savedViewResultsTTL is how long executed view results are served from
+	// cache before the clusters are queried again. ?refresh=true bypasses it.
+	savedViewResultsTTL = 30 * time.Second
+)
+
+// savedViewClient defines the narrow subset of k8s.MultiClusterClient used by
+// SavedViewHandler.
+type savedViewClient interface {
+	HealthyClusters(ctx context.Context) (healthy []k8s.ClusterInfo, offline []k8s.ClusterInfo, err error)
+	ListResourceTable(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string, opts k8s.ResourceListOptions) (*k8s.ResourceTable, error)
+}
+
+// SavedViewResultRow is one object in a saved view result, tagged with the
+// cluster it came from. Cells line up with SavedViewResults.Columns.
+type SavedViewResultRow struct {
+	Cluster   string        `json:"cluster"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Cells     []interface{} `json:"cells"`
+}
+
+// SavedViewResults is the response for GET /api/views/:id/results.
+type SavedViewResults struct {
+	ViewID    uuid.UUID            `json:"view_id"`
+	Columns   []string             `json:"columns"`
+	Rows      []SavedViewResultRow `json:"rows"`
+	Clusters  []string             `json:"clusters"`
+	Errors    map[string]string    `json:"errors,omitempty"`
+	FetchedAt time.Time            `json:"fetched_at"`
+	Cached    bool                 `json:"cached"`
+}
+
+// savedViewCacheEntry holds the last executed result of one saved view.
+type savedViewCacheEntry struct {
+	results   SavedViewResults
+	expiresAt time.Time
+}
+
+// SavedViewHandler serves named cross-cluster resource queries shared within
+// the active console project and executes them against the clusters they
+// target.
+type SavedViewHandler struct {
+	store     store.Store
+	k8sClient savedViewClient
+	project   string
+
+	cacheMu sync.Mutex
+	cache   map[uuid.UUID]savedViewCacheEntry
+}
+
+// NewSavedViewHandler creates a new saved view handler scoped to project.
+func NewSavedViewHandler(s store.Store, k8sClient *k8s.MultiClusterClient, project string) *SavedViewHandler {
+	h := &SavedViewHandler{
+		store:   s,
+		project: project,
+		cache:   make(map[uuid.UUID]savedViewCacheEntry),
+	}
+	// Avoid storing a typed nil pointer in the interface so the nil check in
+	// GetResults behaves as expected.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// savedViewInput is the body accepted by POST and PUT /api/views.
+type savedViewInput struct {
+	Name  string                `json:"name"`
+	Query models.SavedViewQuery `json:"query"`
+}
+
+// validateSavedViewInput checks a saved view definition before it is stored.
+func validateSavedViewInput(in *savedViewInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(in.Name) > maxSavedViewNameLen {
+		return fmt.Errorf("name must be at most %d characters", maxSavedViewNameLen)
+	}
+	return validateSavedViewQuery(in.Query)
+}
+
+// validateSavedViewQuery checks a resource query before it is stored or
+// scheduled.
+func validateSavedViewQuery(q models.SavedViewQuery) error {
+	if q.Group != "" {
+		if err := validateDNSSubdomain("query.group", q.Group); err != nil {
+			return err
+		}
+	}
+	if err := validateDNSLabel("query.version", q.Version); err != nil {
+		return err
+	}
+	if err := validateDNSLabel("query.resource", q.Resource); err != nil {
+		return err
+	}
+	if q.Namespace != "" {
+		if err := validateDNSLabel("query.namespace", q.Namespace); err != nil {
+			return err
+		}
+	}
+	if len(q.Clusters) > 0 && q.ClusterGroup != "" {
+		return errors.New("query.clusters and query.cluster_group are mutually exclusive")
+	}
+	if len(q.Clusters) > maxSavedViewClusters {
+		return fmt.Errorf("query.clusters must list at most %d clusters", maxSavedViewClusters)
+	}
+	for _, cluster := range q.Clusters {
+		if err := validateClusterName("query.clusters", cluster); err != nil {
+			return err
+		}
+	}
+	if q.ClusterGroup != "" {
+		if err := validateClusterName("query.cluster_group", q.ClusterGroup); err != nil {
+			return err
+		}
+	}
+	if q.LabelSelector != "" {
+		if _, err := labels.Parse(q.LabelSelector); err != nil {
+			return fmt.Errorf("query.label_selector is invalid: %v", err)
+		}
+	}
+	if len(q.Columns) > maxSavedViewColumns {
+		return fmt.Errorf("query.columns must list at most %d columns", maxSavedViewColumns)
+	}
+	for _, col := range q.Columns {
+		if strings.TrimSpace(col) == "" {
+			return errors.New("query.columns must not contain empty names")
+		}
+	}
+	return nil
+}
+
+// loadView fetches a saved view by the :id path parameter and hides views
+// that belong to another project.
+func (h *SavedViewHandler) loadView(c *fiber.Ctx) (*models.SavedView, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid view ID")
+	}
+	view, err := h.store.GetSavedView(c.UserContext(), id)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get view")
+	}
+	if view == nil || view.Project != h.project {
+		return nil, fiber.NewError(fiber.StatusNotFound, "View not found")
+	}
+	return view, nil
+}
+
+// ListViews returns a page of the saved views in the active project.
+// GET /api/views
+func (h *SavedViewHandler) ListViews(c *fiber.Ctx) error {
+	limit, offset, err := ParsePageParams(c)
+	if err != nil {
+		return err
+	}
+	views, err := h.store.ListSavedViews(c.UserContext(), h.project, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list views")
+	}
+	// Never marshal a Go nil slice as JSON null; clients expect [].
+	if views == nil {
+		views = []models.SavedView{}
+	}
+	return c.JSON(views)
+}
+
+// GetView returns a single saved view definition.
+// GET /api/views/:id
+func (h *SavedViewHandler) GetView(c *fiber.Ctx) error {
+	view, err := h.loadView(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(view)
+}
+
+// CreateView saves a new named query in the active project.
+// POST /api/views
+func (h *SavedViewHandler) CreateView(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+
+	var input savedViewInput
+	if err := c.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateSavedViewInput(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	count, err := h.store.CountProjectSavedViews(c.UserContext(), h.project)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to check view count")
+	}
+	if count >= MaxSavedViewsPerProject {
+		return fiber.NewError(fiber.StatusTooManyRequests,
+			fmt.Sprintf("View limit reached (%d), maximum is %d per project", count, MaxSavedViewsPerProject))
+	}
+
+	view := &models.SavedView{
+		Project:   h.project,
+		Name:      input.Name,
+		Query:     input.Query,
+		CreatedBy: middleware.GetUserID(c),
+	}
+	if err := h.store.CreateSavedView(c.UserContext(), view); err != nil {
+		if errors.Is(err, store.ErrSavedViewNameTaken) {
+			return fiber.NewError(fiber.StatusConflict, "A view with this name already exists")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create view")
+	}
+	return c.Status(fiber.StatusCreated).JSON(view)
+}
+
+// UpdateView replaces the name and query of a saved view.
+// PUT /api/views/:id
+func (h *SavedViewHandler) UpdateView(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	view, err := h.loadView(c)
+	if err != nil {
+		return err
+	}
+
+	var input savedViewInput
+	if err := c.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateSavedViewInput(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	view.Name = input.Name
+	view.Query = input.Query
+	if err := h.store.UpdateSavedView(c.UserContext(), view); err != nil {
+		switch {
+		case errors.Is(err, store.ErrSavedViewNameTaken):
+			return fiber.NewError(fiber.StatusConflict, "A view with this name already exists")
+		case errors.Is(err, store.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "View not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update view")
+	}
+	h.invalidate(view.ID)
+	return c.JSON(view)
+}
+
+// DeleteView removes a saved view.
+// DELETE /api/views/:id
+func (h *SavedViewHandler) DeleteView(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	view, err := h.loadView(c)
+	if err != nil {
+		return err
+	}
+	if err := h.store.DeleteSavedView(c.UserContext(), view.ID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete view")
+	}
+	h.invalidate(view.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetResults executes a saved view across its target clusters and returns
+// the merged rows. Results are cached for savedViewResultsTTL; pass
+// ?refresh=true to force a fresh fan-out. Per-cluster failures are reported
+// in the errors map instead of failing the whole request.
+// GET /api/views/:id/results
+func (h *SavedViewHandler) GetResults(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	view, err := h.loadView(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Query("refresh") != "true" {
+		if cached, ok := h.cached(view.ID); ok {
+			return c.JSON(cached)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+
+	clusters, err := h.resolveClusters(ctx, view.Query)
+	if err != nil {
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			return err
+		}
+		return HandleK8sError(c, err)
+	}
+
+	results := h.execute(ctx, view, clusters)
+	h.cacheMu.Lock()
+	h.cache[view.ID] = savedViewCacheEntry{results: results, expiresAt: results.FetchedAt.Add(savedViewResultsTTL)}
+	h.cacheMu.Unlock()
+	return c.JSON(results)
+}
+
+// cached returns an unexpired cached result for a view.
+func (h *SavedViewHandler) cached(id uuid.UUID) (SavedViewResults, bool) {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+	entry, ok := h.cache[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return SavedViewResults{}, false
+	}
+	results := entry.results
+	results.Cached = true
+	return results, true
+}
+
+// invalidate drops the cached result of a view after it is edited or deleted.
+func (h *SavedViewHandler) invalidate(id uuid.UUID) {
+	h.cacheMu.Lock()
+	delete(h.cache, id)
+	h.cacheMu.Unlock()
+}
+
+// resolveClusters returns the cluster contexts a query targets: the explicit
+// list, the persisted members of its cluster group, or every healthy cluster.
+func (h *SavedViewHandler) resolveClusters(ctx context.Context, q models.SavedViewQuery) ([]string, error) {
+	if len(q.Clusters) > 0 {
+		return q.Clusters, nil
+	}
+	if q.ClusterGroup != "" {
+		groups, err := h.store.ListClusterGroups(ctx)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to load cluster groups")
+		}
+		data, ok := groups[q.ClusterGroup]
+		if !ok {
+			return nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("Cluster group %q not found", q.ClusterGroup))
+		}
+		// Dynamic groups persist their last evaluation result in Clusters, so
+		// the same field serves both static and dynamic groups.
+		var group struct {
+			Clusters []string `json:"clusters"`
+		}
+		if err := json.Unmarshal(data, &group); err != nil {
+			slog.Error("[SavedViews] failed to unmarshal persisted cluster group", "name", q.ClusterGroup, "error", err)
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to load cluster group")
+		}
+		return group.Clusters, nil
+	}
+
+	healthy, _, err := h.k8sClient.HealthyClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(healthy))
+	for _, cl := range healthy {
+		names = append(names, cl.Name)
+	}
+	return names, nil
+}
+
+// execute fans the view's query out to clusters and merges the per-cluster
+// tables onto a single column layout.
+func (h *SavedViewHandler) execute(ctx context.Context, view *models.SavedView, clusters []string) SavedViewResults {
+	q := view.Query
+	gvr := schema.GroupVersionResource{Group: q.Group, Version: q.Version, Resource: q.Resource}
+	opts := k8s.ResourceListOptions{LabelSelector: q.LabelSelector}
+
+	tables := make([]*k8s.ResourceTable, len(clusters))
+	clusterErrors := make(map[string]string)
+	var mu sync.Mutex
+
+	// Fan out across clusters in parallel, bounded by the shared per-cluster
+	// connection budget (#7966).
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(defaultClusterFanoutConcurrency)
+	for i, cluster := range clusters {
+		g.Go(func() error {
+			table, err := h.k8sClient.ListResourceTable(gctx, cluster, gvr, q.Namespace, opts)
+			if err != nil {
+				slog.Error("[SavedViews] failed to list resources", "view", view.ID, "cluster", cluster, "resource", gvr.String(), "error", err)
+				mu.Lock()
+				clusterErrors[cluster] = "failed to list resources"
+				mu.Unlock()
+				return nil
+			}
+			tables[i] = table
+			return nil
+		})
+	}
+	_ = g.Wait() // per-cluster errors are non-fatal and collected in clusterErrors.
+
+	columns := q.Columns
+	if len(columns) == 0 {
+		for _, t := range tables {
+			if t != nil {
+				for _, col := range t.Columns {
+					columns = append(columns, col.Name)
+				}
+				break
+			}
+		}
+	}
+
+	results := SavedViewResults{
+		ViewID:    view.ID,
+		Columns:   columns,
+		Rows:      make([]SavedViewResultRow, 0),
+		Clusters:  clusters,
+		FetchedAt: time.Now(),
+	}
+	if results.Columns == nil {
+		results.Columns = []string{}
+	}
+	for i, t := range tables {
+		if t == nil {
+			continue
+		}
+		index := projectColumns(t.Columns, columns)
+		for _, row := range t.Rows {
+			cells := make([]interface{}, len(index))
+			for j, src := range index {
+				if src >= 0 && src < len(row.Cells) {
+					cells[j] = row.Cells[src]
+				}
+			}
+			results.Rows = append(results.Rows, SavedViewResultRow{
+				Cluster:   clusters[i],
+				Name:      row.Name,
+				Namespace: row.Namespace,
+				Cells:     cells,
+			})
+		}
+	}
+	if len(clusterErrors) > 0 {
+		results.Errors = clusterErrors
+	}
+	return results
+}
+
+// projectColumns maps each wanted column name (case-insensitive) to its index
+// in have, or -1 when the cluster does not print that column.
+func projectColumns(have []k8s.ResourceTableColumn, want []string) []int {
+	byName := make(map[string]int, len(have))
+	for i, col := range have {
+		byName[strings.ToLower(col.Name)] = i
+	}
+	index := make([]int, len(want))
+	for i, name := range want {
+		src, ok := byName[strings.ToLower(name)]
+		if !ok {
+			src = -1
+		}
+		index[i] = src
+	}
+	return index
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const testSavedViewProject = "kubestellar"
+
+func setupSavedViewTest(t *testing.T) (*testEnv, *test.MockStore, *SavedViewHandler) {
+	t.Helper()
+	env := setupTestEnv(t)
+	mockStore := env.Store.(*test.MockStore)
+	handler := NewSavedViewHandler(env.Store, env.K8sClient, testSavedViewProject)
+	env.App.Get("/api/views", handler.ListViews)
+	env.App.Post("/api/views", handler.CreateView)
+	env.App.Get("/api/views/:id", handler.GetView)
+	env.App.Put("/api/views/:id", handler.UpdateView)
+	env.App.Delete("/api/views/:id", handler.DeleteView)
+	env.App.Get("/api/views/:id/results", handler.GetResults)
+	return env, mockStore, handler
+}
+
+func seedWidgets(t *testing.T, env *testEnv) schema.GroupVersionResource {
+	t.Helper()
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	dynClient := injectDynamicCluster(env, "test-cluster", map[schema.GroupVersionResource]string{widgets: "WidgetList"})
+	for name, gpu := range map[string]string{"w1": "true", "w2": "false"} {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"labels":    map[string]interface{}{"gpu": gpu},
+			},
+		}}
+		_, err := dynClient.Resource(widgets).Namespace("default").Create(context.Background(), obj, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	return widgets
+}
+
+func TestSavedViews_Create(t *testing.T) {
+	env, mockStore, _ := setupSavedViewTest(t)
+	mockStore.On("CountProjectSavedViews", testSavedViewProject).Return(0, nil)
+	mockStore.On("CreateSavedView", mock.MatchedBy(func(v *models.SavedView) bool {
+		return v.Project == testSavedViewProject && v.Name == "GPU pods" && v.CreatedBy == testAdminUserID
+	})).Return(nil)
+
+	body, _ := json.Marshal(savedViewInput{
+		Name:  "  GPU pods ",
+		Query: models.SavedViewQuery{Version: "v1", Resource: "pods", ClusterGroup: "prod", LabelSelector: "gpu=true"},
+	})
+	req, err := http.NewRequest(http.MethodPost, "/api/views", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	mockStore.AssertExpectations(t)
+}
+
+func TestSavedViews_CreateErrors(t *testing.T) {
+	env, mockStore, _ := setupSavedViewTest(t)
+	mockStore.On("CountProjectSavedViews", testSavedViewProject).Return(0, nil)
+	mockStore.On("CreateSavedView", mock.Anything).Return(store.ErrSavedViewNameTaken)
+
+	cases := map[string]struct {
+		input savedViewInput
+		want  int
+	}{
+		"missing name":     {savedViewInput{Query: models.SavedViewQuery{Version: "v1", Resource: "pods"}}, http.StatusBadRequest},
+		"missing resource": {savedViewInput{Name: "x", Query: models.SavedViewQuery{Version: "v1"}}, http.StatusBadRequest},
+		"bad selector":     {savedViewInput{Name: "x", Query: models.SavedViewQuery{Version: "v1", Resource: "pods", LabelSelector: "a in ("}}, http.StatusBadRequest},
+		"clusters and group": {savedViewInput{Name: "x", Query: models.SavedViewQuery{
+			Version: "v1", Resource: "pods", Clusters: []string{"c1"}, ClusterGroup: "prod",
+		}}, http.StatusBadRequest},
+		"duplicate name": {savedViewInput{Name: "x", Query: models.SavedViewQuery{Version: "v1", Resource: "pods"}}, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		body, _ := json.Marshal(tc.input)
+		req, err := http.NewRequest(http.MethodPost, "/api/views", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := env.App.Test(req, 5000)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, resp.StatusCode, name)
+	}
+}
+
+func TestSavedViews_GetOtherProjectIsNotFound(t *testing.T) {
+	env, mockStore, _ := setupSavedViewTest(t)
+	id := uuid.New()
+	mockStore.On("GetSavedView", id).Return(&models.SavedView{ID: id, Project: "istio", Name: "x"}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/views/"+id.String(), nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSavedViews_ResultsFromClusterGroup(t *testing.T) {
+	env, mockStore, _ := setupSavedViewTest(t)
+	widgets := seedWidgets(t, env)
+	// Drop the permissive cluster-group defaults from setupTestEnv so the
+	// "prod" group below is what the handler resolves.
+	mockStore.ExpectedCalls = nil
+
+	id := uuid.New()
+	mockStore.On("GetSavedView", id).Return(&models.SavedView{
+		ID:      id,
+		Project: testSavedViewProject,
+		Name:    "GPU widgets",
+		Query: models.SavedViewQuery{
+			Group:         widgets.Group,
+			Version:       widgets.Version,
+			Resource:      widgets.Resource,
+			Namespace:     "default",
+			ClusterGroup:  "prod",
+			LabelSelector: "gpu=true",
+			Columns:       []string{"name", "Missing"},
+		},
+	}, nil)
+	mockStore.On("ListClusterGroups").Return(map[string][]byte{
+		"prod": []byte(`{"name":"prod","kind":"static","clusters":["test-cluster","gone-cluster"]}`),
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/views/"+id.String()+"/results", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var results SavedViewResults
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	assert.False(t, results.Cached)
+	assert.Equal(t, []string{"name", "Missing"}, results.Columns)
+	assert.Equal(t, []string{"test-cluster", "gone-cluster"}, results.Clusters)
+	require.Len(t, results.Rows, 1)
+	assert.Equal(t, "test-cluster", results.Rows[0].Cluster)
+	assert.Equal(t, "w1", results.Rows[0].Name)
+	require.Len(t, results.Rows[0].Cells, 2)
+	assert.Equal(t, "w1", results.Rows[0].Cells[0])
+	assert.Nil(t, results.Rows[0].Cells[1])
+	assert.Contains(t, results.Errors, "gone-cluster")
+
+	// A second request within the TTL is served from cache.
+	req, err = http.NewRequest(http.MethodGet, "/api/views/"+id.String()+"/results", nil)
+	require.NoError(t, err)
+	resp, err = env.App.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	assert.True(t, results.Cached)
+}
+
+func TestSavedViews_ResultsUnknownGroup(t *testing.T) {
+	env, mockStore, _ := setupSavedViewTest(t)
+	id := uuid.New()
+	mockStore.On("GetSavedView", id).Return(&models.SavedView{
+		ID:      id,
+		Project: testSavedViewProject,
+		Query:   models.SavedViewQuery{Version: "v1", Resource: "pods", ClusterGroup: "nope"},
+	}, nil)
+	mockStore.On("ListClusterGroups").Return(map[string][]byte{}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/views/"+id.String()+"/results", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSavedViews_ResultsNoClient(t *testing.T) {
+	env := setupTestEnv(t)
+	handler := NewSavedViewHandler(env.Store, nil, testSavedViewProject)
+	env.App.Get("/api/views/:id/results", handler.GetResults)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/views/"+uuid.NewString()+"/results", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+const (
+	// maxSimulationClusters bounds the clusters one simulation fans out to.
+	maxSimulationClusters = 20
+	// maxSimulatedReplicas bounds the placement loop (replicas x nodes).
+	maxSimulatedReplicas = 1000
+	// simulationClusterTimeout bounds the snapshot read of one cluster.
+	simulationClusterTimeout = 15 * time.Second
+)
+
+// Reasons a node cannot take a simulated pod, worded like the scheduler's
+// FailedScheduling events.
+const (
+	simReasonNotReady      = "node(s) were not ready"
+	simReasonUnschedulable = "node(s) were unschedulable"
+	simReasonSelector      = "node(s) didn't match Pod's node affinity/selector"
+	simReasonTaint         = "node(s) had untolerated taint"
+	simReasonTooManyPods   = "Too many pods"
+	simReasonCPU           = "Insufficient cpu"
+	simReasonMemory        = "Insufficient memory"
+)
+
+// schedulingSnapshotter is the subset of k8s.MultiClusterClient the scale
+// simulator needs.
+type schedulingSnapshotter interface {
+	GetSchedulingSnapshot(ctx context.Context, contextName, kind, namespace, name string) (*k8s.SchedulingSnapshot, error)
+}
+
+// ScaleSimulationHandler answers what-if questions about scaling a workload:
+// would the proposed replicas fit on the nodes of each cluster, and where.
+type ScaleSimulationHandler struct {
+	k8sClient schedulingSnapshotter
+}
+
+// NewScaleSimulationHandler creates a scale simulation handler.
+func NewScaleSimulationHandler(k8sClient *k8s.MultiClusterClient) *ScaleSimulationHandler {
+	h := &ScaleSimulationHandler{}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// scaleSimulationRequest is the body accepted by POST /api/simulate/scale.
+type scaleSimulationRequest struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Clusters  []string `json:"clusters"`
+	// Replicas is the proposed replica count; nil keeps the current one.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Resources overrides the per-pod requests of the pod template.
+	Resources *scaleResourceOverride `json:"resources,omitempty"`
+}
+
+// scaleResourceOverride replaces the total requests of each pod. Unset
+// fields keep the template's value.
+type scaleResourceOverride struct {
+	CPU          string           `json:"cpu,omitempty"`
+	Memory       string           `json:"memory,omitempty"`
+	Accelerators map[string]int64 `json:"accelerators,omitempty"`
+}
+
+// ScaleNodeResult is the simulated state of one node after placement.
+type ScaleNodeResult struct {
+	Name     string `json:"name"`
+	Eligible bool   `json:"eligible"`
+	// Reason explains why an ineligible node cannot run the workload.
+	Reason      string              `json:"reason,omitempty"`
+	Placed      int                 `json:"placed"`
+	Allocatable k8s.ResourceAmounts `json:"allocatable"`
+	Requested   k8s.ResourceAmounts `json:"requested"`
+	Headroom    k8s.ResourceAmounts `json:"headroom"`
+}
+
+// ScaleClusterResult is the outcome of the simulation on one cluster.
+type ScaleClusterResult struct {
+	Cluster          string              `json:"cluster"`
+	Error            string              `json:"error,omitempty"`
+	CurrentReplicas  int32               `json:"currentReplicas"`
+	ProposedReplicas int32               `json:"proposedReplicas"`
+	PodRequests      k8s.ResourceAmounts `json:"podRequests"`
+	Schedulable      bool                `json:"schedulable"`
+	Scheduled        int                 `json:"scheduled"`
+	Unscheduled      int                 `json:"unscheduled"`
+	// UnschedulableReasons counts nodes by why they rejected the first pod
+	// that did not fit, like a FailedScheduling event.
+	UnschedulableReasons map[string]int    `json:"unschedulableReasons,omitempty"`
+	Nodes                []ScaleNodeResult `json:"nodes"`
+}
+
+// ScaleSimulationResponse is the response of POST /api/simulate/scale.
+type ScaleSimulationResponse struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Schedulable is set when every cluster can run all proposed replicas.
+	Schedulable bool                 `json:"schedulable"`
+	Results     []ScaleClusterResult `json:"results"`
+}
+
+func validateScaleSimulation(req *scaleSimulationRequest) error {
+	if req.Kind == "" {
+		req.Kind = k8s.WorkloadKindDeployment
+	}
+	if err := validateEnum("kind", req.Kind, []string{k8s.WorkloadKindDeployment, k8s.WorkloadKindStatefulSet}); err != nil {
+		return err
+	}
+	if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+		return err
+	}
+	if err := validateDNSSubdomain("name", req.Name); err != nil {
+		return err
+	}
+	if len(req.Clusters) == 0 {
+		return errors.New("clusters is required")
+	}
+	if len(req.Clusters) > maxSimulationClusters {
+		return fmt.Errorf("at most %d clusters can be simulated at once", maxSimulationClusters)
+	}
+	seen := make(map[string]bool, len(req.Clusters))
+	for _, c := range req.Clusters {
+		if err := validateClusterName("clusters", c); err != nil {
+			return err
+		}
+		if seen[c] {
+			return fmt.Errorf("cluster %q is listed twice", c)
+		}
+		seen[c] = true
+	}
+	if req.Replicas != nil && (*req.Replicas < 0 || *req.Replicas > maxSimulatedReplicas) {
+		return fmt.Errorf("replicas must be between 0 and %d", maxSimulatedReplicas)
+	}
+	if o := req.Resources; o != nil {
+		for field, raw := range map[string]string{"resources.cpu": o.CPU, "resources.memory": o.Memory} {
+			if raw == "" {
+				continue
+			}
+			q, err := resource.ParseQuantity(raw)
+			if err != nil || q.Sign() < 0 {
+				return fmt.Errorf("%s must be a non-negative Kubernetes quantity", field)
+			}
+		}
+		for name, n := range o.Accelerators {
+			if !k8s.IsGPUResourceName(corev1.ResourceName(name)) {
+				return fmt.Errorf("resources.accelerators: unknown accelerator %q", name)
+			}
+			if n < 0 {
+				return errors.New("resources.accelerators must not be negative")
+			}
+		}
+	}
+	return nil
+}
+
+// SimulateScale places the proposed replicas of a workload on the nodes of
+// each target cluster, as the scheduler would, without changing anything.
+// POST /api/simulate/scale
+func (h *ScaleSimulationHandler) SimulateScale(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	var req scaleSimulationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateScaleSimulation(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.UserContext()
+	results := make([]ScaleClusterResult, len(req.Clusters))
+	var wg sync.WaitGroup
+	for i, cluster := range req.Clusters {
+		wg.Add(1)
+		safego.GoWith("simulate-scale/"+cluster, func() {
+			defer wg.Done()
+			results[i] = h.simulateCluster(ctx, cluster, &req)
+		})
+	}
+	wg.Wait()
+
+	resp := ScaleSimulationResponse{Kind: req.Kind, Namespace: req.Namespace, Name: req.Name, Schedulable: true, Results: results}
+	for _, r := range results {
+		if !r.Schedulable {
+			resp.Schedulable = false
+		}
+	}
+	return c.JSON(resp)
+}
+
+func (h *ScaleSimulationHandler) simulateCluster(ctx context.Context, cluster string, req *scaleSimulationRequest) ScaleClusterResult {
+	ctx, cancel := context.WithTimeout(ctx, simulationClusterTimeout)
+	defer cancel()
+
+	res := ScaleClusterResult{Cluster: cluster, Nodes: []ScaleNodeResult{}}
+	snap, err := h.k8sClient.GetSchedulingSnapshot(ctx, cluster, req.Kind, req.Namespace, req.Name)
+	if err != nil {
+		res.Error = simulationError(err)
+		slog.Info("[SimulateScale] snapshot failed", "cluster", cluster, "workload", req.Namespace+"/"+req.Name, "error", err)
+		return res
+	}
+
+	res.CurrentReplicas = snap.Replicas
+	res.ProposedReplicas = snap.Replicas
+	if req.Replicas != nil {
+		res.ProposedReplicas = *req.Replicas
+	}
+	res.PodRequests = k8s.PodRequests(&snap.Template)
+	if req.Resources != nil {
+		res.PodRequests = applyResourceOverride(res.PodRequests, req.Resources)
+	}
+	simulatePlacement(&res, snap)
+	return res
+}
+
+// simulationError turns a snapshot error into a message safe to return.
+func simulationError(err error) string {
+	if apierrors.IsNotFound(err) {
+		return "workload not found"
+	}
+	if msg, ok := SanitizedErrorMessages[k8s.ClassifyError(err.Error())]; ok {
+		return msg
+	}
+	return "failed to read cluster state"
+}
+
+func applyResourceOverride(r k8s.ResourceAmounts, o *scaleResourceOverride) k8s.ResourceAmounts {
+	if o.CPU != "" {
+		q := resource.MustParse(o.CPU)
+		r.CPUMillis = q.MilliValue()
+	}
+	if o.Memory != "" {
+		q := resource.MustParse(o.Memory)
+		r.MemoryBytes = q.Value()
+	}
+	if len(o.Accelerators) > 0 {
+		r.Accelerators = make(map[string]int64, len(o.Accelerators))
+		for name, n := range o.Accelerators {
+			if n > 0 {
+				r.Accelerators[name] = n
+			}
+		}
+	}
+	return r
+}
+
+// simulatePlacement binds res.ProposedReplicas pods one at a time, each to
+// the feasible node that keeps the most CPU and memory free, which mirrors
+// the scheduler's default least-allocated scoring. Pod anti-affinity and
+// topology spread constraints are not modelled.
+func simulatePlacement(res *ScaleClusterResult, snap *k8s.SchedulingSnapshot) {
+	nodes := make([]ScaleNodeResult, len(snap.Nodes))
+	for i, n := range snap.Nodes {
+		nodes[i] = ScaleNodeResult{
+			Name:        n.Name,
+			Allocatable: n.Allocatable,
+			Requested:   n.Requested,
+		}
+		nodes[i].Reason = staticIneligibility(&n, &snap.Template)
+		nodes[i].Eligible = nodes[i].Reason == ""
+	}
+
+	for placed := 0; placed < int(res.ProposedReplicas); placed++ {
+		best, bestScore := -1, 0.0
+		for i := range nodes {
+			if !nodes[i].Eligible || fitFailure(&nodes[i], res.PodRequests) != "" {
+				continue
+			}
+			if s := leastAllocatedScore(&nodes[i], res.PodRequests); best < 0 || s > bestScore {
+				best, bestScore = i, s
+			}
+		}
+		if best < 0 {
+			res.Unscheduled = int(res.ProposedReplicas) - placed
+			res.UnschedulableReasons = make(map[string]int)
+			for i := range nodes {
+				reason := nodes[i].Reason
+				if nodes[i].Eligible {
+					reason = fitFailure(&nodes[i], res.PodRequests)
+				} else {
+					reason, _, _ = strings.Cut(reason, ": ")
+				}
+				res.UnschedulableReasons[reason]++
+			}
+			break
+		}
+		nodes[best].Requested.Add(res.PodRequests)
+		nodes[best].Placed++
+		res.Scheduled++
+	}
+	res.Schedulable = res.Unscheduled == 0
+
+	for i := range nodes {
+		nodes[i].Headroom = nodes[i].Allocatable.Sub(nodes[i].Requested)
+	}
+	sort.SliceStable(nodes, func(a, b int) bool { return nodes[a].Name < nodes[b].Name })
+	res.Nodes = nodes
+}
+
+// staticIneligibility returns why a node can never run the pod regardless of
+// free capacity, or "" when it can.
+func staticIneligibility(n *k8s.NodeCapacity, spec *corev1.PodSpec) string {
+	switch {
+	case !n.Ready:
+		return simReasonNotReady
+	case n.Unschedulable:
+		return simReasonUnschedulable
+	case !matchesNodeSelector(n, spec):
+		return simReasonSelector
+	}
+	for i := range n.Taints {
+		t := &n.Taints[i]
+		if t.Effect != corev1.TaintEffectNoSchedule && t.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if !toleratesTaint(spec.Tolerations, t) {
+			return fmt.Sprintf("%s: %s=%s:%s", simReasonTaint, t.Key, t.Value, t.Effect)
+		}
+	}
+	return ""
+}
+
+// fitFailure returns the first resource the node lacks for the pod, or "".
+func fitFailure(n *ScaleNodeResult, pod k8s.ResourceAmounts) string {
+	free := n.Allocatable.Sub(n.Requested)
+	// Nodes that do not report a pod limit are not limited by it.
+	if n.Allocatable.Pods > 0 && free.Pods < pod.Pods {
+		return simReasonTooManyPods
+	}
+	if free.CPUMillis < pod.CPUMillis {
+		return simReasonCPU
+	}
+	if free.MemoryBytes < pod.MemoryBytes {
+		return simReasonMemory
+	}
+	for _, name := range slices.Sorted(maps.Keys(pod.Accelerators)) {
+		if free.Accelerators[name] < pod.Accelerators[name] {
+			return "Insufficient " + name
+		}
+	}
+	return ""
+}
+
+// leastAllocatedScore is the mean share of CPU and memory left free on the
+// node after placing pod.
+func leastAllocatedScore(n *ScaleNodeResult, pod k8s.ResourceAmounts) float64 {
+	freeShare := func(alloc, used int64) float64 {
+		if alloc <= 0 {
+			return 0
+		}
+		return float64(alloc-used) / float64(alloc)
+	}
+	cpu := freeShare(n.Allocatable.CPUMillis, n.Requested.CPUMillis+pod.CPUMillis)
+	mem := freeShare(n.Allocatable.MemoryBytes, n.Requested.MemoryBytes+pod.MemoryBytes)
+	return (cpu + mem) / 2
+}
+
+// matchesNodeSelector checks spec.nodeSelector and the required node
+// affinity terms against the node's labels and name.
+func matchesNodeSelector(n *k8s.NodeCapacity, spec *corev1.PodSpec) bool {
+	nodeLabels := labels.Set(n.Labels)
+	for k, v := range spec.NodeSelector {
+		if got, ok := nodeLabels[k]; !ok || got != v {
+			return false
+		}
+	}
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil ||
+		spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	terms := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	fields := labels.Set{"metadata.name": n.Name}
+	for _, term := range terms {
+		if matchesRequirements(term.MatchExpressions, nodeLabels) && matchesRequirements(term.MatchFields, fields) &&
+			(len(term.MatchExpressions) > 0 || len(term.MatchFields) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func matchesRequirements(reqs []corev1.NodeSelectorRequirement, set labels.Set) bool {
+	for _, r := range reqs {
+		op, ok := nodeSelectorOperators[r.Operator]
+		if !ok {
+			return false
+		}
+		req, err := labels.NewRequirement(r.Key, op, r.Values)
+		if err != nil || !req.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+// toleratesTaint reports whether any toleration matches the taint, using the
+// same key, operator, value and effect rules as the scheduler.
+func toleratesTaint(tolerations []corev1.Toleration, t *corev1.Taint) bool {
+	for _, tol := range tolerations {
+		if tol.Effect != "" && tol.Effect != t.Effect {
+			continue
+		}
+		if tol.Key != "" && tol.Key != t.Key {
+			continue
+		}
+		switch tol.Operator {
+		case corev1.TolerationOpExists:
+			return true
+		case "", corev1.TolerationOpEqual:
+			if tol.Key != "" && tol.Value == t.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
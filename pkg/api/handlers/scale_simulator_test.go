@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+const gib = 1024 * 1024 * 1024
+
+type fakeSnapshotter map[string]*k8s.SchedulingSnapshot
+
+func (f fakeSnapshotter) GetSchedulingSnapshot(_ context.Context, cluster, kind, namespace, name string) (*k8s.SchedulingSnapshot, error) {
+	if cluster == "broken" {
+		return nil, errors.New("dial tcp 10.0.0.1:6443: connect: connection refused")
+	}
+	snap, ok := f[cluster]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, name)
+	}
+	return snap, nil
+}
+
+func simNode(name string, cpuMillis, memGiB int64) k8s.NodeCapacity {
+	return k8s.NodeCapacity{
+		Name:        name,
+		Ready:       true,
+		Allocatable: k8s.ResourceAmounts{CPUMillis: cpuMillis, MemoryBytes: memGiB * gib, Pods: 110},
+	}
+}
+
+func simSnapshot(replicas int32, nodes ...k8s.NodeCapacity) *k8s.SchedulingSnapshot {
+	return &k8s.SchedulingSnapshot{Kind: k8s.WorkloadKindDeployment, Namespace: "shop", Name: "api", Replicas: replicas, Nodes: nodes}
+}
+
+func postSimulation(t *testing.T, app *fiber.App, body any) (int, ScaleSimulationResponse) {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/simulate/scale", bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	var out ScaleSimulationResponse
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out
+}
+
+func TestSimulatePlacement_SpreadsAndReportsHeadroom(t *testing.T) {
+	busy := simNode("busy", 4000, 8)
+	busy.Requested = k8s.ResourceAmounts{CPUMillis: 2000, MemoryBytes: gib, Pods: 5}
+	snap := simSnapshot(1, simNode("idle", 4000, 8), busy)
+
+	res := ScaleClusterResult{ProposedReplicas: 4, PodRequests: k8s.ResourceAmounts{CPUMillis: 500, MemoryBytes: gib, Pods: 1}}
+	simulatePlacement(&res, snap)
+
+	assert.True(t, res.Schedulable)
+	assert.Equal(t, 4, res.Scheduled)
+	require.Len(t, res.Nodes, 2)
+	assert.Equal(t, "busy", res.Nodes[0].Name, "nodes are sorted by name")
+	assert.Equal(t, 1, res.Nodes[0].Placed, "least-allocated scoring fills the idle node first")
+	assert.Equal(t, 3, res.Nodes[1].Placed)
+	assert.Equal(t, int64(2500), res.Nodes[1].Headroom.CPUMillis)
+	assert.Equal(t, int64(5*gib), res.Nodes[1].Headroom.MemoryBytes)
+}
+
+func TestSimulatePlacement_Unschedulable(t *testing.T) {
+	tainted := simNode("gpu", 16000, 64)
+	tainted.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	cordoned := simNode("cordoned", 16000, 64)
+	cordoned.Unschedulable = true
+	snap := simSnapshot(1, simNode("small", 1000, 4), tainted, cordoned)
+
+	res := ScaleClusterResult{ProposedReplicas: 3, PodRequests: k8s.ResourceAmounts{CPUMillis: 400, MemoryBytes: gib, Pods: 1}}
+	simulatePlacement(&res, snap)
+
+	assert.False(t, res.Schedulable)
+	assert.Equal(t, 2, res.Scheduled)
+	assert.Equal(t, 1, res.Unscheduled)
+	assert.Equal(t, map[string]int{
+		simReasonCPU:           1,
+		simReasonTaint:         1,
+		simReasonUnschedulable: 1,
+	}, res.UnschedulableReasons)
+	assert.Equal(t, simReasonTaint+": dedicated=gpu:NoSchedule", res.Nodes[1].Reason)
+	assert.False(t, res.Nodes[1].Eligible)
+}
+
+func TestSimulatePlacement_SelectorsTolerationsAndAccelerators(t *testing.T) {
+	gpuNode := simNode("gpu", 16000, 64)
+	gpuNode.Labels = map[string]string{"accelerator": "a100", "gpus": "8"}
+	gpuNode.Taints = []corev1.Taint{{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}}
+	gpuNode.Allocatable.Accelerators = map[string]int64{"nvidia.com/gpu": 2}
+	cpuNode := simNode("cpu", 16000, 64)
+	snap := simSnapshot(1, gpuNode, cpuNode)
+	snap.Template = corev1.PodSpec{
+		NodeSelector: map[string]string{"accelerator": "a100"},
+		Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpus", Operator: corev1.NodeSelectorOpGt, Values: []string{"4"}}},
+			}}},
+		}},
+	}
+
+	res := ScaleClusterResult{ProposedReplicas: 3, PodRequests: k8s.ResourceAmounts{
+		CPUMillis: 1000, MemoryBytes: gib, Pods: 1, Accelerators: map[string]int64{"nvidia.com/gpu": 1}}}
+	simulatePlacement(&res, snap)
+
+	assert.Equal(t, 2, res.Scheduled, "only two GPUs are free")
+	assert.Equal(t, map[string]int{"Insufficient nvidia.com/gpu": 1, simReasonSelector: 1}, res.UnschedulableReasons)
+	assert.Equal(t, int64(0), res.Nodes[1].Headroom.Accelerators["nvidia.com/gpu"])
+}
+
+func TestToleratesTaint(t *testing.T) {
+	taint := &corev1.Taint{Key: "team", Value: "ml", Effect: corev1.TaintEffectNoSchedule}
+	cases := []struct {
+		name string
+		tol  corev1.Toleration
+		want bool
+	}{
+		{"exists any", corev1.Toleration{Operator: corev1.TolerationOpExists}, true},
+		{"equal match", corev1.Toleration{Key: "team", Value: "ml"}, true},
+		{"equal other value", corev1.Toleration{Key: "team", Value: "web"}, false},
+		{"other effect", corev1.Toleration{Key: "team", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, toleratesTaint([]corev1.Toleration{tc.tol}, taint), tc.name)
+	}
+}
+
+func TestSimulateScale_Handler(t *testing.T) {
+	env := setupTestEnv(t)
+	snap := simSnapshot(2, simNode("n1", 2000, 8))
+	snap.Template = corev1.PodSpec{}
+	h := &ScaleSimulationHandler{k8sClient: fakeSnapshotter{"prod": snap}}
+	env.App.Post("/api/simulate/scale", h.SimulateScale)
+
+	status, out := postSimulation(t, env.App, map[string]any{
+		"namespace": "shop", "name": "api", "clusters": []string{"prod", "missing", "broken"},
+		"replicas": 5, "resources": map[string]any{"cpu": "500m", "memory": "1Gi"},
+	})
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, k8s.WorkloadKindDeployment, out.Kind)
+	assert.False(t, out.Schedulable)
+	require.Len(t, out.Results, 3)
+
+	prod := out.Results[0]
+	assert.Equal(t, int32(2), prod.CurrentReplicas)
+	assert.Equal(t, int32(5), prod.ProposedReplicas)
+	assert.Equal(t, int64(500), prod.PodRequests.CPUMillis)
+	assert.Equal(t, 4, prod.Scheduled)
+	assert.Equal(t, 1, prod.UnschedulableReasons[simReasonCPU])
+
+	assert.Equal(t, "workload not found", out.Results[1].Error)
+	assert.Equal(t, SanitizedErrorMessages["network"], out.Results[2].Error)
+}
+
+func TestSimulateScale_Validation(t *testing.T) {
+	env := setupTestEnv(t)
+	h := &ScaleSimulationHandler{k8sClient: fakeSnapshotter{}}
+	env.App.Post("/api/simulate/scale", h.SimulateScale)
+
+	base := func() map[string]any {
+		return map[string]any{"namespace": "shop", "name": "api", "clusters": []string{"prod"}}
+	}
+	mutate := []func(m map[string]any){
+		func(m map[string]any) { m["kind"] = "DaemonSet" },
+		func(m map[string]any) { m["namespace"] = "Bad_NS" },
+		func(m map[string]any) { m["clusters"] = []string{} },
+		func(m map[string]any) { m["clusters"] = []string{"a", "a"} },
+		func(m map[string]any) { m["replicas"] = maxSimulatedReplicas + 1 },
+		func(m map[string]any) { m["resources"] = map[string]any{"cpu": "lots"} },
+		func(m map[string]any) {
+			m["resources"] = map[string]any{"accelerators": map[string]int{"example.com/fpga": 1}}
+		},
+	}
+	for i, fn := range mutate {
+		body := base()
+		fn(body)
+		status, _ := postSimulation(t, env.App, body)
+		assert.Equal(t, http.StatusBadRequest, status, "case %d", i)
+	}
+}
+
+func TestSimulateScale_NoClusterAccess(t *testing.T) {
+	env := setupTestEnv(t)
+	h := NewScaleSimulationHandler(nil)
+	env.App.Post("/api/simulate/scale", h.SimulateScale)
+
+	status, _ := postSimulation(t, env.App, map[string]any{"namespace": "shop", "name": "api", "clusters": []string{"prod"}})
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
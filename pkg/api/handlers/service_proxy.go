@@ -0,0 +1,461 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// defaultServiceProxyTTL is how long a proxy session works when the
+	// request does not say.
+	defaultServiceProxyTTL = time.Hour
+	// maxServiceProxyTTL bounds the requested session lifetime.
+	maxServiceProxyTTL = 8 * time.Hour
+	// serviceProxyTimeout bounds one proxied request, including reading the
+	// response body.
+	serviceProxyTimeout = 60 * time.Second
+	// maxServiceProxyRewriteBytes bounds HTML responses, which are buffered
+	// to rewrite links. Other responses are streamed.
+	maxServiceProxyRewriteBytes = 8 * 1024 * 1024
+
+	// serviceProxyKeyContext separates the proxy signing key from other uses
+	// of the server secret.
+	serviceProxyKeyContext = "kubestellar-console/service-proxy"
+	// PublicServiceProxyPath is where signed proxy sessions are served.
+	PublicServiceProxyPath = "/api/public/service-proxy/"
+
+	// serviceProxyCSP sandboxes proxied pages into an opaque origin so their
+	// scripts cannot call the console API with the user's session.
+	serviceProxyCSP = "sandbox allow-scripts allow-forms allow-popups allow-downloads"
+)
+
+// serviceProxyRequestHeaders are the request headers forwarded upstream.
+// Cookies and Authorization are never forwarded.
+var serviceProxyRequestHeaders = []string{
+	"Accept", "Accept-Language", "Cache-Control", "Content-Type",
+	"If-Modified-Since", "If-None-Match", "Range", "User-Agent",
+}
+
+// serviceProxyDroppedResponseHeaders are upstream response headers that are
+// not passed back. Cookies would be scoped to the console origin, and the
+// console sets its own security headers.
+var serviceProxyDroppedResponseHeaders = map[string]bool{
+	"Set-Cookie":                       true,
+	"Connection":                       true,
+	"Keep-Alive":                       true,
+	"Transfer-Encoding":                true,
+	"Content-Length":                   true,
+	"Content-Security-Policy":          true,
+	"Strict-Transport-Security":        true,
+	"Access-Control-Allow-Origin":      true,
+	"Access-Control-Allow-Credentials": true,
+}
+
+// serviceProxyPortPattern matches a port number or an IANA service name.
+var serviceProxyPortPattern = regexp.MustCompile(`^([0-9]{1,5}|[a-z0-9]([a-z0-9-]{0,13}[a-z0-9])?)$`)
+
+// serviceProxyLinkPattern matches root-relative links in HTML attributes.
+var serviceProxyLinkPattern = regexp.MustCompile(`(?i)((?:href|src|action)\s*=\s*["'])(/[^/"'][^"']*|/)(["'])`)
+
+// serviceProxyClient is the subset of k8s.MultiClusterClient the service
+// and API server proxies need.
+type serviceProxyClient interface {
+	GetRestConfig(contextName string) (*rest.Config, error)
+}
+
+// serviceProxyTarget is a service or pod port a proxy session tunnels to.
+// It is carried, signed, in the session token.
+type serviceProxyTarget struct {
+	Cluster   string `json:"c"`
+	Namespace string `json:"n"`
+	// Resource is "services" or "pods".
+	Resource string `json:"r"`
+	Name     string `json:"s"`
+	Port     string `json:"p"`
+	// Scheme is "https" for TLS backends; empty means http.
+	Scheme  string `json:"x,omitempty"`
+	User    string `json:"u"`
+	Expires int64  `json:"e"`
+}
+
+// upstreamPrefix is the API server proxy path of the target, with a trailing
+// slash.
+func (t serviceProxyTarget) upstreamPrefix() string {
+	name := t.Name + ":" + t.Port
+	if t.Scheme != "" {
+		name = t.Scheme + ":" + name
+	}
+	return fmt.Sprintf("/api/v1/namespaces/%s/%s/%s/proxy/", t.Namespace, t.Resource, name)
+}
+
+// serviceProxyRequest is the body accepted by CreateSession.
+type serviceProxyRequest struct {
+	Namespace string `json:"namespace"`
+	// Kind is "service" or "pod".
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Port   string `json:"port"`
+	Scheme string `json:"scheme,omitempty"`
+	// TTLSeconds is how long the session works; zero uses the default.
+	TTLSeconds int `json:"ttlSeconds,omitempty"`
+}
+
+// ServiceProxySession is returned when a proxy session is opened.
+type ServiceProxySession struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ServiceProxyHandler tunnels HTTP to a service or pod port on a managed
+// cluster through the API server proxy subresource, so in-cluster UIs can
+// be opened from the console. Editors and admins open a session and get a
+// signed URL; requests under it are proxied without a console cookie until
+// the session expires. Responses are sandboxed with a CSP so proxied pages
+// cannot act as the user.
+type ServiceProxyHandler struct {
+	k8sClient serviceProxyClient
+	store     store.Store
+	key       []byte
+	now       func() time.Time
+	clients   proxyHTTPClients
+}
+
+// NewServiceProxyHandler creates a service proxy handler that signs session
+// URLs with a key derived from secret. With an empty secret no sessions are
+// issued.
+func NewServiceProxyHandler(k8sClient *k8s.MultiClusterClient, s store.Store, secret string) *ServiceProxyHandler {
+	h := &ServiceProxyHandler{store: s, now: time.Now}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(serviceProxyKeyContext))
+		h.key = mac.Sum(nil)
+	}
+	return h
+}
+
+// sign returns the hex HMAC of an encoded session payload.
+func (h *ServiceProxyHandler) sign(payload string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// token encodes and signs a session target.
+func (h *ServiceProxyHandler) token(t serviceProxyTarget) (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + h.sign(payload), nil
+}
+
+// parseToken verifies a session token and returns its target.
+func (h *ServiceProxyHandler) parseToken(token string) (serviceProxyTarget, bool) {
+	var t serviceProxyTarget
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return t, false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return t, false
+	}
+	want, _ := hex.DecodeString(h.sign(payload))
+	if !hmac.Equal(got, want) {
+		return t, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(data, &t) != nil {
+		return t, false
+	}
+	return t, true
+}
+
+// CreateSession opens a proxy session to a service or pod port and returns
+// the URL to load it from.
+// POST /api/clusters/:cluster/service-proxy
+func (h *ServiceProxyHandler) CreateSession(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if len(h.key) == 0 {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "Service proxy is not configured")
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	var req serviceProxyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if err := validateEnum("kind", req.Kind, []string{"service", "pod"}); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err := validateDNSLabel("namespace", req.Namespace); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if req.Name == "" || !IsValidK8sName(req.Name) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name must be a valid Kubernetes resource name"})
+	}
+	if !serviceProxyPortPattern.MatchString(req.Port) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "port must be a port number or name"})
+	}
+	if n, err := strconv.Atoi(req.Port); err == nil && (n < 1 || n > 65535) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "port must be between 1 and 65535"})
+	}
+	if req.Scheme != "" {
+		if err := validateEnum("scheme", req.Scheme, []string{"http", "https"}); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+	ttl := defaultServiceProxyTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+		if ttl < time.Minute || ttl > maxServiceProxyTTL {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("ttlSeconds must be between 60 and %d", int(maxServiceProxyTTL.Seconds())),
+			})
+		}
+	}
+
+	target := serviceProxyTarget{
+		Cluster:   cluster,
+		Namespace: req.Namespace,
+		Resource:  req.Kind + "s",
+		Name:      req.Name,
+		Port:      req.Port,
+		User:      middleware.GetUserID(c).String(),
+		Expires:   h.now().Add(ttl).Unix(),
+	}
+	if req.Scheme == "https" {
+		target.Scheme = "https"
+	}
+	token, err := h.token(target)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to open proxy session"})
+	}
+	audit.Log(c, audit.ActionOpenServiceProxy, req.Kind, cluster+"/"+req.Namespace+"/"+req.Name,
+		fmt.Sprintf("port=%s ttl=%s", req.Port, ttl))
+	return c.Status(fiber.StatusCreated).JSON(ServiceProxySession{
+		URL:       PublicServiceProxyPath + token + "/",
+		ExpiresAt: time.Unix(target.Expires, 0).UTC(),
+	})
+}
+
+// Proxy forwards a request under a signed session URL to the target's API
+// server proxy path. Redirects and root-relative links in HTML are rewritten
+// to stay under the session URL. Only GET, HEAD and POST are forwarded.
+// ANY /api/public/service-proxy/:token/*
+func (h *ServiceProxyHandler) Proxy(c *fiber.Ctx) error {
+	notFound := fiber.NewError(fiber.StatusNotFound, "Proxy session not found")
+	if len(h.key) == 0 || h.k8sClient == nil {
+		return notFound
+	}
+	target, ok := h.parseToken(c.Params("token"))
+	if !ok {
+		return notFound
+	}
+	if h.now().Unix() >= target.Expires {
+		return fiber.NewError(fiber.StatusGone, "Proxy session has expired")
+	}
+	method := c.Method()
+	if method != fiber.MethodGet && method != fiber.MethodHead && method != fiber.MethodPost {
+		return fiber.NewError(fiber.StatusMethodNotAllowed, "Method not allowed")
+	}
+	rest, err := url.PathUnescape(c.Params("*"))
+	if err != nil || !safeProxyPath(rest) {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid proxy path")
+	}
+
+	config, err := h.k8sClient.GetRestConfig(target.Cluster)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	client, err := h.clients.get(target.Cluster, config)
+	if err != nil {
+		slog.Error("[ServiceProxy] failed to build transport", "cluster", target.Cluster, "error", err)
+		return fiber.NewError(fiber.StatusBadGateway, "Failed to reach cluster")
+	}
+
+	upstream := target.upstreamPrefix()
+	u, err := url.Parse(strings.TrimSuffix(config.Host, "/"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadGateway, "Failed to reach cluster")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + upstream + rest
+	u.RawQuery = string(c.Request().URI().QueryString())
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), serviceProxyTimeout)
+	var body io.Reader
+	if method == fiber.MethodPost {
+		body = strings.NewReader(string(c.Body()))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		cancel()
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid proxy request")
+	}
+	for _, name := range serviceProxyRequestHeaders {
+		if v := c.Get(name); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+	consolePrefix := PublicServiceProxyPath + c.Params("token") + "/"
+	req.Header.Set("X-Forwarded-Prefix", strings.TrimSuffix(consolePrefix, "/"))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		slog.Info("[ServiceProxy] upstream request failed", "cluster", target.Cluster, "target", target.Name, "error", err)
+		return fiber.NewError(fiber.StatusBadGateway, "Upstream request failed")
+	}
+
+	for name, values := range resp.Header {
+		if serviceProxyDroppedResponseHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		for _, v := range values {
+			c.Response().Header.Add(name, v)
+		}
+	}
+	if loc := resp.Header.Get("Location"); loc != "" {
+		c.Set(fiber.HeaderLocation, rewriteProxyLink(loc, config.Host, upstream, consolePrefix))
+	}
+	c.Set(fiber.HeaderContentSecurityPolicy, serviceProxyCSP)
+	// Sandboxed pages have an opaque origin, so their own fetches back to
+	// the session URL are cross-origin. The URL is the credential, so no
+	// cookies are involved.
+	c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+	c.Set(fiber.HeaderReferrerPolicy, "no-referrer")
+	c.Set("X-Robots-Tag", "noindex")
+	c.Status(resp.StatusCode)
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") && resp.Header.Get("Content-Encoding") == "" {
+		defer cancel()
+		defer resp.Body.Close()
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxServiceProxyRewriteBytes+1))
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadGateway, "Upstream request failed")
+		}
+		if len(data) > maxServiceProxyRewriteBytes {
+			return fiber.NewError(fiber.StatusBadGateway, "Upstream page is too large")
+		}
+		return c.Send([]byte(rewriteProxyHTML(string(data), config.Host, upstream, consolePrefix)))
+	}
+	// The body is streamed after the handler returns, so the timeout is
+	// released when the stream is closed rather than here.
+	c.Response().SetBodyStream(&cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, -1)
+	return nil
+}
+
+// proxyHTTPClients caches HTTP clients for cluster API servers so each
+// proxied request does not repeat the TLS handshake. The zero value is ready
+// to use.
+type proxyHTTPClients struct {
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// get returns the cached client for a cluster's API server, building it from
+// config on first use.
+func (p *proxyHTTPClients) get(cluster string, config *rest.Config) (*http.Client, error) {
+	key := cluster + "|" + config.Host
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[key]; ok {
+		return c, nil
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+	// Redirects are passed to the caller, not followed.
+	c := &http.Client{
+		Transport:     transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	if p.clients == nil {
+		p.clients = make(map[string]*http.Client)
+	}
+	p.clients[key] = c
+	return c, nil
+}
+
+// safeProxyPath rejects paths with dot segments, which the API server would
+// resolve outside the target's proxy subresource.
+func safeProxyPath(p string) bool {
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "." || seg == ".." || strings.ContainsAny(seg, "\\\x00") {
+			return false
+		}
+	}
+	return true
+}
+
+// rewriteProxyLink maps a link from an upstream response onto the session
+// URL. Links to the API server proxy path of the target, absolute or not,
+// and other root-relative links are rewritten; everything else is returned
+// unchanged.
+func rewriteProxyLink(link, apiHost, upstreamPrefix, consolePrefix string) string {
+	link = strings.TrimPrefix(link, strings.TrimSuffix(apiHost, "/"))
+	switch {
+	case strings.HasPrefix(link, consolePrefix):
+		return link
+	case strings.HasPrefix(link, upstreamPrefix):
+		return consolePrefix + strings.TrimPrefix(link, upstreamPrefix)
+	case strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//"):
+		return consolePrefix + strings.TrimPrefix(link, "/")
+	}
+	return link
+}
+
+// rewriteProxyHTML rewrites root-relative href, src and action attributes.
+func rewriteProxyHTML(html, apiHost, upstreamPrefix, consolePrefix string) string {
+	return serviceProxyLinkPattern.ReplaceAllStringFunc(html, func(m string) string {
+		parts := serviceProxyLinkPattern.FindStringSubmatch(m)
+		return parts[1] + rewriteProxyLink(parts[2], apiHost, upstreamPrefix, consolePrefix) + parts[3]
+	})
+}
+
+// cancelOnClose releases a request context once a streamed body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
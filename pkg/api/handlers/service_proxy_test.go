@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type fakeRestConfigClient struct{ host string }
+
+func (f fakeRestConfigClient) GetRestConfig(string) (*rest.Config, error) {
+	return &rest.Config{Host: f.host}, nil
+}
+
+func setupServiceProxyTest(t *testing.T, upstream http.HandlerFunc) (*fiber.App, *ServiceProxyHandler) {
+	t.Helper()
+	apiServer := httptest.NewServer(upstream)
+	t.Cleanup(apiServer.Close)
+
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: models.UserRoleEditor}, nil).Maybe()
+
+	h := &ServiceProxyHandler{
+		k8sClient: fakeRestConfigClient{host: apiServer.URL},
+		store:     mockStore,
+		key:       []byte("test-key"),
+		now:       time.Now,
+	}
+	app := fiber.New()
+	app.All(PublicServiceProxyPath+":token/*", h.Proxy)
+	authed := app.Group("/api", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	authed.Post("/clusters/:cluster/service-proxy", h.CreateSession)
+	return app, h
+}
+
+func openServiceProxySession(t *testing.T, app *fiber.App, body string) (int, ServiceProxySession) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/clusters/prod/service-proxy", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	var session ServiceProxySession
+	if resp.StatusCode == http.StatusCreated {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&session))
+	}
+	return resp.StatusCode, session
+}
+
+func TestServiceProxy_ForwardsAndRewrites(t *testing.T) {
+	const prefix = "/api/v1/namespaces/llm/services/vllm:8000/proxy/"
+	var gotPath, gotQuery, gotCookie string
+	app, _ := setupServiceProxyTest(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotCookie = r.URL.Path, r.URL.RawQuery, r.Header.Get("Cookie")
+		switch r.URL.Path {
+		case prefix + "old":
+			http.Redirect(w, r, "/metrics", http.StatusFound)
+		case prefix + "metrics":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Set-Cookie", "upstream=1")
+			_, _ = io.WriteString(w, "vllm:num_requests_running 2\n")
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = io.WriteString(w, `<a href="/metrics">m</a><script src="`+prefix+`app.js"></script><a href="https://example.com/x">e</a><img src="//cdn.example.com/i.png">`)
+		}
+	})
+
+	status, session := openServiceProxySession(t, app, `{"namespace":"llm","kind":"service","name":"vllm","port":"8000"}`)
+	require.Equal(t, http.StatusCreated, status)
+	require.True(t, strings.HasPrefix(session.URL, PublicServiceProxyPath))
+
+	req := httptest.NewRequest(http.MethodGet, session.URL+"metrics?format=text", nil)
+	req.Header.Set("Cookie", "kc_auth=secret")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "vllm:num_requests_running 2\n", string(body))
+	assert.Equal(t, prefix+"metrics", gotPath)
+	assert.Equal(t, "format=text", gotQuery)
+	assert.Empty(t, gotCookie, "console cookies are never forwarded")
+	assert.Empty(t, resp.Header.Get("Set-Cookie"))
+	assert.Contains(t, resp.Header.Get("Content-Security-Policy"), "sandbox")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, session.URL+"old", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, session.URL+"metrics", resp.Header.Get("Location"))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, session.URL, nil), 5000)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `href="`+session.URL+`metrics"`)
+	assert.Contains(t, string(body), `src="`+session.URL+`app.js"`)
+	assert.Contains(t, string(body), `href="https://example.com/x"`)
+	assert.Contains(t, string(body), `src="//cdn.example.com/i.png"`)
+}
+
+func TestServiceProxy_Rejections(t *testing.T) {
+	app, h := setupServiceProxyTest(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected upstream request %s", r.URL.Path)
+	})
+
+	for _, body := range []string{
+		`{"namespace":"llm","kind":"deployment","name":"vllm","port":"8000"}`,
+		`{"namespace":"llm","kind":"service","name":"vllm","port":"70000"}`,
+		`{"namespace":"llm","kind":"service","name":"vllm","port":"8000","ttlSeconds":999999}`,
+		`{"namespace":"../kube-system","kind":"service","name":"vllm","port":"8000"}`,
+	} {
+		status, _ := openServiceProxySession(t, app, body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+	}
+
+	status, session := openServiceProxySession(t, app, `{"namespace":"llm","kind":"pod","name":"vllm-0","port":"8000"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, session.URL+"../../secrets", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, session.URL+"%2e%2e/%2e%2e/secrets", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, session.URL+"x", nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	tampered := strings.Replace(session.URL, PublicServiceProxyPath, PublicServiceProxyPath+"x", 1)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, tampered, nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	h.now = func() time.Time { return time.Now().Add(2 * maxServiceProxyTTL) }
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, session.URL, nil), 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+}
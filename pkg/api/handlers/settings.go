@@ -11,8 +11,9 @@ import (
 
 // SettingsHandler handles persistent settings API endpoints
 type SettingsHandler struct {
-	manager *settings.SettingsManager
-	store   store.Store
+	manager     *settings.SettingsManager
+	store       store.Store
+	persistence *store.PersistenceStore
 }
 
 // NewSettingsHandler creates a new settings handler
@@ -20,6 +21,12 @@ func NewSettingsHandler(manager *settings.SettingsManager, s store.Store) *Setti
 	return &SettingsHandler{manager: manager, store: s}
 }
 
+// SetPersistenceStore includes the persistence config in the effective
+// settings view.
+func (h *SettingsHandler) SetPersistenceStore(ps *store.PersistenceStore) {
+	h.persistence = ps
+}
+
 // requireAdmin verifies the current user has the admin role. It MUST be the
 // first call in every settings handler — no configuration, secrets, or
 // request body may be loaded until this check passes (#6000). Without this
@@ -146,3 +153,69 @@ func (h *SettingsHandler) ImportSettings(c *fiber.Ctx) error {
 		"message": "Settings imported",
 	})
 }
+
+// ValidateSettings checks a settings payload against the current schema
+// without saving it. The body may be a settings file (as produced by export)
+// or the plain settings object used by PUT /api/settings.
+// POST /api/settings/validate
+func (h *SettingsHandler) ValidateSettings(c *fiber.Ctx) error {
+	if err := h.RequireAdmin(c); err != nil {
+		return err
+	}
+
+	body := c.Body()
+	if len(body) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Empty request body",
+		})
+	}
+
+	result, err := settings.ValidateJSON(body)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid settings data",
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(result)
+}
+
+// effectiveSettingsResponse is returned by GET /api/settings/effective.
+type effectiveSettingsResponse struct {
+	Precedence  []settings.Source         `json:"precedence"`
+	Settings    []settings.EffectiveValue `json:"settings"`
+	Persistence []settings.EffectiveValue `json:"persistence,omitempty"`
+}
+
+// GetEffectiveSettings returns the resolved value of every setting and
+// whether it came from a flag, an environment variable, the settings file or
+// the built-in default. Secret values are masked.
+// GET /api/settings/effective
+func (h *SettingsHandler) GetEffectiveSettings(c *fiber.Ctx) error {
+	if err := h.RequireAdmin(c); err != nil {
+		return err
+	}
+
+	values, err := h.manager.Effective()
+	if err != nil {
+		slog.Error("[settings] failed to resolve effective settings", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load settings",
+		})
+	}
+	resp := effectiveSettingsResponse{
+		Precedence: []settings.Source{settings.SourceFlag, settings.SourceEnv, settings.SourceFile, settings.SourceDefault},
+		Settings:   values,
+	}
+	if h.persistence != nil {
+		resp.Persistence, err = settings.Describe(settings.SectionPersistence,
+			h.persistence.GetConfig(), store.DefaultPersistenceConfig())
+		if err != nil {
+			slog.Error("[settings] failed to resolve persistence settings", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load settings",
+			})
+		}
+	}
+	return c.JSON(resp)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/kubestellar/console/pkg/models"
 	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/store"
 	"github.com/kubestellar/console/pkg/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -165,6 +166,87 @@ func TestExportImportSettings(t *testing.T) {
 	assert.Equal(t, 400, respEmpty.StatusCode)
 }
 
+func TestValidateSettings(t *testing.T) {
+	env := setupTestEnv(t)
+	handler := NewSettingsHandler(env.Settings, env.Store)
+	env.App.Post("/api/settings/validate", handler.ValidateSettings)
+
+	post := func(body []byte) (*settings.ValidationResult, int) {
+		req := httptest.NewRequest("POST", "/api/settings/validate", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := env.App.Test(req, fiberTestTimeout)
+		require.NoError(t, err)
+		var result settings.ValidationResult
+		if resp.StatusCode == 200 {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		}
+		return &result, resp.StatusCode
+	}
+
+	valid, _ := json.Marshal(settings.DefaultAllSettings())
+	result, status := post(valid)
+	assert.Equal(t, 200, status)
+	assert.True(t, result.Valid)
+	assert.Equal(t, settings.CurrentSchemaVersion, result.CurrentVersion)
+
+	result, status = post([]byte(`{"aiMode":"turbo"}`))
+	assert.Equal(t, 200, status)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "aiMode", result.Issues[0].Field)
+
+	_, status = post([]byte("not-json"))
+	assert.Equal(t, 400, status)
+
+	_, status = post(nil)
+	assert.Equal(t, 400, status)
+}
+
+func TestGetEffectiveSettings(t *testing.T) {
+	env := setupTestEnv(t)
+	handler := NewSettingsHandler(env.Settings, env.Store)
+	ps := store.NewPersistenceStore("")
+	ps.SetConfigOverrides(func(c *store.PersistenceConfig) {
+		require.NoError(t, settings.ApplyOverrides(settings.SectionPersistence, c))
+	})
+	handler.SetPersistenceStore(ps)
+	env.App.Get("/api/settings/effective", handler.GetEffectiveSettings)
+
+	require.NoError(t, env.Settings.SaveAll(&settings.AllSettings{Theme: "nord"}))
+	t.Setenv("KC_AI_MODE", "high")
+	t.Setenv("OPENAI_API_KEY", "sk-openai-secret")
+	t.Setenv("KC_PERSISTENCE_NAMESPACE", "env-ns")
+
+	req := httptest.NewRequest("GET", "/api/settings/effective", nil)
+	resp, err := env.App.Test(req, fiberTestTimeout)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "sk-openai-secret", "secrets must be masked")
+
+	var result struct {
+		Precedence  []string                  `json:"precedence"`
+		Settings    []settings.EffectiveValue `json:"settings"`
+		Persistence []settings.EffectiveValue `json:"persistence"`
+	}
+	require.NoError(t, json.Unmarshal(body, &result))
+	assert.Equal(t, []string{"flag", "env", "file", "default"}, result.Precedence)
+
+	byKey := map[string]settings.EffectiveValue{}
+	for _, v := range append(result.Settings, result.Persistence...) {
+		byKey[v.Key] = v
+	}
+	assert.Equal(t, settings.EffectiveValue{Key: "aiMode", Value: "high", Source: settings.SourceEnv, Env: "KC_AI_MODE"}, byKey["aiMode"])
+	assert.Equal(t, settings.SourceFile, byKey["theme"].Source)
+	assert.Equal(t, "********", byKey["apiKeys.openai.apiKey"].Value)
+	assert.Equal(t, settings.SourceEnv, byKey["apiKeys.openai.apiKey"].Source)
+	assert.Equal(t, "env-ns", byKey["persistence.namespace"].Value)
+	assert.Equal(t, settings.SourceEnv, byKey["persistence.namespace"].Source)
+	assert.Equal(t, settings.SourceDefault, byKey["persistence.enabled"].Source)
+}
+
 // setupNonAdminSettingsEnv builds a settings test environment where the
 // injected user has the viewer role instead of admin, so requireAdmin (#6000)
 // rejects mutating and reading calls with 403. Returns the env plus the
@@ -242,6 +324,28 @@ func TestSettings_NonAdmin_Forbidden(t *testing.T) {
 		assert.Equal(t, 403, resp.StatusCode)
 	})
 
+	t.Run("GetEffectiveSettings", func(t *testing.T) {
+		env, handler := setupNonAdminSettingsEnv(t)
+		env.App.Get("/api/settings/effective", handler.GetEffectiveSettings)
+
+		req := httptest.NewRequest("GET", "/api/settings/effective", nil)
+		resp, err := env.App.Test(req, fiberTestTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+
+	t.Run("ValidateSettings", func(t *testing.T) {
+		env, handler := setupNonAdminSettingsEnv(t)
+		env.App.Post("/api/settings/validate", handler.ValidateSettings)
+
+		req := httptest.NewRequest("POST", "/api/settings/validate",
+			bytes.NewReader([]byte(`{"theme":"light"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := env.App.Test(req, fiberTestTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, 403, resp.StatusCode)
+	})
+
 	t.Run("ImportSettings", func(t *testing.T) {
 		env, handler := setupNonAdminSettingsEnv(t)
 		env.App.Post("/api/settings/import", handler.ImportSettings)
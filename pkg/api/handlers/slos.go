@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/slo"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// MaxSLOsPerProject caps how many SLOs a console project may define. The
+	// evaluator queries Prometheus for every alerting SLO each interval.
+	MaxSLOsPerProject = 200
+	// maxSLONameLen bounds the display name of an SLO.
+	maxSLONameLen = 128
+	// maxSLODescriptionLen bounds the free-text description of an SLO.
+	maxSLODescriptionLen = 1024
+	// maxSLOQueryLen bounds custom indicator templates. Rendered queries are
+	// additionally capped by the Prometheus querier.
+	maxSLOQueryLen = 1024
+	// defaultSLOWindowDays is the compliance window when none is given.
+	defaultSLOWindowDays = 30
+	// maxSLOWindowDays bounds the compliance window to what a typical
+	// Prometheus retention can answer.
+	maxSLOWindowDays = 90
+	// maxSLOObjective keeps the error budget large enough to measure.
+	maxSLOObjective = 0.99999
+	// sloStatusTimeout bounds the Prometheus queries behind one status call.
+	sloStatusTimeout = 30 * time.Second
+)
+
+// promMetricNameRegex matches a Prometheus metric name.
+var promMetricNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// SLOHandler serves the service level objectives of the active console
+// project and their error-budget status.
+type SLOHandler struct {
+	store   store.Store
+	querier slo.Querier
+	project string
+}
+
+// NewSLOHandler creates a new SLO handler scoped to project. querier may be
+// nil when no cluster is reachable; status requests then fail with 503.
+func NewSLOHandler(s store.Store, querier slo.Querier, project string) *SLOHandler {
+	return &SLOHandler{store: s, querier: querier, project: project}
+}
+
+// sloInput is the body accepted by POST and PUT /api/slos.
+type sloInput struct {
+	Name          string              `json:"name"`
+	Description   string              `json:"description"`
+	Kind          models.SLOKind      `json:"kind"`
+	Target        models.SLOTarget    `json:"target"`
+	Indicator     models.SLOIndicator `json:"indicator"`
+	Objective     float64             `json:"objective"`
+	WindowDays    int                 `json:"window_days"`
+	AlertsEnabled *bool               `json:"alerts_enabled"`
+}
+
+// validateSLOInput checks an SLO definition before it is stored and fills in
+// the default window.
+func validateSLOInput(in *sloInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	if in.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(in.Name) > maxSLONameLen {
+		return fmt.Errorf("name must be at most %d characters", maxSLONameLen)
+	}
+	if len(in.Description) > maxSLODescriptionLen {
+		return fmt.Errorf("description must be at most %d characters", maxSLODescriptionLen)
+	}
+	if in.Kind != models.SLOKindAvailability && in.Kind != models.SLOKindLatency {
+		return fmt.Errorf("kind must be %q or %q", models.SLOKindAvailability, models.SLOKindLatency)
+	}
+	if in.Objective <= 0 || in.Objective > maxSLOObjective {
+		return fmt.Errorf("objective must be greater than 0 and at most %g", maxSLOObjective)
+	}
+	if in.WindowDays == 0 {
+		in.WindowDays = defaultSLOWindowDays
+	}
+	if in.WindowDays < 1 || in.WindowDays > maxSLOWindowDays {
+		return fmt.Errorf("window_days must be between 1 and %d", maxSLOWindowDays)
+	}
+
+	t := in.Target
+	if err := validateClusterName("target.cluster", t.Cluster); err != nil {
+		return err
+	}
+	if err := validateDNSLabel("target.namespace", t.Namespace); err != nil {
+		return err
+	}
+	if t.Service != "" && t.Workload != "" {
+		return errors.New("target.service and target.workload are mutually exclusive")
+	}
+	if t.Service != "" {
+		if err := validateDNSLabel("target.service", t.Service); err != nil {
+			return err
+		}
+	}
+	if t.Workload != "" {
+		if err := validateDNSLabel("target.workload", t.Workload); err != nil {
+			return err
+		}
+	}
+
+	ind := in.Indicator
+	custom := ind.GoodQuery != "" || ind.TotalQuery != ""
+	if custom {
+		if ind.GoodQuery == "" || ind.TotalQuery == "" {
+			return errors.New("indicator.good_query and indicator.total_query must be set together")
+		}
+		if len(ind.GoodQuery) > maxSLOQueryLen || len(ind.TotalQuery) > maxSLOQueryLen {
+			return fmt.Errorf("indicator queries must be at most %d characters", maxSLOQueryLen)
+		}
+		return nil
+	}
+	if t.Service == "" && t.Workload == "" {
+		return errors.New("target.service or target.workload is required unless custom indicator queries are set")
+	}
+	if ind.Metric != "" && !promMetricNameRegex.MatchString(ind.Metric) {
+		return errors.New("indicator.metric must be a valid Prometheus metric name")
+	}
+	if in.Kind == models.SLOKindLatency && ind.ThresholdSeconds <= 0 {
+		return errors.New("indicator.threshold_seconds is required for latency SLOs")
+	}
+	return nil
+}
+
+// apply copies a validated input onto s.
+func (in *sloInput) apply(s *models.SLO) {
+	s.Name = in.Name
+	s.Description = in.Description
+	s.Kind = in.Kind
+	s.Target = in.Target
+	s.Indicator = in.Indicator
+	s.Objective = in.Objective
+	s.WindowDays = in.WindowDays
+	if in.AlertsEnabled != nil {
+		s.AlertsEnabled = *in.AlertsEnabled
+	}
+}
+
+// loadSLO fetches an SLO by the :id path parameter and hides SLOs that
+// belong to another project.
+func (h *SLOHandler) loadSLO(c *fiber.Ctx) (*models.SLO, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid SLO ID")
+	}
+	s, err := h.store.GetSLO(c.UserContext(), id)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to get SLO")
+	}
+	if s == nil || s.Project != h.project {
+		return nil, fiber.NewError(fiber.StatusNotFound, "SLO not found")
+	}
+	return s, nil
+}
+
+// ListSLOs returns a page of the SLOs in the active project.
+// GET /api/slos
+func (h *SLOHandler) ListSLOs(c *fiber.Ctx) error {
+	limit, offset, err := ParsePageParams(c)
+	if err != nil {
+		return err
+	}
+	slos, err := h.store.ListSLOs(c.UserContext(), h.project, limit, offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to list SLOs")
+	}
+	// Never marshal a Go nil slice as JSON null; clients expect [].
+	if slos == nil {
+		slos = []models.SLO{}
+	}
+	return c.JSON(slos)
+}
+
+// GetSLO returns a single SLO definition.
+// GET /api/slos/:id
+func (h *SLOHandler) GetSLO(c *fiber.Ctx) error {
+	s, err := h.loadSLO(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(s)
+}
+
+// CreateSLO defines a new SLO in the active project. Alerts are enabled
+// unless the body sets alerts_enabled to false.
+// POST /api/slos
+func (h *SLOHandler) CreateSLO(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+
+	var input sloInput
+	if err := c.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateSLOInput(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	count, err := h.store.CountProjectSLOs(c.UserContext(), h.project)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to check SLO count")
+	}
+	if count >= MaxSLOsPerProject {
+		return fiber.NewError(fiber.StatusTooManyRequests,
+			fmt.Sprintf("SLO limit reached (%d), maximum is %d per project", count, MaxSLOsPerProject))
+	}
+
+	s := &models.SLO{
+		Project:       h.project,
+		AlertsEnabled: true,
+		CreatedBy:     middleware.GetUserID(c),
+	}
+	input.apply(s)
+	if err := h.store.CreateSLO(c.UserContext(), s); err != nil {
+		if errors.Is(err, store.ErrSLONameTaken) {
+			return fiber.NewError(fiber.StatusConflict, "An SLO with this name already exists")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create SLO")
+	}
+	return c.Status(fiber.StatusCreated).JSON(s)
+}
+
+// UpdateSLO replaces the definition of an SLO. Omitting alerts_enabled keeps
+// the current setting.
+// PUT /api/slos/:id
+func (h *SLOHandler) UpdateSLO(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	s, err := h.loadSLO(c)
+	if err != nil {
+		return err
+	}
+
+	var input sloInput
+	if err := c.BodyParser(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	if err := validateSLOInput(&input); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	input.apply(s)
+	if err := h.store.UpdateSLO(c.UserContext(), s); err != nil {
+		switch {
+		case errors.Is(err, store.ErrSLONameTaken):
+			return fiber.NewError(fiber.StatusConflict, "An SLO with this name already exists")
+		case errors.Is(err, store.ErrNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "SLO not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to update SLO")
+	}
+	return c.JSON(s)
+}
+
+// DeleteSLO removes an SLO. Alerts it fired resolve on the next evaluation.
+// DELETE /api/slos/:id
+func (h *SLOHandler) DeleteSLO(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	s, err := h.loadSLO(c)
+	if err != nil {
+		return err
+	}
+	if err := h.store.DeleteSLO(c.UserContext(), s.ID); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete SLO")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetStatus evaluates an SLO against Prometheus and returns its SLI, error
+// budget and the state of each burn-rate alert rule.
+// GET /api/slos/:id/status
+func (h *SLOHandler) GetStatus(c *fiber.Ctx) error {
+	if h.querier == nil {
+		return ErrNoClusterAccess(c)
+	}
+	s, err := h.loadSLO(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), sloStatusTimeout)
+	defer cancel()
+	st, err := slo.Evaluate(ctx, h.querier, s, slo.DefaultRules, time.Now())
+	if err != nil {
+		slog.Warn("[SLO] status evaluation failed", "slo", s.Name, "cluster", s.Target.Cluster, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Failed to query Prometheus"})
+	}
+	return c.JSON(st)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/slo"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSLOProject = "kubestellar"
+
+// errorRatioQuerier reports 1000 requests per query, of which ratio are good.
+type errorRatioQuerier struct {
+	ratio float64
+	err   error
+}
+
+func (q errorRatioQuerier) Query(_ context.Context, _, promql string) (float64, bool, error) {
+	if q.err != nil {
+		return 0, false, q.err
+	}
+	if strings.Contains(promql, `code!~"5.."`) {
+		return 1000 * q.ratio, true, nil
+	}
+	return 1000, true, nil
+}
+
+func setupSLOTest(t *testing.T, querier slo.Querier) (*testEnv, *test.MockStore) {
+	t.Helper()
+	env := setupTestEnv(t)
+	mockStore := env.Store.(*test.MockStore)
+	handler := NewSLOHandler(env.Store, querier, testSLOProject)
+	env.App.Get("/api/slos", handler.ListSLOs)
+	env.App.Post("/api/slos", handler.CreateSLO)
+	env.App.Get("/api/slos/:id", handler.GetSLO)
+	env.App.Put("/api/slos/:id", handler.UpdateSLO)
+	env.App.Delete("/api/slos/:id", handler.DeleteSLO)
+	env.App.Get("/api/slos/:id/status", handler.GetStatus)
+	return env, mockStore
+}
+
+func validSLOInput() sloInput {
+	return sloInput{
+		Name:      "checkout availability",
+		Kind:      models.SLOKindAvailability,
+		Target:    models.SLOTarget{Cluster: "test-cluster", Namespace: "shop", Service: "checkout"},
+		Objective: 0.999,
+	}
+}
+
+func postSLO(t *testing.T, env *testEnv, method, path string, in sloInput) *http.Response {
+	t.Helper()
+	body, _ := json.Marshal(in)
+	req, err := http.NewRequest(method, path, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestSLOs_Create(t *testing.T) {
+	env, mockStore := setupSLOTest(t, nil)
+	mockStore.On("CountProjectSLOs", testSLOProject).Return(0, nil)
+	mockStore.On("CreateSLO", mock.MatchedBy(func(s *models.SLO) bool {
+		return s.Project == testSLOProject && s.Name == "checkout availability" &&
+			s.WindowDays == defaultSLOWindowDays && s.AlertsEnabled && s.CreatedBy == testAdminUserID
+	})).Return(nil)
+
+	in := validSLOInput()
+	in.Name = "  checkout availability "
+	resp := postSLO(t, env, http.MethodPost, "/api/slos", in)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	mockStore.AssertExpectations(t)
+}
+
+func TestSLOs_CreateErrors(t *testing.T) {
+	env, mockStore := setupSLOTest(t, nil)
+	mockStore.On("CountProjectSLOs", testSLOProject).Return(0, nil)
+	mockStore.On("CreateSLO", mock.Anything).Return(store.ErrSLONameTaken)
+
+	cases := map[string]struct {
+		mutate func(*sloInput)
+		want   int
+	}{
+		"missing name":              {func(in *sloInput) { in.Name = "" }, http.StatusBadRequest},
+		"unknown kind":              {func(in *sloInput) { in.Kind = "throughput" }, http.StatusBadRequest},
+		"objective of one":          {func(in *sloInput) { in.Objective = 1 }, http.StatusBadRequest},
+		"window too long":           {func(in *sloInput) { in.WindowDays = 365 }, http.StatusBadRequest},
+		"bad namespace":             {func(in *sloInput) { in.Target.Namespace = "Shop" }, http.StatusBadRequest},
+		"no service":                {func(in *sloInput) { in.Target.Service = "" }, http.StatusBadRequest},
+		"service & workload":        {func(in *sloInput) { in.Target.Workload = "vllm" }, http.StatusBadRequest},
+		"bad metric":                {func(in *sloInput) { in.Indicator.Metric = "http requests" }, http.StatusBadRequest},
+		"latency without threshold": {func(in *sloInput) { in.Kind = models.SLOKindLatency }, http.StatusBadRequest},
+		"half custom query":         {func(in *sloInput) { in.Indicator.GoodQuery = "sum(up)" }, http.StatusBadRequest},
+		"duplicate name":            {func(in *sloInput) {}, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		in := validSLOInput()
+		tc.mutate(&in)
+		resp := postSLO(t, env, http.MethodPost, "/api/slos", in)
+		assert.Equal(t, tc.want, resp.StatusCode, name)
+	}
+}
+
+func TestSLOs_CreateLimit(t *testing.T) {
+	env, mockStore := setupSLOTest(t, nil)
+	mockStore.On("CountProjectSLOs", testSLOProject).Return(MaxSLOsPerProject, nil)
+
+	resp := postSLO(t, env, http.MethodPost, "/api/slos", validSLOInput())
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestSLOs_UpdateKeepsAlertSetting(t *testing.T) {
+	env, mockStore := setupSLOTest(t, nil)
+	id := uuid.New()
+	mockStore.On("GetSLO", id).Return(&models.SLO{ID: id, Project: testSLOProject, Name: "old", AlertsEnabled: false}, nil)
+	mockStore.On("UpdateSLO", mock.MatchedBy(func(s *models.SLO) bool {
+		return s.Name == "checkout availability" && !s.AlertsEnabled
+	})).Return(nil)
+
+	resp := postSLO(t, env, http.MethodPut, "/api/slos/"+id.String(), validSLOInput())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockStore.AssertExpectations(t)
+}
+
+func TestSLOs_GetOtherProjectIsNotFound(t *testing.T) {
+	env, mockStore := setupSLOTest(t, nil)
+	id := uuid.New()
+	mockStore.On("GetSLO", id).Return(&models.SLO{ID: id, Project: "istio"}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/slos/"+id.String(), nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSLOs_Status(t *testing.T) {
+	env, mockStore := setupSLOTest(t, errorRatioQuerier{ratio: 0.9995})
+	id := uuid.New()
+	in := validSLOInput()
+	s := &models.SLO{ID: id, Project: testSLOProject}
+	require.NoError(t, validateSLOInput(&in))
+	in.apply(s)
+	mockStore.On("GetSLO", id).Return(s, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/slos/"+id.String()+"/status", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var st slo.Status
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&st))
+	assert.Equal(t, id, st.SLOID)
+	require.NotNil(t, st.ErrorBudget.Remaining)
+	assert.InDelta(t, 0.5, *st.ErrorBudget.Remaining, 1e-6)
+	require.Len(t, st.Rules, len(slo.DefaultRules))
+	assert.False(t, st.Rules[0].Firing)
+}
+
+func TestSLOs_StatusErrors(t *testing.T) {
+	env, _ := setupSLOTest(t, nil)
+	req, err := http.NewRequest(http.MethodGet, "/api/slos/"+uuid.NewString()+"/status", nil)
+	require.NoError(t, err)
+	resp, err := env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	env, mockStore := setupSLOTest(t, errorRatioQuerier{err: errors.New("connection refused")})
+	id := uuid.New()
+	mockStore.On("GetSLO", id).Return(&models.SLO{ID: id, Project: testSLOProject, Objective: 0.99, WindowDays: 30}, nil)
+	req, err = http.NewRequest(http.MethodGet, "/api/slos/"+id.String()+"/status", nil)
+	require.NoError(t, err)
+	resp, err = env.App.Test(req, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
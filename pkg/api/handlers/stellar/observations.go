@@ -520,7 +520,17 @@ func (h *Handler) IngestEvent(c *fiber.Ctx) error {
 	if err := auth.RequireEditorOrAdmin(c, h.userStore); err != nil {
 		return err
 	}
+	return h.ingestEvent(c)
+}
+
+// IngestAgentEvent is IngestEvent for the agent relay listener. The caller is
+// a paired kc-agent, authenticated by its client certificate before this
+// handler runs, rather than a console user.
+func (h *Handler) IngestAgentEvent(c *fiber.Ctx) error {
+	return h.ingestEvent(c)
+}
 
+func (h *Handler) ingestEvent(c *fiber.Ctx) error {
 	var event IncomingEvent
 	if err := c.BodyParser(&event); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid event"})
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/telemetry"
+)
+
+// TelemetryHandler serves the usage telemetry opt-in and preview endpoints.
+type TelemetryHandler struct {
+	telemetry *telemetry.Service
+	store     store.Store
+}
+
+// NewTelemetryHandler creates a handler for the given telemetry service.
+func NewTelemetryHandler(t *telemetry.Service, s store.Store) *TelemetryHandler {
+	return &TelemetryHandler{telemetry: t, store: s}
+}
+
+// GetStatus returns the telemetry configuration and last delivery.
+// GET /api/admin/telemetry
+func (h *TelemetryHandler) GetStatus(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	return c.JSON(h.telemetry.Status())
+}
+
+// UpdateConfig opts in to or out of telemetry.
+// PUT /api/admin/telemetry
+func (h *TelemetryHandler) UpdateConfig(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	var body struct {
+		Enabled  *bool  `json:"enabled"`
+		Endpoint string `json:"endpoint"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "enabled is required"})
+	}
+	if err := h.telemetry.Configure(*body.Enabled, body.Endpoint); err != nil {
+		switch {
+		case errors.Is(err, telemetry.ErrDisabled):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case errors.Is(err, telemetry.ErrInvalidEndpoint):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		default:
+			slog.Error("[Telemetry] failed to save telemetry settings", "error", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save telemetry settings"})
+		}
+	}
+	audit.Log(c, audit.ActionUpdateTelemetry, "telemetry", "usage",
+		fmt.Sprintf("enabled=%t endpoint=%s", *body.Enabled, body.Endpoint))
+	return c.JSON(h.telemetry.Status())
+}
+
+// Preview returns exactly the report the next delivery would send.
+// GET /api/admin/telemetry/preview
+func (h *TelemetryHandler) Preview(c *fiber.Ctx) error {
+	if err := RequireAdmin(c, h.store); err != nil {
+		return err
+	}
+	return c.JSON(h.telemetry.Preview(c.UserContext()))
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/telemetry"
+	"github.com/kubestellar/console/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memTelemetryStore struct {
+	cfg settings.TelemetrySettings
+}
+
+func (m *memTelemetryStore) GetTelemetry() settings.TelemetrySettings { return m.cfg }
+
+func (m *memTelemetryStore) SaveTelemetry(t settings.TelemetrySettings) error {
+	m.cfg = t
+	return nil
+}
+
+func setupTelemetryApp(t *testing.T, role models.UserRole) (*fiber.App, *telemetry.Service) {
+	t.Helper()
+	t.Setenv(telemetry.EnvDisabled, "")
+	t.Setenv("DO_NOT_TRACK", "")
+	app := fiber.New()
+	mockStore := new(test.MockStore)
+	userID := uuid.New()
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	usage := telemetry.New(&memTelemetryStore{}, "test", nil, http.DefaultClient)
+	h := NewTelemetryHandler(usage, mockStore)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/admin/telemetry", h.GetStatus)
+	app.Put("/api/admin/telemetry", h.UpdateConfig)
+	app.Get("/api/admin/telemetry/preview", h.Preview)
+	return app, usage
+}
+
+func TestTelemetry_ViewerForbidden(t *testing.T) {
+	app, _ := setupTelemetryApp(t, models.UserRoleViewer)
+	for _, r := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/admin/telemetry", ""},
+		{http.MethodPut, "/api/admin/telemetry", `{"enabled":true,"endpoint":"https://t.example.com"}`},
+		{http.MethodGet, "/api/admin/telemetry/preview", ""},
+	} {
+		status, _ := doFlagRequest(t, app, r.method, r.path, r.body)
+		assert.Equal(t, http.StatusForbidden, status, "%s %s", r.method, r.path)
+	}
+}
+
+func TestTelemetry_OptInAndPreview(t *testing.T) {
+	app, usage := setupTelemetryApp(t, models.UserRoleAdmin)
+
+	status, _ := doFlagRequest(t, app, http.MethodPut, "/api/admin/telemetry", `{"endpoint":"https://t.example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, status, "enabled is required")
+	status, _ = doFlagRequest(t, app, http.MethodPut, "/api/admin/telemetry", `{"enabled":true,"endpoint":"ftp://t.example.com"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body := doFlagRequest(t, app, http.MethodPut, "/api/admin/telemetry", `{"enabled":true,"endpoint":"https://t.example.com"}`)
+	require.Equal(t, http.StatusOK, status)
+	var st telemetry.Status
+	require.NoError(t, json.Unmarshal(body, &st))
+	assert.True(t, st.Active)
+
+	usage.RecordRequest("/api/workloads/list", http.StatusOK)
+	status, body = doFlagRequest(t, app, http.MethodGet, "/api/admin/telemetry/preview", "")
+	require.Equal(t, http.StatusOK, status)
+	var report telemetry.Report
+	require.NoError(t, json.Unmarshal(body, &report))
+	assert.Equal(t, int64(1), report.Features["workloads"])
+	assert.Equal(t, "unknown", report.ClusterCount)
+}
+
+func TestTelemetry_HardOffSwitch(t *testing.T) {
+	app, _ := setupTelemetryApp(t, models.UserRoleAdmin)
+	t.Setenv(telemetry.EnvDisabled, "true")
+
+	status, _ := doFlagRequest(t, app, http.MethodPut, "/api/admin/telemetry", `{"enabled":true,"endpoint":"https://t.example.com"}`)
+	assert.Equal(t, http.StatusConflict, status)
+
+	status, body := doFlagRequest(t, app, http.MethodGet, "/api/admin/telemetry", "")
+	require.Equal(t, http.StatusOK, status)
+	var st telemetry.Status
+	require.NoError(t, json.Unmarshal(body, &st))
+	assert.True(t, st.DisabledByEnv)
+	assert.False(t, st.Active)
+}
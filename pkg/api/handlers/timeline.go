@@ -56,6 +56,16 @@ const timelineMaxLimit = 1000
 // demoTimelineSpanHours defines how far back demo events extend.
 const demoTimelineSpanHours = 24
 
+// Cluster health transitions are journaled as synthetic events on a
+// "Cluster" object so the incident correlation view can place them on the
+// same timeline as Kubernetes events.
+const (
+	clusterHealthObjectKind    = "Cluster"
+	clusterHealthReasonDown    = "ClusterUnhealthy"
+	clusterHealthReasonUp      = "ClusterRecovered"
+	clusterHealthEventUIDInfix = "/Cluster/health/"
+)
+
 // ---------------------------------------------------------------------------
 // TimelineHandler
 // ---------------------------------------------------------------------------
@@ -80,6 +90,10 @@ type TimelineHandler struct {
 	store        store.Store
 	k8sClient    timelineClient
 	stellarSink  StellarEventSink
+
+	// lastHealthy is the health of each cluster at the previous poll, used
+	// to journal transitions. Only the collector goroutine touches it.
+	lastHealthy map[string]bool
 }
 
 // NewTimelineHandler creates a TimelineHandler.
@@ -175,11 +189,12 @@ func (h *TimelineHandler) runCollector(done <-chan struct{}) {
 func (h *TimelineHandler) collectAll() {
 	ctx, cancel := context.WithTimeout(context.Background(), clusterListTimeout)
 	defer cancel()
-	healthy, _, err := h.k8sClient.HealthyClusters(ctx)
+	healthy, offline, err := h.k8sClient.HealthyClusters(ctx)
 	if err != nil {
 		slog.Error("[Timeline] failed to list clusters", "error", err)
 		return
 	}
+	h.recordHealthChanges(ctx, healthy, offline, time.Now().UTC())
 
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, maxTimelineWorkers)
@@ -260,6 +275,61 @@ func (h *TimelineHandler) collectCluster(ci k8s.ClusterInfo) {
 	}
 }
 
+// recordHealthChanges journals a ClusterUnhealthy or ClusterRecovered event
+// for every cluster whose health differs from the previous poll. The first
+// poll only establishes the baseline, so a restart does not re-journal
+// clusters that were already down.
+func (h *TimelineHandler) recordHealthChanges(ctx context.Context, healthy, offline []k8s.ClusterInfo, now time.Time) {
+	prev := h.lastHealthy
+	current := make(map[string]bool, len(healthy)+len(offline))
+	for _, ci := range healthy {
+		current[ci.Name] = true
+	}
+	for _, ci := range offline {
+		// A cluster whose health is momentarily unknown keeps its last
+		// known state rather than counting as down.
+		if !ci.HealthUnknown {
+			current[ci.Name] = false
+		} else if was, ok := prev[ci.Name]; ok {
+			current[ci.Name] = was
+		}
+	}
+	h.lastHealthy = current
+	if prev == nil {
+		return
+	}
+
+	ts := now.Format(time.RFC3339)
+	for name, up := range current {
+		was, known := prev[name]
+		if !known || was == up {
+			continue
+		}
+		ce := store.ClusterEvent{
+			ID:                 uuid.NewString(),
+			ClusterName:        name,
+			EventType:          "Warning",
+			Reason:             clusterHealthReasonDown,
+			Message:            "Cluster stopped responding to health checks",
+			InvolvedObjectKind: clusterHealthObjectKind,
+			InvolvedObjectName: name,
+			EventUID:           name + clusterHealthEventUIDInfix + ts,
+			EventCount:         1,
+			FirstSeen:          ts,
+			LastSeen:           ts,
+		}
+		if up {
+			ce.EventType = "Normal"
+			ce.Reason = clusterHealthReasonUp
+			ce.Message = "Cluster is responding to health checks again"
+		}
+		if err := h.store.InsertOrUpdateEvent(ctx, ce); err != nil {
+			slog.Warn("[Timeline] failed to record health change",
+				"cluster", name, "error", err)
+		}
+	}
+}
+
 // parseObject splits "Kind/Name" into its parts.
 func parseObject(obj string) (kind, name string) {
 	idx := strings.IndexByte(obj, '/')
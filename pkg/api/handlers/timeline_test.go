@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/kubestellar/console/pkg/k8s"
 	"github.com/kubestellar/console/pkg/store"
 	"github.com/kubestellar/console/pkg/test"
 	"github.com/stretchr/testify/assert"
@@ -61,3 +64,58 @@ func TestTimelineGetTimeline_Error(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 }
+
+// recordingEventStore captures the events the collector journals.
+type recordingEventStore struct {
+	*test.MockStore
+	events []store.ClusterEvent
+}
+
+func (s *recordingEventStore) InsertOrUpdateEvent(_ context.Context, e store.ClusterEvent) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func TestTimelineRecordHealthChanges(t *testing.T) {
+	rec := &recordingEventStore{MockStore: new(test.MockStore)}
+	h := &TimelineHandler{store: rec}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	up := func(names ...string) []k8s.ClusterInfo {
+		out := make([]k8s.ClusterInfo, len(names))
+		for i, n := range names {
+			out[i] = k8s.ClusterInfo{Name: n, Healthy: true}
+		}
+		return out
+	}
+	down := func(names ...string) []k8s.ClusterInfo {
+		out := make([]k8s.ClusterInfo, len(names))
+		for i, n := range names {
+			out[i] = k8s.ClusterInfo{Name: n}
+		}
+		return out
+	}
+	ctx := context.Background()
+
+	h.recordHealthChanges(ctx, up("a"), down("b"), now)
+	assert.Empty(t, rec.events, "the first poll only sets the baseline")
+
+	h.recordHealthChanges(ctx, up("b"), down("a"), now.Add(time.Minute))
+	require.Len(t, rec.events, 2)
+	byCluster := map[string]store.ClusterEvent{}
+	for _, e := range rec.events {
+		byCluster[e.ClusterName] = e
+	}
+	assert.Equal(t, clusterHealthReasonDown, byCluster["a"].Reason)
+	assert.Equal(t, "Warning", byCluster["a"].EventType)
+	assert.Equal(t, clusterHealthObjectKind, byCluster["a"].InvolvedObjectKind)
+	assert.Equal(t, clusterHealthReasonUp, byCluster["b"].Reason)
+	assert.Equal(t, "2026-05-01T12:01:00Z", byCluster["b"].FirstSeen)
+
+	// Unknown health keeps the last state; unchanged clusters are not journaled.
+	rec.events = nil
+	h.recordHealthChanges(ctx, up("b"), []k8s.ClusterInfo{{Name: "a", HealthUnknown: true}}, now.Add(2*time.Minute))
+	assert.Empty(t, rec.events)
+	h.recordHealthChanges(ctx, up("a", "b"), nil, now.Add(3*time.Minute))
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, clusterHealthReasonUp, rec.events[0].Reason)
+}
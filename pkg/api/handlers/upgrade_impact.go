@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// upgradeScanTimeout bounds the scan of one cluster. It lists every object
+// of each removed kind, so it gets more time than a single read.
+const upgradeScanTimeout = 60 * time.Second
+
+// upgradeScanner is the subset of k8s.MultiClusterClient the upgrade impact
+// report needs.
+type upgradeScanner interface {
+	GetUpgradeScan(ctx context.Context, contextName string, target k8s.KubeVersion) (*k8s.UpgradeScan, error)
+}
+
+// managedWorkloadSource returns the ManagedWorkloads that deploy to a
+// cluster, directly or through a ClusterGroup.
+type managedWorkloadSource interface {
+	ManagedWorkloadsTargeting(ctx context.Context, cluster string) ([]v1alpha1.ManagedWorkload, error)
+}
+
+// persistenceWorkloadSource reads ManagedWorkloads from the console
+// persistence cluster. It returns nothing while persistence is disabled.
+type persistenceWorkloadSource struct {
+	store *store.PersistenceStore
+}
+
+func (s persistenceWorkloadSource) ManagedWorkloadsTargeting(ctx context.Context, cluster string) ([]v1alpha1.ManagedWorkload, error) {
+	if !s.store.IsEnabled() {
+		return nil, nil
+	}
+	client, _, err := s.store.GetActiveClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	persistence := k8s.NewConsolePersistence(client)
+
+	var out []v1alpha1.ManagedWorkload
+	for _, namespace := range s.store.GetNamespaces() {
+		workloads, err := persistence.ListManagedWorkloads(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := persistence.ListClusterGroups(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		inGroup := make(map[string]bool)
+		for _, g := range groups {
+			if slices.Contains(g.Status.MatchedClusters, cluster) {
+				inGroup[g.Name] = true
+			}
+		}
+
+		for _, mw := range workloads {
+			targeted := slices.Contains(mw.Spec.TargetClusters, cluster)
+			for _, g := range mw.Spec.TargetGroups {
+				targeted = targeted || inGroup[g]
+			}
+			if targeted {
+				out = append(out, mw)
+			}
+		}
+	}
+	return out, nil
+}
+
+// UpgradeImpactHandler builds pre-upgrade reports: what in a cluster will
+// break, or stall the rollout, when it moves to a newer Kubernetes version.
+type UpgradeImpactHandler struct {
+	k8sClient upgradeScanner
+	workloads managedWorkloadSource
+}
+
+// NewUpgradeImpactHandler creates an upgrade impact handler. A nil
+// persistenceStore leaves ManagedWorkloads out of the report.
+func NewUpgradeImpactHandler(k8sClient *k8s.MultiClusterClient, persistenceStore *store.PersistenceStore) *UpgradeImpactHandler {
+	h := &UpgradeImpactHandler{}
+	// Avoid storing typed nil pointers in the interfaces.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	if persistenceStore != nil {
+		h.workloads = persistenceWorkloadSource{store: persistenceStore}
+	}
+	return h
+}
+
+// UpgradeImpactSummary counts the findings of a report.
+type UpgradeImpactSummary struct {
+	RemovedAPIUsages int `json:"removedApiUsages"`
+	ConsoleManaged   int `json:"consoleManaged"`
+	PDBRisks         int `json:"pdbRisks"`
+}
+
+// UpgradeImpactReport is the response of GET
+// /api/clusters/:cluster/upgrade-impact.
+type UpgradeImpactReport struct {
+	Cluster string `json:"cluster"`
+	k8s.UpgradeScan
+	// ManagedWorkloadsChecked is false when the ManagedWorkload list could
+	// not be read; console-managed findings may then be incomplete.
+	ManagedWorkloadsChecked bool                 `json:"managedWorkloadsChecked"`
+	Summary                 UpgradeImpactSummary `json:"summary"`
+	// Ready is set when nothing uses a removed API and no PDB blocks drains.
+	Ready bool `json:"ready"`
+}
+
+// GetUpgradeImpact reports the objects of a cluster last written through an
+// API removed by the target version, the ManagedWorkloads that still
+// reference such an API, and the PodDisruptionBudgets that will stall node
+// drains. Read-only.
+// GET /api/clusters/:cluster/upgrade-impact?targetVersion=1.31
+func (h *UpgradeImpactHandler) GetUpgradeImpact(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	raw := c.Query("targetVersion")
+	if raw == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "targetVersion is required")
+	}
+	target, err := k8s.ParseKubeVersion(raw)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "targetVersion must look like 1.31")
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), upgradeScanTimeout)
+	defer cancel()
+	scan, err := h.k8sClient.GetUpgradeScan(ctx, cluster, target)
+	if err != nil {
+		if errors.Is(err, k8s.ErrNotAnUpgrade) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if msg, ok := SanitizedErrorMessages[k8s.ClassifyError(err.Error())]; ok {
+			slog.Info("[UpgradeImpact] scan failed", "cluster", cluster, "error", err)
+			return fiber.NewError(fiber.StatusBadGateway, msg)
+		}
+		return handleK8sError(c, err)
+	}
+
+	report := UpgradeImpactReport{Cluster: cluster, UpgradeScan: *scan, ManagedWorkloadsChecked: true}
+	if h.workloads != nil {
+		workloads, err := h.workloads.ManagedWorkloadsTargeting(ctx, cluster)
+		if err != nil {
+			slog.Warn("[UpgradeImpact] failed to list managed workloads", "cluster", cluster, "error", err)
+			report.ManagedWorkloadsChecked = false
+		}
+		report.Usages = append(report.Usages, managedWorkloadUsages(workloads, scan.RemovedAPIs)...)
+	}
+
+	for _, u := range report.Usages {
+		if u.ConsoleManaged {
+			report.Summary.ConsoleManaged++
+		}
+	}
+	report.Summary.RemovedAPIUsages = len(report.Usages)
+	report.Summary.PDBRisks = len(report.PDBRisks)
+	report.Ready = report.Summary.RemovedAPIUsages == 0 && report.Summary.PDBRisks == 0
+	return c.JSON(report)
+}
+
+// managedWorkloadUsages flags ManagedWorkloads whose workloadRef names a
+// removed API version: the next reconcile would deploy through it.
+func managedWorkloadUsages(workloads []v1alpha1.ManagedWorkload, removed []k8s.RemovedAPI) []k8s.RemovedAPIUsage {
+	var out []k8s.RemovedAPIUsage
+	for _, mw := range workloads {
+		ref := mw.Spec.WorkloadRef
+		api, ok := k8s.LookupRemovedAPI(removed, ref.APIVersion, ref.Kind)
+		if !ok {
+			continue
+		}
+		out = append(out, k8s.RemovedAPIUsage{
+			Kind:           "ManagedWorkload",
+			Namespace:      mw.Namespace,
+			Name:           mw.Name,
+			APIVersion:     api.APIVersion(),
+			Replacement:    api.Replacement,
+			RemovedIn:      api.RemovedIn,
+			Source:         k8s.UsageSourceManagedWorkload,
+			ConsoleManaged: true,
+		})
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+type fakeUpgradeScanner struct {
+	scan   *k8s.UpgradeScan
+	err    error
+	target k8s.KubeVersion
+}
+
+func (f *fakeUpgradeScanner) GetUpgradeScan(_ context.Context, _ string, target k8s.KubeVersion) (*k8s.UpgradeScan, error) {
+	f.target = target
+	return f.scan, f.err
+}
+
+type fakeWorkloadSource struct {
+	workloads []v1alpha1.ManagedWorkload
+	err       error
+}
+
+func (f fakeWorkloadSource) ManagedWorkloadsTargeting(context.Context, string) ([]v1alpha1.ManagedWorkload, error) {
+	return f.workloads, f.err
+}
+
+func managedWorkload(name, apiVersion, kind string) v1alpha1.ManagedWorkload {
+	return v1alpha1.ManagedWorkload{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubestellar-console", Name: name},
+		Spec: v1alpha1.ManagedWorkloadSpec{
+			WorkloadRef:    v1alpha1.WorkloadReference{APIVersion: apiVersion, Kind: kind, Name: name},
+			TargetClusters: []string{"prod"},
+		},
+	}
+}
+
+func getUpgradeImpact(t *testing.T, h *UpgradeImpactHandler, query string) (int, UpgradeImpactReport) {
+	t.Helper()
+	env := setupTestEnv(t)
+	env.App.Get("/api/clusters/:cluster/upgrade-impact", h.GetUpgradeImpact)
+	resp, err := env.App.Test(httptest.NewRequest(http.MethodGet, "/api/clusters/prod/upgrade-impact"+query, nil), 5000)
+	require.NoError(t, err)
+	var out UpgradeImpactReport
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out
+}
+
+func TestGetUpgradeImpact_Report(t *testing.T) {
+	removed := k8s.RemovedAPIsBetween(k8s.KubeVersion{Major: 1, Minor: 24}, k8s.KubeVersion{Major: 1, Minor: 25})
+	scanner := &fakeUpgradeScanner{scan: &k8s.UpgradeScan{
+		CurrentVersion: "1.24",
+		TargetVersion:  "1.25",
+		RemovedAPIs:    removed,
+		Usages: []k8s.RemovedAPIUsage{{
+			Kind: "CronJob", Namespace: "ops", Name: "backup", APIVersion: "batch/v1beta1",
+			Replacement: "batch/v1", RemovedIn: "1.25", Source: k8s.UsageSourceManagedFields, Manager: "helm",
+		}},
+		PDBRisks: []k8s.PDBRisk{{Namespace: "shop", Name: "api", Reason: k8s.PDBRiskZeroMaxUnavailable}},
+	}}
+	h := &UpgradeImpactHandler{k8sClient: scanner, workloads: fakeWorkloadSource{workloads: []v1alpha1.ManagedWorkload{
+		managedWorkload("nightly", "batch/v1beta1", "CronJob"),
+		managedWorkload("web", "apps/v1", "Deployment"),
+	}}}
+
+	status, out := getUpgradeImpact(t, h, "?targetVersion=v1.25.0")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, k8s.KubeVersion{Major: 1, Minor: 25}, scanner.target)
+	assert.Equal(t, "prod", out.Cluster)
+	assert.True(t, out.ManagedWorkloadsChecked)
+	assert.False(t, out.Ready)
+	assert.Equal(t, UpgradeImpactSummary{RemovedAPIUsages: 2, ConsoleManaged: 1, PDBRisks: 1}, out.Summary)
+
+	require.Len(t, out.Usages, 2)
+	mw := out.Usages[1]
+	assert.Equal(t, "ManagedWorkload", mw.Kind)
+	assert.Equal(t, "nightly", mw.Name)
+	assert.Equal(t, k8s.UsageSourceManagedWorkload, mw.Source)
+	assert.Equal(t, "batch/v1", mw.Replacement)
+	assert.True(t, mw.ConsoleManaged)
+}
+
+func TestGetUpgradeImpact_WorkloadSourceFailureIsPartial(t *testing.T) {
+	scanner := &fakeUpgradeScanner{scan: &k8s.UpgradeScan{Usages: []k8s.RemovedAPIUsage{}, PDBRisks: []k8s.PDBRisk{}}}
+	h := &UpgradeImpactHandler{k8sClient: scanner, workloads: fakeWorkloadSource{err: errors.New("persistence cluster unreachable")}}
+
+	status, out := getUpgradeImpact(t, h, "?targetVersion=1.31")
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, out.ManagedWorkloadsChecked)
+	assert.True(t, out.Ready)
+}
+
+func TestGetUpgradeImpact_Errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		h      *UpgradeImpactHandler
+		query  string
+		status int
+	}{
+		{"no cluster access", NewUpgradeImpactHandler(nil, nil), "?targetVersion=1.31", http.StatusServiceUnavailable},
+		{"missing target", &UpgradeImpactHandler{k8sClient: &fakeUpgradeScanner{}}, "", http.StatusBadRequest},
+		{"bad target", &UpgradeImpactHandler{k8sClient: &fakeUpgradeScanner{}}, "?targetVersion=next", http.StatusBadRequest},
+		{"downgrade", &UpgradeImpactHandler{k8sClient: &fakeUpgradeScanner{
+			err: fmt.Errorf("%w: cluster runs 1.31", k8s.ErrNotAnUpgrade),
+		}}, "?targetVersion=1.30", http.StatusBadRequest},
+		{"unreachable", &UpgradeImpactHandler{k8sClient: &fakeUpgradeScanner{
+			err: errors.New("dial tcp 10.0.0.1:6443: connect: connection refused"),
+		}}, "?targetVersion=1.31", http.StatusBadGateway},
+	}
+	for _, tc := range cases {
+		status, _ := getUpgradeImpact(t, tc.h, tc.query)
+		assert.Equal(t, tc.status, status, tc.name)
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+const (
+	// defaultVeleroListLimit and maxVeleroListLimit bound the backups and
+	// restores returned per cluster.
+	defaultVeleroListLimit = 50
+	maxVeleroListLimit     = 500
+	// maxVeleroBackupNamespaces bounds includedNamespaces of an ad-hoc backup.
+	maxVeleroBackupNamespaces = 50
+)
+
+// veleroClient is the subset of k8s.MultiClusterClient the Velero endpoints
+// need.
+type veleroClient interface {
+	ListVeleroSchedules(ctx context.Context, contextName string) ([]v1alpha1.VeleroSchedule, error)
+	ListVeleroBackups(ctx context.Context, contextName string, limit int) ([]v1alpha1.VeleroBackup, error)
+	ListVeleroRestores(ctx context.Context, contextName string, limit int) ([]v1alpha1.VeleroRestore, error)
+	CreateVeleroBackup(ctx context.Context, contextName, namespace string, req v1alpha1.VeleroBackupRequest) (*v1alpha1.VeleroBackup, error)
+}
+
+// veleroBackupBody is the body accepted by CreateBackup.
+type veleroBackupBody struct {
+	v1alpha1.VeleroBackupRequest
+	// Namespace is where Velero runs; defaults to "velero".
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// VeleroHandler serves Velero schedules, backups and restores on managed
+// clusters and triggers ad-hoc backups.
+type VeleroHandler struct {
+	k8sClient veleroClient
+	store     store.Store
+}
+
+// NewVeleroHandler creates a Velero handler.
+func NewVeleroHandler(k8sClient *k8s.MultiClusterClient, s store.Store) *VeleroHandler {
+	h := &VeleroHandler{store: s}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// veleroListLimit reads the limit query parameter.
+func veleroListLimit(c *fiber.Ctx) (int, error) {
+	limit := c.QueryInt("limit", defaultVeleroListLimit)
+	if limit < 1 || limit > maxVeleroListLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxVeleroListLimit)
+	}
+	return limit, nil
+}
+
+// ListSchedules lists a cluster's Velero schedules.
+// GET /api/clusters/:cluster/velero/schedules
+func (h *VeleroHandler) ListSchedules(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	schedules, err := h.k8sClient.ListVeleroSchedules(c.UserContext(), cluster)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	if schedules == nil {
+		schedules = []v1alpha1.VeleroSchedule{}
+	}
+	return c.JSON(fiber.Map{"schedules": schedules})
+}
+
+// ListBackups lists a cluster's most recent Velero backups, newest first.
+// GET /api/clusters/:cluster/velero/backups?limit=50
+func (h *VeleroHandler) ListBackups(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	limit, err := veleroListLimit(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	backups, err := h.k8sClient.ListVeleroBackups(c.UserContext(), cluster, limit)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	if backups == nil {
+		backups = []v1alpha1.VeleroBackup{}
+	}
+	return c.JSON(fiber.Map{"backups": backups})
+}
+
+// ListRestores lists a cluster's most recent Velero restores, newest first.
+// GET /api/clusters/:cluster/velero/restores?limit=50
+func (h *VeleroHandler) ListRestores(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	limit, err := veleroListLimit(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	restores, err := h.k8sClient.ListVeleroRestores(c.UserContext(), cluster, limit)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	if restores == nil {
+		restores = []v1alpha1.VeleroRestore{}
+	}
+	return c.JSON(fiber.Map{"restores": restores})
+}
+
+// validateVeleroBackupRequest checks an ad-hoc backup request. A namespace
+// of "*" is Velero's "every namespace".
+func validateVeleroBackupRequest(req *v1alpha1.VeleroBackupRequest) error {
+	if req.Name != "" {
+		if err := validateDNSSubdomain("name", req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Schedule != "" {
+		if err := validateDNSSubdomain("schedule", req.Schedule); err != nil {
+			return err
+		}
+	}
+	if len(req.IncludedNamespaces) > maxVeleroBackupNamespaces {
+		return fmt.Errorf("at most %d includedNamespaces are allowed", maxVeleroBackupNamespaces)
+	}
+	for _, ns := range req.IncludedNamespaces {
+		if ns == "*" {
+			continue
+		}
+		if err := validateDNSLabel("includedNamespaces", ns); err != nil {
+			return err
+		}
+	}
+	if req.StorageLocation != "" {
+		if err := validateDNSSubdomain("storageLocation", req.StorageLocation); err != nil {
+			return err
+		}
+	}
+	return v1alpha1.ValidateVeleroTTL("ttl", req.TTL)
+}
+
+// CreateBackup triggers an ad-hoc Velero backup, optionally from a
+// schedule's template. Editor or admin only.
+// POST /api/clusters/:cluster/velero/backups
+func (h *VeleroHandler) CreateBackup(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	var body veleroBackupBody
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+	if body.Namespace == "" {
+		body.Namespace = k8s.DefaultVeleroNamespace
+	}
+	if err := validateDNSLabel("namespace", body.Namespace); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	req := body.VeleroBackupRequest
+	if err := validateVeleroBackupRequest(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	backup, err := h.k8sClient.CreateVeleroBackup(c.UserContext(), cluster, body.Namespace, req)
+	if err != nil {
+		slog.Error("[Velero] failed to create backup", "cluster", cluster, "schedule", req.Schedule, "error", err)
+		return HandleK8sError(c, err)
+	}
+	detail := "namespaces=" + strings.Join(backup.IncludedNamespaces, ",")
+	if req.Schedule != "" {
+		detail += " schedule=" + req.Schedule
+	}
+	audit.Log(c, audit.ActionTriggerVeleroBackup, "velero_backup", cluster+"/"+backup.Namespace+"/"+backup.Name, detail)
+	return c.Status(fiber.StatusCreated).JSON(backup)
+}
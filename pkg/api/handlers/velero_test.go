@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type fakeVeleroClient struct {
+	backups   []v1alpha1.VeleroBackup
+	limit     int
+	namespace string
+	req       *v1alpha1.VeleroBackupRequest
+}
+
+func (f *fakeVeleroClient) ListVeleroSchedules(_ context.Context, contextName string) ([]v1alpha1.VeleroSchedule, error) {
+	return []v1alpha1.VeleroSchedule{{Name: "daily", Namespace: "velero", Cluster: contextName, Schedule: "0 2 * * *"}}, nil
+}
+
+func (f *fakeVeleroClient) ListVeleroBackups(_ context.Context, _ string, limit int) ([]v1alpha1.VeleroBackup, error) {
+	f.limit = limit
+	return f.backups, nil
+}
+
+func (f *fakeVeleroClient) ListVeleroRestores(_ context.Context, _ string, limit int) ([]v1alpha1.VeleroRestore, error) {
+	f.limit = limit
+	return nil, nil
+}
+
+func (f *fakeVeleroClient) CreateVeleroBackup(_ context.Context, contextName, namespace string, req v1alpha1.VeleroBackupRequest) (*v1alpha1.VeleroBackup, error) {
+	f.namespace = namespace
+	f.req = &req
+	name := req.Name
+	if name == "" {
+		name = "console-20261014020000"
+	}
+	return &v1alpha1.VeleroBackup{
+		Name: name, Namespace: namespace, Cluster: contextName,
+		Phase: v1alpha1.VeleroPhaseNew, IncludedNamespaces: req.IncludedNamespaces,
+	}, nil
+}
+
+func runVeleroRequest(t *testing.T, role models.UserRole, client *fakeVeleroClient, method, path, body string) *http.Response {
+	t.Helper()
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	h := &VeleroHandler{k8sClient: client, store: mockStore}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		return c.Next()
+	})
+	app.Get("/api/clusters/:cluster/velero/schedules", h.ListSchedules)
+	app.Get("/api/clusters/:cluster/velero/backups", h.ListBackups)
+	app.Post("/api/clusters/:cluster/velero/backups", h.CreateBackup)
+	app.Get("/api/clusters/:cluster/velero/restores", h.ListRestores)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestVelero_ListBackups(t *testing.T) {
+	client := &fakeVeleroClient{backups: []v1alpha1.VeleroBackup{{Name: "daily-1", Phase: v1alpha1.VeleroPhaseFailed}}}
+	resp := runVeleroRequest(t, models.UserRoleViewer, client, http.MethodGet, "/api/clusters/prod/velero/backups?limit=10", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Backups []v1alpha1.VeleroBackup `json:"backups"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Backups, 1)
+	assert.Equal(t, "daily-1", body.Backups[0].Name)
+	assert.Equal(t, 10, client.limit)
+
+	resp = runVeleroRequest(t, models.UserRoleViewer, client, http.MethodGet, "/api/clusters/prod/velero/backups?limit=5000", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestVelero_ListSchedulesAndRestores(t *testing.T) {
+	client := &fakeVeleroClient{}
+	resp := runVeleroRequest(t, models.UserRoleViewer, client, http.MethodGet, "/api/clusters/prod/velero/schedules", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var schedules struct {
+		Schedules []v1alpha1.VeleroSchedule `json:"schedules"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&schedules))
+	require.Len(t, schedules.Schedules, 1)
+	assert.Equal(t, "prod", schedules.Schedules[0].Cluster)
+
+	resp = runVeleroRequest(t, models.UserRoleViewer, client, http.MethodGet, "/api/clusters/prod/velero/restores", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var restores map[string]json.RawMessage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&restores))
+	assert.JSONEq(t, `[]`, string(restores["restores"]), "no restores is an empty list, not null")
+	assert.Equal(t, defaultVeleroListLimit, client.limit)
+}
+
+func TestVelero_CreateBackup(t *testing.T) {
+	client := &fakeVeleroClient{}
+	resp := runVeleroRequest(t, models.UserRoleEditor, client, http.MethodPost, "/api/clusters/prod/velero/backups",
+		`{"schedule":"daily","includedNamespaces":["shop"],"ttl":"72h","labels":{"velero.io/schedule-name":"other"}}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	require.NotNil(t, client.req)
+	assert.Equal(t, "velero", client.namespace)
+	assert.Equal(t, "daily", client.req.Schedule)
+	assert.Equal(t, []string{"shop"}, client.req.IncludedNamespaces)
+	assert.Equal(t, "72h", client.req.TTL)
+	assert.Empty(t, client.req.Labels, "labels are not taken from the request")
+}
+
+func TestVelero_CreateBackup_EmptyBody(t *testing.T) {
+	client := &fakeVeleroClient{}
+	resp := runVeleroRequest(t, models.UserRoleAdmin, client, http.MethodPost, "/api/clusters/prod/velero/backups", "")
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.NotNil(t, client.req)
+}
+
+func TestVelero_CreateBackup_RequiresEditor(t *testing.T) {
+	client := &fakeVeleroClient{}
+	resp := runVeleroRequest(t, models.UserRoleViewer, client, http.MethodPost, "/api/clusters/prod/velero/backups", `{}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Nil(t, client.req)
+}
+
+func TestVelero_CreateBackup_Validation(t *testing.T) {
+	for name, body := range map[string]string{
+		"bad ttl":        `{"ttl":"forever"}`,
+		"negative ttl":   `{"ttl":"-1h"}`,
+		"bad namespace":  `{"includedNamespaces":["Shop_1"]}`,
+		"bad schedule":   `{"schedule":"../daily"}`,
+		"bad velero ns":  `{"namespace":"kube system"}`,
+		"malformed body": `{"ttl":`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &fakeVeleroClient{}
+			resp := runVeleroRequest(t, models.UserRoleEditor, client, http.MethodPost, "/api/clusters/prod/velero/backups", body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Nil(t, client.req)
+		})
+	}
+
+	client := &fakeVeleroClient{}
+	resp := runVeleroRequest(t, models.UserRoleEditor, client, http.MethodPost, "/api/clusters/prod/velero/backups",
+		`{"includedNamespaces":["*"]}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "* backs up every namespace")
+}
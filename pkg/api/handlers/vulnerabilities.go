@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/vulnscan"
+)
+
+const (
+	// vulnerabilityScanTimeout bounds the scans of one request. Scans still
+	// running then finish in the background and fill the cache, so a retry
+	// returns them.
+	vulnerabilityScanTimeout = 2 * time.Minute
+	// maxVulnerabilityImages bounds the distinct images scanned per request.
+	maxVulnerabilityImages = 500
+)
+
+// workloadImageLister is the subset of k8s.MultiClusterClient the
+// vulnerability endpoints need.
+type workloadImageLister interface {
+	ListWorkloadImages(ctx context.Context, contextName, namespace string) ([]k8s.WorkloadImages, error)
+}
+
+// workloadVulnerabilities is one workload with the vulnerability counts of
+// its images summed.
+type workloadVulnerabilities struct {
+	k8s.WorkloadImages
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+	// Unscanned lists the images without a report or whose scan failed;
+	// they are not counted.
+	Unscanned []string `json:"unscanned"`
+}
+
+// VulnerabilityHandler serves CVE summaries of the images running on
+// managed clusters, as reported by the configured scanner.
+type VulnerabilityHandler struct {
+	k8sClient workloadImageLister
+	scanner   vulnscan.Scanner
+}
+
+// NewVulnerabilityHandler creates a vulnerability handler. A nil scanner
+// makes every endpoint return 503.
+func NewVulnerabilityHandler(k8sClient *k8s.MultiClusterClient, scanner vulnscan.Scanner) *VulnerabilityHandler {
+	h := &VulnerabilityHandler{scanner: scanner}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+func errNoScanner(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "vulnerability scanner not configured"})
+}
+
+// ListWorkloadVulnerabilities returns the workloads of a cluster with the
+// vulnerability counts of their images, and the summary of each image.
+// GET /api/clusters/:cluster/vulnerabilities?namespace=shop
+func (h *VulnerabilityHandler) ListWorkloadVulnerabilities(c *fiber.Ctx) error {
+	if h.scanner == nil {
+		return errNoScanner(c)
+	}
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	cluster := c.Params("cluster")
+	if err := validateClusterName("cluster", cluster); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	namespace := c.Query("namespace")
+	if namespace != "" {
+		if err := validateDNSLabel("namespace", namespace); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	workloads, err := h.k8sClient.ListWorkloadImages(c.UserContext(), cluster, namespace)
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+	var images []string
+	seen := make(map[string]bool)
+	for _, w := range workloads {
+		for _, image := range w.Images {
+			if !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	if len(images) > maxVulnerabilityImages {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("%d images exceed the limit of %d; filter by namespace", len(images), maxVulnerabilityImages),
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), vulnerabilityScanTimeout)
+	defer cancel()
+	results := vulnscan.ScanAll(ctx, h.scanner, images)
+
+	summaries := make(map[string]*vulnscan.Summary)
+	scanErrors := make(map[string]string)
+	for image, r := range results {
+		switch {
+		case r.Err == nil:
+			summaries[image] = r.Summary
+		case errors.Is(r.Err, vulnscan.ErrNotFound):
+		default:
+			slog.Warn("[Vulnerabilities] image scan failed", "cluster", cluster, "image", image, "error", r.Err)
+			scanErrors[image] = "scan failed"
+		}
+	}
+
+	out := make([]workloadVulnerabilities, 0, len(workloads))
+	for _, w := range workloads {
+		wv := workloadVulnerabilities{WorkloadImages: w, Unscanned: []string{}}
+		for _, image := range w.Images {
+			s, ok := summaries[image]
+			if !ok {
+				wv.Unscanned = append(wv.Unscanned, image)
+				continue
+			}
+			wv.Critical += s.Critical
+			wv.High += s.High
+			wv.Medium += s.Medium
+			wv.Low += s.Low
+			wv.Unknown += s.Unknown
+		}
+		out = append(out, wv)
+	}
+	return c.JSON(fiber.Map{
+		"scanner":   h.scanner.Name(),
+		"workloads": out,
+		"images":    summaries,
+		"errors":    scanErrors,
+	})
+}
+
+// GetImageVulnerabilities returns the vulnerability summary of one image.
+// GET /api/vulnerabilities/image?image=ghcr.io/acme/checkout:1.2
+func (h *VulnerabilityHandler) GetImageVulnerabilities(c *fiber.Ctx) error {
+	if h.scanner == nil {
+		return errNoScanner(c)
+	}
+	image := c.Query("image")
+	if err := vulnscan.ValidateImage(image); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(c.UserContext(), vulnerabilityScanTimeout)
+	defer cancel()
+	summary, err := h.scanner.Scan(ctx, image)
+	if err != nil {
+		if errors.Is(err, vulnscan.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no vulnerability report for image"})
+		}
+		slog.Warn("[Vulnerabilities] image scan failed", "image", image, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "vulnerability scan failed"})
+	}
+	return c.JSON(summary)
+}
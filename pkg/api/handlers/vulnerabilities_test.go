@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/vulnscan"
+)
+
+// fakeVulnScanner returns a fixed summary or error per image; other images
+// have no report.
+type fakeVulnScanner struct {
+	summaries map[string]*vulnscan.Summary
+	errs      map[string]error
+}
+
+func (f *fakeVulnScanner) Name() string { return "fake" }
+
+func (f *fakeVulnScanner) Scan(_ context.Context, image string) (*vulnscan.Summary, error) {
+	if err := f.errs[image]; err != nil {
+		return nil, err
+	}
+	if s, ok := f.summaries[image]; ok {
+		return s, nil
+	}
+	return nil, vulnscan.ErrNotFound
+}
+
+type fakeImageLister struct {
+	workloads []k8s.WorkloadImages
+	namespace string
+}
+
+func (f *fakeImageLister) ListWorkloadImages(_ context.Context, _, namespace string) ([]k8s.WorkloadImages, error) {
+	f.namespace = namespace
+	return f.workloads, nil
+}
+
+func runVulnerabilityRequest(t *testing.T, h *VulnerabilityHandler, path string) (int, map[string]interface{}) {
+	t.Helper()
+	// Render fiber errors as JSON like the server's error handler.
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		code := fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			code = fiberErr.Code
+		}
+		return c.Status(code).JSON(fiber.Map{"error": err.Error()})
+	}})
+	app.Get("/api/clusters/:cluster/vulnerabilities", h.ListWorkloadVulnerabilities)
+	app.Get("/api/vulnerabilities/image", h.GetImageVulnerabilities)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), 5000)
+	require.NoError(t, err)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestListWorkloadVulnerabilities(t *testing.T) {
+	lister := &fakeImageLister{workloads: []k8s.WorkloadImages{
+		{Cluster: "prod", Namespace: "shop", Kind: "Deployment", Name: "web", Images: []string{"nginx:1.27", "envoy:1.30"}},
+		{Cluster: "prod", Namespace: "shop", Kind: "StatefulSet", Name: "db", Images: []string{"postgres:16", "nginx:1.27"}},
+	}}
+	h := &VulnerabilityHandler{k8sClient: lister, scanner: &fakeVulnScanner{
+		summaries: map[string]*vulnscan.Summary{
+			"nginx:1.27": {Image: "nginx:1.27", Critical: 1, High: 2},
+			"envoy:1.30": {Image: "envoy:1.30", Medium: 4},
+		},
+		errs: map[string]error{"postgres:16": errors.New("connection refused")},
+	}}
+
+	status, body := runVulnerabilityRequest(t, h, "/api/clusters/prod/vulnerabilities?namespace=shop")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "shop", lister.namespace)
+	assert.Equal(t, "fake", body["scanner"])
+
+	workloads := body["workloads"].([]interface{})
+	require.Len(t, workloads, 2)
+	web := workloads[0].(map[string]interface{})
+	assert.Equal(t, "web", web["name"])
+	assert.Equal(t, float64(1), web["critical"])
+	assert.Equal(t, float64(2), web["high"])
+	assert.Equal(t, float64(4), web["medium"])
+	assert.Empty(t, web["unscanned"])
+	db := workloads[1].(map[string]interface{})
+	assert.Equal(t, float64(1), db["critical"])
+	assert.Equal(t, []interface{}{"postgres:16"}, db["unscanned"])
+
+	assert.Len(t, body["images"], 2)
+	assert.Equal(t, map[string]interface{}{"postgres:16": "scan failed"}, body["errors"])
+
+	status, _ = runVulnerabilityRequest(t, h, "/api/clusters/prod/vulnerabilities?namespace=Not_Valid")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestGetImageVulnerabilities(t *testing.T) {
+	h := &VulnerabilityHandler{scanner: &fakeVulnScanner{
+		summaries: map[string]*vulnscan.Summary{"nginx:1.27": {Image: "nginx:1.27", High: 3}},
+		errs:      map[string]error{"broken:1": errors.New("timeout")},
+	}}
+
+	status, body := runVulnerabilityRequest(t, h, "/api/vulnerabilities/image?image=nginx:1.27")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(3), body["high"])
+
+	status, _ = runVulnerabilityRequest(t, h, "/api/vulnerabilities/image?image=missing:1")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = runVulnerabilityRequest(t, h, "/api/vulnerabilities/image?image=broken:1")
+	assert.Equal(t, http.StatusBadGateway, status)
+	status, _ = runVulnerabilityRequest(t, h, "/api/vulnerabilities/image?image=--help")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestVulnerabilityHandler_NoScanner(t *testing.T) {
+	h := NewVulnerabilityHandler(nil, nil)
+	status, body := runVulnerabilityRequest(t, h, "/api/vulnerabilities/image?image=nginx:1.27")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "vulnerability scanner not configured", body["error"])
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// MaxWorkloadSnapshotsPerWorkload caps stored snapshots per workload so a
+// scripted capture loop cannot grow the database without bound.
+const MaxWorkloadSnapshotsPerWorkload = 20
+
+// maxWorkloadSnapshotDescriptionLen bounds the free-text description.
+const maxWorkloadSnapshotDescriptionLen = 512
+
+// workloadSnapshotClient defines the narrow subset of k8s.MultiClusterClient
+// used by WorkloadSnapshotHandler.
+type workloadSnapshotClient interface {
+	SnapshotWorkload(ctx context.Context, contextName, kind, namespace, name string, redactSecrets bool) (*k8s.WorkloadSnapshot, error)
+	ApplyManifest(ctx context.Context, contextName string, manifest []byte, defaultNamespace string) (*k8s.ManifestApplyResult, error)
+}
+
+// WorkloadSnapshotHandler captures a workload and the ConfigMaps and Secrets
+// it references into a stored bundle, and re-applies a bundle on restore.
+// Capture and restore are editor/admin only; capturing unredacted Secret
+// values is admin only.
+type WorkloadSnapshotHandler struct {
+	k8sClient workloadSnapshotClient
+	store     store.Store
+}
+
+// NewWorkloadSnapshotHandler creates a workload snapshot handler.
+func NewWorkloadSnapshotHandler(k8sClient *k8s.MultiClusterClient, s store.Store) *WorkloadSnapshotHandler {
+	h := &WorkloadSnapshotHandler{store: s}
+	// Avoid storing a typed nil pointer in the interface.
+	if k8sClient != nil {
+		h.k8sClient = k8sClient
+	}
+	return h
+}
+
+// createWorkloadSnapshotRequest is the body accepted by CreateSnapshot.
+type createWorkloadSnapshotRequest struct {
+	Description string `json:"description,omitempty"`
+	// RedactSecrets defaults to true. Capturing Secret values requires admin.
+	RedactSecrets *bool `json:"redactSecrets,omitempty"`
+}
+
+// restoreWorkloadSnapshotResponse is the apply result plus the redacted
+// Secrets that were left untouched.
+type restoreWorkloadSnapshotResponse struct {
+	*k8s.ManifestApplyResult
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// workloadParams validates the cluster, kind, namespace and name path
+// parameters and returns the normalized kind.
+func workloadParams(c *fiber.Ctx) (cluster, kind, namespace, name string, err error) {
+	cluster, namespace, name = c.Params("cluster"), c.Params("ns"), c.Params("name")
+	if err = validateClusterName("cluster", cluster); err != nil {
+		return
+	}
+	if err = validateDNSLabel("namespace", namespace); err != nil {
+		return
+	}
+	if err = validateK8sName("name", name); err != nil {
+		return
+	}
+	kind, err = k8s.NormalizeSnapshotKind(c.Params("kind"))
+	return
+}
+
+// CreateSnapshot captures a workload and its referenced ConfigMaps and
+// Secrets. Secret values are redacted unless redactSecrets is false.
+// POST /api/clusters/:cluster/workloads/:kind/:ns/:name/snapshot
+func (h *WorkloadSnapshotHandler) CreateSnapshot(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	cluster, kind, namespace, name, err := workloadParams(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	var req createWorkloadSnapshotRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if len(req.Description) > maxWorkloadSnapshotDescriptionLen {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("description must be at most %d characters", maxWorkloadSnapshotDescriptionLen),
+		})
+	}
+	redact := req.RedactSecrets == nil || *req.RedactSecrets
+	if !redact {
+		if err := RequireAdmin(c, h.store); err != nil {
+			return err
+		}
+	}
+
+	ctx := c.UserContext()
+	count, err := h.store.CountWorkloadSnapshots(ctx, cluster, kind, namespace, name)
+	if err != nil {
+		slog.Error("[WorkloadSnapshot] failed to count snapshots", "cluster", cluster, "name", name, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create snapshot"})
+	}
+	if count >= MaxWorkloadSnapshotsPerWorkload {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": fmt.Sprintf("workload already has %d snapshots; delete one first", MaxWorkloadSnapshotsPerWorkload),
+		})
+	}
+
+	k8sCtx, cancel := context.WithTimeout(ctx, resourceExplorerTimeout)
+	defer cancel()
+	captured, err := h.k8sClient.SnapshotWorkload(k8sCtx, cluster, kind, namespace, name, redact)
+	if errors.Is(err, k8s.ErrWorkloadNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+
+	snap := &models.WorkloadSnapshot{
+		Cluster:     cluster,
+		Kind:        kind,
+		Namespace:   namespace,
+		Name:        name,
+		Description: req.Description,
+		Redacted:    captured.Redacted,
+		Contents:    snapshotContents(captured.Objects),
+		Missing:     captured.Missing,
+		Objects:     captured.Objects,
+		CreatedBy:   middleware.GetGitHubLogin(c),
+	}
+	if err := h.store.CreateWorkloadSnapshot(ctx, snap); err != nil {
+		slog.Error("[WorkloadSnapshot] failed to save snapshot", "cluster", cluster, "name", name, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create snapshot"})
+	}
+	audit.Log(c, audit.ActionSnapshotWorkload, "workload_snapshot", snap.ID.String(),
+		fmt.Sprintf("cluster=%s workload=%s/%s/%s redacted=%t", cluster, kind, namespace, name, redact))
+
+	snap.Objects = nil
+	return c.Status(fiber.StatusCreated).JSON(snap)
+}
+
+// ListSnapshots returns a workload's snapshots, newest first, without their
+// objects.
+// GET /api/clusters/:cluster/workloads/:kind/:ns/:name/snapshots
+func (h *WorkloadSnapshotHandler) ListSnapshots(c *fiber.Ctx) error {
+	cluster, kind, namespace, name, err := workloadParams(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	snaps, err := h.store.ListWorkloadSnapshots(c.UserContext(), cluster, kind, namespace, name, 0)
+	if err != nil {
+		slog.Error("[WorkloadSnapshot] failed to list snapshots", "cluster", cluster, "name", name, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list snapshots"})
+	}
+	return c.JSON(fiber.Map{"snapshots": snaps})
+}
+
+// GetSnapshot returns a snapshot with its objects. Secret values are always
+// masked in the response, even for unredacted snapshots.
+// GET /api/workload-snapshots/:id
+func (h *WorkloadSnapshotHandler) GetSnapshot(c *fiber.Ctx) error {
+	snap, err := h.loadSnapshot(c)
+	if err != nil || snap == nil {
+		return err
+	}
+	for _, obj := range snap.Objects {
+		k8s.RedactSnapshotSecret(obj)
+	}
+	return c.JSON(snap)
+}
+
+// RestoreSnapshot re-applies a snapshot to the cluster it was taken from.
+// Every object is dry-run first and nothing is written unless all pass; a
+// failed dry-run returns 422 with the per-object results. Redacted Secrets
+// are skipped so live values are not overwritten with placeholders.
+// POST /api/workload-snapshots/:id/restore
+func (h *WorkloadSnapshotHandler) RestoreSnapshot(c *fiber.Ctx) error {
+	if h.k8sClient == nil {
+		return ErrNoClusterAccess(c)
+	}
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	snap, err := h.loadSnapshot(c)
+	if err != nil || snap == nil {
+		return err
+	}
+
+	manifest, skipped, err := k8s.SnapshotRestoreManifest(snap.Objects)
+	if err != nil {
+		slog.Error("[WorkloadSnapshot] failed to build restore manifest", "id", snap.ID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to restore snapshot"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), resourceExplorerTimeout)
+	defer cancel()
+	result, err := h.k8sClient.ApplyManifest(ctx, snap.Cluster, manifest, snap.Namespace)
+	if errors.Is(err, k8s.ErrInvalidManifest) || errors.Is(err, k8s.ErrTooManyManifestDocuments) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return HandleK8sError(c, err)
+	}
+
+	resp := restoreWorkloadSnapshotResponse{ManifestApplyResult: result, Skipped: skipped}
+	if !result.Applied {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(resp)
+	}
+	audit.Log(c, audit.ActionRestoreWorkloadSnapshot, "workload_snapshot", snap.ID.String(),
+		fmt.Sprintf("cluster=%s objects=%d skipped=%d", snap.Cluster, len(result.Objects), len(skipped)))
+	return c.JSON(resp)
+}
+
+// DeleteSnapshot removes a stored snapshot. The cluster is not touched.
+// DELETE /api/workload-snapshots/:id
+func (h *WorkloadSnapshotHandler) DeleteSnapshot(c *fiber.Ctx) error {
+	if err := RequireEditorOrAdmin(c, h.store); err != nil {
+		return err
+	}
+	snap, err := h.loadSnapshot(c)
+	if err != nil || snap == nil {
+		return err
+	}
+	if err := h.store.DeleteWorkloadSnapshot(c.UserContext(), snap.ID); err != nil {
+		slog.Error("[WorkloadSnapshot] failed to delete snapshot", "id", snap.ID, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete snapshot"})
+	}
+	audit.Log(c, audit.ActionDeleteWorkloadSnapshot, "workload_snapshot", snap.ID.String())
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// loadSnapshot fetches the snapshot named by the :id parameter. When it
+// returns a nil snapshot the error response has already been written.
+func (h *WorkloadSnapshotHandler) loadSnapshot(c *fiber.Ctx) (*models.WorkloadSnapshot, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid snapshot ID"})
+	}
+	snap, err := h.store.GetWorkloadSnapshot(c.UserContext(), id)
+	if err != nil {
+		slog.Error("[WorkloadSnapshot] failed to load snapshot", "id", id, "error", err)
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load snapshot"})
+	}
+	if snap == nil {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Snapshot not found"})
+	}
+	return snap, nil
+}
+
+// snapshotContents lists the captured objects as "Kind/name".
+func snapshotContents(objects []map[string]interface{}) []string {
+	out := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		kind, _ := obj["kind"].(string)
+		name := ""
+		if meta, ok := obj["metadata"].(map[string]interface{}); ok {
+			name, _ = meta["name"].(string)
+		}
+		out = append(out, kind+"/"+name)
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/test"
+)
+
+type fakeSnapshotClient struct {
+	redact   bool
+	manifest string
+	applied  bool
+}
+
+func (f *fakeSnapshotClient) SnapshotWorkload(_ context.Context, contextName, kind, namespace, name string, redactSecrets bool) (*k8s.WorkloadSnapshot, error) {
+	f.redact = redactSecrets
+	if name == "missing" {
+		return nil, k8s.ErrWorkloadNotFound
+	}
+	secret := map[string]interface{}{
+		"apiVersion": "v1", "kind": "Secret",
+		"metadata": map[string]interface{}{"name": "db-creds", "namespace": namespace},
+		"data":     map[string]interface{}{"password": "c2VjcmV0"},
+	}
+	if redactSecrets {
+		k8s.RedactSnapshotSecret(secret)
+	}
+	return &k8s.WorkloadSnapshot{
+		Cluster: contextName, Kind: kind, Namespace: namespace, Name: name, Redacted: redactSecrets,
+		Objects: []map[string]interface{}{secret, {
+			"apiVersion": "apps/v1", "kind": kind,
+			"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+		}},
+	}, nil
+}
+
+func (f *fakeSnapshotClient) ApplyManifest(_ context.Context, contextName string, manifest []byte, _ string) (*k8s.ManifestApplyResult, error) {
+	f.manifest = string(manifest)
+	return &k8s.ManifestApplyResult{Cluster: contextName, Applied: f.applied}, nil
+}
+
+func setupWorkloadSnapshotTest(t *testing.T, role models.UserRole) (*fiber.App, *test.MockStore, *fakeSnapshotClient) {
+	t.Helper()
+	userID := uuid.New()
+	mockStore := new(test.MockStore)
+	mockStore.On("GetUser", userID).Return(&models.User{ID: userID, Role: role}, nil).Maybe()
+
+	client := &fakeSnapshotClient{applied: true}
+	h := &WorkloadSnapshotHandler{k8sClient: client, store: mockStore}
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Locals("githubLogin", "octocat")
+		return c.Next()
+	})
+	app.Post("/api/clusters/:cluster/workloads/:kind/:ns/:name/snapshot", h.CreateSnapshot)
+	app.Get("/api/workload-snapshots/:id", h.GetSnapshot)
+	app.Post("/api/workload-snapshots/:id/restore", h.RestoreSnapshot)
+	return app, mockStore, client
+}
+
+func doWorkloadSnapshotRequest(t *testing.T, app *fiber.App, method, path, body string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, 5000)
+	require.NoError(t, err)
+	var out map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func TestCreateWorkloadSnapshot(t *testing.T) {
+	app, mockStore, client := setupWorkloadSnapshotTest(t, models.UserRoleEditor)
+	mockStore.On("CountWorkloadSnapshots", "prod", "Deployment", "shop", "web").Return(0, nil)
+	var saved *models.WorkloadSnapshot
+	mockStore.On("CreateWorkloadSnapshot", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*models.WorkloadSnapshot)
+	}).Return(nil)
+
+	resp, out := doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/clusters/prod/workloads/deployments/shop/web/snapshot", `{"description":"before resize"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, out)
+	assert.True(t, client.redact, "secrets are redacted by default")
+	require.NotNil(t, saved)
+	assert.Equal(t, "Deployment", saved.Kind)
+	assert.Equal(t, "octocat", saved.CreatedBy)
+	assert.Equal(t, []string{"Secret/db-creds", "Deployment/web"}, saved.Contents)
+	assert.NotContains(t, out, "objects")
+}
+
+func TestCreateWorkloadSnapshot_Rejections(t *testing.T) {
+	app, mockStore, _ := setupWorkloadSnapshotTest(t, models.UserRoleEditor)
+	mockStore.On("CountWorkloadSnapshots", "prod", "Deployment", "shop", "full").Return(MaxWorkloadSnapshotsPerWorkload, nil)
+	mockStore.On("CountWorkloadSnapshots", "prod", "Deployment", "shop", "missing").Return(0, nil)
+
+	resp, _ := doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/clusters/prod/workloads/deployment/shop/web/snapshot", `{"redactSecrets":false}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "unredacted capture is admin only")
+
+	resp, _ = doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/clusters/prod/workloads/cronjob/shop/web/snapshot", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/clusters/prod/workloads/deployment/shop/full/snapshot", "")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, _ = doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/clusters/prod/workloads/deployment/shop/missing/snapshot", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestGetAndRestoreWorkloadSnapshot(t *testing.T) {
+	app, mockStore, client := setupWorkloadSnapshotTest(t, models.UserRoleEditor)
+	captured, err := client.SnapshotWorkload(context.Background(), "prod", "Deployment", "shop", "web", true)
+	require.NoError(t, err)
+	id := uuid.New()
+	load := func() *models.WorkloadSnapshot {
+		return &models.WorkloadSnapshot{ID: id, Cluster: "prod", Kind: "Deployment", Namespace: "shop", Name: "web", Redacted: true, Objects: captured.Objects}
+	}
+	mockStore.On("GetWorkloadSnapshot", id).Return(load(), nil).Once()
+	mockStore.On("GetWorkloadSnapshot", id).Return(load(), nil).Once()
+
+	resp, out := doWorkloadSnapshotRequest(t, app, http.MethodGet, "/api/workload-snapshots/"+id.String(), "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, mustJSON(t, out), "c2VjcmV0")
+
+	resp, out = doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/workload-snapshots/"+id.String()+"/restore", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode, out)
+	assert.Equal(t, []interface{}{"Secret/db-creds"}, out["skipped"])
+	assert.NotContains(t, client.manifest, "Secret")
+	assert.Contains(t, client.manifest, `"kind":"Deployment"`)
+
+	unknown := uuid.New()
+	mockStore.On("GetWorkloadSnapshot", unknown).Return(nil, nil)
+	resp, _ = doWorkloadSnapshotRequest(t, app, http.MethodPost, "/api/workload-snapshots/"+unknown.String()+"/restore", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -15,8 +15,8 @@ import (
 
 // ClusterFilter is a single condition on cluster metadata
 type ClusterFilter struct {
-	Field    string `json:"field"`    // healthy, distribution, cpuCores, memoryGB, gpuCount, nodeCount, podCount
-	Operator string `json:"operator"` // eq, neq, gt, gte, lt, lte, in
+	Field    string `json:"field"`    // healthy, cpuCores, memoryGB, gpuCount, gpuType, nodeCount, podCount, region, zone, provider, version
+	Operator string `json:"operator"` // eq, neq, gt, gte, lt, lte, regex
 	Value    string `json:"value"`
 }
 
@@ -129,6 +129,12 @@ func (h *WorkloadHandlers) deletePersistedClusterGroup(ctx context.Context, name
 // ListClusterGroups returns all cluster groups
 // GET /api/cluster-groups
 func (h *WorkloadHandlers) ListClusterGroups(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"groups": h.listClusterGroups(c.Context())})
+}
+
+// listClusterGroups returns the built-in "all healthy clusters" group
+// followed by every user-defined group.
+func (h *WorkloadHandlers) listClusterGroups(ctx context.Context) []ClusterGroup {
 	clusterGroupsMu.RLock()
 	groups := make([]ClusterGroup, 0, len(clusterGroups)+1)
 	for _, g := range clusterGroups {
@@ -147,7 +153,7 @@ func (h *WorkloadHandlers) ListClusterGroups(c *fiber.Ctx) error {
 		},
 	}
 	if h.k8sClient != nil {
-		ctx, cancel := context.WithTimeout(c.Context(), workloadListTimeout)
+		ctx, cancel := context.WithTimeout(ctx, workloadListTimeout)
 		defer cancel()
 		if healthyClusters, _, err := h.k8sClient.HealthyClusters(ctx); err == nil {
 			names := make([]string, 0, len(healthyClusters))
@@ -161,9 +167,7 @@ func (h *WorkloadHandlers) ListClusterGroups(c *fiber.Ctx) error {
 	if builtIn.Clusters == nil {
 		builtIn.Clusters = []string{}
 	}
-	groups = append([]ClusterGroup{builtIn}, groups...)
-
-	return c.JSON(fiber.Map{"groups": groups})
+	return append([]ClusterGroup{builtIn}, groups...)
 }
 
 // CreateClusterGroup creates a new cluster group and labels the member clusters
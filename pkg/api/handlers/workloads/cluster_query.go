@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -81,7 +82,7 @@ func (h *WorkloadHandlers) EvaluateClusterQuery(c *fiber.Ctx) error {
 
 	// Fetch nodes in parallel using errgroup instead of sequentially (#7012).
 	nodesByCluster := make(map[string][]k8s.NodeInfo)
-	needNodes := query.LabelSelector != "" || hasGPUFilter(query.Filters)
+	needNodes := query.LabelSelector != "" || filtersNeedNodes(query.Filters)
 	if needNodes {
 		var nodesMu sync.Mutex
 		g, gctx := errgroup.WithContext(ctx)
@@ -161,7 +162,7 @@ func clusterMatchesFilter(health k8s.ClusterHealth, nodes []k8s.NodeInfo, f Clus
 	switch f.Field {
 	case "healthy":
 		return compareBool(health.Healthy, f.Operator, f.Value)
-	case "cpuCores":
+	case "cpuCores", "cpuCount":
 		return compareInt(int64(health.CpuCores), f.Operator, f.Value)
 	case "memoryGB":
 		return compareFloat(health.MemoryGB, f.Operator, f.Value)
@@ -177,15 +178,43 @@ func clusterMatchesFilter(health k8s.ClusterHealth, nodes []k8s.NodeInfo, f Clus
 	case "gpuType":
 		types := clusterGPUTypes(nodes)
 		return compareStringSet(types, f.Operator, f.Value)
+	case "region":
+		return compareTopology(k8s.ClusterRegions(nodes), f.Operator, f.Value)
+	case "zone":
+		return compareTopology(k8s.ClusterZones(nodes), f.Operator, f.Value)
+	case "provider":
+		provider := k8s.ClusterProvider(health.APIServer, nodes)
+		if provider == "" {
+			return false
+		}
+		return compareStringSet([]string{provider}, f.Operator, f.Value)
+	case "version":
+		version := k8s.ClusterVersion(nodes)
+		if version == "" {
+			return false
+		}
+		return compareVersion(version, f.Operator, f.Value)
 	default:
-		return true // unknown fields pass (don't block)
+		// Unknown fields fail so a typo does not silently select every cluster.
+		return false
+	}
+}
+
+// compareTopology is compareStringSet for regions or zones, except that a
+// cluster whose nodes carry no topology labels matches nothing: its
+// location is unknown, not outside the excluded one.
+func compareTopology(values []string, op, value string) bool {
+	if len(values) == 0 {
+		return false
 	}
+	return compareStringSet(values, op, value)
 }
 
-// hasGPUFilter returns true if any filter references GPU fields
-func hasGPUFilter(filters []ClusterFilter) bool {
+// filtersNeedNodes returns true if any filter is computed from node data
+func filtersNeedNodes(filters []ClusterFilter) bool {
 	for _, f := range filters {
-		if f.Field == "gpuCount" || f.Field == "gpuType" {
+		switch f.Field {
+		case "gpuCount", "gpuType", "region", "zone", "provider", "version":
 			return true
 		}
 	}
@@ -234,6 +263,46 @@ func compareStringSet(actual []string, op, value string) bool {
 			}
 		}
 		return true
+	case "regex":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return false
+		}
+		for _, s := range actual {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// compareVersion compares a kubelet version against a version such as 1.29
+// on the components the value gives, so "eq 1.29" matches 1.29.4 and
+// "gt 1.29" starts at 1.30. contains and regex match the raw string.
+func compareVersion(actual, op, value string) bool {
+	if op == "contains" || op == "regex" {
+		return compareStringSet([]string{actual}, op, value)
+	}
+	cmp, err := k8s.CompareVersionPrefix(actual, value)
+	if err != nil {
+		return false
+	}
+	switch op {
+	case "eq":
+		return cmp == 0
+	case "neq":
+		return cmp != 0
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
 	default:
 		return false
 	}
@@ -356,9 +425,13 @@ Available filter fields and their types:
 - gpuType (string) — GPU product type (e.g., "NVIDIA-A100-SXM4-80GB", "AMD GPU"). Use eq for substring match, neq to exclude.
 - nodeCount (int) — number of nodes
 - podCount (int) — number of running pods
+- region (string) — node region from topology.kubernetes.io/region (e.g., "us-east-1")
+- zone (string) — node availability zone from topology.kubernetes.io/zone (e.g., "us-east-1a")
+- provider (string) — one of eks, gke, aks, openshift, oci, alibaba, digitalocean, coreweave, minikube, k3s, kubernetes
+- version (version) — lowest kubelet version (e.g., "1.29"); compared on the components given, so eq 1.29 matches any 1.29 patch
 
-Operators for numeric/bool: eq, neq, gt, gte, lt, lte
-Operators for string: eq (contains/matches), neq (excludes)
+Operators for numeric/bool/version: eq, neq, gt, gte, lt, lte
+Operators for string: eq (contains/matches), neq (excludes), regex (Go regular expression)
 
 Label selectors use standard Kubernetes syntax (e.g., "topology.kubernetes.io/zone in (us-east-1a,us-east-1b)").
 
@@ -196,6 +196,9 @@ func TestCompareStringSet(t *testing.T) {
 		{"excludes_present", gpuTypes, "excludes", "A100", false},
 		{"empty_set_eq", []string{}, "eq", "A100", false},
 		{"empty_set_neq", []string{}, "neq", "A100", true},
+		{"regex_match", gpuTypes, "regex", "^NVIDIA-A100", true},
+		{"regex_no_match", gpuTypes, "regex", "^Intel", false},
+		{"regex_invalid", gpuTypes, "regex", "A100(", false},
 		{"unknown_op", gpuTypes, "like", "A100", false},
 	}
 	for _, tt := range tests {
@@ -250,8 +253,8 @@ func TestClusterGPUTypes(t *testing.T) {
 	}
 }
 
-// TestHasGPUFilter verifies GPU filter detection.
-func TestHasGPUFilter(t *testing.T) {
+// TestFiltersNeedNodes verifies detection of filters computed from node data.
+func TestFiltersNeedNodes(t *testing.T) {
 	tests := []struct {
 		name    string
 		filters []ClusterFilter
@@ -262,15 +265,49 @@ func TestHasGPUFilter(t *testing.T) {
 		{"gpuCount", []ClusterFilter{{Field: "gpuCount"}}, true},
 		{"gpuType", []ClusterFilter{{Field: "gpuType"}}, true},
 		{"mixed", []ClusterFilter{{Field: "cpuCores"}, {Field: "gpuCount"}}, true},
+		{"region", []ClusterFilter{{Field: "region"}}, true},
+		{"zone", []ClusterFilter{{Field: "zone"}}, true},
+		{"provider", []ClusterFilter{{Field: "provider"}}, true},
+		{"version", []ClusterFilter{{Field: "version"}}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := hasGPUFilter(tt.filters)
+			got := filtersNeedNodes(tt.filters)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
 
+// TestCompareVersion verifies kubelet versions compare on the components
+// the filter value gives.
+func TestCompareVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		actual string
+		op     string
+		value  string
+		want   bool
+	}{
+		{"eq_minor", "v1.29.4-eks-036c24b", "eq", "1.29", true},
+		{"eq_patch", "v1.29.4", "eq", "1.29.3", false},
+		{"neq_minor", "v1.29.4", "neq", "1.30", true},
+		{"gt_same_minor", "v1.29.4", "gt", "1.29", false},
+		{"gt_patch", "v1.29.4", "gt", "1.29.3", true},
+		{"gte_minor", "v1.29.4", "gte", "1.29", true},
+		{"lt_minor", "v1.29.4", "lt", "1.30", true},
+		{"lte_older", "v1.31.0", "lte", "1.30", false},
+		{"contains", "v1.29.4+k3s1", "contains", "k3s", true},
+		{"regex", "v1.29.4-gke.1043000", "regex", `^v1\.29\.`, true},
+		{"invalid_value", "v1.29.4", "gt", "newest", false},
+		{"unknown_op", "v1.29.4", "like", "1.29", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersion(tt.actual, tt.op, tt.value))
+		})
+	}
+}
+
 // TestGenerateClusterQuery_AIRateLimiter verifies that the AI rate limiter
 // returns 429 after 20 requests/minute and sets the Retry-After header (#17294 / #17296).
 func TestGenerateClusterQuery_AIRateLimiter(t *testing.T) {
@@ -25,7 +25,7 @@ func demoResponse(c *fiber.Ctx, key string, data interface{}) error {
 }
 
 func handleK8sError(c *fiber.Ctx, err error) error {
-	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 }
 
 func getDemoWorkloads() []v1alpha1.Workload {
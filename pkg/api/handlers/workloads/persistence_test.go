@@ -280,8 +280,38 @@ func TestClusterMatchesFilter_UnknownField(t *testing.T) {
 	health := makeClusterHealth(true, true, 4, 16.0, 3, 100)
 	var nodes []k8s.NodeInfo
 
-	// Unknown fields should pass (not block)
-	assert.True(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "unknownField", Operator: "eq", Value: "anything"}))
+	// Unknown fields fail so a typo does not select every cluster
+	assert.False(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "unknownField", Operator: "eq", Value: "anything"}))
+}
+
+func TestClusterMatchesFilter_CPUCountAlias(t *testing.T) {
+	health := makeClusterHealth(true, true, 4, 16.0, 3, 100)
+
+	assert.True(t, clusterMatchesFilter(health, nil, ClusterFilter{Field: "cpuCount", Operator: "eq", Value: "4"}))
+	assert.False(t, clusterMatchesFilter(health, nil, ClusterFilter{Field: "cpuCount", Operator: "gt", Value: "4"}))
+}
+
+func TestClusterMatchesFilter_TopologyFields(t *testing.T) {
+	health := makeClusterHealth(true, true, 4, 16.0, 3, 100)
+	health.APIServer = "https://aks-dev-dns-abc123.hcp.westeurope.azmk8s.io:443"
+	nodes := []k8s.NodeInfo{
+		{Name: "n1", KubeletVersion: "v1.30.2", Labels: map[string]string{
+			"topology.kubernetes.io/region": "westeurope",
+			"topology.kubernetes.io/zone":   "westeurope-1",
+		}},
+	}
+
+	assert.True(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "region", Operator: "eq", Value: "westeurope"}))
+	assert.False(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "region", Operator: "eq", Value: "eastus"}))
+	assert.True(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "zone", Operator: "regex", Value: "-[12]$"}))
+	assert.True(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "provider", Operator: "eq", Value: "aks"}))
+	assert.False(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "provider", Operator: "eq", Value: "eks"}))
+	assert.True(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "version", Operator: "gte", Value: "1.29"}))
+	assert.False(t, clusterMatchesFilter(health, nodes, ClusterFilter{Field: "version", Operator: "lt", Value: "1.30"}))
+
+	// Without node data the node-backed fields do not match
+	assert.False(t, clusterMatchesFilter(health, nil, ClusterFilter{Field: "region", Operator: "neq", Value: "eastus"}))
+	assert.False(t, clusterMatchesFilter(health, nil, ClusterFilter{Field: "version", Operator: "gte", Value: "1.0"}))
 }
 
 func TestClusterMatchesFilter_NodeCountField(t *testing.T) {
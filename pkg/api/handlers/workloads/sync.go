@@ -0,0 +1,70 @@
+package workloads
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/api/transport"
+)
+
+// Kinds served to WebSocket "sync" requests, so a freshly connected client
+// can load its initial state in pages over the socket instead of issuing one
+// REST call per view.
+const (
+	SyncKindWorkloads = "workloads"
+	SyncKindGroups    = "groups"
+	SyncKindClusters  = "clusters"
+)
+
+// errSyncNoClusterAccess is reported for cluster-backed kinds when the
+// backend has no kubeconfig.
+var errSyncNoClusterAccess = errors.New(noClusterAccessMsg)
+
+// RegisterSyncSources registers the workload, cluster-group and cluster
+// snapshots with the hub. Each returns the same items as the matching REST
+// list endpoint.
+func (h *WorkloadHandlers) RegisterSyncSources(hub *transport.Hub) {
+	hub.RegisterSyncSource(SyncKindWorkloads, h.syncWorkloads)
+	hub.RegisterSyncSource(SyncKindGroups, h.syncGroups)
+	hub.RegisterSyncSource(SyncKindClusters, h.syncClusters)
+}
+
+func (h *WorkloadHandlers) syncWorkloads(ctx context.Context, _ uuid.UUID) ([]any, error) {
+	if h.k8sClient == nil {
+		return nil, errSyncNoClusterAccess
+	}
+	list, err := h.k8sClient.ListWorkloads(ctx, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	items := make([]any, 0, len(list.Items))
+	for _, w := range list.Items {
+		items = append(items, w)
+	}
+	return items, nil
+}
+
+func (h *WorkloadHandlers) syncGroups(ctx context.Context, _ uuid.UUID) ([]any, error) {
+	groups := h.listClusterGroups(ctx)
+	items := make([]any, 0, len(groups))
+	for _, g := range groups {
+		items = append(items, g)
+	}
+	return items, nil
+}
+
+func (h *WorkloadHandlers) syncClusters(ctx context.Context, _ uuid.UUID) ([]any, error) {
+	if h.k8sClient == nil {
+		return nil, errSyncNoClusterAccess
+	}
+	clusters, err := h.k8sClient.DeduplicatedClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]any, 0, len(clusters))
+	for _, cl := range clusters {
+		items = append(items, cl)
+	}
+	return items, nil
+}
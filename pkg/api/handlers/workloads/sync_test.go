@@ -0,0 +1,52 @@
+package workloads
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncSources(t *testing.T) {
+	env := setupTestEnv(t)
+	h := NewWorkloadHandlers(env.K8sClient, env.Hub, env.Store)
+	h.RegisterSyncSources(env.Hub)
+	ctx := context.Background()
+
+	clusterGroupsMu.Lock()
+	clusterGroups["sync-test"] = ClusterGroup{Name: "sync-test", Kind: "static", Clusters: []string{"test-cluster"}}
+	clusterGroupsMu.Unlock()
+	t.Cleanup(func() {
+		clusterGroupsMu.Lock()
+		delete(clusterGroups, "sync-test")
+		clusterGroupsMu.Unlock()
+	})
+
+	groups, err := h.syncGroups(ctx, uuid.New())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(groups), 2)
+	assert.Equal(t, allHealthyClustersGroupName, groups[0].(ClusterGroup).Name, "built-in group comes first, as in the REST list")
+	assert.Contains(t, groups, any(ClusterGroup{Name: "sync-test", Kind: "static", Clusters: []string{"test-cluster"}}))
+
+	clusters, err := h.syncClusters(ctx, uuid.New())
+	require.NoError(t, err)
+	require.NotEmpty(t, clusters)
+	assert.IsType(t, k8s.ClusterInfo{}, clusters[0])
+}
+
+func TestSyncSources_NoClusterAccess(t *testing.T) {
+	h := NewWorkloadHandlers(nil, nil, nil)
+	ctx := context.Background()
+
+	_, err := h.syncWorkloads(ctx, uuid.New())
+	assert.ErrorIs(t, err, errSyncNoClusterAccess)
+	_, err = h.syncClusters(ctx, uuid.New())
+	assert.ErrorIs(t, err, errSyncNoClusterAccess)
+
+	groups, err := h.syncGroups(ctx, uuid.New())
+	require.NoError(t, err, "groups are served from memory")
+	assert.NotEmpty(t, groups)
+}
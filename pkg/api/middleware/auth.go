@@ -45,6 +45,7 @@ type UserClaims struct {
 	UserID      uuid.UUID       `json:"user_id"`
 	GitHubLogin string          `json:"github_login"`
 	Role        models.UserRole `json:"role"`
+	SessionID   string          `json:"sid,omitempty"` // server-side session; see ValidateSession
 	jwt.RegisteredClaims
 }
 
@@ -697,9 +698,24 @@ func JWTAuth(secret string, agentToken ...string) fiber.Handler {
 			}
 		}
 
+		if err := ValidateSession(c.UserContext(), claims, c.IP()); err != nil {
+			switch {
+			case errors.Is(err, ErrSessionRevoked), errors.Is(err, ErrSessionExpired):
+				slog.Info("[Auth] rejected token for inactive session", "path", c.Path(), "userID", claims.UserID, "error", err)
+				audit.Log(c, audit.ActionAuthFailed, "endpoint", c.Path(), "inactive_session")
+				return fiber.NewError(fiber.StatusUnauthorized, "Session expired")
+			default:
+				slog.Error("[Auth] session check failed, failing closed", "path", c.Path(), "userID", claims.UserID, "error", err)
+				return fiber.NewError(fiber.StatusServiceUnavailable, "Authentication temporarily unavailable")
+			}
+		}
+
 		// Store user info in context
 		c.Locals("userID", claims.UserID)
 		c.Locals("githubLogin", claims.GitHubLogin)
+		if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+			c.Locals("sessionID", sessionID)
+		}
 
 		// Signal the client to silently refresh its token when more than half
 		// the JWT lifetime has elapsed. Derive the lifetime from the token's own
@@ -858,5 +874,9 @@ func ValidateJWT(tokenString, secret string) (*UserClaims, error) {
 		return nil, fmt.Errorf("user validation failed: %w", err)
 	}
 
+	if err := ValidateSession(context.Background(), claims, ""); err != nil {
+		return nil, fmt.Errorf("session validation failed: %w", err)
+	}
+
 	return claims, nil
 }
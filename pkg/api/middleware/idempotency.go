@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/idempotency"
+)
+
+// Idempotency returns a Fiber middleware that makes a create endpoint safe
+// to retry. A request carrying an Idempotency-Key header runs once per user
+// and key; a retry with the same key and body gets the first response back
+// with Idempotent-Replayed: true. Requests without the header are passed
+// through unchanged.
+//
+// Only responses below 500 are kept, so a retry after a server error runs
+// the request again. A key reused with a different body gets 422, and a
+// retry while the first request is still running gets 409.
+func Idempotency(cache *idempotency.Cache) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(idempotency.Header)
+		if key == "" {
+			return c.Next()
+		}
+		if !idempotency.ValidKey(key) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid Idempotency-Key header"})
+		}
+
+		// Scope the key to the caller and endpoint so one user's key cannot
+		// replay another's response.
+		scoped := GetUserID(c).String() + " " + c.Method() + " " + c.Path() + " " + key
+		stored, err := cache.Begin(scoped, idempotency.Fingerprint(c.Body()))
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, idempotency.ErrInProgress):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		case stored != nil:
+			c.Set(idempotency.ReplayedHeader, "true")
+			if stored.Location != "" {
+				c.Location(stored.Location)
+			}
+			if stored.ContentType != "" {
+				c.Set(fiber.HeaderContentType, stored.ContentType)
+			}
+			return c.Status(stored.Status).Send(stored.Body)
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				cache.Release(scoped)
+			}
+		}()
+		// An error is rendered by the app's error handler after this
+		// middleware returns, so its response is not kept.
+		if err := c.Next(); err != nil {
+			return err
+		}
+		resp := c.Response()
+		if resp.StatusCode() >= fiber.StatusInternalServerError {
+			return nil
+		}
+		cache.Complete(scoped, idempotency.Response{
+			Status:      resp.StatusCode(),
+			ContentType: string(resp.Header.ContentType()),
+			Location:    string(resp.Header.Peek(fiber.HeaderLocation)),
+			Body:        resp.Body(),
+		})
+		completed = true
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/idempotency"
+)
+
+// newIdempotencyTestApp serves POST /items as user, creating an item with a
+// new ID per call. status is the status the handler responds with.
+func newIdempotencyTestApp(user uuid.UUID, status *int) (*fiber.App, *int) {
+	created := 0
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", user)
+		return c.Next()
+	})
+	app.Post("/items", middleware.Idempotency(idempotency.NewCache(0)), func(c *fiber.Ctx) error {
+		created++
+		c.Location(fmt.Sprintf("/items/%d", created))
+		return c.Status(*status).JSON(fiber.Map{"id": created})
+	})
+	return app, &created
+}
+
+func postItem(t *testing.T, app *fiber.App, key, body string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotency.Header, key)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	return resp, string(b)
+}
+
+func TestIdempotency_ReplaysCreate(t *testing.T) {
+	t.Parallel()
+	status := http.StatusCreated
+	app, created := newIdempotencyTestApp(uuid.New(), &status)
+
+	first, firstBody := postItem(t, app, "key-1", `{"name":"a"}`)
+	retry, retryBody := postItem(t, app, "key-1", `{"name":"a"}`)
+	if *created != 1 {
+		t.Fatalf("handler ran %d times, want 1", *created)
+	}
+	if retry.StatusCode != http.StatusCreated || retryBody != firstBody {
+		t.Errorf("retry got %d %s, want %d %s", retry.StatusCode, retryBody, first.StatusCode, firstBody)
+	}
+	if retry.Header.Get("Location") != "/items/1" || retry.Header.Get(idempotency.ReplayedHeader) != "true" {
+		t.Errorf("retry headers = %v", retry.Header)
+	}
+	if first.Header.Get(idempotency.ReplayedHeader) != "" {
+		t.Error("first response must not be marked replayed")
+	}
+
+	if resp, _ := postItem(t, app, "key-1", `{"name":"b"}`); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with another body got %d, want 422", resp.StatusCode)
+	}
+	postItem(t, app, "key-2", `{"name":"a"}`)
+	postItem(t, app, "", `{"name":"a"}`)
+	if *created != 3 {
+		t.Errorf("handler ran %d times, want 3", *created)
+	}
+}
+
+func TestIdempotency_RetriesServerErrors(t *testing.T) {
+	t.Parallel()
+	status := http.StatusServiceUnavailable
+	app, created := newIdempotencyTestApp(uuid.New(), &status)
+
+	postItem(t, app, "key-1", `{}`)
+	status = http.StatusCreated
+	if resp, _ := postItem(t, app, "key-1", `{}`); resp.StatusCode != http.StatusCreated {
+		t.Errorf("retry after 503 got %d, want 201", resp.StatusCode)
+	}
+	if *created != 2 {
+		t.Errorf("handler ran %d times, want 2", *created)
+	}
+}
+
+func TestIdempotency_InvalidKey(t *testing.T) {
+	t.Parallel()
+	status := http.StatusCreated
+	app, created := newIdempotencyTestApp(uuid.New(), &status)
+	if resp, _ := postItem(t, app, strings.Repeat("k", idempotency.MaxKeyLength+1), `{}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %d, want 400", resp.StatusCode)
+	}
+	if *created != 0 {
+		t.Errorf("handler ran %d times, want 0", *created)
+	}
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsOtherRoute labels requests that no registered route served:
+// static assets (served by app.Use) and 404s. Using the raw path would
+// give every probe URL its own series.
+const metricsOtherRoute = "other"
+
+// HTTPMetrics exports per-route request counts, latencies, response sizes
+// and in-flight requests for the API server.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	sizes    *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+
+	// routesMu guards the set of non-middleware routes ("GET /api/x"),
+	// rebuilt whenever the app's handler count changes.
+	routesMu      sync.RWMutex
+	routes        map[string]bool
+	routesVersion uint32
+}
+
+// NewHTTPMetrics registers the HTTP metrics with reg. When they are already
+// registered (several servers in one process, e.g. in tests) the existing
+// collectors are reused.
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: RegisterOrExisting(reg, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kc_http_requests_total",
+				Help: "Total HTTP requests handled by the API server",
+			},
+			[]string{"method", "route", "status_class"},
+		)),
+		duration: RegisterOrExisting(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kc_http_request_duration_seconds",
+				Help:    "Duration of HTTP requests handled by the API server",
+				Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"method", "route", "status_class"},
+		)),
+		sizes: RegisterOrExisting(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "kc_http_response_size_bytes",
+				Help:    "Size of HTTP response bodies written by the API server",
+				Buckets: prometheus.ExponentialBuckets(256, 4, 8), // 256 B … 4 MB
+			},
+			[]string{"method", "route"},
+		)),
+		inFlight: RegisterOrExisting(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "kc_http_requests_in_flight",
+				Help: "HTTP requests currently being handled by the API server",
+			},
+		)),
+	}
+}
+
+// RegisterOrExisting registers c with reg, or returns the collector already
+// registered under the same descriptors.
+func RegisterOrExisting[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// Handler returns middleware recording every request. Routes are labeled by
+// their registered pattern (/api/dashboards/:id), never the raw path.
+func (m *HTTPMetrics) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		m.inFlight.Inc()
+		start := time.Now()
+		err := c.Next()
+		m.inFlight.Dec()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The error handler has not set the status yet.
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+		}
+		route := metricsOtherRoute
+		if r := c.Route(); r != nil && m.isRoute(c.App(), r) {
+			route = r.Path
+		}
+		method := c.Method()
+		class := strconv.Itoa(status/100) + "xx"
+
+		m.requests.WithLabelValues(method, route, class).Inc()
+		m.duration.WithLabelValues(method, route, class).Observe(time.Since(start).Seconds())
+		m.sizes.WithLabelValues(method, route).Observe(float64(len(c.Response().Body())))
+		return err
+	}
+}
+
+// isRoute reports whether r is a registered route rather than an app.Use
+// middleware, which is what c.Route() still points at when nothing matched.
+func (m *HTTPMetrics) isRoute(app *fiber.App, r *fiber.Route) bool {
+	key := r.Method + " " + r.Path
+	version := app.HandlersCount()
+	m.routesMu.RLock()
+	if m.routes != nil && m.routesVersion == version {
+		ok := m.routes[key]
+		m.routesMu.RUnlock()
+		return ok
+	}
+	m.routesMu.RUnlock()
+
+	routes := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		routes[route.Method+" "+route.Path] = true
+	}
+	m.routesMu.Lock()
+	m.routes, m.routesVersion = routes, version
+	m.routesMu.Unlock()
+	return routes[key]
+}
+
+// MetricsHandler serves the default Prometheus registry. When token is set,
+// scrapes must send "Authorization: Bearer <token>".
+func MetricsHandler(token string) fiber.Handler {
+	serve := adaptor.HTTPHandler(promhttp.Handler())
+	return func(c *fiber.Ctx) error {
+		if token != "" {
+			got := c.Get(fiber.HeaderAuthorization)
+			want := "Bearer " + token
+			if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+				return fiber.NewError(fiber.StatusUnauthorized, "Invalid metrics token")
+			}
+		}
+		return serve(c)
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetrics_LabelsByRoutePattern(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTPMetrics(reg)
+
+	app := fiber.New()
+	app.Use(m.Handler())
+	app.Get("/api/dashboards/:id", func(c *fiber.Ctx) error {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.inFlight), "the request is in flight while handled")
+		return c.SendString("ok")
+	})
+	app.Get("/api/fail", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusBadGateway, "upstream")
+	})
+
+	for _, path := range []string{"/api/dashboards/a", "/api/dashboards/b", "/api/fail", "/nope"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/api/dashboards/:id", "2xx")),
+		"paths sharing a route pattern share a series")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", "/api/fail", "5xx")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("GET", metricsOtherRoute, "4xx")),
+		"unmatched paths do not create their own series")
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.inFlight))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.duration))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.sizes))
+
+	// A second server in the same process reuses the registered collectors.
+	assert.Same(t, m.requests, NewHTTPMetrics(reg).requests)
+}
+
+func TestMetricsHandler_Token(t *testing.T) {
+	app := fiber.New()
+	app.Get("/metrics", MetricsHandler("scrape-secret"))
+
+	for name, tc := range map[string]struct {
+		auth   string
+		status int
+	}{
+		"missing": {"", fiber.StatusUnauthorized},
+		"wrong":   {"Bearer nope", fiber.StatusUnauthorized},
+		"valid":   {"Bearer scrape-secret", fiber.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, tc.status, resp.StatusCode, name)
+	}
+}
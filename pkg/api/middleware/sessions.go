@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+const (
+	// DefaultSessionIdleTimeout signs a device out after this long without
+	// an authenticated request.
+	DefaultSessionIdleTimeout = 24 * time.Hour
+
+	// DefaultSessionMaxLifetime caps how long a session can be kept alive by
+	// refreshing its token before the user has to log in again.
+	DefaultSessionMaxLifetime = 30 * 24 * time.Hour
+
+	// sessionCacheTTL is how long a looked-up session is trusted before the
+	// store is consulted again. Revocations on this instance evict the entry
+	// immediately; other instances see them within the TTL.
+	sessionCacheTTL = 30 * time.Second
+
+	// sessionTouchInterval throttles last-seen writes so a busy dashboard
+	// does not update the session row on every request.
+	sessionTouchInterval = 1 * time.Minute
+)
+
+// SessionPolicy holds the idle and absolute session timeouts. A zero value
+// disables that timeout.
+type SessionPolicy struct {
+	IdleTimeout time.Duration
+	MaxLifetime time.Duration
+}
+
+// SessionTracker is the subset of store.Store needed for session checks.
+// Defined here to avoid a circular import with the store package.
+type SessionTracker interface {
+	GetSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error)
+	TouchSession(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+}
+
+type sessionCacheEntry struct {
+	session   models.UserSession
+	checkedAt time.Time
+}
+
+var (
+	sessionStoreMu sync.RWMutex
+	sessionStore   SessionTracker
+	sessionPolicy  = SessionPolicy{IdleTimeout: DefaultSessionIdleTimeout, MaxLifetime: DefaultSessionMaxLifetime}
+	sessionCache   sync.Map // session ID string -> sessionCacheEntry
+)
+
+var (
+	// ErrSessionRevoked is returned for tokens whose session was revoked
+	// (logout, "sign out other devices", or an admin) or no longer exists.
+	ErrSessionRevoked = errors.New("session has been revoked")
+	// ErrSessionExpired is returned for tokens whose session passed its idle
+	// or absolute timeout.
+	ErrSessionExpired = errors.New("session has expired")
+
+	errSessionCheckFailed = errors.New("session check failed")
+)
+
+// InitSessionTracking enables server-side session checks for tokens that
+// carry a sid claim. Tokens issued before sessions existed carry none and are
+// accepted until they expire.
+func InitSessionTracking(store SessionTracker, policy SessionPolicy) {
+	sessionStoreMu.Lock()
+	sessionStore = store
+	sessionPolicy = policy
+	sessionStoreMu.Unlock()
+	// Clear in place: readers use sessionCache without holding the lock.
+	sessionCache.Range(func(key, _ interface{}) bool {
+		sessionCache.Delete(key)
+		return true
+	})
+}
+
+// CurrentSessionPolicy returns the timeouts configured by InitSessionTracking.
+func CurrentSessionPolicy() SessionPolicy {
+	sessionStoreMu.RLock()
+	defer sessionStoreMu.RUnlock()
+	return sessionPolicy
+}
+
+func getSessionStore() SessionTracker {
+	sessionStoreMu.RLock()
+	defer sessionStoreMu.RUnlock()
+	return sessionStore
+}
+
+func resetSessionTrackingForTest() {
+	InitSessionTracking(nil, SessionPolicy{IdleTimeout: DefaultSessionIdleTimeout, MaxLifetime: DefaultSessionMaxLifetime})
+}
+
+// ForgetSession drops a session from the lookup cache so a revocation takes
+// effect on the next request instead of after sessionCacheTTL.
+func ForgetSession(id uuid.UUID) {
+	sessionCache.Delete(id.String())
+}
+
+// SessionActive reports whether session may still be used at now under policy.
+func SessionActive(session *models.UserSession, policy SessionPolicy, now time.Time) bool {
+	return sessionState(session, policy, now) == nil
+}
+
+func sessionState(session *models.UserSession, policy SessionPolicy, now time.Time) error {
+	if session == nil || session.RevokedAt != nil {
+		return ErrSessionRevoked
+	}
+	if !session.ExpiresAt.IsZero() && now.After(session.ExpiresAt) {
+		return ErrSessionExpired
+	}
+	if policy.IdleTimeout > 0 && now.Sub(session.LastSeenAt) > policy.IdleTimeout {
+		return ErrSessionExpired
+	}
+	return nil
+}
+
+// ValidateSession checks that the session named by the token's sid claim is
+// still active and records the activity. ip is stored as the session's last
+// address; pass "" to keep the stored one. It returns ErrSessionRevoked or
+// ErrSessionExpired for tokens that must be rejected, and any other error
+// when the store could not be consulted (callers fail closed).
+func ValidateSession(ctx context.Context, claims *UserClaims, ip string) error {
+	store := getSessionStore()
+	if store == nil || claims == nil || claims.SessionID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return ErrSessionRevoked
+	}
+
+	now := time.Now()
+	var session *models.UserSession
+	checkedAt := now
+	if cached, ok := sessionCache.Load(id.String()); ok {
+		if entry, ok := cached.(sessionCacheEntry); ok && now.Sub(entry.checkedAt) <= sessionCacheTTL {
+			session = &entry.session
+			checkedAt = entry.checkedAt
+		}
+	}
+	if session == nil {
+		session, err = store.GetSession(ctx, id)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errSessionCheckFailed, err)
+		}
+		if session == nil {
+			sessionCache.Delete(id.String())
+			return ErrSessionRevoked
+		}
+	}
+
+	if session.UserID != claims.UserID {
+		return ErrSessionRevoked
+	}
+	if err := sessionState(session, CurrentSessionPolicy(), now); err != nil {
+		sessionCache.Delete(id.String())
+		return err
+	}
+
+	entry := sessionCacheEntry{session: *session, checkedAt: checkedAt}
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval || (ip != "" && ip != session.IP) {
+		if err := store.TouchSession(ctx, id, ip, now); err != nil {
+			return fmt.Errorf("%w: %w", errSessionCheckFailed, err)
+		}
+		entry.session.LastSeenAt = now
+		if ip != "" {
+			entry.session.IP = ip
+		}
+	}
+	sessionCache.Store(id.String(), entry)
+	return nil
+}
+
+// GetSessionID returns the session of the authenticated request, or uuid.Nil
+// for tokens without one.
+func GetSessionID(c *fiber.Ctx) uuid.UUID {
+	id, ok := c.Locals("sessionID").(uuid.UUID)
+	if !ok {
+		return uuid.Nil
+	}
+	return id
+}
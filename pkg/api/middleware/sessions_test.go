@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionTestStore struct {
+	sessions map[uuid.UUID]*models.UserSession
+	err      error
+	gets     int
+	touches  int
+}
+
+func (s *sessionTestStore) GetSession(_ context.Context, id uuid.UUID) (*models.UserSession, error) {
+	s.gets++
+	if s.err != nil {
+		return nil, s.err
+	}
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *sessionTestStore) TouchSession(_ context.Context, id uuid.UUID, ip string, at time.Time) error {
+	s.touches++
+	if session, ok := s.sessions[id]; ok {
+		session.LastSeenAt = at
+		if ip != "" {
+			session.IP = ip
+		}
+	}
+	return nil
+}
+
+func TestJWTAuth_Sessions(t *testing.T) {
+	t.Cleanup(resetSessionTrackingForTest)
+
+	secret := "test-secret"
+	userID := uuid.New()
+	app := fiber.New()
+	app.Get("/protected", JWTAuth(secret), func(c *fiber.Ctx) error {
+		return c.SendString(GetSessionID(c).String())
+	})
+
+	makeToken := func(sessionID string) string {
+		t.Helper()
+		claims := UserClaims{
+			UserID:      userID,
+			GitHubLogin: "test-user",
+			Role:        models.UserRoleViewer,
+			SessionID:   sessionID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        uuid.NewString(),
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return signed
+	}
+	call := func(token string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, 5000)
+		require.NoError(t, err)
+		return resp
+	}
+	newStore := func(sessions ...*models.UserSession) *sessionTestStore {
+		store := &sessionTestStore{sessions: map[uuid.UUID]*models.UserSession{}}
+		for _, s := range sessions {
+			store.sessions[s.ID] = s
+		}
+		InitSessionTracking(store, SessionPolicy{IdleTimeout: time.Hour, MaxLifetime: 24 * time.Hour})
+		return store
+	}
+	activeSession := func() *models.UserSession {
+		now := time.Now()
+		return &models.UserSession{ID: uuid.New(), UserID: userID, CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(24 * time.Hour)}
+	}
+
+	t.Run("accepts an active session and caches it", func(t *testing.T) {
+		session := activeSession()
+		store := newStore(session)
+		token := makeToken(session.ID.String())
+		for i := 0; i < 2; i++ {
+			resp := call(token)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		}
+		assert.Equal(t, 1, store.gets)
+		assert.Equal(t, 1, store.touches, "a new client IP is recorded once")
+	})
+
+	t.Run("legacy tokens without a session pass", func(t *testing.T) {
+		store := newStore()
+		resp := call(makeToken(""))
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, 0, store.gets)
+	})
+
+	t.Run("rejects revoked, missing and foreign sessions", func(t *testing.T) {
+		revokedAt := time.Now()
+		revoked := activeSession()
+		revoked.RevokedAt = &revokedAt
+		foreign := activeSession()
+		foreign.UserID = uuid.New()
+		newStore(revoked, foreign)
+		for _, sid := range []string{revoked.ID.String(), foreign.ID.String(), uuid.NewString(), "not-a-uuid"} {
+			resp := call(makeToken(sid))
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, sid)
+		}
+	})
+
+	t.Run("rejects idle and expired sessions", func(t *testing.T) {
+		idle := activeSession()
+		idle.LastSeenAt = time.Now().Add(-2 * time.Hour)
+		expired := activeSession()
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		newStore(idle, expired)
+		for _, s := range []*models.UserSession{idle, expired} {
+			resp := call(makeToken(s.ID.String()))
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+
+	t.Run("ForgetSession makes revocation immediate", func(t *testing.T) {
+		session := activeSession()
+		store := newStore(session)
+		token := makeToken(session.ID.String())
+		require.Equal(t, fiber.StatusOK, call(token).StatusCode)
+
+		revokedAt := time.Now()
+		store.sessions[session.ID].RevokedAt = &revokedAt
+		ForgetSession(session.ID)
+		assert.Equal(t, fiber.StatusUnauthorized, call(token).StatusCode)
+	})
+
+	t.Run("fails closed on lookup errors", func(t *testing.T) {
+		store := newStore()
+		store.err = assertErr{}
+		resp := call(makeToken(uuid.NewString()))
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	})
+
+	t.Run("ValidateJWT checks the session too", func(t *testing.T) {
+		session := activeSession()
+		revokedAt := time.Now()
+		session.RevokedAt = &revokedAt
+		newStore(session)
+		_, err := ValidateJWT(makeToken(session.ID.String()), secret)
+		require.ErrorIs(t, err, ErrSessionRevoked)
+	})
+}
+
+func TestSessionActive(t *testing.T) {
+	now := time.Now()
+	policy := SessionPolicy{IdleTimeout: time.Hour, MaxLifetime: 24 * time.Hour}
+	session := &models.UserSession{LastSeenAt: now.Add(-30 * time.Minute), ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, SessionActive(session, policy, now))
+	assert.False(t, SessionActive(session, policy, now.Add(time.Hour)), "idle for more than an hour")
+	assert.True(t, SessionActive(session, SessionPolicy{}, now.Add(45*time.Minute)), "a zero idle timeout never expires a session for inactivity")
+	assert.False(t, SessionActive(session, SessionPolicy{}, now.Add(2*time.Hour)), "the absolute expiry always applies")
+	assert.False(t, SessionActive(nil, policy, now))
+}
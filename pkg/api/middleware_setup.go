@@ -1,7 +1,9 @@
 package api
 
 import (
+"errors"
 "fmt"
+"log/slog"
 "strings"
 
 "github.com/gofiber/fiber/v2"
@@ -9,12 +11,45 @@ import (
 "github.com/gofiber/fiber/v2/middleware/cors"
 "github.com/gofiber/fiber/v2/middleware/logger"
 "github.com/gofiber/fiber/v2/middleware/recover"
+"github.com/prometheus/client_golang/prometheus"
+
+"github.com/kubestellar/console/pkg/api/middleware"
 )
 
 func (s *Server) setupMiddleware() {
+	// Serve under BASE_PATH for path-prefix proxies that do not strip it.
+	if basePath := normalizeBasePath(s.config.BasePath); basePath != "" {
+		slog.Info("[Server] serving under base path", "basePath", basePath)
+		s.app.Use(stripBasePath(basePath))
+	}
+
 	// Recovery middleware
 	s.app.Use(recover.New())
 
+	// Per-route request metrics on the default Prometheus registry, served
+	// at /metrics. Registered first so latency covers every later middleware.
+	s.app.Use(middleware.NewHTTPMetrics(prometheus.DefaultRegisterer).Handler())
+
+	// Opt-in usage telemetry counts API areas and error classes only; see
+	// pkg/telemetry. RecordRequest is a no-op until an admin opts in.
+	if s.background != nil && s.background.telemetry != nil {
+		usage := s.background.telemetry
+		s.app.Use("/api", func(c *fiber.Ctx) error {
+			err := c.Next()
+			status := c.Response().StatusCode()
+			if err != nil {
+				// The error handler has not set the status yet.
+				status = fiber.StatusInternalServerError
+				var fe *fiber.Error
+				if errors.As(err, &fe) {
+					status = fe.Code
+				}
+			}
+			usage.RecordRequest(c.Path(), status)
+			return err
+		})
+	}
+
 	// Gzip/Brotli compression for API responses only — static assets are pre-compressed at build time.
 	// The handler is created once and reused across requests (#7575).
 	compressHandler := compress.New(compress.Config{
@@ -35,13 +70,7 @@ func (s *Server) setupMiddleware() {
 	}))
 
 	// CORS
-	s.app.Use(cors.New(cors.Config{
-		AllowOrigins:     s.config.FrontendURL,
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With,X-KC-Client-Auth",
-		ExposeHeaders:    "X-Token-Refresh",
-		AllowCredentials: true,
-	}))
+	s.app.Use(cors.New(s.corsConfig()))
 
 	// Security headers (#7037 CSP, #7038 HSTS)
 	s.app.Use(func(c *fiber.Ctx) error {
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// defaultTrustedProxyCIDRs are the RFC-1918 and link-local ranges typical of
+// Kubernetes ingress controllers, cloud load-balancers, and service meshes.
+// When EnableTrustedProxyCheck is true, Fiber only honours X-Forwarded-For /
+// X-Real-Ip from source IPs within these CIDRs, so c.IP() returns the real
+// client IP instead of the proxy's IP (#7028). TRUSTED_PROXIES replaces them.
+var defaultTrustedProxyCIDRs = []string{
+	"10.0.0.0/8",     // RFC-1918 Class A private
+	"172.16.0.0/12",  // RFC-1918 Class B private
+	"192.168.0.0/16", // RFC-1918 Class C private
+	"fc00::/7",       // IPv6 ULA
+	"127.0.0.0/8",    // loopback
+	"::1/128",        // IPv6 loopback
+}
+
+const (
+	// corsDefaultAllowHeaders are the request headers the frontend sends.
+	corsDefaultAllowHeaders = "Origin,Content-Type,Accept,Authorization,X-Requested-With,X-KC-Client-Auth,Idempotency-Key"
+	// corsDefaultExposeHeaders are the response headers the frontend reads.
+	corsDefaultExposeHeaders = "X-Token-Refresh,Idempotent-Replayed"
+)
+
+// splitList splits a comma-separated setting, dropping blanks.
+func splitList(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// trustedProxySettings resolves TRUSTED_PROXIES into Fiber's
+// EnableTrustedProxyCheck/TrustedProxies pair. "*" disables the check (every
+// peer may set X-Forwarded-*); "none" trusts no peer. Entries that are neither
+// an IP nor a CIDR are dropped with a warning, and an empty result falls back
+// to defaultTrustedProxyCIDRs.
+func (p ProxyConfig) trustedProxySettings() (check bool, proxies []string) {
+	switch strings.TrimSpace(p.TrustedProxies) {
+	case "":
+		return true, defaultTrustedProxyCIDRs
+	case "*":
+		slog.Warn("[Server] TRUSTED_PROXIES=* — X-Forwarded-* headers are honored from any peer; only use behind a proxy that overwrites them")
+		return false, nil
+	case "none":
+		return true, []string{}
+	}
+	for _, entry := range splitList(p.TrustedProxies) {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				slog.Warn("[Server] ignoring invalid TRUSTED_PROXIES entry", "entry", entry)
+				continue
+			}
+		}
+		proxies = append(proxies, entry)
+	}
+	if len(proxies) == 0 {
+		return true, defaultTrustedProxyCIDRs
+	}
+	return true, proxies
+}
+
+// normalizeBasePath turns BASE_PATH into "/prefix" form, or "" when the
+// console is served at the root. Values with "..", a query or a fragment are
+// rejected since they cannot be a plain path prefix.
+func normalizeBasePath(raw string) string {
+	p := strings.Trim(strings.TrimSpace(raw), "/")
+	if p == "" {
+		return ""
+	}
+	if strings.Contains(p, "..") || strings.ContainsAny(p, "?#") {
+		slog.Warn("[Server] ignoring invalid BASE_PATH", "value", raw)
+		return ""
+	}
+	return "/" + p
+}
+
+// stripBasePath removes basePath from incoming request paths so routes are
+// matched as if the console were served at "/". It supports proxies that
+// forward the prefix as-is; requests outside the prefix (e.g. kubelet probes
+// on /healthz) are routed unchanged.
+func stripBasePath(basePath string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p := c.Path()
+		if p == basePath {
+			c.Path("/")
+		} else if strings.HasPrefix(p, basePath+"/") {
+			c.Path(strings.TrimPrefix(p, basePath))
+		}
+		return c.Next()
+	}
+}
+
+// corsConfig builds the CORS middleware configuration. Origins default to the
+// frontend URL. Invalid origins are dropped (Fiber panics on them), and
+// credentials are turned off for a wildcard origin, which browsers reject.
+func (s *Server) corsConfig() cors.Config {
+	rawOrigins := s.config.CORSAllowedOrigins
+	if strings.TrimSpace(rawOrigins) == "" {
+		rawOrigins = s.config.FrontendURL
+	}
+	var origins []string
+	for _, origin := range splitList(rawOrigins) {
+		if origin == "*" {
+			origins = append(origins, origin)
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			slog.Warn("[Server] ignoring invalid CORS origin", "origin", origin)
+			continue
+		}
+		origins = append(origins, u.Scheme+"://"+u.Host)
+	}
+
+	credentials := !s.config.CORSNoCredentials
+	allowOrigins := strings.Join(origins, ",")
+	if credentials && strings.Contains(allowOrigins, "*") {
+		slog.Warn("[Server] CORS wildcard origin configured — disabling credentialed cross-origin requests")
+		credentials = false
+	}
+
+	headers := corsDefaultAllowHeaders
+	if extra := splitList(s.config.CORSAllowedHeaders); len(extra) > 0 {
+		headers += "," + strings.Join(extra, ",")
+	}
+	expose := corsDefaultExposeHeaders
+	if extra := splitList(s.config.CORSExposedHeaders); len(extra) > 0 {
+		expose += "," + strings.Join(extra, ",")
+	}
+
+	cfg := cors.Config{
+		AllowOrigins:     allowOrigins,
+		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
+		AllowHeaders:     headers,
+		ExposeHeaders:    expose,
+		AllowCredentials: credentials,
+	}
+	if allowOrigins == "" {
+		// An empty list would make Fiber default to "*"; keep CORS closed
+		// instead when every configured origin was invalid.
+		cfg.AllowOriginsFunc = func(string) bool { return false }
+	}
+	return cfg
+}
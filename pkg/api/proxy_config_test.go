@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProxyTestServer applies the middleware for cfg and echoes the routed
+// path, client IP and protocol.
+func newProxyTestServer(t *testing.T, cfg Config) *Server {
+	t.Helper()
+	check, proxies := cfg.trustedProxySettings()
+	s := &Server{
+		app: fiber.New(fiber.Config{
+			ErrorHandler:            customErrorHandler,
+			EnableTrustedProxyCheck: check,
+			TrustedProxies:          proxies,
+			ProxyHeader:             cfg.ProxyIPHeader,
+		}),
+		config: cfg,
+		auth:   newAuthRuntime(),
+	}
+	s.setupMiddleware()
+	s.app.Get("/api/echo", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"path": c.Path(), "ip": c.IP(), "protocol": c.Protocol()})
+	})
+	return s
+}
+
+func TestCORSConfig(t *testing.T) {
+	for name, tc := range map[string]struct {
+		proxy       ProxyConfig
+		origins     string
+		credentials bool
+		headers     string
+	}{
+		"defaults to frontend url": {
+			origins: "http://localhost:3000", credentials: true, headers: corsDefaultAllowHeaders,
+		},
+		"origin list": {
+			proxy:   ProxyConfig{CORSAllowedOrigins: "https://a.example.com/, not-a-url, https://b.example.com:8443, https://c.example.com/path"},
+			origins: "https://a.example.com,https://b.example.com:8443", credentials: true, headers: corsDefaultAllowHeaders,
+		},
+		"wildcard drops credentials": {
+			proxy:   ProxyConfig{CORSAllowedOrigins: "*"},
+			origins: "*", credentials: false, headers: corsDefaultAllowHeaders,
+		},
+		"extra headers without credentials": {
+			proxy:   ProxyConfig{CORSNoCredentials: true, CORSAllowedHeaders: "X-Correlation-Id, ,X-Tenant"},
+			origins: "http://localhost:3000", credentials: false, headers: corsDefaultAllowHeaders + ",X-Correlation-Id,X-Tenant",
+		},
+	} {
+		s := &Server{config: Config{IntegrationsConfig: IntegrationsConfig{FrontendURL: "http://localhost:3000"}, ProxyConfig: tc.proxy}}
+		cfg := s.corsConfig()
+		assert.Equal(t, tc.origins, cfg.AllowOrigins, name)
+		assert.Equal(t, tc.credentials, cfg.AllowCredentials, name)
+		assert.Equal(t, tc.headers, cfg.AllowHeaders, name)
+		assert.Nil(t, cfg.AllowOriginsFunc, name)
+	}
+
+	s := &Server{config: Config{ProxyConfig: ProxyConfig{CORSAllowedOrigins: "nope", CORSExposedHeaders: "X-Request-Id"}}}
+	cfg := s.corsConfig()
+	require.NotNil(t, cfg.AllowOriginsFunc, "no valid origin keeps CORS closed instead of Fiber's wildcard default")
+	assert.False(t, cfg.AllowOriginsFunc("https://evil.example.com"))
+	assert.Equal(t, corsDefaultExposeHeaders+",X-Request-Id", cfg.ExposeHeaders)
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	s := newProxyTestServer(t, Config{
+		IntegrationsConfig: IntegrationsConfig{FrontendURL: "http://localhost:3000"},
+		ProxyConfig:        ProxyConfig{CORSAllowedOrigins: "https://portal.example.com", CORSAllowedHeaders: "X-Tenant"},
+	})
+	req := httptest.NewRequest(http.MethodOptions, "/api/echo", nil)
+	req.Header.Set("Origin", "https://portal.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	resp, err := s.app.Test(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "https://portal.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), "X-Tenant")
+}
+
+func TestTrustedProxySettings(t *testing.T) {
+	check, proxies := ProxyConfig{}.trustedProxySettings()
+	assert.True(t, check)
+	assert.Equal(t, defaultTrustedProxyCIDRs, proxies)
+
+	check, proxies = ProxyConfig{TrustedProxies: "10.1.0.0/16, 203.0.113.7, bogus"}.trustedProxySettings()
+	assert.True(t, check)
+	assert.Equal(t, []string{"10.1.0.0/16", "203.0.113.7"}, proxies)
+
+	check, proxies = ProxyConfig{TrustedProxies: "bogus"}.trustedProxySettings()
+	assert.True(t, check)
+	assert.Equal(t, defaultTrustedProxyCIDRs, proxies, "nothing valid falls back to the defaults")
+
+	check, proxies = ProxyConfig{TrustedProxies: "none"}.trustedProxySettings()
+	assert.True(t, check)
+	assert.Empty(t, proxies)
+
+	check, _ = ProxyConfig{TrustedProxies: "*"}.trustedProxySettings()
+	assert.False(t, check)
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	for raw, want := range map[string]string{
+		"":             "",
+		"/":            "",
+		"console":      "/console",
+		"/console/":    "/console",
+		" /a/b ":       "/a/b",
+		"/../etc":      "",
+		"/console?x=1": "",
+	} {
+		assert.Equal(t, want, normalizeBasePath(raw), raw)
+	}
+}
+
+func TestBasePathAndForwardedHeaders(t *testing.T) {
+	s := newProxyTestServer(t, Config{
+		IntegrationsConfig: IntegrationsConfig{FrontendURL: "http://localhost:3000"},
+		ProxyConfig:        ProxyConfig{BasePath: "/console/", TrustedProxies: "0.0.0.0/0", ProxyIPHeader: "X-Real-Ip"},
+	})
+
+	for _, path := range []string{"/console/api/echo", "/api/echo"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Real-Ip", "198.51.100.4")
+		req.Header.Set("X-Forwarded-Proto", "https")
+		resp, err := s.app.Test(req)
+		require.NoError(t, err)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		resp.Body.Close()
+		assert.Equal(t, "/api/echo", body["path"], path)
+		assert.Equal(t, "198.51.100.4", body["ip"], path)
+		assert.Equal(t, "https", body["protocol"], path)
+		assert.NotEmpty(t, resp.Header.Get("Strict-Transport-Security"), "HSTS follows the forwarded protocol")
+	}
+
+	resp, err := s.app.Test(httptest.NewRequest(http.MethodGet, "/consoleapi/echo", nil))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "only whole path segments are stripped")
+}
+
+func TestForwardedHeaders_UntrustedPeer(t *testing.T) {
+	s := newProxyTestServer(t, Config{
+		IntegrationsConfig: IntegrationsConfig{FrontendURL: "http://localhost:3000"},
+		ProxyConfig:        ProxyConfig{TrustedProxies: "none", ProxyIPHeader: fiber.HeaderXForwardedFor},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/echo", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.4")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	resp, err := s.app.Test(req)
+	require.NoError(t, err)
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	resp.Body.Close()
+	assert.NotEqual(t, "198.51.100.4", body["ip"])
+	assert.Equal(t, "http", body["protocol"])
+}
@@ -15,10 +15,15 @@ import (
 	"github.com/kubestellar/console/pkg/api/handlers/compliance"
 	"github.com/kubestellar/console/pkg/api/handlers/github"
 	"github.com/kubestellar/console/pkg/api/handlers/missions"
+	"github.com/kubestellar/console/pkg/compliance/manifestpolicy"
+	"github.com/kubestellar/console/pkg/featureflags"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
 	"github.com/kubestellar/console/pkg/notifications"
 	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/slo"
 	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/vulnscan"
 )
 
 type apiCoreRouteGroup struct {
@@ -29,10 +34,11 @@ type apiCoreRouteGroup struct {
 	notificationService *notifications.Service
 	persistenceStore    *store.PersistenceStore
 	k8sClient           *k8s.MultiClusterClient
+	limits              *limits.Enforcer
 	done                <-chan struct{}
 }
 
-func newAPICoreRouteGroup(app *fiber.App, store store.Store, cfg Config, hub *transport.Hub, notificationService *notifications.Service, persistenceStore *store.PersistenceStore, k8sClient *k8s.MultiClusterClient, done <-chan struct{}) *apiCoreRouteGroup {
+func newAPICoreRouteGroup(app *fiber.App, store store.Store, cfg Config, hub *transport.Hub, notificationService *notifications.Service, persistenceStore *store.PersistenceStore, k8sClient *k8s.MultiClusterClient, enforcer *limits.Enforcer, done <-chan struct{}) *apiCoreRouteGroup {
 	return &apiCoreRouteGroup{
 		app:                 app,
 		store:               store,
@@ -41,6 +47,7 @@ func newAPICoreRouteGroup(app *fiber.App, store store.Store, cfg Config, hub *tr
 		notificationService: notificationService,
 		persistenceStore:    persistenceStore,
 		k8sClient:           k8sClient,
+		limits:              enforcer,
 		done:                done,
 	}
 }
@@ -140,35 +147,77 @@ func (g *apiCoreRouteGroup) Register(routes *routeSetupContext) {
 	api.Get("/acmm/badge", compliance.ACMMBadgeHandler)
 
 	settingsHandler := handlers.NewSettingsHandler(settings.GetSettingsManager(), g.store)
+	settingsHandler.SetPersistenceStore(g.persistenceStore)
 	api.Get("/settings", settingsHandler.GetSettings)
+	api.Get("/settings/effective", settingsHandler.GetEffectiveSettings)
 	api.Put("/settings", settingsHandler.SaveSettings)
 	api.Post("/settings/export", settingsHandler.ExportSettings)
 	api.Post("/settings/import", settingsHandler.ImportSettings)
+	api.Post("/settings/validate", settingsHandler.ValidateSettings)
 
 	onboarding := handlers.NewOnboardingHandler(g.store)
 	api.Get("/onboarding/questions", onboarding.GetQuestions)
 	api.Post("/onboarding/responses", onboarding.SaveResponses)
 	api.Post("/onboarding/complete", onboarding.CompleteOnboarding)
 
-	dashboard := handlers.NewDashboardHandler(g.store)
+	dashboard := handlers.NewDashboardHandler(g.store, g.hub)
 	api.Get("/dashboards", dashboard.ListDashboards)
 	api.Get("/dashboards/:id", dashboard.GetDashboard)
 	api.Get("/dashboards/:id/export", dashboard.ExportDashboard)
-	api.Post("/dashboards/import", dashboard.ImportDashboard)
-	api.Post("/dashboards", dashboard.CreateDashboard)
+	api.Post("/dashboards/import", routes.idempotent, dashboard.ImportDashboard)
+	api.Post("/dashboards", routes.idempotent, dashboard.CreateDashboard)
 	api.Put("/dashboards/:id", dashboard.UpdateDashboard)
+	api.Post("/dashboards/:id/presence", dashboard.UpdatePresence)
+	api.Post("/dashboards/:id/snapshot", routes.idempotent, routes.snapshots.CreateSnapshot)
 	api.Delete("/dashboards/:id", dashboard.DeleteDashboard)
 
+	// Saved resource views: named cross-cluster queries shared within the
+	// active console project. Results are executed server-side and cached.
+	savedViews := handlers.NewSavedViewHandler(g.store, g.k8sClient, g.config.ConsoleProject)
+	api.Get("/views", savedViews.ListViews)
+	api.Post("/views", routes.idempotent, savedViews.CreateView)
+	api.Get("/views/:id", savedViews.GetView)
+	api.Put("/views/:id", savedViews.UpdateView)
+	api.Delete("/views/:id", savedViews.DeleteView)
+	api.Get("/views/:id/results", savedViews.GetResults)
+
+	// Service level objectives: error budgets and burn-rate alerts computed
+	// from each target cluster's Prometheus.
+	var sloQuerier slo.Querier
+	if g.k8sClient != nil {
+		sloQuerier = slo.NewPrometheusQuerier(g.k8sClient)
+		if g.done != nil {
+			slo.NewEvaluator(g.store, sloQuerier, g.notificationService, g.config.ConsoleProject).Start(g.done)
+		}
+	}
+	slos := handlers.NewSLOHandler(g.store, sloQuerier, g.config.ConsoleProject)
+	api.Get("/slos", slos.ListSLOs)
+	api.Post("/slos", routes.idempotent, slos.CreateSLO)
+	api.Get("/slos/:id", slos.GetSLO)
+	api.Put("/slos/:id", slos.UpdateSLO)
+	api.Delete("/slos/:id", slos.DeleteSLO)
+	api.Get("/slos/:id/status", slos.GetStatus)
+
 	cards := handlers.NewCardHandler(g.store, g.hub)
 	api.Get("/dashboards/:id/cards", cards.ListCards)
-	api.Post("/dashboards/:id/cards", cards.CreateCard)
+	api.Post("/dashboards/:id/cards", routes.idempotent, cards.CreateCard)
 	api.Put("/cards/:id", cards.UpdateCard)
 	api.Delete("/cards/:id", cards.DeleteCard)
 	api.Post("/cards/:id/focus", cards.RecordFocus)
 	api.Post("/cards/:id/move", cards.MoveCard)
 	api.Get("/card-types", cards.GetCardTypes)
+	api.Post("/card-types/:type/validate", cards.ValidateCardConfig)
 	api.Get("/card-history", cards.GetHistory)
 
+	// Server-side card refresh: cards register the query they display and
+	// receive card_data pushes over the hub when its result changes.
+	cardRefresh := handlers.NewCardRefreshHandler(g.store, g.hub, savedViews)
+	if g.done != nil {
+		cardRefresh.Start(g.done)
+	}
+	api.Post("/cards/:id/refresh", cardRefresh.RegisterRefresh)
+	api.Delete("/cards/:id/refresh", cardRefresh.UnregisterRefresh)
+
 	cardProxy := handlers.NewCardProxyHandler(g.store)
 	api.Get("/card-proxy", cardProxy.Proxy)
 
@@ -208,17 +257,61 @@ func (g *apiCoreRouteGroup) Register(routes *routeSetupContext) {
 	api.Post("/notifications/config", notificationHandler.SaveNotificationConfig)
 
 	persistenceHandler := handlers.NewConsolePersistenceHandlers(g.persistenceStore, g.k8sClient, g.hub, g.store)
+	persistenceHandler.SetProject(g.config.ConsoleProject)
+	persistenceHandler.SetNotificationService(g.notificationService)
+	persistenceHandler.SetOperationManager(routes.operationManager(g.hub, g.done))
+	persistenceHandler.SetLimits(g.limits)
+	if g.config.DeploymentPolicyFile != "" {
+		policyEngine, err := manifestpolicy.LoadEngine(g.config.DeploymentPolicyFile)
+		if err != nil {
+			slog.Error("Failed to load deployment policies; WorkloadDeployment rollouts will not be policy-gated",
+				"path", g.config.DeploymentPolicyFile, "error", err)
+		} else {
+			persistenceHandler.SetPolicyEngine(policyEngine)
+		}
+	}
+	// Image vulnerability summaries from the configured scanner, which also
+	// feed the deployment policies.
+	var scanner vulnscan.Scanner
+	if vulnCache, err := vulnscan.FromEnv(); err != nil {
+		slog.Error("Failed to configure the vulnerability scanner; image vulnerabilities will be unavailable", "error", err)
+	} else if vulnCache != nil {
+		scanner = vulnCache
+		persistenceHandler.SetVulnerabilityScanner(scanner)
+	}
+	vulnHandler := handlers.NewVulnerabilityHandler(g.k8sClient, scanner)
+	api.Get("/clusters/:cluster/vulnerabilities", vulnHandler.ListWorkloadVulnerabilities)
+	api.Get("/vulnerabilities/image", vulnHandler.GetImageVulnerabilities)
+
 	api.Get("/persistence/config", persistenceHandler.GetConfig)
 	api.Put("/persistence/config", persistenceHandler.UpdateConfig)
 	api.Get("/persistence/status", persistenceHandler.GetStatus)
 	api.Post("/persistence/sync", persistenceHandler.SyncNow)
 	api.Post("/persistence/test", persistenceHandler.TestConnection)
+	api.Get("/persistence/crds", persistenceHandler.GetCRDStatus)
+	api.Post("/persistence/install-crds", persistenceHandler.InstallCRDs)
 	api.Get("/persistence/workloads", persistenceHandler.ListManagedWorkloads)
+	api.Post("/persistence/workloads/import-from-cluster", persistenceHandler.ImportManagedWorkload)
 	api.Get("/persistence/workloads/:name", persistenceHandler.GetManagedWorkload)
+	api.Get("/persistence/workloads/:name/dry-run", persistenceHandler.DryRunManagedWorkload)
 	api.Get("/persistence/groups", persistenceHandler.ListClusterGroups)
+	api.Post("/persistence/groups/preview", persistenceHandler.PreviewClusterGroup)
 	api.Get("/persistence/groups/:name", persistenceHandler.GetClusterGroup)
+	api.Get("/persistence/groups/:name/settings", persistenceHandler.GetClusterGroupSettings)
 	api.Get("/persistence/deployments", persistenceHandler.ListWorkloadDeployments)
 	api.Get("/persistence/deployments/:name", persistenceHandler.GetWorkloadDeployment)
+	api.Get("/persistence/deployments/:name/placement", persistenceHandler.GetDeploymentPlacement)
+	api.Get("/persistence/deployments/:name/provenance", persistenceHandler.GetDeploymentProvenance)
+	canaryFlag := routes.featureFlagsHandler(g.store).RequireFeature(featureflags.CanaryEngine)
+	api.Post("/persistence/deployments/:name/promote", canaryFlag, persistenceHandler.PromoteCanary)
+	api.Post("/persistence/deployments/:name/abort", canaryFlag, persistenceHandler.AbortCanary)
+	api.Get("/persistence/change-policy", persistenceHandler.GetChangePolicy)
+	api.Put("/persistence/change-policy", persistenceHandler.UpdateChangePolicy)
+
+	operationsHandler := handlers.NewOperationsHandler(routes.operationManager(g.hub, g.done))
+	api.Get("/operations", operationsHandler.ListOperations)
+	api.Get("/operations/:id", operationsHandler.GetOperation)
+	api.Post("/operations/:id/cancel", operationsHandler.CancelOperation)
 
 	nightlyE2E := github.NewNightlyE2EHandler(g.config.GitHubToken)
 	api.Get("/nightly-e2e/runs", nightlyE2E.GetRuns)
@@ -5,21 +5,30 @@ import (
 	"github.com/kubestellar/console/pkg/api/handlers/compliance"
 	"github.com/kubestellar/console/pkg/api/middleware"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
 	"github.com/kubestellar/console/pkg/services/team"
+	"github.com/kubestellar/console/pkg/settings"
 	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/telemetry"
 )
 
 type governanceRouteGroup struct {
 	store          store.Store
 	k8sClient      *k8s.MultiClusterClient
 	failureTracker *middleware.FailureTracker
+	telemetry      *telemetry.Service
+	persistence    *store.PersistenceStore
+	limits         *limits.Enforcer
 }
 
-func newGovernanceRouteGroup(store store.Store, k8sClient *k8s.MultiClusterClient, failureTracker *middleware.FailureTracker) *governanceRouteGroup {
+func newGovernanceRouteGroup(store store.Store, k8sClient *k8s.MultiClusterClient, failureTracker *middleware.FailureTracker, usage *telemetry.Service, persistence *store.PersistenceStore, enforcer *limits.Enforcer) *governanceRouteGroup {
 	return &governanceRouteGroup{
 		store:          store,
 		k8sClient:      k8sClient,
 		failureTracker: failureTracker,
+		telemetry:      usage,
+		persistence:    persistence,
+		limits:         enforcer,
 	}
 }
 
@@ -66,6 +75,26 @@ func (g *governanceRouteGroup) Register(routes *routeSetupContext) {
 	adminHandler := handlers.NewAdminHandler(g.failureTracker, g.store)
 	api.Get("/admin/rate-limit-status", adminHandler.GetRateLimitStatus)
 
+	flags := routes.featureFlagsHandler(g.store)
+	api.Get("/capabilities", flags.GetCapabilities)
+	api.Get("/admin/feature-flags", flags.ListFlags)
+	api.Put("/admin/feature-flags/:key", flags.UpdateFlag)
+	api.Delete("/admin/feature-flags/:key", flags.ResetFlag)
+	api.Put("/admin/feature-flags/:key/users/:user", flags.SetUserOverride)
+	api.Delete("/admin/feature-flags/:key/users/:user", flags.DeleteUserOverride)
+
+	usage := handlers.NewTelemetryHandler(g.telemetry, g.store)
+	api.Get("/admin/telemetry", usage.GetStatus)
+	api.Put("/admin/telemetry", usage.UpdateConfig)
+	api.Get("/admin/telemetry/preview", usage.Preview)
+
+	branding := handlers.NewBrandingHandler(settings.GetSettingsManager(), g.store)
+	api.Put("/admin/branding", branding.UpdateBranding)
+
+	limitsHandler := handlers.NewLimitsHandler(g.limits, settings.GetSettingsManager(), g.store, g.k8sClient, g.persistence)
+	api.Get("/limits", limitsHandler.GetLimits)
+	api.Put("/admin/limits", limitsHandler.UpdateLimits)
+
 	// SIEM export (admin-only, moved from public routes — fix #16518).
 	siemHandler := compliance.NewSIEMHandler(g.store)
 	siemHandler.RegisterRoutes(api)
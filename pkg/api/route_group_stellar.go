@@ -19,6 +19,8 @@ type stellarRouteGroup struct {
 	userStore store.Store
 	k8sClient *k8s.MultiClusterClient
 	done      <-chan struct{}
+	// handler is set by Register.
+	handler *stellar.Handler
 }
 
 func newStellarRouteGroup(stelStore stellar.Store, k8sClient *k8s.MultiClusterClient, done <-chan struct{}, userStore store.Store) *stellarRouteGroup {
@@ -32,6 +34,7 @@ func newStellarRouteGroup(stelStore stellar.Store, k8sClient *k8s.MultiClusterCl
 
 func (g *stellarRouteGroup) Register(api fiber.Router) {
 	handler := stellar.NewHandler(g.store, g.k8sClient, stellar.WithUserStore(g.userStore))
+	g.handler = handler
 	g.startWorkers(handler)
 
 	api.Get("/stellar/preferences", handler.GetPreferences)
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kubestellar/console/pkg/agentpki"
+	"github.com/kubestellar/console/pkg/api/handlers"
+	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/store"
+)
+
+// agentRelay is the mutual-TLS listener paired kc-agents connect to. Only
+// agents holding a certificate from the console's agent authority get past
+// the handshake.
+type agentRelay struct {
+	addr     string
+	tls      *tls.Config
+	app      *fiber.App
+	handlers *handlers.AgentPKIHandlers
+	// routes is the /relay group; everything registered on it requires an
+	// agent certificate.
+	routes fiber.Router
+}
+
+// newAgentRelay opens the agent certificate authority next to the database
+// and builds the relay listener. It returns nil when AGENT_RELAY_ADDR is
+// unset.
+func newAgentRelay(cfg Config, s store.Store) (*agentRelay, error) {
+	if cfg.AgentRelayAddr == "" {
+		return nil, nil
+	}
+	authority, err := agentpki.Open(filepath.Join(filepath.Dir(cfg.DatabasePath), "agentpki"))
+	if err != nil {
+		return nil, err
+	}
+	var hosts []string
+	for _, host := range strings.Split(cfg.AgentRelayHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	tlsConfig, err := authority.RelayTLSConfig(hosts...)
+	if err != nil {
+		return nil, err
+	}
+	h := handlers.NewAgentPKIHandlers(authority, s)
+	app, routes := newAgentRelayApp(h)
+	return &agentRelay{
+		addr:     cfg.AgentRelayAddr,
+		tls:      tlsConfig,
+		app:      app,
+		handlers: h,
+		routes:   routes,
+	}, nil
+}
+
+// newAgentRelayApp serves the relay routes. Every route sits behind the
+// per-request certificate check; other route groups add theirs to the
+// returned /relay group.
+func newAgentRelayApp(h *handlers.AgentPKIHandlers) (*fiber.App, fiber.Router) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true, ErrorHandler: customErrorHandler})
+	routes := app.Group("/relay", h.RequireAgentCertificate)
+	routes.Post("/rotate", h.Rotate)
+	return app, routes
+}
+
+// start listens on the relay address in the background.
+func (r *agentRelay) start() error {
+	ln, err := tls.Listen("tcp", r.addr, r.tls)
+	if err != nil {
+		return fmt.Errorf("agent relay listen: %w", err)
+	}
+	slog.Info("[Server] agent relay listening", "addr", r.addr)
+	safego.GoWith("api/agent-relay", func() {
+		if err := r.app.Listener(ln); err != nil {
+			slog.Error("[Server] agent relay stopped", "error", err)
+		}
+	})
+	return nil
+}
+
+// setupAgentRelayRoutes registers the admin side of agent pairing. The
+// agent-facing POST /api/agents/pair is registered with the public routes.
+func (s *Server) setupAgentRelayRoutes(api fiber.Router) {
+	if s.agentRelay == nil {
+		return
+	}
+	h := s.agentRelay.handlers
+	api.Post("/admin/agents/pairing-codes", h.CreatePairingCode)
+	api.Post("/admin/agents/:id/revoke", h.Revoke)
+}
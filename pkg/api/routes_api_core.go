@@ -10,5 +10,5 @@ const (
 // setupAPICoreRoutes registers the main authenticated API surface through a
 // focused route group.
 func (s *Server) setupAPICoreRoutes(routes *routeSetupContext) {
-	newAPICoreRouteGroup(s.app, s.store, s.config, s.hub, s.notificationService, s.persistenceStore, s.k8sClient, s.lifecycle.done).Register(routes)
+	newAPICoreRouteGroup(s.app, s.store, s.config, s.hub, s.notificationService, s.persistenceStore, s.k8sClient, s.limits, s.lifecycle.done).Register(routes)
 }
@@ -12,9 +12,17 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 
 	"github.com/kubestellar/console/pkg/api/handlers"
+	"github.com/kubestellar/console/pkg/api/handlers/benchmarks"
 	"github.com/kubestellar/console/pkg/api/handlers/feedback"
 	mcphandlers "github.com/kubestellar/console/pkg/api/handlers/mcp"
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/api/transport"
+	"github.com/kubestellar/console/pkg/featureflags"
+	"github.com/kubestellar/console/pkg/idempotency"
+	"github.com/kubestellar/console/pkg/operations"
+	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/store"
 )
 
 type routeSetupContext struct {
@@ -26,8 +34,40 @@ type routeSetupContext struct {
 	api                fiber.Router
 	bodyGuard          fiber.Handler
 	feedback           *feedback.FeedbackHandler
+	snapshots          *handlers.DashboardSnapshotHandler
+	serviceProxy       *handlers.ServiceProxyHandler
+	benchmarks         *benchmarks.BenchmarkHandlers
 	namespaces         *handlers.NamespaceHandler
+	featureFlags       *handlers.FeatureFlagsHandler
 	aiLimiter          fiber.Handler // per-user rate limit for AI-calling endpoints (#17294)
+	idempotent         fiber.Handler // replays retried creates that carry an Idempotency-Key
+	operations         *operations.Manager
+}
+
+// featureFlagsHandler returns the shared feature flag handler, creating it
+// on first use. Flag state lives in the settings file.
+func (r *routeSetupContext) featureFlagsHandler(s store.Store) *handlers.FeatureFlagsHandler {
+	if r.featureFlags == nil {
+		r.featureFlags = handlers.NewFeatureFlagsHandler(featureflags.NewManager(settings.GetSettingsManager()), s)
+	}
+	return r.featureFlags
+}
+
+// operationManager returns the shared manager of long-running operations,
+// creating it on first use. Running operations are cancelled once done is
+// closed.
+func (r *routeSetupContext) operationManager(hub *transport.Hub, done <-chan struct{}) *operations.Manager {
+	if r.operations == nil {
+		r.operations = handlers.NewOperationManager(hub)
+		if done != nil {
+			m := r.operations
+			safego.GoWith("api/operations-close", func() {
+				<-done
+				m.Close()
+			})
+		}
+	}
+	return r.operations
 }
 
 // oauthConfigured reports whether the server has a usable GitHub OAuth configuration.
@@ -83,6 +123,7 @@ func (s *Server) reloadOAuth(clientID, clientSecret string) {
 	})
 	s.auth.handler.SetHub(s.hub)
 	s.auth.handler.SetSSECanceller(mcphandlers.CancelUserSSEStreams)
+	s.auth.handler.SetLimits(s.limits)
 	slog.Info("[Server] OAuth config hot-reloaded after manifest flow")
 }
 
@@ -131,6 +172,7 @@ func (s *Server) setupAuthRoutes(app *fiber.App) *routeSetupContext {
 
 	auth.SetHub(s.hub)
 	auth.SetSSECanceller(mcphandlers.CancelUserSSEStreams)
+	auth.SetLimits(s.limits)
 	currentAuthHandler := func() *handlers.AuthHandler {
 		s.auth.oauthMu.RLock()
 		defer s.auth.oauthMu.RUnlock()
@@ -265,6 +307,27 @@ func (s *Server) setupAuthRoutes(app *fiber.App) *routeSetupContext {
 	feedbackHandler := feedback.NewFeedbackHandler(s.store, feedbackCfg)
 	app.Post("/api/feedback/requests", feedbackBodyGuard, csrfGuard, jwtAuth, feedbackLimiter, feedbackHandler.CreateFeatureRequest)
 
+	// Snapshot links are opened by people without a console session, so the
+	// public route has to be registered before the /api group adds jwtAuth.
+	snapshots := handlers.NewDashboardSnapshotHandler(s.store, s.config.JWTSecret)
+	app.Get(handlers.PublicSnapshotPath+":id", publicLimiter, snapshots.GetPublicSnapshot)
+
+	// Proxied in-cluster UIs run in a sandboxed origin that never sees the
+	// console cookie; requests are authorized by the signed session URL.
+	serviceProxy := handlers.NewServiceProxyHandler(s.k8sClient, s.store, s.config.JWTSecret)
+	app.All(handlers.PublicServiceProxyPath+":token/*", serviceProxy.Proxy)
+
+	// CI jobs push benchmark runs with a token of their own rather than a
+	// console session, so the webhook is registered before the /api group
+	// adds jwtAuth.
+	benchmarkHandlers := s.newBenchmarkHandlers()
+	app.Post("/api/benchmarks/webhook", publicLimiter, benchmarkHandlers.IngestWebhook)
+
+	// kc-agents pair with a one-time code before they hold any credential.
+	if s.agentRelay != nil {
+		app.Post("/api/agents/pair", publicLimiter, s.agentRelay.handlers.Pair)
+	}
+
 	apiLimiterSkipPaths := map[string]bool{
 		"/api/feedback/requests": true,
 		"/api/me":                true,
@@ -287,6 +350,22 @@ func (s *Server) setupAuthRoutes(app *fiber.App) *routeSetupContext {
 
 	api := app.Group("/api", apiLimiterWithSkip, bodyGuard, csrfGuard, jwtAuth)
 
+	api.Get("/sessions", func(c *fiber.Ctx) error {
+		return currentAuthHandler().ListSessions(c)
+	})
+	api.Delete("/sessions", func(c *fiber.Ctx) error {
+		return currentAuthHandler().RevokeOtherSessions(c)
+	})
+	api.Delete("/sessions/:id", func(c *fiber.Ctx) error {
+		return currentAuthHandler().RevokeSession(c)
+	})
+	api.Get("/users/:id/sessions", func(c *fiber.Ctx) error {
+		return currentAuthHandler().ListUserSessions(c)
+	})
+	api.Delete("/users/:id/sessions", func(c *fiber.Ctx) error {
+		return currentAuthHandler().RevokeUserSessions(c)
+	})
+
 	return &routeSetupContext{
 		jwtAuth:            jwtAuth,
 		csrfGuard:          csrfGuard,
@@ -296,6 +375,10 @@ func (s *Server) setupAuthRoutes(app *fiber.App) *routeSetupContext {
 		api:                api,
 		bodyGuard:          bodyGuard,
 		feedback:           feedbackHandler,
+		snapshots:          snapshots,
+		serviceProxy:       serviceProxy,
+		benchmarks:         benchmarkHandlers,
 		aiLimiter:          aiLimiter,
+		idempotent:         middleware.Idempotency(idempotency.NewCache(idempotency.DefaultWindow)),
 	}
 }
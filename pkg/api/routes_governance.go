@@ -3,5 +3,5 @@ package api
 // setupGovernanceRoutes registers RBAC, compliance, namespace, and admin routes
 // through a focused route group.
 func (s *Server) setupGovernanceRoutes(routes *routeSetupContext) {
-	newGovernanceRouteGroup(s.store, s.k8sClient, s.auth.failureTracker).Register(routes)
+	newGovernanceRouteGroup(s.store, s.k8sClient, s.auth.failureTracker, s.background.telemetry, s.persistenceStore, s.limits).Register(routes)
 }
@@ -5,6 +5,11 @@ import (
 	"sync/atomic"
 
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/kubestellar/console/pkg/api/handlers"
+	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/settings"
 )
 
 // isQuantumWorkloadRunning detects if quantum-kc-demo is running in any cluster.
@@ -15,10 +20,13 @@ func (s *Server) isQuantumWorkloadRunning() bool {
 	return s.quantumCache.isRunning(s.k8sClient)
 }
 
-// setupHealthRoutes registers the /healthz, /health, and /api/version
-// endpoints. These are unauthenticated and used by load balancers,
-// liveness probes, and the frontend boot sequence.
+// setupHealthRoutes registers the /healthz, /readyz, /health, /metrics,
+// /api/branding and /api/version endpoints. These are unauthenticated and used by load
+// balancers, liveness probes, Prometheus, and the frontend boot sequence.
 func (s *Server) setupHealthRoutes() {
+	// Prometheus scrape endpoint; optionally bearer-protected by METRICS_TOKEN.
+	s.app.Get("/metrics", middleware.MetricsHandler(s.config.MetricsToken))
+
 	// Minimal probe endpoint for load balancers and k8s liveness checks.
 	// Returns only status — no configuration metadata.
 	s.app.Get("/healthz", func(c *fiber.Ctx) error {
@@ -28,6 +36,11 @@ func (s *Server) setupHealthRoutes() {
 		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
+	// Readiness probe. Reports 503 until the startup cluster warm-up has
+	// finished so traffic only arrives once caches are primed; progress is
+	// in "warmup" (per cluster with ?verbose=true).
+	s.app.Get("/readyz", s.handleReadyz)
+
 	// Health check — returns version and UI configuration for the frontend.
 	// Build metadata (go_version, git_commit, etc.) lives in /api/version.
 	s.app.Get("/health", func(c *fiber.Ctx) error {
@@ -61,6 +74,7 @@ func (s *Server) setupHealthRoutes() {
 			"oauth_configured": s.oauthConfigured(),
 			"in_cluster":       inCluster,
 			"no_local_agent":   noLocalAgent,
+			"air_gapped":       egress.AirGapped(),
 			"install_method":   detectInstallMethod(inCluster),
 			"project":          s.config.ConsoleProject,
 			"workloads": fiber.Map{
@@ -103,6 +117,11 @@ func (s *Server) setupHealthRoutes() {
 		return c.JSON(resp)
 	})
 
+	// Branding is public so the login page can be white-labeled before
+	// anyone signs in. Admins change it through PUT /api/admin/branding.
+	branding := handlers.NewBrandingHandler(settings.GetSettingsManager(), s.store)
+	s.app.Get("/api/branding", branding.GetBranding)
+
 	// Version endpoint — lightweight, returns only build metadata.
 	// In dev mode (go run), VCS info from debug.ReadBuildInfo() may be empty,
 	// so we fall back to git commands for commit and time.
@@ -127,3 +146,24 @@ func (s *Server) setupHealthRoutes() {
 		})
 	})
 }
+
+// handleReadyz serves /readyz.
+func (s *Server) handleReadyz(c *fiber.Ctx) error {
+	if s.lifecycle != nil && atomic.LoadInt32(&s.lifecycle.shuttingDown) == 1 {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"status": "shutting_down"})
+	}
+	var warmup *clusterWarmup
+	if s.background != nil {
+		warmup = s.background.clusterWarmup
+	}
+	if warmup == nil {
+		return c.JSON(fiber.Map{"status": "ready"})
+	}
+	resp := fiber.Map{"status": "ready", "warmup": warmup.progress(c.QueryBool("verbose"))}
+	if !warmup.ready() {
+		resp["status"] = "warming_up"
+		c.Set(fiber.HeaderRetryAfter, "1")
+		return c.Status(fiber.StatusServiceUnavailable).JSON(resp)
+	}
+	return c.JSON(resp)
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/kubestellar/console/pkg/api/handlers"
 	"github.com/kubestellar/console/pkg/api/handlers/benchmarks"
 	"github.com/kubestellar/console/pkg/api/handlers/mcp"
+	"github.com/kubestellar/console/pkg/client"
 	"github.com/kubestellar/console/pkg/kagent"
 	"github.com/kubestellar/console/pkg/kagentiprovider"
+	"github.com/kubestellar/console/pkg/testharness"
 )
 
-// setupIntegrationsRoutes registers MCP, timeline, benchmark, GPU, and agent integrations.
+// setupIntegrationsRoutes registers MCP, timeline, incident, benchmark, GPU, and agent integrations.
 func (s *Server) setupIntegrationsRoutes(routes *routeSetupContext) {
 	api := routes.api
 
@@ -34,13 +36,46 @@ func (s *Server) setupIntegrationsRoutes(routes *routeSetupContext) {
 		namespaces = handlers.NewNamespaceHandler(s.store, s.k8sClient)
 		routes.namespaces = namespaces
 	}
-	s.setupMCPRoutes(api, namespaces)
+	s.setupMCPRoutes(api, namespaces, routes.featureFlagsHandler(s.store))
 	s.setupGitOpsRoutes(api)
-	s.setupK8sResourceRoutes(api, routes.aiLimiter)
+	s.setupK8sResourceRoutes(api, routes.aiLimiter, routes.idempotent)
+	// Opens a signed session for the in-cluster UI proxy registered in
+	// setupAuthRoutes. Editor/admin only.
+	api.Post("/clusters/:cluster/service-proxy", routes.serviceProxy.CreateSession)
+	// Read-only passthrough to the cluster's API server. Registered for every
+	// method so writes are refused and audited rather than falling through.
+	api.All("/clusters/:cluster/proxy/*", handlers.NewAPIProxyHandler(s.k8sClient, s.store).Proxy)
 
-	benchmarkHandlers := benchmarks.NewBenchmarkHandlers(s.config.BenchmarkGoogleDriveAPIKey, s.config.BenchmarkFolderID)
+	benchmarkHandlers := routes.benchmarks
+	if benchmarkHandlers == nil {
+		benchmarkHandlers = s.newBenchmarkHandlers()
+		routes.benchmarks = benchmarkHandlers
+	}
 	api.Get("/benchmarks/reports", benchmarkHandlers.GetReports)
 	api.Get("/benchmarks/reports/stream", benchmarkHandlers.StreamReports)
+	api.Get("/benchmarks/search", benchmarkHandlers.SearchReports)
+	api.Get("/benchmarks/compare", benchmarkHandlers.CompareReports)
+	api.Get("/benchmarks/scenarios", benchmarkHandlers.GetScenarios)
+	benchmarkHandlers.SetOperationManager(routes.operationManager(s.hub, s.lifecycle.done))
+	api.Post("/benchmarks/refresh", benchmarkHandlers.RefreshReports)
+	benchmarkHandlers.SetAnnotationStore(s.store)
+	api.Get("/benchmarks/annotations", benchmarkHandlers.ListAnnotations)
+	api.Put("/benchmarks/annotations/:uid", benchmarkHandlers.PutAnnotation)
+	api.Delete("/benchmarks/annotations/:uid", benchmarkHandlers.DeleteAnnotation)
+	benchmarkHandlers.StartRetentionPruner(s.lifecycle.done)
+	benchmarkHandlers.SetRegressionAlerts(func(alert benchmarks.RegressionAlert) {
+		s.hub.BroadcastAll(handlers.Message{Type: benchmarks.BenchmarkRegressionType, Data: alert})
+	})
+	benchmarkHandlers.SetIngestWebhook(s.config.BenchmarkWebhookToken, func(added benchmarks.BenchmarksAdded) {
+		s.hub.BroadcastAll(handlers.Message{Type: benchmarks.BenchmarksAddedType, Data: added})
+	})
+	benchmarkAdmin := benchmarks.NewBenchmarkAdminHandlers(benchmarkHandlers, s.store)
+	api.Get("/admin/benchmarks/retention", benchmarkAdmin.GetRetention)
+	api.Delete("/admin/benchmarks/experiments/:experiment", benchmarkAdmin.PurgeExperiment)
+
+	incidents := handlers.NewIncidentHandler(s.store,
+		handlers.NewAgentPredictionSource(kcAgentBaseURL, s.config.AgentToken), benchmarkHandlers)
+	api.Get("/incidents/:window/correlate", incidents.Correlate)
 
 	gpuCapacity := handlers.ClusterCapacityProvider(func(ctx context.Context, cluster string) int {
 		if s.k8sClient == nil {
@@ -57,7 +92,7 @@ func (s *Server) setupIntegrationsRoutes(routes *routeSetupContext) {
 		return total
 	})
 	gpuHandler := handlers.NewGPUHandler(s.store, gpuCapacity, s.k8sClient)
-	api.Post("/gpu/reservations", gpuHandler.CreateReservation)
+	api.Post("/gpu/reservations", routes.idempotent, gpuHandler.CreateReservation)
 	api.Get("/gpu/reservations", gpuHandler.ListReservations)
 	api.Get("/gpu/reservations/:id", gpuHandler.GetReservation)
 	api.Put("/gpu/reservations/:id", gpuHandler.UpdateReservation)
@@ -93,3 +128,28 @@ func (s *Server) setupIntegrationsRoutes(routes *routeSetupContext) {
 	api.Post("/kagenti-provider/tools/call", kagentiProviderHandler.CallTool)
 	api.Post("/kagenti-provider/tools/call-direct", kagentiProviderHandler.CallToolDirect)
 }
+
+// newBenchmarkHandlers returns the benchmark handlers over the configured
+// source: the fake-mode Drive harness, BENCHMARK_SOURCE, or Google Drive
+// with a service account or an API key.
+func (s *Server) newBenchmarkHandlers() *benchmarks.BenchmarkHandlers {
+	if s.harness != nil {
+		h := benchmarks.NewBenchmarkHandlers(fakeModeDriveAPIKey, testharness.DriveRootFolderID)
+		h.SetHTTPClient(s.harness.Drive.Client())
+		return h
+	}
+	h := benchmarks.NewBenchmarkHandlers(s.config.BenchmarkGoogleDriveAPIKey, s.config.BenchmarkFolderID)
+	if s.config.BenchmarkGoogleCredentials != "" {
+		if err := h.SetServiceAccount(s.config.BenchmarkGoogleCredentials); err != nil {
+			slog.Error("[Server] Google service account not usable, falling back to the Drive API key", "error", err)
+		}
+	}
+	if s.config.BenchmarkSource != "" {
+		if src, err := benchmarks.NewBenchmarkSource(s.config.BenchmarkSource, client.External); err != nil {
+			slog.Error("[Server] benchmark source not usable, benchmark data is unavailable", "error", err)
+		} else {
+			h.SetSource(src)
+		}
+	}
+	return h
+}
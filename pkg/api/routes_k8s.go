@@ -5,14 +5,16 @@ import (
 
 	"github.com/kubestellar/console/pkg/api/handlers"
 	"github.com/kubestellar/console/pkg/api/handlers/workloads"
+	"github.com/kubestellar/console/pkg/velero"
 )
 
 // setupK8sResourceRoutes registers Kubernetes resource routes including MCS,
 // Gateway API, CRDs, workloads, cluster groups, and related endpoints.
 // The workload handlers are stored in the background service group because they
 // have startup side effects (cache refresh, persisted groups).
-// aiLimiter is a per-user rate limiter applied to AI-calling endpoints (#17294).
-func (s *Server) setupK8sResourceRoutes(api fiber.Router, aiLimiter fiber.Handler) {
+// aiLimiter is a per-user rate limiter applied to AI-calling endpoints (#17294),
+// and idempotent replays retried creates (see middleware.Idempotency).
+func (s *Server) setupK8sResourceRoutes(api fiber.Router, aiLimiter, idempotent fiber.Handler) {
 	// MCS (Multi-Cluster Service) routes
 	mcsHandlers := handlers.NewMCSHandlers(s.k8sClient, s.hub)
 	api.Get("/mcs/status", mcsHandlers.GetMCSStatus)
@@ -36,6 +38,89 @@ func (s *Server) setupK8sResourceRoutes(api fiber.Router, aiLimiter fiber.Handle
 	crdHandlers := handlers.NewCRDHandlers(s.k8sClient)
 	api.Get("/crds", crdHandlers.ListCRDs)
 
+	// Resource explorer routes (generic GVR browser). Read-only; patches and
+	// RBAC capability hints are served by kc-agent under the user's kubeconfig.
+	explorerHandlers := handlers.NewResourceExplorerHandlers(s.k8sClient)
+	api.Get("/explorer/:cluster/resources", explorerHandlers.ListAPIResources)
+	api.Get("/explorer/:cluster/objects", explorerHandlers.ListObjects)
+	api.Get("/explorer/:cluster/object", explorerHandlers.GetObject)
+	api.Get("/explorer/:cluster/schema", explorerHandlers.GetSchema)
+
+	// YAML editor validation: schema check plus server-side dry-run apply.
+	// Nothing is persisted. Apply is editor/admin only and runs local policy
+	// checks (privileged pods, resource limits, ...) before the cluster sees
+	// the manifest.
+	manifestHandlers := handlers.NewManifestHandlers(s.k8sClient, s.store)
+	api.Post("/clusters/:cluster/validate-manifest", manifestHandlers.ValidateManifest)
+	api.Get("/manifest-policies", manifestHandlers.ListPolicies)
+	api.Post("/clusters/:cluster/apply", manifestHandlers.ApplyManifest)
+
+	// Workload snapshots: a workload plus the ConfigMaps and Secrets it
+	// references, stored so they can be re-applied after a risky change.
+	// Secret values are redacted unless an admin opts out.
+	snapshotHandler := handlers.NewWorkloadSnapshotHandler(s.k8sClient, s.store)
+	api.Post("/clusters/:cluster/workloads/:kind/:ns/:name/snapshot", snapshotHandler.CreateSnapshot)
+	api.Get("/clusters/:cluster/workloads/:kind/:ns/:name/snapshots", snapshotHandler.ListSnapshots)
+	api.Get("/workload-snapshots/:id", snapshotHandler.GetSnapshot)
+	api.Post("/workload-snapshots/:id/restore", snapshotHandler.RestoreSnapshot)
+	api.Delete("/workload-snapshots/:id", snapshotHandler.DeleteSnapshot)
+
+	// What-if scaling: bin-packs proposed replicas against each cluster's
+	// node headroom. Read-only.
+	scaleSimulator := handlers.NewScaleSimulationHandler(s.k8sClient)
+	api.Post("/simulate/scale", scaleSimulator.SimulateScale)
+
+	// Pre-upgrade report: removed APIs still in use and PDBs that would
+	// stall node drains. Read-only.
+	upgradeImpact := handlers.NewUpgradeImpactHandler(s.k8sClient, s.persistenceStore)
+	api.Get("/clusters/:cluster/upgrade-impact", upgradeImpact.GetUpgradeImpact)
+
+	// Permission preflight: batched access reviews so the UI can disable
+	// actions the user cannot perform. Read-only; RBAC still applies on use.
+	authzPreflight := handlers.NewAuthzPreflightHandler(s.k8sClient)
+	api.Post("/clusters/:cluster/authz/preflight", authzPreflight.Preflight)
+
+	// Cluster timezones: WorkloadDeployment deployment windows are evaluated
+	// in each cluster's local time. Changes are admin only.
+	clusterTimezones := handlers.NewClusterTimezoneHandler(s.store)
+	api.Get("/cluster-timezones", clusterTimezones.ListTimezones)
+	api.Put("/clusters/:cluster/timezone", clusterTimezones.SetTimezone)
+	api.Delete("/clusters/:cluster/timezone", clusterTimezones.DeleteTimezone)
+
+	// Controlled disruption experiments (admin only): evict a pod or cordon
+	// a node, then time the recovery. Every experiment is audit logged.
+	disruptions := handlers.NewDisruptionHandler(s.k8sClient, s.store)
+	api.Post("/admin/disruptions", disruptions.StartExperiment)
+	api.Get("/admin/disruptions", disruptions.ListExperiments)
+	api.Get("/admin/disruptions/:id", disruptions.GetExperiment)
+
+	// Temporary debug resources (editor/admin): netshoot pods and busybox
+	// jobs from fixed templates, quota'd per user and deleted by a
+	// background sweep once their TTL ends.
+	debugResources := handlers.NewDebugResourceHandler(s.k8sClient, s.store)
+	debugResources.StartSweeper(s.lifecycle.done)
+	api.Get("/debug-resources/templates", debugResources.ListTemplates)
+	api.Get("/clusters/:cluster/debug-resources", debugResources.ListDebugResources)
+	api.Post("/clusters/:cluster/debug-resources", debugResources.CreateDebugResource)
+	api.Delete("/clusters/:cluster/debug-resources/:kind/:namespace/:name", debugResources.DeleteDebugResource)
+
+	// Cluster diagnostics: API latency, clock skew, and DNS and registry
+	// reachability from a short-lived pod (editor/admin unless the pod
+	// checks are skipped).
+	diagnostics := handlers.NewClusterDiagnosticsHandler(s.k8sClient, s.store)
+	api.Post("/clusters/:cluster/diagnostics", diagnostics.RunDiagnostics)
+
+	// Velero: schedules, recent backups and restores, ad-hoc backups
+	// (editor/admin), and alerts for failed backups.
+	veleroHandler := handlers.NewVeleroHandler(s.k8sClient, s.store)
+	api.Get("/clusters/:cluster/velero/schedules", veleroHandler.ListSchedules)
+	api.Get("/clusters/:cluster/velero/backups", veleroHandler.ListBackups)
+	api.Post("/clusters/:cluster/velero/backups", veleroHandler.CreateBackup)
+	api.Get("/clusters/:cluster/velero/restores", veleroHandler.ListRestores)
+	if s.k8sClient != nil && s.lifecycle.done != nil {
+		velero.NewMonitor(s.k8sClient, s.notificationService).Start(s.lifecycle.done)
+	}
+
 	// Lima routes (Lima VM status)
 	limaHandlers := handlers.NewLimaHandlers(s.k8sClient)
 	api.Get("/lima", limaHandlers.ListLima)
@@ -58,6 +143,8 @@ func (s *Server) setupK8sResourceRoutes(api fiber.Router, aiLimiter fiber.Handle
 	// refresh so multi-instance deployments converge on DB state (#10007).
 	workloadHandlers.LoadPersistedClusterGroups()
 	workloadHandlers.StartCacheRefresh()
+	// Serve workloads, groups and clusters to WebSocket "sync" requests.
+	workloadHandlers.RegisterSyncSources(s.hub)
 	s.background.workloadHandlers = workloadHandlers
 	api.Get("/workloads", workloadHandlers.ListWorkloads)
 	api.Get("/workloads/capabilities", workloadHandlers.GetClusterCapabilities)
@@ -74,7 +161,7 @@ func (s *Server) setupK8sResourceRoutes(api fiber.Router, aiLimiter fiber.Handle
 
 	// Cluster Group routes
 	api.Get("/cluster-groups", workloadHandlers.ListClusterGroups)
-	api.Post("/cluster-groups", workloadHandlers.CreateClusterGroup)
+	api.Post("/cluster-groups", idempotent, workloadHandlers.CreateClusterGroup)
 	api.Post("/cluster-groups/sync", workloadHandlers.SyncClusterGroups)
 	api.Post("/cluster-groups/evaluate", workloadHandlers.EvaluateClusterQuery)
 	api.Post("/cluster-groups/ai-query", aiLimiter, workloadHandlers.GenerateClusterQuery)
@@ -5,12 +5,14 @@ import (
 
 	"github.com/kubestellar/console/pkg/api/handlers"
 	"github.com/kubestellar/console/pkg/api/handlers/mcp"
+	"github.com/kubestellar/console/pkg/featureflags"
 )
 
 // setupMCPRoutes registers all /mcp/* routes including SSE streaming
 // variants and the /drasi/proxy/* reverse proxy. The namespaces handler
-// is shared with setupRoutes for the /api/namespaces endpoint.
-func (s *Server) setupMCPRoutes(api fiber.Router, namespaces *handlers.NamespaceHandler) {
+// is shared with setupRoutes for the /api/namespaces endpoint. Tool calls
+// are gated by the ai-tools feature flag.
+func (s *Server) setupMCPRoutes(api fiber.Router, namespaces *handlers.NamespaceHandler, flags *handlers.FeatureFlagsHandler) {
 	// MCP handlers (cluster operations via kubestellar tools and direct k8s)
 	mcpHandlers := mcp.NewMCPHandlers(s.bridge, s.k8sClient, s.store)
 
@@ -52,8 +54,8 @@ api.Post("/mcp/resourcequotas", mcpHandlers.CreateOrUpdateResourceQuota)
 api.Delete("/mcp/resourcequotas", mcpHandlers.DeleteResourceQuota)
 api.Get("/mcp/limitranges", mcpHandlers.GetLimitRanges)
 api.Get("/mcp/pods/logs", mcpHandlers.GetPodLogs)
-api.Post("/mcp/tools/ops/call", mcpHandlers.CallOpsTool)
-api.Post("/mcp/tools/deploy/call", mcpHandlers.CallDeployTool)
+api.Post("/mcp/tools/ops/call", flags.RequireFeature(featureflags.AITools), mcpHandlers.CallOpsTool)
+api.Post("/mcp/tools/deploy/call", flags.RequireFeature(featureflags.AITools), mcpHandlers.CallDeployTool)
 api.Get("/mcp/wasmcloud/hosts", mcpHandlers.GetWasmCloudHosts)
 api.Get("/mcp/wasmcloud/actors", mcpHandlers.GetWasmCloudActors)
 api.Get("/mcp/custom-resources", mcpHandlers.GetCustomResources)
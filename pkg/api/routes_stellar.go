@@ -15,5 +15,11 @@ func (s *Server) setupStellarRoutes(routes *routeSetupContext) {
 		return
 	}
 
-	newStellarRouteGroup(stelStore, s.k8sClient, s.lifecycle.done, s.store).Register(routes.api)
+	group := newStellarRouteGroup(stelStore, s.k8sClient, s.lifecycle.done, s.store)
+	group.Register(routes.api)
+	if s.agentRelay != nil {
+		// Paired agents forward cluster events over the relay; they hold a
+		// client certificate rather than a console session.
+		s.agentRelay.routes.Post("/events", group.handler.IngestAgentEvent)
+	}
 }
@@ -13,14 +13,18 @@ import (
 
 	"github.com/kubestellar/console/pkg/ai"
 	"github.com/kubestellar/console/pkg/api/audit"
+	"github.com/kubestellar/console/pkg/api/handlers"
 	"github.com/kubestellar/console/pkg/api/transport"
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/egress"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/limits"
 	"github.com/kubestellar/console/pkg/mcp"
 	"github.com/kubestellar/console/pkg/notifications"
 	"github.com/kubestellar/console/pkg/safego"
 	"github.com/kubestellar/console/pkg/settings"
 	"github.com/kubestellar/console/pkg/store"
+	"github.com/kubestellar/console/pkg/testharness"
 )
 
 const (
@@ -48,6 +52,9 @@ type Server struct {
 	auth                *authRuntime
 	background          *backgroundServices
 	quantumCache        *quantumWorkloadCache
+	limits              *limits.Enforcer
+	harness             *testharness.Harness // non-nil in fake mode
+	agentRelay          *agentRelay          // nil unless AGENT_RELAY_ADDR is set
 }
 
 // NewServer creates a new API server. It starts a temporary loading page
@@ -85,6 +92,8 @@ func NewServer(cfg Config) (*Server, error) {
 		}
 	}
 
+	egress.LogReport("Server", applyAirGap(&cfg))
+
 	// Start a temporary loading page server immediately so the user
 	// sees a loading screen instead of "connection refused" during init.
 	// When BackendPort is set (watchdog mode), listen on that port instead.
@@ -103,24 +112,24 @@ func NewServer(cfg Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to initialize store: %w", err)
 	}
 
+	agentRelay, err := newAgentRelay(cfg, db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize agent relay: %w", err)
+	}
+
 	// Wire up persistent token revocation so revoked JWTs survive restarts.
 	middleware.InitTokenRevocation(db)
 	middleware.InitUserValidation(db)
+	middleware.InitSessionTracking(db, middleware.SessionPolicy{
+		IdleTimeout: cfg.SessionIdleTimeout,
+		MaxLifetime: cfg.SessionMaxLifetime,
+	})
 
 	// Create Fiber app
-	// trustedProxyCIDRs are the RFC-1918 and link-local ranges typical of
-	// Kubernetes ingress controllers, cloud load-balancers, and service meshes.
-	// When EnableTrustedProxyCheck is true, Fiber only honours X-Forwarded-For /
-	// X-Real-Ip from source IPs within these CIDRs, so c.IP() returns the real
-	// client IP instead of the proxy's IP (#7028).
-	trustedProxyCIDRs := []string{
-		"10.0.0.0/8",     // RFC-1918 Class A private
-		"172.16.0.0/12",  // RFC-1918 Class B private
-		"192.168.0.0/16", // RFC-1918 Class C private
-		"fc00::/7",       // IPv6 ULA
-		"127.0.0.0/8",    // loopback
-		"::1/128",        // IPv6 loopback
-	}
+	// X-Forwarded-* headers are only honored from TRUSTED_PROXIES (default:
+	// private ranges), so c.IP() and c.Protocol() cannot be spoofed (#7028).
+	trustProxyCheck, trustedProxies := cfg.trustedProxySettings()
 
 	// BodyLimit defaults to defaultMaxBodyBytes so POST /api/feedback/requests can
 	// accept one advertised 10 MB attachment after base64 expansion. The route's
@@ -139,20 +148,39 @@ func NewServer(cfg Config) (*Server, error) {
 		ReadTimeout:             30 * time.Second,
 		WriteTimeout:            5 * time.Minute, // large static assets on slow networks
 		IdleTimeout:             2 * time.Minute,
-		EnableTrustedProxyCheck: true,
-		TrustedProxies:          trustedProxyCIDRs,
-		ProxyHeader:             "X-Forwarded-For",
+		EnableTrustedProxyCheck: trustProxyCheck,
+		TrustedProxies:          trustedProxies,
+		ProxyHeader:             cfg.ProxyIPHeader,
 	})
 
 	// WebSocket hub
 	hub := transport.NewHub()
 	hub.SetJWTSecret(cfg.JWTSecret)
 	hub.SetDevMode(cfg.DevMode)
+	hub.ConfigureBatching(handlers.ConsoleResourceChangedType, handlers.ConsoleResourceBatching())
+	if cfg.HubBackplaneURL != "" {
+		if bp, err := transport.NewRedisBackplane(cfg.HubBackplaneURL, cfg.HubBackplaneStream); err != nil {
+			slog.Error("[Server] hub backplane disabled — broadcasts will only reach clients on this replica", "error", err)
+		} else {
+			hub.AttachBackplane(bp)
+		}
+	}
+	hub.AttachEventLog(db)
 	safego.GoWith("api/hub-run", func() { hub.Run() })
 
+	// Fake mode replaces every external backend with the deterministic
+	// in-memory fakes from pkg/testharness (used by E2E and Playwright runs).
+	var harness *testharness.Harness
+	if cfg.FakeMode {
+		harness = newFakeModeHarness(cfg)
+	}
+
 	// Initialize Kubernetes multi-cluster client
-	k8sClient, err := k8s.NewMultiClusterClient(cfg.Kubeconfig)
-	if err != nil {
+	var k8sClient *k8s.MultiClusterClient
+	if harness != nil {
+		k8sClient = harness.Clusters
+		k8sClient.WarmupHealthCache()
+	} else if k8sClient, err = k8s.NewMultiClusterClient(cfg.Kubeconfig); err != nil {
 		slog.Warn("Kubernetes client initialization failed — connect clusters via Settings or place a kubeconfig at ~/.kube/config", "error", err)
 	} else {
 		k8sClient.SetOnReload(func() {
@@ -183,7 +211,7 @@ func NewServer(cfg Config) (*Server, error) {
 
 	// Initialize MCP bridge (starts in background)
 	var bridge *mcp.Bridge
-	if cfg.KubestellarOpsPath != "" || cfg.KubestellarDeployPath != "" {
+	if !cfg.FakeMode && (cfg.KubestellarOpsPath != "" || cfg.KubestellarDeployPath != "") {
 		bridge = mcp.NewBridge(mcp.BridgeConfig{
 			KubestellarOpsPath:    cfg.KubestellarOpsPath,
 			KubestellarDeployPath: cfg.KubestellarDeployPath,
@@ -209,8 +237,7 @@ func NewServer(cfg Config) (*Server, error) {
 	slog.Info("Notification service initialized")
 
 	// Initialize persistence store
-	persistenceConfigPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "persistence.json")
-	persistenceStore := store.NewPersistenceStore(persistenceConfigPath)
+	persistenceStore := newPersistenceStore(cfg)
 	if err := persistenceStore.Load(); err != nil {
 		slog.Error("[Server] failed to load persistence config", "error", err)
 	}
@@ -236,13 +263,25 @@ func NewServer(cfg Config) (*Server, error) {
 		auth:                newAuthRuntime(),
 		background:          newBackgroundServices(),
 		quantumCache:        newQuantumWorkloadCache(),
+		limits:              limits.FromEnv(settings.GetSettingsManager()),
+		harness:             harness,
+		agentRelay:          agentRelay,
 	}
 
 	// Enable SQLite persistence for audit entries (#8670 Phase 3).
 	audit.SetStore(db)
 
+	server.background.telemetry = newTelemetryService(k8sClient)
+	if k8sClient != nil {
+		server.background.clusterWarmup = newClusterWarmup(k8sClient)
+	}
+
 	server.setupMiddleware()
 	server.setupRoutes()
+	server.background.telemetry.Start()
+	if server.background.clusterWarmup != nil {
+		server.background.clusterWarmup.start(server.lifecycle.done)
+	}
 
 	// Start GPU utilization background worker (collects hourly snapshots)
 	if k8sClient != nil {
@@ -258,6 +297,18 @@ func NewServer(cfg Config) (*Server, error) {
 	return server, nil
 }
 
+// newPersistenceStore returns the persistence store of cfg, with the
+// settings overrides applied; the caller loads it.
+func newPersistenceStore(cfg Config) *store.PersistenceStore {
+	persistenceStore := store.NewPersistenceStore(filepath.Join(filepath.Dir(cfg.DatabasePath), "persistence.json"))
+	persistenceStore.SetConfigOverrides(func(c *store.PersistenceConfig) {
+		if err := settings.ApplyOverrides(settings.SectionPersistence, c); err != nil {
+			slog.Warn("[Server] failed to apply persistence overrides", "error", err)
+		}
+	})
+	return persistenceStore
+}
+
 func (s *Server) setupRoutes() {
 	s.setupHealthRoutes()
 
@@ -271,6 +322,7 @@ func (s *Server) setupRoutes() {
 	s.setupIntegrationsRoutes(routes)
 	s.setupFeedbackRoutes(routes)
 	s.setupStellarRoutes(routes)
+	s.setupAgentRelayRoutes(routes.api)
 	s.setupWebSocketStaticRoutes(routes)
 }
 
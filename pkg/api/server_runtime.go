@@ -11,7 +11,10 @@ import (
 	"github.com/kubestellar/console/pkg/api/handlers/rewards"
 	"github.com/kubestellar/console/pkg/api/handlers/workloads"
 	"github.com/kubestellar/console/pkg/api/middleware"
+	"github.com/kubestellar/console/pkg/client"
 	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/settings"
+	"github.com/kubestellar/console/pkg/telemetry"
 )
 
 const (
@@ -38,6 +41,8 @@ type backgroundServices struct {
 	gpuUtilWorker    *GPUUtilizationWorker
 	workloadHandlers *workloads.WorkloadHandlers
 	rewardsHandler   *rewards.RewardsHandler
+	telemetry        *telemetry.Service
+	clusterWarmup    *clusterWarmup
 }
 
 type quantumWorkloadCache struct {
@@ -61,6 +66,19 @@ func newBackgroundServices() *backgroundServices {
 	return &backgroundServices{}
 }
 
+// newTelemetryService builds the opt-in usage telemetry service. It reports
+// the number of kubeconfig contexts the console manages as its cluster count.
+func newTelemetryService(k8sClient *k8s.MultiClusterClient) *telemetry.Service {
+	var clusters telemetry.ClusterCounter
+	if k8sClient != nil {
+		clusters = func(ctx context.Context) (int, error) {
+			list, err := k8sClient.ListClusters(ctx)
+			return len(list), err
+		}
+	}
+	return telemetry.New(settings.GetSettingsManager(), Version, clusters, client.External)
+}
+
 func newQuantumWorkloadCache() *quantumWorkloadCache {
 	return &quantumWorkloadCache{}
 }
@@ -22,7 +22,9 @@ import (
 	"github.com/kubestellar/console/pkg/api/handlers/gitops"
 	"github.com/kubestellar/console/pkg/api/middleware"
 	"github.com/kubestellar/console/pkg/fileutil"
+	"github.com/kubestellar/console/pkg/i18n"
 	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/sanitize"
 )
 
 // startLoadingServer starts a temporary HTTP server that serves a loading page.
@@ -36,9 +38,10 @@ import (
 // back with HTTP 200 — which looks like a broken auth contract but is
 // actually the loading page's catch-all `/` handler answering the request.
 // A 503 on /health forces probes to keep polling until the real server is up.
+// /readyz answers the same way.
 func startLoadingServer(addr string) *http.Server {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	starting := func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		// 503 + Retry-After tells orchestrators and smoke tests the backend is
 		// not ready yet. The body still describes the state for human debugging.
@@ -46,7 +49,9 @@ func startLoadingServer(addr string) *http.Server {
 		w.Header().Set("Retry-After", loadingHealthRetryAfterSec)
 		w.WriteHeader(http.StatusServiceUnavailable)
 		w.Write([]byte(`{"status":"starting"}`))
-	})
+	}
+	mux.HandleFunc("/health", starting)
+	mux.HandleFunc("/readyz", starting)
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
 		w.Write([]byte(startupLoadingHTML))
@@ -211,6 +216,12 @@ func (s *Server) Start() error {
 		slog.Info("[Server] OAuth not configured — running in dev mode")
 	}
 
+	if s.agentRelay != nil {
+		if err := s.agentRelay.start(); err != nil {
+			return err
+		}
+	}
+
 	slog.Info("[Server] starting", "addr", addr, "devMode", s.config.DevMode)
 	return s.app.Listen(addr)
 }
@@ -270,6 +281,9 @@ func (s *Server) Shutdown() error {
 		if s.background != nil && s.background.gpuUtilWorker != nil {
 			s.background.gpuUtilWorker.Stop()
 		}
+		if s.background != nil && s.background.telemetry != nil {
+			s.background.telemetry.Stop()
+		}
 		s.hub.Close()
 		// #10007 — stop the periodic cluster group cache refresh goroutine.
 		if s.background != nil && s.background.workloadHandlers != nil {
@@ -301,6 +315,11 @@ func (s *Server) Shutdown() error {
 			shutdownErr = err
 			return
 		}
+		if s.agentRelay != nil {
+			if err := s.agentRelay.app.Shutdown(); err != nil {
+				slog.Error("[Server] agent relay shutdown error", "error", err)
+			}
+		}
 		shutdownErr = s.app.Shutdown()
 	})
 	return shutdownErr
@@ -318,9 +337,16 @@ func customErrorHandler(c *fiber.Ctx, err error) error {
 		message = "Request body too large"
 	}
 
-	return c.Status(code).JSON(fiber.Map{
-		"error": message,
-	})
+	// Validation errors raised with fiber.NewError are rendered from the
+	// message catalog; localize them for the caller like handler errors.
+	loc := i18n.Default()
+	key, localized := loc.Translate(loc.Negotiate(c.Get(fiber.HeaderAcceptLanguage)), message)
+	body := fiber.Map{"error": sanitize.Secrets(localized)}
+	if key != "" {
+		c.Vary(fiber.HeaderAcceptLanguage)
+		body["code"] = key
+	}
+	return c.Status(code).JSON(body)
 }
 
 // devSecretBytes is the number of random bytes used to generate a dev secret (32 bytes = 256 bits).
@@ -0,0 +1,290 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultBackplaneStream is the Redis stream key used when none is
+	// configured.
+	DefaultBackplaneStream = "kc:hub:broadcasts"
+
+	redisDefaultPort = "6379"
+	// redisDialTimeout bounds connecting and authenticating.
+	redisDialTimeout = 5 * time.Second
+	// redisBlock is how long one XREAD waits for new entries. It also bounds
+	// how long a dead connection goes unnoticed.
+	redisBlock = 5 * time.Second
+	// redisReadCount is the most entries one XREAD returns.
+	redisReadCount = 100
+	// redisStreamMaxLen approximately trims the stream. It only needs to
+	// cover entries published while a replica reconnects.
+	redisStreamMaxLen = 10000
+	// redisEnvelopeField is the stream entry field holding the envelope.
+	redisEnvelopeField = "e"
+	// redisRetryMin and redisRetryMax bound the subscriber's reconnect backoff.
+	redisRetryMin = 500 * time.Millisecond
+	redisRetryMax = 30 * time.Second
+)
+
+// errBackplaneClosed is returned by Publish after Close.
+var errBackplaneClosed = errors.New("backplane closed")
+
+// RedisBackplane is a Backplane on a Redis stream. Every replica appends to
+// and reads from the same stream; because entries persist, a subscriber that
+// reconnects resumes from the last entry it read instead of missing the
+// broadcasts published while it was away.
+type RedisBackplane struct {
+	addr     string
+	username string
+	password string
+	db       int
+	tls      bool
+	stream   string
+
+	pubMu sync.Mutex
+	pub   *respConn
+
+	subMu sync.Mutex
+	sub   *respConn
+
+	closed atomic.Bool
+}
+
+// NewRedisBackplane parses a redis:// or rediss:// URL, for example
+// redis://:password@redis:6379/0, and returns a backplane on stream. It does
+// not connect until first used.
+func NewRedisBackplane(rawURL, stream string) (*RedisBackplane, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backplane URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("unsupported backplane URL scheme %q (want redis:// or rediss://)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("invalid backplane URL: missing host")
+	}
+	port := u.Port()
+	if port == "" {
+		port = redisDefaultPort
+	}
+	b := &RedisBackplane{
+		addr:   net.JoinHostPort(u.Hostname(), port),
+		tls:    u.Scheme == "rediss",
+		stream: stream,
+	}
+	if b.stream == "" {
+		b.stream = DefaultBackplaneStream
+	}
+	if u.User != nil {
+		b.username = u.User.Username()
+		b.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if b.db, err = strconv.Atoi(db); err != nil || b.db < 0 {
+			return nil, fmt.Errorf("invalid backplane URL: bad database %q", db)
+		}
+	}
+	return b, nil
+}
+
+func (b *RedisBackplane) dial(ctx context.Context) (*respConn, error) {
+	dctx, cancel := context.WithTimeout(ctx, redisDialTimeout)
+	defer cancel()
+	var conn net.Conn
+	var err error
+	if b.tls {
+		host, _, _ := net.SplitHostPort(b.addr)
+		d := &tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		conn, err = d.DialContext(dctx, "tcp", b.addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(dctx, "tcp", b.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := newRESPConn(conn)
+	_ = conn.SetDeadline(time.Now().Add(redisDialTimeout))
+	if b.password != "" {
+		args := []string{"AUTH", b.password}
+		if b.username != "" {
+			args = []string{"AUTH", b.username, b.password}
+		}
+		if _, err := c.do(args...); err != nil {
+			_ = c.close()
+			return nil, fmt.Errorf("redis AUTH: %w", err)
+		}
+	}
+	if b.db != 0 {
+		if _, err := c.do("SELECT", strconv.Itoa(b.db)); err != nil {
+			_ = c.close()
+			return nil, fmt.Errorf("redis SELECT: %w", err)
+		}
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+// Publish appends env to the stream with XADD.
+func (b *RedisBackplane) Publish(ctx context.Context, env Envelope) error {
+	if b.closed.Load() {
+		return errBackplaneClosed
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	b.pubMu.Lock()
+	defer b.pubMu.Unlock()
+	if b.pub == nil {
+		if b.pub, err = b.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = b.pub.conn.SetDeadline(deadline)
+	}
+	_, err = b.pub.do("XADD", b.stream, "MAXLEN", "~", strconv.Itoa(redisStreamMaxLen), "*", redisEnvelopeField, string(data))
+	if err != nil {
+		// The connection may be mid-reply; start fresh next time.
+		_ = b.pub.close()
+		b.pub = nil
+		return fmt.Errorf("redis XADD: %w", err)
+	}
+	return nil
+}
+
+// Subscribe reads the stream from its current end, reconnecting with backoff
+// after failures. It returns nil when ctx is done or the backplane is closed.
+func (b *RedisBackplane) Subscribe(ctx context.Context, deliver func(Envelope)) error {
+	lastID := ""
+	backoff := redisRetryMin
+	for ctx.Err() == nil && !b.closed.Load() {
+		err := b.readStream(ctx, &lastID, deliver)
+		if ctx.Err() != nil || b.closed.Load() {
+			break
+		}
+		slog.Warn("[WebSocket] backplane subscription interrupted, reconnecting", "retryIn", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+		}
+		backoff = min(backoff*2, redisRetryMax)
+	}
+	return nil
+}
+
+// readStream reads entries after *lastID until an error. On the first call
+// *lastID is empty and reading starts at the stream's current last entry.
+func (b *RedisBackplane) readStream(ctx context.Context, lastID *string, deliver func(Envelope)) error {
+	c, err := b.dial(ctx)
+	if err != nil {
+		return err
+	}
+	b.subMu.Lock()
+	b.sub = c
+	b.subMu.Unlock()
+	stop := context.AfterFunc(ctx, func() { _ = c.close() })
+	defer func() {
+		stop()
+		b.subMu.Lock()
+		b.sub = nil
+		b.subMu.Unlock()
+		_ = c.close()
+	}()
+
+	if *lastID == "" {
+		_ = c.conn.SetDeadline(time.Now().Add(redisDialTimeout))
+		reply, err := c.do("XREVRANGE", b.stream, "+", "-", "COUNT", "1")
+		if err != nil {
+			return fmt.Errorf("redis XREVRANGE: %w", err)
+		}
+		*lastID = "0-0"
+		if entries, ok := reply.([]any); ok && len(entries) > 0 {
+			if id, _, ok := parseStreamEntry(entries[0]); ok {
+				*lastID = id
+			}
+		}
+	}
+
+	blockMs := strconv.FormatInt(redisBlock.Milliseconds(), 10)
+	for {
+		_ = c.conn.SetDeadline(time.Now().Add(redisBlock + redisDialTimeout))
+		reply, err := c.do("XREAD", "COUNT", strconv.Itoa(redisReadCount), "BLOCK", blockMs, "STREAMS", b.stream, *lastID)
+		if err != nil {
+			return fmt.Errorf("redis XREAD: %w", err)
+		}
+		streams, _ := reply.([]any) // nil when BLOCK timed out
+		for _, s := range streams {
+			pair, ok := s.([]any)
+			if !ok || len(pair) != 2 {
+				return errRESPProtocol
+			}
+			entries, _ := pair[1].([]any)
+			for _, e := range entries {
+				id, fields, ok := parseStreamEntry(e)
+				if !ok {
+					return errRESPProtocol
+				}
+				*lastID = id
+				var env Envelope
+				if err := json.Unmarshal([]byte(fields[redisEnvelopeField]), &env); err != nil {
+					slog.Warn("[WebSocket] skipping malformed backplane entry", "id", id, "error", err)
+					continue
+				}
+				deliver(env)
+			}
+		}
+	}
+}
+
+// parseStreamEntry decodes one [id, [field, value, ...]] stream entry.
+func parseStreamEntry(v any) (id string, fields map[string]string, ok bool) {
+	entry, ok := v.([]any)
+	if !ok || len(entry) != 2 {
+		return "", nil, false
+	}
+	id, ok = entry[0].(string)
+	if !ok {
+		return "", nil, false
+	}
+	kv, _ := entry[1].([]any)
+	fields = make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		k, _ := kv[i].(string)
+		val, _ := kv[i+1].(string)
+		fields[k] = val
+	}
+	return id, fields, true
+}
+
+// Close closes both connections and ends Subscribe.
+func (b *RedisBackplane) Close() error {
+	b.closed.Store(true)
+	b.pubMu.Lock()
+	if b.pub != nil {
+		_ = b.pub.close()
+		b.pub = nil
+	}
+	b.pubMu.Unlock()
+	b.subMu.Lock()
+	if b.sub != nil {
+		_ = b.sub.close()
+	}
+	b.subMu.Unlock()
+	return nil
+}
@@ -2,6 +2,7 @@ package transport
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net"
 	"os"
@@ -28,6 +29,18 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+// getEnvDuration reads a Go duration from the environment, falling back to
+// defaultVal. Invalid or negative values return the default.
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+		slog.Warn("[WebSocket] invalid duration, using default", "key", key, "value", v, "default", defaultVal)
+	}
+	return defaultVal
+}
+
 const (
 	// wsInactiveCutoff is how long a client can be idle before being considered inactive.
 	wsInactiveCutoff = 60 * time.Second
@@ -60,6 +73,9 @@ const (
 type Message struct {
 	Type string `json:"type"`
 	Data any    `json:"data"`
+	// Seq numbers broadcasts when an event log is attached, so a client can
+	// replay the ones it missed; see AttachEventLog.
+	Seq int64 `json:"seq,omitempty"`
 }
 
 // Client represents a WebSocket client
@@ -67,6 +83,7 @@ type Client struct {
 	conn      *websocket.Conn
 	netConn   net.Conn // #9736 — captured at creation to avoid racing with releaseConn
 	userID    uuid.UUID
+	sessionID string // sid claim of the token that authenticated the socket; "" for legacy tokens
 	send      chan []byte
 	closeOnce sync.Once // #6584 — guard against double Close on the underlying conn
 	// #7306 — writeMu serializes conn.WriteMessage and conn.Close so that
@@ -74,6 +91,19 @@ type Client struct {
 	// WriteMessage call. gorilla/websocket documents that Close must not be
 	// called concurrently with Write.
 	writeMu sync.Mutex
+	// lastSeen is the UnixNano time of the last inbound frame, read by the
+	// hub's idle reaper.
+	lastSeen atomic.Int64
+	// closeFrame is the close code and reconnect hint the writer sends when
+	// the hub closes this client; nil for client-initiated closes.
+	closeFrame atomic.Pointer[closeFrame]
+	// removed is set once the hub has closed send (guarded by Hub.mu), so
+	// writers outside the Run loop can tell before sending.
+	removed bool
+	// syncing is set while an initial sync streams to this client.
+	syncing atomic.Bool
+	// replaying is set while an event replay streams to this client.
+	replaying atomic.Bool
 }
 
 // closeConn closes the underlying network connection exactly once (#6584).
@@ -94,7 +124,7 @@ func (cl *Client) closeConn() {
 		cl.writeMu.Lock()
 		if cl.netConn != nil {
 			_ = cl.netConn.Close()
-		} else {
+		} else if cl.conn != nil {
 			_ = cl.conn.Close()
 		}
 		cl.writeMu.Unlock()
@@ -127,6 +157,19 @@ type Hub struct {
 	jwtSecret      string // JWT secret for WebSocket auth (guarded by configMu)
 	devMode        bool   // when true, demo-token bypass is allowed (guarded by configMu)
 	maxConnections int    // Maximum allowed concurrent WebSocket connections
+	// batches holds messages of batched topics until their window flushes;
+	// see ConfigureBatching.
+	batches *batcher
+	// relay carries broadcasts to and from other replicas; nil when no
+	// backplane is attached. See AttachBackplane.
+	relay *backplaneRelay
+	// events numbers broadcasts and keeps them for replay; nil when no
+	// event log is attached. See AttachEventLog.
+	events *eventLog
+	// syncMu guards syncSources, the snapshot providers for sync requests;
+	// see RegisterSyncSource.
+	syncMu      sync.RWMutex
+	syncSources map[string]SyncSource
 }
 
 // Client.closeOnce ensures the underlying WebSocket connection is closed
@@ -152,7 +195,7 @@ func NewHub() *Hub {
 	}
 	slog.Info("[WebSocket] connection limit configured", "max", maxConnections)
 
-	return &Hub{
+	h := &Hub{
 		clients:        make(map[*Client]bool),
 		userIndex:      make(map[uuid.UUID][]*Client),
 		demoSessions:   make(map[string]time.Time),
@@ -161,7 +204,10 @@ func NewHub() *Hub {
 		unregister:     make(chan *Client),
 		done:           make(chan struct{}),
 		maxConnections: maxConnections,
+		syncSources:    make(map[string]SyncSource),
 	}
+	h.batches = newBatcher(h.deliverBatch)
+	return h
 }
 
 // SetJWTSecret sets the JWT secret for WebSocket authentication (#6576).
@@ -191,10 +237,15 @@ func (h *Hub) config() (jwtSecret string, devMode bool) {
 func (h *Hub) Run() {
 	evictionTicker := time.NewTicker(wsEvictionInterval)
 	defer evictionTicker.Stop()
+	reapTicker := time.NewTicker(wsReapInterval)
+	defer reapTicker.Stop()
 
 	for {
 		select {
 		case client := <-h.register:
+			if client.lastSeen.Load() == 0 {
+				client.touch()
+			}
 			h.mu.Lock()
 			h.clients[client] = true
 			h.userIndex[client.userID] = append(h.userIndex[client.userID], client)
@@ -203,23 +254,7 @@ func (h *Hub) Run() {
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-				atomic.AddInt64(&h.activeConns, -1) // #11877 — decrement atomic counter
-
-				// Remove from user index
-				clients := h.userIndex[client.userID]
-				for i, c := range clients {
-					if c == client {
-						h.userIndex[client.userID] = append(clients[:i], clients[i+1:]...)
-						break
-					}
-				}
-				if len(h.userIndex[client.userID]) == 0 {
-					delete(h.userIndex, client.userID)
-				}
-			}
+			h.removeClientLocked(client)
 			h.mu.Unlock()
 			slog.Info("[WebSocket] client disconnected", "user", client.userID)
 
@@ -242,23 +277,18 @@ func (h *Hub) Run() {
 					slog.Warn("[WebSocket] slow client buffer full, disconnecting",
 						"user", client.userID)
 					c := client
-					safego.Go(func() {
-						// #11877 — Use a timeout instead of default case so
-						// the unregister is never silently dropped. If the
-						// unregister channel is full for >1s, forcibly close
-						// the connection to prevent goroutine/FD leaks.
-						select {
-						case h.unregister <- c:
-						case <-time.After(1 * time.Second):
-							slog.Warn("[WebSocket] unregister channel full, force-closing client",
-								"user", c.userID)
-							c.closeConn()
-						case <-h.done:
-						}
-					})
+					safego.Go(func() { h.evictSlowClient(c, "") })
 				}
 			}
 
+		case now := <-reapTicker.C:
+			h.mu.Lock()
+			reaped := h.reapIdleLocked(now)
+			h.mu.Unlock()
+			if reaped > 0 {
+				slog.Info("[WebSocket] reaped idle connections", "count", reaped)
+			}
+
 		case <-evictionTicker.C:
 			// Periodically evict stale demo sessions to prevent unbounded map growth
 			h.mu.Lock()
@@ -276,6 +306,31 @@ func (h *Hub) Run() {
 	}
 }
 
+// removeClientLocked drops client from the hub and closes its send channel,
+// which tells the writer goroutine to exit. It is a no-op for clients that
+// were already removed. Callers must hold h.mu.
+func (h *Hub) removeClientLocked(client *Client) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	client.removed = true
+	close(client.send)
+	atomic.AddInt64(&h.activeConns, -1) // #11877 — decrement atomic counter
+
+	// Remove from user index
+	clients := h.userIndex[client.userID]
+	for i, c := range clients {
+		if c == client {
+			h.userIndex[client.userID] = append(clients[:i], clients[i+1:]...)
+			break
+		}
+	}
+	if len(h.userIndex[client.userID]) == 0 {
+		delete(h.userIndex, client.userID)
+	}
+}
+
 // Close shuts down the hub. It is safe to call multiple times;
 // only the first call actually closes the done channel.
 //
@@ -286,9 +341,13 @@ func (h *Hub) Run() {
 func (h *Hub) Close() {
 	h.closeOnce.Do(func() {
 		close(h.done)
+		h.batches.close()
+		h.relay.close()
 
 		h.mu.Lock()
 		for client := range h.clients {
+			client.setCloseFrame(shutdownCloseFrame)
+			client.removed = true
 			close(client.send)
 			delete(h.clients, client)
 		}
@@ -296,6 +355,8 @@ func (h *Hub) Close() {
 			delete(h.userIndex, uid)
 		}
 		h.mu.Unlock()
+
+		h.events.close()
 	})
 }
 
@@ -303,15 +364,35 @@ func (h *Hub) Close() {
 // Uses non-blocking send to prevent callers from blocking indefinitely
 // when the broadcast buffer is full or the hub has been shut down.
 // Messages that cannot be delivered are dropped rather than stalling the sender.
+//
+// Messages of a batched type (see ConfigureBatching) are queued and delivered
+// with the next flush of that user's batch. With a backplane attached the
+// message is also relayed to the user's clients on other replicas.
 func (h *Hub) Broadcast(userID uuid.UUID, msg Message) {
-	data, err := json.Marshal(msg)
-	if err != nil {
-		slog.Error("[WebSocket] failed to marshal message", "error", err)
+	h.relay.publish(false, userID, msg)
+	h.broadcastLocal(userID, msg)
+}
+
+// broadcastLocal sends msg to userID's clients on this replica only.
+func (h *Hub) broadcastLocal(userID uuid.UUID, msg Message) {
+	data := h.events.record(userID, &msg)
+	if h.batches.add(batchTarget{userID: userID}, msg) {
 		return
 	}
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(msg); err != nil {
+			slog.Error("[WebSocket] failed to marshal message", "error", err)
+			return
+		}
+	}
+	h.broadcastEncoded(userID, data, msg.Type)
+}
 
+// broadcastEncoded queues an already-encoded message for userID's clients.
+func (h *Hub) broadcastEncoded(userID uuid.UUID, data []byte, msgType string) {
 	if len(data) > wsMaxBroadcastBytes {
-		slog.Warn("[WebSocket] dropping oversized broadcast message", "user", userID, "type", msg.Type, "bytes", len(data), "limit", wsMaxBroadcastBytes)
+		slog.Warn("[WebSocket] dropping oversized broadcast message", "user", userID, "type", msgType, "bytes", len(data), "limit", wsMaxBroadcastBytes)
 		return
 	}
 
@@ -323,7 +404,7 @@ func (h *Hub) Broadcast(userID uuid.UUID, msg Message) {
 		slog.Info("[WebSocket] hub closed, dropping broadcast", "user", userID)
 	default:
 		// Broadcast buffer is full; drop the message to avoid blocking the sender
-		slog.Info("[WebSocket] broadcast buffer full, dropping message", "user", userID, "type", msg.Type)
+		slog.Info("[WebSocket] broadcast buffer full, dropping message", "user", userID, "type", msgType)
 	}
 }
 
@@ -359,6 +440,7 @@ func (h *Hub) DisconnectUser(userID uuid.UUID) {
 	h.mu.RUnlock()
 
 	for _, client := range clients {
+		client.setCloseFrame(invalidatedCloseFrame)
 		// #7041 — Send a sentinel value through the client's send channel so the
 		// writer goroutine sends the close frame itself, maintaining single-writer
 		// semantics. Previously DisconnectUser called conn.WriteMessage directly,
@@ -375,6 +457,50 @@ func (h *Hub) DisconnectUser(userID uuid.UUID) {
 	slog.Info("[WebSocket] disconnected all connections for user", "user", userID, "count", len(clients))
 }
 
+// SessionRevokedMessage is the message type sent to sockets of a session
+// that was signed out from another device or by an admin, just before the
+// hub closes them with CloseSessionInvalidated.
+const SessionRevokedMessage = "session_revoked"
+
+// DisconnectSession force-logs-out the sockets authenticated by one session
+// of a user. Each socket first receives a session_revoked message so the
+// frontend can show why it was signed out, then the writer sends the
+// session-invalidated close frame. Other sessions of the same user stay
+// connected.
+func (h *Hub) DisconnectSession(userID uuid.UUID, sessionID, reason string) {
+	notice, err := json.Marshal(Message{Type: SessionRevokedMessage, Data: map[string]string{
+		"sessionId": sessionID,
+		"reason":    reason,
+	}})
+	if err != nil {
+		slog.Error("[WebSocket] failed to marshal session_revoked message", "error", err)
+		return
+	}
+
+	count := 0
+	h.mu.RLock()
+	for _, client := range h.userIndex[userID] {
+		if client.sessionID != sessionID || client.removed {
+			continue
+		}
+		count++
+		client.setCloseFrame(invalidatedCloseFrame)
+		// Same single-writer hand-off as DisconnectUser: the notice and the
+		// nil sentinel are both written by the client's writer goroutine.
+		select {
+		case client.send <- notice:
+		default:
+		}
+		select {
+		case client.send <- nil:
+		default:
+			client.closeConn()
+		}
+	}
+	h.mu.RUnlock()
+	slog.Info("[WebSocket] disconnected connections for session", "user", userID, "count", count)
+}
+
 // RecordDemoSession records a heartbeat from a demo mode session.
 // The endpoint is unauthenticated (demo mode only) so we cap the number of
 // unique sessions and reject oversized IDs to limit abuse potential.
@@ -428,6 +554,8 @@ func (h *Hub) GetDemoSessionCount() int {
 // BroadcastAll sends a message to all connected clients.
 // Checks if the hub is shut down before iterating clients, and uses
 // non-blocking sends to avoid stalling on any individual client.
+// Messages of a batched type are delivered with the next flush instead, and
+// with a backplane attached the message is relayed to other replicas too.
 func (h *Hub) BroadcastAll(msg Message) {
 	// Check if the hub has been shut down before doing any work
 	select {
@@ -437,14 +565,30 @@ func (h *Hub) BroadcastAll(msg Message) {
 	default:
 	}
 
-	data, err := json.Marshal(msg)
-	if err != nil {
-		slog.Error("[WebSocket] failed to marshal message", "error", err)
+	h.relay.publish(true, uuid.Nil, msg)
+	h.broadcastAllLocal(msg)
+}
+
+// broadcastAllLocal sends msg to every client on this replica only.
+func (h *Hub) broadcastAllLocal(msg Message) {
+	data := h.events.record(uuid.Nil, &msg)
+	if h.batches.add(batchTarget{all: true}, msg) {
 		return
 	}
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(msg); err != nil {
+			slog.Error("[WebSocket] failed to marshal message", "error", err)
+			return
+		}
+	}
+	h.broadcastAllEncoded(data, msg.Type)
+}
 
+// broadcastAllEncoded queues an already-encoded message for every client.
+func (h *Hub) broadcastAllEncoded(data []byte, msgType string) {
 	if len(data) > wsMaxBroadcastBytes {
-		slog.Warn("[WebSocket] dropping oversized broadcast-all message", "type", msg.Type, "bytes", len(data), "limit", wsMaxBroadcastBytes)
+		slog.Warn("[WebSocket] dropping oversized broadcast-all message", "type", msgType, "bytes", len(data), "limit", wsMaxBroadcastBytes)
 		return
 	}
 
@@ -461,23 +605,9 @@ func (h *Hub) BroadcastAll(msg Message) {
 			// Closing the client via the unregister channel forces a
 			// reconnect, which re-fetches current state.
 			slog.Warn("[WebSocket] slow client buffer full, disconnecting",
-				"user", client.userID, "type", msg.Type)
+				"user", client.userID, "type", msgType)
 			c := client
-			safego.Go(func() {
-				// #12112 — Use a timeout instead of default case so
-				// the unregister is never silently dropped. If the
-				// unregister channel is full for >1s, forcibly close
-				// the connection to prevent goroutine/FD leaks.
-				// This matches the fix applied to Broadcast() in #11877.
-				select {
-				case h.unregister <- c:
-				case <-time.After(1 * time.Second):
-					slog.Warn("[WebSocket] unregister channel full, force-closing client",
-						"user", c.userID, "type", msg.Type)
-					c.closeConn()
-				case <-h.done:
-				}
-			})
+			safego.Go(func() { h.evictSlowClient(c, msgType) })
 		}
 	}
 }
@@ -488,6 +618,7 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 	// This keeps tokens out of URLs and server logs
 
 	var userID uuid.UUID
+	var sessionID string
 	var authenticated bool
 
 	// #6576 — snapshot hub config under lock so subsequent reads are
@@ -555,6 +686,7 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 			return
 		}
 		userID = claims.UserID
+		sessionID = claims.SessionID
 		authenticated = true
 		slog.Info("[WebSocket] authenticated connection", "user", claims.GitHubLogin)
 	} else {
@@ -595,6 +727,7 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 		if err := conn.WriteJSON(Message{Type: "error", Data: map[string]string{"message": "server at capacity"}}); err != nil {
 			slog.Error("[WebSocket] failed to send limit error", "error", err)
 		}
+		_ = conn.WriteControl(websocket.CloseMessage, capacityCloseFrame.payload(), time.Now().Add(wsWriteWait))
 		conn.Close()
 		return
 	}
@@ -606,18 +739,20 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 
 	// Register a pong handler that resets the read deadline whenever the
 	// browser responds to our server-sent pings (automatic in all browsers).
+	client := &Client{
+		conn:      conn,
+		netConn:   conn.NetConn(), // #9736 — capture before releaseConn can nil the wrapper
+		userID:    userID,
+		sessionID: sessionID,
+		send:      make(chan []byte, 256),
+	}
+	client.touch()
 	conn.SetPongHandler(func(string) error {
+		client.touch()
 		conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
 		return nil
 	})
 
-	client := &Client{
-		conn:    conn,
-		netConn: conn.NetConn(), // #9736 — capture before releaseConn can nil the wrapper
-		userID:  userID,
-		send:    make(chan []byte, 256),
-	}
-
 	// Register with the hub, but abort if the hub has already been shut down
 	// (e.g. during server shutdown or a race between Close and a new
 	// connection). A plain blocking send would leak this goroutine forever
@@ -636,7 +771,7 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 	wg.Add(1)
 	safego.Go(func() {
 		defer wg.Done()
-		pingTicker := time.NewTicker(30 * time.Second)
+		pingTicker := time.NewTicker(wsPingInterval)
 		defer func() {
 			pingTicker.Stop()
 			// #6584 — close exactly once across all goroutines.
@@ -647,22 +782,22 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 			select {
 			case msg, ok := <-client.send:
 				if !ok {
+					// The hub closed the channel. If it did so on purpose
+					// (reaper, slow consumer, shutdown) tell the client why.
+					client.writeCloseFrame()
 					return
 				}
 				// #7041 — nil sentinel from DisconnectUser: send a close frame
 				// from the writer goroutine (single-writer semantics) and exit.
 				if msg == nil {
-					client.writeMu.Lock()
-					if err := conn.WriteMessage(websocket.CloseMessage,
-						websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session invalidated")); err != nil {
-						slog.Error("[WebSocket] close frame error", "error", err)
-					}
-					client.writeMu.Unlock()
+					client.setCloseFrame(invalidatedCloseFrame)
+					client.writeCloseFrame()
 					return
 				}
 				// #7306 — Hold writeMu during WriteMessage so closeConn() cannot
 				// race with an in-flight write.
 				client.writeMu.Lock()
+				conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
 				err := conn.WriteMessage(websocket.TextMessage, msg)
 				client.writeMu.Unlock()
 				if err != nil {
@@ -671,7 +806,7 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 				}
 			case <-pingTicker.C:
 				client.writeMu.Lock()
-				err := conn.WriteMessage(websocket.PingMessage, nil)
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
 				client.writeMu.Unlock()
 				if err != nil {
 					return
@@ -688,6 +823,12 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 		case h.unregister <- client:
 		case <-h.done:
 		}
+		// Unregistering closes client.send, so the writer is already on its
+		// way out. When a close frame is pending, let the writer send it
+		// (its write deadline bounds the wait) before the socket is closed.
+		if client.closeFrame.Load() != nil {
+			wg.Wait()
+		}
 		// #6584 — close exactly once across all goroutines.
 		client.closeConn()
 
@@ -699,7 +840,11 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				// Nothing arrived within the idle timeout, not even a pong.
+				client.setCloseFrame(idleCloseFrame)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				slog.Error("[WebSocket] unexpected close error", "error", err)
 			}
 			break
@@ -707,6 +852,7 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 
 		// Reset idle deadline on every received message so active connections
 		// are never dropped while they are communicating.
+		client.touch()
 		conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
 
 		// Handle incoming messages (ping/pong, etc.)
@@ -722,6 +868,10 @@ func (h *Hub) HandleConnection(conn *websocket.Conn) {
 			default:
 				slog.Info("[WebSocket] dropping pong, send channel full", "user", client.userID)
 			}
+		case SyncMessageType:
+			h.handleSyncMessage(client, msg.Data)
+		case ReplayMessageType:
+			h.handleReplayMessage(client, msg.Data)
 		}
 	}
 }
@@ -0,0 +1,202 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// Backplane relays hub broadcasts between backend replicas, so a message
+// broadcast on one replica reaches WebSocket clients connected to another.
+// Implementations must deliver every published envelope at least once and in
+// publish order; the hub drops duplicates by sequence number.
+type Backplane interface {
+	// Publish appends env to the shared log.
+	Publish(ctx context.Context, env Envelope) error
+	// Subscribe calls deliver for every envelope published after it was
+	// called, including this replica's own, until ctx is done or the
+	// backplane is closed. Transient failures are retried internally and
+	// resume after the last delivered envelope, so none are skipped.
+	Subscribe(ctx context.Context, deliver func(Envelope)) error
+	// Close releases the backplane's connections and ends Subscribe.
+	Close() error
+}
+
+// Envelope is one broadcast as carried on the backplane.
+type Envelope struct {
+	// Origin identifies the publishing replica for the lifetime of its
+	// process.
+	Origin string `json:"origin"`
+	// Seq increases by one for every envelope an origin publishes. A gap
+	// means envelopes were lost; a repeat is a redelivery.
+	Seq uint64 `json:"seq"`
+	// All is set for BroadcastAll; otherwise the message is for UserID.
+	All     bool            `json:"all,omitempty"`
+	UserID  uuid.UUID       `json:"userId"`
+	Message json.RawMessage `json:"message"`
+}
+
+const (
+	// backplaneQueueSize bounds envelopes waiting to be published, so a
+	// backplane outage cannot grow memory without limit. Overflow is dropped
+	// and shows up as a sequence gap on the other replicas.
+	backplaneQueueSize = 1024
+	// backplanePublishTimeout bounds a single publish attempt.
+	backplanePublishTimeout = 5 * time.Second
+	// backplaneRetryMin and backplaneRetryMax bound the backoff between
+	// attempts to publish the same envelope.
+	backplaneRetryMin = 100 * time.Millisecond
+	backplaneRetryMax = 5 * time.Second
+	// maxBackplaneOrigins caps how many replicas' sequence positions are
+	// remembered. Origins change on every restart, so the oldest are pruned.
+	maxBackplaneOrigins = 256
+)
+
+// backplaneRelay publishes this hub's broadcasts and delivers other
+// replicas' broadcasts to local clients.
+type backplaneRelay struct {
+	bp     Backplane
+	origin string
+	seq    atomic.Uint64
+	queue  chan Envelope
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	lastSeq map[string]uint64 // origin -> highest sequence delivered
+	origins []string          // origins in first-seen order, for pruning
+}
+
+// AttachBackplane relays broadcasts through bp so every replica attached to
+// the same backplane reaches all clients. It must be called before the hub
+// starts serving; Close detaches and closes bp.
+func (h *Hub) AttachBackplane(bp Backplane) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &backplaneRelay{
+		bp:      bp,
+		origin:  uuid.NewString(),
+		queue:   make(chan Envelope, backplaneQueueSize),
+		cancel:  cancel,
+		lastSeq: make(map[string]uint64),
+	}
+	h.relay = r
+	slog.Info("[WebSocket] backplane attached", "origin", r.origin)
+
+	safego.GoWith("api/hub-backplane-publish", func() { r.publishLoop(ctx) })
+	safego.GoWith("api/hub-backplane-subscribe", func() {
+		if err := bp.Subscribe(ctx, h.receiveRemote); err != nil && ctx.Err() == nil {
+			slog.Error("[WebSocket] backplane subscription ended", "error", err)
+		}
+	})
+}
+
+// publish queues msg for the other replicas. A nil relay publishes nothing.
+func (r *backplaneRelay) publish(all bool, userID uuid.UUID, msg Message) {
+	if r == nil {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("[WebSocket] failed to marshal backplane message", "type", msg.Type, "error", err)
+		return
+	}
+	env := Envelope{Origin: r.origin, Seq: r.seq.Add(1), All: all, UserID: userID, Message: data}
+	select {
+	case r.queue <- env:
+	default:
+		slog.Warn("[WebSocket] backplane queue full, dropping broadcast", "type", msg.Type, "seq", env.Seq)
+	}
+}
+
+// publishLoop publishes queued envelopes in order, retrying each until it
+// succeeds so a backplane outage delays broadcasts instead of losing them.
+func (r *backplaneRelay) publishLoop(ctx context.Context) {
+	for {
+		select {
+		case env := <-r.queue:
+			r.publishWithRetry(ctx, env)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *backplaneRelay) publishWithRetry(ctx context.Context, env Envelope) {
+	backoff := backplaneRetryMin
+	for {
+		pctx, cancel := context.WithTimeout(ctx, backplanePublishTimeout)
+		err := r.bp.Publish(pctx, env)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		slog.Warn("[WebSocket] backplane publish failed, retrying", "seq", env.Seq, "retryIn", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, backplaneRetryMax)
+	}
+}
+
+// accept reports whether env is new, recording its sequence. Redeliveries
+// are rejected and gaps are logged.
+func (r *backplaneRelay) accept(env Envelope) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last, known := r.lastSeq[env.Origin]
+	if known && env.Seq <= last {
+		return false
+	}
+	if known && env.Seq != last+1 {
+		slog.Warn("[WebSocket] backplane sequence gap, broadcasts were lost",
+			"origin", env.Origin, "missed", env.Seq-last-1)
+	}
+	if !known {
+		r.origins = append(r.origins, env.Origin)
+		if len(r.origins) > maxBackplaneOrigins {
+			delete(r.lastSeq, r.origins[0])
+			r.origins = r.origins[1:]
+		}
+	}
+	r.lastSeq[env.Origin] = env.Seq
+	return true
+}
+
+// close stops relaying and closes the backplane. A nil relay is a no-op.
+func (r *backplaneRelay) close() {
+	if r == nil {
+		return
+	}
+	r.cancel()
+	if err := r.bp.Close(); err != nil {
+		slog.Debug("[WebSocket] backplane close error", "error", err)
+	}
+}
+
+// receiveRemote delivers another replica's broadcast to local clients.
+func (h *Hub) receiveRemote(env Envelope) {
+	if env.Origin == h.relay.origin || !h.relay.accept(env) {
+		return
+	}
+	var wire struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(env.Message, &wire); err != nil {
+		slog.Warn("[WebSocket] dropping malformed backplane message", "origin", env.Origin, "seq", env.Seq, "error", err)
+		return
+	}
+	msg := Message{Type: wire.Type, Data: wire.Data}
+	if env.All {
+		h.broadcastAllLocal(msg)
+	} else {
+		h.broadcastLocal(env.UserID, msg)
+	}
+}
@@ -0,0 +1,191 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRelayWait = 2 * time.Second
+
+// memLog is an in-memory shared log standing in for Redis.
+type memLog struct {
+	mu      sync.Mutex
+	entries []Envelope
+	notify  chan struct{}
+}
+
+func newMemLog() *memLog { return &memLog{notify: make(chan struct{})} }
+
+// memBackplane is one replica's view of a memLog. It replays the whole log to
+// new subscribers, which the hub's own-origin and sequence checks tolerate.
+type memBackplane struct {
+	log       *memLog
+	failFirst atomic.Int32 // fail this many publishes before succeeding
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newMemBackplane(log *memLog) *memBackplane {
+	return &memBackplane{log: log, closed: make(chan struct{})}
+}
+
+func (m *memBackplane) Publish(_ context.Context, env Envelope) error {
+	if m.failFirst.Add(-1) >= 0 {
+		return errors.New("backplane unavailable")
+	}
+	m.log.mu.Lock()
+	m.log.entries = append(m.log.entries, env)
+	close(m.log.notify)
+	m.log.notify = make(chan struct{})
+	m.log.mu.Unlock()
+	return nil
+}
+
+func (m *memBackplane) Subscribe(ctx context.Context, deliver func(Envelope)) error {
+	pos := 0
+	for {
+		m.log.mu.Lock()
+		pending := append([]Envelope(nil), m.log.entries[pos:]...)
+		notify := m.log.notify
+		m.log.mu.Unlock()
+		for _, env := range pending {
+			deliver(env)
+		}
+		pos += len(pending)
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil
+		case <-m.closed:
+			return nil
+		}
+	}
+}
+
+func (m *memBackplane) Close() error {
+	m.closeOnce.Do(func() { close(m.closed) })
+	return nil
+}
+
+func addTestClient(h *Hub, userID uuid.UUID) *Client {
+	c := &Client{userID: userID, send: make(chan []byte, 8)}
+	h.mu.Lock()
+	h.clients[c] = true
+	h.userIndex[userID] = append(h.userIndex[userID], c)
+	h.mu.Unlock()
+	return c
+}
+
+func receiveType(t *testing.T, ch <-chan []byte) string {
+	t.Helper()
+	select {
+	case raw := <-ch:
+		var msg Message
+		require.NoError(t, json.Unmarshal(raw, &msg))
+		return msg.Type
+	case <-time.After(testRelayWait):
+		t.Fatal("message was not delivered")
+		return ""
+	}
+}
+
+func TestBackplane_BroadcastAllReachesOtherReplica(t *testing.T) {
+	log := newMemLog()
+	a, b := NewHub(), NewHub()
+	a.AttachBackplane(newMemBackplane(log))
+	b.AttachBackplane(newMemBackplane(log))
+	defer a.Close()
+	defer b.Close()
+
+	onA := addTestClient(a, uuid.New())
+	onB := addTestClient(b, uuid.New())
+
+	a.BroadcastAll(Message{Type: "settings_updated", Data: map[string]string{"k": "v"}})
+
+	assert.Equal(t, "settings_updated", receiveType(t, onB.send))
+	assert.Equal(t, "settings_updated", receiveType(t, onA.send))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, onA.send, "a replica must not redeliver its own broadcast")
+}
+
+func TestBackplane_BroadcastReachesUserOnOtherReplica(t *testing.T) {
+	log := newMemLog()
+	a, b := NewHub(), NewHub()
+	a.AttachBackplane(newMemBackplane(log))
+	b.AttachBackplane(newMemBackplane(log))
+	defer a.Close()
+	defer b.Close()
+
+	userID := uuid.New()
+	a.Broadcast(userID, Message{Type: "notification", Data: "hi"})
+
+	// b's Run loop is not running, so read its broadcast queue directly.
+	select {
+	case bm := <-b.broadcast:
+		assert.Equal(t, userID, bm.userID)
+		var msg Message
+		require.NoError(t, json.Unmarshal(bm.data, &msg))
+		assert.Equal(t, "notification", msg.Type)
+		assert.Equal(t, "hi", msg.Data)
+	case <-time.After(testRelayWait):
+		t.Fatal("broadcast was not relayed")
+	}
+}
+
+func TestBackplane_PublishRetriesUntilDelivered(t *testing.T) {
+	log := newMemLog()
+	flaky := newMemBackplane(log)
+	flaky.failFirst.Store(2)
+	a, b := NewHub(), NewHub()
+	a.AttachBackplane(flaky)
+	b.AttachBackplane(newMemBackplane(log))
+	defer a.Close()
+	defer b.Close()
+
+	onB := addTestClient(b, uuid.New())
+	a.BroadcastAll(Message{Type: "first"})
+	a.BroadcastAll(Message{Type: "second"})
+
+	assert.Equal(t, "first", receiveType(t, onB.send))
+	assert.Equal(t, "second", receiveType(t, onB.send), "order is kept across retries")
+}
+
+func TestBackplaneRelay_Accept(t *testing.T) {
+	r := &backplaneRelay{lastSeq: make(map[string]uint64)}
+
+	assert.True(t, r.accept(Envelope{Origin: "a", Seq: 5}), "joining mid-stream is fine")
+	assert.True(t, r.accept(Envelope{Origin: "a", Seq: 6}))
+	assert.False(t, r.accept(Envelope{Origin: "a", Seq: 6}), "redelivery is dropped")
+	assert.False(t, r.accept(Envelope{Origin: "a", Seq: 3}))
+	assert.True(t, r.accept(Envelope{Origin: "a", Seq: 9}), "a gap is logged but delivered")
+	assert.True(t, r.accept(Envelope{Origin: "b", Seq: 1}))
+}
+
+func TestBackplaneRelay_PrunesOldOrigins(t *testing.T) {
+	r := &backplaneRelay{lastSeq: make(map[string]uint64)}
+	for i := 0; i <= maxBackplaneOrigins; i++ {
+		r.accept(Envelope{Origin: fmt.Sprint(i), Seq: 1})
+	}
+	assert.Len(t, r.lastSeq, maxBackplaneOrigins)
+	_, kept := r.lastSeq["0"]
+	assert.False(t, kept, "the oldest origin is pruned first")
+}
+
+func TestHubWithoutBackplane(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	c := addTestClient(h, uuid.New())
+
+	h.BroadcastAll(Message{Type: "local"})
+	assert.Equal(t, "local", receiveType(t, c.send))
+}
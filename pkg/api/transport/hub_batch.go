@@ -0,0 +1,202 @@
+package transport
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BatchMessageType is the Message.Type of a flushed batch. Its Data is a
+// BatchData.
+const BatchMessageType = "batch"
+
+// BatchConfig enables time-window batching for one message type (topic).
+// Messages of that type are held for up to Window and then delivered together
+// as a single "batch" message, so an event storm costs one frame per window
+// instead of one per event.
+type BatchConfig struct {
+	// Window is how long the first message of a batch may wait before the
+	// batch is flushed.
+	Window time.Duration
+	// MaxEvents flushes a batch early once it holds this many events. Zero
+	// means no limit.
+	MaxEvents int
+	// Key returns the coalescing key of a message's Data. Within one window a
+	// message replaces the pending message with the same key, keeping its
+	// position, so only the latest state of each resource is sent. Nil, or an
+	// empty key, disables coalescing for that message.
+	Key func(data any) string
+}
+
+// BatchData is the payload of a batch message.
+type BatchData struct {
+	Topic  string `json:"topic"`
+	Events []any  `json:"events"`
+	// Coalesced is how many messages were replaced by a later message for
+	// the same key before the flush.
+	Coalesced int `json:"coalesced,omitempty"`
+	// Seq is the highest Seq of the batched messages, when they are
+	// numbered; see AttachEventLog.
+	Seq int64 `json:"seq,omitempty"`
+}
+
+// batchTarget identifies one pending batch: a topic for one user, or for all
+// clients when all is set.
+type batchTarget struct {
+	all    bool
+	userID uuid.UUID
+	topic  string
+}
+
+type pendingBatch struct {
+	events    []any
+	index     map[string]int // coalescing key -> position in events
+	coalesced int
+	seq       int64
+	timer     *time.Timer
+}
+
+// batcher holds pending batches per target. It never calls back into the hub
+// while holding mu.
+type batcher struct {
+	mu      sync.Mutex
+	configs map[string]BatchConfig
+	pending map[batchTarget]*pendingBatch
+	closed  bool
+	flush   func(target batchTarget, data BatchData, single Message)
+}
+
+func newBatcher(flush func(batchTarget, BatchData, Message)) *batcher {
+	return &batcher{
+		configs: make(map[string]BatchConfig),
+		pending: make(map[batchTarget]*pendingBatch),
+		flush:   flush,
+	}
+}
+
+// ConfigureBatching enables batching for messages of type topic, replacing
+// any previous configuration. A zero Window disables batching for the topic;
+// messages already pending are still flushed on schedule.
+func (h *Hub) ConfigureBatching(topic string, cfg BatchConfig) {
+	h.batches.mu.Lock()
+	defer h.batches.mu.Unlock()
+	if cfg.Window <= 0 {
+		delete(h.batches.configs, topic)
+		return
+	}
+	h.batches.configs[topic] = cfg
+	slog.Info("[WebSocket] batching enabled", "topic", topic, "window", cfg.Window, "maxEvents", cfg.MaxEvents)
+}
+
+// add queues msg if its type is batched and reports whether it did. A nil
+// batcher batches nothing.
+func (b *batcher) add(target batchTarget, msg Message) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	cfg, ok := b.configs[msg.Type]
+	if !ok || b.closed {
+		b.mu.Unlock()
+		return false
+	}
+	target.topic = msg.Type
+
+	p := b.pending[target]
+	if p == nil {
+		p = &pendingBatch{index: make(map[string]int)}
+		b.pending[target] = p
+		p.timer = time.AfterFunc(cfg.Window, func() { b.flushTarget(target) })
+	}
+
+	key := ""
+	if cfg.Key != nil {
+		key = cfg.Key(msg.Data)
+	}
+	if i, dup := p.index[key]; key != "" && dup {
+		p.events[i] = msg.Data
+		p.coalesced++
+	} else {
+		if key != "" {
+			p.index[key] = len(p.events)
+		}
+		p.events = append(p.events, msg.Data)
+	}
+	p.seq = max(p.seq, msg.Seq)
+	full := cfg.MaxEvents > 0 && len(p.events) >= cfg.MaxEvents
+	b.mu.Unlock()
+
+	if full {
+		b.flushTarget(target)
+	}
+	return true
+}
+
+// flushTarget delivers and clears the pending batch for target, if any.
+func (b *batcher) flushTarget(target batchTarget) {
+	b.mu.Lock()
+	p := b.pending[target]
+	if p == nil {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, target)
+	p.timer.Stop()
+	closed := b.closed
+	b.mu.Unlock()
+
+	if closed || len(p.events) == 0 {
+		return
+	}
+	// A lone message is sent unchanged so quiet periods look exactly as they
+	// did without batching.
+	var single Message
+	if len(p.events) == 1 && p.coalesced == 0 {
+		single = Message{Type: target.topic, Data: p.events[0], Seq: p.seq}
+	}
+	b.flush(target, BatchData{Topic: target.topic, Events: p.events, Coalesced: p.coalesced, Seq: p.seq}, single)
+}
+
+// close drops every pending batch and stops accepting new ones.
+func (b *batcher) close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for target, p := range b.pending {
+		p.timer.Stop()
+		delete(b.pending, target)
+	}
+}
+
+// deliverBatch sends a flushed batch, splitting it when the encoded message
+// would exceed wsMaxBroadcastBytes so large storms are not dropped whole.
+func (h *Hub) deliverBatch(target batchTarget, data BatchData, single Message) {
+	msg := single
+	if single.Type == "" {
+		msg = Message{Type: BatchMessageType, Data: data}
+	}
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("[WebSocket] failed to marshal batch", "topic", target.topic, "error", err)
+		return
+	}
+	if len(encoded) > wsMaxBroadcastBytes && len(data.Events) > 1 {
+		half := len(data.Events) / 2
+		// Only the second half carries Seq, so a client that receives just
+		// the first still replays the rest.
+		h.deliverBatch(target, BatchData{Topic: data.Topic, Events: data.Events[:half], Coalesced: data.Coalesced}, Message{})
+		h.deliverBatch(target, BatchData{Topic: data.Topic, Events: data.Events[half:], Seq: data.Seq}, Message{})
+		return
+	}
+	if target.all {
+		h.broadcastAllEncoded(encoded, msg.Type)
+	} else {
+		h.broadcastEncoded(target.userID, encoded, msg.Type)
+	}
+}
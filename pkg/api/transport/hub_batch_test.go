@@ -0,0 +1,231 @@
+package transport
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBatchTopic  = "resource_changed"
+	testBatchWindow = 20 * time.Millisecond
+	testBatchWait   = 2 * time.Second
+)
+
+type testEvent struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+func testEventKey(data any) string {
+	if ev, ok := data.(testEvent); ok {
+		return ev.Name
+	}
+	return ""
+}
+
+type flushed struct {
+	target batchTarget
+	data   BatchData
+	single Message
+}
+
+// recordingBatcher returns a batcher whose flushes are sent on the returned
+// channel.
+func recordingBatcher(cfg BatchConfig) (*batcher, chan flushed) {
+	out := make(chan flushed, 16)
+	b := newBatcher(func(target batchTarget, data BatchData, single Message) {
+		out <- flushed{target, data, single}
+	})
+	b.configs[testBatchTopic] = cfg
+	return b, out
+}
+
+func nextFlush(t *testing.T, ch chan flushed) flushed {
+	t.Helper()
+	select {
+	case f := <-ch:
+		return f
+	case <-time.After(testBatchWait):
+		t.Fatal("batch was not flushed")
+		return flushed{}
+	}
+}
+
+func TestBatcher_CoalescesByKey(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: testBatchWindow, Key: testEventKey})
+	defer b.close()
+
+	target := batchTarget{all: true}
+	for _, ev := range []testEvent{{"a", "1"}, {"b", "1"}, {"a", "2"}, {"a", "3"}} {
+		require.True(t, b.add(target, Message{Type: testBatchTopic, Data: ev}))
+	}
+
+	f := nextFlush(t, out)
+	assert.True(t, f.target.all)
+	assert.Equal(t, testBatchTopic, f.data.Topic)
+	assert.Equal(t, []any{testEvent{"a", "3"}, testEvent{"b", "1"}}, f.data.Events, "latest state wins, first position kept")
+	assert.Equal(t, 2, f.data.Coalesced)
+	assert.Empty(t, f.single.Type)
+}
+
+func TestBatcher_SingleMessagePassesThrough(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: testBatchWindow, Key: testEventKey})
+	defer b.close()
+
+	msg := Message{Type: testBatchTopic, Data: testEvent{"a", "1"}}
+	require.True(t, b.add(batchTarget{all: true}, msg))
+
+	f := nextFlush(t, out)
+	assert.Equal(t, msg, f.single)
+}
+
+func TestBatcher_UnconfiguredTopicNotBatched(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: testBatchWindow})
+	defer b.close()
+
+	assert.False(t, b.add(batchTarget{all: true}, Message{Type: "other"}))
+	assert.Empty(t, out)
+
+	var nilBatcher *batcher
+	assert.False(t, nilBatcher.add(batchTarget{all: true}, Message{Type: testBatchTopic}))
+}
+
+func TestBatcher_FlushesEarlyAtMaxEvents(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: time.Hour, MaxEvents: 3})
+	defer b.close()
+
+	target := batchTarget{userID: uuid.New()}
+	for i := 0; i < 4; i++ {
+		b.add(target, Message{Type: testBatchTopic, Data: i})
+	}
+
+	f := nextFlush(t, out)
+	assert.Equal(t, []any{0, 1, 2}, f.data.Events)
+	assert.Equal(t, target.userID, f.target.userID)
+	assert.Empty(t, out, "the fourth event waits for its own window")
+}
+
+func TestBatcher_SeparatesTargets(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: testBatchWindow})
+	defer b.close()
+
+	alice, bob := uuid.New(), uuid.New()
+	b.add(batchTarget{userID: alice}, Message{Type: testBatchTopic, Data: "a1"})
+	b.add(batchTarget{userID: bob}, Message{Type: testBatchTopic, Data: "b1"})
+	b.add(batchTarget{userID: alice}, Message{Type: testBatchTopic, Data: "a2"})
+
+	got := map[uuid.UUID][]any{}
+	for i := 0; i < 2; i++ {
+		f := nextFlush(t, out)
+		got[f.target.userID] = f.data.Events
+	}
+	assert.Equal(t, []any{"a1", "a2"}, got[alice])
+	assert.Equal(t, []any{"b1"}, got[bob])
+}
+
+func TestBatcher_CloseDropsPending(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: testBatchWindow})
+	b.add(batchTarget{all: true}, Message{Type: testBatchTopic, Data: 1})
+	b.close()
+
+	assert.False(t, b.add(batchTarget{all: true}, Message{Type: testBatchTopic, Data: 2}))
+	time.Sleep(3 * testBatchWindow)
+	assert.Empty(t, out)
+}
+
+func TestConfigureBatching_ZeroWindowDisables(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+
+	h.ConfigureBatching(testBatchTopic, BatchConfig{Window: testBatchWindow})
+	assert.True(t, h.batches.add(batchTarget{all: true}, Message{Type: testBatchTopic}))
+	h.ConfigureBatching(testBatchTopic, BatchConfig{})
+	assert.False(t, h.batches.add(batchTarget{all: true}, Message{Type: testBatchTopic}))
+}
+
+func TestBroadcastAll_DeliversBatch(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	h.ConfigureBatching(testBatchTopic, BatchConfig{Window: testBatchWindow, Key: testEventKey})
+
+	client := &Client{userID: uuid.New(), send: make(chan []byte, 4)}
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
+
+	h.BroadcastAll(Message{Type: testBatchTopic, Data: testEvent{"a", "1"}})
+	h.BroadcastAll(Message{Type: testBatchTopic, Data: testEvent{"b", "1"}})
+	h.BroadcastAll(Message{Type: "unbatched"})
+
+	var first Message
+	require.NoError(t, json.Unmarshal(<-client.send, &first))
+	assert.Equal(t, "unbatched", first.Type, "other topics are sent immediately")
+
+	var raw []byte
+	select {
+	case raw = <-client.send:
+	case <-time.After(testBatchWait):
+		t.Fatal("batch was not delivered")
+	}
+	var got struct {
+		Type string    `json:"type"`
+		Data BatchData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &got))
+	assert.Equal(t, BatchMessageType, got.Type)
+	assert.Equal(t, testBatchTopic, got.Data.Topic)
+	assert.Len(t, got.Data.Events, 2)
+}
+
+func TestBroadcast_DeliversToUser(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	h.ConfigureBatching(testBatchTopic, BatchConfig{Window: testBatchWindow})
+
+	userID := uuid.New()
+	h.Broadcast(userID, Message{Type: testBatchTopic, Data: "only"})
+
+	select {
+	case bm := <-h.broadcast:
+		assert.Equal(t, userID, bm.userID)
+		var msg Message
+		require.NoError(t, json.Unmarshal(bm.data, &msg))
+		assert.Equal(t, testBatchTopic, msg.Type, "a lone event is sent unwrapped")
+		assert.Equal(t, "only", msg.Data)
+	case <-time.After(testBatchWait):
+		t.Fatal("batch was not delivered")
+	}
+}
+
+func TestDeliverBatch_SplitsOversized(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+
+	client := &Client{userID: uuid.New(), send: make(chan []byte, 8)}
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
+
+	// Four events that together exceed the frame limit but fit two per frame.
+	big := strings.Repeat("x", wsMaxBroadcastBytes/3)
+	events := []any{big, big, big, big}
+	h.deliverBatch(batchTarget{all: true, topic: testBatchTopic}, BatchData{Topic: testBatchTopic, Events: events}, Message{})
+
+	total := 0
+	for len(client.send) > 0 {
+		raw := <-client.send
+		assert.LessOrEqual(t, len(raw), wsMaxBroadcastBytes)
+		var got struct {
+			Data BatchData `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &got))
+		total += len(got.Data.Events)
+	}
+	assert.Equal(t, len(events), total, "no events lost when splitting")
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"encoding/json"
 	"strings"
 	"sync"
 	"testing"
@@ -292,3 +293,29 @@ func TestDisconnectUser_SendsNilSentinel(t *testing.T) {
 		t.Fatal("expected nil sentinel on send channel")
 	}
 }
+
+func TestDisconnectSession_OnlyClosesThatSession(t *testing.T) {
+	h := newTestHub()
+	userID := uuid.New()
+	revoked := &Client{userID: userID, sessionID: "s-1", send: make(chan []byte, 10)}
+	other := &Client{userID: userID, sessionID: "s-2", send: make(chan []byte, 10)}
+	h.mu.Lock()
+	h.userIndex[userID] = []*Client{revoked, other}
+	h.mu.Unlock()
+
+	h.DisconnectSession(userID, "s-1", "revoked_by_admin")
+
+	require.Len(t, revoked.send, 2, "notice then nil sentinel")
+	var notice Message
+	require.NoError(t, json.Unmarshal(<-revoked.send, &notice))
+	assert.Equal(t, SessionRevokedMessage, notice.Type)
+	data, ok := notice.Data.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "revoked_by_admin", data["reason"])
+	assert.Nil(t, <-revoked.send, "nil sentinel signals close")
+	require.NotNil(t, revoked.closeFrame.Load())
+	assert.Equal(t, CloseSessionInvalidated, revoked.closeFrame.Load().code)
+
+	assert.Empty(t, other.send, "other sessions of the user stay connected")
+	assert.Nil(t, other.closeFrame.Load())
+}
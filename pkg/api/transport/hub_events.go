@@ -0,0 +1,312 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// Message types of event replay. A client that reconnects sends
+// ReplayMessageType with a ReplayRequest holding the highest Seq it
+// received; the hub resends every stored message after it, unchanged, then a
+// single ReplayCompleteMessageType.
+const (
+	ReplayMessageType         = "replay"
+	ReplayCompleteMessageType = "replay_complete"
+)
+
+const (
+	// defaultEventLogSize is how many broadcasts are kept for replay when
+	// HUB_EVENT_LOG_SIZE is unset. 0 disables the event log.
+	defaultEventLogSize = 1000
+	// defaultEventLogMaxAge is how long broadcasts are kept for replay when
+	// HUB_EVENT_LOG_MAX_AGE is unset. A client away for longer needs a full
+	// sync anyway.
+	defaultEventLogMaxAge = time.Hour
+	// eventLogQueueSize bounds broadcasts waiting to be written, so a slow
+	// store cannot grow memory without limit. Overflow is not kept and
+	// shows up as truncated replays.
+	eventLogQueueSize = 1024
+	// eventLogWriteBatch caps how many queued broadcasts one transaction
+	// writes.
+	eventLogWriteBatch = 100
+	// eventLogWriteTimeout bounds a single write.
+	eventLogWriteTimeout = 5 * time.Second
+	// eventLogPruneInterval is how often the log is trimmed to its bounds.
+	eventLogPruneInterval = time.Minute
+	// eventLogCloseTimeout bounds how long Close waits for queued
+	// broadcasts to be written.
+	eventLogCloseTimeout = 5 * time.Second
+	// replayPageSize is how many events one store read returns.
+	replayPageSize = 500
+)
+
+// EventLog stores broadcasts for replay. *store.SQLiteStore implements it.
+type EventLog interface {
+	// AppendHubEvents stores events, ignoring any whose Seq is stored.
+	AppendHubEvents(ctx context.Context, events []models.HubEvent) error
+	// ListHubEvents returns, oldest first, up to limit events after afterSeq
+	// for userID or for every client.
+	ListHubEvents(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]models.HubEvent, error)
+	// HubEventSeqRange returns the lowest and highest stored Seq.
+	HubEventSeqRange(ctx context.Context) (first, last int64, err error)
+	// PruneHubEvents deletes all but the newest keep events and those
+	// created before before, always keeping the newest.
+	PruneHubEvents(ctx context.Context, keep int, before time.Time) (int64, error)
+}
+
+// ReplayRequest is the Data of a client's replay message.
+type ReplayRequest struct {
+	// RequestID is echoed in replay_complete.
+	RequestID string `json:"requestId"`
+	// After is the highest Seq the client received; 0 replays every stored
+	// event.
+	After int64 `json:"after"`
+}
+
+// ReplayComplete is the Data of the replay_complete message that ends a
+// replay.
+type ReplayComplete struct {
+	RequestID string `json:"requestId"`
+	// Replayed is how many messages were resent.
+	Replayed int `json:"replayed"`
+	// Head is the Seq of the latest broadcast.
+	Head int64 `json:"head"`
+	// Truncated is set when events after After are no longer stored, or
+	// After is from another log, e.g. another replica's or a reset
+	// database. The client missed events the replay cannot restore and
+	// should run a full sync.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// eventLog numbers this hub's broadcasts and writes them to an EventLog in
+// the background, trimmed to size events and maxAge.
+type eventLog struct {
+	log    EventLog
+	size   int
+	maxAge time.Duration
+	seq    atomic.Int64
+	queue  chan models.HubEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// AttachEventLog keeps the hub's broadcasts in log, bounded by
+// HUB_EVENT_LOG_SIZE and HUB_EVENT_LOG_MAX_AGE, so clients can replay the
+// ones they missed, including across a backend restart. Every broadcast is
+// stamped with a Seq. It must be called before the hub starts serving;
+// Close writes what is still queued.
+func (h *Hub) AttachEventLog(log EventLog) {
+	size := getEnvInt("HUB_EVENT_LOG_SIZE", defaultEventLogSize)
+	if size <= 0 {
+		slog.Info("[WebSocket] event log disabled", "size", size)
+		return
+	}
+	maxAge := getEnvDuration("HUB_EVENT_LOG_MAX_AGE", defaultEventLogMaxAge)
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventLogWriteTimeout)
+	_, last, err := log.HubEventSeqRange(ctx)
+	cancel()
+	if err != nil {
+		slog.Error("[WebSocket] event log disabled — cannot read its position", "error", err)
+		return
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	l := &eventLog{
+		log:    log,
+		size:   size,
+		maxAge: maxAge,
+		queue:  make(chan models.HubEvent, eventLogQueueSize),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	// Broadcasts still queued when the previous process stopped were sent
+	// but never stored. Skipping past every number they could have had keeps
+	// a number from ever meaning two different broadcasts.
+	l.seq.Store(last + eventLogQueueSize + eventLogWriteBatch)
+	h.events = l
+	slog.Info("[WebSocket] event log attached", "size", size, "maxAge", maxAge, "seq", l.seq.Load())
+
+	safego.GoWith("api/hub-event-log-write", func() { l.writeLoop(ctx) })
+	safego.GoWith("api/hub-event-log-prune", func() { l.pruneLoop(ctx) })
+}
+
+// record stamps msg with the next Seq, queues it for storage and returns it
+// encoded. A nil log leaves msg alone and returns nil.
+func (l *eventLog) record(userID uuid.UUID, msg *Message) []byte {
+	if l == nil {
+		return nil
+	}
+	msg.Seq = l.seq.Add(1)
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("[WebSocket] failed to marshal message", "error", err)
+		return nil
+	}
+	if len(data) > wsMaxBroadcastBytes {
+		// Too large to send, so there is nothing to replay either.
+		return data
+	}
+	select {
+	case l.queue <- models.HubEvent{Seq: msg.Seq, UserID: userID, Type: msg.Type, Message: data, CreatedAt: time.Now()}:
+	default:
+		slog.Warn("[WebSocket] event log queue full, broadcast will not be replayable", "type", msg.Type, "seq", msg.Seq)
+	}
+	return data
+}
+
+// head returns the Seq of the latest broadcast.
+func (l *eventLog) head() int64 {
+	return l.seq.Load()
+}
+
+// writeLoop writes queued broadcasts in batches until ctx is done, then
+// writes what is left.
+func (l *eventLog) writeLoop(ctx context.Context) {
+	defer close(l.done)
+	for {
+		select {
+		case e := <-l.queue:
+			l.write(context.Background(), l.drain(e))
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), eventLogCloseTimeout)
+			defer cancel()
+			for {
+				select {
+				case e := <-l.queue:
+					l.write(flushCtx, l.drain(e))
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// drain returns first and whatever else is queued, up to eventLogWriteBatch.
+func (l *eventLog) drain(first models.HubEvent) []models.HubEvent {
+	batch := []models.HubEvent{first}
+	for len(batch) < eventLogWriteBatch {
+		select {
+		case e := <-l.queue:
+			batch = append(batch, e)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (l *eventLog) write(ctx context.Context, batch []models.HubEvent) {
+	wctx, cancel := context.WithTimeout(ctx, eventLogWriteTimeout)
+	defer cancel()
+	if err := l.log.AppendHubEvents(wctx, batch); err != nil {
+		slog.Warn("[WebSocket] failed to store broadcasts, they will not be replayable",
+			"count", len(batch), "firstSeq", batch[0].Seq, "error", err)
+	}
+}
+
+func (l *eventLog) pruneLoop(ctx context.Context) {
+	ticker := time.NewTicker(eventLogPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pctx, cancel := context.WithTimeout(ctx, eventLogWriteTimeout)
+			n, err := l.log.PruneHubEvents(pctx, l.size, time.Now().Add(-l.maxAge))
+			cancel()
+			if err != nil {
+				slog.Warn("[WebSocket] failed to prune event log", "error", err)
+			} else if n > 0 {
+				slog.Debug("[WebSocket] pruned event log", "deleted", n)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// close stops the loops and waits, up to eventLogCloseTimeout, for queued
+// broadcasts to be written. A nil log is a no-op.
+func (l *eventLog) close() {
+	if l == nil {
+		return
+	}
+	l.cancel()
+	select {
+	case <-l.done:
+	case <-time.After(eventLogCloseTimeout):
+		slog.Warn("[WebSocket] timed out writing queued broadcasts to the event log")
+	}
+}
+
+// handleReplayMessage starts a replay for client. Like a sync, it streams
+// from a separate goroutine, and only one runs per connection at a time.
+func (h *Hub) handleReplayMessage(client *Client, raw any) {
+	var req ReplayRequest
+	if data, err := json.Marshal(raw); err == nil {
+		_ = json.Unmarshal(data, &req)
+	}
+	if client.userID == uuid.Nil {
+		h.sendSyncError(client, req.RequestID, "replay requires an authenticated session")
+		return
+	}
+	if h.events == nil {
+		h.sendSyncError(client, req.RequestID, "event replay is not enabled")
+		return
+	}
+	if !client.replaying.CompareAndSwap(false, true) {
+		h.sendSyncError(client, req.RequestID, "replay already in progress")
+		return
+	}
+	safego.GoWith("ws-replay", func() {
+		defer client.replaying.Store(false)
+		h.runReplay(client, req)
+	})
+}
+
+// runReplay resends client's stored messages after req.After, then sends
+// replay_complete. Live broadcasts keep arriving meanwhile, so a client
+// should skip any Seq it has already applied. It stops early if the client
+// goes away.
+func (h *Hub) runReplay(client *Client, req ReplayRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncSourceTimeout)
+	defer cancel()
+
+	done := ReplayComplete{RequestID: req.RequestID, Head: h.events.head()}
+	first, _, err := h.events.log.HubEventSeqRange(ctx)
+	if err != nil {
+		slog.Warn("[WebSocket] replay failed", "user", client.userID, "error", err)
+		h.sendSyncError(client, req.RequestID, "replay failed")
+		return
+	}
+	done.Truncated = req.After > done.Head || (req.After > 0 && first > req.After+1)
+
+	for after := req.After; ; {
+		events, err := h.events.log.ListHubEvents(ctx, client.userID, after, replayPageSize)
+		if err != nil {
+			slog.Warn("[WebSocket] replay failed", "user", client.userID, "error", err)
+			h.sendSyncError(client, req.RequestID, "replay failed")
+			return
+		}
+		for _, e := range events {
+			if !h.enqueueSync(client, e.Message) {
+				return
+			}
+			after = e.Seq
+			done.Replayed++
+		}
+		if len(events) < replayPageSize {
+			break
+		}
+	}
+	h.sendSyncMessage(client, Message{Type: ReplayCompleteMessageType, Data: done})
+}
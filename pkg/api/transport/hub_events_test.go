@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// memEventLog is an in-memory EventLog.
+type memEventLog struct {
+	mu     sync.Mutex
+	events []models.HubEvent
+}
+
+func (m *memEventLog) AppendHubEvents(_ context.Context, events []models.HubEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *memEventLog) ListHubEvents(_ context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]models.HubEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []models.HubEvent
+	for _, e := range m.events {
+		if e.Seq > afterSeq && (e.UserID == uuid.Nil || e.UserID == userID) && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *memEventLog) HubEventSeqRange(context.Context) (int64, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.events) == 0 {
+		return 0, 0, nil
+	}
+	return m.events[0].Seq, m.events[len(m.events)-1].Seq, nil
+}
+
+func (m *memEventLog) PruneHubEvents(context.Context, int, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *memEventLog) snapshot() []models.HubEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.HubEvent(nil), m.events...)
+}
+
+func storedEvent(seq int64, userID uuid.UUID) models.HubEvent {
+	data, _ := json.Marshal(Message{Type: "deployment_progress", Data: map[string]int64{"step": seq}, Seq: seq})
+	return models.HubEvent{Seq: seq, UserID: userID, Type: "deployment_progress", Message: data}
+}
+
+func decodeSent(t *testing.T, raw []byte) (Message, json.RawMessage) {
+	t.Helper()
+	var msg struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+		Seq  int64           `json:"seq"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &msg))
+	return Message{Type: msg.Type, Seq: msg.Seq}, msg.Data
+}
+
+func TestAttachEventLog_NumbersAndStoresBroadcasts(t *testing.T) {
+	log := &memEventLog{events: []models.HubEvent{storedEvent(10, uuid.Nil)}}
+	h := NewHub()
+	h.AttachEventLog(log)
+	require.NotNil(t, h.events)
+	go h.Run()
+	c := syncTestClient(h, 8)
+
+	h.Broadcast(c.userID, Message{Type: "deployment_progress", Data: "step 1"})
+	h.BroadcastAll(Message{Type: "settings_updated", Data: "theme"})
+
+	start := int64(10 + eventLogQueueSize + eventLogWriteBatch)
+	seqs := map[string]int64{}
+	for range 2 {
+		select {
+		case raw := <-c.send:
+			msg, _ := decodeSent(t, raw)
+			seqs[msg.Type] = msg.Seq
+		case <-time.After(time.Second):
+			t.Fatal("broadcast was not delivered")
+		}
+	}
+	assert.Equal(t, map[string]int64{"deployment_progress": start + 1, "settings_updated": start + 2}, seqs,
+		"numbering resumes past the stored events and anything unwritten")
+
+	h.Close()
+	events := log.snapshot()
+	require.Len(t, events, 3, "Close writes what is queued")
+	assert.Equal(t, c.userID, events[1].UserID)
+	assert.Equal(t, uuid.Nil, events[2].UserID, "broadcasts to everyone are stored without a user")
+	msg, data := decodeSent(t, events[2].Message)
+	assert.Equal(t, start+2, msg.Seq)
+	assert.JSONEq(t, `"theme"`, string(data))
+}
+
+func TestAttachEventLog_Disabled(t *testing.T) {
+	t.Setenv("HUB_EVENT_LOG_SIZE", "0")
+	h := NewHub()
+	defer h.Close()
+	h.AttachEventLog(&memEventLog{})
+	assert.Nil(t, h.events)
+
+	c := syncTestClient(h, 8)
+	h.handleReplayMessage(c, map[string]any{"requestId": "r1", "after": 5})
+	_, data := decodeSent(t, <-c.send)
+	assert.JSONEq(t, `{"message":"event replay is not enabled","requestId":"r1"}`, string(data))
+}
+
+func TestRunReplay(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	c := syncTestClient(h, 16)
+	other := uuid.New()
+	log := &memEventLog{events: []models.HubEvent{
+		storedEvent(3, uuid.Nil), storedEvent(4, c.userID), storedEvent(5, other), storedEvent(6, uuid.Nil),
+	}}
+	h.events = &eventLog{log: log, cancel: func() {}, done: make(chan struct{})}
+	close(h.events.done)
+	h.events.seq.Store(6)
+
+	replay := func(after int64) (seqs []int64, done ReplayComplete) {
+		h.runReplay(c, ReplayRequest{RequestID: "r1", After: after})
+		for {
+			msg, data := decodeSent(t, <-c.send)
+			if msg.Type == ReplayCompleteMessageType {
+				require.NoError(t, json.Unmarshal(data, &done))
+				return seqs, done
+			}
+			seqs = append(seqs, msg.Seq)
+		}
+	}
+
+	seqs, done := replay(3)
+	assert.Equal(t, []int64{4, 6}, seqs, "only the client's own and everyone's events")
+	assert.Equal(t, ReplayComplete{RequestID: "r1", Replayed: 2, Head: 6}, done)
+
+	_, done = replay(0)
+	assert.False(t, done.Truncated, "a new client has nothing to miss")
+	_, done = replay(1)
+	assert.True(t, done.Truncated, "event 2 is no longer stored")
+	_, done = replay(9)
+	assert.True(t, done.Truncated, "a position past the head is from another log")
+}
+
+func TestBatcher_CarriesHighestSeq(t *testing.T) {
+	b, out := recordingBatcher(BatchConfig{Window: testBatchWindow})
+	target := batchTarget{userID: uuid.New()}
+	b.add(target, Message{Type: testBatchTopic, Data: testEvent{Name: "a"}, Seq: 7})
+	b.add(target, Message{Type: testBatchTopic, Data: testEvent{Name: "b"}, Seq: 8})
+	assert.Equal(t, int64(8), nextFlush(t, out).data.Seq)
+
+	b.add(target, Message{Type: testBatchTopic, Data: testEvent{Name: "c"}, Seq: 9})
+	assert.Equal(t, int64(9), nextFlush(t, out).single.Seq, "a lone message keeps its Seq")
+}
@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+const (
+	// wsPingInterval is how often the writer sends a protocol-level ping. It
+	// must be shorter than wsIdleTimeout so the browser's automatic pong
+	// arrives before the read deadline expires; the previous 30s interval
+	// outlived the 20s deadline and dropped every quiet connection.
+	wsPingInterval = wsIdleTimeout * 9 / 10
+	// wsWriteWait bounds every frame write so a peer that stopped reading
+	// cannot pin the writer goroutine (and its file descriptor) forever.
+	wsWriteWait = 10 * time.Second
+	// wsReapInterval is how often Run sweeps the client map for connections
+	// that have gone silent.
+	wsReapInterval = 10 * time.Second
+	// wsReapAfter is how long a client may go without any inbound frame
+	// before the reaper drops it. It allows one full write deadline past the
+	// read deadline so the reader normally gets to exit on its own first.
+	wsReapAfter = wsIdleTimeout + wsWriteWait
+)
+
+// Close codes sent by the hub. 4000-4999 is the private-use range of RFC 6455;
+// clients read the CloseHint in the close reason to decide how to reconnect.
+const (
+	// CloseIdleTimeout means no frame (message, ping or pong) arrived within
+	// the idle timeout. Reconnecting right away is safe.
+	CloseIdleTimeout = 4000
+	// CloseServerAtCapacity means the connection limit was reached.
+	CloseServerAtCapacity = 4001
+	// CloseSessionInvalidated means the user logged out or the session was
+	// revoked. Do not reconnect without authenticating again.
+	CloseSessionInvalidated = 4002
+	// CloseSlowConsumer means the client fell behind and its buffer filled.
+	// Reconnect and refetch state, since broadcasts were dropped.
+	CloseSlowConsumer = 4003
+)
+
+const (
+	// capacityRetryAfter asks rejected clients to back off long enough for
+	// other connections to drain.
+	capacityRetryAfter = 30 * time.Second
+	// shutdownRetryAfter gives a restarting server time to come back up.
+	shutdownRetryAfter = 2 * time.Second
+	// maxCloseReasonBytes is the RFC 6455 limit on a close frame's reason.
+	maxCloseReasonBytes = 123
+)
+
+// CloseHint is the JSON carried in the reason of a hub-initiated close frame.
+type CloseHint struct {
+	Reason       string `json:"reason"`
+	Reconnect    bool   `json:"reconnect"`
+	RetryAfterMs int64  `json:"retryAfterMs,omitempty"`
+}
+
+// closeFrame is a close code plus its hint, queued for the writer goroutine.
+type closeFrame struct {
+	code int
+	hint CloseHint
+}
+
+var (
+	idleCloseFrame        = closeFrame{CloseIdleTimeout, CloseHint{Reason: "idle timeout", Reconnect: true}}
+	capacityCloseFrame    = closeFrame{CloseServerAtCapacity, CloseHint{Reason: "server at capacity", Reconnect: true, RetryAfterMs: capacityRetryAfter.Milliseconds()}}
+	invalidatedCloseFrame = closeFrame{CloseSessionInvalidated, CloseHint{Reason: "session invalidated"}}
+	slowCloseFrame        = closeFrame{CloseSlowConsumer, CloseHint{Reason: "slow consumer", Reconnect: true}}
+	shutdownCloseFrame    = closeFrame{websocket.CloseGoingAway, CloseHint{Reason: "server shutting down", Reconnect: true, RetryAfterMs: shutdownRetryAfter.Milliseconds()}}
+)
+
+// payload encodes f as a close frame body.
+func (f closeFrame) payload() []byte {
+	reason, err := json.Marshal(f.hint)
+	if err != nil || len(reason) > maxCloseReasonBytes {
+		reason = []byte(f.hint.Reason)
+	}
+	return websocket.FormatCloseMessage(f.code, string(reason))
+}
+
+// touch records inbound activity for the reaper.
+func (cl *Client) touch() {
+	cl.lastSeen.Store(time.Now().UnixNano())
+}
+
+// setCloseFrame records why the hub is closing the client. The first reason
+// wins, so a later generic close does not overwrite a more specific hint.
+func (cl *Client) setCloseFrame(f closeFrame) {
+	cl.closeFrame.CompareAndSwap(nil, &f)
+}
+
+// writeCloseFrame sends the recorded close frame, if any. Only the writer
+// goroutine calls it, so it never races the connection wrapper's release.
+func (cl *Client) writeCloseFrame() {
+	f := cl.closeFrame.Load()
+	if f == nil {
+		return
+	}
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
+	if err := cl.conn.WriteControl(websocket.CloseMessage, f.payload(), time.Now().Add(wsWriteWait)); err != nil {
+		slog.Debug("[WebSocket] close frame error", "user", cl.userID, "code", f.code, "error", err)
+	}
+}
+
+// reapIdleLocked drops clients that have sent nothing for wsReapAfter and
+// returns how many were removed. Normally the reader's read deadline closes
+// such connections first; the reaper catches ghosts whose handler exited
+// without unregistering, so broadcasts stop being queued for them.
+// Callers must hold h.mu.
+func (h *Hub) reapIdleLocked(now time.Time) int {
+	cutoff := now.Add(-wsReapAfter).UnixNano()
+	reaped := 0
+	for client := range h.clients {
+		if client.lastSeen.Load() > cutoff {
+			continue
+		}
+		client.setCloseFrame(idleCloseFrame)
+		h.removeClientLocked(client)
+		// The writer sends the close frame when it sees the closed send
+		// channel. If the handler is already gone there is no writer, so
+		// close the socket directly once it has had time to try.
+		time.AfterFunc(wsWriteWait, client.closeConn)
+		reaped++
+	}
+	return reaped
+}
+
+// evictSlowClient unregisters a client whose send buffer is full.
+func (h *Hub) evictSlowClient(c *Client, msgType string) {
+	c.setCloseFrame(slowCloseFrame)
+	// #11877 / #12112 — Use a timeout instead of default case so the
+	// unregister is never silently dropped. If the unregister channel is full
+	// for >1s, forcibly close the connection to prevent goroutine/FD leaks.
+	select {
+	case h.unregister <- c:
+	case <-time.After(1 * time.Second):
+		slog.Warn("[WebSocket] unregister channel full, force-closing client",
+			"user", c.userID, "type", msgType)
+		c.closeConn()
+	case <-h.done:
+	}
+}
@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	fasthttpws "github.com/fasthttp/websocket"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPingIntervalBeatsIdleTimeout(t *testing.T) {
+	assert.Less(t, wsPingInterval, wsIdleTimeout, "pongs must arrive before the read deadline")
+	assert.Greater(t, wsReapAfter, wsIdleTimeout, "the reader should time out before the reaper runs")
+}
+
+func TestCloseFramePayload(t *testing.T) {
+	for _, f := range []closeFrame{idleCloseFrame, capacityCloseFrame, invalidatedCloseFrame, slowCloseFrame, shutdownCloseFrame} {
+		payload := f.payload()
+		require.LessOrEqual(t, len(payload)-2, maxCloseReasonBytes, f.hint.Reason)
+
+		code := int(payload[0])<<8 | int(payload[1])
+		assert.Equal(t, f.code, code)
+		var hint CloseHint
+		require.NoError(t, json.Unmarshal(payload[2:], &hint))
+		assert.Equal(t, f.hint, hint)
+	}
+	assert.False(t, invalidatedCloseFrame.hint.Reconnect, "a logged-out client must not reconnect")
+}
+
+func TestReapIdle(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+
+	now := time.Now()
+	stale := &Client{userID: uuid.New(), send: make(chan []byte, 1)}
+	stale.lastSeen.Store(now.Add(-wsReapAfter - time.Second).UnixNano())
+	fresh := &Client{userID: uuid.New(), send: make(chan []byte, 1)}
+	fresh.lastSeen.Store(now.UnixNano())
+
+	h.mu.Lock()
+	for _, c := range []*Client{stale, fresh} {
+		h.clients[c] = true
+		h.userIndex[c.userID] = append(h.userIndex[c.userID], c)
+		h.activeConns++
+	}
+	reaped := h.reapIdleLocked(now)
+	h.mu.Unlock()
+
+	assert.Equal(t, 1, reaped)
+	assert.Equal(t, 1, h.GetTotalConnectionsCount())
+	assert.Equal(t, 1, h.GetActiveUsersCount())
+	_, ok := <-stale.send
+	assert.False(t, ok, "reaped client's send channel should be closed")
+	require.NotNil(t, stale.closeFrame.Load())
+	assert.Equal(t, CloseIdleTimeout, stale.closeFrame.Load().code)
+	assert.Nil(t, fresh.closeFrame.Load())
+}
+
+func TestSetCloseFrame_FirstWins(t *testing.T) {
+	c := &Client{}
+	c.setCloseFrame(invalidatedCloseFrame)
+	c.setCloseFrame(shutdownCloseFrame)
+	assert.Equal(t, CloseSessionInvalidated, c.closeFrame.Load().code)
+}
+
+// TestDisconnectUser_SendsCloseHint dials a real connection and checks the
+// close frame a logged-out client receives.
+func TestDisconnectUser_SendsCloseHint(t *testing.T) {
+	h := NewHub()
+	h.SetDevMode(true)
+	go h.Run()
+	defer h.Close()
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
+		h.HandleConnection(c)
+	}))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	defer app.Shutdown()
+
+	conn, _, err := fasthttpws.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", ln.Addr()), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "auth", "token": "demo-token"}))
+	var msg Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, "authenticated", msg.Type)
+
+	require.Eventually(t, func() bool { return h.GetTotalConnectionsCount() == 1 }, time.Second, 10*time.Millisecond)
+	h.DisconnectUser(uuid.Nil)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, _, err = conn.ReadMessage()
+	var closeErr *fasthttpws.CloseError
+	require.True(t, errors.As(err, &closeErr), "expected close frame, got %v", err)
+	assert.Equal(t, CloseSessionInvalidated, closeErr.Code)
+	var hint CloseHint
+	require.NoError(t, json.Unmarshal([]byte(closeErr.Text), &hint))
+	assert.False(t, hint.Reconnect)
+	assert.Equal(t, "session invalidated", hint.Reason)
+}
@@ -0,0 +1,279 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// Message types of the initial-sync exchange. A client sends SyncMessageType
+// with a SyncRequest; the hub answers with one SyncChunkMessageType per page
+// of every requested kind, then a single SyncCompleteMessageType.
+const (
+	SyncMessageType         = "sync"
+	SyncChunkMessageType    = "sync_chunk"
+	SyncCompleteMessageType = "sync_complete"
+)
+
+const (
+	// defaultSyncPageSize is used when a request does not set pageSize.
+	defaultSyncPageSize = 200
+	// maxSyncPageSize caps pageSize so one chunk stays well under
+	// wsMaxBroadcastBytes for typical resources.
+	maxSyncPageSize = 1000
+	// syncSourceTimeout bounds each kind's snapshot fetch.
+	syncSourceTimeout = 30 * time.Second
+	// syncSendTimeout is how long a chunk may wait for room in the client's
+	// send buffer before the sync is abandoned. Chunks apply backpressure
+	// instead of evicting the client the way a broadcast would.
+	syncSendTimeout = 5 * time.Second
+	// syncSendRetry is the poll interval while the send buffer is full.
+	syncSendRetry = 10 * time.Millisecond
+)
+
+// errUnknownSyncKind is reported in SyncKindStatus.Error for a requested kind
+// with no registered source.
+var errUnknownSyncKind = errors.New("unknown sync kind")
+
+// SyncSource returns the full current snapshot of one kind for a user. The
+// hub pages the result; sources do not need to.
+type SyncSource func(ctx context.Context, userID uuid.UUID) ([]any, error)
+
+// SyncRequest is the Data of a client's sync message.
+type SyncRequest struct {
+	// RequestID is echoed in every chunk so a client can tell overlapping
+	// syncs apart, e.g. after a reconnect.
+	RequestID string `json:"requestId"`
+	// Kinds limits the snapshot to these kinds. Empty means every
+	// registered kind.
+	Kinds []string `json:"kinds,omitempty"`
+	// PageSize is the number of items per chunk, capped at maxSyncPageSize.
+	PageSize int `json:"pageSize,omitempty"`
+}
+
+// SyncChunk is the Data of a sync_chunk message: one page of one kind.
+type SyncChunk struct {
+	RequestID string `json:"requestId"`
+	Kind      string `json:"kind"`
+	Page      int    `json:"page"`
+	// Offset is the index of Items[0] in the kind's snapshot.
+	Offset int   `json:"offset"`
+	Total  int   `json:"total"`
+	Items  []any `json:"items"`
+	// Last marks the final chunk of Kind. A kind with no items still sends
+	// one empty, last chunk.
+	Last bool `json:"last"`
+}
+
+// SyncKindStatus reports how one kind of a sync finished.
+type SyncKindStatus struct {
+	Kind  string `json:"kind"`
+	Total int    `json:"total"`
+	Error string `json:"error,omitempty"`
+}
+
+// SyncComplete is the Data of the sync_complete message that ends a sync.
+type SyncComplete struct {
+	RequestID string           `json:"requestId"`
+	Kinds     []SyncKindStatus `json:"kinds"`
+}
+
+// RegisterSyncSource makes kind available to sync requests, replacing any
+// previous source for it. A nil source removes the kind.
+func (h *Hub) RegisterSyncSource(kind string, source SyncSource) {
+	h.syncMu.Lock()
+	defer h.syncMu.Unlock()
+	if source == nil {
+		delete(h.syncSources, kind)
+		return
+	}
+	h.syncSources[kind] = source
+}
+
+// syncKinds resolves the kinds a request asks for, in request order with
+// duplicates dropped, or every registered kind sorted by name.
+func (h *Hub) syncKinds(requested []string) []string {
+	if len(requested) > 0 {
+		seen := make(map[string]bool, len(requested))
+		kinds := make([]string, 0, len(requested))
+		for _, k := range requested {
+			if !seen[k] {
+				seen[k] = true
+				kinds = append(kinds, k)
+			}
+		}
+		return kinds
+	}
+	h.syncMu.RLock()
+	defer h.syncMu.RUnlock()
+	kinds := make([]string, 0, len(h.syncSources))
+	for k := range h.syncSources {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func (h *Hub) syncSource(kind string) SyncSource {
+	h.syncMu.RLock()
+	defer h.syncMu.RUnlock()
+	return h.syncSources[kind]
+}
+
+// handleSyncMessage starts a sync for client. Only one sync per connection
+// runs at a time; the snapshot is streamed from a separate goroutine so the
+// reader keeps servicing pings while large kinds are paged out.
+func (h *Hub) handleSyncMessage(client *Client, raw any) {
+	var req SyncRequest
+	if data, err := json.Marshal(raw); err == nil {
+		_ = json.Unmarshal(data, &req)
+	}
+	if client.userID == uuid.Nil {
+		h.sendSyncError(client, req.RequestID, "sync requires an authenticated session")
+		return
+	}
+	if !client.syncing.CompareAndSwap(false, true) {
+		h.sendSyncError(client, req.RequestID, "sync already in progress")
+		return
+	}
+	safego.GoWith("ws-sync", func() {
+		defer client.syncing.Store(false)
+		h.runSync(client, req)
+	})
+}
+
+// runSync fetches and streams every requested kind, then sends
+// sync_complete. It stops early if the client goes away.
+func (h *Hub) runSync(client *Client, req SyncRequest) {
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultSyncPageSize
+	}
+	pageSize = min(pageSize, maxSyncPageSize)
+
+	done := SyncComplete{RequestID: req.RequestID, Kinds: []SyncKindStatus{}}
+	for _, kind := range h.syncKinds(req.Kinds) {
+		status := SyncKindStatus{Kind: kind}
+		items, err := h.fetchSyncKind(client.userID, kind)
+		if err != nil {
+			slog.Warn("[WebSocket] sync source failed", "user", client.userID, "kind", kind, "error", err)
+			status.Error = err.Error()
+			items = nil
+		}
+		status.Total = len(items)
+		if !h.sendSyncPages(client, req.RequestID, kind, items, pageSize) {
+			return
+		}
+		done.Kinds = append(done.Kinds, status)
+	}
+	h.sendSyncMessage(client, Message{Type: SyncCompleteMessageType, Data: done})
+}
+
+func (h *Hub) fetchSyncKind(userID uuid.UUID, kind string) ([]any, error) {
+	source := h.syncSource(kind)
+	if source == nil {
+		return nil, errUnknownSyncKind
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), syncSourceTimeout)
+	defer cancel()
+	return source(ctx, userID)
+}
+
+// sendSyncPages streams items as chunks of up to pageSize, always sending at
+// least one (possibly empty) chunk. A chunk that would exceed
+// wsMaxBroadcastBytes is retried with half as many items. It reports false if
+// the client went away.
+func (h *Hub) sendSyncPages(client *Client, requestID, kind string, items []any, pageSize int) bool {
+	for page, offset := 0, 0; ; page++ {
+		size := pageSize
+		for {
+			end := min(offset+size, len(items))
+			chunk := SyncChunk{
+				RequestID: requestID,
+				Kind:      kind,
+				Page:      page,
+				Offset:    offset,
+				Total:     len(items),
+				Items:     items[offset:end],
+				Last:      end == len(items),
+			}
+			if chunk.Items == nil {
+				chunk.Items = []any{}
+			}
+			data, err := json.Marshal(Message{Type: SyncChunkMessageType, Data: chunk})
+			if err != nil {
+				slog.Error("[WebSocket] failed to marshal sync chunk", "kind", kind, "error", err)
+				return false
+			}
+			if len(data) > wsMaxBroadcastBytes && end-offset > 1 {
+				size = (end - offset) / 2
+				continue
+			}
+			if !h.enqueueSync(client, data) {
+				return false
+			}
+			if chunk.Last {
+				return true
+			}
+			offset = end
+			break
+		}
+	}
+}
+
+func (h *Hub) sendSyncError(client *Client, requestID, message string) {
+	h.sendSyncMessage(client, Message{Type: "error", Data: map[string]string{
+		"message":   message,
+		"requestId": requestID,
+	}})
+}
+
+// sendSyncMessage encodes and queues a non-chunk sync message.
+func (h *Hub) sendSyncMessage(client *Client, msg Message) bool {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("[WebSocket] failed to marshal sync message", "type", msg.Type, "error", err)
+		return false
+	}
+	return h.enqueueSync(client, data)
+}
+
+// enqueueSync queues data on client's send channel, waiting up to
+// syncSendTimeout for buffer space. It reports false once the client has
+// been removed or stayed full for too long.
+func (h *Hub) enqueueSync(client *Client, data []byte) bool {
+	deadline := time.Now().Add(syncSendTimeout)
+	for {
+		// Holding the read lock keeps removeClientLocked and Close from
+		// closing client.send while we write to it.
+		h.mu.RLock()
+		if client.removed {
+			h.mu.RUnlock()
+			return false
+		}
+		select {
+		case client.send <- data:
+			h.mu.RUnlock()
+			return true
+		default:
+		}
+		h.mu.RUnlock()
+
+		if time.Now().After(deadline) {
+			slog.Warn("[WebSocket] abandoning sync, client send buffer full", "user", client.userID)
+			return false
+		}
+		select {
+		case <-time.After(syncSendRetry):
+		case <-h.done:
+			return false
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	fasthttpws "github.com/fasthttp/websocket"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncTestClient registers a socket-less client whose send buffer a test can
+// drain directly.
+func syncTestClient(h *Hub, buffer int) *Client {
+	c := &Client{userID: uuid.New(), send: make(chan []byte, buffer)}
+	h.mu.Lock()
+	h.clients[c] = true
+	h.userIndex[c.userID] = append(h.userIndex[c.userID], c)
+	h.mu.Unlock()
+	return c
+}
+
+type decodedSyncMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func drainSync(t *testing.T, c *Client) (chunks []SyncChunk, complete *SyncComplete) {
+	t.Helper()
+	for {
+		select {
+		case raw := <-c.send:
+			var msg decodedSyncMessage
+			require.NoError(t, json.Unmarshal(raw, &msg))
+			switch msg.Type {
+			case SyncChunkMessageType:
+				var chunk SyncChunk
+				require.NoError(t, json.Unmarshal(msg.Data, &chunk))
+				chunks = append(chunks, chunk)
+			case SyncCompleteMessageType:
+				complete = &SyncComplete{}
+				require.NoError(t, json.Unmarshal(msg.Data, complete))
+				return chunks, complete
+			default:
+				t.Fatalf("unexpected message %s", raw)
+			}
+		default:
+			return chunks, complete
+		}
+	}
+}
+
+func itemsOf(n int) []any {
+	items := make([]any, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("item-%d", i)
+	}
+	return items
+}
+
+func TestRunSync_PagesEveryKind(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	h.RegisterSyncSource("workloads", func(context.Context, uuid.UUID) ([]any, error) { return itemsOf(5), nil })
+	h.RegisterSyncSource("clusters", func(context.Context, uuid.UUID) ([]any, error) { return nil, nil })
+	c := syncTestClient(h, 64)
+
+	h.runSync(c, SyncRequest{RequestID: "r1", PageSize: 2})
+	chunks, complete := drainSync(t, c)
+
+	require.NotNil(t, complete)
+	assert.Equal(t, "r1", complete.RequestID)
+	assert.Equal(t, []SyncKindStatus{{Kind: "clusters"}, {Kind: "workloads", Total: 5}}, complete.Kinds)
+
+	require.Len(t, chunks, 4, "one empty chunk for clusters, three pages of workloads")
+	assert.Equal(t, SyncChunk{RequestID: "r1", Kind: "clusters", Items: []any{}, Last: true}, chunks[0])
+	var got []any
+	for i, chunk := range chunks[1:] {
+		assert.Equal(t, "workloads", chunk.Kind)
+		assert.Equal(t, i, chunk.Page)
+		assert.Equal(t, i*2, chunk.Offset)
+		assert.Equal(t, 5, chunk.Total)
+		assert.Equal(t, i == 2, chunk.Last)
+		got = append(got, chunk.Items...)
+	}
+	assert.Equal(t, itemsOf(5), got)
+}
+
+func TestRunSync_KindFilterAndErrors(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	var calls []string
+	h.RegisterSyncSource("groups", func(context.Context, uuid.UUID) ([]any, error) {
+		calls = append(calls, "groups")
+		return itemsOf(1), nil
+	})
+	h.RegisterSyncSource("workloads", func(context.Context, uuid.UUID) ([]any, error) {
+		calls = append(calls, "workloads")
+		return nil, errors.New("cluster unreachable")
+	})
+	h.RegisterSyncSource("clusters", func(context.Context, uuid.UUID) ([]any, error) {
+		calls = append(calls, "clusters")
+		return itemsOf(1), nil
+	})
+	c := syncTestClient(h, 64)
+
+	h.runSync(c, SyncRequest{RequestID: "r2", Kinds: []string{"workloads", "groups", "workloads", "nodes"}})
+	chunks, complete := drainSync(t, c)
+
+	assert.Equal(t, []string{"workloads", "groups"}, calls, "only requested kinds are fetched")
+	require.NotNil(t, complete)
+	assert.Equal(t, []SyncKindStatus{
+		{Kind: "workloads", Error: "cluster unreachable"},
+		{Kind: "groups", Total: 1},
+		{Kind: "nodes", Error: errUnknownSyncKind.Error()},
+	}, complete.Kinds)
+	require.Len(t, chunks, 3, "failed and unknown kinds still send a last chunk")
+	for _, chunk := range chunks {
+		assert.True(t, chunk.Last)
+	}
+}
+
+func TestRunSync_PageSizeDefaultsAndCap(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	h.RegisterSyncSource("big", func(context.Context, uuid.UUID) ([]any, error) { return itemsOf(maxSyncPageSize + 1), nil })
+	c := syncTestClient(h, 64)
+
+	h.runSync(c, SyncRequest{PageSize: maxSyncPageSize * 10})
+	chunks, _ := drainSync(t, c)
+	require.Len(t, chunks, 2)
+	assert.Len(t, chunks[0].Items, maxSyncPageSize)
+
+	h.runSync(c, SyncRequest{})
+	chunks, _ = drainSync(t, c)
+	assert.Len(t, chunks[0].Items, defaultSyncPageSize)
+}
+
+func TestRunSync_SplitsOversizedChunks(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	const items = 4
+	large := strings.Repeat("x", wsMaxBroadcastBytes/3)
+	h.RegisterSyncSource("logs", func(context.Context, uuid.UUID) ([]any, error) {
+		out := make([]any, items)
+		for i := range out {
+			out[i] = large
+		}
+		return out, nil
+	})
+	c := syncTestClient(h, 64)
+
+	h.runSync(c, SyncRequest{PageSize: items})
+	chunks, complete := drainSync(t, c)
+	require.NotNil(t, complete)
+	total := 0
+	for i, chunk := range chunks {
+		assert.Equal(t, i, chunk.Page)
+		assert.Equal(t, total, chunk.Offset)
+		total += len(chunk.Items)
+	}
+	assert.Equal(t, items, total)
+	assert.Greater(t, len(chunks), 1)
+}
+
+func TestEnqueueSync_StopsForRemovedClient(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+	c := syncTestClient(h, 1)
+	h.mu.Lock()
+	h.removeClientLocked(c)
+	h.mu.Unlock()
+
+	assert.False(t, h.enqueueSync(c, []byte(`{}`)), "must not send on a closed channel")
+}
+
+func TestHandleSyncMessage_Guards(t *testing.T) {
+	h := NewHub()
+	defer h.Close()
+
+	demo := syncTestClient(h, 4)
+	demo.userID = uuid.Nil
+	h.handleSyncMessage(demo, map[string]any{"requestId": "d"})
+	var msg Message
+	require.NoError(t, json.Unmarshal(<-demo.send, &msg))
+	assert.Equal(t, "error", msg.Type)
+	assert.Equal(t, map[string]any{"message": "sync requires an authenticated session", "requestId": "d"}, msg.Data)
+
+	busy := syncTestClient(h, 4)
+	busy.syncing.Store(true)
+	h.handleSyncMessage(busy, map[string]any{"requestId": "b"})
+	require.NoError(t, json.Unmarshal(<-busy.send, &msg))
+	assert.Equal(t, "sync already in progress", msg.Data.(map[string]any)["message"])
+}
+
+// TestHandleConnection_Sync dials a real connection and checks that a sync
+// request reaches the hub's reader loop.
+func TestHandleConnection_Sync(t *testing.T) {
+	h := NewHub()
+	h.SetDevMode(true)
+	go h.Run()
+	defer h.Close()
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws", websocket.New(func(c *websocket.Conn) {
+		h.HandleConnection(c)
+	}))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	defer app.Shutdown()
+
+	conn, _, err := fasthttpws.DefaultDialer.Dial(fmt.Sprintf("ws://%s/ws", ln.Addr()), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "auth", "token": "demo-token"}))
+	var msg Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	require.Equal(t, "authenticated", msg.Type)
+
+	require.NoError(t, conn.WriteJSON(Message{Type: SyncMessageType, Data: SyncRequest{RequestID: "live"}}))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, "error", msg.Type, "demo sessions are refused")
+	assert.Equal(t, "live", msg.Data.(map[string]any)["requestId"])
+}
@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NDJSONContentType is the media type of newline-delimited JSON streams.
+const NDJSONContentType = "application/x-ndjson"
+
+// ndjsonFormatParam is the ?format= value selecting NDJSON for clients that
+// cannot set an Accept header (e.g. a plain fetch from a download link).
+const ndjsonFormatParam = "ndjson"
+
+// WantsNDJSON reports whether the client asked for a newline-delimited JSON
+// stream instead of SSE, via Accept or ?format=ndjson.
+func WantsNDJSON(c *fiber.Ctx) bool {
+	if c.Query("format") == ndjsonFormatParam {
+		return true
+	}
+	accept := c.Get(fiber.HeaderAccept)
+	return strings.Contains(accept, NDJSONContentType) || strings.Contains(accept, "application/ndjson")
+}
+
+// SetNDJSONHeaders sets the headers of an NDJSON stream. Like SSE, proxy
+// buffering is disabled so each line reaches the client as it is written.
+func SetNDJSONHeaders(c *fiber.Ctx) {
+	c.Set("Content-Type", NDJSONContentType)
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+}
+
+// WriteNDJSON writes v as one JSON line. The caller flushes.
+func WriteNDJSON(w io.Writer, v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
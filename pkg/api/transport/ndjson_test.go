@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWantsNDJSON(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(map[bool]string{true: "ndjson", false: "sse"}[WantsNDJSON(c)])
+	})
+
+	for name, tc := range map[string]struct {
+		target string
+		accept string
+		want   string
+	}{
+		"default":          {"/", "", "sse"},
+		"event stream":     {"/", "text/event-stream", "sse"},
+		"x-ndjson":         {"/", "application/x-ndjson", "ndjson"},
+		"ndjson in a list": {"/", "application/ndjson, */*;q=0.1", "ndjson"},
+		"format param":     {"/?format=ndjson", "", "ndjson"},
+	} {
+		req := httptest.NewRequest("GET", tc.target, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, string(body), name)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf strings.Builder
+	require.NoError(t, WriteNDJSON(&buf, map[string]string{"type": "item"}))
+	require.NoError(t, WriteNDJSON(&buf, []int{1}))
+	assert.Equal(t, "{\"type\":\"item\"}\n[1]\n", buf.String())
+	assert.Error(t, WriteNDJSON(&buf, make(chan int)))
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+const (
+	// respMaxBulkBytes caps a single bulk string read from Redis. Envelopes
+	// are bounded by wsMaxBroadcastBytes, so anything far larger is corrupt.
+	respMaxBulkBytes = 4 * wsMaxBroadcastBytes
+	// respMaxArrayLen caps array replies for the same reason.
+	respMaxArrayLen = 1 << 16
+)
+
+// redisError is an error reply ("-ERR ...") from the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// errRESPProtocol reports a malformed reply.
+var errRESPProtocol = errors.New("redis: protocol error")
+
+// respConn is a minimal RESP2 client connection: enough for AUTH, SELECT
+// and the stream commands the backplane uses.
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func newRESPConn(conn net.Conn) *respConn {
+	return &respConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+// do sends a command and reads its reply. Replies decode to string, int64,
+// []any or nil; error replies are returned as redisError.
+func (c *respConn) do(args ...string) (any, error) {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	reply, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	if rerr, ok := reply.(redisError); ok {
+		return nil, rerr
+	}
+	return reply, nil
+}
+
+func (c *respConn) read() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errRESPProtocol
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return redisError(body), nil
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, errRESPProtocol
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n > respMaxBulkBytes {
+			return nil, errRESPProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n > respMaxArrayLen {
+			return nil, errRESPProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, errRESPProtocol
+	}
+}
+
+func (c *respConn) close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,105 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+)
+
+// StrategyCanary deploys a WorkloadDeployment to a growing share of its
+// target clusters, as configured by its CanaryConfig.
+const StrategyCanary = "Canary"
+
+// CanaryConfig defaults, used for fields left unset.
+const (
+	DefaultCanaryInitialWeight = 10
+	DefaultCanaryStepWeight    = 10
+	DefaultCanaryMaxWeight     = 50
+	DefaultCanaryStepInterval  = 5 * time.Minute
+)
+
+// maxCanaryWeight is the weight of a fully promoted canary.
+const maxCanaryWeight = 100
+
+// Canary phases recorded in CanaryStatus.Phase.
+const (
+	CanaryPhaseProgressing       = "Progressing"
+	CanaryPhaseAwaitingPromotion = "AwaitingPromotion"
+	CanaryPhasePromoting         = "Promoting"
+	CanaryPhasePromoted          = "Promoted"
+	CanaryPhaseAborted           = "Aborted"
+)
+
+// IsCanary reports whether spec is rolled out as a canary. That is the case
+// when the strategy is Canary and a CanaryConfig is set; without one every
+// cluster is deployed at once.
+func (spec WorkloadDeploymentSpec) IsCanary() bool {
+	return spec.Strategy == StrategyCanary && spec.CanaryConfig != nil
+}
+
+func (c CanaryConfig) initialWeight() int {
+	if c.InitialWeight == 0 {
+		return DefaultCanaryInitialWeight
+	}
+	return c.InitialWeight
+}
+
+func (c CanaryConfig) stepWeight() int {
+	if c.StepWeight == 0 {
+		return DefaultCanaryStepWeight
+	}
+	return c.StepWeight
+}
+
+func (c CanaryConfig) maxWeight() int {
+	if c.MaxWeight == 0 {
+		return max(DefaultCanaryMaxWeight, c.initialWeight())
+	}
+	return c.MaxWeight
+}
+
+// Weights returns the weight of each step: InitialWeight, then StepWeight
+// more per step up to MaxWeight. Promotion to 100 follows the last step.
+// The config must be valid.
+func (c CanaryConfig) Weights() []int {
+	limit := c.maxWeight()
+	weights := []int{c.initialWeight()}
+	for w := weights[0]; w < limit; {
+		w = min(w+c.stepWeight(), limit)
+		weights = append(weights, w)
+	}
+	return weights
+}
+
+// Interval returns StepInterval, or DefaultCanaryStepInterval when unset.
+func (c CanaryConfig) Interval() (time.Duration, error) {
+	return parseSpecDuration("canaryConfig.stepInterval", c.StepInterval, DefaultCanaryStepInterval)
+}
+
+// Validate checks that the weights are between 1 and 100, InitialWeight does
+// not exceed MaxWeight, and StepInterval parses.
+func (c CanaryConfig) Validate() error {
+	for _, f := range []struct {
+		name   string
+		weight int
+	}{
+		{"canaryConfig.initialWeight", c.InitialWeight},
+		{"canaryConfig.stepWeight", c.StepWeight},
+		{"canaryConfig.maxWeight", c.MaxWeight},
+	} {
+		if f.weight < 0 || f.weight > maxCanaryWeight {
+			return fmt.Errorf("%s must be between 1 and %d", f.name, maxCanaryWeight)
+		}
+	}
+	if c.initialWeight() > c.maxWeight() {
+		return fmt.Errorf("canaryConfig.initialWeight must not exceed canaryConfig.maxWeight")
+	}
+	_, err := c.Interval()
+	return err
+}
+
+// CanaryClusterCount is how many of clusters a canary at weight deploys to:
+// the weight's share rounded up, so every step reaches at least one cluster.
+func CanaryClusterCount(weight, clusters int) int {
+	n := (weight*clusters + maxCanaryWeight - 1) / maxCanaryWeight
+	return min(max(n, 1), clusters)
+}
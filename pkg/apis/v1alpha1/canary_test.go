@@ -0,0 +1,80 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCanaryConfigWeights(t *testing.T) {
+	tests := []struct {
+		config CanaryConfig
+		want   []int
+	}{
+		{CanaryConfig{}, []int{10, 20, 30, 40, 50}},
+		{CanaryConfig{InitialWeight: 20, StepWeight: 25, MaxWeight: 60}, []int{20, 45, 60}},
+		{CanaryConfig{InitialWeight: 70}, []int{70}},
+		{CanaryConfig{InitialWeight: 100, MaxWeight: 100}, []int{100}},
+	}
+	for _, tt := range tests {
+		if got := tt.config.Weights(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Weights(%+v) = %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestCanaryConfigValidate(t *testing.T) {
+	var c CanaryConfig
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() on an empty config = %v", err)
+	}
+	if got, _ := c.Interval(); got != DefaultCanaryStepInterval {
+		t.Errorf("Interval() = %v, want %v", got, DefaultCanaryStepInterval)
+	}
+	c = CanaryConfig{StepInterval: "30s"}
+	if got, _ := c.Interval(); got != 30*time.Second {
+		t.Errorf("Interval() = %v, want 30s", got)
+	}
+
+	for _, bad := range []CanaryConfig{
+		{InitialWeight: -1},
+		{StepWeight: 101},
+		{InitialWeight: 60, MaxWeight: 40},
+		{StepInterval: "often"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
+
+func TestCanaryClusterCount(t *testing.T) {
+	tests := []struct{ weight, clusters, want int }{
+		{10, 4, 1},
+		{25, 4, 1},
+		{30, 4, 2},
+		{50, 5, 3},
+		{100, 5, 5},
+		{0, 3, 1},
+	}
+	for _, tt := range tests {
+		if got := CanaryClusterCount(tt.weight, tt.clusters); got != tt.want {
+			t.Errorf("CanaryClusterCount(%d, %d) = %d, want %d", tt.weight, tt.clusters, got, tt.want)
+		}
+	}
+}
+
+func TestIsCanary(t *testing.T) {
+	spec := WorkloadDeploymentSpec{Strategy: StrategyCanary}
+	if spec.IsCanary() {
+		t.Error("IsCanary() without a CanaryConfig = true")
+	}
+	spec.CanaryConfig = &CanaryConfig{}
+	if !spec.IsCanary() {
+		t.Error("IsCanary() = false")
+	}
+	spec.Strategy = "RollingUpdate"
+	if spec.IsCanary() {
+		t.Error("IsCanary() with a RollingUpdate strategy = true")
+	}
+}
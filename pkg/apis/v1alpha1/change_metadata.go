@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Change metadata annotations on WorkloadDeployment CRs. They record why a
+// rollout happened and are copied into each history entry.
+const (
+	AnnotationChangeDescription = Group + "/change-description"
+	AnnotationChangeTicket      = Group + "/change-ticket"
+	AnnotationChangeApprover    = Group + "/change-approver"
+)
+
+// Limits on change metadata values, well inside the 256 KiB annotation budget.
+const (
+	maxChangeDescriptionLen = 2000
+	maxChangeTicketLen      = 2048
+	maxChangeApproverLen    = 256
+)
+
+// ChangeMetadata describes the change a WorkloadDeployment rolls out.
+type ChangeMetadata struct {
+	// Description is a free-form note on what the change does
+	Description string `json:"description,omitempty"`
+
+	// TicketURL links the change ticket, e.g. a Jira or ServiceNow URL
+	TicketURL string `json:"ticketUrl,omitempty"`
+
+	// Approver is who approved the change
+	Approver string `json:"approver,omitempty"`
+}
+
+// IsEmpty reports whether no change metadata is set.
+func (m ChangeMetadata) IsEmpty() bool {
+	return m == ChangeMetadata{}
+}
+
+// ChangeMetadata returns the change metadata annotations of wd, or nil when
+// it has none.
+func (wd *WorkloadDeployment) ChangeMetadata() *ChangeMetadata {
+	m := ChangeMetadata{
+		Description: strings.TrimSpace(wd.Annotations[AnnotationChangeDescription]),
+		TicketURL:   strings.TrimSpace(wd.Annotations[AnnotationChangeTicket]),
+		Approver:    strings.TrimSpace(wd.Annotations[AnnotationChangeApprover]),
+	}
+	if m.IsEmpty() {
+		return nil
+	}
+	return &m
+}
+
+// Validate checks that the values fit in annotations and that the ticket is
+// an absolute http(s) URL. Whether a field is required is project policy and
+// is not checked here.
+func (m ChangeMetadata) Validate() error {
+	if len(m.Description) > maxChangeDescriptionLen {
+		return fmt.Errorf("change description exceeds %d characters", maxChangeDescriptionLen)
+	}
+	if len(m.Approver) > maxChangeApproverLen {
+		return fmt.Errorf("change approver exceeds %d characters", maxChangeApproverLen)
+	}
+	if m.TicketURL == "" {
+		return nil
+	}
+	if len(m.TicketURL) > maxChangeTicketLen {
+		return fmt.Errorf("change ticket URL exceeds %d characters", maxChangeTicketLen)
+	}
+	u, err := url.Parse(m.TicketURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("change ticket must be an http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadDeploymentChangeMetadata(t *testing.T) {
+	wd := &WorkloadDeployment{}
+	if wd.ChangeMetadata() != nil {
+		t.Error("expected nil change metadata without annotations")
+	}
+
+	wd.ObjectMeta = metav1.ObjectMeta{Annotations: map[string]string{
+		AnnotationChangeDescription: " Bump to 1.4 ",
+		AnnotationChangeTicket:      "https://jira.example.com/browse/OPS-12",
+		AnnotationChangeApprover:    "alex",
+	}}
+	got := wd.ChangeMetadata()
+	if got == nil {
+		t.Fatal("expected change metadata")
+	}
+	want := ChangeMetadata{Description: "Bump to 1.4", TicketURL: "https://jira.example.com/browse/OPS-12", Approver: "alex"}
+	if *got != want {
+		t.Errorf("ChangeMetadata() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestChangeMetadataValidate(t *testing.T) {
+	valid := []ChangeMetadata{
+		{},
+		{Description: "note only"},
+		{TicketURL: "https://jira.example.com/browse/OPS-12"},
+	}
+	for _, m := range valid {
+		if err := m.Validate(); err != nil {
+			t.Errorf("Validate(%+v) = %v, want nil", m, err)
+		}
+	}
+
+	invalid := map[string]ChangeMetadata{
+		"relative ticket":    {TicketURL: "OPS-12"},
+		"non-http ticket":    {TicketURL: "javascript:alert(1)"},
+		"long description":   {Description: strings.Repeat("x", maxChangeDescriptionLen+1)},
+		"long approver name": {Approver: strings.Repeat("x", maxChangeApproverLen+1)},
+	}
+	for name, m := range invalid {
+		if err := m.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -67,14 +67,31 @@ type ManagedWorkloadSpec struct {
 	// TargetGroups is a list of ClusterGroup names to target
 	TargetGroups []string `json:"targetGroups,omitempty"`
 
-	// Replicas overrides the replica count for deployment
+	// Replicas overrides the replica count for deployment. With a
+	// ReplicaDistribution it is the total split across the target clusters
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// ReplicaDistribution splits Replicas across the target clusters instead
+	// of running Replicas on each of them
+	ReplicaDistribution *ReplicaDistribution `json:"replicaDistribution,omitempty"`
+
 	// Overrides are field overrides to apply when deploying
 	Overrides map[string]interface{} `json:"overrides,omitempty"`
 
 	// Suspend suspends the workload deployment
 	Suspend bool `json:"suspend,omitempty"`
+
+	// NetworkPolicy generates a baseline NetworkPolicy for the workload and
+	// applies it alongside the workload on each target cluster
+	NetworkPolicy *GeneratedNetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// Placement are affinity, anti-affinity and spread constraints on the
+	// target clusters the workload is deployed to
+	Placement []PlacementConstraint `json:"placement,omitempty"`
+
+	// Manifest is the workload captured from the source cluster when it was
+	// imported. When set it is deployed in place of the live workload
+	Manifest map[string]interface{} `json:"manifest,omitempty"`
 }
 
 // WorkloadReference identifies a workload resource
@@ -112,7 +129,8 @@ type ClusterDeploymentStatus struct {
 	// Cluster is the cluster name
 	Cluster string `json:"cluster"`
 
-	// Status is the deployment status (Pending, Running, Degraded, Failed)
+	// Status is the workload's health on the cluster (Available, Progressing,
+	// Degraded, Missing, Unknown)
 	Status string `json:"status,omitempty"`
 
 	// Replicas shows ready/desired replicas (e.g., "3/3")
@@ -155,8 +173,67 @@ type ClusterGroupSpec struct {
 	// DynamicFilters are filters for dynamic cluster membership
 	DynamicFilters []ClusterFilter `json:"dynamicFilters,omitempty"`
 
+	// Expression is a CEL expression evaluated against each cluster's
+	// metadata (e.g. cluster.labels["tier"] == "prod" && cluster.gpuCount >= 8).
+	// Clusters must satisfy both the expression and every dynamic filter.
+	Expression string `json:"expression,omitempty"`
+
 	// Priority for deployment ordering (higher = first)
 	Priority int `json:"priority,omitempty"`
+
+	// MemberGroups are ClusterGroups in the same namespace whose clusters
+	// also belong to this group. Member groups inherit this group's Settings.
+	MemberGroups []string `json:"memberGroups,omitempty"`
+
+	// Settings is metadata inherited by member groups and their clusters
+	Settings *ClusterGroupSettings `json:"settings,omitempty"`
+}
+
+// ClusterGroupSettings is metadata that member groups inherit from the groups
+// that include them, e.g. region -> environment -> team.
+type ClusterGroupSettings struct {
+	// MaintenanceWindows are when the group's clusters may be disrupted.
+	// Inherited windows are added to a group's own.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// Frozen stops WorkloadDeployment rollouts to the group's clusters. A
+	// group's own value overrides the one it inherits; unset inherits.
+	Frozen *bool `json:"frozen,omitempty"`
+
+	// FreezeReason explains a freeze
+	FreezeReason string `json:"freezeReason,omitempty"`
+
+	// NotificationRoutes are where alerts about the group's clusters go.
+	// Inherited routes are added to a group's own.
+	NotificationRoutes []NotificationRoute `json:"notificationRoutes,omitempty"`
+
+	// RetryPolicy is the default retry policy of deployments to the group's
+	// clusters. A group's own policy overrides the one it inherits.
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// MaintenanceWindow is a recurring local-time range
+type MaintenanceWindow struct {
+	// Start is the local time the window opens (HH:MM, 24h)
+	Start string `json:"start"`
+
+	// End is the local time the window closes (HH:MM, 24h)
+	End string `json:"end"`
+
+	// Days restricts the window to the weekdays it opens on (Mon..Sun)
+	Days []string `json:"days,omitempty"`
+
+	// Timezone is an IANA timezone overriding each cluster's own timezone
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// NotificationRoute sends alerts to one notification channel
+type NotificationRoute struct {
+	// Type is the channel type (slack, email, webhook, pagerduty, opsgenie)
+	Type string `json:"type"`
+
+	// Target is the destination, e.g. a Slack channel or an email address
+	Target string `json:"target"`
 }
 
 // ClusterFilter defines a filter condition for cluster membership
@@ -233,6 +310,70 @@ type WorkloadDeploymentSpec struct {
 
 	// Suspend suspends the deployment
 	Suspend bool `json:"suspend,omitempty"`
+
+	// DeploymentWindows restrict when target clusters may be deployed to.
+	// A cluster that any window applies to is deployed only while it is
+	// inside one of them; until then it is queued.
+	DeploymentWindows []DeploymentWindow `json:"deploymentWindows,omitempty"`
+
+	// PreDeployBackup takes a Velero backup of the workload's namespace on
+	// each target cluster before deploying to it
+	PreDeployBackup *PreDeployBackup `json:"preDeployBackup,omitempty"`
+
+	// DependsOn names WorkloadDeployments in the same namespace that must be
+	// healthy on a cluster before this deployment deploys to it
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// DependencyTimeout is how long to wait for the dependencies to become
+	// healthy on a cluster (default 10m)
+	DependencyTimeout string `json:"dependencyTimeout,omitempty"`
+
+	// RetryPolicy retries clusters whose apply failed. It overrides the
+	// policies of the target clusters' groups
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// PreDeployBackup configures the Velero backups taken before a rollout. A
+// cluster whose backup fails is not deployed to.
+type PreDeployBackup struct {
+	// Namespace is where Velero runs (default velero)
+	Namespace string `json:"namespace,omitempty"`
+
+	// StorageLocation is the BackupStorageLocation to write to; empty uses
+	// Velero's default
+	StorageLocation string `json:"storageLocation,omitempty"`
+
+	// TTL is how long Velero keeps the backup, e.g. "168h"
+	TTL string `json:"ttl,omitempty"`
+
+	// Timeout is how long to wait for the backup to complete (default 3m)
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// DeploymentWindow is a recurring local-time range during which clusters may
+// be deployed to. With neither Clusters nor ClusterGroup set it applies to
+// every target cluster.
+type DeploymentWindow struct {
+	// Clusters limits the window to these clusters
+	Clusters []string `json:"clusters,omitempty"`
+
+	// ClusterGroup limits the window to members of this ClusterGroup in the
+	// deployment's namespace
+	ClusterGroup string `json:"clusterGroup,omitempty"`
+
+	// Start is the local time the window opens (HH:MM, 24h)
+	Start string `json:"start"`
+
+	// End is the local time the window closes (HH:MM, 24h). An End before
+	// Start wraps past midnight.
+	End string `json:"end"`
+
+	// Days restricts the window to the weekdays it opens on (Mon..Sun).
+	// Empty means every day.
+	Days []string `json:"days,omitempty"`
+
+	// Timezone is an IANA timezone overriding each cluster's own timezone
+	Timezone string `json:"timezone,omitempty"`
 }
 
 // ResourceReference identifies a resource
@@ -261,16 +402,18 @@ type RolloutConfig struct {
 
 // CanaryConfig defines canary deployment configuration
 type CanaryConfig struct {
-	// InitialWeight is the initial traffic weight for canary (0-100)
+	// InitialWeight is the initial traffic weight for canary (1-100,
+	// default 10). Across clusters it is the share of target clusters
+	// deployed to.
 	InitialWeight int `json:"initialWeight,omitempty"`
 
-	// StepWeight is the traffic weight increment per step
+	// StepWeight is the traffic weight increment per step (default 10)
 	StepWeight int `json:"stepWeight,omitempty"`
 
-	// StepInterval is the duration between steps
+	// StepInterval is the duration between steps (default 5m)
 	StepInterval string `json:"stepInterval,omitempty"`
 
-	// MaxWeight is the maximum weight before full promotion
+	// MaxWeight is the maximum weight before full promotion (default 50)
 	MaxWeight int `json:"maxWeight,omitempty"`
 }
 
@@ -302,6 +445,10 @@ type WorkloadDeploymentStatus struct {
 
 	// History is the history of deployment attempts
 	History []DeploymentHistoryEntry `json:"history,omitempty"`
+
+	// NextEligibleAt is when the next queued cluster's deployment window
+	// opens. Set while the phase is Queued.
+	NextEligibleAt *metav1.Time `json:"nextEligibleAt,omitempty"`
 }
 
 // ClusterRolloutStatus contains rollout status for a single cluster
@@ -309,7 +456,7 @@ type ClusterRolloutStatus struct {
 	// Cluster is the cluster name
 	Cluster string `json:"cluster"`
 
-	// Phase is the rollout phase (Pending, InProgress, Complete, Failed, Skipped)
+	// Phase is the rollout phase (Pending, Queued, InProgress, Complete, Failed, Skipped)
 	Phase string `json:"phase,omitempty"`
 
 	// Progress is the progress percentage (e.g., "67%")
@@ -326,6 +473,28 @@ type ClusterRolloutStatus struct {
 
 	// RollbackAvailable indicates if rollback is available
 	RollbackAvailable bool `json:"rollbackAvailable,omitempty"`
+
+	// NextEligibleAt is when a queued cluster's deployment window opens
+	NextEligibleAt *metav1.Time `json:"nextEligibleAt,omitempty"`
+
+	// PreDeployBackup is the Velero backup taken before deploying
+	PreDeployBackup string `json:"preDeployBackup,omitempty"`
+
+	// Replicas is the replica count the workload's replica distribution
+	// assigned to this cluster
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Images are the container images this cluster ran when the rollout
+	// completed, with their digests
+	Images []ImageProvenance `json:"images,omitempty"`
+
+	// Attempts is how many times the cluster was deployed to under a retry
+	// policy
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// NextRetryAt is when a failed cluster is deployed to again. Set while
+	// the phase is Retrying.
+	NextRetryAt *metav1.Time `json:"nextRetryAt,omitempty"`
 }
 
 // CanaryStatus contains canary deployment status
@@ -344,6 +513,13 @@ type CanaryStatus struct {
 
 	// Metrics are collected metrics for canary analysis
 	Metrics map[string]interface{} `json:"metrics,omitempty"`
+
+	// Phase is where the canary stands (Progressing, AwaitingPromotion,
+	// Promoting, Promoted, Aborted)
+	Phase string `json:"phase,omitempty"`
+
+	// NextStepAt is when the next step runs. Set while Progressing.
+	NextStepAt *metav1.Time `json:"nextStepAt,omitempty"`
 }
 
 // DeploymentHistoryEntry contains a single history entry
@@ -362,6 +538,10 @@ type DeploymentHistoryEntry struct {
 
 	// Message contains additional information
 	Message string `json:"message,omitempty"`
+
+	// Change is the change metadata the deployment carried when this
+	// attempt ran
+	Change *ChangeMetadata `json:"change,omitempty"`
 }
 
 // =============================================================================
@@ -0,0 +1,13 @@
+package v1alpha1
+
+import "time"
+
+// DefaultDependencyTimeout is how long a deployment waits for the
+// deployments it depends on when DependencyTimeout is unset.
+const DefaultDependencyTimeout = 10 * time.Minute
+
+// DependencyWait returns DependencyTimeout, or DefaultDependencyTimeout when
+// unset.
+func (spec WorkloadDeploymentSpec) DependencyWait() (time.Duration, error) {
+	return parseSpecDuration("dependencyTimeout", spec.DependencyTimeout, DefaultDependencyTimeout)
+}
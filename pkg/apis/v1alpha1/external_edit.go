@@ -0,0 +1,25 @@
+package v1alpha1
+
+// Annotations the console sets on a ManagedWorkload whose spec was changed
+// outside the console, e.g. with kubectl edit. They name the field manager
+// of the last spec change and when it happened.
+const (
+	AnnotationLastModifiedBy = Group + "/last-modified-by"
+	AnnotationLastModifiedAt = Group + "/last-modified-at"
+)
+
+// ConditionModifiedOutsideConsole is True on a ManagedWorkload whose current
+// spec was last changed outside the console, and False once the console
+// changes it again.
+const ConditionModifiedOutsideConsole = "ModifiedOutsideConsole"
+
+// Reasons of ConditionModifiedOutsideConsole.
+const (
+	// ReasonExternalEdit: changed outside the console, and still valid.
+	ReasonExternalEdit = "ExternalEdit"
+	// ReasonInvalidExternalEdit: changed outside the console and no longer
+	// valid. Deployments of the workload fail until it is fixed.
+	ReasonInvalidExternalEdit = "InvalidExternalEdit"
+	// ReasonEditedInConsole: the console changed the spec since.
+	ReasonEditedInConsole = "EditedInConsole"
+)
@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	"net"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// NetworkPolicyDirectionIngress and NetworkPolicyDirectionEgress limit an
+// allowed peer to one direction; an empty direction allows both.
+const (
+	NetworkPolicyDirectionIngress = "Ingress"
+	NetworkPolicyDirectionEgress  = "Egress"
+)
+
+// supportedPeerProtocols lists the protocols a NetworkPolicyPort may name.
+var supportedPeerProtocols = []string{"TCP", "UDP", "SCTP"}
+
+// GeneratedNetworkPolicy configures the baseline NetworkPolicy generated for
+// a ManagedWorkload. The policy selects the workload's pods and admits
+// ingress from pods in the same namespace and egress to DNS; AllowedPeers
+// widens it.
+type GeneratedNetworkPolicy struct {
+	// Enabled turns the generated policy on
+	Enabled bool `json:"enabled"`
+
+	// AllowedPeers are additional sources and destinations
+	AllowedPeers []NetworkPolicyPeer `json:"allowedPeers,omitempty"`
+}
+
+// NetworkPolicyPeer is a source or destination the generated policy allows:
+// pods selected by Namespace and PodLabels, or an IP block.
+type NetworkPolicyPeer struct {
+	// Direction is Ingress or Egress; empty allows both
+	Direction string `json:"direction,omitempty"`
+
+	// Namespace selects pods in this namespace; empty is the workload's own
+	Namespace string `json:"namespace,omitempty"`
+
+	// PodLabels selects pods by label; empty is every pod in the namespace
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// CIDR is an IP block, exclusive with Namespace and PodLabels
+	CIDR string `json:"cidr,omitempty"`
+
+	// Ports limits the peer to these ports; empty allows every port
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+// NetworkPolicyPort is a port an allowed peer may use.
+type NetworkPolicyPort struct {
+	// Port is the port number
+	Port int32 `json:"port"`
+
+	// Protocol is TCP (default), UDP or SCTP
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// AllowsIngress reports whether the peer applies to ingress.
+func (p NetworkPolicyPeer) AllowsIngress() bool {
+	return p.Direction != NetworkPolicyDirectionEgress
+}
+
+// AllowsEgress reports whether the peer applies to egress.
+func (p NetworkPolicyPeer) AllowsEgress() bool {
+	return p.Direction != NetworkPolicyDirectionIngress
+}
+
+// validate reports each problem with the allowed peers against its field
+// under path: an unknown direction or protocol, a port out of range, a peer
+// with both a CIDR and a pod selector, and a CIDR that does not parse.
+func (n GeneratedNetworkPolicy) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	directions := []string{NetworkPolicyDirectionIngress, NetworkPolicyDirectionEgress}
+	for i, peer := range n.AllowedPeers {
+		pp := path.Child("allowedPeers").Index(i)
+		if peer.Direction != "" && !slices.Contains(directions, peer.Direction) {
+			errs = append(errs, field.NotSupported(pp.Child("direction"), peer.Direction, directions))
+		}
+		if peer.CIDR != "" {
+			if peer.Namespace != "" || len(peer.PodLabels) > 0 {
+				errs = append(errs, field.Forbidden(pp.Child("cidr"), "may not be set together with namespace or podLabels"))
+			} else if _, _, err := net.ParseCIDR(peer.CIDR); err != nil {
+				errs = append(errs, field.Invalid(pp.Child("cidr"), peer.CIDR, "must be a CIDR such as 10.0.0.0/8"))
+			}
+		}
+		for j, port := range peer.Ports {
+			portPath := pp.Child("ports").Index(j)
+			if port.Port < 1 || port.Port > 65535 {
+				errs = append(errs, field.Invalid(portPath.Child("port"), port.Port, "must be between 1 and 65535"))
+			}
+			if port.Protocol != "" && !slices.Contains(supportedPeerProtocols, port.Protocol) {
+				errs = append(errs, field.NotSupported(portPath.Child("protocol"), port.Protocol, supportedPeerProtocols))
+			}
+		}
+	}
+	return errs
+}
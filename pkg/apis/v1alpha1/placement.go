@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Placement constraint types. Affinity and AntiAffinity relate a workload
+// to where other ManagedWorkloads are placed; Spread relates its own target
+// clusters to each other.
+const (
+	PlacementAffinity     = "Affinity"
+	PlacementAntiAffinity = "AntiAffinity"
+	PlacementSpread       = "Spread"
+)
+
+// Topology keys decide which clusters count as the same place.
+const (
+	PlacementTopologyCluster = "cluster"
+	PlacementTopologyRegion  = "region"
+	PlacementTopologyZone    = "zone"
+)
+
+var (
+	supportedPlacementTypes      = []string{PlacementAffinity, PlacementAntiAffinity, PlacementSpread}
+	supportedPlacementTopologies = []string{PlacementTopologyCluster, PlacementTopologyRegion, PlacementTopologyZone}
+)
+
+// PlacementConstraint restricts or steers which target clusters a
+// ManagedWorkload is deployed to.
+type PlacementConstraint struct {
+	// Type is Affinity, AntiAffinity or Spread
+	Type string `json:"type"`
+
+	// Workloads are the ManagedWorkloads, in the same namespace, an Affinity
+	// constraint places this one with and an AntiAffinity constraint keeps
+	// it away from
+	Workloads []string `json:"workloads,omitempty"`
+
+	// TopologyKey is what counts as the same place: cluster (default),
+	// region or zone
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// Required leaves out the target clusters that violate the constraint.
+	// Otherwise violations are only reported
+	Required bool `json:"required,omitempty"`
+}
+
+// EffectiveTopologyKey returns the topology key, defaulting to cluster.
+func (p PlacementConstraint) EffectiveTopologyKey() string {
+	if p.TopologyKey == "" {
+		return PlacementTopologyCluster
+	}
+	return p.TopologyKey
+}
+
+// validate reports each problem with the constraint against its field under
+// path: an unknown type or topology key, Affinity or AntiAffinity without
+// workloads or naming self, and Spread with workloads or across clusters,
+// which distinct target clusters always are.
+func (p PlacementConstraint) validate(path *field.Path, self string) field.ErrorList {
+	var errs field.ErrorList
+	if !slices.Contains(supportedPlacementTypes, p.Type) {
+		errs = append(errs, field.NotSupported(path.Child("type"), p.Type, supportedPlacementTypes))
+	}
+	key := p.EffectiveTopologyKey()
+	if !slices.Contains(supportedPlacementTopologies, key) {
+		errs = append(errs, field.NotSupported(path.Child("topologyKey"), p.TopologyKey, supportedPlacementTopologies))
+	}
+	switch p.Type {
+	case PlacementAffinity, PlacementAntiAffinity:
+		if len(p.Workloads) == 0 {
+			errs = append(errs, field.Required(path.Child("workloads"), "the workloads the "+p.Type+" constraint relates to"))
+		}
+		for i, w := range p.Workloads {
+			switch {
+			case w == "":
+				errs = append(errs, field.Required(path.Child("workloads").Index(i), ""))
+			case w == self:
+				errs = append(errs, field.Invalid(path.Child("workloads").Index(i), w, "may not name the workload itself"))
+			}
+		}
+	case PlacementSpread:
+		if len(p.Workloads) > 0 {
+			errs = append(errs, field.Forbidden(path.Child("workloads"), "only applies to Affinity and AntiAffinity"))
+		}
+		if key == PlacementTopologyCluster {
+			errs = append(errs, field.Invalid(path.Child("topologyKey"), key, "Spread needs region or zone"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,21 @@
+package v1alpha1
+
+// ImageProvenance is a container image a cluster ran after a deployment,
+// resolved to the digest its pods pulled.
+type ImageProvenance struct {
+	// Container is the name of the container in the pod template
+	Container string `json:"container"`
+
+	// Image is the image reference in the pod template
+	Image string `json:"image"`
+
+	// Digest is the content digest the cluster resolved Image to, e.g.
+	// "sha256:4c1e...". Empty when no pod had pulled it yet.
+	Digest string `json:"digest,omitempty"`
+
+	// SBOM is where the image's software bill of materials is kept
+	SBOM string `json:"sbom,omitempty"`
+
+	// Attestation is where the image's provenance attestation is kept
+	Attestation string `json:"attestation,omitempty"`
+}
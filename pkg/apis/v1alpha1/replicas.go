@@ -0,0 +1,96 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Replica distribution strategies. Even and Capacity split Replicas across
+// the target clusters; Fixed gives each listed cluster its own count.
+const (
+	ReplicaStrategyEven     = "Even"
+	ReplicaStrategyCapacity = "Capacity"
+	ReplicaStrategyFixed    = "Fixed"
+)
+
+// Cluster resources the Capacity strategy can weigh clusters by.
+const (
+	ReplicaCapacityCPU    = "cpu"
+	ReplicaCapacityMemory = "memory"
+)
+
+var (
+	supportedReplicaStrategies = []string{ReplicaStrategyEven, ReplicaStrategyCapacity, ReplicaStrategyFixed}
+	supportedCapacityResources = []string{ReplicaCapacityCPU, ReplicaCapacityMemory}
+)
+
+// ReplicaDistribution decides how many replicas of a ManagedWorkload each
+// target cluster runs.
+type ReplicaDistribution struct {
+	// Strategy is Even (default), Capacity or Fixed
+	Strategy string `json:"strategy,omitempty"`
+
+	// CapacityResource is the allocatable resource the Capacity strategy
+	// weighs clusters by: cpu (default) or memory
+	CapacityResource string `json:"capacityResource,omitempty"`
+
+	// Clusters pins the replica count of these clusters. Even and Capacity
+	// split what is left of Replicas across the other clusters
+	Clusters map[string]int32 `json:"clusters,omitempty"`
+}
+
+// EffectiveStrategy returns the strategy, defaulting to Even.
+func (d ReplicaDistribution) EffectiveStrategy() string {
+	if d.Strategy == "" {
+		return ReplicaStrategyEven
+	}
+	return d.Strategy
+}
+
+// EffectiveCapacityResource returns the capacity resource, defaulting to cpu.
+func (d ReplicaDistribution) EffectiveCapacityResource() string {
+	if d.CapacityResource == "" {
+		return ReplicaCapacityCPU
+	}
+	return d.CapacityResource
+}
+
+// validate reports each problem with the distribution against its field
+// under path: an unknown strategy or capacity resource, a negative pinned
+// count, Even or Capacity without a total in replicas or with more replicas
+// pinned than the total, and Fixed without any cluster.
+func (d ReplicaDistribution) validate(path *field.Path, replicas *int32) field.ErrorList {
+	var errs field.ErrorList
+	strategy := d.EffectiveStrategy()
+	if !slices.Contains(supportedReplicaStrategies, strategy) {
+		errs = append(errs, field.NotSupported(path.Child("strategy"), d.Strategy, supportedReplicaStrategies))
+	}
+	if d.CapacityResource != "" {
+		if strategy != ReplicaStrategyCapacity {
+			errs = append(errs, field.Forbidden(path.Child("capacityResource"), "only applies to the Capacity strategy"))
+		} else if !slices.Contains(supportedCapacityResources, d.CapacityResource) {
+			errs = append(errs, field.NotSupported(path.Child("capacityResource"), d.CapacityResource, supportedCapacityResources))
+		}
+	}
+	var pinned int64
+	for cluster, n := range d.Clusters {
+		if n < 0 {
+			errs = append(errs, field.Invalid(path.Child("clusters").Key(cluster), n, "must be 0 or more"))
+		}
+		pinned += int64(n)
+	}
+	switch strategy {
+	case ReplicaStrategyEven, ReplicaStrategyCapacity:
+		if replicas == nil {
+			errs = append(errs, field.Required(field.NewPath("spec", "replicas"), "the total the "+strategy+" strategy splits"))
+		} else if pinned > int64(*replicas) {
+			errs = append(errs, field.Invalid(path.Child("clusters"), pinned, "pins more replicas than spec.replicas"))
+		}
+	case ReplicaStrategyFixed:
+		if len(d.Clusters) == 0 {
+			errs = append(errs, field.Required(path.Child("clusters"), "the Fixed strategy needs a count per cluster"))
+		}
+	}
+	return errs
+}
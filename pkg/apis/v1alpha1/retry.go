@@ -0,0 +1,105 @@
+package v1alpha1
+
+import (
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// What a cluster that used up its retry attempts does to the deployment.
+const (
+	// RetryFailFast fails the deployment and stops other clusters' retries.
+	RetryFailFast = "FailFast"
+	// RetryWarn records the cluster as Failed but lets the deployment
+	// complete on its other clusters.
+	RetryWarn = "Warn"
+)
+
+// Defaults of RetryPolicy.
+const (
+	DefaultRetryBackoff    = 30 * time.Second
+	DefaultRetryMaxBackoff = 10 * time.Minute
+)
+
+var supportedRetryFailurePolicies = []string{RetryFailFast, RetryWarn}
+
+// RetryPolicy retries deploying to a cluster whose apply failed, e.g. while
+// its API server was briefly unreachable. A WorkloadDeployment's own policy
+// applies to all of its clusters; otherwise a cluster takes the policy of a
+// ClusterGroup it belongs to.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a cluster is deployed to, counting the
+	// first attempt (default 1, no retries)
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// Backoff is the wait before the first retry, doubled before each
+	// further one (default 30s)
+	Backoff string `json:"backoff,omitempty"`
+
+	// MaxBackoff caps the wait between retries (default 10m)
+	MaxBackoff string `json:"maxBackoff,omitempty"`
+
+	// OnFailure is what a cluster that used up its attempts does to the
+	// deployment: FailFast (default) or Warn
+	OnFailure string `json:"onFailure,omitempty"`
+}
+
+// Attempts returns MaxAttempts, or 1 when unset.
+func (p RetryPolicy) Attempts() int32 {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// WarnOnly reports whether a cluster that used up its attempts only warns.
+func (p RetryPolicy) WarnOnly() bool {
+	return p.OnFailure == RetryWarn
+}
+
+// Delay returns the wait after the given failed attempt, counting from 1:
+// Backoff doubled for each attempt after the first, capped at MaxBackoff.
+func (p RetryPolicy) Delay(attempt int32) (time.Duration, error) {
+	backoff, err := parseSpecDuration("retryPolicy.backoff", p.Backoff, DefaultRetryBackoff)
+	if err != nil {
+		return 0, err
+	}
+	limit, err := parseSpecDuration("retryPolicy.maxBackoff", p.MaxBackoff, DefaultRetryMaxBackoff)
+	if err != nil {
+		return 0, err
+	}
+	for i := int32(1); i < attempt && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit), nil
+}
+
+// Validate checks that MaxAttempts is not negative, the durations parse and
+// OnFailure is known.
+func (p RetryPolicy) Validate() error {
+	return p.validate(field.NewPath("retryPolicy")).ToAggregate()
+}
+
+// validate is Validate reporting each problem against its field under path
+// instead of stopping at the first.
+func (p RetryPolicy) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if p.MaxAttempts < 0 {
+		errs = append(errs, field.Invalid(path.Child("maxAttempts"), p.MaxAttempts, "must not be negative"))
+	}
+	for _, d := range []struct {
+		name, value string
+	}{
+		{"backoff", p.Backoff},
+		{"maxBackoff", p.MaxBackoff},
+	} {
+		if _, detail := checkSpecDuration(d.value, 0); detail != "" {
+			errs = append(errs, field.Invalid(path.Child(d.name), d.value, detail))
+		}
+	}
+	if p.OnFailure != "" && !slices.Contains(supportedRetryFailurePolicies, p.OnFailure) {
+		errs = append(errs, field.NotSupported(path.Child("onFailure"), p.OnFailure, supportedRetryFailurePolicies))
+	}
+	return errs
+}
@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	var p RetryPolicy
+	if got := p.Attempts(); got != 1 {
+		t.Errorf("Attempts() = %d, want 1", got)
+	}
+	if p.WarnOnly() {
+		t.Error("WarnOnly() = true, want FailFast by default")
+	}
+	if got, err := p.Delay(1); err != nil || got != DefaultRetryBackoff {
+		t.Errorf("Delay(1) = %v, %v, want %v", got, err, DefaultRetryBackoff)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: "10s", MaxBackoff: "1m"}
+	for attempt, want := range map[int32]time.Duration{
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 40 * time.Second,
+		4: time.Minute,
+		9: time.Minute,
+	} {
+		if got, err := p.Delay(attempt); err != nil || got != want {
+			t.Errorf("Delay(%d) = %v, %v, want %v", attempt, got, err, want)
+		}
+	}
+
+	if _, err := (RetryPolicy{Backoff: "soon"}).Delay(1); err == nil {
+		t.Error("Delay with an invalid backoff should fail")
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	if err := (RetryPolicy{MaxAttempts: 3, Backoff: "30s", MaxBackoff: "5m", OnFailure: RetryWarn}).Validate(); err != nil {
+		t.Errorf("valid policy: %v", err)
+	}
+	for name, p := range map[string]RetryPolicy{
+		"negative attempts": {MaxAttempts: -1},
+		"bad backoff":       {Backoff: "soon"},
+		"long max backoff":  {MaxBackoff: "48h"},
+		"unknown onFailure": {OnFailure: "Ignore"},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	wd := &WorkloadDeployment{Spec: WorkloadDeploymentSpec{
+		WorkloadRef: ResourceReference{Name: "app"},
+		RetryPolicy: &RetryPolicy{MaxAttempts: -1, OnFailure: "Ignore"},
+	}}
+	if errs := wd.Validate(); len(errs) != 2 {
+		t.Errorf("Validate() = %v, want both retryPolicy problems", errs)
+	}
+}
@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+)
+
+// StrategyRollingUpdate deploys a WorkloadDeployment to its target clusters
+// a few at a time, as configured by its RolloutConfig.
+const StrategyRollingUpdate = "RollingUpdate"
+
+// DefaultHealthCheckTimeout is how long a rolling rollout waits for a
+// cluster's workload to become ready when HealthCheckTimeout is unset.
+const DefaultHealthCheckTimeout = 5 * time.Minute
+
+// maxSpecDuration bounds the waits a WorkloadDeployment configures, so a
+// typo like "60h" is rejected rather than stalling a rollout for days.
+const maxSpecDuration = 24 * time.Hour
+
+// IsRolling reports whether spec is rolled out cluster by cluster. That is
+// the case when a RolloutConfig is set and the strategy is RollingUpdate or
+// unset; without a RolloutConfig every cluster is deployed at once.
+func (spec WorkloadDeploymentSpec) IsRolling() bool {
+	return spec.RolloutConfig != nil && (spec.Strategy == "" || spec.Strategy == StrategyRollingUpdate)
+}
+
+// BatchSize is how many clusters are deployed to at a time: MaxUnavailable,
+// or 1 when unset.
+func (r RolloutConfig) BatchSize() int {
+	if r.MaxUnavailable == nil {
+		return 1
+	}
+	return int(*r.MaxUnavailable)
+}
+
+// Pause returns PauseBetweenClusters, or 0 when unset.
+func (r RolloutConfig) Pause() (time.Duration, error) {
+	return parseSpecDuration("rolloutConfig.pauseBetweenClusters", r.PauseBetweenClusters, 0)
+}
+
+// HealthTimeout returns HealthCheckTimeout, or DefaultHealthCheckTimeout when
+// unset.
+func (r RolloutConfig) HealthTimeout() (time.Duration, error) {
+	return parseSpecDuration("rolloutConfig.healthCheckTimeout", r.HealthCheckTimeout, DefaultHealthCheckTimeout)
+}
+
+// Validate checks that MaxUnavailable is positive and the durations parse.
+// MaxSurge has no meaning across clusters and is not checked.
+func (r RolloutConfig) Validate() error {
+	if r.MaxUnavailable != nil && *r.MaxUnavailable < 1 {
+		return fmt.Errorf("rolloutConfig.maxUnavailable must be at least 1")
+	}
+	if _, err := r.Pause(); err != nil {
+		return err
+	}
+	_, err := r.HealthTimeout()
+	return err
+}
+
+func parseSpecDuration(field, s string, def time.Duration) (time.Duration, error) {
+	d, detail := checkSpecDuration(s, def)
+	if detail != "" {
+		return 0, fmt.Errorf("%s %s", field, detail)
+	}
+	return d, nil
+}
+
+// checkSpecDuration parses s, returning def when it is empty. When s is not a
+// duration within bounds it returns what is wrong, phrased to follow the
+// field name.
+func checkSpecDuration(s string, def time.Duration) (time.Duration, string) {
+	if s == "" {
+		return def, ""
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, "must be a duration such as 30s or 5m"
+	}
+	if d < 0 || d > maxSpecDuration {
+		return 0, fmt.Sprintf("must be between 0 and %s", maxSpecDuration)
+	}
+	return d, ""
+}
@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkloadDeploymentSpecIsRolling(t *testing.T) {
+	cfg := &RolloutConfig{}
+	tests := []struct {
+		spec WorkloadDeploymentSpec
+		want bool
+	}{
+		{WorkloadDeploymentSpec{}, false},
+		{WorkloadDeploymentSpec{Strategy: StrategyRollingUpdate}, false},
+		{WorkloadDeploymentSpec{RolloutConfig: cfg}, true},
+		{WorkloadDeploymentSpec{Strategy: StrategyRollingUpdate, RolloutConfig: cfg}, true},
+		{WorkloadDeploymentSpec{Strategy: "Recreate", RolloutConfig: cfg}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.IsRolling(); got != tt.want {
+			t.Errorf("IsRolling(strategy=%q, config=%t) = %t, want %t",
+				tt.spec.Strategy, tt.spec.RolloutConfig != nil, got, tt.want)
+		}
+	}
+}
+
+func TestRolloutConfigDefaults(t *testing.T) {
+	var r RolloutConfig
+	if got := r.BatchSize(); got != 1 {
+		t.Errorf("BatchSize() = %d, want 1", got)
+	}
+	if got, err := r.Pause(); err != nil || got != 0 {
+		t.Errorf("Pause() = %v, %v, want 0", got, err)
+	}
+	if got, err := r.HealthTimeout(); err != nil || got != DefaultHealthCheckTimeout {
+		t.Errorf("HealthTimeout() = %v, %v, want %v", got, err, DefaultHealthCheckTimeout)
+	}
+
+	two := int32(2)
+	r = RolloutConfig{MaxUnavailable: &two, PauseBetweenClusters: "30s", HealthCheckTimeout: "2m"}
+	if got := r.BatchSize(); got != 2 {
+		t.Errorf("BatchSize() = %d, want 2", got)
+	}
+	if got, _ := r.Pause(); got != 30*time.Second {
+		t.Errorf("Pause() = %v, want 30s", got)
+	}
+	if got, _ := r.HealthTimeout(); got != 2*time.Minute {
+		t.Errorf("HealthTimeout() = %v, want 2m", got)
+	}
+}
+
+func TestRolloutConfigValidate(t *testing.T) {
+	zero := int32(0)
+	invalid := map[string]RolloutConfig{
+		"zero maxUnavailable": {MaxUnavailable: &zero},
+		"bad pause":           {PauseBetweenClusters: "thirty seconds"},
+		"negative pause":      {PauseBetweenClusters: "-1s"},
+		"huge health timeout": {HealthCheckTimeout: "60h"},
+	}
+	for name, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := (RolloutConfig{PauseBetweenClusters: "0s"}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
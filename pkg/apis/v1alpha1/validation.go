@@ -0,0 +1,128 @@
+package v1alpha1
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// StrategyRecreate and StrategyBlueGreen are the remaining strategies the
+// WorkloadDeployment CRD accepts alongside StrategyRollingUpdate and
+// StrategyCanary.
+const (
+	StrategyRecreate  = "Recreate"
+	StrategyBlueGreen = "BlueGreen"
+)
+
+// supportedStrategies lists the values Strategy may take, in the order the
+// CRD's enum declares them.
+var supportedStrategies = []string{StrategyRollingUpdate, StrategyRecreate, StrategyBlueGreen, StrategyCanary}
+
+// Validate reports every problem with the ManagedWorkload's spec that would
+// leave it impossible to deploy: a workloadRef without a kind or name, or
+// both targetClusters and targetGroups set, which would leave it ambiguous
+// where the workload goes, a networkPolicy peer the generated policy could
+// not express, a replicaDistribution that cannot be split, a placement
+// constraint that cannot be evaluated, and a captured manifest that is not
+// the workload workloadRef names.
+func (mw *ManagedWorkload) Validate() field.ErrorList {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	ref := spec.Child("workloadRef")
+	if mw.Spec.WorkloadRef.Kind == "" {
+		errs = append(errs, field.Required(ref.Child("kind"), ""))
+	}
+	if mw.Spec.WorkloadRef.Name == "" {
+		errs = append(errs, field.Required(ref.Child("name"), ""))
+	}
+	if len(mw.Spec.TargetClusters) > 0 && len(mw.Spec.TargetGroups) > 0 {
+		errs = append(errs, field.Forbidden(spec.Child("targetGroups"), "may not be set together with spec.targetClusters"))
+	}
+	if np := mw.Spec.NetworkPolicy; np != nil {
+		errs = append(errs, np.validate(spec.Child("networkPolicy"))...)
+	}
+	if rd := mw.Spec.ReplicaDistribution; rd != nil {
+		errs = append(errs, rd.validate(spec.Child("replicaDistribution"), mw.Spec.Replicas)...)
+	}
+	for i, p := range mw.Spec.Placement {
+		errs = append(errs, p.validate(spec.Child("placement").Index(i), mw.Name)...)
+	}
+	if mw.Spec.Manifest != nil {
+		manifest := unstructured.Unstructured{Object: mw.Spec.Manifest}
+		path := spec.Child("manifest")
+		if kind := manifest.GetKind(); kind != mw.Spec.WorkloadRef.Kind {
+			errs = append(errs, field.Invalid(path.Child("kind"), kind, "must match spec.workloadRef.kind"))
+		}
+		if name := manifest.GetName(); name != mw.Spec.WorkloadRef.Name {
+			errs = append(errs, field.Invalid(path.Child("metadata", "name"), name, "must match spec.workloadRef.name"))
+		}
+	}
+	return errs
+}
+
+// Validate reports every problem with the WorkloadDeployment's spec: a
+// workloadRef without a name, an unknown strategy, a rolloutConfig whose
+// maxUnavailable or durations are out of range, a dependsOn entry that is
+// empty, repeated or names the deployment itself, and an invalid
+// retryPolicy. An empty strategy is
+// allowed and means RollingUpdate. Unlike a ManagedWorkload's targets,
+// targetClusters and targetGroupRef may be combined; their clusters are
+// merged. Cycles through other deployments are only found when the
+// deployment is reconciled.
+//
+// Deployment windows, canary and backup settings and change metadata are
+// checked separately by their own Validate methods.
+func (wd *WorkloadDeployment) Validate() field.ErrorList {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	if wd.Spec.WorkloadRef.Name == "" {
+		errs = append(errs, field.Required(spec.Child("workloadRef", "name"), ""))
+	}
+	if s := wd.Spec.Strategy; s != "" && !slices.Contains(supportedStrategies, s) {
+		errs = append(errs, field.NotSupported(spec.Child("strategy"), s, supportedStrategies))
+	}
+	if rc := wd.Spec.RolloutConfig; rc != nil {
+		errs = append(errs, rc.validate(spec.Child("rolloutConfig"))...)
+	}
+	seen := make(map[string]bool, len(wd.Spec.DependsOn))
+	for i, name := range wd.Spec.DependsOn {
+		path := spec.Child("dependsOn").Index(i)
+		switch {
+		case name == "":
+			errs = append(errs, field.Required(path, ""))
+		case name == wd.Name:
+			errs = append(errs, field.Invalid(path, name, "a deployment cannot depend on itself"))
+		case seen[name]:
+			errs = append(errs, field.Duplicate(path, name))
+		}
+		seen[name] = true
+	}
+	if _, detail := checkSpecDuration(wd.Spec.DependencyTimeout, 0); detail != "" {
+		errs = append(errs, field.Invalid(spec.Child("dependencyTimeout"), wd.Spec.DependencyTimeout, detail))
+	}
+	if rp := wd.Spec.RetryPolicy; rp != nil {
+		errs = append(errs, rp.validate(spec.Child("retryPolicy"))...)
+	}
+	return errs
+}
+
+// validate is Validate reporting each problem against its field under path
+// instead of stopping at the first.
+func (r RolloutConfig) validate(path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if r.MaxUnavailable != nil && *r.MaxUnavailable < 1 {
+		errs = append(errs, field.Invalid(path.Child("maxUnavailable"), *r.MaxUnavailable, "must be at least 1"))
+	}
+	for _, d := range []struct {
+		name, value string
+	}{
+		{"pauseBetweenClusters", r.PauseBetweenClusters},
+		{"healthCheckTimeout", r.HealthCheckTimeout},
+	} {
+		if _, detail := checkSpecDuration(d.value, 0); detail != "" {
+			errs = append(errs, field.Invalid(path.Child(d.name), d.value, detail))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,213 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestManagedWorkloadValidate(t *testing.T) {
+	valid := ManagedWorkload{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Spec: ManagedWorkloadSpec{
+			WorkloadRef:  WorkloadReference{Kind: "Deployment", Name: "app"},
+			TargetGroups: []string{"prod"},
+		},
+	}
+	if errs := valid.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+
+	invalid := ManagedWorkload{Spec: ManagedWorkloadSpec{
+		TargetClusters: []string{"c1"},
+		TargetGroups:   []string{"prod"},
+	}}
+	errs := invalid.Validate()
+	want := []string{"spec.workloadRef.kind", "spec.workloadRef.name", "spec.targetGroups"}
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() = %v, want errors on %v", errs, want)
+	}
+	for i, e := range errs {
+		if e.Field != want[i] {
+			t.Errorf("error %d is on %s, want %s", i, e.Field, want[i])
+		}
+	}
+
+	withPolicy := valid
+	withPolicy.Spec.NetworkPolicy = &GeneratedNetworkPolicy{Enabled: true, AllowedPeers: []NetworkPolicyPeer{
+		{Namespace: "monitoring", Ports: []NetworkPolicyPort{{Port: 9090}}},
+		{Direction: NetworkPolicyDirectionEgress, CIDR: "10.0.0.0/8", Ports: []NetworkPolicyPort{{Port: 5432, Protocol: "TCP"}}},
+	}}
+	if errs := withPolicy.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+	withPolicy.Spec.NetworkPolicy = &GeneratedNetworkPolicy{Enabled: true, AllowedPeers: []NetworkPolicyPeer{
+		{Direction: "Both", CIDR: "10.0.0.0/8", PodLabels: map[string]string{"app": "db"}},
+		{CIDR: "10.0.0.0", Ports: []NetworkPolicyPort{{Port: 70000, Protocol: "ICMP"}}},
+	}}
+	errs = withPolicy.Validate()
+	want = []string{
+		"spec.networkPolicy.allowedPeers[0].direction",
+		"spec.networkPolicy.allowedPeers[0].cidr",
+		"spec.networkPolicy.allowedPeers[1].cidr",
+		"spec.networkPolicy.allowedPeers[1].ports[0].port",
+		"spec.networkPolicy.allowedPeers[1].ports[0].protocol",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() = %v, want errors on %v", errs, want)
+	}
+	for i, e := range errs {
+		if e.Field != want[i] {
+			t.Errorf("error %d is on %s, want %s", i, e.Field, want[i])
+		}
+	}
+
+	six := int32(6)
+	distributions := []struct {
+		name         string
+		replicas     *int32
+		distribution ReplicaDistribution
+		want         []string
+	}{
+		{name: "even", replicas: &six, distribution: ReplicaDistribution{Clusters: map[string]int32{"c1": 2}}},
+		{name: "capacity", replicas: &six, distribution: ReplicaDistribution{Strategy: ReplicaStrategyCapacity, CapacityResource: ReplicaCapacityMemory}},
+		{name: "fixed", distribution: ReplicaDistribution{Strategy: ReplicaStrategyFixed, Clusters: map[string]int32{"c1": 0}}},
+		{name: "unknown strategy", replicas: &six, distribution: ReplicaDistribution{Strategy: "Random"},
+			want: []string{"spec.replicaDistribution.strategy"}},
+		{name: "even without total", distribution: ReplicaDistribution{},
+			want: []string{"spec.replicas"}},
+		{name: "too many pinned", replicas: &six, distribution: ReplicaDistribution{Clusters: map[string]int32{"c1": 7}},
+			want: []string{"spec.replicaDistribution.clusters"}},
+		{name: "negative pinned", replicas: &six, distribution: ReplicaDistribution{Clusters: map[string]int32{"c1": -1}},
+			want: []string{"spec.replicaDistribution.clusters[c1]"}},
+		{name: "capacity resource on even", replicas: &six, distribution: ReplicaDistribution{CapacityResource: ReplicaCapacityCPU},
+			want: []string{"spec.replicaDistribution.capacityResource"}},
+		{name: "unknown capacity resource", replicas: &six, distribution: ReplicaDistribution{Strategy: ReplicaStrategyCapacity, CapacityResource: "gpu"},
+			want: []string{"spec.replicaDistribution.capacityResource"}},
+		{name: "fixed without clusters", distribution: ReplicaDistribution{Strategy: ReplicaStrategyFixed},
+			want: []string{"spec.replicaDistribution.clusters"}},
+	}
+	for _, tt := range distributions {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := valid
+			mw.Spec.Replicas = tt.replicas
+			mw.Spec.ReplicaDistribution = &tt.distribution
+			errs := mw.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want errors on %v", errs, tt.want)
+			}
+			for i, e := range errs {
+				if e.Field != tt.want[i] {
+					t.Errorf("error %d is on %s, want %s", i, e.Field, tt.want[i])
+				}
+			}
+		})
+	}
+
+	constraints := []struct {
+		name       string
+		constraint PlacementConstraint
+		want       []string
+	}{
+		{name: "anti-affinity", constraint: PlacementConstraint{Type: PlacementAntiAffinity, Workloads: []string{"db"}, Required: true}},
+		{name: "affinity by zone", constraint: PlacementConstraint{Type: PlacementAffinity, Workloads: []string{"db"}, TopologyKey: PlacementTopologyZone}},
+		{name: "spread", constraint: PlacementConstraint{Type: PlacementSpread, TopologyKey: PlacementTopologyRegion}},
+		{name: "unknown type", constraint: PlacementConstraint{Type: "Pack"},
+			want: []string{"spec.placement[0].type"}},
+		{name: "unknown topology", constraint: PlacementConstraint{Type: PlacementAntiAffinity, Workloads: []string{"db"}, TopologyKey: "rack"},
+			want: []string{"spec.placement[0].topologyKey"}},
+		{name: "affinity without workloads", constraint: PlacementConstraint{Type: PlacementAffinity},
+			want: []string{"spec.placement[0].workloads"}},
+		{name: "names itself", constraint: PlacementConstraint{Type: PlacementAntiAffinity, Workloads: []string{"db", "app", ""}},
+			want: []string{"spec.placement[0].workloads[1]", "spec.placement[0].workloads[2]"}},
+		{name: "spread with workloads across clusters", constraint: PlacementConstraint{Type: PlacementSpread, Workloads: []string{"db"}},
+			want: []string{"spec.placement[0].workloads", "spec.placement[0].topologyKey"}},
+	}
+	for _, tt := range constraints {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := valid
+			mw.Spec.Placement = []PlacementConstraint{tt.constraint}
+			errs := mw.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want errors on %v", errs, tt.want)
+			}
+			for i, e := range errs {
+				if e.Field != tt.want[i] {
+					t.Errorf("error %d is on %s, want %s", i, e.Field, tt.want[i])
+				}
+			}
+		})
+	}
+
+	imported := valid
+	imported.Spec.Manifest = map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "app"},
+	}
+	if errs := imported.Validate(); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+	imported.Spec.Manifest = map[string]interface{}{"kind": "StatefulSet", "metadata": map[string]interface{}{"name": "other"}}
+	errs = imported.Validate()
+	want = []string{"spec.manifest.kind", "spec.manifest.metadata.name"}
+	if len(errs) != len(want) {
+		t.Fatalf("Validate() = %v, want errors on %v", errs, want)
+	}
+	for i, e := range errs {
+		if e.Field != want[i] {
+			t.Errorf("error %d is on %s, want %s", i, e.Field, want[i])
+		}
+	}
+}
+
+func TestWorkloadDeploymentValidate(t *testing.T) {
+	zero := int32(0)
+	tests := []struct {
+		name   string
+		spec   WorkloadDeploymentSpec
+		fields []string
+	}{
+		{"minimal", WorkloadDeploymentSpec{WorkloadRef: ResourceReference{Name: "app"}}, nil},
+		{"supported strategy", WorkloadDeploymentSpec{WorkloadRef: ResourceReference{Name: "app"}, Strategy: StrategyBlueGreen}, nil},
+		{"missing workload", WorkloadDeploymentSpec{}, []string{"spec.workloadRef.name"}},
+		{"unknown strategy", WorkloadDeploymentSpec{WorkloadRef: ResourceReference{Name: "app"}, Strategy: "Rolling"}, []string{"spec.strategy"}},
+		{"merged targets", WorkloadDeploymentSpec{
+			WorkloadRef:    ResourceReference{Name: "app"},
+			TargetClusters: []string{"c1"},
+			TargetGroupRef: &ResourceReference{Name: "prod"},
+		}, nil},
+		{"rollout config", WorkloadDeploymentSpec{
+			WorkloadRef: ResourceReference{Name: "app"},
+			RolloutConfig: &RolloutConfig{
+				MaxUnavailable:       &zero,
+				PauseBetweenClusters: "a minute",
+				HealthCheckTimeout:   "60h",
+			},
+		}, []string{"spec.rolloutConfig.maxUnavailable", "spec.rolloutConfig.pauseBetweenClusters", "spec.rolloutConfig.healthCheckTimeout"}},
+		{"dependencies", WorkloadDeploymentSpec{
+			WorkloadRef:       ResourceReference{Name: "app"},
+			DependsOn:         []string{"model-server", "cache"},
+			DependencyTimeout: "15m",
+		}, nil},
+		{"invalid dependencies", WorkloadDeploymentSpec{
+			WorkloadRef:       ResourceReference{Name: "app"},
+			DependsOn:         []string{"", "router", "cache", "cache"},
+			DependencyTimeout: "forever",
+		}, []string{"spec.dependsOn[0]", "spec.dependsOn[1]", "spec.dependsOn[3]", "spec.dependencyTimeout"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wd := WorkloadDeployment{ObjectMeta: metav1.ObjectMeta{Name: "router"}, Spec: tt.spec}
+			errs := wd.Validate()
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("Validate() = %v, want errors on %v", errs, tt.fields)
+			}
+			for i, e := range errs {
+				if e.Field != tt.fields[i] {
+					t.Errorf("error %d is on %s, want %s", i, e.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
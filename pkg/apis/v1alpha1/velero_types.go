@@ -0,0 +1,160 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Velero CRD Group Version Resources
+var (
+	// VeleroBackupGVR is the GroupVersionResource for Velero Backup (v1)
+	VeleroBackupGVR = schema.GroupVersionResource{
+		Group:    "velero.io",
+		Version:  "v1",
+		Resource: "backups",
+	}
+
+	// VeleroScheduleGVR is the GroupVersionResource for Velero Schedule (v1)
+	VeleroScheduleGVR = schema.GroupVersionResource{
+		Group:    "velero.io",
+		Version:  "v1",
+		Resource: "schedules",
+	}
+
+	// VeleroRestoreGVR is the GroupVersionResource for Velero Restore (v1)
+	VeleroRestoreGVR = schema.GroupVersionResource{
+		Group:    "velero.io",
+		Version:  "v1",
+		Resource: "restores",
+	}
+)
+
+// Velero backup phases the console acts on.
+const (
+	VeleroPhaseNew              = "New"
+	VeleroPhaseInProgress       = "InProgress"
+	VeleroPhaseCompleted        = "Completed"
+	VeleroPhasePartiallyFailed  = "PartiallyFailed"
+	VeleroPhaseFailed           = "Failed"
+	VeleroPhaseFailedValidation = "FailedValidation"
+)
+
+// VeleroScheduleLabel is set by Velero on backups a Schedule created.
+const VeleroScheduleLabel = "velero.io/schedule-name"
+
+// VeleroBackup represents a Velero Backup resource
+type VeleroBackup struct {
+	Name               string   `json:"name"`
+	Namespace          string   `json:"namespace"`
+	Cluster            string   `json:"cluster"`
+	Phase              string   `json:"phase"`              // New, InProgress, Completed, PartiallyFailed, Failed, ...
+	Schedule           string   `json:"schedule,omitempty"` // Schedule that created the backup
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	StorageLocation    string   `json:"storageLocation,omitempty"`
+	TTL                string   `json:"ttl,omitempty"`
+	StartedAt          string   `json:"startedAt,omitempty"`
+	CompletedAt        string   `json:"completedAt,omitempty"`
+	Expiration         string   `json:"expiration,omitempty"`
+	Errors             int64    `json:"errors"`
+	Warnings           int64    `json:"warnings"`
+	FailureReason      string   `json:"failureReason,omitempty"`
+}
+
+// Failed reports whether the backup finished without a usable result.
+// PartiallyFailed counts, since some resources are missing from it.
+func (b VeleroBackup) Failed() bool {
+	switch b.Phase {
+	case VeleroPhaseFailed, VeleroPhasePartiallyFailed, VeleroPhaseFailedValidation:
+		return true
+	}
+	return false
+}
+
+// Finished reports whether Velero is done with the backup.
+func (b VeleroBackup) Finished() bool {
+	return b.Phase == VeleroPhaseCompleted || b.Failed()
+}
+
+// VeleroSchedule represents a Velero Schedule resource
+type VeleroSchedule struct {
+	Name               string   `json:"name"`
+	Namespace          string   `json:"namespace"`
+	Cluster            string   `json:"cluster"`
+	Schedule           string   `json:"schedule"` // cron expression
+	Paused             bool     `json:"paused"`
+	Phase              string   `json:"phase,omitempty"` // New, Enabled, FailedValidation
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	TTL                string   `json:"ttl,omitempty"`
+	LastBackup         string   `json:"lastBackup,omitempty"`
+}
+
+// VeleroRestore represents a Velero Restore resource
+type VeleroRestore struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Cluster       string `json:"cluster"`
+	Backup        string `json:"backup"`
+	Phase         string `json:"phase"`
+	StartedAt     string `json:"startedAt,omitempty"`
+	CompletedAt   string `json:"completedAt,omitempty"`
+	Errors        int64  `json:"errors"`
+	Warnings      int64  `json:"warnings"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// VeleroBackupRequest describes an ad-hoc backup. With Schedule set the
+// backup copies that schedule's template, like `velero backup create
+// --from-schedule`; the other fields then override it.
+type VeleroBackupRequest struct {
+	// Name of the backup; generated from Schedule or "console" when empty
+	Name string `json:"name,omitempty"`
+
+	// Schedule is a Schedule whose template the backup copies
+	Schedule string `json:"schedule,omitempty"`
+
+	// IncludedNamespaces limits the backup to these namespaces; empty means
+	// all namespaces unless Schedule sets them
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+
+	// StorageLocation is the BackupStorageLocation to write to
+	StorageLocation string `json:"storageLocation,omitempty"`
+
+	// TTL is how long Velero keeps the backup, e.g. "72h"
+	TTL string `json:"ttl,omitempty"`
+
+	// Labels are added to the backup. The console sets them on the backups
+	// it takes itself; they are not read from API requests.
+	Labels map[string]string `json:"-"`
+}
+
+// DefaultPreDeployBackupTimeout is how long a rollout waits for a pre-deploy
+// backup when PreDeployBackup.Timeout is unset.
+const DefaultPreDeployBackupTimeout = 3 * time.Minute
+
+// WaitTimeout returns Timeout, or DefaultPreDeployBackupTimeout when unset.
+func (b PreDeployBackup) WaitTimeout() (time.Duration, error) {
+	return parseSpecDuration("preDeployBackup.timeout", b.Timeout, DefaultPreDeployBackupTimeout)
+}
+
+// Validate checks that the TTL and timeout parse.
+func (b PreDeployBackup) Validate() error {
+	if err := ValidateVeleroTTL("preDeployBackup.ttl", b.TTL); err != nil {
+		return err
+	}
+	_, err := b.WaitTimeout()
+	return err
+}
+
+// ValidateVeleroTTL checks that an optional backup TTL is a positive
+// duration. Velero keeps backups for as long as asked, so there is no cap.
+func ValidateVeleroTTL(field, ttl string) error {
+	if ttl == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(ttl); err != nil || d <= 0 {
+		return fmt.Errorf("%s must be a positive duration such as 72h", field)
+	}
+	return nil
+}
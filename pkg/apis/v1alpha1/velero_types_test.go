@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVeleroBackupPhases(t *testing.T) {
+	tests := []struct {
+		phase            string
+		failed, finished bool
+	}{
+		{VeleroPhaseNew, false, false},
+		{VeleroPhaseInProgress, false, false},
+		{VeleroPhaseCompleted, false, true},
+		{VeleroPhasePartiallyFailed, true, true},
+		{VeleroPhaseFailed, true, true},
+		{VeleroPhaseFailedValidation, true, true},
+	}
+	for _, tt := range tests {
+		b := VeleroBackup{Phase: tt.phase}
+		if got := b.Failed(); got != tt.failed {
+			t.Errorf("Failed(%s) = %t, want %t", tt.phase, got, tt.failed)
+		}
+		if got := b.Finished(); got != tt.finished {
+			t.Errorf("Finished(%s) = %t, want %t", tt.phase, got, tt.finished)
+		}
+	}
+}
+
+func TestPreDeployBackupValidate(t *testing.T) {
+	var b PreDeployBackup
+	if got, err := b.WaitTimeout(); err != nil || got != DefaultPreDeployBackupTimeout {
+		t.Errorf("WaitTimeout() = %v, %v, want %v", got, err, DefaultPreDeployBackupTimeout)
+	}
+	if err := b.Validate(); err != nil {
+		t.Errorf("Validate() on an empty config = %v", err)
+	}
+
+	b = PreDeployBackup{TTL: "720h", Timeout: "10m"}
+	if err := b.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if got, _ := b.WaitTimeout(); got != 10*time.Minute {
+		t.Errorf("WaitTimeout() = %v, want 10m", got)
+	}
+
+	for _, bad := range []PreDeployBackup{
+		{TTL: "a month"},
+		{TTL: "0s"},
+		{Timeout: "soon"},
+		{Timeout: "48h"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", bad)
+		}
+	}
+}
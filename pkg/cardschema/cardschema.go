@@ -0,0 +1,160 @@
+// Package cardschema holds the config schema of each dashboard card type and
+// validates card configs against it. The schemas are a small subset of JSON
+// Schema: an object whose properties are strings, integers or booleans, so
+// they can be served to clients as-is.
+package cardschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Property types.
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+const (
+	// maxClusterNameLength bounds a cluster (kubeconfig context) name.
+	maxClusterNameLength = 253
+	// maxNamespaceLength is the Kubernetes namespace name limit.
+	maxNamespaceLength = 63
+	// maxTextLength bounds other free-text properties.
+	maxTextLength = 256
+	// maxListItems bounds the item count properties; cards page beyond it.
+	maxListItems = 1000
+)
+
+// Property is the schema of one config key.
+type Property struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	MaxLength   int    `json:"maxLength,omitempty"`
+	Minimum     *int64 `json:"minimum,omitempty"`
+	Maximum     *int64 `json:"maximum,omitempty"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// Schema is the schema of a card type's config. Keys it does not declare
+// are allowed and kept, so a client can store settings the server does not
+// know about yet; declared keys must match their Property.
+type Schema struct {
+	Type                 string              `json:"type"`
+	Properties           map[string]Property `json:"properties"`
+	AdditionalProperties bool                `json:"additionalProperties"`
+}
+
+// Defaults returns the default value of each property that has one.
+func (s Schema) Defaults() map[string]any {
+	defaults := make(map[string]any)
+	for name, p := range s.Properties {
+		if p.Default != nil {
+			defaults[name] = p.Default
+		}
+	}
+	return defaults
+}
+
+// Violation is one way a config fails its schema. Field is the config key,
+// or empty when the config as a whole is wrong.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return v.Field + ": " + v.Message
+}
+
+// Validate checks config against the schema and returns every violation,
+// sorted by field. An empty or null config is valid, as is a null value for
+// any key, which the card treats as unset.
+func (s Schema) Validate(config json.RawMessage) []Violation {
+	trimmed := bytes.TrimSpace(config)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	var values map[string]json.RawMessage
+	if trimmed[0] != '{' || json.Unmarshal(trimmed, &values) != nil {
+		return []Violation{{Message: "config must be a JSON object"}}
+	}
+
+	var violations []Violation
+	for name, raw := range values {
+		p, ok := s.Properties[name]
+		if !ok {
+			if !s.AdditionalProperties {
+				violations = append(violations, Violation{Field: name, Message: "is not a known setting"})
+			}
+			continue
+		}
+		if msg := p.check(raw); msg != "" {
+			violations = append(violations, Violation{Field: name, Message: msg})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+	return violations
+}
+
+// check returns what is wrong with raw, or "" when it matches p.
+func (p Property) check(raw json.RawMessage) string {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return ""
+	}
+	switch p.Type {
+	case TypeString:
+		var v string
+		if json.Unmarshal(raw, &v) != nil {
+			return "must be a string"
+		}
+		if p.MaxLength > 0 && len(v) > p.MaxLength {
+			return fmt.Sprintf("must be at most %d characters", p.MaxLength)
+		}
+	case TypeInteger:
+		var v int64
+		if json.Unmarshal(raw, &v) != nil {
+			return "must be a whole number"
+		}
+		if p.Minimum != nil && v < *p.Minimum {
+			return fmt.Sprintf("must be at least %d", *p.Minimum)
+		}
+		if p.Maximum != nil && v > *p.Maximum {
+			return fmt.Sprintf("must be at most %d", *p.Maximum)
+		}
+	case TypeBoolean:
+		var v bool
+		if json.Unmarshal(raw, &v) != nil {
+			return "must be true or false"
+		}
+	}
+	return ""
+}
+
+// For returns the config schema of a card type, and false for a type
+// models.GetCardTypes does not list.
+func For(t models.CardType) (Schema, bool) {
+	props, ok := registry[t]
+	if !ok {
+		return Schema{}, false
+	}
+	return Schema{Type: "object", Properties: props, AdditionalProperties: true}, true
+}
+
+// Validate checks config against the schema of card type t. An unknown type
+// is reported as a single violation.
+func Validate(t models.CardType, config json.RawMessage) []Violation {
+	s, ok := For(t)
+	if !ok {
+		return []Violation{{Message: fmt.Sprintf("unknown card_type %q", t)}}
+	}
+	return s.Validate(config)
+}
@@ -0,0 +1,72 @@
+package cardschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+func TestEveryCardTypeHasASchema(t *testing.T) {
+	for _, info := range models.GetCardTypes() {
+		s, ok := For(info.Type)
+		if !ok {
+			t.Errorf("%s has no config schema", info.Type)
+			continue
+		}
+		if violations := s.Validate(mustJSON(t, s.Defaults())); len(violations) != 0 {
+			t.Errorf("%s defaults fail its own schema: %v", info.Type, violations)
+		}
+	}
+	if _, ok := For("no_such_card"); ok {
+		t.Error("For returned a schema for an unknown type")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"empty", ``, nil},
+		{"null", `null`, nil},
+		{"valid", `{"cluster":"prod","namespace":"shop","limit":50,"autoRefresh":false}`, nil},
+		{"null value is unset", `{"limit":null}`, nil},
+		{"unknown key is kept", `{"title":"Mine"}`, nil},
+		{"not an object", `[1,2]`, []string{"config must be a JSON object"}},
+		{"every bad field", `{"limit":"50","cluster":7,"autoRefresh":"yes"}`, []string{
+			"autoRefresh: must be true or false",
+			"cluster: must be a string",
+			"limit: must be a whole number",
+		}},
+		{"out of range", `{"limit":0}`, []string{"limit: must be at least 1"}},
+		{"fraction", `{"limit":2.5}`, []string{"limit: must be a whole number"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Validate(models.CardTypeEventStream, json.RawMessage(tt.config))
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Validate(%s) = %v, want %v", tt.config, violations, tt.want)
+			}
+			for i, v := range violations {
+				if v.String() != tt.want[i] {
+					t.Errorf("violation %d = %q, want %q", i, v, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if violations := Validate("no_such_card", nil); len(violations) != 1 {
+		t.Errorf("unknown type got %v, want one violation", violations)
+	}
+}
+
+func mustJSON(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,133 @@
+package cardschema
+
+import "github.com/kubestellar/console/pkg/models"
+
+// Properties shared by several card types. The behavior toggles mirror the
+// card editor's, with the same defaults.
+var (
+	clusterProp   = Property{Type: TypeString, Description: "Only show this cluster", MaxLength: maxClusterNameLength}
+	namespaceProp = Property{Type: TypeString, Description: "Only show this namespace", MaxLength: maxNamespaceLength}
+	limitProp     = Property{Type: TypeInteger, Description: "Items per page", Minimum: ptr(1), Maximum: ptr(maxListItems)}
+	maxItemsProp  = Property{Type: TypeInteger, Description: "Max items to display", Minimum: ptr(1), Maximum: ptr(maxListItems)}
+)
+
+func ptr(v int64) *int64 { return &v }
+
+func toggle(description string, def bool) Property {
+	return Property{Type: TypeBoolean, Description: description, Default: def}
+}
+
+// registry maps each card type in models.GetCardTypes to its config
+// properties. Types the card editor gives no fields of their own get the
+// generic cluster, namespace and maxItems filters.
+var registry = map[models.CardType]map[string]Property{
+	models.CardTypeClusterHealth: {
+		"autoRefresh":        toggle("Refresh every 30 seconds", true),
+		"showUnhealthyFirst": toggle("Show unhealthy clusters at the top", true),
+		"alertOnChange":      toggle("Notify when cluster health changes", false),
+	},
+	models.CardTypeAppStatus: {
+		"appName":         {Type: TypeString, Description: "App to show", MaxLength: maxTextLength},
+		"namespace":       namespaceProp,
+		"autoRefresh":     toggle("Refresh app status periodically", true),
+		"showAllReplicas": toggle("Show each replica's status", false),
+	},
+	models.CardTypeEventStream: {
+		"cluster":        clusterProp,
+		"namespace":      namespaceProp,
+		"limit":          {Type: TypeInteger, Description: "Max events", Minimum: ptr(1), Maximum: ptr(maxListItems)},
+		"autoRefresh":    toggle("Poll for new events every 10 seconds", true),
+		"warningsOnly":   toggle("Only show warning and error events", false),
+		"groupByCluster": toggle("Group events by source cluster", false),
+		"soundOnWarning": toggle("Play a sound for new warning events", false),
+	},
+	models.CardTypeDeploymentProgress: {
+		"cluster":         clusterProp,
+		"namespace":       namespaceProp,
+		"maxItems":        maxItemsProp,
+		"autoRefresh":     toggle("Refresh rollout status periodically", true),
+		"showPercentage":  toggle("Show progress as a percentage", true),
+		"alertOnComplete": toggle("Notify when a rollout completes", false),
+		"alertOnStalled":  toggle("Notify when a rollout stalls", true),
+	},
+	models.CardTypePodIssues: {
+		"cluster":          clusterProp,
+		"namespace":        namespaceProp,
+		"autoRefresh":      toggle("Check for new issues every 30 seconds", true),
+		"showRestartCount": toggle("Show container restart counts", true),
+		"includeCompleted": toggle("Show completed pods", false),
+		"alertOnNew":       toggle("Notify when new pod issues appear", false),
+	},
+	models.CardTypeDeploymentIssues: {
+		"cluster":         clusterProp,
+		"namespace":       namespaceProp,
+		"limit":           limitProp,
+		"autoRefresh":     toggle("Check for issues periodically", true),
+		"showAllClusters": toggle("Show issues from every cluster", true),
+		"showProgress":    toggle("Show rollout progress", true),
+		"alertOnNew":      toggle("Notify when new issues appear", false),
+		"paginate":        toggle("Page through issues", false),
+	},
+	models.CardTypeTopPods: {
+		"cluster":      clusterProp,
+		"namespace":    namespaceProp,
+		"maxItems":     maxItemsProp,
+		"autoRefresh":  toggle("Update pod rankings periodically", true),
+		"sortByCPU":    toggle("Rank pods by CPU usage", true),
+		"sortByMemory": toggle("Rank pods by memory usage", false),
+	},
+	models.CardTypeResourceCapacity: {
+		"cluster":     clusterProp,
+		"namespace":   namespaceProp,
+		"maxItems":    maxItemsProp,
+		"autoRefresh": toggle("Refresh capacity periodically", true),
+		"showTrend":   toggle("Show trend indicators", false),
+		"alertOnLow":  toggle("Notify when capacity runs low", false),
+	},
+	models.CardTypeGitOpsDrift: {
+		"cluster":          clusterProp,
+		"namespace":        namespaceProp,
+		"maxItems":         maxItemsProp,
+		"autoRefresh":      toggle("Check for drift periodically", true),
+		"showAllResources": toggle("Show resources in sync too", false),
+		"alertOnDrift":     toggle("Notify when drift is detected", true),
+	},
+	models.CardTypeSecurityIssues: {
+		"cluster":            clusterProp,
+		"namespace":          namespaceProp,
+		"autoRefresh":        toggle("Check for security issues periodically", true),
+		"includeLowSeverity": toggle("Show informational items", false),
+		"alertOnCritical":    toggle("Notify on critical issues", true),
+	},
+	models.CardTypeRBACOverview: {
+		"cluster":       clusterProp,
+		"namespace":     namespaceProp,
+		"maxItems":      maxItemsProp,
+		"autoRefresh":   toggle("Refresh this card automatically", true),
+		"showDetails":   toggle("Show detailed information", true),
+		"alertOnChange": toggle("Notify on significant changes", false),
+	},
+	models.CardTypePolicyViolations: {
+		"cluster":      clusterProp,
+		"namespace":    namespaceProp,
+		"maxItems":     maxItemsProp,
+		"autoRefresh":  toggle("Check for violations periodically", true),
+		"showResolved": toggle("Show resolved violations", false),
+		"alertOnNew":   toggle("Notify on new violations", false),
+	},
+	models.CardTypeUpgradeStatus: {
+		"cluster":             clusterProp,
+		"autoRefresh":         toggle("Check for upgrades periodically", true),
+		"showOnlyUpgradeable": toggle("Only show clusters with an upgrade", false),
+		"hideUnreachable":     toggle("Hide unreachable clusters", false),
+		"alertOnNewUpgrade":   toggle("Notify when an upgrade is available", false),
+	},
+	models.CardTypeNamespaceAnalysis: {
+		"cluster":       clusterProp,
+		"namespace":     namespaceProp,
+		"maxItems":      maxItemsProp,
+		"autoRefresh":   toggle("Refresh this card automatically", true),
+		"showDetails":   toggle("Show detailed information", true),
+		"alertOnChange": toggle("Notify on significant changes", false),
+	},
+}
@@ -7,18 +7,22 @@
 //
 // Handlers with custom Transport settings (SSRF-protection dial hooks,
 // TLS config, redirect policies) should keep their own clients.
+//
+// In air-gapped mode these clients only reach the hosts egress allows.
 package client
 
 import (
 	"net"
 	"net/http"
 	"time"
+
+	"github.com/kubestellar/console/pkg/egress"
 )
 
 // Shared transport with tuned connection pool settings.
 // All simple clients share this transport for TCP connection reuse.
 var sharedTransport = &http.Transport{
-	DialContext:         (&net.Dialer{Timeout: 5 * time.Second}).DialContext,
+	DialContext:         egress.Dial((&net.Dialer{Timeout: 5 * time.Second}).DialContext),
 	MaxIdleConns:        100,
 	MaxConnsPerHost:     10,
 	IdleConnTimeout:     90 * time.Second,
@@ -0,0 +1,247 @@
+// Package clusterexpr evaluates CEL membership expressions for ClusterGroups.
+//
+// An expression sees a single variable, cluster, describing one cluster:
+//
+//	cluster.labels["tier"] == "prod" && cluster.gpuCount >= 8
+//
+// Field names are type-checked when the expression is compiled, so a typo is
+// rejected when the group is created instead of silently matching nothing.
+package clusterexpr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	"github.com/google/cel-go/parser"
+)
+
+const (
+	// MaxExpressionLength bounds the source length accepted by Compile.
+	MaxExpressionLength = 2048
+	// costLimit bounds the work a single evaluation may do; large fleets
+	// evaluate the expression once per cluster on every reconcile.
+	costLimit = 100_000
+)
+
+// ErrInvalidExpression is returned when an expression fails to parse or
+// type-check.
+var ErrInvalidExpression = errors.New("invalid cluster expression")
+
+// Cluster is the metadata environment an expression is evaluated against.
+type Cluster struct {
+	Name      string  `cel:"name"`
+	Healthy   bool    `cel:"healthy"`
+	Reachable bool    `cel:"reachable"`
+	NodeCount int     `cel:"nodeCount"`
+	PodCount  int     `cel:"podCount"`
+	CPUCores  int     `cel:"cpuCores"`
+	MemoryGB  float64 `cel:"memoryGB"`
+	GPUCount  int     `cel:"gpuCount"`
+	// GPUTypes lists the distinct GPU types across all nodes.
+	GPUTypes []string `cel:"gpuTypes"`
+	// Labels holds the node labels every node agrees on, which is where
+	// cluster-wide facts such as region or zone usually live.
+	Labels map[string]string `cel:"labels"`
+	Nodes  []Node            `cel:"nodes"`
+}
+
+// Node is the per-node metadata exposed through cluster.nodes.
+type Node struct {
+	Name     string            `cel:"name"`
+	Labels   map[string]string `cel:"labels"`
+	GPUCount int               `cel:"gpuCount"`
+	GPUType  string            `cel:"gpuType"`
+}
+
+// nodeFields are the Cluster fields derived from per-node data.
+var nodeFields = map[string]bool{"gpuCount": true, "gpuTypes": true, "labels": true, "nodes": true}
+
+// Expression is a compiled membership expression. It is safe for concurrent
+// use.
+type Expression struct {
+	source     string
+	checked    *cel.Ast
+	program    cel.Program
+	explainer  cel.Program
+	needsNodes bool
+}
+
+// Explanation describes how an expression evaluated for one cluster.
+type Explanation struct {
+	Cluster string `json:"cluster"`
+	Matched bool   `json:"matched"`
+	Error   string `json:"error,omitempty"`
+	// Clauses are the top-level && / || operands and what each evaluated to.
+	Clauses []Clause `json:"clauses,omitempty"`
+	// Inputs are the cluster fields the expression reads, with their values.
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// Clause is one operand of the expression's top-level && or || chain.
+type Clause struct {
+	Expression string `json:"expression"`
+	Value      string `json:"value"`
+}
+
+// Compile parses and type-checks expr. The expression must return a bool.
+func Compile(expr string) (*Expression, error) {
+	if len(expr) > MaxExpressionLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidExpression, MaxExpressionLength)
+	}
+	env, err := cel.NewEnv(
+		ext.NativeTypes(reflect.TypeOf(Cluster{}), reflect.TypeOf(Node{}), ext.ParseStructTags(true)),
+		cel.Variable("cluster", cel.ObjectType("clusterexpr.Cluster")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("build cel environment: %w", err)
+	}
+	checked, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, issues.Err())
+	}
+	if checked.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("%w: must return bool, got %s", ErrInvalidExpression, checked.OutputType())
+	}
+	program, err := env.Program(checked, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+	explainer, err := env.Program(checked, cel.CostLimit(costLimit), cel.EvalOptions(cel.OptExhaustiveEval))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExpression, err)
+	}
+
+	e := &Expression{source: expr, checked: checked, program: program, explainer: explainer}
+	for field := range referencedFields(checked.NativeRep().Expr()) {
+		if nodeFields[field] {
+			e.needsNodes = true
+		}
+	}
+	return e, nil
+}
+
+// String returns the expression source.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// NeedsNodes reports whether the expression reads node-derived fields, so
+// callers can skip fetching nodes when it does not.
+func (e *Expression) NeedsNodes() bool {
+	return e.needsNodes
+}
+
+// Matches evaluates the expression for c. Evaluation errors (for example a
+// missing map key) are returned; callers treat them as a non-match.
+func (e *Expression) Matches(c Cluster) (bool, error) {
+	out, _, err := e.program.Eval(map[string]interface{}{"cluster": c})
+	if err != nil {
+		return false, err
+	}
+	return out == types.True, nil
+}
+
+// Explain evaluates the expression for c without short-circuiting and
+// reports the value of each top-level clause and every cluster field read.
+func (e *Expression) Explain(c Cluster) Explanation {
+	exp := Explanation{Cluster: c.Name}
+	out, details, err := e.explainer.Eval(map[string]interface{}{"cluster": c})
+	if err != nil {
+		exp.Error = err.Error()
+	} else {
+		exp.Matched = out == types.True
+	}
+	if details == nil {
+		return exp
+	}
+	state := details.State()
+	native := e.checked.NativeRep()
+
+	for _, clause := range topLevelClauses(native.Expr()) {
+		text, unparseErr := parser.Unparse(clause, native.SourceInfo())
+		if unparseErr != nil {
+			continue
+		}
+		value := "not evaluated"
+		if v, ok := state.Value(clause.ID()); ok {
+			value = formatValue(v)
+		}
+		exp.Clauses = append(exp.Clauses, Clause{Expression: text, Value: value})
+	}
+
+	for field, ids := range referencedFields(native.Expr()) {
+		for _, id := range ids {
+			if v, ok := state.Value(id); ok {
+				if exp.Inputs == nil {
+					exp.Inputs = make(map[string]string)
+				}
+				exp.Inputs["cluster."+field] = formatValue(v)
+				break
+			}
+		}
+	}
+	return exp
+}
+
+// topLevelClauses flattens the outermost && or || chain. An expression
+// without one is a single clause.
+func topLevelClauses(root ast.Expr) []ast.Expr {
+	if root.Kind() != ast.CallKind {
+		return []ast.Expr{root}
+	}
+	op := root.AsCall().FunctionName()
+	if op != operators.LogicalAnd && op != operators.LogicalOr {
+		return []ast.Expr{root}
+	}
+	var out []ast.Expr
+	var walk func(ast.Expr)
+	walk = func(e ast.Expr) {
+		if e.Kind() == ast.CallKind && e.AsCall().FunctionName() == op {
+			for _, arg := range e.AsCall().Args() {
+				walk(arg)
+			}
+			return
+		}
+		out = append(out, e)
+	}
+	walk(root)
+	return out
+}
+
+// referencedFields returns the cluster fields selected directly off the
+// cluster variable, with the expression IDs of each selection sorted so
+// Explain picks a deterministic one.
+func referencedFields(root ast.Expr) map[string][]int64 {
+	fields := make(map[string][]int64)
+	ast.PreOrderVisit(root, ast.NewExprVisitor(func(e ast.Expr) {
+		if e.Kind() != ast.SelectKind {
+			return
+		}
+		sel := e.AsSelect()
+		if sel.Operand().Kind() == ast.IdentKind && sel.Operand().AsIdent() == "cluster" {
+			fields[sel.FieldName()] = append(fields[sel.FieldName()], e.ID())
+		}
+	}))
+	for _, ids := range fields {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return fields
+}
+
+func formatValue(v ref.Val) string {
+	if types.IsError(v) {
+		return fmt.Sprintf("error: %v", v)
+	}
+	if s, ok := v.Value().(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v.Value())
+}
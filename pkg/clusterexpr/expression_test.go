@@ -0,0 +1,109 @@
+package clusterexpr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func prodGPUCluster() Cluster {
+	return Cluster{
+		Name:     "gpu-east",
+		Healthy:  true,
+		GPUCount: 16,
+		GPUTypes: []string{"H100"},
+		Labels:   map[string]string{"tier": "prod"},
+		Nodes: []Node{
+			{Name: "n1", GPUCount: 8, GPUType: "H100", Labels: map[string]string{"tier": "prod"}},
+			{Name: "n2", GPUCount: 8, GPUType: "H100", Labels: map[string]string{"tier": "prod"}},
+		},
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for name, expr := range map[string]string{
+		"syntax":        `cluster.gpuCount >=`,
+		"unknown field": `cluster.gpuCnt >= 8`,
+		"not bool":      `cluster.gpuCount + 1`,
+		"unknown var":   `node.name == "a"`,
+		"too long":      `cluster.name == "` + strings.Repeat("a", MaxExpressionLength) + `"`,
+	} {
+		if _, err := Compile(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("%s: expected ErrInvalidExpression, got %v", name, err)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	expr, err := Compile(`cluster.labels["tier"] == "prod" && cluster.gpuCount >= 8`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if !expr.NeedsNodes() {
+		t.Error("labels and gpuCount are node-derived")
+	}
+	c := prodGPUCluster()
+	if ok, err := expr.Matches(c); err != nil || !ok {
+		t.Errorf("expected match, got %v, %v", ok, err)
+	}
+	c.GPUCount = 4
+	if ok, _ := expr.Matches(c); ok {
+		t.Error("expected no match with 4 GPUs")
+	}
+	// A missing label is an evaluation error, which callers treat as a miss.
+	c.GPUCount = 16
+	c.Labels = map[string]string{}
+	if ok, err := expr.Matches(c); ok || err == nil {
+		t.Errorf("expected error for missing label, got %v, %v", ok, err)
+	}
+}
+
+func TestMatches_NodesMacro(t *testing.T) {
+	expr, err := Compile(`cluster.healthy && cluster.nodes.exists(n, n.gpuType == "H100")`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if ok, err := expr.Matches(prodGPUCluster()); err != nil || !ok {
+		t.Errorf("expected match, got %v, %v", ok, err)
+	}
+}
+
+func TestNeedsNodes_HealthOnly(t *testing.T) {
+	expr, err := Compile(`cluster.healthy && cluster.cpuCores > 4`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if expr.NeedsNodes() {
+		t.Error("health-only expression should not need nodes")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	expr, err := Compile(`cluster.labels["tier"] == "prod" && cluster.gpuCount >= 8 && cluster.name.startsWith("gpu-")`)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	c := prodGPUCluster()
+	c.GPUCount = 4
+
+	exp := expr.Explain(c)
+	if exp.Matched || exp.Cluster != "gpu-east" || exp.Error != "" {
+		t.Fatalf("unexpected explanation: %+v", exp)
+	}
+	if len(exp.Clauses) != 3 {
+		t.Fatalf("expected 3 clauses, got %+v", exp.Clauses)
+	}
+	want := []Clause{
+		{Expression: `cluster.labels["tier"] == "prod"`, Value: "true"},
+		{Expression: `cluster.gpuCount >= 8`, Value: "false"},
+		{Expression: `cluster.name.startsWith("gpu-")`, Value: "true"},
+	}
+	for i, w := range want {
+		if exp.Clauses[i] != w {
+			t.Errorf("clause %d: want %+v, got %+v", i, w, exp.Clauses[i])
+		}
+	}
+	if exp.Inputs["cluster.gpuCount"] != "4" || exp.Inputs["cluster.name"] != `"gpu-east"` {
+		t.Errorf("unexpected inputs: %v", exp.Inputs)
+	}
+}
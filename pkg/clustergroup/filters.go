@@ -0,0 +1,105 @@
+package clustergroup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// filterKinds maps each supported filter field to the kind of value
+// it compares, which decides the operators it accepts.
+var filterKinds = map[string]string{
+	"name":      "string",
+	"healthy":   "bool",
+	"reachable": "bool",
+	"nodeCount": "int",
+	"podCount":  "int",
+	"cpuCores":  "int",
+	"cpuCount":  "int",
+	"memoryGB":  "float",
+	"gpuCount":  "int",
+	"gpuType":   "string",
+	"region":    "string",
+	"zone":      "string",
+	"provider":  "string",
+	"version":   "version",
+	"label":     "string",
+}
+
+// ValidateFilters reports the first dynamic filter that can never match as
+// written: an unknown field or operator, or a value of the wrong type.
+func ValidateFilters(filters []v1alpha1.ClusterFilter) error {
+	for i, f := range filters {
+		kind, ok := filterKinds[f.Field]
+		if !ok {
+			return fmt.Errorf("dynamicFilters[%d]: unknown field %q", i, f.Field)
+		}
+		if f.Field == "label" && f.LabelKey == "" {
+			return fmt.Errorf("dynamicFilters[%d]: labelKey is required for the label field", i)
+		}
+		var err error
+		switch kind {
+		case "string":
+			err = validateStringOperator(f.Operator, f.Value)
+		case "bool":
+			if f.Operator != "eq" && f.Operator != "neq" {
+				err = fmt.Errorf("operator %q is not supported, use eq or neq", f.Operator)
+			}
+		case "int":
+			if !isOrderOperator(f.Operator) {
+				err = errOrderOperator(f.Operator)
+			} else if _, perr := strconv.ParseInt(f.Value, 10, 64); perr != nil {
+				err = fmt.Errorf("value %q is not a whole number", f.Value)
+			}
+		case "float":
+			if !isOrderOperator(f.Operator) {
+				err = errOrderOperator(f.Operator)
+			} else if _, perr := strconv.ParseFloat(f.Value, 64); perr != nil {
+				err = fmt.Errorf("value %q is not a number", f.Value)
+			}
+		case "version":
+			if isOrderOperator(f.Operator) {
+				if _, perr := k8s.CompareVersionPrefix(f.Value, f.Value); perr != nil {
+					err = fmt.Errorf("value %q is not a version such as 1.29", f.Value)
+				}
+			} else if f.Operator == "contains" || f.Operator == "regex" {
+				err = validateStringOperator(f.Operator, f.Value)
+			} else {
+				err = fmt.Errorf("operator %q is not supported, use eq, neq, gt, gte, lt, lte, contains or regex", f.Operator)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("dynamicFilters[%d] (%s): %w", i, f.Field, err)
+		}
+	}
+	return nil
+}
+
+func validateStringOperator(operator, value string) error {
+	switch operator {
+	case "eq", "neq", "contains":
+		return nil
+	case "regex":
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("operator %q is not supported, use eq, neq, contains or regex", operator)
+	}
+}
+
+func errOrderOperator(op string) error {
+	return fmt.Errorf("operator %q is not supported, use eq, neq, gt, gte, lt or lte", op)
+}
+
+func isOrderOperator(op string) bool {
+	switch op {
+	case "eq", "neq", "gt", "gte", "lt", "lte":
+		return true
+	}
+	return false
+}
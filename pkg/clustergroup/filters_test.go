@@ -0,0 +1,58 @@
+package clustergroup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+func TestValidateFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  v1alpha1.ClusterFilter
+		wantErr string
+	}{
+		{"numeric", v1alpha1.ClusterFilter{Field: "gpuCount", Operator: "gte", Value: "4"}, ""},
+		{"float", v1alpha1.ClusterFilter{Field: "memoryGB", Operator: "lt", Value: "64.5"}, ""},
+		{"cpu count alias", v1alpha1.ClusterFilter{Field: "cpuCount", Operator: "gt", Value: "8"}, ""},
+		{"regex", v1alpha1.ClusterFilter{Field: "region", Operator: "regex", Value: "^eu-"}, ""},
+		{"version", v1alpha1.ClusterFilter{Field: "version", Operator: "gte", Value: "1.29"}, ""},
+		{"version contains", v1alpha1.ClusterFilter{Field: "version", Operator: "contains", Value: "eks"}, ""},
+		{"label", v1alpha1.ClusterFilter{Field: "label", LabelKey: "env", Operator: "eq", Value: "prod"}, ""},
+		{"unknown field", v1alpha1.ClusterFilter{Field: "color", Operator: "eq", Value: "red"}, "unknown field"},
+		{"string order operator", v1alpha1.ClusterFilter{Field: "zone", Operator: "gt", Value: "a"}, "not supported"},
+		{"bad regex", v1alpha1.ClusterFilter{Field: "name", Operator: "regex", Value: "("}, "invalid regex"},
+		{"bad int", v1alpha1.ClusterFilter{Field: "nodeCount", Operator: "gt", Value: "2.5"}, "whole number"},
+		{"bad float", v1alpha1.ClusterFilter{Field: "memoryGB", Operator: "gt", Value: "lots"}, "not a number"},
+		{"bad version", v1alpha1.ClusterFilter{Field: "version", Operator: "gt", Value: "latest"}, "not a version"},
+		{"label without key", v1alpha1.ClusterFilter{Field: "label", Operator: "eq", Value: "prod"}, "labelKey"},
+		{"bool operator", v1alpha1.ClusterFilter{Field: "healthy", Operator: "gt", Value: "true"}, "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFilters([]v1alpha1.ClusterFilter{tt.filter})
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("expected an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateFilters_ReportsIndex(t *testing.T) {
+	err := ValidateFilters([]v1alpha1.ClusterFilter{
+		{Field: "healthy", Operator: "eq", Value: "true"},
+		{Field: "gpuCount", Operator: "gte", Value: "many"},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "dynamicFilters[1] (gpuCount)") {
+		t.Fatalf("got %v, want an error for dynamicFilters[1]", err)
+	}
+	if err := ValidateFilters(nil); err != nil {
+		t.Fatalf("no filters: %v", err)
+	}
+}
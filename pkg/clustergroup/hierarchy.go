@@ -0,0 +1,315 @@
+// Package clustergroup resolves ClusterGroup hierarchies: groups that include
+// other groups through spec.memberGroups, and the settings member groups
+// inherit from the groups that include them. It also validates the dynamic
+// filters of a group.
+package clustergroup
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/deploywindow"
+)
+
+// ErrCycle is returned when memberGroups would make a group a member of
+// itself.
+var ErrCycle = errors.New("cluster group cycle")
+
+// Hierarchy indexes the memberGroups edges between the ClusterGroups of one
+// namespace. Member groups that do not exist are ignored.
+type Hierarchy struct {
+	groups map[string]*v1alpha1.ClusterGroup
+	// parents maps a group to the groups that list it in memberGroups.
+	parents map[string][]string
+}
+
+// New builds the hierarchy of groups.
+func New(groups []v1alpha1.ClusterGroup) *Hierarchy {
+	h := &Hierarchy{
+		groups:  make(map[string]*v1alpha1.ClusterGroup, len(groups)),
+		parents: make(map[string][]string),
+	}
+	for i := range groups {
+		h.groups[groups[i].Name] = &groups[i]
+	}
+	for _, g := range h.groups {
+		for _, child := range g.Spec.MemberGroups {
+			h.parents[child] = append(h.parents[child], g.Name)
+		}
+	}
+	for child := range h.parents {
+		sort.Strings(h.parents[child])
+	}
+	return h
+}
+
+// Group returns the named group, or nil.
+func (h *Hierarchy) Group(name string) *v1alpha1.ClusterGroup {
+	return h.groups[name]
+}
+
+// Names returns every group name, sorted.
+func (h *Hierarchy) Names() []string {
+	out := make([]string, 0, len(h.groups))
+	for name := range h.groups {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CheckCycle reports ErrCycle when saving updated, replacing any group of the
+// same name in groups, would create a memberGroups cycle.
+func CheckCycle(groups []v1alpha1.ClusterGroup, updated v1alpha1.ClusterGroup) error {
+	next := make([]v1alpha1.ClusterGroup, 0, len(groups)+1)
+	for _, g := range groups {
+		if g.Name != updated.Name {
+			next = append(next, g)
+		}
+	}
+	next = append(next, updated)
+	// Any new cycle runs through updated's own edges, so it is reachable
+	// from updated.
+	if path := New(next).FindCycle(updated.Name); path != nil {
+		return fmt.Errorf("%w: %s", ErrCycle, strings.Join(path, " -> "))
+	}
+	return nil
+}
+
+// FindCycle returns a memberGroups cycle reachable from name as a path that
+// starts and ends with the same group, or nil when there is none.
+func (h *Hierarchy) FindCycle(name string) []string {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(string) []string
+	visit = func(g string) []string {
+		switch state[g] {
+		case visiting:
+			for i, p := range path {
+				if p == g {
+					return append(append([]string{}, path[i:]...), g)
+				}
+			}
+		case done:
+			return nil
+		}
+		group := h.groups[g]
+		if group == nil {
+			return nil
+		}
+		state[g] = visiting
+		path = append(path, g)
+		for _, child := range group.Spec.MemberGroups {
+			if cycle := visit(child); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[g] = done
+		return nil
+	}
+	return visit(name)
+}
+
+// Expand returns roots and every group they include, transitively, sorted.
+// Cycles are cut rather than followed.
+func (h *Hierarchy) Expand(roots []string) []string {
+	seen := make(map[string]bool)
+	queue := append([]string{}, roots...)
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		if seen[g] || h.groups[g] == nil {
+			continue
+		}
+		seen[g] = true
+		queue = append(queue, h.groups[g].Spec.MemberGroups...)
+	}
+	out := make([]string, 0, len(seen))
+	for g := range seen {
+		out = append(out, g)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Descendants returns the groups name includes, transitively, excluding
+// name itself.
+func (h *Hierarchy) Descendants(name string) []string {
+	group := h.groups[name]
+	if group == nil {
+		return nil
+	}
+	out := h.Expand(group.Spec.MemberGroups)
+	for i, g := range out {
+		if g == name {
+			return append(out[:i], out[i+1:]...)
+		}
+	}
+	return out
+}
+
+// Ancestors returns the groups that include name, transitively, nearest
+// first.
+func (h *Hierarchy) Ancestors(name string) []string {
+	seen := map[string]bool{name: true}
+	var out []string
+	queue := append([]string{}, h.parents[name]...)
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		if seen[g] {
+			continue
+		}
+		seen[g] = true
+		out = append(out, g)
+		queue = append(queue, h.parents[g]...)
+	}
+	return out
+}
+
+// Effective is a group's settings after inheritance.
+type Effective struct {
+	Settings v1alpha1.ClusterGroupSettings `json:"settings"`
+	// FrozenBy is the group whose freeze applies, when Settings.Frozen is
+	// true.
+	FrozenBy string `json:"frozenBy,omitempty"`
+	// Ancestors are the groups settings were inherited from, nearest first.
+	Ancestors []string `json:"ancestors,omitempty"`
+}
+
+// IsFrozen reports whether rollouts to the group's clusters are stopped.
+func (e Effective) IsFrozen() bool {
+	return e.Settings.Frozen != nil && *e.Settings.Frozen
+}
+
+// Effective merges name's own settings with those it inherits. Maintenance
+// windows and notification routes accumulate down the hierarchy. A group's
+// own frozen value wins; otherwise it is frozen when any group including it
+// is. A group's own retry policy wins over the nearest inherited one.
+func (h *Hierarchy) Effective(name string) Effective {
+	memo := make(map[string]*Effective)
+	eff := h.effective(name, memo, map[string]bool{})
+	eff.Ancestors = h.Ancestors(name)
+	return eff
+}
+
+func (h *Hierarchy) effective(name string, memo map[string]*Effective, visiting map[string]bool) Effective {
+	if e, ok := memo[name]; ok {
+		return *e
+	}
+	group := h.groups[name]
+	if group == nil || visiting[name] {
+		return Effective{}
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var own v1alpha1.ClusterGroupSettings
+	if group.Spec.Settings != nil {
+		own = *group.Spec.Settings
+	}
+	eff := Effective{Settings: v1alpha1.ClusterGroupSettings{
+		MaintenanceWindows: append([]v1alpha1.MaintenanceWindow{}, own.MaintenanceWindows...),
+		NotificationRoutes: append([]v1alpha1.NotificationRoute{}, own.NotificationRoutes...),
+	}}
+	if own.RetryPolicy != nil {
+		policy := *own.RetryPolicy
+		eff.Settings.RetryPolicy = &policy
+	}
+	if own.Frozen != nil {
+		frozen := *own.Frozen
+		eff.Settings.Frozen = &frozen
+		if frozen {
+			eff.Settings.FreezeReason = own.FreezeReason
+			eff.FrozenBy = name
+		}
+	}
+
+	for _, parent := range h.parents[name] {
+		inherited := h.effective(parent, memo, visiting)
+		eff.Settings.MaintenanceWindows = appendWindows(eff.Settings.MaintenanceWindows, inherited.Settings.MaintenanceWindows)
+		eff.Settings.NotificationRoutes = appendRoutes(eff.Settings.NotificationRoutes, inherited.Settings.NotificationRoutes)
+		if eff.Settings.RetryPolicy == nil {
+			eff.Settings.RetryPolicy = inherited.Settings.RetryPolicy
+		}
+		if own.Frozen == nil && inherited.IsFrozen() && eff.FrozenBy == "" {
+			frozen := true
+			eff.Settings.Frozen = &frozen
+			eff.Settings.FreezeReason = inherited.Settings.FreezeReason
+			eff.FrozenBy = inherited.FrozenBy
+		}
+	}
+	memo[name] = &eff
+	return eff
+}
+
+func appendWindows(dst, src []v1alpha1.MaintenanceWindow) []v1alpha1.MaintenanceWindow {
+	for _, w := range src {
+		dup := false
+		for _, d := range dst {
+			if d.Start == w.Start && d.End == w.End && d.Timezone == w.Timezone &&
+				strings.Join(d.Days, ",") == strings.Join(w.Days, ",") {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, w)
+		}
+	}
+	return dst
+}
+
+func appendRoutes(dst, src []v1alpha1.NotificationRoute) []v1alpha1.NotificationRoute {
+	for _, r := range src {
+		dup := false
+		for _, d := range dst {
+			if d == r {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, r)
+		}
+	}
+	return dst
+}
+
+// notificationRouteTypes are the channel types a NotificationRoute may name.
+var notificationRouteTypes = map[string]bool{
+	"slack": true, "email": true, "webhook": true, "pagerduty": true, "opsgenie": true,
+}
+
+// ValidateSettings rejects maintenance windows that do not parse,
+// notification routes to unknown channel types and invalid retry policies.
+func ValidateSettings(s *v1alpha1.ClusterGroupSettings) error {
+	if s == nil {
+		return nil
+	}
+	for i, w := range s.MaintenanceWindows {
+		if _, err := deploywindow.Parse(w.Start, w.End, w.Days, w.Timezone); err != nil {
+			return fmt.Errorf("maintenanceWindows[%d]: %w", i, err)
+		}
+	}
+	for i, r := range s.NotificationRoutes {
+		if !notificationRouteTypes[r.Type] {
+			return fmt.Errorf("notificationRoutes[%d]: unknown type %q", i, r.Type)
+		}
+		if strings.TrimSpace(r.Target) == "" {
+			return fmt.Errorf("notificationRoutes[%d]: target is required", i)
+		}
+	}
+	if s.RetryPolicy != nil {
+		return s.RetryPolicy.Validate()
+	}
+	return nil
+}
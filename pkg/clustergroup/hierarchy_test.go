@@ -0,0 +1,135 @@
+package clustergroup
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func group(name string, settings *v1alpha1.ClusterGroupSettings, members ...string) v1alpha1.ClusterGroup {
+	return v1alpha1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.ClusterGroupSpec{MemberGroups: members, Settings: settings},
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// region -> env-prod -> team-a, region -> env-dev
+func orgGroups() []v1alpha1.ClusterGroup {
+	return []v1alpha1.ClusterGroup{
+		group("region-emea", &v1alpha1.ClusterGroupSettings{
+			MaintenanceWindows: []v1alpha1.MaintenanceWindow{{Start: "01:00", End: "03:00"}},
+			NotificationRoutes: []v1alpha1.NotificationRoute{{Type: "slack", Target: "#emea-ops"}},
+		}, "env-prod", "env-dev"),
+		group("env-prod", &v1alpha1.ClusterGroupSettings{Frozen: boolPtr(true), FreezeReason: "year-end freeze"}, "team-a"),
+		group("env-dev", nil),
+		group("team-a", &v1alpha1.ClusterGroupSettings{
+			NotificationRoutes: []v1alpha1.NotificationRoute{{Type: "email", Target: "team-a@example.com"}, {Type: "slack", Target: "#emea-ops"}},
+		}),
+	}
+}
+
+func TestCheckCycle(t *testing.T) {
+	groups := orgGroups()
+	if err := CheckCycle(groups, group("team-a", nil, "env-dev")); err != nil {
+		t.Errorf("sharing a member group is not a cycle: %v", err)
+	}
+	err := CheckCycle(groups, group("team-a", nil, "region-emea"))
+	if !errors.Is(err, ErrCycle) {
+		t.Fatalf("err = %v, want ErrCycle", err)
+	}
+	if want := "cluster group cycle: team-a -> region-emea -> env-prod -> team-a"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+	if err := CheckCycle(groups, group("env-dev", nil, "env-dev")); !errors.Is(err, ErrCycle) {
+		t.Errorf("self membership: err = %v, want ErrCycle", err)
+	}
+}
+
+func TestDescendantsAndAncestors(t *testing.T) {
+	h := New(orgGroups())
+	if got, want := h.Descendants("region-emea"), []string{"env-dev", "env-prod", "team-a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants = %v, want %v", got, want)
+	}
+	if got, want := h.Ancestors("team-a"), []string{"env-prod", "region-emea"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ancestors = %v, want %v", got, want)
+	}
+
+	// A cycle written outside the console is cut, not followed forever.
+	cyclic := New([]v1alpha1.ClusterGroup{group("a", nil, "b"), group("b", nil, "a")})
+	if got := cyclic.Descendants("a"); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("Descendants with cycle = %v, want [b]", got)
+	}
+}
+
+func TestEffective_Inherits(t *testing.T) {
+	h := New(orgGroups())
+
+	team := h.Effective("team-a")
+	if !team.IsFrozen() || team.FrozenBy != "env-prod" || team.Settings.FreezeReason != "year-end freeze" {
+		t.Errorf("team-a should inherit env-prod's freeze, got %+v", team)
+	}
+	if len(team.Settings.MaintenanceWindows) != 1 {
+		t.Errorf("team-a should inherit the region's maintenance window, got %v", team.Settings.MaintenanceWindows)
+	}
+	wantRoutes := []v1alpha1.NotificationRoute{
+		{Type: "email", Target: "team-a@example.com"},
+		{Type: "slack", Target: "#emea-ops"},
+	}
+	if !reflect.DeepEqual(team.Settings.NotificationRoutes, wantRoutes) {
+		t.Errorf("routes = %v, want own routes plus deduplicated inherited ones %v", team.Settings.NotificationRoutes, wantRoutes)
+	}
+	if want := []string{"env-prod", "region-emea"}; !reflect.DeepEqual(team.Ancestors, want) {
+		t.Errorf("Ancestors = %v, want %v", team.Ancestors, want)
+	}
+
+	if h.Effective("env-dev").IsFrozen() {
+		t.Error("a sibling of a frozen group is not frozen")
+	}
+}
+
+func TestEffective_OwnFreezeOverrides(t *testing.T) {
+	groups := orgGroups()
+	groups[3].Spec.Settings.Frozen = boolPtr(false)
+	if New(groups).Effective("team-a").IsFrozen() {
+		t.Error("frozen: false on the group overrides an inherited freeze")
+	}
+}
+
+func TestEffective_RetryPolicy(t *testing.T) {
+	groups := orgGroups()
+	groups[0].Spec.Settings.RetryPolicy = &v1alpha1.RetryPolicy{MaxAttempts: 3}
+	if got := New(groups).Effective("team-a").Settings.RetryPolicy; got == nil || got.MaxAttempts != 3 {
+		t.Errorf("team-a should inherit the region's retry policy, got %+v", got)
+	}
+
+	groups[1].Spec.Settings.RetryPolicy = &v1alpha1.RetryPolicy{MaxAttempts: 5, OnFailure: v1alpha1.RetryWarn}
+	h := New(groups)
+	if got := h.Effective("team-a").Settings.RetryPolicy; got == nil || got.MaxAttempts != 5 {
+		t.Errorf("the nearest group's retry policy wins, got %+v", got)
+	}
+	if got := h.Effective("region-emea").Settings.RetryPolicy; got == nil || got.MaxAttempts != 3 {
+		t.Errorf("a group's own retry policy is kept, got %+v", got)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	for name, s := range map[string]*v1alpha1.ClusterGroupSettings{
+		"bad window":   {MaintenanceWindows: []v1alpha1.MaintenanceWindow{{Start: "1am", End: "03:00"}}},
+		"bad route":    {NotificationRoutes: []v1alpha1.NotificationRoute{{Type: "pager", Target: "x"}}},
+		"no target":    {NotificationRoutes: []v1alpha1.NotificationRoute{{Type: "slack"}}},
+		"bad timezone": {MaintenanceWindows: []v1alpha1.MaintenanceWindow{{Start: "01:00", End: "03:00", Timezone: "Mars/Olympus"}}},
+		"bad retry":    {RetryPolicy: &v1alpha1.RetryPolicy{OnFailure: "Ignore"}},
+	} {
+		if err := ValidateSettings(s); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := ValidateSettings(orgGroups()[0].Spec.Settings); err != nil {
+		t.Errorf("valid settings rejected: %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package manifestpolicy
+
+// Custom CEL and Rego policies. A policy file selects built-in policies and
+// defines custom ones; LoadEngine compiles every definition up front so a
+// broken expression is reported at startup rather than on the first rollout.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// celCostLimit bounds the work a single CEL evaluation may do.
+	celCostLimit = 1_000_000
+	// regoEvalTimeout bounds a single Rego query.
+	regoEvalTimeout = 5 * time.Second
+)
+
+// ErrInvalidPolicy is returned when a policy file or definition is malformed
+// or fails to compile.
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+var policyIDPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// LoadEngine reads a YAML policy file and returns an engine with the selected
+// built-in policies and the compiled custom policies.
+func LoadEngine(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy file: %w", err)
+	}
+	var file PolicyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: parse %s: %v", ErrInvalidPolicy, path, err)
+	}
+	return NewCustomEngine(file)
+}
+
+// NewCustomEngine compiles a policy file into an engine. Unlike NewEngine,
+// built-in policies are only included when listed in file.Builtins.
+func NewCustomEngine(file PolicyFile) (*Engine, error) {
+	builtins := make(map[string]rule)
+	for _, r := range builtinRules() {
+		builtins[r.policy.ID] = r
+	}
+
+	e := &Engine{}
+	seen := make(map[string]bool)
+	for _, id := range file.Builtins {
+		r, ok := builtins[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPolicy, id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		e.rules = append(e.rules, r)
+	}
+	for _, def := range file.Policies {
+		if _, ok := builtins[def.ID]; ok || seen[def.ID] {
+			return nil, fmt.Errorf("%w: duplicate policy id %q", ErrInvalidPolicy, def.ID)
+		}
+		r, err := compileDefinition(def)
+		if err != nil {
+			return nil, err
+		}
+		seen[def.ID] = true
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+func compileDefinition(def Definition) (rule, error) {
+	if !policyIDPattern.MatchString(def.ID) {
+		return rule{}, fmt.Errorf("%w: id %q must be a lowercase DNS label", ErrInvalidPolicy, def.ID)
+	}
+	switch def.Severity {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+	default:
+		return rule{}, fmt.Errorf("%w: %s: unknown severity %q", ErrInvalidPolicy, def.ID, def.Severity)
+	}
+	action := def.Action
+	switch action {
+	case ActionEnforce, ActionAudit:
+	case "":
+		action = ActionAudit
+		if def.Severity == SeverityCritical || def.Severity == SeverityHigh {
+			action = ActionEnforce
+		}
+	default:
+		return rule{}, fmt.Errorf("%w: %s: unknown action %q", ErrInvalidPolicy, def.ID, def.Action)
+	}
+
+	name := def.Name
+	if name == "" {
+		name = def.ID
+	}
+	r := rule{policy: Policy{
+		ID:          def.ID,
+		Name:        name,
+		Description: def.Description,
+		Severity:    def.Severity,
+		Action:      action,
+	}}
+
+	var check func(obj Object, env Environment) []finding
+	var err error
+	switch {
+	case def.CEL != "" && def.Rego != "":
+		return rule{}, fmt.Errorf("%w: %s: set only one of cel or rego", ErrInvalidPolicy, def.ID)
+	case def.CEL != "":
+		check, err = compileCEL(def)
+	case def.Rego != "":
+		check, err = compileRego(def)
+	default:
+		return rule{}, fmt.Errorf("%w: %s: one of cel or rego is required", ErrInvalidPolicy, def.ID)
+	}
+	if err != nil {
+		return rule{}, err
+	}
+	r.check = matchKinds(def.Kinds, check)
+	return r, nil
+}
+
+// matchKinds wraps check so it only runs for the listed kinds.
+func matchKinds(kinds []string, check func(Object, Environment) []finding) func(Object, Environment) []finding {
+	if len(kinds) == 0 {
+		return check
+	}
+	allowed := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		allowed[k] = true
+	}
+	return func(obj Object, env Environment) []finding {
+		if kind, _ := obj.Content["kind"].(string); !allowed[kind] {
+			return nil
+		}
+		return check(obj, env)
+	}
+}
+
+// policyInput is what custom policies see: the object plus its environment.
+func policyInput(obj Object, env Environment) map[string]interface{} {
+	return map[string]interface{}{
+		"object":          obj.Content,
+		"cluster":         map[string]interface{}{"name": env.Cluster},
+		"vulnerabilities": vulnerabilityInput(obj, env),
+	}
+}
+
+func compileCEL(def Definition) (func(Object, Environment) []finding, error) {
+	celEnv, err := cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("cluster", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("vulnerabilities", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, def.ID, err)
+	}
+	checked, issues := celEnv.Compile(def.CEL)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, def.ID, issues.Err())
+	}
+	if out := checked.OutputType(); out != cel.BoolType && out != cel.DynType {
+		return nil, fmt.Errorf("%w: %s: expression must return bool, got %s", ErrInvalidPolicy, def.ID, out)
+	}
+	program, err := celEnv.Program(checked, cel.CostLimit(celCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, def.ID, err)
+	}
+
+	message := def.Message
+	if message == "" {
+		message = fmt.Sprintf("failed expression: %s", def.CEL)
+	}
+	return func(obj Object, env Environment) []finding {
+		out, _, err := program.Eval(policyInput(obj, env))
+		if err != nil {
+			// Fail closed: an expression that cannot be evaluated (e.g. a
+			// missing key) counts as a violation.
+			return []finding{{message: fmt.Sprintf("policy evaluation failed: %v", err)}}
+		}
+		if out == types.True {
+			return nil
+		}
+		if _, isBool := out.Value().(bool); !isBool {
+			return []finding{{message: fmt.Sprintf("policy evaluation failed: expression returned %s", out.Type().TypeName())}}
+		}
+		return []finding{{message: message}}
+	}, nil
+}
+
+func compileRego(def Definition) (func(Object, Environment) []finding, error) {
+	module, err := ast.ParseModuleWithOpts(def.ID+".rego", def.Rego, ast.ParserOptions{RegoVersion: ast.RegoV1})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, def.ID, err)
+	}
+	query, err := rego.New(
+		rego.Query(module.Package.Path.String()+".deny"),
+		rego.ParsedModule(module),
+	).PrepareForEval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, def.ID, err)
+	}
+
+	return func(obj Object, env Environment) []finding {
+		ctx, cancel := context.WithTimeout(context.Background(), regoEvalTimeout)
+		defer cancel()
+		results, err := query.Eval(ctx, rego.EvalInput(policyInput(obj, env)))
+		if err != nil {
+			return []finding{{message: fmt.Sprintf("policy evaluation failed: %v", err)}}
+		}
+		var out []finding
+		for _, result := range results {
+			for _, expr := range result.Expressions {
+				entries, _ := expr.Value.([]interface{})
+				for _, entry := range entries {
+					out = append(out, regoFinding(entry))
+				}
+			}
+		}
+		return out
+	}, nil
+}
+
+// regoFinding converts one deny entry: a message string, or an object with
+// msg and optional field.
+func regoFinding(entry interface{}) finding {
+	switch v := entry.(type) {
+	case string:
+		return finding{message: v}
+	case map[string]interface{}:
+		msg, _ := v["msg"].(string)
+		field, _ := v["field"].(string)
+		if msg == "" {
+			msg = fmt.Sprintf("%v", v)
+		}
+		return finding{field: field, message: msg}
+	default:
+		return finding{message: fmt.Sprintf("%v", v)}
+	}
+}
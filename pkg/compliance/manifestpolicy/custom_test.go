@@ -0,0 +1,139 @@
+package manifestpolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPolicyFile = `
+builtins: [disallow-privileged]
+policies:
+- id: require-team-label
+  severity: high
+  kinds: [Deployment]
+  message: workloads must carry a team label
+  cel: "has(object.metadata.labels) && 'team' in object.metadata.labels"
+- id: prod-single-replica
+  severity: medium
+  rego: |
+    package console.prodreplicas
+
+    deny contains {"msg": "production needs at least 2 replicas", "field": "spec.replicas"} if {
+      input.cluster.name == "prod"
+      input.object.spec.replicas < 2
+    }
+`
+
+func loadTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, []byte(testPolicyFile), 0o600); err != nil {
+		t.Fatalf("write policy file: %v", err)
+	}
+	e, err := LoadEngine(path)
+	if err != nil {
+		t.Fatalf("LoadEngine: %v", err)
+	}
+	return e
+}
+
+func TestLoadEngine_Policies(t *testing.T) {
+	e := loadTestEngine(t)
+	actions := map[string]Action{}
+	for _, p := range e.Policies() {
+		actions[p.ID] = p.Action
+	}
+	want := map[string]Action{
+		PolicyDisallowPrivileged: ActionEnforce,
+		"require-team-label":     ActionEnforce, // high severity defaults to enforce
+		"prod-single-replica":    ActionAudit,   // medium severity defaults to audit
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("expected %d policies, got %v", len(want), actions)
+	}
+	for id, action := range want {
+		if actions[id] != action {
+			t.Errorf("policy %s: expected action %s, got %s", id, action, actions[id])
+		}
+	}
+}
+
+func TestCustomPolicies_EvaluatePerCluster(t *testing.T) {
+	e := loadTestEngine(t)
+	obj := deployment(limitedContainer("app", "nginx:1.27"))
+	obj.Content["spec"].(map[string]interface{})["replicas"] = int64(1)
+
+	report, err := e.Evaluate([]Object{obj}, nil, Environment{Cluster: "dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Denied || len(report.Violations) != 1 || report.Violations[0].PolicyID != "require-team-label" {
+		t.Fatalf("expected only the CEL label policy to deny on dev, got %+v", report)
+	}
+	if report.Violations[0].Message != "workloads must carry a team label" {
+		t.Errorf("unexpected message: %q", report.Violations[0].Message)
+	}
+
+	obj.Content["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"team": "web"}
+	report, err = e.Evaluate([]Object{obj}, nil, Environment{Cluster: "prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Denied {
+		t.Error("audit-mode Rego violation must not deny")
+	}
+	if len(report.Violations) != 1 || report.Violations[0].PolicyID != "prod-single-replica" || report.Violations[0].Field != "spec.replicas" {
+		t.Errorf("expected the Rego replica warning on prod, got %+v", report.Violations)
+	}
+}
+
+func TestCustomPolicies_KindsFilter(t *testing.T) {
+	e := loadTestEngine(t)
+	cm := Object{Content: map[string]interface{}{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "cfg"}}}
+	report, err := e.Evaluate([]Object{cm}, []string{"require-team-label"}, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Errorf("expected kinds filter to skip the ConfigMap, got %+v", report.Violations)
+	}
+}
+
+func TestCustomPolicies_EvaluationErrorFailsClosed(t *testing.T) {
+	e, err := NewCustomEngine(PolicyFile{Policies: []Definition{{
+		ID:       "needs-spec",
+		Severity: SeverityCritical,
+		CEL:      "object.spec.replicas > 0",
+	}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, err := e.Evaluate([]Object{{Content: map[string]interface{}{"kind": "ConfigMap"}}}, nil, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Denied || len(report.Violations) != 1 {
+		t.Errorf("expected an evaluation error to count as a violation, got %+v", report)
+	}
+}
+
+func TestNewCustomEngine_Invalid(t *testing.T) {
+	cases := map[string]PolicyFile{
+		"unknown builtin":   {Builtins: []string{"nope"}},
+		"bad id":            {Policies: []Definition{{ID: "Bad_ID", Severity: SeverityLow, CEL: "true"}}},
+		"bad severity":      {Policies: []Definition{{ID: "a", Severity: "urgent", CEL: "true"}}},
+		"no body":           {Policies: []Definition{{ID: "a", Severity: SeverityLow}}},
+		"both bodies":       {Policies: []Definition{{ID: "a", Severity: SeverityLow, CEL: "true", Rego: "package a"}}},
+		"cel syntax":        {Policies: []Definition{{ID: "a", Severity: SeverityLow, CEL: "object.("}}},
+		"cel non-bool":      {Policies: []Definition{{ID: "a", Severity: SeverityLow, CEL: "'x'"}}},
+		"rego syntax":       {Policies: []Definition{{ID: "a", Severity: SeverityLow, Rego: "package a\ndeny contains"}}},
+		"duplicate builtin": {Policies: []Definition{{ID: PolicyDisallowPrivileged, Severity: SeverityLow, CEL: "true"}}},
+	}
+	for name, file := range cases {
+		if _, err := NewCustomEngine(file); !errors.Is(err, ErrInvalidPolicy) && !errors.Is(err, ErrUnknownPolicy) {
+			t.Errorf("%s: expected ErrInvalidPolicy or ErrUnknownPolicy, got %v", name, err)
+		}
+	}
+}
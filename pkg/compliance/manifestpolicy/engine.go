@@ -0,0 +1,316 @@
+package manifestpolicy
+
+// Local policy checks for manifests submitted through the console's apply
+// endpoint. Policies run against the decoded objects before anything is sent
+// to the cluster, so an enforce-mode violation never reaches the apiserver.
+// The built-in set mirrors the Kubernetes Pod Security "baseline" checks that
+// Kyverno and Gatekeeper libraries ship by default; custom CEL and Rego
+// policies can be loaded from a policy file (see custom.go).
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Built-in policy IDs.
+const (
+	PolicyDisallowPrivileged     = "disallow-privileged"
+	PolicyDisallowHostNamespaces = "disallow-host-namespaces"
+	PolicyRequireResourceLimits  = "require-resource-limits"
+	PolicyDisallowLatestTag      = "disallow-latest-tag"
+
+	PolicyDisallowCriticalVulnerabilities = "disallow-critical-vulnerabilities"
+)
+
+// ErrUnknownPolicy is returned when Evaluate is asked for a policy ID that is
+// not registered.
+var ErrUnknownPolicy = errors.New("unknown policy")
+
+// finding is one rule match before it is tied to a policy and object.
+type finding struct {
+	field   string
+	message string
+}
+
+// rule pairs a policy with the check that implements it.
+type rule struct {
+	policy Policy
+	check  func(obj Object, env Environment) []finding
+}
+
+// Engine evaluates manifest policies. It holds no mutable state and is safe
+// for concurrent use.
+type Engine struct {
+	rules []rule
+}
+
+// NewEngine returns an engine loaded with the built-in policies.
+func NewEngine() *Engine {
+	return &Engine{rules: builtinRules()}
+}
+
+// Policies returns the registered policies, sorted by ID.
+func (e *Engine) Policies() []Policy {
+	out := make([]Policy, 0, len(e.rules))
+	for _, r := range e.rules {
+		out = append(out, r.policy)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Evaluate runs the selected policies against objects in env. An empty
+// policyIDs selects every registered policy.
+func (e *Engine) Evaluate(objects []Object, policyIDs []string, env Environment) (*Report, error) {
+	selected, err := e.selectRules(policyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Evaluated: make([]string, 0, len(selected)), Violations: make([]Violation, 0)}
+	for _, r := range selected {
+		report.Evaluated = append(report.Evaluated, r.policy.ID)
+	}
+	for _, obj := range objects {
+		kind, _ := obj.Content["kind"].(string)
+		name, namespace := objectMeta(obj.Content)
+		for _, r := range selected {
+			for _, f := range r.check(obj, env) {
+				report.Violations = append(report.Violations, Violation{
+					PolicyID:  r.policy.ID,
+					Severity:  r.policy.Severity,
+					Action:    r.policy.Action,
+					Index:     obj.Index,
+					Kind:      kind,
+					Namespace: namespace,
+					Name:      name,
+					Field:     f.field,
+					Message:   f.message,
+				})
+				if r.policy.Action == ActionEnforce {
+					report.Denied = true
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+func (e *Engine) selectRules(policyIDs []string) ([]rule, error) {
+	if len(policyIDs) == 0 {
+		return e.rules, nil
+	}
+	byID := make(map[string]rule, len(e.rules))
+	for _, r := range e.rules {
+		byID[r.policy.ID] = r
+	}
+	seen := make(map[string]bool, len(policyIDs))
+	out := make([]rule, 0, len(policyIDs))
+	for _, id := range policyIDs {
+		r, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPolicy, id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func builtinRules() []rule {
+	return []rule{
+		{
+			policy: Policy{
+				ID:          PolicyDisallowPrivileged,
+				Name:        "Disallow privileged containers",
+				Description: "Containers must not set securityContext.privileged to true.",
+				Severity:    SeverityCritical,
+				Action:      ActionEnforce,
+			},
+			check: checkPrivileged,
+		},
+		{
+			policy: Policy{
+				ID:          PolicyDisallowHostNamespaces,
+				Name:        "Disallow host namespaces",
+				Description: "Pods must not share the host network, PID, or IPC namespace.",
+				Severity:    SeverityHigh,
+				Action:      ActionEnforce,
+			},
+			check: checkHostNamespaces,
+		},
+		{
+			policy: Policy{
+				ID:          PolicyRequireResourceLimits,
+				Name:        "Require resource limits",
+				Description: "Containers must set CPU and memory limits.",
+				Severity:    SeverityMedium,
+				Action:      ActionEnforce,
+			},
+			check: checkResourceLimits,
+		},
+		{
+			policy: Policy{
+				ID:          PolicyDisallowLatestTag,
+				Name:        "Disallow latest image tag",
+				Description: "Container images should be pinned to a tag other than latest or a digest.",
+				Severity:    SeverityLow,
+				Action:      ActionAudit,
+			},
+			check: checkLatestTag,
+		},
+		{
+			policy: Policy{
+				ID:          PolicyDisallowCriticalVulnerabilities,
+				Name:        "Disallow critical vulnerabilities",
+				Description: "Container images must have no critical vulnerabilities in the configured scanner.",
+				Severity:    SeverityHigh,
+				Action:      ActionEnforce,
+			},
+			check: checkCriticalVulnerabilities,
+		},
+	}
+}
+
+func checkPrivileged(obj Object, _ Environment) []finding {
+	var out []finding
+	forEachContainer(obj, true, func(path string, container map[string]interface{}) {
+		sc, _ := container["securityContext"].(map[string]interface{})
+		if privileged, _ := sc["privileged"].(bool); privileged {
+			out = append(out, finding{
+				field:   path + ".securityContext.privileged",
+				message: fmt.Sprintf("container %q must not run privileged", containerName(container)),
+			})
+		}
+	})
+	return out
+}
+
+func checkHostNamespaces(obj Object, _ Environment) []finding {
+	spec, path := podSpec(obj.Content)
+	if spec == nil {
+		return nil
+	}
+	var out []finding
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if enabled, _ := spec[field].(bool); enabled {
+			out = append(out, finding{field: path + "." + field, message: field + " must not be enabled"})
+		}
+	}
+	return out
+}
+
+func checkResourceLimits(obj Object, _ Environment) []finding {
+	var out []finding
+	// Ephemeral containers cannot declare resources, so they are skipped.
+	forEachContainer(obj, false, func(path string, container map[string]interface{}) {
+		resources, _ := container["resources"].(map[string]interface{})
+		limits, _ := resources["limits"].(map[string]interface{})
+		missing := make([]string, 0, 2)
+		for _, resource := range []string{"cpu", "memory"} {
+			if _, ok := limits[resource]; !ok {
+				missing = append(missing, resource)
+			}
+		}
+		if len(missing) > 0 {
+			out = append(out, finding{
+				field:   path + ".resources.limits",
+				message: fmt.Sprintf("container %q must set %s limits", containerName(container), strings.Join(missing, " and ")),
+			})
+		}
+	})
+	return out
+}
+
+func checkLatestTag(obj Object, _ Environment) []finding {
+	var out []finding
+	forEachContainer(obj, true, func(path string, container map[string]interface{}) {
+		image, _ := container["image"].(string)
+		if image == "" || strings.Contains(image, "@") {
+			return
+		}
+		// A colon after the last slash separates the tag; one before it is a
+		// registry port.
+		tag := ""
+		if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			tag = image[i+1:]
+		}
+		if tag == "" || tag == "latest" {
+			out = append(out, finding{
+				field:   path + ".image",
+				message: fmt.Sprintf("container %q image %q is not pinned to a specific tag", containerName(container), image),
+			})
+		}
+	})
+	return out
+}
+
+// podTemplatePaths maps workload kinds to the location of their pod spec.
+var podTemplatePaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// podSpec returns the pod spec embedded in a workload object and its dotted
+// path, or nil when the kind carries no pod template.
+func podSpec(content map[string]interface{}) (map[string]interface{}, string) {
+	kind, _ := content["kind"].(string)
+	path, ok := podTemplatePaths[kind]
+	if !ok {
+		return nil, ""
+	}
+	current := content
+	for _, key := range path {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil, ""
+		}
+		current = next
+	}
+	return current, strings.Join(path, ".")
+}
+
+// forEachContainer calls fn for every init and regular container in the
+// object's pod spec, plus ephemeral containers when includeEphemeral is set.
+func forEachContainer(obj Object, includeEphemeral bool, fn func(path string, container map[string]interface{})) {
+	spec, specPath := podSpec(obj.Content)
+	if spec == nil {
+		return
+	}
+	lists := []string{"initContainers", "containers"}
+	if includeEphemeral {
+		lists = append(lists, "ephemeralContainers")
+	}
+	for _, list := range lists {
+		containers, _ := spec[list].([]interface{})
+		for i, item := range containers {
+			if container, ok := item.(map[string]interface{}); ok {
+				fn(specPath+"."+list+"["+strconv.Itoa(i)+"]", container)
+			}
+		}
+	}
+}
+
+func containerName(container map[string]interface{}) string {
+	name, _ := container["name"].(string)
+	return name
+}
+
+func objectMeta(content map[string]interface{}) (name, namespace string) {
+	md, _ := content["metadata"].(map[string]interface{})
+	name, _ = md["name"].(string)
+	namespace, _ = md["namespace"].(string)
+	return name, namespace
+}
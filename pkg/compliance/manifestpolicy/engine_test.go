@@ -0,0 +1,145 @@
+package manifestpolicy
+
+import (
+	"errors"
+	"testing"
+)
+
+func deployment(containers ...interface{}) Object {
+	return Object{Index: 2, Content: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "apps"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+	}}
+}
+
+func limitedContainer(name, image string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":  name,
+		"image": image,
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{"cpu": "500m", "memory": "256Mi"},
+		},
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	policies := NewEngine().Policies()
+	if len(policies) != 5 {
+		t.Fatalf("expected 5 built-in policies, got %d", len(policies))
+	}
+	for i := 1; i < len(policies); i++ {
+		if policies[i-1].ID > policies[i].ID {
+			t.Errorf("policies not sorted by ID: %s before %s", policies[i-1].ID, policies[i].ID)
+		}
+	}
+}
+
+func TestEvaluate_CompliantWorkload(t *testing.T) {
+	report, err := NewEngine().Evaluate([]Object{deployment(limitedContainer("app", "nginx:1.27"))}, nil, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Denied || len(report.Violations) != 0 {
+		t.Errorf("expected no violations, got %+v", report.Violations)
+	}
+	if len(report.Evaluated) != 5 {
+		t.Errorf("expected all policies evaluated, got %v", report.Evaluated)
+	}
+}
+
+func TestEvaluate_PrivilegedAndMissingLimits(t *testing.T) {
+	privileged := map[string]interface{}{
+		"name":            "app",
+		"image":           "nginx:1.27",
+		"securityContext": map[string]interface{}{"privileged": true},
+	}
+	report, err := NewEngine().Evaluate([]Object{deployment(privileged)}, nil, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Denied {
+		t.Error("expected enforce violations to deny the apply")
+	}
+
+	fields := map[string]string{}
+	for _, v := range report.Violations {
+		fields[v.PolicyID] = v.Field
+		if v.Index != 2 || v.Kind != "Deployment" || v.Name != "web" || v.Namespace != "apps" {
+			t.Errorf("violation not tied to its object: %+v", v)
+		}
+	}
+	if fields[PolicyDisallowPrivileged] != "spec.template.spec.containers[0].securityContext.privileged" {
+		t.Errorf("unexpected privileged field: %q", fields[PolicyDisallowPrivileged])
+	}
+	if fields[PolicyRequireResourceLimits] != "spec.template.spec.containers[0].resources.limits" {
+		t.Errorf("unexpected limits field: %q", fields[PolicyRequireResourceLimits])
+	}
+}
+
+func TestEvaluate_HostNamespacesAndCronJob(t *testing.T) {
+	cronJob := Object{Content: map[string]interface{}{
+		"kind":     "CronJob",
+		"metadata": map[string]interface{}{"name": "nightly"},
+		"spec": map[string]interface{}{
+			"jobTemplate": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"hostNetwork": true,
+							"containers":  []interface{}{limitedContainer("job", "busybox:1.36")},
+						},
+					},
+				},
+			},
+		},
+	}}
+	report, err := NewEngine().Evaluate([]Object{cronJob}, []string{PolicyDisallowHostNamespaces}, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].Field != "spec.jobTemplate.spec.template.spec.hostNetwork" {
+		t.Errorf("expected a single hostNetwork violation, got %+v", report.Violations)
+	}
+}
+
+func TestEvaluate_LatestTagIsAuditOnly(t *testing.T) {
+	obj := deployment(
+		limitedContainer("a", "nginx"),
+		limitedContainer("b", "registry.local:5000/app:latest"),
+		limitedContainer("c", "registry.local:5000/app"),
+		limitedContainer("d", "nginx@sha256:abc"),
+	)
+	report, err := NewEngine().Evaluate([]Object{obj}, []string{PolicyDisallowLatestTag}, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Denied {
+		t.Error("audit-only policy must not deny the apply")
+	}
+	if len(report.Violations) != 3 {
+		t.Errorf("expected 3 unpinned images, got %+v", report.Violations)
+	}
+}
+
+func TestEvaluate_NonWorkloadIgnored(t *testing.T) {
+	cm := Object{Content: map[string]interface{}{"kind": "ConfigMap", "metadata": map[string]interface{}{"name": "cfg"}}}
+	report, err := NewEngine().Evaluate([]Object{cm}, nil, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Errorf("expected no violations for a ConfigMap, got %+v", report.Violations)
+	}
+}
+
+func TestEvaluate_UnknownPolicy(t *testing.T) {
+	if _, err := NewEngine().Evaluate(nil, []string{"no-such-policy"}, Environment{}); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("expected ErrUnknownPolicy, got %v", err)
+	}
+}
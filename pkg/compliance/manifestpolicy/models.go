@@ -0,0 +1,112 @@
+package manifestpolicy
+
+// Severity of a policy violation.
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+)
+
+// Action decides what happens when a policy matches, mirroring Kyverno's
+// validationFailureAction.
+type Action string
+
+const (
+	// ActionEnforce blocks the apply.
+	ActionEnforce Action = "enforce"
+	// ActionAudit reports the violation but lets the apply proceed.
+	ActionAudit Action = "audit"
+)
+
+// Policy describes one built-in manifest policy.
+type Policy struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Action      Action   `json:"action"`
+}
+
+// Object is one decoded manifest document. Index is the document's position
+// in the submitted manifest so violations can be mapped back to the editor.
+type Object struct {
+	Index   int
+	Content map[string]interface{}
+}
+
+// Environment is the context an object is evaluated in. Custom policies see
+// it as the cluster and vulnerabilities variables (CEL) or input.cluster and
+// input.vulnerabilities (Rego).
+type Environment struct {
+	Cluster string
+	// Vulnerabilities holds the scan results of the images being evaluated,
+	// keyed by image reference. It is nil when no scanner is configured; an
+	// image without an entry has no report in the scanner.
+	Vulnerabilities map[string]ImageVulnerabilities
+}
+
+// ImageVulnerabilities counts an image's known vulnerabilities by severity.
+// Error is set instead when the scanner could not be queried.
+type ImageVulnerabilities struct {
+	Critical int    `json:"critical"`
+	High     int    `json:"high"`
+	Medium   int    `json:"medium"`
+	Low      int    `json:"low"`
+	Unknown  int    `json:"unknown"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Violation is a single policy failure on one object.
+type Violation struct {
+	PolicyID  string   `json:"policy_id"`
+	Severity  Severity `json:"severity"`
+	Action    Action   `json:"action"`
+	Index     int      `json:"index"`
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Field     string   `json:"field,omitempty"`
+	Message   string   `json:"message"`
+}
+
+// Report is the outcome of evaluating a set of objects.
+type Report struct {
+	// Denied is true when at least one enforce-mode policy was violated.
+	Denied     bool        `json:"denied"`
+	Evaluated  []string    `json:"evaluated"`
+	Violations []Violation `json:"violations"`
+}
+
+// Definition is a custom policy loaded from a policy file. Exactly one of CEL
+// or Rego must be set.
+//
+// A CEL expression sees the variables object, cluster and vulnerabilities
+// and must evaluate to true for the object to pass, as in a
+// ValidatingAdmissionPolicy. A Rego module is queried for its deny set
+// (Gatekeeper/conftest style); each entry is either a message string or an
+// object with msg and optional field keys.
+type Definition struct {
+	ID          string   `yaml:"id" json:"id"`
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Severity    Severity `yaml:"severity" json:"severity"`
+	// Action defaults from Severity: critical and high enforce, medium and
+	// low audit.
+	Action Action `yaml:"action" json:"action"`
+	// Kinds limits the policy to these object kinds; empty matches all.
+	Kinds []string `yaml:"kinds" json:"kinds"`
+	// Message is reported when a CEL expression evaluates to false.
+	Message string `yaml:"message" json:"message"`
+	CEL     string `yaml:"cel" json:"cel"`
+	Rego    string `yaml:"rego" json:"rego"`
+}
+
+// PolicyFile is the on-disk policy configuration.
+type PolicyFile struct {
+	// Builtins selects built-in policies by ID to run alongside Policies.
+	Builtins []string     `yaml:"builtins" json:"builtins"`
+	Policies []Definition `yaml:"policies" json:"policies"`
+}
@@ -0,0 +1,93 @@
+package manifestpolicy
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Images returns the distinct container images of objects, sorted.
+func Images(objects []Object) []string {
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		forEachContainer(obj, true, func(_ string, container map[string]interface{}) {
+			if image, _ := container["image"].(string); image != "" {
+				seen[image] = true
+			}
+		})
+	}
+	out := make([]string, 0, len(seen))
+	for image := range seen {
+		out = append(out, image)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// checkCriticalVulnerabilities flags containers whose image has critical
+// vulnerabilities. An image the scanner could not be queried for is flagged
+// too, so a scanner outage cannot wave images through; one the scanner has
+// no report for passes.
+func checkCriticalVulnerabilities(obj Object, env Environment) []finding {
+	if env.Vulnerabilities == nil {
+		return nil
+	}
+	var out []finding
+	forEachContainer(obj, true, func(path string, container map[string]interface{}) {
+		image, _ := container["image"].(string)
+		v, ok := env.Vulnerabilities[image]
+		switch {
+		case !ok:
+		case v.Error != "":
+			out = append(out, finding{
+				field:   path + ".image",
+				message: fmt.Sprintf("container %q image %q could not be scanned: %s", containerName(container), image, v.Error),
+			})
+		case v.Critical > 0:
+			out = append(out, finding{
+				field:   path + ".image",
+				message: fmt.Sprintf("container %q image %q has %d critical vulnerabilities", containerName(container), image, v.Critical),
+			})
+		}
+	})
+	return out
+}
+
+// vulnerabilityInput is the vulnerabilities variable of custom policies:
+// the severity counts summed over the object's images with a report, plus
+// the per-image entries. scanned is false when no scanner is configured.
+func vulnerabilityInput(obj Object, env Environment) map[string]interface{} {
+	var total ImageVulnerabilities
+	images := make(map[string]interface{})
+	forEachContainer(obj, true, func(_ string, container map[string]interface{}) {
+		image, _ := container["image"].(string)
+		v, ok := env.Vulnerabilities[image]
+		if !ok || images[image] != nil {
+			return
+		}
+		entry := map[string]interface{}{
+			"critical": v.Critical,
+			"high":     v.High,
+			"medium":   v.Medium,
+			"low":      v.Low,
+			"unknown":  v.Unknown,
+		}
+		if v.Error != "" {
+			entry["error"] = v.Error
+		}
+		images[image] = entry
+		total.Critical += v.Critical
+		total.High += v.High
+		total.Medium += v.Medium
+		total.Low += v.Low
+		total.Unknown += v.Unknown
+	})
+	return map[string]interface{}{
+		"scanned":  env.Vulnerabilities != nil,
+		"critical": total.Critical,
+		"high":     total.High,
+		"medium":   total.Medium,
+		"low":      total.Low,
+		"unknown":  total.Unknown,
+		"images":   images,
+	}
+}
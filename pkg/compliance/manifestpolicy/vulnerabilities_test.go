@@ -0,0 +1,110 @@
+package manifestpolicy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImages(t *testing.T) {
+	objects := []Object{
+		deployment(limitedContainer("app", "nginx:1.27"), limitedContainer("sidecar", "envoy:1.30")),
+		deployment(limitedContainer("app", "nginx:1.27")),
+		{Content: map[string]interface{}{"kind": "ConfigMap"}},
+	}
+	if got, want := Images(objects), []string{"envoy:1.30", "nginx:1.27"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Images() = %v, want %v", got, want)
+	}
+}
+
+func TestEvaluate_CriticalVulnerabilities(t *testing.T) {
+	obj := deployment(limitedContainer("app", "nginx:1.27"), limitedContainer("sidecar", "envoy:1.30"))
+	policy := []string{PolicyDisallowCriticalVulnerabilities}
+
+	report, err := NewEngine().Evaluate([]Object{obj}, policy, Environment{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Errorf("expected no violations without a scanner, got %+v", report.Violations)
+	}
+
+	env := Environment{Vulnerabilities: map[string]ImageVulnerabilities{
+		"nginx:1.27": {Critical: 2, High: 5},
+		"envoy:1.30": {High: 1},
+	}}
+	report, err = NewEngine().Evaluate([]Object{obj}, policy, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Denied || len(report.Violations) != 1 {
+		t.Fatalf("expected the nginx image to be denied, got %+v", report)
+	}
+	if v := report.Violations[0]; v.Field != "spec.template.spec.containers[0].image" ||
+		v.Message != `container "app" image "nginx:1.27" has 2 critical vulnerabilities` {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+
+	env.Vulnerabilities = map[string]ImageVulnerabilities{"envoy:1.30": {Error: "connection refused"}}
+	report, err = NewEngine().Evaluate([]Object{obj}, policy, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Denied || len(report.Violations) != 1 || report.Violations[0].Field != "spec.template.spec.containers[1].image" {
+		t.Errorf("expected only the unscannable envoy image to be denied, got %+v", report.Violations)
+	}
+}
+
+func TestCustomPolicies_Vulnerabilities(t *testing.T) {
+	e, err := NewCustomEngine(PolicyFile{Policies: []Definition{
+		{
+			ID:       "limit-high",
+			Severity: SeverityHigh,
+			Message:  "too many high vulnerabilities",
+			CEL:      "!vulnerabilities.scanned || vulnerabilities.high < 3",
+		},
+		{
+			ID:       "no-unscanned-prod",
+			Severity: SeverityHigh,
+			Rego: `package console
+deny contains msg if {
+	input.cluster.name == "prod"
+	input.vulnerabilities.scanned
+	count(input.vulnerabilities.images) == 0
+	msg := "images must be scanned before reaching prod"
+}`,
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj := deployment(limitedContainer("app", "nginx:1.27"), limitedContainer("sidecar", "envoy:1.30"))
+
+	env := Environment{Cluster: "dev", Vulnerabilities: map[string]ImageVulnerabilities{
+		"nginx:1.27": {High: 2},
+		"envoy:1.30": {High: 1},
+	}}
+	report, err := e.Evaluate([]Object{obj}, nil, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].PolicyID != "limit-high" {
+		t.Errorf("expected the summed high count to fail limit-high, got %+v", report.Violations)
+	}
+
+	env = Environment{Cluster: "prod", Vulnerabilities: map[string]ImageVulnerabilities{}}
+	report, err = e.Evaluate([]Object{obj}, nil, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 1 || report.Violations[0].PolicyID != "no-unscanned-prod" {
+		t.Errorf("expected unscanned images to fail on prod, got %+v", report.Violations)
+	}
+
+	report, err = e.Evaluate([]Object{obj}, nil, Environment{Cluster: "prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Violations) != 0 {
+		t.Errorf("expected no violations without a scanner, got %+v", report.Violations)
+	}
+}
@@ -0,0 +1,187 @@
+// Package deploywindow evaluates deployment windows: recurring wall-clock
+// ranges such as "02:00-05:00 on weekdays" during which a cluster may be
+// deployed to. Windows are evaluated in the cluster's local timezone unless
+// the window names its own.
+package deploywindow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidWindow is returned when a window's times, days or timezone do not
+// parse.
+var ErrInvalidWindow = errors.New("invalid deployment window")
+
+const (
+	minutesPerHour = 60
+	minutesPerDay  = 24 * minutesPerHour
+	daysPerWeek    = 7
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Window is a parsed deployment window. The zero value is not usable; build
+// one with Parse.
+type Window struct {
+	// start and end are minutes after local midnight. end < start wraps
+	// past midnight into the next day.
+	start, end int
+	// days holds the weekdays the window opens on; all false means every day.
+	days [daysPerWeek]bool
+	// loc overrides the cluster's timezone when set.
+	loc *time.Location
+}
+
+// Parse builds a window from HH:MM start and end times, optional weekday
+// names (Mon..Sun, matched on the day the window opens) and an optional IANA
+// timezone that overrides the cluster's.
+func Parse(start, end string, days []string, timezone string) (Window, error) {
+	var w Window
+	var err error
+	if w.start, err = parseClock(start); err != nil {
+		return Window{}, fmt.Errorf("%w: start: %v", ErrInvalidWindow, err)
+	}
+	if w.end, err = parseClock(end); err != nil {
+		return Window{}, fmt.Errorf("%w: end: %v", ErrInvalidWindow, err)
+	}
+	if w.start == w.end {
+		return Window{}, fmt.Errorf("%w: start and end must differ", ErrInvalidWindow)
+	}
+	for _, d := range days {
+		wd, ok := weekdays[strings.ToLower(strings.TrimSpace(d))]
+		if !ok {
+			return Window{}, fmt.Errorf("%w: unknown day %q", ErrInvalidWindow, d)
+		}
+		w.days[wd] = true
+	}
+	if timezone != "" {
+		if w.loc, err = LoadLocation(timezone); err != nil {
+			return Window{}, err
+		}
+	}
+	return w, nil
+}
+
+// LoadLocation resolves an IANA timezone name such as "Asia/Tokyo". An empty
+// name is UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidWindow, name)
+	}
+	return loc, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return t.Hour()*minutesPerHour + t.Minute(), nil
+}
+
+func (w Window) location(clusterLoc *time.Location) *time.Location {
+	if w.loc != nil {
+		return w.loc
+	}
+	if clusterLoc != nil {
+		return clusterLoc
+	}
+	return time.UTC
+}
+
+func (w Window) opensOn(day time.Weekday) bool {
+	for _, set := range w.days {
+		if set {
+			return w.days[day]
+		}
+	}
+	return true
+}
+
+// Contains reports whether t falls inside the window for a cluster in
+// clusterLoc.
+func (w Window) Contains(t time.Time, clusterLoc *time.Location) bool {
+	local := t.In(w.location(clusterLoc))
+	m := local.Hour()*minutesPerHour + local.Minute()
+	if w.start < w.end {
+		return m >= w.start && m < w.end && w.opensOn(local.Weekday())
+	}
+	// Wraps midnight: the tail of yesterday's window or the head of today's.
+	if m >= w.start {
+		return w.opensOn(local.Weekday())
+	}
+	return m < w.end && w.opensOn(local.AddDate(0, 0, -1).Weekday())
+}
+
+// NextOpen returns t if the window is open, otherwise the next instant it
+// opens.
+func (w Window) NextOpen(t time.Time, clusterLoc *time.Location) time.Time {
+	if w.Contains(t, clusterLoc) {
+		return t
+	}
+	loc := w.location(clusterLoc)
+	local := t.In(loc)
+	// One extra day covers a window that opens later today but only runs
+	// on today's weekday a week from now.
+	for d := 0; d <= daysPerWeek; d++ {
+		day := local.AddDate(0, 0, d)
+		open := time.Date(day.Year(), day.Month(), day.Day(), w.start/minutesPerHour, w.start%minutesPerHour, 0, 0, loc)
+		if open.After(t) && w.opensOn(open.Weekday()) {
+			return open
+		}
+	}
+	// Unreachable: every window opens at least once a week.
+	return t.Add(daysPerWeek * 24 * time.Hour)
+}
+
+// String formats the window as "02:00-05:00 Mon,Tue Asia/Tokyo".
+func (w Window) String() string {
+	s := fmt.Sprintf("%02d:%02d-%02d:%02d", w.start/minutesPerHour, w.start%minutesPerHour, w.end/minutesPerHour, w.end%minutesPerHour)
+	var days []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if w.days[d] {
+			days = append(days, d.String()[:3])
+		}
+	}
+	if len(days) > 0 {
+		s += " " + strings.Join(days, ",")
+	}
+	if w.loc != nil {
+		s += " " + w.loc.String()
+	}
+	return s
+}
+
+// Eligible reports whether t is inside any of windows for a cluster in
+// clusterLoc. When it is not, next is the earliest instant one opens. No
+// windows means no restriction.
+func Eligible(windows []Window, t time.Time, clusterLoc *time.Location) (ok bool, next time.Time) {
+	if len(windows) == 0 {
+		return true, t
+	}
+	for _, w := range windows {
+		open := w.NextOpen(t, clusterLoc)
+		if open.Equal(t) {
+			return true, t
+		}
+		if next.IsZero() || open.Before(next) {
+			next = open
+		}
+	}
+	return false, next
+}
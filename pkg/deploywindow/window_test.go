@@ -0,0 +1,130 @@
+package deploywindow
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func mustParse(t *testing.T, start, end string, days []string, tz string) Window {
+	t.Helper()
+	w, err := Parse(start, end, days, tz)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return w
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, args := range map[string][4]string{
+		"bad start":    {"2am", "05:00", "", ""},
+		"bad end":      {"02:00", "25:00", "", ""},
+		"empty window": {"02:00", "02:00", "", ""},
+		"bad day":      {"02:00", "05:00", "Funday", ""},
+		"bad timezone": {"02:00", "05:00", "", "Mars/Olympus"},
+	} {
+		var days []string
+		if args[2] != "" {
+			days = []string{args[2]}
+		}
+		if _, err := Parse(args[0], args[1], days, args[3]); !errors.Is(err, ErrInvalidWindow) {
+			t.Errorf("%s: err = %v, want ErrInvalidWindow", name, err)
+		}
+	}
+}
+
+func TestContains_ClusterTimezone(t *testing.T) {
+	tokyo := mustLoad(t, "Asia/Tokyo")
+	w := mustParse(t, "02:00", "05:00", nil, "")
+
+	// 18:30 UTC is 03:30 the next morning in Tokyo.
+	at := time.Date(2026, 10, 14, 18, 30, 0, 0, time.UTC)
+	if !w.Contains(at, tokyo) {
+		t.Error("03:30 Tokyo should be inside 02:00-05:00")
+	}
+	if w.Contains(at, time.UTC) {
+		t.Error("18:30 UTC should be outside 02:00-05:00")
+	}
+	if w.Contains(time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC), tokyo) {
+		t.Error("the end time is exclusive")
+	}
+
+	// A window timezone overrides the cluster's.
+	utcWindow := mustParse(t, "02:00", "05:00", nil, "UTC")
+	if utcWindow.Contains(at, tokyo) {
+		t.Error("window timezone should override the cluster timezone")
+	}
+}
+
+func TestContains_WrapsMidnightAndDays(t *testing.T) {
+	// Friday 22:00 to Saturday 02:00, opening on Fridays only.
+	w := mustParse(t, "22:00", "02:00", []string{"Fri"}, "")
+	fri := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	sat := time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC)
+	sun := time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC)
+	if !w.Contains(fri, nil) || !w.Contains(sat, nil) {
+		t.Error("Friday night window should cover 23:00 Fri and 01:00 Sat")
+	}
+	if w.Contains(sun, nil) {
+		t.Error("01:00 Sunday belongs to a Saturday opening, which is not allowed")
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	tokyo := mustLoad(t, "Asia/Tokyo")
+	w := mustParse(t, "02:00", "05:00", nil, "")
+
+	// 06:00 Tokyo: the next window opens at 02:00 Tokyo tomorrow.
+	at := time.Date(2026, 10, 14, 21, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 10, 16, 2, 0, 0, 0, tokyo)
+	if got := w.NextOpen(at, tokyo); !got.Equal(want) {
+		t.Errorf("NextOpen = %v, want %v", got, want)
+	}
+	if got := w.NextOpen(want, tokyo); !got.Equal(want) {
+		t.Errorf("NextOpen inside the window = %v, want %v", got, want)
+	}
+
+	// Weekdays only: Saturday evening waits until Monday.
+	weekdays := mustParse(t, "02:00", "05:00", []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, "")
+	sat := time.Date(2026, 10, 17, 18, 0, 0, 0, time.UTC)
+	want = time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC)
+	if got := weekdays.NextOpen(sat, nil); !got.Equal(want) {
+		t.Errorf("NextOpen(weekdays) = %v, want %v", got, want)
+	}
+}
+
+func TestEligible(t *testing.T) {
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	if ok, _ := Eligible(nil, at, nil); !ok {
+		t.Error("no windows means no restriction")
+	}
+
+	morning := mustParse(t, "02:00", "05:00", nil, "")
+	evening := mustParse(t, "20:00", "22:00", nil, "")
+	ok, next := Eligible([]Window{morning, evening}, at, nil)
+	if ok {
+		t.Fatal("noon is outside both windows")
+	}
+	if want := time.Date(2026, 10, 14, 20, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("next = %v, want the earliest opening %v", next, want)
+	}
+	if ok, _ := Eligible([]Window{morning, evening}, next, nil); !ok {
+		t.Error("the window is open at its next opening time")
+	}
+}
+
+func TestString(t *testing.T) {
+	w := mustParse(t, "2:00", "05:30", []string{"monday", "Fri"}, "Asia/Tokyo")
+	if got, want := w.String(), "02:00-05:30 Mon,Fri Asia/Tokyo"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+// Package doctor runs the configuration checks behind the --check flag of
+// console and kc-agent. The checks exercise what the process would use at
+// startup — the store, kubeconfigs, CRDs, data sources and provider keys —
+// and the result is a JSON report on stdout, so CI jobs and Helm pre-install
+// hooks can act on it.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/kubestellar/console/pkg/egress"
+)
+
+// Status is the outcome of one check.
+type Status string
+
+const (
+	// StatusOK means the check passed.
+	StatusOK Status = "ok"
+	// StatusWarn means the process starts but a feature will not work.
+	StatusWarn Status = "warn"
+	// StatusFail means the process cannot start, or runs misconfigured.
+	StatusFail Status = "fail"
+	// StatusSkipped means the check does not apply to this configuration.
+	StatusSkipped Status = "skipped"
+)
+
+// DefaultTimeout bounds each check.
+const DefaultTimeout = 20 * time.Second
+
+// Check is one configuration check. Run returns the status and a
+// human-readable detail.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (Status, string)
+}
+
+// Result is the outcome of a Check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     Status `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of every check of a component. OK is false when
+// any check failed; warnings do not fail the report.
+type Report struct {
+	Component string    `json:"component"`
+	Version   string    `json:"version"`
+	OK        bool      `json:"ok"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Run runs checks in order, each bounded by timeout, and returns the
+// report. A check that panics or outlives its timeout fails.
+func Run(ctx context.Context, component, version string, timeout time.Duration, checks []Check) Report {
+	report := Report{Component: component, Version: version, OK: true, CheckedAt: time.Now().UTC(), Checks: make([]Result, 0, len(checks))}
+	for _, check := range checks {
+		result := runOne(ctx, check, timeout)
+		if result.Status == StatusFail {
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+func runOne(ctx context.Context, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+
+	type outcome struct {
+		status Status
+		detail string
+	}
+	done := make(chan outcome, 1)
+	// The check runs on its own goroutine so a call that ignores ctx
+	// cannot hold up the report; the process exits right after it.
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{StatusFail, fmt.Sprintf("check panicked: %v", r)}
+			}
+		}()
+		status, detail := check.Run(ctx)
+		done <- outcome{status, detail}
+	}()
+
+	result := Result{Name: check.Name}
+	select {
+	case o := <-done:
+		result.Status, result.Detail = o.status, o.detail
+	case <-ctx.Done():
+		result.Status, result.Detail = StatusFail, fmt.Sprintf("timed out after %s", timeout)
+	}
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result
+}
+
+// AirGapCheck reports the air-gapped policy the process set up; setupErr
+// is what egress.Setup returned.
+func AirGapCheck(setupErr error) Check {
+	return Check{Name: "air-gapped", Run: func(context.Context) (Status, string) {
+		if setupErr != nil {
+			return StatusFail, setupErr.Error()
+		}
+		if !egress.AirGapped() {
+			return StatusSkipped, "air-gapped mode is off"
+		}
+		return StatusOK, "air-gapped mode is on"
+	}}
+}
+
+// Main runs the --check flag for component: the air-gapped check followed by
+// checks, with the report written to w. It returns the process exit code.
+func Main(w io.Writer, component, version string, egressErr error, checks ...Check) int {
+	checks = append([]Check{AirGapCheck(egressErr)}, checks...)
+	report := Run(context.Background(), component, version, DefaultTimeout, checks)
+	if err := report.Write(w); err != nil {
+		slog.Error("failed to write check report", "error", err)
+		return 1
+	}
+	return report.ExitCode()
+}
+
+// Write writes the report to w as indented JSON.
+func (r Report) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ExitCode is the process exit code for the report: 0 when it is OK, else 1.
+func (r Report) ExitCode() int {
+	if r.OK {
+		return 0
+	}
+	return 1
+}
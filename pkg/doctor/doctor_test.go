@@ -0,0 +1,121 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubestellar/console/pkg/egress"
+)
+
+func staticCheck(name string, status Status, detail string) Check {
+	return Check{Name: name, Run: func(context.Context) (Status, string) { return status, detail }}
+}
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), "console", "v1.2.3", time.Second, []Check{
+		staticCheck("store", StatusOK, "./data/console.db"),
+		staticCheck("kubeconfig", StatusWarn, "no kubeconfig"),
+		staticCheck("console-crds", StatusSkipped, "persistence is off"),
+	})
+	if !report.OK || report.ExitCode() != 0 {
+		t.Fatalf("warnings and skips must not fail the report: %+v", report)
+	}
+	var names []string
+	for _, r := range report.Checks {
+		names = append(names, r.Name)
+	}
+	if got := strings.Join(names, ","); got != "store,kubeconfig,console-crds" {
+		t.Errorf("checks out of order: %s", got)
+	}
+
+	report = Run(context.Background(), "console", "v1.2.3", time.Second, []Check{
+		staticCheck("store", StatusOK, ""),
+		staticCheck("jwt-secret", StatusFail, "JWT_SECRET is required"),
+	})
+	if report.OK || report.ExitCode() != 1 {
+		t.Errorf("a failed check must fail the report: %+v", report)
+	}
+}
+
+func TestRun_TimeoutAndPanicFail(t *testing.T) {
+	report := Run(context.Background(), "kc-agent", "dev", 10*time.Millisecond, []Check{
+		{Name: "hangs", Run: func(context.Context) (Status, string) {
+			time.Sleep(time.Second)
+			return StatusOK, ""
+		}},
+		{Name: "panics", Run: func(context.Context) (Status, string) { panic("boom") }},
+	})
+	for _, r := range report.Checks {
+		if r.Status != StatusFail {
+			t.Errorf("%s: status %q, want fail", r.Name, r.Status)
+		}
+	}
+	if !strings.Contains(report.Checks[0].Detail, "timed out") || !strings.Contains(report.Checks[1].Detail, "boom") {
+		t.Errorf("details: %q, %q", report.Checks[0].Detail, report.Checks[1].Detail)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := Run(context.Background(), "console", "v1.2.3", time.Second, []Check{staticCheck("store", StatusOK, "")})
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var decoded struct {
+		Component string `json:"component"`
+		OK        bool   `json:"ok"`
+		Checks    []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, buf.String())
+	}
+	if decoded.Component != "console" || !decoded.OK || len(decoded.Checks) != 1 || decoded.Checks[0].Status != "ok" {
+		t.Errorf("decoded report: %+v", decoded)
+	}
+}
+
+func TestAirGapCheck(t *testing.T) {
+	t.Cleanup(func() { _ = egress.Configure(egress.Policy{}) })
+
+	if status, _ := AirGapCheck(errors.New("invalid host")).Run(context.Background()); status != StatusFail {
+		t.Errorf("setup error: status %q, want fail", status)
+	}
+	_ = egress.Configure(egress.Policy{})
+	if status, _ := AirGapCheck(nil).Run(context.Background()); status != StatusSkipped {
+		t.Errorf("off: status %q, want skipped", status)
+	}
+	_ = egress.Configure(egress.Policy{AirGapped: true})
+	if status, _ := AirGapCheck(nil).Run(context.Background()); status != StatusOK {
+		t.Errorf("on: status %q, want ok", status)
+	}
+}
+
+func TestMain_PrependsAirGapCheck(t *testing.T) {
+	t.Cleanup(func() { _ = egress.Configure(egress.Policy{}) })
+	_ = egress.Configure(egress.Policy{})
+
+	var buf bytes.Buffer
+	if code := Main(&buf, "kc-agent", "v1.2.3", nil, staticCheck("kubeconfig", StatusOK, "")); code != 0 {
+		t.Errorf("exit code %d, want 0", code)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded.Checks) != 2 || decoded.Checks[0].Name != "air-gapped" || decoded.Checks[1].Name != "kubeconfig" {
+		t.Errorf("checks: %+v", decoded.Checks)
+	}
+
+	buf.Reset()
+	if code := Main(&buf, "kc-agent", "v1.2.3", errors.New("invalid host")); code != 1 {
+		t.Errorf("setup error: exit code %d, want 1", code)
+	}
+}
@@ -0,0 +1,191 @@
+// Package egress implements the console's air-gapped mode, for regulated
+// environments where no data may leave the network.
+//
+// Setting AIR_GAPPED=true (or passing --air-gapped to console or kc-agent)
+// turns off every integration that talks to a service outside the network:
+// telemetry, Google Drive benchmark data, cloud AI providers and
+// auto-update. Outbound HTTP from the shared clients is refused unless it
+// goes to loopback or to a host listed in AIR_GAPPED_ALLOWED_HOSTS, so an
+// integration that was missed fails closed rather than leaking.
+//
+// Connections to Kubernetes API servers do not go through these clients and
+// are not affected.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// EnvAirGapped switches air-gapped mode on.
+	EnvAirGapped = "AIR_GAPPED"
+	// EnvAllowedHosts lists the in-network hosts outbound HTTP may still
+	// reach, comma-separated. An entry is a host name, a "*.domain" wildcard
+	// or an IP range in CIDR notation.
+	EnvAllowedHosts = "AIR_GAPPED_ALLOWED_HOSTS"
+)
+
+// ErrBlocked is returned when dialing a host air-gapped mode does not allow.
+var ErrBlocked = errors.New("outbound connection blocked by air-gapped mode")
+
+// DialFunc is the signature of http.Transport.DialContext.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Policy is the egress configuration of a process.
+type Policy struct {
+	AirGapped bool
+	// AllowedHosts are the hosts outbound HTTP may reach while air-gapped,
+	// in the format of EnvAllowedHosts. Loopback is always allowed.
+	AllowedHosts []string
+}
+
+// compiledPolicy is a Policy with its allowlist parsed.
+type compiledPolicy struct {
+	Policy
+	hosts    map[string]bool
+	suffixes []string
+	networks []*net.IPNet
+}
+
+var current atomic.Pointer[compiledPolicy]
+
+// PolicyFromEnv reads the policy from EnvAirGapped and EnvAllowedHosts.
+func PolicyFromEnv() Policy {
+	p := Policy{AirGapped: os.Getenv(EnvAirGapped) == "true"}
+	for _, h := range strings.Split(os.Getenv(EnvAllowedHosts), ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			p.AllowedHosts = append(p.AllowedHosts, h)
+		}
+	}
+	return p
+}
+
+// Configure makes p the process's policy. It rejects an allowlist entry it
+// cannot parse rather than silently allowing less or more than intended.
+func Configure(p Policy) error {
+	cp := &compiledPolicy{Policy: p, hosts: make(map[string]bool)}
+	for _, entry := range p.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return fmt.Errorf("%s: invalid range %q: %w", EnvAllowedHosts, entry, err)
+			}
+			cp.networks = append(cp.networks, network)
+		case strings.HasPrefix(entry, "*."):
+			cp.suffixes = append(cp.suffixes, entry[1:])
+		case entry == "" || strings.ContainsAny(entry, "*:"):
+			return fmt.Errorf("%s: invalid host %q", EnvAllowedHosts, entry)
+		default:
+			cp.hosts[entry] = true
+		}
+	}
+	current.Store(cp)
+	return nil
+}
+
+// Setup configures the process from the environment at startup, with
+// airGapped (the --air-gapped flag) forcing air-gapped mode on. In
+// air-gapped mode it also guards http.DefaultTransport.
+func Setup(airGapped bool) error {
+	p := PolicyFromEnv()
+	p.AirGapped = p.AirGapped || airGapped
+	if err := Configure(p); err != nil {
+		return err
+	}
+	if p.AirGapped {
+		GuardDefaultTransport()
+	}
+	return nil
+}
+
+// AirGapped reports whether air-gapped mode is on.
+func AirGapped() bool {
+	cp := current.Load()
+	return cp != nil && cp.AirGapped
+}
+
+// Allowed reports whether outbound HTTP may reach host. Every host is
+// allowed unless air-gapped mode is on.
+func Allowed(host string) bool {
+	cp := current.Load()
+	if cp == nil || !cp.AirGapped {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || cp.hosts[host] {
+		return true
+	}
+	for _, suffix := range cp.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() {
+			return true
+		}
+		for _, network := range cp.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Dial wraps next so it refuses addresses Allowed rejects. next nil means a
+// plain net.Dialer.
+func Dial(next DialFunc) DialFunc {
+	if next == nil {
+		next = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		if !Allowed(host) {
+			return nil, fmt.Errorf("%w: %s", ErrBlocked, host)
+		}
+		return next(ctx, network, addr)
+	}
+}
+
+// GuardDefaultTransport routes http.DefaultTransport, and so every client
+// without a transport of its own, through Dial.
+func GuardDefaultTransport() {
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.DialContext = Dial(t.DialContext)
+	}
+}
+
+// Feature is one line of the startup report: an outbound integration and
+// what air-gapped mode did with it.
+type Feature struct {
+	Name     string `json:"name"`
+	Disabled bool   `json:"disabled"`
+	Detail   string `json:"detail"`
+}
+
+// LogReport logs the policy and each feature of a component's report. It
+// logs nothing when air-gapped mode is off.
+func LogReport(component string, features []Feature) {
+	cp := current.Load()
+	if cp == nil || !cp.AirGapped {
+		return
+	}
+	slog.Info("["+component+"] air-gapped mode is on", "allowedHosts", cp.AllowedHosts)
+	for _, f := range features {
+		slog.Info("["+component+"] air-gapped feature", "feature", f.Name, "disabled", f.Disabled, "detail", f.Detail)
+	}
+}
@@ -0,0 +1,112 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func configure(t *testing.T, p Policy) {
+	t.Helper()
+	if err := Configure(p); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	t.Cleanup(func() { _ = Configure(Policy{}) })
+}
+
+func TestAllowed(t *testing.T) {
+	configure(t, Policy{AirGapped: true, AllowedHosts: []string{"git.corp.example", "*.svc.cluster.local", "10.20.0.0/16"}})
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"localhost", true},
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"git.corp.example", true},
+		{"GIT.corp.example.", true},
+		{"ollama.ai.svc.cluster.local", true},
+		{"svc.cluster.local", false},
+		{"10.20.3.4", true},
+		{"10.21.0.1", false},
+		{"api.github.com", false},
+		{"www.googleapis.com", false},
+		{"corp.example", false},
+	}
+	for _, tt := range tests {
+		if got := Allowed(tt.host); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestAllowed_EverythingWhenNotAirGapped(t *testing.T) {
+	configure(t, Policy{AllowedHosts: []string{"git.corp.example"}})
+	if AirGapped() {
+		t.Fatal("air-gapped mode should be off")
+	}
+	if !Allowed("api.github.com") {
+		t.Error("every host should be allowed outside air-gapped mode")
+	}
+}
+
+func TestConfigure_RejectsBadEntries(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Policy{}) })
+	for _, entry := range []string{"10.0.0.0/33", "git.*.example", "host:8080"} {
+		if err := Configure(Policy{AirGapped: true, AllowedHosts: []string{entry}}); err == nil {
+			t.Errorf("Configure accepted %q", entry)
+		}
+	}
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv(EnvAirGapped, "true")
+	t.Setenv(EnvAllowedHosts, " git.corp.example, ,10.0.0.0/8 ")
+	p := PolicyFromEnv()
+	if !p.AirGapped {
+		t.Error("AirGapped should be read from the environment")
+	}
+	if len(p.AllowedHosts) != 2 || p.AllowedHosts[0] != "git.corp.example" || p.AllowedHosts[1] != "10.0.0.0/8" {
+		t.Errorf("AllowedHosts = %q", p.AllowedHosts)
+	}
+}
+
+func TestDial(t *testing.T) {
+	configure(t, Policy{AirGapped: true})
+	var dialed []string
+	dial := Dial(func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, errors.New("stop")
+	})
+
+	if _, err := dial(context.Background(), "tcp", "api.github.com:443"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("dial to api.github.com: err = %v, want ErrBlocked", err)
+	}
+	if _, err := dial(context.Background(), "tcp", "127.0.0.1:8585"); errors.Is(err, ErrBlocked) {
+		t.Errorf("dial to loopback was blocked: %v", err)
+	}
+	if len(dialed) != 1 || dialed[0] != "127.0.0.1:8585" {
+		t.Errorf("dialed = %q, want only the loopback address", dialed)
+	}
+}
+
+func TestDial_HTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+	configure(t, Policy{AirGapped: true})
+
+	client := &http.Client{Transport: &http.Transport{DialContext: Dial(nil)}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("loopback request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get("http://example.com/"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("request to example.com: err = %v, want ErrBlocked", err)
+	}
+}
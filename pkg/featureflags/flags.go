@@ -0,0 +1,299 @@
+// Package featureflags gates experimental subsystems behind admin-controlled
+// flags.
+//
+// A flag is either boolean (on or off for everyone) or a percentage rollout,
+// where each user is hashed into a stable bucket so the same user keeps the
+// same answer as the percentage grows. Either kind can be forced on or off for
+// individual users. Flag state is stored in the settings file, so it is
+// included in settings export and import.
+package featureflags
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+// Kind is the evaluation strategy of a flag.
+type Kind string
+
+const (
+	KindBoolean    Kind = "boolean"
+	KindPercentage Kind = "percentage"
+)
+
+// Well-known flags.
+const (
+	// CanaryEngine enables progressive canary rollouts for WorkloadDeployments.
+	CanaryEngine = "canary-engine"
+	// AITools allows AI-driven tool execution through the MCP tool endpoints.
+	AITools = "ai-tools"
+	// RelayMode routes cluster traffic through the kc-agent relay.
+	RelayMode = "relay-mode"
+)
+
+// fullRollout is the percentage at which a percentage flag is on for everyone.
+const fullRollout = 100
+
+var (
+	// ErrUnknownFlag is returned for a flag name that is not defined.
+	ErrUnknownFlag = errors.New("unknown feature flag")
+	// ErrInvalidUpdate is returned for an update the flag cannot accept.
+	ErrInvalidUpdate = errors.New("invalid feature flag update")
+)
+
+// Definition describes a flag and its default state.
+type Definition struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Kind        Kind   `json:"kind"`
+	// Experimental flags default to off.
+	Experimental      bool `json:"experimental"`
+	DefaultEnabled    bool `json:"defaultEnabled"`
+	DefaultPercentage int  `json:"defaultPercentage,omitempty"`
+}
+
+var definitions = []Definition{
+	{
+		Key:          CanaryEngine,
+		Description:  "Progressive canary rollouts for WorkloadDeployments",
+		Kind:         KindPercentage,
+		Experimental: true,
+	},
+	{
+		Key:            AITools,
+		Description:    "AI-driven tool execution through the MCP tool endpoints",
+		Kind:           KindBoolean,
+		DefaultEnabled: true,
+	},
+	{
+		Key:          RelayMode,
+		Description:  "Route cluster traffic through the kc-agent relay",
+		Kind:         KindBoolean,
+		Experimental: true,
+	},
+}
+
+// Definitions returns every defined flag, sorted by key.
+func Definitions() []Definition {
+	out := append([]Definition(nil), definitions...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+func lookup(key string) (Definition, bool) {
+	for _, d := range definitions {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Store persists flag configuration. *settings.SettingsManager implements it.
+type Store interface {
+	GetFeatureFlags() map[string]settings.FeatureFlagSetting
+	SaveFeatureFlags(map[string]settings.FeatureFlagSetting) error
+}
+
+// User identifies who a flag is evaluated for. Overrides match either field.
+type User struct {
+	ID    string
+	Login string
+}
+
+// State is a flag's definition, its configuration, and its value for the
+// requesting user.
+type State struct {
+	Definition
+	Enabled    bool            `json:"enabled"`
+	Percentage int             `json:"percentage,omitempty"`
+	Users      map[string]bool `json:"users,omitempty"`
+	// Configured is false when the flag still has its default state.
+	Configured bool `json:"configured"`
+	// Active is the flag's value for the requesting user.
+	Active bool `json:"active"`
+}
+
+// Update changes a flag's global state. Nil fields are left unchanged.
+type Update struct {
+	Enabled    *bool `json:"enabled"`
+	Percentage *int  `json:"percentage"`
+}
+
+// Manager evaluates and updates feature flags. It is safe for concurrent use.
+type Manager struct {
+	mu    sync.Mutex
+	store Store
+}
+
+// NewManager returns a Manager backed by store.
+func NewManager(store Store) *Manager {
+	return &Manager{store: store}
+}
+
+// configFor returns the stored configuration of d, or its default.
+func configFor(d Definition, stored map[string]settings.FeatureFlagSetting) (settings.FeatureFlagSetting, bool) {
+	if f, ok := stored[d.Key]; ok {
+		return f, true
+	}
+	return settings.FeatureFlagSetting{Enabled: d.DefaultEnabled, Percentage: d.DefaultPercentage}, false
+}
+
+// IsEnabled reports whether flag key is on for user. Unknown flags are off.
+// A nil Manager reports every flag at its default.
+func (m *Manager) IsEnabled(key string, user User) bool {
+	d, ok := lookup(key)
+	if !ok {
+		return false
+	}
+	var stored map[string]settings.FeatureFlagSetting
+	if m != nil && m.store != nil {
+		stored = m.store.GetFeatureFlags()
+	}
+	f, _ := configFor(d, stored)
+	return evaluate(d, f, user)
+}
+
+func evaluate(d Definition, f settings.FeatureFlagSetting, user User) bool {
+	if v, ok := userOverride(f, user); ok {
+		return v
+	}
+	if !f.Enabled {
+		return false
+	}
+	if d.Kind != KindPercentage || f.Percentage >= fullRollout {
+		return true
+	}
+	// Anonymous callers have no stable bucket, so partial rollouts exclude them.
+	if user.ID == "" && user.Login == "" {
+		return false
+	}
+	return bucket(d.Key, user) < f.Percentage
+}
+
+func userOverride(f settings.FeatureFlagSetting, user User) (bool, bool) {
+	if user.ID != "" {
+		if v, ok := f.Users[user.ID]; ok {
+			return v, true
+		}
+	}
+	if user.Login != "" {
+		if v, ok := f.Users[user.Login]; ok {
+			return v, true
+		}
+	}
+	return false, false
+}
+
+// bucket maps a user to a stable value in [0, 100) per flag, so users are
+// rolled out to independently for each flag.
+func bucket(key string, user User) int {
+	id := user.ID
+	if id == "" {
+		id = user.Login
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key + ":" + id))
+	return int(h.Sum32() % fullRollout)
+}
+
+// List returns the state of every flag for user.
+func (m *Manager) List(user User) []State {
+	stored := m.store.GetFeatureFlags()
+	out := make([]State, 0, len(definitions))
+	for _, d := range Definitions() {
+		f, configured := configFor(d, stored)
+		out = append(out, State{
+			Definition: d,
+			Enabled:    f.Enabled,
+			Percentage: f.Percentage,
+			Users:      f.Users,
+			Configured: configured,
+			Active:     evaluate(d, f, user),
+		})
+	}
+	return out
+}
+
+// Active returns each flag's value for user, keyed by flag name.
+func (m *Manager) Active(user User) map[string]bool {
+	out := make(map[string]bool, len(definitions))
+	for _, s := range m.List(user) {
+		out[s.Key] = s.Active
+	}
+	return out
+}
+
+// Set applies u to flag key.
+func (m *Manager) Set(key string, u Update) error {
+	if u.Percentage != nil && (*u.Percentage < 0 || *u.Percentage > fullRollout) {
+		return fmt.Errorf("%w: percentage must be between 0 and %d", ErrInvalidUpdate, fullRollout)
+	}
+	return m.modify(key, func(d Definition, f *settings.FeatureFlagSetting) error {
+		if u.Percentage != nil {
+			if d.Kind != KindPercentage {
+				return fmt.Errorf("%w: %s is a %s flag and has no percentage", ErrInvalidUpdate, key, d.Kind)
+			}
+			f.Percentage = *u.Percentage
+		}
+		if u.Enabled != nil {
+			f.Enabled = *u.Enabled
+		}
+		return nil
+	})
+}
+
+// SetUserOverride forces flag key on or off for user, which is a user ID or
+// GitHub login. A nil enabled removes the override.
+func (m *Manager) SetUserOverride(key, user string, enabled *bool) error {
+	if user == "" {
+		return fmt.Errorf("%w: user is required", ErrInvalidUpdate)
+	}
+	return m.modify(key, func(_ Definition, f *settings.FeatureFlagSetting) error {
+		if enabled == nil {
+			delete(f.Users, user)
+			if len(f.Users) == 0 {
+				f.Users = nil
+			}
+			return nil
+		}
+		if f.Users == nil {
+			f.Users = make(map[string]bool)
+		}
+		f.Users[user] = *enabled
+		return nil
+	})
+}
+
+// Reset returns flag key to its default state, dropping user overrides.
+func (m *Manager) Reset(key string) error {
+	if _, ok := lookup(key); !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, key)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.store.GetFeatureFlags()
+	delete(stored, key)
+	return m.store.SaveFeatureFlags(stored)
+}
+
+func (m *Manager) modify(key string, fn func(Definition, *settings.FeatureFlagSetting) error) error {
+	d, ok := lookup(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, key)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := m.store.GetFeatureFlags()
+	f, _ := configFor(d, stored)
+	if err := fn(d, &f); err != nil {
+		return err
+	}
+	stored[key] = f
+	return m.store.SaveFeatureFlags(stored)
+}
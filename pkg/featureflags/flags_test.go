@@ -0,0 +1,172 @@
+package featureflags
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+type memStore struct {
+	flags map[string]settings.FeatureFlagSetting
+	saves int
+}
+
+func (m *memStore) GetFeatureFlags() map[string]settings.FeatureFlagSetting {
+	out := make(map[string]settings.FeatureFlagSetting, len(m.flags))
+	for k, v := range m.flags {
+		out[k] = v
+	}
+	return out
+}
+
+func (m *memStore) SaveFeatureFlags(flags map[string]settings.FeatureFlagSetting) error {
+	m.flags = flags
+	m.saves++
+	return nil
+}
+
+func boolPtr(b bool) *bool { return &b }
+func intPtr(i int) *int    { return &i }
+
+func TestDefaults(t *testing.T) {
+	m := NewManager(&memStore{})
+	user := User{ID: "u1"}
+	if !m.IsEnabled(AITools, user) {
+		t.Error("ai-tools should default to on")
+	}
+	if m.IsEnabled(CanaryEngine, user) || m.IsEnabled(RelayMode, user) {
+		t.Error("experimental flags should default to off")
+	}
+	if m.IsEnabled("no-such-flag", user) {
+		t.Error("unknown flags are off")
+	}
+	var nilManager *Manager
+	if !nilManager.IsEnabled(AITools, user) {
+		t.Error("nil manager should report defaults")
+	}
+}
+
+func TestBooleanFlagAndUserOverrides(t *testing.T) {
+	store := &memStore{}
+	m := NewManager(store)
+	if err := m.Set(RelayMode, Update{Enabled: boolPtr(true)}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !m.IsEnabled(RelayMode, User{ID: "u1"}) {
+		t.Error("relay-mode should be on after Set")
+	}
+
+	if err := m.SetUserOverride(RelayMode, "octocat", boolPtr(false)); err != nil {
+		t.Fatalf("SetUserOverride: %v", err)
+	}
+	if m.IsEnabled(RelayMode, User{ID: "u2", Login: "octocat"}) {
+		t.Error("login override should turn the flag off")
+	}
+	if !m.IsEnabled(RelayMode, User{ID: "u1"}) {
+		t.Error("other users keep the global value")
+	}
+
+	if err := m.SetUserOverride(RelayMode, "octocat", nil); err != nil {
+		t.Fatalf("remove override: %v", err)
+	}
+	if store.flags[RelayMode].Users != nil {
+		t.Errorf("empty override map should be dropped, got %v", store.flags[RelayMode].Users)
+	}
+
+	if err := m.Reset(RelayMode); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if m.IsEnabled(RelayMode, User{ID: "u1"}) {
+		t.Error("reset should restore the default")
+	}
+}
+
+func TestPercentageRollout(t *testing.T) {
+	m := NewManager(&memStore{})
+	if err := m.Set(CanaryEngine, Update{Enabled: boolPtr(true), Percentage: intPtr(30)}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	const users = 1000
+	on := map[string]bool{}
+	for i := 0; i < users; i++ {
+		id := fmt.Sprintf("user-%d", i)
+		if m.IsEnabled(CanaryEngine, User{ID: id}) {
+			on[id] = true
+		}
+	}
+	if len(on) < 250 || len(on) > 350 {
+		t.Errorf("expected about 30%% of users, got %d/%d", len(on), users)
+	}
+	if m.IsEnabled(CanaryEngine, User{}) {
+		t.Error("anonymous callers are excluded from partial rollouts")
+	}
+
+	// Raising the percentage keeps everyone who already had the flag.
+	if err := m.Set(CanaryEngine, Update{Percentage: intPtr(60)}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	for id := range on {
+		if !m.IsEnabled(CanaryEngine, User{ID: id}) {
+			t.Fatalf("%s lost the flag when the rollout grew", id)
+		}
+	}
+
+	if err := m.Set(CanaryEngine, Update{Percentage: intPtr(100)}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !m.IsEnabled(CanaryEngine, User{}) {
+		t.Error("a full rollout includes anonymous callers")
+	}
+}
+
+func TestInvalidUpdates(t *testing.T) {
+	store := &memStore{}
+	m := NewManager(store)
+	for name, err := range map[string]error{
+		"unknown flag":       m.Set("nope", Update{Enabled: boolPtr(true)}),
+		"percentage range":   m.Set(CanaryEngine, Update{Percentage: intPtr(101)}),
+		"boolean percentage": m.Set(RelayMode, Update{Percentage: intPtr(50)}),
+		"missing user":       m.SetUserOverride(RelayMode, "", boolPtr(true)),
+	} {
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := m.Set("nope", Update{}); !errors.Is(err, ErrUnknownFlag) {
+		t.Errorf("expected ErrUnknownFlag, got %v", err)
+	}
+	if err := m.Set(CanaryEngine, Update{Percentage: intPtr(-1)}); !errors.Is(err, ErrInvalidUpdate) {
+		t.Errorf("expected ErrInvalidUpdate, got %v", err)
+	}
+	if store.saves != 0 {
+		t.Errorf("invalid updates must not be saved, got %d saves", store.saves)
+	}
+}
+
+func TestList(t *testing.T) {
+	m := NewManager(&memStore{})
+	if err := m.SetUserOverride(CanaryEngine, "u1", boolPtr(true)); err != nil {
+		t.Fatalf("SetUserOverride: %v", err)
+	}
+	states := m.List(User{ID: "u1"})
+	if len(states) != len(definitions) {
+		t.Fatalf("expected %d flags, got %d", len(definitions), len(states))
+	}
+	byKey := map[string]State{}
+	for _, s := range states {
+		byKey[s.Key] = s
+	}
+	canary := byKey[CanaryEngine]
+	if !canary.Configured || !canary.Active || canary.Enabled {
+		t.Errorf("unexpected canary state: %+v", canary)
+	}
+	if byKey[AITools].Configured || !byKey[AITools].Active {
+		t.Errorf("unexpected ai-tools state: %+v", byKey[AITools])
+	}
+	if active := m.Active(User{ID: "u2"}); active[CanaryEngine] || !active[AITools] {
+		t.Errorf("unexpected active flags for u2: %v", active)
+	}
+}
@@ -0,0 +1,154 @@
+// Package i18n localizes the error and status strings the console returns
+// to users. Messages are looked up by key in a Catalog, in the language
+// negotiated from Accept-Language, falling back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language every key must exist in and the one
+// used when negotiation finds no match.
+const DefaultLanguage = "en"
+
+// Catalog holds message templates by language and key. Templates use the
+// same {{name}} placeholders as the web UI's i18next catalogs.
+type Catalog interface {
+	// Message returns the template of key in lang, exactly as registered.
+	Message(lang, key string) (string, bool)
+	// Messages returns every key and template of lang.
+	Messages(lang string) map[string]string
+	// Languages returns the languages with at least one message.
+	Languages() []string
+}
+
+// MapCatalog is a Catalog held in memory, keyed by language then key.
+type MapCatalog map[string]map[string]string
+
+// Message implements Catalog.
+func (m MapCatalog) Message(lang, key string) (string, bool) {
+	msg, ok := m[lang][key]
+	return msg, ok
+}
+
+// Messages implements Catalog.
+func (m MapCatalog) Messages(lang string) map[string]string {
+	return m[lang]
+}
+
+// Languages implements Catalog.
+func (m MapCatalog) Languages() []string {
+	langs := make([]string, 0, len(m))
+	for lang, msgs := range m {
+		if len(msgs) > 0 {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Merge adds the messages of other, replacing existing keys.
+func (m MapCatalog) Merge(other Catalog) {
+	for _, lang := range other.Languages() {
+		if m[lang] == nil {
+			m[lang] = make(map[string]string)
+		}
+		for key, msg := range other.Messages(lang) {
+			m[lang][key] = msg
+		}
+	}
+}
+
+// Decoder parses one catalog file into keys and templates.
+type Decoder func(data []byte) (map[string]string, error)
+
+// DecodeJSON reads a JSON catalog. Nested objects are flattened into dotted
+// keys, so {"deployment": {"failed": "..."}} defines deployment.failed.
+func DecodeJSON(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	if err := flatten("", raw, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func flatten(prefix string, raw map[string]any, out map[string]string) error {
+	for k, v := range raw {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case string:
+			out[key] = v
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: message must be a string or an object", key)
+		}
+	}
+	return nil
+}
+
+// Decoders are the catalog formats Load reads, by file extension. Register
+// another format by adding its decoder.
+var Decoders = map[string]Decoder{
+	".json": DecodeJSON,
+}
+
+// Load reads the catalog files in dir of fsys. Each file is named after
+// its language, e.g. fr.json or zh-TW.json, and is parsed with the Decoder
+// of its extension; files of other extensions are ignored.
+func Load(fsys fs.FS, dir string) (MapCatalog, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(MapCatalog)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := path.Ext(e.Name())
+		decode, ok := Decoders[ext]
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		catalog.Merge(MapCatalog{strings.TrimSuffix(e.Name(), ext): msgs})
+	}
+	return catalog, nil
+}
+
+//go:embed locales
+var embeddedLocales embed.FS
+
+// Builtin returns the catalogs shipped with the console.
+func Builtin() MapCatalog {
+	catalog, err := Load(embeddedLocales, "locales")
+	if err != nil {
+		// The embedded files are checked by the package tests.
+		panic(fmt.Sprintf("i18n: invalid builtin catalog: %v", err))
+	}
+	return catalog
+}
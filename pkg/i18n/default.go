@@ -0,0 +1,35 @@
+package i18n
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// CatalogDirEnv names a directory of extra catalog files, in any format of
+// Decoders, merged over the builtin catalogs. It adds languages or
+// overrides builtin messages without rebuilding the console.
+const CatalogDirEnv = "I18N_CATALOG_DIR"
+
+var (
+	defaultOnce      sync.Once
+	defaultLocalizer *Localizer
+)
+
+// Default returns the localizer over the builtin catalogs and those of
+// CatalogDirEnv. A directory that cannot be read is logged and skipped.
+func Default() *Localizer {
+	defaultOnce.Do(func() {
+		catalog := Builtin()
+		if dir := os.Getenv(CatalogDirEnv); dir != "" {
+			extra, err := Load(os.DirFS(dir), ".")
+			if err != nil {
+				slog.Warn("[i18n] ignoring catalog directory", "env", CatalogDirEnv, "dir", dir, "error", err)
+			} else {
+				catalog.Merge(extra)
+			}
+		}
+		defaultLocalizer = NewLocalizer(catalog)
+	})
+	return defaultLocalizer
+}
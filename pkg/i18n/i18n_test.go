@@ -0,0 +1,130 @@
+package i18n
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuiltinCatalogs(t *testing.T) {
+	catalog := Builtin()
+	en := catalog.Messages(DefaultLanguage)
+	if len(en) == 0 {
+		t.Fatal("no English messages")
+	}
+	for _, lang := range catalog.Languages() {
+		for key, msg := range catalog.Messages(lang) {
+			tmpl, ok := en[key]
+			if !ok {
+				t.Errorf("%s: key %s is not in the English catalog", lang, key)
+				continue
+			}
+			if got, want := placeholders(msg), placeholders(tmpl); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s has placeholders %v, want %v", lang, key, got, want)
+			}
+		}
+	}
+}
+
+func placeholders(tmpl string) []string {
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(tmpl, -1) {
+		names = append(names, m[1])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/fr.json":   {Data: []byte(`{"deployment": {"failed": "Échec du déploiement"}}`)},
+		"locales/README.md": {Data: []byte("ignored")},
+	}
+	catalog, err := Load(fsys, "locales")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if msg, ok := catalog.Message("fr", "deployment.failed"); !ok || msg != "Échec du déploiement" {
+		t.Errorf("got %q, %v", msg, ok)
+	}
+	if got := catalog.Languages(); !reflect.DeepEqual(got, []string{"fr"}) {
+		t.Errorf("languages = %v, want [fr]", got)
+	}
+
+	fsys["locales/de.json"] = &fstest.MapFile{Data: []byte(`{"deployment": {"failed": 1}}`)}
+	if _, err := Load(fsys, "locales"); err == nil {
+		t.Error("expected an error for a non-string message")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	supported := []string{"en", "es", "pt", "zh", "zh-TW"}
+	for _, tc := range []struct {
+		header, want string
+	}{
+		{"", "en"},
+		{"es", "es"},
+		{"ES-mx", "es"},
+		{"pt-BR,pt;q=0.9", "pt"},
+		{"zh-TW", "zh-TW"},
+		{"zh-HK", "zh"},
+		{"fr;q=1, es;q=0.5", "es"},
+		{"de, *;q=0.5", "en"},
+		{"es;q=0, pt", "pt"},
+		{"es;q=abc, pt;q=0.1", "pt"},
+	} {
+		if got := Negotiate(tc.header, supported); got != tc.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+func testLocalizer() *Localizer {
+	return NewLocalizer(MapCatalog{
+		"en": {
+			"deployment.failed":            "Deployment failed",
+			"deployment.windowCheckFailed": "Deployment window check failed: {{detail}}",
+			"deployment.partial":           "Partial deployment: {{succeeded}} succeeded, {{failed}} failed",
+			"deployment.succeeded":         "Deployed successfully",
+		},
+		"es": {
+			"deployment.failed":  "Falló el despliegue",
+			"deployment.partial": "Despliegue parcial: {{succeeded}} correctos, {{failed}} fallidos",
+		},
+	})
+}
+
+func TestLocalize(t *testing.T) {
+	l := testLocalizer()
+	if got := l.Localize("es-MX", "deployment.failed", nil); got != "Falló el despliegue" {
+		t.Errorf("base language: got %q", got)
+	}
+	if got := l.Localize("es", "deployment.succeeded", nil); got != "Deployed successfully" {
+		t.Errorf("English fallback: got %q", got)
+	}
+	if got := l.Localize("es", "deployment.unknown", nil); got != "deployment.unknown" {
+		t.Errorf("unknown key: got %q", got)
+	}
+	got := l.Localize("es", "deployment.partial", Params{"succeeded": "2", "failed": "1"})
+	if got != "Despliegue parcial: 2 correctos, 1 fallidos" {
+		t.Errorf("params: got %q", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	l := testLocalizer()
+	key, got := l.Translate("es", "Partial deployment: 3 succeeded, 2 failed")
+	if key != "deployment.partial" || got != "Despliegue parcial: 3 correctos, 2 fallidos" {
+		t.Errorf("got %q, %q", key, got)
+	}
+	// The longer template wins over the bare "Deployment failed" prefix.
+	key, got = l.Translate("es", "Deployment window check failed: timeout")
+	if key != "deployment.windowCheckFailed" || got != "Deployment window check failed: timeout" {
+		t.Errorf("got %q, %q", key, got)
+	}
+	key, got = l.Translate("es", "something else")
+	if key != "" || got != "something else" {
+		t.Errorf("unmatched: got %q, %q", key, got)
+	}
+}
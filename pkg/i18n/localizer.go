@@ -0,0 +1,142 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholderPattern matches a {{name}} placeholder in a template.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// Params are the values substituted for a template's placeholders.
+type Params map[string]string
+
+// Localizer renders catalog messages. It is safe for concurrent use; the
+// catalog must not change after NewLocalizer.
+type Localizer struct {
+	catalog   Catalog
+	languages []string
+	// matchers recognize rendered English messages, most specific first.
+	matchers []messageMatcher
+}
+
+// messageMatcher recognizes the English rendering of one key.
+type messageMatcher struct {
+	key     string
+	pattern *regexp.Regexp
+	params  []string
+	// literal is the length of the template without placeholders; longer
+	// literals are tried first so "Deployment failed" never swallows
+	// "Deployment window check failed: ...".
+	literal int
+}
+
+// NewLocalizer creates a localizer over catalog.
+func NewLocalizer(catalog Catalog) *Localizer {
+	l := &Localizer{catalog: catalog, languages: catalog.Languages()}
+	for key, tmpl := range catalog.Messages(DefaultLanguage) {
+		l.matchers = append(l.matchers, compileMatcher(key, tmpl))
+	}
+	sort.Slice(l.matchers, func(i, j int) bool {
+		if l.matchers[i].literal != l.matchers[j].literal {
+			return l.matchers[i].literal > l.matchers[j].literal
+		}
+		return l.matchers[i].key < l.matchers[j].key
+	})
+	return l
+}
+
+// Languages returns the languages messages can be localized to.
+func (l *Localizer) Languages() []string {
+	return l.languages
+}
+
+// Negotiate picks the language of an Accept-Language header among the
+// localizer's languages.
+func (l *Localizer) Negotiate(header string) string {
+	return Negotiate(header, l.languages)
+}
+
+// Localize renders key in lang. A key missing in lang is rendered from its
+// base language, then from DefaultLanguage; an unknown key is returned as
+// is so a missing translation never hides the message.
+func (l *Localizer) Localize(lang, key string, params Params) string {
+	for _, candidate := range fallbackChain(lang) {
+		if tmpl, ok := l.catalog.Message(candidate, key); ok {
+			return render(tmpl, params)
+		}
+	}
+	return key
+}
+
+// Translate localizes a message already rendered in English, such as a
+// deployment failure reason stored in a resource's status. It returns the
+// key the message was rendered from, or "" and the message unchanged when
+// no English template matches.
+func (l *Localizer) Translate(lang, message string) (key, localized string) {
+	key, params, ok := l.Match(message)
+	if !ok {
+		return "", message
+	}
+	return key, l.Localize(lang, key, params)
+}
+
+// Match finds the English template message was rendered from and the
+// values of its placeholders.
+func (l *Localizer) Match(message string) (string, Params, bool) {
+	for _, m := range l.matchers {
+		groups := m.pattern.FindStringSubmatch(message)
+		if groups == nil {
+			continue
+		}
+		params := make(Params, len(m.params))
+		for i, name := range m.params {
+			params[name] = groups[i+1]
+		}
+		return m.key, params, true
+	}
+	return "", nil, false
+}
+
+// fallbackChain lists the languages tried for lang: lang itself, its base
+// language and DefaultLanguage.
+func fallbackChain(lang string) []string {
+	chain := []string{lang}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		chain = append(chain, base)
+	}
+	if lang != DefaultLanguage {
+		chain = append(chain, DefaultLanguage)
+	}
+	return chain
+}
+
+func render(tmpl string, params Params) string {
+	return placeholderPattern.ReplaceAllStringFunc(tmpl, func(p string) string {
+		name := placeholderPattern.FindStringSubmatch(p)[1]
+		if v, ok := params[name]; ok {
+			return v
+		}
+		return p
+	})
+}
+
+func compileMatcher(key, tmpl string) messageMatcher {
+	m := messageMatcher{key: key}
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(tmpl, -1) {
+		b.WriteString(regexp.QuoteMeta(tmpl[last:loc[0]]))
+		m.literal += loc[0] - last
+		b.WriteString("(.*?)")
+		m.params = append(m.params, tmpl[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(tmpl[last:]))
+	m.literal += len(tmpl) - last
+	b.WriteString("$")
+	m.pattern = regexp.MustCompile(b.String())
+	return m
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxAcceptLanguageTags bounds how many tags of an Accept-Language header
+// are considered, so a huge header costs no more than a normal one.
+const maxAcceptLanguageTags = 16
+
+// languagePreference is one tag of an Accept-Language header.
+type languagePreference struct {
+	tag string
+	q   float64
+}
+
+// ParseAcceptLanguage returns the tags of an Accept-Language header, most
+// preferred first. Tags with q=0 and malformed entries are dropped.
+func ParseAcceptLanguage(header string) []string {
+	var prefs []languagePreference
+	for _, part := range strings.Split(header, ",") {
+		if len(prefs) == maxAcceptLanguageTags {
+			break
+		}
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		prefs = append(prefs, languagePreference{tag: tag, q: q})
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+	tags := make([]string, len(prefs))
+	for i, p := range prefs {
+		tags[i] = p.tag
+	}
+	return tags
+}
+
+// Negotiate picks the supported language that best matches an
+// Accept-Language header. A tag matches a supported language of the same
+// name, ignoring case, or, failing that, one named after its base language,
+// so pt-BR is served pt. DefaultLanguage is returned when nothing matches.
+func Negotiate(header string, supported []string) string {
+	for _, tag := range ParseAcceptLanguage(header) {
+		if tag == "*" {
+			break
+		}
+		if lang, ok := findLanguage(tag, supported); ok {
+			return lang
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if lang, ok := findLanguage(base, supported); ok {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+func findLanguage(tag string, supported []string) (string, bool) {
+	for _, lang := range supported {
+		if strings.EqualFold(tag, lang) {
+			return lang, true
+		}
+	}
+	return "", false
+}
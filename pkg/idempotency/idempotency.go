@@ -0,0 +1,168 @@
+// Package idempotency makes create requests safe to retry. A client sends a
+// unique Idempotency-Key header with a request; the first response for the
+// key is kept for a window, and a retry with the same key and body gets that
+// response back instead of creating the resource again.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Header is the request header carrying the client's key.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set to "true" on a response replayed from the cache.
+	ReplayedHeader = "Idempotent-Replayed"
+	// DefaultWindow is how long a key's response is kept.
+	DefaultWindow = 24 * time.Hour
+	// MaxKeyLength bounds a key; a UUID fits comfortably.
+	MaxKeyLength = 255
+)
+
+// maxEntries bounds the keys held at once. Past it, the entries closest to
+// expiry are dropped first, down to pruneTarget, so a full cache isn't
+// sorted on every request.
+const (
+	maxEntries  = 10000
+	pruneTarget = maxEntries * 9 / 10
+)
+
+var (
+	// ErrInProgress is returned while the first request with a key is still
+	// running.
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrMismatch is returned when a key is reused for a different request.
+	ErrMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// Response is a stored response, replayed on retry.
+type Response struct {
+	Status      int
+	ContentType string
+	Location    string
+	Body        []byte
+}
+
+// Cache maps keys to the response of their first request. The zero value
+// is not usable; use NewCache.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	window  time.Duration
+	now     func() time.Time
+}
+
+type entry struct {
+	fingerprint string
+	// resp is nil while the first request is in progress.
+	resp    *Response
+	expires time.Time
+}
+
+// NewCache creates a cache that keeps responses for window, DefaultWindow
+// if zero.
+func NewCache(window time.Duration) *Cache {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Cache{
+		entries: make(map[string]*entry),
+		window:  window,
+		now:     time.Now,
+	}
+}
+
+// ValidKey reports whether key can be used: non-empty, at most
+// MaxKeyLength and printable ASCII.
+func ValidKey(key string) bool {
+	if key == "" || len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Fingerprint identifies a request body, so a key reused for another
+// request is detected.
+func Fingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Begin claims key for a request with fingerprint. When the key already has
+// a response for the same request, Begin returns it for replay. Otherwise it
+// returns nil, and the caller runs the request and then calls Complete, or
+// Release when the response should not be kept. Begin returns
+// ErrInProgress while the key's first request runs and ErrMismatch when the
+// key was used for a different request.
+func (c *Cache) Begin(key, fingerprint string) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if e, ok := c.entries[key]; ok && now.Before(e.expires) {
+		switch {
+		case e.fingerprint != fingerprint:
+			return nil, ErrMismatch
+		case e.resp == nil:
+			return nil, ErrInProgress
+		}
+		resp := *e.resp
+		return &resp, nil
+	}
+	c.pruneLocked(now)
+	c.entries[key] = &entry{fingerprint: fingerprint, expires: now.Add(c.window)}
+	return nil, nil
+}
+
+// Complete stores resp as the response for key.
+func (c *Cache) Complete(key string, resp Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		resp.Body = append([]byte(nil), resp.Body...)
+		e.resp = &resp
+		e.expires = c.now().Add(c.window)
+	}
+}
+
+// Release forgets key, so a retry runs the request again.
+func (c *Cache) Release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok && e.resp == nil {
+		delete(c.entries, key)
+	}
+}
+
+// pruneLocked drops expired entries once the cache is full and, if it is
+// still full, the entries closest to expiry.
+func (c *Cache) pruneLocked(now time.Time) {
+	if len(c.entries) < maxEntries {
+		return
+	}
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < maxEntries {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].expires.Before(c.entries[keys[j]].expires) })
+	for _, key := range keys[:len(keys)-pruneTarget] {
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_Replay(t *testing.T) {
+	c := NewCache(time.Minute)
+	fp := Fingerprint([]byte(`{"name":"a"}`))
+
+	if resp, err := c.Begin("k", fp); resp != nil || err != nil {
+		t.Fatalf("first Begin = %v, %v; want nil, nil", resp, err)
+	}
+	if _, err := c.Begin("k", fp); !errors.Is(err, ErrInProgress) {
+		t.Errorf("Begin while running = %v, want ErrInProgress", err)
+	}
+
+	body := []byte(`{"id":"1"}`)
+	c.Complete("k", Response{Status: 201, ContentType: "application/json", Body: body})
+	body[0] = 'x'
+
+	resp, err := c.Begin("k", fp)
+	if err != nil || resp == nil {
+		t.Fatalf("retry Begin = %v, %v; want the stored response", resp, err)
+	}
+	if resp.Status != 201 || string(resp.Body) != `{"id":"1"}` {
+		t.Errorf("replayed %d %s", resp.Status, resp.Body)
+	}
+	if _, err := c.Begin("k", Fingerprint([]byte(`{"name":"b"}`))); !errors.Is(err, ErrMismatch) {
+		t.Errorf("Begin with another body = %v, want ErrMismatch", err)
+	}
+}
+
+func TestCache_ReleaseAndExpiry(t *testing.T) {
+	c := NewCache(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Begin("k", "fp")
+	c.Release("k")
+	if resp, err := c.Begin("k", "fp"); resp != nil || err != nil {
+		t.Fatalf("Begin after Release = %v, %v; want a fresh claim", resp, err)
+	}
+	c.Complete("k", Response{Status: 201})
+
+	now = now.Add(2 * time.Minute)
+	if resp, err := c.Begin("k", "other"); resp != nil || err != nil {
+		t.Errorf("Begin after the window = %v, %v; want a fresh claim", resp, err)
+	}
+}
+
+func TestCache_Bounded(t *testing.T) {
+	c := NewCache(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	for i := 0; i < maxEntries+10; i++ {
+		now = now.Add(time.Millisecond)
+		c.Begin(fmt.Sprintf("k%d", i), "fp")
+	}
+	if len(c.entries) > maxEntries {
+		t.Errorf("cache holds %d entries, want at most %d", len(c.entries), maxEntries)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"":                                     false,
+		"3f2a6c1e-5d8e-4a57-9b8e-0a4a3f6c1d2b": true,
+		"has space":                            false,
+		strings.Repeat("a", MaxKeyLength+1):    false,
+	} {
+		if got := ValidKey(key); got != want {
+			t.Errorf("ValidKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -333,6 +333,30 @@ func NewMultiClusterClient(kubeconfig string) (*MultiClusterClient, error) {
 	return client, nil
 }
 
+// NewInMemoryMultiClusterClient creates a client that serves only the
+// contexts in rawConfig through the given pre-built clients. Unlike
+// NewMultiClusterClient it never reads a kubeconfig file or probes for
+// in-cluster config, so fake mode and integration tests stay hermetic.
+func NewInMemoryMultiClusterClient(rawConfig *api.Config, clients map[string]kubernetes.Interface, dynamicClients map[string]dynamic.Interface) *MultiClusterClient {
+	client := &MultiClusterClient{
+		clients:        make(map[string]kubernetes.Interface, len(clients)),
+		dynamicClients: make(map[string]dynamic.Interface, len(dynamicClients)),
+		configs:        make(map[string]*rest.Config),
+		rawConfig:      rawConfig,
+		healthCache:    make(map[string]*ClusterHealth),
+		cacheTTL:       clusterCacheTTL,
+		cacheTime:      make(map[string]time.Time),
+		slowClusters:   make(map[string]time.Time),
+	}
+	for name, c := range clients {
+		client.clients[name] = c
+	}
+	for name, c := range dynamicClients {
+		client.dynamicClients[name] = c
+	}
+	return client
+}
+
 // detectInClusterName tries to determine a friendly name for the local cluster.
 // Priority: CLUSTER_NAME env var > OpenShift Infrastructure resource > "in-cluster".
 func detectInClusterName(cfg *rest.Config) string {
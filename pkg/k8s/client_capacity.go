@@ -0,0 +1,224 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Workload kinds GetSchedulingSnapshot can read a pod template from.
+const (
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindStatefulSet = "StatefulSet"
+)
+
+// activePodsFieldSelector skips pods that no longer hold resources on a node.
+const activePodsFieldSelector = "status.phase!=Succeeded,status.phase!=Failed"
+
+// ResourceAmounts is a set of schedulable quantities: CPU in millicores,
+// memory in bytes, a pod count, and accelerators keyed by resource name.
+type ResourceAmounts struct {
+	CPUMillis    int64            `json:"cpuMillis"`
+	MemoryBytes  int64            `json:"memoryBytes"`
+	Pods         int64            `json:"pods"`
+	Accelerators map[string]int64 `json:"accelerators,omitempty"`
+}
+
+// Add accumulates o into r.
+func (r *ResourceAmounts) Add(o ResourceAmounts) {
+	r.CPUMillis += o.CPUMillis
+	r.MemoryBytes += o.MemoryBytes
+	r.Pods += o.Pods
+	for name, n := range o.Accelerators {
+		if r.Accelerators == nil {
+			r.Accelerators = make(map[string]int64)
+		}
+		r.Accelerators[name] += n
+	}
+}
+
+// Sub returns r minus o. Accelerators r does not have go negative.
+func (r ResourceAmounts) Sub(o ResourceAmounts) ResourceAmounts {
+	out := ResourceAmounts{
+		CPUMillis:   r.CPUMillis - o.CPUMillis,
+		MemoryBytes: r.MemoryBytes - o.MemoryBytes,
+		Pods:        r.Pods - o.Pods,
+	}
+	for name, n := range r.Accelerators {
+		if out.Accelerators == nil {
+			out.Accelerators = make(map[string]int64)
+		}
+		out.Accelerators[name] = n
+	}
+	for name, n := range o.Accelerators {
+		if out.Accelerators == nil {
+			out.Accelerators = make(map[string]int64)
+		}
+		out.Accelerators[name] -= n
+	}
+	return out
+}
+
+// NodeCapacity is what one node offers to the scheduler and what the pods
+// bound to it already request.
+type NodeCapacity struct {
+	Name          string            `json:"name"`
+	Ready         bool              `json:"ready"`
+	Unschedulable bool              `json:"unschedulable"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Taints        []corev1.Taint    `json:"taints,omitempty"`
+	Allocatable   ResourceAmounts   `json:"allocatable"`
+	Requested     ResourceAmounts   `json:"requested"`
+}
+
+// SchedulingSnapshot is the input to a what-if scheduling simulation: a
+// workload's pod template and the nodes of its cluster. The workload's own
+// pods are left out of each node's Requested so the simulation can place the
+// proposed replicas in their stead.
+type SchedulingSnapshot struct {
+	Kind      string         `json:"kind"`
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Replicas  int32          `json:"replicas"`
+	Template  corev1.PodSpec `json:"-"`
+	// ExcludedPods is how many running pods of the workload were left out.
+	ExcludedPods int            `json:"excludedPods"`
+	Nodes        []NodeCapacity `json:"nodes"`
+}
+
+// GetSchedulingSnapshot reads the pod template of a Deployment or
+// StatefulSet together with the allocatable and requested resources of every
+// node in the cluster. A missing workload returns the API server's NotFound
+// error.
+func (m *MultiClusterClient) GetSchedulingSnapshot(ctx context.Context, contextName, kind, namespace, name string) (*SchedulingSnapshot, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &SchedulingSnapshot{Kind: kind, Namespace: namespace, Name: name}
+	var selector *metav1.LabelSelector
+	switch kind {
+	case WorkloadKindDeployment:
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		snap.Template, selector = d.Spec.Template.Spec, d.Spec.Selector
+		snap.Replicas = 1
+		if d.Spec.Replicas != nil {
+			snap.Replicas = *d.Spec.Replicas
+		}
+	case WorkloadKindStatefulSet:
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		snap.Template, selector = s.Spec.Template.Spec, s.Spec.Selector
+		snap.Replicas = 1
+		if s.Spec.Replicas != nil {
+			snap.Replicas = *s.Spec.Replicas
+		}
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+	own, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("workload selector: %w", err)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: activePodsFieldSelector})
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]ResourceAmounts, len(nodes.Items))
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		if p.Namespace == namespace && !own.Empty() && own.Matches(labels.Set(p.Labels)) {
+			snap.ExcludedPods++
+			continue
+		}
+		r := requested[p.Spec.NodeName]
+		r.Add(PodRequests(&p.Spec))
+		requested[p.Spec.NodeName] = r
+	}
+
+	snap.Nodes = make([]NodeCapacity, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nc := NodeCapacity{
+			Name:          node.Name,
+			Unschedulable: node.Spec.Unschedulable,
+			Labels:        node.Labels,
+			Taints:        node.Spec.Taints,
+			Allocatable:   amountsOf(node.Status.Allocatable),
+			Requested:     requested[node.Name],
+		}
+		if pods, ok := node.Status.Allocatable[corev1.ResourcePods]; ok {
+			nc.Allocatable.Pods = pods.Value()
+		}
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady {
+				nc.Ready = cond.Status == corev1.ConditionTrue
+			}
+		}
+		snap.Nodes = append(snap.Nodes, nc)
+	}
+	return snap, nil
+}
+
+// PodRequests returns what the scheduler reserves for a pod: the larger of
+// the summed container requests and the largest init container request, plus
+// the pod overhead. Pods is always 1.
+func PodRequests(spec *corev1.PodSpec) ResourceAmounts {
+	var sum ResourceAmounts
+	for _, c := range spec.Containers {
+		sum.Add(amountsOf(c.Resources.Requests))
+	}
+	for _, c := range spec.InitContainers {
+		init := amountsOf(c.Resources.Requests)
+		sum.CPUMillis = max(sum.CPUMillis, init.CPUMillis)
+		sum.MemoryBytes = max(sum.MemoryBytes, init.MemoryBytes)
+		for name, n := range init.Accelerators {
+			if n > sum.Accelerators[name] {
+				if sum.Accelerators == nil {
+					sum.Accelerators = make(map[string]int64)
+				}
+				sum.Accelerators[name] = n
+			}
+		}
+	}
+	sum.Add(amountsOf(spec.Overhead))
+	sum.Pods = 1
+	return sum
+}
+
+// amountsOf converts the CPU, memory and accelerator entries of rl.
+func amountsOf(rl corev1.ResourceList) ResourceAmounts {
+	var r ResourceAmounts
+	if cpu, ok := rl[corev1.ResourceCPU]; ok {
+		r.CPUMillis = cpu.MilliValue()
+	}
+	if mem, ok := rl[corev1.ResourceMemory]; ok {
+		r.MemoryBytes = mem.Value()
+	}
+	for _, name := range GPUResourceNames {
+		if qty, ok := rl[name]; ok && qty.Value() > 0 {
+			if r.Accelerators == nil {
+				r.Accelerators = make(map[string]int64)
+			}
+			r.Accelerators[string(name)] = qty.Value()
+		}
+	}
+	return r
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func capacityPod(namespace, name, node string, labels map[string]string, cpu, mem string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(mem),
+				}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestGetSchedulingSnapshot(t *testing.T) {
+	replicas := int32(2)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Template: corev1.PodTemplateSpec{Spec: capacityPod("", "", "", nil, "500m", "1Gi").Spec},
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"zone": "a"}},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:                    resource.MustParse("4"),
+				corev1.ResourceMemory:                 resource.MustParse("8Gi"),
+				corev1.ResourcePods:                   resource.MustParse("110"),
+				corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	ownPod := capacityPod("shop", "api-1", "node-a", map[string]string{"app": "api"}, "500m", "1Gi")
+	sameLabelsOtherNS := capacityPod("other", "api-1", "node-a", map[string]string{"app": "api"}, "1", "2Gi")
+	pending := capacityPod("shop", "web-1", "", nil, "3", "1Gi")
+
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset(deploy, node, ownPod, sameLabelsOtherNS, pending))
+
+	snap, err := client.GetSchedulingSnapshot(context.Background(), "c1", WorkloadKindDeployment, "shop", "api")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), snap.Replicas)
+	assert.Equal(t, 1, snap.ExcludedPods, "only the workload's own pods are excluded")
+	require.Len(t, snap.Nodes, 1)
+
+	n := snap.Nodes[0]
+	assert.True(t, n.Ready)
+	assert.Equal(t, int64(4000), n.Allocatable.CPUMillis)
+	assert.Equal(t, int64(110), n.Allocatable.Pods)
+	assert.Equal(t, map[string]int64{"nvidia.com/gpu": 2}, n.Allocatable.Accelerators)
+	assert.Equal(t, int64(1000), n.Requested.CPUMillis, "pending pods hold no node resources")
+	assert.Equal(t, int64(1), n.Requested.Pods)
+	assert.Equal(t, int64(500), PodRequests(&snap.Template).CPUMillis)
+}
+
+func TestGetSchedulingSnapshot_Errors(t *testing.T) {
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset())
+
+	_, err := client.GetSchedulingSnapshot(context.Background(), "c1", WorkloadKindStatefulSet, "shop", "db")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	_, err = client.GetSchedulingSnapshot(context.Background(), "c1", "DaemonSet", "shop", "agent")
+	assert.ErrorContains(t, err, "unsupported workload kind")
+}
+
+func TestPodRequests_InitContainersAndOverhead(t *testing.T) {
+	spec := capacityPod("", "", "", nil, "200m", "256Mi").Spec
+	spec.Containers = append(spec.Containers, spec.Containers[0])
+	spec.InitContainers = []corev1.Container{{
+		Name: "migrate",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		}},
+	}}
+	spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")}
+
+	r := PodRequests(&spec)
+	assert.Equal(t, int64(1050), r.CPUMillis, "largest init container wins, plus overhead")
+	assert.Equal(t, int64(512*1024*1024), r.MemoryBytes, "summed containers win")
+	assert.Equal(t, int64(1), r.Pods)
+}
+
+func TestResourceAmounts_Sub(t *testing.T) {
+	a := ResourceAmounts{CPUMillis: 1000, Accelerators: map[string]int64{"nvidia.com/gpu": 2}}
+	b := ResourceAmounts{CPUMillis: 250, Accelerators: map[string]int64{"amd.com/gpu": 1}}
+	d := a.Sub(b)
+	assert.Equal(t, int64(750), d.CPUMillis)
+	assert.Equal(t, map[string]int64{"nvidia.com/gpu": 2, "amd.com/gpu": -1}, d.Accelerators)
+	assert.Equal(t, map[string]int64{"nvidia.com/gpu": 2}, a.Accelerators, "receiver is not modified")
+}
@@ -0,0 +1,224 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/kubestellar/console/deploy/crds"
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// States of a console CRD reported by ConsoleCRDStatus and
+// InstallConsoleCRDs.
+const (
+	// ConsoleCRDPresent means the cluster already serves the resource.
+	ConsoleCRDPresent = "present"
+	// ConsoleCRDMissing means the cluster does not serve the resource.
+	ConsoleCRDMissing = "missing"
+	// ConsoleCRDInstalled means the CRD was created and is established.
+	ConsoleCRDInstalled = "installed"
+	// ConsoleCRDPending means the CRD exists but was not established before
+	// InstallConsoleCRDs stopped waiting; it usually is moments later.
+	ConsoleCRDPending = "pending"
+	// ConsoleCRDFailed means the CRD could not be created; Error says why.
+	ConsoleCRDFailed = "failed"
+)
+
+// consoleCRDEstablishTimeout bounds how long InstallConsoleCRDs waits for
+// the CRDs it created to be established, and consoleCRDPollInterval is how
+// often it checks. Vars so tests can shorten them.
+var (
+	consoleCRDEstablishTimeout = 30 * time.Second
+	consoleCRDPollInterval     = 500 * time.Millisecond
+)
+
+// crdManifestReadBuffer is the decoder's lookahead for YAML-vs-JSON sniffing.
+const crdManifestReadBuffer = 4096
+
+// ConsoleCRD is the install state of one console.kubestellar.io CRD on a
+// cluster.
+type ConsoleCRD struct {
+	// Name is the CRD name, such as managedworkloads.console.kubestellar.io.
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// consoleCRDManifest is an embedded CRD manifest with the fields the
+// bootstrap needs pulled out.
+type consoleCRDManifest struct {
+	obj    *unstructured.Unstructured
+	kind   string
+	plural string
+}
+
+// loadConsoleCRDs decodes the CRD manifests embedded from deploy/crds,
+// sorted by name.
+func loadConsoleCRDs() ([]consoleCRDManifest, error) {
+	files, err := fs.Glob(crds.FS, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var manifests []consoleCRDManifest
+	for _, file := range files {
+		data, err := fs.ReadFile(crds.FS, file)
+		if err != nil {
+			return nil, err
+		}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), crdManifestReadBuffer)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("decoding %s: %w", file, err)
+			}
+			if obj.GetKind() != "CustomResourceDefinition" || obj.GetName() == "" {
+				continue
+			}
+			kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+			plural, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "plural")
+			manifests = append(manifests, consoleCRDManifest{obj: &obj, kind: kind, plural: plural})
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].obj.GetName() < manifests[j].obj.GetName() })
+	return manifests, nil
+}
+
+// servedConsoleResources returns the console.kubestellar.io/v1alpha1
+// resources the cluster serves, by plural name. A cluster without the
+// group serves none.
+func servedConsoleResources(disc discovery.DiscoveryInterface) (map[string]bool, error) {
+	served := make(map[string]bool)
+	list, err := disc.ServerResourcesForGroupVersion(v1alpha1.GroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return served, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range list.APIResources {
+		if !strings.Contains(r.Name, "/") {
+			served[r.Name] = true
+		}
+	}
+	return served, nil
+}
+
+// ConsoleCRDStatus reports, via discovery, which of the console's CRDs the
+// cluster serves. Each is ConsoleCRDPresent or ConsoleCRDMissing.
+func (m *MultiClusterClient) ConsoleCRDStatus(contextName string) ([]ConsoleCRD, error) {
+	statuses, _, err := m.consoleCRDStatus(contextName)
+	return statuses, err
+}
+
+func (m *MultiClusterClient) consoleCRDStatus(contextName string) ([]ConsoleCRD, []consoleCRDManifest, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifests, err := loadConsoleCRDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	served, err := servedConsoleResources(client.Discovery())
+	if err != nil {
+		return nil, nil, err
+	}
+	statuses := make([]ConsoleCRD, len(manifests))
+	for i, crd := range manifests {
+		statuses[i] = ConsoleCRD{Name: crd.obj.GetName(), Kind: crd.kind, State: ConsoleCRDMissing}
+		if served[crd.plural] {
+			statuses[i].State = ConsoleCRDPresent
+		}
+	}
+	return statuses, manifests, nil
+}
+
+// InstallConsoleCRDs creates the console CRDs the cluster is missing from
+// the embedded manifests and waits for them to be established. CRDs the
+// cluster already serves are left alone, even if they are older, so an
+// install never rewrites a schema someone manages by other means. The
+// result has one entry per CRD; a failure to create one does not stop the
+// others.
+func (m *MultiClusterClient) InstallConsoleCRDs(ctx context.Context, contextName string) ([]ConsoleCRD, error) {
+	statuses, manifests, err := m.consoleCRDStatus(contextName)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	api := dyn.Resource(gvrCRDs)
+
+	var created []int
+	for i, crd := range manifests {
+		if statuses[i].State == ConsoleCRDPresent {
+			continue
+		}
+		_, err := api.Create(ctx, crd.obj.DeepCopy(), metav1.CreateOptions{})
+		// A CRD that exists but is not served yet is still being set up,
+		// so it is waited for like one created here.
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			statuses[i].State = ConsoleCRDFailed
+			statuses[i].Error = err.Error()
+			continue
+		}
+		statuses[i].State = ConsoleCRDPending
+		created = append(created, i)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, consoleCRDEstablishTimeout)
+	defer cancel()
+	for _, i := range created {
+		if waitCRDEstablished(waitCtx, api, statuses[i].Name) {
+			statuses[i].State = ConsoleCRDInstalled
+		}
+	}
+	return statuses, nil
+}
+
+// waitCRDEstablished polls the named CRD until it is established, reporting
+// false if ctx ends first.
+func waitCRDEstablished(ctx context.Context, api dynamic.ResourceInterface, name string) bool {
+	for {
+		got, err := api.Get(ctx, name, metav1.GetOptions{})
+		if err == nil && crdEstablished(got) {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(consoleCRDPollInterval):
+		}
+	}
+}
+
+// crdEstablished reports whether the CRD's Established condition is true.
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == "Established" {
+			return cond["status"] == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// consoleCRDClient serves the given console resources via discovery. The
+// apiserver establishes created CRDs at once, except that creating
+// workloaddeployments is forbidden.
+func consoleCRDClient(t *testing.T, served ...string) (*MultiClusterClient, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	typed := k8sfake.NewSimpleClientset()
+	resources := &metav1.APIResourceList{GroupVersion: v1alpha1.GroupVersion.String()}
+	for _, name := range served {
+		resources.APIResources = append(resources.APIResources, metav1.APIResource{Name: name}, metav1.APIResource{Name: name + "/status"})
+	}
+	if len(served) > 0 {
+		typed.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{resources}
+	}
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvrCRDs: "CustomResourceDefinitionList"})
+	dyn.PrependReactor("create", "customresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		crd := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if crd.GetName() == "workloaddeployments.console.kubestellar.io" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}, crd.GetName(), nil)
+		}
+		_ = unstructured.SetNestedSlice(crd.Object, []interface{}{
+			map[string]interface{}{"type": "Established", "status": "True"},
+		}, "status", "conditions")
+		return false, nil, nil
+	})
+
+	client := &MultiClusterClient{}
+	client.SetClient("c1", typed)
+	client.SetDynamicClient("c1", dyn)
+	return client, dyn
+}
+
+func crdStates(crds []ConsoleCRD) map[string]string {
+	states := make(map[string]string)
+	for _, crd := range crds {
+		states[crd.Kind] = crd.State
+	}
+	return states
+}
+
+func TestConsoleCRDStatus(t *testing.T) {
+	client, _ := consoleCRDClient(t, "managedworkloads")
+	crds, err := client.ConsoleCRDStatus("c1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ClusterGroup":       ConsoleCRDMissing,
+		"ManagedWorkload":    ConsoleCRDPresent,
+		"WorkloadDeployment": ConsoleCRDMissing,
+	}, crdStates(crds))
+
+	client, _ = consoleCRDClient(t)
+	crds, err = client.ConsoleCRDStatus("c1")
+	require.NoError(t, err, "a cluster without the group serves none of the CRDs")
+	assert.Len(t, crds, 3)
+	for _, crd := range crds {
+		assert.Equal(t, ConsoleCRDMissing, crd.State, crd.Name)
+	}
+}
+
+func TestInstallConsoleCRDs(t *testing.T) {
+	client, dyn := consoleCRDClient(t, "managedworkloads")
+	crds, err := client.InstallConsoleCRDs(context.Background(), "c1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ClusterGroup":       ConsoleCRDInstalled,
+		"ManagedWorkload":    ConsoleCRDPresent,
+		"WorkloadDeployment": ConsoleCRDFailed,
+	}, crdStates(crds))
+	for _, crd := range crds {
+		if crd.State == ConsoleCRDFailed {
+			assert.Contains(t, crd.Error, "forbidden")
+		}
+	}
+
+	_, err = dyn.Resource(gvrCRDs).Get(context.Background(), "managedworkloads.console.kubestellar.io", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "a served CRD is not rewritten")
+	created, err := dyn.Resource(gvrCRDs).Get(context.Background(), "clustergroups.console.kubestellar.io", metav1.GetOptions{})
+	require.NoError(t, err)
+	group, _, _ := unstructured.NestedString(created.Object, "spec", "group")
+	assert.Equal(t, v1alpha1.Group, group)
+}
+
+func TestInstallConsoleCRDs_Pending(t *testing.T) {
+	oldTimeout, oldInterval := consoleCRDEstablishTimeout, consoleCRDPollInterval
+	consoleCRDEstablishTimeout, consoleCRDPollInterval = 20*time.Millisecond, time.Millisecond
+	t.Cleanup(func() { consoleCRDEstablishTimeout, consoleCRDPollInterval = oldTimeout, oldInterval })
+
+	client, dyn := consoleCRDClient(t)
+	// Already created by someone else and not established yet.
+	stale := &unstructured.Unstructured{}
+	stale.SetAPIVersion("apiextensions.k8s.io/v1")
+	stale.SetKind("CustomResourceDefinition")
+	stale.SetName("clustergroups.console.kubestellar.io")
+	_, err := dyn.Resource(gvrCRDs).Create(context.Background(), stale.DeepCopy(), metav1.CreateOptions{})
+	require.NoError(t, err)
+	dyn.PrependReactor("get", "customresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.GetAction).GetName() == stale.GetName() {
+			return true, stale.DeepCopy(), nil
+		}
+		return false, nil, nil
+	})
+
+	crds, err := client.InstallConsoleCRDs(context.Background(), "c1")
+	require.NoError(t, err)
+	assert.Equal(t, ConsoleCRDPending, crdStates(crds)["ClusterGroup"])
+	assert.Equal(t, ConsoleCRDInstalled, crdStates(crds)["ManagedWorkload"])
+}
@@ -0,0 +1,312 @@
+package k8s
+
+// Debug resources are short-lived troubleshooting pods and jobs started from
+// a fixed set of templates. Everything needed to garbage-collect them lives
+// on the objects themselves, so a console restart does not orphan any.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// DebugResourceLabel marks an object created as a debug resource.
+	DebugResourceLabel = "kubestellar.io/debug-resource"
+	// DebugOwnerLabel holds the label-safe login of the user who created a
+	// debug resource.
+	DebugOwnerLabel = "kubestellar.io/debug-owner"
+	// DebugTemplateLabel names the template a debug resource came from.
+	DebugTemplateLabel = "kubestellar.io/debug-template"
+	// DebugExpiresAnnotation is the RFC 3339 time after which a debug
+	// resource is deleted.
+	DebugExpiresAnnotation = "kubestellar.io/debug-expires-at"
+)
+
+// Debug resource templates.
+const (
+	DebugTemplateNetshoot = "netshoot"
+	DebugTemplateBusybox  = "busybox"
+)
+
+// debugJobTTLAfterFinished lets the Job controller clean up a finished Job
+// even if the console is not running when it expires.
+const debugJobTTLAfterFinished = int32(60)
+
+// ErrNotDebugResource is returned when asked to delete an object that was not
+// created as a debug resource.
+var ErrNotDebugResource = errors.New("not a debug resource")
+
+// DebugTemplate is a kind of debug resource users can create.
+type DebugTemplate struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Image       string `json:"image"`
+	Description string `json:"description"`
+}
+
+// DebugTemplates lists the templates CreateDebugResource accepts.
+var DebugTemplates = []DebugTemplate{
+	{Name: DebugTemplateNetshoot, Kind: "Pod", Image: "nicolaka/netshoot:v0.13", Description: "Network troubleshooting pod (dig, curl, tcpdump, iperf)"},
+	{Name: DebugTemplateBusybox, Kind: "Job", Image: "busybox:1.36", Description: "Minimal shell in a Job that exits when its TTL ends"},
+}
+
+// LookupDebugTemplate returns the named template.
+func LookupDebugTemplate(name string) (DebugTemplate, bool) {
+	for _, t := range DebugTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return DebugTemplate{}, false
+}
+
+// DebugResourceSpec describes a debug resource to create.
+type DebugResourceSpec struct {
+	Template  string
+	Namespace string
+	// Owner is the login of the requesting user; it is stored as
+	// DebugOwnerLabel after DebugOwnerLabelValue.
+	Owner string
+	TTL   time.Duration
+}
+
+// DebugResource is a debug pod or job found on a cluster.
+type DebugResource struct {
+	Cluster   string    `json:"cluster"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Template  string    `json:"template"`
+	Owner     string    `json:"owner"`
+	Phase     string    `json:"phase,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is zero when the annotation is missing or malformed; such
+	// resources are treated as already expired.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Expired reports whether the resource should be garbage-collected at now.
+func (r DebugResource) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// DebugOwnerLabelValue turns a login into a label value: lowercase, only
+// alphanumerics, '-', '_' and '.', at most 63 characters.
+func DebugOwnerLabelValue(login string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(login) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+	v := b.String()
+	if len(v) > 63 {
+		v = v[:63]
+	}
+	return strings.Trim(v, "-_.")
+}
+
+// CreateDebugResource creates a debug pod or job from a template. The pod
+// sleeps for the TTL and has an active deadline of the TTL, so it stops even
+// if nothing deletes it.
+func (m *MultiClusterClient) CreateDebugResource(ctx context.Context, contextName string, spec DebugResourceSpec) (*DebugResource, error) {
+	tmpl, ok := LookupDebugTemplate(spec.Template)
+	if !ok {
+		return nil, fmt.Errorf("unknown debug template %q", spec.Template)
+	}
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	expires := now.Add(spec.TTL)
+	seconds := int64(spec.TTL.Seconds())
+	noGrace, noToken, noEscalation := int64(0), false, false
+	noRetries, finishedTTL := int32(0), debugJobTTLAfterFinished
+	meta := metav1.ObjectMeta{
+		Name:      fmt.Sprintf("debug-%s-%s", tmpl.Name, rand.String(5)),
+		Namespace: spec.Namespace,
+		Labels: map[string]string{
+			DebugResourceLabel: "true",
+			DebugOwnerLabel:    DebugOwnerLabelValue(spec.Owner),
+			DebugTemplateLabel: tmpl.Name,
+		},
+		Annotations: map[string]string{DebugExpiresAnnotation: expires.Format(time.RFC3339)},
+	}
+	podSpec := corev1.PodSpec{
+		RestartPolicy:                 corev1.RestartPolicyNever,
+		ActiveDeadlineSeconds:         &seconds,
+		TerminationGracePeriodSeconds: &noGrace,
+		AutomountServiceAccountToken:  &noToken,
+		Containers: []corev1.Container{{
+			Name:    tmpl.Name,
+			Image:   tmpl.Image,
+			Command: []string{"sleep", fmt.Sprint(seconds)},
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: &noEscalation,
+			},
+		}},
+	}
+
+	res := &DebugResource{
+		Cluster: contextName, Kind: tmpl.Kind, Namespace: spec.Namespace, Name: meta.Name,
+		Template: tmpl.Name, Owner: meta.Labels[DebugOwnerLabel], CreatedAt: now, ExpiresAt: expires,
+	}
+	switch tmpl.Kind {
+	case "Job":
+		job := &batchv1.Job{ObjectMeta: meta, Spec: batchv1.JobSpec{
+			BackoffLimit:            &noRetries,
+			ActiveDeadlineSeconds:   &seconds,
+			TTLSecondsAfterFinished: &finishedTTL,
+			Template:                corev1.PodTemplateSpec{Spec: podSpec},
+		}}
+		if _, err := client.BatchV1().Jobs(spec.Namespace).Create(ctx, job, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	default:
+		pod := &corev1.Pod{ObjectMeta: meta, Spec: podSpec}
+		if _, err := client.CoreV1().Pods(spec.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// ListDebugResources returns the debug resources on a cluster, optionally
+// only those whose owner label is owner.
+func (m *MultiClusterClient) ListDebugResources(ctx context.Context, contextName, owner string) ([]DebugResource, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	selector := DebugResourceLabel + "=true"
+	if owner != "" {
+		selector += "," + DebugOwnerLabel + "=" + owner
+	}
+	opts := metav1.ListOptions{LabelSelector: selector}
+
+	pods, err := client.CoreV1().Pods("").List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := client.BatchV1().Jobs("").List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]DebugResource, 0, len(pods.Items)+len(jobs.Items))
+	for _, pod := range pods.Items {
+		r := debugResourceFromMeta(contextName, "Pod", pod.ObjectMeta)
+		r.Phase = string(pod.Status.Phase)
+		out = append(out, r)
+	}
+	for _, job := range jobs.Items {
+		r := debugResourceFromMeta(contextName, "Job", job.ObjectMeta)
+		switch {
+		case job.Status.Succeeded > 0:
+			r.Phase = "Succeeded"
+		case job.Status.Failed > 0:
+			r.Phase = "Failed"
+		case job.Status.Active > 0:
+			r.Phase = "Running"
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func debugResourceFromMeta(cluster, kind string, meta metav1.ObjectMeta) DebugResource {
+	expires, _ := time.Parse(time.RFC3339, meta.Annotations[DebugExpiresAnnotation])
+	return DebugResource{
+		Cluster:   cluster,
+		Kind:      kind,
+		Namespace: meta.Namespace,
+		Name:      meta.Name,
+		Template:  meta.Labels[DebugTemplateLabel],
+		Owner:     meta.Labels[DebugOwnerLabel],
+		CreatedAt: meta.CreationTimestamp.Time,
+		ExpiresAt: expires,
+	}
+}
+
+// GetDebugResource returns one debug resource. ErrNotDebugResource is
+// returned when the object exists but is not a debug resource, so callers
+// cannot be used to reach arbitrary pods or jobs.
+func (m *MultiClusterClient) GetDebugResource(ctx context.Context, contextName, kind, namespace, name string) (*DebugResource, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	var meta metav1.ObjectMeta
+	switch kind {
+	case "Pod":
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		meta = pod.ObjectMeta
+	case "Job":
+		job, err := client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		meta = job.ObjectMeta
+	default:
+		return nil, fmt.Errorf("%w: unsupported kind %q", ErrNotDebugResource, kind)
+	}
+	if meta.Labels[DebugResourceLabel] != "true" {
+		return nil, fmt.Errorf("%w: %s %s/%s", ErrNotDebugResource, kind, namespace, name)
+	}
+	r := debugResourceFromMeta(contextName, kind, meta)
+	return &r, nil
+}
+
+// DeleteDebugResource deletes a debug pod or job, and a job's pods with it.
+// Objects that are not debug resources are refused with ErrNotDebugResource.
+func (m *MultiClusterClient) DeleteDebugResource(ctx context.Context, contextName, kind, namespace, name string) error {
+	if _, err := m.GetDebugResource(ctx, contextName, kind, namespace, name); err != nil {
+		return err
+	}
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return err
+	}
+	propagation := metav1.DeletePropagationBackground
+	opts := metav1.DeleteOptions{PropagationPolicy: &propagation}
+	if kind == "Job" {
+		return client.BatchV1().Jobs(namespace).Delete(ctx, name, opts)
+	}
+	return client.CoreV1().Pods(namespace).Delete(ctx, name, opts)
+}
+
+// SweepExpiredDebugResources deletes the debug resources on a cluster that
+// expired at or before now and returns how many were deleted. A failed
+// delete is returned after the remaining resources have been tried.
+func (m *MultiClusterClient) SweepExpiredDebugResources(ctx context.Context, contextName string, now time.Time) (int, error) {
+	resources, err := m.ListDebugResources(ctx, contextName, "")
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	var errs []error
+	for _, r := range resources {
+		if !r.Expired(now) {
+			continue
+		}
+		if err := m.DeleteDebugResource(ctx, contextName, r.Kind, r.Namespace, r.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s/%s: %w", r.Kind, r.Namespace, r.Name, err))
+			continue
+		}
+		deleted++
+	}
+	return deleted, errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDebugOwnerLabelValue(t *testing.T) {
+	assert.Equal(t, "octocat", DebugOwnerLabelValue("OctoCat"))
+	assert.Equal(t, "devuser", DebugOwnerLabelValue("dev user@"))
+	assert.Equal(t, "a", DebugOwnerLabelValue("-a-"))
+}
+
+func TestCreateDebugResource(t *testing.T) {
+	cs := k8sfake.NewSimpleClientset()
+	client := &MultiClusterClient{}
+	client.SetClient("c1", cs)
+	ctx := context.Background()
+
+	pod, err := client.CreateDebugResource(ctx, "c1", DebugResourceSpec{Template: DebugTemplateNetshoot, Namespace: "shop", Owner: "OctoCat", TTL: 10 * time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, "Pod", pod.Kind)
+	assert.Equal(t, "octocat", pod.Owner)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), pod.ExpiresAt, time.Minute)
+
+	created, err := cs.CoreV1().Pods("shop").Get(ctx, pod.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", created.Labels[DebugResourceLabel])
+	assert.Equal(t, int64(600), *created.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, []string{"sleep", "600"}, created.Spec.Containers[0].Command)
+
+	job, err := client.CreateDebugResource(ctx, "c1", DebugResourceSpec{Template: DebugTemplateBusybox, Namespace: "shop", Owner: "hubot", TTL: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, "Job", job.Kind)
+
+	_, err = client.CreateDebugResource(ctx, "c1", DebugResourceSpec{Template: "kali", Namespace: "shop", TTL: time.Minute})
+	assert.Error(t, err)
+
+	all, err := client.ListDebugResources(ctx, "c1", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	mine, err := client.ListDebugResources(ctx, "c1", "octocat")
+	require.NoError(t, err)
+	require.Len(t, mine, 1)
+	assert.Equal(t, pod.Name, mine[0].Name)
+}
+
+func TestDebugResourceGuardsAndSweep(t *testing.T) {
+	expired := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "shop", Name: "debug-netshoot-old",
+		Labels:      map[string]string{DebugResourceLabel: "true", DebugOwnerLabel: "octocat"},
+		Annotations: map[string]string{DebugExpiresAnnotation: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)},
+	}}
+	live := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "shop", Name: "debug-netshoot-new",
+		Labels:      map[string]string{DebugResourceLabel: "true", DebugOwnerLabel: "octocat"},
+		Annotations: map[string]string{DebugExpiresAnnotation: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)},
+	}}
+	unmarked := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "shop", Name: "debug-netshoot-bad",
+		Labels: map[string]string{DebugResourceLabel: "true"},
+	}}
+	app := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"}}
+
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset(expired, live, unmarked, app))
+	ctx := context.Background()
+
+	err := client.DeleteDebugResource(ctx, "c1", "Pod", "shop", "api")
+	assert.True(t, errors.Is(err, ErrNotDebugResource), "err = %v", err)
+
+	deleted, err := client.SweepExpiredDebugResources(ctx, "c1", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted, "resources without a valid expiry are treated as expired")
+
+	left, err := client.ListDebugResources(ctx, "c1", "")
+	require.NoError(t, err)
+	require.Len(t, left, 1)
+	assert.Equal(t, "debug-netshoot-new", left[0].Name)
+}
@@ -0,0 +1,591 @@
+package k8s
+
+// Cluster diagnostics run a fixed suite of checks for "this cluster looks
+// unhealthy" triage: API server latency and clock skew from the console, and
+// DNS and image registry reachability from inside the cluster via a
+// short-lived pod. The pod is labelled as a debug resource, so the debug
+// resource sweeper removes it if the console stops before it does.
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/rest"
+)
+
+// Diagnostic check names.
+const (
+	DiagnosticAPILatency = "api_latency"
+	DiagnosticClockSkew  = "clock_skew"
+	DiagnosticDNS        = "dns"
+	DiagnosticRegistry   = "registry"
+)
+
+// Diagnostic check statuses.
+const (
+	DiagnosticPass    = "pass"
+	DiagnosticWarn    = "warn"
+	DiagnosticFail    = "fail"
+	DiagnosticSkipped = "skipped"
+)
+
+// Overall diagnostics report statuses.
+const (
+	DiagnosticsHealthy   = "healthy"
+	DiagnosticsDegraded  = "degraded"
+	DiagnosticsUnhealthy = "unhealthy"
+)
+
+const (
+	// diagnosticsTemplate is the DebugTemplateLabel value of diagnostics pods.
+	diagnosticsTemplate = "diagnostics"
+	// diagnosticsOwner is the DebugOwnerLabel value of diagnostics pods, so
+	// they do not count against any user's debug resource quota.
+	diagnosticsOwner = "console-diagnostics"
+	// diagnosticsImage has dig and curl.
+	diagnosticsImage = "nicolaka/netshoot:v0.13"
+	// diagnosticsPodDeadline bounds the pod's lifetime, including image pull.
+	diagnosticsPodDeadline = 3 * time.Minute
+	// diagnosticsPodWait is how long to wait for the pod to finish.
+	diagnosticsPodWait = 2 * time.Minute
+
+	// diagnosticsLatencySamples is the number of API server round trips
+	// timed; the median is reported.
+	diagnosticsLatencySamples = 3
+	apiLatencyWarn            = 300 * time.Millisecond
+	apiLatencyFail            = time.Second
+
+	// The HTTP Date header has one-second resolution, so skew below
+	// clockSkewWarn is noise.
+	clockSkewWarn = 5 * time.Second
+	clockSkewFail = 30 * time.Second
+	// nodeLeaseNamespace holds the kubelet heartbeat leases.
+	nodeLeaseNamespace = "kube-node-lease"
+
+	// maxDiagnosticsRegistries bounds the registries checked, whether given
+	// or derived from running images.
+	maxDiagnosticsRegistries = 5
+	// maxDiagnosticsDNSNames bounds the extra names resolved.
+	maxDiagnosticsDNSNames = 5
+	// diagnosticsImageScanLimit bounds the pods listed to find registries.
+	diagnosticsImageScanLimit = 500
+)
+
+// diagnosticsClusterDNSName must resolve from any pod.
+const diagnosticsClusterDNSName = "kubernetes.default.svc"
+
+// diagnosticsPollInterval is how often the diagnostics pod is polled. Tests
+// shorten it.
+var diagnosticsPollInterval = 2 * time.Second
+
+// diagnosticsScript runs inside the diagnostics pod. Names and registries
+// come from the environment so nothing user-supplied is interpolated.
+const diagnosticsScript = `for n in $DIAG_DNS_NAMES; do
+  a=$(dig +search +short +time=2 +tries=2 "$n" | grep -v '\.$' | head -n 1)
+  if [ -n "$a" ]; then echo "DNS $n ok $a"; else echo "DNS $n fail"; fi
+done
+for r in $DIAG_REGISTRIES; do
+  echo "REGISTRY $r $(curl -s -o /dev/null -m 5 -w '%{http_code}' "https://$r/v2/")"
+done
+echo DONE`
+
+// DiagnosticsOptions configures RunClusterDiagnostics.
+type DiagnosticsOptions struct {
+	// Namespace is where the diagnostics pod runs.
+	Namespace string
+	// DNSNames are resolved in addition to the cluster's own API service.
+	DNSNames []string
+	// Registries are hosts, optionally with a port, whose /v2/ endpoint is
+	// requested. When empty, the registries of running images are used.
+	Registries []string
+	// SkipPodChecks skips the DNS and registry checks, which need a pod.
+	SkipPodChecks bool
+}
+
+// DiagnosticCheck is the result of one diagnostic check.
+type DiagnosticCheck struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message"`
+	DurationMs int64                  `json:"durationMs"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// DiagnosticsReport is the scored result of RunClusterDiagnostics.
+type DiagnosticsReport struct {
+	Cluster string `json:"cluster"`
+	// Score is 0-100: a passing check counts fully, a warning half, and
+	// skipped checks are left out.
+	Score     int               `json:"score"`
+	Status    string            `json:"status"`
+	Checks    []DiagnosticCheck `json:"checks"`
+	StartedAt time.Time         `json:"startedAt"`
+	// DurationMs is the wall time of the whole run.
+	DurationMs int64 `json:"durationMs"`
+}
+
+// RunClusterDiagnostics runs the diagnostics suite against a cluster. A check
+// that cannot run is reported as failed rather than returned as an error;
+// only an unknown cluster is an error.
+func (m *MultiClusterClient) RunClusterDiagnostics(ctx context.Context, contextName string, opts DiagnosticsOptions) (*DiagnosticsReport, error) {
+	config, err := m.GetRestConfig(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	report := &DiagnosticsReport{Cluster: contextName, StartedAt: start.UTC()}
+
+	latency, skew := checkAPIServer(ctx, config)
+	report.Checks = append(report.Checks, latency)
+	report.Checks = append(report.Checks, m.checkClockSkew(ctx, contextName, skew))
+
+	if opts.SkipPodChecks {
+		report.Checks = append(report.Checks,
+			DiagnosticCheck{Name: DiagnosticDNS, Status: DiagnosticSkipped, Message: "Pod checks were skipped"},
+			DiagnosticCheck{Name: DiagnosticRegistry, Status: DiagnosticSkipped, Message: "Pod checks were skipped"},
+		)
+	} else {
+		registries := opts.Registries
+		if len(registries) == 0 {
+			registries = m.runningImageRegistries(ctx, contextName)
+		}
+		names := append([]string{diagnosticsClusterDNSName}, opts.DNSNames...)
+		dns, registry := m.runDiagnosticsPod(ctx, contextName, opts.Namespace, names, registries)
+		report.Checks = append(report.Checks, dns, registry)
+	}
+
+	report.Score, report.Status = scoreDiagnostics(report.Checks)
+	report.DurationMs = time.Since(start).Milliseconds()
+	return report, nil
+}
+
+// scoreDiagnostics returns the report score and overall status.
+func scoreDiagnostics(checks []DiagnosticCheck) (int, string) {
+	var points, counted float64
+	status := DiagnosticsHealthy
+	for _, c := range checks {
+		switch c.Status {
+		case DiagnosticPass:
+			points++
+		case DiagnosticWarn:
+			points += 0.5
+			if status == DiagnosticsHealthy {
+				status = DiagnosticsDegraded
+			}
+		case DiagnosticFail:
+			status = DiagnosticsUnhealthy
+		default:
+			continue
+		}
+		counted++
+	}
+	if counted == 0 {
+		return 0, status
+	}
+	return int(math.Round(100 * points / counted)), status
+}
+
+// apiServerSkew is the API server clock minus the console clock, measured
+// from the Date header of the fastest /version round trip.
+type apiServerSkew struct {
+	skew time.Duration
+	ok   bool
+}
+
+// checkAPIServer times /version round trips and reads the API server clock
+// from their Date headers.
+func checkAPIServer(ctx context.Context, config *rest.Config) (check DiagnosticCheck, skew apiServerSkew) {
+	check.Name = DiagnosticAPILatency
+	start := time.Now()
+	defer func() { check.DurationMs = time.Since(start).Milliseconds() }()
+
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		check.Status, check.Message = DiagnosticFail, "Failed to build API server client: "+err.Error()
+		return check, apiServerSkew{}
+	}
+	versionURL := strings.TrimSuffix(config.Host, "/") + "/version"
+
+	var samples []time.Duration
+	fastest := time.Duration(math.MaxInt64)
+	var lastErr error
+	for i := 0; i < diagnosticsLatencySamples; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, versionURL, nil)
+		if err != nil {
+			lastErr = err
+			break
+		}
+		sent := time.Now()
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rtt := time.Since(sent)
+		resp.Body.Close()
+		samples = append(samples, rtt)
+		if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil && rtt < fastest {
+			fastest = rtt
+			// Date is truncated to the second, so compare against the
+			// midpoint of the request truncated the same way.
+			skew = apiServerSkew{skew: date.Sub(sent.Add(rtt / 2).Truncate(time.Second)), ok: true}
+		}
+	}
+	if len(samples) == 0 {
+		check.Status, check.Message = DiagnosticFail, fmt.Sprintf("API server unreachable: %v", lastErr)
+		return check, apiServerSkew{}
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	median := samples[len(samples)/2]
+	check.Details = map[string]interface{}{
+		"medianMs": median.Milliseconds(),
+		"samples":  len(samples),
+		"failed":   diagnosticsLatencySamples - len(samples),
+	}
+	switch {
+	case median >= apiLatencyFail:
+		check.Status = DiagnosticFail
+	case median >= apiLatencyWarn || len(samples) < diagnosticsLatencySamples:
+		check.Status = DiagnosticWarn
+	default:
+		check.Status = DiagnosticPass
+	}
+	check.Message = fmt.Sprintf("Median API server round trip %dms", median.Milliseconds())
+	if len(samples) < diagnosticsLatencySamples {
+		check.Message += fmt.Sprintf(", %d of %d requests failed", diagnosticsLatencySamples-len(samples), diagnosticsLatencySamples)
+	}
+	return check, skew
+}
+
+// checkClockSkew reports the API server's clock against the console's and
+// nodes whose kubelet lease renewals are stamped ahead of the API server
+// clock. A node running behind shows up as a stale lease, which the node
+// controller already reports as NotReady, so only nodes ahead are flagged.
+func (m *MultiClusterClient) checkClockSkew(ctx context.Context, contextName string, api apiServerSkew) (check DiagnosticCheck) {
+	check = DiagnosticCheck{Name: DiagnosticClockSkew, Status: DiagnosticPass, Details: map[string]interface{}{}}
+	start := time.Now()
+	defer func() { check.DurationMs = time.Since(start).Milliseconds() }()
+
+	var worst time.Duration
+	var messages []string
+	if api.ok {
+		check.Details["apiServerSkewSeconds"] = api.skew.Seconds()
+		worst = absDuration(api.skew)
+		if worst >= clockSkewWarn {
+			messages = append(messages, fmt.Sprintf("API server clock is %s off the console's", formatSkew(api.skew)))
+		}
+	} else {
+		messages = append(messages, "API server clock could not be read")
+	}
+
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		check.Status, check.Message = DiagnosticFail, err.Error()
+		return check
+	}
+	leases, err := client.CoordinationV1().Leases(nodeLeaseNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		messages = append(messages, "node leases could not be read")
+	} else {
+		apiNow := time.Now().Add(api.skew)
+		ahead := map[string]float64{}
+		for _, lease := range leases.Items {
+			if lease.Spec.RenewTime == nil {
+				continue
+			}
+			d := lease.Spec.RenewTime.Sub(apiNow)
+			if d < clockSkewWarn {
+				continue
+			}
+			ahead[lease.Name] = math.Round(d.Seconds())
+			if d > worst {
+				worst = d
+			}
+		}
+		check.Details["nodesChecked"] = len(leases.Items)
+		if len(ahead) > 0 {
+			check.Details["nodesAheadSeconds"] = ahead
+			messages = append(messages, fmt.Sprintf("%d node(s) have clocks ahead of the API server", len(ahead)))
+		}
+	}
+
+	switch {
+	case worst >= clockSkewFail:
+		check.Status = DiagnosticFail
+	case worst >= clockSkewWarn || !api.ok:
+		check.Status = DiagnosticWarn
+	}
+	if len(messages) == 0 {
+		check.Message = "Clocks are in sync"
+	} else {
+		check.Message = strings.Join(messages, "; ")
+	}
+	return check
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// formatSkew formats a skew as e.g. "12s ahead" or "3s behind".
+func formatSkew(d time.Duration) string {
+	if d < 0 {
+		return fmt.Sprintf("%s behind", (-d).Round(time.Second))
+	}
+	return fmt.Sprintf("%s ahead", d.Round(time.Second))
+}
+
+// runningImageRegistries returns the most used registries among the images
+// of running pods.
+func (m *MultiClusterClient) runningImageRegistries(ctx context.Context, contextName string) []string {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil
+	}
+	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{Limit: diagnosticsImageScanLimit})
+	if err != nil {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, pod := range pods.Items {
+		for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			counts[ImageRegistryHost(c.Image)]++
+		}
+	}
+	registries := make([]string, 0, len(counts))
+	for r := range counts {
+		registries = append(registries, r)
+	}
+	sort.Slice(registries, func(i, j int) bool {
+		if counts[registries[i]] != counts[registries[j]] {
+			return counts[registries[i]] > counts[registries[j]]
+		}
+		return registries[i] < registries[j]
+	})
+	if len(registries) > maxDiagnosticsRegistries {
+		registries = registries[:maxDiagnosticsRegistries]
+	}
+	return registries
+}
+
+// ImageRegistryHost returns the registry host an image is pulled from, e.g.
+// "ghcr.io" for "ghcr.io/org/app:1" and "registry-1.docker.io" for
+// "nginx:1.27".
+func ImageRegistryHost(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") || first == "docker.io" || first == "index.docker.io" {
+		return "registry-1.docker.io"
+	}
+	return first
+}
+
+// runDiagnosticsPod runs the DNS and registry checks in a short-lived pod and
+// deletes it afterwards.
+func (m *MultiClusterClient) runDiagnosticsPod(ctx context.Context, contextName, namespace string, names, registries []string) (DiagnosticCheck, DiagnosticCheck) {
+	dns := DiagnosticCheck{Name: DiagnosticDNS}
+	registry := DiagnosticCheck{Name: DiagnosticRegistry}
+	start := time.Now()
+	fail := func(message string) (DiagnosticCheck, DiagnosticCheck) {
+		elapsed := time.Since(start).Milliseconds()
+		dns.Status, dns.Message, dns.DurationMs = DiagnosticFail, message, elapsed
+		registry.Status, registry.Message, registry.DurationMs = DiagnosticFail, message, elapsed
+		if len(registries) == 0 {
+			registry.Status, registry.Message = DiagnosticSkipped, "No registries to check"
+		}
+		return dns, registry
+	}
+
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return fail(err.Error())
+	}
+	if len(names) > maxDiagnosticsDNSNames+1 {
+		names = names[:maxDiagnosticsDNSNames+1]
+	}
+	if len(registries) > maxDiagnosticsRegistries {
+		registries = registries[:maxDiagnosticsRegistries]
+	}
+
+	deadline := int64(diagnosticsPodDeadline.Seconds())
+	noGrace, noToken, noEscalation := int64(0), false, false
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "diagnostics-" + rand.String(5),
+			Namespace: namespace,
+			Labels: map[string]string{
+				DebugResourceLabel: "true",
+				DebugOwnerLabel:    diagnosticsOwner,
+				DebugTemplateLabel: diagnosticsTemplate,
+			},
+			Annotations: map[string]string{
+				DebugExpiresAnnotation: time.Now().UTC().Add(diagnosticsPodDeadline).Format(time.RFC3339),
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds:         &deadline,
+			TerminationGracePeriodSeconds: &noGrace,
+			AutomountServiceAccountToken:  &noToken,
+			Containers: []corev1.Container{{
+				Name:    diagnosticsTemplate,
+				Image:   diagnosticsImage,
+				Command: []string{"sh", "-c", diagnosticsScript},
+				Env: []corev1.EnvVar{
+					{Name: "DIAG_DNS_NAMES", Value: strings.Join(names, " ")},
+					{Name: "DIAG_REGISTRIES", Value: strings.Join(registries, " ")},
+				},
+				SecurityContext: &corev1.SecurityContext{AllowPrivilegeEscalation: &noEscalation},
+			}},
+		},
+	}
+	pods := client.CoreV1().Pods(namespace)
+	if _, err := pods.Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return fail("Failed to start diagnostics pod: " + err.Error())
+	}
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		_ = pods.Delete(cleanupCtx, pod.Name, metav1.DeleteOptions{})
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, diagnosticsPodWait)
+	defer cancel()
+	ticker := time.NewTicker(diagnosticsPollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := pods.Get(waitCtx, pod.Name, metav1.GetOptions{})
+		if err == nil && (current.Status.Phase == corev1.PodSucceeded || current.Status.Phase == corev1.PodFailed) {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			reason := "timed out"
+			if current != nil {
+				reason = podWaitingReason(current)
+			}
+			return fail("Diagnostics pod did not finish: " + reason)
+		case <-ticker.C:
+		}
+	}
+
+	logs, err := pods.GetLogs(pod.Name, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return fail("Failed to read diagnostics pod logs: " + err.Error())
+	}
+	dns, registry = parseDiagnosticsOutput(string(logs), names, registries)
+	elapsed := time.Since(start).Milliseconds()
+	dns.DurationMs, registry.DurationMs = elapsed, elapsed
+	return dns, registry
+}
+
+// podWaitingReason describes why a pod has not finished, e.g.
+// "ImagePullBackOff" or "Pending".
+func podWaitingReason(pod *corev1.Pod) string {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.State.Waiting.Reason
+		}
+	}
+	if pod.Status.Phase != "" {
+		return string(pod.Status.Phase)
+	}
+	return "timed out"
+}
+
+// parseDiagnosticsOutput turns the diagnostics script output into the DNS
+// and registry checks. names[0] is the cluster's own API service, which must
+// resolve; other names failing is a warning.
+func parseDiagnosticsOutput(out string, names, registries []string) (DiagnosticCheck, DiagnosticCheck) {
+	resolved := map[string]string{}
+	codes := map[string]int{}
+	done := false
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 1 && fields[0] == "DONE":
+			done = true
+		case len(fields) >= 3 && fields[0] == "DNS":
+			if fields[2] == "ok" && len(fields) == 4 {
+				resolved[fields[1]] = fields[3]
+			} else {
+				resolved[fields[1]] = ""
+			}
+		case len(fields) == 3 && fields[0] == "REGISTRY":
+			code, _ := strconv.Atoi(fields[2])
+			codes[fields[1]] = code
+		}
+	}
+
+	dns := DiagnosticCheck{Name: DiagnosticDNS, Status: DiagnosticPass}
+	results := map[string]interface{}{}
+	var failed []string
+	for i, n := range names {
+		addr, ok := resolved[n]
+		results[n] = addr
+		if !ok || addr == "" {
+			failed = append(failed, n)
+			if i == 0 {
+				dns.Status = DiagnosticFail
+			} else if dns.Status == DiagnosticPass {
+				dns.Status = DiagnosticWarn
+			}
+		}
+	}
+	dns.Details = map[string]interface{}{"resolved": results}
+	if len(failed) == 0 {
+		dns.Message = fmt.Sprintf("Resolved %d name(s) from inside the cluster", len(names))
+	} else {
+		dns.Message = "Failed to resolve " + strings.Join(failed, ", ")
+	}
+
+	registry := DiagnosticCheck{Name: DiagnosticRegistry, Status: DiagnosticPass}
+	if len(registries) == 0 {
+		registry.Status, registry.Message = DiagnosticSkipped, "No registries to check"
+	} else {
+		statuses := map[string]interface{}{}
+		var unreachable []string
+		for _, r := range registries {
+			// Any HTTP response, including 401, means the registry is
+			// reachable; curl reports 000 when it is not.
+			code := codes[r]
+			statuses[r] = code
+			if code == 0 {
+				unreachable = append(unreachable, r)
+			}
+		}
+		registry.Details = map[string]interface{}{"httpStatus": statuses}
+		switch {
+		case len(unreachable) == 0:
+			registry.Message = fmt.Sprintf("%d of %d registries reachable", len(registries), len(registries))
+		case len(unreachable) == len(registries):
+			registry.Status, registry.Message = DiagnosticFail, "No registry is reachable: "+strings.Join(unreachable, ", ")
+		default:
+			registry.Status, registry.Message = DiagnosticWarn, "Unreachable: "+strings.Join(unreachable, ", ")
+		}
+	}
+
+	if !done {
+		msg := "Diagnostics pod output was incomplete"
+		if dns.Status == DiagnosticPass {
+			dns.Status = DiagnosticWarn
+		}
+		dns.Message += "; " + msg
+	}
+	return dns, registry
+}
@@ -0,0 +1,187 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func diagnosticsTestClient(t *testing.T, apiClockOffset time.Duration, objects ...runtime.Object) (*MultiClusterClient, *fake.Clientset) {
+	t.Helper()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(apiClockOffset).UTC().Format(http.TimeFormat))
+		_, _ = w.Write([]byte(`{"gitVersion":"v1.31.0"}`))
+	}))
+	t.Cleanup(apiServer.Close)
+
+	clientset := fake.NewSimpleClientset(objects...)
+	client := &MultiClusterClient{}
+	client.InjectClient("c1", clientset)
+	client.InjectRestConfig("c1", &rest.Config{Host: apiServer.URL})
+	return client, clientset
+}
+
+func nodeLease(name string, renew time.Time) *coordinationv1.Lease {
+	t := metav1.NewMicroTime(renew)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nodeLeaseNamespace},
+		Spec:       coordinationv1.LeaseSpec{RenewTime: &t},
+	}
+}
+
+func TestRunClusterDiagnostics_SkipPodChecks(t *testing.T) {
+	client, _ := diagnosticsTestClient(t, 0,
+		nodeLease("node-a", time.Now().Add(-3*time.Second)),
+		nodeLease("node-b", time.Now().Add(2*time.Minute)),
+	)
+
+	report, err := client.RunClusterDiagnostics(context.Background(), "c1", DiagnosticsOptions{SkipPodChecks: true})
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 4)
+
+	latency := report.Checks[0]
+	assert.Equal(t, DiagnosticAPILatency, latency.Name)
+	assert.Equal(t, DiagnosticPass, latency.Status, latency.Message)
+	assert.Equal(t, 3, latency.Details["samples"])
+
+	skew := report.Checks[1]
+	assert.Equal(t, DiagnosticClockSkew, skew.Name)
+	assert.Equal(t, DiagnosticFail, skew.Status, "node-b is two minutes ahead")
+	assert.Contains(t, skew.Details["nodesAheadSeconds"], "node-b")
+	assert.NotContains(t, skew.Details["nodesAheadSeconds"], "node-a")
+
+	assert.Equal(t, DiagnosticSkipped, report.Checks[2].Status)
+	assert.Equal(t, DiagnosticSkipped, report.Checks[3].Status)
+	assert.Equal(t, 50, report.Score)
+	assert.Equal(t, DiagnosticsUnhealthy, report.Status)
+}
+
+func TestRunClusterDiagnostics_APIServerSkew(t *testing.T) {
+	client, _ := diagnosticsTestClient(t, 10*time.Second)
+
+	report, err := client.RunClusterDiagnostics(context.Background(), "c1", DiagnosticsOptions{SkipPodChecks: true})
+	require.NoError(t, err)
+	skew := report.Checks[1]
+	assert.Equal(t, DiagnosticWarn, skew.Status)
+	assert.Contains(t, skew.Message, "ahead")
+	assert.InDelta(t, 10, skew.Details["apiServerSkewSeconds"], 2)
+}
+
+func TestRunClusterDiagnostics_Pod(t *testing.T) {
+	old := diagnosticsPollInterval
+	diagnosticsPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { diagnosticsPollInterval = old })
+
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "web", Image: "ghcr.io/acme/web:1"},
+			{Name: "proxy", Image: "nginx:1.27"},
+		}},
+	}
+	client, clientset := diagnosticsTestClient(t, 0, running)
+
+	var created *corev1.Pod
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		created = action.(k8stesting.CreateAction).GetObject().(*corev1.Pod).DeepCopy()
+		return false, nil, nil
+	})
+	clientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		get, ok := action.(k8stesting.GetAction)
+		if !ok {
+			return false, nil, nil // GetLogs
+		}
+		name := get.GetName()
+		return true, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}, nil
+	})
+
+	report, err := client.RunClusterDiagnostics(context.Background(), "c1", DiagnosticsOptions{Namespace: "default"})
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 4)
+
+	require.NotNil(t, created)
+	assert.Equal(t, "true", created.Labels[DebugResourceLabel])
+	assert.Equal(t, diagnosticsOwner, created.Labels[DebugOwnerLabel])
+	assert.NotEmpty(t, created.Annotations[DebugExpiresAnnotation])
+	assert.False(t, *created.Spec.AutomountServiceAccountToken)
+	env := map[string]string{}
+	for _, e := range created.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	assert.Equal(t, "kubernetes.default.svc", env["DIAG_DNS_NAMES"])
+	assert.Equal(t, "ghcr.io registry-1.docker.io", env["DIAG_REGISTRIES"])
+
+	// The fake clientset's logs are not script output, so nothing resolved.
+	assert.Equal(t, DiagnosticFail, report.Checks[2].Status)
+	assert.Equal(t, DiagnosticFail, report.Checks[3].Status)
+
+	pods, err := clientset.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, pods.Items, "the diagnostics pod is deleted")
+}
+
+func TestParseDiagnosticsOutput(t *testing.T) {
+	out := "DNS kubernetes.default.svc ok 10.96.0.1\nDNS internal.example.com fail\n" +
+		"REGISTRY ghcr.io 401\nREGISTRY registry.internal:5000 000\nDONE\n"
+	dns, registry := parseDiagnosticsOutput(out,
+		[]string{"kubernetes.default.svc", "internal.example.com"},
+		[]string{"ghcr.io", "registry.internal:5000"})
+
+	assert.Equal(t, DiagnosticWarn, dns.Status)
+	assert.Contains(t, dns.Message, "internal.example.com")
+	assert.Equal(t, "10.96.0.1", dns.Details["resolved"].(map[string]interface{})["kubernetes.default.svc"])
+	assert.Equal(t, DiagnosticWarn, registry.Status)
+	assert.Equal(t, "Unreachable: registry.internal:5000", registry.Message)
+
+	dns, registry = parseDiagnosticsOutput("DNS kubernetes.default.svc fail\nDONE\n", []string{"kubernetes.default.svc"}, nil)
+	assert.Equal(t, DiagnosticFail, dns.Status)
+	assert.Equal(t, DiagnosticSkipped, registry.Status)
+
+	dns, _ = parseDiagnosticsOutput("DNS kubernetes.default.svc ok 10.96.0.1\n", []string{"kubernetes.default.svc"}, nil)
+	assert.Equal(t, DiagnosticWarn, dns.Status, "output without DONE was cut short")
+}
+
+func TestImageRegistryHost(t *testing.T) {
+	tests := map[string]string{
+		"nginx":                           "registry-1.docker.io",
+		"nginx:1.27":                      "registry-1.docker.io",
+		"bitnami/redis:7":                 "registry-1.docker.io",
+		"docker.io/library/nginx":         "registry-1.docker.io",
+		"ghcr.io/acme/web:1":              "ghcr.io",
+		"registry.internal:5000/team/app": "registry.internal:5000",
+		"localhost/app":                   "localhost",
+		"quay.io/prometheus/node-exporter@sha256:abc": "quay.io",
+	}
+	for image, want := range tests {
+		assert.Equal(t, want, ImageRegistryHost(image), image)
+	}
+}
+
+func TestScoreDiagnostics(t *testing.T) {
+	score, status := scoreDiagnostics([]DiagnosticCheck{{Status: DiagnosticPass}, {Status: DiagnosticPass}, {Status: DiagnosticSkipped}})
+	assert.Equal(t, 100, score)
+	assert.Equal(t, DiagnosticsHealthy, status)
+
+	score, status = scoreDiagnostics([]DiagnosticCheck{{Status: DiagnosticPass}, {Status: DiagnosticWarn}})
+	assert.Equal(t, 75, score)
+	assert.Equal(t, DiagnosticsDegraded, status)
+
+	score, status = scoreDiagnostics([]DiagnosticCheck{{Status: DiagnosticWarn}, {Status: DiagnosticFail}})
+	assert.Equal(t, 25, score)
+	assert.Equal(t, DiagnosticsUnhealthy, status)
+}
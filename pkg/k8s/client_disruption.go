@@ -0,0 +1,201 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Workload kinds whose readiness GetWorkloadReadiness can report, in
+// addition to WorkloadKindDeployment and WorkloadKindStatefulSet.
+const (
+	WorkloadKindDaemonSet  = "DaemonSet"
+	WorkloadKindReplicaSet = "ReplicaSet"
+)
+
+// PodTarget describes a pod picked for eviction: where it runs and which
+// workload is expected to replace it. WorkloadKind is empty for pods no
+// controller owns.
+type PodTarget struct {
+	UID          types.UID `json:"uid"`
+	NodeName     string    `json:"nodeName,omitempty"`
+	WorkloadKind string    `json:"workloadKind,omitempty"`
+	WorkloadName string    `json:"workloadName,omitempty"`
+}
+
+// WorkloadReadiness is the desired and ready pod count of a workload.
+// Updated counts the pods running the latest spec; it stays 0 until the
+// controller has observed that spec.
+type WorkloadReadiness struct {
+	Desired int32 `json:"desired"`
+	Ready   int32 `json:"ready"`
+	Updated int32 `json:"updated"`
+}
+
+// RolledOut reports whether every desired pod runs the latest spec and is
+// ready.
+func (r WorkloadReadiness) RolledOut() bool {
+	return r.Updated >= r.Desired && r.Ready >= r.Desired
+}
+
+// NodeSchedulingStatus is whether a node is Ready and accepting new pods.
+type NodeSchedulingStatus struct {
+	Ready         bool `json:"ready"`
+	Unschedulable bool `json:"unschedulable"`
+}
+
+// GetPodTarget reads a pod and resolves its controller. Pods owned by a
+// ReplicaSet that a Deployment manages resolve to the Deployment.
+func (m *MultiClusterClient) GetPodTarget(ctx context.Context, contextName, namespace, name string) (*PodTarget, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	target := &PodTarget{UID: pod.UID, NodeName: pod.Spec.NodeName}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return target, nil
+	}
+	target.WorkloadKind, target.WorkloadName = owner.Kind, owner.Name
+	if owner.Kind == WorkloadKindReplicaSet {
+		rs, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if d := metav1.GetControllerOf(rs); d != nil && d.Kind == WorkloadKindDeployment {
+			target.WorkloadKind, target.WorkloadName = d.Kind, d.Name
+		}
+	}
+	return target, nil
+}
+
+// GetPodUID returns the UID of a pod, or the API server's NotFound error
+// once it is gone. A StatefulSet replacement keeps the name but not the UID.
+func (m *MultiClusterClient) GetPodUID(ctx context.Context, contextName, namespace, name string) (types.UID, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return "", err
+	}
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return pod.UID, nil
+}
+
+// EvictPod evicts a pod through the Eviction API, so PodDisruptionBudgets
+// are honoured: a budget that allows no disruption returns 429.
+func (m *MultiClusterClient) EvictPod(ctx context.Context, contextName, namespace, name string) error {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return err
+	}
+	return client.PolicyV1().Evictions(namespace).Evict(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	})
+}
+
+// GetWorkloadReadiness returns the desired and ready pods of a Deployment,
+// StatefulSet, DaemonSet or ReplicaSet.
+func (m *MultiClusterClient) GetWorkloadReadiness(ctx context.Context, contextName, kind, namespace, name string) (WorkloadReadiness, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return WorkloadReadiness{}, err
+	}
+	replicas := func(p *int32) int32 {
+		if p == nil {
+			return 1
+		}
+		return *p
+	}
+	switch kind {
+	case WorkloadKindDeployment:
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return WorkloadReadiness{}, err
+		}
+		r := WorkloadReadiness{Desired: replicas(d.Spec.Replicas), Ready: d.Status.ReadyReplicas}
+		if d.Status.ObservedGeneration >= d.Generation {
+			r.Updated = d.Status.UpdatedReplicas
+		}
+		return r, nil
+	case WorkloadKindStatefulSet:
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return WorkloadReadiness{}, err
+		}
+		r := WorkloadReadiness{Desired: replicas(s.Spec.Replicas), Ready: s.Status.ReadyReplicas}
+		if s.Status.ObservedGeneration >= s.Generation {
+			r.Updated = s.Status.UpdatedReplicas
+		}
+		return r, nil
+	case WorkloadKindDaemonSet:
+		d, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return WorkloadReadiness{}, err
+		}
+		r := WorkloadReadiness{Desired: d.Status.DesiredNumberScheduled, Ready: d.Status.NumberReady}
+		if d.Status.ObservedGeneration >= d.Generation {
+			r.Updated = d.Status.UpdatedNumberScheduled
+		}
+		return r, nil
+	case WorkloadKindReplicaSet:
+		rs, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return WorkloadReadiness{}, err
+		}
+		// A ReplicaSet has a single pod template, so every pod is current.
+		return WorkloadReadiness{Desired: replicas(rs.Spec.Replicas), Ready: rs.Status.ReadyReplicas, Updated: rs.Status.Replicas}, nil
+	default:
+		return WorkloadReadiness{}, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+}
+
+// SetNodeUnschedulable cordons or uncordons a node and returns whether it
+// was unschedulable before the call.
+func (m *MultiClusterClient) SetNodeUnschedulable(ctx context.Context, contextName, name string, unschedulable bool) (bool, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return false, err
+	}
+	node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	was := node.Spec.Unschedulable
+	if was == unschedulable {
+		return was, nil
+	}
+	patch := fmt.Appendf(nil, `{"spec":{"unschedulable":%t}}`, unschedulable)
+	if _, err := client.CoreV1().Nodes().Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return was, err
+	}
+	return was, nil
+}
+
+// GetNodeSchedulingStatus reports whether a node is Ready and schedulable.
+func (m *MultiClusterClient) GetNodeSchedulingStatus(ctx context.Context, contextName, name string) (NodeSchedulingStatus, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return NodeSchedulingStatus{}, err
+	}
+	node, err := client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return NodeSchedulingStatus{}, err
+	}
+	st := NodeSchedulingStatus{Unschedulable: node.Spec.Unschedulable}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			st.Ready = cond.Status == corev1.ConditionTrue
+		}
+	}
+	return st, nil
+}
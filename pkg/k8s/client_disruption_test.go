@@ -0,0 +1,106 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	yes := true
+	return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &yes}}
+}
+
+func TestGetPodTarget_ResolvesDeployment(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "shop", Name: "api-7d9f", OwnerReferences: controllerRef(WorkloadKindDeployment, "api"),
+	}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api-7d9f-x1", UID: "uid-1", OwnerReferences: controllerRef(WorkloadKindReplicaSet, "api-7d9f")},
+		Spec:       corev1.PodSpec{NodeName: "node-a"},
+	}
+	bare := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "debug"}}
+
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset(rs, pod, bare))
+
+	target, err := client.GetPodTarget(context.Background(), "c1", "shop", "api-7d9f-x1")
+	require.NoError(t, err)
+	assert.Equal(t, &PodTarget{UID: "uid-1", NodeName: "node-a", WorkloadKind: WorkloadKindDeployment, WorkloadName: "api"}, target)
+
+	target, err = client.GetPodTarget(context.Background(), "c1", "shop", "debug")
+	require.NoError(t, err)
+	assert.Empty(t, target.WorkloadKind)
+
+	_, err = client.GetPodUID(context.Background(), "c1", "shop", "gone")
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestGetWorkloadReadiness(t *testing.T) {
+	replicas := int32(3)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "pg"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+	}
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "agent"},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, NumberReady: 4},
+	}
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset(sts, ds))
+
+	r, err := client.GetWorkloadReadiness(context.Background(), "c1", WorkloadKindStatefulSet, "db", "pg")
+	require.NoError(t, err)
+	assert.Equal(t, WorkloadReadiness{Desired: 3, Ready: 2}, r)
+
+	r, err = client.GetWorkloadReadiness(context.Background(), "c1", WorkloadKindDaemonSet, "kube-system", "agent")
+	require.NoError(t, err)
+	assert.Equal(t, WorkloadReadiness{Desired: 4, Ready: 4}, r)
+
+	// A spec the controller has not observed yet has no updated pods.
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 3},
+	}
+	client.SetClient("c2", k8sfake.NewSimpleClientset(dep))
+	r, err = client.GetWorkloadReadiness(context.Background(), "c2", WorkloadKindDeployment, "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, WorkloadReadiness{Desired: 3, Ready: 3}, r)
+	assert.False(t, r.RolledOut())
+
+	_, err = client.GetWorkloadReadiness(context.Background(), "c1", "Job", "batch", "x")
+	assert.ErrorContains(t, err, "unsupported workload kind")
+}
+
+func TestSetNodeUnschedulable(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}},
+	}
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset(node))
+	ctx := context.Background()
+
+	was, err := client.SetNodeUnschedulable(ctx, "c1", "node-a", true)
+	require.NoError(t, err)
+	assert.False(t, was)
+	st, err := client.GetNodeSchedulingStatus(ctx, "c1", "node-a")
+	require.NoError(t, err)
+	assert.Equal(t, NodeSchedulingStatus{Ready: true, Unschedulable: true}, st)
+
+	was, err = client.SetNodeUnschedulable(ctx, "c1", "node-a", false)
+	require.NoError(t, err)
+	assert.True(t, was)
+	st, err = client.GetNodeSchedulingStatus(ctx, "c1", "node-a")
+	require.NoError(t, err)
+	assert.False(t, st.Unschedulable)
+}
@@ -0,0 +1,525 @@
+package k8s
+
+// Manifest validation for the in-console YAML editor. A submitted
+// multi-document manifest is parsed, each object's kind is resolved against
+// the cluster's discovery data, and the object is sent as a server-side
+// dry-run apply with strict field validation so the apiserver checks it
+// against its OpenAPI schema and runs admission without persisting anything.
+// The dry-run result is diffed against the live object so the editor can show
+// exactly what an apply would change. ApplyManifest reuses the same path:
+// every object must pass the dry-run before any of them is applied for real.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// ManifestFieldManager is the server-side apply field manager used for
+// manifests submitted through the console.
+const ManifestFieldManager = "kubestellar-console"
+
+const (
+	// maxManifestDocuments caps how many objects one manifest may contain.
+	maxManifestDocuments = 50
+	// maxManifestChanges caps the diff entries reported per object so a
+	// large create does not produce an unbounded response.
+	maxManifestChanges = 500
+)
+
+// Validation stages reported in ManifestError.Stage.
+const (
+	ManifestStageParse     = "parse"
+	ManifestStageDiscovery = "discovery"
+	ManifestStageSchema    = "schema"
+	ManifestStageDryRun    = "dryRun"
+	ManifestStageApply     = "apply"
+)
+
+// Actions reported in ManifestObjectResult.Action.
+const (
+	ManifestActionCreate    = "create"
+	ManifestActionUpdate    = "update"
+	ManifestActionUnchanged = "unchanged"
+)
+
+// ErrInvalidManifest is returned when the manifest stream itself cannot be
+// split into documents.
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// ErrTooManyManifestDocuments is returned when a manifest exceeds
+// maxManifestDocuments objects.
+var ErrTooManyManifestDocuments = fmt.Errorf("manifest contains more than %d documents", maxManifestDocuments)
+
+// ManifestError is a single problem found while validating one document.
+type ManifestError struct {
+	Stage   string `json:"stage"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ManifestFieldChange is one leaf-level difference between the live object
+// and the dry-run result. Paths use dotted notation with list indexes, e.g.
+// spec.template.spec.containers[0].image.
+type ManifestFieldChange struct {
+	Path   string      `json:"path"`
+	Type   string      `json:"type"` // added, removed, changed
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ManifestObjectResult is the validation outcome for one manifest document.
+type ManifestObjectResult struct {
+	Index      int    `json:"index"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Valid      bool   `json:"valid"`
+	// Action is what an apply would do; empty when validation failed.
+	Action           string                `json:"action,omitempty"`
+	Errors           []ManifestError       `json:"errors,omitempty"`
+	Changes          []ManifestFieldChange `json:"changes,omitempty"`
+	ChangesTruncated bool                  `json:"changesTruncated,omitempty"`
+}
+
+// ManifestValidation is the result of ValidateManifest.
+type ManifestValidation struct {
+	Cluster string                 `json:"cluster"`
+	Valid   bool                   `json:"valid"`
+	Objects []ManifestObjectResult `json:"objects"`
+}
+
+// ManifestApplyResult is the result of ApplyManifest. Applied is false when
+// any object failed validation, in which case nothing was written.
+type ManifestApplyResult struct {
+	Cluster string                 `json:"cluster"`
+	Applied bool                   `json:"applied"`
+	Objects []ManifestObjectResult `json:"objects"`
+}
+
+// manifestDocument is one decoded document plus its position in the input.
+type manifestDocument struct {
+	index int
+	obj   *unstructured.Unstructured
+	err   error
+}
+
+// splitManifest decodes a multi-document YAML or JSON manifest. Empty
+// documents are skipped; a document that fails to parse is returned with err
+// set so the remaining documents can still be validated.
+func splitManifest(manifest []byte) ([]manifestDocument, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifest)))
+	docs := make([]manifestDocument, 0)
+	for index := 0; ; index++ {
+		raw, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+		}
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		if len(docs) >= maxManifestDocuments {
+			return nil, ErrTooManyManifestDocuments
+		}
+
+		doc := manifestDocument{index: index}
+		jsonDoc, err := utilyaml.ToJSON(raw)
+		if err != nil {
+			doc.err = err
+			docs = append(docs, doc)
+			continue
+		}
+		var content map[string]interface{}
+		if err := json.Unmarshal(jsonDoc, &content); err != nil {
+			doc.err = errors.New("document is not a YAML or JSON object")
+			docs = append(docs, doc)
+			continue
+		}
+		if content == nil {
+			// A document holding only comments.
+			continue
+		}
+		doc.obj = &unstructured.Unstructured{Object: content}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// ManifestObject is one decoded document from ParseManifest.
+type ManifestObject struct {
+	Index  int
+	Object *unstructured.Unstructured
+}
+
+// ParseManifest decodes a multi-document manifest without contacting a
+// cluster, so callers can inspect the objects (e.g. run local policy checks)
+// before applying. Unlike ValidateManifest, any malformed document fails the
+// whole manifest with ErrInvalidManifest.
+func ParseManifest(manifest []byte) ([]ManifestObject, error) {
+	docs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ManifestObject, 0, len(docs))
+	for _, doc := range docs {
+		if doc.err != nil {
+			return nil, fmt.Errorf("%w: document %d: %v", ErrInvalidManifest, doc.index, doc.err)
+		}
+		out = append(out, ManifestObject{Index: doc.index, Object: doc.obj})
+	}
+	return out, nil
+}
+
+// ValidateManifest validates every object in a YAML or JSON manifest against
+// a cluster and reports, per object, structured errors or the diff a real
+// apply would produce. Objects without a namespace are placed in
+// defaultNamespace when their kind is namespaced. Nothing is persisted: each
+// object is sent as a server-side apply with dryRun=All.
+func (m *MultiClusterClient) ValidateManifest(ctx context.Context, contextName string, manifest []byte, defaultNamespace string) (*ManifestValidation, error) {
+	docs, dyn, mapper, err := m.prepareManifest(contextName, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+
+	result := &ManifestValidation{Cluster: contextName, Valid: true, Objects: make([]ManifestObjectResult, 0, len(docs))}
+	for _, doc := range docs {
+		res := applyManifestObject(ctx, dyn, mapper, doc, defaultNamespace, true)
+		if !res.Valid {
+			result.Valid = false
+		}
+		result.Objects = append(result.Objects, res)
+	}
+	return result, nil
+}
+
+// ApplyManifest server-side applies every object in a manifest to a cluster
+// under ManifestFieldManager. All objects are dry-run first; if any fails,
+// nothing is written and the dry-run results are returned with Applied=false.
+// Objects are then applied in document order, so a failure part-way (e.g. a
+// conflicting admission decision) is reported on that object and the
+// remaining objects are still attempted.
+func (m *MultiClusterClient) ApplyManifest(ctx context.Context, contextName string, manifest []byte, defaultNamespace string) (*ManifestApplyResult, error) {
+	docs, dyn, mapper, err := m.prepareManifest(contextName, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+
+	result := &ManifestApplyResult{Cluster: contextName, Objects: make([]ManifestObjectResult, 0, len(docs))}
+	valid := true
+	for _, doc := range docs {
+		res := applyManifestObject(ctx, dyn, mapper, doc, defaultNamespace, true)
+		if !res.Valid {
+			valid = false
+		}
+		result.Objects = append(result.Objects, res)
+	}
+	if !valid {
+		return result, nil
+	}
+
+	result.Applied = true
+	for i, doc := range docs {
+		result.Objects[i] = applyManifestObject(ctx, dyn, mapper, doc, defaultNamespace, false)
+	}
+	return result, nil
+}
+
+// prepareManifest splits a manifest and resolves the clients and REST mapper
+// for contextName.
+func (m *MultiClusterClient) prepareManifest(contextName string, manifest []byte) ([]manifestDocument, dynamic.Interface, meta.RESTMapper, error) {
+	docs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	groupResources, err := restmapper.GetAPIGroupResources(client.Discovery())
+	if err != nil && len(groupResources) == 0 {
+		return nil, nil, nil, fmt.Errorf("failed to discover API resources: %w", err)
+	}
+	return docs, dyn, restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// applyManifestObject runs the discovery, schema, and apply stages for a
+// single decoded document. With dryRun set nothing is persisted.
+func applyManifestObject(ctx context.Context, dyn dynamic.Interface, mapper meta.RESTMapper, doc manifestDocument, defaultNamespace string, dryRun bool) ManifestObjectResult {
+	res := ManifestObjectResult{Index: doc.index}
+	fail := func(stage, field, msg string) ManifestObjectResult {
+		res.Errors = append(res.Errors, ManifestError{Stage: stage, Field: field, Message: msg})
+		return res
+	}
+	if doc.err != nil {
+		return fail(ManifestStageParse, "", doc.err.Error())
+	}
+
+	obj := doc.obj
+	res.APIVersion = obj.GetAPIVersion()
+	res.Kind = obj.GetKind()
+	res.Name = obj.GetName()
+	switch {
+	case res.APIVersion == "":
+		return fail(ManifestStageParse, "apiVersion", "apiVersion is required")
+	case res.Kind == "":
+		return fail(ManifestStageParse, "kind", "kind is required")
+	case res.Name == "":
+		return fail(ManifestStageParse, "metadata.name", "metadata.name is required (generateName is not supported)")
+	}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return fail(ManifestStageDiscovery, "kind", fmt.Sprintf("%s %s is not served by this cluster", res.APIVersion, res.Kind))
+	}
+
+	namespace := obj.GetNamespace()
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if namespace == "" {
+			namespace = defaultNamespace
+			obj.SetNamespace(namespace)
+		}
+	} else if namespace != "" {
+		return fail(ManifestStageSchema, "metadata.namespace", fmt.Sprintf("%s is cluster-scoped and must not set metadata.namespace", res.Kind))
+	}
+	res.Namespace = namespace
+
+	resource := dyn.Resource(mapping.Resource).Namespace(namespace)
+	live, err := resource.Get(ctx, res.Name, metav1.GetOptions{})
+	stage := ManifestStageDryRun
+	if !dryRun {
+		stage = ManifestStageApply
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fail(stage, "", err.Error())
+	}
+	if err != nil {
+		live = nil
+	}
+
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return fail(ManifestStageParse, "", err.Error())
+	}
+	force := true
+	opts := metav1.PatchOptions{
+		FieldManager:    ManifestFieldManager,
+		FieldValidation: "Strict",
+		Force:           &force,
+	}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := resource.Patch(ctx, res.Name, types.ApplyPatchType, data, opts)
+	if err != nil {
+		res.Errors = append(res.Errors, manifestErrorsFromStatus(err, stage)...)
+		return res
+	}
+
+	res.Valid = true
+	var before map[string]interface{}
+	if live != nil {
+		before = sanitizeForDiff(live.Object)
+	}
+	diffManifestValues("", before, sanitizeForDiff(applied.Object), &res)
+	redactSecretChanges(&res)
+	switch {
+	case live == nil:
+		res.Action = ManifestActionCreate
+	case len(res.Changes) == 0:
+		res.Action = ManifestActionUnchanged
+	default:
+		res.Action = ManifestActionUpdate
+	}
+	return res
+}
+
+// manifestErrorsFromStatus converts an apiserver error into structured
+// errors. Invalid/BadRequest responses come from schema and field
+// validation; anything else (admission webhooks, RBAC, quota) is reported
+// under stage.
+func manifestErrorsFromStatus(err error, stage string) []ManifestError {
+	if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+		stage = ManifestStageSchema
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if details := status.Status().Details; details != nil && len(details.Causes) > 0 {
+			out := make([]ManifestError, 0, len(details.Causes))
+			for _, cause := range details.Causes {
+				out = append(out, ManifestError{Stage: stage, Field: cause.Field, Message: cause.Message})
+			}
+			return out
+		}
+	}
+	return []ManifestError{{Stage: stage, Message: err.Error()}}
+}
+
+// diffIgnoredMetadata lists server-managed metadata fields that change on
+// every write and would only add noise to a diff.
+var diffIgnoredMetadata = []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"}
+
+// sanitizeForDiff returns a copy of obj without status and server-managed
+// metadata.
+func sanitizeForDiff(obj map[string]interface{}) map[string]interface{} {
+	out := runtime.DeepCopyJSON(obj)
+	delete(out, "status")
+	if md, ok := out["metadata"].(map[string]interface{}); ok {
+		for _, field := range diffIgnoredMetadata {
+			delete(md, field)
+		}
+		if ann, ok := md["annotations"].(map[string]interface{}); ok {
+			delete(ann, "kubectl.kubernetes.io/last-applied-configuration")
+			if len(ann) == 0 {
+				delete(md, "annotations")
+			}
+		}
+	}
+	return out
+}
+
+// redactedManifestValue stands in for Secret values in a diff.
+const redactedManifestValue = "<redacted>"
+
+// redactSecretChanges blanks Secret data and stringData values in
+// res.Changes. The live values come from the console's credentials, not the
+// caller's, so only the changed keys are reported.
+func redactSecretChanges(res *ManifestObjectResult) {
+	if res.APIVersion != "v1" || res.Kind != "Secret" {
+		return
+	}
+	for i := range res.Changes {
+		change := &res.Changes[i]
+		if !isSecretValuePath(change.Path) {
+			continue
+		}
+		if change.Before != nil {
+			change.Before = redactedManifestValue
+		}
+		if change.After != nil {
+			change.After = redactedManifestValue
+		}
+	}
+}
+
+// isSecretValuePath reports whether a diff path is under a Secret's data or
+// stringData.
+func isSecretValuePath(path string) bool {
+	for _, field := range []string{"data", "stringData"} {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// diffManifestValues appends leaf-level differences between before and after
+// to res.Changes, stopping at maxManifestChanges.
+func diffManifestValues(path string, before, after interface{}, res *ManifestObjectResult) {
+	if res.ChangesTruncated {
+		return
+	}
+	record := func(change ManifestFieldChange) {
+		if len(res.Changes) >= maxManifestChanges {
+			res.ChangesTruncated = true
+			return
+		}
+		res.Changes = append(res.Changes, change)
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap || before == nil && afterIsMap || after == nil && beforeIsMap {
+		keys := make(map[string]bool, len(beforeMap)+len(afterMap))
+		for k := range beforeMap {
+			keys[k] = true
+		}
+		for k := range afterMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			diffManifestValues(joinDiffPath(path, k), beforeMap[k], afterMap[k], res)
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList || before == nil && afterIsList || after == nil && beforeIsList {
+		n := len(beforeList)
+		if len(afterList) > n {
+			n = len(afterList)
+		}
+		for i := 0; i < n; i++ {
+			var b, a interface{}
+			if i < len(beforeList) {
+				b = beforeList[i]
+			}
+			if i < len(afterList) {
+				a = afterList[i]
+			}
+			diffManifestValues(path+"["+strconv.Itoa(i)+"]", b, a, res)
+		}
+		return
+	}
+
+	switch {
+	case before == nil && after == nil:
+	case before == nil:
+		record(ManifestFieldChange{Path: path, Type: "added", After: after})
+	case after == nil:
+		record(ManifestFieldChange{Path: path, Type: "removed", Before: before})
+	case !reflect.DeepEqual(before, after):
+		record(ManifestFieldChange{Path: path, Type: "changed", Before: before, After: after})
+	}
+}
+
+// joinDiffPath appends key to a dotted diff path. Keys containing dots
+// (label and annotation names) are bracket-quoted.
+func joinDiffPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
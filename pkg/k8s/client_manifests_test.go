@@ -0,0 +1,338 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secretGVR    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+)
+
+// newManifestTestClient returns a client whose dynamic fake answers
+// server-side apply dry-runs by echoing the submitted object, and rejects
+// any object named "invalid" the way strict field validation would.
+func newManifestTestClient(t *testing.T, objects ...runtime.Object) (*MultiClusterClient, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	cs := k8sfake.NewSimpleClientset()
+	cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list", "patch"}},
+				{Name: "namespaces", Kind: "Namespace", Namespaced: false, Verbs: []string{"get", "list", "patch"}},
+				{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"get", "list", "patch"}},
+			},
+		},
+	}
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMapGVR: "ConfigMapList",
+		secretGVR:    "SecretList",
+	}, objects...)
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		if patch.GetName() == "invalid" {
+			return true, nil, apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "invalid", nil)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+		obj.SetResourceVersion("2")
+		return true, obj, nil
+	})
+
+	m := newTestClient()
+	m.clients["c1"] = cs
+	m.dynamicClients["c1"] = dyn
+	return m, dyn
+}
+
+func TestSplitManifest(t *testing.T) {
+	docs, err := splitManifest([]byte("---\n# only a comment\n---\napiVersion: v1\nkind: ConfigMap\n---\n: bad: yaml\n---\n- a\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents (comment-only skipped), got %d", len(docs))
+	}
+	if docs[0].obj == nil || docs[0].obj.GetKind() != "ConfigMap" {
+		t.Errorf("expected first document to decode, got %+v", docs[0])
+	}
+	if docs[1].err == nil || docs[2].err == nil {
+		t.Errorf("expected parse errors for malformed and non-object documents")
+	}
+}
+
+func TestValidateManifest_CreateUpdateAndErrors(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "existing", "namespace": "apps", "resourceVersion": "1"},
+		"data":       map[string]interface{}{"mode": "old", "keep": "x"},
+	}}
+	m, dyn := newManifestTestClient(t, live)
+
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fresh
+data:
+  a: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: existing
+  namespace: apps
+data:
+  mode: new
+  keep: x
+---
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: g
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ns
+  namespace: apps
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: invalid
+`
+	result, err := m.ValidateManifest(context.Background(), "c1", []byte(manifest), "team-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid {
+		t.Error("expected overall result to be invalid")
+	}
+	if len(result.Objects) != 5 {
+		t.Fatalf("expected 5 object results, got %d", len(result.Objects))
+	}
+
+	fresh := result.Objects[0]
+	if !fresh.Valid || fresh.Action != ManifestActionCreate || fresh.Namespace != "team-a" {
+		t.Errorf("unexpected result for new object: %+v", fresh)
+	}
+
+	existing := result.Objects[1]
+	if !existing.Valid || existing.Action != ManifestActionUpdate {
+		t.Fatalf("unexpected result for existing object: %+v", existing)
+	}
+	if len(existing.Changes) != 1 || existing.Changes[0].Path != "data.mode" || existing.Changes[0].Type != "changed" {
+		t.Errorf("expected a single data.mode change (resourceVersion ignored), got %+v", existing.Changes)
+	}
+
+	if errs := result.Objects[2].Errors; len(errs) != 1 || errs[0].Stage != ManifestStageDiscovery {
+		t.Errorf("expected discovery error for unknown kind, got %+v", errs)
+	}
+	if errs := result.Objects[3].Errors; len(errs) != 1 || errs[0].Field != "metadata.namespace" {
+		t.Errorf("expected namespace error for cluster-scoped kind, got %+v", errs)
+	}
+	if errs := result.Objects[4].Errors; len(errs) != 1 || errs[0].Stage != ManifestStageSchema {
+		t.Errorf("expected schema error from the apiserver, got %+v", errs)
+	}
+
+	// The dry-run must not have changed the live object.
+	got, err := dyn.Resource(configMapGVR).Namespace("apps").Get(context.Background(), "existing", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get live object: %v", err)
+	}
+	if mode, _, _ := unstructured.NestedString(got.Object, "data", "mode"); mode != "old" {
+		t.Errorf("live object was modified: mode=%q", mode)
+	}
+}
+
+// liveSecret is an existing Secret whose values must never reach a diff.
+func liveSecret() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db", "namespace": "apps", "resourceVersion": "1"},
+		"data":       map[string]interface{}{"password": "bGl2ZS1wYXNzd29yZA==", "user": "YWRtaW4="},
+	}}
+}
+
+// assertNoLiveSecretValues fails when any change carries a value of
+// liveSecret.
+func assertNoLiveSecretValues(t *testing.T, changes []ManifestFieldChange) {
+	t.Helper()
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatalf("marshal changes: %v", err)
+	}
+	for _, value := range []string{"bGl2ZS1wYXNzd29yZA==", "YWRtaW4="} {
+		if strings.Contains(string(data), value) {
+			t.Errorf("diff leaks live Secret value %q: %s", value, data)
+		}
+	}
+}
+
+func TestValidateManifest_RedactsSecretValues(t *testing.T) {
+	m, _ := newManifestTestClient(t, liveSecret())
+
+	manifest := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: apps\ndata:\n  password: bmV3\n"
+	result, err := m.ValidateManifest(context.Background(), "c1", []byte(manifest), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	obj := result.Objects[0]
+	if !obj.Valid || obj.Action != ManifestActionUpdate {
+		t.Fatalf("unexpected result: %+v", obj)
+	}
+	paths := map[string]string{}
+	for _, change := range obj.Changes {
+		paths[change.Path] = change.Type
+		if change.Before != nil && change.Before != redactedManifestValue || change.After != nil && change.After != redactedManifestValue {
+			t.Errorf("expected redacted values, got %+v", change)
+		}
+	}
+	if paths["data.password"] != "changed" || paths["data.user"] != "removed" {
+		t.Errorf("expected changed keys to be reported, got %+v", obj.Changes)
+	}
+	assertNoLiveSecretValues(t, obj.Changes)
+}
+
+func TestValidateManifest_TooManyDocuments(t *testing.T) {
+	m, _ := newManifestTestClient(t)
+	var manifest []byte
+	for i := 0; i <= maxManifestDocuments; i++ {
+		manifest = append(manifest, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: x\n---\n")...)
+	}
+	if _, err := m.ValidateManifest(context.Background(), "c1", manifest, ""); !errors.Is(err, ErrTooManyManifestDocuments) {
+		t.Errorf("expected ErrTooManyManifestDocuments, got %v", err)
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	objects, err := ParseManifest([]byte("# leading comment\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Index != 1 || objects[0].Object.GetName() != "a" {
+		t.Errorf("unexpected objects: %+v", objects)
+	}
+
+	if _, err := ParseManifest([]byte("apiVersion: v1\nkind: ConfigMap\n---\n- a\n")); !errors.Is(err, ErrInvalidManifest) {
+		t.Errorf("expected ErrInvalidManifest for a non-object document, got %v", err)
+	}
+}
+
+// countAppliedPatches records server-side apply patches that were not dry
+// runs.
+func countAppliedPatches(dyn *dynamicfake.FakeDynamicClient) *int {
+	applied := 0
+	dyn.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if patch, ok := action.(k8stesting.PatchActionImpl); ok && len(patch.PatchOptions.DryRun) == 0 {
+			applied++
+		}
+		return false, nil, nil
+	})
+	return &applied
+}
+
+func TestApplyManifest(t *testing.T) {
+	m, dyn := newManifestTestClient(t)
+	applied := countAppliedPatches(dyn)
+
+	result, err := m.ApplyManifest(context.Background(), "c1", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n"), "apps")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Applied || len(result.Objects) != 2 {
+		t.Fatalf("expected both objects applied, got %+v", result)
+	}
+	for _, obj := range result.Objects {
+		if !obj.Valid || obj.Action != ManifestActionCreate || obj.Namespace != "apps" {
+			t.Errorf("unexpected object result: %+v", obj)
+		}
+	}
+	if *applied != 2 {
+		t.Errorf("expected 2 real apply patches, got %d", *applied)
+	}
+}
+
+func TestApplyManifest_RedactsSecretValues(t *testing.T) {
+	m, _ := newManifestTestClient(t, liveSecret())
+
+	result, err := m.ApplyManifest(context.Background(), "c1", []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\n  namespace: apps\ndata:\n  password: bmV3\n"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Applied || result.Objects[0].Action != ManifestActionUpdate {
+		t.Fatalf("expected the Secret to be updated, got %+v", result)
+	}
+	assertNoLiveSecretValues(t, result.Objects[0].Changes)
+}
+
+func TestApplyManifest_InvalidObjectBlocksAll(t *testing.T) {
+	m, dyn := newManifestTestClient(t)
+	applied := countAppliedPatches(dyn)
+
+	result, err := m.ApplyManifest(context.Background(), "c1", []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ok\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: invalid\n"), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Applied {
+		t.Error("expected nothing to be applied when one object is invalid")
+	}
+	if *applied != 0 {
+		t.Errorf("expected no real apply patches, got %d", *applied)
+	}
+	if errs := result.Objects[1].Errors; len(errs) != 1 || errs[0].Stage != ManifestStageSchema {
+		t.Errorf("expected schema error on the invalid object, got %+v", errs)
+	}
+}
+
+func TestDiffManifestValues(t *testing.T) {
+	before := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app.kubernetes.io/name": "a"}},
+		"spec":     map[string]interface{}{"ports": []interface{}{int64(80), int64(443)}},
+	}
+	after := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app.kubernetes.io/name": "b"}},
+		"spec":     map[string]interface{}{"ports": []interface{}{int64(80)}, "replicas": int64(2)},
+	}
+	var res ManifestObjectResult
+	diffManifestValues("", before, after, &res)
+
+	want := map[string]string{
+		`metadata.labels["app.kubernetes.io/name"]`: "changed",
+		"spec.ports[1]": "removed",
+		"spec.replicas": "added",
+	}
+	if len(res.Changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), res.Changes)
+	}
+	for _, change := range res.Changes {
+		if want[change.Path] != change.Type {
+			t.Errorf("unexpected change %+v", change)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeployedImage is a container image of a workload and the digest its pods
+// resolved it to. Digest is empty when no pod reported pulling the image.
+type DeployedImage struct {
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	Digest    string `json:"digest,omitempty"`
+}
+
+// GetWorkloadImages reads the images in a workload's pod template and
+// resolves each to the digest its pods run, from the image IDs in their
+// container statuses. Pods still running an older image are ignored. A
+// container whose pods disagree, as after a mutable tag moved between
+// pulls, is listed once per digest. A missing workload returns the API
+// server's NotFound error.
+func (m *MultiClusterClient) GetWorkloadImages(ctx context.Context, contextName, kind, namespace, name string) ([]DeployedImage, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	var template corev1.PodSpec
+	var selector *metav1.LabelSelector
+	switch kind {
+	case WorkloadKindDeployment:
+		d, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		template, selector = d.Spec.Template.Spec, d.Spec.Selector
+	case WorkloadKindStatefulSet:
+		s, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		template, selector = s.Spec.Template.Spec, s.Spec.Selector
+	case WorkloadKindDaemonSet:
+		d, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		template, selector = d.Spec.Template.Spec, d.Spec.Selector
+	case WorkloadKindReplicaSet:
+		r, err := client.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		template, selector = r.Spec.Template.Spec, r.Spec.Selector
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %q", kind)
+	}
+
+	var pods []corev1.Pod
+	if selector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("workload selector: %w", err)
+		}
+		if !sel.Empty() {
+			list, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+			if err != nil {
+				return nil, err
+			}
+			pods = list.Items
+		}
+	}
+
+	containers := append(slices.Clone(template.InitContainers), template.Containers...)
+	images := make([]DeployedImage, 0, len(containers))
+	for _, c := range containers {
+		var digests []string
+		if d := imageDigest(c.Image); d != "" {
+			// Pinned in the template; the cluster cannot resolve it otherwise.
+			digests = []string{d}
+		} else {
+			for i := range pods {
+				p := &pods[i]
+				statuses := append(slices.Clone(p.Status.InitContainerStatuses), p.Status.ContainerStatuses...)
+				for _, st := range statuses {
+					if st.Name != c.Name || normalizeImage(st.Image) != normalizeImage(c.Image) {
+						continue
+					}
+					if d := imageDigest(st.ImageID); d != "" && !slices.Contains(digests, d) {
+						digests = append(digests, d)
+					}
+				}
+			}
+			slices.Sort(digests)
+		}
+		if len(digests) == 0 {
+			images = append(images, DeployedImage{Container: c.Name, Image: c.Image})
+			continue
+		}
+		for _, d := range digests {
+			images = append(images, DeployedImage{Container: c.Name, Image: c.Image, Digest: d})
+		}
+	}
+	return images, nil
+}
+
+// imageDigest returns the digest of an image reference or image ID that
+// names one, such as "nginx@sha256:..." or
+// "docker-pullable://nginx@sha256:...".
+func imageDigest(ref string) string {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[i+1:]
+	}
+	return ""
+}
+
+// normalizeImage expands an image reference the way the kubelet reports it
+// in container statuses, so "nginx" and "docker.io/library/nginx:latest"
+// compare equal.
+func normalizeImage(ref string) string {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if slash := strings.LastIndex(ref, "/"); !strings.Contains(ref[slash+1:], ":") {
+		ref += ":latest"
+	}
+	first, _, found := strings.Cut(ref, "/")
+	if !found || !strings.ContainsAny(first, ".:") && first != "localhost" {
+		ref = "docker.io/" + ref
+	}
+	if rest, ok := strings.CutPrefix(ref, "docker.io/"); ok && !strings.Contains(rest, "/") {
+		ref = "docker.io/library/" + rest
+	}
+	return ref
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func appPod(name, image, imageID string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name, Labels: map[string]string{"app": "api"}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "api", Image: image, ImageID: imageID},
+			{Name: "proxy", Image: "ghcr.io/acme/proxy@sha256:bbb", ImageID: "ghcr.io/acme/proxy@sha256:bbb"},
+		}},
+	}
+}
+
+func TestGetWorkloadImages(t *testing.T) {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "api"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "api", Image: "nginx:1.27"},
+				{Name: "proxy", Image: "ghcr.io/acme/proxy@sha256:bbb"},
+				{Name: "sidecar", Image: "busybox"},
+			}}},
+		},
+	}
+	client := &MultiClusterClient{}
+	client.SetClient("c1", k8sfake.NewSimpleClientset(d,
+		appPod("api-1", "docker.io/library/nginx:1.27", "docker-pullable://nginx@sha256:aaa"),
+		appPod("api-2", "docker.io/library/nginx:1.27", "docker.io/library/nginx@sha256:aaa"),
+		// Still running the previous image mid-rollout.
+		appPod("api-old", "docker.io/library/nginx:1.26", "docker.io/library/nginx@sha256:old"),
+	))
+
+	images, err := client.GetWorkloadImages(context.Background(), "c1", WorkloadKindDeployment, "shop", "api")
+	require.NoError(t, err)
+	assert.Equal(t, []DeployedImage{
+		{Container: "api", Image: "nginx:1.27", Digest: "sha256:aaa"},
+		{Container: "proxy", Image: "ghcr.io/acme/proxy@sha256:bbb", Digest: "sha256:bbb"},
+		{Container: "sidecar", Image: "busybox"},
+	}, images)
+
+	_, err = client.GetWorkloadImages(context.Background(), "c1", WorkloadKindDeployment, "shop", "gone")
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = client.GetWorkloadImages(context.Background(), "c1", "CronJob", "shop", "api")
+	assert.Error(t, err)
+}
+
+func TestNormalizeImage(t *testing.T) {
+	for ref, want := range map[string]string{
+		"nginx":                            "docker.io/library/nginx:latest",
+		"nginx:1.27":                       "docker.io/library/nginx:1.27",
+		"acme/api:v2":                      "docker.io/acme/api:v2",
+		"ghcr.io/acme/api":                 "ghcr.io/acme/api:latest",
+		"localhost:5000/api:v1":            "localhost:5000/api:v1",
+		"docker.io/library/nginx:1.27":     "docker.io/library/nginx:1.27",
+		"registry.local/team/api@sha256:1": "registry.local/team/api:latest",
+	} {
+		assert.Equal(t, want, normalizeImage(ref), ref)
+	}
+}
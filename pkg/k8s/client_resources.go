@@ -1,5 +1,420 @@
 package k8s
 
-// Resource-specific client helpers are split across focused modules.
-// Keep this file reserved for future custom-resource helpers that do not fit
-// one of the existing Kubernetes resource groupings.
+// Generic resource explorer helpers. These work on arbitrary
+// GroupVersionResources (built-in kinds and CRDs alike) so the console can
+// browse resource kinds it has no typed model for. Reads are shared by the
+// backend explorer routes and kc-agent; PatchResource and
+// GetResourceCapabilities are only exposed through kc-agent so that they run
+// under the user's kubeconfig (#7993).
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+)
+
+// resourceTableAcceptHeader asks the apiserver to render a list as a
+// meta.k8s.io/v1 Table, which carries the same printer columns kubectl get
+// shows (including additionalPrinterColumns declared on CRDs).
+const resourceTableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// maxResourceTableLimit caps a single explorer page so a cluster with tens of
+// thousands of objects of one kind cannot produce an unbounded response.
+const maxResourceTableLimit = 500
+
+// ErrSchemaUnavailable is returned by GetResourceSchema when the resource is
+// not backed by a CRD with a structural openAPIV3Schema.
+var ErrSchemaUnavailable = errors.New("schema unavailable for resource")
+
+// explorerCapabilityVerbs are the verbs probed by GetResourceCapabilities.
+var explorerCapabilityVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+// allowedExplorerPatchTypes are the patch strategies accepted by PatchResource.
+var allowedExplorerPatchTypes = map[types.PatchType]bool{
+	types.MergePatchType:          true,
+	types.JSONPatchType:           true,
+	types.StrategicMergePatchType: true,
+	types.ApplyPatchType:          false, // server-side apply needs a field manager flow; not supported here
+}
+
+// APIResourceInfo describes a single resource kind discovered on a cluster.
+type APIResourceInfo struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"`
+	Kind       string   `json:"kind"`
+	Namespaced bool     `json:"namespaced"`
+	Verbs      []string `json:"verbs"`
+	ShortNames []string `json:"shortNames,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	// Custom is true when the resource is served by a CustomResourceDefinition.
+	Custom bool `json:"custom"`
+}
+
+// GVR returns the GroupVersionResource for this resource.
+func (r APIResourceInfo) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// ResourceTableColumn is a single server-side printer column.
+type ResourceTableColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority"`
+}
+
+// ResourceTableRow is a single object rendered as table cells.
+type ResourceTableRow struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Cells     []interface{} `json:"cells"`
+}
+
+// ResourceTable is a page of objects of one kind rendered with the
+// apiserver's printer columns.
+type ResourceTable struct {
+	Cluster  string                `json:"cluster"`
+	Group    string                `json:"group"`
+	Version  string                `json:"version"`
+	Resource string                `json:"resource"`
+	Columns  []ResourceTableColumn `json:"columns"`
+	Rows     []ResourceTableRow    `json:"rows"`
+	Continue string                `json:"continue,omitempty"`
+	// ServerPrinted is false when the apiserver could not render a Table and
+	// the fallback Name/Namespace/Age columns were used instead.
+	ServerPrinted bool `json:"serverPrinted"`
+}
+
+// ResourceListOptions narrows a list call such as ListResourceTable.
+type ResourceListOptions struct {
+	LabelSelector string
+	FieldSelector string
+	Limit         int64
+	Continue      string
+}
+
+// listOptions converts opts to the apiserver's list options.
+func (opts ResourceListOptions) listOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: opts.LabelSelector,
+		FieldSelector: opts.FieldSelector,
+		Limit:         opts.Limit,
+		Continue:      opts.Continue,
+	}
+}
+
+// DiscoverAPIResources returns every listable resource kind the cluster
+// serves, using the preferred version of each group. Partial discovery
+// failures (an aggregated API that is down) are tolerated so one broken
+// APIService does not hide every CRD on the cluster.
+func (m *MultiClusterClient) DiscoverAPIResources(ctx context.Context, contextName string) ([]APIResourceInfo, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := discovery.ServerPreferredResources(client.Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover API resources: %w", err)
+	}
+
+	customNames := m.crdResourceNames(ctx, contextName)
+
+	result := make([]APIResourceInfo, 0)
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		gv, parseErr := schema.ParseGroupVersion(list.GroupVersion)
+		if parseErr != nil {
+			continue
+		}
+		for _, res := range list.APIResources {
+			// Skip subresources (pods/log, deployments/scale) — they are not
+			// browsable kinds on their own.
+			if strings.Contains(res.Name, "/") {
+				continue
+			}
+			if !containsVerb(res.Verbs, "list") {
+				continue
+			}
+			result = append(result, APIResourceInfo{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   res.Name,
+				Kind:       res.Kind,
+				Namespaced: res.Namespaced,
+				Verbs:      append([]string(nil), res.Verbs...),
+				ShortNames: res.ShortNames,
+				Categories: res.Categories,
+				Custom:     customNames[res.Name+"."+gv.Group],
+			})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Group != result[j].Group {
+			return result[i].Group < result[j].Group
+		}
+		return result[i].Resource < result[j].Resource
+	})
+	return result, nil
+}
+
+// crdResourceNames returns the set of "<plural>.<group>" names backed by a
+// CRD on the cluster. Failures return an empty set — the Custom flag is a UI
+// hint only.
+func (m *MultiClusterClient) crdResourceNames(ctx context.Context, contextName string) map[string]bool {
+	names := make(map[string]bool)
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return names
+	}
+	list, err := dyn.Resource(gvrCRDs).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return names
+	}
+	for _, item := range list.Items {
+		names[item.GetName()] = true
+	}
+	return names
+}
+
+// ListResourceTable lists objects of an arbitrary resource kind and renders
+// them with the apiserver's printer columns. When the server cannot render a
+// Table (or the client has no raw REST access, as with fake clients) the
+// objects are listed through the dynamic client and rendered with generic
+// Name/Namespace/Age columns.
+func (m *MultiClusterClient) ListResourceTable(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string, opts ResourceListOptions) (*ResourceTable, error) {
+	if opts.Limit <= 0 || opts.Limit > maxResourceTableLimit {
+		opts.Limit = maxResourceTableLimit
+	}
+
+	table, err := m.listServerTable(ctx, contextName, gvr, namespace, opts)
+	if err == nil && table != nil {
+		return table, nil
+	}
+
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	list, err := dyn.Resource(gvr).Namespace(namespace).List(ctx, opts.listOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	table = &ResourceTable{
+		Cluster:  contextName,
+		Group:    gvr.Group,
+		Version:  gvr.Version,
+		Resource: gvr.Resource,
+		Columns: []ResourceTableColumn{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Namespace", Type: "string"},
+			{Name: "Age", Type: "string"},
+		},
+		Rows:     make([]ResourceTableRow, 0, len(list.Items)),
+		Continue: list.GetContinue(),
+	}
+	for _, item := range list.Items {
+		table.Rows = append(table.Rows, ResourceTableRow{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Cells:     []interface{}{item.GetName(), item.GetNamespace(), formatAge(item.GetCreationTimestamp().Time)},
+		})
+	}
+	return table, nil
+}
+
+// listServerTable fetches a meta.k8s.io/v1 Table rendering of the list.
+// Returns (nil, nil) when the typed client has no raw REST access.
+func (m *MultiClusterClient) listServerTable(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string, opts ResourceListOptions) (*ResourceTable, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	rc := client.Discovery().RESTClient()
+	if rc == nil {
+		return nil, nil
+	}
+
+	req := rc.Get().AbsPath(resourcePathSegments(gvr, namespace)...).
+		SetHeader("Accept", resourceTableAcceptHeader).
+		Param("limit", fmt.Sprintf("%d", opts.Limit))
+	if opts.LabelSelector != "" {
+		req = req.Param("labelSelector", opts.LabelSelector)
+	}
+	if opts.FieldSelector != "" {
+		req = req.Param("fieldSelector", opts.FieldSelector)
+	}
+	if opts.Continue != "" {
+		req = req.Param("continue", opts.Continue)
+	}
+
+	raw, err := req.Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var t metav1.Table
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("failed to decode table response: %w", err)
+	}
+	if t.Kind != "Table" {
+		return nil, fmt.Errorf("server returned %q instead of Table", t.Kind)
+	}
+
+	table := &ResourceTable{
+		Cluster:       contextName,
+		Group:         gvr.Group,
+		Version:       gvr.Version,
+		Resource:      gvr.Resource,
+		Columns:       make([]ResourceTableColumn, 0, len(t.ColumnDefinitions)),
+		Rows:          make([]ResourceTableRow, 0, len(t.Rows)),
+		Continue:      t.Continue,
+		ServerPrinted: true,
+	}
+	for _, col := range t.ColumnDefinitions {
+		table.Columns = append(table.Columns, ResourceTableColumn{
+			Name:        col.Name,
+			Type:        col.Type,
+			Format:      col.Format,
+			Description: col.Description,
+			Priority:    col.Priority,
+		})
+	}
+	for _, row := range t.Rows {
+		r := ResourceTableRow{Cells: row.Cells}
+		if len(row.Object.Raw) > 0 {
+			var meta metav1.PartialObjectMetadata
+			if json.Unmarshal(row.Object.Raw, &meta) == nil {
+				r.Name = meta.Name
+				r.Namespace = meta.Namespace
+			}
+		}
+		table.Rows = append(table.Rows, r)
+	}
+	return table, nil
+}
+
+// resourcePathSegments builds the REST path for a collection of gvr.
+func resourcePathSegments(gvr schema.GroupVersionResource, namespace string) []string {
+	segments := []string{"/apis", gvr.Group, gvr.Version}
+	if gvr.Group == "" {
+		segments = []string{"/api", gvr.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	return append(segments, gvr.Resource)
+}
+
+// GetResource returns a single object of an arbitrary resource kind.
+func (m *MultiClusterClient) GetResource(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	return dyn.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// PatchResource applies a merge, JSON, or strategic-merge patch to a single
+// object of an arbitrary resource kind.
+func (m *MultiClusterClient) PatchResource(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	if !allowedExplorerPatchTypes[patchType] {
+		return nil, fmt.Errorf("unsupported patch type %q", patchType)
+	}
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	return dyn.Resource(gvr).Namespace(namespace).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+}
+
+// GetResourceSchema returns the openAPIV3Schema for a CRD-backed resource at
+// the requested version, for generating edit forms. Returns
+// ErrSchemaUnavailable for built-in kinds and CRDs without a schema.
+func (m *MultiClusterClient) GetResourceSchema(ctx context.Context, contextName string, gvr schema.GroupVersionResource) (map[string]interface{}, error) {
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	crd, err := dyn.Resource(gvrCRDs).Get(ctx, gvr.Resource+"."+gvr.Group, metav1.GetOptions{})
+	if err != nil {
+		return nil, ErrSchemaUnavailable
+	}
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		vMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _ := vMap["name"].(string); name != gvr.Version {
+			continue
+		}
+		schemaObj, found, _ := unstructured.NestedMap(vMap, "schema", "openAPIV3Schema")
+		if !found {
+			return nil, ErrSchemaUnavailable
+		}
+		return schemaObj, nil
+	}
+	return nil, ErrSchemaUnavailable
+}
+
+// GetResourceCapabilities runs one SelfSubjectAccessReview per explorer verb
+// and reports which actions the caller may perform on the resource kind in
+// the given namespace. Must be called with a client built from the user's
+// kubeconfig for the answer to reflect the user (see pkg/agent/server_rbac.go).
+func (m *MultiClusterClient) GetResourceCapabilities(ctx context.Context, contextName string, gvr schema.GroupVersionResource, namespace string) (map[string]bool, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RBACDefaultTimeout)
+	defer cancel()
+
+	caps := make(map[string]bool, len(explorerCapabilityVerbs))
+	for _, verb := range explorerCapabilityVerbs {
+		review := &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authv1.ResourceAttributes{
+					Verb:      verb,
+					Group:     gvr.Group,
+					Version:   gvr.Version,
+					Resource:  gvr.Resource,
+					Namespace: namespace,
+				},
+			},
+		}
+		result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to perform access review for %s: %w", verb, err)
+		}
+		caps[verb] = result.Status.Allowed
+	}
+	return caps, nil
+}
+
+func containsVerb(verbs []string, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
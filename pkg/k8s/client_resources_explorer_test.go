@@ -0,0 +1,219 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var widgetGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+func newWidgetCRD() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets.example.com"},
+		"spec": map[string]interface{}{
+			"group": "example.com",
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name": "v1",
+					"schema": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"spec": map[string]interface{}{"type": "object"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func newWidget(ns, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": name, "namespace": ns},
+		"spec":       map[string]interface{}{"size": "small"},
+	}}
+}
+
+func newExplorerTestClient(t *testing.T, objects ...runtime.Object) (*MultiClusterClient, *k8sfake.Clientset) {
+	t.Helper()
+	cs := k8sfake.NewSimpleClientset()
+	cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"get", "list", "watch"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: []string{"create"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"get", "list", "patch"}, ShortNames: []string{"wd"}},
+			},
+		},
+	}
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvrCRDs:   "CustomResourceDefinitionList",
+		widgetGVR: "WidgetList",
+	}, objects...)
+
+	m := newTestClient()
+	m.clients["c1"] = cs
+	m.dynamicClients["c1"] = dyn
+	return m, cs
+}
+
+func TestDiscoverAPIResources_FiltersAndFlagsCustom(t *testing.T) {
+	m, _ := newExplorerTestClient(t, newWidgetCRD())
+
+	resources, err := m.DiscoverAPIResources(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 listable resources (subresources and create-only kinds skipped), got %d: %+v", len(resources), resources)
+	}
+	// Sorted by group: core ("") first.
+	if resources[0].Resource != "pods" || resources[0].Custom {
+		t.Errorf("expected built-in pods first, got %+v", resources[0])
+	}
+	if resources[1].Resource != "widgets" || !resources[1].Custom {
+		t.Errorf("expected widgets flagged as custom, got %+v", resources[1])
+	}
+	if resources[1].GVR() != widgetGVR {
+		t.Errorf("GVR() = %v, want %v", resources[1].GVR(), widgetGVR)
+	}
+}
+
+func TestListResourceTable_FallsBackToDynamicList(t *testing.T) {
+	m, _ := newExplorerTestClient(t, newWidget("default", "w1"), newWidget("default", "w2"), newWidget("other", "w3"))
+
+	table, err := m.ListResourceTable(context.Background(), "c1", widgetGVR, "default", ResourceListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.ServerPrinted {
+		t.Error("fake clients cannot render Tables; expected fallback columns")
+	}
+	if len(table.Columns) != 3 || table.Columns[0].Name != "Name" {
+		t.Errorf("unexpected fallback columns: %+v", table.Columns)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("expected 2 rows in namespace default, got %d", len(table.Rows))
+	}
+	for _, row := range table.Rows {
+		if row.Namespace != "default" || len(row.Cells) != 3 {
+			t.Errorf("unexpected row: %+v", row)
+		}
+	}
+}
+
+func TestResourcePathSegments(t *testing.T) {
+	got := resourcePathSegments(schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "ns")
+	want := []string{"/api", "v1", "namespaces", "ns", "pods"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	got = resourcePathSegments(widgetGVR, "")
+	if len(got) != 4 || got[0] != "/apis" || got[1] != "example.com" || got[3] != "widgets" {
+		t.Errorf("unexpected cluster-scoped path: %v", got)
+	}
+}
+
+func TestGetAndPatchResource(t *testing.T) {
+	m, _ := newExplorerTestClient(t, newWidget("default", "w1"))
+	ctx := context.Background()
+
+	obj, err := m.GetResource(ctx, "c1", widgetGVR, "default", "w1")
+	if err != nil {
+		t.Fatalf("GetResource: %v", err)
+	}
+	if obj.GetName() != "w1" {
+		t.Errorf("got name %q", obj.GetName())
+	}
+
+	patched, err := m.PatchResource(ctx, "c1", widgetGVR, "default", "w1", types.MergePatchType, []byte(`{"spec":{"size":"large"}}`))
+	if err != nil {
+		t.Fatalf("PatchResource: %v", err)
+	}
+	size, _, _ := unstructured.NestedString(patched.Object, "spec", "size")
+	if size != "large" {
+		t.Errorf("expected patched size=large, got %q", size)
+	}
+
+	if _, err := m.PatchResource(ctx, "c1", widgetGVR, "default", "w1", types.ApplyPatchType, []byte(`{}`)); err == nil {
+		t.Error("expected apply patch type to be rejected")
+	}
+}
+
+func TestGetResourceSchema(t *testing.T) {
+	m, _ := newExplorerTestClient(t, newWidgetCRD())
+	ctx := context.Background()
+
+	s, err := m.GetResourceSchema(ctx, "c1", widgetGVR)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s["type"] != "object" {
+		t.Errorf("unexpected schema: %v", s)
+	}
+
+	_, err = m.GetResourceSchema(ctx, "c1", schema.GroupVersionResource{Group: "example.com", Version: "v2", Resource: "widgets"})
+	if !errors.Is(err, ErrSchemaUnavailable) {
+		t.Errorf("expected ErrSchemaUnavailable for unknown version, got %v", err)
+	}
+	_, err = m.GetResourceSchema(ctx, "c1", schema.GroupVersionResource{Version: "v1", Resource: "pods"})
+	if !errors.Is(err, ErrSchemaUnavailable) {
+		t.Errorf("expected ErrSchemaUnavailable for built-in kind, got %v", err)
+	}
+}
+
+func TestGetResourceCapabilities(t *testing.T) {
+	m, cs := newExplorerTestClient(t)
+	cs.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+		verb := review.Spec.ResourceAttributes.Verb
+		review.Status.Allowed = verb == "get" || verb == "list"
+		return true, review, nil
+	})
+
+	caps, err := m.GetResourceCapabilities(context.Background(), "c1", widgetGVR, "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !caps["get"] || !caps["list"] {
+		t.Errorf("expected get/list allowed, got %v", caps)
+	}
+	if caps["patch"] || caps["delete"] {
+		t.Errorf("expected patch/delete denied, got %v", caps)
+	}
+	if len(caps) != len(explorerCapabilityVerbs) {
+		t.Errorf("expected %d verbs, got %d", len(explorerCapabilityVerbs), len(caps))
+	}
+}
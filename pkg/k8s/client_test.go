@@ -41,6 +41,30 @@ func TestGetClient_ReturnsInjectedClient(t *testing.T) {
 	}
 }
 
+func TestNewInMemoryMultiClusterClient(t *testing.T) {
+	rawConfig := &api.Config{
+		Contexts: map[string]*api.Context{"mem": {Cluster: "mem"}},
+		Clusters: map[string]*api.Cluster{"mem": {Server: "https://mem.invalid"}},
+	}
+	fakeClient := k8sfake.NewSimpleClientset()
+	m := NewInMemoryMultiClusterClient(rawConfig, map[string]kubernetes.Interface{"mem": fakeClient}, nil)
+
+	retrieved, err := m.GetClient("mem")
+	if err != nil || retrieved != fakeClient {
+		t.Fatalf("GetClient(mem) = %v, %v; want the injected client", retrieved, err)
+	}
+	if _, err := m.GetClient("other"); err == nil {
+		t.Error("GetClient for an unknown context should fail instead of reading a kubeconfig")
+	}
+	clusters, err := m.ListClusters(context.Background())
+	if err != nil || len(clusters) != 1 || clusters[0].Name != "mem" {
+		t.Fatalf("ListClusters = %v, %v; want only mem", clusters, err)
+	}
+	if health, err := m.GetClusterHealth(context.Background(), "mem"); err != nil || !health.Reachable {
+		t.Fatalf("GetClusterHealth = %+v, %v; want reachable", health, err)
+	}
+}
+
 func TestMultiClusterClient_ListClusters(t *testing.T) {
 	// Setup a config with multiple contexts
 	rawConfig := &api.Config{
@@ -0,0 +1,388 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// consoleManagedLabel marks objects the console created; see workload_deploy.go.
+const (
+	consoleManagedLabel      = "kubestellar.io/managed-by"
+	consoleManagedLabelValue = "kubestellar-console"
+)
+
+// lastAppliedAnnotation holds the manifest of the last `kubectl apply`.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Sources of a RemovedAPIUsage.
+const (
+	UsageSourceManagedFields   = "managedFields"
+	UsageSourceLastApplied     = "lastAppliedConfiguration"
+	UsageSourceManagedWorkload = "managedWorkload"
+)
+
+// PDB risk reasons.
+const (
+	PDBRiskNoDisruptionsAllowed = "no_disruptions_allowed"
+	PDBRiskZeroMaxUnavailable   = "zero_max_unavailable"
+	PDBRiskMinAvailableAll      = "min_available_all_pods"
+)
+
+// ErrNotAnUpgrade is returned when the target version is not newer than the
+// cluster's current version.
+var ErrNotAnUpgrade = errors.New("target version is not newer than the current version")
+
+// KubeVersion is a Kubernetes major.minor release. Patch releases never
+// remove APIs, so they are not tracked.
+type KubeVersion struct {
+	Major int
+	Minor int
+}
+
+func (v KubeVersion) String() string { return fmt.Sprintf("%d.%d", v.Major, v.Minor) }
+
+// Less reports whether v is an older release than o.
+func (v KubeVersion) Less(o KubeVersion) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	return v.Minor < o.Minor
+}
+
+var kubeVersionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+
+// ParseKubeVersion accepts "1.30", "v1.30.2" and vendor strings such as
+// "v1.29.4-eks-036c24b" or "1.28+".
+func ParseKubeVersion(s string) (KubeVersion, error) {
+	m := kubeVersionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q", s)
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return KubeVersion{Major: major, Minor: minor}, nil
+}
+
+// RemovedAPI is a group/version of a kind that the API server stops serving
+// in RemovedIn. Replacement is the version to migrate to; it is also the
+// version GetUpgradeScan lists objects through.
+type RemovedAPI struct {
+	Group       string `json:"group"`
+	Version     string `json:"version"`
+	Kind        string `json:"kind"`
+	Resource    string `json:"resource"`
+	Replacement string `json:"replacement"`
+	RemovedIn   string `json:"removedIn"`
+}
+
+// APIVersion returns the removed group/version as written in manifests.
+func (r RemovedAPI) APIVersion() string {
+	return schema.GroupVersion{Group: r.Group, Version: r.Version}.String()
+}
+
+// ReplacementGVR returns the resource the kind is served at after removal.
+func (r RemovedAPI) ReplacementGVR() schema.GroupVersionResource {
+	gv, _ := schema.ParseGroupVersion(r.Replacement)
+	return gv.WithResource(r.Resource)
+}
+
+// RemovedAPIs lists the built-in APIs removed since 1.16, from the Kubernetes
+// deprecated API migration guide.
+var RemovedAPIs = []RemovedAPI{
+	{"extensions", "v1beta1", "Deployment", "deployments", "apps/v1", "1.16"},
+	{"extensions", "v1beta1", "DaemonSet", "daemonsets", "apps/v1", "1.16"},
+	{"extensions", "v1beta1", "ReplicaSet", "replicasets", "apps/v1", "1.16"},
+	{"extensions", "v1beta1", "NetworkPolicy", "networkpolicies", "networking.k8s.io/v1", "1.16"},
+	{"apps", "v1beta1", "Deployment", "deployments", "apps/v1", "1.16"},
+	{"apps", "v1beta2", "Deployment", "deployments", "apps/v1", "1.16"},
+	{"apps", "v1beta1", "StatefulSet", "statefulsets", "apps/v1", "1.16"},
+	{"apps", "v1beta2", "StatefulSet", "statefulsets", "apps/v1", "1.16"},
+	{"apps", "v1beta2", "DaemonSet", "daemonsets", "apps/v1", "1.16"},
+	{"apps", "v1beta2", "ReplicaSet", "replicasets", "apps/v1", "1.16"},
+	{"extensions", "v1beta1", "Ingress", "ingresses", "networking.k8s.io/v1", "1.22"},
+	{"networking.k8s.io", "v1beta1", "Ingress", "ingresses", "networking.k8s.io/v1", "1.22"},
+	{"networking.k8s.io", "v1beta1", "IngressClass", "ingressclasses", "networking.k8s.io/v1", "1.22"},
+	{"admissionregistration.k8s.io", "v1beta1", "MutatingWebhookConfiguration", "mutatingwebhookconfigurations", "admissionregistration.k8s.io/v1", "1.22"},
+	{"admissionregistration.k8s.io", "v1beta1", "ValidatingWebhookConfiguration", "validatingwebhookconfigurations", "admissionregistration.k8s.io/v1", "1.22"},
+	{"apiextensions.k8s.io", "v1beta1", "CustomResourceDefinition", "customresourcedefinitions", "apiextensions.k8s.io/v1", "1.22"},
+	{"apiregistration.k8s.io", "v1beta1", "APIService", "apiservices", "apiregistration.k8s.io/v1", "1.22"},
+	{"certificates.k8s.io", "v1beta1", "CertificateSigningRequest", "certificatesigningrequests", "certificates.k8s.io/v1", "1.22"},
+	{"coordination.k8s.io", "v1beta1", "Lease", "leases", "coordination.k8s.io/v1", "1.22"},
+	{"rbac.authorization.k8s.io", "v1beta1", "ClusterRole", "clusterroles", "rbac.authorization.k8s.io/v1", "1.22"},
+	{"rbac.authorization.k8s.io", "v1beta1", "ClusterRoleBinding", "clusterrolebindings", "rbac.authorization.k8s.io/v1", "1.22"},
+	{"rbac.authorization.k8s.io", "v1beta1", "Role", "roles", "rbac.authorization.k8s.io/v1", "1.22"},
+	{"rbac.authorization.k8s.io", "v1beta1", "RoleBinding", "rolebindings", "rbac.authorization.k8s.io/v1", "1.22"},
+	{"scheduling.k8s.io", "v1beta1", "PriorityClass", "priorityclasses", "scheduling.k8s.io/v1", "1.22"},
+	{"storage.k8s.io", "v1beta1", "CSIDriver", "csidrivers", "storage.k8s.io/v1", "1.22"},
+	{"storage.k8s.io", "v1beta1", "CSINode", "csinodes", "storage.k8s.io/v1", "1.22"},
+	{"storage.k8s.io", "v1beta1", "StorageClass", "storageclasses", "storage.k8s.io/v1", "1.22"},
+	{"storage.k8s.io", "v1beta1", "VolumeAttachment", "volumeattachments", "storage.k8s.io/v1", "1.22"},
+	{"batch", "v1beta1", "CronJob", "cronjobs", "batch/v1", "1.25"},
+	{"discovery.k8s.io", "v1beta1", "EndpointSlice", "endpointslices", "discovery.k8s.io/v1", "1.25"},
+	{"events.k8s.io", "v1beta1", "Event", "events", "events.k8s.io/v1", "1.25"},
+	{"autoscaling", "v2beta1", "HorizontalPodAutoscaler", "horizontalpodautoscalers", "autoscaling/v2", "1.25"},
+	{"policy", "v1beta1", "PodDisruptionBudget", "poddisruptionbudgets", "policy/v1", "1.25"},
+	{"node.k8s.io", "v1beta1", "RuntimeClass", "runtimeclasses", "node.k8s.io/v1", "1.25"},
+	{"autoscaling", "v2beta2", "HorizontalPodAutoscaler", "horizontalpodautoscalers", "autoscaling/v2", "1.26"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta1", "FlowSchema", "flowschemas", "flowcontrol.apiserver.k8s.io/v1", "1.26"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta1", "PriorityLevelConfiguration", "prioritylevelconfigurations", "flowcontrol.apiserver.k8s.io/v1", "1.26"},
+	{"storage.k8s.io", "v1beta1", "CSIStorageCapacity", "csistoragecapacities", "storage.k8s.io/v1", "1.27"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta2", "FlowSchema", "flowschemas", "flowcontrol.apiserver.k8s.io/v1", "1.29"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta2", "PriorityLevelConfiguration", "prioritylevelconfigurations", "flowcontrol.apiserver.k8s.io/v1", "1.29"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta3", "FlowSchema", "flowschemas", "flowcontrol.apiserver.k8s.io/v1", "1.32"},
+	{"flowcontrol.apiserver.k8s.io", "v1beta3", "PriorityLevelConfiguration", "prioritylevelconfigurations", "flowcontrol.apiserver.k8s.io/v1", "1.32"},
+}
+
+// RemovedAPIsBetween returns the APIs removed after current, up to and
+// including target.
+func RemovedAPIsBetween(current, target KubeVersion) []RemovedAPI {
+	var out []RemovedAPI
+	for _, api := range RemovedAPIs {
+		removed, err := ParseKubeVersion(api.RemovedIn)
+		if err != nil {
+			continue
+		}
+		if current.Less(removed) && !target.Less(removed) {
+			out = append(out, api)
+		}
+	}
+	return out
+}
+
+// LookupRemovedAPI returns the entry for apiVersion and kind in apis, if any.
+func LookupRemovedAPI(apis []RemovedAPI, apiVersion, kind string) (RemovedAPI, bool) {
+	for _, api := range apis {
+		if api.Kind == kind && api.APIVersion() == apiVersion {
+			return api, true
+		}
+	}
+	return RemovedAPI{}, false
+}
+
+// RemovedAPIUsage is an object that was last written through an API version
+// the target release no longer serves. Clients still using that version
+// (CI pipelines, Helm charts, operators) will fail after the upgrade.
+type RemovedAPIUsage struct {
+	Kind           string `json:"kind"`
+	Namespace      string `json:"namespace,omitempty"`
+	Name           string `json:"name"`
+	APIVersion     string `json:"apiVersion"`
+	Replacement    string `json:"replacement"`
+	RemovedIn      string `json:"removedIn"`
+	Source         string `json:"source"`
+	Manager        string `json:"manager,omitempty"`
+	ConsoleManaged bool   `json:"consoleManaged"`
+}
+
+// PDBRisk is a PodDisruptionBudget that will stall node drains during the
+// upgrade.
+type PDBRisk struct {
+	Namespace          string `json:"namespace"`
+	Name               string `json:"name"`
+	Reason             string `json:"reason"`
+	Message            string `json:"message"`
+	ExpectedPods       int32  `json:"expectedPods"`
+	CurrentHealthy     int32  `json:"currentHealthy"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed"`
+	ConsoleManaged     bool   `json:"consoleManaged"`
+}
+
+// UpgradeScan is what GetUpgradeScan found on one cluster.
+type UpgradeScan struct {
+	CurrentVersion string            `json:"currentVersion"`
+	TargetVersion  string            `json:"targetVersion"`
+	RemovedAPIs    []RemovedAPI      `json:"removedApis"`
+	Usages         []RemovedAPIUsage `json:"usages"`
+	PDBRisks       []PDBRisk         `json:"pdbRisks"`
+	// Skipped lists resources that could not be listed, e.g. for lack of
+	// RBAC. Their objects were not checked.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// GetUpgradeScan compares the cluster's server version with target and
+// checks every object of the kinds removed in between for writes through a
+// removed API version, and every PodDisruptionBudget for drain blockers.
+// ErrNotAnUpgrade is returned when target is not newer than the cluster.
+func (m *MultiClusterClient) GetUpgradeScan(ctx context.Context, contextName string, target KubeVersion) (*UpgradeScan, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	current, err := ParseKubeVersion(info.GitVersion)
+	if err != nil {
+		current, err = ParseKubeVersion(info.Major + "." + info.Minor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !current.Less(target) {
+		return nil, fmt.Errorf("%w: cluster runs %s", ErrNotAnUpgrade, current)
+	}
+
+	scan := &UpgradeScan{
+		CurrentVersion: current.String(),
+		TargetVersion:  target.String(),
+		RemovedAPIs:    RemovedAPIsBetween(current, target),
+		Usages:         []RemovedAPIUsage{},
+		PDBRisks:       []PDBRisk{},
+	}
+
+	if len(scan.RemovedAPIs) > 0 {
+		dyn, err := m.GetDynamicClient(contextName)
+		if err != nil {
+			return nil, err
+		}
+		byGVR := make(map[schema.GroupVersionResource][]RemovedAPI)
+		var order []schema.GroupVersionResource
+		for _, api := range scan.RemovedAPIs {
+			gvr := api.ReplacementGVR()
+			if _, ok := byGVR[gvr]; !ok {
+				order = append(order, gvr)
+			}
+			byGVR[gvr] = append(byGVR[gvr], api)
+		}
+		for _, gvr := range order {
+			list, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				scan.Skipped = append(scan.Skipped, gvr.GroupResource().String())
+				continue
+			}
+			for i := range list.Items {
+				scan.Usages = append(scan.Usages, removedAPIUsagesOf(&list.Items[i], byGVR[gvr])...)
+			}
+		}
+	}
+
+	pdbs, err := client.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+	switch {
+	case err == nil:
+		for i := range pdbs.Items {
+			if risk, ok := pdbRiskOf(&pdbs.Items[i]); ok {
+				scan.PDBRisks = append(scan.PDBRisks, risk)
+			}
+		}
+	case apierrors.IsForbidden(err) || apierrors.IsNotFound(err):
+		scan.Skipped = append(scan.Skipped, "poddisruptionbudgets.policy")
+	default:
+		return nil, err
+	}
+
+	sort.Slice(scan.Usages, func(i, j int) bool {
+		a, b := scan.Usages[i], scan.Usages[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return scan, nil
+}
+
+// removedAPIUsagesOf reports at most one usage per removed API: the
+// managedFields entry if there is one, otherwise the last-applied manifest.
+func removedAPIUsagesOf(obj *unstructured.Unstructured, apis []RemovedAPI) []RemovedAPIUsage {
+	var lastApplied string
+	if raw := obj.GetAnnotations()[lastAppliedAnnotation]; raw != "" {
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		if json.Unmarshal([]byte(raw), &head) == nil {
+			lastApplied = head.APIVersion
+		}
+	}
+	managed := obj.GetLabels()[consoleManagedLabel] == consoleManagedLabelValue
+
+	var out []RemovedAPIUsage
+	for _, api := range apis {
+		usage := RemovedAPIUsage{
+			Kind:           api.Kind,
+			Namespace:      obj.GetNamespace(),
+			Name:           obj.GetName(),
+			APIVersion:     api.APIVersion(),
+			Replacement:    api.Replacement,
+			RemovedIn:      api.RemovedIn,
+			ConsoleManaged: managed,
+		}
+		found := false
+		for _, mf := range obj.GetManagedFields() {
+			if mf.APIVersion == usage.APIVersion {
+				usage.Source, usage.Manager = UsageSourceManagedFields, mf.Manager
+				found = true
+				break
+			}
+		}
+		if !found && lastApplied == usage.APIVersion {
+			usage.Source = UsageSourceLastApplied
+			found = true
+		}
+		if found {
+			out = append(out, usage)
+		}
+	}
+	return out
+}
+
+// pdbRiskOf reports a PDB that lets no pod be evicted, so draining any node
+// hosting one of its pods waits forever. PDBs selecting no pods are ignored.
+func pdbRiskOf(pdb *policyv1.PodDisruptionBudget) (PDBRisk, bool) {
+	st := pdb.Status
+	if st.ExpectedPods == 0 {
+		return PDBRisk{}, false
+	}
+	risk := PDBRisk{
+		Namespace:          pdb.Namespace,
+		Name:               pdb.Name,
+		ExpectedPods:       st.ExpectedPods,
+		CurrentHealthy:     st.CurrentHealthy,
+		DisruptionsAllowed: st.DisruptionsAllowed,
+		ConsoleManaged:     pdb.Labels[consoleManagedLabel] == consoleManagedLabelValue,
+	}
+	spec := pdb.Spec
+	switch {
+	case spec.MaxUnavailable != nil && isZeroIntOrPercent(*spec.MaxUnavailable):
+		risk.Reason = PDBRiskZeroMaxUnavailable
+		risk.Message = "maxUnavailable is 0; no pod can ever be evicted"
+	case spec.MinAvailable != nil && requiresAllPods(*spec.MinAvailable, st.ExpectedPods):
+		risk.Reason = PDBRiskMinAvailableAll
+		risk.Message = fmt.Sprintf("minAvailable %s covers all %d pods; no pod can be evicted", spec.MinAvailable.String(), st.ExpectedPods)
+	case st.DisruptionsAllowed == 0:
+		risk.Reason = PDBRiskNoDisruptionsAllowed
+		risk.Message = fmt.Sprintf("no disruptions allowed with %d of %d pods healthy", st.CurrentHealthy, st.ExpectedPods)
+	default:
+		return PDBRisk{}, false
+	}
+	return risk, true
+}
+
+func isZeroIntOrPercent(v intstr.IntOrString) bool {
+	if v.Type == intstr.Int {
+		return v.IntValue() == 0
+	}
+	return v.StrVal == "0%" || v.StrVal == "0"
+}
+
+func requiresAllPods(v intstr.IntOrString, expected int32) bool {
+	n, err := intstr.GetScaledValueFromIntOrPercent(&v, int(expected), true)
+	return err == nil && n >= int(expected)
+}
@@ -0,0 +1,141 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseKubeVersion(t *testing.T) {
+	for in, want := range map[string]KubeVersion{
+		"1.30":                {1, 30},
+		"v1.29.4-eks-036c24b": {1, 29},
+		"1.28+":               {1, 28},
+	} {
+		got, err := ParseKubeVersion(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := ParseKubeVersion("latest")
+	assert.Error(t, err)
+}
+
+func TestRemovedAPIsBetween(t *testing.T) {
+	apis := RemovedAPIsBetween(KubeVersion{1, 25}, KubeVersion{1, 29})
+	var removedIn []string
+	for _, api := range apis {
+		removedIn = append(removedIn, api.RemovedIn)
+	}
+	assert.Subset(t, removedIn, []string{"1.26", "1.27", "1.29"})
+	assert.NotContains(t, removedIn, "1.25", "already removed on the current version")
+	assert.NotContains(t, removedIn, "1.32")
+
+	api, ok := LookupRemovedAPI(apis, "autoscaling/v2beta2", "HorizontalPodAutoscaler")
+	require.True(t, ok)
+	assert.Equal(t, "autoscaling/v2", api.Replacement)
+}
+
+func upgradeObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func upgradeScanClient(t *testing.T, gitVersion string, objects []runtime.Object, pdbs ...runtime.Object) *MultiClusterClient {
+	t.Helper()
+	listKinds := make(map[schema.GroupVersionResource]string)
+	for _, api := range RemovedAPIs {
+		listKinds[api.ReplacementGVR()] = api.Kind + "List"
+	}
+	typed := k8sfake.NewSimpleClientset(pdbs...)
+	typed.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: gitVersion}
+
+	client := &MultiClusterClient{}
+	client.SetClient("c1", typed)
+	client.SetDynamicClient("c1", dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...))
+	return client
+}
+
+func TestGetUpgradeScan_RemovedAPIUsage(t *testing.T) {
+	hpa := upgradeObject("autoscaling/v2", "HorizontalPodAutoscaler", "shop", "api")
+	hpa.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "kube-controller-manager", APIVersion: "autoscaling/v2"},
+		{Manager: "helm", APIVersion: "autoscaling/v2beta2"},
+	})
+	hpa.SetLabels(map[string]string{consoleManagedLabel: consoleManagedLabelValue})
+	flow := upgradeObject("flowcontrol.apiserver.k8s.io/v1", "FlowSchema", "", "tenant")
+	flow.SetAnnotations(map[string]string{lastAppliedAnnotation: `{"apiVersion":"flowcontrol.apiserver.k8s.io/v1beta2","kind":"FlowSchema"}`})
+	clean := upgradeObject("autoscaling/v2", "HorizontalPodAutoscaler", "shop", "web")
+
+	client := upgradeScanClient(t, "v1.25.3", []runtime.Object{hpa, flow, clean})
+	scan, err := client.GetUpgradeScan(context.Background(), "c1", KubeVersion{1, 29})
+	require.NoError(t, err)
+	assert.Equal(t, "1.25", scan.CurrentVersion)
+	assert.Equal(t, "1.29", scan.TargetVersion)
+
+	require.Len(t, scan.Usages, 2)
+	assert.Equal(t, RemovedAPIUsage{
+		Kind: "FlowSchema", Name: "tenant", APIVersion: "flowcontrol.apiserver.k8s.io/v1beta2",
+		Replacement: "flowcontrol.apiserver.k8s.io/v1", RemovedIn: "1.29", Source: UsageSourceLastApplied,
+	}, scan.Usages[0])
+	assert.Equal(t, RemovedAPIUsage{
+		Kind: "HorizontalPodAutoscaler", Namespace: "shop", Name: "api", APIVersion: "autoscaling/v2beta2",
+		Replacement: "autoscaling/v2", RemovedIn: "1.26", Source: UsageSourceManagedFields, Manager: "helm",
+		ConsoleManaged: true,
+	}, scan.Usages[1])
+}
+
+func TestGetUpgradeScan_PDBRisks(t *testing.T) {
+	pdb := func(name string, spec policyv1.PodDisruptionBudgetSpec, expected, healthy, allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec:       spec,
+			Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: expected, CurrentHealthy: healthy, DisruptionsAllowed: allowed},
+		}
+	}
+	zero := intstr.FromInt32(0)
+	all := intstr.FromString("100%")
+	one := intstr.FromInt32(1)
+
+	client := upgradeScanClient(t, "v1.31.0", nil,
+		pdb("frozen", policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &zero}, 3, 3, 0),
+		pdb("strict", policyv1.PodDisruptionBudgetSpec{MinAvailable: &all}, 2, 2, 0),
+		pdb("degraded", policyv1.PodDisruptionBudgetSpec{MinAvailable: &one}, 2, 1, 0),
+		pdb("fine", policyv1.PodDisruptionBudgetSpec{MinAvailable: &one}, 3, 3, 2),
+		pdb("empty", policyv1.PodDisruptionBudgetSpec{MaxUnavailable: &zero}, 0, 0, 0),
+	)
+	scan, err := client.GetUpgradeScan(context.Background(), "c1", KubeVersion{1, 32})
+	require.NoError(t, err)
+
+	reasons := map[string]string{}
+	for _, r := range scan.PDBRisks {
+		reasons[r.Name] = r.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"frozen":   PDBRiskZeroMaxUnavailable,
+		"strict":   PDBRiskMinAvailableAll,
+		"degraded": PDBRiskNoDisruptionsAllowed,
+	}, reasons)
+}
+
+func TestGetUpgradeScan_NotAnUpgrade(t *testing.T) {
+	client := upgradeScanClient(t, "v1.30.1", nil)
+	_, err := client.GetUpgradeScan(context.Background(), "c1", KubeVersion{1, 30})
+	assert.True(t, errors.Is(err, ErrNotAnUpgrade))
+}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+// Well-known node labels describing where a node runs. The beta labels are
+// still set by older clusters and some cloud providers.
+const (
+	labelRegion     = "topology.kubernetes.io/region"
+	labelZone       = "topology.kubernetes.io/zone"
+	labelRegionBeta = "failure-domain.beta.kubernetes.io/region"
+	labelZoneBeta   = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// ProviderKubernetes is reported for clusters with nodes but no sign of a
+// known distribution or cloud provider.
+const ProviderKubernetes = "kubernetes"
+
+// providerServerSuffixes maps API server host suffixes of managed services
+// to their provider. Identifiers match the frontend's CloudProvider type.
+var providerServerSuffixes = []struct {
+	suffix   string
+	provider string
+}{
+	{".openshiftapps.com", "openshift"},
+	{".eks.amazonaws.com", "eks"},
+	{".azmk8s.io", "aks"},
+	{".oraclecloud.com", "oci"},
+	{".aliyuncs.com", "alibaba"},
+	{".k8s.ondigitalocean.com", "digitalocean"},
+}
+
+// providerNodeLabelPrefixes maps node label key prefixes set by a provider's
+// node agent or node pools to that provider. Earlier entries win, so
+// OpenShift is reported over the cloud it runs on.
+var providerNodeLabelPrefixes = []struct {
+	prefix   string
+	provider string
+}{
+	{"node.openshift.io/", "openshift"},
+	{"eks.amazonaws.com/", "eks"},
+	{"cloud.google.com/gke-", "gke"},
+	{"kubernetes.azure.com/", "aks"},
+	{"oci.oraclecloud.com/", "oci"},
+	{"alibabacloud.com/", "alibaba"},
+	{"doks.digitalocean.com/", "digitalocean"},
+	{"node.coreweave.cloud/", "coreweave"},
+	{"minikube.k8s.io/", "minikube"},
+	{"k3s.io/", "k3s"},
+}
+
+// ClusterRegions returns the distinct regions of nodes, sorted.
+func ClusterRegions(nodes []NodeInfo) []string {
+	return distinctNodeLabel(nodes, labelRegion, labelRegionBeta)
+}
+
+// ClusterZones returns the distinct availability zones of nodes, sorted.
+func ClusterZones(nodes []NodeInfo) []string {
+	return distinctNodeLabel(nodes, labelZone, labelZoneBeta)
+}
+
+func distinctNodeLabel(nodes []NodeInfo, key, betaKey string) []string {
+	seen := make(map[string]bool)
+	for _, node := range nodes {
+		v := node.Labels[key]
+		if v == "" {
+			v = node.Labels[betaKey]
+		}
+		if v != "" {
+			seen[v] = true
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for v := range seen {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ClusterProvider infers the cloud provider or distribution of a cluster
+// from its API server URL and node labels, such as "eks", "gke" or
+// "openshift". It returns ProviderKubernetes when nodes carry no known
+// marker, and "" when there is nothing to go on.
+func ClusterProvider(server string, nodes []NodeInfo) string {
+	if u, err := url.Parse(server); err == nil && u.Hostname() != "" {
+		host := strings.ToLower(u.Hostname())
+		for _, s := range providerServerSuffixes {
+			if strings.HasSuffix(host, s.suffix) {
+				return s.provider
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return ""
+	}
+	for _, p := range providerNodeLabelPrefixes {
+		for _, node := range nodes {
+			for key := range node.Labels {
+				if strings.HasPrefix(key, p.prefix) {
+					return p.provider
+				}
+			}
+		}
+	}
+	for _, node := range nodes {
+		if strings.Contains(node.KubeletVersion, "+k3s") {
+			return "k3s"
+		}
+	}
+	return ProviderKubernetes
+}
+
+// ClusterVersion returns the lowest kubelet version among nodes, which is
+// the version every node is known to run at least. Unparseable versions
+// are skipped; "" means no node reported one.
+func ClusterVersion(nodes []NodeInfo) string {
+	lowest := ""
+	var lowestVersion *utilversion.Version
+	for _, node := range nodes {
+		v, err := utilversion.ParseGeneric(node.KubeletVersion)
+		if err != nil {
+			continue
+		}
+		if lowestVersion == nil || v.LessThan(lowestVersion) {
+			lowest, lowestVersion = node.KubeletVersion, v
+		}
+	}
+	return lowest
+}
+
+// CompareVersionPrefix compares a Kubernetes version such as
+// v1.29.4-eks-1a2b3c against a version such as 1.29, on only as many
+// components as want has: it returns 0 for v1.29.4 against 1.29, so "1.29"
+// stands for every 1.29 patch release. want needs at least major.minor.
+func CompareVersionPrefix(version, want string) (int, error) {
+	have, err := utilversion.ParseGeneric(version)
+	if err != nil {
+		return 0, err
+	}
+	w, err := utilversion.ParseGeneric(want)
+	if err != nil {
+		return 0, err
+	}
+	haveParts := have.Components()
+	for i, wp := range w.Components() {
+		var hp uint
+		if i < len(haveParts) {
+			hp = haveParts[i]
+		}
+		switch {
+		case hp < wp:
+			return -1, nil
+		case hp > wp:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterRegionsAndZones(t *testing.T) {
+	nodes := []NodeInfo{
+		{Name: "a", Labels: map[string]string{labelRegion: "us-east-1", labelZone: "us-east-1b"}},
+		{Name: "b", Labels: map[string]string{labelRegion: "us-east-1", labelZone: "us-east-1a"}},
+		{Name: "c", Labels: map[string]string{labelRegionBeta: "us-west-2", labelZoneBeta: "us-west-2a"}},
+		{Name: "d"},
+	}
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, ClusterRegions(nodes))
+	assert.Equal(t, []string{"us-east-1a", "us-east-1b", "us-west-2a"}, ClusterZones(nodes))
+	assert.Empty(t, ClusterRegions(nil))
+}
+
+func TestClusterProvider(t *testing.T) {
+	withLabel := func(key string) []NodeInfo {
+		return []NodeInfo{{Name: "n", Labels: map[string]string{"kubernetes.io/os": "linux", key: "x"}}}
+	}
+	tests := []struct {
+		name   string
+		server string
+		nodes  []NodeInfo
+		want   string
+	}{
+		{"eks server", "https://ABC123.gr7.us-east-1.eks.amazonaws.com", nil, "eks"},
+		{"aks server", "https://aks-dev-dns-abc123.hcp.westeurope.azmk8s.io:443", nil, "aks"},
+		{"rosa server", "https://api.prod.abcd.p1.openshiftapps.com:6443", nil, "openshift"},
+		{"gke node pool", "https://34.1.2.3", withLabel("cloud.google.com/gke-nodepool"), "gke"},
+		{"eks node group", "https://10.0.0.1:6443", withLabel("eks.amazonaws.com/nodegroup"), "eks"},
+		{"openshift on azure", "https://10.0.0.1:6443", []NodeInfo{
+			{Name: "a", Labels: map[string]string{"kubernetes.azure.com/cluster": "x"}},
+			{Name: "b", Labels: map[string]string{"node.openshift.io/os_id": "rhcos"}},
+		}, "openshift"},
+		{"k3s kubelet", "https://127.0.0.1:6443", []NodeInfo{{Name: "n", KubeletVersion: "v1.29.4+k3s1"}}, "k3s"},
+		{"plain nodes", "https://10.0.0.1:6443", withLabel("node-role.kubernetes.io/worker"), ProviderKubernetes},
+		{"nothing known", "https://10.0.0.1:6443", nil, ""},
+		{"bad server", "::not a url", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClusterProvider(tt.server, tt.nodes))
+		})
+	}
+}
+
+func TestClusterVersion(t *testing.T) {
+	nodes := []NodeInfo{
+		{Name: "a", KubeletVersion: "v1.30.1-eks-036c24b"},
+		{Name: "b", KubeletVersion: "v1.29.10-eks-036c24b"},
+		{Name: "c", KubeletVersion: "v1.29.9-eks-036c24b"},
+		{Name: "d", KubeletVersion: "unknown"},
+	}
+	assert.Equal(t, "v1.29.9-eks-036c24b", ClusterVersion(nodes))
+	assert.Equal(t, "", ClusterVersion([]NodeInfo{{Name: "x"}}))
+}
+
+func TestCompareVersionPrefix(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		cmp     int
+	}{
+		{"v1.29.4", "1.29", 0},
+		{"v1.29.4", "1.29.4", 0},
+		{"v1.29.4", "1.29.5", -1},
+		{"v1.29.4-gke.100", "1.28", 1},
+		{"v1.29", "1.29.0", 0},
+		{"v1.29", "1.29.1", -1},
+		{"v1.10.0", "1.9", 1},
+	}
+	for _, tt := range tests {
+		got, err := CompareVersionPrefix(tt.version, tt.want)
+		require.NoError(t, err, "%s vs %s", tt.version, tt.want)
+		assert.Equal(t, tt.cmp, got, "%s vs %s", tt.version, tt.want)
+	}
+
+	_, err := CompareVersionPrefix("v1.29.4", "latest")
+	assert.Error(t, err)
+	_, err = CompareVersionPrefix("dev", "1.29")
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConsoleFieldManager is the field manager of the console's writes to its
+// own resources, from the backend and from kc-agent. A spec change recorded
+// under any other manager was made outside the console.
+const ConsoleFieldManager = "kubestellar-console"
+
+// ConsoleSyncFieldManager is the field manager of the annotations the
+// console sets when it notices a change made outside it. It is kept apart
+// from ConsoleFieldManager so recording an outside change does not count as
+// the console changing the resource.
+const ConsoleSyncFieldManager = "kubestellar-console-sync"
+
+// legacyConsoleFieldManagers are the managers client-go derived from the
+// binary name for console writes made before ConsoleFieldManager was set.
+var legacyConsoleFieldManagers = []string{"console", "kc-agent"}
+
+// specFieldsKey is the top-level key of spec fields in a FieldsV1 set.
+const specFieldsKey = "f:spec"
+
+// SpecEditor is the field manager that last changed a resource's spec.
+type SpecEditor struct {
+	Manager string
+	Time    time.Time
+}
+
+// IsConsoleFieldManager reports whether manager is one the console writes
+// its own resources with.
+func IsConsoleFieldManager(manager string) bool {
+	return manager == ConsoleFieldManager || slices.Contains(legacyConsoleFieldManagers, manager)
+}
+
+// LastSpecEditor returns the manager whose managedFields entry owns spec
+// fields and was updated last, and false when no entry owns spec fields.
+// Status entries are skipped. On a tie the console wins, as timestamps only
+// have a precision of one second.
+func LastSpecEditor(entries []metav1.ManagedFieldsEntry) (SpecEditor, bool) {
+	var last SpecEditor
+	found := false
+	for _, entry := range entries {
+		if entry.Subresource != "" || !ownsSpecFields(entry) {
+			continue
+		}
+		var at time.Time
+		if entry.Time != nil {
+			at = entry.Time.Time
+		}
+		if found && !at.After(last.Time) && !(at.Equal(last.Time) && IsConsoleFieldManager(entry.Manager)) {
+			continue
+		}
+		last, found = SpecEditor{Manager: entry.Manager, Time: at}, true
+	}
+	return last, found
+}
+
+// ownsSpecFields reports whether a managedFields entry owns any spec field.
+func ownsSpecFields(entry metav1.ManagedFieldsEntry) bool {
+	if entry.FieldsV1 == nil {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+		return false
+	}
+	_, ok := fields[specFieldsKey]
+	return ok
+}
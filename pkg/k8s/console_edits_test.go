@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fieldsEntry returns a managedFields entry of manager at t owning fields.
+func fieldsEntry(manager string, t time.Time, fields, subresource string) metav1.ManagedFieldsEntry {
+	at := metav1.NewTime(t)
+	return metav1.ManagedFieldsEntry{
+		Manager:     manager,
+		Operation:   metav1.ManagedFieldsOperationUpdate,
+		Time:        &at,
+		FieldsType:  "FieldsV1",
+		FieldsV1:    &metav1.FieldsV1{Raw: []byte(fields)},
+		Subresource: subresource,
+	}
+}
+
+func TestLastSpecEditor(t *testing.T) {
+	created := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	edited := created.Add(time.Hour)
+	spec := `{"f:spec":{"f:replicas":{}}}`
+
+	tests := []struct {
+		name    string
+		entries []metav1.ManagedFieldsEntry
+		want    SpecEditor
+		wantOK  bool
+	}{
+		{
+			name: "outside edit after the console",
+			entries: []metav1.ManagedFieldsEntry{
+				fieldsEntry(ConsoleFieldManager, created, spec, ""),
+				fieldsEntry("kubectl-edit", edited, spec, ""),
+			},
+			want:   SpecEditor{Manager: "kubectl-edit", Time: edited},
+			wantOK: true,
+		},
+		{
+			name: "console edit after kubectl",
+			entries: []metav1.ManagedFieldsEntry{
+				fieldsEntry("kubectl-edit", created, spec, ""),
+				fieldsEntry(ConsoleFieldManager, edited, spec, ""),
+			},
+			want:   SpecEditor{Manager: ConsoleFieldManager, Time: edited},
+			wantOK: true,
+		},
+		{
+			name: "console wins a tie",
+			entries: []metav1.ManagedFieldsEntry{
+				fieldsEntry("kubectl-edit", edited, spec, ""),
+				fieldsEntry(ConsoleFieldManager, edited, spec, ""),
+			},
+			want:   SpecEditor{Manager: ConsoleFieldManager, Time: edited},
+			wantOK: true,
+		},
+		{
+			name: "status and metadata entries are skipped",
+			entries: []metav1.ManagedFieldsEntry{
+				fieldsEntry(ConsoleFieldManager, created, spec, ""),
+				fieldsEntry("kubectl-edit", edited, `{"f:status":{}}`, "status"),
+				fieldsEntry(ConsoleSyncFieldManager, edited, `{"f:metadata":{"f:annotations":{}}}`, ""),
+			},
+			want:   SpecEditor{Manager: ConsoleFieldManager, Time: created},
+			wantOK: true,
+		},
+		{
+			name:    "no spec owner",
+			entries: []metav1.ManagedFieldsEntry{fieldsEntry("kubectl-edit", edited, `{"f:metadata":{}}`, "")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LastSpecEditor(tt.entries)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want.Manager, got.Manager)
+			assert.True(t, tt.want.Time.Equal(got.Time), "time %v, want %v", got.Time, tt.want.Time)
+		})
+	}
+}
+
+func TestIsConsoleFieldManager(t *testing.T) {
+	assert.True(t, IsConsoleFieldManager(ConsoleFieldManager))
+	assert.True(t, IsConsoleFieldManager("kc-agent"))
+	assert.False(t, IsConsoleFieldManager(ConsoleSyncFieldManager))
+	assert.False(t, IsConsoleFieldManager("kubectl-edit"))
+}
@@ -2,35 +2,46 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 
 	"github.com/kubestellar/console/pkg/apis/v1alpha1"
 )
 
-// ConsolePersistence provides CRUD operations for console CRs
+// ConsolePersistence provides CRUD operations for console CRs.
+// The List*Page operations list one page narrowed by opts and return the
+// continue token of the next page, empty on the last one. A zero Limit
+// lists everything.
 type ConsolePersistence interface {
 	// ManagedWorkload operations
 	ListManagedWorkloads(ctx context.Context, namespace string) ([]v1alpha1.ManagedWorkload, error)
+	ListManagedWorkloadsPage(ctx context.Context, namespace string, opts ResourceListOptions) ([]v1alpha1.ManagedWorkload, string, error)
 	GetManagedWorkload(ctx context.Context, namespace, name string) (*v1alpha1.ManagedWorkload, error)
 	CreateManagedWorkload(ctx context.Context, mw *v1alpha1.ManagedWorkload) (*v1alpha1.ManagedWorkload, error)
 	UpdateManagedWorkload(ctx context.Context, mw *v1alpha1.ManagedWorkload) (*v1alpha1.ManagedWorkload, error)
+	UpdateManagedWorkloadStatus(ctx context.Context, mw *v1alpha1.ManagedWorkload) (*v1alpha1.ManagedWorkload, error)
+	AnnotateManagedWorkload(ctx context.Context, namespace, name string, annotations map[string]string) (*v1alpha1.ManagedWorkload, error)
 	DeleteManagedWorkload(ctx context.Context, namespace, name string) error
 
 	// ClusterGroup operations
 	ListClusterGroups(ctx context.Context, namespace string) ([]v1alpha1.ClusterGroup, error)
+	ListClusterGroupsPage(ctx context.Context, namespace string, opts ResourceListOptions) ([]v1alpha1.ClusterGroup, string, error)
 	GetClusterGroup(ctx context.Context, namespace, name string) (*v1alpha1.ClusterGroup, error)
 	CreateClusterGroup(ctx context.Context, cg *v1alpha1.ClusterGroup) (*v1alpha1.ClusterGroup, error)
 	UpdateClusterGroup(ctx context.Context, cg *v1alpha1.ClusterGroup) (*v1alpha1.ClusterGroup, error)
+	UpdateClusterGroupStatus(ctx context.Context, cg *v1alpha1.ClusterGroup) (*v1alpha1.ClusterGroup, error)
 	DeleteClusterGroup(ctx context.Context, namespace, name string) error
 
 	// WorkloadDeployment operations
 	ListWorkloadDeployments(ctx context.Context, namespace string) ([]v1alpha1.WorkloadDeployment, error)
+	ListWorkloadDeploymentsPage(ctx context.Context, namespace string, opts ResourceListOptions) ([]v1alpha1.WorkloadDeployment, string, error)
 	GetWorkloadDeployment(ctx context.Context, namespace, name string) (*v1alpha1.WorkloadDeployment, error)
 	CreateWorkloadDeployment(ctx context.Context, wd *v1alpha1.WorkloadDeployment) (*v1alpha1.WorkloadDeployment, error)
 	UpdateWorkloadDeployment(ctx context.Context, wd *v1alpha1.WorkloadDeployment) (*v1alpha1.WorkloadDeployment, error)
@@ -48,25 +59,39 @@ func NewConsolePersistence(client dynamic.Interface) ConsolePersistence {
 	return &consolePersistenceImpl{client: client}
 }
 
+// ManagedWorkloadCounter returns a function counting the ManagedWorkloads
+// of a namespace, for limits.Enforcer.CheckManagedWorkload.
+func ManagedWorkloadCounter(ctx context.Context, p ConsolePersistence) func(namespace string) (int, error) {
+	return func(namespace string) (int, error) {
+		workloads, err := p.ListManagedWorkloads(ctx, namespace)
+		return len(workloads), err
+	}
+}
+
 // =============================================================================
 // ManagedWorkload CRUD
 // =============================================================================
 
 func (c *consolePersistenceImpl) ListManagedWorkloads(ctx context.Context, namespace string) ([]v1alpha1.ManagedWorkload, error) {
-	list, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	workloads, _, err := c.ListManagedWorkloadsPage(ctx, namespace, ResourceListOptions{})
+	return workloads, err
+}
+
+func (c *consolePersistenceImpl) ListManagedWorkloadsPage(ctx context.Context, namespace string, opts ResourceListOptions) ([]v1alpha1.ManagedWorkload, string, error) {
+	list, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(namespace).List(ctx, opts.listOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to list ManagedWorkloads: %w", err)
+		return nil, "", fmt.Errorf("failed to list ManagedWorkloads: %w", err)
 	}
 
 	workloads := make([]v1alpha1.ManagedWorkload, 0, len(list.Items))
 	for _, item := range list.Items {
 		mw, err := v1alpha1.ManagedWorkloadFromUnstructured(&item)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert ManagedWorkload: %w", err)
+			return nil, "", fmt.Errorf("failed to convert ManagedWorkload: %w", err)
 		}
 		workloads = append(workloads, *mw)
 	}
-	return workloads, nil
+	return workloads, list.GetContinue(), nil
 }
 
 func (c *consolePersistenceImpl) GetManagedWorkload(ctx context.Context, namespace, name string) (*v1alpha1.ManagedWorkload, error) {
@@ -83,7 +108,7 @@ func (c *consolePersistenceImpl) CreateManagedWorkload(ctx context.Context, mw *
 		return nil, fmt.Errorf("failed to convert ManagedWorkload to unstructured: %w", err)
 	}
 
-	created, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(mw.Namespace).Create(ctx, u, metav1.CreateOptions{})
+	created, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(mw.Namespace).Create(ctx, u, metav1.CreateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ManagedWorkload: %w", err)
 	}
@@ -96,7 +121,7 @@ func (c *consolePersistenceImpl) UpdateManagedWorkload(ctx context.Context, mw *
 		return nil, fmt.Errorf("failed to convert ManagedWorkload to unstructured: %w", err)
 	}
 
-	updated, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(mw.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+	updated, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(mw.Namespace).Update(ctx, u, metav1.UpdateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update ManagedWorkload: %w", err)
 	}
@@ -106,6 +131,41 @@ func (c *consolePersistenceImpl) UpdateManagedWorkload(ctx context.Context, mw *
 	return v1alpha1.ManagedWorkloadFromUnstructured(updated)
 }
 
+func (c *consolePersistenceImpl) UpdateManagedWorkloadStatus(ctx context.Context, mw *v1alpha1.ManagedWorkload) (*v1alpha1.ManagedWorkload, error) {
+	u, err := mw.ToUnstructured()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert ManagedWorkload to unstructured: %w", err)
+	}
+
+	// Use the status subresource for status updates
+	updated, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(mw.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{FieldManager: ConsoleFieldManager})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ManagedWorkload status: %w", err)
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("update ManagedWorkload status returned nil object")
+	}
+	return v1alpha1.ManagedWorkloadFromUnstructured(updated)
+}
+
+// AnnotateManagedWorkload merges annotations into a ManagedWorkload's
+// metadata under ConsoleSyncFieldManager, leaving the rest of the object,
+// and who last changed its spec, untouched.
+func (c *consolePersistenceImpl) AnnotateManagedWorkload(ctx context.Context, namespace, name string, annotations map[string]string) (*v1alpha1.ManagedWorkload, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode annotations: %w", err)
+	}
+	patched, err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(namespace).Patch(ctx, name,
+		types.MergePatchType, patch, metav1.PatchOptions{FieldManager: ConsoleSyncFieldManager})
+	if err != nil {
+		return nil, fmt.Errorf("failed to annotate ManagedWorkload: %w", err)
+	}
+	return v1alpha1.ManagedWorkloadFromUnstructured(patched)
+}
+
 func (c *consolePersistenceImpl) DeleteManagedWorkload(ctx context.Context, namespace, name string) error {
 	err := c.client.Resource(v1alpha1.ManagedWorkloadGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
@@ -119,20 +179,25 @@ func (c *consolePersistenceImpl) DeleteManagedWorkload(ctx context.Context, name
 // =============================================================================
 
 func (c *consolePersistenceImpl) ListClusterGroups(ctx context.Context, namespace string) ([]v1alpha1.ClusterGroup, error) {
-	list, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	groups, _, err := c.ListClusterGroupsPage(ctx, namespace, ResourceListOptions{})
+	return groups, err
+}
+
+func (c *consolePersistenceImpl) ListClusterGroupsPage(ctx context.Context, namespace string, opts ResourceListOptions) ([]v1alpha1.ClusterGroup, string, error) {
+	list, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(namespace).List(ctx, opts.listOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to list ClusterGroups: %w", err)
+		return nil, "", fmt.Errorf("failed to list ClusterGroups: %w", err)
 	}
 
 	groups := make([]v1alpha1.ClusterGroup, 0, len(list.Items))
 	for _, item := range list.Items {
 		cg, err := v1alpha1.ClusterGroupFromUnstructured(&item)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert ClusterGroup: %w", err)
+			return nil, "", fmt.Errorf("failed to convert ClusterGroup: %w", err)
 		}
 		groups = append(groups, *cg)
 	}
-	return groups, nil
+	return groups, list.GetContinue(), nil
 }
 
 func (c *consolePersistenceImpl) GetClusterGroup(ctx context.Context, namespace, name string) (*v1alpha1.ClusterGroup, error) {
@@ -149,7 +214,7 @@ func (c *consolePersistenceImpl) CreateClusterGroup(ctx context.Context, cg *v1a
 		return nil, fmt.Errorf("failed to convert ClusterGroup to unstructured: %w", err)
 	}
 
-	created, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(cg.Namespace).Create(ctx, u, metav1.CreateOptions{})
+	created, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(cg.Namespace).Create(ctx, u, metav1.CreateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ClusterGroup: %w", err)
 	}
@@ -162,13 +227,30 @@ func (c *consolePersistenceImpl) UpdateClusterGroup(ctx context.Context, cg *v1a
 		return nil, fmt.Errorf("failed to convert ClusterGroup to unstructured: %w", err)
 	}
 
-	updated, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(cg.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+	updated, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(cg.Namespace).Update(ctx, u, metav1.UpdateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update ClusterGroup: %w", err)
 	}
 	return v1alpha1.ClusterGroupFromUnstructured(updated)
 }
 
+func (c *consolePersistenceImpl) UpdateClusterGroupStatus(ctx context.Context, cg *v1alpha1.ClusterGroup) (*v1alpha1.ClusterGroup, error) {
+	u, err := cg.ToUnstructured()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert ClusterGroup to unstructured: %w", err)
+	}
+
+	// Use the status subresource for status updates
+	updated, err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(cg.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{FieldManager: ConsoleFieldManager})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ClusterGroup status: %w", err)
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("update ClusterGroup status returned nil object")
+	}
+	return v1alpha1.ClusterGroupFromUnstructured(updated)
+}
+
 func (c *consolePersistenceImpl) DeleteClusterGroup(ctx context.Context, namespace, name string) error {
 	err := c.client.Resource(v1alpha1.ClusterGroupGVR).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
@@ -182,20 +264,25 @@ func (c *consolePersistenceImpl) DeleteClusterGroup(ctx context.Context, namespa
 // =============================================================================
 
 func (c *consolePersistenceImpl) ListWorkloadDeployments(ctx context.Context, namespace string) ([]v1alpha1.WorkloadDeployment, error) {
-	list, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	deployments, _, err := c.ListWorkloadDeploymentsPage(ctx, namespace, ResourceListOptions{})
+	return deployments, err
+}
+
+func (c *consolePersistenceImpl) ListWorkloadDeploymentsPage(ctx context.Context, namespace string, opts ResourceListOptions) ([]v1alpha1.WorkloadDeployment, string, error) {
+	list, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(namespace).List(ctx, opts.listOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to list WorkloadDeployments: %w", err)
+		return nil, "", fmt.Errorf("failed to list WorkloadDeployments: %w", err)
 	}
 
 	deployments := make([]v1alpha1.WorkloadDeployment, 0, len(list.Items))
 	for _, item := range list.Items {
 		wd, err := v1alpha1.WorkloadDeploymentFromUnstructured(&item)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert WorkloadDeployment: %w", err)
+			return nil, "", fmt.Errorf("failed to convert WorkloadDeployment: %w", err)
 		}
 		deployments = append(deployments, *wd)
 	}
-	return deployments, nil
+	return deployments, list.GetContinue(), nil
 }
 
 func (c *consolePersistenceImpl) GetWorkloadDeployment(ctx context.Context, namespace, name string) (*v1alpha1.WorkloadDeployment, error) {
@@ -212,7 +299,7 @@ func (c *consolePersistenceImpl) CreateWorkloadDeployment(ctx context.Context, w
 		return nil, fmt.Errorf("failed to convert WorkloadDeployment to unstructured: %w", err)
 	}
 
-	created, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(wd.Namespace).Create(ctx, u, metav1.CreateOptions{})
+	created, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(wd.Namespace).Create(ctx, u, metav1.CreateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create WorkloadDeployment: %w", err)
 	}
@@ -225,7 +312,7 @@ func (c *consolePersistenceImpl) UpdateWorkloadDeployment(ctx context.Context, w
 		return nil, fmt.Errorf("failed to convert WorkloadDeployment to unstructured: %w", err)
 	}
 
-	updated, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(wd.Namespace).Update(ctx, u, metav1.UpdateOptions{})
+	updated, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(wd.Namespace).Update(ctx, u, metav1.UpdateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update WorkloadDeployment: %w", err)
 	}
@@ -239,7 +326,7 @@ func (c *consolePersistenceImpl) UpdateWorkloadDeploymentStatus(ctx context.Cont
 	}
 
 	// Use the status subresource for status updates
-	updated, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(wd.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{})
+	updated, err := c.client.Resource(v1alpha1.WorkloadDeploymentGVR).Namespace(wd.Namespace).UpdateStatus(ctx, u, metav1.UpdateOptions{FieldManager: ConsoleFieldManager})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update WorkloadDeployment status: %w", err)
 	}
@@ -76,6 +76,11 @@ func TestConsoleResources(t *testing.T) {
 		t.Errorf("UpdateManagedWorkload failed: %v", err)
 	}
 
+	annotatedMW, err := cp.AnnotateManagedWorkload(ctx, ns, "mw1", map[string]string{v1alpha1.AnnotationLastModifiedBy: "kubectl-edit"})
+	if err != nil || annotatedMW.Annotations[v1alpha1.AnnotationLastModifiedBy] != "kubectl-edit" || annotatedMW.Labels["foo"] != "bar" {
+		t.Errorf("AnnotateManagedWorkload failed: %v", err)
+	}
+
 	// 3. Test ClusterGroup CRUD
 	listCG, err := cp.ListClusterGroups(ctx, ns)
 	if err != nil || len(listCG) != 1 {
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// GeneratedNetworkPolicyLabel marks the NetworkPolicies the console generates
+// for a workload, as opposed to those copied from the source cluster.
+const GeneratedNetworkPolicyLabel = "kubestellar.io/generated"
+
+// generatedNetworkPolicySuffix is appended to the workload name to name its
+// generated NetworkPolicy.
+const generatedNetworkPolicySuffix = "-console-baseline"
+
+// dnsPort is the port the generated policy allows egress to for DNS.
+const dnsPort = 53
+
+// namespaceNameLabel is the label Kubernetes sets on every namespace to its
+// name, which lets a policy select a namespace by name.
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// GenerateNetworkPolicy builds the baseline NetworkPolicy for workload. It
+// selects the workload's pods and allows ingress from pods in the same
+// namespace, egress to DNS on any host, and the rules for cfg's allowed
+// peers; everything else in or out of the pods is denied.
+func GenerateNetworkPolicy(workload *unstructured.Unstructured, cfg v1alpha1.GeneratedNetworkPolicy) (*unstructured.Unstructured, error) {
+	podLabels := extractPodTemplateLabels(workload)
+	if len(podLabels) == 0 {
+		return nil, fmt.Errorf("%s %s has no pod labels to select", workload.GetKind(), workload.GetName())
+	}
+
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	dns := intstr.FromInt32(dnsPort)
+	np := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workload.GetName() + generatedNetworkPolicySuffix,
+			Namespace: workload.GetNamespace(),
+			Labels:    map[string]string{GeneratedNetworkPolicyLabel: "true"},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: podLabels},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+			}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: &dns}, {Protocol: &tcp, Port: &dns}},
+			}},
+		},
+	}
+	for _, peer := range cfg.AllowedPeers {
+		to := []networkingv1.NetworkPolicyPeer{policyPeer(peer)}
+		ports := policyPorts(peer.Ports)
+		if peer.AllowsIngress() {
+			np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: to, Ports: ports})
+		}
+		if peer.AllowsEgress() {
+			np.Spec.Egress = append(np.Spec.Egress, networkingv1.NetworkPolicyEgressRule{To: to, Ports: ports})
+		}
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(np)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert NetworkPolicy: %w", err)
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+func policyPeer(peer v1alpha1.NetworkPolicyPeer) networkingv1.NetworkPolicyPeer {
+	if peer.CIDR != "" {
+		return networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: peer.CIDR}}
+	}
+	p := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: peer.PodLabels}}
+	if peer.Namespace != "" {
+		p.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: peer.Namespace}}
+	}
+	return p
+}
+
+func policyPorts(ports []v1alpha1.NetworkPolicyPort) []networkingv1.NetworkPolicyPort {
+	out := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, p := range ports {
+		protocol := corev1.ProtocolTCP
+		if p.Protocol != "" {
+			protocol = corev1.Protocol(p.Protocol)
+		}
+		port := intstr.FromInt32(p.Port)
+		out = append(out, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+func netpolTestWorkload(podLabels map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "vllm", "namespace": "llm"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": podLabels},
+			},
+		},
+	}}
+}
+
+func TestGenerateNetworkPolicy_Baseline(t *testing.T) {
+	obj, err := GenerateNetworkPolicy(netpolTestWorkload(map[string]interface{}{"app": "vllm"}), v1alpha1.GeneratedNetworkPolicy{Enabled: true})
+	require.NoError(t, err)
+
+	var np networkingv1.NetworkPolicy
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &np))
+	assert.Equal(t, "vllm-console-baseline", np.Name)
+	assert.Equal(t, "llm", np.Namespace)
+	assert.Equal(t, "true", np.Labels[GeneratedNetworkPolicyLabel])
+	assert.Equal(t, map[string]string{"app": "vllm"}, np.Spec.PodSelector.MatchLabels)
+	assert.ElementsMatch(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, np.Spec.PolicyTypes)
+
+	require.Len(t, np.Spec.Ingress, 1)
+	require.Len(t, np.Spec.Ingress[0].From, 1)
+	assert.NotNil(t, np.Spec.Ingress[0].From[0].PodSelector, "ingress from the same namespace")
+	assert.Nil(t, np.Spec.Ingress[0].From[0].NamespaceSelector)
+
+	require.Len(t, np.Spec.Egress, 1)
+	assert.Empty(t, np.Spec.Egress[0].To, "DNS to any host")
+	require.Len(t, np.Spec.Egress[0].Ports, 2)
+	for _, p := range np.Spec.Egress[0].Ports {
+		assert.Equal(t, int32(53), p.Port.IntVal)
+	}
+}
+
+func TestGenerateNetworkPolicy_AllowedPeers(t *testing.T) {
+	obj, err := GenerateNetworkPolicy(netpolTestWorkload(map[string]interface{}{"app": "vllm"}), v1alpha1.GeneratedNetworkPolicy{
+		Enabled: true,
+		AllowedPeers: []v1alpha1.NetworkPolicyPeer{
+			{Direction: v1alpha1.NetworkPolicyDirectionIngress, Namespace: "gateway", PodLabels: map[string]string{"app": "router"}, Ports: []v1alpha1.NetworkPolicyPort{{Port: 8000}}},
+			{Direction: v1alpha1.NetworkPolicyDirectionEgress, CIDR: "10.20.0.0/16", Ports: []v1alpha1.NetworkPolicyPort{{Port: 443, Protocol: "TCP"}}},
+			{Namespace: "monitoring"},
+		},
+	})
+	require.NoError(t, err)
+	var np networkingv1.NetworkPolicy
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &np))
+
+	require.Len(t, np.Spec.Ingress, 3, "baseline, gateway and monitoring")
+	gateway := np.Spec.Ingress[1]
+	assert.Equal(t, map[string]string{namespaceNameLabel: "gateway"}, gateway.From[0].NamespaceSelector.MatchLabels)
+	assert.Equal(t, map[string]string{"app": "router"}, gateway.From[0].PodSelector.MatchLabels)
+	require.Len(t, gateway.Ports, 1)
+	assert.Equal(t, int32(8000), gateway.Ports[0].Port.IntVal)
+	assert.Equal(t, "TCP", string(*gateway.Ports[0].Protocol))
+
+	require.Len(t, np.Spec.Egress, 3, "DNS, the CIDR and monitoring")
+	assert.Equal(t, "10.20.0.0/16", np.Spec.Egress[1].To[0].IPBlock.CIDR)
+	assert.Equal(t, map[string]string{namespaceNameLabel: "monitoring"}, np.Spec.Egress[2].To[0].NamespaceSelector.MatchLabels)
+	assert.Empty(t, np.Spec.Egress[2].Ports, "every port")
+}
+
+func TestGenerateNetworkPolicy_NoPodLabels(t *testing.T) {
+	_, err := GenerateNetworkPolicy(netpolTestWorkload(nil), v1alpha1.GeneratedNetworkPolicy{Enabled: true})
+	assert.ErrorContains(t, err, "no pod labels")
+}
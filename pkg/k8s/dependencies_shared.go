@@ -6,6 +6,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
 )
 
 type DependencyKind string
@@ -110,6 +112,9 @@ type Dependency struct {
 	Object    *unstructured.Unstructured
 	Order     int
 	Optional  bool
+	// Generated is set on dependencies the console creates rather than
+	// copies from the source cluster.
+	Generated bool
 }
 
 type DependencyBundle struct {
@@ -122,4 +127,14 @@ type DependencyBundle struct {
 type DeployOptions struct {
 	DeployedBy string
 	GroupName  string
+	// NetworkPolicy, when enabled, adds the workload's generated baseline
+	// NetworkPolicy to its dependencies.
+	NetworkPolicy *v1alpha1.GeneratedNetworkPolicy
+	// ClusterReplicas overrides the replica count per target cluster,
+	// including with 0. Clusters it does not list keep the count passed to
+	// DeployWorkload.
+	ClusterReplicas map[string]int32
+	// Manifest, when set, is deployed in place of the workload read from the
+	// source cluster. Its dependencies are still read from there.
+	Manifest *unstructured.Unstructured
 }
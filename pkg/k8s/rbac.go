@@ -29,6 +29,11 @@ const maxConcurrentClusterRBACQueries = 5
 // finishes comfortably within budget.
 const perClusterRBACTimeout = 15 * time.Second
 
+// maxConcurrentAccessReviews bounds how many access reviews CheckAccessBatch
+// sends to one cluster at a time, so a large UI preflight does not flood the
+// apiserver.
+const maxConcurrentAccessReviews = 8
+
 // RBACDefaultTimeout is the per-cluster timeout for standard RBAC queries.
 // Used by both pkg/api/handlers/rbac.go and pkg/agent/server_rbac.go for
 // single-cluster permission checks and RBAC data fetches. Centralized here
@@ -0,0 +1,207 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/kubestellar/console/pkg/models"
+	"golang.org/x/sync/errgroup"
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultPermissionNamespace is the namespace a permission matrix is computed
+// for when the caller names none. SelfSubjectRulesReview always needs one.
+const DefaultPermissionNamespace = "default"
+
+// rbacWildcard matches any verb, group or resource in an RBAC rule.
+const rbacWildcard = "*"
+
+// PermissionMatrixOperations are the console actions a permission matrix
+// reports on, in the order they are returned. Namespaced operations are
+// evaluated in the matrix's namespace.
+var PermissionMatrixOperations = []models.AuthzCheck{
+	{Verb: "list", Resource: "pods"},
+	{Verb: "delete", Resource: "pods"},
+	{Verb: "get", Resource: "pods", Subresource: "log"},
+	{Verb: "create", Resource: "pods", Subresource: "exec"},
+	{Verb: "create", Resource: "pods", Subresource: "portforward"},
+	{Verb: "list", Resource: "deployments", Group: "apps"},
+	{Verb: "create", Resource: "deployments", Group: "apps"},
+	{Verb: "patch", Resource: "deployments", Group: "apps"},
+	{Verb: "delete", Resource: "deployments", Group: "apps"},
+	{Verb: "patch", Resource: "deployments", Group: "apps", Subresource: "scale"},
+	{Verb: "patch", Resource: "statefulsets", Group: "apps"},
+	{Verb: "patch", Resource: "daemonsets", Group: "apps"},
+	{Verb: "create", Resource: "jobs", Group: "batch"},
+	{Verb: "list", Resource: "services"},
+	{Verb: "list", Resource: "configmaps"},
+	{Verb: "create", Resource: "configmaps"},
+	{Verb: "get", Resource: "secrets"},
+	{Verb: "list", Resource: "events"},
+	{Verb: "create", Resource: "serviceaccounts"},
+	{Verb: "create", Resource: "rolebindings", Group: "rbac.authorization.k8s.io"},
+	{Verb: "list", Resource: "namespaces"},
+	{Verb: "create", Resource: "namespaces"},
+	{Verb: "list", Resource: "nodes"},
+	{Verb: "patch", Resource: "nodes"},
+}
+
+// clusterScopedMatrixResources are the PermissionMatrixOperations resources
+// that have no namespace.
+var clusterScopedMatrixResources = map[string]bool{
+	"namespaces": true,
+	"nodes":      true,
+}
+
+// GetPermissionMatrix lists what the client's own identity may do in
+// namespace of a cluster with a SelfSubjectRulesReview, and evaluates
+// PermissionMatrixOperations against the rules. Rules from ClusterRoleBindings
+// are included, so cluster-scoped operations are covered too. When the
+// cluster reports the rules as incomplete, as it does with webhook
+// authorizers, the operations the rules do not allow are checked with
+// SelfSubjectAccessReviews instead of being reported as denied.
+func (m *MultiClusterClient) GetPermissionMatrix(ctx context.Context, contextName, namespace string) (*models.ClusterPermissionMatrix, error) {
+	if namespace == "" {
+		namespace = DefaultPermissionNamespace
+	}
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := client.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &authv1.SelfSubjectRulesReview{
+		Spec: authv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform rules review: %w", err)
+	}
+
+	matrix := &models.ClusterPermissionMatrix{
+		Cluster:         contextName,
+		Namespace:       namespace,
+		Incomplete:      review.Status.Incomplete,
+		EvaluationError: review.Status.EvaluationError,
+	}
+	for _, rule := range review.Status.ResourceRules {
+		matrix.ResourceRules = append(matrix.ResourceRules, models.PermissionResourceRule{
+			Verbs:         rule.Verbs,
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	for _, rule := range review.Status.NonResourceRules {
+		matrix.NonResourceRules = append(matrix.NonResourceRules, models.PermissionNonResourceRule{
+			Verbs:           rule.Verbs,
+			NonResourceURLs: rule.NonResourceURLs,
+		})
+	}
+
+	matrix.Operations = make([]models.AuthzCheckResult, len(PermissionMatrixOperations))
+	var uncovered []models.AuthzCheck
+	var uncoveredIdx []int
+	for i, op := range PermissionMatrixOperations {
+		if !clusterScopedMatrixResources[op.Resource] {
+			op.Namespace = namespace
+		}
+		matrix.Operations[i] = models.AuthzCheckResult{AuthzCheck: op, Allowed: RulesAllow(matrix.ResourceRules, op)}
+		if !matrix.Operations[i].Allowed && matrix.Incomplete {
+			uncovered = append(uncovered, op)
+			uncoveredIdx = append(uncoveredIdx, i)
+		}
+	}
+	if len(uncovered) > 0 {
+		results, err := m.CheckAccessBatch(ctx, contextName, nil, uncovered)
+		if err != nil {
+			return nil, err
+		}
+		for j, result := range results {
+			matrix.Operations[uncoveredIdx[j]] = result
+		}
+	}
+	return matrix, nil
+}
+
+// GetAllPermissionMatrices returns the permission matrix of namespace on
+// every cluster, in cluster order. A cluster that cannot be reviewed keeps
+// its entry with Error set, so one unreachable cluster does not hide the
+// others.
+func (m *MultiClusterClient) GetAllPermissionMatrices(ctx context.Context, namespace string) ([]models.ClusterPermissionMatrix, error) {
+	clusters, err := m.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ClusterPermissionMatrix, len(clusters))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentClusterRBACQueries)
+
+	for i, cluster := range clusters {
+		i, cluster := i, cluster
+		g.Go(func() error {
+			clusterCtx, cancel := context.WithTimeout(gctx, perClusterRBACTimeout)
+			defer cancel()
+
+			matrix, err := m.GetPermissionMatrix(clusterCtx, cluster.Name, namespace)
+			if err != nil {
+				ns := namespace
+				if ns == "" {
+					ns = DefaultPermissionNamespace
+				}
+				result[i] = models.ClusterPermissionMatrix{Cluster: cluster.Name, Namespace: ns, Error: err.Error()}
+				return nil
+			}
+			result[i] = *matrix
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return result, nil
+}
+
+// RulesAllow reports whether any of rules grants check, using the RBAC
+// matching rules: "*" matches any verb, group or resource, "*/sub" the
+// subresource sub of any resource, and a rule with resource names only
+// covers checks naming one of those objects.
+func RulesAllow(rules []models.PermissionResourceRule, check models.AuthzCheck) bool {
+	resource := check.Resource
+	if check.Subresource != "" {
+		resource += "/" + check.Subresource
+	}
+	for _, rule := range rules {
+		if !ruleMatches(rule.Verbs, check.Verb) || !ruleMatches(rule.APIGroups, check.Group) {
+			continue
+		}
+		if !ruleMatchesResource(rule.Resources, resource, check.Subresource) {
+			continue
+		}
+		if len(rule.ResourceNames) > 0 && !slices.Contains(rule.ResourceNames, check.Name) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func ruleMatches(values []string, want string) bool {
+	for _, v := range values {
+		if v == rbacWildcard || v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleMatchesResource(resources []string, want, subresource string) bool {
+	for _, r := range resources {
+		if r == rbacWildcard || r == want || (subresource != "" && r == rbacWildcard+"/"+subresource) {
+			return true
+		}
+	}
+	return false
+}
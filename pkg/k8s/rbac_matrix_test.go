@@ -0,0 +1,176 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// rulesReviewClientset answers SelfSubjectRulesReviews with status.
+func rulesReviewClientset(status authv1.SubjectRulesReviewStatus) *k8sfake.Clientset {
+	clientset := k8sfake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectRulesReview).DeepCopy()
+		review.Status = status
+		return true, review, nil
+	})
+	return clientset
+}
+
+// matrixOperation returns the verdict for verb on resource in matrix.
+func matrixOperation(t *testing.T, matrix *models.ClusterPermissionMatrix, verb, resource, subresource string) models.AuthzCheckResult {
+	t.Helper()
+	for _, op := range matrix.Operations {
+		if op.Verb == verb && op.Resource == resource && op.Subresource == subresource {
+			return op
+		}
+	}
+	t.Fatalf("no operation %s %s/%s", verb, resource, subresource)
+	return models.AuthzCheckResult{}
+}
+
+func TestRulesAllow(t *testing.T) {
+	rules := []models.PermissionResourceRule{
+		{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+		{Verbs: []string{"*"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}},
+		{Verbs: []string{"patch"}, APIGroups: []string{"*"}, Resources: []string{"*/scale"}},
+		{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"registry"}},
+	}
+	tests := []struct {
+		check models.AuthzCheck
+		want  bool
+	}{
+		{models.AuthzCheck{Verb: "list", Resource: "pods"}, true},
+		{models.AuthzCheck{Verb: "delete", Resource: "pods"}, false},
+		{models.AuthzCheck{Verb: "get", Resource: "pods", Subresource: "log"}, true},
+		{models.AuthzCheck{Verb: "create", Resource: "pods", Subresource: "exec"}, false},
+		{models.AuthzCheck{Verb: "delete", Resource: "deployments", Group: "apps"}, true},
+		{models.AuthzCheck{Verb: "delete", Resource: "deployments"}, false},
+		{models.AuthzCheck{Verb: "patch", Resource: "statefulsets", Group: "apps", Subresource: "scale"}, true},
+		{models.AuthzCheck{Verb: "get", Resource: "secrets"}, false},
+		{models.AuthzCheck{Verb: "get", Resource: "secrets", Name: "registry"}, true},
+		{models.AuthzCheck{Verb: "get", Resource: "secrets", Name: "token"}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, RulesAllow(rules, tt.check), "%+v", tt.check)
+	}
+}
+
+func TestGetPermissionMatrix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("operations follow the rules", func(t *testing.T) {
+		t.Parallel()
+		clientset := rulesReviewClientset(authv1.SubjectRulesReviewStatus{
+			ResourceRules: []authv1.ResourceRule{
+				{Verbs: []string{"get", "list", "watch"}, APIGroups: []string{"", "apps"}, Resources: []string{"pods", "deployments", "nodes"}},
+			},
+			NonResourceRules: []authv1.NonResourceRule{{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}}},
+		})
+		var reviewed string
+		clientset.PrependReactor("create", "selfsubjectrulesreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			reviewed = action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectRulesReview).Spec.Namespace
+			return false, nil, nil
+		})
+
+		client := newRBACPermissionsClient(clientset)
+		matrix, err := client.GetPermissionMatrix(context.Background(), testRBACPermissionsCluster, "")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPermissionNamespace, reviewed)
+		assert.Equal(t, DefaultPermissionNamespace, matrix.Namespace)
+		assert.Len(t, matrix.Operations, len(PermissionMatrixOperations))
+		assert.Len(t, matrix.ResourceRules, 1)
+		assert.Len(t, matrix.NonResourceRules, 1)
+
+		assert.True(t, matrixOperation(t, matrix, "list", "pods", "").Allowed)
+		assert.Equal(t, DefaultPermissionNamespace, matrixOperation(t, matrix, "list", "pods", "").Namespace)
+		assert.False(t, matrixOperation(t, matrix, "delete", "pods", "").Allowed)
+		assert.True(t, matrixOperation(t, matrix, "list", "deployments", "").Allowed)
+		assert.False(t, matrixOperation(t, matrix, "patch", "deployments", "scale").Allowed)
+		nodes := matrixOperation(t, matrix, "list", "nodes", "")
+		assert.True(t, nodes.Allowed)
+		assert.Empty(t, nodes.Namespace, "nodes are cluster-scoped")
+	})
+
+	t.Run("incomplete rules fall back to access reviews", func(t *testing.T) {
+		t.Parallel()
+		clientset := rulesReviewClientset(authv1.SubjectRulesReviewStatus{
+			ResourceRules:   []authv1.ResourceRule{{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"pods"}}},
+			Incomplete:      true,
+			EvaluationError: "webhook authorizer does not support rules review",
+		})
+		var reviews atomic.Int32
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			reviews.Add(1)
+			attrs := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview).Spec.ResourceAttributes
+			allowed := attrs.Verb == "delete" && attrs.Resource == "pods"
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: allowed}}, nil
+		})
+
+		client := newRBACPermissionsClient(clientset)
+		matrix, err := client.GetPermissionMatrix(context.Background(), testRBACPermissionsCluster, "shop")
+		require.NoError(t, err)
+		assert.True(t, matrix.Incomplete)
+		assert.NotEmpty(t, matrix.EvaluationError)
+		assert.Equal(t, int32(len(PermissionMatrixOperations)-1), reviews.Load(), "only operations the rules do not allow are reviewed")
+		assert.True(t, matrixOperation(t, matrix, "list", "pods", "").Allowed)
+		assert.True(t, matrixOperation(t, matrix, "delete", "pods", "").Allowed)
+		assert.Equal(t, "shop", matrixOperation(t, matrix, "delete", "pods", "").Namespace)
+		assert.False(t, matrixOperation(t, matrix, "get", "secrets", "").Allowed)
+	})
+
+	t.Run("review errors are returned", func(t *testing.T) {
+		t.Parallel()
+		clientset := k8sfake.NewSimpleClientset()
+		clientset.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("forbidden")
+		})
+		client := newRBACPermissionsClient(clientset)
+		_, err := client.GetPermissionMatrix(context.Background(), testRBACPermissionsCluster, "shop")
+		assert.ErrorContains(t, err, "forbidden")
+
+		_, err = client.GetPermissionMatrix(context.Background(), "missing", "shop")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetAllPermissionMatrices(t *testing.T) {
+	m, _ := NewMultiClusterClient("")
+	m.rawConfig = &api.Config{Contexts: map[string]*api.Context{
+		"c1": {Cluster: "cl1"},
+		"c2": {Cluster: "cl2"},
+	}}
+	m.clients["c1"] = rulesReviewClientset(authv1.SubjectRulesReviewStatus{
+		ResourceRules: []authv1.ResourceRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+	})
+	failing := k8sfake.NewSimpleClientset()
+	failing.PrependReactor("create", "selfsubjectrulesreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	m.clients["c2"] = failing
+
+	matrices, err := m.GetAllPermissionMatrices(context.Background(), "shop")
+	require.NoError(t, err)
+	require.Len(t, matrices, 2)
+	byCluster := map[string]models.ClusterPermissionMatrix{}
+	for _, matrix := range matrices {
+		byCluster[matrix.Cluster] = matrix
+	}
+	for _, op := range byCluster["c1"].Operations {
+		assert.True(t, op.Allowed, "%+v", op.AuthzCheck)
+	}
+	assert.Empty(t, byCluster["c1"].Error)
+	assert.Contains(t, byCluster["c2"].Error, "connection refused")
+	assert.Equal(t, "shop", byCluster["c2"].Namespace)
+	assert.Empty(t, byCluster["c2"].Operations)
+}
@@ -211,6 +211,78 @@ func (m *MultiClusterClient) CheckCanI(ctx context.Context, contextName string,
 	}, nil
 }
 
+// AccessSubject names the user an access review is evaluated for.
+type AccessSubject struct {
+	User   string
+	Groups []string
+}
+
+// CheckAccessBatch evaluates each check against a cluster's RBAC and returns
+// the verdicts in the order of checks. With a nil subject the reviews are
+// SelfSubjectAccessReviews for the client's own identity; otherwise they are
+// SubjectAccessReviews for subject. A review that fails is reported as denied
+// with Error set, so one bad tuple does not fail the batch; only a missing
+// cluster client returns an error.
+func (m *MultiClusterClient) CheckAccessBatch(ctx context.Context, contextName string, subject *AccessSubject, checks []models.AuthzCheck) ([]models.AuthzCheckResult, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.AuthzCheckResult, len(checks))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentAccessReviews)
+	for i, check := range checks {
+		i, check := i, check
+		g.Go(func() error {
+			attrs := &authv1.ResourceAttributes{
+				Verb:        check.Verb,
+				Resource:    check.Resource,
+				Group:       check.Group,
+				Subresource: check.Subresource,
+				Namespace:   check.Namespace,
+				Name:        check.Name,
+			}
+			var status authv1.SubjectAccessReviewStatus
+			var err error
+			if subject == nil {
+				var review *authv1.SelfSubjectAccessReview
+				review, err = client.AuthorizationV1().SelfSubjectAccessReviews().Create(gctx, &authv1.SelfSubjectAccessReview{
+					Spec: authv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+				}, metav1.CreateOptions{})
+				if err == nil {
+					status = review.Status
+				}
+			} else {
+				var review *authv1.SubjectAccessReview
+				review, err = client.AuthorizationV1().SubjectAccessReviews().Create(gctx, &authv1.SubjectAccessReview{
+					Spec: authv1.SubjectAccessReviewSpec{
+						User:               subject.User,
+						Groups:             subject.Groups,
+						ResourceAttributes: attrs,
+					},
+				}, metav1.CreateOptions{})
+				if err == nil {
+					status = review.Status
+				}
+			}
+
+			result := models.AuthzCheckResult{AuthzCheck: check}
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Allowed = status.Allowed && !status.Denied
+				result.Reason = status.Reason
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return results, nil
+}
+
 // GetPermissionsSummary returns a comprehensive permission summary for a cluster
 func (m *MultiClusterClient) GetPermissionsSummary(ctx context.Context, contextName string) (*PermissionsSummary, error) {
 	summary := &PermissionsSummary{
@@ -253,3 +253,68 @@ func TestGetPermissionsSummary_FallsBackToAccessibleNamespaces(t *testing.T) {
 	assert.False(t, summary.CanViewSecrets)
 	assert.Equal(t, []string{accessibleNamespace}, summary.AccessibleNamespaces)
 }
+
+func TestCheckAccessBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("self reviews keep request order and isolate failures", func(t *testing.T) {
+		t.Parallel()
+		clientset := k8sfake.NewSimpleClientset()
+		clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			attrs := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview).Spec.ResourceAttributes
+			switch attrs.Verb {
+			case "get":
+				return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: true}}, nil
+			case "delete":
+				return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Reason: "no RBAC policy matched"}}, nil
+			default:
+				return true, nil, errors.New("apiserver unavailable")
+			}
+		})
+
+		client := newRBACPermissionsClient(clientset)
+		results, err := client.CheckAccessBatch(context.Background(), testRBACPermissionsCluster, nil, []models.AuthzCheck{
+			{Verb: "get", Resource: "pods", Namespace: "shop"},
+			{Verb: "delete", Resource: "deployments", Group: "apps", Namespace: "shop"},
+			{Verb: "patch", Resource: "nodes"},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.True(t, results[0].Allowed)
+		assert.Equal(t, "pods", results[0].Resource)
+		assert.False(t, results[1].Allowed)
+		assert.Equal(t, "no RBAC policy matched", results[1].Reason)
+		assert.False(t, results[2].Allowed)
+		assert.Contains(t, results[2].Error, "apiserver unavailable")
+	})
+
+	t.Run("subject reviews carry the user and groups", func(t *testing.T) {
+		t.Parallel()
+		clientset := k8sfake.NewSimpleClientset()
+		var captured *authv1.SubjectAccessReview
+		clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			captured = action.(k8stesting.CreateAction).GetObject().(*authv1.SubjectAccessReview).DeepCopy()
+			return true, &authv1.SubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: true, Denied: true}}, nil
+		})
+
+		client := newRBACPermissionsClient(clientset)
+		results, err := client.CheckAccessBatch(context.Background(), testRBACPermissionsCluster,
+			&AccessSubject{User: "octocat", Groups: []string{"devs"}},
+			[]models.AuthzCheck{{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: "shop", Name: "web-0"}})
+		require.NoError(t, err)
+		require.NotNil(t, captured)
+		assert.Equal(t, "octocat", captured.Spec.User)
+		assert.Equal(t, []string{"devs"}, captured.Spec.Groups)
+		assert.Equal(t, "exec", captured.Spec.ResourceAttributes.Subresource)
+		assert.Equal(t, "web-0", captured.Spec.ResourceAttributes.Name)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Allowed, "an explicit deny wins")
+	})
+
+	t.Run("unknown cluster fails the batch", func(t *testing.T) {
+		t.Parallel()
+		client := newRBACPermissionsClient(k8sfake.NewSimpleClientset())
+		_, err := client.CheckAccessBatch(context.Background(), "missing", nil, []models.AuthzCheck{{Verb: "get", Resource: "pods"}})
+		assert.Error(t, err)
+	})
+}
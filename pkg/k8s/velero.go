@@ -0,0 +1,245 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+// DefaultVeleroNamespace is where Velero is installed unless told otherwise.
+// Velero only processes Backups in its own namespace.
+const DefaultVeleroNamespace = "velero"
+
+// ListVeleroSchedules lists Velero Schedules in a cluster. Returns an empty
+// list (not an error) if the Velero CRDs are not installed.
+func (m *MultiClusterClient) ListVeleroSchedules(ctx context.Context, contextName string) ([]v1alpha1.VeleroSchedule, error) {
+	items, err := m.listVelero(ctx, contextName, v1alpha1.VeleroScheduleGVR, 0)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make([]v1alpha1.VeleroSchedule, 0, len(items))
+	for i := range items {
+		schedules = append(schedules, parseVeleroSchedule(&items[i], contextName))
+	}
+	return schedules, nil
+}
+
+// ListVeleroBackups lists Velero Backups in a cluster, newest first. A limit
+// above zero keeps only that many. Returns an empty list if the Velero CRDs
+// are not installed.
+func (m *MultiClusterClient) ListVeleroBackups(ctx context.Context, contextName string, limit int) ([]v1alpha1.VeleroBackup, error) {
+	items, err := m.listVelero(ctx, contextName, v1alpha1.VeleroBackupGVR, limit)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]v1alpha1.VeleroBackup, 0, len(items))
+	for i := range items {
+		backups = append(backups, parseVeleroBackup(&items[i], contextName))
+	}
+	return backups, nil
+}
+
+// ListVeleroRestores lists Velero Restores in a cluster, newest first. A
+// limit above zero keeps only that many. Returns an empty list if the Velero
+// CRDs are not installed.
+func (m *MultiClusterClient) ListVeleroRestores(ctx context.Context, contextName string, limit int) ([]v1alpha1.VeleroRestore, error) {
+	items, err := m.listVelero(ctx, contextName, v1alpha1.VeleroRestoreGVR, limit)
+	if err != nil {
+		return nil, err
+	}
+	restores := make([]v1alpha1.VeleroRestore, 0, len(items))
+	for i := range items {
+		restores = append(restores, parseVeleroRestore(&items[i], contextName))
+	}
+	return restores, nil
+}
+
+// GetVeleroBackup reads one Velero Backup.
+func (m *MultiClusterClient) GetVeleroBackup(ctx context.Context, contextName, namespace, name string) (*v1alpha1.VeleroBackup, error) {
+	dynamicClient, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	u, err := dynamicClient.Resource(v1alpha1.VeleroBackupGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	b := parseVeleroBackup(u, contextName)
+	return &b, nil
+}
+
+// CreateVeleroBackup creates a Velero Backup in namespace, which must be the
+// namespace Velero runs in. With req.Schedule set the backup starts from that
+// schedule's template, as `velero backup create --from-schedule` does.
+func (m *MultiClusterClient) CreateVeleroBackup(ctx context.Context, contextName, namespace string, req v1alpha1.VeleroBackupRequest) (*v1alpha1.VeleroBackup, error) {
+	dynamicClient, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := map[string]interface{}{}
+	labels := map[string]interface{}{}
+	if req.Schedule != "" {
+		schedule, err := dynamicClient.Resource(v1alpha1.VeleroScheduleGVR).Namespace(namespace).Get(ctx, req.Schedule, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if template, found, _ := unstructured.NestedMap(schedule.Object, "spec", "template"); found {
+			spec = template
+		}
+		labels[v1alpha1.VeleroScheduleLabel] = req.Schedule
+	}
+	if len(req.IncludedNamespaces) > 0 {
+		namespaces := make([]interface{}, len(req.IncludedNamespaces))
+		for i, ns := range req.IncludedNamespaces {
+			namespaces[i] = ns
+		}
+		spec["includedNamespaces"] = namespaces
+	}
+	if req.StorageLocation != "" {
+		spec["storageLocation"] = req.StorageLocation
+	}
+	if req.TTL != "" {
+		spec["ttl"] = req.TTL
+	}
+	for k, v := range req.Labels {
+		labels[k] = v
+	}
+
+	name := req.Name
+	if name == "" {
+		prefix := req.Schedule
+		if prefix == "" {
+			prefix = "console"
+		}
+		name = VeleroBackupName(prefix, time.Now())
+	}
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": v1alpha1.VeleroBackupGVR.GroupVersion().String(),
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    labels,
+		},
+		"spec": spec,
+	}}
+	created, err := dynamicClient.Resource(v1alpha1.VeleroBackupGVR).Namespace(namespace).Create(ctx, backup, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	b := parseVeleroBackup(created, contextName)
+	return &b, nil
+}
+
+// VeleroBackupName appends a timestamp to prefix, the way Velero names
+// scheduled backups, keeping the result a valid object name.
+func VeleroBackupName(prefix string, now time.Time) string {
+	const maxNameLen = 63
+	suffix := "-" + now.UTC().Format("20060102150405")
+	if len(prefix)+len(suffix) > maxNameLen {
+		prefix = strings.TrimRight(prefix[:maxNameLen-len(suffix)], "-.")
+	}
+	return prefix + suffix
+}
+
+// listVelero lists a Velero resource across namespaces, newest first.
+func (m *MultiClusterClient) listVelero(ctx context.Context, contextName string, gvr schema.GroupVersionResource, limit int) ([]unstructured.Unstructured, error) {
+	dynamicClient, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	list, err := dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || isNoMatchError(err) {
+			// Velero CRDs not installed — return empty list silently
+			return nil, nil
+		}
+		slog.Error("[velero] error listing resources", "cluster", contextName, "resource", gvr.Resource, "error", err)
+		return nil, err
+	}
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := items[i].GetCreationTimestamp(), items[j].GetCreationTimestamp()
+		if ti.Equal(&tj) {
+			return items[i].GetName() > items[j].GetName()
+		}
+		return tj.Before(&ti)
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func parseVeleroBackup(u *unstructured.Unstructured, contextName string) v1alpha1.VeleroBackup {
+	b := v1alpha1.VeleroBackup{
+		Name:      u.GetName(),
+		Namespace: u.GetNamespace(),
+		Cluster:   contextName,
+		Schedule:  u.GetLabels()[v1alpha1.VeleroScheduleLabel],
+	}
+	b.IncludedNamespaces, _, _ = unstructured.NestedStringSlice(u.Object, "spec", "includedNamespaces")
+	b.StorageLocation, _, _ = unstructured.NestedString(u.Object, "spec", "storageLocation")
+	b.TTL, _, _ = unstructured.NestedString(u.Object, "spec", "ttl")
+	b.Phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+	if b.Phase == "" {
+		b.Phase = v1alpha1.VeleroPhaseNew
+	}
+	b.StartedAt, _, _ = unstructured.NestedString(u.Object, "status", "startTimestamp")
+	b.CompletedAt, _, _ = unstructured.NestedString(u.Object, "status", "completionTimestamp")
+	b.Expiration, _, _ = unstructured.NestedString(u.Object, "status", "expiration")
+	b.Errors, _, _ = unstructured.NestedInt64(u.Object, "status", "errors")
+	b.Warnings, _, _ = unstructured.NestedInt64(u.Object, "status", "warnings")
+	b.FailureReason, _, _ = unstructured.NestedString(u.Object, "status", "failureReason")
+	if b.FailureReason == "" {
+		// FailedValidation carries its reasons here instead.
+		if errs, _, _ := unstructured.NestedStringSlice(u.Object, "status", "validationErrors"); len(errs) > 0 {
+			b.FailureReason = strings.Join(errs, "; ")
+		}
+	}
+	return b
+}
+
+func parseVeleroSchedule(u *unstructured.Unstructured, contextName string) v1alpha1.VeleroSchedule {
+	s := v1alpha1.VeleroSchedule{
+		Name:      u.GetName(),
+		Namespace: u.GetNamespace(),
+		Cluster:   contextName,
+	}
+	s.Schedule, _, _ = unstructured.NestedString(u.Object, "spec", "schedule")
+	s.Paused, _, _ = unstructured.NestedBool(u.Object, "spec", "paused")
+	s.IncludedNamespaces, _, _ = unstructured.NestedStringSlice(u.Object, "spec", "template", "includedNamespaces")
+	s.TTL, _, _ = unstructured.NestedString(u.Object, "spec", "template", "ttl")
+	s.Phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+	s.LastBackup, _, _ = unstructured.NestedString(u.Object, "status", "lastBackup")
+	return s
+}
+
+func parseVeleroRestore(u *unstructured.Unstructured, contextName string) v1alpha1.VeleroRestore {
+	r := v1alpha1.VeleroRestore{
+		Name:      u.GetName(),
+		Namespace: u.GetNamespace(),
+		Cluster:   contextName,
+	}
+	r.Backup, _, _ = unstructured.NestedString(u.Object, "spec", "backupName")
+	r.Phase, _, _ = unstructured.NestedString(u.Object, "status", "phase")
+	if r.Phase == "" {
+		r.Phase = v1alpha1.VeleroPhaseNew
+	}
+	r.StartedAt, _, _ = unstructured.NestedString(u.Object, "status", "startTimestamp")
+	r.CompletedAt, _, _ = unstructured.NestedString(u.Object, "status", "completionTimestamp")
+	r.Errors, _, _ = unstructured.NestedInt64(u.Object, "status", "errors")
+	r.Warnings, _, _ = unstructured.NestedInt64(u.Object, "status", "warnings")
+	r.FailureReason, _, _ = unstructured.NestedString(u.Object, "status", "failureReason")
+	return r
+}
@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+)
+
+func newVeleroObject(kind, name string, created time.Time, spec, status map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": DefaultVeleroNamespace},
+		"spec":       spec,
+		"status":     status,
+	}}
+	u.SetCreationTimestamp(metav1.NewTime(created))
+	return u
+}
+
+func veleroTestClient(t *testing.T, objects ...runtime.Object) (*MultiClusterClient, *dynfake.FakeDynamicClient) {
+	t.Helper()
+	dyn := dynfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		v1alpha1.VeleroBackupGVR:   "BackupList",
+		v1alpha1.VeleroScheduleGVR: "ScheduleList",
+		v1alpha1.VeleroRestoreGVR:  "RestoreList",
+	}, objects...)
+	client, _ := NewMultiClusterClient("")
+	client.SetDynamicClient("c1", dyn)
+	return client, dyn
+}
+
+func TestListVeleroBackups(t *testing.T) {
+	now := time.Now()
+	failed := newVeleroObject("Backup", "daily-20261014020000", now.Add(-time.Hour),
+		map[string]interface{}{"includedNamespaces": []interface{}{"shop"}, "ttl": "720h0m0s"},
+		map[string]interface{}{"phase": "PartiallyFailed", "errors": int64(2), "startTimestamp": "2026-10-14T02:00:00Z"})
+	failed.SetLabels(map[string]string{v1alpha1.VeleroScheduleLabel: "daily"})
+	older := newVeleroObject("Backup", "daily-20261013020000", now.Add(-25*time.Hour), map[string]interface{}{},
+		map[string]interface{}{"phase": "Completed"})
+	invalid := newVeleroObject("Backup", "manual", now, map[string]interface{}{},
+		map[string]interface{}{"phase": "FailedValidation", "validationErrors": []interface{}{"storage location not found"}})
+	client, _ := veleroTestClient(t, older, failed, invalid)
+
+	backups, err := client.ListVeleroBackups(context.Background(), "c1", 0)
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	assert.Equal(t, []string{"manual", "daily-20261014020000", "daily-20261013020000"},
+		[]string{backups[0].Name, backups[1].Name, backups[2].Name}, "newest first")
+
+	assert.Equal(t, "storage location not found", backups[0].FailureReason)
+	assert.True(t, backups[0].Failed())
+	b := backups[1]
+	assert.Equal(t, "c1", b.Cluster)
+	assert.Equal(t, "daily", b.Schedule)
+	assert.Equal(t, []string{"shop"}, b.IncludedNamespaces)
+	assert.Equal(t, int64(2), b.Errors)
+	assert.True(t, b.Failed())
+	assert.True(t, b.Finished())
+	assert.False(t, backups[2].Failed())
+
+	backups, err = client.ListVeleroBackups(context.Background(), "c1", 1)
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestListVeleroSchedulesAndRestores(t *testing.T) {
+	schedule := newVeleroObject("Schedule", "daily", time.Now(),
+		map[string]interface{}{
+			"schedule": "0 2 * * *",
+			"template": map[string]interface{}{"includedNamespaces": []interface{}{"shop"}, "ttl": "720h0m0s"},
+		},
+		map[string]interface{}{"phase": "Enabled", "lastBackup": "2026-10-14T02:00:00Z"})
+	restore := newVeleroObject("Restore", "shop-restore", time.Now(),
+		map[string]interface{}{"backupName": "daily-20261014020000"},
+		map[string]interface{}{"phase": "Completed", "warnings": int64(1)})
+	client, _ := veleroTestClient(t, schedule, restore)
+
+	schedules, err := client.ListVeleroSchedules(context.Background(), "c1")
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, v1alpha1.VeleroSchedule{
+		Name: "daily", Namespace: "velero", Cluster: "c1", Schedule: "0 2 * * *", Phase: "Enabled",
+		IncludedNamespaces: []string{"shop"}, TTL: "720h0m0s", LastBackup: "2026-10-14T02:00:00Z",
+	}, schedules[0])
+
+	restores, err := client.ListVeleroRestores(context.Background(), "c1", 0)
+	require.NoError(t, err)
+	require.Len(t, restores, 1)
+	assert.Equal(t, "daily-20261014020000", restores[0].Backup)
+	assert.Equal(t, int64(1), restores[0].Warnings)
+}
+
+func TestCreateVeleroBackup_FromSchedule(t *testing.T) {
+	schedule := newVeleroObject("Schedule", "daily", time.Now(),
+		map[string]interface{}{
+			"schedule": "0 2 * * *",
+			"template": map[string]interface{}{
+				"includedNamespaces": []interface{}{"shop"},
+				"storageLocation":    "default",
+				"snapshotVolumes":    true,
+			},
+		}, map[string]interface{}{})
+	client, dyn := veleroTestClient(t, schedule)
+
+	b, err := client.CreateVeleroBackup(context.Background(), "c1", DefaultVeleroNamespace, v1alpha1.VeleroBackupRequest{
+		Schedule: "daily",
+		TTL:      "72h",
+		Labels:   map[string]string{"owner": "alex"},
+	})
+	require.NoError(t, err)
+	assert.Regexp(t, `^daily-\d{14}$`, b.Name)
+	assert.Equal(t, "daily", b.Schedule)
+	assert.Equal(t, v1alpha1.VeleroPhaseNew, b.Phase)
+
+	u, err := dyn.Resource(v1alpha1.VeleroBackupGVR).Namespace(DefaultVeleroNamespace).Get(context.Background(), b.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	snapshot, _, _ := unstructured.NestedBool(u.Object, "spec", "snapshotVolumes")
+	assert.True(t, snapshot, "the schedule template is copied")
+	ttl, _, _ := unstructured.NestedString(u.Object, "spec", "ttl")
+	assert.Equal(t, "72h", ttl)
+	assert.Equal(t, "alex", u.GetLabels()["owner"])
+
+	_, err = client.CreateVeleroBackup(context.Background(), "c1", DefaultVeleroNamespace, v1alpha1.VeleroBackupRequest{Schedule: "missing"})
+	assert.Error(t, err)
+}
+
+func TestVeleroBackupName(t *testing.T) {
+	at := time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, "console-20261014020000", VeleroBackupName("console", at))
+	long := VeleroBackupName("a-very-long-workload-deployment-name-that-keeps-going-on", at)
+	assert.LessOrEqual(t, len(long), 63)
+	assert.Regexp(t, `[a-z0-9]-20261014020000$`, long)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -73,41 +74,56 @@ func (m *MultiClusterClient) ResolveWorkloadDependencies(
 }
 
 
-// DeployWorkload fetches a workload manifest from the source cluster and applies it to target clusters
-func (m *MultiClusterClient) DeployWorkload(ctx context.Context, sourceCluster, namespace, name string, targetClusters []string, replicas int32, opts *DeployOptions) (*v1alpha1.DeployResponse, error) {
-	if opts == nil {
-		opts = &DeployOptions{DeployedBy: "anonymous"}
-	}
-
-	// 1. Fetch the workload from the source cluster
-	sourceClient, err := m.GetDynamicClient(sourceCluster)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get source cluster client: %w", err)
-	}
-
-	// Try Deployment, StatefulSet, DaemonSet in order
-	gvrs := []struct {
-		gvr  schema.GroupVersionResource
-		kind string
-	}{
-		{gvrDeployments, "Deployment"},
-		{gvrStatefulSets, "StatefulSet"},
-		{gvrDaemonSets, "DaemonSet"},
-	}
+// renderedWorkload is a workload prepared for cross-cluster apply.
+type renderedWorkload struct {
+	obj    *unstructured.Unstructured
+	gvr    schema.GroupVersionResource
+	bundle *DependencyBundle
+}
 
+// renderWorkload fetches a workload from the source cluster, or takes
+// opts.Manifest, resolves its dependencies, and cleans the manifest for
+// cross-cluster apply.
+func (m *MultiClusterClient) renderWorkload(ctx context.Context, sourceCluster, namespace, name string, replicas int32, opts *DeployOptions) (*renderedWorkload, error) {
 	var sourceObj *unstructured.Unstructured
 	var sourceGVR schema.GroupVersionResource
-	for _, g := range gvrs {
-		obj, getErr := sourceClient.Resource(g.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-		if getErr == nil {
-			sourceObj = obj
-			sourceGVR = g.gvr
-			break
+	if opts.Manifest != nil {
+		// 1. Deploy the captured manifest
+		k, err := lookupSnapshotKind(opts.Manifest.GetKind())
+		if err != nil {
+			return nil, err
+		}
+		sourceObj, sourceGVR = opts.Manifest.DeepCopy(), k.gvr
+		sourceObj.SetNamespace(namespace)
+	} else {
+		// 1. Fetch the workload from the source cluster
+		sourceClient, err := m.GetDynamicClient(sourceCluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source cluster client: %w", err)
 		}
-	}
 
-	if sourceObj == nil {
-		return nil, fmt.Errorf("workload %s/%s not found in cluster %s", namespace, name, sourceCluster)
+		// Try Deployment, StatefulSet, DaemonSet in order
+		gvrs := []struct {
+			gvr  schema.GroupVersionResource
+			kind string
+		}{
+			{gvrDeployments, "Deployment"},
+			{gvrStatefulSets, "StatefulSet"},
+			{gvrDaemonSets, "DaemonSet"},
+		}
+
+		for _, g := range gvrs {
+			obj, getErr := sourceClient.Resource(g.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+			if getErr == nil {
+				sourceObj = obj
+				sourceGVR = g.gvr
+				break
+			}
+		}
+
+		if sourceObj == nil {
+			return nil, fmt.Errorf("workload %s/%s not found in cluster %s", namespace, name, sourceCluster)
+		}
 	}
 
 	// 2. Resolve dependencies (ConfigMaps, Secrets, SA, RBAC, PVCs, Services, Ingress, NetworkPolicy, HPA, PDB)
@@ -116,6 +132,9 @@ func (m *MultiClusterClient) DeployWorkload(ctx context.Context, sourceCluster,
 		slog.Warn("[deploy] dependency resolution failed", "error", err)
 		bundle = &DependencyBundle{Workload: sourceObj}
 	}
+	if np := opts.NetworkPolicy; np != nil && np.Enabled {
+		addGeneratedNetworkPolicy(bundle, sourceObj, sourceCluster, namespace, *np, opts)
+	}
 	if len(bundle.Warnings) > 0 {
 		for _, w := range bundle.Warnings {
 			slog.Info("[deploy] dependency warning", "warning", w)
@@ -131,6 +150,68 @@ func (m *MultiClusterClient) DeployWorkload(ctx context.Context, sourceCluster,
 			spec["replicas"] = int64(replicas)
 		}
 	}
+	return &renderedWorkload{obj: cleanedObj, gvr: sourceGVR, bundle: bundle}, nil
+}
+
+// addGeneratedNetworkPolicy adds the workload's baseline NetworkPolicy to
+// bundle in apply order. A policy that cannot be generated is a warning, not
+// a failed deploy.
+func addGeneratedNetworkPolicy(bundle *DependencyBundle, workload *unstructured.Unstructured,
+	sourceCluster, namespace string, cfg v1alpha1.GeneratedNetworkPolicy, opts *DeployOptions,
+) {
+	np, err := GenerateNetworkPolicy(workload, cfg)
+	if err != nil {
+		bundle.Warnings = append(bundle.Warnings, "Baseline NetworkPolicy not generated: "+err.Error())
+		return
+	}
+	bundle.Dependencies = append(bundle.Dependencies, Dependency{
+		Kind:      DepNetworkPolicy,
+		Name:      np.GetName(),
+		Namespace: namespace,
+		GVR:       gvrNetworkPolicies,
+		Object:    cleanManifestForDeploy(np, sourceCluster, opts),
+		Order:     depApplyOrder[DepNetworkPolicy],
+		Generated: true,
+	})
+	sort.SliceStable(bundle.Dependencies, func(i, j int) bool {
+		return bundle.Dependencies[i].Order < bundle.Dependencies[j].Order
+	})
+}
+
+// RenderWorkload returns the manifests DeployWorkload would apply to a target
+// cluster: the cleaned workload first, followed by its dependencies. Nothing
+// is written. The reconciler uses it to run policy checks before deploying.
+func (m *MultiClusterClient) RenderWorkload(ctx context.Context, sourceCluster, namespace, name string, replicas int32, opts *DeployOptions) ([]*unstructured.Unstructured, error) {
+	if opts == nil {
+		opts = &DeployOptions{DeployedBy: "anonymous"}
+	}
+	rendered, err := m.renderWorkload(ctx, sourceCluster, namespace, name, replicas, opts)
+	if err != nil {
+		return nil, err
+	}
+	workload := rendered.obj.DeepCopy()
+	normalizeImageNames(workload)
+	out := make([]*unstructured.Unstructured, 0, 1+len(rendered.bundle.Dependencies))
+	out = append(out, workload)
+	for _, dep := range rendered.bundle.Dependencies {
+		if dep.Object != nil {
+			out = append(out, dep.Object)
+		}
+	}
+	return out, nil
+}
+
+// DeployWorkload fetches a workload manifest from the source cluster and applies it to target clusters
+func (m *MultiClusterClient) DeployWorkload(ctx context.Context, sourceCluster, namespace, name string, targetClusters []string, replicas int32, opts *DeployOptions) (*v1alpha1.DeployResponse, error) {
+	if opts == nil {
+		opts = &DeployOptions{DeployedBy: "anonymous"}
+	}
+
+	rendered, err := m.renderWorkload(ctx, sourceCluster, namespace, name, replicas, opts)
+	if err != nil {
+		return nil, err
+	}
+	cleanedObj, sourceGVR, bundle := rendered.obj, rendered.gvr, rendered.bundle
 
 	// 4. Apply to each target cluster in parallel
 	var wg sync.WaitGroup
@@ -194,6 +275,12 @@ func (m *MultiClusterClient) DeployWorkload(ctx context.Context, sourceCluster,
 			// 4c. Apply the workload itself
 			objCopy := cleanedObj.DeepCopy()
 			normalizeImageNames(objCopy)
+			// Kinds without spec.replicas, such as DaemonSets, keep theirs.
+			if n, ok := opts.ClusterReplicas[targetCluster]; ok {
+				if spec, ok := objCopy.Object["spec"].(map[string]interface{}); ok && spec["replicas"] != nil {
+					spec["replicas"] = int64(n)
+				}
+			}
 
 			_, err = targetClient.Resource(sourceGVR).Namespace(namespace).Create(clusterCtx, objCopy, metav1.CreateOptions{})
 			if err != nil {
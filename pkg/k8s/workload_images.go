@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkloadImages lists the container images of one workload.
+type WorkloadImages struct {
+	Cluster   string   `json:"cluster"`
+	Namespace string   `json:"namespace"`
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Images    []string `json:"images"`
+}
+
+// ListWorkloadImages returns the Deployments, StatefulSets, DaemonSets and
+// CronJobs of a namespace, or of every namespace when namespace is empty,
+// with the images of their init and regular containers. The result is
+// sorted by namespace, kind and name.
+func (m *MultiClusterClient) ListWorkloadImages(ctx context.Context, contextName, namespace string) ([]WorkloadImages, error) {
+	client, err := m.GetClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	var out []WorkloadImages
+	add := func(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec) {
+		out = append(out, WorkloadImages{
+			Cluster:   contextName,
+			Namespace: meta.Namespace,
+			Kind:      kind,
+			Name:      meta.Name,
+			Images:    podSpecImages(spec),
+		})
+	}
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		add("Deployment", d.ObjectMeta, d.Spec.Template.Spec)
+	}
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ss := range statefulSets.Items {
+		add("StatefulSet", ss.ObjectMeta, ss.Spec.Template.Spec)
+	}
+	daemonSets, err := client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range daemonSets.Items {
+		add("DaemonSet", ds.ObjectMeta, ds.Spec.Template.Spec)
+	}
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, cj := range cronJobs.Items {
+		add("CronJob", cj.ObjectMeta, cj.Spec.JobTemplate.Spec.Template.Spec)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return out, nil
+}
+
+// podSpecImages returns the distinct images of a pod spec's init and regular
+// containers, in order of appearance.
+func podSpecImages(spec corev1.PodSpec) []string {
+	images := make([]string, 0, len(spec.InitContainers)+len(spec.Containers))
+	seen := make(map[string]bool)
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			if c.Image != "" && !seen[c.Image] {
+				seen[c.Image] = true
+				images = append(images, c.Image)
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListWorkloadImages(t *testing.T) {
+	podSpec := func(init []string, images ...string) corev1.PodTemplateSpec {
+		var spec corev1.PodSpec
+		for _, i := range init {
+			spec.InitContainers = append(spec.InitContainers, corev1.Container{Name: "init", Image: i})
+		}
+		for _, i := range images {
+			spec.Containers = append(spec.Containers, corev1.Container{Name: "app", Image: i})
+		}
+		return corev1.PodTemplateSpec{Spec: spec}
+	}
+	m := newWorkloadsTestClient(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Template: podSpec([]string{"busybox:1.36"}, "nginx:1.27", "envoy:1.30", "nginx:1.27")},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+			Spec:       appsv1.StatefulSetSpec{Template: podSpec(nil, "postgres:16")},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+			Spec:       appsv1.DaemonSetSpec{Template: podSpec(nil, "fluent-bit:3.0")},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "shop"},
+			Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{Template: podSpec(nil, "report:2")},
+			}},
+		},
+	)
+
+	all, err := m.ListWorkloadImages(context.Background(), workloadTestContext, "")
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, "kube-system", all[0].Namespace)
+	assert.Equal(t, []string{"CronJob", "Deployment", "StatefulSet"}, []string{all[1].Kind, all[2].Kind, all[3].Kind})
+	assert.Equal(t, []string{"busybox:1.36", "nginx:1.27", "envoy:1.30"}, all[2].Images)
+	assert.Equal(t, workloadTestContext, all[2].Cluster)
+
+	shop, err := m.ListWorkloadImages(context.Background(), workloadTestContext, "shop")
+	require.NoError(t, err)
+	assert.Len(t, shop, 3)
+
+	_, err = m.ListWorkloadImages(context.Background(), "missing", "")
+	assert.Error(t, err)
+}
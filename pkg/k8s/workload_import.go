@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// importDroppedAnnotations are annotations the server or an earlier console
+// deploy set that would be stale on an imported workload's copies.
+var importDroppedAnnotations = []string{
+	"deployment.kubernetes.io/revision",
+	"kubestellar.io/deploy-timestamp",
+	"kubestellar.io/source-cluster",
+}
+
+// CaptureWorkload reads a live Deployment, StatefulSet or DaemonSet so it can
+// become the manifest of an imported ManagedWorkload. Besides what snapshots
+// strip, the deletion fields, the revision and console deploy annotations
+// and the pod template's empty creationTimestamp are removed. Returns
+// ErrUnsupportedSnapshotKind or ErrWorkloadNotFound for the obvious cases.
+func (m *MultiClusterClient) CaptureWorkload(ctx context.Context, contextName, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	k, err := lookupSnapshotKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := dyn.Resource(k.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %s %s/%s", ErrWorkloadNotFound, k.kind, namespace, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return normalizeCapturedWorkload(obj), nil
+}
+
+// normalizeCapturedWorkload returns a copy of obj without the fields the
+// server manages.
+func normalizeCapturedWorkload(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	clean := &unstructured.Unstructured{Object: cleanSnapshotObject(obj)}
+	clean.SetDeletionTimestamp(nil)
+	clean.SetDeletionGracePeriodSeconds(nil)
+	if ann := clean.GetAnnotations(); ann != nil {
+		for _, key := range importDroppedAnnotations {
+			delete(ann, key)
+		}
+		clean.SetAnnotations(ann)
+	}
+	unstructured.RemoveNestedField(clean.Object, "spec", "template", "metadata", "creationTimestamp")
+	return clean
+}
@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCaptureWorkload(t *testing.T) {
+	client := snapshotTestClient(t)
+
+	obj, err := client.CaptureWorkload(context.Background(), "c1", "deployments", "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", obj.GetKind())
+	assert.Equal(t, "shop", obj.GetNamespace())
+	assert.Empty(t, obj.GetUID())
+	assert.Empty(t, obj.GetResourceVersion())
+	assert.Equal(t, map[string]string{"team": "storefront"}, obj.GetAnnotations())
+	assert.NotContains(t, obj.Object, "status")
+
+	_, err = client.CaptureWorkload(context.Background(), "c1", "job", "shop", "web")
+	assert.True(t, errors.Is(err, ErrUnsupportedSnapshotKind), "err = %v", err)
+	_, err = client.CaptureWorkload(context.Background(), "c1", "daemonset", "shop", "web")
+	assert.True(t, errors.Is(err, ErrWorkloadNotFound), "err = %v", err)
+}
+
+func TestNormalizeCapturedWorkload(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":              "web",
+			"deletionTimestamp": "2026-01-02T03:04:05Z",
+			"annotations": map[string]interface{}{
+				"deployment.kubernetes.io/revision": "7",
+				"kubestellar.io/deploy-timestamp":   "2026-01-01T00:00:00Z",
+				"team":                              "storefront",
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"creationTimestamp": nil, "labels": map[string]interface{}{"app": "web"}},
+			},
+		},
+	}}
+
+	clean := normalizeCapturedWorkload(obj)
+	assert.Nil(t, clean.GetDeletionTimestamp())
+	assert.Equal(t, map[string]string{"team": "storefront"}, clean.GetAnnotations())
+	template, _, _ := unstructured.NestedMap(clean.Object, "spec", "template", "metadata")
+	assert.Equal(t, map[string]interface{}{"labels": map[string]interface{}{"app": "web"}}, template)
+	assert.Equal(t, "7", obj.GetAnnotations()["deployment.kubernetes.io/revision"], "the input is not modified")
+}
+
+func TestRenderWorkload_Manifest(t *testing.T) {
+	client := snapshotTestClient(t)
+	manifest := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "StatefulSet",
+		"metadata":   map[string]interface{}{"name": "db", "namespace": "elsewhere"},
+		"spec":       map[string]interface{}{"replicas": int64(1)},
+	}}
+
+	objects, err := client.RenderWorkload(context.Background(), "c1", "shop", "db", 3,
+		&DeployOptions{DeployedBy: "test", Manifest: manifest})
+	require.NoError(t, err, "the captured manifest is used, not the source cluster's workload")
+	require.NotEmpty(t, objects)
+	workload := objects[0]
+	assert.Equal(t, "StatefulSet", workload.GetKind())
+	assert.Equal(t, "shop", workload.GetNamespace())
+	replicas, _, _ := unstructured.NestedInt64(workload.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	assert.Equal(t, "elsewhere", manifest.GetNamespace(), "the manifest is not modified")
+
+	manifest.SetKind("CronJob")
+	_, err = client.RenderWorkload(context.Background(), "c1", "shop", "db", 0, &DeployOptions{Manifest: manifest})
+	assert.True(t, errors.Is(err, ErrUnsupportedSnapshotKind), "err = %v", err)
+}
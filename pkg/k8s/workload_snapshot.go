@@ -0,0 +1,236 @@
+package k8s
+
+// Workload snapshots capture a workload's live object plus the ConfigMaps and
+// Secrets its pod template references, so the set can be re-applied before a
+// risky change made outside the deployment engine. Restores go through
+// ApplyManifest, so every object is dry-run before any is written.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SnapshotRedactedAnnotation marks a Secret whose data was redacted when it
+// was captured. Restores skip such Secrets rather than overwrite the live
+// values with placeholders.
+const SnapshotRedactedAnnotation = "kubestellar.io/snapshot-redacted"
+
+// ErrUnsupportedSnapshotKind is returned for workload kinds that cannot be
+// snapshotted.
+var ErrUnsupportedSnapshotKind = errors.New("unsupported workload kind")
+
+// ErrWorkloadNotFound is returned when the workload to snapshot does not
+// exist.
+var ErrWorkloadNotFound = errors.New("workload not found")
+
+// snapshotWorkloadKind is a workload resource SnapshotWorkload accepts.
+type snapshotWorkloadKind struct {
+	gvr  schema.GroupVersionResource
+	kind string
+}
+
+// snapshotWorkloadKinds maps the lowercase kind accepted by SnapshotWorkload
+// to the workload resource.
+var snapshotWorkloadKinds = map[string]snapshotWorkloadKind{
+	"deployment":  {gvrDeployments, "Deployment"},
+	"statefulset": {gvrStatefulSets, "StatefulSet"},
+	"daemonset":   {gvrDaemonSets, "DaemonSet"},
+}
+
+// WorkloadSnapshot is a workload's live state as captured by
+// SnapshotWorkload.
+type WorkloadSnapshot struct {
+	Cluster   string `json:"cluster"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Objects are the referenced ConfigMaps and Secrets followed by the
+	// workload itself, the order they are restored in.
+	Objects []map[string]interface{} `json:"objects"`
+	// Missing lists referenced objects that did not exist, e.g.
+	// "Secret/db-creds".
+	Missing  []string `json:"missing,omitempty"`
+	Redacted bool     `json:"redacted"`
+}
+
+// lookupSnapshotKind resolves a kind or resource name such as "deployment",
+// "Deployments" or "statefulset".
+func lookupSnapshotKind(kind string) (snapshotWorkloadKind, error) {
+	k, ok := snapshotWorkloadKinds[strings.TrimSuffix(strings.ToLower(kind), "s")]
+	if !ok {
+		return snapshotWorkloadKind{}, fmt.Errorf("%w: %s", ErrUnsupportedSnapshotKind, kind)
+	}
+	return k, nil
+}
+
+// NormalizeSnapshotKind returns the workload kind SnapshotWorkload would
+// capture for kind, e.g. "Deployment" for "deployments".
+func NormalizeSnapshotKind(kind string) (string, error) {
+	k, err := lookupSnapshotKind(kind)
+	return k.kind, err
+}
+
+// SnapshotWorkload captures a Deployment, StatefulSet or DaemonSet and the
+// ConfigMaps and Secrets its pod template references. With redactSecrets set
+// Secret values are replaced by empty strings; the keys are kept. Service
+// account tokens are never captured.
+func (m *MultiClusterClient) SnapshotWorkload(ctx context.Context, contextName, kind, namespace, name string, redactSecrets bool) (*WorkloadSnapshot, error) {
+	k, err := lookupSnapshotKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := m.GetDynamicClient(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := dyn.Resource(k.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %s %s/%s", ErrWorkloadNotFound, k.kind, namespace, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &WorkloadSnapshot{Cluster: contextName, Kind: k.kind, Namespace: namespace, Name: name, Redacted: redactSecrets}
+	configMaps, secrets := workloadConfigRefs(workload)
+	if len(configMaps)+len(secrets)+1 > maxManifestDocuments {
+		return nil, fmt.Errorf("workload references more than %d objects", maxManifestDocuments-1)
+	}
+
+	for _, cm := range configMaps {
+		obj, err := dyn.Resource(gvrConfigMaps).Namespace(namespace).Get(ctx, cm, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			snap.Missing = append(snap.Missing, "ConfigMap/"+cm)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s: %w", cm, err)
+		}
+		snap.Objects = append(snap.Objects, cleanSnapshotObject(obj))
+	}
+	for _, sec := range secrets {
+		obj, err := dyn.Resource(gvrSecrets).Namespace(namespace).Get(ctx, sec, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			snap.Missing = append(snap.Missing, "Secret/"+sec)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Secret %s: %w", sec, err)
+		}
+		if secretType, _, _ := unstructured.NestedString(obj.Object, "type"); secretType == "kubernetes.io/service-account-token" {
+			continue
+		}
+		clean := cleanSnapshotObject(obj)
+		if redactSecrets {
+			RedactSnapshotSecret(clean)
+		}
+		snap.Objects = append(snap.Objects, clean)
+	}
+	snap.Objects = append(snap.Objects, cleanSnapshotObject(workload))
+	return snap, nil
+}
+
+// workloadConfigRefs returns the sorted names of the ConfigMaps and Secrets
+// referenced by a workload's containers, volumes and image pull secrets.
+func workloadConfigRefs(workload *unstructured.Unstructured) (configMaps, secrets []string) {
+	podSpec, err := extractPodTemplateSpec(workload)
+	if err != nil {
+		return nil, nil
+	}
+	containers := append(getSlice(podSpec, "containers"), getSlice(podSpec, "initContainers")...)
+	cms, secs := walkContainerRefs(containers)
+	volCMs, volSecs, _ := walkVolumeRefs(getSlice(podSpec, "volumes"))
+	for _, ps := range getSlice(podSpec, "imagePullSecrets") {
+		if psMap, ok := ps.(map[string]interface{}); ok {
+			if name, _ := psMap["name"].(string); name != "" {
+				secs = append(secs, name)
+			}
+		}
+	}
+	return uniqueSorted(append(cms, volCMs...)), uniqueSorted(append(secs, volSecs...))
+}
+
+func uniqueSorted(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// cleanSnapshotObject strips status and the server-managed metadata that
+// would make a later apply conflict or point at objects that no longer
+// exist.
+func cleanSnapshotObject(obj *unstructured.Unstructured) map[string]interface{} {
+	clean := obj.DeepCopy()
+	clean.SetResourceVersion("")
+	clean.SetUID("")
+	clean.SetSelfLink("")
+	clean.SetGeneration(0)
+	clean.SetManagedFields(nil)
+	clean.SetCreationTimestamp(metav1.Time{})
+	clean.SetOwnerReferences(nil)
+	delete(clean.Object, "status")
+	if ann := clean.GetAnnotations(); ann != nil {
+		delete(ann, "kubectl.kubernetes.io/last-applied-configuration")
+		clean.SetAnnotations(ann)
+	}
+	return clean.Object
+}
+
+// RedactSnapshotSecret replaces the values of a captured Secret with empty
+// strings and marks it with SnapshotRedactedAnnotation. Other kinds are left
+// alone.
+func RedactSnapshotSecret(obj map[string]interface{}) {
+	u := &unstructured.Unstructured{Object: obj}
+	if u.GetKind() != "Secret" {
+		return
+	}
+	if data, ok := obj["data"].(map[string]interface{}); ok {
+		for k := range data {
+			data[k] = ""
+		}
+	}
+	delete(obj, "stringData")
+	ann := u.GetAnnotations()
+	if ann == nil {
+		ann = make(map[string]string)
+	}
+	ann[SnapshotRedactedAnnotation] = "true"
+	u.SetAnnotations(ann)
+}
+
+// SnapshotRestoreManifest turns snapshot objects into a manifest for
+// ApplyManifest. Redacted Secrets are left out and returned as skipped,
+// e.g. "Secret/db-creds".
+func SnapshotRestoreManifest(objects []map[string]interface{}) (manifest []byte, skipped []string, err error) {
+	docs := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		u := &unstructured.Unstructured{Object: obj}
+		if u.GetAnnotations()[SnapshotRedactedAnnotation] == "true" {
+			skipped = append(skipped, u.GetKind()+"/"+u.GetName())
+			continue
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode %s/%s: %w", u.GetKind(), u.GetName(), err)
+		}
+		docs = append(docs, string(data))
+	}
+	return []byte(strings.Join(docs, "\n---\n")), skipped, nil
+}
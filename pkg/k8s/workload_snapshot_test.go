@@ -0,0 +1,118 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func snapshotTestClient(t *testing.T) *MultiClusterClient {
+	t.Helper()
+	deploy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "shop",
+			"uid":             "abc-123",
+			"resourceVersion": "42",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"team": "storefront",
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{
+						"name":    "web",
+						"envFrom": []interface{}{map[string]interface{}{"configMapRef": map[string]interface{}{"name": "web-config"}}},
+						"env": []interface{}{map[string]interface{}{
+							"name":      "DB_PASSWORD",
+							"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "db-creds", "key": "password"}},
+						}},
+					}},
+					"volumes": []interface{}{map[string]interface{}{
+						"name":   "tls",
+						"secret": map[string]interface{}{"secretName": "web-tls"},
+					}},
+				},
+			},
+		},
+		"status": map[string]interface{}{"readyReplicas": int64(2)},
+	}}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "web-config", "namespace": "shop"},
+		"data":       map[string]interface{}{"MODE": "prod"},
+	}}
+	secret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "db-creds", "namespace": "shop"},
+		"data":       map[string]interface{}{"password": "c2VjcmV0"},
+	}}
+
+	client := &MultiClusterClient{}
+	client.SetDynamicClient("c1", dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), deploy, configMap, secret))
+	return client
+}
+
+func TestSnapshotWorkload(t *testing.T) {
+	client := snapshotTestClient(t)
+
+	snap, err := client.SnapshotWorkload(context.Background(), "c1", "deployments", "shop", "web", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", snap.Kind)
+	assert.Equal(t, []string{"Secret/web-tls"}, snap.Missing)
+	require.Len(t, snap.Objects, 3)
+
+	kinds := make([]string, 0, len(snap.Objects))
+	for _, obj := range snap.Objects {
+		kinds = append(kinds, obj["kind"].(string))
+	}
+	assert.Equal(t, []string{"ConfigMap", "Secret", "Deployment"}, kinds, "dependencies are restored before the workload")
+
+	workload := &unstructured.Unstructured{Object: snap.Objects[2]}
+	assert.Empty(t, workload.GetUID())
+	assert.Empty(t, workload.GetResourceVersion())
+	assert.Equal(t, map[string]string{"team": "storefront"}, workload.GetAnnotations())
+	assert.NotContains(t, snap.Objects[2], "status")
+	assert.Equal(t, "c2VjcmV0", snap.Objects[1]["data"].(map[string]interface{})["password"])
+}
+
+func TestSnapshotWorkload_Redacted(t *testing.T) {
+	client := snapshotTestClient(t)
+
+	snap, err := client.SnapshotWorkload(context.Background(), "c1", "deployment", "shop", "web", true)
+	require.NoError(t, err)
+	assert.True(t, snap.Redacted)
+	secret := snap.Objects[1]
+	assert.Equal(t, map[string]interface{}{"password": ""}, secret["data"], "keys are kept, values dropped")
+
+	manifest, skipped, err := SnapshotRestoreManifest(snap.Objects)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Secret/db-creds"}, skipped)
+	objects, err := ParseManifest(manifest)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.False(t, strings.Contains(string(manifest), "c2VjcmV0"))
+}
+
+func TestSnapshotWorkload_Errors(t *testing.T) {
+	client := snapshotTestClient(t)
+
+	_, err := client.SnapshotWorkload(context.Background(), "c1", "cronjob", "shop", "web", true)
+	assert.True(t, errors.Is(err, ErrUnsupportedSnapshotKind), "err = %v", err)
+
+	_, err = client.SnapshotWorkload(context.Background(), "c1", "statefulset", "shop", "web", true)
+	assert.True(t, errors.Is(err, ErrWorkloadNotFound), "err = %v", err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
 )
 
 func TestResolveWorkloadDependencies(t *testing.T) {
@@ -533,6 +536,166 @@ func TestDeployWorkload(t *testing.T) {
 	}
 }
 
+func TestDeployWorkload_ClusterReplicas(t *testing.T) {
+	deployObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "dep1", "namespace": "default"},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{"name": "c1", "image": "nginx"}},
+					},
+				},
+			},
+		},
+	}
+	emptyList := func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &unstructured.UnstructuredList{Items: []unstructured.Unstructured{}}, nil
+	}
+	sourceClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), buildTestGVRMap(), deployObj)
+	sourceClient.PrependReactor("list", "*", emptyList)
+
+	m, _ := NewMultiClusterClient("")
+	m.rawConfig = &api.Config{Contexts: map[string]*api.Context{
+		"src":  {Cluster: "source"},
+		"tgt1": {Cluster: "target1"},
+		"tgt2": {Cluster: "target2"},
+	}}
+	m.dynamicClients["src"] = sourceClient
+	var mu sync.Mutex
+	replicas := make(map[string]interface{})
+	for _, name := range []string{"tgt1", "tgt2"} {
+		target := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), buildTestGVRMap())
+		target.PrependReactor("list", "*", emptyList)
+		target.PrependReactor("create", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+			mu.Lock()
+			replicas[name] = obj.Object["spec"].(map[string]interface{})["replicas"]
+			mu.Unlock()
+			return true, obj, nil
+		})
+		m.dynamicClients[name] = target
+	}
+
+	opts := &DeployOptions{DeployedBy: "test-user", ClusterReplicas: map[string]int32{"tgt1": 0}}
+	resp, err := m.DeployWorkload(context.Background(), "src", "default", "dep1", []string{"tgt1", "tgt2"}, 5, opts)
+	if err != nil {
+		t.Fatalf("DeployWorkload failed: %v", err)
+	}
+	if len(resp.DeployedTo) != 2 {
+		t.Fatalf("Expected both clusters deployed, got %v", resp.DeployedTo)
+	}
+	if replicas["tgt1"] != int64(0) {
+		t.Errorf("tgt1 replicas = %v, want the override 0", replicas["tgt1"])
+	}
+	if replicas["tgt2"] != int64(5) {
+		t.Errorf("tgt2 replicas = %v, want 5", replicas["tgt2"])
+	}
+}
+
+func TestRenderWorkload(t *testing.T) {
+	deployObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "dep1", "namespace": "default", "resourceVersion": "7"},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{"name": "c1", "image": "nginx"}},
+					},
+				},
+			},
+		},
+	}
+
+	sourceClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), buildTestGVRMap(), deployObj)
+	sourceClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &unstructured.UnstructuredList{Items: []unstructured.Unstructured{}}, nil
+	})
+
+	m, _ := NewMultiClusterClient("")
+	m.dynamicClients["src"] = sourceClient
+
+	objs, err := m.RenderWorkload(context.Background(), "src", "default", "dep1", 3, &DeployOptions{DeployedBy: "test-user"})
+	if err != nil {
+		t.Fatalf("RenderWorkload failed: %v", err)
+	}
+	if len(objs) == 0 || objs[0].GetKind() != "Deployment" {
+		t.Fatalf("expected the workload first, got %v", objs)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(objs[0].Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("expected replicas=3, got %d", replicas)
+	}
+	if objs[0].GetResourceVersion() != "" {
+		t.Error("expected resourceVersion to be stripped from the rendered manifest")
+	}
+	if objs[0].GetLabels()["kubestellar.io/deployed-by"] != "test-user" {
+		t.Errorf("expected deployed-by label, got %v", objs[0].GetLabels())
+	}
+	for _, action := range sourceClient.Actions() {
+		if action.GetVerb() != "get" && action.GetVerb() != "list" {
+			t.Errorf("render must not write to the source cluster, saw %s", action.GetVerb())
+		}
+	}
+}
+
+func TestRenderWorkload_GeneratedNetworkPolicy(t *testing.T) {
+	deployObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "dep1", "namespace": "default"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "dep1"}},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{"name": "c1", "image": "nginx"}},
+					},
+				},
+			},
+		},
+	}
+	sourceClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), buildTestGVRMap(), deployObj)
+	sourceClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &unstructured.UnstructuredList{Items: []unstructured.Unstructured{}}, nil
+	})
+	m, _ := NewMultiClusterClient("")
+	m.dynamicClients["src"] = sourceClient
+
+	objs, err := m.RenderWorkload(context.Background(), "src", "default", "dep1", 0, &DeployOptions{
+		DeployedBy:    "test-user",
+		NetworkPolicy: &v1alpha1.GeneratedNetworkPolicy{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("RenderWorkload failed: %v", err)
+	}
+	if len(objs) != 2 || objs[1].GetKind() != "NetworkPolicy" {
+		t.Fatalf("expected the workload and its generated NetworkPolicy, got %v", objs)
+	}
+	np := objs[1]
+	if np.GetName() != "dep1-console-baseline" || np.GetNamespace() != "default" {
+		t.Errorf("generated policy is %s/%s", np.GetNamespace(), np.GetName())
+	}
+	if np.GetLabels()[GeneratedNetworkPolicyLabel] != "true" || np.GetLabels()["kubestellar.io/managed-by"] != "kubestellar-console" {
+		t.Errorf("generated policy labels: %v", np.GetLabels())
+	}
+
+	objs, err = m.RenderWorkload(context.Background(), "src", "default", "dep1", 0, &DeployOptions{
+		NetworkPolicy: &v1alpha1.GeneratedNetworkPolicy{},
+	})
+	if err != nil {
+		t.Fatalf("RenderWorkload failed: %v", err)
+	}
+	if len(objs) != 1 {
+		t.Errorf("a disabled policy must not be generated, got %v", objs)
+	}
+}
+
 func TestDeployWorkloadWithFailingDependency(t *testing.T) {
 	deployObj := &unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -0,0 +1,72 @@
+package limits
+
+import (
+	"os"
+
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+// SettingsSourceName is the name of the settings Source.
+const SettingsSourceName = "settings"
+
+// PublicKey is the base64 ed25519 key licenses are verified with, normally
+// set at build time with -ldflags "-X
+// github.com/kubestellar/console/pkg/limits.PublicKey=...".
+// LICENSE_PUBLIC_KEY overrides it.
+var PublicKey string
+
+// SettingsStore reads the admin-configured limits. The settings manager
+// implements it.
+type SettingsStore interface {
+	GetLimits() settings.LimitsSettings
+}
+
+type settingsSource struct {
+	store SettingsStore
+}
+
+// NewSettingsSource creates a source of the limits and project quotas
+// stored in settings.
+func NewSettingsSource(store SettingsStore) Source {
+	return settingsSource{store: store}
+}
+
+func (s settingsSource) Name() string { return SettingsSourceName }
+
+func (s settingsSource) Limits() (Limits, error) {
+	l := s.store.GetLimits()
+	return Limits{
+		MaxClusters:         l.MaxClusters,
+		MaxManagedWorkloads: l.MaxManagedWorkloads,
+		MaxUsers:            l.MaxUsers,
+	}, nil
+}
+
+func (s settingsSource) Quotas() Quotas {
+	projects := s.store.GetLimits().Projects
+	out := make(Quotas, len(projects))
+	for p, q := range projects {
+		out[p] = ProjectQuota(q)
+	}
+	return out
+}
+
+// FromEnv creates the enforcer of the limits in settings and, when
+// LICENSE_FILE is set, of that license file. The license is verified with
+// LICENSE_PUBLIC_KEY or else PublicKey; a missing or bad key makes the
+// license unusable, which refuses creations rather than lifting its limits.
+// A nil store leaves out the settings.
+func FromEnv(store SettingsStore) *Enforcer {
+	var sources []Source
+	if store != nil {
+		sources = append(sources, NewSettingsSource(store))
+	}
+	if path := os.Getenv("LICENSE_FILE"); path != "" {
+		key := os.Getenv("LICENSE_PUBLIC_KEY")
+		if key == "" {
+			key = PublicKey
+		}
+		sources = append(sources, NewLicenseSource(path, key))
+	}
+	return NewEnforcer(sources...)
+}
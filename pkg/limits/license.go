@@ -0,0 +1,139 @@
+package limits
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LicenseSourceName is the name of the license Source.
+const LicenseSourceName = "license"
+
+// License is the signed part of a license file.
+type License struct {
+	ID        string    `json:"id"`
+	Licensee  string    `json:"licensee"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	Limits    Limits    `json:"limits"`
+}
+
+// licenseFile is the on-disk form of a license: the license JSON and an
+// ed25519 signature of exactly those bytes, base64 encoded.
+type licenseFile struct {
+	License   json.RawMessage `json:"license"`
+	Signature string          `json:"signature"`
+}
+
+// ErrLicenseExpired is returned for licenses past their expiry.
+var ErrLicenseExpired = errors.New("license expired")
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid license public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid license public key: %d bytes, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ParseLicense verifies a license file against key and returns the license.
+// It does not check expiry.
+func ParseLicense(data []byte, key ed25519.PublicKey) (*License, error) {
+	var f licenseFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid license file: %w", err)
+	}
+	if len(f.License) == 0 || f.Signature == "" {
+		return nil, errors.New("invalid license file: license and signature are required")
+	}
+	sig, err := base64.StdEncoding.DecodeString(f.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid license signature: %w", err)
+	}
+	if !ed25519.Verify(key, f.License, sig) {
+		return nil, errors.New("license signature does not verify")
+	}
+	var l License
+	if err := json.Unmarshal(f.License, &l); err != nil {
+		return nil, fmt.Errorf("invalid license: %w", err)
+	}
+	if err := l.Limits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid license: %w", err)
+	}
+	return &l, nil
+}
+
+// LicenseSource reads limits from a license file. The file is re-read when
+// it changes, so a renewed license applies without a restart.
+type LicenseSource struct {
+	path string
+	key  ed25519.PublicKey
+	// keyErr is set when the public key is misconfigured; every read fails
+	// with it.
+	keyErr error
+	now    func() time.Time
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	license *License
+	err     error
+}
+
+// NewLicenseSource creates a source reading the license file at path,
+// verified against the base64 ed25519 public key.
+func NewLicenseSource(path, publicKey string) *LicenseSource {
+	s := &LicenseSource{path: path, now: time.Now}
+	s.key, s.keyErr = ParsePublicKey(publicKey)
+	return s
+}
+
+// Name implements Source.
+func (s *LicenseSource) Name() string { return LicenseSourceName }
+
+// Limits implements Source. An expired license fails with
+// ErrLicenseExpired.
+func (s *LicenseSource) Limits() (Limits, error) {
+	l, err := s.License()
+	if err != nil {
+		return Limits{}, err
+	}
+	if !l.ExpiresAt.IsZero() && s.now().After(l.ExpiresAt) {
+		return Limits{}, fmt.Errorf("%w on %s", ErrLicenseExpired, l.ExpiresAt.UTC().Format(time.DateOnly))
+	}
+	return l.Limits, nil
+}
+
+// License returns the verified license, re-reading the file if it changed.
+func (s *LicenseSource) License() (*License, error) {
+	if s.keyErr != nil {
+		return nil, s.keyErr
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read license file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if (s.license != nil || s.err != nil) && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.license, s.err
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read license file: %w", err)
+	}
+	s.modTime, s.size = info.ModTime(), info.Size()
+	s.license, s.err = ParseLicense(data, s.key)
+	return s.license, s.err
+}
@@ -0,0 +1,142 @@
+package limits
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signLicense(t *testing.T, priv ed25519.PrivateKey, l License) []byte {
+	t.Helper()
+	raw, err := json.Marshal(l)
+	require.NoError(t, err)
+	data, err := json.Marshal(licenseFile{
+		License:   raw,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(priv, raw)),
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func newKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(pub), priv
+}
+
+func TestParseLicense(t *testing.T) {
+	pubB64, priv := newKey(t)
+	pub, err := ParsePublicKey(pubB64)
+	require.NoError(t, err)
+	data := signLicense(t, priv, License{ID: "lic-1", Licensee: "Acme", Limits: Limits{MaxClusters: 50}})
+
+	l, err := ParseLicense(data, pub)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", l.Licensee)
+	assert.Equal(t, 50, l.Limits.MaxClusters)
+
+	// Raising a limit in the file breaks the signature.
+	var f licenseFile
+	require.NoError(t, json.Unmarshal(data, &f))
+	f.License = json.RawMessage(`{"id":"lic-1","licensee":"Acme","limits":{"maxClusters":5000}}`)
+	tampered, err := json.Marshal(f)
+	require.NoError(t, err)
+	_, err = ParseLicense(tampered, pub)
+	assert.ErrorContains(t, err, "signature does not verify")
+
+	otherB64, _ := newKey(t)
+	other, err := ParsePublicKey(otherB64)
+	require.NoError(t, err)
+	_, err = ParseLicense(data, other)
+	assert.Error(t, err, "signed with another key")
+
+	_, err = ParseLicense([]byte(`{"license":{}}`), pub)
+	assert.Error(t, err, "missing signature")
+}
+
+func TestParsePublicKey(t *testing.T) {
+	_, err := ParsePublicKey("")
+	assert.Error(t, err)
+	_, err = ParsePublicKey("not base64!")
+	assert.Error(t, err)
+	_, err = ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
+
+func TestLicenseSource(t *testing.T) {
+	pubB64, priv := newKey(t)
+	path := filepath.Join(t.TempDir(), "license.json")
+	require.NoError(t, os.WriteFile(path, signLicense(t, priv, License{
+		ID:        "lic-1",
+		ExpiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Limits:    Limits{MaxUsers: 10},
+	}), 0o600))
+
+	s := NewLicenseSource(path, pubB64)
+	s.now = func() time.Time { return time.Date(2029, 6, 1, 0, 0, 0, 0, time.UTC) }
+	l, err := s.Limits()
+	require.NoError(t, err)
+	assert.Equal(t, 10, l.MaxUsers)
+
+	// A renewed license is picked up without recreating the source.
+	require.NoError(t, os.WriteFile(path, signLicense(t, priv, License{
+		ID:     "lic-2",
+		Limits: Limits{MaxUsers: 250},
+	}), 0o600))
+	l, err = s.Limits()
+	require.NoError(t, err)
+	assert.Equal(t, 250, l.MaxUsers)
+
+	require.NoError(t, os.Remove(path))
+	_, err = s.Limits()
+	assert.Error(t, err)
+}
+
+func TestLicenseSource_Expired(t *testing.T) {
+	pubB64, priv := newKey(t)
+	path := filepath.Join(t.TempDir(), "license.json")
+	require.NoError(t, os.WriteFile(path, signLicense(t, priv, License{
+		ID:        "lic-1",
+		ExpiresAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Limits:    Limits{MaxClusters: 10},
+	}), 0o600))
+
+	s := NewLicenseSource(path, pubB64)
+	s.now = func() time.Time { return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC) }
+	_, err := s.Limits()
+	assert.ErrorIs(t, err, ErrLicenseExpired)
+
+	// The license itself is still readable for display.
+	l, err := s.License()
+	require.NoError(t, err)
+	assert.Equal(t, "lic-1", l.ID)
+
+	assert.ErrorIs(t, NewEnforcer(s).Check(Clusters, 0, 1), ErrSourceUnavailable)
+}
+
+func TestLicenseSource_BadKey(t *testing.T) {
+	s := NewLicenseSource(filepath.Join(t.TempDir(), "license.json"), "")
+	_, err := s.Limits()
+	assert.ErrorContains(t, err, "public key")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LICENSE_FILE", "")
+	e := FromEnv(nil)
+	assert.Nil(t, e.License())
+	assert.NoError(t, e.Check(Clusters, 1000, 1))
+
+	t.Setenv("LICENSE_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv("LICENSE_PUBLIC_KEY", "")
+	e = FromEnv(nil)
+	require.NotNil(t, e.License())
+	assert.ErrorIs(t, e.Check(Clusters, 0, 1), ErrSourceUnavailable)
+}
@@ -0,0 +1,252 @@
+// Package limits caps how many clusters, managed workloads and users a
+// console manages. Each cap comes from settings or a signed license file,
+// and creation endpoints check it before adding anything.
+package limits
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Resource names a counted thing a limit applies to.
+type Resource string
+
+const (
+	Clusters         Resource = "clusters"
+	ManagedWorkloads Resource = "managedWorkloads"
+	Users            Resource = "users"
+)
+
+// Resources lists every limited resource.
+var Resources = []Resource{Clusters, ManagedWorkloads, Users}
+
+// Limits are the maximum counts of each resource. Zero means unlimited.
+type Limits struct {
+	MaxClusters         int `json:"maxClusters,omitempty"`
+	MaxManagedWorkloads int `json:"maxManagedWorkloads,omitempty"`
+	MaxUsers            int `json:"maxUsers,omitempty"`
+}
+
+// For returns the limit on r, 0 if unlimited.
+func (l Limits) For(r Resource) int {
+	switch r {
+	case Clusters:
+		return l.MaxClusters
+	case ManagedWorkloads:
+		return l.MaxManagedWorkloads
+	case Users:
+		return l.MaxUsers
+	}
+	return 0
+}
+
+// Validate rejects negative limits.
+func (l Limits) Validate() error {
+	for _, r := range Resources {
+		if n := l.For(r); n < 0 {
+			return fmt.Errorf("%s limit must not be negative, got %d", r, n)
+		}
+	}
+	return nil
+}
+
+// Source supplies limits, such as the settings file or a license.
+type Source interface {
+	// Name identifies the source in errors and the limits API.
+	Name() string
+	// Limits returns the source's current limits. An error means the source
+	// is configured but unusable, such as an expired license.
+	Limits() (Limits, error)
+}
+
+var (
+	// ErrLimitExceeded is wrapped by the errors of creations that would go
+	// over a limit.
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// ErrSourceUnavailable is wrapped by the errors of creations refused
+	// because a source of limits could not be read, so that a broken or
+	// expired license cannot lift its limits.
+	ErrSourceUnavailable = errors.New("limits unavailable")
+)
+
+// Error codes of refused creations, for API error payloads.
+const (
+	CodeLimitExceeded     = "limit_exceeded"
+	CodeSourceUnavailable = "limits_unavailable"
+)
+
+// ExceededError reports a creation that would go over a limit.
+type ExceededError struct {
+	Resource Resource `json:"resource"`
+	Limit    int      `json:"limit"`
+	Current  int      `json:"current"`
+	Adding   int      `json:"adding"`
+	Source   string   `json:"source"`
+	// Project is set when a project's quota is exceeded.
+	Project string `json:"project,omitempty"`
+}
+
+func (e *ExceededError) Error() string {
+	if e.Project != "" {
+		return fmt.Sprintf("project %s %s quota of %d reached: %d in use, %d more requested (quota set by %s)",
+			e.Project, e.Resource, e.Limit, e.Current, e.Adding, e.Source)
+	}
+	return fmt.Sprintf("%s limit of %d reached: %d in use, %d more requested (limit set by %s)",
+		e.Resource, e.Limit, e.Current, e.Adding, e.Source)
+}
+
+func (e *ExceededError) Unwrap() error { return ErrLimitExceeded }
+
+// Code returns the error code of an error returned by Enforcer.Check, or ""
+// if err is not a refusal.
+func Code(err error) string {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return CodeRateLimited
+	case errors.Is(err, ErrLimitExceeded):
+		return CodeLimitExceeded
+	case errors.Is(err, ErrSourceUnavailable):
+		return CodeSourceUnavailable
+	}
+	return ""
+}
+
+// Payload is the JSON error body of a creation refused by Check or a
+// project quota: the error message and code, plus the resource, counts and
+// project when it is over a limit or quota.
+func Payload(err error) map[string]interface{} {
+	p := map[string]interface{}{"error": err.Error(), "code": Code(err)}
+	var exceeded *ExceededError
+	var rate *RateExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		p["resource"] = exceeded.Resource
+		p["limit"] = exceeded.Limit
+		p["current"] = exceeded.Current
+		p["adding"] = exceeded.Adding
+		if exceeded.Project != "" {
+			p["project"] = exceeded.Project
+		}
+	case errors.As(err, &rate):
+		p["resource"] = rate.Resource
+		p["limit"] = rate.Limit
+		p["current"] = rate.Current
+		p["project"] = rate.Project
+		p["retryAfterSeconds"] = int(rate.RetryAfter.Seconds() + 0.5)
+	}
+	return p
+}
+
+// Limit is the effective limit on a resource and the source setting it.
+type Limit struct {
+	Max    int    `json:"max"`
+	Source string `json:"source,omitempty"`
+}
+
+// Enforcer combines sources of limits. The lowest non-zero limit of any
+// source applies, so settings can tighten a license but not lift it. A nil
+// Enforcer enforces nothing.
+type Enforcer struct {
+	sources []Source
+	license *LicenseSource
+}
+
+// NewEnforcer creates an enforcer of the given sources. Nil sources are
+// skipped.
+func NewEnforcer(sources ...Source) *Enforcer {
+	e := &Enforcer{}
+	for _, s := range sources {
+		if s == nil {
+			continue
+		}
+		if ls, ok := s.(*LicenseSource); ok {
+			if ls == nil {
+				continue
+			}
+			e.license = ls
+		}
+		e.sources = append(e.sources, s)
+	}
+	return e
+}
+
+// Effective returns the limit on each resource. It also returns the errors
+// of sources that could not be read; their limits are left out.
+func (e *Enforcer) Effective() (map[Resource]Limit, error) {
+	out := make(map[Resource]Limit, len(Resources))
+	for _, r := range Resources {
+		out[r] = Limit{}
+	}
+	if e == nil {
+		return out, nil
+	}
+	var errs []error
+	for _, s := range e.sources {
+		l, err := s.Limits()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+			continue
+		}
+		for _, r := range Resources {
+			n := l.For(r)
+			if n > 0 && (out[r].Max == 0 || n < out[r].Max) {
+				out[r] = Limit{Max: n, Source: s.Name()}
+			}
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// Check reports whether adding more of r to the current count stays within
+// the limit. It returns an *ExceededError if not, and an error wrapping
+// ErrSourceUnavailable if a source could not be read.
+func (e *Enforcer) Check(r Resource, current, adding int) error {
+	if e == nil || adding <= 0 {
+		return nil
+	}
+	effective, err := e.Effective()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSourceUnavailable, err)
+	}
+	l := effective[r]
+	if l.Max > 0 && current+adding > l.Max {
+		return &ExceededError{Resource: r, Limit: l.Max, Current: current, Adding: adding, Source: l.Source}
+	}
+	return nil
+}
+
+// QuotaSource is a Source that also sets per-project quotas.
+type QuotaSource interface {
+	Source
+	Quotas() Quotas
+}
+
+// Quotas returns the per-project quotas set by the enforcer's sources.
+// Licenses do not set any; a project in more than one source keeps the
+// first one's quota.
+func (e *Enforcer) Quotas() Quotas {
+	out := Quotas{}
+	if e == nil {
+		return out
+	}
+	for _, s := range e.sources {
+		qs, ok := s.(QuotaSource)
+		if !ok {
+			continue
+		}
+		for p, q := range qs.Quotas() {
+			if _, seen := out[p]; !seen {
+				out[p] = q
+			}
+		}
+	}
+	return out
+}
+
+// License returns the configured license source, or nil if there is none.
+func (e *Enforcer) License() *LicenseSource {
+	if e == nil {
+		return nil
+	}
+	return e.license
+}
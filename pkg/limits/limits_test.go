@@ -0,0 +1,95 @@
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSource struct {
+	name   string
+	limits Limits
+	err    error
+}
+
+func (s staticSource) Name() string            { return s.name }
+func (s staticSource) Limits() (Limits, error) { return s.limits, s.err }
+
+func TestEnforcer_LowestLimitWins(t *testing.T) {
+	e := NewEnforcer(
+		staticSource{name: "settings", limits: Limits{MaxClusters: 5, MaxUsers: 100}},
+		staticSource{name: "license", limits: Limits{MaxClusters: 10, MaxUsers: 20}},
+	)
+	effective, err := e.Effective()
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Max: 5, Source: "settings"}, effective[Clusters])
+	assert.Equal(t, Limit{Max: 20, Source: "license"}, effective[Users])
+	assert.Equal(t, Limit{}, effective[ManagedWorkloads])
+}
+
+func TestEnforcer_Check(t *testing.T) {
+	e := NewEnforcer(staticSource{name: "settings", limits: Limits{MaxClusters: 3}})
+
+	assert.NoError(t, e.Check(Clusters, 2, 1))
+	assert.NoError(t, e.Check(ManagedWorkloads, 1000, 1), "unlimited resource")
+
+	err := e.Check(Clusters, 2, 2)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, CodeLimitExceeded, Code(err))
+	var exceeded *ExceededError
+	require.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, ExceededError{Resource: Clusters, Limit: 3, Current: 2, Adding: 2, Source: "settings"}, *exceeded)
+	assert.Contains(t, err.Error(), "clusters limit of 3 reached")
+
+	// Adding nothing never fails, even over the limit.
+	assert.NoError(t, e.Check(Clusters, 5, 0))
+}
+
+func TestEnforcer_UnavailableSourceRefuses(t *testing.T) {
+	e := NewEnforcer(
+		staticSource{name: "settings", limits: Limits{MaxClusters: 10}},
+		staticSource{name: "license", err: ErrLicenseExpired},
+	)
+	err := e.Check(Users, 0, 1)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrSourceUnavailable)
+	assert.Equal(t, CodeSourceUnavailable, Code(err))
+
+	effective, err := e.Effective()
+	assert.ErrorIs(t, err, ErrLicenseExpired)
+	assert.Equal(t, Limit{Max: 10, Source: "settings"}, effective[Clusters])
+}
+
+func TestEnforcer_Nil(t *testing.T) {
+	var e *Enforcer
+	assert.NoError(t, e.Check(Clusters, 100, 1))
+	assert.Nil(t, e.License())
+	effective, err := e.Effective()
+	require.NoError(t, err)
+	assert.Len(t, effective, len(Resources))
+}
+
+func TestPayload(t *testing.T) {
+	p := Payload(&ExceededError{Resource: Users, Limit: 5, Current: 5, Adding: 1, Source: "license"})
+	assert.Equal(t, CodeLimitExceeded, p["code"])
+	assert.Equal(t, Users, p["resource"])
+	assert.Equal(t, 5, p["limit"])
+
+	p = Payload(fmt.Errorf("%w: license: expired", ErrSourceUnavailable))
+	assert.Equal(t, CodeSourceUnavailable, p["code"])
+	assert.NotContains(t, p, "resource")
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, "", Code(nil))
+	assert.Equal(t, "", Code(errors.New("boom")))
+}
+
+func TestLimitsValidate(t *testing.T) {
+	assert.NoError(t, Limits{MaxClusters: 1}.Validate())
+	assert.Error(t, Limits{MaxUsers: -1}.Validate())
+}
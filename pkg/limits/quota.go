@@ -0,0 +1,191 @@
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Resources limited per project.
+const (
+	ProjectManagedWorkloads Resource = "projectManagedWorkloads"
+	DeploymentsPerDay       Resource = "deploymentsPerDay"
+	TargetClusters          Resource = "targetClusters"
+)
+
+// DeploymentWindow is the period MaxDeploymentsPerDay counts creations over.
+const DeploymentWindow = 24 * time.Hour
+
+// ErrRateLimited is wrapped by the errors of creations refused because a
+// project created too many in the last DeploymentWindow.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// CodeRateLimited is the error code of creations refused by ErrRateLimited.
+const CodeRateLimited = "rate_limited"
+
+// ProjectQuota caps what one project creates. Zero means unlimited.
+type ProjectQuota struct {
+	// Namespaces are the persistence namespaces holding the project's
+	// console CRs. Creations in them count against the quota.
+	Namespaces           []string `json:"namespaces"`
+	MaxManagedWorkloads  int      `json:"maxManagedWorkloads,omitempty"`
+	MaxDeploymentsPerDay int      `json:"maxDeploymentsPerDay,omitempty"`
+	MaxTargetClusters    int      `json:"maxTargetClusters,omitempty"`
+}
+
+// Quotas are per-project quotas, keyed by project name.
+type Quotas map[string]ProjectQuota
+
+// Validate rejects negative quotas, projects without namespaces and
+// namespaces claimed by more than one project.
+func (q Quotas) Validate() error {
+	projects := make([]string, 0, len(q))
+	for p := range q {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+
+	owners := make(map[string]string)
+	for _, p := range projects {
+		quota := q[p]
+		if p == "" {
+			return errors.New("project quota needs a project name")
+		}
+		if len(quota.Namespaces) == 0 {
+			return fmt.Errorf("project %s quota needs at least one namespace", p)
+		}
+		for _, ns := range quota.Namespaces {
+			if owner, dup := owners[ns]; dup {
+				return fmt.Errorf("namespace %s is in the quotas of both %s and %s", ns, owner, p)
+			}
+			owners[ns] = p
+		}
+		for r, n := range map[Resource]int{
+			ProjectManagedWorkloads: quota.MaxManagedWorkloads,
+			DeploymentsPerDay:       quota.MaxDeploymentsPerDay,
+			TargetClusters:          quota.MaxTargetClusters,
+		} {
+			if n < 0 {
+				return fmt.Errorf("project %s %s quota must not be negative, got %d", p, r, n)
+			}
+		}
+	}
+	return nil
+}
+
+// ForNamespace returns the project whose quota covers namespace and that
+// quota. ok is false when no quota covers it.
+func (q Quotas) ForNamespace(namespace string) (project string, quota ProjectQuota, ok bool) {
+	for p, quota := range q {
+		for _, ns := range quota.Namespaces {
+			if ns == namespace {
+				return p, quota, true
+			}
+		}
+	}
+	return "", ProjectQuota{}, false
+}
+
+// CheckManagedWorkloads reports whether one more ManagedWorkload stays
+// within the quota, given the current count across its namespaces.
+func (q ProjectQuota) CheckManagedWorkloads(project string, current int) error {
+	if q.MaxManagedWorkloads > 0 && current+1 > q.MaxManagedWorkloads {
+		return &ExceededError{Resource: ProjectManagedWorkloads, Limit: q.MaxManagedWorkloads,
+			Current: current, Adding: 1, Source: SettingsSourceName, Project: project}
+	}
+	return nil
+}
+
+// CheckManagedWorkload reports whether one more ManagedWorkload in namespace
+// stays within the managed workload limit and the quota of the project
+// owning namespace. count returns how many ManagedWorkloads a namespace
+// holds; the other namespaces of the project are only counted when it has a
+// managed workload quota. A nil Enforcer allows everything.
+func (e *Enforcer) CheckManagedWorkload(namespace string, count func(namespace string) (int, error)) error {
+	if e == nil {
+		return nil
+	}
+	existing, err := count(namespace)
+	if err != nil {
+		return err
+	}
+	if err := e.Check(ManagedWorkloads, existing, 1); err != nil {
+		return err
+	}
+	project, quota, ok := e.Quotas().ForNamespace(namespace)
+	if !ok || quota.MaxManagedWorkloads <= 0 {
+		return nil
+	}
+	total := 0
+	for _, ns := range quota.Namespaces {
+		if ns == namespace {
+			total += existing
+			continue
+		}
+		n, err := count(ns)
+		if err != nil {
+			return err
+		}
+		total += n
+	}
+	return quota.CheckManagedWorkloads(project, total)
+}
+
+// CheckTargetClusters reports whether a WorkloadDeployment targeting
+// clusters distinct clusters stays within the quota.
+func (q ProjectQuota) CheckTargetClusters(project string, clusters int) error {
+	if q.MaxTargetClusters > 0 && clusters > q.MaxTargetClusters {
+		return &ExceededError{Resource: TargetClusters, Limit: q.MaxTargetClusters,
+			Adding: clusters, Source: SettingsSourceName, Project: project}
+	}
+	return nil
+}
+
+// CheckDeploymentsPerDay reports whether one more WorkloadDeployment stays
+// within the daily quota, given when the project's existing ones were
+// created. It returns a *RateExceededError saying when the oldest creation
+// in the window leaves it if not.
+func (q ProjectQuota) CheckDeploymentsPerDay(project string, created []time.Time, now time.Time) error {
+	if q.MaxDeploymentsPerDay <= 0 {
+		return nil
+	}
+	since := now.Add(-DeploymentWindow)
+	var recent []time.Time
+	for _, t := range created {
+		if t.After(since) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent)+1 <= q.MaxDeploymentsPerDay {
+		return nil
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i].Before(recent[j]) })
+	// Enough of the oldest must leave the window to make room for one more.
+	freeing := recent[len(recent)-q.MaxDeploymentsPerDay]
+	return &RateExceededError{
+		Project:    project,
+		Resource:   DeploymentsPerDay,
+		Limit:      q.MaxDeploymentsPerDay,
+		Current:    len(recent),
+		RetryAfter: freeing.Add(DeploymentWindow).Sub(now),
+	}
+}
+
+// RateExceededError reports a creation over a project's quota for the last
+// DeploymentWindow.
+type RateExceededError struct {
+	Project  string   `json:"project"`
+	Resource Resource `json:"resource"`
+	Limit    int      `json:"limit"`
+	Current  int      `json:"current"`
+	// RetryAfter is how long until the creation would be allowed.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *RateExceededError) Error() string {
+	return fmt.Sprintf("project %s %s quota of %d reached: %d created in the last %s, retry in %s",
+		e.Project, e.Resource, e.Limit, e.Current, DeploymentWindow, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateExceededError) Unwrap() error { return ErrRateLimited }
@@ -0,0 +1,125 @@
+package limits
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+type quotaSettings settings.LimitsSettings
+
+func (q quotaSettings) GetLimits() settings.LimitsSettings { return settings.LimitsSettings(q) }
+
+func TestQuotasValidate(t *testing.T) {
+	assert.NoError(t, Quotas{
+		"payments": {Namespaces: []string{"payments-console"}, MaxManagedWorkloads: 10},
+		"platform": {Namespaces: []string{"platform-console", "platform-staging"}},
+	}.Validate())
+	assert.Error(t, Quotas{"payments": {}}.Validate(), "no namespaces")
+	assert.Error(t, Quotas{"": {Namespaces: []string{"ns"}}}.Validate(), "no name")
+	assert.Error(t, Quotas{"payments": {Namespaces: []string{"ns"}, MaxDeploymentsPerDay: -1}}.Validate())
+
+	err := Quotas{
+		"a": {Namespaces: []string{"shared"}},
+		"b": {Namespaces: []string{"shared"}},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "namespace shared is in the quotas of both a and b")
+}
+
+func TestQuotasForNamespace(t *testing.T) {
+	q := Quotas{"payments": {Namespaces: []string{"payments-console"}, MaxTargetClusters: 3}}
+	project, quota, ok := q.ForNamespace("payments-console")
+	require.True(t, ok)
+	assert.Equal(t, "payments", project)
+	assert.Equal(t, 3, quota.MaxTargetClusters)
+
+	_, _, ok = q.ForNamespace("kubestellar-console")
+	assert.False(t, ok)
+}
+
+func TestProjectQuota_Checks(t *testing.T) {
+	q := ProjectQuota{MaxManagedWorkloads: 2, MaxTargetClusters: 3}
+
+	assert.NoError(t, q.CheckManagedWorkloads("payments", 1))
+	err := q.CheckManagedWorkloads("payments", 2)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, "project payments projectManagedWorkloads quota of 2 reached: 2 in use, 1 more requested (quota set by settings)", err.Error())
+
+	assert.NoError(t, q.CheckTargetClusters("payments", 3))
+	err = q.CheckTargetClusters("payments", 4)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	p := Payload(err)
+	assert.Equal(t, TargetClusters, p["resource"])
+	assert.Equal(t, "payments", p["project"])
+
+	assert.NoError(t, ProjectQuota{}.CheckManagedWorkloads("payments", 1000), "unlimited")
+}
+
+func TestProjectQuota_CheckDeploymentsPerDay(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	q := ProjectQuota{MaxDeploymentsPerDay: 2}
+	created := []time.Time{
+		now.Add(-25 * time.Hour), // outside the window
+		now.Add(-time.Hour),
+	}
+	assert.NoError(t, q.CheckDeploymentsPerDay("payments", created, now))
+
+	created = append(created, now.Add(-20*time.Hour))
+	err := q.CheckDeploymentsPerDay("payments", created, now)
+	require.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, CodeRateLimited, Code(err))
+	var rate *RateExceededError
+	require.True(t, errors.As(err, &rate))
+	assert.Equal(t, 2, rate.Current)
+	assert.Equal(t, 4*time.Hour, rate.RetryAfter, "the creation 20h ago leaves the window in 4h")
+	assert.Equal(t, 14400, Payload(err)["retryAfterSeconds"])
+}
+
+func TestEnforcer_Quotas(t *testing.T) {
+	var nilEnforcer *Enforcer
+	assert.Empty(t, nilEnforcer.Quotas())
+
+	e := NewEnforcer(
+		NewSettingsSource(quotaSettings{Projects: map[string]settings.ProjectQuotaSettings{
+			"payments": {Namespaces: []string{"payments-console"}, MaxDeploymentsPerDay: 5},
+		}}),
+		staticSource{name: "license", limits: Limits{MaxClusters: 10}},
+	)
+	assert.Equal(t, Quotas{"payments": {Namespaces: []string{"payments-console"}, MaxDeploymentsPerDay: 5}}, e.Quotas())
+}
+
+func TestEnforcer_CheckManagedWorkload(t *testing.T) {
+	counts := map[string]int{"payments-console": 2, "payments-staging": 1, "shop": 4}
+	count := func(ns string) (int, error) { return counts[ns], nil }
+
+	var nilEnforcer *Enforcer
+	assert.NoError(t, nilEnforcer.CheckManagedWorkload("shop", count))
+
+	e := NewEnforcer(NewSettingsSource(quotaSettings{
+		MaxManagedWorkloads: 5,
+		Projects: map[string]settings.ProjectQuotaSettings{
+			"payments": {Namespaces: []string{"payments-console", "payments-staging"}, MaxManagedWorkloads: 3},
+		},
+	}))
+	assert.NoError(t, e.CheckManagedWorkload("shop", count))
+
+	err := e.CheckManagedWorkload("payments-console", count)
+	var exceeded *ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, "payments", exceeded.Project)
+	assert.Equal(t, 3, exceeded.Current)
+
+	counts["shop"] = 5
+	err = e.CheckManagedWorkload("shop", count)
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, ManagedWorkloads, exceeded.Resource)
+
+	listErr := errors.New("list failed")
+	assert.ErrorIs(t, e.CheckManagedWorkload("shop", func(string) (int, error) { return 0, listErr }), listErr)
+}
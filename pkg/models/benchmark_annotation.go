@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BenchmarkAnnotation is the star, note and labels attached to one benchmark
+// run, keyed by the report's Run.UID. Annotations are shared by every
+// console user, e.g. a run starred and labeled "baseline-H100" by one
+// engineer shows up that way for the whole team.
+type BenchmarkAnnotation struct {
+	ReportUID string    `json:"reportUid"`
+	Starred   bool      `json:"starred"`
+	Note      string    `json:"note,omitempty"`
+	Labels    []string  `json:"labels"`
+	UpdatedBy uuid.UUID `json:"updatedBy"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
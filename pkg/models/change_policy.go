@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ChangePolicy is a console project's requirements for the change metadata
+// WorkloadDeployments carry. A project without a saved policy requires
+// nothing.
+type ChangePolicy struct {
+	Project            string `json:"project"`
+	RequireDescription bool   `json:"require_description"`
+	RequireTicket      bool   `json:"require_ticket"`
+	RequireApprover    bool   `json:"require_approver"`
+	// TicketURLPrefix, when set, restricts ticket links to one tracker,
+	// e.g. "https://jira.example.com/browse/".
+	TicketURLPrefix string     `json:"ticket_url_prefix,omitempty"`
+	UpdatedBy       string     `json:"updated_by,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
@@ -14,6 +14,7 @@ type Dashboard struct {
 	Name      string          `json:"name"`
 	Layout    json.RawMessage `json:"layout,omitempty"`
 	IsDefault bool            `json:"is_default"`
+	Version   int             `json:"version"`
 	CreatedAt time.Time       `json:"created_at"`
 	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
 }
@@ -23,3 +24,28 @@ type DashboardWithCards struct {
 	Dashboard
 	Cards []Card `json:"cards"`
 }
+
+// DashboardSnapshot is an immutable copy of a dashboard and the data its
+// cards showed at creation time. Snapshots are read through a signed public
+// URL, so they carry no owner details in their JSON form.
+type DashboardSnapshot struct {
+	ID          uuid.UUID       `json:"id"`
+	DashboardID uuid.UUID       `json:"dashboard_id"`
+	UserID      uuid.UUID       `json:"-"`
+	Name        string          `json:"name"`
+	Layout      json.RawMessage `json:"layout,omitempty"`
+	Cards       []SnapshotCard  `json:"cards"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+// SnapshotCard is one card of a DashboardSnapshot. Data is whatever the
+// client rendered for the card when the snapshot was taken.
+type SnapshotCard struct {
+	ID       uuid.UUID       `json:"id"`
+	CardType CardType        `json:"card_type"`
+	Config   json.RawMessage `json:"config,omitempty"`
+	Position CardPosition    `json:"position"`
+	Summary  string          `json:"summary,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HubEvent is one WebSocket broadcast kept so clients that were away, e.g.
+// while the backend restarted, can replay what they missed. UserID is
+// uuid.Nil for a broadcast to every client. Message is the encoded message
+// as it was sent, including its sequence number.
+type HubEvent struct {
+	Seq       int64           `json:"seq"`
+	UserID    uuid.UUID       `json:"userId"`
+	Type      string          `json:"type"`
+	Message   json.RawMessage `json:"message"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
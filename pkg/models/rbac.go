@@ -165,6 +165,64 @@ type CanIResponse struct {
 	Reason  string `json:"reason,omitempty"`
 }
 
+// AuthzCheck is one verb/resource tuple of a permission preflight.
+type AuthzCheck struct {
+	Verb        string `json:"verb"`
+	Resource    string `json:"resource"`
+	Group       string `json:"group,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// AuthzCheckResult is the verdict for one AuthzCheck. Error is set when the
+// access review could not be performed; Allowed is then false.
+type AuthzCheckResult struct {
+	AuthzCheck
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PermissionResourceRule is one rule of a SelfSubjectRulesReview: the verbs
+// allowed on some resources. "*" matches anything in each list; an empty
+// ResourceNames means every object.
+type PermissionResourceRule struct {
+	Verbs         []string `json:"verbs"`
+	APIGroups     []string `json:"apiGroups,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+// PermissionNonResourceRule lists the verbs allowed on non-resource URLs such
+// as /healthz.
+type PermissionNonResourceRule struct {
+	Verbs           []string `json:"verbs"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// ClusterPermissionMatrix is what the caller may do in one namespace of one
+// cluster. Operations holds the verdicts for the console's own actions. When
+// Incomplete is set the cluster could not list every rule, and operations the
+// rules do not cover were checked one by one instead. Error is set when the
+// cluster could not be reviewed at all.
+type ClusterPermissionMatrix struct {
+	Cluster          string                      `json:"cluster"`
+	Namespace        string                      `json:"namespace"`
+	Operations       []AuthzCheckResult          `json:"operations"`
+	ResourceRules    []PermissionResourceRule    `json:"resourceRules,omitempty"`
+	NonResourceRules []PermissionNonResourceRule `json:"nonResourceRules,omitempty"`
+	Incomplete       bool                        `json:"incomplete,omitempty"`
+	EvaluationError  string                      `json:"evaluationError,omitempty"`
+	Error            string                      `json:"error,omitempty"`
+}
+
+// PermissionMatrixResponse is the response of the kc-agent /rbac/matrix
+// endpoint, one entry per cluster.
+type PermissionMatrixResponse struct {
+	Clusters []ClusterPermissionMatrix `json:"clusters"`
+}
+
 // PermissionsSummaryResponse represents the API response for permission summaries
 type PermissionsSummaryResponse struct {
 	Clusters map[string]ClusterPermissionsSummary `json:"clusters"`
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedViewQuery is the resource query a saved view executes: one resource
+// kind across a set of clusters, optionally narrowed by namespace and label
+// selector. Clusters and ClusterGroup are alternatives — when ClusterGroup is
+// set its current member list is resolved at execution time; when both are
+// empty the query runs against every healthy cluster.
+type SavedViewQuery struct {
+	Group         string   `json:"group,omitempty"`
+	Version       string   `json:"version"`
+	Resource      string   `json:"resource"`
+	Namespace     string   `json:"namespace,omitempty"`
+	Clusters      []string `json:"clusters,omitempty"`
+	ClusterGroup  string   `json:"cluster_group,omitempty"`
+	LabelSelector string   `json:"label_selector,omitempty"`
+	// Columns restricts the result table to the named printer columns, in
+	// order. Empty keeps every column the apiserver prints.
+	Columns []string `json:"columns,omitempty"`
+}
+
+// SavedView is a named, reusable cross-cluster resource query shared by all
+// users of a console project (e.g. "all GPU pods in the prod group").
+type SavedView struct {
+	ID        uuid.UUID      `json:"id"`
+	Project   string         `json:"project"`
+	Name      string         `json:"name"`
+	Query     SavedViewQuery `json:"query"`
+	CreatedBy uuid.UUID      `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SLOKind is what a service level objective measures.
+type SLOKind string
+
+const (
+	// SLOKindAvailability counts non-5xx responses as good requests.
+	SLOKindAvailability SLOKind = "availability"
+	// SLOKindLatency counts requests served within the latency threshold as
+	// good requests.
+	SLOKindLatency SLOKind = "latency"
+)
+
+// SLOTarget is the service or inference workload an SLO is bound to. Service
+// and Workload are alternatives; Workload matches pods by name prefix, which
+// covers Deployments, StatefulSets and InferenceService predictors alike.
+type SLOTarget struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Service   string `json:"service,omitempty"`
+	Workload  string `json:"workload,omitempty"`
+}
+
+// SLOIndicator describes how good and total events are counted in
+// Prometheus. When GoodQuery and TotalQuery are empty the queries are derived
+// from the SLO kind and Metric. Custom queries may use $selector for the
+// target's label matchers and $window for the evaluation range.
+type SLOIndicator struct {
+	// Metric is the request counter (availability) or histogram base name
+	// (latency). Empty selects the kind's default.
+	Metric string `json:"metric,omitempty"`
+	// ThresholdSeconds is the latency bound of a latency SLO. It must match
+	// one of the histogram's bucket boundaries.
+	ThresholdSeconds float64 `json:"threshold_seconds,omitempty"`
+	GoodQuery        string  `json:"good_query,omitempty"`
+	TotalQuery       string  `json:"total_query,omitempty"`
+}
+
+// SLO is a service level objective shared by all users of a console project,
+// e.g. "99.5% of vLLM requests in prod succeed over 30 days".
+type SLO struct {
+	ID          uuid.UUID    `json:"id"`
+	Project     string       `json:"project"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Kind        SLOKind      `json:"kind"`
+	Target      SLOTarget    `json:"target"`
+	Indicator   SLOIndicator `json:"indicator"`
+	// Objective is the fraction of good events required, e.g. 0.999.
+	Objective  float64 `json:"objective"`
+	WindowDays int     `json:"window_days"`
+	// AlertsEnabled sends burn-rate alerts to the notification channels.
+	AlertsEnabled bool       `json:"alerts_enabled"`
+	CreatedBy     uuid.UUID  `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
@@ -96,3 +96,18 @@ func GetOnboardingQuestions() []OnboardingQuestion {
 		},
 	}
 }
+
+// UserSession is a signed-in browser or device. Every JWT carries the ID of
+// its session in the sid claim, so revoking the session signs the device out
+// even though the JWT itself has not expired.
+type UserSession struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
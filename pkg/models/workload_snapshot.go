@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkloadSnapshot is a stored capture of a workload's live object plus the
+// ConfigMaps and Secrets it references, taken so the set can be re-applied
+// after a risky change.
+type WorkloadSnapshot struct {
+	ID        uuid.UUID `json:"id"`
+	Cluster   string    `json:"cluster"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	// Description is an optional note, e.g. "before node pool migration".
+	Description string `json:"description,omitempty"`
+	// Redacted is set when Secret values were dropped at capture time.
+	Redacted bool `json:"redacted"`
+	// Contents lists the captured objects as Kind/name, in restore order.
+	Contents []string `json:"contents"`
+	// Missing lists referenced objects that did not exist at capture time.
+	Missing []string `json:"missing,omitempty"`
+	// Objects are the captured manifests. Omitted from listings.
+	Objects   []map[string]interface{} `json:"objects,omitempty"`
+	CreatedBy string                   `json:"created_by"`
+	CreatedAt time.Time                `json:"created_at"`
+}
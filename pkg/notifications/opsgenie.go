@@ -93,6 +93,13 @@ func (o *OpsGenieNotifier) createAlert(alert Alert, alias string) error {
 		"resource":     alert.Resource,
 		"resourceKind": alert.ResourceKind,
 	}
+	// OpsGenie details are string-valued; the alert's own details are added
+	// without overriding the fields above.
+	for k, v := range alert.Details {
+		if _, set := details[k]; !set {
+			details[k] = fmt.Sprint(v)
+		}
+	}
 
 	ogAlert := opsgenieAlert{
 		Message:     message,
@@ -76,6 +76,32 @@ func TestOpsGenie_Send_UsesFallbackAliasWhenAllEmpty(t *testing.T) {
 	require.Contains(t, captured.Alias, "fallback-")
 }
 
+func TestOpsGenie_Send_IncludesAlertDetails(t *testing.T) {
+	var captured opsgenieAlert
+	o := NewOpsGenieNotifier("test-key")
+	o.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		json.Unmarshal(body, &captured)
+		return &http.Response{
+			StatusCode: http.StatusAccepted,
+			Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+		}, nil
+	})
+
+	alert := Alert{
+		RuleName: "WorkloadDeployment Complete",
+		Severity: SeverityInfo,
+		Resource: "checkout-v2",
+		Details:  map[string]interface{}{"change_ticket": "https://jira.example.com/browse/OPS-12", "revision": 3, "resource": "ignored"},
+		FiredAt:  time.Now(),
+	}
+	require.NoError(t, o.Send(alert))
+
+	require.Equal(t, "https://jira.example.com/browse/OPS-12", captured.Details["change_ticket"])
+	require.Equal(t, "3", captured.Details["revision"])
+	require.Equal(t, "checkout-v2", captured.Details["resource"], "alert details do not override the built-in fields")
+}
+
 func TestOpsGenie_AliasEscaping(t *testing.T) {
 	// Rejects obviously hostile content.
 	o := &OpsGenieNotifier{}
@@ -50,6 +50,9 @@ type webhookPayload struct {
 	Timestamp time.Time `json:"timestamp"`
 	RuleID    string    `json:"ruleId,omitempty"`
 	ID        string    `json:"id,omitempty"`
+	// Details carries the alert's structured context, e.g. the change
+	// ticket of a WorkloadDeployment rollout.
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 // NewWebhookNotifier validates the URL and returns a ready-to-use notifier.
@@ -199,6 +202,7 @@ func (w *WebhookNotifier) Send(alert Alert) error {
 		Timestamp: alert.FiredAt,
 		RuleID:    alert.RuleID,
 		ID:        alert.ID,
+		Details:   alert.Details,
 	}
 
 	body, err := json.Marshal(payload)
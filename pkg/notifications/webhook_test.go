@@ -31,6 +31,7 @@ func TestWebhookNotifier_Send(t *testing.T) {
 		Status:   "firing",
 		Cluster:  "prod",
 		Message:  "Disk > 80%",
+		Details:  map[string]interface{}{"change_ticket": "https://jira.example.com/browse/OPS-12"},
 		FiredAt:  time.Now(),
 	}
 
@@ -41,6 +42,7 @@ func TestWebhookNotifier_Send(t *testing.T) {
 	require.Equal(t, string(alert.Severity), captured.Severity)
 	require.Equal(t, alert.Status, captured.Status)
 	require.Equal(t, alert.Message, captured.Message)
+	require.Equal(t, alert.Details, captured.Details)
 }
 
 func TestWebhookNotifier_NewError(t *testing.T) {
@@ -0,0 +1,303 @@
+// Package operations runs work that outlives a request timeout in the
+// background. Starting an operation returns its ID at once; callers poll
+// the operation for progress and its result, and may cancel it.
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+// State is the lifecycle state of an operation.
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Done reports whether s is a final state.
+func (s State) Done() bool {
+	return s != StateRunning
+}
+
+// DefaultRetention is how long a finished operation can still be polled.
+const DefaultRetention = time.Hour
+
+// maxFinishedOperations bounds the finished operations kept for polling,
+// oldest dropped first, so a burst of operations cannot grow memory until
+// they age out.
+const maxFinishedOperations = 500
+
+var (
+	// ErrNotFound is returned for an unknown or expired operation ID.
+	ErrNotFound = errors.New("operation not found")
+	// ErrFinished is returned when cancelling an operation that is done.
+	ErrFinished = errors.New("operation already finished")
+)
+
+// Progress is how far an operation has come. Total is zero when the
+// amount of work is not known up front.
+type Progress struct {
+	Completed int    `json:"completed"`
+	Total     int    `json:"total,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Operation is a snapshot of a background operation.
+type Operation struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Owner string `json:"owner,omitempty"`
+	State State  `json:"state"`
+	// Progress is the last progress the operation reported.
+	Progress   Progress   `json:"progress"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Reporter records an operation's progress.
+type Reporter interface {
+	Report(Progress)
+}
+
+// Func is the work of an operation. It should return promptly once ctx is
+// cancelled; its result is kept only when it succeeds.
+type Func func(ctx context.Context, progress Reporter) (any, error)
+
+// Spec describes an operation to start.
+type Spec struct {
+	// Kind names what the operation does, e.g. "benchmarks.refresh".
+	Kind string
+	// Owner is the user who started it; only they can see it.
+	Owner string
+	// Exclusive makes Start return the running operation of the same kind,
+	// if any, instead of starting another.
+	Exclusive bool
+	// Timeout bounds the operation; zero leaves it unbounded.
+	Timeout time.Duration
+}
+
+// Manager runs and tracks operations. The zero value is not usable; use
+// NewManager.
+type Manager struct {
+	mu        sync.Mutex
+	ops       map[string]*entry
+	retention time.Duration
+	now       func() time.Time
+	// onFinish is called with every operation that reaches a final state.
+	onFinish func(Operation)
+	ctx      context.Context
+	stop     context.CancelFunc
+}
+
+type entry struct {
+	op     Operation
+	cancel context.CancelFunc
+}
+
+// NewManager creates a manager that keeps finished operations for
+// retention, DefaultRetention if zero, and calls onFinish, if not nil,
+// when an operation finishes.
+func NewManager(retention time.Duration, onFinish func(Operation)) *Manager {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &Manager{
+		ops:       make(map[string]*entry),
+		retention: retention,
+		now:       time.Now,
+		onFinish:  onFinish,
+		ctx:       ctx,
+		stop:      stop,
+	}
+}
+
+// Start runs fn in the background and returns the new operation.
+func (m *Manager) Start(spec Spec, fn Func) Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	if spec.Exclusive {
+		for _, e := range m.ops {
+			if e.op.Kind == spec.Kind && !e.op.State.Done() {
+				return e.op
+			}
+		}
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if spec.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(m.ctx, spec.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(m.ctx)
+	}
+	e := &entry{
+		op: Operation{
+			ID:        uuid.New().String(),
+			Kind:      spec.Kind,
+			Owner:     spec.Owner,
+			State:     StateRunning,
+			CreatedAt: m.now(),
+		},
+		cancel: cancel,
+	}
+	m.ops[e.op.ID] = e
+	id := e.op.ID
+	safego.GoWith("operations/"+spec.Kind, func() { m.run(ctx, id, fn) })
+	return e.op
+}
+
+// run executes fn and records its outcome.
+func (m *Manager) run(ctx context.Context, id string, fn Func) {
+	var (
+		result any
+		err    error
+	)
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("[operations] operation panicked", "id", id, "recover", r, "stack", string(debug.Stack()))
+				err = fmt.Errorf("operation panicked")
+			}
+		}()
+		result, err = fn(ctx, reporter{m: m, id: id})
+	}()
+	m.finish(ctx, id, result, err)
+}
+
+// finish records the outcome of the operation with id. An operation whose
+// context was cancelled is cancelled whatever fn returned.
+func (m *Manager) finish(ctx context.Context, id string, result any, err error) {
+	m.mu.Lock()
+	e, ok := m.ops[id]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	cancelled := errors.Is(ctx.Err(), context.Canceled)
+	e.cancel()
+	now := m.now()
+	e.op.FinishedAt = &now
+	switch {
+	case cancelled:
+		e.op.State = StateCancelled
+		e.op.Error = "cancelled"
+	case err != nil:
+		e.op.State = StateFailed
+		e.op.Error = err.Error()
+	default:
+		e.op.State = StateSucceeded
+		e.op.Result = result
+	}
+	op := e.op
+	m.mu.Unlock()
+
+	slog.Info("[operations] operation finished", "id", op.ID, "kind", op.Kind, "state", op.State)
+	if m.onFinish != nil {
+		m.onFinish(op)
+	}
+}
+
+// Get returns the operation with id.
+func (m *Manager) Get(id string) (Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	e, ok := m.ops[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	return e.op, nil
+}
+
+// List returns the operations of owner, newest first.
+func (m *Manager) List(owner string) []Operation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	ops := make([]Operation, 0, len(m.ops))
+	for _, e := range m.ops {
+		if e.op.Owner == owner {
+			ops = append(ops, e.op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].CreatedAt.After(ops[j].CreatedAt) })
+	return ops
+}
+
+// Cancel asks the operation with id to stop. It finishes as cancelled once
+// its Func returns.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.ops[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if e.op.State.Done() {
+		return ErrFinished
+	}
+	e.cancel()
+	return nil
+}
+
+// Close cancels every running operation.
+func (m *Manager) Close() {
+	m.stop()
+}
+
+// pruneLocked drops finished operations older than the retention and, past
+// maxFinishedOperations, the oldest finished ones.
+func (m *Manager) pruneLocked() {
+	cutoff := m.now().Add(-m.retention)
+	var finished []*entry
+	for id, e := range m.ops {
+		if e.op.FinishedAt == nil {
+			continue
+		}
+		if e.op.FinishedAt.Before(cutoff) {
+			delete(m.ops, id)
+			continue
+		}
+		finished = append(finished, e)
+	}
+	if len(finished) <= maxFinishedOperations {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].op.FinishedAt.Before(*finished[j].op.FinishedAt) })
+	for _, e := range finished[:len(finished)-maxFinishedOperations] {
+		delete(m.ops, e.op.ID)
+	}
+}
+
+// reporter records progress on one operation.
+type reporter struct {
+	m  *Manager
+	id string
+}
+
+func (r reporter) Report(p Progress) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if e, ok := r.m.ops[r.id]; ok && !e.op.State.Done() {
+		e.op.Progress = p
+	}
+}
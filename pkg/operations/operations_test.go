@@ -0,0 +1,143 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitDone polls until the operation with id finishes.
+func waitDone(t *testing.T, m *Manager, id string) Operation {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		op, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if op.State.Done() {
+			return op
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("operation %s did not finish", id)
+	return Operation{}
+}
+
+func TestManager_Succeeds(t *testing.T) {
+	finished := make(chan Operation, 1)
+	m := NewManager(0, func(op Operation) { finished <- op })
+	defer m.Close()
+
+	release := make(chan struct{})
+	op := m.Start(Spec{Kind: "test", Owner: "alice"}, func(ctx context.Context, p Reporter) (any, error) {
+		p.Report(Progress{Completed: 1, Total: 2, Message: "halfway"})
+		<-release
+		return "result", nil
+	})
+	if op.State != StateRunning || op.ID == "" {
+		t.Fatalf("got %+v, want a running operation", op)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := m.Get(op.ID)
+		if got.Progress.Message == "halfway" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("progress not reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	done := waitDone(t, m, op.ID)
+	if done.State != StateSucceeded || done.Result != "result" || done.FinishedAt == nil {
+		t.Errorf("got %+v", done)
+	}
+	if got := <-finished; got.ID != op.ID {
+		t.Errorf("onFinish got %s, want %s", got.ID, op.ID)
+	}
+}
+
+func TestManager_Fails(t *testing.T) {
+	m := NewManager(0, nil)
+	defer m.Close()
+	op := m.Start(Spec{Kind: "test"}, func(context.Context, Reporter) (any, error) {
+		return "ignored", errors.New("boom")
+	})
+	done := waitDone(t, m, op.ID)
+	if done.State != StateFailed || done.Error != "boom" || done.Result != nil {
+		t.Errorf("got %+v", done)
+	}
+
+	op = m.Start(Spec{Kind: "test"}, func(context.Context, Reporter) (any, error) {
+		panic("bad")
+	})
+	if done := waitDone(t, m, op.ID); done.State != StateFailed {
+		t.Errorf("panic: got %+v", done)
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	m := NewManager(0, nil)
+	defer m.Close()
+	op := m.Start(Spec{Kind: "test"}, func(ctx context.Context, _ Reporter) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err := m.Cancel(op.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if done := waitDone(t, m, op.ID); done.State != StateCancelled {
+		t.Errorf("got %+v", done)
+	}
+	if err := m.Cancel(op.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Cancel = %v, want ErrFinished", err)
+	}
+	if err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_ExclusiveAndList(t *testing.T) {
+	m := NewManager(0, nil)
+	defer m.Close()
+	block := func(ctx context.Context, _ Reporter) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	first := m.Start(Spec{Kind: "refresh", Owner: "alice", Exclusive: true}, block)
+	second := m.Start(Spec{Kind: "refresh", Owner: "bob", Exclusive: true}, block)
+	if second.ID != first.ID {
+		t.Errorf("exclusive start returned %s, want running %s", second.ID, first.ID)
+	}
+	other := m.Start(Spec{Kind: "other", Owner: "alice"}, block)
+
+	ops := m.List("alice")
+	if len(ops) != 2 {
+		t.Fatalf("got %d operations for alice, want 2", len(ops))
+	}
+	if len(m.List("bob")) != 0 {
+		t.Error("bob should not see alice's operations")
+	}
+	m.Close()
+	waitDone(t, m, first.ID)
+	waitDone(t, m, other.ID)
+}
+
+func TestManager_PrunesExpired(t *testing.T) {
+	m := NewManager(time.Minute, nil)
+	defer m.Close()
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	op := m.Start(Spec{Kind: "test"}, func(context.Context, Reporter) (any, error) { return nil, nil })
+	waitDone(t, m, op.ID)
+
+	now = now.Add(2 * time.Minute)
+	if _, err := m.Get(op.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after retention = %v, want ErrNotFound", err)
+	}
+}
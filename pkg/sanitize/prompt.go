@@ -1,6 +1,7 @@
-// Package sanitize provides string sanitization utilities for prompt
-// injection prevention. These functions neutralize user-controlled input
-// before it is interpolated into LLM prompts.
+// Package sanitize provides string sanitization utilities: prompt
+// injection prevention, which neutralizes user-controlled input before it is
+// interpolated into LLM prompts, and secret redaction, which scrubs
+// credentials from text leaving the process (logs, errors, audit entries).
 //
 // Extracted from pkg/agent to break the pkg/api → pkg/agent coupling.
 // Both packages can depend on this leaf package without creating a cycle.
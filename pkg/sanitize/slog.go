@@ -0,0 +1,90 @@
+package sanitize
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// sensitiveLogKeys are attribute keys whose values are always redacted,
+// whatever they look like.
+var sensitiveLogKeys = map[string]bool{
+	"token":         true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"idtoken":       true,
+	"password":      true,
+	"secret":        true,
+	"clientsecret":  true,
+	"apikey":        true,
+	"authorization": true,
+	"cookie":        true,
+	"kubeconfig":    true,
+	"privatekey":    true,
+}
+
+// NewRedactingHandler wraps next so every log record passes through Secrets:
+// the message, string and error attributes, and values of attributes whose
+// key names a credential (token, password, apiKey, ...).
+func NewRedactingHandler(next slog.Handler) slog.Handler {
+	return &redactingHandler{next: next}
+}
+
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Secrets(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if sensitiveLogKey(a.Key) && a.Value.Kind() != slog.KindGroup {
+		return slog.String(a.Key, Redacted)
+	}
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Secrets(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, g := range group {
+			redacted[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, Secrets(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// sensitiveLogKey matches keys case- and separator-insensitively, so
+// "api_key", "apiKey" and "API-Key" are all treated alike.
+func sensitiveLogKey(key string) bool {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(key))
+	return sensitiveLogKeys[k]
+}
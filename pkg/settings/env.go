@@ -0,0 +1,531 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	agentconfig "github.com/kubestellar/console/pkg/agent/config"
+)
+
+// Configuration for containerized deploys.
+//
+// Every setting that can be edited in the UI can also be supplied by a
+// command-line flag or an environment variable, so a container can be
+// configured without mounting a settings file. Precedence, highest first:
+//
+//	-setting key=value  >  environment variable  >  settings file  >  default
+//
+// Overrides are applied when settings are read and are never written back to
+// disk: removing the variable restores the value from the file. The full list
+// of variables is returned by Bindings and documented in
+// docs/settings-environment.md.
+//
+// The GitHub token is the one exception: FEEDBACK_GITHUB_TOKEN (or
+// GITHUB_TOKEN) is only used when no token was saved in the UI, as before.
+
+// Source identifies where an effective setting value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Sections name the configuration files bindings apply to.
+const (
+	// SectionSettings is the user settings file (~/.kc/settings.json).
+	SectionSettings = "settings"
+	// SectionPersistence is the console CR persistence config (persistence.json).
+	SectionPersistence = "persistence"
+)
+
+// envPrefix is prepended to the derived environment variable names.
+const envPrefix = "KC_"
+
+// maskedValue replaces secret values in the effective settings view.
+const maskedValue = "********"
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindBool
+	kindInt
+	kindFloat
+)
+
+// Binding maps one setting to the flag key and environment variable that
+// override it.
+type Binding struct {
+	Section string `json:"section"`
+	// Key is the JSON path of the setting within its section, for example
+	// "predictions.interval". It is also the -setting flag key, qualified
+	// by the section ("persistence.namespace") outside SectionSettings.
+	Key    string `json:"key"`
+	Env    string `json:"env"`
+	Secret bool   `json:"secret,omitempty"`
+	kind   valueKind
+}
+
+// FlagKey returns the key accepted by the -setting flag.
+func (b Binding) FlagKey() string {
+	if b.Section == SectionSettings {
+		return b.Key
+	}
+	return b.Section + "." + b.Key
+}
+
+// overrideProviders are the AI providers whose API key and model can be set
+// from the environment. The variable names are the ones kc-agent already
+// reads, so one variable configures both processes.
+var overrideProviders = []string{"claude", "openai", "gemini", "openrouter", "groq", "open-webui"}
+
+var bindings = buildBindings()
+
+func buildBindings() []Binding {
+	out := []Binding{
+		settingBinding("aiMode", kindString),
+		settingBinding("theme", kindString),
+		settingBinding("autoUpdateEnabled", kindBool),
+		settingBinding("autoUpdateChannel", kindString),
+		settingBinding("predictions.aiEnabled", kindBool),
+		settingBinding("predictions.interval", kindInt),
+		settingBinding("predictions.minConfidence", kindInt),
+		settingBinding("predictions.maxPredictions", kindInt),
+		settingBinding("predictions.consensusMode", kindBool),
+		settingBinding("predictions.thresholds.highRestartCount", kindInt),
+		settingBinding("predictions.thresholds.cpuPressure", kindInt),
+		settingBinding("predictions.thresholds.memoryPressure", kindInt),
+		settingBinding("predictions.thresholds.gpuMemoryPressure", kindInt),
+		settingBinding("tokenUsage.limit", kindInt),
+		settingBinding("tokenUsage.warningThreshold", kindFloat),
+		settingBinding("tokenUsage.criticalThreshold", kindFloat),
+		settingBinding("tokenUsage.stopThreshold", kindFloat),
+		settingBinding("accessibility.colorBlindMode", kindBool),
+		settingBinding("accessibility.reduceMotion", kindBool),
+		settingBinding("accessibility.highContrast", kindBool),
+		settingBinding("profile.email", kindString),
+		settingBinding("profile.slackId", kindString),
+		settingBinding("widget.selectedWidget", kindString),
+		settingBinding("notifications.slackWebhookUrl", kindString).secret(),
+		settingBinding("notifications.slackChannel", kindString),
+		settingBinding("notifications.emailSMTPHost", kindString),
+		settingBinding("notifications.emailSMTPPort", kindInt),
+		settingBinding("notifications.emailFrom", kindString),
+		settingBinding("notifications.emailTo", kindString),
+		settingBinding("notifications.emailUsername", kindString),
+		settingBinding("notifications.emailPassword", kindString).secret(),
+	}
+	for _, provider := range overrideProviders {
+		if env := agentconfig.GetEnvKeyForProvider(provider); env != "" {
+			out = append(out, Binding{Section: SectionSettings, Key: "apiKeys." + provider + ".apiKey", Env: env, Secret: true})
+		}
+		if env := agentconfig.GetModelEnvKeyForProvider(provider); env != "" {
+			out = append(out, Binding{Section: SectionSettings, Key: "apiKeys." + provider + ".model", Env: env})
+		}
+	}
+	for _, key := range []string{"primaryCluster", "secondaryCluster", "namespace", "syncMode"} {
+		out = append(out, Binding{Section: SectionPersistence, Key: key, Env: deriveEnvName(SectionPersistence + "." + key)})
+	}
+	out = append(out, Binding{Section: SectionPersistence, Key: "enabled", Env: deriveEnvName("persistence.enabled"), kind: kindBool})
+	return out
+}
+
+func settingBinding(key string, kind valueKind) Binding {
+	return Binding{Section: SectionSettings, Key: key, Env: deriveEnvName(key), kind: kind}
+}
+
+func (b Binding) secret() Binding {
+	b.Secret = true
+	return b
+}
+
+// deriveEnvName turns a dotted camelCase path into KC_UPPER_SNAKE_CASE, e.g.
+// "predictions.minConfidence" becomes KC_PREDICTIONS_MIN_CONFIDENCE.
+func deriveEnvName(key string) string {
+	var sb strings.Builder
+	sb.WriteString(envPrefix)
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '.':
+			sb.WriteByte('_')
+		case unicode.IsUpper(r):
+			// Start a new word at a lower→upper boundary, and at the last
+			// capital of an acronym ("emailSMTPHost" → EMAIL_SMTP_HOST).
+			prevLower := i > 0 && unicode.IsLower(runes[i-1])
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || acronymEnd {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return sb.String()
+}
+
+// Bindings returns every flag/environment binding, ordered by section and key.
+func Bindings() []Binding {
+	out := append([]Binding(nil), bindings...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Section != out[j].Section {
+			return out[i].Section > out[j].Section // settings before persistence
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func bindingByFlagKey(key string) (Binding, bool) {
+	for _, b := range bindings {
+		if b.FlagKey() == key {
+			return b, true
+		}
+	}
+	return Binding{}, false
+}
+
+var (
+	flagMu        sync.RWMutex
+	flagOverrides = map[string]string{}
+)
+
+// settingFlag implements flag.Value for the repeatable -setting flag.
+type settingFlag struct{}
+
+func (settingFlag) String() string { return "" }
+
+func (settingFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	b, known := bindingByFlagKey(key)
+	if !known {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if _, err := parseValue(b.kind, value); err != nil {
+		return fmt.Errorf("setting %s: %w", key, err)
+	}
+	flagMu.Lock()
+	defer flagMu.Unlock()
+	flagOverrides[b.FlagKey()] = value
+	return nil
+}
+
+// RegisterFlags adds the repeatable -setting key=value flag to fs.
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(settingFlag{}, "setting",
+		"Override a setting, e.g. -setting predictions.interval=30 (repeatable; takes precedence over environment variables)")
+}
+
+// override is an active flag or environment value for a binding.
+type override struct {
+	binding Binding
+	raw     string
+	source  Source
+}
+
+// activeOverrides returns the overrides currently in effect for section.
+func activeOverrides(section string) []override {
+	flagMu.RLock()
+	defer flagMu.RUnlock()
+	var out []override
+	for _, b := range bindings {
+		if b.Section != section {
+			continue
+		}
+		if v, ok := flagOverrides[b.FlagKey()]; ok {
+			out = append(out, override{binding: b, raw: v, source: SourceFlag})
+		} else if v := os.Getenv(b.Env); v != "" {
+			out = append(out, override{binding: b, raw: v, source: SourceEnv})
+		}
+	}
+	return out
+}
+
+// ApplyOverrides writes the active flag and environment overrides for
+// section into v, which must be a pointer to the JSON-tagged struct stored
+// in that section's file. Values that fail to parse are logged and skipped.
+func ApplyOverrides(section string, v interface{}) error {
+	overrides := activeOverrides(section)
+	if len(overrides) == 0 {
+		return nil
+	}
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	for _, o := range overrides {
+		value, err := parseValue(o.binding.kind, o.raw)
+		if err != nil {
+			slog.Warn("[settings] ignoring invalid override", "env", o.binding.Env, "key", o.binding.Key, "error", err)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encode override %s: %w", o.binding.Key, err)
+		}
+		if doc, err = setPath(doc, splitKey(o.binding.Key), encoded); err != nil {
+			return err
+		}
+	}
+	return decodeInto(doc, v)
+}
+
+// restoreOverridden copies the current file values of every overridden key
+// from file into v, so saving settings read with overrides applied does not
+// persist the override values.
+func restoreOverridden(section string, v, file interface{}) error {
+	overrides := activeOverrides(section)
+	if len(overrides) == 0 {
+		return nil
+	}
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	fileDoc, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("encode settings: %w", err)
+	}
+	for _, o := range overrides {
+		path := splitKey(o.binding.Key)
+		if value, ok := getPath(fileDoc, path); ok {
+			doc, err = setPath(doc, path, value)
+		} else {
+			doc, err = deletePath(doc, path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return decodeInto(doc, v)
+}
+
+// EffectiveValue is one resolved setting and where its value came from.
+type EffectiveValue struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source Source      `json:"source"`
+	Env    string      `json:"env,omitempty"`
+	Secret bool        `json:"secret,omitempty"`
+}
+
+// Describe reports the effective value and source of every binding in
+// section. current must have overrides applied; values equal to the
+// corresponding value in defaults are reported as SourceDefault. Secret
+// values are masked.
+func Describe(section string, current, defaults interface{}) ([]EffectiveValue, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("encode settings: %w", err)
+	}
+	defaultDoc, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, fmt.Errorf("encode settings: %w", err)
+	}
+	sources := make(map[string]Source)
+	for _, o := range activeOverrides(section) {
+		sources[o.binding.Key] = o.source
+	}
+
+	var out []EffectiveValue
+	for _, b := range Bindings() {
+		if b.Section != section {
+			continue
+		}
+		raw, _ := getPath(doc, splitKey(b.Key))
+		value := decodeValue(raw)
+		source, overridden := sources[b.Key]
+		if !overridden {
+			source = SourceFile
+			if def, _ := getPath(defaultDoc, splitKey(b.Key)); sameValue(value, decodeValue(def)) {
+				source = SourceDefault
+			}
+		}
+		if b.Secret {
+			value = maskSecret(value)
+		}
+		out = append(out, EffectiveValue{Key: b.FlagKey(), Value: value, Source: source, Env: b.Env, Secret: b.Secret})
+	}
+	return out, nil
+}
+
+func maskSecret(v interface{}) interface{} {
+	if s, ok := v.(string); ok && s != "" {
+		return maskedValue
+	}
+	return ""
+}
+
+func parseValue(kind valueKind, raw string) (interface{}, error) {
+	switch kind {
+	case kindBool:
+		return strconv.ParseBool(raw)
+	case kindInt:
+		return strconv.Atoi(raw)
+	case kindFloat:
+		return strconv.ParseFloat(raw, 64)
+	default:
+		return raw, nil
+	}
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, ".")
+}
+
+// The path helpers below decode only the objects along the path and keep
+// every other value as raw JSON, so untouched fields such as customThemes
+// round-trip byte for byte.
+
+func decodeObject(raw json.RawMessage) (map[string]json.RawMessage, error) {
+	obj := map[string]json.RawMessage{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return obj, nil
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("decode settings: %w", err)
+	}
+	return obj, nil
+}
+
+func getPath(doc json.RawMessage, path []string) (json.RawMessage, bool) {
+	obj, err := decodeObject(doc)
+	if err != nil {
+		return nil, false
+	}
+	v, ok := obj[path[0]]
+	if !ok || len(path) == 1 {
+		return v, ok
+	}
+	return getPath(v, path[1:])
+}
+
+func setPath(doc json.RawMessage, path []string, value json.RawMessage) (json.RawMessage, error) {
+	obj, err := decodeObject(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 1 {
+		obj[path[0]] = value
+	} else if obj[path[0]], err = setPath(obj[path[0]], path[1:], value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+func deletePath(doc json.RawMessage, path []string) (json.RawMessage, error) {
+	obj, err := decodeObject(doc)
+	if err != nil {
+		return nil, err
+	}
+	child, ok := obj[path[0]]
+	if !ok {
+		return doc, nil
+	}
+	if len(path) == 1 {
+		delete(obj, path[0])
+	} else {
+		if child, err = deletePath(child, path[1:]); err != nil {
+			return nil, err
+		}
+		obj[path[0]] = child
+		// Drop objects emptied by the delete, such as an API key entry
+		// that existed only in the environment.
+		if string(child) == "{}" {
+			delete(obj, path[0])
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// decodeInto replaces *v with doc. v is reset first so map entries removed
+// from doc are not merged back in from the previous value.
+func decodeInto(doc json.RawMessage, v interface{}) error {
+	target := reflect.ValueOf(v).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if err := json.Unmarshal(doc, v); err != nil {
+		return fmt.Errorf("apply settings overrides: %w", err)
+	}
+	return nil
+}
+
+func decodeValue(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// sameValue compares decoded JSON values, treating a missing value and its
+// zero value alike since omitempty fields disappear when unset.
+func sameValue(a, b interface{}) bool {
+	if isZeroValue(a) && isZeroValue(b) {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isZeroValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case json.Number:
+		f, err := t.Float64()
+		return err == nil && f == 0
+	}
+	return false
+}
+
+// Effective returns every user setting with its resolved value and source.
+// Secrets are masked.
+func (sm *SettingsManager) Effective() ([]EffectiveValue, error) {
+	all, err := sm.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	values, err := Describe(SectionSettings, all, DefaultAllSettings())
+	if err != nil {
+		return nil, err
+	}
+	token := EffectiveValue{
+		Key:    "feedbackGithubToken",
+		Value:  maskSecret(all.FeedbackGitHubToken),
+		Source: SourceDefault,
+		Env:    "FEEDBACK_GITHUB_TOKEN",
+		Secret: true,
+	}
+	switch all.FeedbackGitHubTokenSource {
+	case GitHubTokenSourceSettings:
+		token.Source = SourceFile
+	case GitHubTokenSourceEnv:
+		token.Source = SourceEnv
+	}
+	return append(values, token), nil
+}
@@ -0,0 +1,221 @@
+package settings
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+)
+
+// TestMain clears every override variable so developer shells that export,
+// say, ANTHROPIC_API_KEY do not leak into the settings under test.
+func TestMain(m *testing.M) {
+	for _, b := range bindings {
+		os.Unsetenv(b.Env)
+	}
+	os.Exit(m.Run())
+}
+
+func resetFlagOverrides(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		flagMu.Lock()
+		flagOverrides = map[string]string{}
+		flagMu.Unlock()
+	})
+}
+
+func TestDeriveEnvName(t *testing.T) {
+	for key, want := range map[string]string{
+		"aiMode":                             "KC_AI_MODE",
+		"predictions.minConfidence":          "KC_PREDICTIONS_MIN_CONFIDENCE",
+		"predictions.thresholds.cpuPressure": "KC_PREDICTIONS_THRESHOLDS_CPU_PRESSURE",
+		"notifications.emailSMTPHost":        "KC_NOTIFICATIONS_EMAIL_SMTP_HOST",
+		"notifications.slackWebhookUrl":      "KC_NOTIFICATIONS_SLACK_WEBHOOK_URL",
+		"persistence.primaryCluster":         "KC_PERSISTENCE_PRIMARY_CLUSTER",
+	} {
+		if got := deriveEnvName(key); got != want {
+			t.Errorf("deriveEnvName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestBindings_UniqueNames(t *testing.T) {
+	envs := map[string]bool{}
+	keys := map[string]bool{}
+	for _, b := range Bindings() {
+		if envs[b.Env] || keys[b.FlagKey()] {
+			t.Errorf("duplicate binding %s (%s)", b.FlagKey(), b.Env)
+		}
+		envs[b.Env] = true
+		keys[b.FlagKey()] = true
+	}
+	if !envs["ANTHROPIC_API_KEY"] || !envs["KC_PERSISTENCE_ENABLED"] {
+		t.Error("expected provider and persistence bindings")
+	}
+}
+
+func TestGetAll_EnvOverridesFile(t *testing.T) {
+	sm := newTestManager(t)
+	if err := sm.SaveAll(&AllSettings{
+		AIMode:      "low",
+		Theme:       "nord",
+		Predictions: PredictionSettings{Interval: 60, MaxPredictions: 10},
+		APIKeys:     map[string]APIKeyEntry{"claude": {APIKey: "sk-file", Model: "file-model"}},
+	}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+
+	t.Setenv("KC_AI_MODE", "high")
+	t.Setenv("KC_PREDICTIONS_INTERVAL", "15")
+	t.Setenv("KC_TOKEN_USAGE_WARNING_THRESHOLD", "0.5")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-env")
+	t.Setenv("OPENAI_API_KEY", "sk-openai-env")
+	t.Setenv("KC_NOTIFICATIONS_EMAIL_SMTP_PORT", "587")
+	t.Setenv("KC_PREDICTIONS_MAX_PREDICTIONS", "lots")
+
+	all, err := sm.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if all.AIMode != "high" || all.Theme != "nord" {
+		t.Errorf("aiMode=%q theme=%q, want high from env and nord from file", all.AIMode, all.Theme)
+	}
+	if all.Predictions.Interval != 15 || all.TokenUsage.WarningThreshold != 0.5 {
+		t.Errorf("numeric overrides not applied: interval=%d warning=%v", all.Predictions.Interval, all.TokenUsage.WarningThreshold)
+	}
+	if all.Predictions.MaxPredictions != 10 {
+		t.Errorf("invalid override should be ignored, maxPredictions=%d", all.Predictions.MaxPredictions)
+	}
+	if got := all.APIKeys["claude"]; got.APIKey != "sk-env" || got.Model != "file-model" {
+		t.Errorf("claude entry = %+v, want env key with file model", got)
+	}
+	if all.APIKeys["openai"].APIKey != "sk-openai-env" {
+		t.Errorf("provider only set in env should be added, got %+v", all.APIKeys)
+	}
+	if all.Notifications.EmailSMTPPort != 587 {
+		t.Errorf("smtp port = %d, want 587", all.Notifications.EmailSMTPPort)
+	}
+}
+
+func TestSaveAll_DoesNotPersistOverrides(t *testing.T) {
+	sm := newTestManager(t)
+	if err := sm.SaveAll(&AllSettings{AIMode: "low", APIKeys: map[string]APIKeyEntry{}}); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+
+	t.Setenv("KC_AI_MODE", "high")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-env")
+
+	// The UI reads effective settings, changes the theme and saves them back.
+	all, err := sm.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	all.Theme = "dracula"
+	if err := sm.SaveAll(all); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+
+	sm.mu.RLock()
+	file := sm.fileSettingsLocked()
+	sm.mu.RUnlock()
+	if file.AIMode != "low" || file.Theme != "dracula" {
+		t.Errorf("file aiMode=%q theme=%q, want low and dracula", file.AIMode, file.Theme)
+	}
+	if _, ok := file.APIKeys["claude"]; ok {
+		t.Errorf("env API key must not be written to the settings file: %+v", file.APIKeys)
+	}
+}
+
+func TestSettingFlag(t *testing.T) {
+	resetFlagOverrides(t)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(nopWriter{})
+	RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"-setting", "aiMode=high", "-setting", "persistence.namespace=flag-ns"}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, bad := range [][]string{
+		{"-setting", "aiMode"},
+		{"-setting", "nope=1"},
+		{"-setting", "predictions.interval=soon"},
+	} {
+		if err := fs.Parse(bad); err == nil {
+			t.Errorf("expected %v to be rejected", bad)
+		}
+	}
+
+	// Flags take precedence over the environment.
+	t.Setenv("KC_AI_MODE", "low")
+	all := DefaultAllSettings()
+	if err := ApplyOverrides(SectionSettings, all); err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	if all.AIMode != "high" {
+		t.Errorf("aiMode = %q, want flag value high", all.AIMode)
+	}
+
+	var persistence struct {
+		Namespace string `json:"namespace"`
+	}
+	if err := ApplyOverrides(SectionPersistence, &persistence); err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	if persistence.Namespace != "flag-ns" {
+		t.Errorf("namespace = %q, want flag-ns", persistence.Namespace)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	resetFlagOverrides(t)
+	if err := (settingFlag{}).Set("theme=flag-theme"); err != nil {
+		t.Fatalf("set flag: %v", err)
+	}
+	t.Setenv("KC_AI_MODE", "high")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-env")
+
+	all := DefaultAllSettings()
+	all.Predictions.Interval = 30 // as if read from the file
+	if err := ApplyOverrides(SectionSettings, all); err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	values, err := Describe(SectionSettings, all, DefaultAllSettings())
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	byKey := map[string]EffectiveValue{}
+	for _, v := range values {
+		byKey[v.Key] = v
+	}
+
+	want := map[string]struct {
+		value  interface{}
+		source Source
+	}{
+		"theme":                 {"flag-theme", SourceFlag},
+		"aiMode":                {"high", SourceEnv},
+		"predictions.interval":  {json.Number("30"), SourceFile},
+		"predictions.aiEnabled": {true, SourceDefault},
+		"apiKeys.claude.apiKey": {maskedValue, SourceEnv},
+		"apiKeys.openai.apiKey": {"", SourceDefault},
+	}
+	for key, w := range want {
+		got, ok := byKey[key]
+		if !ok {
+			t.Errorf("%s missing from effective settings", key)
+			continue
+		}
+		if got.Value != w.value || got.Source != w.source {
+			t.Errorf("%s = %v (%s), want %v (%s)", key, got.Value, got.Source, w.value, w.source)
+		}
+	}
+	if !byKey["apiKeys.claude.apiKey"].Secret || byKey["apiKeys.claude.apiKey"].Env != "ANTHROPIC_API_KEY" {
+		t.Errorf("unexpected claude binding: %+v", byKey["apiKeys.claude.apiKey"])
+	}
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
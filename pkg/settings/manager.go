@@ -2,8 +2,10 @@ package settings
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"sync"
@@ -103,8 +105,19 @@ func (sm *SettingsManager) Load() error {
 		return fmt.Errorf("failed to read settings: %w", err)
 	}
 
-	var sf SettingsFile
-	if err := json.Unmarshal(data, &sf); err != nil {
+	parsed, fromVersion, applied, err := decodeSettingsFile(data)
+	if errors.Is(err, ErrNewerSchema) {
+		// A newer build wrote this file. Load what this build understands,
+		// but keep a copy first since saving drops fields it doesn't know.
+		slog.Warn("[settings] settings file is newer than this build; loading known fields only",
+			"version", fromVersion, "supported", CurrentSchemaVersion, "path", sm.settingsPath)
+		var sf SettingsFile
+		if err = json.Unmarshal(data, &sf); err == nil {
+			parsed = &sf
+			applied = []string{fmt.Sprintf("downgrade from version %d", fromVersion)}
+		}
+	}
+	if err != nil {
 		backupPath := sm.settingsPath + ".corrupt." + time.Now().UTC().Format("20060102T150405Z")
 		if renameErr := os.Rename(sm.settingsPath, backupPath); renameErr != nil {
 			sm.loadErr = fmt.Errorf("failed to back up corrupt settings file: %w", renameErr)
@@ -117,6 +130,8 @@ func (sm *SettingsManager) Load() error {
 		return nil
 	}
 
+	sf := *parsed
+	sf.Version = CurrentSchemaVersion
 	sm.loadErr = nil
 
 	// Detect missing boolean fields in older settings files (#7572).
@@ -190,10 +205,55 @@ func (sm *SettingsManager) Load() error {
 		sf.Settings.TokenUsage.StopThreshold = defaults.Settings.TokenUsage.StopThreshold
 	}
 
+	// Out-of-range values would otherwise break consumers that assume the
+	// UI's constraints; reset them to defaults in memory.
+	for _, issue := range sanitize(&sf.Settings) {
+		slog.Warn("[settings] invalid setting reset to default", "field", issue.Field, "problem", issue.Message)
+	}
+
 	sm.settings = &sf
+
+	if len(applied) > 0 {
+		backupPath, backupErr := sm.backupPreMigrationLocked(data, fromVersion)
+		if backupErr != nil {
+			sm.loadErr = fmt.Errorf("failed to back up settings before migration: %w", backupErr)
+			slog.Error("[settings] failed to back up settings before migration", "error", backupErr, "path", sm.settingsPath)
+			return sm.loadErr
+		}
+		slog.Info("[settings] migrated settings file", "from", fromVersion, "to", CurrentSchemaVersion,
+			"migrations", applied, "backup", backupPath)
+		if saveErr := sm.saveLocked(); saveErr != nil {
+			slog.Error("[settings] failed to persist migrated settings", "error", saveErr)
+		}
+	}
 	return nil
 }
 
+// backupPreMigrationLocked writes the original file contents next to the
+// settings file as settings.json.v<version>.bak. An existing backup for the
+// same version is kept, so the oldest copy survives repeated failed upgrades.
+// Must be called while sm.mu is held with an exclusive write Lock.
+func (sm *SettingsManager) backupPreMigrationLocked(data []byte, version int) (string, error) {
+	backupPath := fmt.Sprintf("%s.v%d.bak", sm.settingsPath, version)
+	f, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, settingsFileMode)
+	if err != nil {
+		if os.IsExist(err) {
+			return backupPath, nil
+		}
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(backupPath)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(backupPath)
+		return "", err
+	}
+	return backupPath, nil
+}
+
 // Save writes the settings file to disk with secure permissions
 func (sm *SettingsManager) Save() error {
 	sm.mu.Lock()
@@ -283,10 +343,20 @@ func (sm *SettingsManager) GetAll() (*AllSettings, error) {
 	}
 
 	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+	all := sm.fileSettingsLocked()
+	sm.mu.RUnlock()
 
+	if err := ApplyOverrides(SectionSettings, all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// fileSettingsLocked returns the decrypted settings file without flag or
+// environment overrides. sm.mu must be held.
+func (sm *SettingsManager) fileSettingsLocked() *AllSettings {
 	if sm.settings == nil {
-		return DefaultAllSettings(), nil
+		return DefaultAllSettings()
 	}
 
 	all := &AllSettings{
@@ -307,7 +377,7 @@ func (sm *SettingsManager) GetAll() (*AllSettings, error) {
 
 	// Cannot decrypt without an encryption key (init may have failed)
 	if sm.key == nil {
-		return all, nil
+		return all
 	}
 
 	// Decrypt API keys
@@ -362,7 +432,7 @@ func (sm *SettingsManager) GetAll() (*AllSettings, error) {
 		}
 	}
 
-	return all, nil
+	return all
 }
 
 // SaveAll accepts the combined decrypted view and persists it with encryption
@@ -377,6 +447,14 @@ func (sm *SettingsManager) SaveAll(all *AllSettings) error {
 		sm.settings = DefaultSettings()
 	}
 
+	// Values read from flags or the environment stay out of the file, so
+	// keep whatever the file had for every overridden key.
+	persisted := *all
+	if err := restoreOverridden(SectionSettings, &persisted, sm.fileSettingsLocked()); err != nil {
+		return err
+	}
+	all = &persisted
+
 	// Apply defaults for zero-value fields
 	applyDefaults(all)
 
@@ -443,6 +521,168 @@ func (sm *SettingsManager) SaveAll(all *AllSettings) error {
 	return sm.saveLocked()
 }
 
+// GetFeatureFlags returns a copy of the stored feature flag configuration.
+func (sm *SettingsManager) GetFeatureFlags() map[string]FeatureFlagSetting {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	out := make(map[string]FeatureFlagSetting)
+	if sm.settings == nil {
+		return out
+	}
+	for name, f := range sm.settings.Settings.FeatureFlags {
+		out[name] = copyFeatureFlag(f)
+	}
+	return out
+}
+
+// SaveFeatureFlags replaces the stored feature flag configuration.
+func (sm *SettingsManager) SaveFeatureFlags(flags map[string]FeatureFlagSetting) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.pendingLoadErrorLocked(); err != nil {
+		return err
+	}
+	if sm.settings == nil {
+		sm.settings = DefaultSettings()
+	}
+	stored := make(map[string]FeatureFlagSetting, len(flags))
+	for name, f := range flags {
+		stored[name] = copyFeatureFlag(f)
+	}
+	sm.settings.Settings.FeatureFlags = stored
+	return sm.saveLocked()
+}
+
+// GetTelemetry returns the stored telemetry configuration.
+func (sm *SettingsManager) GetTelemetry() TelemetrySettings {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.settings == nil || sm.settings.Settings.Telemetry == nil {
+		return TelemetrySettings{}
+	}
+	return *sm.settings.Settings.Telemetry
+}
+
+// SaveTelemetry replaces the stored telemetry configuration.
+func (sm *SettingsManager) SaveTelemetry(t TelemetrySettings) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.pendingLoadErrorLocked(); err != nil {
+		return err
+	}
+	if sm.settings == nil {
+		sm.settings = DefaultSettings()
+	}
+	sm.settings.Settings.Telemetry = &t
+	return sm.saveLocked()
+}
+
+// GetBranding returns the stored branding configuration.
+func (sm *SettingsManager) GetBranding() BrandingSettings {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.settings == nil || sm.settings.Settings.Branding == nil {
+		return BrandingSettings{}
+	}
+	return *sm.settings.Settings.Branding
+}
+
+// SaveBranding replaces the stored branding configuration. Saving the zero
+// value restores the built-in branding.
+func (sm *SettingsManager) SaveBranding(b BrandingSettings) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.pendingLoadErrorLocked(); err != nil {
+		return err
+	}
+	if sm.settings == nil {
+		sm.settings = DefaultSettings()
+	}
+	if b == (BrandingSettings{}) {
+		sm.settings.Settings.Branding = nil
+	} else {
+		sm.settings.Settings.Branding = &b
+	}
+	return sm.saveLocked()
+}
+
+// GetLimits returns the stored resource limits.
+func (sm *SettingsManager) GetLimits() LimitsSettings {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.settings == nil || sm.settings.Settings.Limits == nil {
+		return LimitsSettings{}
+	}
+	l := *sm.settings.Settings.Limits
+	l.Projects = maps.Clone(l.Projects)
+	return l
+}
+
+// SaveLimits replaces the stored resource limits. Saving the zero value
+// removes them.
+func (sm *SettingsManager) SaveLimits(l LimitsSettings) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.pendingLoadErrorLocked(); err != nil {
+		return err
+	}
+	if sm.settings == nil {
+		sm.settings = DefaultSettings()
+	}
+	if l.IsZero() {
+		sm.settings.Settings.Limits = nil
+	} else {
+		sm.settings.Settings.Limits = &l
+	}
+	return sm.saveLocked()
+}
+
+// GetKubeconfigSecrets returns a copy of the stored kubeconfig Secret
+// references.
+func (sm *SettingsManager) GetKubeconfigSecrets() []KubeconfigSecretRef {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sm.settings == nil {
+		return nil
+	}
+	return append([]KubeconfigSecretRef(nil), sm.settings.Settings.KubeconfigSecrets...)
+}
+
+// SaveKubeconfigSecrets replaces the stored kubeconfig Secret references.
+func (sm *SettingsManager) SaveKubeconfigSecrets(refs []KubeconfigSecretRef) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.pendingLoadErrorLocked(); err != nil {
+		return err
+	}
+	if sm.settings == nil {
+		sm.settings = DefaultSettings()
+	}
+	sm.settings.Settings.KubeconfigSecrets = append([]KubeconfigSecretRef(nil), refs...)
+	return sm.saveLocked()
+}
+
+func copyFeatureFlag(f FeatureFlagSetting) FeatureFlagSetting {
+	if f.Users != nil {
+		users := make(map[string]bool, len(f.Users))
+		for u, v := range f.Users {
+			users[u] = v
+		}
+		f.Users = users
+	}
+	return f
+}
+
 // MigrateFromConfigYaml performs a one-time migration of API keys from ~/.kc/config.yaml.
 // Accepts a ConfigProvider to avoid circular dependency with the agent package.
 func (sm *SettingsManager) MigrateFromConfigYaml(cp ConfigProvider) error {
@@ -535,10 +775,11 @@ func (sm *SettingsManager) ExportEncrypted() ([]byte, error) {
 // ImportEncrypted validates and imports a settings file.
 // Only plaintext settings are imported; encrypted fields require the original key.
 func (sm *SettingsManager) ImportEncrypted(data []byte) error {
-	var imported SettingsFile
-	if err := json.Unmarshal(data, &imported); err != nil {
+	parsed, _, _, err := decodeSettingsFile(data)
+	if err != nil {
 		return fmt.Errorf("invalid settings file: %w", err)
 	}
+	imported := *parsed
 
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -605,6 +846,9 @@ func (sm *SettingsManager) ImportEncrypted(data []byte) error {
 	if t.StopThreshold == 0 {
 		t.StopThreshold = dt.StopThreshold
 	}
+	for _, issue := range sanitize(&sm.settings.Settings) {
+		slog.Warn("[settings] invalid imported setting reset to default", "field", issue.Field, "problem", issue.Message)
+	}
 
 	// Import encrypted fields only if the key fingerprint matches
 	if imported.KeyFingerprint == keyFingerprint(sm.key) {
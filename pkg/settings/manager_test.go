@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -28,8 +29,8 @@ func TestManager_InitCreatesDefaults(t *testing.T) {
 	if sm.settings == nil {
 		t.Fatal("settings should not be nil after init")
 	}
-	if sm.settings.Version != 1 {
-		t.Errorf("version = %d, want 1", sm.settings.Version)
+	if sm.settings.Version != CurrentSchemaVersion {
+		t.Errorf("version = %d, want %d", sm.settings.Version, CurrentSchemaVersion)
 	}
 	if sm.settings.Settings.AIMode != "medium" {
 		t.Errorf("aiMode = %q, want %q", sm.settings.Settings.AIMode, "medium")
@@ -213,6 +214,122 @@ func TestManager_SchemaVersion_ForwardCompat(t *testing.T) {
 	}
 }
 
+func TestManager_FeatureFlags(t *testing.T) {
+	sm := newTestManager(t)
+	flags := map[string]FeatureFlagSetting{
+		"canary-engine": {Enabled: true, Percentage: 25, Users: map[string]bool{"octocat": true}},
+	}
+	if err := sm.SaveFeatureFlags(flags); err != nil {
+		t.Fatalf("SaveFeatureFlags failed: %v", err)
+	}
+	// The caller's map is copied, not retained.
+	flags["canary-engine"].Users["octocat"] = false
+
+	// Saving the settings page must not drop flag configuration.
+	all, err := sm.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	all.Theme = "nord"
+	if err := sm.SaveAll(all); err != nil {
+		t.Fatalf("SaveAll failed: %v", err)
+	}
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	got := sm.GetFeatureFlags()["canary-engine"]
+	if !got.Enabled || got.Percentage != 25 || !got.Users["octocat"] {
+		t.Errorf("feature flag not persisted: %+v", got)
+	}
+}
+
+func TestManager_Telemetry(t *testing.T) {
+	sm := newTestManager(t)
+	if got := sm.GetTelemetry(); got.Enabled || got.Endpoint != "" {
+		t.Errorf("telemetry should default to off, got %+v", got)
+	}
+	want := TelemetrySettings{Enabled: true, Endpoint: "https://telemetry.example.com"}
+	if err := sm.SaveTelemetry(want); err != nil {
+		t.Fatalf("SaveTelemetry failed: %v", err)
+	}
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := sm.GetTelemetry(); got != want {
+		t.Errorf("telemetry = %+v, want %+v", got, want)
+	}
+}
+
+func TestManager_Branding(t *testing.T) {
+	sm := newTestManager(t)
+	if got := sm.GetBranding(); got != (BrandingSettings{}) {
+		t.Errorf("branding should default to empty, got %+v", got)
+	}
+	want := BrandingSettings{ProductName: "Acme", AccentColors: BrandingColors{Primary: "#ff6600"}, LoginMessage: "Hi"}
+	if err := sm.SaveBranding(want); err != nil {
+		t.Fatalf("SaveBranding failed: %v", err)
+	}
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := sm.GetBranding(); got != want {
+		t.Errorf("branding = %+v, want %+v", got, want)
+	}
+
+	if err := sm.SaveBranding(BrandingSettings{}); err != nil {
+		t.Fatalf("SaveBranding failed: %v", err)
+	}
+	if sm.settings.Settings.Branding != nil {
+		t.Errorf("saving empty branding should clear it, got %+v", sm.settings.Settings.Branding)
+	}
+}
+
+func TestManager_Limits(t *testing.T) {
+	sm := newTestManager(t)
+	want := LimitsSettings{
+		MaxClusters: 10,
+		MaxUsers:    25,
+		Projects: map[string]ProjectQuotaSettings{
+			"payments": {Namespaces: []string{"payments-console"}, MaxDeploymentsPerDay: 20},
+		},
+	}
+	if err := sm.SaveLimits(want); err != nil {
+		t.Fatalf("SaveLimits failed: %v", err)
+	}
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := sm.GetLimits(); !reflect.DeepEqual(got, want) {
+		t.Errorf("limits = %+v, want %+v", got, want)
+	}
+
+	if err := sm.SaveLimits(LimitsSettings{}); err != nil {
+		t.Fatalf("SaveLimits failed: %v", err)
+	}
+	if sm.settings.Settings.Limits != nil {
+		t.Errorf("saving empty limits should clear them, got %+v", sm.settings.Settings.Limits)
+	}
+}
+
+func TestManager_KubeconfigSecrets(t *testing.T) {
+	sm := newTestManager(t)
+	if got := sm.GetKubeconfigSecrets(); len(got) != 0 {
+		t.Errorf("kubeconfig secrets should default to empty, got %+v", got)
+	}
+	want := []KubeconfigSecretRef{{Context: "mgmt", Namespace: "capi", Name: "edge-1-kubeconfig", Key: "value"}}
+	if err := sm.SaveKubeconfigSecrets(want); err != nil {
+		t.Fatalf("SaveKubeconfigSecrets failed: %v", err)
+	}
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got := sm.GetKubeconfigSecrets()
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("kubeconfig secrets = %+v, want %+v", got, want)
+	}
+}
+
 func TestManager_LoadReturnsErrorWhenCorruptBackupFails(t *testing.T) {
 	dir := t.TempDir()
 	sm := &SettingsManager{
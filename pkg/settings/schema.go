@@ -0,0 +1,470 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CurrentSchemaVersion is the settings file version written by this build.
+// Bump it together with a new entry in migrations whenever the on-disk shape
+// changes in a way older files need rewriting for.
+const CurrentSchemaVersion = 2
+
+// ErrNewerSchema is returned when a settings document was written by a newer
+// build than this one.
+var ErrNewerSchema = errors.New("settings file was written by a newer version")
+
+// migration upgrades a raw settings document from version `from` to from+1.
+// Migrations work on the raw JSON so they can rename or move fields that the
+// current Go types no longer declare.
+type migration struct {
+	from        int
+	description string
+	apply       func(doc map[string]json.RawMessage) error
+}
+
+// migrations must stay ordered by from and cover every version below
+// CurrentSchemaVersion.
+var migrations = []migration{
+	{
+		from:        0,
+		description: "stamp unversioned settings file as version 1",
+		apply:       func(map[string]json.RawMessage) error { return nil },
+	},
+	{
+		from:        1,
+		description: "move legacy encrypted githubToken to feedbackGithubToken",
+		apply:       migrateLegacyGitHubTokenField,
+	},
+}
+
+// migrateDocument upgrades doc in place to CurrentSchemaVersion. It returns
+// the version doc started at and the descriptions of the migrations applied.
+func migrateDocument(doc map[string]json.RawMessage) (int, []string, error) {
+	from := 0
+	if raw, ok := doc["version"]; ok {
+		if err := json.Unmarshal(raw, &from); err != nil {
+			return 0, nil, fmt.Errorf("invalid settings version: %w", err)
+		}
+	}
+	if from > CurrentSchemaVersion {
+		return from, nil, fmt.Errorf("%w: version %d, this build supports %d", ErrNewerSchema, from, CurrentSchemaVersion)
+	}
+
+	var applied []string
+	version := from
+	for _, m := range migrations {
+		if m.from != version {
+			continue
+		}
+		if err := m.apply(doc); err != nil {
+			return from, applied, fmt.Errorf("migrate settings v%d to v%d: %w", m.from, m.from+1, err)
+		}
+		version++
+		applied = append(applied, m.description)
+	}
+	if version != CurrentSchemaVersion {
+		return from, applied, fmt.Errorf("no settings migration from version %d", version)
+	}
+	doc["version"] = json.RawMessage(fmt.Sprintf("%d", version))
+	return from, applied, nil
+}
+
+// migrateLegacyGitHubTokenField folds encrypted.githubToken into
+// encrypted.feedbackGithubToken, keeping the latter when both are set.
+func migrateLegacyGitHubTokenField(doc map[string]json.RawMessage) error {
+	raw, ok := doc["encrypted"]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	var encrypted map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &encrypted); err != nil {
+		return err
+	}
+	legacy, ok := encrypted["githubToken"]
+	if !ok {
+		return nil
+	}
+	if current, has := encrypted["feedbackGithubToken"]; !has || string(current) == "null" {
+		encrypted["feedbackGithubToken"] = legacy
+	}
+	delete(encrypted, "githubToken")
+	out, err := json.Marshal(encrypted)
+	if err != nil {
+		return err
+	}
+	doc["encrypted"] = out
+	return nil
+}
+
+// decodeSettingsFile parses a settings file, migrating it to the current
+// schema first. It returns the version the file was written at and the
+// migrations that were applied.
+func decodeSettingsFile(data []byte) (*SettingsFile, int, []string, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, nil, err
+	}
+	if doc == nil {
+		return nil, 0, nil, errors.New("settings file must be a JSON object")
+	}
+	from, applied, err := migrateDocument(doc)
+	if err != nil {
+		return nil, from, nil, err
+	}
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, from, nil, err
+	}
+	var sf SettingsFile
+	if err := json.Unmarshal(migrated, &sf); err != nil {
+		return nil, from, nil, err
+	}
+	return &sf, from, applied, nil
+}
+
+// ValidationIssue describes one setting that failed validation.
+type ValidationIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationResult is the outcome of ValidateJSON.
+type ValidationResult struct {
+	Valid bool `json:"valid"`
+	// Version is the schema version of the submitted settings file; zero for
+	// a bare settings payload.
+	Version        int `json:"version,omitempty"`
+	CurrentVersion int `json:"currentVersion"`
+	// Migrations lists the migrations loading the file would apply.
+	Migrations []string          `json:"migrations,omitempty"`
+	Issues     []ValidationIssue `json:"issues"`
+}
+
+// fieldRule validates one plaintext setting and knows how to reset it.
+type fieldRule struct {
+	field string
+	// check returns a problem description, or "" when the value is valid.
+	check func(s *PlaintextSettings) string
+	reset func(s, defaults *PlaintextSettings)
+}
+
+func percentRule(field string, get func(s *PlaintextSettings) *int) fieldRule {
+	return fieldRule{
+		field: field,
+		check: func(s *PlaintextSettings) string {
+			if v := *get(s); v < 0 || v > 100 {
+				return fmt.Sprintf("must be between 0 and 100, got %d", v)
+			}
+			return ""
+		},
+		reset: func(s, d *PlaintextSettings) { *get(s) = *get(d) },
+	}
+}
+
+var fieldRules = []fieldRule{
+	{
+		field: "aiMode",
+		check: func(s *PlaintextSettings) string {
+			switch s.AIMode {
+			case "", "low", "medium", "high":
+				return ""
+			}
+			return fmt.Sprintf("must be one of low, medium, high, got %q", s.AIMode)
+		},
+		reset: func(s, d *PlaintextSettings) { s.AIMode = d.AIMode },
+	},
+	{
+		field: "autoUpdateChannel",
+		check: func(s *PlaintextSettings) string {
+			switch s.AutoUpdateChannel {
+			case "", "stable", "unstable", "developer":
+				return ""
+			}
+			return fmt.Sprintf("must be one of stable, unstable, developer, got %q", s.AutoUpdateChannel)
+		},
+		reset: func(s, d *PlaintextSettings) { s.AutoUpdateChannel = d.AutoUpdateChannel },
+	},
+	{
+		field: "predictions.interval",
+		check: func(s *PlaintextSettings) string {
+			if s.Predictions.Interval < 0 {
+				return fmt.Sprintf("must not be negative, got %d", s.Predictions.Interval)
+			}
+			return ""
+		},
+		reset: func(s, d *PlaintextSettings) { s.Predictions.Interval = d.Predictions.Interval },
+	},
+	percentRule("predictions.minConfidence", func(s *PlaintextSettings) *int { return &s.Predictions.MinConfidence }),
+	{
+		field: "predictions.maxPredictions",
+		check: func(s *PlaintextSettings) string {
+			if s.Predictions.MaxPredictions < 0 {
+				return fmt.Sprintf("must not be negative, got %d", s.Predictions.MaxPredictions)
+			}
+			return ""
+		},
+		reset: func(s, d *PlaintextSettings) { s.Predictions.MaxPredictions = d.Predictions.MaxPredictions },
+	},
+	{
+		field: "predictions.thresholds.highRestartCount",
+		check: func(s *PlaintextSettings) string {
+			if s.Predictions.Thresholds.HighRestartCount < 0 {
+				return fmt.Sprintf("must not be negative, got %d", s.Predictions.Thresholds.HighRestartCount)
+			}
+			return ""
+		},
+		reset: func(s, d *PlaintextSettings) {
+			s.Predictions.Thresholds.HighRestartCount = d.Predictions.Thresholds.HighRestartCount
+		},
+	},
+	percentRule("predictions.thresholds.cpuPressure", func(s *PlaintextSettings) *int { return &s.Predictions.Thresholds.CPUPressure }),
+	percentRule("predictions.thresholds.memoryPressure", func(s *PlaintextSettings) *int { return &s.Predictions.Thresholds.MemoryPressure }),
+	percentRule("predictions.thresholds.gpuMemoryPressure", func(s *PlaintextSettings) *int { return &s.Predictions.Thresholds.GPUMemoryPressure }),
+	{
+		field: "tokenUsage.limit",
+		check: func(s *PlaintextSettings) string {
+			if s.TokenUsage.Limit < 0 {
+				return fmt.Sprintf("must not be negative, got %d", s.TokenUsage.Limit)
+			}
+			return ""
+		},
+		reset: func(s, d *PlaintextSettings) { s.TokenUsage.Limit = d.TokenUsage.Limit },
+	},
+	{
+		// Zero thresholds are backfilled with defaults, so only negative and
+		// out-of-order values are rejected.
+		field: "tokenUsage",
+		check: func(s *PlaintextSettings) string {
+			t := s.TokenUsage
+			if t.WarningThreshold < 0 || t.CriticalThreshold < 0 || t.StopThreshold < 0 {
+				return "thresholds must not be negative"
+			}
+			if t.WarningThreshold > 0 && t.CriticalThreshold > 0 && t.WarningThreshold > t.CriticalThreshold {
+				return fmt.Sprintf("warningThreshold %.2f exceeds criticalThreshold %.2f", t.WarningThreshold, t.CriticalThreshold)
+			}
+			if t.CriticalThreshold > 0 && t.StopThreshold > 0 && t.CriticalThreshold > t.StopThreshold {
+				return fmt.Sprintf("criticalThreshold %.2f exceeds stopThreshold %.2f", t.CriticalThreshold, t.StopThreshold)
+			}
+			return ""
+		},
+		reset: func(s, d *PlaintextSettings) {
+			s.TokenUsage.WarningThreshold = d.TokenUsage.WarningThreshold
+			s.TokenUsage.CriticalThreshold = d.TokenUsage.CriticalThreshold
+			s.TokenUsage.StopThreshold = d.TokenUsage.StopThreshold
+		},
+	},
+	{
+		field: "customThemes",
+		check: func(s *PlaintextSettings) string {
+			if len(s.CustomThemes) == 0 || string(s.CustomThemes) == "null" {
+				return ""
+			}
+			var themes []json.RawMessage
+			if err := json.Unmarshal(s.CustomThemes, &themes); err != nil {
+				return "must be a JSON array"
+			}
+			return ""
+		},
+		reset: func(s, _ *PlaintextSettings) { s.CustomThemes = nil },
+	},
+	{
+		field: "featureFlags",
+		check: func(s *PlaintextSettings) string {
+			for _, name := range sortedFlagNames(s.FeatureFlags) {
+				if p := s.FeatureFlags[name].Percentage; p < 0 || p > 100 {
+					return fmt.Sprintf("%s: percentage must be between 0 and 100, got %d", name, p)
+				}
+			}
+			return ""
+		},
+		reset: func(s, _ *PlaintextSettings) {
+			for name, f := range s.FeatureFlags {
+				if f.Percentage < 0 || f.Percentage > 100 {
+					delete(s.FeatureFlags, name)
+				}
+			}
+		},
+	},
+	{
+		field: "telemetry.endpoint",
+		check: func(s *PlaintextSettings) string {
+			if s.Telemetry == nil || s.Telemetry.Endpoint == "" {
+				return ""
+			}
+			u, err := url.Parse(s.Telemetry.Endpoint)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Sprintf("must be an absolute http(s) URL, got %q", s.Telemetry.Endpoint)
+			}
+			return ""
+		},
+		// Drop the whole opt-in rather than keep sending to nowhere.
+		reset: func(s, _ *PlaintextSettings) { s.Telemetry = nil },
+	},
+	{
+		field: "branding",
+		check: func(s *PlaintextSettings) string {
+			if s.Branding == nil {
+				return ""
+			}
+			if issues := ValidateBranding(s.Branding); len(issues) > 0 {
+				return issues[0].Field + ": " + issues[0].Message
+			}
+			return ""
+		},
+		// A half-valid brand looks worse than the built-in one.
+		reset: func(s, _ *PlaintextSettings) { s.Branding = nil },
+	},
+	{
+		field: "limits",
+		check: func(s *PlaintextSettings) string {
+			if l := s.Limits; l != nil && (l.MaxClusters < 0 || l.MaxManagedWorkloads < 0 || l.MaxUsers < 0) {
+				return "limits must not be negative"
+			}
+			return ""
+		},
+		reset: func(s, _ *PlaintextSettings) { s.Limits = nil },
+	},
+}
+
+const (
+	maxBrandingProductNameLen  = 64
+	maxBrandingLogoURLLen      = 2048
+	maxBrandingLoginMessageLen = 1000
+)
+
+// ValidateBranding reports every branding field that is invalid. The logo
+// may be an absolute http(s) URL or a path on the console's own origin.
+func ValidateBranding(b *BrandingSettings) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	add := func(field, msg string) {
+		issues = append(issues, ValidationIssue{Field: "branding." + field, Message: msg})
+	}
+	if n := utf8.RuneCountInString(b.ProductName); n > maxBrandingProductNameLen {
+		add("productName", fmt.Sprintf("must be at most %d characters, got %d", maxBrandingProductNameLen, n))
+	} else if strings.IndexFunc(b.ProductName, unicode.IsControl) >= 0 {
+		add("productName", "must not contain control characters")
+	}
+	if b.LogoURL != "" && !validLogoURL(b.LogoURL) {
+		add("logoUrl", "must be an absolute http(s) URL or a path starting with /")
+	}
+	if b.AccentColors.Primary != "" && !hexColorPattern.MatchString(b.AccentColors.Primary) {
+		add("accentColors.primary", fmt.Sprintf("must be a hex color like #1a73e8, got %q", b.AccentColors.Primary))
+	}
+	if b.AccentColors.Secondary != "" && !hexColorPattern.MatchString(b.AccentColors.Secondary) {
+		add("accentColors.secondary", fmt.Sprintf("must be a hex color like #1a73e8, got %q", b.AccentColors.Secondary))
+	}
+	if n := utf8.RuneCountInString(b.LoginMessage); n > maxBrandingLoginMessageLen {
+		add("loginMessage", fmt.Sprintf("must be at most %d characters, got %d", maxBrandingLoginMessageLen, n))
+	}
+	return issues
+}
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func validLogoURL(raw string) bool {
+	if len(raw) > maxBrandingLogoURLLen {
+		return false
+	}
+	if strings.HasPrefix(raw, "/") {
+		// "//host" and "/\host" are protocol-relative in browsers.
+		return !strings.HasPrefix(raw, "//") && !strings.HasPrefix(raw, "/\\")
+	}
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func sortedFlagNames(flags map[string]FeatureFlagSetting) []string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate reports every plaintext setting that is out of range.
+func Validate(s *PlaintextSettings) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	for _, r := range fieldRules {
+		if msg := r.check(s); msg != "" {
+			issues = append(issues, ValidationIssue{Field: r.field, Message: msg})
+		}
+	}
+	return issues
+}
+
+// sanitize resets every invalid plaintext setting to its default and returns
+// what was reset.
+func sanitize(s *PlaintextSettings) []ValidationIssue {
+	issues := Validate(s)
+	if len(issues) == 0 {
+		return issues
+	}
+	defaults := DefaultSettings().Settings
+	for _, r := range fieldRules {
+		if r.check(s) != "" {
+			r.reset(s, &defaults)
+		}
+	}
+	return issues
+}
+
+// ValidateJSON validates a settings payload without saving it. It accepts
+// either a full settings file (as produced by export) or the settings body
+// sent to PUT /api/settings.
+func ValidateJSON(data []byte) (*ValidationResult, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid settings JSON: %w", err)
+	}
+	result := &ValidationResult{CurrentVersion: CurrentSchemaVersion}
+
+	var plaintext PlaintextSettings
+	if _, isFile := probe["settings"]; isFile {
+		sf, from, applied, err := decodeSettingsFile(data)
+		result.Version = from
+		if err != nil {
+			if errors.Is(err, ErrNewerSchema) {
+				result.Issues = []ValidationIssue{{Field: "version", Message: err.Error()}}
+				return result, nil
+			}
+			return nil, fmt.Errorf("invalid settings file: %w", err)
+		}
+		result.Migrations = applied
+		plaintext = sf.Settings
+	} else {
+		var all AllSettings
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("invalid settings: %w", err)
+		}
+		plaintext = all.plaintext()
+	}
+
+	result.Issues = Validate(&plaintext)
+	result.Valid = len(result.Issues) == 0
+	return result, nil
+}
+
+// plaintext returns the non-sensitive part of a.
+func (a *AllSettings) plaintext() PlaintextSettings {
+	return PlaintextSettings{
+		AIMode:            a.AIMode,
+		Predictions:       a.Predictions,
+		TokenUsage:        a.TokenUsage,
+		Theme:             a.Theme,
+		CustomThemes:      a.CustomThemes,
+		Accessibility:     a.Accessibility,
+		Profile:           a.Profile,
+		Widget:            a.Widget,
+		AutoUpdateEnabled: a.AutoUpdateEnabled,
+		AutoUpdateChannel: a.AutoUpdateChannel,
+	}
+}
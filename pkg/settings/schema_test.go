@@ -0,0 +1,284 @@
+package settings
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func writeRawSettings(t *testing.T, sm *SettingsManager, doc map[string]interface{}) []byte {
+	t.Helper()
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := os.WriteFile(sm.settingsPath, data, settingsFileMode); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	return data
+}
+
+func TestSchema_LoadMigratesV1AndBacksUp(t *testing.T) {
+	sm := newTestManager(t)
+	legacy, err := encrypt(sm.key, []byte("ghp_legacy"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	original := writeRawSettings(t, sm, map[string]interface{}{
+		"version":   1,
+		"settings":  map[string]interface{}{"theme": "nord"},
+		"encrypted": map[string]interface{}{"githubToken": legacy},
+	})
+
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	backup, err := os.ReadFile(sm.settingsPath + ".v1.bak")
+	if err != nil {
+		t.Fatalf("expected pre-migration backup: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Error("backup does not match the pre-migration file")
+	}
+
+	onDisk, err := os.ReadFile(sm.settingsPath)
+	if err != nil {
+		t.Fatalf("read migrated file: %v", err)
+	}
+	var migrated SettingsFile
+	if err := json.Unmarshal(onDisk, &migrated); err != nil {
+		t.Fatalf("parse migrated file: %v", err)
+	}
+	if migrated.Version != CurrentSchemaVersion {
+		t.Errorf("version on disk = %d, want %d", migrated.Version, CurrentSchemaVersion)
+	}
+	if migrated.Encrypted.GitHubToken != nil || migrated.Encrypted.FeedbackGitHubToken == nil {
+		t.Error("legacy githubToken should be moved to feedbackGithubToken")
+	}
+
+	all, err := sm.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if all.FeedbackGitHubToken != "ghp_legacy" || all.Theme != "nord" {
+		t.Errorf("migrated settings lost data: token=%q theme=%q", all.FeedbackGitHubToken, all.Theme)
+	}
+}
+
+func TestSchema_LoadUnversionedFile(t *testing.T) {
+	sm := newTestManager(t)
+	writeRawSettings(t, sm, map[string]interface{}{
+		"settings": map[string]interface{}{"aiMode": "low"},
+	})
+
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if sm.settings.Version != CurrentSchemaVersion || sm.settings.Settings.AIMode != "low" {
+		t.Errorf("unexpected settings after migration: version=%d aiMode=%q", sm.settings.Version, sm.settings.Settings.AIMode)
+	}
+	if _, err := os.Stat(sm.settingsPath + ".v0.bak"); err != nil {
+		t.Errorf("expected v0 backup: %v", err)
+	}
+}
+
+func TestSchema_LoadCurrentVersionDoesNotBackUp(t *testing.T) {
+	sm := newTestManager(t)
+	if err := sm.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := os.Stat(sm.settingsPath + ".v2.bak"); !os.IsNotExist(err) {
+		t.Errorf("no backup expected for a current file, stat err = %v", err)
+	}
+}
+
+func TestSchema_LoadNewerVersionKeepsCopy(t *testing.T) {
+	sm := newTestManager(t)
+	writeRawSettings(t, sm, map[string]interface{}{
+		"version":  CurrentSchemaVersion + 1,
+		"settings": map[string]interface{}{"theme": "dracula", "futureField": true},
+	})
+
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if sm.settings.Settings.Theme != "dracula" {
+		t.Errorf("theme = %q, want dracula", sm.settings.Settings.Theme)
+	}
+	backup, err := os.ReadFile(sm.settingsPath + ".v3.bak")
+	if err != nil {
+		t.Fatalf("expected backup of newer file: %v", err)
+	}
+	if !strings.Contains(string(backup), "futureField") {
+		t.Error("backup should keep fields this build does not know")
+	}
+}
+
+func TestSchema_LoadResetsInvalidValues(t *testing.T) {
+	sm := newTestManager(t)
+	writeRawSettings(t, sm, map[string]interface{}{
+		"version": CurrentSchemaVersion,
+		"settings": map[string]interface{}{
+			"aiMode":            "turbo",
+			"autoUpdateChannel": "nightly",
+			"predictions": map[string]interface{}{
+				"minConfidence": 60,
+				"thresholds":    map[string]interface{}{"cpuPressure": 250},
+			},
+			"featureFlags": map[string]interface{}{
+				"canary-engine": map[string]interface{}{"enabled": true, "percentage": 150},
+				"relay-mode":    map[string]interface{}{"enabled": true},
+			},
+			"telemetry": map[string]interface{}{"enabled": true, "endpoint": "ftp://collector"},
+			"branding":  map[string]interface{}{"productName": "Acme", "accentColors": map[string]interface{}{"primary": "red"}},
+			"limits":    map[string]interface{}{"maxClusters": -1},
+		},
+	})
+
+	if err := sm.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	s := sm.settings.Settings
+	if s.AIMode != "medium" || s.AutoUpdateChannel != "" || s.Predictions.Thresholds.CPUPressure != 80 {
+		t.Errorf("invalid values not reset: aiMode=%q channel=%q cpu=%d",
+			s.AIMode, s.AutoUpdateChannel, s.Predictions.Thresholds.CPUPressure)
+	}
+	if s.Predictions.MinConfidence != 60 {
+		t.Errorf("valid value changed: minConfidence=%d", s.Predictions.MinConfidence)
+	}
+	if _, ok := s.FeatureFlags["canary-engine"]; ok || !s.FeatureFlags["relay-mode"].Enabled {
+		t.Errorf("expected only the out-of-range flag to be dropped: %+v", s.FeatureFlags)
+	}
+	if s.Telemetry != nil {
+		t.Errorf("telemetry with an invalid endpoint should be dropped: %+v", s.Telemetry)
+	}
+	if s.Branding != nil {
+		t.Errorf("branding with an invalid color should be dropped: %+v", s.Branding)
+	}
+	if s.Limits != nil {
+		t.Errorf("negative limits should be dropped: %+v", s.Limits)
+	}
+}
+
+func TestValidateBranding(t *testing.T) {
+	valid := BrandingSettings{
+		ProductName:  "Acme Fleet Console",
+		LogoURL:      "https://cdn.acme.example/logo.svg",
+		AccentColors: BrandingColors{Primary: "#1A73E8", Secondary: "#fff"},
+		LoginMessage: "Authorized use only.",
+	}
+	if issues := ValidateBranding(&valid); len(issues) != 0 {
+		t.Errorf("valid branding rejected: %+v", issues)
+	}
+	if issues := ValidateBranding(&BrandingSettings{LogoURL: "/branding/logo.png"}); len(issues) != 0 {
+		t.Errorf("same-origin logo path rejected: %+v", issues)
+	}
+
+	for name, tc := range map[string]struct {
+		b     BrandingSettings
+		field string
+	}{
+		"long name":          {BrandingSettings{ProductName: strings.Repeat("n", maxBrandingProductNameLen+1)}, "branding.productName"},
+		"control char":       {BrandingSettings{ProductName: "Acme\n"}, "branding.productName"},
+		"javascript logo":    {BrandingSettings{LogoURL: "javascript:alert(1)"}, "branding.logoUrl"},
+		"protocol-relative":  {BrandingSettings{LogoURL: "//evil.example/logo.png"}, "branding.logoUrl"},
+		"relative logo":      {BrandingSettings{LogoURL: "logo.png"}, "branding.logoUrl"},
+		"named color":        {BrandingSettings{AccentColors: BrandingColors{Primary: "red"}}, "branding.accentColors.primary"},
+		"bad hex":            {BrandingSettings{AccentColors: BrandingColors{Secondary: "#12345"}}, "branding.accentColors.secondary"},
+		"long login message": {BrandingSettings{LoginMessage: strings.Repeat("m", maxBrandingLoginMessageLen+1)}, "branding.loginMessage"},
+	} {
+		issues := ValidateBranding(&tc.b)
+		if len(issues) != 1 || issues[0].Field != tc.field {
+			t.Errorf("%s: issues = %+v, want one for %s", name, issues, tc.field)
+		}
+	}
+}
+
+func TestSchema_ImportMigratesOldFile(t *testing.T) {
+	sm := newTestManager(t)
+	legacy, err := encrypt(sm.key, []byte("ghp_imported"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"version":        1,
+		"settings":       map[string]interface{}{"theme": "nord"},
+		"encrypted":      map[string]interface{}{"githubToken": legacy},
+		"keyFingerprint": keyFingerprint(sm.key),
+	})
+	if err := sm.ImportEncrypted(data); err != nil {
+		t.Fatalf("ImportEncrypted failed: %v", err)
+	}
+	all, err := sm.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if all.FeedbackGitHubToken != "ghp_imported" {
+		t.Errorf("imported token = %q, want ghp_imported", all.FeedbackGitHubToken)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	t.Run("valid settings body", func(t *testing.T) {
+		data, _ := json.Marshal(DefaultAllSettings())
+		result, err := ValidateJSON(data)
+		if err != nil {
+			t.Fatalf("ValidateJSON failed: %v", err)
+		}
+		if !result.Valid || len(result.Issues) != 0 {
+			t.Errorf("expected valid, got %+v", result)
+		}
+	})
+
+	t.Run("invalid settings body", func(t *testing.T) {
+		all := DefaultAllSettings()
+		all.AIMode = "turbo"
+		all.TokenUsage.WarningThreshold = 0.95
+		all.TokenUsage.CriticalThreshold = 0.9
+		data, _ := json.Marshal(all)
+		result, err := ValidateJSON(data)
+		if err != nil {
+			t.Fatalf("ValidateJSON failed: %v", err)
+		}
+		fields := map[string]bool{}
+		for _, issue := range result.Issues {
+			fields[issue.Field] = true
+		}
+		if result.Valid || !fields["aiMode"] || !fields["tokenUsage"] {
+			t.Errorf("expected aiMode and tokenUsage issues, got %+v", result.Issues)
+		}
+	})
+
+	t.Run("old settings file lists migrations", func(t *testing.T) {
+		data, _ := json.Marshal(map[string]interface{}{"version": 1, "settings": map[string]interface{}{}})
+		result, err := ValidateJSON(data)
+		if err != nil {
+			t.Fatalf("ValidateJSON failed: %v", err)
+		}
+		if result.Version != 1 || result.CurrentVersion != CurrentSchemaVersion || len(result.Migrations) != 1 {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("newer settings file", func(t *testing.T) {
+		data, _ := json.Marshal(map[string]interface{}{"version": CurrentSchemaVersion + 1, "settings": map[string]interface{}{}})
+		result, err := ValidateJSON(data)
+		if err != nil {
+			t.Fatalf("ValidateJSON failed: %v", err)
+		}
+		if result.Valid || len(result.Issues) != 1 || result.Issues[0].Field != "version" {
+			t.Errorf("expected a version issue, got %+v", result)
+		}
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		if _, err := ValidateJSON([]byte("{nope")); err == nil {
+			t.Error("expected error for invalid JSON")
+		}
+	})
+}
@@ -30,6 +30,97 @@ type PlaintextSettings struct {
 	// Auto-update configuration — persisted so user changes survive restarts (#7571).
 	AutoUpdateEnabled bool   `json:"autoUpdateEnabled"`
 	AutoUpdateChannel string `json:"autoUpdateChannel"`
+
+	// FeatureFlags holds admin-configured feature flag state keyed by flag
+	// name. It is managed through the feature flag admin API rather than
+	// PUT /api/settings, so it is not part of AllSettings.
+	FeatureFlags map[string]FeatureFlagSetting `json:"featureFlags,omitempty"`
+
+	// Telemetry holds the usage telemetry opt-in. Like FeatureFlags it is
+	// managed through its own admin API.
+	Telemetry *TelemetrySettings `json:"telemetry,omitempty"`
+
+	// Branding holds the white-label configuration. It is managed through
+	// the branding admin API and served unauthenticated to the login page.
+	Branding *BrandingSettings `json:"branding,omitempty"`
+
+	// Limits holds the admin-configured resource limits. It is managed
+	// through the limits admin API; a license file can tighten it further.
+	Limits *LimitsSettings `json:"limits,omitempty"`
+
+	// KubeconfigSecrets lists kubeconfigs imported from Secrets on connected
+	// clusters that kc-agent re-reads to pick up rotated credentials.
+	KubeconfigSecrets []KubeconfigSecretRef `json:"kubeconfigSecrets,omitempty"`
+}
+
+// KubeconfigSecretRef points at a kubeconfig stored in a Secret, such as the
+// <cluster>-kubeconfig Secret Cluster API writes for each workload cluster.
+type KubeconfigSecretRef struct {
+	// Context is the already-connected kubeconfig context holding the Secret.
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Key is the data key holding the kubeconfig.
+	Key string `json:"key"`
+}
+
+// BrandingSettings is the stored white-label configuration. Empty fields
+// mean the console's built-in branding.
+type BrandingSettings struct {
+	ProductName  string         `json:"productName,omitempty"`
+	LogoURL      string         `json:"logoUrl,omitempty"`
+	AccentColors BrandingColors `json:"accentColors"`
+	LoginMessage string         `json:"loginMessage,omitempty"`
+}
+
+// LimitsSettings caps how many of each resource the console manages. Zero
+// means unlimited.
+type LimitsSettings struct {
+	MaxClusters         int `json:"maxClusters,omitempty"`
+	MaxManagedWorkloads int `json:"maxManagedWorkloads,omitempty"`
+	MaxUsers            int `json:"maxUsers,omitempty"`
+
+	// Projects are per-project quotas, keyed by project name.
+	Projects map[string]ProjectQuotaSettings `json:"projects,omitempty"`
+}
+
+// IsZero reports whether no limit or quota is set.
+func (l LimitsSettings) IsZero() bool {
+	return l.MaxClusters == 0 && l.MaxManagedWorkloads == 0 && l.MaxUsers == 0 && len(l.Projects) == 0
+}
+
+// ProjectQuotaSettings caps what one project creates. Zero means unlimited.
+type ProjectQuotaSettings struct {
+	// Namespaces are the persistence namespaces holding the project's
+	// console CRs.
+	Namespaces           []string `json:"namespaces"`
+	MaxManagedWorkloads  int      `json:"maxManagedWorkloads,omitempty"`
+	MaxDeploymentsPerDay int      `json:"maxDeploymentsPerDay,omitempty"`
+	MaxTargetClusters    int      `json:"maxTargetClusters,omitempty"`
+}
+
+// BrandingColors are CSS hex colors (#rgb or #rrggbb).
+type BrandingColors struct {
+	Primary   string `json:"primary,omitempty"`
+	Secondary string `json:"secondary,omitempty"`
+}
+
+// TelemetrySettings is the stored usage telemetry configuration. Telemetry is
+// off unless an admin opts in and an endpoint is configured.
+type TelemetrySettings struct {
+	Enabled  bool   `json:"enabled"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// FeatureFlagSetting is the stored configuration of one feature flag.
+type FeatureFlagSetting struct {
+	Enabled bool `json:"enabled"`
+	// Percentage is the share of users (0-100) a percentage flag is rolled
+	// out to. Ignored for boolean flags.
+	Percentage int `json:"percentage,omitempty"`
+	// Users overrides the flag for individual users, keyed by user ID or
+	// GitHub login.
+	Users map[string]bool `json:"users,omitempty"`
 }
 
 // PredictionSettings mirrors the frontend PredictionSettings type
@@ -188,7 +279,7 @@ type NotificationSecrets struct {
 // DefaultSettings returns a SettingsFile with sensible defaults
 func DefaultSettings() *SettingsFile {
 	return &SettingsFile{
-		Version: 1,
+		Version: CurrentSchemaVersion,
 		Settings: PlaintextSettings{
 			AIMode: "medium",
 			Predictions: PredictionSettings{
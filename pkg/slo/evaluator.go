@@ -0,0 +1,226 @@
+package slo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/notifications"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+const (
+	// defaultEvalInterval is how often SLOs are evaluated for alerting. It
+	// only needs to be well below the shortest rule window.
+	defaultEvalInterval = time.Minute
+	// evalTimeout bounds one evaluation of one SLO.
+	evalTimeout = 30 * time.Second
+
+	alertStatusFiring   = "firing"
+	alertStatusResolved = "resolved"
+)
+
+// sloLister is the subset of store.SLOStore the evaluator needs.
+type sloLister interface {
+	ListSLOs(ctx context.Context, project string, limit, offset int) ([]models.SLO, error)
+}
+
+// alertSender is the subset of notifications.Service the evaluator needs.
+type alertSender interface {
+	SendAlert(alert notifications.Alert) error
+}
+
+// Evaluator periodically evaluates the burn-rate rules of every SLO in a
+// project that has alerts enabled. It sends a firing alert when a rule starts
+// firing and a resolved alert when it stops, never one per evaluation.
+type Evaluator struct {
+	store    sloLister
+	querier  Querier
+	alerts   alertSender
+	project  string
+	rules    []BurnRateRule
+	interval time.Duration
+
+	mu     sync.Mutex
+	firing map[string]notifications.Alert // keyed by alert RuleID
+}
+
+// NewEvaluator returns an evaluator for project that runs every
+// SLO_EVAL_INTERVAL (default 1m).
+func NewEvaluator(store sloLister, querier Querier, alerts alertSender, project string) *Evaluator {
+	interval := defaultEvalInterval
+	if raw := os.Getenv("SLO_EVAL_INTERVAL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			interval = d
+		} else {
+			slog.Warn("[SLO] invalid SLO_EVAL_INTERVAL, using default", "value", raw, "default", defaultEvalInterval)
+		}
+	}
+	return &Evaluator{
+		store:    store,
+		querier:  querier,
+		alerts:   alerts,
+		project:  project,
+		rules:    DefaultRules,
+		interval: interval,
+		firing:   make(map[string]notifications.Alert),
+	}
+}
+
+// Start evaluates SLOs every interval until done is closed.
+func (e *Evaluator) Start(done <-chan struct{}) {
+	// Cancelling on done aborts in-flight Prometheus queries on shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	safego.GoWith("slo/evaluator-cancel", func() {
+		<-done
+		cancel()
+	})
+	safego.GoWith("slo/evaluator", func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				e.evaluateAll(ctx, now)
+			}
+		}
+	})
+	slog.Info("[SLO] evaluator started", "interval", e.interval, "project", e.project)
+}
+
+// evaluateAll evaluates every alerting SLO once and sends alerts for rules
+// whose firing state changed.
+func (e *Evaluator) evaluateAll(ctx context.Context, now time.Time) {
+	slos, err := e.store.ListSLOs(ctx, e.project, 0, 0)
+	if err != nil {
+		slog.Error("[SLO] failed to list SLOs", "error", err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := range slos {
+		s := &slos[i]
+		if !s.AlertsEnabled {
+			continue
+		}
+		evalCtx, cancel := context.WithTimeout(ctx, evalTimeout)
+		st, err := Evaluate(evalCtx, e.querier, s, e.rules, now)
+		cancel()
+		if err != nil {
+			// Keep the previous state so a Prometheus outage neither
+			// resolves nor re-fires alerts.
+			slog.Warn("[SLO] evaluation failed", "slo", s.Name, "cluster", s.Target.Cluster, "error", err)
+			for _, r := range e.rules {
+				seen[alertRuleID(s.ID, r.Name)] = true
+			}
+			continue
+		}
+		for _, rs := range st.Rules {
+			id := alertRuleID(s.ID, rs.Name)
+			seen[id] = true
+			e.transition(id, rs.Firing, now, func() notifications.Alert { return newAlert(s, st, rs, now) })
+		}
+	}
+
+	// SLOs that were deleted or had alerts disabled resolve their alerts.
+	e.mu.Lock()
+	var stale []notifications.Alert
+	for id, a := range e.firing {
+		if !seen[id] {
+			stale = append(stale, a)
+			delete(e.firing, id)
+		}
+	}
+	e.mu.Unlock()
+	for _, a := range stale {
+		e.send(resolved(a, now, "SLO was removed or its alerts were disabled"))
+	}
+}
+
+// transition records the firing state of one rule and sends an alert when it
+// changed.
+func (e *Evaluator) transition(id string, firing bool, now time.Time, build func() notifications.Alert) {
+	e.mu.Lock()
+	prev, wasFiring := e.firing[id]
+	var out *notifications.Alert
+	switch {
+	case firing && !wasFiring:
+		a := build()
+		e.firing[id] = a
+		out = &a
+	case !firing && wasFiring:
+		delete(e.firing, id)
+		r := resolved(prev, now, "Error budget burn rate is back below the threshold")
+		out = &r
+	}
+	e.mu.Unlock()
+	if out != nil {
+		e.send(*out)
+	}
+}
+
+func (e *Evaluator) send(a notifications.Alert) {
+	if e.alerts == nil {
+		return
+	}
+	if err := e.alerts.SendAlert(a); err != nil {
+		slog.Error("[SLO] failed to send alert", "rule", a.RuleName, "status", a.Status, "error", err)
+	}
+}
+
+// alertRuleID is stable across evaluations so PagerDuty and OpsGenie
+// deduplicate repeated firings and match the resolve to the trigger.
+func alertRuleID(sloID uuid.UUID, rule string) string {
+	return "slo:" + sloID.String() + ":" + rule
+}
+
+func newAlert(s *models.SLO, st *Status, rs RuleStatus, now time.Time) notifications.Alert {
+	resource, kind := s.Target.Service, "Service"
+	if resource == "" {
+		resource, kind = s.Target.Workload, "Workload"
+	}
+	details := map[string]interface{}{
+		"slo_id":          s.ID.String(),
+		"objective":       s.Objective,
+		"window_days":     s.WindowDays,
+		"long_window":     rs.LongWindow,
+		"short_window":    rs.ShortWindow,
+		"threshold":       rs.Threshold,
+		"long_burn_rate":  *rs.LongBurnRate,
+		"short_burn_rate": *rs.ShortBurnRate,
+	}
+	if st.ErrorBudget.Remaining != nil {
+		details["budget_remaining"] = *st.ErrorBudget.Remaining
+	}
+	return notifications.Alert{
+		ID:       uuid.New().String(),
+		RuleID:   alertRuleID(s.ID, rs.Name),
+		RuleName: fmt.Sprintf("SLO %s: %s", s.Name, rs.Name),
+		Severity: rs.Severity,
+		Status:   alertStatusFiring,
+		Message: fmt.Sprintf("Error budget burning at %.1fx over %s (threshold %.1fx)",
+			*rs.LongBurnRate, rs.LongWindow, rs.Threshold),
+		Details:      details,
+		Cluster:      s.Target.Cluster,
+		Namespace:    s.Target.Namespace,
+		Resource:     resource,
+		ResourceKind: kind,
+		FiredAt:      now,
+	}
+}
+
+func resolved(a notifications.Alert, now time.Time, message string) notifications.Alert {
+	a.ID = uuid.New().String()
+	a.Status = alertStatusResolved
+	a.Message = message
+	a.FiredAt = now
+	return a
+}
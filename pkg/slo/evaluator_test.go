@@ -0,0 +1,121 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/notifications"
+)
+
+type staticLister struct{ slos []models.SLO }
+
+func (s *staticLister) ListSLOs(context.Context, string, int, int) ([]models.SLO, error) {
+	return s.slos, nil
+}
+
+type recordingSender struct {
+	mu     sync.Mutex
+	alerts []notifications.Alert
+}
+
+func (r *recordingSender) SendAlert(a notifications.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func (r *recordingSender) take() []notifications.Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.alerts
+	r.alerts = nil
+	return out
+}
+
+func newTestEvaluator(slo *models.SLO, q Querier) (*Evaluator, *staticLister, *recordingSender) {
+	lister := &staticLister{slos: []models.SLO{*slo}}
+	sender := &recordingSender{}
+	return NewEvaluator(lister, q, sender, "kubestellar"), lister, sender
+}
+
+func TestEvaluator_FiresOnceAndResolves(t *testing.T) {
+	s := testSLO()
+	s.AlertsEnabled = true
+	q := &fakeQuerier{total: 100, good: map[string]float64{"1h": 0.98, "5m": 0.98}}
+	e, _, sender := newTestEvaluator(s, q)
+	ctx := context.Background()
+
+	e.evaluateAll(ctx, time.Now())
+	alerts := sender.take()
+	require.Len(t, alerts, 1)
+	a := alerts[0]
+	assert.Equal(t, alertStatusFiring, a.Status)
+	assert.Equal(t, notifications.SeverityCritical, a.Severity)
+	assert.Equal(t, "slo:"+s.ID.String()+":fast-burn", a.RuleID)
+	assert.Equal(t, "prod", a.Cluster)
+	assert.Equal(t, "checkout", a.Resource)
+	assert.Equal(t, "Service", a.ResourceKind)
+
+	e.evaluateAll(ctx, time.Now())
+	assert.Empty(t, sender.take(), "a rule that keeps firing is not re-sent")
+
+	q.good["5m"] = 1
+	e.evaluateAll(ctx, time.Now())
+	alerts = sender.take()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alertStatusResolved, alerts[0].Status)
+	assert.Equal(t, a.RuleID, alerts[0].RuleID)
+}
+
+func TestEvaluator_OutageKeepsState(t *testing.T) {
+	s := testSLO()
+	s.AlertsEnabled = true
+	q := &fakeQuerier{total: 100, good: map[string]float64{"1h": 0.98, "5m": 0.98}}
+	e, _, sender := newTestEvaluator(s, q)
+	ctx := context.Background()
+
+	e.evaluateAll(ctx, time.Now())
+	require.Len(t, sender.take(), 1)
+
+	q.err = errors.New("prometheus unreachable")
+	e.evaluateAll(ctx, time.Now())
+	assert.Empty(t, sender.take(), "a failed evaluation neither resolves nor re-fires")
+	assert.Len(t, e.firing, 1)
+}
+
+func TestEvaluator_RemovedSLOResolves(t *testing.T) {
+	s := testSLO()
+	s.AlertsEnabled = true
+	q := &fakeQuerier{total: 100, good: map[string]float64{"1h": 0.98, "5m": 0.98}}
+	e, lister, sender := newTestEvaluator(s, q)
+	ctx := context.Background()
+
+	e.evaluateAll(ctx, time.Now())
+	require.Len(t, sender.take(), 1)
+
+	lister.slos[0].AlertsEnabled = false
+	e.evaluateAll(ctx, time.Now())
+	alerts := sender.take()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alertStatusResolved, alerts[0].Status)
+	assert.Empty(t, e.firing)
+
+	q.queries = nil
+	e.evaluateAll(ctx, time.Now())
+	assert.Empty(t, q.queries, "SLOs without alerts are not evaluated")
+}
+
+func TestNewEvaluator_Interval(t *testing.T) {
+	t.Setenv("SLO_EVAL_INTERVAL", "30s")
+	assert.Equal(t, 30*time.Second, NewEvaluator(nil, nil, nil, "").interval)
+	t.Setenv("SLO_EVAL_INTERVAL", "soon")
+	assert.Equal(t, defaultEvalInterval, NewEvaluator(nil, nil, nil, "").interval)
+}
@@ -0,0 +1,172 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	defaultPrometheusNamespace = "monitoring"
+	defaultPrometheusService   = "prometheus"
+	defaultPrometheusPort      = "9090"
+
+	// prometheusQueryTimeout bounds one instant query.
+	prometheusQueryTimeout = 10 * time.Second
+	// maxPromQLQueryLength matches the limit kc-agent applies to proxied
+	// queries so custom indicators cannot issue arbitrarily large queries.
+	maxPromQLQueryLength = 2048
+	// prometheusMaxResponseBytes caps the response body. SLO queries
+	// aggregate to a single sample, so anything larger is a misconfiguration.
+	prometheusMaxResponseBytes = 1 << 20
+)
+
+// restConfigSource is the subset of k8s.MultiClusterClient the querier needs.
+type restConfigSource interface {
+	GetRestConfig(contextName string) (*rest.Config, error)
+}
+
+// PrometheusQuerier runs queries against the Prometheus Service of each
+// cluster through the cluster API server's service proxy, so the console
+// needs no direct network path to Prometheus.
+type PrometheusQuerier struct {
+	configs   restConfigSource
+	namespace string
+	service   string
+	port      string
+
+	mu      sync.Mutex
+	clients map[string]*http.Client // keyed by cluster + API server host
+}
+
+// NewPrometheusQuerier returns a querier for the Service named by
+// SLO_PROMETHEUS_NAMESPACE, SLO_PROMETHEUS_SERVICE and SLO_PROMETHEUS_PORT
+// (default monitoring/prometheus:9090).
+func NewPrometheusQuerier(configs restConfigSource) *PrometheusQuerier {
+	return &PrometheusQuerier{
+		configs:   configs,
+		namespace: envOrDefault("SLO_PROMETHEUS_NAMESPACE", defaultPrometheusNamespace),
+		service:   envOrDefault("SLO_PROMETHEUS_SERVICE", defaultPrometheusService),
+		port:      envOrDefault("SLO_PROMETHEUS_PORT", defaultPrometheusPort),
+		clients:   make(map[string]*http.Client),
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// promResponse is the envelope of the Prometheus HTTP API.
+type promResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Query runs an instant query and returns the value of the first sample of a
+// vector result, or the value of a scalar result.
+func (p *PrometheusQuerier) Query(ctx context.Context, cluster, promql string) (float64, bool, error) {
+	if len(promql) > maxPromQLQueryLength {
+		return 0, false, errors.New("query exceeds maximum allowed length")
+	}
+	config, err := p.configs.GetRestConfig(cluster)
+	if err != nil {
+		return 0, false, fmt.Errorf("cluster config: %w", err)
+	}
+	client, err := p.client(cluster, config)
+	if err != nil {
+		return 0, false, fmt.Errorf("prometheus transport: %w", err)
+	}
+
+	proxyPath := fmt.Sprintf("/api/v1/namespaces/%s/services/%s:%s/proxy/api/v1/query",
+		url.PathEscape(p.namespace), url.PathEscape(p.service), url.PathEscape(p.port))
+	params := url.Values{"query": {promql}}
+
+	ctx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.Host+proxyPath+"?"+params.Encode(), nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("prometheus query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var pr promResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, prometheusMaxResponseBytes)).Decode(&pr); err != nil {
+		return 0, false, fmt.Errorf("prometheus returned status %d", resp.StatusCode)
+	}
+	if pr.Status != "success" {
+		return 0, false, fmt.Errorf("prometheus query failed: %s", pr.Error)
+	}
+	return parseResult(pr.Data.ResultType, pr.Data.Result)
+}
+
+// parseResult extracts the first sample value from a vector or scalar result.
+func parseResult(resultType string, raw json.RawMessage) (float64, bool, error) {
+	var sample [2]any
+	switch resultType {
+	case "vector":
+		var vec []struct {
+			Value [2]any `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &vec); err != nil {
+			return 0, false, fmt.Errorf("decode vector: %w", err)
+		}
+		if len(vec) == 0 {
+			return 0, false, nil
+		}
+		sample = vec[0].Value
+	case "scalar":
+		if err := json.Unmarshal(raw, &sample); err != nil {
+			return 0, false, fmt.Errorf("decode scalar: %w", err)
+		}
+	default:
+		return 0, false, fmt.Errorf("unsupported result type %q", resultType)
+	}
+	s, ok := sample[1].(string)
+	if !ok {
+		return 0, false, errors.New("sample value is not a string")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parse sample value: %w", err)
+	}
+	return v, true, nil
+}
+
+// client returns a cached HTTP client for the cluster's API server so each
+// evaluation does not repeat the TLS handshake.
+func (p *PrometheusQuerier) client(cluster string, config *rest.Config) (*http.Client, error) {
+	key := cluster + "|" + config.Host
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.clients[key]; ok {
+		return c, nil
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, err
+	}
+	c := &http.Client{Transport: transport}
+	p.clients[key] = c
+	return c, nil
+}
@@ -0,0 +1,78 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+type staticConfigs struct {
+	host string
+	err  error
+}
+
+func (s staticConfigs) GetRestConfig(string) (*rest.Config, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &rest.Config{Host: s.host}, nil
+}
+
+func TestPrometheusQuerier_Query(t *testing.T) {
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.Query().Get("query")
+		switch gotQuery {
+		case "empty":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		case "scalar":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"0.5"]}}`))
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+		default:
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"42.5"]}]}}`))
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("SLO_PROMETHEUS_NAMESPACE", "observability")
+	q := NewPrometheusQuerier(staticConfigs{host: srv.URL})
+	ctx := context.Background()
+
+	v, ok, err := q.Query(ctx, "prod", `sum(rate(x[5m]))`)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 42.5, v)
+	assert.Equal(t, "/api/v1/namespaces/observability/services/prometheus:9090/proxy/api/v1/query", gotPath)
+	assert.Equal(t, `sum(rate(x[5m]))`, gotQuery)
+
+	_, ok, err = q.Query(ctx, "prod", "empty")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	v, ok, err = q.Query(ctx, "prod", "scalar")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, v)
+
+	_, _, err = q.Query(ctx, "prod", "bad")
+	assert.ErrorContains(t, err, "parse error")
+
+	_, _, err = q.Query(ctx, "prod", strings.Repeat("x", maxPromQLQueryLength+1))
+	assert.ErrorContains(t, err, "maximum allowed length")
+}
+
+func TestPrometheusQuerier_UnknownCluster(t *testing.T) {
+	q := NewPrometheusQuerier(staticConfigs{err: errors.New("context not found")})
+	_, _, err := q.Query(context.Background(), "missing", "up")
+	assert.ErrorContains(t, err, "context not found")
+}
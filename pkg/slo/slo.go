@@ -0,0 +1,259 @@
+// Package slo evaluates service level objectives against Prometheus: it
+// renders the good/total event queries of an SLO, computes its error budget
+// and multi-window burn rates, and turns sustained burn into alerts for the
+// notification subsystem.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/kubestellar/console/pkg/notifications"
+)
+
+const (
+	// DefaultAvailabilityMetric is the request counter availability SLOs
+	// count when no metric is configured. Its code label carries the HTTP
+	// status.
+	DefaultAvailabilityMetric = "http_requests_total"
+	// DefaultLatencyMetric is the request duration histogram latency SLOs
+	// read when no metric is configured.
+	DefaultLatencyMetric = "http_request_duration_seconds"
+
+	// SelectorPlaceholder and WindowPlaceholder are substituted into custom
+	// indicator queries.
+	SelectorPlaceholder = "$selector"
+	WindowPlaceholder   = "$window"
+)
+
+// Querier runs an instant PromQL query against one cluster's Prometheus. ok
+// is false when the query matched no series.
+type Querier interface {
+	Query(ctx context.Context, cluster, promql string) (value float64, ok bool, err error)
+}
+
+// BurnRateRule alerts when the error budget burns faster than a sustainable
+// rate over both a long and a short window, as in the multi-window,
+// multi-burn-rate alerts of the Google SRE workbook. The short window stops
+// the alert soon after the burn ends.
+type BurnRateRule struct {
+	Name        string
+	Severity    notifications.AlertSeverity
+	LongWindow  time.Duration
+	ShortWindow time.Duration
+	// BudgetFraction is the share of the whole error budget that may burn
+	// within LongWindow before the rule fires.
+	BudgetFraction float64
+}
+
+// Threshold returns the burn rate at which r fires for an SLO window. For a
+// 30 day window the default rules fire at 14.4x and 6x.
+func (r BurnRateRule) Threshold(window time.Duration) float64 {
+	return r.BudgetFraction * window.Hours() / r.LongWindow.Hours()
+}
+
+// DefaultRules are the burn-rate alerts evaluated for every SLO: 2% of the
+// budget in an hour pages, 5% in six hours warns.
+var DefaultRules = []BurnRateRule{
+	{Name: "fast-burn", Severity: notifications.SeverityCritical, LongWindow: time.Hour, ShortWindow: 5 * time.Minute, BudgetFraction: 0.02},
+	{Name: "slow-burn", Severity: notifications.SeverityWarning, LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, BudgetFraction: 0.05},
+}
+
+// Window returns the compliance window of s.
+func Window(s *models.SLO) time.Duration {
+	return time.Duration(s.WindowDays) * 24 * time.Hour
+}
+
+// Selector returns the PromQL label matchers selecting the target's series.
+func Selector(t models.SLOTarget) string {
+	parts := []string{"namespace=" + strconv.Quote(t.Namespace)}
+	if t.Service != "" {
+		parts = append(parts, "service="+strconv.Quote(t.Service))
+	}
+	if t.Workload != "" {
+		parts = append(parts, "pod=~"+strconv.Quote(regexp.QuoteMeta(t.Workload)+"-.*"))
+	}
+	return strings.Join(parts, ",")
+}
+
+// QueryTemplates returns the good and total event queries of s with the
+// $selector and $window placeholders still in place.
+func QueryTemplates(s *models.SLO) (good, total string) {
+	ind := s.Indicator
+	if ind.GoodQuery != "" && ind.TotalQuery != "" {
+		return ind.GoodQuery, ind.TotalQuery
+	}
+	switch s.Kind {
+	case models.SLOKindLatency:
+		metric := ind.Metric
+		if metric == "" {
+			metric = DefaultLatencyMetric
+		}
+		le := strconv.Quote(strconv.FormatFloat(ind.ThresholdSeconds, 'f', -1, 64))
+		good = fmt.Sprintf("sum(rate(%s_bucket{$selector,le=%s}[$window]))", metric, le)
+		total = fmt.Sprintf("sum(rate(%s_count{$selector}[$window]))", metric)
+	default:
+		metric := ind.Metric
+		if metric == "" {
+			metric = DefaultAvailabilityMetric
+		}
+		good = fmt.Sprintf(`sum(rate(%s{$selector,code!~"5.."}[$window]))`, metric)
+		total = fmt.Sprintf("sum(rate(%s{$selector}[$window]))", metric)
+	}
+	return good, total
+}
+
+// Render substitutes the target selector and window into a query template.
+func Render(tmpl string, t models.SLOTarget, window time.Duration) string {
+	return strings.NewReplacer(SelectorPlaceholder, Selector(t), WindowPlaceholder, promDuration(window)).Replace(tmpl)
+}
+
+// promDuration formats d as a PromQL range in the largest whole unit.
+func promDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// ErrorBudget is how much of an SLO's allowed error ratio the compliance
+// window has used. Consumed and Remaining are fractions of the budget;
+// Remaining goes negative once the objective is missed.
+type ErrorBudget struct {
+	Allowed   float64  `json:"allowed"`
+	Consumed  *float64 `json:"consumed,omitempty"`
+	Remaining *float64 `json:"remaining,omitempty"`
+}
+
+// RuleStatus is the state of one burn-rate rule. Burn rates are nil when the
+// window saw no traffic.
+type RuleStatus struct {
+	Name          string                      `json:"name"`
+	Severity      notifications.AlertSeverity `json:"severity"`
+	LongWindow    string                      `json:"long_window"`
+	ShortWindow   string                      `json:"short_window"`
+	Threshold     float64                     `json:"threshold"`
+	LongBurnRate  *float64                    `json:"long_burn_rate,omitempty"`
+	ShortBurnRate *float64                    `json:"short_burn_rate,omitempty"`
+	Firing        bool                        `json:"firing"`
+}
+
+// Status is the evaluated error-budget state of one SLO.
+type Status struct {
+	SLOID      uuid.UUID `json:"slo_id"`
+	Name       string    `json:"name"`
+	Objective  float64   `json:"objective"`
+	WindowDays int       `json:"window_days"`
+	// SLI is the good event ratio over the compliance window.
+	SLI         *float64     `json:"sli,omitempty"`
+	ErrorBudget ErrorBudget  `json:"error_budget"`
+	Rules       []RuleStatus `json:"rules"`
+	// NoData is set when the compliance window saw no events at all.
+	NoData      bool      `json:"no_data"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+}
+
+// Evaluate queries the error ratio of s over its compliance window and every
+// rule window and returns the resulting budget and burn-rate state.
+func Evaluate(ctx context.Context, q Querier, s *models.SLO, rules []BurnRateRule, now time.Time) (*Status, error) {
+	window := Window(s)
+	allowed := 1 - s.Objective
+	good, total := QueryTemplates(s)
+
+	ratios := make(map[time.Duration]*float64)
+	ratioFor := func(w time.Duration) (*float64, error) {
+		if r, ok := ratios[w]; ok {
+			return r, nil
+		}
+		r, err := errorRatio(ctx, q, s, good, total, w)
+		if err != nil {
+			return nil, err
+		}
+		ratios[w] = r
+		return r, nil
+	}
+
+	st := &Status{
+		SLOID:       s.ID,
+		Name:        s.Name,
+		Objective:   s.Objective,
+		WindowDays:  s.WindowDays,
+		ErrorBudget: ErrorBudget{Allowed: allowed},
+		Rules:       make([]RuleStatus, 0, len(rules)),
+		EvaluatedAt: now,
+	}
+	overall, err := ratioFor(window)
+	if err != nil {
+		return nil, err
+	}
+	if overall == nil {
+		st.NoData = true
+	} else {
+		sli := 1 - *overall
+		consumed := *overall / allowed
+		remaining := 1 - consumed
+		st.SLI, st.ErrorBudget.Consumed, st.ErrorBudget.Remaining = &sli, &consumed, &remaining
+	}
+
+	for _, r := range rules {
+		rs := RuleStatus{
+			Name:        r.Name,
+			Severity:    r.Severity,
+			LongWindow:  promDuration(r.LongWindow),
+			ShortWindow: promDuration(r.ShortWindow),
+			Threshold:   r.Threshold(window),
+		}
+		long, err := ratioFor(r.LongWindow)
+		if err != nil {
+			return nil, err
+		}
+		short, err := ratioFor(r.ShortWindow)
+		if err != nil {
+			return nil, err
+		}
+		rs.LongBurnRate = burnRate(long, allowed)
+		rs.ShortBurnRate = burnRate(short, allowed)
+		rs.Firing = rs.LongBurnRate != nil && rs.ShortBurnRate != nil &&
+			*rs.LongBurnRate > rs.Threshold && *rs.ShortBurnRate > rs.Threshold
+		st.Rules = append(st.Rules, rs)
+	}
+	return st, nil
+}
+
+// errorRatio returns the bad event ratio of s over window, or nil when the
+// window saw no events.
+func errorRatio(ctx context.Context, q Querier, s *models.SLO, goodTmpl, totalTmpl string, window time.Duration) (*float64, error) {
+	total, ok, err := q.Query(ctx, s.Target.Cluster, Render(totalTmpl, s.Target, window))
+	if err != nil {
+		return nil, fmt.Errorf("total events over %s: %w", promDuration(window), err)
+	}
+	if !ok || total <= 0 {
+		return nil, nil
+	}
+	good, _, err := q.Query(ctx, s.Target.Cluster, Render(goodTmpl, s.Target, window))
+	if err != nil {
+		return nil, fmt.Errorf("good events over %s: %w", promDuration(window), err)
+	}
+	ratio := min(max(1-good/total, 0), 1)
+	return &ratio, nil
+}
+
+func burnRate(ratio *float64, allowed float64) *float64 {
+	if ratio == nil || allowed <= 0 {
+		return nil
+	}
+	b := *ratio / allowed
+	return &b
+}
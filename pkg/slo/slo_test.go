@@ -0,0 +1,128 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// fakeQuerier answers total queries with total and good queries with the
+// good ratio configured for the query's range.
+type fakeQuerier struct {
+	total   float64
+	good    map[string]float64 // range -> good/total ratio
+	err     error
+	queries []string
+}
+
+func (f *fakeQuerier) Query(_ context.Context, _ string, promql string) (float64, bool, error) {
+	f.queries = append(f.queries, promql)
+	if f.err != nil {
+		return 0, false, f.err
+	}
+	if f.total == 0 {
+		return 0, false, nil
+	}
+	if !strings.Contains(promql, `code!~"5.."`) {
+		return f.total, true, nil
+	}
+	for rng, ratio := range f.good {
+		if strings.Contains(promql, "["+rng+"]") {
+			return ratio * f.total, true, nil
+		}
+	}
+	return f.total, true, nil
+}
+
+func testSLO() *models.SLO {
+	return &models.SLO{
+		ID:         uuid.New(),
+		Name:       "checkout availability",
+		Kind:       models.SLOKindAvailability,
+		Target:     models.SLOTarget{Cluster: "prod", Namespace: "shop", Service: "checkout"},
+		Objective:  0.999,
+		WindowDays: 30,
+	}
+}
+
+func TestRuleThreshold(t *testing.T) {
+	month := 30 * 24 * time.Hour
+	assert.InDelta(t, 14.4, DefaultRules[0].Threshold(month), 1e-9)
+	assert.InDelta(t, 6.0, DefaultRules[1].Threshold(month), 1e-9)
+	assert.InDelta(t, 3.36, DefaultRules[0].Threshold(7*24*time.Hour), 1e-9)
+}
+
+func TestQueryTemplates(t *testing.T) {
+	s := testSLO()
+	good, total := QueryTemplates(s)
+	assert.Equal(t, `sum(rate(http_requests_total{namespace="shop",service="checkout",code!~"5.."}[1h]))`, Render(good, s.Target, time.Hour))
+	assert.Equal(t, `sum(rate(http_requests_total{namespace="shop",service="checkout"}[30d]))`, Render(total, s.Target, Window(s)))
+
+	s.Kind = models.SLOKindLatency
+	s.Target = models.SLOTarget{Cluster: "prod", Namespace: "llm", Workload: "vllm.llama"}
+	s.Indicator = models.SLOIndicator{Metric: "vllm:e2e_request_latency_seconds", ThresholdSeconds: 2.5}
+	good, total = QueryTemplates(s)
+	assert.Equal(t, `sum(rate(vllm:e2e_request_latency_seconds_bucket{namespace="llm",pod=~"vllm\\.llama-.*",le="2.5"}[5m]))`, Render(good, s.Target, 5*time.Minute))
+	assert.Equal(t, `sum(rate(vllm:e2e_request_latency_seconds_count{namespace="llm",pod=~"vllm\\.llama-.*"}[6h]))`, Render(total, s.Target, 6*time.Hour))
+
+	s.Indicator = models.SLOIndicator{GoodQuery: "sum(rate(ok{$selector}[$window]))", TotalQuery: "sum(rate(all{$selector}[$window]))"}
+	good, _ = QueryTemplates(s)
+	assert.Equal(t, `sum(rate(ok{namespace="llm",pod=~"vllm\\.llama-.*"}[30m]))`, Render(good, s.Target, 30*time.Minute))
+}
+
+func TestEvaluate_BudgetAndBurnRates(t *testing.T) {
+	// 0.05% errors over 30d uses half the 0.1% budget; 2% errors in the
+	// last hour and 5 minutes burns at 20x and fires the fast-burn rule.
+	q := &fakeQuerier{total: 100, good: map[string]float64{
+		"30d": 0.9995, "1h": 0.98, "5m": 0.98, "6h": 0.999, "30m": 0.995,
+	}}
+	st, err := Evaluate(context.Background(), q, testSLO(), DefaultRules, time.Now())
+	require.NoError(t, err)
+	assert.False(t, st.NoData)
+	require.NotNil(t, st.SLI)
+	assert.InDelta(t, 0.9995, *st.SLI, 1e-9)
+	assert.InDelta(t, 0.001, st.ErrorBudget.Allowed, 1e-9)
+	assert.InDelta(t, 0.5, *st.ErrorBudget.Consumed, 1e-6)
+	assert.InDelta(t, 0.5, *st.ErrorBudget.Remaining, 1e-6)
+
+	require.Len(t, st.Rules, 2)
+	fast, slow := st.Rules[0], st.Rules[1]
+	assert.Equal(t, "fast-burn", fast.Name)
+	assert.InDelta(t, 20, *fast.LongBurnRate, 1e-6)
+	assert.True(t, fast.Firing)
+	assert.InDelta(t, 1, *slow.LongBurnRate, 1e-6)
+	assert.False(t, slow.Firing, "the 6h window is within budget")
+	assert.Len(t, q.queries, 10, "each window is queried once")
+}
+
+func TestEvaluate_ShortWindowRecovered(t *testing.T) {
+	q := &fakeQuerier{total: 100, good: map[string]float64{"1h": 0.98, "5m": 1}}
+	st, err := Evaluate(context.Background(), q, testSLO(), DefaultRules, time.Now())
+	require.NoError(t, err)
+	assert.False(t, st.Rules[0].Firing, "a recovered short window stops the alert")
+}
+
+func TestEvaluate_NoTraffic(t *testing.T) {
+	st, err := Evaluate(context.Background(), &fakeQuerier{}, testSLO(), DefaultRules, time.Now())
+	require.NoError(t, err)
+	assert.True(t, st.NoData)
+	assert.Nil(t, st.SLI)
+	assert.Nil(t, st.ErrorBudget.Remaining)
+	for _, r := range st.Rules {
+		assert.Nil(t, r.LongBurnRate)
+		assert.False(t, r.Firing)
+	}
+}
+
+func TestEvaluate_QueryError(t *testing.T) {
+	_, err := Evaluate(context.Background(), &fakeQuerier{err: errors.New("unreachable")}, testSLO(), DefaultRules, time.Now())
+	assert.ErrorContains(t, err, "unreachable")
+}
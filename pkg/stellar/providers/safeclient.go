@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"time"
+
+	"github.com/kubestellar/console/pkg/egress"
 )
 
 // newSafeHTTPClient returns an *http.Client whose Transport resolves DNS at
@@ -17,7 +19,7 @@ func newSafeHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{
 		Timeout: timeout,
 		Transport: &http.Transport{
-			DialContext: safeDialContext,
+			DialContext: egress.Dial(safeDialContext),
 		},
 	}
 }
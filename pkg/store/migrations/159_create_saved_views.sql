@@ -0,0 +1,14 @@
+-- Saved resource views: named cross-cluster queries shared within a console
+-- project. The query column holds the JSON-encoded models.SavedViewQuery so
+-- new query fields do not need a schema change.
+CREATE TABLE IF NOT EXISTS saved_views (
+    id TEXT PRIMARY KEY,
+    project TEXT NOT NULL,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME,
+    UNIQUE(project, name)
+);
+CREATE INDEX IF NOT EXISTS idx_saved_views_project ON saved_views(project, name);
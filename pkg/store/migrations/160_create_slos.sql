@@ -0,0 +1,20 @@
+-- Service level objectives bound to a service or inference workload. The
+-- target and indicator columns hold the JSON-encoded models.SLOTarget and
+-- models.SLOIndicator so new selector fields do not need a schema change.
+CREATE TABLE IF NOT EXISTS slos (
+    id TEXT PRIMARY KEY,
+    project TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    target TEXT NOT NULL,
+    indicator TEXT NOT NULL,
+    objective REAL NOT NULL,
+    window_days INTEGER NOT NULL,
+    alerts_enabled INTEGER NOT NULL DEFAULT 1,
+    created_by TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME,
+    UNIQUE(project, name)
+);
+CREATE INDEX IF NOT EXISTS idx_slos_project ON slos(project, name);
@@ -0,0 +1,12 @@
+-- Stars, notes and labels on benchmark runs, keyed by the report's Run.UID.
+-- Reports themselves live in Google Drive; only the annotations are stored.
+-- The labels column holds a JSON array of strings.
+CREATE TABLE IF NOT EXISTS benchmark_annotations (
+    report_uid TEXT PRIMARY KEY,
+    starred INTEGER NOT NULL DEFAULT 0,
+    note TEXT NOT NULL DEFAULT '',
+    labels TEXT NOT NULL DEFAULT '[]',
+    updated_by TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_benchmark_annotations_starred ON benchmark_annotations(starred);
@@ -0,0 +1,3 @@
+-- Dashboard version for optimistic concurrency. Every update bumps it, and
+-- writers that send the version they read are rejected once it has moved.
+ALTER TABLE dashboards ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Immutable read-only dashboard snapshots shared through signed public URLs.
+-- The payload column holds the JSON dashboard name, layout and cards with the
+-- data they showed; rows are never updated, only purged once expired.
+CREATE TABLE IF NOT EXISTS dashboard_snapshots (
+    id TEXT PRIMARY KEY,
+    dashboard_id TEXT NOT NULL REFERENCES dashboards(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_dashboard_snapshots_user ON dashboard_snapshots(user_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_dashboard_snapshots_expires ON dashboard_snapshots(expires_at);
@@ -0,0 +1,7 @@
+-- Per-cluster IANA timezone. Deployment windows are evaluated in the
+-- cluster's local time; clusters without a row use UTC.
+CREATE TABLE IF NOT EXISTS cluster_timezones (
+    cluster TEXT PRIMARY KEY,
+    timezone TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- Per-project requirements for the change metadata (description, ticket
+-- URL, approver) WorkloadDeployments carry. Projects without a row require
+-- nothing.
+CREATE TABLE IF NOT EXISTS change_policies (
+    project TEXT PRIMARY KEY,
+    require_description INTEGER NOT NULL DEFAULT 0,
+    require_ticket INTEGER NOT NULL DEFAULT 0,
+    require_approver INTEGER NOT NULL DEFAULT 0,
+    ticket_url_prefix TEXT NOT NULL DEFAULT '',
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
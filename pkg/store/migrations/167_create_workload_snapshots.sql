@@ -0,0 +1,20 @@
+-- Captured live state of a workload and the ConfigMaps/Secrets it
+-- references. objects holds the manifests as a JSON array; contents and
+-- missing are JSON arrays of Kind/name.
+CREATE TABLE IF NOT EXISTS workload_snapshots (
+    id TEXT PRIMARY KEY,
+    cluster TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    namespace TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    redacted INTEGER NOT NULL DEFAULT 1,
+    contents TEXT NOT NULL DEFAULT '[]',
+    missing TEXT NOT NULL DEFAULT '[]',
+    objects TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workload_snapshots_workload
+    ON workload_snapshots (cluster, kind, namespace, name, created_at);
@@ -0,0 +1,12 @@
+-- Recent WebSocket hub broadcasts, replayed to clients that reconnect after
+-- missing them. seq is assigned by the hub. user_id is the nil UUID for
+-- broadcasts to every client; message is the encoded message as sent.
+CREATE TABLE IF NOT EXISTS hub_events (
+    seq INTEGER PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_hub_events_created_at ON hub_events (created_at);
@@ -0,0 +1,10 @@
+-- Single-use codes an admin issues to pair a kc-agent with the relay. Only
+-- the SHA-256 of a code is kept, and each code is bound to the agent ID it
+-- may be traded for.
+CREATE TABLE IF NOT EXISTS agent_pairing_codes (
+    code_hash TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_agent_pairing_codes_expires ON agent_pairing_codes(expires_at);
@@ -8,12 +8,24 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-func TestRun_CreatesTrackingTable(t *testing.T) {
+// openLegacyDB returns an in-memory database holding the legacy tables that
+// file-based migrations alter. In production the inline migrations in
+// sqlite_migrations.go create them before Run.
+func openLegacyDB(t *testing.T) *sql.DB {
+	t.Helper()
 	db, err := sql.Open("sqlite", ":memory:")
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer db.Close()
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`CREATE TABLE dashboards (id TEXT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRun_CreatesTrackingTable(t *testing.T) {
+	db := openLegacyDB(t)
 
 	ctx := context.Background()
 	if err := Run(ctx, db); err != nil {
@@ -22,7 +34,7 @@ func TestRun_CreatesTrackingTable(t *testing.T) {
 
 	// Verify schema_migrations table exists
 	var count int
-	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
 	if err != nil {
 		t.Fatalf("schema_migrations table not created: %v", err)
 	}
@@ -32,11 +44,7 @@ func TestRun_CreatesTrackingTable(t *testing.T) {
 }
 
 func TestRun_Idempotent(t *testing.T) {
-	db, err := sql.Open("sqlite", ":memory:")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer db.Close()
+	db := openLegacyDB(t)
 
 	ctx := context.Background()
 	// Run twice — should not error
@@ -48,12 +56,15 @@ func TestRun_Idempotent(t *testing.T) {
 	}
 
 	var count int
-	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	// Should have exactly one record per embedded migration, not duplicated
+	files, err := migrationFS.ReadDir(".")
 	if err != nil {
 		t.Fatal(err)
 	}
-	// Should have exactly 1 migration (the seed), not duplicated
-	if count != 1 {
-		t.Fatalf("expected 1 migration record, got %d", count)
+	if count != len(files) {
+		t.Fatalf("expected %d migration records, got %d", len(files), count)
 	}
 }
@@ -7,10 +7,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/kubestellar/console/pkg/fileutil"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
@@ -34,6 +36,11 @@ type PersistenceConfig struct {
 	// Namespace is where console CRs are stored (default: kubestellar-console)
 	Namespace string `json:"namespace"`
 
+	// Namespaces are further namespaces holding console CRs, each for one
+	// project or team. Namespace stays the default and is open to every
+	// user.
+	Namespaces []PersistenceNamespace `json:"namespaces,omitempty"`
+
 	// SyncMode controls how CRs are synced
 	// - "primary-only": Only sync to primary cluster
 	// - "active-passive": Sync to primary, failover to secondary if unavailable
@@ -43,6 +50,21 @@ type PersistenceConfig struct {
 	LastModified time.Time `json:"lastModified,omitempty"`
 }
 
+// PersistenceNamespace is an additional namespace for console CRs.
+type PersistenceNamespace struct {
+	// Namespace holds the CRs.
+	Namespace string `json:"namespace"`
+
+	// Project is the console project whose change policy applies to the
+	// WorkloadDeployments in Namespace. Empty uses the console's project.
+	Project string `json:"project,omitempty"`
+
+	// Teams are the names of the console teams whose members may see and
+	// use the CRs in Namespace. Admins always may; with no teams, only
+	// they may.
+	Teams []string `json:"teams,omitempty"`
+}
+
 // PersistenceStatus provides the current status of persistence
 type PersistenceStatus struct {
 	// Active indicates whether persistence is currently working
@@ -115,6 +137,23 @@ type PersistenceStore struct {
 
 	// Client factory for creating dynamic clients
 	getClient func(clusterName string) (dynamic.Interface, *rest.Config, error)
+
+	// overrides applies flag/environment overrides on top of the file
+	// config. It is applied on every read and never saved, so p.config
+	// always mirrors what is on disk.
+	overrides func(*PersistenceConfig)
+
+	// lastSync is when RecordSync was last called; guarded by mu.
+	lastSync *time.Time
+}
+
+// DefaultPersistenceConfig returns the config used when no file exists.
+func DefaultPersistenceConfig() PersistenceConfig {
+	return PersistenceConfig{
+		Enabled:   false,
+		Namespace: DefaultNamespace,
+		SyncMode:  "primary-only",
+	}
 }
 
 // NewPersistenceStore creates a new PersistenceStore
@@ -150,6 +189,26 @@ func (p *PersistenceStore) SetClientFactory(factory func(clusterName string) (dy
 	p.getClient = factory
 }
 
+// SetConfigOverrides sets a function that adjusts the effective config, for
+// example from environment variables. It must be called before the store is
+// shared between goroutines.
+func (p *PersistenceStore) SetConfigOverrides(fn func(*PersistenceConfig)) {
+	p.overrides = fn
+}
+
+// effectiveLocked returns the file config with overrides applied. p.mu must
+// be held.
+func (p *PersistenceStore) effectiveLocked() PersistenceConfig {
+	config := *p.config
+	if p.overrides != nil {
+		p.overrides(&config)
+	}
+	if config.Namespace == "" {
+		config.Namespace = DefaultNamespace
+	}
+	return config
+}
+
 // Load loads the persistence config from disk.
 //
 // #6616: the previous implementation held p.mu (exclusive write lock) for
@@ -165,11 +224,8 @@ func (p *PersistenceStore) Load() error {
 
 	data, err := os.ReadFile(p.configPath)
 	if os.IsNotExist(err) {
-		loaded = &PersistenceConfig{
-			Enabled:   false,
-			Namespace: DefaultNamespace,
-			SyncMode:  "primary-only",
-		}
+		defaults := DefaultPersistenceConfig()
+		loaded = &defaults
 	} else if err != nil {
 		return fmt.Errorf("failed to read persistence config: %w", err)
 	} else {
@@ -181,11 +237,8 @@ func (p *PersistenceStore) Load() error {
 		// to nil. Reset to defaults when that happens so the subsequent
 		// Namespace dereference cannot panic.
 		if loaded == nil {
-			loaded = &PersistenceConfig{
-				Enabled:   false,
-				Namespace: DefaultNamespace,
-				SyncMode:  "primary-only",
-			}
+			defaults := DefaultPersistenceConfig()
+			loaded = &defaults
 		}
 		if loaded.Namespace == "" {
 			loaded.Namespace = DefaultNamespace
@@ -244,11 +297,11 @@ func (p *PersistenceStore) Save() error {
 	return nil
 }
 
-// GetConfig returns the current persistence config
+// GetConfig returns the current persistence config, with any overrides applied
 func (p *PersistenceStore) GetConfig() PersistenceConfig {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	return *p.config
+	return p.effectiveLocked()
 }
 
 // UpdateConfig updates the persistence config.
@@ -263,6 +316,9 @@ func (p *PersistenceStore) GetConfig() PersistenceConfig {
 func (p *PersistenceStore) UpdateConfig(config PersistenceConfig) error {
 	// Validate before touching any state so a rejection cannot leave
 	// the in-memory struct partially mutated.
+	if err := validatePersistenceNamespaces(config); err != nil {
+		return err
+	}
 	if config.Enabled {
 		if config.PrimaryCluster == "" {
 			return fmt.Errorf("primary cluster is required when persistence is enabled")
@@ -298,12 +354,13 @@ func (p *PersistenceStore) UpdateConfig(config PersistenceConfig) error {
 // GetStatus returns the current persistence status
 func (p *PersistenceStore) GetStatus(ctx context.Context) PersistenceStatus {
 	p.mu.RLock()
-	config := *p.config
+	config := p.effectiveLocked()
 	p.mu.RUnlock()
 
 	status := PersistenceStatus{
 		Active:        false,
 		PrimaryHealth: ClusterHealthUnknown,
+		LastSync:      p.getLastSync(),
 	}
 
 	if !config.Enabled {
@@ -386,15 +443,81 @@ func (p *PersistenceStore) GetActiveClient(ctx context.Context) (dynamic.Interfa
 func (p *PersistenceStore) IsEnabled() bool {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	return p.config.Enabled
+	return p.effectiveLocked().Enabled
+}
+
+// RecordSync records when console resources were last synced, reported as
+// PersistenceStatus.LastSync.
+func (p *PersistenceStore) RecordSync(at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastSync = &at
+}
+
+func (p *PersistenceStore) getLastSync() *time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.lastSync
 }
 
 // GetNamespace returns the namespace for console CRs
 func (p *PersistenceStore) GetNamespace() string {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	if p.config.Namespace == "" {
-		return DefaultNamespace
+	return p.effectiveLocked().Namespace
+}
+
+// GetNamespaces returns every namespace for console CRs, the default one
+// first.
+func (p *PersistenceStore) GetNamespaces() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	config := p.effectiveLocked()
+	namespaces := []string{config.Namespace}
+	for _, ns := range config.Namespaces {
+		namespaces = append(namespaces, ns.Namespace)
+	}
+	return namespaces
+}
+
+// LookupNamespace returns the settings of namespace. ok is false when it
+// holds no console CRs; restricted is false for the default namespace,
+// which every user may use.
+func (p *PersistenceStore) LookupNamespace(namespace string) (ns PersistenceNamespace, restricted, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	config := p.effectiveLocked()
+	if namespace == config.Namespace {
+		return PersistenceNamespace{Namespace: namespace}, false, true
+	}
+	for _, ns := range config.Namespaces {
+		if ns.Namespace == namespace {
+			return ns, true, true
+		}
+	}
+	return PersistenceNamespace{}, false, false
+}
+
+// validatePersistenceNamespaces checks that config's additional namespaces
+// are valid names, distinct from each other and from the default one.
+func validatePersistenceNamespaces(config PersistenceConfig) error {
+	seen := map[string]bool{config.Namespace: true}
+	if config.Namespace == "" {
+		seen[DefaultNamespace] = true
 	}
-	return p.config.Namespace
+	for _, ns := range config.Namespaces {
+		if errs := validation.IsDNS1123Label(ns.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid persistence namespace %q: %s", ns.Namespace, strings.Join(errs, "; "))
+		}
+		if seen[ns.Namespace] {
+			return fmt.Errorf("persistence namespace %q is listed twice", ns.Namespace)
+		}
+		seen[ns.Namespace] = true
+		for _, team := range ns.Teams {
+			if strings.TrimSpace(team) == "" {
+				return fmt.Errorf("persistence namespace %q has an empty team name", ns.Namespace)
+			}
+		}
+	}
+	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -156,6 +157,26 @@ func TestPersistenceStore_IsEnabled(t *testing.T) {
 	require.True(t, ps.IsEnabled())
 }
 
+func TestPersistenceStore_ConfigOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "persistence.json")
+	ps := NewPersistenceStore(path)
+	ps.SetConfigOverrides(func(c *PersistenceConfig) {
+		c.Enabled = true
+		c.PrimaryCluster = "env-cluster"
+	})
+	require.NoError(t, ps.Load())
+
+	require.True(t, ps.IsEnabled())
+	require.Equal(t, "env-cluster", ps.GetConfig().PrimaryCluster)
+	require.Equal(t, DefaultNamespace, ps.GetNamespace())
+
+	// Overrides are applied on read only and never written to disk.
+	require.NoError(t, ps.Save())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "env-cluster")
+}
+
 func TestPersistenceStore_GetNamespace(t *testing.T) {
 	t.Run("returns default when not configured", func(t *testing.T) {
 		ps := NewPersistenceStore("")
@@ -174,6 +195,45 @@ func TestPersistenceStore_GetNamespace(t *testing.T) {
 	})
 }
 
+func TestPersistenceStore_Namespaces(t *testing.T) {
+	ps := NewPersistenceStore("")
+	require.Equal(t, []string{DefaultNamespace}, ps.GetNamespaces())
+
+	require.NoError(t, ps.UpdateConfig(PersistenceConfig{
+		Namespace: "console",
+		Namespaces: []PersistenceNamespace{
+			{Namespace: "team-a", Project: "payments", Teams: []string{"payments-devs"}},
+			{Namespace: "team-b"},
+		},
+	}))
+	require.Equal(t, []string{"console", "team-a", "team-b"}, ps.GetNamespaces())
+
+	ns, restricted, ok := ps.LookupNamespace("console")
+	require.True(t, ok)
+	require.False(t, restricted, "the default namespace is open to every user")
+	require.Equal(t, "console", ns.Namespace)
+
+	ns, restricted, ok = ps.LookupNamespace("team-a")
+	require.True(t, ok)
+	require.True(t, restricted)
+	require.Equal(t, "payments", ns.Project)
+	require.Equal(t, []string{"payments-devs"}, ns.Teams)
+
+	_, _, ok = ps.LookupNamespace("kube-system")
+	require.False(t, ok)
+
+	for name, namespaces := range map[string][]PersistenceNamespace{
+		"invalid name": {{Namespace: "Team_A"}},
+		"duplicate":    {{Namespace: "team-a"}, {Namespace: "team-a"}},
+		"default":      {{Namespace: "console"}},
+		"empty team":   {{Namespace: "team-a", Teams: []string{" "}}},
+	} {
+		err := ps.UpdateConfig(PersistenceConfig{Namespace: "console", Namespaces: namespaces})
+		require.Error(t, err, name)
+	}
+	require.Equal(t, []string{"console", "team-a", "team-b"}, ps.GetNamespaces(), "a rejected config changes nothing")
+}
+
 func TestPersistenceStore_GetStatus(t *testing.T) {
 	ctx := context.Background()
 
@@ -272,6 +332,17 @@ func TestPersistenceStore_GetStatus(t *testing.T) {
 	})
 }
 
+func TestPersistenceStore_RecordSync(t *testing.T) {
+	ps := NewPersistenceStore("")
+	require.Nil(t, ps.GetStatus(context.Background()).LastSync)
+
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	ps.RecordSync(at)
+	status := ps.GetStatus(context.Background())
+	require.NotNil(t, status.LastSync)
+	require.True(t, status.LastSync.Equal(at))
+}
+
 func TestPersistenceStore_GetActiveCluster(t *testing.T) {
 	ctx := context.Background()
 
@@ -73,6 +73,11 @@ func sqlDriver(name string) (driver.Driver, error) {
 // map this error to HTTP 429 Too Many Requests.
 var ErrDashboardCardLimitReached = errors.New("dashboard card limit reached")
 
+// ErrDashboardVersionConflict is returned by UpdateDashboardIfVersion when
+// the dashboard changed since the caller read it, or no longer exists.
+// Handlers should map this error to HTTP 409 Conflict.
+var ErrDashboardVersionConflict = errors.New("dashboard version conflict")
+
 // ErrDailyBonusUnavailable is returned by ClaimDailyBonus when the user's
 // last claim is within the daily-bonus cooldown window (issue #6011).
 // Handlers should map this error to HTTP 429 Too Many Requests.
@@ -82,6 +87,16 @@ var ErrDailyBonusUnavailable = errors.New("daily bonus already claimed within co
 // Handlers map this sentinel to HTTP 404 Not Found.
 var ErrNotFound = errors.New("not found")
 
+// ErrSavedViewNameTaken is returned when a saved view is created or renamed
+// to a name already used within the same project. Handlers should map this
+// error to HTTP 409 Conflict.
+var ErrSavedViewNameTaken = errors.New("saved view name already exists in project")
+
+// ErrSLONameTaken is returned when an SLO is created or renamed to a name
+// already used within the same project. Handlers should map this error to
+// HTTP 409 Conflict.
+var ErrSLONameTaken = errors.New("slo name already exists in project")
+
 // MinCoinBalance is the floor for user coin balances. Negative increments
 // are clamped to this value so buggy clients cannot drive balances below
 // zero. Exported so handlers and tests can reference the same constant.
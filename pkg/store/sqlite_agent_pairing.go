@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Agent pairing code methods
+
+// CreateAgentPairingCode stores the hash of a pairing code bound to agentID.
+func (s *SQLiteStore) CreateAgentPairingCode(ctx context.Context, codeHash, agentID string, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO agent_pairing_codes (code_hash, agent_id, created_at, expires_at) VALUES (?, ?, ?, ?)`,
+		codeHash, agentID, time.Now().UTC(), expiresAt.UTC())
+	return err
+}
+
+// ConsumeAgentPairingCode deletes a pairing code and returns the agent ID it
+// was bound to, or "" when the code is unknown or expired at now. The
+// delete and read are one statement, so a code is consumed at most once.
+func (s *SQLiteStore) ConsumeAgentPairingCode(ctx context.Context, codeHash string, now time.Time) (string, error) {
+	var agentID string
+	var expiresAt time.Time
+	err := s.db.QueryRowContext(ctx,
+		`DELETE FROM agent_pairing_codes WHERE code_hash = ? RETURNING agent_id, expires_at`, codeHash,
+	).Scan(&agentID, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !now.Before(expiresAt) {
+		return "", nil
+	}
+	return agentID, nil
+}
+
+// DeleteExpiredAgentPairingCodes removes codes that expired before cutoff and
+// returns how many were removed.
+func (s *SQLiteStore) DeleteExpiredAgentPairingCodes(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM agent_pairing_codes WHERE expires_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
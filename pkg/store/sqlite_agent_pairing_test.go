@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteAgentPairingCodes(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.CreateAgentPairingCode(ctx, "hash-1", "agent-1", now.Add(time.Minute)))
+	require.NoError(t, store.CreateAgentPairingCode(ctx, "hash-2", "agent-2", now.Add(-time.Minute)))
+	require.NoError(t, store.CreateAgentPairingCode(ctx, "hash-3", "agent-3", now.Add(-time.Hour)))
+
+	agentID, err := store.ConsumeAgentPairingCode(ctx, "hash-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", agentID)
+
+	agentID, err = store.ConsumeAgentPairingCode(ctx, "hash-1", now)
+	require.NoError(t, err)
+	assert.Empty(t, agentID, "codes are single use")
+
+	agentID, err = store.ConsumeAgentPairingCode(ctx, "hash-2", now)
+	require.NoError(t, err)
+	assert.Empty(t, agentID, "expired codes are not accepted")
+
+	agentID, err = store.ConsumeAgentPairingCode(ctx, "unknown", now)
+	require.NoError(t, err)
+	assert.Empty(t, agentID)
+
+	require.NoError(t, store.CreateAgentPairingCode(ctx, "hash-4", "agent-4", now.Add(time.Minute)))
+	deleted, err := store.DeleteExpiredAgentPairingCodes(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted, "only the expired hash-3 is left to purge")
+	agentID, err = store.ConsumeAgentPairingCode(ctx, "hash-4", now)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-4", agentID)
+}
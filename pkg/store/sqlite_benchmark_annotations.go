@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Benchmark annotation methods
+
+const benchmarkAnnotationColumns = `report_uid, starred, note, labels, updated_by, updated_at`
+
+// maxBenchmarkAnnotations bounds ListBenchmarkAnnotations. It is well above
+// the number of runs the benchmark cache retains.
+const maxBenchmarkAnnotations = 10000
+
+// GetBenchmarkAnnotation returns the annotation of one report, or nil when
+// the report has none.
+func (s *SQLiteStore) GetBenchmarkAnnotation(ctx context.Context, reportUID string) (*models.BenchmarkAnnotation, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+benchmarkAnnotationColumns+` FROM benchmark_annotations WHERE report_uid = ?`, reportUID)
+	a, err := scanBenchmarkAnnotation(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+// ListBenchmarkAnnotations returns every benchmark annotation, most recently
+// updated first.
+func (s *SQLiteStore) ListBenchmarkAnnotations(ctx context.Context) ([]models.BenchmarkAnnotation, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+benchmarkAnnotationColumns+` FROM benchmark_annotations ORDER BY updated_at DESC, report_uid ASC LIMIT ?`, maxBenchmarkAnnotations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	annotations := make([]models.BenchmarkAnnotation, 0)
+	for rows.Next() {
+		a, err := scanBenchmarkAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, *a)
+	}
+	return annotations, rows.Err()
+}
+
+// SaveBenchmarkAnnotation creates or replaces the annotation of
+// annotation.ReportUID and stamps UpdatedAt.
+func (s *SQLiteStore) SaveBenchmarkAnnotation(ctx context.Context, annotation *models.BenchmarkAnnotation) error {
+	annotation.UpdatedAt = time.Now()
+	if annotation.Labels == nil {
+		annotation.Labels = []string{}
+	}
+	labels, err := json.Marshal(annotation.Labels)
+	if err != nil {
+		return fmt.Errorf("marshal benchmark annotation labels: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO benchmark_annotations (report_uid, starred, note, labels, updated_by, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(report_uid) DO UPDATE SET starred = excluded.starred, note = excluded.note, labels = excluded.labels,
+		 updated_by = excluded.updated_by, updated_at = excluded.updated_at`,
+		annotation.ReportUID, annotation.Starred, annotation.Note, string(labels), annotation.UpdatedBy.String(), annotation.UpdatedAt)
+	return err
+}
+
+// DeleteBenchmarkAnnotation removes the annotation of one report.
+func (s *SQLiteStore) DeleteBenchmarkAnnotation(ctx context.Context, reportUID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM benchmark_annotations WHERE report_uid = ?`, reportUID)
+	return err
+}
+
+// scanBenchmarkAnnotation decodes a benchmark_annotations row from either
+// *sql.Row or *sql.Rows.
+func scanBenchmarkAnnotation(row interface {
+	Scan(dest ...any) error
+}) (*models.BenchmarkAnnotation, error) {
+	var a models.BenchmarkAnnotation
+	var labels, updatedBy string
+	if err := row.Scan(&a.ReportUID, &a.Starred, &a.Note, &labels, &updatedBy, &a.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(labels), &a.Labels); err != nil {
+		return nil, fmt.Errorf("unmarshal benchmark annotation labels: %w", err)
+	}
+	a.UpdatedBy = parseUUID(updatedBy, "a.UpdatedBy")
+	return &a, nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchmarkAnnotations_CRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user := uuid.New()
+
+	got, err := s.GetBenchmarkAnnotation(ctx, "llama/r1/stage-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	a := &models.BenchmarkAnnotation{ReportUID: "llama/r1/stage-1", Starred: true, Labels: []string{"baseline-H100"}, UpdatedBy: user}
+	require.NoError(t, s.SaveBenchmarkAnnotation(ctx, a))
+	assert.False(t, a.UpdatedAt.IsZero())
+	require.NoError(t, s.SaveBenchmarkAnnotation(ctx, &models.BenchmarkAnnotation{ReportUID: "llama/r2/stage-1", Note: "bad network day", UpdatedBy: user}))
+
+	got, err = s.GetBenchmarkAnnotation(ctx, "llama/r1/stage-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, got.Starred)
+	assert.Equal(t, []string{"baseline-H100"}, got.Labels)
+	assert.Equal(t, user, got.UpdatedBy)
+
+	// Saving again replaces the whole annotation.
+	require.NoError(t, s.SaveBenchmarkAnnotation(ctx, &models.BenchmarkAnnotation{ReportUID: "llama/r1/stage-1", Note: "rerun", UpdatedBy: user}))
+	got, err = s.GetBenchmarkAnnotation(ctx, "llama/r1/stage-1")
+	require.NoError(t, err)
+	assert.False(t, got.Starred)
+	assert.Equal(t, "rerun", got.Note)
+	assert.Equal(t, []string{}, got.Labels)
+
+	all, err := s.ListBenchmarkAnnotations(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	require.NoError(t, s.DeleteBenchmarkAnnotation(ctx, "llama/r2/stage-1"))
+	all, err = s.ListBenchmarkAnnotations(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "llama/r1/stage-1", all[0].ReportUID)
+}
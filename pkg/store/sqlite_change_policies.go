@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Change policy methods
+
+// GetChangePolicy returns a project's change metadata policy, or nil when
+// the project has none.
+func (s *SQLiteStore) GetChangePolicy(ctx context.Context, project string) (*models.ChangePolicy, error) {
+	p := models.ChangePolicy{Project: project}
+	var updatedAt time.Time
+	err := s.db.QueryRowContext(ctx,
+		`SELECT require_description, require_ticket, require_approver, ticket_url_prefix, updated_by, updated_at
+		 FROM change_policies WHERE project = ?`, project).
+		Scan(&p.RequireDescription, &p.RequireTicket, &p.RequireApprover, &p.TicketURLPrefix, &p.UpdatedBy, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = &updatedAt
+	return &p, nil
+}
+
+// SaveChangePolicy creates or replaces a project's change metadata policy.
+func (s *SQLiteStore) SaveChangePolicy(ctx context.Context, policy *models.ChangePolicy) error {
+	now := time.Now()
+	policy.UpdatedAt = &now
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO change_policies (project, require_description, require_ticket, require_approver, ticket_url_prefix, updated_by, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(project) DO UPDATE SET
+		   require_description = excluded.require_description,
+		   require_ticket = excluded.require_ticket,
+		   require_approver = excluded.require_approver,
+		   ticket_url_prefix = excluded.ticket_url_prefix,
+		   updated_by = excluded.updated_by,
+		   updated_at = excluded.updated_at`,
+		policy.Project, policy.RequireDescription, policy.RequireTicket, policy.RequireApprover,
+		policy.TicketURLPrefix, policy.UpdatedBy, now)
+	return err
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteChangePolicies(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	policy, err := store.GetChangePolicy(ctx, "kubestellar")
+	require.NoError(t, err)
+	assert.Nil(t, policy, "a project without a policy requires nothing")
+
+	require.NoError(t, store.SaveChangePolicy(ctx, &models.ChangePolicy{
+		Project: "kubestellar", RequireTicket: true, TicketURLPrefix: "https://jira.example.com/browse/", UpdatedBy: "admin",
+	}))
+	require.NoError(t, store.SaveChangePolicy(ctx, &models.ChangePolicy{Project: "istio", RequireApprover: true}))
+	require.NoError(t, store.SaveChangePolicy(ctx, &models.ChangePolicy{
+		Project: "kubestellar", RequireTicket: true, RequireDescription: true, UpdatedBy: "admin",
+	}))
+
+	policy, err = store.GetChangePolicy(ctx, "kubestellar")
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.True(t, policy.RequireDescription)
+	assert.True(t, policy.RequireTicket)
+	assert.False(t, policy.RequireApprover)
+	assert.Empty(t, policy.TicketURLPrefix, "saving again replaces the policy")
+	assert.Equal(t, "admin", policy.UpdatedBy)
+	assert.NotNil(t, policy.UpdatedAt)
+
+	other, err := store.GetChangePolicy(ctx, "istio")
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	assert.True(t, other.RequireApprover)
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Cluster timezone methods
+
+// maxClusterTimezones is the upper bound on timezone rows returned.
+const maxClusterTimezones = 1000
+
+// SetClusterTimezone records the IANA timezone of a cluster.
+func (s *SQLiteStore) SetClusterTimezone(ctx context.Context, cluster, timezone string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO cluster_timezones (cluster, timezone, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(cluster) DO UPDATE SET timezone = excluded.timezone, updated_at = CURRENT_TIMESTAMP`,
+		cluster, timezone)
+	return err
+}
+
+// GetClusterTimezone returns a cluster's timezone, or "" when none is set.
+func (s *SQLiteStore) GetClusterTimezone(ctx context.Context, cluster string) (string, error) {
+	var timezone string
+	err := s.db.QueryRowContext(ctx, `SELECT timezone FROM cluster_timezones WHERE cluster = ?`, cluster).Scan(&timezone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return timezone, err
+}
+
+// ListClusterTimezones returns every recorded cluster timezone keyed by
+// cluster name.
+func (s *SQLiteStore) ListClusterTimezones(ctx context.Context) (map[string]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT cluster, timezone FROM cluster_timezones ORDER BY cluster LIMIT ?`, maxClusterTimezones)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timezones := make(map[string]string)
+	for rows.Next() {
+		var cluster, timezone string
+		if err := rows.Scan(&cluster, &timezone); err != nil {
+			return nil, err
+		}
+		timezones[cluster] = timezone
+	}
+	return timezones, rows.Err()
+}
+
+// DeleteClusterTimezone clears a cluster's timezone so it falls back to UTC.
+func (s *SQLiteStore) DeleteClusterTimezone(ctx context.Context, cluster string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM cluster_timezones WHERE cluster = ?`, cluster)
+	return err
+}
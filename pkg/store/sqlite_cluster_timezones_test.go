@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteClusterTimezones(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	tz, err := store.GetClusterTimezone(ctx, "apac-1")
+	require.NoError(t, err)
+	assert.Empty(t, tz)
+
+	require.NoError(t, store.SetClusterTimezone(ctx, "apac-1", "Asia/Tokyo"))
+	require.NoError(t, store.SetClusterTimezone(ctx, "eu-1", "Europe/Berlin"))
+	require.NoError(t, store.SetClusterTimezone(ctx, "apac-1", "Asia/Singapore"))
+
+	tz, err = store.GetClusterTimezone(ctx, "apac-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Singapore", tz, "setting again replaces the timezone")
+
+	all, err := store.ListClusterTimezones(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"apac-1": "Asia/Singapore", "eu-1": "Europe/Berlin"}, all)
+
+	require.NoError(t, store.DeleteClusterTimezone(ctx, "eu-1"))
+	all, err = store.ListClusterTimezones(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"apac-1": "Asia/Singapore"}, all)
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Dashboard snapshot methods
+
+// dashboardSnapshotPayload is the JSON stored in dashboard_snapshots.payload.
+type dashboardSnapshotPayload struct {
+	Name   string                `json:"name"`
+	Layout json.RawMessage       `json:"layout,omitempty"`
+	Cards  []models.SnapshotCard `json:"cards"`
+}
+
+// CreateDashboardSnapshot stores a new snapshot and stamps its ID and
+// CreatedAt. Snapshots cannot be changed afterwards.
+func (s *SQLiteStore) CreateDashboardSnapshot(ctx context.Context, snapshot *models.DashboardSnapshot) error {
+	if snapshot.ID == uuid.Nil {
+		snapshot.ID = uuid.New()
+	}
+	snapshot.CreatedAt = time.Now().UTC()
+	snapshot.ExpiresAt = snapshot.ExpiresAt.UTC()
+	if snapshot.Cards == nil {
+		snapshot.Cards = []models.SnapshotCard{}
+	}
+
+	payload, err := json.Marshal(dashboardSnapshotPayload{Name: snapshot.Name, Layout: snapshot.Layout, Cards: snapshot.Cards})
+	if err != nil {
+		return fmt.Errorf("marshal dashboard snapshot: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO dashboard_snapshots (id, dashboard_id, user_id, payload, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		snapshot.ID.String(), snapshot.DashboardID.String(), snapshot.UserID.String(), string(payload), snapshot.CreatedAt, snapshot.ExpiresAt)
+	return err
+}
+
+// GetDashboardSnapshot returns a snapshot by ID, or nil when it does not
+// exist. Expired snapshots are still returned until they are purged; callers
+// check ExpiresAt.
+func (s *SQLiteStore) GetDashboardSnapshot(ctx context.Context, id uuid.UUID) (*models.DashboardSnapshot, error) {
+	var snapshot models.DashboardSnapshot
+	var dashboardID, userID, payload string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT dashboard_id, user_id, payload, created_at, expires_at FROM dashboard_snapshots WHERE id = ?`, id.String()).
+		Scan(&dashboardID, &userID, &payload, &snapshot.CreatedAt, &snapshot.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p dashboardSnapshotPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return nil, fmt.Errorf("unmarshal dashboard snapshot: %w", err)
+	}
+	snapshot.ID = id
+	snapshot.DashboardID = parseUUID(dashboardID, "snapshot.DashboardID")
+	snapshot.UserID = parseUUID(userID, "snapshot.UserID")
+	snapshot.Name = p.Name
+	snapshot.Layout = p.Layout
+	snapshot.Cards = p.Cards
+	return &snapshot, nil
+}
+
+// CountUserDashboardSnapshots returns how many of a user's snapshots are
+// still valid at now.
+func (s *SQLiteStore) CountUserDashboardSnapshots(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM dashboard_snapshots WHERE user_id = ? AND expires_at > ?`,
+		userID.String(), now.UTC()).Scan(&count)
+	return count, err
+}
+
+// DeleteExpiredDashboardSnapshots removes snapshots that expired before
+// cutoff and returns how many were removed.
+func (s *SQLiteStore) DeleteExpiredDashboardSnapshots(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM dashboard_snapshots WHERE expires_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+func TestSQLiteDashboardSnapshots(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	require.NoError(t, store.CreateUser(ctx, &models.User{ID: userID, GitHubID: "snap-1", GitHubLogin: "snapuser"}))
+	dashboard := &models.Dashboard{UserID: userID, Name: "Ops"}
+	require.NoError(t, store.CreateDashboard(ctx, dashboard))
+
+	got, err := store.GetDashboardSnapshot(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	now := time.Now()
+	cardID := uuid.New()
+	live := &models.DashboardSnapshot{
+		DashboardID: dashboard.ID,
+		UserID:      userID,
+		Name:        "Ops",
+		Cards: []models.SnapshotCard{{
+			ID:       cardID,
+			CardType: models.CardTypeClusterHealth,
+			Position: models.CardPosition{W: 4, H: 2},
+			Data:     json.RawMessage(`{"healthy":3}`),
+		}},
+		ExpiresAt: now.Add(time.Hour),
+	}
+	require.NoError(t, store.CreateDashboardSnapshot(ctx, live))
+	require.NotEqual(t, uuid.Nil, live.ID)
+	expired := &models.DashboardSnapshot{DashboardID: dashboard.ID, UserID: userID, Name: "Old", ExpiresAt: now.Add(-time.Hour)}
+	require.NoError(t, store.CreateDashboardSnapshot(ctx, expired))
+
+	got, err = store.GetDashboardSnapshot(ctx, live.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, dashboard.ID, got.DashboardID)
+	assert.Equal(t, userID, got.UserID)
+	assert.Equal(t, live.ExpiresAt.Unix(), got.ExpiresAt.Unix())
+	require.Len(t, got.Cards, 1)
+	assert.Equal(t, cardID, got.Cards[0].ID)
+	assert.JSONEq(t, `{"healthy":3}`, string(got.Cards[0].Data))
+
+	count, err := store.CountUserDashboardSnapshots(ctx, userID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "expired snapshots do not count")
+
+	removed, err := store.DeleteExpiredDashboardSnapshots(ctx, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, removed)
+	got, err = store.GetDashboardSnapshot(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	// Deleting the dashboard removes its snapshots.
+	require.NoError(t, store.DeleteDashboard(ctx, dashboard.ID))
+	got, err = store.GetDashboardSnapshot(ctx, live.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -15,7 +15,7 @@ import (
 // Dashboard methods
 
 func (s *SQLiteStore) GetDashboard(ctx context.Context, id uuid.UUID) (*models.Dashboard, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE id = ?`, id.String())
+	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, name, layout, is_default, version, created_at, updated_at FROM dashboards WHERE id = ?`, id.String())
 	return s.scanDashboard(row)
 }
 
@@ -36,7 +36,7 @@ func (s *SQLiteStore) CountUserDashboards(ctx context.Context, userID uuid.UUID)
 func (s *SQLiteStore) GetUserDashboards(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.Dashboard, error) {
 	lim := resolvePageLimit(limit, defaultPageLimit)
 	off := resolvePageOffset(offset)
-	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE user_id = ? ORDER BY is_default DESC, created_at ASC, id ASC LIMIT ? OFFSET ?`, userID.String(), lim, off)
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, name, layout, is_default, version, created_at, updated_at FROM dashboards WHERE user_id = ? ORDER BY is_default DESC, created_at ASC, id ASC LIMIT ? OFFSET ?`, userID.String(), lim, off)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +54,7 @@ func (s *SQLiteStore) GetUserDashboards(ctx context.Context, userID uuid.UUID, l
 }
 
 func (s *SQLiteStore) GetDefaultDashboard(ctx context.Context, userID uuid.UUID) (*models.Dashboard, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, name, layout, is_default, created_at, updated_at FROM dashboards WHERE user_id = ? AND is_default = 1`, userID.String())
+	row := s.db.QueryRowContext(ctx, `SELECT id, user_id, name, layout, is_default, version, created_at, updated_at FROM dashboards WHERE user_id = ? AND is_default = 1`, userID.String())
 	return s.scanDashboard(row)
 }
 
@@ -65,7 +65,7 @@ func (s *SQLiteStore) scanDashboard(row *sql.Row) (*models.Dashboard, error) {
 	var isDefault int
 	var updatedAt sql.NullTime
 
-	err := row.Scan(&idStr, &userIDStr, &d.Name, &layout, &isDefault, &d.CreatedAt, &updatedAt)
+	err := row.Scan(&idStr, &userIDStr, &d.Name, &layout, &isDefault, &d.Version, &d.CreatedAt, &updatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -92,7 +92,7 @@ func (s *SQLiteStore) scanDashboardRow(ctx context.Context, rows *sql.Rows) (*mo
 	var isDefault int
 	var updatedAt sql.NullTime
 
-	err := rows.Scan(&idStr, &userIDStr, &d.Name, &layout, &isDefault, &d.CreatedAt, &updatedAt)
+	err := rows.Scan(&idStr, &userIDStr, &d.Name, &layout, &isDefault, &d.Version, &d.CreatedAt, &updatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +143,7 @@ func (s *SQLiteStore) createDashboard(ctx context.Context, execer sqlContextExec
 		dashboard.ID = uuid.New()
 	}
 	dashboard.CreatedAt = time.Now()
+	dashboard.Version = 1
 
 	var layoutStr *string
 	if dashboard.Layout != nil {
@@ -150,14 +151,29 @@ func (s *SQLiteStore) createDashboard(ctx context.Context, execer sqlContextExec
 		layoutStr = &str
 	}
 
-	_, err := execer.ExecContext(ctx, `INSERT INTO dashboards (id, user_id, name, layout, is_default, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
-		dashboard.ID.String(), dashboard.UserID.String(), dashboard.Name, layoutStr, boolToInt(dashboard.IsDefault), dashboard.CreatedAt)
+	_, err := execer.ExecContext(ctx, `INSERT INTO dashboards (id, user_id, name, layout, is_default, version, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		dashboard.ID.String(), dashboard.UserID.String(), dashboard.Name, layoutStr, boolToInt(dashboard.IsDefault), dashboard.Version, dashboard.CreatedAt)
 	return err
 }
 
+// UpdateDashboard writes dashboard unconditionally and bumps its version.
 func (s *SQLiteStore) UpdateDashboard(ctx context.Context, dashboard *models.Dashboard) error {
+	return s.updateDashboard(ctx, dashboard, 0)
+}
+
+// UpdateDashboardIfVersion writes dashboard only while its stored version is
+// still expectedVersion, returning ErrDashboardVersionConflict otherwise. On
+// success dashboard.Version holds the new version.
+func (s *SQLiteStore) UpdateDashboardIfVersion(ctx context.Context, dashboard *models.Dashboard, expectedVersion int) error {
+	if expectedVersion <= 0 {
+		return ErrDashboardVersionConflict
+	}
+	return s.updateDashboard(ctx, dashboard, expectedVersion)
+}
+
+// updateDashboard applies the update; expectedVersion 0 skips the check.
+func (s *SQLiteStore) updateDashboard(ctx context.Context, dashboard *models.Dashboard, expectedVersion int) error {
 	now := time.Now()
-	dashboard.UpdatedAt = &now
 
 	var layoutStr *string
 	if dashboard.Layout != nil {
@@ -165,9 +181,23 @@ func (s *SQLiteStore) UpdateDashboard(ctx context.Context, dashboard *models.Das
 		layoutStr = &str
 	}
 
-	_, err := s.db.ExecContext(ctx, `UPDATE dashboards SET name = ?, layout = ?, is_default = ?, updated_at = ? WHERE id = ?`,
-		dashboard.Name, layoutStr, boolToInt(dashboard.IsDefault), dashboard.UpdatedAt, dashboard.ID.String())
-	return err
+	var version int
+	err := s.db.QueryRowContext(ctx, `UPDATE dashboards SET name = ?, layout = ?, is_default = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?) RETURNING version`,
+		dashboard.Name, layoutStr, boolToInt(dashboard.IsDefault), now, dashboard.ID.String(), expectedVersion, expectedVersion).Scan(&version)
+	if err == sql.ErrNoRows {
+		if expectedVersion == 0 {
+			// Unconditional updates of a missing row have always been a no-op.
+			return nil
+		}
+		return ErrDashboardVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+	dashboard.UpdatedAt = &now
+	dashboard.Version = version
+	return nil
 }
 
 func (s *SQLiteStore) DeleteDashboard(ctx context.Context, id uuid.UUID) error {
@@ -117,6 +117,41 @@ func TestSQLiteDashboards_Delete(t *testing.T) {
 	require.Nil(t, deleted, "dashboard should be deleted")
 }
 
+func TestSQLiteDashboards_UpdateIfVersion(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	require.NoError(t, store.CreateUser(ctx, &models.User{ID: userID, GitHubID: "ver-1", GitHubLogin: "veruser"}))
+	dashboard := &models.Dashboard{UserID: userID, Name: "Original"}
+	require.NoError(t, store.CreateDashboard(ctx, dashboard))
+	require.Equal(t, 1, dashboard.Version)
+
+	// Two sessions read version 1; the first write wins, the second is stale.
+	first := *dashboard
+	first.Name = "First"
+	require.NoError(t, store.UpdateDashboardIfVersion(ctx, &first, 1))
+	require.Equal(t, 2, first.Version)
+
+	second := *dashboard
+	second.Name = "Second"
+	require.ErrorIs(t, store.UpdateDashboardIfVersion(ctx, &second, 1), ErrDashboardVersionConflict)
+
+	got, err := store.GetDashboard(ctx, dashboard.ID)
+	require.NoError(t, err)
+	require.Equal(t, "First", got.Name)
+	require.Equal(t, 2, got.Version)
+
+	// Unconditional updates still bump the version.
+	got.Name = "Blind"
+	require.NoError(t, store.UpdateDashboard(ctx, got))
+	require.Equal(t, 3, got.Version)
+
+	missing := &models.Dashboard{ID: uuid.New(), Name: "Gone"}
+	require.ErrorIs(t, store.UpdateDashboardIfVersion(ctx, missing, 1), ErrDashboardVersionConflict)
+	require.NoError(t, store.UpdateDashboard(ctx, missing))
+}
+
 func TestGetUserPendingSwaps(t *testing.T) {
 	store := OpenTestDB(t)
 	ctx := context.Background()
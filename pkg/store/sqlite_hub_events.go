@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Hub event methods
+
+const hubEventColumns = `seq, user_id, type, message, created_at`
+
+// AppendHubEvents stores events in one transaction. An event whose Seq is
+// already stored is left as it is.
+func (s *SQLiteStore) AppendHubEvents(ctx context.Context, events []models.HubEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return s.WithTransaction(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO hub_events (`+hubEventColumns+`) VALUES (?, ?, ?, ?, ?)`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, e := range events {
+			createdAt := e.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			if _, err := stmt.ExecContext(ctx, e.Seq, e.UserID.String(), e.Type, string(e.Message), createdAt.UTC()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListHubEvents returns the events after afterSeq that reached userID, its
+// own and those broadcast to every client, oldest first. Pass 0 for limit
+// to use the store default.
+func (s *SQLiteStore) ListHubEvents(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]models.HubEvent, error) {
+	lim := resolvePageLimit(limit, defaultPageLimit)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+hubEventColumns+` FROM hub_events
+		WHERE seq > ? AND user_id IN (?, ?) ORDER BY seq ASC LIMIT ?`,
+		afterSeq, uuid.Nil.String(), userID.String(), lim)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]models.HubEvent, 0)
+	for rows.Next() {
+		var e models.HubEvent
+		var userStr, message string
+		if err := rows.Scan(&e.Seq, &userStr, &e.Type, &message, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.UserID = parseUUID(userStr, "hubEvent.UserID")
+		e.Message = []byte(message)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// HubEventSeqRange returns the lowest and highest stored sequence numbers,
+// both 0 when no events are stored.
+func (s *SQLiteStore) HubEventSeqRange(ctx context.Context) (first, last int64, err error) {
+	err = s.db.QueryRowContext(ctx, `SELECT COALESCE(MIN(seq), 0), COALESCE(MAX(seq), 0) FROM hub_events`).Scan(&first, &last)
+	return first, last, err
+}
+
+// PruneHubEvents deletes all but the newest keep events and those created
+// before before, and returns how many it deleted. The newest event is always
+// kept so the hub can resume numbering after it.
+func (s *SQLiteStore) PruneHubEvents(ctx context.Context, keep int, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM hub_events
+		WHERE seq < (SELECT MAX(seq) FROM hub_events)
+		AND (seq <= (SELECT MAX(seq) FROM hub_events) - ? OR created_at < ?)`, keep, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
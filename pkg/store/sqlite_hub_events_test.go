@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteHubEvents(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	first, last, err := store.HubEventSeqRange(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, first)
+	assert.Zero(t, last)
+
+	alice, bob := uuid.New(), uuid.New()
+	old := time.Now().Add(-2 * time.Hour)
+	event := func(seq int64, user uuid.UUID, createdAt time.Time) models.HubEvent {
+		return models.HubEvent{Seq: seq, UserID: user, Type: "deployment_progress",
+			Message: json.RawMessage(`{"type":"deployment_progress","seq":1}`), CreatedAt: createdAt}
+	}
+	require.NoError(t, store.AppendHubEvents(ctx, []models.HubEvent{
+		event(1, uuid.Nil, old), event(2, alice, old), event(3, bob, time.Time{}), event(4, uuid.Nil, time.Time{}),
+	}))
+	require.NoError(t, store.AppendHubEvents(ctx, []models.HubEvent{event(4, bob, time.Time{})}), "a stored seq is ignored")
+
+	got, err := store.ListHubEvents(ctx, alice, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, got, 3, "alice's own events and those for everyone")
+	assert.Equal(t, []int64{1, 2, 4}, []int64{got[0].Seq, got[1].Seq, got[2].Seq})
+	assert.Equal(t, uuid.Nil, got[2].UserID)
+	assert.JSONEq(t, `{"type":"deployment_progress","seq":1}`, string(got[0].Message))
+
+	got, err = store.ListHubEvents(ctx, bob, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(3), got[0].Seq)
+
+	first, last, err = store.HubEventSeqRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first)
+	assert.Equal(t, int64(4), last)
+
+	pruned, err := store.PruneHubEvents(ctx, 10, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruned, "events older than the cutoff")
+	pruned, err = store.PruneHubEvents(ctx, 1, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned, "all but the newest")
+	pruned, err = store.PruneHubEvents(ctx, 0, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, pruned, "the newest event is always kept")
+
+	first, last, err = store.HubEventSeqRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), first)
+	assert.Equal(t, int64(4), last)
+}
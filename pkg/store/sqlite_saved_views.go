@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Saved view methods
+
+const savedViewColumns = `id, project, name, query, created_by, created_at, updated_at`
+
+// GetSavedView returns a saved view by ID, or nil when it does not exist.
+func (s *SQLiteStore) GetSavedView(ctx context.Context, id uuid.UUID) (*models.SavedView, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+savedViewColumns+` FROM saved_views WHERE id = ?`, id.String())
+	v, err := scanSavedView(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return v, err
+}
+
+// CountProjectSavedViews returns the number of saved views in a project.
+func (s *SQLiteStore) CountProjectSavedViews(ctx context.Context, project string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_views WHERE project = ?`, project).Scan(&count)
+	return count, err
+}
+
+// ListSavedViews returns a page of the saved views shared within a project,
+// ordered by name. Pass 0 for limit to use the store default.
+func (s *SQLiteStore) ListSavedViews(ctx context.Context, project string, limit, offset int) ([]models.SavedView, error) {
+	lim := resolvePageLimit(limit, defaultPageLimit)
+	off := resolvePageOffset(offset)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+savedViewColumns+` FROM saved_views WHERE project = ? ORDER BY name ASC, id ASC LIMIT ? OFFSET ?`, project, lim, off)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make([]models.SavedView, 0)
+	for rows.Next() {
+		v, err := scanSavedView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *v)
+	}
+	return views, rows.Err()
+}
+
+// CreateSavedView inserts a new saved view. Returns ErrSavedViewNameTaken
+// when the project already has a view with the same name.
+func (s *SQLiteStore) CreateSavedView(ctx context.Context, view *models.SavedView) error {
+	if view.ID == uuid.Nil {
+		view.ID = uuid.New()
+	}
+	view.CreatedAt = time.Now()
+
+	query, err := json.Marshal(view.Query)
+	if err != nil {
+		return fmt.Errorf("marshal saved view query: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO saved_views (id, project, name, query, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		view.ID.String(), view.Project, view.Name, string(query), view.CreatedBy.String(), view.CreatedAt)
+	return mapSavedViewErr(err)
+}
+
+// UpdateSavedView replaces the name and query of an existing saved view.
+// Returns ErrNotFound when the view does not exist and ErrSavedViewNameTaken
+// when the new name collides with another view in the project.
+func (s *SQLiteStore) UpdateSavedView(ctx context.Context, view *models.SavedView) error {
+	now := time.Now()
+	view.UpdatedAt = &now
+
+	query, err := json.Marshal(view.Query)
+	if err != nil {
+		return fmt.Errorf("marshal saved view query: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE saved_views SET name = ?, query = ?, updated_at = ? WHERE id = ?`,
+		view.Name, string(query), view.UpdatedAt, view.ID.String())
+	if err != nil {
+		return mapSavedViewErr(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSavedView removes a saved view.
+func (s *SQLiteStore) DeleteSavedView(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM saved_views WHERE id = ?`, id.String())
+	return err
+}
+
+// scanSavedView decodes a saved_views row from either *sql.Row or *sql.Rows.
+func scanSavedView(row interface {
+	Scan(dest ...any) error
+}) (*models.SavedView, error) {
+	var v models.SavedView
+	var idStr, createdByStr, query string
+	var updatedAt sql.NullTime
+
+	if err := row.Scan(&idStr, &v.Project, &v.Name, &query, &createdByStr, &v.CreatedAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(query), &v.Query); err != nil {
+		return nil, fmt.Errorf("unmarshal saved view query: %w", err)
+	}
+	v.ID = parseUUID(idStr, "v.ID")
+	v.CreatedBy = parseUUID(createdByStr, "v.CreatedBy")
+	if updatedAt.Valid {
+		v.UpdatedAt = &updatedAt.Time
+	}
+	return &v, nil
+}
+
+// mapSavedViewErr translates the (project, name) uniqueness violation into
+// ErrSavedViewNameTaken so handlers do not need to inspect driver errors.
+func mapSavedViewErr(err error) error {
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return ErrSavedViewNameTaken
+	}
+	return err
+}
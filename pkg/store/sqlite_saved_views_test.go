@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSavedView(project, name string) *models.SavedView {
+	return &models.SavedView{
+		Project: project,
+		Name:    name,
+		Query: models.SavedViewQuery{
+			Version:       "v1",
+			Resource:      "pods",
+			ClusterGroup:  "prod",
+			LabelSelector: "gpu=true",
+			Columns:       []string{"Name", "Status"},
+		},
+		CreatedBy: uuid.New(),
+	}
+}
+
+func TestSavedViews_CRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	view := newTestSavedView("kubestellar", "GPU pods")
+	require.NoError(t, s.CreateSavedView(ctx, view))
+	require.NotEqual(t, uuid.Nil, view.ID)
+
+	got, err := s.GetSavedView(ctx, view.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "GPU pods", got.Name)
+	assert.Equal(t, view.Query, got.Query)
+	assert.Equal(t, view.CreatedBy, got.CreatedBy)
+	assert.Nil(t, got.UpdatedAt)
+
+	got.Name = "Prod GPU pods"
+	got.Query.Namespace = "ml"
+	require.NoError(t, s.UpdateSavedView(ctx, got))
+
+	got, err = s.GetSavedView(ctx, view.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Prod GPU pods", got.Name)
+	assert.Equal(t, "ml", got.Query.Namespace)
+	assert.NotNil(t, got.UpdatedAt)
+
+	require.NoError(t, s.DeleteSavedView(ctx, view.ID))
+	got, err = s.GetSavedView(ctx, view.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestSavedViews_ListScopedToProject(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateSavedView(ctx, newTestSavedView("kubestellar", "b")))
+	require.NoError(t, s.CreateSavedView(ctx, newTestSavedView("kubestellar", "a")))
+	require.NoError(t, s.CreateSavedView(ctx, newTestSavedView("istio", "c")))
+
+	views, err := s.ListSavedViews(ctx, "kubestellar", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, "a", views[0].Name)
+	assert.Equal(t, "b", views[1].Name)
+
+	count, err := s.CountProjectSavedViews(ctx, "istio")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestSavedViews_NameUniquePerProject(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateSavedView(ctx, newTestSavedView("kubestellar", "dup")))
+	err := s.CreateSavedView(ctx, newTestSavedView("kubestellar", "dup"))
+	assert.ErrorIs(t, err, ErrSavedViewNameTaken)
+
+	// The same name in another project is fine.
+	require.NoError(t, s.CreateSavedView(ctx, newTestSavedView("istio", "dup")))
+
+	other := newTestSavedView("kubestellar", "other")
+	require.NoError(t, s.CreateSavedView(ctx, other))
+	other.Name = "dup"
+	assert.ErrorIs(t, s.UpdateSavedView(ctx, other), ErrSavedViewNameTaken)
+}
+
+func TestSavedViews_UpdateMissing(t *testing.T) {
+	s := newTestStore(t)
+	view := newTestSavedView("kubestellar", "ghost")
+	view.ID = uuid.New()
+	assert.ErrorIs(t, s.UpdateSavedView(context.Background(), view), ErrNotFound)
+}
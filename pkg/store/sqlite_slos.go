@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// SLO methods
+
+const sloColumns = `id, project, name, description, kind, target, indicator, objective, window_days, alerts_enabled, created_by, created_at, updated_at`
+
+// GetSLO returns an SLO by ID, or nil when it does not exist.
+func (s *SQLiteStore) GetSLO(ctx context.Context, id uuid.UUID) (*models.SLO, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+sloColumns+` FROM slos WHERE id = ?`, id.String())
+	slo, err := scanSLO(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return slo, err
+}
+
+// CountProjectSLOs returns the number of SLOs in a project.
+func (s *SQLiteStore) CountProjectSLOs(ctx context.Context, project string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM slos WHERE project = ?`, project).Scan(&count)
+	return count, err
+}
+
+// ListSLOs returns a page of the SLOs defined in a project, ordered by name.
+// Pass 0 for limit to use the store default.
+func (s *SQLiteStore) ListSLOs(ctx context.Context, project string, limit, offset int) ([]models.SLO, error) {
+	lim := resolvePageLimit(limit, defaultPageLimit)
+	off := resolvePageOffset(offset)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+sloColumns+` FROM slos WHERE project = ? ORDER BY name ASC, id ASC LIMIT ? OFFSET ?`, project, lim, off)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slos := make([]models.SLO, 0)
+	for rows.Next() {
+		slo, err := scanSLO(rows)
+		if err != nil {
+			return nil, err
+		}
+		slos = append(slos, *slo)
+	}
+	return slos, rows.Err()
+}
+
+// CreateSLO inserts a new SLO. Returns ErrSLONameTaken when the project
+// already has an SLO with the same name.
+func (s *SQLiteStore) CreateSLO(ctx context.Context, slo *models.SLO) error {
+	if slo.ID == uuid.Nil {
+		slo.ID = uuid.New()
+	}
+	slo.CreatedAt = time.Now()
+
+	target, indicator, err := marshalSLOSpec(slo)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO slos (id, project, name, description, kind, target, indicator, objective, window_days, alerts_enabled, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		slo.ID.String(), slo.Project, slo.Name, slo.Description, string(slo.Kind), target, indicator,
+		slo.Objective, slo.WindowDays, slo.AlertsEnabled, slo.CreatedBy.String(), slo.CreatedAt)
+	return mapSLOErr(err)
+}
+
+// UpdateSLO replaces the definition of an existing SLO. Returns ErrNotFound
+// when the SLO does not exist and ErrSLONameTaken when the new name collides
+// with another SLO in the project.
+func (s *SQLiteStore) UpdateSLO(ctx context.Context, slo *models.SLO) error {
+	now := time.Now()
+	slo.UpdatedAt = &now
+
+	target, indicator, err := marshalSLOSpec(slo)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE slos SET name = ?, description = ?, kind = ?, target = ?, indicator = ?, objective = ?, window_days = ?, alerts_enabled = ?, updated_at = ? WHERE id = ?`,
+		slo.Name, slo.Description, string(slo.Kind), target, indicator,
+		slo.Objective, slo.WindowDays, slo.AlertsEnabled, slo.UpdatedAt, slo.ID.String())
+	if err != nil {
+		return mapSLOErr(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSLO removes an SLO.
+func (s *SQLiteStore) DeleteSLO(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM slos WHERE id = ?`, id.String())
+	return err
+}
+
+func marshalSLOSpec(slo *models.SLO) (target, indicator string, err error) {
+	t, err := json.Marshal(slo.Target)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal slo target: %w", err)
+	}
+	i, err := json.Marshal(slo.Indicator)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal slo indicator: %w", err)
+	}
+	return string(t), string(i), nil
+}
+
+// scanSLO decodes a slos row from either *sql.Row or *sql.Rows.
+func scanSLO(row interface {
+	Scan(dest ...any) error
+}) (*models.SLO, error) {
+	var slo models.SLO
+	var idStr, createdByStr, kind, target, indicator string
+	var updatedAt sql.NullTime
+
+	if err := row.Scan(&idStr, &slo.Project, &slo.Name, &slo.Description, &kind, &target, &indicator,
+		&slo.Objective, &slo.WindowDays, &slo.AlertsEnabled, &createdByStr, &slo.CreatedAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(target), &slo.Target); err != nil {
+		return nil, fmt.Errorf("unmarshal slo target: %w", err)
+	}
+	if err := json.Unmarshal([]byte(indicator), &slo.Indicator); err != nil {
+		return nil, fmt.Errorf("unmarshal slo indicator: %w", err)
+	}
+	slo.Kind = models.SLOKind(kind)
+	slo.ID = parseUUID(idStr, "slo.ID")
+	slo.CreatedBy = parseUUID(createdByStr, "slo.CreatedBy")
+	if updatedAt.Valid {
+		slo.UpdatedAt = &updatedAt.Time
+	}
+	return &slo, nil
+}
+
+// mapSLOErr translates the (project, name) uniqueness violation into
+// ErrSLONameTaken so handlers do not need to inspect driver errors.
+func mapSLOErr(err error) error {
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return ErrSLONameTaken
+	}
+	return err
+}
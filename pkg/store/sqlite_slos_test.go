@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSLO(project, name string) *models.SLO {
+	return &models.SLO{
+		Project: project,
+		Name:    name,
+		Kind:    models.SLOKindLatency,
+		Target: models.SLOTarget{
+			Cluster:   "prod-east",
+			Namespace: "llm",
+			Workload:  "vllm-llama",
+		},
+		Indicator:     models.SLOIndicator{Metric: "vllm:e2e_request_latency_seconds", ThresholdSeconds: 2.5},
+		Objective:     0.99,
+		WindowDays:    30,
+		AlertsEnabled: true,
+		CreatedBy:     uuid.New(),
+	}
+}
+
+func TestSLOs_CRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	slo := newTestSLO("kubestellar", "vLLM latency")
+	require.NoError(t, s.CreateSLO(ctx, slo))
+	require.NotEqual(t, uuid.Nil, slo.ID)
+
+	got, err := s.GetSLO(ctx, slo.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "vLLM latency", got.Name)
+	assert.Equal(t, models.SLOKindLatency, got.Kind)
+	assert.Equal(t, slo.Target, got.Target)
+	assert.Equal(t, slo.Indicator, got.Indicator)
+	assert.Equal(t, 0.99, got.Objective)
+	assert.True(t, got.AlertsEnabled)
+	assert.Nil(t, got.UpdatedAt)
+
+	got.Objective = 0.995
+	got.AlertsEnabled = false
+	got.Target.Namespace = "inference"
+	require.NoError(t, s.UpdateSLO(ctx, got))
+
+	got, err = s.GetSLO(ctx, slo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0.995, got.Objective)
+	assert.False(t, got.AlertsEnabled)
+	assert.Equal(t, "inference", got.Target.Namespace)
+	assert.NotNil(t, got.UpdatedAt)
+
+	require.NoError(t, s.DeleteSLO(ctx, slo.ID))
+	got, err = s.GetSLO(ctx, slo.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	assert.ErrorIs(t, s.UpdateSLO(ctx, slo), ErrNotFound)
+}
+
+func TestSLOs_ListScopedToProject(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateSLO(ctx, newTestSLO("kubestellar", "b")))
+	require.NoError(t, s.CreateSLO(ctx, newTestSLO("kubestellar", "a")))
+	require.NoError(t, s.CreateSLO(ctx, newTestSLO("istio", "c")))
+
+	slos, err := s.ListSLOs(ctx, "kubestellar", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, slos, 2)
+	assert.Equal(t, "a", slos[0].Name)
+	assert.Equal(t, "b", slos[1].Name)
+
+	count, err := s.CountProjectSLOs(ctx, "istio")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestSLOs_NameUniquePerProject(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateSLO(ctx, newTestSLO("kubestellar", "dup")))
+	assert.ErrorIs(t, s.CreateSLO(ctx, newTestSLO("kubestellar", "dup")), ErrSLONameTaken)
+	require.NoError(t, s.CreateSLO(ctx, newTestSLO("istio", "dup")))
+
+	other := newTestSLO("kubestellar", "other")
+	require.NoError(t, s.CreateSLO(ctx, other))
+	other.Name = "dup"
+	assert.ErrorIs(t, s.UpdateSLO(ctx, other), ErrSLONameTaken)
+}
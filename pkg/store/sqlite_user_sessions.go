@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// User session methods
+
+const userSessionColumns = `id, user_id, device, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+// CreateSession stores a new login session and stamps its ID, CreatedAt and
+// LastSeenAt. ExpiresAt is the absolute lifetime chosen by the caller.
+func (s *SQLiteStore) CreateSession(ctx context.Context, session *models.UserSession) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	now := time.Now().UTC()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	session.ExpiresAt = session.ExpiresAt.UTC()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO user_sessions (id, user_id, device, user_agent, ip, created_at, last_seen_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.ID.String(), session.UserID.String(), session.Device, session.UserAgent, session.IP,
+		session.CreatedAt, session.LastSeenAt, session.ExpiresAt)
+	return err
+}
+
+// GetSession returns a session by ID, or nil when it does not exist. Revoked
+// and expired sessions are still returned until they are purged; callers
+// check RevokedAt and ExpiresAt.
+func (s *SQLiteStore) GetSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+userSessionColumns+` FROM user_sessions WHERE id = ?`, id.String())
+	return scanUserSession(row)
+}
+
+// ListUserSessions returns a user's sessions that are neither revoked nor
+// past their absolute expiry at now, most recently active first.
+func (s *SQLiteStore) ListUserSessions(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.UserSession, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+userSessionColumns+` FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC`,
+		userID.String(), now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.UserSession{}
+	for rows.Next() {
+		session, err := scanUserSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchSession records activity on a session. An empty ip keeps the stored one.
+func (s *SQLiteStore) TouchSession(ctx context.Context, id uuid.UUID, ip string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE user_sessions SET last_seen_at = ?, ip = CASE WHEN ? = '' THEN ip ELSE ? END WHERE id = ?`,
+		at.UTC(), ip, ip, id.String())
+	return err
+}
+
+// RevokeSession marks a session revoked and reports whether it was active.
+// Revoking an already revoked session keeps the first revocation time.
+func (s *SQLiteStore) RevokeSession(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE user_sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, at.UTC(), id.String())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RevokeUserSessions revokes every active session of a user except keep
+// (uuid.Nil keeps none) and returns the IDs it revoked.
+func (s *SQLiteStore) RevokeUserSessions(ctx context.Context, userID, keep uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`UPDATE user_sessions SET revoked_at = ?
+		WHERE user_id = ? AND id != ? AND revoked_at IS NULL RETURNING id`,
+		at.UTC(), userID.String(), keep.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, parseUUID(id, "session.ID"))
+	}
+	return ids, rows.Err()
+}
+
+// DeleteExpiredSessions removes sessions that expired before cutoff, were
+// last active before idleCutoff (zero skips the idle check), or were revoked,
+// and returns how many were removed. A missing session rejects its tokens
+// just like a revoked one, so purging revoked rows is safe.
+func (s *SQLiteStore) DeleteExpiredSessions(ctx context.Context, cutoff, idleCutoff time.Time) (int64, error) {
+	query := `DELETE FROM user_sessions WHERE expires_at < ? OR revoked_at IS NOT NULL`
+	args := []any{cutoff.UTC()}
+	if !idleCutoff.IsZero() {
+		query += ` OR last_seen_at < ?`
+		args = append(args, idleCutoff.UTC())
+	}
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanUserSession(row rowScanner) (*models.UserSession, error) {
+	var session models.UserSession
+	var id, userID string
+	var revokedAt sql.NullTime
+	err := row.Scan(&id, &userID, &session.Device, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.ID = parseUUID(id, "session.ID")
+	session.UserID = parseUUID(userID, "session.UserID")
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
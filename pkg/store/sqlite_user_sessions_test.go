@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/models"
+)
+
+func TestSQLiteUserSessions(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	got, err := store.GetSession(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	now := time.Now()
+	userID, otherUser := uuid.New(), uuid.New()
+	laptop := &models.UserSession{UserID: userID, Device: "Firefox on Linux", UserAgent: "Mozilla/5.0", IP: "10.0.0.1", ExpiresAt: now.Add(time.Hour)}
+	phone := &models.UserSession{UserID: userID, Device: "Safari on iOS", IP: "10.0.0.2", ExpiresAt: now.Add(time.Hour)}
+	expired := &models.UserSession{UserID: userID, ExpiresAt: now.Add(-time.Minute)}
+	foreign := &models.UserSession{UserID: otherUser, ExpiresAt: now.Add(time.Hour)}
+	for _, s := range []*models.UserSession{laptop, phone, expired, foreign} {
+		require.NoError(t, store.CreateSession(ctx, s))
+		require.NotEqual(t, uuid.Nil, s.ID)
+	}
+
+	got, err = store.GetSession(ctx, laptop.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, userID, got.UserID)
+	assert.Equal(t, "Firefox on Linux", got.Device)
+	assert.Equal(t, "10.0.0.1", got.IP)
+	assert.Nil(t, got.RevokedAt)
+
+	// Touching the laptop makes it the most recently active session; an
+	// empty IP keeps the stored address.
+	require.NoError(t, store.TouchSession(ctx, laptop.ID, "", now.Add(time.Minute)))
+	sessions, err := store.ListUserSessions(ctx, userID, now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2, "expired sessions and other users' sessions are not listed")
+	assert.Equal(t, laptop.ID, sessions[0].ID)
+	assert.Equal(t, "10.0.0.1", sessions[0].IP)
+	require.NoError(t, store.TouchSession(ctx, laptop.ID, "192.0.2.7", now.Add(2*time.Minute)))
+	got, err = store.GetSession(ctx, laptop.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.7", got.IP)
+
+	revoked, err := store.RevokeSession(ctx, phone.ID, now)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = store.RevokeSession(ctx, phone.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, revoked, "revoking twice reports the session as already inactive")
+	got, err = store.GetSession(ctx, phone.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.RevokedAt)
+	assert.WithinDuration(t, now, *got.RevokedAt, time.Second)
+
+	second := &models.UserSession{UserID: userID, ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, store.CreateSession(ctx, second))
+	ids, err := store.RevokeUserSessions(ctx, userID, laptop.ID, now)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{second.ID, expired.ID}, ids, "already revoked and kept sessions are skipped")
+	sessions, err = store.ListUserSessions(ctx, userID, now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, laptop.ID, sessions[0].ID)
+
+	n, err := store.DeleteExpiredSessions(ctx, now, time.Time{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, n, "expired and revoked sessions are purged")
+	n, err = store.DeleteExpiredSessions(ctx, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n, "sessions idle since before the idle cutoff are purged")
+	got, err = store.GetSession(ctx, foreign.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
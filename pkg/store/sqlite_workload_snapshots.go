@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kubestellar/console/pkg/models"
+)
+
+// Workload snapshot methods
+
+const workloadSnapshotColumns = `id, cluster, kind, namespace, name, description, redacted, contents, missing, created_by, created_at`
+
+// CreateWorkloadSnapshot stores a new snapshot, assigning its ID and
+// creation time.
+func (s *SQLiteStore) CreateWorkloadSnapshot(ctx context.Context, snap *models.WorkloadSnapshot) error {
+	if snap.ID == uuid.Nil {
+		snap.ID = uuid.New()
+	}
+	snap.CreatedAt = time.Now()
+
+	contents, err := json.Marshal(snap.Contents)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot contents: %w", err)
+	}
+	missing, err := json.Marshal(snap.Missing)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot missing: %w", err)
+	}
+	objects, err := json.Marshal(snap.Objects)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot objects: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO workload_snapshots (`+workloadSnapshotColumns+`, objects) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		snap.ID.String(), snap.Cluster, snap.Kind, snap.Namespace, snap.Name, snap.Description, snap.Redacted,
+		string(contents), string(missing), snap.CreatedBy, snap.CreatedAt, string(objects))
+	return err
+}
+
+// GetWorkloadSnapshot returns a snapshot including its objects, or nil when
+// it does not exist.
+func (s *SQLiteStore) GetWorkloadSnapshot(ctx context.Context, id uuid.UUID) (*models.WorkloadSnapshot, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+workloadSnapshotColumns+`, objects FROM workload_snapshots WHERE id = ?`, id.String())
+	var objects string
+	snap, err := scanWorkloadSnapshot(row, &objects)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(objects), &snap.Objects); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot objects: %w", err)
+	}
+	return snap, nil
+}
+
+// CountWorkloadSnapshots returns how many snapshots exist for one workload.
+func (s *SQLiteStore) CountWorkloadSnapshots(ctx context.Context, cluster, kind, namespace, name string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM workload_snapshots WHERE cluster = ? AND kind = ? AND namespace = ? AND name = ?`,
+		cluster, kind, namespace, name).Scan(&count)
+	return count, err
+}
+
+// ListWorkloadSnapshots returns a workload's snapshots, newest first, without
+// their objects. Pass 0 for limit to use the store default.
+func (s *SQLiteStore) ListWorkloadSnapshots(ctx context.Context, cluster, kind, namespace, name string, limit int) ([]models.WorkloadSnapshot, error) {
+	lim := resolvePageLimit(limit, defaultPageLimit)
+	rows, err := s.db.QueryContext(ctx, `SELECT `+workloadSnapshotColumns+` FROM workload_snapshots
+		WHERE cluster = ? AND kind = ? AND namespace = ? AND name = ? ORDER BY created_at DESC, id ASC LIMIT ?`,
+		cluster, kind, namespace, name, lim)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snaps := make([]models.WorkloadSnapshot, 0)
+	for rows.Next() {
+		snap, err := scanWorkloadSnapshot(rows, nil)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, *snap)
+	}
+	return snaps, rows.Err()
+}
+
+// DeleteWorkloadSnapshot removes a snapshot.
+func (s *SQLiteStore) DeleteWorkloadSnapshot(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM workload_snapshots WHERE id = ?`, id.String())
+	return err
+}
+
+// scanWorkloadSnapshot decodes a workload_snapshots row. When objects is
+// non-nil the row carries the objects column last and it is scanned into
+// objects undecoded.
+func scanWorkloadSnapshot(row interface {
+	Scan(dest ...any) error
+}, objects *string) (*models.WorkloadSnapshot, error) {
+	var snap models.WorkloadSnapshot
+	var idStr, contents, missing string
+	dest := []any{&idStr, &snap.Cluster, &snap.Kind, &snap.Namespace, &snap.Name, &snap.Description, &snap.Redacted,
+		&contents, &missing, &snap.CreatedBy, &snap.CreatedAt}
+	if objects != nil {
+		dest = append(dest, objects)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(contents), &snap.Contents); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot contents: %w", err)
+	}
+	if err := json.Unmarshal([]byte(missing), &snap.Missing); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot missing: %w", err)
+	}
+	snap.ID = parseUUID(idStr, "workloadSnapshot.ID")
+	return &snap, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteWorkloadSnapshots(t *testing.T) {
+	store := OpenTestDB(t)
+	ctx := context.Background()
+
+	snap := &models.WorkloadSnapshot{
+		Cluster: "prod", Kind: "Deployment", Namespace: "shop", Name: "web",
+		Description: "before migration", Redacted: true,
+		Contents:  []string{"ConfigMap/web-config", "Deployment/web"},
+		Missing:   []string{"Secret/web-tls"},
+		Objects:   []map[string]interface{}{{"kind": "ConfigMap"}, {"kind": "Deployment"}},
+		CreatedBy: "alex",
+	}
+	require.NoError(t, store.CreateWorkloadSnapshot(ctx, snap))
+	require.NoError(t, store.CreateWorkloadSnapshot(ctx, &models.WorkloadSnapshot{
+		Cluster: "prod", Kind: "Deployment", Namespace: "shop", Name: "api", Objects: []map[string]interface{}{},
+	}))
+
+	got, err := store.GetWorkloadSnapshot(ctx, snap.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "before migration", got.Description)
+	assert.True(t, got.Redacted)
+	assert.Equal(t, snap.Contents, got.Contents)
+	assert.Equal(t, snap.Missing, got.Missing)
+	assert.Equal(t, snap.Objects, got.Objects)
+
+	count, err := store.CountWorkloadSnapshots(ctx, "prod", "Deployment", "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	list, err := store.ListWorkloadSnapshots(ctx, "prod", "Deployment", "shop", "web", 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, snap.ID, list[0].ID)
+	assert.Nil(t, list[0].Objects, "listings omit the objects")
+
+	require.NoError(t, store.DeleteWorkloadSnapshot(ctx, snap.ID))
+	got, err = store.GetWorkloadSnapshot(ctx, snap.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
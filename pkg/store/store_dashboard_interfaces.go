@@ -17,9 +17,18 @@ type DashboardStore interface {
 	CreateDashboard(ctx context.Context, dashboard *models.Dashboard) error
 	ImportDashboardAtomic(ctx context.Context, dashboard *models.Dashboard, cards []*models.Card, maxCards int) error
 	UpdateDashboard(ctx context.Context, dashboard *models.Dashboard) error
+	UpdateDashboardIfVersion(ctx context.Context, dashboard *models.Dashboard, expectedVersion int) error
 	DeleteDashboard(ctx context.Context, id uuid.UUID) error
 }
 
+// DashboardSnapshotStore manages immutable read-only dashboard snapshots.
+type DashboardSnapshotStore interface {
+	CreateDashboardSnapshot(ctx context.Context, snapshot *models.DashboardSnapshot) error
+	GetDashboardSnapshot(ctx context.Context, id uuid.UUID) (*models.DashboardSnapshot, error)
+	CountUserDashboardSnapshots(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	DeleteExpiredDashboardSnapshots(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
 // CardStore manages dashboard cards.
 type CardStore interface {
 	GetCard(ctx context.Context, id uuid.UUID) (*models.Card, error)
@@ -47,3 +56,14 @@ type PendingSwapStore interface {
 	UpdateSwapStatus(ctx context.Context, id uuid.UUID, status models.SwapStatus) error
 	SnoozeSwap(ctx context.Context, id uuid.UUID, newSwapAt time.Time) error
 }
+
+// SavedViewStore manages saved cross-cluster resource views shared within a
+// console project.
+type SavedViewStore interface {
+	GetSavedView(ctx context.Context, id uuid.UUID) (*models.SavedView, error)
+	CountProjectSavedViews(ctx context.Context, project string) (int, error)
+	ListSavedViews(ctx context.Context, project string, limit, offset int) ([]models.SavedView, error)
+	CreateSavedView(ctx context.Context, view *models.SavedView) error
+	UpdateSavedView(ctx context.Context, view *models.SavedView) error
+	DeleteSavedView(ctx context.Context, id uuid.UUID) error
+}
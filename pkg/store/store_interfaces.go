@@ -7,19 +7,28 @@ type Store interface {
 	TeamStore
 	OnboardingStore
 	DashboardStore
+	DashboardSnapshotStore
 	CardStore
 	CardHistoryStore
 	PendingSwapStore
+	SavedViewStore
 	FeatureRequestStore
 	PRFeedbackStore
 	NotificationStore
 	GPUReservationStore
 	GPUUtilizationStore
+	SLOStore
 	AuditStore
 	AuthStore
+	AgentPairingStore
 	RewardsStore
 	EventStore
 	ClusterGroupStore
+	ClusterTimezoneStore
+	ChangePolicyStore
+	WorkloadSnapshotStore
+	HubEventStore
+	BenchmarkAnnotationStore
 	KBGapStore
 	TransactionStore
 	LifecycleStore
@@ -32,19 +41,24 @@ var (
 	_ TeamStore                  = (*SQLiteStore)(nil)
 	_ OnboardingStore            = (*SQLiteStore)(nil)
 	_ DashboardStore             = (*SQLiteStore)(nil)
+	_ DashboardSnapshotStore     = (*SQLiteStore)(nil)
 	_ CardStore                  = (*SQLiteStore)(nil)
 	_ CardHistoryStore           = (*SQLiteStore)(nil)
 	_ PendingSwapStore           = (*SQLiteStore)(nil)
+	_ SavedViewStore             = (*SQLiteStore)(nil)
 	_ FeatureRequestStore        = (*SQLiteStore)(nil)
 	_ PRFeedbackStore            = (*SQLiteStore)(nil)
 	_ NotificationStore          = (*SQLiteStore)(nil)
 	_ GPUReservationStore        = (*SQLiteStore)(nil)
 	_ GPUUtilizationStore        = (*SQLiteStore)(nil)
+	_ SLOStore                   = (*SQLiteStore)(nil)
 	_ AuditStore                 = (*SQLiteStore)(nil)
 	_ TokenStore                 = (*SQLiteStore)(nil)
 	_ OAuthCredentialStore       = (*SQLiteStore)(nil)
 	_ OAuthStateStore            = (*SQLiteStore)(nil)
+	_ SessionStore               = (*SQLiteStore)(nil)
 	_ AuthStore                  = (*SQLiteStore)(nil)
+	_ AgentPairingStore          = (*SQLiteStore)(nil)
 	_ UserRewardsStore           = (*SQLiteStore)(nil)
 	_ UserTokenUsageStore        = (*SQLiteStore)(nil)
 	_ RewardsStore               = (*SQLiteStore)(nil)
@@ -52,6 +66,11 @@ var (
 	_ ClusterEventStore          = (*SQLiteStore)(nil)
 	_ EventStore                 = (*SQLiteStore)(nil)
 	_ ClusterGroupStore          = (*SQLiteStore)(nil)
+	_ ClusterTimezoneStore       = (*SQLiteStore)(nil)
+	_ ChangePolicyStore          = (*SQLiteStore)(nil)
+	_ WorkloadSnapshotStore      = (*SQLiteStore)(nil)
+	_ HubEventStore              = (*SQLiteStore)(nil)
+	_ BenchmarkAnnotationStore   = (*SQLiteStore)(nil)
 	_ KBGapStore                 = (*SQLiteStore)(nil)
 	_ TransactionStore           = (*SQLiteStore)(nil)
 	_ LifecycleStore             = (*SQLiteStore)(nil)
@@ -65,6 +65,16 @@ type GPUUtilizationStore interface {
 	DeleteOldUtilizationSnapshots(ctx context.Context, before time.Time) (int64, error)
 }
 
+// SLOStore manages service level objectives shared within a console project.
+type SLOStore interface {
+	GetSLO(ctx context.Context, id uuid.UUID) (*models.SLO, error)
+	CountProjectSLOs(ctx context.Context, project string) (int, error)
+	ListSLOs(ctx context.Context, project string, limit, offset int) ([]models.SLO, error)
+	CreateSLO(ctx context.Context, slo *models.SLO) error
+	UpdateSLO(ctx context.Context, slo *models.SLO) error
+	DeleteSLO(ctx context.Context, id uuid.UUID) error
+}
+
 // AuditStore manages security audit log access.
 type AuditStore interface {
 	InsertAuditLog(ctx context.Context, userID, action, detail string) error
@@ -91,11 +101,31 @@ type OAuthStateStore interface {
 	CleanupExpiredOAuthStates(ctx context.Context) (int64, error)
 }
 
+// SessionStore manages server-side login sessions.
+type SessionStore interface {
+	CreateSession(ctx context.Context, session *models.UserSession) error
+	GetSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.UserSession, error)
+	TouchSession(ctx context.Context, id uuid.UUID, ip string, at time.Time) error
+	RevokeSession(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID, keep uuid.UUID, at time.Time) ([]uuid.UUID, error)
+	DeleteExpiredSessions(ctx context.Context, cutoff, idleCutoff time.Time) (int64, error)
+}
+
+// AgentPairingStore manages the single-use codes kc-agents trade for a relay
+// client certificate.
+type AgentPairingStore interface {
+	CreateAgentPairingCode(ctx context.Context, codeHash, agentID string, expiresAt time.Time) error
+	ConsumeAgentPairingCode(ctx context.Context, codeHash string, now time.Time) (string, error)
+	DeleteExpiredAgentPairingCodes(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
 // AuthStore keeps the legacy aggregate auth persistence contract.
 type AuthStore interface {
 	TokenStore
 	OAuthCredentialStore
 	OAuthStateStore
+	SessionStore
 }
 
 // UserRewardsStore manages persisted gamification balances.
@@ -145,6 +175,48 @@ type ClusterGroupStore interface {
 	ListClusterGroups(ctx context.Context) (map[string][]byte, error)
 }
 
+// ClusterTimezoneStore manages the timezone metadata deployment windows are
+// evaluated in.
+type ClusterTimezoneStore interface {
+	SetClusterTimezone(ctx context.Context, cluster, timezone string) error
+	GetClusterTimezone(ctx context.Context, cluster string) (string, error)
+	ListClusterTimezones(ctx context.Context) (map[string]string, error)
+	DeleteClusterTimezone(ctx context.Context, cluster string) error
+}
+
+// ChangePolicyStore manages the per-project requirements for WorkloadDeployment
+// change metadata.
+type ChangePolicyStore interface {
+	GetChangePolicy(ctx context.Context, project string) (*models.ChangePolicy, error)
+	SaveChangePolicy(ctx context.Context, policy *models.ChangePolicy) error
+}
+
+// WorkloadSnapshotStore manages captured workload state used for restores.
+type WorkloadSnapshotStore interface {
+	CreateWorkloadSnapshot(ctx context.Context, snap *models.WorkloadSnapshot) error
+	GetWorkloadSnapshot(ctx context.Context, id uuid.UUID) (*models.WorkloadSnapshot, error)
+	CountWorkloadSnapshots(ctx context.Context, cluster, kind, namespace, name string) (int, error)
+	ListWorkloadSnapshots(ctx context.Context, cluster, kind, namespace, name string, limit int) ([]models.WorkloadSnapshot, error)
+	DeleteWorkloadSnapshot(ctx context.Context, id uuid.UUID) error
+}
+
+// HubEventStore keeps recent WebSocket hub broadcasts for replay to clients
+// that reconnect after missing them.
+type HubEventStore interface {
+	AppendHubEvents(ctx context.Context, events []models.HubEvent) error
+	ListHubEvents(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]models.HubEvent, error)
+	HubEventSeqRange(ctx context.Context) (first, last int64, err error)
+	PruneHubEvents(ctx context.Context, keep int, before time.Time) (int64, error)
+}
+
+// BenchmarkAnnotationStore manages stars, notes and labels on benchmark runs.
+type BenchmarkAnnotationStore interface {
+	GetBenchmarkAnnotation(ctx context.Context, reportUID string) (*models.BenchmarkAnnotation, error)
+	ListBenchmarkAnnotations(ctx context.Context) ([]models.BenchmarkAnnotation, error)
+	SaveBenchmarkAnnotation(ctx context.Context, annotation *models.BenchmarkAnnotation) error
+	DeleteBenchmarkAnnotation(ctx context.Context, reportUID string) error
+}
+
 // KBGapStore manages recorded knowledge-base misses.
 type KBGapStore interface {
 	RecordKBGap(ctx context.Context, path string) error
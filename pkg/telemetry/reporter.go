@@ -0,0 +1,134 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kubestellar/console/pkg/safego"
+	"github.com/kubestellar/console/pkg/ssrf"
+)
+
+const (
+	// defaultReportInterval is how often reports are sent.
+	defaultReportInterval = 24 * time.Hour
+	// minReportInterval stops KC_TELEMETRY_INTERVAL from turning telemetry
+	// into a firehose.
+	minReportInterval = time.Minute
+	// envInterval overrides defaultReportInterval with a Go duration.
+	envInterval = "KC_TELEMETRY_INTERVAL"
+
+	// sendTimeout bounds each report POST, including the cluster count.
+	sendTimeout = 30 * time.Second
+)
+
+// endpointValidator rejects endpoints that resolve to private or internal
+// addresses. Tests override it to allow loopback servers.
+var endpointValidator = ssrf.ValidateURL
+
+func reportInterval() time.Duration {
+	raw := os.Getenv(envInterval)
+	if raw == "" {
+		return defaultReportInterval
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < minReportInterval {
+		slog.Warn("[Telemetry] invalid "+envInterval+", using default",
+			"value", raw, "min", minReportInterval, "default", defaultReportInterval)
+		return defaultReportInterval
+	}
+	return d
+}
+
+// Start sends a report every interval until Stop is called. The opt-in is
+// checked on every tick, so enabling or disabling telemetry takes effect
+// without a restart.
+func (s *Service) Start() {
+	safego.GoWith("telemetry-reporter", func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+				if err := s.Flush(ctx); err != nil {
+					slog.Warn("[Telemetry] failed to send usage report", "error", err)
+				}
+				cancel()
+			case <-s.stopCh:
+				return
+			}
+		}
+	})
+}
+
+// Stop ends the reporting loop. It is safe to call multiple times.
+func (s *Service) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+// Flush sends the current report and resets the aggregate on success. It is a
+// no-op while telemetry is off. On failure the counts are kept and included
+// in the next attempt.
+func (s *Service) Flush(ctx context.Context) error {
+	if !s.Active() {
+		return nil
+	}
+	endpoint := s.store.GetTelemetry().Endpoint
+	report := s.Preview(ctx)
+
+	err := s.send(ctx, endpoint, report)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastError = err.Error()
+		return err
+	}
+	// Subtract what was sent rather than clearing, so requests counted while
+	// the POST was in flight are kept for the next report.
+	for k, v := range report.Features {
+		if s.features[k] -= v; s.features[k] <= 0 {
+			delete(s.features, k)
+		}
+	}
+	for k, v := range report.Errors {
+		if s.errors[k] -= v; s.errors[k] <= 0 {
+			delete(s.errors, k)
+		}
+	}
+	s.periodStart = report.PeriodEnd
+	s.lastSentAt = report.PeriodEnd
+	s.lastError = ""
+	return nil
+}
+
+func (s *Service) send(ctx context.Context, endpoint string, report Report) error {
+	if err := endpointValidator(endpoint); err != nil {
+		return fmt.Errorf("telemetry endpoint rejected: %w", err)
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("encode telemetry report: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("kubestellar-console-telemetry/%d", SchemaVersion))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("POST %s returned status %d", endpoint, resp.StatusCode)
+	}
+	return nil
+}
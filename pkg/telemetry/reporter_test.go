@@ -0,0 +1,107 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+func allowLoopback(t *testing.T) {
+	t.Helper()
+	orig := endpointValidator
+	endpointValidator = func(string) error { return nil }
+	t.Cleanup(func() { endpointValidator = orig })
+}
+
+func TestFlush_SendsPreviewAndResets(t *testing.T) {
+	allowLoopback(t)
+	var received []Report
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		received = append(received, report)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s := newTestService(t, true)
+	s.store.(*memStore).cfg.Endpoint = srv.URL
+	s.client = srv.Client()
+	s.RecordRequest("/api/mcp", http.StatusOK)
+
+	preview := s.Preview(context.Background())
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if len(received) != 1 || received[0].Features["mcp"] != preview.Features["mcp"] {
+		t.Fatalf("sent report differs from preview: %+v vs %+v", received, preview)
+	}
+	if len(s.Preview(context.Background()).Features) != 0 {
+		t.Error("aggregate should reset after a successful send")
+	}
+	if s.Status().LastSentAt == nil {
+		t.Error("status should record the delivery")
+	}
+
+	// A failed delivery keeps the counts for the next attempt.
+	status = http.StatusInternalServerError
+	s.RecordRequest("/api/workloads", http.StatusOK)
+	if err := s.Flush(context.Background()); err == nil {
+		t.Fatal("expected error for a 500 response")
+	}
+	if s.Preview(context.Background()).Features["workloads"] != 1 {
+		t.Error("counts should be kept after a failed send")
+	}
+	if s.Status().LastError == "" {
+		t.Error("status should record the failure")
+	}
+}
+
+func TestFlush_NoopWhenOff(t *testing.T) {
+	allowLoopback(t)
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	s := newTestService(t, false)
+	s.store = &memStore{cfg: settings.TelemetrySettings{Endpoint: srv.URL}}
+	s.client = srv.Client()
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if called {
+		t.Error("nothing should be sent before opting in")
+	}
+}
+
+func TestFlush_RejectsPrivateEndpoint(t *testing.T) {
+	s := newTestService(t, true)
+	s.store.(*memStore).cfg.Endpoint = "http://127.0.0.1:9/collect"
+	if err := s.Flush(context.Background()); err == nil {
+		t.Error("expected loopback endpoint to be rejected")
+	}
+}
+
+func TestReportInterval(t *testing.T) {
+	t.Setenv(envInterval, "")
+	if got := reportInterval(); got != defaultReportInterval {
+		t.Errorf("default interval = %v", got)
+	}
+	t.Setenv(envInterval, "1s")
+	if got := reportInterval(); got != defaultReportInterval {
+		t.Errorf("intervals below the minimum should fall back, got %v", got)
+	}
+	t.Setenv(envInterval, "6h")
+	if got := reportInterval(); got.Hours() != 6 {
+		t.Errorf("interval = %v, want 6h", got)
+	}
+}
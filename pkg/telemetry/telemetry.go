@@ -0,0 +1,326 @@
+// Package telemetry aggregates anonymous usage counts and, when an admin has
+// opted in, periodically posts them to a configured endpoint.
+//
+// Nothing is collected until telemetry is enabled. A report carries only
+// coarse counters: which API areas were used, which classes of errors were
+// returned and a bucketed cluster count. It never includes paths beyond the
+// first segment, user identities, cluster names or request bodies. Preview
+// returns the exact payload the next report would send.
+//
+// Setting KC_TELEMETRY_DISABLED=true (or DO_NOT_TRACK=1) is a hard off switch:
+// it stops collection and sending and rejects attempts to opt in via the API.
+// Air-gapped mode (see pkg/egress) throws the same switch.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+const (
+	// EnvDisabled is the hard off switch.
+	EnvDisabled = "KC_TELEMETRY_DISABLED"
+	// envDoNotTrack is the cross-tool DO_NOT_TRACK convention, honoured as an
+	// alias of EnvDisabled.
+	envDoNotTrack = "DO_NOT_TRACK"
+
+	// SchemaVersion tags the report so receivers can evolve the shape.
+	SchemaVersion = 1
+
+	// maxCounterKeys caps distinct feature and error names so a misbehaving
+	// client cannot grow the aggregate without bound.
+	maxCounterKeys = 64
+)
+
+// ErrDisabled is returned when opting in while the hard off switch is set.
+var ErrDisabled = errors.New("telemetry is disabled by " + EnvDisabled)
+
+// ErrInvalidEndpoint is returned for an endpoint that is not an absolute
+// http(s) URL.
+var ErrInvalidEndpoint = errors.New("telemetry endpoint must be an absolute http(s) URL")
+
+// featurePattern restricts feature names to route-like identifiers.
+var featurePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,39}$`)
+
+// Store persists the telemetry opt-in. *settings.SettingsManager implements it.
+type Store interface {
+	GetTelemetry() settings.TelemetrySettings
+	SaveTelemetry(settings.TelemetrySettings) error
+}
+
+// ClusterCounter returns the number of clusters the console manages.
+type ClusterCounter func(ctx context.Context) (int, error)
+
+// Report is the payload posted to the telemetry endpoint.
+type Report struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Version       string    `json:"version"`
+	PeriodStart   time.Time `json:"periodStart"`
+	PeriodEnd     time.Time `json:"periodEnd"`
+	// ClusterCount is a bucket such as "2-5", never the exact count.
+	ClusterCount string           `json:"clusterCount"`
+	Features     map[string]int64 `json:"features"`
+	Errors       map[string]int64 `json:"errors"`
+}
+
+// Status describes the telemetry configuration and the last delivery.
+type Status struct {
+	// Active is true when reports are being collected and sent.
+	Active        bool       `json:"active"`
+	OptedIn       bool       `json:"optedIn"`
+	Endpoint      string     `json:"endpoint,omitempty"`
+	DisabledByEnv bool       `json:"disabledByEnv"`
+	Interval      string     `json:"interval"`
+	LastSentAt    *time.Time `json:"lastSentAt,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+}
+
+// Service aggregates usage counts and sends them on a schedule.
+type Service struct {
+	store    Store
+	version  string
+	clusters ClusterCounter
+	client   *http.Client
+	interval time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	periodStart time.Time
+	features    map[string]int64
+	errors      map[string]int64
+	lastSentAt  time.Time
+	lastError   string
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// New returns a Service backed by store. clusters may be nil, in which case
+// reports carry an "unknown" cluster count.
+func New(store Store, version string, clusters ClusterCounter, client *http.Client) *Service {
+	s := &Service{
+		store:    store,
+		version:  version,
+		clusters: clusters,
+		client:   client,
+		interval: reportInterval(),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+	s.resetLocked()
+	return s
+}
+
+// DisabledByEnv reports whether the hard off switch is set. Air-gapped mode
+// sets it too.
+func DisabledByEnv() bool {
+	if egress.AirGapped() {
+		return true
+	}
+	if v := strings.ToLower(os.Getenv(EnvDisabled)); v == "true" || v == "1" {
+		return true
+	}
+	return os.Getenv(envDoNotTrack) == "1"
+}
+
+// Active reports whether usage is being collected and sent.
+func (s *Service) Active() bool {
+	if s == nil || DisabledByEnv() {
+		return false
+	}
+	cfg := s.store.GetTelemetry()
+	return cfg.Enabled && cfg.Endpoint != ""
+}
+
+// RecordRequest counts an API request. path is the request path; only the
+// segment after /api/ is kept. Requests are ignored while telemetry is off.
+func (s *Service) RecordRequest(path string, status int) {
+	if !s.Active() {
+		return
+	}
+	feature := featureFromPath(path)
+	category := errorCategory(status)
+	if feature == "" && category == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Unknown routes return 404 and their first segment is caller-chosen, so
+	// only count features for routes that exist.
+	if feature != "" && status != http.StatusNotFound {
+		increment(s.features, feature)
+	}
+	if category != "" {
+		increment(s.errors, category)
+	}
+}
+
+// RecordError counts an error in category outside the HTTP path, e.g. a
+// failed background sync. Ignored while telemetry is off.
+func (s *Service) RecordError(category string) {
+	if !s.Active() || !featurePattern.MatchString(category) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	increment(s.errors, category)
+}
+
+func increment(counts map[string]int64, key string) {
+	if _, ok := counts[key]; !ok && len(counts) >= maxCounterKeys {
+		return
+	}
+	counts[key]++
+}
+
+// featureFromPath maps /api/<feature>/... to <feature>.
+func featureFromPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return ""
+	}
+	feature, _, _ := strings.Cut(rest, "/")
+	if !featurePattern.MatchString(feature) {
+		return ""
+	}
+	return feature
+}
+
+// errorCategory maps an HTTP status to a coarse error class, or "" for
+// non-error responses.
+func errorCategory(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return ""
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return "invalid_request"
+	case status == http.StatusUnauthorized:
+		return "unauthorized"
+	case status == http.StatusForbidden:
+		return "forbidden"
+	case status == http.StatusNotFound:
+		return "not_found"
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return "timeout"
+	case status == http.StatusTooManyRequests:
+		return "rate_limited"
+	case status == http.StatusBadGateway || status == http.StatusServiceUnavailable:
+		return "unavailable"
+	case status < http.StatusInternalServerError:
+		return "client_error"
+	default:
+		return "server_error"
+	}
+}
+
+// clusterBucket coarsens a cluster count so reports cannot fingerprint an
+// installation by its exact size.
+func clusterBucket(n int) string {
+	switch {
+	case n <= 0:
+		return "0"
+	case n == 1:
+		return "1"
+	case n <= 5:
+		return "2-5"
+	case n <= 20:
+		return "6-20"
+	case n <= 100:
+		return "21-100"
+	default:
+		return "100+"
+	}
+}
+
+// Preview returns the report that would be sent now. It does not reset the
+// aggregate.
+func (s *Service) Preview(ctx context.Context) Report {
+	bucket := "unknown"
+	if s.clusters != nil {
+		if n, err := s.clusters(ctx); err == nil {
+			bucket = clusterBucket(n)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Report{
+		SchemaVersion: SchemaVersion,
+		Version:       s.version,
+		PeriodStart:   s.periodStart,
+		PeriodEnd:     s.now().UTC(),
+		ClusterCount:  bucket,
+		Features:      copyCounts(s.features),
+		Errors:        copyCounts(s.errors),
+	}
+}
+
+func copyCounts(in map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+func (s *Service) resetLocked() {
+	s.periodStart = s.now().UTC()
+	s.features = make(map[string]int64)
+	s.errors = make(map[string]int64)
+}
+
+// Status returns the current configuration and delivery state.
+func (s *Service) Status() Status {
+	cfg := s.store.GetTelemetry()
+	st := Status{
+		Active:        s.Active(),
+		OptedIn:       cfg.Enabled,
+		Endpoint:      cfg.Endpoint,
+		DisabledByEnv: DisabledByEnv(),
+		Interval:      s.interval.String(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.lastSentAt.IsZero() {
+		sent := s.lastSentAt
+		st.LastSentAt = &sent
+	}
+	st.LastError = s.lastError
+	return st
+}
+
+// Configure stores the opt-in. Opting out discards the aggregate so nothing
+// collected before is sent if telemetry is later re-enabled.
+func (s *Service) Configure(enabled bool, endpoint string) error {
+	if enabled && DisabledByEnv() {
+		return ErrDisabled
+	}
+	if endpoint != "" {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidEndpoint, endpoint)
+		}
+	}
+	if enabled && endpoint == "" {
+		return fmt.Errorf("%w: an endpoint is required to enable telemetry", ErrInvalidEndpoint)
+	}
+	if err := s.store.SaveTelemetry(settings.TelemetrySettings{Enabled: enabled, Endpoint: endpoint}); err != nil {
+		return err
+	}
+	if !enabled {
+		s.mu.Lock()
+		s.resetLocked()
+		s.lastError = ""
+		s.mu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/kubestellar/console/pkg/egress"
+	"github.com/kubestellar/console/pkg/settings"
+)
+
+type memStore struct {
+	cfg settings.TelemetrySettings
+}
+
+func (m *memStore) GetTelemetry() settings.TelemetrySettings { return m.cfg }
+
+func (m *memStore) SaveTelemetry(t settings.TelemetrySettings) error {
+	m.cfg = t
+	return nil
+}
+
+func newTestService(t *testing.T, enabled bool) *Service {
+	t.Helper()
+	t.Setenv(EnvDisabled, "")
+	t.Setenv(envDoNotTrack, "")
+	store := &memStore{cfg: settings.TelemetrySettings{Enabled: enabled, Endpoint: "https://telemetry.example.com/v1"}}
+	return New(store, "v1.2.3", func(context.Context) (int, error) { return 3, nil }, http.DefaultClient)
+}
+
+func TestRecordRequest_OffByDefault(t *testing.T) {
+	s := newTestService(t, false)
+	s.RecordRequest("/api/mcp/tools", http.StatusOK)
+	s.RecordError("sync")
+	report := s.Preview(context.Background())
+	if len(report.Features) != 0 || len(report.Errors) != 0 {
+		t.Errorf("nothing should be collected before opting in: %+v", report)
+	}
+}
+
+func TestRecordRequest_Aggregates(t *testing.T) {
+	s := newTestService(t, true)
+	s.RecordRequest("/api/mcp/tools/ops/call", http.StatusOK)
+	s.RecordRequest("/api/mcp/clusters", http.StatusBadGateway)
+	s.RecordRequest("/api/workloads", http.StatusForbidden)
+	s.RecordRequest("/api/some-user-typed-path", http.StatusNotFound)
+	s.RecordRequest("/api/Secret_Name", http.StatusOK)
+	s.RecordRequest("/health", http.StatusOK)
+	s.RecordError("sync")
+
+	report := s.Preview(context.Background())
+	if report.SchemaVersion != SchemaVersion || report.Version != "v1.2.3" || report.ClusterCount != "2-5" {
+		t.Errorf("unexpected header: %+v", report)
+	}
+	wantFeatures := map[string]int64{"mcp": 2, "workloads": 1}
+	if len(report.Features) != len(wantFeatures) {
+		t.Errorf("features = %v, want %v", report.Features, wantFeatures)
+	}
+	for k, v := range wantFeatures {
+		if report.Features[k] != v {
+			t.Errorf("features[%s] = %d, want %d", k, report.Features[k], v)
+		}
+	}
+	wantErrors := map[string]int64{"unavailable": 1, "forbidden": 1, "not_found": 1, "sync": 1}
+	for k, v := range wantErrors {
+		if report.Errors[k] != v {
+			t.Errorf("errors[%s] = %d, want %d", k, report.Errors[k], v)
+		}
+	}
+
+	// Preview does not consume the aggregate.
+	if again := s.Preview(context.Background()); again.Features["mcp"] != 2 {
+		t.Errorf("preview reset the aggregate: %v", again.Features)
+	}
+}
+
+func TestRecordRequest_CapsKeys(t *testing.T) {
+	s := newTestService(t, true)
+	for i := 0; i < maxCounterKeys+10; i++ {
+		s.RecordRequest("/api/f"+string(rune('a'+i%26))+string(rune('a'+i/26)), http.StatusOK)
+	}
+	if got := len(s.Preview(context.Background()).Features); got != maxCounterKeys {
+		t.Errorf("expected %d features, got %d", maxCounterKeys, got)
+	}
+}
+
+func TestHardOffSwitch(t *testing.T) {
+	for _, env := range []string{EnvDisabled, envDoNotTrack} {
+		t.Run(env, func(t *testing.T) {
+			s := newTestService(t, true)
+			t.Setenv(env, "1")
+			if s.Active() {
+				t.Error("hard off switch should override the opt-in")
+			}
+			s.RecordRequest("/api/mcp", http.StatusOK)
+			if len(s.Preview(context.Background()).Features) != 0 {
+				t.Error("nothing should be collected while disabled")
+			}
+			if err := s.Configure(true, "https://telemetry.example.com"); !errors.Is(err, ErrDisabled) {
+				t.Errorf("expected ErrDisabled, got %v", err)
+			}
+			if err := s.Configure(false, ""); err != nil {
+				t.Errorf("opting out must always work: %v", err)
+			}
+			if !s.Status().DisabledByEnv {
+				t.Error("status should report the env switch")
+			}
+		})
+	}
+}
+
+func TestAirGappedModeDisables(t *testing.T) {
+	s := newTestService(t, true)
+	if err := egress.Configure(egress.Policy{AirGapped: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = egress.Configure(egress.Policy{}) })
+
+	if s.Active() {
+		t.Error("air-gapped mode should override the opt-in")
+	}
+	if err := s.Configure(true, "https://telemetry.example.com"); !errors.Is(err, ErrDisabled) {
+		t.Errorf("expected ErrDisabled, got %v", err)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	s := newTestService(t, true)
+	for _, endpoint := range []string{"ftp://example.com", "/relative", "not a url"} {
+		if err := s.Configure(true, endpoint); !errors.Is(err, ErrInvalidEndpoint) {
+			t.Errorf("%q: expected ErrInvalidEndpoint, got %v", endpoint, err)
+		}
+	}
+	if err := s.Configure(true, ""); !errors.Is(err, ErrInvalidEndpoint) {
+		t.Errorf("enabling without an endpoint should fail, got %v", err)
+	}
+
+	s.RecordRequest("/api/mcp", http.StatusOK)
+	if err := s.Configure(false, "https://telemetry.example.com"); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if s.Active() || s.Status().OptedIn {
+		t.Error("telemetry should be off after opting out")
+	}
+	if len(s.Preview(context.Background()).Features) != 0 {
+		t.Error("opting out should discard the aggregate")
+	}
+}
+
+func TestClusterBucket(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 1: "1", 2: "2-5", 5: "2-5", 6: "6-20", 21: "21-100", 101: "100+"} {
+		if got := clusterBucket(n); got != want {
+			t.Errorf("clusterBucket(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -119,8 +119,35 @@ func (m *MockStore) CreateDashboardTx(ctx context.Context, tx *sql.Tx, dashboard
 func (m *MockStore) UpdateDashboard(ctx context.Context, dashboard *models.Dashboard) error {
 	return nil
 }
+func (m *MockStore) UpdateDashboardIfVersion(ctx context.Context, dashboard *models.Dashboard, expectedVersion int) error {
+	args := m.Called(dashboard, expectedVersion)
+	return args.Error(0)
+}
 func (m *MockStore) DeleteDashboard(ctx context.Context, id uuid.UUID) error { return nil }
 
+func (m *MockStore) CreateDashboardSnapshot(ctx context.Context, snapshot *models.DashboardSnapshot) error {
+	args := m.Called(snapshot)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetDashboardSnapshot(ctx context.Context, id uuid.UUID) (*models.DashboardSnapshot, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DashboardSnapshot), args.Error(1)
+}
+
+func (m *MockStore) CountUserDashboardSnapshots(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	args := m.Called(userID, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStore) DeleteExpiredDashboardSnapshots(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(cutoff)
+	return int64(args.Int(0)), args.Error(1)
+}
+
 func (m *MockStore) GetCard(ctx context.Context, id uuid.UUID) (*models.Card, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -547,6 +574,96 @@ func (m *MockStore) ConsumeOAuthState(ctx context.Context, state string) (bool,
 
 func (m *MockStore) CleanupExpiredOAuthStates(ctx context.Context) (int64, error) { return 0, nil }
 
+// expects reports whether a test registered an expectation for method. The
+// session methods below run on every login and refresh, so they are no-ops
+// unless a test opts in.
+func (m *MockStore) expects(method string) bool {
+	for _, call := range m.ExpectedCalls {
+		if call.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockStore) CreateAgentPairingCode(ctx context.Context, codeHash, agentID string, expiresAt time.Time) error {
+	if !m.expects("CreateAgentPairingCode") {
+		return nil
+	}
+	return m.Called(codeHash, agentID).Error(0)
+}
+
+func (m *MockStore) ConsumeAgentPairingCode(ctx context.Context, codeHash string, now time.Time) (string, error) {
+	if !m.expects("ConsumeAgentPairingCode") {
+		return "", nil
+	}
+	args := m.Called(codeHash)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStore) DeleteExpiredAgentPairingCodes(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockStore) CreateSession(ctx context.Context, session *models.UserSession) error {
+	if !m.expects("CreateSession") {
+		return nil
+	}
+	return m.Called(session).Error(0)
+}
+
+func (m *MockStore) GetSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	if !m.expects("GetSession") {
+		return nil, nil
+	}
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSession), args.Error(1)
+}
+
+func (m *MockStore) ListUserSessions(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.UserSession, error) {
+	if !m.expects("ListUserSessions") {
+		return []models.UserSession{}, nil
+	}
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserSession), args.Error(1)
+}
+
+func (m *MockStore) TouchSession(ctx context.Context, id uuid.UUID, ip string, at time.Time) error {
+	if !m.expects("TouchSession") {
+		return nil
+	}
+	return m.Called(id, ip).Error(0)
+}
+
+func (m *MockStore) RevokeSession(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	if !m.expects("RevokeSession") {
+		return false, nil
+	}
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStore) RevokeUserSessions(ctx context.Context, userID, keep uuid.UUID, at time.Time) ([]uuid.UUID, error) {
+	if !m.expects("RevokeUserSessions") {
+		return nil, nil
+	}
+	args := m.Called(userID, keep)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockStore) DeleteExpiredSessions(ctx context.Context, cutoff, idleCutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (m *MockStore) CountUserDashboards(ctx context.Context, userID uuid.UUID) (int, error) {
 	args := m.Called(userID)
 	return args.Int(0), args.Error(1)
@@ -570,6 +687,194 @@ func (m *MockStore) ListClusterGroups(ctx context.Context) (map[string][]byte, e
 	return args.Get(0).(map[string][]byte), args.Error(1)
 }
 
+func (m *MockStore) GetSavedView(ctx context.Context, id uuid.UUID) (*models.SavedView, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedView), args.Error(1)
+}
+
+func (m *MockStore) CountProjectSavedViews(ctx context.Context, project string) (int, error) {
+	args := m.Called(project)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStore) ListSavedViews(ctx context.Context, project string, limit, offset int) ([]models.SavedView, error) {
+	args := m.Called(project, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SavedView), args.Error(1)
+}
+
+func (m *MockStore) CreateSavedView(ctx context.Context, view *models.SavedView) error {
+	args := m.Called(view)
+	return args.Error(0)
+}
+
+func (m *MockStore) UpdateSavedView(ctx context.Context, view *models.SavedView) error {
+	args := m.Called(view)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteSavedView(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetSLO(ctx context.Context, id uuid.UUID) (*models.SLO, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SLO), args.Error(1)
+}
+
+func (m *MockStore) CountProjectSLOs(ctx context.Context, project string) (int, error) {
+	args := m.Called(project)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStore) ListSLOs(ctx context.Context, project string, limit, offset int) ([]models.SLO, error) {
+	args := m.Called(project, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SLO), args.Error(1)
+}
+
+func (m *MockStore) CreateSLO(ctx context.Context, slo *models.SLO) error {
+	args := m.Called(slo)
+	return args.Error(0)
+}
+
+func (m *MockStore) UpdateSLO(ctx context.Context, slo *models.SLO) error {
+	args := m.Called(slo)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteSLO(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStore) SetClusterTimezone(ctx context.Context, cluster, timezone string) error {
+	args := m.Called(cluster, timezone)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetClusterTimezone(ctx context.Context, cluster string) (string, error) {
+	args := m.Called(cluster)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockStore) ListClusterTimezones(ctx context.Context) (map[string]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockStore) DeleteClusterTimezone(ctx context.Context, cluster string) error {
+	args := m.Called(cluster)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetChangePolicy(ctx context.Context, project string) (*models.ChangePolicy, error) {
+	args := m.Called(project)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChangePolicy), args.Error(1)
+}
+
+func (m *MockStore) SaveChangePolicy(ctx context.Context, policy *models.ChangePolicy) error {
+	args := m.Called(policy)
+	return args.Error(0)
+}
+
+func (m *MockStore) CreateWorkloadSnapshot(ctx context.Context, snap *models.WorkloadSnapshot) error {
+	args := m.Called(snap)
+	return args.Error(0)
+}
+
+func (m *MockStore) GetWorkloadSnapshot(ctx context.Context, id uuid.UUID) (*models.WorkloadSnapshot, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WorkloadSnapshot), args.Error(1)
+}
+
+func (m *MockStore) CountWorkloadSnapshots(ctx context.Context, cluster, kind, namespace, name string) (int, error) {
+	args := m.Called(cluster, kind, namespace, name)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStore) ListWorkloadSnapshots(ctx context.Context, cluster, kind, namespace, name string, limit int) ([]models.WorkloadSnapshot, error) {
+	args := m.Called(cluster, kind, namespace, name, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WorkloadSnapshot), args.Error(1)
+}
+
+func (m *MockStore) DeleteWorkloadSnapshot(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStore) AppendHubEvents(ctx context.Context, events []models.HubEvent) error {
+	args := m.Called(events)
+	return args.Error(0)
+}
+
+func (m *MockStore) ListHubEvents(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]models.HubEvent, error) {
+	args := m.Called(userID, afterSeq, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.HubEvent), args.Error(1)
+}
+
+func (m *MockStore) HubEventSeqRange(ctx context.Context) (int64, int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStore) PruneHubEvents(ctx context.Context, keep int, before time.Time) (int64, error) {
+	args := m.Called(keep, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStore) GetBenchmarkAnnotation(ctx context.Context, reportUID string) (*models.BenchmarkAnnotation, error) {
+	args := m.Called(reportUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BenchmarkAnnotation), args.Error(1)
+}
+
+func (m *MockStore) ListBenchmarkAnnotations(ctx context.Context) ([]models.BenchmarkAnnotation, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BenchmarkAnnotation), args.Error(1)
+}
+
+func (m *MockStore) SaveBenchmarkAnnotation(ctx context.Context, annotation *models.BenchmarkAnnotation) error {
+	args := m.Called(annotation)
+	return args.Error(0)
+}
+
+func (m *MockStore) DeleteBenchmarkAnnotation(ctx context.Context, reportUID string) error {
+	args := m.Called(reportUID)
+	return args.Error(0)
+}
+
 func (m *MockStore) InsertAuditLog(_ context.Context, _, _, _ string) error {
 	return nil
 }
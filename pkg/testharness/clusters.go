@@ -0,0 +1,377 @@
+package testharness
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/k8s/k8stest"
+)
+
+// Fake cluster context names. ClusterGPU is the only cluster with GPU
+// nodes; ClusterDegraded runs one crash-looping pod on a node under memory
+// pressure, so health and issue views always have something to show.
+const (
+	ClusterPrimary  = "fake-us-east"
+	ClusterDegraded = "fake-eu-west"
+	ClusterGPU      = "fake-edge-gpu"
+)
+
+// ClusterNames lists the fake contexts in kubeconfig order.
+var ClusterNames = []string{ClusterPrimary, ClusterDegraded, ClusterGPU}
+
+// FakeKubernetesVersion is what every fake cluster's discovery reports.
+const FakeKubernetesVersion = "v1.31.4"
+
+const (
+	fakeServerDomain  = "fake.invalid"
+	gpuResourceName   = corev1.ResourceName("nvidia.com/gpu")
+	gpuProductLabel   = "nvidia.com/gpu.product"
+	gpuProduct        = "NVIDIA-A100-SXM4-80GB"
+	gpusPerNode       = 4
+	crashLoopRestarts = 7
+	podSuffixLen      = 5
+	templateHashLen   = 10
+)
+
+// fakeWorkload is a Deployment every fake cluster runs.
+type fakeWorkload struct {
+	namespace string
+	name      string
+	image     string
+	port      int32
+	gpu       bool // only scheduled on ClusterGPU
+}
+
+var fakeWorkloads = []fakeWorkload{
+	{namespace: "shop", name: "frontend", image: "ghcr.io/kubestellar/fake-frontend:1.4.2", port: 8080},
+	{namespace: "shop", name: "cart", image: "ghcr.io/kubestellar/fake-cart:2.0.1", port: 9090},
+	{namespace: "shop", name: "checkout", image: "ghcr.io/kubestellar/fake-checkout:1.1.0", port: 9091},
+	{namespace: "ml-serving", name: "inference", image: "ghcr.io/kubestellar/fake-vllm:0.6.3", port: 8000, gpu: true},
+}
+
+var fakeNamespaces = []string{"default", "kube-system", "shop", "ml-serving"}
+
+// clusterObjects is the typed fake data set of one cluster.
+type clusterObjects struct {
+	namespaces  []*corev1.Namespace
+	nodes       []*corev1.Node
+	deployments []*appsv1.Deployment
+	replicaSets []*appsv1.ReplicaSet
+	pods        []*corev1.Pod
+	services    []*corev1.Service
+	events      []*corev1.Event
+}
+
+func (o *clusterObjects) all() []runtime.Object {
+	var objs []runtime.Object
+	for _, x := range o.namespaces {
+		objs = append(objs, x)
+	}
+	for _, x := range o.nodes {
+		objs = append(objs, x)
+	}
+	for _, x := range o.deployments {
+		objs = append(objs, x)
+	}
+	for _, x := range o.replicaSets {
+		objs = append(objs, x)
+	}
+	for _, x := range o.pods {
+		objs = append(objs, x)
+	}
+	for _, x := range o.services {
+		objs = append(objs, x)
+	}
+	for _, x := range o.events {
+		objs = append(objs, x)
+	}
+	return objs
+}
+
+// newFakeClusters builds a MultiClusterClient whose contexts are backed by
+// fake typed and dynamic clients seeded with generateCluster.
+func newFakeClusters(opts Options) *k8s.MultiClusterClient {
+	rawConfig := &api.Config{
+		CurrentContext: ClusterPrimary,
+		Contexts:       map[string]*api.Context{},
+		Clusters:       map[string]*api.Cluster{},
+		AuthInfos:      map[string]*api.AuthInfo{},
+	}
+	listKinds := k8stest.BuildGVRMap()
+	listKinds[appsv1.SchemeGroupVersion.WithResource("replicasets")] = "ReplicaSetList"
+	clients := make(map[string]kubernetes.Interface, len(ClusterNames))
+	dynamicClients := make(map[string]dynamic.Interface, len(ClusterNames))
+	for _, name := range ClusterNames {
+		rawConfig.Contexts[name] = &api.Context{Cluster: name, AuthInfo: name}
+		rawConfig.Clusters[name] = &api.Cluster{Server: fmt.Sprintf("https://%s.%s:6443", name, fakeServerDomain)}
+		rawConfig.AuthInfos[name] = &api.AuthInfo{Token: "fake-token"}
+
+		objs := generateCluster(opts, name)
+		typed := k8sfake.NewSimpleClientset(objs.all()...)
+		if d, ok := typed.Discovery().(*fakediscovery.FakeDiscovery); ok {
+			d.FakedServerVersion = &version.Info{Major: "1", Minor: "31", GitVersion: FakeKubernetesVersion, Platform: "linux/amd64"}
+		}
+		clients[name] = typed
+		dynamicClients[name] = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, toUnstructured(objs.all())...)
+	}
+	return k8s.NewInMemoryMultiClusterClient(rawConfig, clients, dynamicClients)
+}
+
+// generateCluster returns the objects of one fake cluster. Every random
+// choice comes from a stream keyed by the seed and the cluster name.
+func generateCluster(opts Options, cluster string) *clusterObjects {
+	rng := newRand(opts.Seed, "cluster/"+cluster)
+	created := func(hoursAgo int) metav1.Time {
+		return metav1.NewTime(opts.BaseTime.Add(-time.Duration(hoursAgo) * time.Hour))
+	}
+	objs := &clusterObjects{}
+
+	for _, ns := range fakeNamespaces {
+		objs.namespaces = append(objs.namespaces, &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: ns, UID: fakeUID(cluster, "ns", ns), CreationTimestamp: created(24 * 90)},
+			Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+		})
+	}
+
+	nodeCount := 2 + rng.IntN(3)
+	for i := range nodeCount {
+		name := fmt.Sprintf("%s-node-%d", cluster, i+1)
+		cpu := []int{8, 16, 32}[rng.IntN(3)]
+		memGiB := cpu * 4
+		node := k8stest.NewHealthyNode(name, cpu, memGiB)
+		node.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Node"}
+		node.UID = fakeUID(cluster, "node", name)
+		node.CreationTimestamp = created(24 * 60)
+		node.Labels = map[string]string{
+			"kubernetes.io/hostname":        name,
+			"topology.kubernetes.io/region": cluster,
+		}
+		node.Status.NodeInfo = corev1.NodeSystemInfo{KubeletVersion: FakeKubernetesVersion, OSImage: "Fake Linux 1.0", ContainerRuntimeVersion: "containerd://1.7.22"}
+		storage := resource.MustParse("100Gi")
+		node.Status.Capacity[corev1.ResourceEphemeralStorage] = storage
+		node.Status.Allocatable[corev1.ResourceEphemeralStorage] = storage
+		if cluster == ClusterGPU && i == 0 {
+			gpus := *resource.NewQuantity(gpusPerNode, resource.DecimalSI)
+			node.Labels[gpuProductLabel] = gpuProduct
+			node.Status.Capacity[gpuResourceName] = gpus
+			node.Status.Allocatable[gpuResourceName] = gpus
+		}
+		if cluster == ClusterDegraded && i == nodeCount-1 {
+			node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+				Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasInsufficientMemory",
+			})
+		}
+		objs.nodes = append(objs.nodes, node)
+	}
+
+	for _, w := range fakeWorkloads {
+		if w.gpu && cluster != ClusterGPU {
+			continue
+		}
+		replicas := int32(1 + rng.IntN(3))
+		crashing := cluster == ClusterDegraded && w.name == "checkout"
+		ready := replicas
+		if crashing {
+			ready = replicas - 1
+		}
+		labels := map[string]string{"app": w.name}
+		deployUID := fakeUID(cluster, "deploy", w.namespace+"/"+w.name)
+		rsName := w.name + "-" + randomName(rng, templateHashLen)
+		rsUID := fakeUID(cluster, "rs", w.namespace+"/"+rsName)
+
+		container := corev1.Container{
+			Name:  w.name,
+			Image: w.image,
+			Ports: []corev1.ContainerPort{{ContainerPort: w.port}},
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			}},
+		}
+		if w.gpu {
+			container.Resources.Requests[gpuResourceName] = *resource.NewQuantity(1, resource.DecimalSI)
+			container.Resources.Limits = corev1.ResourceList{gpuResourceName: *resource.NewQuantity(1, resource.DecimalSI)}
+		}
+		template := corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+		}
+
+		objs.deployments = append(objs.deployments, &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: w.namespace, Name: w.name, UID: deployUID, Labels: labels, CreationTimestamp: created(24 * 30)},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: template,
+			},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           replicas,
+				UpdatedReplicas:    replicas,
+				ReadyReplicas:      ready,
+				AvailableReplicas:  ready,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: boolCondition(ready == replicas), Reason: "MinimumReplicasAvailable"},
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+				},
+			},
+		})
+		objs.replicaSets = append(objs.replicaSets, &appsv1.ReplicaSet{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: w.namespace, Name: rsName, UID: rsUID, Labels: labels, CreationTimestamp: created(24 * 30),
+				OwnerReferences: controllerRef("apps/v1", "Deployment", w.name, deployUID),
+			},
+			Spec:   appsv1.ReplicaSetSpec{Replicas: &replicas, Selector: &metav1.LabelSelector{MatchLabels: labels}, Template: template},
+			Status: appsv1.ReplicaSetStatus{Replicas: replicas, ReadyReplicas: ready, AvailableReplicas: ready},
+		})
+
+		for i := range replicas {
+			podName := rsName + "-" + randomName(rng, podSuffixLen)
+			node := objs.nodes[rng.IntN(len(objs.nodes))].Name
+			if w.gpu {
+				node = objs.nodes[0].Name
+			}
+			pod := &corev1.Pod{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: w.namespace, Name: podName, UID: fakeUID(cluster, "pod", w.namespace+"/"+podName),
+					Labels: labels, CreationTimestamp: created(1 + rng.IntN(72)),
+					OwnerReferences: controllerRef("apps/v1", "ReplicaSet", rsName, rsUID),
+				},
+				Spec: corev1.PodSpec{NodeName: node, Containers: []corev1.Container{container}},
+			}
+			if crashing && i == replicas-1 {
+				pod.Status = crashLoopStatus(w.name, pod.CreationTimestamp)
+				objs.events = append(objs.events, &corev1.Event{
+					TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+					ObjectMeta:     metav1.ObjectMeta{Namespace: w.namespace, Name: podName + ".backoff", UID: fakeUID(cluster, "event", podName)},
+					InvolvedObject: corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: w.namespace, Name: podName, UID: pod.UID},
+					Type:           corev1.EventTypeWarning,
+					Reason:         "BackOff",
+					Message:        fmt.Sprintf("Back-off restarting failed container %s in pod %s", w.name, podName),
+					Count:          crashLoopRestarts,
+					FirstTimestamp: pod.CreationTimestamp,
+					LastTimestamp:  metav1.NewTime(opts.BaseTime.Add(-2 * time.Minute)),
+					Source:         corev1.EventSource{Component: "kubelet", Host: node},
+				})
+			} else {
+				pod.Status = runningStatus(w.name, pod.CreationTimestamp)
+			}
+			objs.pods = append(objs.pods, pod)
+		}
+
+		objs.services = append(objs.services, &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: w.namespace, Name: w.name, UID: fakeUID(cluster, "svc", w.namespace+"/"+w.name), Labels: labels, CreationTimestamp: created(24 * 30)},
+			Spec: corev1.ServiceSpec{
+				Type:      corev1.ServiceTypeClusterIP,
+				ClusterIP: fmt.Sprintf("10.96.%d.%d", 1+rng.IntN(254), 1+rng.IntN(254)),
+				Selector:  labels,
+				Ports:     []corev1.ServicePort{{Name: "http", Port: w.port, TargetPort: intstr.FromInt32(w.port), Protocol: corev1.ProtocolTCP}},
+			},
+		})
+	}
+	return objs
+}
+
+func runningStatus(container string, started metav1.Time) corev1.PodStatus {
+	return corev1.PodStatus{
+		Phase:     corev1.PodRunning,
+		StartTime: &started,
+		Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			{Type: corev1.ContainersReady, Status: corev1.ConditionTrue},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  container,
+			Ready: true,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}},
+		}},
+	}
+}
+
+func crashLoopStatus(container string, started metav1.Time) corev1.PodStatus {
+	return corev1.PodStatus{
+		Phase:     corev1.PodRunning,
+		StartTime: &started,
+		Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady"},
+			{Type: corev1.ContainersReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady"},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         container,
+			RestartCount: crashLoopRestarts,
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "CrashLoopBackOff", Message: "back-off 5m0s restarting failed container",
+			}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+		}},
+	}
+}
+
+func boolCondition(ok bool) corev1.ConditionStatus {
+	if ok {
+		return corev1.ConditionTrue
+	}
+	return corev1.ConditionFalse
+}
+
+func controllerRef(apiVersion, kind, name string, uid types.UID) []metav1.OwnerReference {
+	yes := true
+	return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid, Controller: &yes, BlockOwnerDeletion: &yes}}
+}
+
+// randomName returns n characters in the alphabet the ReplicaSet controller
+// uses for template hashes and pod name suffixes.
+func randomName(rng *rand.Rand, n int) string {
+	const alphabet = "bcdfghjklmnpqrstvwxz2456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[rng.IntN(len(alphabet))]
+	}
+	return string(b)
+}
+
+// fakeUID derives a stable UID from the cluster and object identity, so
+// UIDs do not depend on generation order.
+func fakeUID(cluster, kind, name string) types.UID {
+	r := newRand(0, cluster+"/"+kind+"/"+name)
+	return types.UID(fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		r.Uint32(), r.Uint32()&0xffff, r.Uint32()&0xffff, r.Uint32()&0xffff, r.Uint64()&0xffffffffffff))
+}
+
+// toUnstructured converts typed objects for the fake dynamic client, whose
+// scheme knows no Go types.
+func toUnstructured(objs []runtime.Object) []runtime.Object {
+	out := make([]runtime.Object, 0, len(objs))
+	for _, obj := range objs {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			// Only reachable if a generated object is not a valid API type.
+			panic(fmt.Sprintf("testharness: converting %T: %v", obj, err))
+		}
+		out = append(out, &unstructured.Unstructured{Object: m})
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package testharness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFakeClusters_ListAndHealth(t *testing.T) {
+	h := New(Options{})
+	ctx := context.Background()
+
+	clusters, err := h.Clusters.ListClusters(ctx)
+	require.NoError(t, err)
+	names := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		names = append(names, c.Name)
+	}
+	assert.ElementsMatch(t, ClusterNames, names)
+
+	healthy, err := h.Clusters.GetClusterHealth(ctx, ClusterPrimary)
+	require.NoError(t, err)
+	assert.True(t, healthy.Reachable)
+	assert.Empty(t, healthy.Issues)
+
+	degraded, err := h.Clusters.GetClusterHealth(ctx, ClusterDegraded)
+	require.NoError(t, err)
+	assert.True(t, degraded.Reachable)
+	assert.NotEmpty(t, degraded.Issues, "the degraded cluster reports its memory-pressure node")
+
+	typed, err := h.Clusters.GetClient(ClusterGPU)
+	require.NoError(t, err)
+	v, err := typed.Discovery().ServerVersion()
+	require.NoError(t, err)
+	assert.Equal(t, FakeKubernetesVersion, v.GitVersion)
+}
+
+func TestFakeClusters_TypedAndDynamicAgree(t *testing.T) {
+	h := New(Options{})
+	ctx := context.Background()
+
+	typed, err := h.Clusters.GetClient(ClusterDegraded)
+	require.NoError(t, err)
+	deployments, err := typed.AppsV1().Deployments("shop").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+
+	dyn, err := h.Clusters.GetDynamicClient(ClusterDegraded)
+	require.NoError(t, err)
+	list, err := dyn.Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).Namespace("shop").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list.Items, len(deployments.Items))
+
+	var checkout *appsv1.Deployment
+	for i := range deployments.Items {
+		if deployments.Items[i].Name == "checkout" {
+			checkout = &deployments.Items[i]
+		}
+	}
+	require.NotNil(t, checkout)
+	assert.Less(t, checkout.Status.ReadyReplicas, *checkout.Spec.Replicas, "checkout is degraded on "+ClusterDegraded)
+}
+
+func TestGenerateCluster_Deterministic(t *testing.T) {
+	opts := Options{Seed: DefaultSeed, BaseTime: DefaultBaseTime}
+	a := generateCluster(opts, ClusterPrimary)
+	b := generateCluster(opts, ClusterPrimary)
+	assert.Equal(t, a, b)
+
+	other := generateCluster(Options{Seed: DefaultSeed + 1, BaseTime: DefaultBaseTime}, ClusterPrimary)
+	podNames := func(o *clusterObjects) []string {
+		var names []string
+		for _, p := range o.pods {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	assert.NotEqual(t, podNames(a), podNames(other), "a different seed yields different data")
+
+	gpu := generateCluster(opts, ClusterGPU)
+	var gpuNodes int
+	for _, n := range gpu.nodes {
+		if _, ok := n.Status.Allocatable[gpuResourceName]; ok {
+			gpuNodes++
+		}
+	}
+	assert.Equal(t, 1, gpuNodes)
+	for _, d := range a.deployments {
+		assert.NotEqual(t, "inference", d.Name, "GPU workloads only run on "+ClusterGPU)
+	}
+}
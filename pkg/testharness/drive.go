@@ -0,0 +1,255 @@
+package testharness
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DriveRootFolderID is the benchmark folder the fake Drive serves. Point
+// BenchmarkHandlers at it together with DriveServer.Client.
+const DriveRootFolderID = "fake-benchmarks-root"
+
+const (
+	driveFolderMIME = "application/vnd.google-apps.folder"
+	driveYAMLMIME   = "application/x-yaml"
+	driveListHost   = "www.googleapis.com"
+	driveListPath   = "/drive/v3/files"
+	driveFileHost   = "drive.google.com"
+	driveFilePath   = "/uc"
+	// reportStages is how many load stages (and so report files) each run has.
+	reportStages = 2
+)
+
+// fakeExperiment describes one experiment folder. Nested experiments use the
+// run → results → <result> → benchmark_report*.yaml layout, flat ones keep
+// the reports directly in the run folder.
+type fakeExperiment struct {
+	name   string
+	model  string
+	accel  string
+	count  int
+	tp     int
+	runs   int
+	nested bool
+}
+
+var fakeExperiments = []fakeExperiment{
+	{name: "inference-scheduling", model: "meta-llama/Llama-3.1-8B-Instruct", accel: "NVIDIA-H100-80GB-HBM3", count: 2, tp: 1, runs: 2},
+	{name: "pd-disaggregation", model: "Qwen/Qwen3-32B", accel: "NVIDIA-A100-SXM4-80GB", count: 4, tp: 2, runs: 2, nested: true},
+}
+
+// driveEntry is a file or folder as the Drive v3 files.list API returns it.
+type driveEntry struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	MimeType    string `json:"mimeType"`
+	CreatedTime string `json:"createdTime"`
+}
+
+// DriveServer is an in-memory stand-in for the two Google Drive endpoints
+// the benchmark handlers call: files.list on www.googleapis.com and file
+// download on drive.google.com.
+type DriveServer struct {
+	children map[string][]driveEntry
+	files    map[string][]byte
+}
+
+func newDriveServer(opts Options) *DriveServer {
+	d := &DriveServer{children: map[string][]driveEntry{}, files: map[string][]byte{}}
+	for ei, exp := range fakeExperiments {
+		expCreated := opts.BaseTime.Add(-time.Duration(len(fakeExperiments)-ei) * 7 * 24 * time.Hour)
+		expID := d.addFolder(DriveRootFolderID, "exp-"+exp.name, exp.name, expCreated)
+		for run := range exp.runs {
+			runName := fmt.Sprintf("run-%03d", run+1)
+			runCreated := expCreated.Add(time.Duration(run+1) * 24 * time.Hour)
+			runID := d.addFolder(expID, expID+"-"+runName, runName, runCreated)
+			reportParent := runID
+			if exp.nested {
+				resultsID := d.addFolder(runID, runID+"-results", "results", runCreated)
+				reportParent = d.addFolder(resultsID, resultsID+"-0", exp.model, runCreated)
+			}
+			rng := newRand(opts.Seed, "drive/"+exp.name+"/"+runName)
+			for stage := 1; stage <= reportStages; stage++ {
+				name := fmt.Sprintf("benchmark_report_stage_%d.yaml", stage)
+				id := fmt.Sprintf("%s-stage-%d", reportParent, stage)
+				created := runCreated.Add(time.Duration(stage) * time.Hour)
+				d.children[reportParent] = append(d.children[reportParent], driveEntry{
+					ID: id, Name: name, MimeType: driveYAMLMIME, CreatedTime: created.Format(time.RFC3339),
+				})
+				d.files[id] = fakeReport(exp, stage, rng.Float64())
+			}
+		}
+	}
+	return d
+}
+
+func (d *DriveServer) addFolder(parent, id, name string, created time.Time) string {
+	d.children[parent] = append(d.children[parent], driveEntry{
+		ID: id, Name: name, MimeType: driveFolderMIME, CreatedTime: created.Format(time.RFC3339),
+	})
+	return id
+}
+
+// ReportCount is the number of benchmark report files in the fake folder.
+func (d *DriveServer) ReportCount() int {
+	return len(d.files)
+}
+
+// ServeHTTP answers files.list and download requests. Requests are routed
+// on host or path, so the handler also works mounted on an httptest.Server
+// behind a URL-rewriting transport.
+func (d *DriveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Host == driveFileHost || r.URL.Path == driveFilePath:
+		data, ok := d.files[r.URL.Query().Get("id")]
+		if !ok {
+			http.Error(w, "file not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", driveYAMLMIME)
+		w.Write(data) //nolint:errcheck // best-effort write to an in-memory recorder
+	case r.URL.Host == driveListHost || r.URL.Path == driveListPath:
+		parent, ok := parentFromQuery(r.URL.Query().Get("q"))
+		if !ok {
+			http.Error(w, "unsupported query", http.StatusBadRequest)
+			return
+		}
+		files := d.children[parent]
+		if files == nil {
+			files = []driveEntry{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"files": files}) //nolint:errcheck // best-effort write to an in-memory recorder
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Client returns an HTTP client that serves every request from d in
+// process, whatever its host, so no listener or network access is needed.
+func (d *DriveServer) Client() *http.Client {
+	return &http.Client{Transport: inProcessTransport{handler: d}}
+}
+
+type inProcessTransport struct {
+	handler http.Handler
+}
+
+func (t inProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// parentFromQuery extracts the folder ID from a "'<id>' in parents" query.
+func parentFromQuery(q string) (string, bool) {
+	id, rest, ok := strings.Cut(strings.TrimPrefix(q, "'"), "'")
+	if !ok || strings.TrimSpace(rest) != "in parents" || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// fakeReport renders one v0.1 benchmark report. jitter in [0,1) varies the
+// metrics between runs; higher stages run at a higher request rate and get
+// proportionally slower.
+func fakeReport(exp fakeExperiment, stage int, jitter float64) []byte {
+	const (
+		baseTTFTMs        = 45.0
+		baseTPOTMs        = 11.0
+		baseOutputTokRate = 1800.0
+		durationSeconds   = 120
+		outputLen         = 256
+		questionLen       = 512
+		systemPromptLen   = 1024
+	)
+	load := float64(stage)
+	ttft := round2(baseTTFTMs * load * (1 + 0.2*jitter))
+	tpot := round2(baseTPOTMs * (1 + 0.1*load) * (1 + 0.1*jitter))
+	outRate := round2(baseOutputTokRate * float64(exp.count) * (1 - 0.05*jitter))
+	reqRate := round2(outRate / outputLen)
+	stats := func(units string, mean float64) map[string]any {
+		return map[string]any{
+			"units": units,
+			"mean":  mean,
+			"p50":   round2(mean * 0.95),
+			"p90":   round2(mean * 1.4),
+			"p99":   round2(mean * 2.1),
+			"min":   round2(mean * 0.5),
+			"max":   round2(mean * 3),
+		}
+	}
+	stages := make([]map[string]any, 0, reportStages)
+	for s := 1; s <= reportStages; s++ {
+		stages = append(stages, map[string]any{"rate": float64(s) * 2, "duration": durationSeconds / reportStages})
+	}
+	report := map[string]any{
+		"version": "0.1",
+		"metrics": map[string]any{
+			"latency": map[string]any{
+				"time_to_first_token":   stats("ms", ttft),
+				"time_per_output_token": stats("ms", tpot),
+				"request_latency":       stats("ms", round2(ttft+tpot*outputLen)),
+			},
+			"throughput": map[string]any{
+				"output_tokens_per_sec": outRate,
+				"requests_per_sec":      reqRate,
+				"total_tokens_per_sec":  round2(outRate * 3),
+			},
+			"requests": map[string]any{
+				"total":    int(reqRate * durationSeconds),
+				"failures": int(jitter * 3),
+			},
+			"time": map[string]any{"duration": float64(durationSeconds)},
+		},
+		"scenario": map[string]any{
+			"host": map[string]any{
+				"accelerator": []map[string]any{{
+					"count":       exp.count,
+					"model":       exp.accel,
+					"parallelism": map[string]any{"dp": exp.count / exp.tp, "tp": exp.tp, "pp": 1, "ep": 1},
+				}},
+				"type": []string{"decode"},
+			},
+			"load": map[string]any{
+				"name":     "inference-perf",
+				"metadata": map[string]any{"stage": stage},
+				"args": map[string]any{
+					"data": map[string]any{
+						"type": "shared_prefix",
+						"shared_prefix": map[string]any{
+							"num_groups": 8, "num_prompts_per_group": 16,
+							"output_len": outputLen, "question_len": questionLen, "system_prompt_len": systemPromptLen,
+						},
+					},
+					"load":   map[string]any{"type": "constant", "stages": stages, "num_workers": 4},
+					"server": map[string]any{"type": "vllm", "model_name": exp.model, "base_url": "http://inference.fake.invalid:8000", "ignore_eos": true},
+				},
+			},
+			"model":    map[string]any{"name": exp.model},
+			"platform": map[string]any{"engine": []map[string]any{{"name": "vllm/vllm-openai:v0.8.5"}}},
+		},
+	}
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		// Only reachable if the literal above stops being marshalable.
+		panic(fmt.Sprintf("testharness: rendering benchmark report: %v", err))
+	}
+	return data
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
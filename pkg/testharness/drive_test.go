@@ -0,0 +1,66 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/api/handlers/benchmarks"
+)
+
+func TestDriveServer_FeedsBenchmarkHandlers(t *testing.T) {
+	h := New(Options{})
+	bench := benchmarks.NewBenchmarkHandlers("fake-key", DriveRootFolderID)
+	bench.SetHTTPClient(h.Drive.Client())
+
+	app := fiber.New()
+	app.Get("/reports", bench.GetReports)
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/reports", nil), 30000)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Reports       []benchmarks.BenchmarkReport `json:"reports"`
+		Source        string                       `json:"source"`
+		ParseFailures int                          `json:"parse_failures"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "live", body.Source)
+	assert.Zero(t, body.ParseFailures)
+	require.Len(t, body.Reports, h.Drive.ReportCount(), "flat and nested run layouts are both read")
+
+	eids := map[string]bool{}
+	for _, r := range body.Reports {
+		eids[r.Run.EID] = true
+		require.NotNil(t, r.Results.RequestPerformance.Aggregate.Latency.TimeToFirstToken)
+		assert.Positive(t, r.Results.RequestPerformance.Aggregate.Latency.TimeToFirstToken.Mean)
+	}
+	assert.True(t, eids["pd-disaggregation/run-002"])
+}
+
+func TestDriveServer_Deterministic(t *testing.T) {
+	a := New(Options{}).Drive
+	b := New(Options{}).Drive
+	assert.Equal(t, a.files, b.files)
+	assert.Equal(t, a.children, b.children)
+	assert.NotEqual(t, a.files, New(Options{Seed: 7}).Drive.files)
+}
+
+func TestDriveServer_UnknownRequests(t *testing.T) {
+	client := New(Options{}).Drive.Client()
+	for _, url := range []string{
+		"https://drive.google.com/uc?id=missing&export=download",
+		"https://www.googleapis.com/drive/v3/files?q=name+contains+'x'",
+		"https://example.invalid/other",
+	} {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.GreaterOrEqual(t, resp.StatusCode, http.StatusBadRequest, url)
+	}
+}
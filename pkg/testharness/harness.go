@@ -0,0 +1,90 @@
+// Package testharness wires deterministic in-memory fakes for everything the
+// console backend normally reaches over the network: the Kubernetes clusters
+// behind MultiClusterClient, the Google Drive folder the benchmark handlers
+// read, and an AI provider. The server uses it for --fake-mode, and
+// integration tests can build a Harness directly.
+//
+// The same Options always produce the same clusters, objects, benchmark
+// reports and AI replies, so Playwright snapshots and assertions stay stable.
+package testharness
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+
+	"github.com/kubestellar/console/pkg/ai"
+	"github.com/kubestellar/console/pkg/k8s"
+)
+
+// DefaultSeed is the seed used when Options.Seed is zero.
+const DefaultSeed uint64 = 42
+
+// DefaultBaseTime anchors every generated timestamp when Options.BaseTime is
+// zero. Object ages and benchmark run times are offsets back from it.
+var DefaultBaseTime = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Options selects the fake data set.
+type Options struct {
+	// Seed drives every random choice (replica counts, node sizes, metric
+	// values). Zero means DefaultSeed.
+	Seed uint64
+	// BaseTime is "now" for the generated data. Zero means DefaultBaseTime.
+	BaseTime time.Time
+}
+
+// Harness bundles the fakes built from one set of Options.
+type Harness struct {
+	Seed     uint64
+	BaseTime time.Time
+
+	// Clusters serves the fake clusters listed in ClusterNames.
+	Clusters *k8s.MultiClusterClient
+	// Drive serves the benchmark folder rooted at DriveRootFolderID.
+	Drive *DriveServer
+	// Provider answers chat requests with canned, seed-stable replies.
+	Provider *Provider
+}
+
+// New builds a Harness. It never touches the network or the filesystem.
+func New(opts Options) *Harness {
+	if opts.Seed == 0 {
+		opts.Seed = DefaultSeed
+	}
+	if opts.BaseTime.IsZero() {
+		opts.BaseTime = DefaultBaseTime
+	}
+	opts.BaseTime = opts.BaseTime.UTC()
+	return &Harness{
+		Seed:     opts.Seed,
+		BaseTime: opts.BaseTime,
+		Clusters: newFakeClusters(opts),
+		Drive:    newDriveServer(opts),
+		Provider: newProvider(opts.Seed),
+	}
+}
+
+// RegisterProvider adds the fake provider to reg and makes it the default,
+// so chat and mission endpoints answer without any API key.
+func (h *Harness) RegisterProvider(reg ai.Registry) error {
+	if reg == nil {
+		return fmt.Errorf("ai registry is not initialized")
+	}
+	if _, err := reg.Get(h.Provider.Name()); err != nil {
+		if err := reg.Register(h.Provider); err != nil {
+			return err
+		}
+	}
+	return reg.SetDefault(h.Provider.Name())
+}
+
+// newRand returns a generator seeded from seed and a label, so each part of
+// the data set draws from its own stream and adding a cluster or report does
+// not shift the values of the others.
+func newRand(seed uint64, label string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(label)) //nolint:errcheck // hash.Hash.Write never returns an error
+	s := seed ^ h.Sum64()
+	return rand.New(rand.NewPCG(s, s^0x9e3779b97f4a7c15)) // #nosec G404 -- fake data, not security-critical
+}
@@ -0,0 +1,89 @@
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/kubestellar/console/pkg/ai"
+)
+
+// ProviderName is the registry name of the fake AI provider.
+const ProviderName = "fake"
+
+// fakeReplies are the canned answers the fake provider picks from. Each
+// reply names a fake cluster so UI tests can assert on cluster links.
+var fakeReplies = []string{
+	"All workloads on " + ClusterPrimary + " are healthy. No action is needed.",
+	"The checkout deployment on " + ClusterDegraded + " is crash-looping; its last container exited with code 1. Check the pod logs before restarting it.",
+	"Node pressure on " + ClusterDegraded + " is the most likely cause of the restarts. Consider cordoning the node and rescheduling its pods.",
+	ClusterGPU + " has 4 GPUs on one node; the inference deployment requests 1 of them.",
+}
+
+// Provider is a deterministic ai.Provider. The reply to a prompt depends
+// only on the harness seed and the prompt text, never on history or time.
+type Provider struct {
+	seed uint64
+}
+
+var _ ai.Provider = (*Provider)(nil)
+
+func newProvider(seed uint64) *Provider {
+	return &Provider{seed: seed}
+}
+
+func (p *Provider) Name() string        { return ProviderName }
+func (p *Provider) DisplayName() string { return "Fake (test harness)" }
+func (p *Provider) Description() string {
+	return "Deterministic canned replies for fake mode and end-to-end tests"
+}
+func (p *Provider) Provider() string                    { return "kubestellar" }
+func (p *Provider) IsAvailable() bool                   { return true }
+func (p *Provider) Capabilities() ai.ProviderCapability { return ai.CapabilityChat }
+
+// Reply returns the canned answer for prompt.
+func (p *Provider) Reply(prompt string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d/%s", p.seed, strings.TrimSpace(prompt))
+	return fakeReplies[h.Sum64()%uint64(len(fakeReplies))]
+}
+
+// Chat returns the canned answer for the request prompt.
+func (p *Provider) Chat(ctx context.Context, req *ai.ChatRequest) (*ai.ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("chat request is nil")
+	}
+	return p.response(req.Prompt, p.Reply(req.Prompt)), nil
+}
+
+// StreamChat sends the canned answer one word at a time.
+func (p *Provider) StreamChat(ctx context.Context, req *ai.ChatRequest, onChunk func(chunk string)) (*ai.ChatResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("chat request is nil")
+	}
+	reply := p.Reply(req.Prompt)
+	words := strings.SplitAfter(reply, " ")
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if onChunk != nil {
+			onChunk(word)
+		}
+	}
+	return p.response(req.Prompt, reply), nil
+}
+
+func (p *Provider) response(prompt, reply string) *ai.ChatResponse {
+	in, out := len(strings.Fields(prompt)), len(strings.Fields(reply))
+	return &ai.ChatResponse{
+		Content:    reply,
+		Agent:      ProviderName,
+		TokenUsage: &ai.ProviderTokenUsage{InputTokens: in, OutputTokens: out, TotalTokens: in + out},
+		Done:       true,
+	}
+}
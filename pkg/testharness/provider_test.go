@@ -0,0 +1,74 @@
+package testharness
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/ai"
+)
+
+func TestProvider_StableReplies(t *testing.T) {
+	p := New(Options{}).Provider
+	ctx := context.Background()
+	req := &ai.ChatRequest{Prompt: "why is checkout failing?"}
+
+	first, err := p.Chat(ctx, req)
+	require.NoError(t, err)
+	second, err := New(Options{}).Provider.Chat(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Contains(t, fakeReplies, first.Content)
+	assert.Equal(t, ProviderName, first.Agent)
+
+	var chunks []string
+	streamed, err := p.StreamChat(ctx, req, func(c string) { chunks = append(chunks, c) })
+	require.NoError(t, err)
+	assert.Equal(t, first.Content, streamed.Content)
+	assert.Equal(t, first.Content, strings.Join(chunks, ""))
+	assert.Greater(t, len(chunks), 1)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = p.Chat(cancelled, req)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// memRegistry is the minimal ai.Registry RegisterProvider needs.
+type memRegistry struct {
+	ai.Registry
+	providers map[string]ai.Provider
+	def       string
+}
+
+func (r *memRegistry) Get(name string) (ai.Provider, error) {
+	if p, ok := r.providers[name]; ok {
+		return p, nil
+	}
+	return nil, assert.AnError
+}
+
+func (r *memRegistry) Register(p ai.Provider) error {
+	r.providers[p.Name()] = p
+	return nil
+}
+
+func (r *memRegistry) SetDefault(name string) error {
+	r.def = name
+	return nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	h := New(Options{})
+	reg := &memRegistry{providers: map[string]ai.Provider{}}
+
+	require.NoError(t, h.RegisterProvider(reg))
+	require.NoError(t, h.RegisterProvider(reg), "registering twice is a no-op")
+	assert.Equal(t, ProviderName, reg.def)
+	assert.Same(t, h.Provider, reg.providers[ProviderName])
+
+	assert.Error(t, h.RegisterProvider(nil))
+}
@@ -0,0 +1,270 @@
+// Package velero watches Velero backups on managed clusters and alerts when
+// they fail.
+package velero
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/notifications"
+	"github.com/kubestellar/console/pkg/safego"
+)
+
+const (
+	// defaultCheckInterval is how often backups are checked. Schedules rarely
+	// run more than hourly, so a few minutes of delay is fine.
+	defaultCheckInterval = 5 * time.Minute
+	// listTimeout bounds listing the backups of one cluster.
+	listTimeout = 30 * time.Second
+	// lookback is how old a failed backup may be and still alert. Without
+	// it every restart would re-fire alerts for backups long since replaced.
+	lookback = 24 * time.Hour
+
+	alertStatusFiring   = "firing"
+	alertStatusResolved = "resolved"
+)
+
+// backupLister is the subset of k8s.MultiClusterClient the monitor needs.
+type backupLister interface {
+	DeduplicatedClusters(ctx context.Context) ([]k8s.ClusterInfo, error)
+	ListVeleroBackups(ctx context.Context, contextName string, limit int) ([]v1alpha1.VeleroBackup, error)
+}
+
+// alertSender is the subset of notifications.Service the monitor needs.
+type alertSender interface {
+	SendAlert(alert notifications.Alert) error
+}
+
+// Monitor periodically checks the latest backup of every Velero schedule,
+// and every ad-hoc backup, on each cluster. It sends a firing alert when a
+// backup fails and a resolved alert once a later backup of the same schedule
+// completes, never one per check.
+type Monitor struct {
+	clusters backupLister
+	alerts   alertSender
+	interval time.Duration
+
+	mu     sync.Mutex
+	firing map[string]notifications.Alert // keyed by alert RuleID
+}
+
+// NewMonitor returns a monitor that runs every VELERO_MONITOR_INTERVAL
+// (default 5m).
+func NewMonitor(clusters backupLister, alerts alertSender) *Monitor {
+	interval := defaultCheckInterval
+	if raw := os.Getenv("VELERO_MONITOR_INTERVAL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			interval = d
+		} else {
+			slog.Warn("[Velero] invalid VELERO_MONITOR_INTERVAL, using default", "value", raw, "default", defaultCheckInterval)
+		}
+	}
+	return &Monitor{
+		clusters: clusters,
+		alerts:   alerts,
+		interval: interval,
+		firing:   make(map[string]notifications.Alert),
+	}
+}
+
+// Start checks backups every interval until done is closed.
+func (m *Monitor) Start(done <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	safego.GoWith("velero/monitor-cancel", func() {
+		<-done
+		cancel()
+	})
+	safego.GoWith("velero/monitor", func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				m.checkAll(ctx, now)
+			}
+		}
+	})
+	slog.Info("[Velero] backup monitor started", "interval", m.interval)
+}
+
+// checkAll checks the backups of every cluster once and sends alerts for
+// schedules and backups whose state changed.
+func (m *Monitor) checkAll(ctx context.Context, now time.Time) {
+	clusters, err := m.clusters.DeduplicatedClusters(ctx)
+	if err != nil {
+		slog.Error("[Velero] failed to list clusters", "error", err)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range clusters {
+		listCtx, cancel := context.WithTimeout(ctx, listTimeout)
+		backups, err := m.clusters.ListVeleroBackups(listCtx, c.Name, 0)
+		cancel()
+		if err != nil {
+			// Keep the cluster's alerts as they are, so an unreachable
+			// cluster neither resolves nor re-fires them.
+			slog.Warn("[Velero] failed to list backups", "cluster", c.Name, "error", err)
+			m.mu.Lock()
+			for id, a := range m.firing {
+				if a.Cluster == c.Name {
+					seen[id] = true
+				}
+			}
+			m.mu.Unlock()
+			continue
+		}
+		for _, b := range latestFinished(backups, now) {
+			id := alertRuleID(b)
+			seen[id] = true
+			m.transition(id, b, now)
+		}
+	}
+
+	// Backups that were deleted or aged out of the lookback resolve.
+	m.mu.Lock()
+	var stale []notifications.Alert
+	for id, a := range m.firing {
+		if !seen[id] {
+			stale = append(stale, a)
+			delete(m.firing, id)
+		}
+	}
+	m.mu.Unlock()
+	for _, a := range stale {
+		m.send(resolved(a, now, "No failed backup in the last "+lookback.String()))
+	}
+}
+
+// latestFinished returns the newest finished backup per schedule, plus every
+// finished ad-hoc backup, within the lookback. backups must be newest first.
+func latestFinished(backups []v1alpha1.VeleroBackup, now time.Time) []v1alpha1.VeleroBackup {
+	var out []v1alpha1.VeleroBackup
+	seen := make(map[string]bool)
+	for _, b := range backups {
+		if !b.Finished() || !recent(b, now) {
+			continue
+		}
+		id := alertRuleID(b)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, b)
+	}
+	return out
+}
+
+// recent reports whether b finished within the lookback. A backup that
+// failed validation has no timestamps and always counts.
+func recent(b v1alpha1.VeleroBackup, now time.Time) bool {
+	ts := b.CompletedAt
+	if ts == "" {
+		ts = b.StartedAt
+	}
+	if ts == "" {
+		return true
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return true
+	}
+	return now.Sub(t) <= lookback
+}
+
+// transition records whether the latest backup for id failed and sends an
+// alert when that changed.
+func (m *Monitor) transition(id string, b v1alpha1.VeleroBackup, now time.Time) {
+	m.mu.Lock()
+	prev, wasFiring := m.firing[id]
+	var out *notifications.Alert
+	switch {
+	case b.Failed() && !wasFiring:
+		a := newAlert(b, now)
+		m.firing[id] = a
+		out = &a
+	case !b.Failed() && wasFiring:
+		delete(m.firing, id)
+		r := resolved(prev, now, fmt.Sprintf("Backup %s completed", b.Name))
+		out = &r
+	}
+	m.mu.Unlock()
+	if out != nil {
+		m.send(*out)
+	}
+}
+
+func (m *Monitor) send(a notifications.Alert) {
+	if m.alerts == nil {
+		return
+	}
+	if err := m.alerts.SendAlert(a); err != nil {
+		slog.Error("[Velero] failed to send alert", "rule", a.RuleName, "status", a.Status, "error", err)
+	}
+}
+
+// alertRuleID is stable across checks so PagerDuty and OpsGenie deduplicate
+// repeated firings and match the resolve to the trigger. Backups of one
+// schedule share an ID; an ad-hoc backup has its own.
+func alertRuleID(b v1alpha1.VeleroBackup) string {
+	if b.Schedule != "" {
+		return "velero:" + b.Cluster + "/" + b.Namespace + "/schedule/" + b.Schedule
+	}
+	return "velero:" + b.Cluster + "/" + b.Namespace + "/backup/" + b.Name
+}
+
+func newAlert(b v1alpha1.VeleroBackup, now time.Time) notifications.Alert {
+	resource, kind := b.Name, "Backup"
+	if b.Schedule != "" {
+		resource, kind = b.Schedule, "Schedule"
+	}
+	// A partial backup still restores most resources.
+	severity := notifications.SeverityCritical
+	if b.Phase == v1alpha1.VeleroPhasePartiallyFailed {
+		severity = notifications.SeverityWarning
+	}
+	message := fmt.Sprintf("Backup %s %s with %d errors", b.Name, b.Phase, b.Errors)
+	if b.FailureReason != "" {
+		message = fmt.Sprintf("Backup %s %s: %s", b.Name, b.Phase, b.FailureReason)
+	}
+	return notifications.Alert{
+		ID:       uuid.New().String(),
+		RuleID:   alertRuleID(b),
+		RuleName: fmt.Sprintf("Velero %s %s failed", kind, resource),
+		Severity: severity,
+		Status:   alertStatusFiring,
+		Message:  message,
+		Details: map[string]interface{}{
+			"backup":              b.Name,
+			"phase":               b.Phase,
+			"errors":              b.Errors,
+			"warnings":            b.Warnings,
+			"schedule":            b.Schedule,
+			"storage_location":    b.StorageLocation,
+			"included_namespaces": b.IncludedNamespaces,
+		},
+		Cluster:      b.Cluster,
+		Namespace:    b.Namespace,
+		Resource:     resource,
+		ResourceKind: kind,
+		FiredAt:      now,
+	}
+}
+
+func resolved(a notifications.Alert, now time.Time, message string) notifications.Alert {
+	a.ID = uuid.New().String()
+	a.Status = alertStatusResolved
+	a.Message = message
+	a.FiredAt = now
+	return a
+}
@@ -0,0 +1,153 @@
+package velero
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubestellar/console/pkg/apis/v1alpha1"
+	"github.com/kubestellar/console/pkg/k8s"
+	"github.com/kubestellar/console/pkg/notifications"
+)
+
+type fakeLister struct {
+	backups map[string][]v1alpha1.VeleroBackup
+	errs    map[string]error
+}
+
+func (f *fakeLister) DeduplicatedClusters(context.Context) ([]k8s.ClusterInfo, error) {
+	var clusters []k8s.ClusterInfo
+	for name := range f.backups {
+		clusters = append(clusters, k8s.ClusterInfo{Name: name})
+	}
+	return clusters, nil
+}
+
+func (f *fakeLister) ListVeleroBackups(_ context.Context, cluster string, _ int) ([]v1alpha1.VeleroBackup, error) {
+	if err := f.errs[cluster]; err != nil {
+		return nil, err
+	}
+	return f.backups[cluster], nil
+}
+
+type recordingSender struct {
+	mu     sync.Mutex
+	alerts []notifications.Alert
+}
+
+func (r *recordingSender) SendAlert(a notifications.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func (r *recordingSender) take() []notifications.Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.alerts
+	r.alerts = nil
+	return out
+}
+
+func scheduled(name, phase string, completed time.Time) v1alpha1.VeleroBackup {
+	return v1alpha1.VeleroBackup{
+		Name: name, Namespace: "velero", Cluster: "c1", Schedule: "daily", Phase: phase,
+		CompletedAt: completed.UTC().Format(time.RFC3339),
+	}
+}
+
+func TestMonitor_FiresOnceAndResolves(t *testing.T) {
+	now := time.Now()
+	lister := &fakeLister{backups: map[string][]v1alpha1.VeleroBackup{
+		"c1": {
+			scheduled("daily-2", v1alpha1.VeleroPhaseFailed, now.Add(-time.Hour)),
+			scheduled("daily-1", v1alpha1.VeleroPhaseCompleted, now.Add(-25*time.Hour)),
+		},
+	}}
+	lister.backups["c1"][0].FailureReason = "object storage unreachable"
+	sender := &recordingSender{}
+	m := NewMonitor(lister, sender)
+	ctx := context.Background()
+
+	m.checkAll(ctx, now)
+	alerts := sender.take()
+	require.Len(t, alerts, 1)
+	a := alerts[0]
+	assert.Equal(t, alertStatusFiring, a.Status)
+	assert.Equal(t, "velero:c1/velero/schedule/daily", a.RuleID)
+	assert.Equal(t, notifications.SeverityCritical, a.Severity)
+	assert.Equal(t, "Schedule", a.ResourceKind)
+	assert.Equal(t, "daily", a.Resource)
+	assert.Contains(t, a.Message, "object storage unreachable")
+
+	m.checkAll(ctx, now)
+	assert.Empty(t, sender.take(), "a failure already firing is not re-sent")
+
+	// A newer backup still running does not resolve the alert.
+	lister.backups["c1"] = append([]v1alpha1.VeleroBackup{{
+		Name: "daily-3", Namespace: "velero", Cluster: "c1", Schedule: "daily", Phase: v1alpha1.VeleroPhaseInProgress,
+	}}, lister.backups["c1"]...)
+	m.checkAll(ctx, now)
+	assert.Empty(t, sender.take())
+
+	lister.backups["c1"][0] = scheduled("daily-3", v1alpha1.VeleroPhaseCompleted, now)
+	m.checkAll(ctx, now)
+	alerts = sender.take()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alertStatusResolved, alerts[0].Status)
+	assert.Equal(t, a.RuleID, alerts[0].RuleID)
+	assert.Equal(t, "Backup daily-3 completed", alerts[0].Message)
+}
+
+func TestMonitor_AdHocAndLookback(t *testing.T) {
+	now := time.Now()
+	lister := &fakeLister{backups: map[string][]v1alpha1.VeleroBackup{
+		"c1": {
+			{Name: "manual", Namespace: "velero", Cluster: "c1", Phase: v1alpha1.VeleroPhasePartiallyFailed, Errors: 3,
+				CompletedAt: now.Add(-time.Hour).UTC().Format(time.RFC3339)},
+			scheduled("weekly-1", v1alpha1.VeleroPhaseFailed, now.Add(-48*time.Hour)),
+		},
+	}}
+	sender := &recordingSender{}
+	m := NewMonitor(lister, sender)
+
+	m.checkAll(context.Background(), now)
+	alerts := sender.take()
+	require.Len(t, alerts, 1, "failures older than the lookback do not alert")
+	assert.Equal(t, "velero:c1/velero/backup/manual", alerts[0].RuleID)
+	assert.Equal(t, notifications.SeverityWarning, alerts[0].Severity)
+	assert.Equal(t, "Backup manual PartiallyFailed with 3 errors", alerts[0].Message)
+
+	// Deleting the backup resolves its alert.
+	lister.backups["c1"] = nil
+	m.checkAll(context.Background(), now)
+	alerts = sender.take()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alertStatusResolved, alerts[0].Status)
+}
+
+func TestMonitor_ListErrorKeepsState(t *testing.T) {
+	now := time.Now()
+	lister := &fakeLister{backups: map[string][]v1alpha1.VeleroBackup{
+		"c1": {scheduled("daily-1", v1alpha1.VeleroPhaseFailed, now)},
+	}}
+	sender := &recordingSender{}
+	m := NewMonitor(lister, sender)
+
+	m.checkAll(context.Background(), now)
+	require.Len(t, sender.take(), 1)
+
+	lister.errs = map[string]error{"c1": errors.New("connection refused")}
+	m.checkAll(context.Background(), now)
+	assert.Empty(t, sender.take(), "an unreachable cluster neither resolves nor re-fires")
+
+	lister.errs = nil
+	m.checkAll(context.Background(), now)
+	assert.Empty(t, sender.take())
+}
@@ -0,0 +1,101 @@
+package vulnscan
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// notFoundTTL is how long a missing report is remembered. It is short
+	// so a freshly pushed and scanned image is picked up soon.
+	notFoundTTL = 5 * time.Minute
+	// maxCacheEntries bounds the cache; past it the entry closest to
+	// expiry is evicted.
+	maxCacheEntries = 5000
+)
+
+type cacheEntry struct {
+	summary *Summary
+	err     error // nil or ErrNotFound
+	expires time.Time
+}
+
+// Cache is a Scanner that reuses summaries for a TTL and coalesces
+// concurrent scans of the same image. Reports and ErrNotFound are cached;
+// other errors are not, so a scanner outage is retried on the next lookup.
+type Cache struct {
+	scanner Scanner
+	ttl     time.Duration
+	now     func() time.Time
+
+	group   singleflight.Group
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache wraps scanner with a cache keeping summaries for ttl.
+func NewCache(scanner Scanner, ttl time.Duration) *Cache {
+	return &Cache{
+		scanner: scanner,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Name implements Scanner.
+func (c *Cache) Name() string { return c.scanner.Name() }
+
+// Scan implements Scanner.
+func (c *Cache) Scan(ctx context.Context, image string) (*Summary, error) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.entries[image]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.summary, e.err
+	}
+
+	v, err, _ := c.group.Do(image, func() (interface{}, error) {
+		// The scan outlives a caller that gives up, so other callers
+		// waiting on it still get the result.
+		summary, err := c.scanner.Scan(context.WithoutCancel(ctx), image)
+		switch {
+		case err == nil:
+			c.store(image, cacheEntry{summary: summary, expires: c.now().Add(c.ttl)})
+		case errors.Is(err, ErrNotFound):
+			c.store(image, cacheEntry{err: ErrNotFound, expires: c.now().Add(min(notFoundTTL, c.ttl))})
+		}
+		return summary, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Summary), nil
+}
+
+func (c *Cache) store(image string, e cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[image]; !ok && len(c.entries) >= maxCacheEntries {
+		now := c.now()
+		oldest := ""
+		for k, v := range c.entries {
+			if !now.Before(v.expires) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldest == "" || v.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[image] = e
+}
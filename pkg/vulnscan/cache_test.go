@@ -0,0 +1,79 @@
+package vulnscan
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingScanner returns a fixed outcome per image and counts its scans.
+type countingScanner struct {
+	mu    sync.Mutex
+	errs  map[string]error
+	scans map[string]int
+}
+
+func (s *countingScanner) Name() string { return "fake" }
+
+func (s *countingScanner) Scan(_ context.Context, image string) (*Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scans == nil {
+		s.scans = make(map[string]int)
+	}
+	s.scans[image]++
+	if err := s.errs[image]; err != nil {
+		return nil, err
+	}
+	return &Summary{Image: image, High: 1}, nil
+}
+
+func TestCache(t *testing.T) {
+	fake := &countingScanner{errs: map[string]error{
+		"missing:1": ErrNotFound,
+		"broken:1":  errors.New("connection refused"),
+	}}
+	cache := NewCache(fake, time.Hour)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		s, err := cache.Scan(ctx, "app:1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.High)
+		_, err = cache.Scan(ctx, "missing:1")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = cache.Scan(ctx, "broken:1")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, fake.scans["app:1"])
+	assert.Equal(t, 1, fake.scans["missing:1"])
+	assert.Equal(t, 2, fake.scans["broken:1"], "scanner errors are not cached")
+
+	now = now.Add(10 * time.Minute)
+	cache.Scan(ctx, "app:1")
+	cache.Scan(ctx, "missing:1")
+	assert.Equal(t, 1, fake.scans["app:1"])
+	assert.Equal(t, 2, fake.scans["missing:1"], "a missing report is looked up again sooner")
+
+	now = now.Add(time.Hour)
+	cache.Scan(ctx, "app:1")
+	assert.Equal(t, 2, fake.scans["app:1"])
+}
+
+func TestScanAll(t *testing.T) {
+	fake := &countingScanner{errs: map[string]error{"missing:1": ErrNotFound}}
+	results := ScanAll(context.Background(), fake, []string{"a:1", "b:1", "a:1", "missing:1"})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, 1, results["a:1"].Summary.High)
+	assert.NoError(t, results["b:1"].Err)
+	assert.ErrorIs(t, results["missing:1"].Err, ErrNotFound)
+	assert.Equal(t, 1, fake.scans["a:1"])
+}
@@ -0,0 +1,143 @@
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// harborTimeout bounds one Harbor API call.
+	harborTimeout = 15 * time.Second
+	// harborReportMIME is the scan report type Harbor's Trivy adapter
+	// produces, and the key of the artifact's scan overview.
+	harborReportMIME = "application/vnd.security.vulnerability.report; version=1.1"
+	// maxHarborResponseBytes bounds an artifact response.
+	maxHarborResponseBytes = 1 << 20
+)
+
+// HarborScanner reads the scan overview Harbor keeps for each artifact. It
+// only knows images pushed to its own registry; others are ErrNotFound.
+type HarborScanner struct {
+	baseURL  *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewHarborScanner returns a scanner for the Harbor instance at rawURL. The
+// username and password, typically of a robot account, may be empty for
+// public projects.
+func NewHarborScanner(rawURL, username, password string) (*HarborScanner, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("HARBOR_URL must be an http or https URL, got %q", rawURL)
+	}
+	return &HarborScanner{
+		baseURL:  u,
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: harborTimeout},
+	}, nil
+}
+
+// Name implements Scanner.
+func (h *HarborScanner) Name() string { return KindHarbor }
+
+// harborArtifact is the part of Harbor's artifact response the scanner
+// reads.
+type harborArtifact struct {
+	Digest       string `json:"digest"`
+	ScanOverview map[string]struct {
+		ScanStatus string    `json:"scan_status"`
+		EndTime    time.Time `json:"end_time"`
+		Summary    struct {
+			Fixable int            `json:"fixable"`
+			Summary map[string]int `json:"summary"`
+		} `json:"summary"`
+	} `json:"scan_overview"`
+}
+
+// Scan implements Scanner.
+func (h *HarborScanner) Scan(ctx context.Context, image string) (*Summary, error) {
+	if err := ValidateImage(image); err != nil {
+		return nil, err
+	}
+	project, repository, reference, ok := h.splitImage(image)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// Harbor wants the slashes of a nested repository encoded twice, since
+	// the router decodes the path once before matching it.
+	repository = url.PathEscape(url.PathEscape(repository))
+	u := h.baseURL.JoinPath("api/v2.0/projects", project, "repositories")
+	apiURL := u.String() + "/" + repository + "/artifacts/" + url.PathEscape(reference) + "?with_scan_overview=true"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Accept-Vulnerabilities", harborReportMIME)
+	if h.username != "" {
+		req.SetBasicAuth(h.username, h.password)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("harbor: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("harbor: artifact lookup returned %s", resp.Status)
+	}
+
+	var artifact harborArtifact
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxHarborResponseBytes)).Decode(&artifact); err != nil {
+		return nil, fmt.Errorf("harbor: decode artifact: %w", err)
+	}
+	overview, ok := artifact.ScanOverview[harborReportMIME]
+	if !ok || overview.ScanStatus != "Success" {
+		return nil, ErrNotFound
+	}
+	s := &Summary{
+		Image:     image,
+		Digest:    artifact.Digest,
+		Fixable:   overview.Summary.Fixable,
+		Scanner:   KindHarbor,
+		ScannedAt: overview.EndTime,
+	}
+	for severity, n := range overview.Summary.Summary {
+		s.add(severity, n)
+	}
+	return s, nil
+}
+
+// splitImage splits an image of this registry into its Harbor project,
+// repository and tag or digest. ok is false for images of other registries.
+func (h *HarborScanner) splitImage(image string) (project, repository, reference string, ok bool) {
+	host, path, found := strings.Cut(image, "/")
+	if !found || !strings.EqualFold(host, h.baseURL.Host) {
+		return "", "", "", false
+	}
+	reference = "latest"
+	path, digest, _ := strings.Cut(path, "@")
+	if i := strings.LastIndex(path, ":"); i >= 0 {
+		path, reference = path[:i], path[i+1:]
+	}
+	if digest != "" {
+		reference = digest
+	}
+	project, repository, found = strings.Cut(path, "/")
+	if !found || project == "" || repository == "" {
+		return "", "", "", false
+	}
+	return project, repository, reference, true
+}
@@ -0,0 +1,80 @@
+package vulnscan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const harborArtifactJSON = `{
+  "digest": "sha256:abc",
+  "scan_overview": {
+    "application/vnd.security.vulnerability.report; version=1.1": {
+      "scan_status": "Success",
+      "end_time": "2026-10-01T12:00:00Z",
+      "summary": {"total": 9, "fixable": 4, "summary": {"Critical": 1, "High": 2, "Medium": 3, "Low": 2, "Unknown": 1}}
+    }
+  }
+}`
+
+func TestHarborScanner_Scan(t *testing.T) {
+	var gotPath, gotUser string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotUser, _, _ = r.BasicAuth()
+		assert.Equal(t, "true", r.URL.Query().Get("with_scan_overview"))
+		if strings.Contains(gotPath, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(harborArtifactJSON))
+	}))
+	defer srv.Close()
+
+	scanner, err := NewHarborScanner(srv.URL, "robot$console", "secret")
+	require.NoError(t, err)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	s, err := scanner.Scan(context.Background(), host+"/shop/team/checkout:1.2")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2.0/projects/shop/repositories/team%252Fcheckout/artifacts/1.2", gotPath)
+	assert.Equal(t, "robot$console", gotUser)
+	assert.Equal(t, "sha256:abc", s.Digest)
+	assert.Equal(t, 1, s.Critical)
+	assert.Equal(t, 2, s.High)
+	assert.Equal(t, 3, s.Medium)
+	assert.Equal(t, 2, s.Low)
+	assert.Equal(t, 1, s.Unknown)
+	assert.Equal(t, 4, s.Fixable)
+	assert.Equal(t, 9, s.Total())
+
+	_, err = scanner.Scan(context.Background(), host+"/shop/checkout@sha256:def")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2.0/projects/shop/repositories/checkout/artifacts/sha256:def", gotPath)
+
+	_, err = scanner.Scan(context.Background(), host+"/shop/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = scanner.Scan(context.Background(), "docker.io/library/nginx:1.25")
+	assert.ErrorIs(t, err, ErrNotFound, "images of other registries have no report")
+
+	_, err = scanner.Scan(context.Background(), "--help")
+	assert.Error(t, err)
+}
+
+func TestHarborScanner_NotScanned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"digest": "sha256:abc", "scan_overview": {}}`))
+	}))
+	defer srv.Close()
+
+	scanner, err := NewHarborScanner(srv.URL, "", "")
+	require.NoError(t, err)
+	_, err = scanner.Scan(context.Background(), strings.TrimPrefix(srv.URL, "http://")+"/shop/checkout:1.2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,157 @@
+// Package vulnscan looks up CVE summaries for container images in a
+// configured scanner, a Trivy server or a Harbor registry, and caches them.
+package vulnscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Scanner kinds accepted in VULN_SCANNER.
+const (
+	KindTrivy  = "trivy"
+	KindHarbor = "harbor"
+)
+
+const (
+	// defaultCacheTTL is how long a summary is reused. Reports only change
+	// when the scanner's vulnerability database is updated, a few times a day.
+	defaultCacheTTL = 6 * time.Hour
+	// scanConcurrency bounds the parallel lookups of ScanAll.
+	scanConcurrency = 4
+	// maxImageLength bounds an image reference; registries allow far less.
+	maxImageLength = 512
+)
+
+// ErrNotFound is returned when the scanner has no report for an image, for
+// example because it lives in another registry or has not been scanned yet.
+var ErrNotFound = errors.New("no vulnerability report for image")
+
+// imagePattern matches an image reference: registry, path, tag and digest
+// characters only, so a reference is never mistaken for a CLI flag.
+var imagePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/:@+-]*$`)
+
+// Summary counts the vulnerabilities of one image by severity.
+type Summary struct {
+	Image     string    `json:"image"`
+	Digest    string    `json:"digest,omitempty"`
+	Critical  int       `json:"critical"`
+	High      int       `json:"high"`
+	Medium    int       `json:"medium"`
+	Low       int       `json:"low"`
+	Unknown   int       `json:"unknown"`
+	Fixable   int       `json:"fixable"`
+	Scanner   string    `json:"scanner"`
+	ScannedAt time.Time `json:"scannedAt"`
+}
+
+// Total returns the number of vulnerabilities of every severity.
+func (s Summary) Total() int {
+	return s.Critical + s.High + s.Medium + s.Low + s.Unknown
+}
+
+// add counts one vulnerability of the scanner-reported severity.
+func (s *Summary) add(severity string, n int) {
+	switch severity {
+	case "CRITICAL", "Critical":
+		s.Critical += n
+	case "HIGH", "High":
+		s.High += n
+	case "MEDIUM", "Medium":
+		s.Medium += n
+	case "LOW", "Low":
+		s.Low += n
+	default:
+		s.Unknown += n
+	}
+}
+
+// Scanner returns the vulnerability summary of an image.
+type Scanner interface {
+	// Name identifies the scanner in summaries and logs.
+	Name() string
+	// Scan returns ErrNotFound when the scanner has no report for image.
+	Scan(ctx context.Context, image string) (*Summary, error)
+}
+
+// ValidateImage checks that image is a plausible image reference.
+func ValidateImage(image string) error {
+	if image == "" || len(image) > maxImageLength || !imagePattern.MatchString(image) {
+		return fmt.Errorf("invalid image reference %q", image)
+	}
+	return nil
+}
+
+// FromEnv returns a cached scanner configured by VULN_SCANNER and the
+// variables of the selected kind, or nil when VULN_SCANNER is unset.
+func FromEnv() (*Cache, error) {
+	var scanner Scanner
+	switch kind := os.Getenv("VULN_SCANNER"); kind {
+	case "":
+		return nil, nil
+	case KindTrivy:
+		s, err := NewTrivyScanner(os.Getenv("TRIVY_SERVER_URL"), os.Getenv("TRIVY_BINARY"))
+		if err != nil {
+			return nil, err
+		}
+		scanner = s
+	case KindHarbor:
+		s, err := NewHarborScanner(os.Getenv("HARBOR_URL"), os.Getenv("HARBOR_USERNAME"), os.Getenv("HARBOR_PASSWORD"))
+		if err != nil {
+			return nil, err
+		}
+		scanner = s
+	default:
+		return nil, fmt.Errorf("unknown VULN_SCANNER %q, want %s or %s", kind, KindTrivy, KindHarbor)
+	}
+
+	ttl := defaultCacheTTL
+	if raw := os.Getenv("VULN_CACHE_TTL"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			ttl = d
+		} else {
+			slog.Warn("[VulnScan] invalid VULN_CACHE_TTL, using default", "value", raw, "default", defaultCacheTTL)
+		}
+	}
+	return NewCache(scanner, ttl), nil
+}
+
+// Result is the outcome of scanning one image: a summary or an error.
+type Result struct {
+	Summary *Summary
+	Err     error
+}
+
+// ScanAll scans each distinct image, a few at a time, and returns the
+// results keyed by image.
+func ScanAll(ctx context.Context, s Scanner, images []string) map[string]Result {
+	results := make(map[string]Result, len(images))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, scanConcurrency)
+	seen := make(map[string]bool, len(images))
+	for _, image := range images {
+		if seen[image] {
+			continue
+		}
+		seen[image] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			summary, err := s.Scan(ctx, image)
+			mu.Lock()
+			results[image] = Result{Summary: summary, Err: err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,126 @@
+package vulnscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// trivyTimeout bounds one scan. Trivy pulls the image's layers to
+	// analyze them, which takes a while for large images.
+	trivyTimeout = 5 * time.Minute
+	// maxTrivyStderr bounds the stderr quoted in a scan error.
+	maxTrivyStderr = 512
+)
+
+// TrivyScanner scans images with the trivy CLI in client mode against a
+// Trivy server, which holds the vulnerability database. The server
+// authentication token, if any, is read by trivy from TRIVY_TOKEN.
+type TrivyScanner struct {
+	serverURL string
+	binary    string
+	// run executes trivy and returns its stdout; replaced in tests.
+	run func(ctx context.Context, binary string, args ...string) ([]byte, error)
+}
+
+// NewTrivyScanner returns a scanner using the Trivy server at serverURL.
+// binary defaults to trivy on PATH.
+func NewTrivyScanner(serverURL, binary string) (*TrivyScanner, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("TRIVY_SERVER_URL must be an http or https URL, got %q", serverURL)
+	}
+	if binary == "" {
+		binary = "trivy"
+	}
+	return &TrivyScanner{serverURL: serverURL, binary: binary, run: runTrivy}, nil
+}
+
+func runTrivy(ctx context.Context, binary string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxTrivyStderr {
+			msg = msg[len(msg)-maxTrivyStderr:]
+		}
+		return nil, fmt.Errorf("%w: %s", err, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Name implements Scanner.
+func (t *TrivyScanner) Name() string { return KindTrivy }
+
+// trivyReport is the part of trivy's JSON output the scanner reads.
+type trivyReport struct {
+	Metadata struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			PkgName         string `json:"PkgName"`
+			Severity        string `json:"Severity"`
+			FixedVersion    string `json:"FixedVersion"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// Scan implements Scanner.
+func (t *TrivyScanner) Scan(ctx context.Context, image string) (*Summary, error) {
+	if err := ValidateImage(image); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, trivyTimeout)
+	defer cancel()
+	out, err := t.run(ctx, t.binary, "image",
+		"--server", t.serverURL,
+		"--scanners", "vuln",
+		"--format", "json",
+		"--quiet",
+		"--", image)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("trivy: scan of %s timed out after %s", image, trivyTimeout)
+		}
+		return nil, fmt.Errorf("trivy: %w", err)
+	}
+
+	var report trivyReport
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, fmt.Errorf("trivy: decode report: %w", err)
+	}
+	s := &Summary{Image: image, Scanner: KindTrivy, ScannedAt: time.Now().UTC()}
+	if len(report.Metadata.RepoDigests) > 0 {
+		if _, digest, ok := strings.Cut(report.Metadata.RepoDigests[0], "@"); ok {
+			s.Digest = digest
+		}
+	}
+	// A package can be listed once per target it appears in; count each
+	// vulnerability of a package once.
+	seen := make(map[string]bool)
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			key := v.VulnerabilityID + "/" + v.PkgName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			s.add(v.Severity, 1)
+			if v.FixedVersion != "" {
+				s.Fixable++
+			}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,58 @@
+package vulnscan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trivyReportJSON = `{
+  "Metadata": {"RepoDigests": ["ghcr.io/acme/checkout@sha256:abc"]},
+  "Results": [
+    {"Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2026-1", "PkgName": "openssl", "Severity": "CRITICAL", "FixedVersion": "3.0.9"},
+      {"VulnerabilityID": "CVE-2026-2", "PkgName": "zlib", "Severity": "HIGH"},
+      {"VulnerabilityID": "CVE-2026-3", "PkgName": "bash", "Severity": "UNKNOWN"}
+    ]},
+    {"Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2026-1", "PkgName": "openssl", "Severity": "CRITICAL", "FixedVersion": "3.0.9"},
+      {"VulnerabilityID": "CVE-2026-4", "PkgName": "golang.org/x/net", "Severity": "MEDIUM", "FixedVersion": "0.23.0"}
+    ]},
+    {"Vulnerabilities": null}
+  ]
+}`
+
+func TestTrivyScanner_Scan(t *testing.T) {
+	scanner, err := NewTrivyScanner("http://trivy.security:4954", "")
+	require.NoError(t, err)
+	var gotArgs []string
+	scanner.run = func(_ context.Context, binary string, args ...string) ([]byte, error) {
+		assert.Equal(t, "trivy", binary)
+		gotArgs = args
+		return []byte(trivyReportJSON), nil
+	}
+
+	s, err := scanner.Scan(context.Background(), "ghcr.io/acme/checkout:1.2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"image", "--server", "http://trivy.security:4954", "--scanners", "vuln",
+		"--format", "json", "--quiet", "--", "ghcr.io/acme/checkout:1.2"}, gotArgs)
+	assert.Equal(t, "sha256:abc", s.Digest)
+	assert.Equal(t, 1, s.Critical, "a vulnerability listed twice counts once")
+	assert.Equal(t, 1, s.High)
+	assert.Equal(t, 1, s.Medium)
+	assert.Equal(t, 1, s.Unknown)
+	assert.Equal(t, 2, s.Fixable)
+	assert.Equal(t, KindTrivy, s.Scanner)
+
+	scanner.run = func(context.Context, string, ...string) ([]byte, error) {
+		return nil, errors.New("exit status 1: MANIFEST_UNKNOWN")
+	}
+	_, err = scanner.Scan(context.Background(), "ghcr.io/acme/checkout:1.2")
+	assert.ErrorContains(t, err, "MANIFEST_UNKNOWN")
+
+	_, err = NewTrivyScanner("trivy:4954", "")
+	assert.Error(t, err)
+}
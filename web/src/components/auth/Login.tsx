@@ -166,6 +166,20 @@ const OAUTH_ERROR_INFO: Record<string, OAuthErrorEntry> = {
       'Check the backend logs for database errors',
       'If the problem persists, try deleting the local database file and restarting',
     ] },
+  user_limit_reached: {
+    title: 'User Limit Reached',
+    message: 'This console already has as many users as its limits or license allow.',
+    steps: [
+      'Ask a console admin to remove an unused account or raise the user limit',
+      'Users who have signed in before can still sign in',
+    ] },
+  license_invalid: {
+    title: 'License Problem',
+    message: 'The console license is missing, invalid or expired, so no new accounts can be created.',
+    steps: [
+      'Ask a console admin to check the license file set by LICENSE_FILE',
+      'Users who have signed in before can still sign in',
+    ] },
   jwt_failed: {
     title: 'Session Token Generation Failed',
     message: 'The console backend was unable to generate a session token after successful GitHub login.',
@@ -18,12 +18,16 @@ export const FILTER_FIELDS = [
   { field: 'gpuType', label: 'GPU Type', type: 'text' as const },
   { field: 'nodeCount', label: 'Nodes', type: 'number' as const },
   { field: 'podCount', label: 'Pods', type: 'number' as const },
+  { field: 'region', label: 'Region', type: 'text' as const },
+  { field: 'zone', label: 'Zone', type: 'text' as const },
+  { field: 'provider', label: 'Provider', type: 'text' as const },
 ]
 
 export const TEXT_OPERATORS = [
   { value: 'eq', label: 'equals' },
   { value: 'contains', label: 'contains' },
   { value: 'neq', label: 'excludes' },
+  { value: 'regex', label: 'regex' },
 ]
 
 export const MAX_INLINE_BADGES = 4
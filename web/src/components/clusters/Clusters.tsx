@@ -177,7 +177,7 @@ export function Clusters() {
 
   /**
    * Remove an offline cluster's kubeconfig context (#5901).
-   * Backend: `RemoveContext` in pkg/k8s/client.go (added in #5658). The agent
+   * Backend: `RemoveContext` in pkg/agent/kube/client.go (added in #5658). The agent
    * exposes it at POST /kubeconfig/remove on the localhost-only HTTP server.
    *
    * Uses agentFetch() to inject the KC_AGENT_TOKEN Authorization header;
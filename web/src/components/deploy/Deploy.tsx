@@ -103,7 +103,7 @@ export function Deploy() {
             workloadRef: {
               kind: 'Deployment',
               name: workloadName },
-            targetClusters,
+            targetClusters: groupName ? undefined : targetClusters,
             targetGroups: groupName ? [groupName] : undefined } })
 
         // Create WorkloadDeployment CR to track the deployment action
@@ -27,7 +27,7 @@ const ACTIVE_USERS_FETCH_MIN_INTERVAL_MS = 2_000
 const HEARTBEAT_MIN_INTERVAL_MS = HEARTBEAT_INTERVAL
 const STALE_PRESENCE_TIMEOUT_MS = 45_000
 
-import { MAX_WS_RECONNECT_ATTEMPTS, getWsBackoffDelay } from '../lib/constants/network'
+import { MAX_WS_RECONNECT_ATTEMPTS, getWsBackoffDelay, parseWsCloseHint } from '../lib/constants/network'
 import { getWsAuthParams } from '../lib/utils/wsAuth'
 
 const RECOVERY_DELAY = 30_000 // Retry after circuit breaker trips
@@ -300,18 +300,26 @@ async function startPresenceConnection() {
       }
     }
 
-    presenceWs.onclose = () => {
+    presenceWs.onclose = (event) => {
       if (presencePingInterval) clearInterval(presencePingInterval)
       // Clear any pending reconnect before scheduling a new one (#7784)
       if (presenceReconnectTimer) clearTimeout(presenceReconnectTimer)
 
+      // The hub says why it closed the connection and whether to come back.
+      const hint = parseWsCloseHint(event.reason)
+      if (hint && !hint.reconnect) {
+        console.debug(`[ActiveUsers] Connection closed by server (${hint.reason}), not reconnecting`)
+        presenceStarted = false
+        return
+      }
+
       // Check if we've exceeded max reconnect attempts
       if (presenceReconnectAttempts >= MAX_WS_RECONNECT_ATTEMPTS) {
         console.error('[ActiveUsers] Max reconnect attempts exceeded, giving up')
         return
       }
 
-      const delay = getWsBackoffDelay(presenceReconnectAttempts)
+      const delay = hint?.retryAfterMs ?? getWsBackoffDelay(presenceReconnectAttempts)
       console.debug(`[ActiveUsers] Connection lost, reconnecting in ${Math.round(delay)}ms (attempt ${presenceReconnectAttempts + 1}/${MAX_WS_RECONNECT_ATTEMPTS})`)
 
       // Reconnect after exponential backoff delay
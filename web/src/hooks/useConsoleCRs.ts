@@ -34,8 +34,42 @@ export interface ManagedWorkloadSpec {
   targetClusters?: string[]
   targetGroups?: string[]
   replicas?: number
+  replicaDistribution?: ReplicaDistribution
   overrides?: Record<string, unknown>
   suspend?: boolean
+  networkPolicy?: GeneratedNetworkPolicy
+  placement?: PlacementConstraint[]
+}
+
+export interface PlacementConstraint {
+  type: 'Affinity' | 'AntiAffinity' | 'Spread'
+  workloads?: string[]
+  topologyKey?: 'cluster' | 'region' | 'zone'
+  required?: boolean
+}
+
+export interface ReplicaDistribution {
+  strategy?: 'Even' | 'Capacity' | 'Fixed'
+  capacityResource?: 'cpu' | 'memory'
+  clusters?: Record<string, number>
+}
+
+export interface NetworkPolicyPort {
+  port: number
+  protocol?: 'TCP' | 'UDP' | 'SCTP'
+}
+
+export interface NetworkPolicyPeer {
+  direction?: 'Ingress' | 'Egress'
+  namespace?: string
+  podLabels?: Record<string, string>
+  cidr?: string
+  ports?: NetworkPolicyPort[]
+}
+
+export interface GeneratedNetworkPolicy {
+  enabled: boolean
+  allowedPeers?: NetworkPolicyPeer[]
 }
 
 export interface ClusterDeploymentStatus {
@@ -74,7 +108,34 @@ export interface ClusterGroupSpec {
   icon?: string
   staticMembers?: string[]
   dynamicFilters?: ClusterFilter[]
+  /** CEL expression over cluster metadata, ANDed with dynamicFilters */
+  expression?: string
   priority?: number
+  /** ClusterGroups whose clusters also belong to this group; they inherit settings */
+  memberGroups?: string[]
+  settings?: ClusterGroupSettings
+}
+
+export interface ClusterGroupSettings {
+  maintenanceWindows?: MaintenanceWindow[]
+  /** Unset inherits; false overrides an inherited freeze */
+  frozen?: boolean
+  freezeReason?: string
+  notificationRoutes?: NotificationRoute[]
+  /** Default for deployments to the group's clusters; own overrides inherited */
+  retryPolicy?: RetryPolicy
+}
+
+export interface MaintenanceWindow {
+  start: string
+  end: string
+  days?: string[]
+  timezone?: string
+}
+
+export interface NotificationRoute {
+  type: 'slack' | 'email' | 'webhook' | 'pagerduty' | 'opsgenie'
+  target: string
 }
 
 export interface ClusterGroupStatus {
@@ -123,6 +184,32 @@ export interface WorkloadDeploymentSpec {
   dryRun?: boolean
   autoPromote?: boolean
   suspend?: boolean
+  deploymentWindows?: DeploymentWindow[]
+  /** WorkloadDeployments that must be healthy on a cluster before this one deploys to it. */
+  dependsOn?: string[]
+  dependencyTimeout?: string
+  retryPolicy?: RetryPolicy
+}
+
+/** Retries clusters whose apply failed. */
+export interface RetryPolicy {
+  /** Deploy attempts per cluster, counting the first (default 1) */
+  maxAttempts?: number
+  /** Wait before the first retry, doubled before each further one (default 30s) */
+  backoff?: string
+  maxBackoff?: string
+  onFailure?: 'FailFast' | 'Warn'
+}
+
+/** Local-time range during which clusters may be deployed to. */
+export interface DeploymentWindow {
+  clusters?: string[]
+  clusterGroup?: string
+  /** HH:MM, 24h, in the cluster's timezone unless `timezone` is set */
+  start: string
+  end: string
+  days?: string[]
+  timezone?: string
 }
 
 export interface ClusterRolloutStatus {
@@ -133,6 +220,19 @@ export interface ClusterRolloutStatus {
   completedAt?: string
   message?: string
   rollbackAvailable?: boolean
+  nextEligibleAt?: string
+  replicas?: number
+  images?: ImageProvenance[]
+  attempts?: number
+  nextRetryAt?: string
+}
+
+export interface ImageProvenance {
+  container: string
+  image: string
+  digest?: string
+  sbom?: string
+  attestation?: string
 }
 
 export interface CanaryStatus {
@@ -152,6 +252,39 @@ export interface WorkloadDeploymentStatus {
   clusterStatuses?: ClusterRolloutStatus[]
   canaryStatus?: CanaryStatus
   conditions?: Condition[]
+  nextEligibleAt?: string
+  history?: DeploymentHistoryEntry[]
+}
+
+/** Annotations carrying a WorkloadDeployment's change metadata. */
+export const CHANGE_DESCRIPTION_ANNOTATION = 'console.kubestellar.io/change-description'
+export const CHANGE_TICKET_ANNOTATION = 'console.kubestellar.io/change-ticket'
+export const CHANGE_APPROVER_ANNOTATION = 'console.kubestellar.io/change-approver'
+
+export interface ChangeMetadata {
+  description?: string
+  ticketUrl?: string
+  approver?: string
+}
+
+export interface DeploymentHistoryEntry {
+  revision?: number
+  startedAt?: string
+  completedAt?: string
+  phase?: string
+  message?: string
+  change?: ChangeMetadata
+}
+
+/** Change metadata a console project requires (GET /api/persistence/change-policy). */
+export interface ChangePolicy {
+  project: string
+  require_description: boolean
+  require_ticket: boolean
+  require_approver: boolean
+  ticket_url_prefix?: string
+  updated_by?: string
+  updated_at?: string
 }
 
 export interface WorkloadDeployment {
@@ -162,6 +295,7 @@ export interface WorkloadDeployment {
     namespace?: string
     creationTimestamp?: string
     resourceVersion?: string
+    annotations?: Record<string, string>
   }
   spec: WorkloadDeploymentSpec
   status?: WorkloadDeploymentStatus
@@ -9,6 +9,10 @@ import {
   WS_RECONNECT_MAX_DELAY_MS,
   WS_RECONNECT_MAX_RETRIES,
   WS_CONNECTION_TIMEOUT_MS,
+  AGENT_PROTOCOL_VERSION,
+  AGENT_MIN_PROTOCOL_VERSION,
+  HELLO_MESSAGE_TYPE,
+  HELLO_MESSAGE_ID_PREFIX,
 } from './useMissions.constants'
 import type { MissionStatus } from './useMissionTypes'
 import {
@@ -32,6 +36,20 @@ export function createMissionConnectionApi(
     }
   }
 
+  const sendHello = () => {
+    if (state.wsRef.current?.readyState === WebSocket.OPEN) {
+      state.wsRef.current.send(JSON.stringify({
+        id: `${HELLO_MESSAGE_ID_PREFIX}${Date.now()}`,
+        type: HELLO_MESSAGE_TYPE,
+        payload: {
+          protocolVersion: AGENT_PROTOCOL_VERSION,
+          minProtocolVersion: AGENT_MIN_PROTOCOL_VERSION,
+          client: 'console-web',
+        },
+      }))
+    }
+  }
+
   const wsSend = (data: string, onFailure?: () => void): void => {
     let retries = 0
     const WS_SEND_CONNECTING_RETRY_DELAY_MS = 250
@@ -101,6 +119,7 @@ export function createMissionConnectionApi(
         state.wsRef.current.onopen = () => {
           clearTimeout(timeout)
           const epoch = ++state.wsOpenEpoch.current
+          sendHello()
           fetchAgents()
 
           const missionsToReconnect: import('./useMissionTypes').Mission[] = []
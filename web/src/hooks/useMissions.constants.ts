@@ -97,6 +97,17 @@ export const CANCEL_ACK_TIMEOUT_MS = 10_000
 export const CANCEL_ACK_MESSAGE_TYPE = 'cancel_ack'
 export const CANCEL_CONFIRMED_MESSAGE_TYPE = 'cancel_confirmed'
 
+/**
+ * Agent WebSocket protocol range this console speaks (pkg/agent/protocol).
+ * The console sends `hello` on every connect; agents that predate the
+ * handshake answer with an `unknown_type` error and are treated as v1.
+ */
+export const AGENT_PROTOCOL_VERSION = 2
+export const AGENT_MIN_PROTOCOL_VERSION = 1
+export const HELLO_MESSAGE_TYPE = 'hello'
+export const HELLO_ACK_MESSAGE_TYPE = 'hello_ack'
+export const HELLO_MESSAGE_ID_PREFIX = 'hello-'
+
 // ─── Waiting-Input Safety ────────────────────────────────────────────────────
 
 /**
@@ -11,6 +11,8 @@ import { SECONDS_PER_DAY } from '../lib/constants/time'
 import {
   CANCEL_ACK_MESSAGE_TYPE,
   CANCEL_CONFIRMED_MESSAGE_TYPE,
+  HELLO_ACK_MESSAGE_TYPE,
+  HELLO_MESSAGE_ID_PREFIX,
   AGENT_DISCONNECT_ERROR_PATTERNS,
   MISSION_RECONNECT_MAX_AGE_MS,
   STREAM_GAP_THRESHOLD_MS,
@@ -36,6 +38,12 @@ export function createMissionMessageHandler(
       state.wsReconnectAttempts.current = 0
     }
 
+    // Handshake replies never belong to a mission. Pre-handshake agents
+    // answer hello with an unknown_type error, which just means protocol v1.
+    if (message.type === HELLO_ACK_MESSAGE_TYPE || message.id?.startsWith(HELLO_MESSAGE_ID_PREFIX)) {
+      return
+    }
+
     if (message.type === 'agents_list') {
       const payload = message.payload as AgentsListPayload
       const sanitizedAgents = (payload.agents ?? []).map(agent => ({
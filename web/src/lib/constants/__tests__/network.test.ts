@@ -24,6 +24,7 @@ import {
   MAX_MESSAGE_SIZE_CHARS,
   suppressLocalAgent,
   isLocalAgentSuppressed,
+  parseWsCloseHint,
 } from '../network'
 
 const network = {
@@ -116,3 +117,25 @@ describe('network constants', () => {
     }
   })
 })
+
+describe('parseWsCloseHint', () => {
+  it('parses hub reconnect hints', () => {
+    expect(parseWsCloseHint('{"reason":"server at capacity","reconnect":true,"retryAfterMs":30000}')).toEqual({
+      reason: 'server at capacity',
+      reconnect: true,
+      retryAfterMs: 30000,
+    })
+    expect(parseWsCloseHint('{"reason":"session invalidated","reconnect":false}')).toEqual({
+      reason: 'session invalidated',
+      reconnect: false,
+      retryAfterMs: undefined,
+    })
+  })
+
+  it('returns null for closes without a hint', () => {
+    expect(parseWsCloseHint('')).toBeNull()
+    expect(parseWsCloseHint('going away')).toBeNull()
+    expect(parseWsCloseHint('{not json')).toBeNull()
+    expect(parseWsCloseHint('{"reason":"x"}')).toBeNull()
+  })
+})
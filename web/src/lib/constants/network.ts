@@ -364,6 +364,32 @@ export function getWsBackoffDelay(attempt: number): number {
   return delay + jitter
 }
 
+/** Reconnect hint the backend hub sends as JSON in a close frame's reason */
+export interface WsCloseHint {
+  reason: string
+  reconnect: boolean
+  retryAfterMs?: number
+}
+
+/**
+ * Parse the reconnect hint from a hub close frame. Returns null for closes
+ * without a hint (network drops, browser-initiated closes, older backends).
+ */
+export function parseWsCloseHint(reason: string): WsCloseHint | null {
+  if (!reason || reason[0] !== '{') return null
+  try {
+    const hint = JSON.parse(reason) as Partial<WsCloseHint>
+    if (typeof hint.reconnect !== 'boolean') return null
+    return {
+      reason: typeof hint.reason === 'string' ? hint.reason : '',
+      reconnect: hint.reconnect,
+      retryAfterMs: typeof hint.retryAfterMs === 'number' ? hint.retryAfterMs : undefined,
+    }
+  } catch {
+    return null
+  }
+}
+
 /** Delay for simulated AI thinking/processing (300ms) */
 export const AI_THINKING_DELAY_MS = 300
 
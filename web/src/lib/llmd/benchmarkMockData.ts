@@ -36,6 +36,8 @@ export interface Accelerator {
   count: number
   memory?: number
   parallelism?: { dp: number; tp: number; pp: number; ep: number }
+  tdp?: number
+  cost_per_hour?: number
 }
 
 export interface StackComponent {
@@ -143,6 +145,22 @@ export interface BenchmarkReport {
     observability?: { metrics?: ObservabilityMetric[] }
     component_health?: ComponentHealth[]
   }
+  /** Derived by the console from the report; see docs/benchmark-queries.md */
+  extensions?: {
+    efficiency?: {
+      accelerators?: number
+      output_token_rate_per_accelerator?: number
+      total_token_rate_per_accelerator?: number
+      output_token_rate_per_watt?: number
+      goodput?: {
+        ttft_ms?: number
+        tpot_ms?: number
+        attainment: number
+        request_rate?: number
+        output_token_rate?: number
+      }[]
+    }
+  }
 }
 
 // ---------------------------------------------------------------------------